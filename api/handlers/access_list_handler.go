@@ -0,0 +1,118 @@
+/*
+EIP-2930 访问列表API处理器
+
+- POST /api/v1/transactions/access-list - 通过 eth_createAccessList 生成访问列表并对比附带前后的 Gas
+高级发送接口（send-advanced、send-erc20-advanced、合约方法调用）可通过 access_list 指定访问列表，
+或设置 auto_access_list 在发送前自动生成（仅在节省 Gas 时使用）；链不支持时按普通交易发送。
+*/
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"wallet/core"
+	"wallet/pkg/e"
+	"wallet/services"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/gin-gonic/gin"
+)
+
+// AccessListItem 访问列表中的一个合约及其存储槽
+type AccessListItem struct {
+	Address     string   `json:"address"`
+	StorageKeys []string `json:"storage_keys"` // 32 字节 hex
+}
+
+// AccessListRequest 生成访问列表请求
+type AccessListRequest struct {
+	From     string `json:"from" binding:"required"`
+	To       string `json:"to" binding:"required"`
+	ValueWei string `json:"value_wei"` // 可选，十进制字符串
+	DataHex  string `json:"data"`      // 可选，0x 开头或纯 hex
+}
+
+// GenerateAccessList 生成交易的 EIP-2930 访问列表
+// POST /api/v1/transactions/access-list
+// 返回的 access_list 可原样填入高级发送接口的 access_list 字段；recommended 为 false 表示附带后不能节省 Gas
+func (h *WalletHandler) GenerateAccessList(c *gin.Context) {
+	var req AccessListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	var err error
+	if req.From, err = validateAddressField(c, "from", req.From); err != nil {
+		badInput(c, err)
+		return
+	}
+	if req.To, err = validateAddressField(c, "to", req.To); err != nil {
+		badInput(c, err)
+		return
+	}
+	val := big.NewInt(0)
+	if req.ValueWei != "" {
+		if val, err = parseAmountField("value_wei", req.ValueWei, true); err != nil {
+			badInput(c, err)
+			return
+		}
+	}
+	result, err := h.walletService.GenerateAccessList(c.Request.Context(), req.From, req.To, val, req.DataHex)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, core.ErrAccessListUnsupported) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"code": e.ErrorTransactionBuild, "msg": e.GetMsg(e.ErrorTransactionBuild), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": withInputWarnings(c, gin.H{
+		"access_list":      accessListItems(result.AccessList),
+		"gas_with_list":    result.GasWithList,
+		"gas_without_list": result.GasWithoutList,
+		"gas_saved":        result.GasSaved,
+		"recommended":      result.Recommended,
+	})})
+}
+
+// applyAccessList 设置本笔交易的访问列表或自动生成
+func applyAccessList(opts *services.TxOptions, items []AccessListItem, auto bool) error {
+	opts.AutoAccessList = auto
+	if len(items) == 0 {
+		return nil
+	}
+	accessList := make(types.AccessList, 0, len(items))
+	for i, item := range items {
+		if !common.IsHexAddress(item.Address) {
+			return fmt.Errorf("access_list[%d].address 地址格式不正确: %s", i, item.Address)
+		}
+		tuple := types.AccessTuple{Address: common.HexToAddress(item.Address), StorageKeys: make([]common.Hash, 0, len(item.StorageKeys))}
+		for j, key := range item.StorageKeys {
+			raw, err := hexutil.Decode(key)
+			if err != nil || len(raw) != common.HashLength {
+				return fmt.Errorf("access_list[%d].storage_keys[%d] 需要是 0x 开头的 32 字节 hex", i, j)
+			}
+			tuple.StorageKeys = append(tuple.StorageKeys, common.BytesToHash(raw))
+		}
+		accessList = append(accessList, tuple)
+	}
+	opts.AccessList = accessList
+	return nil
+}
+
+// accessListItems 转换为与请求一致的 JSON 格式
+func accessListItems(accessList types.AccessList) []AccessListItem {
+	items := make([]AccessListItem, 0, len(accessList))
+	for _, tuple := range accessList {
+		item := AccessListItem{Address: tuple.Address.Hex(), StorageKeys: make([]string, 0, len(tuple.StorageKeys))}
+		for _, key := range tuple.StorageKeys {
+			item.StorageKeys = append(item.StorageKeys, key.Hex())
+		}
+		items = append(items, item)
+	}
+	return items
+}
@@ -0,0 +1,110 @@
+/*
+收款地址黑名单API处理器
+
+用户维护自己的黑名单，管理员通过 global=true 维护全局黑名单：
+- GET    /api/v1/security/blocklist?global=true - 查询黑名单（global 时附带远程名单状态，仅管理员）
+- POST   /api/v1/security/blocklist - 添加地址
+- DELETE /api/v1/security/blocklist/:address?global=true - 删除地址
+- GET    /api/v1/security/blocklist/check/:address - 检查地址是否在当前用户或全局黑名单中
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"wallet/core"
+	"wallet/pkg/e"
+	"wallet/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BlockedAddressRequest 添加黑名单地址请求
+type BlockedAddressRequest struct {
+	Address string `json:"address" binding:"required"`
+	Reason  string `json:"reason"`
+	Global  bool   `json:"global"` // 添加到全局黑名单（仅管理员）
+}
+
+// ListBlockedAddresses 查询黑名单
+// GET /api/v1/security/blocklist
+func (h *SecurityHandler) ListBlockedAddresses(c *gin.Context) {
+	owner, ok := h.requestOwner(c)
+	if !ok {
+		return
+	}
+	global := c.Query("global") == "true"
+	entries, err := h.securityService.ListBlockedAddresses(owner, global)
+	if err != nil {
+		writeBlocklistError(c, err)
+		return
+	}
+	data := gin.H{"entries": entries, "mode": services.BlocklistMode()}
+	if global {
+		data["status"] = h.securityService.BlocklistStatus()
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": data})
+}
+
+// AddBlockedAddress 添加黑名单地址，已存在时更新原因
+// POST /api/v1/security/blocklist
+func (h *SecurityHandler) AddBlockedAddress(c *gin.Context) {
+	owner, ok := h.requestOwner(c)
+	if !ok {
+		return
+	}
+	var req BlockedAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	entry, err := h.securityService.AddBlockedAddress(c.Request.Context(), owner, req.Address, req.Reason, req.Global)
+	if err != nil {
+		writeBlocklistError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": entry})
+}
+
+// RemoveBlockedAddress 删除黑名单地址
+// DELETE /api/v1/security/blocklist/:address
+func (h *SecurityHandler) RemoveBlockedAddress(c *gin.Context) {
+	owner, ok := h.requestOwner(c)
+	if !ok {
+		return
+	}
+	if err := h.securityService.RemoveBlockedAddress(c.Request.Context(), owner, c.Param("address"), c.Query("global") == "true"); err != nil {
+		writeBlocklistError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": nil})
+}
+
+// CheckBlockedAddress 检查地址是否在当前用户或全局黑名单中，供发送前提示
+// GET /api/v1/security/blocklist/check/:address
+func (h *SecurityHandler) CheckBlockedAddress(c *gin.Context) {
+	owner, ok := h.requestOwner(c)
+	if !ok {
+		return
+	}
+	check, err := h.securityService.CheckRecipient(owner, c.Param("address"))
+	if err != nil {
+		writeBlocklistError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": check})
+}
+
+// writeBlocklistError 黑名单管理错误对应的响应
+func writeBlocklistError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrBlocklistAdminRequired):
+		c.JSON(http.StatusForbidden, gin.H{"code": e.ErrorAuth, "msg": err.Error(), "data": nil})
+	case errors.Is(err, services.ErrBlockedAddressNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"code": e.ERROR, "msg": err.Error(), "data": nil})
+	case errors.Is(err, core.ErrInvalidBlockedAddress):
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ERROR, "msg": e.GetMsg(e.ERROR), "data": err.Error()})
+	}
+}
@@ -0,0 +1,59 @@
+/*
+跨链桥接API处理器
+
+GET /api/v1/bridge/:id/status 返回桥接的实时状态：
+源链确认数、当前步骤、进度（0-1）、目标链交易哈希与下一步提示。
+状态由后台轮询链上数据持续更新，结束后的桥接仍可查询。
+*/
+package handlers
+
+import (
+	"net/http"
+
+	"wallet/pkg/e"
+	"wallet/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BridgeHandler 跨链桥接API处理器
+type BridgeHandler struct {
+	bridgeService *services.BridgeService
+}
+
+// NewBridgeHandler 创建跨链桥接处理器
+func NewBridgeHandler(bridgeService *services.BridgeService) *BridgeHandler {
+	return &BridgeHandler{
+		bridgeService: bridgeService,
+	}
+}
+
+// GetBridgeStatus 查询桥接实时状态
+// GET /api/v1/bridge/:id/status
+func (h *BridgeHandler) GetBridgeStatus(c *gin.Context) {
+	bridgeID := c.Param("id")
+	if bridgeID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  "桥接ID不能为空",
+			"data": nil,
+		})
+		return
+	}
+
+	status, err := h.bridgeService.GetBridgeStatus(c.Request.Context(), bridgeID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": e.ERROR,
+			"msg":  err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "获取桥接状态成功",
+		"data": status,
+	})
+}
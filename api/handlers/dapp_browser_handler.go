@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
+	"wallet/core"
 	"wallet/pkg/e"
 	"wallet/services"
 
@@ -28,6 +30,7 @@ DApp浏览器API处理器
 - /api/v1/dapp/web3/* - Web3请求接口
 - /api/v1/dapp/discovery/* - DApp发现接口
 - /api/v1/dapp/user/* - 用户活动接口
+- /api/v1/dapp/permissions/* - DApp授权管理接口（按方法授权、查看、撤销）
 - /api/v1/dapp/security/* - 安全管理接口
 
 安全特性：
@@ -42,14 +45,16 @@ DApp浏览器API处理器
 // 处理所有DApp浏览器相关的HTTP请求，包括连接管理、Web3请求、DApp发现等功能
 type DAppBrowserHandler struct {
 	dappBrowserService *services.DAppBrowserService // DApp浏览器业务服务实例
+	walletService      *services.WalletService      // 钱包服务（确认交易前的风控与支出限额）
 }
 
 // NewDAppBrowserHandler 创建新的DApp浏览器处理器实例
-// 参数: dappBrowserService - DApp浏览器业务服务实例
+// 参数: dappBrowserService - DApp浏览器业务服务实例；walletService - 钱包服务
 // 返回: 配置好的DApp浏览器处理器
-func NewDAppBrowserHandler(dappBrowserService *services.DAppBrowserService) *DAppBrowserHandler {
+func NewDAppBrowserHandler(dappBrowserService *services.DAppBrowserService, walletService *services.WalletService) *DAppBrowserHandler {
 	return &DAppBrowserHandler{
 		dappBrowserService: dappBrowserService,
+		walletService:      walletService,
 	}
 }
 
@@ -80,6 +85,15 @@ func (h *DAppBrowserHandler) ConnectDApp(c *gin.Context) {
 
 	// 连接DApp
 	response, err := h.dappBrowserService.ConnectDApp(c.Request.Context(), &req)
+	var blocked *core.SecurityBlockedError
+	if errors.As(err, &blocked) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code": e.ErrorPermission,
+			"msg":  blocked.Error(),
+			"data": blocked.Result,
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": e.ERROR,
@@ -96,6 +110,27 @@ func (h *DAppBrowserHandler) ConnectDApp(c *gin.Context) {
 	})
 }
 
+// CheckDAppSecurity 检查DApp域名的钓鱼风险
+// GET /api/v1/dapp/security/check?url=https://...
+// 功能: 连接前预检，返回风险等级、处理动作（allow/warn/block）与命中规则
+func (h *DAppBrowserHandler) CheckDAppSecurity(c *gin.Context) {
+	result, err := h.dappBrowserService.CheckDAppSecurity(c.Query("url"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "检查完成",
+		"data": result,
+	})
+}
+
 // ProcessWeb3Request 处理Web3请求
 // POST /api/v1/dapp/web3/request
 // 请求体: Web3RequestData结构体
@@ -318,10 +353,12 @@ func (h *DAppBrowserHandler) GetUserActivity(c *gin.Context) {
 // 功能: 用户确认或拒绝Web3请求
 func (h *DAppBrowserHandler) ConfirmWeb3Request(c *gin.Context) {
 	var req struct {
-		RequestID string `json:"request_id" binding:"required"`
-		Approved  bool   `json:"approved"`
-		Signature string `json:"signature"`
-		Password  string `json:"password"`
+		RequestID      string `json:"request_id" binding:"required"`
+		Approved       bool   `json:"approved"`
+		DerivationPath string `json:"derivation_path"` // 签名账户派生路径，默认 m/44'/60'/0'/0/0
+		MFACode        string `json:"mfa_code"`        // 交易被判定为异常或超出支出限额时需提交的双因素验证码
+		// 接收地址在黑名单中且处理方式为 warn 时，确认风险后置为 true 重新提交
+		ConfirmRecipient bool `json:"confirm_recipient"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -333,8 +370,43 @@ func (h *DAppBrowserHandler) ConfirmWeb3Request(c *gin.Context) {
 		return
 	}
 
-	// 确认Web3请求
-	err := h.dappBrowserService.ConfirmWeb3Request(c.Request.Context(), req.RequestID, req.Approved, req.Signature)
+	// 确认发送交易前检查接收地址黑名单、评估风险并校验支出限额（与普通发送相同）
+	var risk *txRiskCheck
+	var blocked *services.RecipientCheck
+	if req.Approved {
+		transfer, err := h.dappBrowserService.PendingTransaction(req.RequestID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": e.InvalidParams,
+				"msg":  "确认Web3请求失败: " + err.Error(),
+				"data": nil,
+			})
+			return
+		}
+		var ok bool
+		if transfer != nil {
+			if blocked, ok = checkRecipientOnSend(c, h.walletService, transfer.From, transfer.Recipient, req.ConfirmRecipient); !ok {
+				return
+			}
+		}
+		if risk, ok = checkPendingTransfer(c, h.walletService, transfer, req.MFACode); !ok {
+			return
+		}
+		defer releaseTxRisk(h.walletService, risk)
+	}
+
+	// 确认Web3请求（使用当前钱包会话签名）
+	userID, _ := c.Get("user_id")
+	walletSessionID, _ := userID.(string)
+	result, err := h.dappBrowserService.ConfirmWeb3Request(c.Request.Context(), walletSessionID, req.DerivationPath, req.RequestID, req.Approved)
+	if errors.Is(err, core.ErrRecipientBlocklisted) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code": e.ErrorRecipientBlocked,
+			"msg":  err.Error(),
+			"data": nil,
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": e.ERROR,
@@ -349,14 +421,21 @@ func (h *DAppBrowserHandler) ConfirmWeb3Request(c *gin.Context) {
 		message = "请求已确认"
 	}
 
+	data := gin.H{
+		"request_id": req.RequestID,
+		"approved":   req.Approved,
+		"result":     result.Response,
+		"error":      result.Error,
+		"timestamp":  time.Now().Unix(),
+	}
+	data = withRecipientWarning(data, blocked)
+	if txHash, ok := result.Response.(string); ok && risk != nil {
+		data = recordTxRisk(h.walletService, data, risk, txHash)
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"code": e.SUCCESS,
 		"msg":  message,
-		"data": gin.H{
-			"request_id": req.RequestID,
-			"approved":   req.Approved,
-			"timestamp":  time.Now().Unix(),
-		},
+		"data": data,
 	})
 }
 
@@ -596,3 +675,101 @@ func (h *DAppBrowserHandler) GetSessionInfo(c *gin.Context) {
 		"data": sessionInfo,
 	})
 }
+
+// GrantPermission 授权DApp调用指定方法
+// POST /api/v1/dapp/permissions
+// 请求体: DAppPermissionRequest结构体
+// 功能: 按方法授权（授权 eth_accounts 不包含发送交易与签名），可设置有效期
+func (h *DAppBrowserHandler) GrantPermission(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+
+	var req services.DAppPermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  "请求参数格式错误: " + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	permission, err := h.dappBrowserService.GrantPermission(owner, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  "授权失败: " + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "授权成功",
+		"data": permission,
+	})
+}
+
+// ListPermissions 获取当前用户已授权的DApp
+// GET /api/v1/dapp/permissions
+func (h *DAppBrowserHandler) ListPermissions(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "ok",
+		"data": h.dappBrowserService.ListPermissions(owner),
+	})
+}
+
+// RevokePermission 撤销DApp授权
+// DELETE /api/v1/dapp/permissions/:id
+// 路径参数:
+//   - id: 授权ID
+func (h *DAppBrowserHandler) RevokePermission(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+
+	permissionID := c.Param("id")
+	if err := h.dappBrowserService.RevokePermission(owner, permissionID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": e.InvalidParams,
+			"msg":  "撤销授权失败: " + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "授权已撤销",
+		"data": gin.H{
+			"id":         permissionID,
+			"revoked_at": time.Now().Unix(),
+		},
+	})
+}
+
+// sessionOwner 获取当前会话对应的钱包地址，失败时写入401响应
+func (h *DAppBrowserHandler) sessionOwner(c *gin.Context) (string, bool) {
+	userID, _ := c.Get("user_id")
+	sessionID, _ := userID.(string)
+	owner, err := h.dappBrowserService.SessionAddress(sessionID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code": e.ErrorAuth,
+			"msg":  "会话无效或已过期",
+			"data": nil,
+		})
+		return "", false
+	}
+	return owner, true
+}
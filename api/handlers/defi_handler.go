@@ -43,15 +43,17 @@ import (
 // DeFiHandler DeFi功能API处理器
 // 处理所有DeFi相关的HTTP请求，包括交易、流动性、收益等功能
 type DeFiHandler struct {
-	defiService *services.DeFiService // DeFi业务服务实例
+	defiService   *services.DeFiService   // DeFi业务服务实例
+	walletService *services.WalletService // 钱包服务（Swap 前的风控与支出限额）
 }
 
 // NewDeFiHandler 创建新的DeFi处理器实例
-// 参数: defiService - DeFi业务服务实例
+// 参数: defiService - DeFi业务服务实例；walletService - 钱包服务
 // 返回: 配置好的DeFi处理器
-func NewDeFiHandler(defiService *services.DeFiService) *DeFiHandler {
+func NewDeFiHandler(defiService *services.DeFiService, walletService *services.WalletService) *DeFiHandler {
 	return &DeFiHandler{
-		defiService: defiService,
+		defiService:   defiService,
+		walletService: walletService,
 	}
 }
 
@@ -145,7 +147,7 @@ func (h *DeFiHandler) ExecuteSwap(c *gin.Context) {
 	}
 
 	// 验证数量格式
-	_, ok := new(big.Int).SetString(req.AmountIn, 10)
+	amountIn, ok := new(big.Int).SetString(req.AmountIn, 10)
 	if !ok {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code": e.InvalidParams,
@@ -157,24 +159,44 @@ func (h *DeFiHandler) ExecuteSwap(c *gin.Context) {
 
 	// 执行交易
 	swapReq := &services.SwapRequest{
-		TokenIn:     req.TokenIn,
-		TokenOut:    req.TokenOut,
-		AmountIn:    req.AmountIn,
-		Slippage:    req.Slippage,
-		UserAddress: req.Recipient,
-		Deadline:    req.Deadline,
-		GasPrice:    req.GasPrice,
+		TokenIn:          req.TokenIn,
+		TokenOut:         req.TokenOut,
+		AmountIn:         req.AmountIn,
+		Slippage:         req.Slippage,
+		UserAddress:      req.Recipient,
+		Deadline:         req.Deadline,
+		GasPrice:         req.GasPrice,
+		DerivationPath:   req.DerivationPath,
+		InfiniteApproval: req.InfiniteApproval,
+		AllowHighFee:     req.AllowHighFee,
 	}
 
-	result, err := h.defiService.ExecuteSwap(swapReq, "session_123")
+	// 兑换前评估风险并按输入代币与数量校验支出限额（与普通发送相同）
+	from := ""
+	if signer, err := h.walletService.SessionSigner(req.SessionID, req.DerivationPath); err == nil {
+		from = signer.Address().Hex()
+	}
+	network := h.walletService.GetMultiChainManager().GetCurrentNetwork()
+	risk, ok := checkTxRiskOnNetwork(c, h.walletService, network, from, services.SwapSpendToken(req.TokenIn), amountIn, req.MFACode)
+	if !ok {
+		return
+	}
+	defer releaseTxRisk(h.walletService, risk)
+
+	result, err := h.defiService.ExecuteSwap(swapReq, req.SessionID)
 	if err != nil {
+		code, _ := txSendErrorCode(err, e.ErrorTransactionSend)
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ErrorTransactionSend,
+			"code": code,
 			"msg":  "执行Swap交易失败: " + err.Error(),
 			"data": nil,
 		})
 		return
 	}
+	if result.TxHash != "" {
+		// 响应沿用 SwapResult 结构，风险提示不附加到响应中
+		recordTxRisk(h.walletService, gin.H{}, risk, result.TxHash)
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": e.SUCCESS,
@@ -355,14 +377,13 @@ func (h *DeFiHandler) GetYieldStrategies(c *gin.Context) {
 // GetTokenPrices 获取代币价格信息
 // GET /api/v1/defi/price/tokens
 // 查询参数:
-//   - addresses: 代币地址列表（逗号分隔）
-//   - vs_currency: 计价货币（默认usd）
+//   - addresses: 代币地址列表（逗号分隔，原生币可用 native 或 0xEeee...EEeE）
+//   - chain_id: 链ID（可选，默认当前网络）
 //
-// 响应: 代币价格信息，包含实时价格、24h变化等
+// 响应: 代币地址（小写）到美元价格的映射，无价格数据的代币 price_usd 为 0 且 no_price 为 true
 func (h *DeFiHandler) GetTokenPrices(c *gin.Context) {
 	// 获取参数
 	addresses := c.Query("addresses")
-	_ = c.DefaultQuery("vs_currency", "usd") // 忙略vsCurrency参数
 
 	if addresses == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -373,25 +394,32 @@ func (h *DeFiHandler) GetTokenPrices(c *gin.Context) {
 		return
 	}
 
-	// 获取价格信息 - 简化实现
-	prices := make(map[string]interface{})
-	tokenList := strings.Split(addresses, ",")
-	for _, addr := range tokenList {
-		addr = strings.TrimSpace(addr)
-		if addr != "" {
-			// 模拟价格数据
-			prices[addr] = map[string]interface{}{
-				"price":      "1000.50",
-				"change_24h": "2.5%",
-				"volume":     "1000000",
-				"timestamp":  time.Now().Unix(),
-			}
+	chainID := 0
+	if chainStr := c.Query("chain_id"); chainStr != "" {
+		id, err := strconv.Atoi(chainStr)
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": e.InvalidParams,
+				"msg":  "无效的链ID",
+				"data": nil,
+			})
+			return
+		}
+		chainID = id
+	}
+
+	var tokens []string
+	for _, addr := range strings.Split(addresses, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			tokens = append(tokens, addr)
 		}
 	}
-	var err error // 定义err变量
+
+	// 批量查询价格，无价格数据的代币返回 price_usd=0 且 no_price=true
+	prices, err := h.defiService.GetTokenPrices(c.Request.Context(), chainID, tokens)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
 			"msg":  "获取代币价格失败: " + err.Error(),
 			"data": nil,
 		})
@@ -409,14 +437,19 @@ func (h *DeFiHandler) GetTokenPrices(c *gin.Context) {
 
 // SwapRequest Swap交易请求参数
 type SwapRequest struct {
-	TokenIn      string `json:"token_in" binding:"required"`       // 输入代币地址
-	TokenOut     string `json:"token_out" binding:"required"`      // 输出代币地址
-	AmountIn     string `json:"amount_in" binding:"required"`      // 输入数量
-	AmountOutMin string `json:"amount_out_min" binding:"required"` // 最小输出数量
-	Deadline     int64  `json:"deadline" binding:"required"`       // 交易截止时间
-	Recipient    string `json:"recipient" binding:"required"`      // 接收地址
-	Slippage     string `json:"slippage"`                          // 滑点容忍度
-	GasPrice     string `json:"gas_price"`                         // Gas价格
+	TokenIn          string `json:"token_in" binding:"required"`       // 输入代币地址
+	TokenOut         string `json:"token_out" binding:"required"`      // 输出代币地址
+	AmountIn         string `json:"amount_in" binding:"required"`      // 输入数量
+	AmountOutMin     string `json:"amount_out_min" binding:"required"` // 最小输出数量
+	Deadline         int64  `json:"deadline" binding:"required"`       // 交易截止时间
+	Recipient        string `json:"recipient" binding:"required"`      // 接收地址
+	Slippage         string `json:"slippage"`                          // 滑点容忍度
+	GasPrice         string `json:"gas_price"`                         // Gas价格
+	SessionID        string `json:"session_id" binding:"required"`     // 钱包会话ID（用于签名）
+	DerivationPath   string `json:"derivation_path"`                   // 签名账户派生路径（可选）
+	InfiniteApproval bool   `json:"infinite_approval"`                 // 授权不足时是否无限授权（默认仅授权所需数量）
+	AllowHighFee     bool   `json:"allow_high_fee"`                    // 手续费超过钱包设置的上限时，确认后置为 true 重新提交
+	MFACode          string `json:"mfa_code"`                          // 交易被判定为异常或超出支出限额时需提交的双因素验证码
 }
 
 // AddLiquidityRequest 添加流动性请求参数
@@ -0,0 +1,184 @@
+/*
+代币分发（空投）API处理器
+
+- POST /api/v1/tokens/:token/disperse - 向多个地址分发 ERC20 代币
+  - recipients：逐个指定金额（按权重分配）
+  - total/total_human + addresses：总额平均分配，余数从第一个地址起逐个多分 1 个最小单位
+  - mode：auto（默认）/ contract / batch，使用 Disperse 合约时需先通过 /tokens/:token/approve 授权该合约
+
+与批量发送一样逐个检查接收地址黑名单，并按分发总额检查交易风险与支出限额。
+*/
+package handlers
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"wallet/core"
+	"wallet/pkg/e"
+	"wallet/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DisperseRecipientItem 分发的一个接收方
+type DisperseRecipientItem struct {
+	To          string `json:"to" binding:"required"` // 0x地址、ENS域名或 contact:<联系人ID>
+	Amount      string `json:"amount"`                // 最小单位，十进制字符串（与 amount_human 二选一）
+	AmountHuman string `json:"amount_human"`          // 可读单位金额（如 "1.5"），按代币 decimals 转换
+}
+
+// DisperseRequest 代币分发请求，recipients 与 addresses 二选一
+type DisperseRequest struct {
+	SessionID      string `json:"session_id"`
+	Mnemonic       string `json:"mnemonic"`        // 可选（与 session 二选一）
+	DerivationPath string `json:"derivation_path"` // 默认为 m/44'/60'/0'/0/0
+
+	Recipients []DisperseRecipientItem `json:"recipients"`
+	Addresses  []string                `json:"addresses"`   // 平均分配的接收地址
+	Total      string                  `json:"total"`       // 平均分配的总额，最小单位（与 total_human 二选一）
+	TotalHuman string                  `json:"total_human"` // 平均分配的总额，可读单位
+	Mode       string                  `json:"mode"`        // auto（默认）/ contract / batch
+
+	GasPrice             string `json:"gas_price"`
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas"`
+	MaxFeePerGas         string `json:"max_fee_per_gas"`
+	Nonce                string `json:"nonce"` // 起始 nonce，为空时取 pending nonce
+
+	MFACode string `json:"mfa_code"` // 交易被判定为异常时需提交的双因素验证码
+	// 接收地址在黑名单中且处理方式为 warn 时，确认风险后置为 true 重新提交
+	ConfirmRecipient bool `json:"confirm_recipient"`
+}
+
+// DisperseTokens 向多个地址分发 ERC20 代币
+// POST /api/v1/tokens/:token/disperse
+func (h *WalletHandler) DisperseTokens(c *gin.Context) {
+	token := c.Param("token")
+	var req DisperseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	if req.SessionID == "" && req.Mnemonic == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "需要提供 session_id 或 mnemonic"})
+		return
+	}
+	if (len(req.Recipients) == 0) == (len(req.Addresses) == 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "recipients 与 addresses 必须且只能提供一个"})
+		return
+	}
+	if count := len(req.Recipients) + len(req.Addresses); count > core.MaxBatchTransfers {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": fmt.Sprintf("单次最多分发给 %d 个地址", core.MaxBatchTransfers)})
+		return
+	}
+	opts, err := parseTxOptions(req.GasPrice, req.MaxPriorityFeePerGas, req.MaxFeePerGas, "", req.Nonce)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	recipients, ok := h.disperseRecipients(c, token, &req)
+	if !ok {
+		return
+	}
+
+	from := h.senderAddress(req.SessionID, req.Mnemonic, req.DerivationPath)
+	var warnings []*services.RecipientCheck
+	total := new(big.Int)
+	for i := range recipients {
+		recipient, ok := h.resolveRecipient(c, req.SessionID, recipients[i].Address)
+		if !ok {
+			return
+		}
+		blocked, ok := h.checkRecipient(c, from, recipient.Address, req.ConfirmRecipient)
+		if !ok {
+			return
+		}
+		if blocked != nil && blocked.Blocked {
+			warnings = append(warnings, blocked)
+		}
+		recipients[i].Address = recipient.Address
+		total.Add(total, recipients[i].Amount)
+	}
+	risk, ok := h.checkTxRisk(c, from, token, total, req.MFACode)
+	if !ok {
+		return
+	}
+	defer releaseTxRisk(h.walletService, risk)
+
+	result, err := h.walletService.DisperseTokens(c.Request.Context(), req.SessionID, req.Mnemonic, req.DerivationPath, token, recipients, req.Mode, opts)
+	if err != nil {
+		txSendError(c, http.StatusBadRequest, e.ErrorTransactionSend, err)
+		return
+	}
+	actx := h.txAuditContext(c, req.SessionID, req.Mnemonic, req.DerivationPath)
+	for _, txHash := range result.Transactions {
+		h.walletService.RecordTxAudit("tx_disperse_erc20", txHash, actx, map[string]interface{}{
+			"token":  result.Token,
+			"method": result.Method,
+			"total":  result.Total,
+			"count":  len(result.Recipients),
+		})
+	}
+	if len(result.Transactions) > 0 {
+		recordTxRisk(h.walletService, gin.H{}, risk, result.Transactions[0])
+	}
+
+	data := gin.H{"disperse": result}
+	if len(warnings) > 0 {
+		data["recipient_warnings"] = warnings
+	}
+	for _, item := range result.Recipients {
+		if item.Status == core.BatchStatusFailed || item.Status == core.BatchStatusSkipped {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorTransactionSend, "msg": "部分转账未发送，可重新提交 failed 与 skipped 的接收方", "data": data})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": data})
+}
+
+// disperseRecipients 解析接收方与金额，可读单位金额按代币 decimals 转换；失败时已写入响应
+func (h *WalletHandler) disperseRecipients(c *gin.Context, token string, req *DisperseRequest) ([]core.Recipient, bool) {
+	var decimals *uint8
+	parseAmount := func(raw, human string) (*big.Int, error) {
+		if (raw == "") == (human == "") {
+			return nil, fmt.Errorf("最小单位金额与可读单位金额必须且只能提供一个")
+		}
+		if raw != "" {
+			amount, ok := new(big.Int).SetString(raw, 10)
+			if !ok {
+				return nil, fmt.Errorf("金额需要是十进制数字字符串")
+			}
+			return amount, nil
+		}
+		if decimals == nil {
+			_, _, d, err := h.walletService.GetTokenMetadata(c.Request.Context(), token)
+			if err != nil {
+				return nil, fmt.Errorf("获取代币精度失败: %w", err)
+			}
+			decimals = &d
+		}
+		return core.ParseTokenAmount(human, *decimals)
+	}
+
+	if len(req.Addresses) > 0 {
+		total, err := parseAmount(req.Total, req.TotalHuman)
+		if err == nil {
+			var recipients []core.Recipient
+			if recipients, err = core.EqualSplit(total, req.Addresses); err == nil {
+				return recipients, true
+			}
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return nil, false
+	}
+	recipients := make([]core.Recipient, len(req.Recipients))
+	for i, item := range req.Recipients {
+		amount, err := parseAmount(item.Amount, item.AmountHuman)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": fmt.Sprintf("第 %d 个接收方: %v", i+1, err)})
+			return nil, false
+		}
+		recipients[i] = core.Recipient{Address: item.To, Amount: amount}
+	}
+	return recipients, true
+}
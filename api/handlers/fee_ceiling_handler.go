@@ -0,0 +1,77 @@
+/*
+交易手续费上限API处理器
+
+按钱包地址设置单笔交易的最高手续费（美元），发送时按原生币价格折算，超限的交易在签名前被拒绝（ErrorFeeTooHigh），
+仅会话所属钱包可管理自己的上限：
+- GET    /api/v1/wallets/:address/fee-ceiling - 查询生效的上限（未设置时为全局默认值）
+- PUT    /api/v1/wallets/:address/fee-ceiling - 设置上限
+- DELETE /api/v1/wallets/:address/fee-ceiling - 删除上限，恢复全局默认值
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"wallet/pkg/e"
+	"wallet/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeeCeilingRequest 设置手续费上限请求
+type FeeCeilingRequest struct {
+	MaxFeeUSD float64 `json:"max_fee_usd" binding:"required"` // 单笔交易最高手续费（美元）
+}
+
+// GetFeeCeiling 查询钱包手续费上限
+// GET /api/v1/wallets/:address/fee-ceiling
+func (h *WalletHandler) GetFeeCeiling(c *gin.Context) {
+	owner, ok := h.pathOwner(c, "手续费上限")
+	if !ok {
+		return
+	}
+	status, err := h.walletService.GetFeeCeiling(owner)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ERROR, "msg": e.GetMsg(e.ERROR), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": status})
+}
+
+// SetFeeCeiling 设置钱包手续费上限
+// PUT /api/v1/wallets/:address/fee-ceiling
+func (h *WalletHandler) SetFeeCeiling(c *gin.Context) {
+	owner, ok := h.pathOwner(c, "手续费上限")
+	if !ok {
+		return
+	}
+	var req FeeCeilingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	status, err := h.walletService.SetFeeCeiling(owner, req.MaxFeeUSD)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": status})
+}
+
+// DeleteFeeCeiling 删除钱包手续费上限
+// DELETE /api/v1/wallets/:address/fee-ceiling
+func (h *WalletHandler) DeleteFeeCeiling(c *gin.Context) {
+	owner, ok := h.pathOwner(c, "手续费上限")
+	if !ok {
+		return
+	}
+	if err := h.walletService.DeleteFeeCeiling(owner); err != nil {
+		if errors.Is(err, services.ErrFeeCeilingNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"code": e.ERROR, "msg": err.Error(), "data": nil})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ERROR, "msg": e.GetMsg(e.ERROR), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": nil})
+}
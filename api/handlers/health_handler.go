@@ -0,0 +1,46 @@
+/*
+健康检查API处理器
+
+供编排系统（如 Kubernetes 探针）使用，均无需认证：
+- GET /health - 存活检查，进程能处理请求即返回200
+- GET /ready - 就绪检查，数据库与默认网络RPC可达时返回200，否则返回503；响应中包含各依赖的状态与延迟
+*/
+package handlers
+
+import (
+	"net/http"
+	"wallet/pkg/e"
+	"wallet/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler 健康检查处理器
+type HealthHandler struct {
+	health *services.HealthService
+}
+
+// NewHealthHandler 创建健康检查处理器
+func NewHealthHandler(health *services.HealthService) *HealthHandler {
+	return &HealthHandler{health: health}
+}
+
+// Health 存活检查
+// GET /health
+func (h *HealthHandler) Health(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "ok",
+		"message": "Wallet service is running",
+	})
+}
+
+// Ready 就绪检查
+// GET /ready
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.health.Ready(c.Request.Context())
+	if !report.Ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"code": e.ERROR, "msg": "服务未就绪", "data": report})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": report})
+}
@@ -0,0 +1,115 @@
+/*
+请求参数校验（地址与金额）
+
+common.HexToAddress 对非法输入不报错而是截断或补零，big.Int 的 SetString 接受负数与 0x 前缀，
+直接使用用户输入可能把资金发送到零地址或构造出负数金额。发送类接口在发起交易前先经过这里的校验，
+失败时返回 400 并在 data 中指明出错的字段：
+  - 地址：必须为 0x + 40 位十六进制且不能是零地址；大小写混合时按 EIP-55 校验和校验，不匹配视为输错；
+    全小写/全大写地址没有校验和，允许发送但在响应的 input_warnings 中提示核对
+  - 金额：最小单位的十进制整数，不允许正负号、小数点、空白与 0x 前缀，不超过 uint256；除授权额度外必须大于0
+*/
+package handlers
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"wallet/pkg/e"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/gin-gonic/gin"
+)
+
+// inputWarningsKey 参数校验提示在 gin.Context 中的键
+const inputWarningsKey = "input_warnings"
+
+// validateAddressField 校验 field 字段的 0x 地址，返回 checksum 格式地址；未包含校验和时记录提示
+func validateAddressField(c *gin.Context, field, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("%s 不能为空", field)
+	}
+	if !strings.HasPrefix(value, "0x") && !strings.HasPrefix(value, "0X") {
+		return "", fmt.Errorf("%s 必须以 0x 开头", field)
+	}
+	body := value[2:]
+	if len(body) != common.AddressLength*2 {
+		return "", fmt.Errorf("%s 长度不正确：应为 0x 加 40 位十六进制字符，实际为 %d 位", field, len(body))
+	}
+	for i, ch := range body {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", ch) {
+			return "", fmt.Errorf("%s 第 %d 个字符 %q 不是十六进制字符", field, i+3, ch)
+		}
+	}
+	addr := common.HexToAddress(value)
+	if addr == (common.Address{}) {
+		return "", fmt.Errorf("%s 不能为零地址", field)
+	}
+	checksummed := addr.Hex()
+	if body != strings.ToLower(body) && body != strings.ToUpper(body) {
+		if "0x"+body != checksummed {
+			return "", fmt.Errorf("%s 的 EIP-55 校验和不正确，地址可能输错，正确格式应为 %s", field, checksummed)
+		}
+		return checksummed, nil
+	}
+	addInputWarning(c, fmt.Sprintf("%s 未包含 EIP-55 校验和，请核对地址是否为 %s", field, checksummed))
+	return checksummed, nil
+}
+
+// validateRecipientField 收款目标以 0x 开头时按地址校验；ENS 域名与 contact:<ID> 交由 resolveRecipient 解析
+func validateRecipientField(c *gin.Context, field, value string) (string, error) {
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "0x") || strings.HasPrefix(trimmed, "0X") {
+		return validateAddressField(c, field, trimmed)
+	}
+	return value, nil
+}
+
+// parseAmountField 解析 field 字段的最小单位金额；allowZero 为 false 时金额必须大于0
+func parseAmountField(field, value string, allowZero bool) (*big.Int, error) {
+	if value == "" {
+		return nil, fmt.Errorf("%s 不能为空", field)
+	}
+	for _, ch := range value {
+		if ch < '0' || ch > '9' {
+			return nil, fmt.Errorf("%s 需要是不带符号与小数点的十进制整数（最小单位），包含非法字符 %q", field, ch)
+		}
+	}
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return nil, fmt.Errorf("%s 需要是十进制整数", field)
+	}
+	if amount.Cmp(math.MaxBig256) > 0 {
+		return nil, fmt.Errorf("%s 超出 uint256 范围", field)
+	}
+	if !allowZero && amount.Sign() == 0 {
+		return nil, fmt.Errorf("%s 必须大于0", field)
+	}
+	return amount, nil
+}
+
+// addInputWarning 记录参数校验提示，由 withInputWarnings 附加到响应
+func addInputWarning(c *gin.Context, warning string) {
+	var warnings []string
+	if existing, ok := c.Get(inputWarningsKey); ok {
+		warnings, _ = existing.([]string)
+	}
+	c.Set(inputWarningsKey, append(warnings, warning))
+}
+
+// withInputWarnings 在响应数据中附加参数校验提示（如地址未包含校验和）
+func withInputWarnings(c *gin.Context, data gin.H) gin.H {
+	if existing, ok := c.Get(inputWarningsKey); ok {
+		if warnings, _ := existing.([]string); len(warnings) > 0 {
+			data["input_warnings"] = warnings
+		}
+	}
+	return data
+}
+
+// badInput 写入指明字段的参数错误响应
+func badInput(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+}
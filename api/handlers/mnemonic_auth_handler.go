@@ -9,6 +9,8 @@
 - 通过助记词生成钱包地址
 - 验证助记词有效性
 - 生成临时会话用于交易操作
+- 通过 V3 Keystore 认证，以及将会话钱包导出为 Keystore 备份
+- 由 Shamir 分片恢复助记词并登录（与助记词认证同一流程）
 
 会话管理：
 - 临时会话创建和销毁
@@ -19,14 +21,18 @@
 - 助记词不持久化存储
 - 会话临时存储在内存中
 - 自动会话清理机制
+- 异常登录检测（新设备、新地区、不可能的移动），风险过高时拒绝登录
 */
 
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 	"wallet/api/middleware"
+	"wallet/core"
 	"wallet/pkg/e"
 	"wallet/services"
 
@@ -54,6 +60,7 @@ type MnemonicAuthRequest struct {
 	Mnemonic       string `json:"mnemonic" binding:"required"`
 	DerivationPath string `json:"derivation_path"` // 可选，BIP44派生路径，默认为 m/44'/60'/0'/0/0
 	Name           string `json:"name"`            // 可选，钱包显示名称
+	MFACode        string `json:"mfa_code"`        // 已启用双因素认证时必填：TOTP验证码或一次性备用码
 }
 
 // MnemonicAuthResponse 助记词认证响应
@@ -62,6 +69,10 @@ type MnemonicAuthResponse struct {
 	Address        string `json:"address"`
 	DerivationPath string `json:"derivation_path"`
 	ExpiresAt      int64  `json:"expires_at"`
+	// Risk 登录存在异常（新设备、异地等）但未被拒绝时返回风险评估结果，客户端可提示用户
+	Risk *core.RiskDecision `json:"risk,omitempty"`
+	// Device 本次登录的设备（已登记设备时返回）
+	Device *core.TrustedDevice `json:"device,omitempty"`
 }
 
 // CreateWalletResponse 创建钱包响应
@@ -70,6 +81,19 @@ type CreateWalletResponse struct {
 	Address  string `json:"address"`
 }
 
+// KeystoreAuthRequest Keystore 认证请求
+type KeystoreAuthRequest struct {
+	Keystore json.RawMessage `json:"keystore" binding:"required"` // V3 Keystore，JSON 对象或其字符串形式
+	Password string          `json:"password" binding:"required"` // Keystore 密码
+	MFACode  string          `json:"mfa_code"`                    // 已启用双因素认证时必填
+}
+
+// KeystoreExportRequest Keystore 导出请求
+type KeystoreExportRequest struct {
+	Password       string `json:"password" binding:"required"` // 新 Keystore 的加密密码（至少8个字符）
+	DerivationPath string `json:"derivation_path"`             // 可选，默认为 m/44'/60'/0'/0/0
+}
+
 // =============================================================================
 // 助记词认证和钱包创建
 // =============================================================================
@@ -88,15 +112,63 @@ func (h *MnemonicAuthHandler) AuthenticateWithMnemonic(c *gin.Context) {
 		return
 	}
 
+	h.loginWithMnemonic(c, req.Mnemonic, req.DerivationPath, req.MFACode)
+}
+
+// AuthenticateWithShards
+// * 由不少于阈值数量的 Shamir 分片恢复助记词并登录
+// * 与助记词认证走同一流程：异常登录检测、双因素认证、设备登记后才创建会话
+func (h *MnemonicAuthHandler) AuthenticateWithShards(c *gin.Context) {
+	var req services.ReconstructMnemonicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  "请求参数格式错误: " + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	securityService := h.walletService.GetSecurityService()
+	if securityService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code": e.ERROR,
+			"msg":  "安全服务未初始化",
+			"data": nil,
+		})
+		return
+	}
+	mnemonic, err := securityService.ReconstructMnemonic(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.ERROR,
+			"msg":  "分片恢复失败: " + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	h.loginWithMnemonic(c, mnemonic, req.DerivationPath, req.MFACode)
+}
+
+// loginWithMnemonic 助记词登录流程：派生地址、异常登录检测、双因素认证、设备登记，通过后创建会话并签发JWT
+func (h *MnemonicAuthHandler) loginWithMnemonic(c *gin.Context, mnemonic, derivationPath, mfaCode string) {
 	// 设置默认派生路径
-	derivationPath := req.DerivationPath
 	if derivationPath == "" {
 		derivationPath = "m/44'/60'/0'/0/0"
 	}
 
 	// 通过助记词派生地址
-	address, err := h.walletService.ImportMnemonic(req.Mnemonic, derivationPath)
+	address, err := h.walletService.ImportMnemonic(mnemonic, derivationPath)
 	if err != nil {
+		if code, ok := mnemonicErrorCode(err); ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": code,
+				"msg":  e.GetMsg(code),
+				"data": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": e.ErrorWalletImport,
 			"msg":  "助记词无效或派生地址失败",
@@ -105,8 +177,44 @@ func (h *MnemonicAuthHandler) AuthenticateWithMnemonic(c *gin.Context) {
 		return
 	}
 
+	// 异常登录检测：风险过高直接拒绝；需额外验证时由下方的双因素认证完成
+	event := requestSecurityEvent(c, core.SecurityEventLogin)
+	risk, ok := h.evaluateLoginRisk(c, address, event)
+	if !ok {
+		return
+	}
+
+	// 已启用双因素认证的地址必须提交有效验证码
+	mfaVerified := false
+	if securityService := h.walletService.GetSecurityService(); securityService != nil && securityService.RequiresMFA(address) {
+		if mfaCode == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code": e.ErrorMFARequired,
+				"msg":  e.GetMsg(e.ErrorMFARequired),
+				"data": gin.H{"mfa_required": true},
+			})
+			return
+		}
+		valid, err := securityService.VerifyMFACode(address, mfaCode)
+		if err != nil || !valid {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code": e.ErrorAuth,
+				"msg":  "双因素认证验证码无效",
+				"data": gin.H{"mfa_required": true},
+			})
+			return
+		}
+		mfaVerified = true
+	}
+
+	// 登记登录设备，未识别的设备需确认后才能登录
+	device, ok := h.registerLoginDevice(c, address, mfaVerified)
+	if !ok {
+		return
+	}
+
 	// 创建临时会话（1小时有效期）
-	sessionID, err := h.walletService.CreateSession(req.Mnemonic, derivationPath)
+	sessionID, err := h.walletService.CreateSession(mnemonic, derivationPath)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": e.ERROR,
@@ -116,6 +224,13 @@ func (h *MnemonicAuthHandler) AuthenticateWithMnemonic(c *gin.Context) {
 		return
 	}
 
+	h.walletService.BindSessionDevice(sessionID, address, event.DeviceFingerprint)
+	h.recordLogin(address, event)
+	h.respondWithSession(c, sessionID, address, derivationPath, risk, device)
+}
+
+// respondWithSession 为已创建的会话签发JWT并返回认证响应
+func (h *MnemonicAuthHandler) respondWithSession(c *gin.Context, sessionID, address, derivationPath string, risk *core.RiskDecision, device *core.TrustedDevice) {
 	// 生成JWT token（用于API认证）
 	authManager := middleware.GetAuthManager()
 	if authManager == nil {
@@ -149,17 +264,206 @@ func (h *MnemonicAuthHandler) AuthenticateWithMnemonic(c *gin.Context) {
 			Address:        address,
 			DerivationPath: derivationPath,
 			ExpiresAt:      time.Now().Add(tokenExpiry).Unix(),
+			Risk:           risk,
+			Device:         device,
 		},
 		"token": token,
 	})
 }
 
+// AuthenticateWithKeystore
+// * 通过 V3 Keystore 与密码认证并创建临时会话
+// * 会话仅包含该私钥，可用于交易签名，不支持派生其他地址
+func (h *MnemonicAuthHandler) AuthenticateWithKeystore(c *gin.Context) {
+	var req KeystoreAuthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  "请求参数错误: " + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	// 兼容以字符串形式提交的 Keystore 文件内容
+	keystoreJSON := []byte(req.Keystore)
+	var raw string
+	if err := json.Unmarshal(req.Keystore, &raw); err == nil {
+		keystoreJSON = []byte(raw)
+	}
+
+	sessionID, address, err := h.walletService.ImportKeystoreToSession(keystoreJSON, req.Password)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, core.ErrKeystorePassword) {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, gin.H{
+			"code": e.ErrorWalletKeystore,
+			"msg":  e.GetMsg(e.ErrorWalletKeystore),
+			"data": err.Error(),
+		})
+		return
+	}
+
+	// 异常登录检测：风险过高直接拒绝；需额外验证时由下方的双因素认证完成
+	event := requestSecurityEvent(c, core.SecurityEventLogin)
+	risk, ok := h.evaluateLoginRisk(c, address, event)
+	if !ok {
+		h.walletService.ClearSession(sessionID)
+		return
+	}
+
+	// 已启用双因素认证的地址必须提交有效验证码
+	mfaVerified := false
+	if securityService := h.walletService.GetSecurityService(); securityService != nil && securityService.RequiresMFA(address) {
+		valid := false
+		if req.MFACode != "" {
+			ok, err := securityService.VerifyMFACode(address, req.MFACode)
+			valid = err == nil && ok
+		}
+		if !valid {
+			h.walletService.ClearSession(sessionID)
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code": e.ErrorMFARequired,
+				"msg":  e.GetMsg(e.ErrorMFARequired),
+				"data": gin.H{"mfa_required": true},
+			})
+			return
+		}
+		mfaVerified = true
+	}
+
+	// 登记登录设备，未识别的设备需确认后才能登录
+	device, ok := h.registerLoginDevice(c, address, mfaVerified)
+	if !ok {
+		h.walletService.ClearSession(sessionID)
+		return
+	}
+
+	h.walletService.BindSessionDevice(sessionID, address, event.DeviceFingerprint)
+	h.recordLogin(address, event)
+	h.respondWithSession(c, sessionID, address, "", risk, device)
+}
+
+// evaluateLoginRisk 评估登录风险，被拒绝时写入403响应并返回 false
+// 需额外验证（challenge）的登录：已启用双因素认证的用户本就需提交验证码；未启用的用户放行，并在响应中返回风险评估结果
+func (h *MnemonicAuthHandler) evaluateLoginRisk(c *gin.Context, address string, event core.SecurityEvent) (*core.RiskDecision, bool) {
+	securityService := h.walletService.GetSecurityService()
+	if securityService == nil {
+		return nil, true
+	}
+	decision, err := securityService.EvaluateRisk(c.Request.Context(), address, event)
+	if err == nil && decision.Action == core.RiskActionDeny {
+		err = services.ErrRiskDenied
+	}
+	if !writeRiskError(c, decision, err) {
+		return nil, false
+	}
+	if decision.Action == core.RiskActionAllow {
+		return nil, true
+	}
+	return decision, true
+}
+
+// registerLoginDevice 登记登录设备；设备需确认时写入401响应并返回 false
+// 首次登录的设备与通过双因素认证的设备直接信任，其余新设备需在已信任设备上确认（POST /security/devices/:id/trust）
+func (h *MnemonicAuthHandler) registerLoginDevice(c *gin.Context, address string, mfaVerified bool) (*core.TrustedDevice, bool) {
+	securityService := h.walletService.GetSecurityService()
+	if securityService == nil {
+		return nil, true
+	}
+	device, err := securityService.RegisterLoginDevice(address, services.LoginDevice{
+		Info:      requestDeviceInfo(c),
+		IPAddress: c.ClientIP(),
+		Country:   shareCountry(c),
+	}, mfaVerified)
+	switch {
+	case err == nil:
+		return device, true
+	case errors.Is(err, services.ErrDeviceConfirmationRequired):
+		data := gin.H{"mfa_required": securityService.RequiresMFA(address)}
+		if device != nil {
+			data["device_id"] = device.ID
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code": e.ErrorDeviceUnrecognized,
+			"msg":  err.Error(),
+			"data": data,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ERROR, "msg": e.GetMsg(e.ERROR), "data": err.Error()})
+	}
+	return nil, false
+}
+
+// recordLogin 登录成功后记录事件，更新用户的设备与位置基线
+func (h *MnemonicAuthHandler) recordLogin(address string, event core.SecurityEvent) {
+	if securityService := h.walletService.GetSecurityService(); securityService != nil {
+		securityService.RecordSecurityEvent(address, event)
+	}
+}
+
+// ExportKeystore
+// * 将当前会话钱包指定派生路径的私钥导出为 V3 Keystore，用于备份
+// * 仅助记词会话可导出
+func (h *MnemonicAuthHandler) ExportKeystore(c *gin.Context) {
+	sessionID, _ := c.Get("user_id")
+	id, _ := sessionID.(string)
+
+	var req KeystoreExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  "请求参数错误: " + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	keystoreJSON, err := h.walletService.ExportKeystore(id, req.DerivationPath, req.Password)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.ErrorWalletKeystore,
+			"msg":  e.GetMsg(e.ErrorWalletKeystore),
+			"data": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "Keystore 导出成功，请妥善保管文件与密码",
+		"data": gin.H{"keystore": json.RawMessage(keystoreJSON)},
+	})
+}
+
 // CreateWallet
-// * 创建新的助记词钱包
+// * 创建新的助记词钱包，可选请求体 {"word_count": 24} 指定单词数量（默认12）
 // * 返回助记词和派生地址
 func (h *MnemonicAuthHandler) CreateWallet(c *gin.Context) {
+	var req CreateWalletRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": e.InvalidParams,
+				"msg":  "请求参数错误: " + err.Error(),
+				"data": nil,
+			})
+			return
+		}
+	}
+
 	// 生成新的助记词和地址
-	mnemonic, address, err := h.walletService.CreateNewWallet()
+	mnemonic, address, err := h.walletService.CreateNewWallet(req.WordCount)
+	if errors.Is(err, core.ErrMnemonicWordCount) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.ErrorMnemonicWordCount,
+			"msg":  e.GetMsg(e.ErrorMnemonicWordCount),
+			"data": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": e.ErrorWalletCreate,
@@ -204,3 +508,57 @@ func (h *MnemonicAuthHandler) Logout(c *gin.Context) {
 		"data": nil,
 	})
 }
+
+// =============================================================================
+// 第三方服务密钥
+// =============================================================================
+
+// ProviderKeysRequest 第三方服务密钥设置请求
+// keys: provider -> apiKey，值为空字符串表示删除该服务的用户密钥
+type ProviderKeysRequest struct {
+	Keys map[string]string `json:"keys" binding:"required"`
+}
+
+// UpdateProviderKeys
+// * 设置当前用户的第三方服务API密钥（Alchemy/CoinGecko/OpenSea 等）
+// * 密钥加密存储，接口只写不读，仅返回已配置的服务列表
+func (h *MnemonicAuthHandler) UpdateProviderKeys(c *gin.Context) {
+	sessionID, _ := c.Get("user_id")
+	id, _ := sessionID.(string)
+	address, err := h.walletService.GetSessionAddress(id)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code": e.ErrorAuth,
+			"msg":  "会话无效或已过期",
+			"data": nil,
+		})
+		return
+	}
+
+	var req ProviderKeysRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  "请求参数错误: " + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	keyService := h.walletService.GetProviderKeyService()
+	if err := keyService.SetProviderKeys(address, req.Keys); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  "保存密钥失败: " + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	providers, _ := keyService.ConfiguredProviders(address)
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "密钥已保存",
+		"data": gin.H{"configured_providers": providers},
+	})
+}
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"math/big"
 	"net/http"
 	"strings"
+	"time"
 	"wallet/core"
 	"wallet/pkg/e"
 	"wallet/services"
@@ -11,6 +14,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// networkHealthTimeout 网络健康检查接口的超时时间
+const networkHealthTimeout = 15 * time.Second
+
 // NetworkHandler 网络相关的HTTP请求处理器
 type NetworkHandler struct {
 	multiChain    *core.MultiChainManager
@@ -27,17 +33,23 @@ func NewNetworkHandler(multiChain *core.MultiChainManager, walletService *servic
 
 // NetworkInfoResponse 网络信息响应
 type NetworkInfoResponse struct {
-	ID            string              `json:"id"`
-	Name          string              `json:"name"`
-	ChainID       int64               `json:"chain_id"`
-	Symbol        string              `json:"symbol"`
-	Decimals      int                 `json:"decimals"`
-	BlockExplorer string              `json:"block_explorer"`
-	Testnet       bool                `json:"testnet"`
-	LatestBlock   uint64              `json:"latest_block"`
-	GasSuggestion *core.GasSuggestion `json:"gas_suggestion"`
-	Connected     bool                `json:"connected"`
-	ChainType     string              `json:"chain_type"`
+	ID              string              `json:"id"`
+	Name            string              `json:"name"`
+	ChainID         int64               `json:"chain_id"`
+	DetectedChainID int64               `json:"detected_chain_id,omitempty"` // 节点实际返回的链ID
+	ChainIDMismatch bool                `json:"chain_id_mismatch,omitempty"` // 节点链ID与配置不一致
+	Symbol          string              `json:"symbol"`
+	Decimals        int                 `json:"decimals"`
+	BlockExplorer   string              `json:"block_explorer"`
+	Testnet         bool                `json:"testnet"`
+	LatestBlock     uint64              `json:"latest_block"`
+	GasSuggestion   *core.GasSuggestion `json:"gas_suggestion"`
+	Connected       bool                `json:"connected"`
+	ChainType       string              `json:"chain_type"`
+	Confirmations   uint64              `json:"required_confirmations"` // 交易视为最终确认所需的确认数
+	RPCURL          string              `json:"rpc_url,omitempty"`      // 仅自定义网络返回
+	RPCURLs         []string            `json:"rpc_urls,omitempty"`     // 仅自定义网络返回，首个为主节点
+	Custom          bool                `json:"custom,omitempty"`       // 是否为自定义网络
 }
 
 // ListNetworks 获取网络列表
@@ -47,17 +59,23 @@ func (h *NetworkHandler) ListNetworks(c *gin.Context) {
 	response := make([]NetworkInfoResponse, len(networks))
 	for i, network := range networks {
 		response[i] = NetworkInfoResponse{
-			ID:            network.ID,
-			Name:          network.Name,
-			ChainID:       network.ChainID,
-			Symbol:        network.Symbol,
-			Decimals:      network.Decimals,
-			BlockExplorer: network.BlockExplorer,
-			Testnet:       network.Testnet,
-			LatestBlock:   network.LatestBlock,
-			GasSuggestion: network.GasSuggestion,
-			Connected:     network.Connected,
-			ChainType:     network.ChainType,
+			ID:              network.ID,
+			Name:            network.Name,
+			ChainID:         network.ChainID,
+			DetectedChainID: network.DetectedChainID,
+			ChainIDMismatch: network.ChainIDMismatch,
+			Symbol:          network.Symbol,
+			Decimals:        network.Decimals,
+			BlockExplorer:   network.BlockExplorer,
+			Testnet:         network.Testnet,
+			LatestBlock:     network.LatestBlock,
+			GasSuggestion:   network.GasSuggestion,
+			Connected:       network.Connected,
+			ChainType:       network.ChainType,
+			Confirmations:   network.Confirmations,
+			RPCURL:          network.RPCURL,
+			RPCURLs:         network.RPCURLs,
+			Custom:          network.Custom,
 		}
 	}
 
@@ -91,17 +109,23 @@ func (h *NetworkHandler) GetNetworkInfo(c *gin.Context) {
 	}
 
 	response := NetworkInfoResponse{
-		ID:            networkInfo.ID,
-		Name:          networkInfo.Name,
-		ChainID:       networkInfo.ChainID,
-		Symbol:        networkInfo.Symbol,
-		Decimals:      networkInfo.Decimals,
-		BlockExplorer: networkInfo.BlockExplorer,
-		Testnet:       networkInfo.Testnet,
-		LatestBlock:   networkInfo.LatestBlock,
-		GasSuggestion: networkInfo.GasSuggestion,
-		Connected:     networkInfo.Connected,
-		ChainType:     networkInfo.ChainType,
+		ID:              networkInfo.ID,
+		Name:            networkInfo.Name,
+		ChainID:         networkInfo.ChainID,
+		DetectedChainID: networkInfo.DetectedChainID,
+		ChainIDMismatch: networkInfo.ChainIDMismatch,
+		Symbol:          networkInfo.Symbol,
+		Decimals:        networkInfo.Decimals,
+		BlockExplorer:   networkInfo.BlockExplorer,
+		Testnet:         networkInfo.Testnet,
+		LatestBlock:     networkInfo.LatestBlock,
+		GasSuggestion:   networkInfo.GasSuggestion,
+		Connected:       networkInfo.Connected,
+		ChainType:       networkInfo.ChainType,
+		Confirmations:   networkInfo.Confirmations,
+		RPCURL:          networkInfo.RPCURL,
+		RPCURLs:         networkInfo.RPCURLs,
+		Custom:          networkInfo.Custom,
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -146,18 +170,26 @@ func (h *NetworkHandler) GetCrossChainBalance(c *gin.Context) {
 		return
 	}
 
-	// 转换为字符串格式
+	// 转换为字符串格式，并按各网络原生代币的符号与小数位格式化
 	balanceStrings := make(map[string]string)
+	formatted := make(map[string]gin.H)
 	for network, balance := range balances {
 		balanceStrings[network] = balance.String()
+		native := core.NativeCurrencyFor(network)
+		formatted[network] = gin.H{
+			"balance":  core.FormatUnits(balance, native.Decimals),
+			"symbol":   native.Symbol,
+			"decimals": native.Decimals,
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": e.SUCCESS,
 		"msg":  e.GetMsg(e.SUCCESS),
 		"data": gin.H{
-			"address":  address,
-			"balances": balanceStrings,
+			"address":   address,
+			"balances":  balanceStrings,
+			"formatted": formatted,
 		},
 	})
 }
@@ -232,7 +264,7 @@ func (h *NetworkHandler) GetBalanceOnNetwork(c *gin.Context) {
 	}
 
 	// 使用钱包服务的方法
-	balance, err := h.walletService.GetBalanceOnNetwork(address, networkID)
+	balance, err := h.walletService.GetBalanceOnNetwork(c.Request.Context(), address, networkID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": e.ErrorGetBalance,
@@ -281,10 +313,14 @@ func (h *NetworkHandler) SendETHOnNetwork(c *gin.Context) {
 		err    error
 	)
 
-	// 获取助记词
-	var mnemonic string
+	if !resolveSendAccount(c, h.walletService, req.SessionID, req.Mnemonic, req.Account, &req.DerivationPath) {
+		return
+	}
+
+	// 确定发送方：会话模式使用会话签名者（支持 Keystore 会话）
+	var from string
 	if req.SessionID != "" {
-		mnemonic, err = h.walletService.GetSessionMnemonic(req.SessionID)
+		signer, err := h.walletService.SessionSigner(req.SessionID, req.DerivationPath)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"code": e.InvalidParams,
@@ -293,8 +329,9 @@ func (h *NetworkHandler) SendETHOnNetwork(c *gin.Context) {
 			})
 			return
 		}
+		from = signer.Address().Hex()
 	} else if req.Mnemonic != "" {
-		mnemonic = req.Mnemonic
+		from, _ = h.walletService.ImportMnemonic(req.Mnemonic, req.DerivationPath)
 	} else {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code": e.InvalidParams,
@@ -304,23 +341,34 @@ func (h *NetworkHandler) SendETHOnNetwork(c *gin.Context) {
 		return
 	}
 
+	// 接收地址黑名单、风险评估与支出限额校验
+	blocked, ok := checkRecipientOnSend(c, h.walletService, from, req.To, req.ConfirmRecipient)
+	if !ok {
+		return
+	}
+	risk, ok := checkTxRiskOnNetwork(c, h.walletService, req.NetworkID, from, "", val, req.MFACode)
+	if !ok {
+		return
+	}
+	defer releaseTxRisk(h.walletService, risk)
+
 	// 使用钱包服务的方法
-	txHash, err = h.walletService.SendETHOnNetwork(req.NetworkID, mnemonic, req.DerivationPath, req.To, val)
+	if req.SessionID != "" {
+		txHash, err = h.walletService.SendETHOnNetworkWithSession(c.Request.Context(), req.NetworkID, req.SessionID, req.DerivationPath, req.To, val)
+	} else {
+		txHash, err = h.walletService.SendETHOnNetwork(c.Request.Context(), req.NetworkID, req.Mnemonic, req.DerivationPath, req.To, val)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ErrorTransactionSend,
-			"msg":  e.GetMsg(e.ErrorTransactionSend),
-			"data": err.Error(),
-		})
+		txSendError(c, http.StatusInternalServerError, e.ErrorTransactionSend, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": e.SUCCESS,
 		"msg":  e.GetMsg(e.SUCCESS),
-		"data": gin.H{
+		"data": recordTxRisk(h.walletService, withRecipientWarning(gin.H{
 			"tx_hash": txHash,
-		},
+		}, blocked), risk, txHash),
 	})
 }
 
@@ -363,17 +411,23 @@ func (h *NetworkHandler) SwitchNetwork(c *gin.Context) {
 	}
 
 	response := NetworkInfoResponse{
-		ID:            networkInfo.ID,
-		Name:          networkInfo.Name,
-		ChainID:       networkInfo.ChainID,
-		Symbol:        networkInfo.Symbol,
-		Decimals:      networkInfo.Decimals,
-		BlockExplorer: networkInfo.BlockExplorer,
-		Testnet:       networkInfo.Testnet,
-		LatestBlock:   networkInfo.LatestBlock,
-		GasSuggestion: networkInfo.GasSuggestion,
-		Connected:     networkInfo.Connected,
-		ChainType:     networkInfo.ChainType,
+		ID:              networkInfo.ID,
+		Name:            networkInfo.Name,
+		ChainID:         networkInfo.ChainID,
+		DetectedChainID: networkInfo.DetectedChainID,
+		ChainIDMismatch: networkInfo.ChainIDMismatch,
+		Symbol:          networkInfo.Symbol,
+		Decimals:        networkInfo.Decimals,
+		BlockExplorer:   networkInfo.BlockExplorer,
+		Testnet:         networkInfo.Testnet,
+		LatestBlock:     networkInfo.LatestBlock,
+		GasSuggestion:   networkInfo.GasSuggestion,
+		Connected:       networkInfo.Connected,
+		ChainType:       networkInfo.ChainType,
+		Confirmations:   networkInfo.Confirmations,
+		RPCURL:          networkInfo.RPCURL,
+		RPCURLs:         networkInfo.RPCURLs,
+		Custom:          networkInfo.Custom,
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -383,6 +437,201 @@ func (h *NetworkHandler) SwitchNetwork(c *gin.Context) {
 	})
 }
 
+// AddNetworkRequest 添加自定义网络请求
+type AddNetworkRequest struct {
+	ID            string   `json:"id"` // 网络ID，为空时使用 chain_<chain_id>
+	Name          string   `json:"name" binding:"required"`
+	RPCURL        string   `json:"rpc_url" binding:"required"`
+	FallbackRPCs  []string `json:"fallback_rpc_urls"` // 备用RPC地址（仅 http(s)）
+	ChainID       int64    `json:"chain_id" binding:"required,gt=0"`
+	Symbol        string   `json:"symbol" binding:"required"`
+	Decimals      int      `json:"decimals"`
+	BlockExplorer string   `json:"block_explorer"`
+	Testnet       bool     `json:"testnet"`
+}
+
+// AddNetwork 添加自定义网络（自定义RPC）
+// POST /api/v1/networks
+func (h *NetworkHandler) AddNetwork(c *gin.Context) {
+	var req AddNetworkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  e.GetMsg(e.InvalidParams),
+			"data": err.Error(),
+		})
+		return
+	}
+
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+
+	networkInfo, err := h.walletService.AddCustomNetwork(c.Request.Context(), owner, services.CustomNetworkInput{
+		ID:            req.ID,
+		Name:          req.Name,
+		RPCURL:        req.RPCURL,
+		FallbackRPCs:  req.FallbackRPCs,
+		ChainID:       req.ChainID,
+		Symbol:        req.Symbol,
+		Decimals:      req.Decimals,
+		BlockExplorer: req.BlockExplorer,
+		Testnet:       req.Testnet,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.ERROR,
+			"msg":  err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  e.GetMsg(e.SUCCESS),
+		"data": NetworkInfoResponse{
+			ID:              networkInfo.ID,
+			Name:            networkInfo.Name,
+			ChainID:         networkInfo.ChainID,
+			DetectedChainID: networkInfo.DetectedChainID,
+			ChainIDMismatch: networkInfo.ChainIDMismatch,
+			Symbol:          networkInfo.Symbol,
+			Decimals:        networkInfo.Decimals,
+			BlockExplorer:   networkInfo.BlockExplorer,
+			Testnet:         networkInfo.Testnet,
+			LatestBlock:     networkInfo.LatestBlock,
+			GasSuggestion:   networkInfo.GasSuggestion,
+			Connected:       networkInfo.Connected,
+			ChainType:       networkInfo.ChainType,
+			Confirmations:   networkInfo.Confirmations,
+			RPCURL:          networkInfo.RPCURL,
+			RPCURLs:         networkInfo.RPCURLs,
+			Custom:          networkInfo.Custom,
+		},
+	})
+}
+
+// GetNetworkHealth 检查网络各RPC节点的健康状态（区块高度、延迟、连续失败次数）
+// GET /api/v1/networks/:networkId/health
+func (h *NetworkHandler) GetNetworkHealth(c *gin.Context) {
+	networkID := c.Param("networkId")
+	ctx, cancel := context.WithTimeout(c.Request.Context(), networkHealthTimeout)
+	defer cancel()
+	endpoints, err := h.multiChain.NetworkHealth(ctx, networkID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": e.ERROR,
+			"msg":  err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	healthy := 0
+	for _, ep := range endpoints {
+		if ep.Healthy {
+			healthy++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  e.GetMsg(e.SUCCESS),
+		"data": gin.H{
+			"network_id":        networkID,
+			"healthy":           healthy > 0,
+			"healthy_endpoints": healthy,
+			"endpoints":         endpoints,
+		},
+	})
+}
+
+// RemoveNetwork 移除自定义网络（仅添加者或管理员，当前正在使用的网络不能移除）
+// DELETE /api/v1/networks/:networkId
+func (h *NetworkHandler) RemoveNetwork(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	networkID := c.Param("networkId")
+	if err := h.walletService.RemoveCustomNetwork(owner, networkID); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrCustomNetworkForbidden) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{
+			"code": e.ERROR,
+			"msg":  err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  e.GetMsg(e.SUCCESS),
+		"data": gin.H{"network_id": networkID},
+	})
+}
+
+// sessionOwner 返回当前会话的钱包地址，未登录或会话无效时写入401响应
+func (h *NetworkHandler) sessionOwner(c *gin.Context) (string, bool) {
+	userID, _ := c.Get("user_id")
+	sessionID, _ := userID.(string)
+	owner, err := h.walletService.GetSessionAddress(sessionID)
+	if err != nil || owner == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": e.ErrorAuth, "msg": "需要有效的登录会话", "data": nil})
+		return "", false
+	}
+	return owner, true
+}
+
+// SetConfirmationsRequest 设置网络确认数请求
+type SetConfirmationsRequest struct {
+	Confirmations *uint64 `json:"required_confirmations" binding:"required"` // 为0时恢复网络配置的值
+}
+
+// SetRequiredConfirmations 设置网络的最终确认数（确认等待、待确认交易跟踪与交易历史均按此值判断，仅管理员）
+// PUT /api/v1/networks/:networkId/confirmations
+func (h *NetworkHandler) SetRequiredConfirmations(c *gin.Context) {
+	networkID := c.Param("networkId")
+	var req SetConfirmationsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  e.GetMsg(e.InvalidParams),
+			"data": err.Error(),
+		})
+		return
+	}
+
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+
+	confirmations, err := h.walletService.SetRequiredConfirmations(owner, networkID, *req.Confirmations)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrConfirmationsForbidden) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{
+			"code": e.ERROR,
+			"msg":  err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  e.GetMsg(e.SUCCESS),
+		"data": gin.H{"network_id": networkID, "required_confirmations": confirmations},
+	})
+}
+
 // GetCurrentNetwork 获取当前网络信息
 // GET /api/v1/networks/current
 func (h *NetworkHandler) GetCurrentNetwork(c *gin.Context) {
@@ -399,17 +648,23 @@ func (h *NetworkHandler) GetCurrentNetwork(c *gin.Context) {
 	}
 
 	response := NetworkInfoResponse{
-		ID:            networkInfo.ID,
-		Name:          networkInfo.Name,
-		ChainID:       networkInfo.ChainID,
-		Symbol:        networkInfo.Symbol,
-		Decimals:      networkInfo.Decimals,
-		BlockExplorer: networkInfo.BlockExplorer,
-		Testnet:       networkInfo.Testnet,
-		LatestBlock:   networkInfo.LatestBlock,
-		GasSuggestion: networkInfo.GasSuggestion,
-		Connected:     networkInfo.Connected,
-		ChainType:     networkInfo.ChainType,
+		ID:              networkInfo.ID,
+		Name:            networkInfo.Name,
+		ChainID:         networkInfo.ChainID,
+		DetectedChainID: networkInfo.DetectedChainID,
+		ChainIDMismatch: networkInfo.ChainIDMismatch,
+		Symbol:          networkInfo.Symbol,
+		Decimals:        networkInfo.Decimals,
+		BlockExplorer:   networkInfo.BlockExplorer,
+		Testnet:         networkInfo.Testnet,
+		LatestBlock:     networkInfo.LatestBlock,
+		GasSuggestion:   networkInfo.GasSuggestion,
+		Connected:       networkInfo.Connected,
+		ChainType:       networkInfo.ChainType,
+		Confirmations:   networkInfo.Confirmations,
+		RPCURL:          networkInfo.RPCURL,
+		RPCURLs:         networkInfo.RPCURLs,
+		Custom:          networkInfo.Custom,
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -425,6 +680,10 @@ type SendETHOnNetworkRequest struct {
 	SessionID      string `json:"session_id"`
 	Mnemonic       string `json:"mnemonic"`
 	DerivationPath string `json:"derivation_path"`
+	Account        *int   `json:"account"` // 账户序号（可选，代替 derivation_path）
 	To             string `json:"to" binding:"required"`
 	ValueWei       string `json:"value_wei" binding:"required"`
+	MFACode        string `json:"mfa_code"` // 交易被判定为异常或超出支出限额时需提交的双因素验证码
+	// 接收地址在黑名单中且处理方式为 warn 时，确认风险后置为 true 重新提交
+	ConfirmRecipient bool `json:"confirm_recipient"`
 }
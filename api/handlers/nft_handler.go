@@ -12,6 +12,7 @@ NFT功能API处理器
 
 接口分组：
 - /api/v1/nft/user/* - 用户NFT相关接口
+- /api/v1/nft/owned - 链上枚举地址持有的NFT
 - /api/v1/nft/collections/* - 集合相关接口
 - /api/v1/nft/market/* - 市场数据接口
 - /api/v1/nft/transfer/* - 转账操作接口
@@ -35,6 +36,7 @@ import (
 	"wallet/pkg/e"
 	"wallet/services"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
 )
 
@@ -128,6 +130,68 @@ func (h *NFTHandler) GetUserNFTs(c *gin.Context) {
 	})
 }
 
+// GetOwnedNFTs 枚举地址实际持有的NFT（链上查询）
+// GET /api/v1/nft/owned?address=0x...
+// 查询参数:
+//   - address: 持有者地址（必需）
+//   - contracts: 逗号分隔的合约地址（可选，不指定时从最近的转账日志识别）
+//   - limit: 返回数量限制（默认50，最大200）
+//   - offset: 偏移量（默认0）
+//
+// 响应: 当前页NFT（含元数据）及总数
+func (h *NFTHandler) GetOwnedNFTs(c *gin.Context) {
+	owner := c.Query("address")
+	if !common.IsHexAddress(owner) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  "无效的地址: " + owner,
+			"data": nil,
+		})
+		return
+	}
+
+	var contracts []string
+	for _, contract := range strings.Split(c.Query("contracts"), ",") {
+		if contract = strings.TrimSpace(contract); contract == "" {
+			continue
+		}
+		if !common.IsHexAddress(contract) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": e.InvalidParams,
+				"msg":  "无效的合约地址: " + contract,
+				"data": nil,
+			})
+			return
+		}
+		contracts = append(contracts, common.HexToAddress(contract).Hex())
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	page, err := h.nftService.GetOwnedNFTs(c.Request.Context(), owner, contracts, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "查询持有的NFT失败: " + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "ok",
+		"data": page,
+	})
+}
+
 // GetNFTDetails 获取NFT详细信息
 // GET /api/v1/nft/details/:contract/:tokenId
 // 路径参数:
@@ -267,8 +331,9 @@ func (h *NFTHandler) TransferNFT(c *gin.Context) {
 	// 执行NFT转账
 	result, err := h.nftService.TransferNFT(c.Request.Context(), &req, mnemonic, derivationPath)
 	if err != nil {
+		code, _ := txSendErrorCode(err, e.ErrorTransactionSend)
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ErrorTransactionSend,
+			"code": code,
 			"msg":  "NFT转账失败: " + err.Error(),
 			"data": nil,
 		})
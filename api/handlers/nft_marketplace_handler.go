@@ -6,6 +6,7 @@ NFT市场API处理器
 package handlers
 
 import (
+	"errors"
 	"math/big"
 	"net/http"
 	"strconv"
@@ -79,8 +80,8 @@ func (h *NFTMarketplaceHandler) GetMarketListings(c *gin.Context) {
 
 	listings, err := h.marketplaceService.GetMarketListings(c.Request.Context(), userAddress, request)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
+		c.JSON(marketErrorStatus(err), gin.H{
+			"code": marketErrorCode(err),
 			"msg":  "获取市场挂单失败: " + err.Error(),
 			"data": nil,
 		})
@@ -131,8 +132,8 @@ func (h *NFTMarketplaceHandler) GetMarketTransactions(c *gin.Context) {
 
 	transactions, err := h.marketplaceService.GetMarketTransactions(c.Request.Context(), userAddress, request)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
+		c.JSON(marketErrorStatus(err), gin.H{
+			"code": marketErrorCode(err),
 			"msg":  "获取交易记录失败: " + err.Error(),
 			"data": nil,
 		})
@@ -166,8 +167,8 @@ func (h *NFTMarketplaceHandler) GetMarketStats(c *gin.Context) {
 
 	stats, err := h.marketplaceService.GetMarketStats(c.Request.Context(), contract, platform)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
+		c.JSON(marketErrorStatus(err), gin.H{
+			"code": marketErrorCode(err),
 			"msg":  "获取市场统计失败: " + err.Error(),
 			"data": nil,
 		})
@@ -207,8 +208,8 @@ func (h *NFTMarketplaceHandler) AnalyzeMarket(c *gin.Context) {
 
 	analysis, err := h.marketplaceService.AnalyzeMarket(c.Request.Context(), userAddress, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
+		c.JSON(marketErrorStatus(err), gin.H{
+			"code": marketErrorCode(err),
 			"msg":  "市场分析失败: " + err.Error(),
 			"data": nil,
 		})
@@ -370,11 +371,13 @@ func (h *NFTMarketplaceHandler) CreatePriceAlert(c *gin.Context) {
 	var req struct {
 		Contract    string `json:"contract" binding:"required"`
 		TokenID     string `json:"token_id"`
-		AlertType   string `json:"alert_type" binding:"required"`
-		TargetPrice struct {
+		AlertType   string `json:"alert_type" binding:"required"` // above / below / change
+		TargetPrice *struct {
 			Amount   string `json:"amount" binding:"required"`
 			Currency string `json:"currency" binding:"required"`
-		} `json:"target_price" binding:"required"`
+		} `json:"target_price"` // above / below 必填
+		ChangePercent float64 `json:"change_percent"` // change 必填，涨跌幅阈值（%）
+		Repeat        bool    `json:"repeat"`         // 重复提醒，默认触发一次后停用
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -387,25 +390,27 @@ func (h *NFTMarketplaceHandler) CreatePriceAlert(c *gin.Context) {
 	}
 
 	// 解析目标价格
-	amount, ok := new(big.Int).SetString(req.TargetPrice.Amount, 10)
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code": e.InvalidParams,
-			"msg":  "无效的价格格式",
-			"data": nil,
-		})
-		return
-	}
-
-	targetPrice := &core.MarketPrice{
-		Amount:   amount,
-		Currency: req.TargetPrice.Currency,
-		Symbol:   req.TargetPrice.Currency,
+	var targetPrice *core.MarketPrice
+	if req.TargetPrice != nil {
+		amount, ok := new(big.Int).SetString(req.TargetPrice.Amount, 10)
+		if !ok || amount.Sign() <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": e.InvalidParams,
+				"msg":  "无效的价格格式",
+				"data": nil,
+			})
+			return
+		}
+		targetPrice = &core.MarketPrice{
+			Amount:   amount,
+			Currency: req.TargetPrice.Currency,
+			Symbol:   req.TargetPrice.Currency,
+		}
 	}
 
-	alert, err := h.marketplaceService.CreatePriceAlert(userAddress, req.Contract, req.TokenID, req.AlertType, targetPrice)
+	alert, err := h.marketplaceService.CreatePriceAlert(userAddress, req.Contract, req.TokenID, req.AlertType, targetPrice, req.ChangePercent, req.Repeat)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(http.StatusBadRequest, gin.H{
 			"code": e.ERROR,
 			"msg":  "创建提醒失败: " + err.Error(),
 			"data": nil,
@@ -452,3 +457,88 @@ func (h *NFTMarketplaceHandler) GetPriceAlerts(c *gin.Context) {
 		},
 	})
 }
+
+// PausePriceAlert 暂停价格提醒
+// POST /api/v1/nft/marketplace/price-alerts/:id/pause
+func (h *NFTMarketplaceHandler) PausePriceAlert(c *gin.Context) {
+	h.setPriceAlertActive(c, false)
+}
+
+// ResumePriceAlert 恢复价格提醒（已触发停用的一次性提醒也可恢复）
+// POST /api/v1/nft/marketplace/price-alerts/:id/resume
+func (h *NFTMarketplaceHandler) ResumePriceAlert(c *gin.Context) {
+	h.setPriceAlertActive(c, true)
+}
+
+// setPriceAlertActive 暂停或恢复价格提醒
+func (h *NFTMarketplaceHandler) setPriceAlertActive(c *gin.Context, active bool) {
+	userAddress := c.GetHeader("X-User-Address")
+	if userAddress == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  "用户地址不能为空",
+			"data": nil,
+		})
+		return
+	}
+
+	alert, err := h.marketplaceService.SetPriceAlertActive(userAddress, c.Param("id"), active)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": e.ERROR,
+			"msg":  err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "ok",
+		"data": alert,
+	})
+}
+
+// GetPriceAlertHistory 获取价格提醒触发记录
+// GET /api/v1/nft/marketplace/price-alerts/history
+func (h *NFTMarketplaceHandler) GetPriceAlertHistory(c *gin.Context) {
+	userAddress := c.GetHeader("X-User-Address")
+	if userAddress == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  "用户地址不能为空",
+			"data": nil,
+		})
+		return
+	}
+
+	history := h.marketplaceService.GetPriceAlertHistory(userAddress)
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "获取成功",
+		"data": gin.H{
+			"triggers": history,
+			"count":    len(history),
+		},
+	})
+}
+
+// marketErrorStatus 将市场API错误映射为HTTP状态码：限流为429，集合不存在为404
+func marketErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, core.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, core.ErrMarketNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// marketErrorCode 市场API错误对应的业务错误码
+func marketErrorCode(err error) int {
+	if errors.Is(err, core.ErrRateLimited) {
+		return e.ErrorRateLimit
+	}
+	return e.ERROR
+}
@@ -0,0 +1,183 @@
+/*
+NFT 转账API处理器
+
+基于会话或助记词签名的 ERC-721 / ERC-1155 转账与持有查询：
+- POST /api/v1/nft/transfer/erc721 - safeTransferFrom(from, to, tokenId)
+- POST /api/v1/nft/transfer/erc1155 - safeTransferFrom(from, to, id, amount, data)
+- GET  /api/v1/nft/erc721/:contract/:tokenId/owner - 查询持有者
+- GET  /api/v1/nft/erc1155/:contract/:tokenId/balance?owner= - 查询持有数量
+*/
+package handlers
+
+import (
+	"math/big"
+	"net/http"
+	"wallet/pkg/e"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+// NFTTransferRequest ERC-721 / ERC-1155 转账请求
+type NFTTransferRequest struct {
+	SessionID      string `json:"session_id"`
+	Mnemonic       string `json:"mnemonic"`
+	DerivationPath string `json:"derivation_path"`
+	Account        *int   `json:"account"` // 账户序号（可选，代替 derivation_path）
+	Contract       string `json:"contract" binding:"required"`
+	To             string `json:"to" binding:"required"`
+	TokenID        string `json:"token_id" binding:"required"` // 十进制字符串
+	Amount         string `json:"amount"`                      // 仅 ERC-1155，十进制字符串，默认1
+
+	GasPrice             string `json:"gas_price"`
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas"`
+	MaxFeePerGas         string `json:"max_fee_per_gas"`
+	GasLimit             string `json:"gas_limit"`
+	Nonce                string `json:"nonce"`
+
+	// 接收地址在黑名单中且处理方式为 warn 时，确认风险后置为 true 重新提交
+	ConfirmRecipient bool `json:"confirm_recipient"`
+}
+
+// TransferERC721 转出 ERC-721 NFT
+func (h *WalletHandler) TransferERC721(c *gin.Context) {
+	h.transferNFT(c, false)
+}
+
+// TransferERC1155 转出 ERC-1155 NFT
+func (h *WalletHandler) TransferERC1155(c *gin.Context) {
+	h.transferNFT(c, true)
+}
+
+// transferNFT 解析并校验转账请求，按标准分派
+func (h *WalletHandler) transferNFT(c *gin.Context, erc1155 bool) {
+	var req NFTTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	if !h.resolveAccountPath(c, req.SessionID, req.Mnemonic, req.Account, &req.DerivationPath) {
+		return
+	}
+	if !common.IsHexAddress(req.Contract) || !common.IsHexAddress(req.To) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "contract/to 地址格式不正确"})
+		return
+	}
+	tokenID, ok := new(big.Int).SetString(req.TokenID, 10)
+	if !ok || tokenID.Sign() < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "token_id 需要十进制字符串"})
+		return
+	}
+	amount := big.NewInt(1)
+	if erc1155 && req.Amount != "" {
+		if _, ok := amount.SetString(req.Amount, 10); !ok || amount.Sign() <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "amount 需要大于0的十进制字符串"})
+			return
+		}
+	}
+	opts, err := parseTxOptions(req.GasPrice, req.MaxPriorityFeePerGas, req.MaxFeePerGas, req.GasLimit, req.Nonce)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	if req.DerivationPath == "" {
+		req.DerivationPath = "m/44'/60'/0'/0/0"
+	}
+	blocked, ok := h.checkRecipient(c, h.senderAddress(req.SessionID, req.Mnemonic, req.DerivationPath), req.To, req.ConfirmRecipient)
+	if !ok {
+		return
+	}
+
+	var txHash string
+	switch {
+	case req.SessionID != "" && erc1155:
+		txHash, err = h.walletService.TransferERC1155WithSession(c.Request.Context(), req.SessionID, req.DerivationPath, req.Contract, req.To, tokenID, amount, opts)
+	case req.SessionID != "":
+		txHash, err = h.walletService.TransferERC721WithSession(c.Request.Context(), req.SessionID, req.DerivationPath, req.Contract, req.To, tokenID, opts)
+	case req.Mnemonic != "" && erc1155:
+		txHash, err = h.walletService.TransferERC1155(c.Request.Context(), req.Mnemonic, req.DerivationPath, req.Contract, req.To, tokenID, amount, opts)
+	case req.Mnemonic != "":
+		txHash, err = h.walletService.TransferERC721(c.Request.Context(), req.Mnemonic, req.DerivationPath, req.Contract, req.To, tokenID, opts)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "需要提供 session_id 或 mnemonic"})
+		return
+	}
+	if err != nil {
+		txSendError(c, http.StatusInternalServerError, e.ErrorTransactionSend, err)
+		return
+	}
+
+	standard := "erc721"
+	if erc1155 {
+		standard = "erc1155"
+	}
+	h.walletService.RecordTxAudit("tx_nft_transfer", txHash, h.txAuditContext(c, req.SessionID, req.Mnemonic, req.DerivationPath), map[string]interface{}{
+		"standard":                 standard,
+		"contract":                 req.Contract,
+		"to":                       req.To,
+		"token_id":                 req.TokenID,
+		"amount":                   amount.String(),
+		"gas_price":                req.GasPrice,
+		"max_priority_fee_per_gas": req.MaxPriorityFeePerGas,
+		"max_fee_per_gas":          req.MaxFeePerGas,
+		"gas_limit":                req.GasLimit,
+		"nonce":                    req.Nonce,
+	})
+	data := gin.H{"tx_hash": txHash, "standard": standard, "contract": req.Contract, "to": req.To, "token_id": req.TokenID}
+	if erc1155 {
+		data["amount"] = amount.String()
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": withRecipientWarning(data, blocked)})
+}
+
+// GetERC721Owner 查询 ERC-721 代币持有者
+func (h *WalletHandler) GetERC721Owner(c *gin.Context) {
+	contract, tokenID, ok := parseNFTPath(c)
+	if !ok {
+		return
+	}
+	owner, err := h.walletService.GetERC721Owner(c.Request.Context(), contract, tokenID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorContractCall, "msg": e.GetMsg(e.ErrorContractCall), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{
+		"contract": contract, "token_id": tokenID.String(), "owner": owner,
+	}})
+}
+
+// GetERC1155Balance 查询 ERC-1155 代币持有数量
+func (h *WalletHandler) GetERC1155Balance(c *gin.Context) {
+	contract, tokenID, ok := parseNFTPath(c)
+	if !ok {
+		return
+	}
+	owner := c.Query("owner")
+	if !common.IsHexAddress(owner) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "owner 地址格式不正确"})
+		return
+	}
+	balance, err := h.walletService.GetERC1155Balance(c.Request.Context(), contract, owner, tokenID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorContractCall, "msg": e.GetMsg(e.ErrorContractCall), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{
+		"contract": contract, "token_id": tokenID.String(), "owner": owner, "balance": balance.String(),
+	}})
+}
+
+// parseNFTPath 解析路径中的合约地址与 tokenId，失败时写入400响应
+func parseNFTPath(c *gin.Context) (string, *big.Int, bool) {
+	contract := c.Param("contract")
+	if !common.IsHexAddress(contract) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "合约地址格式不正确"})
+		return "", nil, false
+	}
+	tokenID, ok := new(big.Int).SetString(c.Param("tokenId"), 10)
+	if !ok || tokenID.Sign() < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "tokenId 需要十进制字符串"})
+		return "", nil, false
+	}
+	return contract, tokenID, true
+}
@@ -0,0 +1,185 @@
+/*
+通知收件箱API处理器
+
+价格提醒、多签审批、到账等通知写入会话所属钱包的收件箱，并按通知渠道设置投递：
+- GET  /api/v1/notifications?unread=true&type=&offset=&limit= - 分页查询收件箱（新的在前）
+- GET  /api/v1/notifications/:id - 通知详情与各渠道投递状态
+- POST /api/v1/notifications/:id/read - 标记已读
+- POST /api/v1/notifications/read-all - 全部标记已读
+- GET  /api/v1/notifications/settings - 查询通知渠道设置（含 Webhook 签名密钥）
+- PUT  /api/v1/notifications/settings - 修改通知渠道设置
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"wallet/pkg/e"
+	"wallet/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler 通知收件箱处理器
+type NotificationHandler struct {
+	walletService       *services.WalletService
+	notificationService *services.NotificationService
+}
+
+// NewNotificationHandler 创建通知收件箱处理器
+func NewNotificationHandler(walletService *services.WalletService) *NotificationHandler {
+	return &NotificationHandler{
+		walletService:       walletService,
+		notificationService: walletService.GetNotificationService(),
+	}
+}
+
+// NotificationSettingsRequest 修改通知渠道设置请求，未提交的字段保持不变
+type NotificationSettingsRequest struct {
+	PushEnabled  *bool   `json:"push_enabled"`  // 推送到实时连接
+	Email        *string `json:"email"`         // 邮件地址，空字符串关闭邮件通知
+	WebhookURL   *string `json:"webhook_url"`   // 回调地址（http/https），空字符串关闭 Webhook
+	RotateSecret bool    `json:"rotate_secret"` // 重新生成 Webhook 签名密钥
+}
+
+// ListNotifications 分页查询收件箱
+// GET /api/v1/notifications
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	unreadOnly := c.Query("unread") == "true"
+	page, err := h.notificationService.ListNotifications(owner, unreadOnly, c.Query("type"), offset, limit)
+	if err != nil {
+		writeNotificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": page})
+}
+
+// GetNotification 通知详情与投递状态
+// GET /api/v1/notifications/:id
+func (h *NotificationHandler) GetNotification(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	id, ok := notificationID(c)
+	if !ok {
+		return
+	}
+	notification, err := h.notificationService.GetNotification(owner, id)
+	if err != nil {
+		writeNotificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": notification})
+}
+
+// MarkNotificationRead 标记通知已读
+// POST /api/v1/notifications/:id/read
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	id, ok := notificationID(c)
+	if !ok {
+		return
+	}
+	if err := h.notificationService.MarkRead(owner, id); err != nil {
+		writeNotificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": "已标记为已读", "data": nil})
+}
+
+// MarkAllNotificationsRead 全部标记已读
+// POST /api/v1/notifications/read-all
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	count, err := h.notificationService.MarkAllRead(owner)
+	if err != nil {
+		writeNotificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": "已全部标记为已读", "data": gin.H{"updated": count}})
+}
+
+// GetNotificationSettings 查询通知渠道设置
+// GET /api/v1/notifications/settings
+func (h *NotificationHandler) GetNotificationSettings(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	settings, err := h.notificationService.GetSettings(owner)
+	if err != nil {
+		writeNotificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": settings})
+}
+
+// UpdateNotificationSettings 修改通知渠道设置
+// PUT /api/v1/notifications/settings
+func (h *NotificationHandler) UpdateNotificationSettings(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	var req NotificationSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	settings, err := h.notificationService.UpdateSettings(owner, services.NotificationSettingsUpdate{
+		PushEnabled:  req.PushEnabled,
+		Email:        req.Email,
+		WebhookURL:   req.WebhookURL,
+		RotateSecret: req.RotateSecret,
+	})
+	if err != nil {
+		writeNotificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": "通知设置已更新", "data": settings})
+}
+
+// sessionOwner 获取当前会话对应的钱包地址，失败时写入401响应
+func (h *NotificationHandler) sessionOwner(c *gin.Context) (string, bool) {
+	userID, _ := c.Get("user_id")
+	sessionID, _ := userID.(string)
+	owner, err := h.walletService.GetSessionAddress(sessionID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": e.ErrorAuth, "msg": "会话无效或已过期", "data": err.Error()})
+		return "", false
+	}
+	return owner, true
+}
+
+// notificationID 解析路径中的通知ID，失败时写入400响应
+func notificationID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "通知ID格式不正确"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeNotificationError 通知不存在返回404，其余为参数或存储错误
+func writeNotificationError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrNotificationNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"code": e.ERROR, "msg": err.Error(), "data": nil})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"code": e.ERROR, "msg": err.Error(), "data": nil})
+}
@@ -0,0 +1,106 @@
+/*
+跨链资产汇总API处理器
+
+GET /api/v1/portfolio 一次返回多个地址在多个网络上的原生币与代币余额、美元估值及总计，
+替代逐地址、逐代币、逐网络分别调用余额接口。
+*/
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"wallet/pkg/e"
+	"wallet/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxPortfolioAddresses 单次汇总的最大地址数
+const maxPortfolioAddresses = 20
+
+// PortfolioHandler 跨链资产汇总处理器
+type PortfolioHandler struct {
+	portfolioService *services.PortfolioService
+	walletService    *services.WalletService // 解析会话用户的自定义代币列表
+}
+
+// NewPortfolioHandler 创建跨链资产汇总处理器
+func NewPortfolioHandler(portfolioService *services.PortfolioService, walletService *services.WalletService) *PortfolioHandler {
+	return &PortfolioHandler{
+		portfolioService: portfolioService,
+		walletService:    walletService,
+	}
+}
+
+// GetPortfolio 跨链资产汇总
+// GET /api/v1/portfolio
+// 查询参数:
+//   - addresses: 地址列表（逗号分隔，必填）
+//   - networks: 网络列表（逗号分隔，支持 eth/matic/bnb 简写，默认所有可用网络）
+//   - tokens: 代币合约列表（逗号分隔，可选）；为空时使用用户的自定义代币列表，
+//     该网络没有自定义代币时按 Transfer 日志自动识别
+func (h *PortfolioHandler) GetPortfolio(c *gin.Context) {
+	addresses := splitQueryList(c.Query("addresses"))
+	if len(addresses) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  "缺少地址参数",
+			"data": nil,
+		})
+		return
+	}
+	if len(addresses) > maxPortfolioAddresses {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  "地址数量过多",
+			"data": nil,
+		})
+		return
+	}
+
+	networks := splitQueryList(c.Query("networks"))
+	var portfolio *services.CrossChainPortfolio
+	var err error
+	if tokens := splitQueryList(c.Query("tokens")); len(tokens) > 0 {
+		portfolio, err = h.portfolioService.GetPortfolio(c.Request.Context(), addresses, networks, tokens)
+	} else {
+		portfolio, err = h.portfolioService.GetPortfolioForTokens(c.Request.Context(), addresses, networks, h.userTokens(c))
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "ok",
+		"data": portfolio,
+	})
+}
+
+// userTokens 当前会话用户在各网络的自定义代币，未登录时返回空映射
+func (h *PortfolioHandler) userTokens(c *gin.Context) map[string][]string {
+	userID, _ := c.Get("user_id")
+	sessionID, _ := userID.(string)
+	owner, err := h.walletService.GetSessionAddress(sessionID)
+	if err != nil {
+		return nil
+	}
+	return h.walletService.UserTokenContracts(owner)
+}
+
+// splitQueryList 解析逗号分隔的查询参数，去除空白项
+func splitQueryList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
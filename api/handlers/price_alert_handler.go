@@ -0,0 +1,116 @@
+/*
+代币价格提醒API处理器
+
+按代币美元价格提醒，后台按 price_alerts.interval_seconds 检查，触发后写入用户的通知收件箱并按通知渠道设置投递：
+- POST /api/v1/defi/price/alerts - 创建提醒
+- GET  /api/v1/defi/price/alerts - 提醒列表
+- GET  /api/v1/defi/price/alerts/history - 触发记录
+- POST /api/v1/defi/price/alerts/:id/pause、/resume - 暂停、恢复
+*/
+package handlers
+
+import (
+	"net/http"
+	"wallet/pkg/e"
+	"wallet/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PriceAlertHandler 代币价格提醒处理器
+type PriceAlertHandler struct {
+	priceService *services.PriceService
+}
+
+// NewPriceAlertHandler 创建代币价格提醒处理器
+func NewPriceAlertHandler(priceService *services.PriceService) *PriceAlertHandler {
+	return &PriceAlertHandler{priceService: priceService}
+}
+
+// TokenPriceAlertRequest 创建代币价格提醒请求
+type TokenPriceAlertRequest struct {
+	ChainID       int     `json:"chain_id" binding:"required"`
+	Token         string  `json:"token"`                         // 代币地址，空或 native 表示原生币
+	AlertType     string  `json:"alert_type" binding:"required"` // above / below / change
+	TargetUSD     float64 `json:"target_usd"`                    // above / below 必填
+	ChangePercent float64 `json:"change_percent"`                // change 必填，涨跌幅阈值（%）
+	Repeat        bool    `json:"repeat"`                        // 重复提醒，默认触发一次后停用
+}
+
+// CreateTokenPriceAlert 创建代币价格提醒
+// POST /api/v1/defi/price/alerts
+func (h *PriceAlertHandler) CreateTokenPriceAlert(c *gin.Context) {
+	userAddress, ok := priceAlertUser(c)
+	if !ok {
+		return
+	}
+	var req TokenPriceAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": "参数错误: " + err.Error(), "data": nil})
+		return
+	}
+	alert, err := h.priceService.CreateTokenPriceAlert(userAddress, req.ChainID, req.Token, req.AlertType, req.TargetUSD, req.ChangePercent, req.Repeat)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": "创建提醒失败: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"code": e.SUCCESS, "msg": "价格提醒创建成功", "data": alert})
+}
+
+// GetTokenPriceAlerts 代币价格提醒列表
+// GET /api/v1/defi/price/alerts
+func (h *PriceAlertHandler) GetTokenPriceAlerts(c *gin.Context) {
+	userAddress, ok := priceAlertUser(c)
+	if !ok {
+		return
+	}
+	alerts := h.priceService.GetTokenPriceAlerts(userAddress)
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": "获取成功", "data": gin.H{"alerts": alerts, "count": len(alerts)}})
+}
+
+// GetTokenPriceAlertHistory 代币价格提醒触发记录
+// GET /api/v1/defi/price/alerts/history
+func (h *PriceAlertHandler) GetTokenPriceAlertHistory(c *gin.Context) {
+	userAddress, ok := priceAlertUser(c)
+	if !ok {
+		return
+	}
+	history := h.priceService.GetTokenPriceAlertHistory(userAddress)
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": "获取成功", "data": gin.H{"triggers": history, "count": len(history)}})
+}
+
+// PauseTokenPriceAlert 暂停代币价格提醒
+// POST /api/v1/defi/price/alerts/:id/pause
+func (h *PriceAlertHandler) PauseTokenPriceAlert(c *gin.Context) {
+	h.setActive(c, false)
+}
+
+// ResumeTokenPriceAlert 恢复代币价格提醒
+// POST /api/v1/defi/price/alerts/:id/resume
+func (h *PriceAlertHandler) ResumeTokenPriceAlert(c *gin.Context) {
+	h.setActive(c, true)
+}
+
+// setActive 暂停或恢复代币价格提醒
+func (h *PriceAlertHandler) setActive(c *gin.Context, active bool) {
+	userAddress, ok := priceAlertUser(c)
+	if !ok {
+		return
+	}
+	alert, err := h.priceService.SetTokenPriceAlertActive(userAddress, c.Param("id"), active)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": e.ERROR, "msg": err.Error(), "data": nil})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": "ok", "data": alert})
+}
+
+// priceAlertUser 读取 X-User-Address，缺失时写入 400 响应
+func priceAlertUser(c *gin.Context) (string, bool) {
+	userAddress := c.GetHeader("X-User-Address")
+	if userAddress == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": "用户地址不能为空", "data": nil})
+		return "", false
+	}
+	return userAddress, true
+}
@@ -0,0 +1,177 @@
+/*
+实时推送API处理器
+
+GET /api/v1/ws 升级为 WebSocket 连接，客户端通过 JSON 消息管理订阅：
+- {"action":"subscribe","network":"ethereum","address":"0x..."}   订阅地址（network 为空时使用当前网络）
+- {"action":"unsubscribe","network":"ethereum","address":"0x..."} 取消订阅
+服务端推送：
+- {"type":"subscribed"|"unsubscribed", ...} 订阅操作确认
+- {"type":"error","msg":"..."}             订阅操作失败
+- {"type":"balance", ...} / {"type":"incoming_transfer", ...} 实时事件（见 services.RealtimeEvent）
+连接断开时自动取消全部订阅。
+*/
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"wallet/api/middleware"
+	"wallet/config"
+	"wallet/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteWait      = 10 * time.Second    // 单次写超时
+	wsPongWait       = 60 * time.Second    // 等待客户端 pong 的超时
+	wsPingPeriod     = wsPongWait * 9 / 10 // ping 间隔，需小于 wsPongWait
+	wsMaxMessageSize = 1024                // 客户端消息大小上限
+	wsSubscribeWait  = 15 * time.Second    // 订阅时查询初始余额的超时
+)
+
+// RealtimeHandler 实时推送API处理器
+type RealtimeHandler struct {
+	realtimeService *services.RealtimeService
+	upgrader        websocket.Upgrader
+}
+
+// NewRealtimeHandler 创建实时推送处理器
+func NewRealtimeHandler(realtimeService *services.RealtimeService) *RealtimeHandler {
+	// 跨域策略与 CORS 中间件保持一致：浏览器连接只接受配置中允许的来源，非浏览器客户端（无 Origin）不受限制
+	origins := middleware.NewCORSPolicy(config.AppConfig.CORS)
+	return &RealtimeHandler{
+		realtimeService: realtimeService,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				if origin == "" {
+					return true
+				}
+				allowed, _ := origins.Allowed(origin)
+				return allowed
+			},
+		},
+	}
+}
+
+// wsRequest 客户端订阅消息
+type wsRequest struct {
+	Action  string `json:"action"`  // subscribe / unsubscribe
+	Network string `json:"network"` // 网络ID，为空时使用当前网络
+	Address string `json:"address"` // 订阅地址
+}
+
+// wsReply 订阅操作确认或错误
+type wsReply struct {
+	Type    string `json:"type"`
+	Network string `json:"network,omitempty"`
+	Address string `json:"address,omitempty"`
+	Msg     string `json:"msg,omitempty"`
+}
+
+// Subscribe 建立 WebSocket 连接并处理订阅
+// GET /api/v1/ws
+func (h *RealtimeHandler) Subscribe(c *gin.Context) {
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade 已向客户端写入错误响应
+		return
+	}
+	sub := h.realtimeService.NewSubscription()
+	replies := make(chan wsReply, 8)
+	done := make(chan struct{})
+
+	go h.writeLoop(conn, sub, replies, done)
+	h.readLoop(conn, sub, replies)
+
+	// 客户端断开：取消订阅（关闭事件通道）并等待写协程退出
+	sub.Close()
+	<-done
+}
+
+// readLoop 读取客户端消息直到连接断开
+func (h *RealtimeHandler) readLoop(conn *websocket.Conn, sub *services.RealtimeSubscription, replies chan<- wsReply) {
+	conn.SetReadLimit(wsMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		var req wsRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				slog.DebugContext(context.Background(), "websocket closed unexpectedly", "error", err)
+			}
+			return
+		}
+
+		reply := wsReply{Network: req.Network, Address: req.Address}
+		switch req.Action {
+		case "subscribe":
+			ctx, cancel := context.WithTimeout(context.Background(), wsSubscribeWait)
+			err := sub.Watch(ctx, req.Network, req.Address)
+			cancel()
+			if err != nil {
+				reply.Type, reply.Msg = "error", err.Error()
+			} else {
+				reply.Type = "subscribed"
+			}
+		case "unsubscribe":
+			sub.Unwatch(req.Network, req.Address)
+			reply.Type = "unsubscribed"
+		default:
+			reply.Type, reply.Msg = "error", "不支持的操作: "+req.Action
+		}
+
+		select {
+		case replies <- reply:
+		default:
+			// 客户端不读取确认消息时丢弃，避免阻塞读循环
+		}
+	}
+}
+
+// writeLoop 串行写出确认消息、实时事件与心跳，事件通道关闭或写失败时退出
+func (h *RealtimeHandler) writeLoop(conn *websocket.Conn, sub *services.RealtimeSubscription, replies <-chan wsReply, done chan<- struct{}) {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+		close(done)
+	}()
+
+	events := sub.Events()
+	for {
+		var err error
+		select {
+		case reply := <-replies:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			err = conn.WriteJSON(reply)
+		case event, ok := <-events:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+			err = conn.WriteJSON(event)
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			err = conn.WriteMessage(websocket.PingMessage, nil)
+		}
+		if err != nil {
+			// 关闭连接使读循环退出，随后由 Subscribe 关闭订阅
+			conn.Close()
+			for range events {
+			}
+			return
+		}
+	}
+}
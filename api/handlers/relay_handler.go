@@ -0,0 +1,152 @@
+/*
+ERC20 转账代付Gas API处理器
+
+没有原生币支付Gas的钱包可通过可信转发合约（ERC-2771）转出代币，由服务端中继账户支付Gas：
+- GET  /api/v1/transactions/relay/tokens - 当前配置的代付网络、转发合约、代币与手续费
+- POST /api/v1/transactions/relay/build - 构造转发请求，返回待签署的 EIP-712 typed data（含手续费请求时需按顺序全部签署）
+- POST /api/v1/transactions/relay - 提交签名后的转发请求，由中继账户上链
+
+转发请求的 from 必须为当前会话的钱包地址；提交前与普通发送一样检查收款地址黑名单、交易风险与支出限额。
+*/
+package handlers
+
+import (
+	"errors"
+	"math/big"
+	"net/http"
+	"wallet/core"
+	"wallet/pkg/e"
+	"wallet/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RelayBuildRequest 构造代付转账请求
+type RelayBuildRequest struct {
+	Token       string `json:"token" binding:"required"`
+	To          string `json:"to" binding:"required"` // 0x地址、ENS域名或 contact:<联系人ID>
+	Amount      string `json:"amount"`                // token 最小单位，十进制字符串（与 amount_human 二选一）
+	AmountHuman string `json:"amount_human"`          // 可读单位金额（如 "1.5"），按代币 decimals 转换
+}
+
+// RelaySubmitRequest 提交签名后的转发请求
+type RelaySubmitRequest struct {
+	Requests []services.SignedForwardRequest `json:"requests" binding:"required"` // 按 build 返回的顺序：转账、手续费（如有）
+	MFACode  string                          `json:"mfa_code"`                    // 交易被判定为异常时需提交的双因素验证码
+	// 接收地址在黑名单中且处理方式为 warn 时，确认风险后置为 true 重新提交
+	ConfirmRecipient bool `json:"confirm_recipient"`
+}
+
+// ListRelayTokens 查询支持代付的代币
+// GET /api/v1/transactions/relay/tokens
+func (h *WalletHandler) ListRelayTokens(c *gin.Context) {
+	tokens := h.walletService.GetRelayService().SupportedTokens()
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"tokens": tokens, "total": len(tokens)}})
+}
+
+// BuildRelayRequest 构造代付转账的转发请求
+// POST /api/v1/transactions/relay/build
+func (h *WalletHandler) BuildRelayRequest(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	var req RelayBuildRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	if (req.Amount == "") == (req.AmountHuman == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "amount 与 amount_human 必须且只能提供一个"})
+		return
+	}
+	amount := new(big.Int)
+	if req.AmountHuman != "" {
+		_, _, decimals, err := h.walletService.GetTokenMetadata(c.Request.Context(), req.Token)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": e.ErrorContractCall, "msg": e.GetMsg(e.ErrorContractCall), "data": "获取代币精度失败: " + err.Error()})
+			return
+		}
+		if amount, err = core.ParseTokenAmount(req.AmountHuman, decimals); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+			return
+		}
+	} else if _, ok := amount.SetString(req.Amount, 10); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "amount 需要是十进制数字字符串"})
+		return
+	}
+	recipient, ok := h.resolveRecipient(c, "", req.To)
+	if !ok {
+		return
+	}
+
+	draft, err := h.walletService.GetRelayService().BuildForwardRequest(c.Request.Context(), owner, req.Token, recipient.Address, amount)
+	if err != nil {
+		writeRelayError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": withRecipient(gin.H{"draft": draft}, recipient)})
+}
+
+// RelayTransaction 提交签名后的转发请求，由中继账户支付Gas
+// POST /api/v1/transactions/relay
+func (h *WalletHandler) RelayTransaction(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	var req RelaySubmitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	transfer, err := services.DecodeRelayTransfer(req.Requests)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	blocked, ok := h.checkRecipient(c, owner, transfer.Recipient, req.ConfirmRecipient)
+	if !ok {
+		return
+	}
+	risk, ok := h.checkTxRisk(c, owner, transfer.Token, transfer.Amount, req.MFACode)
+	if !ok {
+		return
+	}
+	defer releaseTxRisk(h.walletService, risk)
+
+	result, err := h.walletService.GetRelayService().RelayTransaction(c.Request.Context(), owner, req.Requests)
+	if err != nil {
+		writeRelayError(c, err)
+		return
+	}
+	actx := h.txAuditContext(c, "", "", "")
+	actx.From = owner
+	h.walletService.RecordTxAudit("tx_relay_erc20", result.TxHash, actx, map[string]interface{}{
+		"token":       result.Token,
+		"to":          result.Recipient,
+		"amount":      result.Amount,
+		"fee":         result.Fee,
+		"fee_tx_hash": result.FeeTxHash,
+		"relayer":     result.Relayer,
+	})
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  e.GetMsg(e.SUCCESS),
+		"data": h.withTxRisk(withRecipientWarning(gin.H{"relay": result, "tx_hash": result.TxHash}, blocked), risk, result.TxHash),
+	})
+}
+
+// writeRelayError 未启用代付返回503，配额用完返回429，其余为请求或链上校验错误
+func writeRelayError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrRelayDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"code": e.ERROR, "msg": err.Error(), "data": nil})
+	case errors.Is(err, services.ErrRelayQuotaExceeded):
+		c.JSON(http.StatusTooManyRequests, gin.H{"code": e.ErrorRelayQuotaExceeded, "msg": e.GetMsg(e.ErrorRelayQuotaExceeded), "data": err.Error()})
+	case errors.Is(err, core.ErrForwardExecutionFailed):
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.ErrorTxSimulationFailed, "msg": e.GetMsg(e.ErrorTxSimulationFailed), "data": err.Error()})
+	default:
+		txSendError(c, http.StatusBadRequest, e.ErrorTransactionSend, err)
+	}
+}
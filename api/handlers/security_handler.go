@@ -15,6 +15,8 @@
 - /api/v1/security/multisig/* - 多重签名接口
 - /api/v1/security/mfa/* - 多因素认证接口
 - /api/v1/security/audit/* - 安全审计接口
+- /api/v1/security/audit-logs - 审计日志分页查询
+- /api/v1/security/ip-whitelist - 签名/发送操作的IP白名单管理
 - /api/v1/security/biometric/* - 生物识别接口
 
 安全特性：
@@ -27,10 +29,13 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"wallet/core"
 	"wallet/pkg/e"
 	"wallet/services"
 
@@ -41,14 +46,16 @@ import (
 // 处理所有安全相关的HTTP请求，包括硬件钱包、多重签名、MFA认证等功能
 type SecurityHandler struct {
 	securityService *services.SecurityService // 安全功能业务服务实例
+	walletService   *services.WalletService   // 钱包服务（多签执行前的风控与支出限额）
 }
 
 // NewSecurityHandler 创建新的安全功能处理器实例
-// 参数: securityService - 安全功能业务服务实例
+// 参数: securityService - 安全功能业务服务实例；walletService - 钱包服务
 // 返回: 配置好的安全功能处理器
-func NewSecurityHandler(securityService *services.SecurityService) *SecurityHandler {
+func NewSecurityHandler(securityService *services.SecurityService, walletService *services.WalletService) *SecurityHandler {
 	return &SecurityHandler{
 		securityService: securityService,
+		walletService:   walletService,
 	}
 }
 
@@ -173,14 +180,9 @@ func (h *SecurityHandler) CreateMultiSigWallet(c *gin.Context) {
 		return
 	}
 
-	// 从请求头获取用户地址
-	userAddress := c.GetHeader("X-User-Address")
-	if userAddress == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code": e.InvalidParams,
-			"msg":  "用户地址不能为空",
-			"data": nil,
-		})
+	// 创建者为当前会话地址
+	userAddress, ok := h.requestOwner(c)
+	if !ok {
 		return
 	}
 
@@ -227,14 +229,9 @@ func (h *SecurityHandler) CreateMultiSigTransaction(c *gin.Context) {
 		return
 	}
 
-	// 从请求头获取用户地址
-	userAddress := c.GetHeader("X-User-Address")
-	if userAddress == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code": e.InvalidParams,
-			"msg":  "用户地址不能为空",
-			"data": nil,
-		})
+	// 发起人为当前会话地址，须为钱包创建者或签名者
+	userAddress, ok := h.requestOwner(c)
+	if !ok {
 		return
 	}
 
@@ -304,6 +301,106 @@ func (h *SecurityHandler) SignMultiSigTransaction(c *gin.Context) {
 	})
 }
 
+// ExecuteMultiSigTransaction 执行多重签名交易
+// POST /api/v1/security/multisig/transaction/execute
+// 请求体: ExecuteMultiSigRequest结构体
+// 功能: 校验已收集的签名并调用Safe合约execTransaction上链
+func (h *SecurityHandler) ExecuteMultiSigTransaction(c *gin.Context) {
+	var req services.ExecuteMultiSigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  "请求参数格式错误: " + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	// 执行前按执行者评估风险并校验支出限额（与普通发送相同）
+	transfer, err := h.securityService.MultiSigPendingTransfer(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.ERROR,
+			"msg":  "执行多签交易失败: " + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	blocked, ok := checkRecipientOnSend(c, h.walletService, transfer.From, transfer.Recipient, req.ConfirmRecipient)
+	if !ok {
+		return
+	}
+	risk, ok := checkPendingTransfer(c, h.walletService, transfer, req.MFACode)
+	if !ok {
+		return
+	}
+	defer releaseTxRisk(h.walletService, risk)
+
+	txHash, err := h.securityService.ExecuteMultiSigTransaction(c.Request.Context(), &req)
+	if errors.Is(err, core.ErrRecipientBlocklisted) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code": e.ErrorRecipientBlocked,
+			"msg":  err.Error(),
+			"data": nil,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  "执行多签交易失败: " + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "多签交易已提交",
+		"data": recordTxRisk(h.walletService, withRecipientWarning(gin.H{
+			"wallet_id":      req.WalletID,
+			"transaction_id": req.TransactionID,
+			"tx_hash":        txHash,
+		}, blocked), risk, txHash),
+	})
+}
+
+// SplitMnemonic 助记词 Shamir 分片
+// POST /api/v1/security/recovery/shards
+// 请求体: SplitMnemonicRequest结构体
+// 功能: 将会话助记词拆分为分片返回给用户分发，服务端仅保存分片元数据
+func (h *SecurityHandler) SplitMnemonic(c *gin.Context) {
+	var req services.SplitMnemonicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  "请求参数格式错误: " + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	shards, err := h.securityService.SplitMnemonic(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.ERROR,
+			"msg":  "助记词分片失败: " + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "助记词分片成功，请将各分片分开保管",
+		"data": gin.H{
+			"threshold":    req.Threshold,
+			"total_shards": req.TotalShards,
+			"shards":       shards,
+		},
+	})
+}
+
 // SetupMFA 设置多因素认证
 // POST /api/v1/security/mfa/setup
 // 请求体: MFASetupRequest结构体
@@ -351,20 +448,18 @@ func (h *SecurityHandler) SetupMFA(c *gin.Context) {
 		}
 	}
 
-	// 从请求头获取用户地址
-	userAddress := c.GetHeader("X-User-Address")
-	if userAddress == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code": e.InvalidParams,
-			"msg":  "用户地址不能为空",
-			"data": nil,
-		})
+	// MFA 只能为会话所属地址设置
+	userAddress, ok := h.requestOwner(c)
+	if !ok {
 		return
 	}
 
 	// 设置MFA
 	response, err := h.securityService.SetupMFA(c.Request.Context(), userAddress, &req)
 	if err != nil {
+		if writeMFAError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": e.ERROR,
 			"msg":  "设置MFA失败: " + err.Error(),
@@ -399,20 +494,27 @@ func (h *SecurityHandler) VerifyMFA(c *gin.Context) {
 		return
 	}
 
-	// 从请求头获取用户地址
-	userAddress := c.GetHeader("X-User-Address")
-	if userAddress == "" {
+	// 只能校验会话所属地址的验证码
+	userAddress, ok := h.requestOwner(c)
+	if !ok {
+		return
+	}
+
+	isValid, err := h.securityService.VerifyMFACode(userAddress, req.Code)
+	if err != nil {
+		if writeMFAError(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code": e.InvalidParams,
-			"msg":  "用户地址不能为空",
-			"data": nil,
+			"msg":  "MFA验证失败: " + err.Error(),
+			"data": gin.H{
+				"valid": false,
+			},
 		})
 		return
 	}
 
-	// 简化实现：验证MFA代码（实际项目中需要实现真实的验证逻辑）
-	isValid := len(req.Code) == 6 // 简单的长度验证
-
 	if !isValid {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"code": e.ERROR,
@@ -512,6 +614,288 @@ func (h *SecurityHandler) GetSecurityAuditLogs(c *gin.Context) {
 	})
 }
 
+// QueryAuditLogs 分页查询安全审计日志
+// GET /api/v1/security/audit-logs
+// 查询参数:
+//   - user_address: 用户地址（仅管理员可查询他人，普通用户默认且只能查询自己）
+//   - action: 操作类型
+//   - result: 操作结果（success/failed）
+//   - start_time / end_time: 时间范围（Unix时间戳，含边界）
+//   - min_risk_score: 最低风险分数（0~1）
+//   - limit: 每页条数（默认50，最大1000）
+//   - offset: 偏移量（默认0）
+//
+// 响应: 当前页日志（时间倒序）与过滤后的总数
+func (h *SecurityHandler) QueryAuditLogs(c *gin.Context) {
+	requester := core.AuditContextFrom(c.Request.Context()).UserAddress
+	if requester == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code": e.ErrorAuth,
+			"msg":  "会话无效或已过期",
+			"data": nil,
+		})
+		return
+	}
+
+	filter := core.AuditLogFilter{
+		UserAddress: strings.TrimSpace(c.Query("user_address")),
+		Action:      c.Query("action"),
+		Result:      c.Query("result"),
+	}
+	badParam := func(msg string) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": msg})
+	}
+	for name, target := range map[string]**time.Time{"start_time": &filter.StartTime, "end_time": &filter.EndTime} {
+		if raw := c.Query(name); raw != "" {
+			ts, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				badParam(name + " 需要是Unix时间戳")
+				return
+			}
+			t := time.Unix(ts, 0)
+			*target = &t
+		}
+	}
+	if raw := c.Query("min_risk_score"); raw != "" {
+		score, err := strconv.ParseFloat(raw, 64)
+		if err != nil || score < 0 || score > 1 {
+			badParam("min_risk_score 需要是 0~1 之间的数字")
+			return
+		}
+		filter.MinRiskScore = score
+	}
+	var err error
+	if filter.Limit, err = strconv.Atoi(c.DefaultQuery("limit", "50")); err != nil {
+		badParam("limit 需要是整数")
+		return
+	}
+	if filter.Offset, err = strconv.Atoi(c.DefaultQuery("offset", "0")); err != nil {
+		badParam("offset 需要是整数")
+		return
+	}
+
+	logs, total, err := h.securityService.QueryAuditLogs(requester, filter)
+	if errors.Is(err, services.ErrAuditForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"code": e.ErrorAuth, "msg": err.Error(), "data": nil})
+		return
+	}
+	if err != nil {
+		badParam(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  e.GetMsg(e.SUCCESS),
+		"data": gin.H{
+			"logs":     logs,
+			"total":    total,
+			"limit":    filter.Limit,
+			"offset":   filter.Offset,
+			"has_more": filter.Offset+len(logs) < total,
+		},
+	})
+}
+
+// IPWhitelistRequest IP白名单规则请求
+type IPWhitelistRequest struct {
+	CIDR  string `json:"cidr" binding:"required"` // 单个IP或CIDR网段
+	Label string `json:"label"`                   // 备注（如 "办公室"）
+}
+
+// ListIPWhitelist 查询当前用户的IP白名单
+// GET /api/v1/security/ip-whitelist
+func (h *SecurityHandler) ListIPWhitelist(c *gin.Context) {
+	owner, ok := h.requestOwner(c)
+	if !ok {
+		return
+	}
+	entries, err := h.securityService.ListIPWhitelist(owner)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ERROR, "msg": e.GetMsg(e.ERROR), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{
+		"entries":   entries,
+		"client_ip": c.ClientIP(),
+		"enforced":  len(entries) > 0,
+	}})
+}
+
+// AddIPWhitelistEntry 添加IP白名单规则
+// POST /api/v1/security/ip-whitelist
+// 添加后的白名单必须包含当前请求IP
+func (h *SecurityHandler) AddIPWhitelistEntry(c *gin.Context) {
+	owner, ok := h.requestOwner(c)
+	if !ok {
+		return
+	}
+	var req IPWhitelistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	entry, err := h.securityService.AddIPWhitelistEntry(owner, req.CIDR, strings.TrimSpace(req.Label), c.ClientIP())
+	if err != nil {
+		writeIPWhitelistError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": entry})
+}
+
+// UpdateIPWhitelistEntry 修改IP白名单规则
+// PUT /api/v1/security/ip-whitelist/:id
+func (h *SecurityHandler) UpdateIPWhitelistEntry(c *gin.Context) {
+	owner, ok := h.requestOwner(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "规则ID格式不正确"})
+		return
+	}
+	var req IPWhitelistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	entry, err := h.securityService.UpdateIPWhitelistEntry(owner, uint(id), req.CIDR, strings.TrimSpace(req.Label), c.ClientIP())
+	if err != nil {
+		writeIPWhitelistError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": entry})
+}
+
+// RemoveIPWhitelistEntry 删除IP白名单规则，删除最后一条即取消IP限制
+// DELETE /api/v1/security/ip-whitelist/:id
+func (h *SecurityHandler) RemoveIPWhitelistEntry(c *gin.Context) {
+	owner, ok := h.requestOwner(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "规则ID格式不正确"})
+		return
+	}
+	if err := h.securityService.RemoveIPWhitelistEntry(owner, uint(id), c.ClientIP()); err != nil {
+		writeIPWhitelistError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": nil})
+}
+
+// ListDevices 查询当前用户登录过的设备
+// GET /api/v1/security/devices
+func (h *SecurityHandler) ListDevices(c *gin.Context) {
+	owner, ok := h.requestOwner(c)
+	if !ok {
+		return
+	}
+	devices, err := h.securityService.ListDevices(owner)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ERROR, "msg": e.GetMsg(e.ERROR), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{
+		"devices": devices,
+		"current": requestDeviceInfo(c).Fingerprint(),
+	}})
+}
+
+// TrustDevice 在已信任设备上确认待确认的新设备，确认后该设备可直接登录
+// POST /api/v1/security/devices/:id/trust
+func (h *SecurityHandler) TrustDevice(c *gin.Context) {
+	owner, ok := h.requestOwner(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "设备ID格式不正确"})
+		return
+	}
+	sessionID, _ := c.Get("user_id")
+	sid, _ := sessionID.(string)
+	device, err := h.securityService.TrustDevice(owner, sid, uint(id))
+	if err != nil {
+		writeDeviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": device})
+}
+
+// RevokeDevice 撤销设备，该设备上的会话立即失效，再次登录需重新确认
+// DELETE /api/v1/security/devices/:id
+func (h *SecurityHandler) RevokeDevice(c *gin.Context) {
+	owner, ok := h.requestOwner(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "设备ID格式不正确"})
+		return
+	}
+	cleared, err := h.securityService.RevokeDevice(owner, uint(id))
+	if err != nil {
+		writeDeviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"revoked_sessions": cleared}})
+}
+
+// writeMFAError 写入需要验证码、验证码错误与锁定错误的响应，其他错误返回 false
+func writeMFAError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, core.ErrMFALocked):
+		c.JSON(http.StatusTooManyRequests, gin.H{"code": e.ErrorRateLimit, "msg": err.Error(), "data": gin.H{"valid": false}})
+	case errors.Is(err, core.ErrMFACodeRequired):
+		c.JSON(http.StatusUnauthorized, gin.H{"code": e.ErrorMFARequired, "msg": err.Error(), "data": nil})
+	case errors.Is(err, core.ErrMFACodeInvalid):
+		c.JSON(http.StatusUnauthorized, gin.H{"code": e.ErrorMFARequired, "msg": err.Error(), "data": nil})
+	default:
+		return false
+	}
+	return true
+}
+
+// writeDeviceError 设备管理错误对应的响应
+func writeDeviceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrDeviceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"code": e.ERROR, "msg": err.Error(), "data": nil})
+	case errors.Is(err, services.ErrDeviceNotTrusted):
+		c.JSON(http.StatusForbidden, gin.H{"code": e.ErrorDeviceUnrecognized, "msg": err.Error(), "data": nil})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ERROR, "msg": e.GetMsg(e.ERROR), "data": err.Error()})
+	}
+}
+
+// requestOwner 取审计上下文中的会话所属地址，未登录时写入401响应
+func (h *SecurityHandler) requestOwner(c *gin.Context) (string, bool) {
+	owner := core.AuditContextFrom(c.Request.Context()).UserAddress
+	if owner == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": e.ErrorAuth, "msg": "会话无效或已过期", "data": nil})
+		return "", false
+	}
+	return owner, true
+}
+
+// writeIPWhitelistError IP白名单管理错误对应的响应
+func writeIPWhitelistError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrIPWhitelistEntryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"code": e.ERROR, "msg": err.Error(), "data": nil})
+	case errors.Is(err, services.ErrIPWhitelistLockout):
+		c.JSON(http.StatusConflict, gin.H{"code": e.InvalidParams, "msg": err.Error(), "data": gin.H{"client_ip": c.ClientIP()}})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+	}
+}
+
 // GetSecurityReport 获取安全报告
 // GET /api/v1/security/audit/report/:address
 // 路径参数:
@@ -779,3 +1163,48 @@ func (h *SecurityHandler) GetSecurityStatus(c *gin.Context) {
 		"data": securityStatus,
 	})
 }
+
+// requestDeviceInfo 从请求头中提取设备指纹所需的信息
+func requestDeviceInfo(c *gin.Context) core.DeviceInfo {
+	return core.DeviceInfo{
+		DeviceID:  c.GetHeader("X-Device-ID"),
+		UserAgent: c.GetHeader("User-Agent"),
+		Brands:    c.GetHeader("Sec-CH-UA"),
+		Platform:  c.GetHeader("Sec-CH-UA-Platform"),
+		Mobile:    c.GetHeader("Sec-CH-UA-Mobile"),
+		Model:     c.GetHeader("Sec-CH-UA-Model"),
+	}
+}
+
+// requestSecurityEvent 从请求中提取风险评估所需的设备与位置信息
+// 设备指纹见 requestDeviceInfo；国家与坐标取自 CDN 注入的访客位置请求头
+func requestSecurityEvent(c *gin.Context, eventType string) core.SecurityEvent {
+	event := core.SecurityEvent{
+		Type:              eventType,
+		Timestamp:         time.Now(),
+		DeviceFingerprint: requestDeviceInfo(c).Fingerprint(),
+		IPAddress:         c.ClientIP(),
+		Country:           shareCountry(c),
+	}
+	lat, errLat := strconv.ParseFloat(strings.TrimSpace(c.GetHeader("CF-IPLatitude")), 64)
+	lon, errLon := strconv.ParseFloat(strings.TrimSpace(c.GetHeader("CF-IPLongitude")), 64)
+	if errLat == nil && errLon == nil && lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180 {
+		event.Location = &core.GeoLocation{Latitude: lat, Longitude: lon}
+	}
+	return event
+}
+
+// writeRiskError 风险评估未通过时写入响应（deny 为403，challenge 为401），通过时返回 true
+func writeRiskError(c *gin.Context, decision *core.RiskDecision, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, services.ErrRiskDenied):
+		c.JSON(http.StatusForbidden, gin.H{"code": e.ErrorRiskDenied, "msg": e.GetMsg(e.ErrorRiskDenied), "data": gin.H{"risk": decision}})
+	case errors.Is(err, services.ErrRiskChallengeRequired):
+		c.JSON(http.StatusUnauthorized, gin.H{"code": e.ErrorRiskChallenge, "msg": e.GetMsg(e.ErrorRiskChallenge), "data": gin.H{"risk": decision, "mfa_required": true}})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+	}
+	return false
+}
@@ -15,6 +15,7 @@
 - /api/v1/social/network/* - 社交网络接口
 - /api/v1/social/user/* - 用户社交资料接口
 - /api/v1/social/search/* - 搜索功能接口
+- /api/v1/social/ens/* - ENS正向/反向解析、头像接口
 
 安全特性：
 - 联系人数据加密
@@ -25,10 +26,13 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"wallet/core"
 	"wallet/pkg/e"
 	"wallet/services"
 
@@ -393,7 +397,7 @@ func (h *SocialHandler) GetShareRecord(c *gin.Context) {
 	// 获取分享记录
 	shareRecord, err := h.socialService.GetShareRecord(c.Request.Context(), shareID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(shareErrorStatus(err), gin.H{
 			"code": e.ERROR,
 			"msg":  "获取分享记录失败: " + err.Error(),
 			"data": nil,
@@ -408,6 +412,90 @@ func (h *SocialHandler) GetShareRecord(c *gin.Context) {
 	})
 }
 
+// ViewShare 公开查看分享
+// GET /api/v1/share/:id
+// 请求头: Authorization（可选，RequireAuth 或设置了允许名单的分享需要登录）、CF-IPCountry / X-Country-Code（可选）
+// 功能: 校验过期与隐私设置，计入查看统计，对非创建者按设置隐藏金额与地址
+func (h *SocialHandler) ViewShare(c *gin.Context) {
+	shareID := c.Param("id")
+	if shareID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  "分享ID不能为空",
+			"data": nil,
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	sessionID, _ := userID.(string)
+	viewer := &core.ShareViewer{
+		ViewerID: c.ClientIP(),
+		Platform: sharePlatform(c.GetHeader("User-Agent")),
+		Country:  shareCountry(c),
+	}
+	shareRecord, err := h.socialService.ViewShare(c.Request.Context(), shareID, sessionID, viewer)
+	if err != nil {
+		c.JSON(shareErrorStatus(err), gin.H{
+			"code": e.ERROR,
+			"msg":  err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "ok",
+		"data": shareRecord,
+	})
+}
+
+// shareErrorStatus 分享访问错误对应的HTTP状态码
+func shareErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, core.ErrShareNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, core.ErrShareExpired):
+		return http.StatusGone
+	case errors.Is(err, core.ErrShareAuthRequired):
+		return http.StatusUnauthorized
+	case errors.Is(err, core.ErrShareForbidden):
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+// sharePlatform 根据 User-Agent 粗略识别访问平台
+func sharePlatform(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case ua == "":
+		return ""
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"), strings.Contains(ua, "ios"):
+		return "ios"
+	case strings.Contains(ua, "android"):
+		return "android"
+	case strings.Contains(ua, "windows"):
+		return "windows"
+	case strings.Contains(ua, "mac os"), strings.Contains(ua, "macintosh"):
+		return "macos"
+	case strings.Contains(ua, "linux"):
+		return "linux"
+	}
+	return "other"
+}
+
+// shareCountry 从CDN/反向代理注入的请求头读取访问者国家代码
+func shareCountry(c *gin.Context) string {
+	for _, header := range []string{"CF-IPCountry", "X-Country-Code"} {
+		if country := strings.TrimSpace(c.GetHeader(header)); len(country) == 2 {
+			return strings.ToUpper(country)
+		}
+	}
+	return ""
+}
+
 // SocialNetworkAction 社交网络操作
 // POST /api/v1/social/network/action
 // 请求体: SocialNetworkRequest结构体
@@ -740,3 +828,111 @@ func (h *SocialHandler) GetFollowing(c *gin.Context) {
 		},
 	})
 }
+
+// ResolveRecipient 解析收款目标
+// GET /api/v1/resolve-recipient?input=
+// 请求头: X-User-Address（可选，用于查找联系人）
+// 功能: 依次按地址、联系人ID、ENS域名解析为具体收款地址，并返回解析来源供前端确认
+func (h *SocialHandler) ResolveRecipient(c *gin.Context) {
+	input := c.Query("input")
+	if input == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  "input 不能为空",
+			"data": nil,
+		})
+		return
+	}
+
+	userAddress := c.GetHeader("X-User-Address")
+	response, err := h.socialService.ResolveRecipient(c.Request.Context(), userAddress, input)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": e.ERROR,
+			"msg":  "解析收款目标失败: " + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "ok",
+		"data": response,
+	})
+}
+
+// ResolveENS 正向解析ENS域名
+// GET /api/v1/social/ens/resolve/:name
+// 功能: 查询链上 ENS Registry，返回绑定地址及 avatar 等文本记录
+func (h *SocialHandler) ResolveENS(c *gin.Context) {
+	record, err := h.socialService.ResolveENS(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		c.JSON(ensErrorStatus(err), gin.H{
+			"code": e.ERROR,
+			"msg":  "解析ENS失败: " + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "ok",
+		"data": record,
+	})
+}
+
+// GetENSAvatar 获取ENS域名的头像图片
+// GET /api/v1/social/ens/avatar/:name
+// 功能: 按 ENSIP-12 解析 avatar 记录（含 ipfs:// 与 NFT 头像），经网关获取后直接返回图片内容
+func (h *SocialHandler) GetENSAvatar(c *gin.Context) {
+	content, contentType, err := h.socialService.FetchENSAvatar(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		c.JSON(ensErrorStatus(err), gin.H{
+			"code": e.ERROR,
+			"msg":  "获取ENS头像失败: " + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Header("X-Content-Type-Options", "nosniff")
+	// SVG 头像可能带脚本，禁止在本域下执行
+	c.Header("Content-Security-Policy", "default-src 'none'; img-src data:; style-src 'unsafe-inline'; sandbox")
+	c.Data(http.StatusOK, contentType, content)
+}
+
+// LookupENSName 反向解析地址的ENS主域名
+// GET /api/v1/social/ens/reverse/:address
+// 功能: 查询地址的反向记录，并校验该域名正向解析指回同一地址
+func (h *SocialHandler) LookupENSName(c *gin.Context) {
+	address := c.Param("address")
+	name, err := h.socialService.LookupENSName(c.Request.Context(), address)
+	if err != nil {
+		c.JSON(ensErrorStatus(err), gin.H{
+			"code": e.ERROR,
+			"msg":  "反向解析ENS失败: " + err.Error(),
+			"data": nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "ok",
+		"data": gin.H{
+			"address":  address,
+			"ens_name": name,
+		},
+	})
+}
+
+// ensErrorStatus 记录不存在返回 404，其余（节点不可用等）返回 502
+func ensErrorStatus(err error) int {
+	if errors.Is(err, core.ErrENSNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadGateway
+}
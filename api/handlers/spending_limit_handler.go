@@ -0,0 +1,87 @@
+/*
+钱包支出限额API处理器
+
+按钱包地址配置每日/每月的美元支出上限，仅会话所属钱包可管理自己的限额：
+- GET    /api/v1/wallets/:address/limits - 查询限额与本周期已支出金额
+- PUT    /api/v1/wallets/:address/limits - 设置限额（调高或取消某个周期需双因素验证码）
+- DELETE /api/v1/wallets/:address/limits?mfa_code= - 删除限额（需双因素验证码）
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"wallet/pkg/e"
+	"wallet/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SpendingLimitRequest 设置支出限额请求
+type SpendingLimitRequest struct {
+	DailyLimitUSD   float64 `json:"daily_limit_usd"`   // 每日限额（美元），0 表示不限制
+	MonthlyLimitUSD float64 `json:"monthly_limit_usd"` // 每月限额（美元），0 表示不限制
+	Timezone        string  `json:"timezone"`          // IANA 时区，决定每日/每月的重置时间，默认 UTC
+	MFACode         string  `json:"mfa_code"`          // 调高或取消限额时需提交的双因素验证码
+}
+
+// GetSpendingLimit 查询钱包支出限额
+// GET /api/v1/wallets/:address/limits
+func (h *WalletHandler) GetSpendingLimit(c *gin.Context) {
+	owner, ok := h.pathOwner(c, "支出限额")
+	if !ok {
+		return
+	}
+	status, err := h.walletService.GetSpendingLimit(owner)
+	if err != nil {
+		writeSpendingLimitManageError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": status})
+}
+
+// SetSpendingLimit 设置钱包支出限额
+// PUT /api/v1/wallets/:address/limits
+func (h *WalletHandler) SetSpendingLimit(c *gin.Context) {
+	owner, ok := h.pathOwner(c, "支出限额")
+	if !ok {
+		return
+	}
+	var req SpendingLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	status, err := h.walletService.SetSpendingLimit(owner, req.DailyLimitUSD, req.MonthlyLimitUSD, req.Timezone, req.MFACode)
+	if err != nil {
+		writeSpendingLimitManageError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": status})
+}
+
+// DeleteSpendingLimit 删除钱包支出限额
+// DELETE /api/v1/wallets/:address/limits
+func (h *WalletHandler) DeleteSpendingLimit(c *gin.Context) {
+	owner, ok := h.pathOwner(c, "支出限额")
+	if !ok {
+		return
+	}
+	if err := h.walletService.DeleteSpendingLimit(owner, c.Query("mfa_code")); err != nil {
+		writeSpendingLimitManageError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": nil})
+}
+
+// writeSpendingLimitManageError 限额管理错误对应的响应
+func writeSpendingLimitManageError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrSpendingLimitNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"code": e.ERROR, "msg": err.Error(), "data": nil})
+	case errors.Is(err, services.ErrSpendingLimitMFARequired):
+		c.JSON(http.StatusUnauthorized, gin.H{"code": e.ErrorMFARequired, "msg": err.Error(), "data": gin.H{"mfa_required": true}})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+	}
+}
@@ -0,0 +1,135 @@
+/*
+ERC20 授权查看与撤销API处理器
+
+- GET  /api/v1/approvals?owner=0x...&tokens=0x...,0x... - 列出当前网络上仍有额度的授权，无限授权排在最前并计入 infinite_count
+- POST /api/v1/approvals/revoke - 撤销一个或多个授权（approve(spender, 0)），nonce 连续分配
+
+撤销只会降低风险，不经过收款地址黑名单与交易风险检查。
+*/
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"wallet/core"
+	"wallet/pkg/e"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RevokeApprovalItem 待撤销的一个授权
+type RevokeApprovalItem struct {
+	Token   string `json:"token" binding:"required"`   // ERC20 合约地址
+	Spender string `json:"spender" binding:"required"` // 被授权地址
+}
+
+// RevokeApprovalsRequest 撤销授权请求
+type RevokeApprovalsRequest struct {
+	SessionID      string               `json:"session_id"`
+	Mnemonic       string               `json:"mnemonic"`        // 可选（与 session 二选一）
+	DerivationPath string               `json:"derivation_path"` // 默认为 m/44'/60'/0'/0/0
+	Approvals      []RevokeApprovalItem `json:"approvals" binding:"required"`
+
+	GasPrice             string `json:"gas_price"`
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas"`
+	MaxFeePerGas         string `json:"max_fee_per_gas"`
+	Nonce                string `json:"nonce"` // 起始 nonce，为空时取 pending nonce
+}
+
+// GetActiveApprovals 列出地址当前仍有额度的 ERC20 授权
+// GET /api/v1/approvals?owner=0x...&tokens=0x...,0x...
+// 注意: tokens 为空时扫描全部代币的 Approval 日志（回溯区块数见配置），指定代币时另外查询常见 DEX 路由等已知 spender
+func (h *WalletHandler) GetActiveApprovals(c *gin.Context) {
+	owner, err := validateAddressField(c, "owner", c.Query("owner"))
+	if err != nil {
+		badInput(c, err)
+		return
+	}
+	var tokens []string
+	for _, token := range strings.Split(c.Query("tokens"), ",") {
+		if token = strings.TrimSpace(token); token == "" {
+			continue
+		}
+		if token, err = validateAddressField(c, "tokens", token); err != nil {
+			badInput(c, err)
+			return
+		}
+		tokens = append(tokens, token)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+	defer cancel()
+	scan, err := h.walletService.GetActiveApprovals(ctx, owner, tokens)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorContractCall, "msg": e.GetMsg(e.ErrorContractCall), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": scan})
+}
+
+// RevokeApprovals 撤销一个或多个 ERC20 授权
+// POST /api/v1/approvals/revoke
+// 某一笔失败时之后的撤销不再发送，响应中逐笔返回 sent/failed/skipped，failed 与 skipped 的授权可重新提交
+func (h *WalletHandler) RevokeApprovals(c *gin.Context) {
+	var req RevokeApprovalsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	if len(req.Approvals) == 0 || len(req.Approvals) > core.MaxBatchRevocations {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": fmt.Sprintf("approvals 需包含 1-%d 个授权", core.MaxBatchRevocations)})
+		return
+	}
+	if req.SessionID == "" && req.Mnemonic == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "需要提供 session_id 或 mnemonic"})
+		return
+	}
+	opts, err := parseTxOptions(req.GasPrice, req.MaxPriorityFeePerGas, req.MaxFeePerGas, "", req.Nonce)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	revocations := make([]core.ApprovalRevocation, 0, len(req.Approvals))
+	for i, item := range req.Approvals {
+		token, err := validateAddressField(c, fmt.Sprintf("approvals[%d].token", i), item.Token)
+		if err != nil {
+			badInput(c, err)
+			return
+		}
+		spender, err := validateAddressField(c, fmt.Sprintf("approvals[%d].spender", i), item.Spender)
+		if err != nil {
+			badInput(c, err)
+			return
+		}
+		revocations = append(revocations, core.ApprovalRevocation{Token: token, Spender: spender})
+	}
+
+	results, err := h.walletService.RevokeApprovals(c.Request.Context(), req.SessionID, req.Mnemonic, req.DerivationPath, revocations, opts)
+	if err != nil {
+		txSendError(c, http.StatusInternalServerError, e.ErrorTransactionSend, err)
+		return
+	}
+
+	sent := 0
+	actx := h.txAuditContext(c, req.SessionID, req.Mnemonic, req.DerivationPath)
+	for _, result := range results {
+		if result.Status != core.BatchStatusSent {
+			continue
+		}
+		sent++
+		h.walletService.RecordTxAudit("tx_revoke_approval", result.TxHash, actx, map[string]interface{}{
+			"index":   result.Index,
+			"token":   result.Token,
+			"spender": result.Spender,
+		})
+	}
+	data := withInputWarnings(c, gin.H{"results": results, "total": len(results), "sent": sent})
+	if sent < len(results) {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorTransactionSend, "msg": "部分授权未撤销，可重新提交 failed 与 skipped 的授权", "data": data})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": data})
+}
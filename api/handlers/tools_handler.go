@@ -0,0 +1,181 @@
+/*
+开发者工具API处理器
+
+本文件提供与业务状态无关的开发者小工具接口：
+- /api/v1/tools/selector - 计算函数签名的4字节选择器
+- /api/v1/tools/event-topic - 计算事件签名的topic0
+- /api/v1/tools/merkle/root|proof|verify - 空投Merkle树的根、证明生成与校验
+*/
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"wallet/core"
+	"wallet/pkg/e"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gin-gonic/gin"
+)
+
+// ToolsHandler 开发者工具处理器
+type ToolsHandler struct{}
+
+// NewToolsHandler 创建开发者工具处理器
+func NewToolsHandler() *ToolsHandler {
+	return &ToolsHandler{}
+}
+
+// SignatureRequest 函数/事件签名请求
+type SignatureRequest struct {
+	Signature string `json:"signature" binding:"required"` // 如 transfer(address,uint256)
+}
+
+// FunctionSelector 计算函数选择器
+// POST /api/v1/tools/selector
+func (h *ToolsHandler) FunctionSelector(c *gin.Context) {
+	var req SignatureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	sig, selector, err := core.FunctionSelector(req.Signature)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"signature": sig, "selector": selector}})
+}
+
+// EventTopic 计算事件topic0
+// POST /api/v1/tools/event-topic
+func (h *ToolsHandler) EventTopic(c *gin.Context) {
+	var req SignatureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	sig, topic, err := core.EventTopic(req.Signature)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"signature": sig, "topic": topic}})
+}
+
+// MerkleTreeRequest Merkle树请求
+type MerkleTreeRequest struct {
+	Leaves     []string `json:"leaves" binding:"required"` // 0x开头的叶子（默认为32字节叶子哈希）
+	HashLeaves bool     `json:"hash_leaves"`               // 为 true 时先对每个叶子做 keccak256
+	Index      int      `json:"index"`                     // 生成证明的叶子索引（proof 接口使用）
+}
+
+// MerkleVerifyRequest Merkle证明校验请求
+type MerkleVerifyRequest struct {
+	Root     string   `json:"root" binding:"required"`
+	Leaf     string   `json:"leaf" binding:"required"`
+	Proof    []string `json:"proof"`
+	HashLeaf bool     `json:"hash_leaf"` // 为 true 时先对叶子做 keccak256
+}
+
+// MerkleRoot 计算Merkle根
+// POST /api/v1/tools/merkle/root
+func (h *ToolsHandler) MerkleRoot(c *gin.Context) {
+	var req MerkleTreeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	leaves, err := decodeMerkleLeaves(req.Leaves, req.HashLeaves)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	root, err := core.BuildMerkleTree(leaves)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"root": hexutil.Encode(root), "leaf_count": len(leaves)}})
+}
+
+// MerkleProof 生成指定叶子的Merkle证明
+// POST /api/v1/tools/merkle/proof
+func (h *ToolsHandler) MerkleProof(c *gin.Context) {
+	var req MerkleTreeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	leaves, err := decodeMerkleLeaves(req.Leaves, req.HashLeaves)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	root, err := core.BuildMerkleTree(leaves)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	proof, err := core.GetMerkleProof(leaves, req.Index)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	proofHex := make([]string, len(proof))
+	for i, p := range proof {
+		proofHex[i] = hexutil.Encode(p)
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{
+		"root":  hexutil.Encode(root),
+		"leaf":  hexutil.Encode(leaves[req.Index]),
+		"index": req.Index,
+		"proof": proofHex,
+	}})
+}
+
+// MerkleVerify 校验Merkle证明
+// POST /api/v1/tools/merkle/verify
+func (h *ToolsHandler) MerkleVerify(c *gin.Context) {
+	var req MerkleVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	root, err := hexutil.Decode(req.Root)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "root 格式错误: " + err.Error()})
+		return
+	}
+	leaves, err := decodeMerkleLeaves([]string{req.Leaf}, req.HashLeaf)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	proof := make([][]byte, len(req.Proof))
+	for i, p := range req.Proof {
+		if proof[i], err = hexutil.Decode(p); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": fmt.Sprintf("第 %d 个证明节点格式错误: %v", i, err)})
+			return
+		}
+	}
+	valid := core.VerifyMerkleProof(root, leaves[0], proof)
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"valid": valid}})
+}
+
+// decodeMerkleLeaves 解析十六进制叶子，hashLeaves 为 true 时对原始数据做 keccak256
+func decodeMerkleLeaves(hexLeaves []string, hashLeaves bool) ([][]byte, error) {
+	leaves := make([][]byte, len(hexLeaves))
+	for i, h := range hexLeaves {
+		raw, err := hexutil.Decode(h)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 个叶子格式错误: %w", i, err)
+		}
+		if hashLeaves {
+			raw = crypto.Keccak256(raw)
+		}
+		leaves[i] = raw
+	}
+	return leaves, nil
+}
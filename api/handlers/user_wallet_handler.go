@@ -31,6 +31,7 @@ package handlers
 
 import (
 	"fmt"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
@@ -702,6 +703,6 @@ func (h *UserWalletHandler) logUserActivity(userID uint, action string, resource
 	err := database.DB.Create(&activityLog).Error
 	if err != nil {
 		// 日志记录失败不应该影响正常流程，只记录错误
-		fmt.Printf("Failed to log user activity: %v\n", err)
+		slog.WarnContext(c.Request.Context(), "user activity log failed", "action", action, "error", err)
 	}
 }
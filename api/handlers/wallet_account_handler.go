@@ -0,0 +1,188 @@
+/*
+HD钱包多账户API处理器
+
+同一助记词下的多个账户（m/44'/60'/N'/0/0），账户列表按钱包持久化，仅会话所属钱包可管理：
+- GET  /api/v1/accounts                - 账户列表（账户0始终存在）
+- POST /api/v1/accounts                - 创建账户（默认序号为当前最大序号+1）
+- PUT  /api/v1/accounts/:index         - 修改账户标签
+- GET  /api/v1/accounts/:index/balance - 查询账户主地址的原生代币余额
+
+发送类接口（send、send-erc20、send-advanced、send-erc20-advanced、batch、合约方法、NFT 转账、
+networks/send-eth）可传 account 代替 derivation_path。
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"wallet/core"
+	"wallet/pkg/e"
+	"wallet/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAccountRequest 创建账户请求
+type CreateAccountRequest struct {
+	Label string `json:"label"` // 可选，默认为 Account N
+	Index *int   `json:"index"` // 可选，指定账户序号
+}
+
+// RenameAccountRequest 修改账户标签请求
+type RenameAccountRequest struct {
+	Label string `json:"label" binding:"required"`
+}
+
+// ListAccounts 列出会话钱包的账户
+// GET /api/v1/accounts
+func (h *WalletHandler) ListAccounts(c *gin.Context) {
+	sessionID, ok := h.accountSession(c)
+	if !ok {
+		return
+	}
+	accounts, err := h.walletService.ListAccounts(sessionID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.ERROR, "msg": err.Error(), "data": nil})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"accounts": accounts, "count": len(accounts)}})
+}
+
+// CreateAccount 为会话钱包创建账户并派生地址
+// POST /api/v1/accounts
+func (h *WalletHandler) CreateAccount(c *gin.Context) {
+	sessionID, ok := h.accountSession(c)
+	if !ok {
+		return
+	}
+	var req CreateAccountRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+			return
+		}
+	}
+	account, err := h.walletService.CreateAccount(sessionID, req.Label, req.Index)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrAccountExists) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"code": e.ERROR, "msg": err.Error(), "data": nil})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": account})
+}
+
+// RenameAccount 修改账户标签
+// PUT /api/v1/accounts/:index
+func (h *WalletHandler) RenameAccount(c *gin.Context) {
+	sessionID, ok := h.accountSession(c)
+	if !ok {
+		return
+	}
+	index, ok := accountIndexParam(c)
+	if !ok {
+		return
+	}
+	var req RenameAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	account, err := h.walletService.RenameAccount(sessionID, index, req.Label)
+	if err != nil {
+		c.JSON(accountErrorStatus(err), gin.H{"code": e.ERROR, "msg": err.Error(), "data": nil})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": account})
+}
+
+// GetAccountBalance 查询账户主地址在当前网络的原生代币余额
+// GET /api/v1/accounts/:index/balance
+func (h *WalletHandler) GetAccountBalance(c *gin.Context) {
+	sessionID, ok := h.accountSession(c)
+	if !ok {
+		return
+	}
+	index, ok := accountIndexParam(c)
+	if !ok {
+		return
+	}
+	account, err := h.walletService.GetAccount(sessionID, index)
+	if err != nil {
+		c.JSON(accountErrorStatus(err), gin.H{"code": e.ERROR, "msg": err.Error(), "data": nil})
+		return
+	}
+	bal, err := h.walletService.GetBalance(c.Request.Context(), account.Address)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorGetBalance, "msg": e.GetMsg(e.ErrorGetBalance), "data": err.Error()})
+		return
+	}
+	native := h.walletService.GetNativeCurrency()
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  e.GetMsg(e.SUCCESS),
+		"data": gin.H{
+			"account":     account,
+			"address":     account.Address,
+			"balance_wei": bal.String(),
+			"balance":     core.FormatUnits(bal, native.Decimals),
+			"symbol":      native.Symbol,
+			"decimals":    native.Decimals,
+		},
+	})
+}
+
+// resolveAccountPath 发送请求指定了 account 时将其换算为派生路径写入 derivationPath，见 resolveSendAccount
+func (h *WalletHandler) resolveAccountPath(c *gin.Context, sessionID, mnemonic string, account *int, derivationPath *string) bool {
+	return resolveSendAccount(c, h.walletService, sessionID, mnemonic, account, derivationPath)
+}
+
+// resolveSendAccount 发送请求指定了 account 时将其换算为派生路径写入 derivationPath，失败时写入响应
+func resolveSendAccount(c *gin.Context, walletService *services.WalletService, sessionID, mnemonic string, account *int, derivationPath *string) bool {
+	if account == nil {
+		return true
+	}
+	if *derivationPath != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "account 与 derivation_path 不能同时指定"})
+		return false
+	}
+	path, err := walletService.AccountDerivationPath(sessionID, mnemonic, *account)
+	if err != nil {
+		c.JSON(accountErrorStatus(err), gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return false
+	}
+	*derivationPath = path
+	return true
+}
+
+// accountSession 返回当前会话ID，会话无效时写入401响应
+func (h *WalletHandler) accountSession(c *gin.Context) (string, bool) {
+	userID, _ := c.Get("user_id")
+	sessionID, _ := userID.(string)
+	if _, err := h.walletService.GetSession(sessionID); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": e.ErrorAuth, "msg": "会话无效或已过期", "data": err.Error()})
+		return "", false
+	}
+	return sessionID, true
+}
+
+// accountIndexParam 解析路径中的账户序号，失败时写入400响应
+func accountIndexParam(c *gin.Context) (int, bool) {
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil || index < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "账户序号必须为非负整数"})
+		return 0, false
+	}
+	return index, true
+}
+
+// accountErrorStatus 账户不存在为404，其余为400
+func accountErrorStatus(err error) int {
+	if errors.Is(err, services.ErrAccountNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
@@ -38,6 +38,7 @@
 package handlers
 
 import (
+	"log/slog"
 	"math/big"
 	"net/http"
 	"time"
@@ -46,12 +47,16 @@ import (
 	"wallet/services"
 
 	// 需要导入 strings 包
+	"context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/gin-gonic/gin"
 )
 
@@ -99,6 +104,14 @@ func (h *WalletHandler) ImportMnemonic(c *gin.Context) {
 	// 调用业务服务层导入助记词并生成地址
 	addr, err := h.walletService.ImportMnemonic(req.Mnemonic, req.DerivationPath)
 	if err != nil {
+		if code, ok := mnemonicErrorCode(err); ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": code,
+				"msg":  e.GetMsg(code),
+				"data": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": e.ErrorWalletImport,
 			"msg":  e.GetMsg(e.ErrorWalletImport),
@@ -115,6 +128,185 @@ func (h *WalletHandler) ImportMnemonic(c *gin.Context) {
 	})
 }
 
+// mnemonicErrorCode 助记词校验错误对应的业务错误码，非校验错误时返回 false
+func mnemonicErrorCode(err error) (int, bool) {
+	switch {
+	case errors.Is(err, core.ErrMnemonicWordCount):
+		return e.ErrorMnemonicWordCount, true
+	case errors.Is(err, core.ErrMnemonicInvalidWord):
+		return e.ErrorMnemonicInvalidWord, true
+	case errors.Is(err, core.ErrMnemonicChecksum):
+		return e.ErrorMnemonicChecksum, true
+	default:
+		return 0, false
+	}
+}
+
+// ValidateMnemonicRequest 校验助记词的请求参数
+type ValidateMnemonicRequest struct {
+	Mnemonic string `json:"mnemonic" binding:"required"` // 待校验的助记词（英文或简体中文）
+}
+
+// ValidateMnemonic 校验助记词
+// POST /api/v1/wallets/validate-mnemonic
+// 功能: 返回单词数量、识别出的词表语言、不在词表中的单词位置以及校验和是否正确，供导入界面逐词提示
+// 注意: 助记词无效时同样返回200，valid 为 false
+func (h *WalletHandler) ValidateMnemonic(c *gin.Context) {
+	var req ValidateMnemonicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  e.GetMsg(e.InvalidParams),
+			"data": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  e.GetMsg(e.SUCCESS),
+		"data": h.walletService.ValidateMnemonic(req.Mnemonic),
+	})
+}
+
+// ValidateAddress 校验地址格式与EIP-55校验和
+// GET /api/v1/address/validate?address=0x...
+// 功能: 返回校验和格式、输入校验和是否正确，以及当前网络上是否为合约地址
+// 注意: 地址格式不合法时同样返回200，valid 为 false
+func (h *WalletHandler) ValidateAddress(c *gin.Context) {
+	address := c.Query("address")
+	if address == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.InvalidParams,
+			"msg":  e.GetMsg(e.InvalidParams),
+			"data": "address 不能为空",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  e.GetMsg(e.SUCCESS),
+		"data": h.walletService.ValidateAddress(ctx, address),
+	})
+}
+
+// GetAddressSummary 获取地址活跃度摘要
+// GET /api/v1/address/:address/summary
+// 功能: 返回当前网络上地址的首次出现与最近活跃区块/时间、交易数（nonce）、是否为合约、原生币余额与持有代币数
+// 注意: 结果短时缓存；首次出现依赖归档节点，无法获取时 warnings 中说明原因
+func (h *WalletHandler) GetAddressSummary(c *gin.Context) {
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "地址格式不正确"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+	summary, err := h.walletService.GetAddressSummaryService().GetAddressSummary(ctx, address)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ERROR, "msg": e.GetMsg(e.ERROR), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": summary})
+}
+
+// GetAddressQRCode 生成收款地址的 EIP-681 支付二维码
+// GET /api/v1/address/:address/qr?amount=1.5&token=0x...&size=256&level=medium&format=png|json
+// 功能: 默认直接返回 PNG 图片；format=json 时返回支付链接与 data URL
+// 注意: amount 为可读单位金额，token 为空表示当前网络原生币
+func (h *WalletHandler) GetAddressQRCode(c *gin.Context) {
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "钱包地址格式不正确"})
+		return
+	}
+	token := strings.TrimSpace(c.Query("token"))
+	if token != "" && !common.IsHexAddress(token) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "代币地址格式不正确"})
+		return
+	}
+	size := 0
+	if raw := c.Query("size"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "size 需要是整数"})
+			return
+		}
+		size = n
+	}
+
+	qr, err := h.walletService.ReceiveQRCode(c.Request.Context(), services.ReceiveQRRequest{
+		Address:       address,
+		Amount:        c.Query("amount"),
+		Token:         token,
+		Size:          size,
+		RecoveryLevel: c.Query("level"),
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+
+	if c.Query("format") == "json" {
+		c.JSON(http.StatusOK, gin.H{
+			"code": e.SUCCESS,
+			"msg":  e.GetMsg(e.SUCCESS),
+			"data": gin.H{
+				"uri":      qr.URI,
+				"chain_id": qr.ChainID,
+				"amount":   qr.Amount,
+				"qr_code":  core.QRCodeDataURL(qr.PNG),
+			},
+		})
+		return
+	}
+	c.Header("X-Payment-URI", qr.URI)
+	c.Data(http.StatusOK, "image/png", qr.PNG)
+}
+
+// PaymentParseRequest 解析支付链接的请求参数
+type PaymentParseRequest struct {
+	URI string `json:"uri" binding:"required"` // EIP-681 支付链接（扫码结果）
+}
+
+// ParsePaymentURI 解析 EIP-681 支付链接，用于预填发送表单
+// POST /api/v1/payment/parse
+// 功能: 返回收款地址、金额（最小单位）、代币与链ID；链ID与当前网络不一致时返回400
+func (h *WalletHandler) ParsePaymentURI(c *gin.Context) {
+	var req PaymentParseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	payment, network, err := h.walletService.ParsePaymentRequest(req.URI)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+
+	data := gin.H{
+		"recipient": payment.Recipient,
+		"chain_id":  payment.ChainID,
+		"network":   network,
+		"token":     payment.Token,
+		"amount":    "",
+	}
+	if payment.Amount != nil {
+		data["amount"] = payment.Amount.String()
+	}
+	if payment.GasLimit > 0 {
+		data["gas_limit"] = strconv.FormatUint(payment.GasLimit, 10)
+	}
+	if payment.GasPrice != nil {
+		data["gas_price"] = payment.GasPrice.String()
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": data})
+}
+
 // GetBalance 查询指定地址的原生代币余额
 // GET /api/v1/wallets/:address/balance
 // 功能: 获取以太坊地址的ETH余额（或其他网络的原生代币）
@@ -133,7 +325,7 @@ func (h *WalletHandler) GetBalance(c *gin.Context) {
 	}
 
 	// 调用业务服务层获取余额
-	bal, err := h.walletService.GetBalance(address)
+	bal, err := h.walletService.GetBalance(c.Request.Context(), address)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": e.ErrorGetBalance,
@@ -143,11 +335,18 @@ func (h *WalletHandler) GetBalance(c *gin.Context) {
 		return
 	}
 
-	// 返回成功响应，余额以wei为单位
+	// 返回成功响应，余额以最小单位返回，同时按当前网络原生代币的小数位格式化
+	native := h.walletService.GetNativeCurrency()
 	c.JSON(http.StatusOK, gin.H{
 		"code": e.SUCCESS,
 		"msg":  e.GetMsg(e.SUCCESS),
-		"data": gin.H{"address": address, "balance_wei": bal.String()},
+		"data": gin.H{
+			"address":     address,
+			"balance_wei": bal.String(),
+			"balance":     core.FormatUnits(bal, native.Decimals),
+			"symbol":      native.Symbol,
+			"decimals":    native.Decimals,
+		},
 	})
 }
 
@@ -157,8 +356,12 @@ type SendTransactionRequest struct {
 	SessionID      string `json:"session_id"`                   // 会话 ID（与 mnemonic 二选一）
 	Mnemonic       string `json:"mnemonic"`                     // BIP39助记词（与 session_id 二选一）
 	DerivationPath string `json:"derivation_path"`              // BIP44派生路径（默认: m/44'/60'/0'/0/0）
-	To             string `json:"to" binding:"required"`        // 接收方地址（必填）
+	Account        *int   `json:"account"`                      // 账户序号（可选，代替 derivation_path，见 /api/v1/accounts）
+	To             string `json:"to" binding:"required"`        // 接收方（必填）：0x地址、ENS域名或 contact:<联系人ID>
 	ValueWei       string `json:"value_wei" binding:"required"` // 转账金额（wei单位的十进制字符串）
+	MFACode        string `json:"mfa_code"`                     // 交易被判定为异常（新设备、异地、大额等）时需提交的双因素验证码
+	// 接收地址在黑名单中且处理方式为 warn 时，确认风险后置为 true 重新提交
+	ConfirmRecipient bool `json:"confirm_recipient"`
 }
 
 // SendTransaction 发送 ETH 交易
@@ -173,45 +376,144 @@ func (h *WalletHandler) SendTransaction(c *gin.Context) {
 		return
 	}
 
-	val := new(big.Int)
-	if _, ok := val.SetString(req.ValueWei, 10); !ok {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code": e.InvalidParams,
-			"msg":  e.GetMsg(e.InvalidParams),
-			"data": "value_wei 需要是十进制数字字符串",
-		})
+	if !h.resolveAccountPath(c, req.SessionID, req.Mnemonic, req.Account, &req.DerivationPath) {
+		return
+	}
+	val, err := parseAmountField("value_wei", req.ValueWei, false)
+	if err != nil {
+		badInput(c, err)
+		return
+	}
+	if req.To, err = validateRecipientField(c, "to", req.To); err != nil {
+		badInput(c, err)
+		return
+	}
+	recipient, ok := h.resolveRecipient(c, req.SessionID, req.To)
+	if !ok {
+		return
+	}
+	req.To = recipient.Address
+	from := h.senderAddress(req.SessionID, req.Mnemonic, req.DerivationPath)
+	blocked, ok := h.checkRecipient(c, from, req.To, req.ConfirmRecipient)
+	if !ok {
+		return
+	}
+	risk, ok := h.checkTxRisk(c, from, "", val, req.MFACode)
+	if !ok {
 		return
 	}
+	defer releaseTxRisk(h.walletService, risk)
 
-	var (
-		txHash string
-		err    error
-	)
+	// 发送前预检：余额预留提醒（不阻止发送）
+	warning := h.walletService.CheckBalanceReserve(c.Request.Context(), from, val, nil)
+
+	var txHash string
 	if req.SessionID != "" {
-		txHash, err = h.walletService.SendETHWithSession(req.SessionID, req.DerivationPath, req.To, val)
+		txHash, err = h.walletService.SendETHWithSession(c.Request.Context(), req.SessionID, req.DerivationPath, req.To, val)
 	} else if req.Mnemonic != "" {
-		txHash, err = h.walletService.SendETH(req.Mnemonic, req.DerivationPath, req.To, val)
+		txHash, err = h.walletService.SendETH(c.Request.Context(), req.Mnemonic, req.DerivationPath, req.To, val)
 	} else {
 		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "需要提供 session_id 或 mnemonic"})
 		return
 	}
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ErrorTransactionSend,
-			"msg":  e.GetMsg(e.ErrorTransactionSend),
-			"data": err.Error(),
-		})
+		txSendError(c, http.StatusInternalServerError, e.ErrorTransactionSend, err)
 		return
 	}
 
+	h.walletService.RecordTxAudit("tx_send_eth", txHash, h.txAuditContext(c, req.SessionID, req.Mnemonic, req.DerivationPath), map[string]interface{}{
+		"to":        req.To,
+		"value_wei": req.ValueWei,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"code": e.SUCCESS,
 		"msg":  e.GetMsg(e.SUCCESS),
-		"data": gin.H{"tx_hash": txHash},
+		"data": withInputWarnings(c, withReserveWarning(h.withTxRisk(withRecipientWarning(withRecipient(gin.H{"tx_hash": txHash}, recipient), blocked), risk, txHash), warning)),
 	})
 }
 
+// maxBatchBalanceTokens 单次批量余额查询的代币数量上限
+const maxBatchBalanceTokens = 500
+
+// GetERC20BalancesBatch 批量查询地址在多个ERC20代币上的余额
+// 查询参数: tokens - 逗号分隔的代币合约地址；未传时使用已登录用户在当前网络的自定义代币列表
+func (h *WalletHandler) GetERC20BalancesBatch(c *gin.Context) {
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "钱包地址格式不正确"})
+		return
+	}
+	var tokens []string
+	for _, t := range strings.Split(c.Query("tokens"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+	if len(tokens) == 0 {
+		if owner := h.optionalSessionOwner(c); owner != "" {
+			network := h.walletService.GetMultiChainManager().GetCurrentNetwork()
+			tokens = h.walletService.UserTokenContracts(owner)[network]
+		}
+	}
+	if len(tokens) == 0 || len(tokens) > maxBatchBalanceTokens {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": fmt.Sprintf("tokens 需包含 1~%d 个代币地址", maxBatchBalanceTokens)})
+		return
+	}
+	for _, t := range tokens {
+		if !common.IsHexAddress(t) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "代币地址格式不正确: " + t})
+			return
+		}
+	}
+
+	balances, err := h.walletService.GetERC20BalancesBatch(c.Request.Context(), address, tokens)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorGetBalance, "msg": e.GetMsg(e.ErrorGetBalance), "data": err.Error()})
+		return
+	}
+	out := make(map[string]string, len(balances))
+	for token, bal := range balances {
+		out[token] = bal.String()
+	}
+	// 调用失败的代币（非合约地址或不兼容ERC20）不出现在 balances 中
+	var failed []string
+	for _, t := range tokens {
+		if _, ok := balances[common.HexToAddress(t).Hex()]; !ok {
+			failed = append(failed, t)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{
+		"address": address, "balances": out, "failed": failed,
+	}})
+}
+
+// GetTokenBalanceWithMetadata 一次返回代币余额、名称、符号、小数位、格式化余额与美元价值
+// GET /api/v1/wallets/:address/tokens/:tokenAddress
+// 注意: 余额实时查询，元数据按网络与代币永久缓存；价格服务不可用或无报价时 no_price 为 true
+func (h *WalletHandler) GetTokenBalanceWithMetadata(c *gin.Context) {
+	address := c.Param("address")
+	tokenAddress := c.Param("tokenAddress")
+	if !common.IsHexAddress(address) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "钱包地址格式不正确"})
+		return
+	}
+	if !common.IsHexAddress(tokenAddress) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "代币地址格式不正确"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+	defer cancel()
+	result, err := h.walletService.GetTokenBalanceWithMetadata(ctx, address, tokenAddress)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorGetBalance, "msg": e.GetMsg(e.ErrorGetBalance), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": result})
+}
+
 // GetERC20Balance 查询指定地址的ERC20代币余额
 // GET /api/v1/wallets/:address/tokens/:tokenAddress/balance
 // 功能: 获取指定地址的ERC20代币余额
@@ -263,10 +565,33 @@ func (h *WalletHandler) GetERC20Balance(c *gin.Context) {
 		return
 	}
 
-	// 调用业务服务层获取ERC20余额
-	bal, err := h.walletService.GetERC20Balance(address, tokenAddress)
+	// 可选的 block 参数：查询指定区块高度时的历史余额
+	var (
+		bal   *big.Int
+		err   error
+		block string
+	)
+	if block = c.Query("block"); block != "" {
+		blockNumber, parseErr := strconv.ParseUint(block, 10, 64)
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": e.InvalidParams,
+				"msg":  e.GetMsg(e.InvalidParams),
+				"data": "block 需要是十进制区块号",
+			})
+			return
+		}
+		bal, err = h.walletService.GetERC20BalanceAtBlock(c.Request.Context(), address, tokenAddress, blockNumber)
+	} else {
+		// 调用业务服务层获取ERC20余额
+		bal, err = h.walletService.GetERC20Balance(c.Request.Context(), address, tokenAddress)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		status := http.StatusInternalServerError
+		if errors.Is(err, core.ErrStateUnavailable) {
+			status = http.StatusUnprocessableEntity
+		}
+		c.JSON(status, gin.H{
 			"code": e.ErrorGetBalance,
 			"msg":  e.GetMsg(e.ErrorGetBalance),
 			"data": err.Error(),
@@ -275,7 +600,7 @@ func (h *WalletHandler) GetERC20Balance(c *gin.Context) {
 	}
 
 	// 获取代币元数据
-	name, symbol, decimals, err := h.walletService.GetTokenMetadata(tokenAddress)
+	name, symbol, decimals, err := h.walletService.GetTokenMetadata(c.Request.Context(), tokenAddress)
 	if err != nil {
 		// 如果获取元数据失败，使用默认值
 		name = "Unknown Token"
@@ -291,6 +616,7 @@ func (h *WalletHandler) GetERC20Balance(c *gin.Context) {
 			"address":       address,
 			"token_address": tokenAddress,
 			"balance":       bal.String(),
+			"block":         block,
 			"name":          name,
 			"symbol":        symbol,
 			"decimals":      decimals,
@@ -303,9 +629,40 @@ type SendERC20Request struct {
 	SessionID      string `json:"session_id"`      // 新增
 	Mnemonic       string `json:"mnemonic"`        // 可选（与 session 二选一）
 	DerivationPath string `json:"derivation_path"` // 默认为 m/44'/60'/0'/0/0
+	Account        *int   `json:"account"`         // 账户序号（可选，代替 derivation_path）
 	Token          string `json:"token" binding:"required"`
-	To             string `json:"to" binding:"required"`
-	Amount         string `json:"amount" binding:"required"` // token 最小单位，十进制字符串
+	To             string `json:"to" binding:"required"` // 0x地址、ENS域名或 contact:<联系人ID>
+	Amount         string `json:"amount"`                // token 最小单位，十进制字符串（与 amount_human 二选一）
+	AmountHuman    string `json:"amount_human"`          // 可读单位金额（如 "1.5"），按代币 decimals 转换
+	MFACode        string `json:"mfa_code"`              // 交易被判定为异常时需提交的双因素验证码
+	// 接收地址在黑名单中且处理方式为 warn 时，确认风险后置为 true 重新提交
+	ConfirmRecipient bool `json:"confirm_recipient"`
+}
+
+// BatchTransferItem 批量发送中的一笔转账
+type BatchTransferItem struct {
+	To          string `json:"to" binding:"required"` // 0x地址、ENS域名或 contact:<联系人ID>
+	Token       string `json:"token"`                 // ERC20 合约地址，为空表示原生币
+	Amount      string `json:"amount"`                // 最小单位，十进制字符串（与 amount_human 二选一）
+	AmountHuman string `json:"amount_human"`          // 可读单位金额（如 "1.5"），按原生币或代币 decimals 转换
+}
+
+// SendBatchRequest 批量发送请求
+type SendBatchRequest struct {
+	SessionID      string              `json:"session_id"`
+	Mnemonic       string              `json:"mnemonic"`        // 可选（与 session 二选一）
+	DerivationPath string              `json:"derivation_path"` // 默认为 m/44'/60'/0'/0/0
+	Account        *int                `json:"account"`         // 账户序号（可选，代替 derivation_path）
+	Transfers      []BatchTransferItem `json:"transfers" binding:"required"`
+
+	GasPrice             string `json:"gas_price"`
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas"`
+	MaxFeePerGas         string `json:"max_fee_per_gas"`
+	Nonce                string `json:"nonce"` // 起始 nonce，为空时取 pending nonce
+
+	MFACode string `json:"mfa_code"` // 交易被判定为异常时需提交的双因素验证码
+	// 接收地址在黑名单中且处理方式为 warn 时，确认风险后置为 true 重新提交
+	ConfirmRecipient bool `json:"confirm_recipient"`
 }
 
 // SendERC20 发送 ERC20 转账
@@ -319,44 +676,224 @@ func (h *WalletHandler) SendERC20(c *gin.Context) {
 		})
 		return
 	}
-	amount := new(big.Int)
-	if _, ok := amount.SetString(req.Amount, 10); !ok {
+	if (req.Amount == "") == (req.AmountHuman == "") {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code": e.InvalidParams,
 			"msg":  e.GetMsg(e.InvalidParams),
-			"data": "amount 需要是十进制数字字符串",
+			"data": "amount 与 amount_human 必须且只能提供一个",
 		})
 		return
 	}
+	if !h.resolveAccountPath(c, req.SessionID, req.Mnemonic, req.Account, &req.DerivationPath) {
+		return
+	}
+	var err error
+	if req.Token, err = validateAddressField(c, "token", req.Token); err != nil {
+		badInput(c, err)
+		return
+	}
+	if req.To, err = validateRecipientField(c, "to", req.To); err != nil {
+		badInput(c, err)
+		return
+	}
+	var amount *big.Int
+	if req.AmountHuman != "" {
+		_, _, decimals, err := h.walletService.GetTokenMetadata(c.Request.Context(), req.Token)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": e.ErrorContractCall,
+				"msg":  e.GetMsg(e.ErrorContractCall),
+				"data": "获取代币精度失败: " + err.Error(),
+			})
+			return
+		}
+		if amount, err = core.ParseTokenAmount(req.AmountHuman, decimals); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": e.InvalidParams,
+				"msg":  e.GetMsg(e.InvalidParams),
+				"data": err.Error(),
+			})
+			return
+		}
+		if amount.Sign() == 0 {
+			badInput(c, fmt.Errorf("amount_human 必须大于0"))
+			return
+		}
+		req.Amount = amount.String()
+	} else if amount, err = parseAmountField("amount", req.Amount, false); err != nil {
+		badInput(c, err)
+		return
+	}
 
-	var (
-		txHash string
-		err    error
-	)
+	recipient, ok := h.resolveRecipient(c, req.SessionID, req.To)
+	if !ok {
+		return
+	}
+	req.To = recipient.Address
+	from := h.senderAddress(req.SessionID, req.Mnemonic, req.DerivationPath)
+	blocked, ok := h.checkRecipient(c, from, req.To, req.ConfirmRecipient)
+	if !ok {
+		return
+	}
+	risk, ok := h.checkTxRisk(c, from, req.Token, amount, req.MFACode)
+	if !ok {
+		return
+	}
+	defer releaseTxRisk(h.walletService, risk)
+
+	var txHash string
 	if req.SessionID != "" {
-		txHash, err = h.walletService.SendERC20WithSession(req.SessionID, req.DerivationPath, req.Token, req.To, amount)
+		txHash, err = h.walletService.SendERC20WithSession(c.Request.Context(), req.SessionID, req.DerivationPath, req.Token, req.To, amount)
 	} else if req.Mnemonic != "" {
-		txHash, err = h.walletService.SendERC20(req.Mnemonic, req.DerivationPath, req.Token, req.To, amount)
+		txHash, err = h.walletService.SendERC20(c.Request.Context(), req.Mnemonic, req.DerivationPath, req.Token, req.To, amount)
 	} else {
 		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "需要提供 session_id 或 mnemonic"})
 		return
 	}
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ErrorTransactionSend,
-			"msg":  e.GetMsg(e.ErrorTransactionSend),
-			"data": err.Error(),
-		})
+		txSendError(c, http.StatusInternalServerError, e.ErrorTransactionSend, err)
 		return
 	}
+	h.walletService.RecordTxAudit("tx_send_erc20", txHash, h.txAuditContext(c, req.SessionID, req.Mnemonic, req.DerivationPath), map[string]interface{}{
+		"token":  req.Token,
+		"to":     req.To,
+		"amount": req.Amount,
+	})
 	c.JSON(http.StatusOK, gin.H{
 		"code": e.SUCCESS,
 		"msg":  e.GetMsg(e.SUCCESS),
-		"data": gin.H{"tx_hash": txHash},
+		"data": withInputWarnings(c, h.withTxRisk(withRecipientWarning(withRecipient(gin.H{"tx_hash": txHash}, recipient), blocked), risk, txHash)),
 	})
 }
 
+// SendBatch 按顺序发送多笔原生币/ERC20转账，nonce 连续分配
+// POST /api/v1/transactions/batch
+// 发送前逐笔检查收款地址黑名单，按资产汇总金额评估交易风险与支出限额；
+// 某一笔失败时之后的转账不再发送，响应中逐笔返回 sent/failed/skipped，failed 与 skipped 的转账可重新提交
+func (h *WalletHandler) SendBatch(c *gin.Context) {
+	var req SendBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	if len(req.Transfers) == 0 || len(req.Transfers) > core.MaxBatchTransfers {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": fmt.Sprintf("transfers 需包含 1-%d 笔转账", core.MaxBatchTransfers)})
+		return
+	}
+	if req.SessionID == "" && req.Mnemonic == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "需要提供 session_id 或 mnemonic"})
+		return
+	}
+	if !h.resolveAccountPath(c, req.SessionID, req.Mnemonic, req.Account, &req.DerivationPath) {
+		return
+	}
+	opts, err := parseTxOptions(req.GasPrice, req.MaxPriorityFeePerGas, req.MaxFeePerGas, "", req.Nonce)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+
+	from := h.senderAddress(req.SessionID, req.Mnemonic, req.DerivationPath)
+	transfers := make([]core.Transfer, 0, len(req.Transfers))
+	var warnings []*services.RecipientCheck
+	totals := make(map[string]*big.Int) // 资产（小写代币地址，原生币为空）-> 汇总金额
+	var assets []string
+	for i, item := range req.Transfers {
+		amount, err := h.batchTransferAmount(c.Request.Context(), item)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": fmt.Sprintf("第 %d 笔转账: %v", i+1, err)})
+			return
+		}
+		recipient, ok := h.resolveRecipient(c, req.SessionID, item.To)
+		if !ok {
+			return
+		}
+		blocked, ok := h.checkRecipient(c, from, recipient.Address, req.ConfirmRecipient)
+		if !ok {
+			return
+		}
+		if blocked != nil && blocked.Blocked {
+			warnings = append(warnings, blocked)
+		}
+		transfers = append(transfers, core.Transfer{To: recipient.Address, Token: item.Token, Amount: amount})
+
+		asset := strings.ToLower(item.Token)
+		if totals[asset] == nil {
+			totals[asset] = new(big.Int)
+			assets = append(assets, asset)
+		}
+		totals[asset].Add(totals[asset], amount)
+	}
+	risks := make(map[string]*txRiskCheck, len(assets))
+	for _, asset := range assets {
+		risk, ok := h.checkTxRisk(c, from, asset, totals[asset], req.MFACode)
+		if !ok {
+			return
+		}
+		defer releaseTxRisk(h.walletService, risk)
+		risks[asset] = risk
+	}
+
+	results, err := h.walletService.SendBatch(c.Request.Context(), req.SessionID, req.Mnemonic, req.DerivationPath, transfers, opts)
+	if err != nil {
+		txSendError(c, http.StatusInternalServerError, e.ErrorTransactionSend, err)
+		return
+	}
+
+	sent := 0
+	actx := h.txAuditContext(c, req.SessionID, req.Mnemonic, req.DerivationPath)
+	for _, result := range results {
+		if result.Status != core.BatchStatusSent {
+			continue
+		}
+		sent++
+		h.walletService.RecordTxAudit("tx_send_batch", result.TxHash, actx, map[string]interface{}{
+			"index":  result.Index,
+			"token":  result.Token,
+			"to":     result.To,
+			"amount": result.Amount,
+		})
+		// 每种资产的风险事件与支出按汇总金额记录一次，关联该资产的第一笔已发送交易
+		asset := strings.ToLower(result.Token)
+		if risk, ok := risks[asset]; ok {
+			recordTxRisk(h.walletService, gin.H{}, risk, result.TxHash)
+			delete(risks, asset)
+		}
+	}
+	data := gin.H{"results": results, "total": len(results), "sent": sent}
+	if len(warnings) > 0 {
+		data["recipient_warnings"] = warnings
+	}
+	if sent < len(results) {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorTransactionSend, "msg": "部分转账未发送，可重新提交 failed 与 skipped 的转账", "data": data})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": data})
+}
+
+// batchTransferAmount 解析批量转账的金额，amount_human 按原生币或代币 decimals 转换
+func (h *WalletHandler) batchTransferAmount(ctx context.Context, item BatchTransferItem) (*big.Int, error) {
+	if (item.Amount == "") == (item.AmountHuman == "") {
+		return nil, fmt.Errorf("amount 与 amount_human 必须且只能提供一个")
+	}
+	if item.Amount != "" {
+		amount, ok := new(big.Int).SetString(item.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("amount 需要是十进制数字字符串")
+		}
+		return amount, nil
+	}
+	decimals := uint8(core.NativeCurrencyFor(h.walletService.GetMultiChainManager().GetCurrentNetwork()).Decimals)
+	if item.Token != "" {
+		var err error
+		if _, _, decimals, err = h.walletService.GetTokenMetadata(ctx, item.Token); err != nil {
+			return nil, fmt.Errorf("获取代币精度失败: %w", err)
+		}
+	}
+	return core.ParseTokenAmount(item.AmountHuman, decimals)
+}
+
 // GetNonces 获取地址的nonce值
 // GET /api/v1/wallets/:address/nonce
 // 功能: 获取指定地址的pending和latest nonce值
@@ -387,7 +924,7 @@ func (h *WalletHandler) GetNonces(c *gin.Context) {
 	}
 
 	// 调用业务服务层获取nonce值
-	pending, latest, err := h.walletService.GetNonces(address)
+	pending, latest, err := h.walletService.GetNonces(c.Request.Context(), address)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": e.ErrorGetBalance,
@@ -409,13 +946,46 @@ func (h *WalletHandler) GetNonces(c *gin.Context) {
 	})
 }
 
-// GetGasSuggestion 获取Gas价格建议
-// GET /api/v1/gas-suggestion
-// 功能: 获取当前网络的Gas价格建议（支持EIP-1559和Legacy模式）
-// 返回: 包含Gas价格建议的JSON响应
-func (h *WalletHandler) GetGasSuggestion(c *gin.Context) {
+// GetNonceGap 检测地址阻塞的待处理交易
+// GET /api/v1/address/:address/nonce-gap
+// 功能: 列出 latest 与 pending nonce 之间的交易及其当前费率，针对最低 nonce 给出加速/取消建议
+// 建议可直接作为 POST /api/v1/transactions/replace 的参数提交
+func (h *WalletHandler) GetNonceGap(c *gin.Context) {
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "钱包地址格式不正确"})
+		return
+	}
+	report, err := h.walletService.DetectNonceGap(c.Request.Context(), address)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ERROR, "msg": e.GetMsg(e.ERROR), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": report})
+}
+
+// GetGasSuggestion 获取Gas价格建议
+// GET /api/v1/gas-suggestion
+// 功能: 获取当前网络的Gas价格建议（支持EIP-1559和Legacy模式）
+// 返回: 包含Gas价格建议的JSON响应
+func (h *WalletHandler) GetGasSuggestion(c *gin.Context) {
+	// 可选 gas_limit：额外估算指定 Gas 用量的费用
+	var gasLimit uint64
+	if raw := c.Query("gas_limit"); raw != "" {
+		v, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil || v == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": e.InvalidParams,
+				"msg":  e.GetMsg(e.InvalidParams),
+				"data": "gas_limit 必须为正整数",
+			})
+			return
+		}
+		gasLimit = v
+	}
+
 	// 调用业务服务层获取Gas价格建议
-	gasSuggestion, err := h.walletService.GetGasSuggestion()
+	gasSuggestion, err := h.walletService.GetGasSuggestion(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": e.ErrorGetBalance,
@@ -431,16 +1001,39 @@ func (h *WalletHandler) GetGasSuggestion(c *gin.Context) {
 		"gas_price": gin.H{
 			"legacy": gasSuggestion.GasPrice.String(),
 		},
+		"native_currency": h.walletService.GetNativeCurrency(),
 	}
 
 	// 如果支持EIP-1559，添加相关信息
 	if gasSuggestion.BaseFee != nil && gasSuggestion.BaseFee.Cmp(big.NewInt(0)) > 0 {
-		response["eip1559"] = gin.H{
+		eip1559 := gin.H{
 			"base_fee":                 gasSuggestion.BaseFee.String(),
 			"max_fee_per_gas":          gasSuggestion.MaxFee.String(),
 			"max_priority_fee_per_gas": gasSuggestion.TipCap.String(),
 		}
+		// 基于费用历史的分档建议（slow/normal/fast）
+		if len(gasSuggestion.Tiers) > 0 {
+			tiers := gin.H{}
+			for name, tier := range gasSuggestion.Tiers {
+				tiers[name] = gin.H{
+					"max_fee_per_gas":          tier.MaxFee.String(),
+					"max_priority_fee_per_gas": tier.TipCap.String(),
+				}
+			}
+			eip1559["tiers"] = tiers
+			eip1559["base_fee_trend"] = gasSuggestion.BaseFeeTrend
+		}
+		response["eip1559"] = eip1559
+	}
+
+	// 常见交易的费用估算（美元）
+	feeEstimate := h.walletService.EstimateGasFeesUSD(c.Request.Context(), gasSuggestion, gasLimit)
+	response["transfer_fee_usd"] = feeEstimate.Default.TransferFeeUSD
+	response["erc20_fee_usd"] = feeEstimate.Default.ERC20FeeUSD
+	if gasLimit > 0 {
+		response["gas_limit_fee_usd"] = feeEstimate.Default.CustomFeeUSD
 	}
+	response["fee_estimates"] = feeEstimate
 
 	// 返回成功响应
 	c.JSON(http.StatusOK, gin.H{
@@ -450,6 +1043,16 @@ func (h *WalletHandler) GetGasSuggestion(c *gin.Context) {
 	})
 }
 
+// GetChainCongestion 获取当前网络拥堵状态（内存池交易数、baseFee 相对近期均值、拥堵等级）
+func (h *WalletHandler) GetChainCongestion(c *gin.Context) {
+	status, err := h.walletService.GetMempoolStatus(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorGasSuggestion, "msg": e.GetMsg(e.ErrorGasSuggestion), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": status})
+}
+
 // EstimateTransaction 估算交易 gasLimit
 type EstimateTxRequest struct {
 	From     string `json:"from" binding:"required"`
@@ -472,12 +1075,97 @@ func (h *WalletHandler) EstimateTransaction(c *gin.Context) {
 		}
 	}
 	// data 解析在服务层处理也可，这里直接传原始 hex 字符串由服务层解析为 bytes
-	limit, err := h.walletService.EstimateGas(req.From, req.To, val, []byte(req.DataHex))
+	limit, err := h.walletService.EstimateGas(c.Request.Context(), req.From, req.To, val, []byte(req.DataHex))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorTransactionBuild, "msg": e.GetMsg(e.ErrorTransactionBuild), "data": err.Error()})
+		return
+	}
+	data := gin.H{"gas_limit": limit}
+	// 原生代币转账预检：余额预留提醒
+	if strings.TrimSpace(req.DataHex) == "" {
+		data = withReserveWarning(data, h.walletService.CheckBalanceReserve(c.Request.Context(), req.From, val, &services.TxOptions{GasLimit: limit}))
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": data})
+}
+
+// SimulateTxRequest 交易模拟请求（字段与估算一致）
+type SimulateTxRequest = EstimateTxRequest
+
+// SimulateTransaction 在最新区块模拟执行交易，返回是否会回滚、回滚原因及预估Gas
+// POST /api/v1/transactions/simulate
+func (h *WalletHandler) SimulateTransaction(c *gin.Context) {
+	var req SimulateTxRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	val := big.NewInt(0)
+	if req.ValueWei != "" {
+		if _, ok := val.SetString(req.ValueWei, 10); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "value_wei 需要是十进制数字字符串"})
+			return
+		}
+	}
+	result, err := h.walletService.SimulateTransaction(c.Request.Context(), req.From, req.To, val, req.DataHex)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorTransactionBuild, "msg": e.GetMsg(e.ErrorTransactionBuild), "data": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"gas_limit": limit}})
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": result})
+}
+
+// DecodeCalldataRequest 解码交易 calldata 的请求
+type DecodeCalldataRequest struct {
+	Data string          `json:"data" binding:"required"` // 0x 开头的 calldata
+	ABI  json.RawMessage `json:"abi"`                     // 可选：合约ABI（JSON数组或其字符串形式）
+}
+
+// DecodeCalldata 解码任意交易 calldata：方法名、参数名/类型/值，以及无限授权等风险提示（只读）
+// POST /api/v1/transactions/decode
+func (h *WalletHandler) DecodeCalldata(c *gin.Context) {
+	var req DecodeCalldataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	abiJSON := ""
+	if len(req.ABI) > 0 && string(req.ABI) != "null" {
+		abiJSON = string(req.ABI)
+		var abiStr string
+		if err := json.Unmarshal(req.ABI, &abiStr); err == nil {
+			abiJSON = abiStr
+		}
+	}
+	data, err := core.ParseCalldata(req.Data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+	defer cancel()
+	decoded, err := h.walletService.DecodeCalldata(ctx, data, abiJSON)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": decoded})
+}
+
+// abortIfSimulationFails 发送前模拟交易，预计回滚时返回错误响应并返回 true
+// 模拟本身出错（节点不可用等）时同样中止，避免在无法确认的情况下发送
+func (h *WalletHandler) abortIfSimulationFails(c *gin.Context, result *core.SimulationResult, err error) bool {
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorTxSimulationFailed, "msg": e.GetMsg(e.ErrorTxSimulationFailed), "data": err.Error()})
+		return true
+	}
+	if !result.Success {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.ErrorTxSimulationFailed, "msg": e.GetMsg(e.ErrorTxSimulationFailed), "data": gin.H{
+			"revert_reason": result.RevertReason,
+			"simulation":    result,
+		}})
+		return true
+	}
+	return false
 }
 
 // BroadcastRawTransaction 广播原始交易
@@ -491,11 +1179,12 @@ func (h *WalletHandler) BroadcastRawTransaction(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
 		return
 	}
-	txHash, err := h.walletService.BroadcastRawTx(req.RawTx)
+	txHash, err := h.walletService.BroadcastRawTx(c.Request.Context(), req.RawTx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorBroadcastRawTx, "msg": e.GetMsg(e.ErrorBroadcastRawTx), "data": err.Error()})
+		txSendError(c, http.StatusInternalServerError, e.ErrorBroadcastRawTx, err)
 		return
 	}
+	h.walletService.RecordTxAudit("tx_broadcast_raw", txHash, h.txAuditContext(c, "", "", ""), nil)
 	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"tx_hash": txHash}})
 }
 
@@ -539,6 +1228,7 @@ type DeriveAddressesRequest struct {
 	SessionID  string `json:"session_id"`  // 可选：优先使用 session
 	Mnemonic   string `json:"mnemonic"`    // 可选：未提供 session_id 时使用
 	PathPrefix string `json:"path_prefix"` // 默认 "m/44'/60'/0'/0"
+	Scheme     string `json:"scheme"`      // 可选：metamask/ledger_live/ledger_legacy/trezor，指定时忽略 path_prefix
 	Start      int    `json:"start"`       // 默认 0
 	Count      int    `json:"count"`       // 默认 5
 }
@@ -552,209 +1242,1290 @@ func (h *WalletHandler) DeriveAddresses(c *gin.Context) {
 	if req.Count <= 0 {
 		req.Count = 5
 	}
+	if req.SessionID == "" && req.Mnemonic == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "需要提供 session_id 或 mnemonic"})
+		return
+	}
+
 	var (
-		addrs []string
-		err   error
+		accounts []core.DerivedAddress
+		err      error
 	)
-	if req.SessionID != "" {
-		addrs, err = h.walletService.DeriveAddressesBySession(req.SessionID, req.PathPrefix, req.Start, req.Count)
-	} else if req.Mnemonic != "" {
-		addrs, err = h.walletService.DeriveAddressesFromMnemonic(req.Mnemonic, req.PathPrefix, req.Start, req.Count)
+	if req.Scheme != "" {
+		scheme, perr := core.ParseDerivationScheme(req.Scheme)
+		if perr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": perr.Error()})
+			return
+		}
+		if req.SessionID != "" {
+			accounts, err = h.walletService.DeriveAddressesSchemeBySession(req.SessionID, scheme, req.Start, req.Count)
+		} else {
+			accounts, err = h.walletService.DeriveAddressesScheme(req.Mnemonic, scheme, req.Start, req.Count)
+		}
 	} else {
-		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "需要提供 session_id 或 mnemonic"})
-		return
+		var addrs []string
+		if req.SessionID != "" {
+			addrs, err = h.walletService.DeriveAddressesBySession(req.SessionID, req.PathPrefix, req.Start, req.Count)
+		} else {
+			addrs, err = h.walletService.DeriveAddressesFromMnemonic(req.Mnemonic, req.PathPrefix, req.Start, req.Count)
+		}
+		prefix := req.PathPrefix
+		if prefix == "" {
+			prefix = "m/44'/60'/0'/0"
+		}
+		for i, addr := range addrs {
+			accounts = append(accounts, core.DerivedAddress{Index: req.Start + i, Path: fmt.Sprintf("%s/%d", prefix, req.Start+i), Address: addr})
+		}
 	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorWalletImport, "msg": e.GetMsg(e.ErrorWalletImport), "data": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"addresses": addrs}})
+	addrs := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		addrs = append(addrs, account.Address)
+	}
+	// addresses 保持兼容，accounts 附带每个地址的完整派生路径
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"addresses": addrs, "accounts": accounts}})
 }
 
 type WatchOnlyAddRequest struct {
 	Address string `json:"address" binding:"required"`
+	Label   string `json:"label"`   // 可选备注
+	Network string `json:"network"` // 可选，默认当前网络
+}
+
+// WatchOnlyLabelRequest 设置只读地址标签
+type WatchOnlyLabelRequest struct {
+	Label   string `json:"label"`   // 为空表示清除标签
+	Network string `json:"network"` // 可选，默认当前网络
+}
+
+// WatchOnlyThresholdRequest 设置只读地址到账通知阈值
+type WatchOnlyThresholdRequest struct {
+	MinValueUSD float64 `json:"min_value_usd"` // 低于该美元金额的到账不通知，0 表示全部通知
+	Network     string  `json:"network"`       // 可选，默认当前网络
+}
+
+// UserTokenAddRequest 添加自定义代币
+type UserTokenAddRequest struct {
+	Contract string `json:"contract" binding:"required"`
+	Network  string `json:"network"` // 可选，默认当前网络
+}
+
+// UserTokenUpdateRequest 修改自定义代币
+type UserTokenUpdateRequest struct {
+	Symbol  string `json:"symbol"`  // 显示符号，为空表示恢复链上符号
+	Network string `json:"network"` // 可选，默认当前网络
+}
+
+// optionalSessionOwner 获取当前会话对应的钱包地址，未登录或会话无效时返回空字符串
+func (h *WalletHandler) optionalSessionOwner(c *gin.Context) string {
+	userID, _ := c.Get("user_id")
+	sessionID, _ := userID.(string)
+	if sessionID == "" {
+		return ""
+	}
+	owner, err := h.walletService.GetSessionAddress(sessionID)
+	if err != nil {
+		return ""
+	}
+	return owner
+}
+
+// sessionOwner 获取当前会话对应的钱包地址，失败时写入401响应
+func (h *WalletHandler) sessionOwner(c *gin.Context) (string, bool) {
+	userID, _ := c.Get("user_id")
+	sessionID, _ := userID.(string)
+	owner, err := h.walletService.GetSessionAddress(sessionID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": e.ErrorAuth, "msg": "会话无效或已过期", "data": err.Error()})
+		return "", false
+	}
+	return owner, true
+}
+
+// pathOwner 校验路径中的钱包地址（:address）属于当前会话，失败时写入响应；resource 为管理的资源名称，用于提示
+func (h *WalletHandler) pathOwner(c *gin.Context, resource string) (string, bool) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return "", false
+	}
+	if !strings.EqualFold(owner, c.Param("address")) {
+		c.JSON(http.StatusForbidden, gin.H{"code": e.ErrorAuth, "msg": "只能管理当前会话钱包的" + resource, "data": nil})
+		return "", false
+	}
+	return owner, true
 }
 
 func (h *WalletHandler) AddWatchOnly(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
 	var req WatchOnlyAddRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
 		return
 	}
-	addr, err := h.walletService.AddWatchOnly(req.Address)
+	entry, err := h.walletService.AddWatchOnly(owner, req.Address, strings.TrimSpace(req.Label), strings.TrimSpace(req.Network))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"address": addr}})
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": entry})
 }
 
+// ListWatchOnly 获取只读地址列表（含标签与当前余额），可通过 network 查询参数过滤
 func (h *WalletHandler) ListWatchOnly(c *gin.Context) {
-	addrs := h.walletService.ListWatchOnly()
-	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"addresses": addrs}})
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	items, err := h.walletService.ListWatchOnlyWithBalances(c.Request.Context(), owner, c.Query("network"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ERROR, "msg": e.GetMsg(e.ERROR), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"addresses": items, "total": len(items)}})
+}
+
+// SetWatchOnlyLabel 设置只读地址标签
+func (h *WalletHandler) SetWatchOnlyLabel(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	var req WatchOnlyLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	entry, err := h.walletService.SetWatchOnlyLabel(owner, c.Param("address"), strings.TrimSpace(req.Network), strings.TrimSpace(req.Label))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": entry})
+}
+
+// SetWatchOnlyThreshold 设置只读地址到账通知的最小美元金额
+func (h *WalletHandler) SetWatchOnlyThreshold(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	var req WatchOnlyThresholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	entry, err := h.walletService.SetWatchOnlyThreshold(owner, c.Param("address"), strings.TrimSpace(req.Network), req.MinValueUSD)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": entry})
+}
+
+// ExportWatchOnly 导出只读地址列表（含标签与当前余额），format=csv|json，可通过 network 过滤
+func (h *WalletHandler) ExportWatchOnly(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "format 仅支持 csv 或 json"})
+		return
+	}
+	items, err := h.walletService.ListWatchOnlyWithBalances(c.Request.Context(), owner, c.Query("network"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ERROR, "msg": e.GetMsg(e.ERROR), "data": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", "attachment; filename=watch_only."+format)
+	if format == "json" {
+		c.Header("Content-Type", "application/json; charset=utf-8")
+		c.Status(http.StatusOK)
+		if err := json.NewEncoder(c.Writer).Encode(items); err != nil {
+			slog.WarnContext(c.Request.Context(), "watch-only export failed", "error", err)
+		}
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	cw := csv.NewWriter(c.Writer)
+	_ = cw.Write([]string{"address", "label", "network", "balance_wei", "balance", "symbol", "added_at", "error"})
+	for _, item := range items {
+		_ = cw.Write([]string{item.Address, item.Label, item.Network, item.BalanceWei, item.Balance, item.Symbol, item.AddedAt.Format(time.RFC3339), item.Error})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		slog.WarnContext(c.Request.Context(), "watch-only export failed", "error", err)
+	}
 }
 
+// RemoveWatchOnly 删除只读地址，可通过 network 查询参数指定网络（默认当前网络）
 func (h *WalletHandler) RemoveWatchOnly(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
 	address := c.Param("address")
 	if address == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "address 不能为空"})
 		return
 	}
-	if err := h.walletService.RemoveWatchOnly(address); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+	if err := h.walletService.RemoveWatchOnly(owner, address, c.Query("network")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": "ok"})
+}
+
+// AddUserToken 添加自定义代币（校验ERC20并从链上读取元数据，按网络+合约去重）
+func (h *WalletHandler) AddUserToken(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	var req UserTokenAddRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	entry, err := h.walletService.AddUserToken(c.Request.Context(), owner, strings.TrimSpace(req.Network), strings.TrimSpace(req.Contract))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": entry})
+}
+
+// ListUserTokens 获取自定义代币列表，可通过 network 查询参数过滤
+func (h *WalletHandler) ListUserTokens(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	tokens, err := h.walletService.ListUserTokens(owner, c.Query("network"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ERROR, "msg": e.GetMsg(e.ERROR), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"tokens": tokens, "total": len(tokens)}})
+}
+
+// UpdateUserToken 修改自定义代币的显示符号
+func (h *WalletHandler) UpdateUserToken(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	var req UserTokenUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	entry, err := h.walletService.UpdateUserTokenSymbol(c.Request.Context(), owner, strings.TrimSpace(req.Network), c.Param("token"), strings.TrimSpace(req.Symbol))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": entry})
+}
+
+// RemoveUserToken 删除自定义代币
+func (h *WalletHandler) RemoveUserToken(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	if err := h.walletService.RemoveUserToken(owner, c.Query("network"), c.Param("token")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": "ok"})
+}
+
+// -------- 新增：交易回执 / token元数据 / 签名 --------
+
+func (h *WalletHandler) GetTxReceipt(c *gin.Context) {
+	hash := c.Param("hash")
+	if hash == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "hash 不能为空"})
+		return
+	}
+	dto, err := h.walletService.GetReceipt(c.Request.Context(), hash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorTransactionBuild, "msg": e.GetMsg(e.ErrorTransactionBuild), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": dto})
+}
+
+// 等待交易确认的默认值与上限（确认数默认为当前网络的 required_confirmations）
+const (
+	maxWaitConfirmations = 256
+	defaultWaitTimeout   = 60 * time.Second
+	maxWaitTimeout       = 300 * time.Second
+)
+
+// WaitForTxConfirmation 长轮询等待交易达到指定确认数
+// 查询参数: confirmations（默认为当前网络要求的确认数，最大256）、timeout（秒，默认60，最大300）
+func (h *WalletHandler) WaitForTxConfirmation(c *gin.Context) {
+	hash := c.Param("hash")
+	if hash == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "hash 不能为空"})
+		return
+	}
+	multiChain := h.walletService.GetMultiChainManager()
+	confirmations := multiChain.RequiredConfirmations(multiChain.GetCurrentNetwork())
+	if v := c.Query("confirmations"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || n > maxWaitConfirmations {
+			c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": fmt.Sprintf("confirmations 需为 0~%d 的整数", maxWaitConfirmations)})
+			return
+		}
+		confirmations = n
+	}
+	timeout := defaultWaitTimeout
+	if v := c.Query("timeout"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs <= 0 || time.Duration(secs)*time.Second > maxWaitTimeout {
+			c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": fmt.Sprintf("timeout 需为 1~%d 的秒数", int(maxWaitTimeout.Seconds()))})
+			return
+		}
+		timeout = time.Duration(secs) * time.Second
+	}
+
+	// 客户端断开时随请求上下文一并取消
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+	dto, err := h.walletService.WaitForConfirmation(ctx, hash, confirmations)
+	if err != nil {
+		if errors.Is(err, core.ErrConfirmationTimeout) {
+			c.JSON(http.StatusRequestTimeout, gin.H{"code": e.ERROR, "msg": e.GetMsg(e.ERROR), "data": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ERROR, "msg": e.GetMsg(e.ERROR), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"confirmations": confirmations, "receipt": dto}})
+}
+
+// GetTxLogs 获取并解码交易触发的事件
+// 可通过查询参数 abi 传入合约ABI（JSON），未提供时使用内置的常见事件ABI
+func (h *WalletHandler) GetTxLogs(c *gin.Context) {
+	hash := c.Param("hash")
+	if hash == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "hash 不能为空"})
+		return
+	}
+	logs, err := h.walletService.GetTransactionLogs(c.Request.Context(), hash, c.Query("abi"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ERROR, "msg": e.GetMsg(e.ERROR), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"tx_hash": hash, "logs": logs}})
+}
+
+func (h *WalletHandler) GetTokenMetadata(c *gin.Context) {
+	token := c.Param("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "token 地址不能为空"})
+		return
+	}
+	name, symbol, decimals, err := h.walletService.GetTokenMetadata(c.Request.Context(), token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorGetBalance, "msg": e.GetMsg(e.ErrorGetBalance), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{
+		"name": name, "symbol": symbol, "decimals": decimals,
+	}})
+}
+
+type SendTransactionAdvanced struct {
+	SessionID      string `json:"session_id"`
+	Mnemonic       string `json:"mnemonic"`
+	DerivationPath string `json:"derivation_path"`
+	Account        *int   `json:"account"`               // 账户序号（可选，代替 derivation_path）
+	To             string `json:"to" binding:"required"` // 0x地址、ENS域名或 contact:<联系人ID>
+	ValueWei       string `json:"value_wei" binding:"required"`
+
+	// gas & nonce（十进制字符串）
+	GasPrice             string `json:"gas_price"`                // legacy
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas"` // EIP-1559
+	MaxFeePerGas         string `json:"max_fee_per_gas"`          // EIP-1559
+	GasLimit             string `json:"gas_limit"`                // 可选
+	Nonce                string `json:"nonce"`                    // 可选
+
+	// 手续费上限（wei），为空时使用钱包设置；超限时确认后置 allow_high_fee 为 true 重新提交
+	MaxFeeWei    string `json:"max_fee_wei"`
+	AllowHighFee bool   `json:"allow_high_fee"`
+
+	// EIP-2930 访问列表；auto_access_list 为 true 时发送前自动生成（仅在节省 Gas 时使用）
+	AccessList     []AccessListItem `json:"access_list"`
+	AutoAccessList bool             `json:"auto_access_list"`
+
+	// 截止时间（Unix 秒），超过后仍未打包则标记过期；auto_cancel 为 true 时自动以相同 nonce 取消
+	ValidUntil int64 `json:"valid_until"`
+	AutoCancel bool  `json:"auto_cancel"`
+
+	// 为 true 时发送前先模拟执行，预计回滚则中止并返回回滚原因
+	Simulate bool `json:"simulate"`
+
+	// 交易被判定为异常时需提交的双因素验证码
+	MFACode string `json:"mfa_code"`
+
+	// 接收地址在黑名单中且处理方式为 warn 时，确认风险后置为 true 重新提交
+	ConfirmRecipient bool `json:"confirm_recipient"`
+}
+
+// AdvancedERC20SendRequest 高级 ERC20 发送
+type AdvancedERC20SendRequest struct {
+	SessionID      string `json:"session_id"`
+	Mnemonic       string `json:"mnemonic"`
+	DerivationPath string `json:"derivation_path"`
+	Account        *int   `json:"account"` // 账户序号（可选，代替 derivation_path）
+	Token          string `json:"token" binding:"required"`
+	To             string `json:"to" binding:"required"` // 0x地址、ENS域名或 contact:<联系人ID>
+	Amount         string `json:"amount" binding:"required"`
+
+	GasPrice             string `json:"gas_price"`
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas"`
+	MaxFeePerGas         string `json:"max_fee_per_gas"`
+	GasLimit             string `json:"gas_limit"`
+	Nonce                string `json:"nonce"`
+
+	// 手续费上限（wei），为空时使用钱包设置；超限时确认后置 allow_high_fee 为 true 重新提交
+	MaxFeeWei    string `json:"max_fee_wei"`
+	AllowHighFee bool   `json:"allow_high_fee"`
+
+	// EIP-2930 访问列表；auto_access_list 为 true 时发送前自动生成（仅在节省 Gas 时使用）
+	AccessList     []AccessListItem `json:"access_list"`
+	AutoAccessList bool             `json:"auto_access_list"`
+
+	// 为 true 时发送前先模拟转账，预计回滚则中止并返回回滚原因
+	Simulate bool `json:"simulate"`
+
+	// 交易被判定为异常时需提交的双因素验证码
+	MFACode string `json:"mfa_code"`
+
+	// 接收地址在黑名单中且处理方式为 warn 时，确认风险后置为 true 重新提交
+	ConfirmRecipient bool `json:"confirm_recipient"`
+}
+
+// ApproveRequest 授权
+type ApproveRequest struct {
+	SessionID      string `json:"session_id"`
+	Mnemonic       string `json:"mnemonic"`
+	DerivationPath string `json:"derivation_path"`
+	Spender        string `json:"spender" binding:"required"`
+	Amount         string `json:"amount" binding:"required"`
+
+	GasPrice             string `json:"gas_price"`
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas"`
+	MaxFeePerGas         string `json:"max_fee_per_gas"`
+	GasLimit             string `json:"gas_limit"`
+	Nonce                string `json:"nonce"`
+
+	// 手续费上限（wei），为空时使用钱包设置；超限时确认后置 allow_high_fee 为 true 重新提交
+	MaxFeeWei    string `json:"max_fee_wei"`
+	AllowHighFee bool   `json:"allow_high_fee"`
+}
+
+func (h *WalletHandler) SendTransactionAdvanced(c *gin.Context) {
+	var req SendTransactionAdvanced
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	if !h.resolveAccountPath(c, req.SessionID, req.Mnemonic, req.Account, &req.DerivationPath) {
+		return
+	}
+	val, err := parseAmountField("value_wei", req.ValueWei, false)
+	if err != nil {
+		badInput(c, err)
+		return
+	}
+	if req.To, err = validateRecipientField(c, "to", req.To); err != nil {
+		badInput(c, err)
+		return
+	}
+	opts, err := parseTxOptions(req.GasPrice, req.MaxPriorityFeePerGas, req.MaxFeePerGas, req.GasLimit, req.Nonce)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	if err := applyFeeCeiling(opts, req.MaxFeeWei, req.AllowHighFee); err != nil {
+		badInput(c, err)
+		return
+	}
+	if err := applyAccessList(opts, req.AccessList, req.AutoAccessList); err != nil {
+		badInput(c, err)
+		return
+	}
+	if req.DerivationPath == "" {
+		req.DerivationPath = "m/44'/60'/0'/0/0"
+	}
+	recipient, ok := h.resolveRecipient(c, req.SessionID, req.To)
+	if !ok {
+		return
+	}
+	req.To = recipient.Address
+	from := h.senderAddress(req.SessionID, req.Mnemonic, req.DerivationPath)
+	blocked, ok := h.checkRecipient(c, from, req.To, req.ConfirmRecipient)
+	if !ok {
+		return
+	}
+	risk, ok := h.checkTxRisk(c, from, "", val, req.MFACode)
+	if !ok {
+		return
+	}
+	defer releaseTxRisk(h.walletService, risk)
+	if req.Simulate && from != "" {
+		result, err := h.walletService.SimulateTransaction(c.Request.Context(), from, req.To, val, "")
+		if h.abortIfSimulationFails(c, result, err) {
+			return
+		}
+	}
+	// 发送前预检：余额预留提醒（不阻止发送）
+	warning := h.walletService.CheckBalanceReserve(c.Request.Context(), from, val, opts)
+	if req.ValidUntil > 0 {
+		h.sendETHWithDeadline(c, &req, val, opts, warning, recipient, blocked, risk)
+		return
+	}
+	var (
+		txHash string
+	)
+	if req.SessionID != "" {
+		txHash, err = h.walletService.SendETHAdvancedWithSession(c.Request.Context(), req.SessionID, req.DerivationPath, req.To, val, opts)
+	} else if req.Mnemonic != "" {
+		txHash, err = h.walletService.SendETHAdvanced(c.Request.Context(), req.Mnemonic, req.DerivationPath, req.To, val, opts)
+	} else {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "需要提供 session_id 或 mnemonic"})
+		return
+	}
+	if err != nil {
+		txSendError(c, http.StatusInternalServerError, e.ErrorTransactionSend, err)
+		return
+	}
+	h.walletService.RecordTxAudit("tx_send_eth", txHash, h.txAuditContext(c, req.SessionID, req.Mnemonic, req.DerivationPath), map[string]interface{}{
+		"to":                       req.To,
+		"value_wei":                req.ValueWei,
+		"gas_price":                req.GasPrice,
+		"max_priority_fee_per_gas": req.MaxPriorityFeePerGas,
+		"max_fee_per_gas":          req.MaxFeePerGas,
+		"gas_limit":                req.GasLimit,
+		"nonce":                    req.Nonce,
+	})
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": withInputWarnings(c, withReserveWarning(h.withTxRisk(withRecipientWarning(withRecipient(gin.H{"tx_hash": txHash}, recipient), blocked), risk, txHash), warning))})
+}
+
+// sendETHWithDeadline 带截止时间的高级发送，返回跟踪记录
+func (h *WalletHandler) sendETHWithDeadline(c *gin.Context, req *SendTransactionAdvanced, val *big.Int, opts *services.TxOptions, warning *services.BalanceReserveWarning, recipient *services.RecipientResolution, blocked *services.RecipientCheck, risk *txRiskCheck) {
+	validUntil := time.Unix(req.ValidUntil, 0)
+	var (
+		record *services.DeadlineTx
+		err    error
+	)
+	if req.SessionID != "" {
+		record, err = h.walletService.SendETHAdvancedWithDeadlineSession(c.Request.Context(), req.SessionID, req.DerivationPath, req.To, val, opts, validUntil, req.AutoCancel)
+	} else if req.Mnemonic != "" {
+		record, err = h.walletService.SendETHAdvancedWithDeadline(c.Request.Context(), req.Mnemonic, req.DerivationPath, req.To, val, opts, validUntil, req.AutoCancel)
+	} else {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "需要提供 session_id 或 mnemonic"})
+		return
+	}
+	if err != nil {
+		txSendError(c, http.StatusInternalServerError, e.ErrorTransactionSend, err)
+		return
+	}
+	h.walletService.RecordTxAudit("tx_send_eth", record.TxHash, h.txAuditContext(c, req.SessionID, req.Mnemonic, req.DerivationPath), map[string]interface{}{
+		"to":                       req.To,
+		"value_wei":                req.ValueWei,
+		"valid_until":              req.ValidUntil,
+		"auto_cancel":              req.AutoCancel,
+		"gas_price":                req.GasPrice,
+		"max_priority_fee_per_gas": req.MaxPriorityFeePerGas,
+		"max_fee_per_gas":          req.MaxFeePerGas,
+		"gas_limit":                req.GasLimit,
+		"nonce":                    req.Nonce,
+	})
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": withInputWarnings(c, withReserveWarning(h.withTxRisk(withRecipientWarning(withRecipient(gin.H{"tx_hash": record.TxHash, "deadline": record}, recipient), blocked), risk, record.TxHash), warning))})
+}
+
+// GetTxDeadline 查询带截止时间交易的跟踪状态
+func (h *WalletHandler) GetTxDeadline(c *gin.Context) {
+	record, err := h.walletService.GetDeadlineTx(c.Param("hash"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": e.ERROR, "msg": e.GetMsg(e.ERROR), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": record})
+}
+
+// GetPendingTransactions 查询地址通过本服务发送的交易及其确认状态
+// GET /api/v1/transactions/pending?address=
+func (h *WalletHandler) GetPendingTransactions(c *gin.Context) {
+	address := c.Query("address")
+	if !common.IsHexAddress(address) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.ErrorWalletAddressInvalid, "msg": e.GetMsg(e.ErrorWalletAddressInvalid), "data": address})
+		return
+	}
+	txs, err := h.walletService.GetPendingTransactions(address)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{
+		"address":      common.HexToAddress(address).Hex(),
+		"transactions": txs,
+	}})
+}
+
+// ReplaceTransactionRequest 按 nonce 加速/取消交易
+type ReplaceTransactionRequest struct {
+	SessionID      string `json:"session_id"`
+	Mnemonic       string `json:"mnemonic"`
+	DerivationPath string `json:"derivation_path"`
+	Mode           string `json:"mode" binding:"required,oneof=speed_up cancel"` // speed_up / cancel
+	Nonce          string `json:"nonce" binding:"required"`                      // 待替换交易的 nonce（十进制字符串）
+	TxHash         string `json:"tx_hash"`                                       // 可选：原交易哈希
+
+	// 新费率（十进制字符串），均为空时在原费率基础上自动上调
+	GasPrice             string `json:"gas_price"`
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas"`
+	MaxFeePerGas         string `json:"max_fee_per_gas"`
+	GasLimit             string `json:"gas_limit"`
+
+	// 原交易费率（十进制字符串），节点无法查询原交易时用于校验涨幅
+	OriginalGasPrice             string `json:"original_gas_price"`
+	OriginalMaxPriorityFeePerGas string `json:"original_max_priority_fee_per_gas"`
+	OriginalMaxFeePerGas         string `json:"original_max_fee_per_gas"`
+
+	// 手续费上限（wei），为空时使用钱包设置；超限时确认后置 allow_high_fee 为 true 重新提交
+	MaxFeeWei    string `json:"max_fee_wei"`
+	AllowHighFee bool   `json:"allow_high_fee"`
+}
+
+// ReplaceTransaction 以相同 nonce 加速或取消仍在交易池中的交易
+func (h *WalletHandler) ReplaceTransaction(c *gin.Context) {
+	var req ReplaceTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	newOpts, err := parseTxOptions(req.GasPrice, req.MaxPriorityFeePerGas, req.MaxFeePerGas, req.GasLimit, req.Nonce)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	original, err := parseTxOptions(req.OriginalGasPrice, req.OriginalMaxPriorityFeePerGas, req.OriginalMaxFeePerGas, "", "")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "original_" + err.Error()})
+		return
+	}
+	opts := &services.ReplaceTxOptions{
+		GasPrice:         newOpts.GasPrice,
+		TipCap:           newOpts.TipCap,
+		FeeCap:           newOpts.FeeCap,
+		GasLimit:         newOpts.GasLimit,
+		OriginalTxHash:   req.TxHash,
+		OriginalGasPrice: original.GasPrice,
+		OriginalTipCap:   original.TipCap,
+		OriginalFeeCap:   original.FeeCap,
+		AllowHighFee:     req.AllowHighFee,
+	}
+	if strings.TrimSpace(req.MaxFeeWei) != "" {
+		if opts.MaxFeeWei, err = parseAmountField("max_fee_wei", req.MaxFeeWei, false); err != nil {
+			badInput(c, err)
+			return
+		}
+	}
+	if req.DerivationPath == "" {
+		req.DerivationPath = "m/44'/60'/0'/0/0"
+	}
+	var txHash string
+	if req.SessionID != "" {
+		txHash, err = h.walletService.ReplaceTransactionWithSession(c.Request.Context(), req.SessionID, req.DerivationPath, req.Mode, *newOpts.Nonce, opts)
+	} else if req.Mnemonic != "" {
+		txHash, err = h.walletService.ReplaceTransaction(c.Request.Context(), req.Mnemonic, req.DerivationPath, req.Mode, *newOpts.Nonce, opts)
+	} else {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "需要提供 session_id 或 mnemonic"})
+		return
+	}
+	if err != nil {
+		if errors.Is(err, core.ErrReplacementUnderpriced) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": e.ErrorReplacementUnderpriced, "msg": e.GetMsg(e.ErrorReplacementUnderpriced), "data": err.Error()})
+			return
+		}
+		txSendError(c, http.StatusInternalServerError, e.ErrorTransactionSend, err)
+		return
+	}
+	action := "tx_speed_up"
+	if req.Mode == services.ReplaceModeCancel {
+		action = "tx_cancel"
+	}
+	h.walletService.RecordTxAudit(action, txHash, h.txAuditContext(c, req.SessionID, req.Mnemonic, req.DerivationPath), map[string]interface{}{
+		"nonce":                    req.Nonce,
+		"original_tx_hash":         req.TxHash,
+		"gas_price":                req.GasPrice,
+		"max_priority_fee_per_gas": req.MaxPriorityFeePerGas,
+		"max_fee_per_gas":          req.MaxFeePerGas,
+		"gas_limit":                req.GasLimit,
+	})
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{
+		"tx_hash": txHash, "mode": req.Mode, "nonce": *newOpts.Nonce, "original_tx_hash": req.TxHash,
+	}})
+}
+
+// txAuditContext 构建交易审计上下文，发送地址由会话或助记词按派生路径推导
+func (h *WalletHandler) txAuditContext(c *gin.Context, sessionID, mnemonic, derivationPath string) *services.TxAuditContext {
+	actx := &services.TxAuditContext{
+		SessionID: sessionID,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.GetHeader("User-Agent"),
+		Endpoint:  c.FullPath(),
+	}
+	if actx.SessionID == "" {
+		if userID, ok := c.Get("user_id"); ok {
+			actx.SessionID, _ = userID.(string)
+		}
+	}
+	actx.From = h.senderAddress(sessionID, mnemonic, derivationPath)
+	return actx
+}
+
+// txSendErrorCode 按节点返回的错误识别细分错误码（余额不足、nonce 过低、节点不可用等），无法识别时使用 fallback
+func txSendErrorCode(err error, fallback int) (int, string) {
+	if errors.Is(err, core.ErrFeeTooHigh) {
+		return e.ErrorFeeTooHigh, e.GetMsg(e.ErrorFeeTooHigh)
+	}
+	code, msg := e.ClassifyRPCError(err)
+	if code == e.ERROR {
+		return fallback, e.GetMsg(fallback)
+	}
+	return code, msg
+}
+
+// txSendError 返回交易发送失败响应，code 为识别出的细分错误码，data 保留节点原始错误
+// 手续费超过上限时返回 400 与计算出的手续费，便于用户确认后以 allow_high_fee 重新提交
+func txSendError(c *gin.Context, status, fallback int, err error) {
+	var feeErr *core.FeeTooHighError
+	if errors.As(err, &feeErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.ErrorFeeTooHigh, "msg": e.GetMsg(e.ErrorFeeTooHigh), "data": feeTooHighData(feeErr)})
+		return
+	}
+	code, msg := txSendErrorCode(err, fallback)
+	c.JSON(status, gin.H{"code": code, "msg": msg, "data": err.Error()})
+}
+
+// feeTooHighData 手续费超限的详细信息
+func feeTooHighData(feeErr *core.FeeTooHighError) gin.H {
+	return gin.H{
+		"error":       feeErr.Error(),
+		"fee_wei":     feeErr.Fee.String(),
+		"ceiling_wei": feeErr.Ceiling.String(),
+		"gas_limit":   feeErr.GasLimit,
+		"fee_per_gas": feeErr.FeePerGas.String(),
+	}
+}
+
+// senderAddress 由会话或助记词按派生路径推导发送地址，失败返回空字符串
+func (h *WalletHandler) senderAddress(sessionID, mnemonic, derivationPath string) string {
+	if sessionID != "" {
+		if signer, err := h.walletService.SessionSigner(sessionID, derivationPath); err == nil {
+			return signer.Address().Hex()
+		}
+	}
+	if mnemonic == "" {
+		return ""
+	}
+	from, err := h.walletService.ImportMnemonic(mnemonic, derivationPath)
+	if err != nil {
+		return ""
+	}
+	return from
+}
+
+// txRiskCheck 发送前的交易风险评估与支出限额校验，交易成功后记入用户基线与支出
+type txRiskCheck struct {
+	from     string
+	event    core.SecurityEvent
+	decision *core.RiskDecision
+	spending *services.SpendingCheck
+}
+
+// checkTxRisk 在当前网络发送交易前评估风险并校验支出限额，见 checkTxRiskOnNetwork
+func (h *WalletHandler) checkTxRisk(c *gin.Context, from, token string, value *big.Int, mfaCode string) (*txRiskCheck, bool) {
+	return checkTxRiskOnNetwork(c, h.walletService, h.walletService.GetMultiChainManager().GetCurrentNetwork(), from, token, value, mfaCode)
+}
+
+// withTxRisk 交易发送成功后记录风险事件与支出，见 recordTxRisk
+func (h *WalletHandler) withTxRisk(data gin.H, check *txRiskCheck, txHash string) gin.H {
+	return recordTxRisk(h.walletService, data, check, txHash)
+}
+
+// checkTxRiskOnNetwork 评估在 network 上发送交易的风险并校验支出限额（token 为空表示原生币），被拒绝或需验证码时写入响应并返回 false
+// 通过时已预留支出额度，调用方需 defer releaseTxRisk，发送成功后由 recordTxRisk 确认
+// 风险评估已校验过双因素验证码时，同一验证码同时用于超额发送
+func checkTxRiskOnNetwork(c *gin.Context, walletService *services.WalletService, network, from, token string, value *big.Int, mfaCode string) (*txRiskCheck, bool) {
+	if from == "" {
+		return nil, true
+	}
+	check := &txRiskCheck{from: from}
+	mfaVerified := false
+	if securityService := walletService.GetSecurityService(); securityService != nil {
+		asset := "native"
+		if token != "" {
+			asset = strings.ToLower(token)
+		}
+		check.event = requestSecurityEvent(c, core.SecurityEventTransaction)
+		check.event.Asset = network + ":" + asset
+		check.event.Value = value
+		decision, err := securityService.AuthorizeRiskyAction(c.Request.Context(), from, check.event, mfaCode)
+		if !writeRiskError(c, decision, err) {
+			return nil, false
+		}
+		check.decision = decision
+		mfaVerified = decision.Action == core.RiskActionChallenge && securityService.RequiresMFA(from)
+	}
+
+	spending, err := walletService.CheckSpendingLimit(c.Request.Context(), from, network, token, value)
+	if errors.Is(err, core.ErrSpendingLimitExceeded) {
+		if mfaVerified {
+			err = walletService.OverrideSpendingLimit(spending)
+		} else if walletService.AuthorizeSpendingOverride(spending, mfaCode) {
+			err = nil
+		}
+	}
+	if err != nil {
+		writeSpendingLimitError(c, walletService, from, spending, err)
+		return nil, false
+	}
+	check.spending = spending
+	return check, true
+}
+
+// checkPendingTransfer 对待签名交易（DApp、WalletConnect、多签执行）评估风险并校验发送方的支出限额，见 checkTxRiskOnNetwork
+// transfer 为 nil（非转账类请求）时不检查
+func checkPendingTransfer(c *gin.Context, walletService *services.WalletService, transfer *core.PendingTransfer, mfaCode string) (*txRiskCheck, bool) {
+	if transfer == nil {
+		return nil, true
+	}
+	return checkTxRiskOnNetwork(c, walletService, transfer.NetworkID, transfer.From, transfer.Token, transfer.Amount, mfaCode)
+}
+
+// recordTxRisk 交易发送成功后记录事件（更新金额等基线）与支出，存在风险提示或超额发送时附加到响应数据
+func recordTxRisk(walletService *services.WalletService, data gin.H, check *txRiskCheck, txHash string) gin.H {
+	if check == nil {
+		return data
+	}
+	if check.decision != nil {
+		walletService.GetSecurityService().RecordSecurityEvent(check.from, check.event)
+		if check.decision.Action != core.RiskActionAllow {
+			data["risk"] = check.decision
+		}
+	}
+	if check.spending != nil {
+		walletService.RecordSpending(check.spending, txHash)
+		if check.spending.Override {
+			data["spending_limit"] = check.spending
+		}
+	}
+	return data
+}
+
+// releaseTxRisk 交易未发送时释放支出限额预留的额度，发送成功后调用为空操作
+// 发送处理函数在 checkTxRisk 通过后 defer 调用
+func releaseTxRisk(walletService *services.WalletService, check *txRiskCheck) {
+	if check != nil && check.spending != nil {
+		walletService.ReleaseSpending(check.spending)
+	}
+}
+
+// writeSpendingLimitError 支出限额校验未通过时写入响应
+func writeSpendingLimitError(c *gin.Context, walletService *services.WalletService, from string, spending *services.SpendingCheck, err error) {
+	if !errors.Is(err, core.ErrSpendingLimitExceeded) {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ERROR, "msg": e.GetMsg(e.ERROR), "data": err.Error()})
+		return
+	}
+	securityService := walletService.GetSecurityService()
+	c.JSON(http.StatusForbidden, gin.H{"code": e.ErrorSpendingLimitExceeded, "msg": err.Error(), "data": gin.H{
+		"spending_limit": spending,
+		"mfa_required":   securityService != nil && securityService.RequiresMFA(from),
+	}})
+}
+
+// checkRecipient 发送前检查接收地址是否在黑名单中，见 checkRecipientOnSend
+func (h *WalletHandler) checkRecipient(c *gin.Context, from, to string, confirmed bool) (*services.RecipientCheck, bool) {
+	return checkRecipientOnSend(c, h.walletService, from, to, confirmed)
+}
+
+// checkRecipientOnSend 检查接收地址是否在发送方或全局黑名单中，被拒绝或需确认风险时写入响应并返回 false
+// 地址格式无效时不检查，由后续发送流程报错
+func checkRecipientOnSend(c *gin.Context, walletService *services.WalletService, from, to string, confirmed bool) (*services.RecipientCheck, bool) {
+	securityService := walletService.GetSecurityService()
+	if securityService == nil || core.NormalizeBlockedAddress(to) == "" {
+		return nil, true
+	}
+	check, err := securityService.AuthorizeRecipient(c.Request.Context(), from, to, confirmed, c.FullPath())
+	switch {
+	case errors.Is(err, services.ErrRecipientBlocked):
+		c.JSON(http.StatusForbidden, gin.H{"code": e.ErrorRecipientBlocked, "msg": err.Error(), "data": gin.H{"recipient": check}})
+		return nil, false
+	case errors.Is(err, services.ErrRecipientConfirmRequired):
+		c.JSON(http.StatusConflict, gin.H{"code": e.ErrorRecipientBlocked, "msg": err.Error(), "data": gin.H{"recipient": check, "confirm_required": true}})
+		return nil, false
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ERROR, "msg": e.GetMsg(e.ERROR), "data": err.Error()})
+		return nil, false
+	}
+	return check, true
+}
+
+// withRecipientWarning 确认风险后向黑名单地址发送时，在响应数据中附加命中的黑名单条目
+func withRecipientWarning(data gin.H, check *services.RecipientCheck) gin.H {
+	if check != nil && check.Blocked {
+		data["recipient_warning"] = check
+	}
+	return data
+}
+
+// resolveRecipient 解析收款目标（0x地址、ENS域名或 contact:<ID>），失败时写入400响应
+// 联系人只在当前登录用户（会话所属地址）的地址簿中查找
+func (h *WalletHandler) resolveRecipient(c *gin.Context, sessionID, to string) (*services.RecipientResolution, bool) {
+	owner := h.optionalSessionOwner(c)
+	if owner == "" && sessionID != "" {
+		owner, _ = h.walletService.GetSessionAddress(sessionID)
+	}
+	resolution, err := h.walletService.GetSocialService().ResolveRecipient(c.Request.Context(), owner, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": "收款目标解析失败", "data": err.Error()})
+		return nil, false
+	}
+	return resolution, true
+}
+
+// withRecipient 在发送响应中附加解析后的收款地址，供前端确认
+func withRecipient(data gin.H, resolution *services.RecipientResolution) gin.H {
+	data["resolved_to"] = resolution.Address
+	data["recipient"] = resolution
+	return data
+}
+
+// withReserveWarning 发送后余额低于预留值时在响应数据中附加提醒
+func withReserveWarning(data gin.H, warning *services.BalanceReserveWarning) gin.H {
+	if warning != nil {
+		data["warnings"] = []*services.BalanceReserveWarning{warning}
+	}
+	return data
+}
+
+// GetTransactionLifecycle 查询单笔交易的完整生命周期（仅限发送方本人）
+func (h *WalletHandler) GetTransactionLifecycle(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	sessionID, _ := userID.(string)
+	owner, err := h.walletService.GetSessionAddress(sessionID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": e.ERROR, "msg": "未找到有效会话", "data": err.Error()})
+		return
+	}
+	lifecycle, err := h.walletService.GetTransactionLifecycle(c.Request.Context(), c.Param("hash"), owner)
+	if err != nil {
+		status := http.StatusNotFound
+		if strings.Contains(err.Error(), "无权") {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{"code": e.ERROR, "msg": e.GetMsg(e.ERROR), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": lifecycle})
+}
+
+func (h *WalletHandler) SendERC20Advanced(c *gin.Context) {
+	var req AdvancedERC20SendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	if !h.resolveAccountPath(c, req.SessionID, req.Mnemonic, req.Account, &req.DerivationPath) {
+		return
+	}
+	var err error
+	if req.Token, err = validateAddressField(c, "token", req.Token); err != nil {
+		badInput(c, err)
+		return
+	}
+	if req.To, err = validateRecipientField(c, "to", req.To); err != nil {
+		badInput(c, err)
+		return
+	}
+	amount, err := parseAmountField("amount", req.Amount, false)
+	if err != nil {
+		badInput(c, err)
+		return
+	}
+	opts, err := parseTxOptions(req.GasPrice, req.MaxPriorityFeePerGas, req.MaxFeePerGas, req.GasLimit, req.Nonce)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	if err := applyFeeCeiling(opts, req.MaxFeeWei, req.AllowHighFee); err != nil {
+		badInput(c, err)
+		return
+	}
+	if err := applyAccessList(opts, req.AccessList, req.AutoAccessList); err != nil {
+		badInput(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": "ok"})
-}
-
-// -------- 新增：交易回执 / token元数据 / 签名 --------
-
-func (h *WalletHandler) GetTxReceipt(c *gin.Context) {
-	hash := c.Param("hash")
-	if hash == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "hash 不能为空"})
+	if req.DerivationPath == "" {
+		req.DerivationPath = "m/44'/60'/0'/0/0"
+	}
+	recipient, ok := h.resolveRecipient(c, req.SessionID, req.To)
+	if !ok {
 		return
 	}
-	dto, err := h.walletService.GetReceipt(hash)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorTransactionBuild, "msg": e.GetMsg(e.ErrorTransactionBuild), "data": err.Error()})
+	req.To = recipient.Address
+	from := h.senderAddress(req.SessionID, req.Mnemonic, req.DerivationPath)
+	blocked, ok := h.checkRecipient(c, from, req.To, req.ConfirmRecipient)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": dto})
-}
-
-func (h *WalletHandler) GetTokenMetadata(c *gin.Context) {
-	token := c.Param("token")
-	if token == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "token 地址不能为空"})
+	risk, ok := h.checkTxRisk(c, from, req.Token, amount, req.MFACode)
+	if !ok {
 		return
 	}
-	name, symbol, decimals, err := h.walletService.GetTokenMetadata(token)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorGetBalance, "msg": e.GetMsg(e.ErrorGetBalance), "data": err.Error()})
+	defer releaseTxRisk(h.walletService, risk)
+	if req.Simulate && from != "" {
+		result, err := h.walletService.SimulateERC20Transfer(c.Request.Context(), from, req.Token, req.To, amount)
+		if h.abortIfSimulationFails(c, result, err) {
+			return
+		}
+	}
+	var txHash string
+	if req.SessionID != "" {
+		txHash, err = h.walletService.SendERC20AdvancedWithSession(c.Request.Context(), req.SessionID, req.DerivationPath, req.Token, req.To, amount, opts)
+	} else if req.Mnemonic != "" {
+		txHash, err = h.walletService.SendERC20Advanced(c.Request.Context(), req.Mnemonic, req.DerivationPath, req.Token, req.To, amount, opts)
+	} else {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "需要提供 session_id 或 mnemonic"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{
-		"name": name, "symbol": symbol, "decimals": decimals,
-	}})
+	if err != nil {
+		txSendError(c, http.StatusInternalServerError, e.ErrorTransactionSend, err)
+		return
+	}
+	h.walletService.RecordTxAudit("tx_send_erc20", txHash, h.txAuditContext(c, req.SessionID, req.Mnemonic, req.DerivationPath), map[string]interface{}{
+		"token":                    req.Token,
+		"to":                       req.To,
+		"amount":                   req.Amount,
+		"gas_price":                req.GasPrice,
+		"max_priority_fee_per_gas": req.MaxPriorityFeePerGas,
+		"max_fee_per_gas":          req.MaxFeePerGas,
+		"gas_limit":                req.GasLimit,
+		"nonce":                    req.Nonce,
+	})
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": withInputWarnings(c, h.withTxRisk(withRecipientWarning(withRecipient(gin.H{"tx_hash": txHash}, recipient), blocked), risk, txHash))})
 }
 
-type SendTransactionAdvanced struct {
-	SessionID      string `json:"session_id"`
-	Mnemonic       string `json:"mnemonic"`
-	DerivationPath string `json:"derivation_path"`
-	To             string `json:"to" binding:"required"`
-	ValueWei       string `json:"value_wei" binding:"required"`
-
-	// gas & nonce（十进制字符串）
-	GasPrice             string `json:"gas_price"`                // legacy
-	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas"` // EIP-1559
-	MaxFeePerGas         string `json:"max_fee_per_gas"`          // EIP-1559
-	GasLimit             string `json:"gas_limit"`                // 可选
-	Nonce                string `json:"nonce"`                    // 可选
+// ContractCallRequest 按ABI调用合约只读方法
+type ContractCallRequest struct {
+	ABI    json.RawMessage `json:"abi" binding:"required"`    // 合约ABI（JSON数组或其字符串形式）
+	Method string          `json:"method" binding:"required"` // 方法名（重载方法为 foo0、foo1…）
+	Args   json.RawMessage `json:"args"`                      // 参数JSON数组，按ABI类型转换
 }
 
-// AdvancedERC20SendRequest 高级 ERC20 发送
-type AdvancedERC20SendRequest struct {
+// ContractSendRequest 按ABI发送合约写入交易
+type ContractSendRequest struct {
+	ContractCallRequest
 	SessionID      string `json:"session_id"`
 	Mnemonic       string `json:"mnemonic"`
 	DerivationPath string `json:"derivation_path"`
-	Token          string `json:"token" binding:"required"`
-	To             string `json:"to" binding:"required"`
-	Amount         string `json:"amount" binding:"required"`
+	Account        *int   `json:"account"`   // 账户序号（可选，代替 derivation_path）
+	ValueWei       string `json:"value_wei"` // 可选，payable 方法附带的原生代币
+	MFACode        string `json:"mfa_code"`  // 交易被判定为异常或超出支出限额时需提交的双因素验证码
+	// 合约地址在黑名单中且处理方式为 warn 时，确认风险后置为 true 重新提交
+	ConfirmRecipient bool `json:"confirm_recipient"`
 
 	GasPrice             string `json:"gas_price"`
 	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas"`
 	MaxFeePerGas         string `json:"max_fee_per_gas"`
 	GasLimit             string `json:"gas_limit"`
 	Nonce                string `json:"nonce"`
+
+	// EIP-2930 访问列表；auto_access_list 为 true 时发送前自动生成（仅在节省 Gas 时使用）
+	AccessList     []AccessListItem `json:"access_list"`
+	AutoAccessList bool             `json:"auto_access_list"`
 }
 
-// ApproveRequest 授权
-type ApproveRequest struct {
-	SessionID      string `json:"session_id"`
-	Mnemonic       string `json:"mnemonic"`
-	DerivationPath string `json:"derivation_path"`
-	Spender        string `json:"spender" binding:"required"`
-	Amount         string `json:"amount" binding:"required"`
+// parseContractRequest 解析ABI与参数（参数中的数字保持精度）
+func parseContractRequest(req *ContractCallRequest) (string, []interface{}, error) {
+	abiJSON := string(req.ABI)
+	var abiStr string
+	if err := json.Unmarshal(req.ABI, &abiStr); err == nil {
+		abiJSON = abiStr
+	}
+	args := []interface{}{}
+	if len(req.Args) > 0 && string(req.Args) != "null" {
+		dec := json.NewDecoder(strings.NewReader(string(req.Args)))
+		dec.UseNumber()
+		if err := dec.Decode(&args); err != nil {
+			return "", nil, fmt.Errorf("args 需要是JSON数组: %w", err)
+		}
+	}
+	return abiJSON, args, nil
+}
 
-	GasPrice             string `json:"gas_price"`
-	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas"`
-	MaxFeePerGas         string `json:"max_fee_per_gas"`
-	GasLimit             string `json:"gas_limit"`
-	Nonce                string `json:"nonce"`
+// CallContractMethod 按ABI调用合约只读方法
+// POST /api/v1/contracts/:address/call
+func (h *WalletHandler) CallContractMethod(c *gin.Context) {
+	var req ContractCallRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	abiJSON, args, err := parseContractRequest(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	outputs, err := h.walletService.CallContractMethod(c.Request.Context(), c.Param("address"), abiJSON, req.Method, args)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorContractCall, "msg": e.GetMsg(e.ErrorContractCall), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"outputs": outputs}})
 }
 
-func (h *WalletHandler) SendTransactionAdvanced(c *gin.Context) {
-	var req SendTransactionAdvanced
+// SendContractMethod 按ABI发送合约写入交易
+// POST /api/v1/contracts/:address/send
+func (h *WalletHandler) SendContractMethod(c *gin.Context) {
+	var req ContractSendRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
 		return
 	}
-	val := new(big.Int)
-	if _, ok := val.SetString(req.ValueWei, 10); !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "value_wei 需要十进制字符串"})
+	if req.SessionID == "" && req.Mnemonic == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "需要提供 session_id 或 mnemonic"})
+		return
+	}
+	if !h.resolveAccountPath(c, req.SessionID, req.Mnemonic, req.Account, &req.DerivationPath) {
+		return
+	}
+	abiJSON, args, err := parseContractRequest(&req.ContractCallRequest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
 		return
 	}
+	value := big.NewInt(0)
+	if req.ValueWei != "" {
+		if _, ok := value.SetString(req.ValueWei, 10); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "value_wei 需要十进制字符串"})
+			return
+		}
+	}
 	opts, err := parseTxOptions(req.GasPrice, req.MaxPriorityFeePerGas, req.MaxFeePerGas, req.GasLimit, req.Nonce)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
 		return
 	}
+	if err := applyAccessList(opts, req.AccessList, req.AutoAccessList); err != nil {
+		badInput(c, err)
+		return
+	}
 	if req.DerivationPath == "" {
 		req.DerivationPath = "m/44'/60'/0'/0/0"
 	}
-	var (
-		txHash string
-	)
-	if req.SessionID != "" {
-		txHash, err = h.walletService.SendETHAdvancedWithSession(req.SessionID, req.DerivationPath, req.To, val, opts)
-	} else if req.Mnemonic != "" {
-		txHash, err = h.walletService.SendETHAdvanced(req.Mnemonic, req.DerivationPath, req.To, val, opts)
-	} else {
-		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "需要提供 session_id 或 mnemonic"})
+
+	contract := c.Param("address")
+	from := h.senderAddress(req.SessionID, req.Mnemonic, req.DerivationPath)
+	blocked, ok := h.checkRecipient(c, from, contract, req.ConfirmRecipient)
+	if !ok {
 		return
 	}
+	risk, ok := h.checkTxRisk(c, from, "", value, req.MFACode)
+	if !ok {
+		return
+	}
+	defer releaseTxRisk(h.walletService, risk)
+
+	txHash, err := h.walletService.SendContractMethod(c.Request.Context(), req.SessionID, req.Mnemonic, req.DerivationPath, contract, abiJSON, req.Method, args, value, opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorTransactionSend, "msg": e.GetMsg(e.ErrorTransactionSend), "data": err.Error()})
+		txSendError(c, http.StatusInternalServerError, e.ErrorTransactionSend, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"tx_hash": txHash}})
+	h.walletService.RecordTxAudit("tx_contract_call", txHash, h.txAuditContext(c, req.SessionID, req.Mnemonic, req.DerivationPath), map[string]interface{}{
+		"contract":  contract,
+		"method":    req.Method,
+		"args":      args,
+		"value_wei": value.String(),
+	})
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": h.withTxRisk(withRecipientWarning(gin.H{"tx_hash": txHash}, blocked), risk, txHash)})
 }
 
-func (h *WalletHandler) SendERC20Advanced(c *gin.Context) {
-	var req AdvancedERC20SendRequest
+// PermitRequest EIP-2612 permit 签名请求
+type PermitRequest struct {
+	SessionID      string `json:"session_id"`
+	Mnemonic       string `json:"mnemonic"`
+	DerivationPath string `json:"derivation_path"`
+	Spender        string `json:"spender" binding:"required"`
+	Value          string `json:"value" binding:"required"` // 授权额度（最小单位，十进制字符串）
+	Deadline       int64  `json:"deadline"`                 // 签名截止时间（Unix 秒），默认30分钟后
+}
+
+// defaultPermitValidity permit 签名默认有效期
+const defaultPermitValidity = 30 * time.Minute
+
+// SignPermit 签署 EIP-2612 permit，免 Gas 授权
+// POST /api/v1/tokens/:token/permit
+// 返回 v/r/s 签名与 permit() 调用数据，由花费方或中继提交上链；代币不支持 permit 时返回明确错误
+func (h *WalletHandler) SignPermit(c *gin.Context) {
+	token := c.Param("token")
+	var req PermitRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
 		return
 	}
-	amount := new(big.Int)
-	if _, ok := amount.SetString(req.Amount, 10); !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "amount 需要十进制字符串"})
+	value := new(big.Int)
+	if _, ok := value.SetString(req.Value, 10); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "value 需要十进制字符串"})
 		return
 	}
-	opts, err := parseTxOptions(req.GasPrice, req.MaxPriorityFeePerGas, req.MaxFeePerGas, req.GasLimit, req.Nonce)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+	if req.SessionID == "" && req.Mnemonic == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "需要提供 session_id 或 mnemonic"})
 		return
 	}
-	if req.DerivationPath == "" {
-		req.DerivationPath = "m/44'/60'/0'/0/0"
+	if req.Deadline == 0 {
+		req.Deadline = time.Now().Add(defaultPermitValidity).Unix()
+	} else if req.Deadline <= time.Now().Unix() {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "deadline 已过期"})
+		return
 	}
-	var txHash string
-	if req.SessionID != "" {
-		txHash, err = h.walletService.SendERC20AdvancedWithSession(req.SessionID, req.DerivationPath, req.Token, req.To, amount, opts)
-	} else if req.Mnemonic != "" {
-		txHash, err = h.walletService.SendERC20Advanced(req.Mnemonic, req.DerivationPath, req.Token, req.To, amount, opts)
-	} else {
-		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "需要提供 session_id 或 mnemonic"})
+
+	permit, err := h.walletService.SignPermit(c.Request.Context(), req.SessionID, req.Mnemonic, req.DerivationPath, token, req.Spender, value, req.Deadline)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, core.ErrPermitNotSupported) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"code": e.ErrorContractCall, "msg": e.GetMsg(e.ErrorContractCall), "data": err.Error()})
 		return
 	}
+	callData, err := core.PermitCallData(permit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorTransactionSend, "msg": e.GetMsg(e.ErrorTransactionSend), "data": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorContractCall, "msg": e.GetMsg(e.ErrorContractCall), "data": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"tx_hash": txHash}})
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{
+		"permit":          permit,
+		"permit_calldata": hexutil.Encode(callData),
+	}})
 }
 
 // ApproveToken 授权
@@ -769,9 +2540,19 @@ func (h *WalletHandler) ApproveToken(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
 		return
 	}
-	amt := new(big.Int)
-	if _, ok := amt.SetString(req.Amount, 10); !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "amount 需要十进制字符串"})
+	var err error
+	if token, err = validateAddressField(c, "token", token); err != nil {
+		badInput(c, err)
+		return
+	}
+	if req.Spender, err = validateAddressField(c, "spender", req.Spender); err != nil {
+		badInput(c, err)
+		return
+	}
+	// 授权额度为0用于撤销授权
+	amt, err := parseAmountField("amount", req.Amount, true)
+	if err != nil {
+		badInput(c, err)
 		return
 	}
 	opts, err := parseTxOptions(req.GasPrice, req.MaxPriorityFeePerGas, req.MaxFeePerGas, req.GasLimit, req.Nonce)
@@ -779,23 +2560,37 @@ func (h *WalletHandler) ApproveToken(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
 		return
 	}
+	if err := applyFeeCeiling(opts, req.MaxFeeWei, req.AllowHighFee); err != nil {
+		badInput(c, err)
+		return
+	}
 	if req.DerivationPath == "" {
 		req.DerivationPath = "m/44'/60'/0'/0/0"
 	}
 	var txHash string
 	if req.SessionID != "" {
-		txHash, err = h.walletService.ApproveTokenWithSession(req.SessionID, req.DerivationPath, token, req.Spender, amt, opts)
+		txHash, err = h.walletService.ApproveTokenWithSession(c.Request.Context(), req.SessionID, req.DerivationPath, token, req.Spender, amt, opts)
 	} else if req.Mnemonic != "" {
-		txHash, err = h.walletService.ApproveToken(req.Mnemonic, req.DerivationPath, token, req.Spender, amt, opts)
+		txHash, err = h.walletService.ApproveToken(c.Request.Context(), req.Mnemonic, req.DerivationPath, token, req.Spender, amt, opts)
 	} else {
 		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "需要提供 session_id 或 mnemonic"})
 		return
 	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorTransactionSend, "msg": e.GetMsg(e.ErrorTransactionSend), "data": err.Error()})
-		return
-	}
-	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{"tx_hash": txHash}})
+		txSendError(c, http.StatusInternalServerError, e.ErrorTransactionSend, err)
+		return
+	}
+	h.walletService.RecordTxAudit("tx_approve", txHash, h.txAuditContext(c, req.SessionID, req.Mnemonic, req.DerivationPath), map[string]interface{}{
+		"token":                    token,
+		"spender":                  req.Spender,
+		"amount":                   req.Amount,
+		"gas_price":                req.GasPrice,
+		"max_priority_fee_per_gas": req.MaxPriorityFeePerGas,
+		"max_fee_per_gas":          req.MaxFeePerGas,
+		"gas_limit":                req.GasLimit,
+		"nonce":                    req.Nonce,
+	})
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": withInputWarnings(c, gin.H{"tx_hash": txHash})})
 }
 
 // GetAllowance 查询授权额度
@@ -807,7 +2602,7 @@ func (h *WalletHandler) GetAllowance(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "token/owner/spender 不能为空"})
 		return
 	}
-	val, err := h.walletService.GetAllowance(token, owner, spender)
+	val, err := h.walletService.GetAllowance(c.Request.Context(), token, owner, spender)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorContractCall, "msg": e.GetMsg(e.ErrorContractCall), "data": err.Error()})
 		return
@@ -864,6 +2659,20 @@ func parseTxOptions(gasPrice, tip, fee, gasLimit, nonce string) (*services.TxOpt
 	return opts, nil
 }
 
+// applyFeeCeiling 设置本笔交易的手续费上限（wei）与高手续费确认
+func applyFeeCeiling(opts *services.TxOptions, maxFeeWei string, allowHighFee bool) error {
+	opts.AllowHighFee = allowHighFee
+	if strings.TrimSpace(maxFeeWei) == "" {
+		return nil
+	}
+	ceiling, err := parseAmountField("max_fee_wei", maxFeeWei, false)
+	if err != nil {
+		return err
+	}
+	opts.MaxFeeWei = ceiling
+	return nil
+}
+
 type SignMessageRequest struct {
 	Mnemonic       string `json:"mnemonic" binding:"required"`
 	DerivationPath string `json:"derivation_path"` // 默认 m/44'/60'/0'/0/0
@@ -879,7 +2688,7 @@ func (h *WalletHandler) PersonalSign(c *gin.Context) {
 	if req.DerivationPath == "" {
 		req.DerivationPath = "m/44'/60'/0'/0/0"
 	}
-	sig, addr, err := h.walletService.PersonalSign(req.Mnemonic, req.DerivationPath, req.Message)
+	sig, addr, err := h.walletService.PersonalSign(c.Request.Context(), req.Mnemonic, req.DerivationPath, req.Message)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorTransactionBuild, "msg": e.GetMsg(e.ErrorTransactionBuild), "data": err.Error()})
 		return
@@ -904,7 +2713,7 @@ func (h *WalletHandler) SignTypedDataV4(c *gin.Context) {
 	if req.DerivationPath == "" {
 		req.DerivationPath = "m/44'/60'/0'/0/0"
 	}
-	sig, addr, err := h.walletService.SignTypedDataV4(req.Mnemonic, req.DerivationPath, req.TypedData)
+	sig, addr, err := h.walletService.SignTypedDataV4(c.Request.Context(), req.Mnemonic, req.DerivationPath, req.TypedData)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"code": e.ErrorTransactionBuild, "msg": e.GetMsg(e.ErrorTransactionBuild), "data": err.Error()})
 		return
@@ -914,6 +2723,59 @@ func (h *WalletHandler) SignTypedDataV4(c *gin.Context) {
 	}})
 }
 
+// VerifySignatureRequest 签名验证请求
+type VerifySignatureRequest struct {
+	Mode      string          `json:"mode"`                         // personal（默认）或 typed
+	Message   string          `json:"message"`                      // personal 模式的原始消息
+	TypedData json.RawMessage `json:"typed_data"`                   // typed 模式的完整 EIP-712 JSON
+	Signature string          `json:"signature" binding:"required"` // 65字节签名（v 可为 27/28 或 0/1）
+	Address   string          `json:"address" binding:"required"`   // 期望的签名者地址
+}
+
+// VerifySignature 验证 personal_sign 或 EIP-712 签名
+// 签名者不匹配时仍返回 200，valid 为 false 并附带恢复出的地址
+func (h *WalletHandler) VerifySignature(c *gin.Context) {
+	var req VerifySignatureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	if !common.IsHexAddress(req.Address) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.ErrorWalletAddressInvalid, "msg": e.GetMsg(e.ErrorWalletAddressInvalid), "data": req.Address})
+		return
+	}
+
+	var (
+		valid     bool
+		recovered string
+		err       error
+	)
+	switch req.Mode {
+	case "", "personal":
+		req.Mode = "personal"
+		valid, recovered, err = h.walletService.VerifyPersonalSign(req.Message, req.Signature, req.Address)
+	case "typed":
+		if len(req.TypedData) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "typed 模式需要提供 typed_data"})
+			return
+		}
+		valid, recovered, err = h.walletService.VerifyTypedDataV4(req.TypedData, req.Signature, req.Address)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "mode 仅支持 personal 或 typed"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.ErrorSignatureVerify, "msg": e.GetMsg(e.ErrorSignatureVerify), "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": gin.H{
+		"mode":              req.Mode,
+		"valid":             valid,
+		"recovered_address": recovered,
+		"expected_address":  common.HexToAddress(req.Address).Hex(),
+	}})
+}
+
 // GetTransactionHistory 获取交易历史
 func (h *WalletHandler) GetTransactionHistory(c *gin.Context) {
 	address := c.Param("address")
@@ -980,7 +2842,7 @@ func (h *WalletHandler) GetTransactionHistory(c *gin.Context) {
 	}
 
 	// 查询交易历史
-	resp, err := h.walletService.GetTransactionHistory(req)
+	resp, err := h.walletService.GetTransactionHistory(c.Request.Context(), req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": e.ErrorGetBalance,
@@ -997,14 +2859,239 @@ func (h *WalletHandler) GetTransactionHistory(c *gin.Context) {
 	})
 }
 
+// ExportTransactionHistory 流式导出交易历史
+// GET /api/v1/wallets/:address/history/export?format=csv|json&start_block=&end_block=&tx_type=
+// 使用分块传输边扫描边输出，不在内存中缓存完整结果；客户端断开时取消底层区块扫描
+func (h *WalletHandler) ExportTransactionHistory(c *gin.Context) {
+	req, format, ok := parseHistoryExportParams(c, c.Param("address"))
+	if !ok {
+		return
+	}
+
+	// 客户端断开时 Request.Context 会被取消，扫描随之停止
+	ctx := c.Request.Context()
+	setExportHeaders(c, fmt.Sprintf("history_%s.%s", strings.ToLower(req.Address), format))
+
+	header := []string{"hash", "block_number", "timestamp", "from", "to", "value", "tx_type", "status", "gas_used", "gas_price", "token_address", "token_symbol", "token_amount", "summary"}
+	row := func(tx core.TransactionInfo) []string {
+		tokenAddr, tokenSymbol, tokenAmount := "", "", ""
+		if tx.TokenInfo != nil {
+			tokenAddr, tokenSymbol, tokenAmount = tx.TokenInfo.TokenAddress, tx.TokenInfo.TokenSymbol, tx.TokenInfo.Amount
+		}
+		return []string{
+			tx.Hash, tx.BlockNumber, strconv.FormatUint(tx.Timestamp, 10), tx.From, tx.To, tx.Value,
+			tx.TxType, strconv.FormatUint(tx.Status, 10), tx.GasUsed, tx.GasPrice, tokenAddr, tokenSymbol, tokenAmount, tx.Summary,
+		}
+	}
+	err := streamExport(c, format, header, row, func(emit func(core.TransactionInfo) error) error {
+		return h.walletService.ExportTransactionHistory(ctx, req, emit)
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "transaction history export interrupted", "address", req.Address, "error", err)
+	}
+}
+
+// parseHistoryExportParams 解析交易历史导出参数（tx_type、start_block、end_block、format），失败时已写入响应
+func parseHistoryExportParams(c *gin.Context, address string) (*core.TransactionHistoryRequest, string, bool) {
+	req := &core.TransactionHistoryRequest{
+		Address: address,
+		TxType:  "all",
+	}
+	if !common.IsHexAddress(req.Address) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.ErrorWalletAddressInvalid, "msg": e.GetMsg(e.ErrorWalletAddressInvalid), "data": req.Address})
+		return nil, "", false
+	}
+	if txType := c.Query("tx_type"); txType == "ETH" || txType == "ERC20" || txType == "CONTRACT" {
+		req.TxType = txType
+	}
+	if v := c.Query("start_block"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "start_block 需要十进制整数"})
+			return nil, "", false
+		}
+		req.StartBlock = n
+	}
+	if v := c.Query("end_block"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "end_block 需要十进制整数"})
+			return nil, "", false
+		}
+		req.EndBlock = n
+	}
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "format 仅支持 csv 或 json"})
+		return nil, "", false
+	}
+	return req, format, true
+}
+
+// setExportHeaders 设置文件下载响应头
+func setExportHeaders(c *gin.Context, filename string) {
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Content-Type-Options", "nosniff")
+}
+
+// streamExport 以分块传输输出导出结果，不在内存中缓存完整结果：
+// CSV 时先写表头，每条记录经 row 转为一行；JSON 时输出对象数组。
+// run 对每条记录调用 emit；返回错误时响应头已发送，无法再修改状态码，不补全结尾，让客户端感知导出不完整
+func streamExport[T any](c *gin.Context, format string, header []string, row func(T) []string, run func(emit func(T) error) error) error {
+	var (
+		emit   func(T) error
+		finish func()
+		w      = c.Writer
+	)
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		cw := csv.NewWriter(w)
+		_ = cw.Write(header)
+		emit = func(item T) error {
+			if err := cw.Write(row(item)); err != nil {
+				return err
+			}
+			cw.Flush()
+			w.Flush()
+			return cw.Error()
+		}
+		finish = func() { cw.Flush(); w.Flush() }
+	} else {
+		c.Header("Content-Type", "application/json; charset=utf-8")
+		first := true
+		_, _ = w.WriteString("[")
+		emit = func(item T) error {
+			b, err := json.Marshal(item)
+			if err != nil {
+				return err
+			}
+			if !first {
+				if _, err := w.WriteString(","); err != nil {
+					return err
+				}
+			}
+			first = false
+			if _, err := w.Write(b); err != nil {
+				return err
+			}
+			w.Flush()
+			return nil
+		}
+		finish = func() { _, _ = w.WriteString("]"); w.Flush() }
+	}
+	c.Status(http.StatusOK)
+	w.Flush()
+
+	if err := run(emit); err != nil {
+		return err
+	}
+	finish()
+	return nil
+}
+
+// TxNoteRequest 交易备注请求
+type TxNoteRequest struct {
+	Network  string `json:"network"` // 为空时使用当前网络
+	Note     string `json:"note"`
+	Category string `json:"category"`
+}
+
+// SetTxNote 设置交易备注与分类（已存在则覆盖）
+// PUT /api/v1/transactions/:hash/note
+func (h *WalletHandler) SetTxNote(c *gin.Context) {
+	var req TxNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	entry, err := h.walletService.SetTxNote(owner, req.Network, c.Param("hash"), req.Note, req.Category)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.ERROR, "msg": err.Error(), "data": nil})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": entry})
+}
+
+// GetTxNote 查询交易备注（?network=）
+// GET /api/v1/transactions/:hash/note
+func (h *WalletHandler) GetTxNote(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	entry, err := h.walletService.GetTxNote(owner, c.Query("network"), c.Param("hash"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": e.ERROR, "msg": err.Error(), "data": nil})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": entry})
+}
+
+// DeleteTxNote 删除交易备注（?network=）
+// DELETE /api/v1/transactions/:hash/note
+func (h *WalletHandler) DeleteTxNote(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	if err := h.walletService.DeleteTxNote(owner, c.Query("network"), c.Param("hash")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": e.ERROR, "msg": err.Error(), "data": nil})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": nil})
+}
+
+// ExportTransactions 流式导出交易历史并合并用户备注（对账用）
+// GET /api/v1/transactions/export?address=0x...&format=csv|json[&tx_type=&start_block=&end_block=]
+// CSV 列：date, hash, from, to, value, token, fee, note, category
+func (h *WalletHandler) ExportTransactions(c *gin.Context) {
+	req, format, ok := parseHistoryExportParams(c, c.Query("address"))
+	if !ok {
+		return
+	}
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	nativeSymbol := h.walletService.GetNativeCurrency().Symbol
+
+	ctx := c.Request.Context()
+	setExportHeaders(c, fmt.Sprintf("transactions_%s.%s", strings.ToLower(req.Address), format))
+
+	header := []string{"date", "hash", "from", "to", "value", "token", "fee", "note", "category"}
+	row := func(tx services.AnnotatedTransaction) []string {
+		date := ""
+		if tx.Timestamp > 0 {
+			date = time.Unix(int64(tx.Timestamp), 0).UTC().Format(time.RFC3339)
+		}
+		value, token := tx.Value, nativeSymbol
+		if tx.TokenInfo != nil {
+			value, token = tx.TokenInfo.Amount, tx.TokenInfo.TokenSymbol
+		}
+		return []string{date, tx.Hash, tx.From, tx.To, value, token, tx.Fee, tx.Note, tx.Category}
+	}
+	err := streamExport(c, format, header, row, func(emit func(services.AnnotatedTransaction) error) error {
+		return h.walletService.ExportAnnotatedHistory(ctx, owner, req, emit)
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "transaction export interrupted", "address", req.Address, "error", err)
+	}
+}
+
 // CreateWalletRequest 创建钱包的请求参数
 type CreateWalletRequest struct {
-	Name string `json:"name"` // 钱包名称（可选）
+	Name      string `json:"name"`       // 钱包名称（可选）
+	WordCount int    `json:"word_count"` // 助记词单词数量（可选，12/15/18/21/24，默认12）
 }
 
 // CreateWallet godoc
 // @Summary      Create a new wallet
-// @Description  Generates a new 12-word mnemonic and derives the first address.
+// @Description  Generates a new mnemonic (12 words by default, or word_count of 12/15/18/21/24) and derives the first address.
 // @Tags         Wallets
 // @Accept       json
 // @Produce      json
@@ -1018,7 +3105,15 @@ func (h *WalletHandler) CreateWallet(c *gin.Context) {
 		req.Name = "My Wallet"
 	}
 
-	mnemonic, address, err := h.walletService.CreateNewWallet()
+	mnemonic, address, err := h.walletService.CreateNewWallet(req.WordCount)
+	if errors.Is(err, core.ErrMnemonicWordCount) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.ErrorMnemonicWordCount,
+			"msg":  e.GetMsg(e.ErrorMnemonicWordCount),
+			"data": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": e.ErrorWalletImport,
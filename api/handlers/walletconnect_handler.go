@@ -0,0 +1,211 @@
+/*
+WalletConnect v2 API处理器
+
+使用 DApp 展示的 wc: 配对 URI 与 DApp 建立会话，账户为当前会话钱包；DApp 发起的签名与交易请求
+进入与 DApp 浏览器相同的确认流程，确认或拒绝后结果经中继回复给 DApp：
+- POST   /api/v1/walletconnect/pair - 配对并响应会话提议（30秒内未收到提议时返回202，会话在后台建立）
+- GET    /api/v1/walletconnect/sessions - 当前钱包的有效会话
+- DELETE /api/v1/walletconnect/sessions/:topic - 断开会话
+- GET    /api/v1/walletconnect/requests - 待确认的请求
+- POST   /api/v1/walletconnect/requests/:id/approve - 确认并执行请求（使用当前钱包会话签名，发送交易前检查接收地址黑名单、评估风险并校验支出限额）
+- POST   /api/v1/walletconnect/requests/:id/reject - 拒绝请求
+未配置 walletconnect.project_id 时返回503。
+*/
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"wallet/core"
+	"wallet/pkg/e"
+	"wallet/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WalletConnectHandler WalletConnect API处理器
+type WalletConnectHandler struct {
+	walletService *services.WalletService
+	walletConnect *services.WalletConnectService
+}
+
+// NewWalletConnectHandler 创建 WalletConnect 处理器
+func NewWalletConnectHandler(walletService *services.WalletService) *WalletConnectHandler {
+	return &WalletConnectHandler{
+		walletService: walletService,
+		walletConnect: walletService.GetWalletConnectService(),
+	}
+}
+
+// WalletConnectPairRequest 配对请求
+type WalletConnectPairRequest struct {
+	URI string `json:"uri" binding:"required"` // wc:{topic}@2?relay-protocol=irn&symKey=...
+}
+
+// WalletConnectResolveRequest 确认请求
+type WalletConnectResolveRequest struct {
+	DerivationPath string `json:"derivation_path"` // 签名账户派生路径，默认 m/44'/60'/0'/0/0
+	MFACode        string `json:"mfa_code"`        // 交易被判定为异常或超出支出限额时需提交的双因素验证码
+	// 接收地址在黑名单中且处理方式为 warn 时，确认风险后置为 true 重新提交
+	ConfirmRecipient bool `json:"confirm_recipient"`
+}
+
+// Pair 使用配对 URI 与 DApp 配对
+// POST /api/v1/walletconnect/pair
+func (h *WalletConnectHandler) Pair(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	var req WalletConnectPairRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+		return
+	}
+	session, err := h.walletConnect.Pair(c.Request.Context(), req.URI, owner)
+	if errors.Is(err, core.ErrWCProposalPending) {
+		c.JSON(http.StatusAccepted, gin.H{"code": e.SUCCESS, "msg": err.Error(), "data": gin.H{"status": "pending"}})
+		return
+	}
+	if err != nil {
+		writeWalletConnectError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": session})
+}
+
+// ListSessions 当前钱包的有效会话
+// GET /api/v1/walletconnect/sessions
+func (h *WalletConnectHandler) ListSessions(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	sessions, err := h.walletConnect.ListSessions(owner)
+	if err != nil {
+		writeWalletConnectError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": sessions})
+}
+
+// Disconnect 断开会话
+// DELETE /api/v1/walletconnect/sessions/:topic
+func (h *WalletConnectHandler) Disconnect(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	if err := h.walletConnect.Disconnect(c.Request.Context(), owner, c.Param("topic")); err != nil {
+		writeWalletConnectError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": nil})
+}
+
+// ListRequests 待确认的请求
+// GET /api/v1/walletconnect/requests
+func (h *WalletConnectHandler) ListRequests(c *gin.Context) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": h.walletConnect.ListRequests(owner)})
+}
+
+// ApproveRequest 确认并执行请求
+// POST /api/v1/walletconnect/requests/:id/approve
+func (h *WalletConnectHandler) ApproveRequest(c *gin.Context) {
+	h.resolveRequest(c, true)
+}
+
+// RejectRequest 拒绝请求
+// POST /api/v1/walletconnect/requests/:id/reject
+func (h *WalletConnectHandler) RejectRequest(c *gin.Context) {
+	h.resolveRequest(c, false)
+}
+
+func (h *WalletConnectHandler) resolveRequest(c *gin.Context, approved bool) {
+	owner, ok := h.sessionOwner(c)
+	if !ok {
+		return
+	}
+	var req WalletConnectResolveRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+			return
+		}
+	}
+
+	// 确认发送交易前检查接收地址黑名单、评估风险并校验支出限额（与普通发送相同）
+	// 未通过时拒绝本次确认、不签名，请求保留待用户补充验证码或确认风险后重试，或直接拒绝
+	var risk *txRiskCheck
+	var blocked *services.RecipientCheck
+	if approved {
+		transfer, err := h.walletConnect.PendingTransaction(owner, c.Param("id"))
+		if err != nil {
+			writeWalletConnectError(c, err)
+			return
+		}
+		if transfer != nil {
+			if blocked, ok = checkRecipientOnSend(c, h.walletService, transfer.From, transfer.Recipient, req.ConfirmRecipient); !ok {
+				return
+			}
+		}
+		if risk, ok = checkPendingTransfer(c, h.walletService, transfer, req.MFACode); !ok {
+			return
+		}
+		defer releaseTxRisk(h.walletService, risk)
+	}
+
+	userID, _ := c.Get("user_id")
+	walletSessionID, _ := userID.(string)
+	result, err := h.walletConnect.ResolveRequest(c.Request.Context(), walletSessionID, owner, c.Param("id"), strings.TrimSpace(req.DerivationPath), approved)
+	if err != nil {
+		writeWalletConnectError(c, err)
+		return
+	}
+	data := gin.H{
+		"request_id": result.ID,
+		"approved":   approved,
+		"status":     result.Status,
+		"result":     result.Response,
+		"error":      result.Error,
+	}
+	data = withRecipientWarning(data, blocked)
+	if txHash, ok := result.Response.(string); ok && risk != nil {
+		data = recordTxRisk(h.walletService, data, risk, txHash)
+	}
+	c.JSON(http.StatusOK, gin.H{"code": e.SUCCESS, "msg": e.GetMsg(e.SUCCESS), "data": data})
+}
+
+// sessionOwner 当前会话的钱包地址，会话无效时写入401响应
+func (h *WalletConnectHandler) sessionOwner(c *gin.Context) (string, bool) {
+	userID, _ := c.Get("user_id")
+	sessionID, _ := userID.(string)
+	owner, err := h.walletService.GetSessionAddress(sessionID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": e.ErrorAuth, "msg": "会话无效或已过期", "data": err.Error()})
+		return "", false
+	}
+	return owner, true
+}
+
+// writeWalletConnectError 未启用返回503，请求不存在返回404，钓鱼域名或接收地址在黑名单中返回403，其余为400
+func writeWalletConnectError(c *gin.Context, err error) {
+	var blocked *core.SecurityBlockedError
+	switch {
+	case errors.Is(err, services.ErrWalletConnectDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"code": e.ERROR, "msg": err.Error(), "data": nil})
+	case errors.Is(err, services.ErrWalletConnectRequestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"code": e.InvalidParams, "msg": err.Error(), "data": nil})
+	case errors.As(err, &blocked):
+		c.JSON(http.StatusForbidden, gin.H{"code": e.ErrorPermission, "msg": blocked.Error(), "data": blocked.Result})
+	case errors.Is(err, core.ErrRecipientBlocklisted):
+		c.JSON(http.StatusForbidden, gin.H{"code": e.ErrorRecipientBlocked, "msg": err.Error(), "data": nil})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": err.Error()})
+	}
+}
@@ -30,12 +30,14 @@ API密钥认证：
 package middleware
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
+	"wallet/core"
 	"wallet/pkg/e"
 
 	"github.com/gin-gonic/gin"
@@ -296,6 +298,41 @@ func OptionalAuth() gin.HandlerFunc {
 	}
 }
 
+// ProviderKeys 将已认证用户配置的第三方服务密钥注入请求context
+// 需在JWTAuth之后使用；resolve 根据 user_id 返回附加了用户密钥的context
+func ProviderKeys(resolve func(ctx context.Context, userID string) context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if userID, ok := c.Get("user_id"); ok {
+			if id, ok := userID.(string); ok && id != "" {
+				c.Request = c.Request.WithContext(resolve(c.Request.Context(), id))
+			}
+		}
+		c.Next()
+	}
+}
+
+// AuditContext 将审计归属信息（会话所属地址、IP、User-Agent、会话与设备ID）放入请求context
+// 核心层记录审计日志时读取（core.LogActionCtx）；resolve 根据会话ID解析钱包地址，失败时地址留空
+func AuditContext(resolve func(sessionID string) (string, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actx := core.AuditContext{
+			IPAddress: c.ClientIP(),
+			UserAgent: c.GetHeader("User-Agent"),
+			DeviceID:  c.GetHeader("X-Device-ID"),
+		}
+		if userID, ok := c.Get("user_id"); ok {
+			if id, ok := userID.(string); ok && id != "" {
+				actx.SessionID = id
+				if address, err := resolve(id); err == nil {
+					actx.UserAddress = address
+				}
+			}
+		}
+		c.Request = c.Request.WithContext(core.WithAuditContext(c.Request.Context(), actx))
+		c.Next()
+	}
+}
+
 // GetAuthManager 获取认证管理器实例
 func GetAuthManager() *AuthManager {
 	return authManager
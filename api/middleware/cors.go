@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"wallet/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// originPattern 允许的来源（见 config.CORSConfig）
+type originPattern struct {
+	any    bool   // "*"：允许所有来源
+	scheme string // 为空时匹配任意协议
+	host   string // 完整主机名；subdomain 为 true 时为父域名
+	port   string // 为空时使用协议默认端口
+	// subdomain 为 true 时匹配 host 的任意子域名（不含 host 本身）
+	subdomain bool
+}
+
+// CORSPolicy 按配置判断跨域请求的来源是否允许
+type CORSPolicy struct {
+	patterns []originPattern
+	cfg      config.CORSConfig
+}
+
+// NewCORSPolicy 解析配置中的来源，无法解析的来源记录日志后忽略
+func NewCORSPolicy(cfg config.CORSConfig) *CORSPolicy {
+	cfg = cfg.WithDefaults()
+	policy := &CORSPolicy{cfg: cfg}
+	for _, raw := range cfg.AllowedOrigins {
+		pattern, ok := parseOriginPattern(raw)
+		if !ok {
+			slog.WarnContext(context.Background(), "ignoring invalid cors origin", "origin", raw)
+			continue
+		}
+		if pattern.any && cfg.AllowCredentials {
+			slog.WarnContext(context.Background(), "cors wildcard origin configured, credentials header will not be sent")
+		}
+		policy.patterns = append(policy.patterns, pattern)
+	}
+	return policy
+}
+
+// Allowed 判断来源是否允许；wildcard 表示由 "*" 匹配（响应 Access-Control-Allow-Origin: *）
+func (p *CORSPolicy) Allowed(origin string) (allowed, wildcard bool) {
+	scheme, host, port, ok := splitOrigin(origin)
+	if !ok {
+		return false, false
+	}
+	for _, pattern := range p.patterns {
+		switch {
+		case pattern.any:
+			wildcard = true
+		case pattern.scheme != "" && pattern.scheme != scheme:
+		case pattern.port != "" && pattern.port != port:
+		case pattern.port == "" && port != defaultPort(scheme):
+		case pattern.subdomain && strings.HasSuffix(host, "."+pattern.host):
+			return true, false
+		case !pattern.subdomain && host == pattern.host:
+			return true, false
+		}
+	}
+	return wildcard, wildcard
+}
+
+// CORS 跨域资源共享中间件，与速率限制等中间件相互独立
+// 只对配置中允许的来源返回 CORS 响应头；预检请求（OPTIONS）在此直接响应，来源不允许时返回403
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
+	policy := NewCORSPolicy(cfg)
+	cfg = policy.cfg
+	methods := strings.Join(cfg.AllowedMethods, ",")
+	headers := strings.Join(cfg.AllowedHeaders, ",")
+	exposed := strings.Join(cfg.ExposedHeaders, ",")
+	maxAge := strconv.Itoa(cfg.MaxAgeSeconds)
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if origin == "" {
+			// 非浏览器跨域请求
+			if c.Request.Method == http.MethodOptions {
+				c.AbortWithStatus(http.StatusNoContent)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		allowed, wildcard := policy.Allowed(origin)
+		if !allowed {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			// 不返回 CORS 头，浏览器将拒绝脚本读取响应
+			c.Next()
+			return
+		}
+
+		if wildcard {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+			if cfg.AllowCredentials {
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
+		}
+		if exposed != "" {
+			c.Header("Access-Control-Expose-Headers", exposed)
+		}
+
+		if preflight {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+			c.Header("Access-Control-Allow-Methods", methods)
+			c.Header("Access-Control-Allow-Headers", headers)
+			c.Header("Access-Control-Max-Age", maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+// parseOriginPattern 解析 https://app.example.com、https://*.example.com、*.example.com 或 *
+func parseOriginPattern(raw string) (originPattern, bool) {
+	raw = strings.TrimRight(strings.ToLower(strings.TrimSpace(raw)), "/")
+	if raw == "*" {
+		return originPattern{any: true}, true
+	}
+	var pattern originPattern
+	rest := raw
+	if i := strings.Index(raw, "://"); i >= 0 {
+		pattern.scheme, rest = raw[:i], raw[i+3:]
+		if pattern.scheme == "" {
+			return pattern, false
+		}
+	}
+	if strings.HasPrefix(rest, "*.") {
+		pattern.subdomain = true
+		rest = rest[2:]
+	}
+	u, err := url.Parse("scheme://" + rest)
+	if err != nil || u.Hostname() == "" || strings.Contains(u.Hostname(), "*") || (u.Path != "" && u.Path != "/") {
+		return pattern, false
+	}
+	pattern.host, pattern.port = u.Hostname(), u.Port()
+	if pattern.port != "" && pattern.port == defaultPort(pattern.scheme) {
+		pattern.port = ""
+	}
+	return pattern, true
+}
+
+// splitOrigin 拆分请求的 Origin 为协议、主机与端口（未指定端口时为协议默认端口）
+func splitOrigin(origin string) (scheme, host, port string, ok bool) {
+	u, err := url.Parse(strings.ToLower(strings.TrimSpace(origin)))
+	if err != nil || u.Scheme == "" || u.Hostname() == "" {
+		return "", "", "", false
+	}
+	port = u.Port()
+	if port == "" {
+		port = defaultPort(u.Scheme)
+	}
+	return u.Scheme, u.Hostname(), port, true
+}
+
+// defaultPort 协议的默认端口
+func defaultPort(scheme string) string {
+	switch scheme {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	}
+	return ""
+}
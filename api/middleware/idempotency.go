@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"wallet/core"
+	"wallet/pkg/e"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader 客户端为每笔转账生成的唯一键（如 UUID），重试时保持不变
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength 幂等键的最大长度
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyStore 幂等键存储（见 services.IdempotencyService）
+type IdempotencyStore interface {
+	Acquire(ctx context.Context, sessionID, clientIP, key, fingerprint string) (string, *core.IdempotentResponse, error)
+	Complete(owner, key string, status int, body []byte)
+	Release(owner, key string)
+}
+
+// idempotencyRecorder 记录响应体，供处理完成后保存
+type idempotencyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency 发送类接口的 Idempotency-Key 去重中间件，需在JWTAuth与IP白名单之后使用
+// 未携带请求头时不做处理；相同键的重试返回首次的响应（响应头 Idempotent-Replayed: true），
+// 首次请求仍在处理时返回409，键已用于内容不同的请求时返回422。
+// 4xx 响应（参数错误、需要双因素验证码等，交易未广播）不保存，客户端可用同一个键重新提交；
+// 其余响应（包括可能已广播的 5xx）在 TTL 内保存，重试不会再次广播
+func Idempotency(store IdempotencyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader(IdempotencyKeyHeader))
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": e.InvalidParams,
+				"msg":  e.GetMsg(e.InvalidParams),
+				"data": fmt.Sprintf("%s 长度不能超过 %d", IdempotencyKeyHeader, maxIdempotencyKeyLength),
+			})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": e.InvalidParams, "msg": e.GetMsg(e.InvalidParams), "data": "读取请求体失败"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+		hash.Write(body)
+		fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+		owner, stored, err := store.Acquire(c.Request.Context(), c.GetString("user_id"), c.ClientIP(), key, fingerprint)
+		switch {
+		case errors.Is(err, core.ErrIdempotencyInProgress):
+			c.JSON(http.StatusConflict, gin.H{"code": e.ErrorIdempotencyInProgress, "msg": e.GetMsg(e.ErrorIdempotencyInProgress), "data": err.Error()})
+			c.Abort()
+			return
+		case errors.Is(err, core.ErrIdempotencyKeyReused):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"code": e.ErrorIdempotencyKeyReused, "msg": e.GetMsg(e.ErrorIdempotencyKeyReused), "data": err.Error()})
+			c.Abort()
+			return
+		case err != nil:
+			slog.WarnContext(c.Request.Context(), "idempotency key handling failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"code": e.ERROR, "msg": "幂等键处理失败", "data": err.Error()})
+			c.Abort()
+			return
+		}
+		if stored != nil {
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(stored.Status, "application/json; charset=utf-8", stored.Body)
+			c.Abort()
+			return
+		}
+
+		recorder := &idempotencyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		// 处理过程中 panic 时无法确定交易是否已广播，键保持 processing 直至过期
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusBadRequest && status < http.StatusInternalServerError {
+			store.Release(owner, key)
+			return
+		}
+		store.Complete(owner, key, status, recorder.body.Bytes())
+	}
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"wallet/core"
+	"wallet/pkg/e"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ipWhitelistCredentials 请求体中处理器用于签名的凭据
+type ipWhitelistCredentials struct {
+	SessionID      string `json:"session_id"`
+	Mnemonic       string `json:"mnemonic"`
+	DerivationPath string `json:"derivation_path"`
+}
+
+// RequireWhitelistedIP IP白名单中间件，用于签名、发送等敏感接口
+// 需在JWTAuth或OptionalAuth之后使用；check 按请求所属用户的白名单校验客户端IP（白名单为空时放行），
+// 不在白名单中返回403。所属用户取处理器实际签名所用的请求体 session_id 或 mnemonic，未提供时取认证令牌的会话；
+// 认证令牌与请求体属于不同钱包时返回403，都无法确定时返回401，不放行。
+// 客户端IP由 gin 按 server.trusted_proxies 解析 X-Forwarded-For，
+// 未配置可信代理时只使用连接的对端地址，避免伪造请求头绕过白名单
+func RequireWhitelistedIP(check func(ctx context.Context, authSessionID, sessionID, mnemonic, derivationPath, ip, resource string) error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		authSessionID, _ := userID.(string)
+		creds := requestCredentials(c)
+		err := check(c.Request.Context(), authSessionID, creds.SessionID, creds.Mnemonic, creds.DerivationPath, c.ClientIP(), c.FullPath())
+		switch {
+		case err == nil:
+			c.Next()
+			return
+		case errors.Is(err, core.ErrIPWhitelistNoOwner):
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code": e.ErrorAuth,
+				"msg":  err.Error(),
+				"data": nil,
+			})
+		case errors.Is(err, core.ErrIPWhitelistOwnerMismatch):
+			c.JSON(http.StatusForbidden, gin.H{
+				"code": e.ErrorAuth,
+				"msg":  err.Error(),
+				"data": nil,
+			})
+		case errors.Is(err, core.ErrIPNotWhitelisted):
+			c.JSON(http.StatusForbidden, gin.H{
+				"code": e.ErrorIPNotWhitelisted,
+				"msg":  e.GetMsg(e.ErrorIPNotWhitelisted),
+				"data": gin.H{"ip": c.ClientIP()},
+			})
+		default:
+			slog.WarnContext(c.Request.Context(), "ip whitelist check failed", "ip", c.ClientIP(), "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": e.ERROR,
+				"msg":  "IP白名单校验失败",
+				"data": nil,
+			})
+		}
+		c.Abort()
+	}
+}
+
+// requestCredentials 从JSON请求体读取会话或助记词，读取后恢复请求体供后续处理器绑定
+func requestCredentials(c *gin.Context) ipWhitelistCredentials {
+	var creds ipWhitelistCredentials
+	if c.Request.Body == nil {
+		return creds
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil || len(body) == 0 {
+		return creds
+	}
+	_ = json.Unmarshal(body, &creds)
+	return creds
+}
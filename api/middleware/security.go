@@ -280,6 +280,8 @@ func isTransactionEndpoint(path string) bool {
 		"/api/v1/transactions/send-advanced",
 		"/api/v1/transactions/send-erc20-advanced",
 		"/api/v1/transactions/broadcast",
+		"/api/v1/transactions/replace",
+		"/api/v1/transactions/batch",
 		"/api/v1/tokens/",
 	}
 
@@ -292,26 +294,6 @@ func isTransactionEndpoint(path string) bool {
 	return false
 }
 
-// CORS 跨域资源共享中间件
-func CORS() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin,Content-Type,Accept,Authorization,X-Requested-With,X-API-Key,X-User-Address")
-		c.Header("Access-Control-Expose-Headers", "Content-Length,X-Request-ID")
-		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Max-Age", "86400")
-
-		// 处理预检请求
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-
-		c.Next()
-	}
-}
-
 // SecurityHeaders 安全头中间件
 func SecurityHeaders() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -325,19 +307,3 @@ func SecurityHeaders() gin.HandlerFunc {
 		c.Next()
 	}
 }
-
-// RequestID 请求ID中间件
-func RequestID() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		requestID := c.GetHeader("X-Request-ID")
-		if requestID == "" {
-			// 生成新的请求ID
-			requestID = fmt.Sprintf("%d-%s", time.Now().UnixNano(), c.ClientIP())
-		}
-
-		c.Header("X-Request-ID", requestID)
-		c.Set("request_id", requestID)
-
-		c.Next()
-	}
-}
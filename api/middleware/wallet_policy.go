@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"wallet/config"
+	"wallet/pkg/e"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireWalletCreation 钱包创建策略中间件
+// 配置 wallet.allow_wallet_creation 为 false 时拒绝创建新钱包的请求
+func RequireWalletCreation() gin.HandlerFunc {
+	return walletPolicyGate(func() bool { return config.AppConfig.Wallet.AllowWalletCreation }, e.ErrorWalletCreateDisabled)
+}
+
+// RequireWalletImport 钱包导入策略中间件
+// 配置 wallet.allow_wallet_import 为 false 时拒绝导入钱包的请求
+func RequireWalletImport() gin.HandlerFunc {
+	return walletPolicyGate(func() bool { return config.AppConfig.Wallet.AllowWalletImport }, e.ErrorWalletImportDisabled)
+}
+
+// walletPolicyGate 按策略开关放行或返回403
+func walletPolicyGate(allowed func() bool, code int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !allowed() {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code": code,
+				"msg":  e.GetMsg(code),
+				"data": nil,
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// LogWalletPolicy 启动时输出钱包创建/导入策略
+func LogWalletPolicy() {
+	policy := config.AppConfig.Wallet
+	slog.InfoContext(context.Background(), "wallet policy", "allow_creation", policy.AllowWalletCreation, "allow_import", policy.AllowWalletImport)
+}
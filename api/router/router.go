@@ -6,17 +6,26 @@ API路由配置包
 路由组织结构：
 - /api/v1/auth/* - 认证相关接口（登录、注册、Token管理）
 - /api/v1/wallets/* - 钱包管理接口（创建、导入、余额查询）
-- /api/v1/networks/* - 多链网络管理接口（切换、状态查询）
+- /api/v1/networks/* - 多链网络管理接口（切换、状态查询、添加/移除自定义网络）
 - /api/v1/transactions/* - 交易相关接口（发送、查询、广播）
 - /api/v1/tokens/* - 代币相关接口（元数据、授权管理）
-- /api/v1/sign/* - 消息签名接口（Personal Sign、EIP-712）
+- /api/v1/contracts/* - 按ABI的通用合约读写接口
+- /api/v1/approvals/* - ERC20 授权查看与撤销
+- /api/v1/sign/* - 消息签名与验签接口（Personal Sign、EIP-712）
 - /api/v1/defi/* - DeFi相关接口（1inch集成、流动性、收益等）
-- /health - 服务健康检查接口
+- /api/v1/ws - WebSocket 实时余额与到账推送
+- /api/v1/bridge/* - 跨链桥接状态查询
+- /api/v1/walletconnect/* - WalletConnect v2 配对、会话与请求确认
+- /api/v1/portfolio - 跨链资产汇总（多地址、多网络、美元估值）
+- /api/v1/address/validate - 地址格式与EIP-55校验和检查
+- /health - 服务存活检查接口
+- /ready - 就绪检查接口（数据库与各网络RPC状态）
+- /metrics - Prometheus 指标（节点调用次数与耗时、交易广播、Gas价格、构建信息）
 
 中间件应用：
-- 全局中间件：错误处理、安全头、请求ID、速率限制
+- 全局中间件：请求ID与结构化访问日志、错误处理、安全头、速率限制
 - 认证中间件：JWT认证、API密钥认证、可选认证
-- 业务中间件：交易验证、特殊速率限制
+- 业务中间件：交易验证、特殊速率限制、签名/发送接口的IP白名单、发送接口的 Idempotency-Key 去重
 
 安全特性：
 - 分层的速率限制策略
@@ -27,25 +36,36 @@ API路由配置包
 package router
 
 import (
-	"net/http"
+	"context"
+	"log/slog"
 	"wallet/api/handlers"
 	"wallet/api/middleware"
+	"wallet/config"
 	"wallet/services"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // NewRouter 创建并配置新的Gin HTTP路由器
 func NewRouter(walletService *services.WalletService) *gin.Engine {
-	// 创建默认Gin引擎（包含Logger和Recovery中间件）
-	r := gin.Default()
+	// 创建Gin引擎（访问日志使用结构化的 RequestLogger 代替 Gin 自带的文本日志）
+	r := gin.New()
+	r.Use(gin.Recovery())
+
+	// 只采信可信代理转发的 X-Forwarded-For，未配置时客户端IP取连接对端地址
+	if err := r.SetTrustedProxies(config.AppConfig.Server.TrustedProxies); err != nil {
+		slog.WarnContext(context.Background(), "invalid trusted proxies, ignoring X-Forwarded-For", "error", err)
+		_ = r.SetTrustedProxies(nil)
+	}
 
 	// 应用全局中间件（按顺序执行）
-	r.Use(middleware.CORS())            // CORS跨域支持
-	r.Use(middleware.ErrorHandler())    // 统一错误处理
-	r.Use(middleware.SecurityHeaders()) // HTTP安全头设置
-	r.Use(middleware.RequestID())       // 请求追踪ID生成
-	r.Use(middleware.RateLimit())       // 通用速率限制
+	r.Use(middleware.RequestID())                 // 请求追踪ID（放入请求 context，日志按 request_id 关联）
+	r.Use(middleware.RequestLogger())             // 结构化访问日志
+	r.Use(middleware.CORS(config.AppConfig.CORS)) // CORS跨域支持（仅允许配置的来源）
+	r.Use(middleware.ErrorHandler())              // 统一错误处理
+	r.Use(middleware.SecurityHeaders())           // HTTP安全头设置
+	r.Use(middleware.RateLimit())                 // 通用速率限制
 	// 可以添加更多中间件，例如日志、CORS等
 
 	// 创建各个业务处理器实例
@@ -53,18 +73,30 @@ func NewRouter(walletService *services.WalletService) *gin.Engine {
 	// 移除传统的认证处理器
 	//authHandler := handlers.NewAuthHandler()                                                             // 认证相关操作处理器
 	// 创建助记词认证处理器
-	mnemonicAuthHandler := handlers.NewMnemonicAuthHandler(walletService)                                // 助记词认证处理器
-	watchAddressHandler := handlers.NewWatchAddressHandler()                                             // 观察地址管理处理器
-	userWalletHandler := handlers.NewUserWalletHandler()                                                 // 用户钱包记录处理器
-	networkHandler := handlers.NewNetworkHandler(walletService.GetMultiChainManager(), walletService)    // 网络相关操作处理器
-	defiHandler := handlers.NewDeFiHandler(walletService.GetDeFiService())                               // DeFi功能处理器
-	nftHandler := handlers.NewNFTHandler(walletService.GetNFTService())                                  // NFT功能处理器
-	dappBrowserHandler := handlers.NewDAppBrowserHandler(walletService.GetDAppBrowserService())          // DApp浏览器处理器
-	socialHandler := handlers.NewSocialHandler(walletService.GetSocialService())                         // 社交功能处理器
-	securityHandler := handlers.NewSecurityHandler(walletService.GetSecurityService())                   // 安全功能处理器
-	nftMarketplaceHandler := handlers.NewNFTMarketplaceHandler(walletService.GetNFTMarketplaceService()) // NFT市场处理器
+	mnemonicAuthHandler := handlers.NewMnemonicAuthHandler(walletService)                                      // 助记词认证处理器
+	watchAddressHandler := handlers.NewWatchAddressHandler()                                                   // 观察地址管理处理器
+	userWalletHandler := handlers.NewUserWalletHandler()                                                       // 用户钱包记录处理器
+	networkHandler := handlers.NewNetworkHandler(walletService.GetMultiChainManager(), walletService)          // 网络相关操作处理器
+	defiHandler := handlers.NewDeFiHandler(walletService.GetDeFiService(), walletService)                      // DeFi功能处理器
+	priceAlertHandler := handlers.NewPriceAlertHandler(walletService.GetPriceService())                        // 代币价格提醒处理器
+	nftHandler := handlers.NewNFTHandler(walletService.GetNFTService())                                        // NFT功能处理器
+	dappBrowserHandler := handlers.NewDAppBrowserHandler(walletService.GetDAppBrowserService(), walletService) // DApp浏览器处理器
+	walletConnectHandler := handlers.NewWalletConnectHandler(walletService)                                    // WalletConnect v2 处理器
+	socialHandler := handlers.NewSocialHandler(walletService.GetSocialService())                               // 社交功能处理器
+	securityHandler := handlers.NewSecurityHandler(walletService.GetSecurityService(), walletService)          // 安全功能处理器
+	nftMarketplaceHandler := handlers.NewNFTMarketplaceHandler(walletService.GetNFTMarketplaceService())       // NFT市场处理器
 	// 创建1inch处理器
-	oneInchHandler := handlers.NewOneInchHandler(walletService.GetDeFiService()) // 1inch聚合器处理器
+	oneInchHandler := handlers.NewOneInchHandler(walletService.GetDeFiService())                         // 1inch聚合器处理器
+	toolsHandler := handlers.NewToolsHandler()                                                           // 开发者工具处理器
+	realtimeHandler := handlers.NewRealtimeHandler(walletService.GetRealtimeService())                   // 实时推送处理器
+	bridgeHandler := handlers.NewBridgeHandler(walletService.GetBridgeService())                         // 跨链桥接处理器
+	portfolioHandler := handlers.NewPortfolioHandler(walletService.GetPortfolioService(), walletService) // 跨链资产汇总处理器
+	notificationHandler := handlers.NewNotificationHandler(walletService)                                // 通知收件箱处理器
+
+	// 签名/发送类接口的IP白名单校验（用户未配置白名单时不限制）
+	ipWhitelist := middleware.RequireWhitelistedIP(walletService.GetSecurityService().CheckRequestIP)
+	// 发送类接口的 Idempotency-Key 去重（重试返回首次结果，不会重复广播）
+	idempotent := middleware.Idempotency(walletService.GetIdempotencyService())
 
 	// 助记词认证相关路由组（替代传统的注册登录）
 	// 包括钱包创建、助记词认证、会话管理等功能
@@ -74,8 +106,9 @@ func NewRouter(walletService *services.WalletService) *gin.Engine {
 		auth.Use(middleware.AuthRateLimit())
 
 		// 公开接口（无需认证）
-		auth.POST("/mnemonic/auth", mnemonicAuthHandler.AuthenticateWithMnemonic) // 助记词认证
-		auth.POST("/mnemonic/create", mnemonicAuthHandler.CreateWallet)           // 创建新钱包
+		auth.POST("/mnemonic/auth", mnemonicAuthHandler.AuthenticateWithMnemonic)                                   // 助记词认证
+		auth.POST("/mnemonic/create", middleware.RequireWalletCreation(), mnemonicAuthHandler.CreateWallet)         // 创建新钱包
+		auth.POST("/keystore/auth", middleware.RequireWalletImport(), mnemonicAuthHandler.AuthenticateWithKeystore) // Keystore（V3）认证
 	}
 
 	// API v1 主路由组（需要用户认证）
@@ -84,7 +117,25 @@ func NewRouter(walletService *services.WalletService) *gin.Engine {
 	v1.Use(middleware.JWTAuth()) // 统一的JWT认证机制
 	{
 		// 添加会话注销接口
-		auth.POST("/logout", mnemonicAuthHandler.Logout) // 会话注销
+		auth.POST("/logout", mnemonicAuthHandler.Logout)                                  // 会话注销
+		v1.PUT("/auth/provider-keys", mnemonicAuthHandler.UpdateProviderKeys)             // 设置第三方服务API密钥（只写）
+		v1.POST("/auth/keystore/export", ipWhitelist, mnemonicAuthHandler.ExportKeystore) // 导出会话钱包为 Keystore 备份
+		v1.GET("/ws", realtimeHandler.Subscribe)                                          // WebSocket 订阅地址余额变化与到账
+		v1.GET("/bridge/:id/status", bridgeHandler.GetBridgeStatus)                       // 跨链桥接实时状态
+
+		// 跨链资产汇总（?addresses=a,b&networks=eth,polygon&tokens=...），优先使用用户自己的 CoinGecko 密钥
+		v1.GET("/portfolio", middleware.ProviderKeys(walletService.WithUserProviderKeys), portfolioHandler.GetPortfolio)
+
+		// 通知收件箱（价格提醒、多签审批、到账等）与通知渠道设置，仅会话所属钱包
+		notificationGroup := v1.Group("/notifications")
+		{
+			notificationGroup.GET("", notificationHandler.ListNotifications)                   // 分页查询收件箱（?unread=true&type=）
+			notificationGroup.POST("/read-all", notificationHandler.MarkAllNotificationsRead)  // 全部标记已读
+			notificationGroup.GET("/settings", notificationHandler.GetNotificationSettings)    // 查询通知渠道设置
+			notificationGroup.PUT("/settings", notificationHandler.UpdateNotificationSettings) // 修改通知渠道设置
+			notificationGroup.GET("/:id", notificationHandler.GetNotification)                 // 通知详情与投递状态
+			notificationGroup.POST("/:id/read", notificationHandler.MarkNotificationRead)      // 标记已读
+		}
 
 		// 观察地址管理相关路由组
 		// 提供用户观察地址的增删改查功能
@@ -108,18 +159,49 @@ func NewRouter(walletService *services.WalletService) *gin.Engine {
 			userWalletGroup.DELETE("/:id", userWalletHandler.DeleteUserWallet)           // 删除钱包记录
 			userWalletGroup.POST("/:id/set-primary", userWalletHandler.SetPrimaryWallet) // 设置主钱包
 		}
+		// HD钱包多账户路由组（m/44'/60'/N'/0/0）
+		// 账户列表按会话所属钱包（账户0地址）持久化到数据库
+		accountGroup := v1.Group("/accounts")
+		{
+			accountGroup.GET("", walletHandler.ListAccounts)                     // 获取账户列表（账户0始终存在）
+			accountGroup.POST("", walletHandler.CreateAccount)                   // 创建账户（默认序号为最大序号+1）
+			accountGroup.PUT("/:index", walletHandler.RenameAccount)             // 修改账户标签
+			accountGroup.GET("/:index/balance", walletHandler.GetAccountBalance) // 查询账户原生代币余额
+		}
+		// 只读钱包（watch-only）路由组
+		// 仅跟踪地址余额，不涉及私钥；按会话所属钱包地址持久化到数据库
+		watchOnlyGroup := v1.Group("/watch-only")
+		{
+			watchOnlyGroup.POST("", walletHandler.AddWatchOnly)                            // 添加只读地址（可带标签）
+			watchOnlyGroup.GET("", walletHandler.ListWatchOnly)                            // 获取只读地址列表（含标签与余额）
+			watchOnlyGroup.GET("/export", walletHandler.ExportWatchOnly)                   // 导出只读地址（CSV/JSON）
+			watchOnlyGroup.PUT("/:address/label", walletHandler.SetWatchOnlyLabel)         // 设置只读地址标签
+			watchOnlyGroup.PUT("/:address/threshold", walletHandler.SetWatchOnlyThreshold) // 设置到账通知的最小美元金额
+			watchOnlyGroup.DELETE("/:address", walletHandler.RemoveWatchOnly)              // 删除只读地址
+		}
+
 		// 钱包管理相关路由组（支持HD钱包功能）
 		// 包括钱包创建、导入、余额查询和交易历史等核心功能
 		// 使用可选认证，兼容现有功能
 		walletGroup := r.Group("/api/v1/wallets")
 		walletGroup.Use(middleware.OptionalAuth()) // 灵活的认证机制
 		{
-			walletGroup.POST("/new", walletHandler.CreateWallet)                                     // 创建新钱包（生成助记词）
-			walletGroup.POST("/import-mnemonic", walletHandler.ImportMnemonic)                       // 通过助记词导入钱包
-			walletGroup.GET("/:address/balance", walletHandler.GetBalance)                           // 获取原生代币余额（ETH/MATIC/BNB）
-			walletGroup.GET("/:address/tokens/:tokenAddress/balance", walletHandler.GetERC20Balance) // 获取ERC20代币余额
-			walletGroup.GET("/:address/nonce", walletHandler.GetNonces)                              // 获取地址的nonce值
-			walletGroup.GET("/:address/history", walletHandler.GetTransactionHistory)                // 查询交易历史（支持分页和过滤）
+			walletGroup.POST("/new", middleware.RequireWalletCreation(), walletHandler.CreateWallet)                                               // 创建新钱包（生成助记词）
+			walletGroup.POST("/import-mnemonic", middleware.RequireWalletImport(), walletHandler.ImportMnemonic)                                   // 通过助记词导入钱包
+			walletGroup.POST("/validate-mnemonic", walletHandler.ValidateMnemonic)                                                                 // 校验助记词（单词数量、词表、校验和）
+			walletGroup.GET("/:address/balance", walletHandler.GetBalance)                                                                         // 获取原生代币余额（ETH/MATIC/BNB）
+			walletGroup.GET("/:address/tokens/:tokenAddress/balance", walletHandler.GetERC20Balance)                                               // 获取ERC20代币余额
+			walletGroup.GET("/:address/tokens/balances", walletHandler.GetERC20BalancesBatch)                                                      // 批量获取ERC20代币余额（Multicall3）
+			walletGroup.GET("/:address/tokens/:tokenAddress", walletHandler.GetTokenBalanceWithMetadata)                                           // 代币余额与名称/符号/小数位/美元价值（一次返回）
+			walletGroup.GET("/:address/nonce", walletHandler.GetNonces)                                                                            // 获取地址的nonce值
+			walletGroup.GET("/:address/history", middleware.ProviderKeys(walletService.WithUserProviderKeys), walletHandler.GetTransactionHistory) // 查询交易历史（支持分页和过滤）
+			walletGroup.GET("/:address/history/export", walletHandler.ExportTransactionHistory)                                                    // 流式导出交易历史（CSV/JSON）
+			walletGroup.GET("/:address/limits", walletHandler.GetSpendingLimit)                                                                    // 查询支出限额与本周期已支出
+			walletGroup.PUT("/:address/limits", ipWhitelist, walletHandler.SetSpendingLimit)                                                       // 设置每日/每月支出限额（美元）
+			walletGroup.DELETE("/:address/limits", ipWhitelist, walletHandler.DeleteSpendingLimit)                                                 // 删除支出限额
+			walletGroup.GET("/:address/fee-ceiling", walletHandler.GetFeeCeiling)                                                                  // 查询单笔交易手续费上限
+			walletGroup.PUT("/:address/fee-ceiling", ipWhitelist, walletHandler.SetFeeCeiling)                                                     // 设置单笔交易手续费上限（美元）
+			walletGroup.DELETE("/:address/fee-ceiling", ipWhitelist, walletHandler.DeleteFeeCeiling)                                               // 删除手续费上限，恢复默认值
 		}
 
 		// 多链网络管理路由组
@@ -127,28 +209,49 @@ func NewRouter(walletService *services.WalletService) *gin.Engine {
 		networkGroup := r.Group("/api/v1/networks")
 		// 注意：网络列表和当前网络信息不需要认证，但其他操作需要认证
 		{
-			networkGroup.GET("", networkHandler.ListNetworks)              // 获取所有可用网络
-			networkGroup.GET("/current", networkHandler.GetCurrentNetwork) // 获取当前活跃网络信息
-			networkGroup.GET("/list", networkHandler.ListNetworks)         // 列出所有可用网络
-			networkGroup.GET("/:networkId", networkHandler.GetNetworkInfo) // 获取特定网络详细信息
+			networkGroup.GET("", networkHandler.ListNetworks)                       // 获取所有可用网络
+			networkGroup.GET("/current", networkHandler.GetCurrentNetwork)          // 获取当前活跃网络信息
+			networkGroup.GET("/list", networkHandler.ListNetworks)                  // 列出所有可用网络
+			networkGroup.GET("/:networkId", networkHandler.GetNetworkInfo)          // 获取特定网络详细信息
+			networkGroup.GET("/:networkId/health", networkHandler.GetNetworkHealth) // RPC节点健康状态（故障转移节点池）
 		}
 
 		// 需要认证的网络操作
 		networkGroupAuth := v1.Group("/networks")
 		networkGroupAuth.Use(middleware.OptionalAuth()) // 灵活的认证机制
 		{
-			networkGroupAuth.GET("/addresses/:address/balance", networkHandler.GetBalanceOnNetwork)                                                    // 获取指定网络上的余额
-			networkGroupAuth.GET("/addresses/:address/cross-chain-balance", networkHandler.GetCrossChainBalance)                                       // 跨链余额查询（聚合所有网络）
-			networkGroupAuth.GET("/addresses/:address/tokens/:tokenAddress/cross-chain-balance", networkHandler.GetCrossChainTokenBalance)             // 跨链代币余额查询
-			networkGroupAuth.POST("/send-eth", middleware.TransactionRateLimit(), middleware.TransactionValidation(), networkHandler.SendETHOnNetwork) // 在指定网络发送ETH
-			networkGroupAuth.POST("/switch", networkHandler.SwitchNetwork)                                                                             // 切换到指定网络
+			networkGroupAuth.GET("/addresses/:address/balance", networkHandler.GetBalanceOnNetwork)                                                                             // 获取指定网络上的余额
+			networkGroupAuth.GET("/addresses/:address/cross-chain-balance", networkHandler.GetCrossChainBalance)                                                                // 跨链余额查询（聚合所有网络）
+			networkGroupAuth.GET("/addresses/:address/tokens/:tokenAddress/cross-chain-balance", networkHandler.GetCrossChainTokenBalance)                                      // 跨链代币余额查询
+			networkGroupAuth.POST("/send-eth", ipWhitelist, idempotent, middleware.TransactionRateLimit(), middleware.TransactionValidation(), networkHandler.SendETHOnNetwork) // 在指定网络发送ETH
+			networkGroupAuth.POST("", networkHandler.AddNetwork)                                                                                                                // 添加自定义网络（自定义RPC）
+			networkGroupAuth.DELETE("/:networkId", networkHandler.RemoveNetwork)                                                                                                // 移除自定义网络
+			networkGroupAuth.PUT("/:networkId/confirmations", networkHandler.SetRequiredConfirmations)                                                                          // 设置网络的最终确认数（0 恢复配置值，仅管理员）
+			networkGroupAuth.POST("/switch", networkHandler.SwitchNetwork)                                                                                                      // 切换到指定网络
+		}
+
+		// 开发者工具接口（无状态，无需认证）
+		toolsGroup := r.Group("/api/v1/tools")
+		{
+			toolsGroup.POST("/selector", toolsHandler.FunctionSelector)  // 计算函数选择器
+			toolsGroup.POST("/event-topic", toolsHandler.EventTopic)     // 计算事件topic0
+			toolsGroup.POST("/merkle/root", toolsHandler.MerkleRoot)     // 计算空投Merkle根
+			toolsGroup.POST("/merkle/proof", toolsHandler.MerkleProof)   // 生成Merkle证明
+			toolsGroup.POST("/merkle/verify", toolsHandler.MerkleVerify) // 校验Merkle证明
 		}
 
 		// Gas价格建议接口（全局可用）
 		gasGroup := r.Group("/api/v1")
 		gasGroup.Use(middleware.OptionalAuth())
 		{
-			gasGroup.GET("/gas-suggestion", walletHandler.GetGasSuggestion) // 获取当前网络的Gas价格建议
+			gasGroup.GET("/gas-suggestion", middleware.ProviderKeys(walletService.WithUserProviderKeys), walletHandler.GetGasSuggestion) // 获取当前网络的Gas价格建议及费用美元估算（?gas_limit=）
+			gasGroup.GET("/chain/congestion", walletHandler.GetChainCongestion)                                                          // 获取当前网络拥堵状态
+			gasGroup.GET("/address/validate", walletHandler.ValidateAddress)                                                             // 校验地址格式、EIP-55校验和及是否为合约
+			gasGroup.GET("/address/:address/qr", walletHandler.GetAddressQRCode)                                                         // 收款地址的 EIP-681 支付二维码（?amount=&token=&size=&level=&format=）
+			gasGroup.GET("/address/:address/summary", walletHandler.GetAddressSummary)                                                   // 地址活跃度摘要（首次出现、交易数、最近活跃、是否合约、持有代币数）
+			gasGroup.GET("/address/:address/nonce-gap", walletHandler.GetNonceGap)                                                       // 阻塞的待处理交易及加速/取消建议
+			gasGroup.POST("/payment/parse", walletHandler.ParsePaymentURI)                                                               // 解析 EIP-681 支付链接（扫码预填发送表单）
+			gasGroup.GET("/share/:id", socialHandler.ViewShare)                                                                          // 公开查看分享（校验过期与隐私设置，计入查看统计）
 		}
 
 		// DeFi功能相关路由组
@@ -158,8 +261,8 @@ func NewRouter(walletService *services.WalletService) *gin.Engine {
 			// DEX交易聚合相关接口
 			swapGroup := defiGroup.Group("/swap")
 			{
-				swapGroup.GET("/quote", defiHandler.GetSwapQuote)                                      // 获取最佳交易报价
-				swapGroup.POST("/execute", middleware.TransactionRateLimit(), defiHandler.ExecuteSwap) // 执行Swap交易
+				swapGroup.GET("/quote", defiHandler.GetSwapQuote)                                                               // 获取最佳交易报价
+				swapGroup.POST("/execute", ipWhitelist, idempotent, middleware.TransactionRateLimit(), defiHandler.ExecuteSwap) // 执行Swap交易
 			}
 
 			// 1inch聚合器相关接口
@@ -174,8 +277,8 @@ func NewRouter(walletService *services.WalletService) *gin.Engine {
 			// 流动性管理相关接口
 			liquidityGroup := defiGroup.Group("/liquidity")
 			{
-				liquidityGroup.GET("/pools", defiHandler.GetLiquidityPools)                              // 获取流动性池列表
-				liquidityGroup.POST("/add", middleware.TransactionRateLimit(), defiHandler.AddLiquidity) // 添加流动性
+				liquidityGroup.GET("/pools", defiHandler.GetLiquidityPools)                                           // 获取流动性池列表
+				liquidityGroup.POST("/add", ipWhitelist, middleware.TransactionRateLimit(), defiHandler.AddLiquidity) // 添加流动性
 			}
 
 			// 收益农场相关接口
@@ -186,8 +289,14 @@ func NewRouter(walletService *services.WalletService) *gin.Engine {
 
 			// 价格查询相关接口
 			priceGroup := defiGroup.Group("/price")
+			priceGroup.Use(middleware.ProviderKeys(walletService.WithUserProviderKeys)) // 优先使用用户自己的 CoinGecko 密钥
 			{
-				priceGroup.GET("/tokens", defiHandler.GetTokenPrices) // 获取代币价格信息
+				priceGroup.GET("/tokens", defiHandler.GetTokenPrices)                          // 批量获取代币美元价格（?addresses=a,b&chain_id=1）
+				priceGroup.POST("/alerts", priceAlertHandler.CreateTokenPriceAlert)            // 创建代币价格提醒
+				priceGroup.GET("/alerts", priceAlertHandler.GetTokenPriceAlerts)               // 代币价格提醒列表
+				priceGroup.GET("/alerts/history", priceAlertHandler.GetTokenPriceAlertHistory) // 代币价格提醒触发记录
+				priceGroup.POST("/alerts/:id/pause", priceAlertHandler.PauseTokenPriceAlert)   // 暂停代币价格提醒
+				priceGroup.POST("/alerts/:id/resume", priceAlertHandler.ResumeTokenPriceAlert) // 恢复代币价格提醒
 			}
 		}
 
@@ -200,6 +309,7 @@ func NewRouter(walletService *services.WalletService) *gin.Engine {
 			{
 				userGroup.GET("/:address/nfts", nftHandler.GetUserNFTs) // 获取用户NFT列表
 			}
+			nftGroup.GET("/owned", nftHandler.GetOwnedNFTs) // 链上枚举地址持有的NFT（含元数据，分页）
 
 			// NFT详情相关接口
 			detailsGroup := nftGroup.Group("/details")
@@ -230,7 +340,11 @@ func NewRouter(walletService *services.WalletService) *gin.Engine {
 			}
 
 			// NFT转账相关接口
-			nftGroup.POST("/transfer", middleware.TransactionRateLimit(), nftHandler.TransferNFT) // 转移NFT
+			nftGroup.POST("/transfer", ipWhitelist, idempotent, middleware.TransactionRateLimit(), nftHandler.TransferNFT)                // 转移NFT
+			nftGroup.POST("/transfer/erc721", ipWhitelist, idempotent, middleware.TransactionRateLimit(), walletHandler.TransferERC721)   // ERC-721 safeTransferFrom
+			nftGroup.POST("/transfer/erc1155", ipWhitelist, idempotent, middleware.TransactionRateLimit(), walletHandler.TransferERC1155) // ERC-1155 safeTransferFrom
+			nftGroup.GET("/erc721/:contract/:tokenId/owner", walletHandler.GetERC721Owner)                                                // 查询ERC-721持有者
+			nftGroup.GET("/erc1155/:contract/:tokenId/balance", walletHandler.GetERC1155Balance)                                          // 查询ERC-1155持有数量
 
 			// NFT投资组合相关接口
 			portfolioGroup := nftGroup.Group("/portfolio")
@@ -242,37 +356,59 @@ func NewRouter(walletService *services.WalletService) *gin.Engine {
 		// NFT市场相关路由组
 		// 提供NFT市场功能，包括交易、列表、统计等
 		marketplaceGroup := v1.Group("/nft/marketplace")
+		marketplaceGroup.Use(middleware.ProviderKeys(walletService.WithUserProviderKeys)) // 优先使用用户自己的第三方API密钥
 		{
-			marketplaceGroup.GET("/listings", nftMarketplaceHandler.GetMarketListings)         // 获取市场列表
-			marketplaceGroup.GET("/transactions", nftMarketplaceHandler.GetMarketTransactions) // 获取市场交易记录
-			marketplaceGroup.GET("/stats/:contract", nftMarketplaceHandler.GetMarketStats)     // 获取市场统计数据
-			marketplaceGroup.POST("/analyze", nftMarketplaceHandler.AnalyzeMarket)             // 分析市场数据
-			marketplaceGroup.GET("/preferences", nftMarketplaceHandler.GetUserPreferences)     // 获取用户偏好设置
-			marketplaceGroup.POST("/preferences", nftMarketplaceHandler.SetUserPreferences)    // 设置用户偏好设置
-			marketplaceGroup.POST("/watchlist", nftMarketplaceHandler.AddToWatchlist)          // 添加到关注列表
-			marketplaceGroup.GET("/watchlist/:listName", nftMarketplaceHandler.GetWatchlist)   // 获取关注列表
-			marketplaceGroup.POST("/price-alert", nftMarketplaceHandler.CreatePriceAlert)      // 创建价格提醒
-			marketplaceGroup.GET("/price-alerts", nftMarketplaceHandler.GetPriceAlerts)        // 获取价格提醒列表
+			marketplaceGroup.GET("/listings", nftMarketplaceHandler.GetMarketListings)                // 获取市场列表
+			marketplaceGroup.GET("/transactions", nftMarketplaceHandler.GetMarketTransactions)        // 获取市场交易记录
+			marketplaceGroup.GET("/stats/:contract", nftMarketplaceHandler.GetMarketStats)            // 获取市场统计数据
+			marketplaceGroup.POST("/analyze", nftMarketplaceHandler.AnalyzeMarket)                    // 分析市场数据
+			marketplaceGroup.GET("/preferences", nftMarketplaceHandler.GetUserPreferences)            // 获取用户偏好设置
+			marketplaceGroup.POST("/preferences", nftMarketplaceHandler.SetUserPreferences)           // 设置用户偏好设置
+			marketplaceGroup.POST("/watchlist", nftMarketplaceHandler.AddToWatchlist)                 // 添加到关注列表
+			marketplaceGroup.GET("/watchlist/:listName", nftMarketplaceHandler.GetWatchlist)          // 获取关注列表
+			marketplaceGroup.POST("/price-alert", nftMarketplaceHandler.CreatePriceAlert)             // 创建价格提醒
+			marketplaceGroup.GET("/price-alerts", nftMarketplaceHandler.GetPriceAlerts)               // 获取价格提醒列表
+			marketplaceGroup.GET("/price-alerts/history", nftMarketplaceHandler.GetPriceAlertHistory) // 价格提醒触发记录
+			marketplaceGroup.POST("/price-alerts/:id/pause", nftMarketplaceHandler.PausePriceAlert)   // 暂停价格提醒
+			marketplaceGroup.POST("/price-alerts/:id/resume", nftMarketplaceHandler.ResumePriceAlert) // 恢复价格提醒
 		}
 
 		// DApp浏览器相关路由组
 		// 提供DApp连接和交互功能
 		dappGroup := v1.Group("/dapp")
 		{
-			dappGroup.POST("/connect", dappBrowserHandler.ConnectDApp)                                                // 连接DApp
-			dappGroup.GET("/connect/:sessionId", dappBrowserHandler.GetSessionInfo)                                   // 获取会话信息
-			dappGroup.DELETE("/connect/:sessionId", dappBrowserHandler.DisconnectDApp)                                // 断开DApp连接
-			dappGroup.POST("/web3/request", dappBrowserHandler.ProcessWeb3Request)                                    // 处理Web3请求
-			dappGroup.POST("/web3/confirm", middleware.TransactionRateLimit(), dappBrowserHandler.ConfirmWeb3Request) // 确认Web3请求
-			dappGroup.GET("/web3/pending/:address", dappBrowserHandler.GetPendingRequests)                            // 获取待处理请求
-			dappGroup.GET("/discovery/list", dappBrowserHandler.GetDAppList)                                          // 获取DApp列表
-			dappGroup.GET("/discovery/featured", dappBrowserHandler.GetFeaturedDApps)                                 // 获取推荐DApp
-			dappGroup.GET("/discovery/search", dappBrowserHandler.SearchDApps)                                        // 搜索DApp
-			dappGroup.GET("/discovery/categories", dappBrowserHandler.GetCategories)                                  // 获取DApp分类
-			dappGroup.GET("/user/:address/activity", dappBrowserHandler.GetUserActivity)                              // 获取用户活动记录
-			dappGroup.POST("/user/favorite", dappBrowserHandler.ManageFavorite)                                       // 管理收藏DApp
+			dappGroup.POST("/connect", dappBrowserHandler.ConnectDApp)                                                             // 连接DApp
+			dappGroup.GET("/security/check", dappBrowserHandler.CheckDAppSecurity)                                                 // 钓鱼域名预检
+			dappGroup.GET("/connect/:sessionId", dappBrowserHandler.GetSessionInfo)                                                // 获取会话信息
+			dappGroup.DELETE("/connect/:sessionId", dappBrowserHandler.DisconnectDApp)                                             // 断开DApp连接
+			dappGroup.POST("/web3/request", dappBrowserHandler.ProcessWeb3Request)                                                 // 处理Web3请求
+			dappGroup.POST("/web3/confirm", ipWhitelist, middleware.TransactionRateLimit(), dappBrowserHandler.ConfirmWeb3Request) // 确认Web3请求
+			dappGroup.GET("/web3/pending/:address", dappBrowserHandler.GetPendingRequests)                                         // 获取待处理请求
+			dappGroup.GET("/discovery/list", dappBrowserHandler.GetDAppList)                                                       // 获取DApp列表
+			dappGroup.GET("/discovery/featured", dappBrowserHandler.GetFeaturedDApps)                                              // 获取推荐DApp
+			dappGroup.GET("/discovery/search", dappBrowserHandler.SearchDApps)                                                     // 搜索DApp
+			dappGroup.GET("/discovery/categories", dappBrowserHandler.GetCategories)                                               // 获取DApp分类
+			dappGroup.GET("/user/:address/activity", dappBrowserHandler.GetUserActivity)                                           // 获取用户活动记录
+			dappGroup.POST("/user/favorite", dappBrowserHandler.ManageFavorite)                                                    // 管理收藏DApp
+			dappGroup.POST("/permissions", dappBrowserHandler.GrantPermission)                                                     // 授权DApp调用指定方法
+			dappGroup.GET("/permissions", dappBrowserHandler.ListPermissions)                                                      // 查看已授权的DApp
+			dappGroup.DELETE("/permissions/:id", dappBrowserHandler.RevokePermission)                                              // 撤销DApp授权
+		}
+
+		// WalletConnect v2：配对 DApp，会话请求进入与 DApp 浏览器相同的确认流程
+		walletConnectGroup := v1.Group("/walletconnect")
+		{
+			walletConnectGroup.POST("/pair", walletConnectHandler.Pair)                                                                           // 使用 wc: URI 配对
+			walletConnectGroup.GET("/sessions", walletConnectHandler.ListSessions)                                                                // 当前钱包的会话
+			walletConnectGroup.DELETE("/sessions/:topic", walletConnectHandler.Disconnect)                                                        // 断开会话
+			walletConnectGroup.GET("/requests", walletConnectHandler.ListRequests)                                                                // 待确认的请求
+			walletConnectGroup.POST("/requests/:id/approve", ipWhitelist, middleware.TransactionRateLimit(), walletConnectHandler.ApproveRequest) // 确认并执行请求
+			walletConnectGroup.POST("/requests/:id/reject", walletConnectHandler.RejectRequest)                                                   // 拒绝请求
 		}
 
+		// 收款目标解析（地址 → 联系人 → ENS）
+		v1.GET("/resolve-recipient", socialHandler.ResolveRecipient)
+
 		// 社交功能相关路由组
 		// 提供联系人管理、交易分享等社交功能
 		socialGroup := v1.Group("/social")
@@ -291,24 +427,43 @@ func NewRouter(walletService *services.WalletService) *gin.Engine {
 			socialGroup.GET("/user/:address/profile", socialHandler.GetUserSocialProfile) // 获取用户社交资料
 			socialGroup.PUT("/user/profile", socialHandler.UpdateUserSocialProfile)       // 更新用户社交资料
 			socialGroup.GET("/search/users", socialHandler.SearchUsers)                   // 搜索用户
+			socialGroup.GET("/ens/resolve/:name", socialHandler.ResolveENS)               // ENS正向解析
+			socialGroup.GET("/ens/reverse/:address", socialHandler.LookupENSName)         // ENS反向解析
+			socialGroup.GET("/ens/avatar/:name", socialHandler.GetENSAvatar)              // ENS头像图片（ENSIP-12）
 		}
 
 		// 安全功能相关路由组
 		// 提供硬件钱包检测、多签钱包、MFA等安全功能
 		securityGroup := v1.Group("/security")
+		securityGroup.Use(middleware.AuditContext(walletService.GetSessionAddress)) // 审计日志归属（会话地址、IP、设备）
 		{
-			securityGroup.GET("/hardware/detect", securityHandler.DetectHardwareWallets)                                                 // 检测硬件钱包
-			securityGroup.POST("/hardware/request", securityHandler.ProcessHardwareWalletRequest)                                        // 处理硬件钱包请求
-			securityGroup.POST("/multisig/create", securityHandler.CreateMultiSigWallet)                                                 // 创建多签钱包
-			securityGroup.POST("/multisig/transaction/create", securityHandler.CreateMultiSigTransaction)                                // 创建多签交易
-			securityGroup.POST("/multisig/transaction/sign", middleware.TransactionRateLimit(), securityHandler.SignMultiSigTransaction) // 签名多签交易
-			securityGroup.POST("/mfa/setup", securityHandler.SetupMFA)                                                                   // 设置MFA
-			securityGroup.POST("/mfa/verify", securityHandler.VerifyMFA)                                                                 // 验证MFA
-			securityGroup.GET("/audit/logs", securityHandler.GetSecurityAuditLogs)                                                       // 获取安全审计日志
-			securityGroup.GET("/audit/report/:address", securityHandler.GetSecurityReport)                                               // 获取安全报告
-			securityGroup.POST("/biometric/enable", securityHandler.EnableBiometric)                                                     // 启用生物识别
-			securityGroup.POST("/biometric/verify", securityHandler.VerifyBiometric)                                                     // 验证生物识别
-			securityGroup.GET("/status/:address", securityHandler.GetSecurityStatus)                                                     // 获取安全状态
+			securityGroup.GET("/hardware/detect", securityHandler.DetectHardwareWallets)                                                                    // 检测硬件钱包
+			securityGroup.POST("/hardware/request", securityHandler.ProcessHardwareWalletRequest)                                                           // 处理硬件钱包请求
+			securityGroup.POST("/multisig/create", securityHandler.CreateMultiSigWallet)                                                                    // 创建多签钱包
+			securityGroup.POST("/multisig/transaction/create", securityHandler.CreateMultiSigTransaction)                                                   // 创建多签交易
+			securityGroup.POST("/multisig/transaction/sign", ipWhitelist, middleware.TransactionRateLimit(), securityHandler.SignMultiSigTransaction)       // 签名多签交易
+			securityGroup.POST("/multisig/transaction/execute", ipWhitelist, middleware.TransactionRateLimit(), securityHandler.ExecuteMultiSigTransaction) // 执行多签交易
+			securityGroup.POST("/recovery/shards", ipWhitelist, securityHandler.SplitMnemonic)                                                              // 助记词Shamir分片
+			securityGroup.POST("/recovery/reconstruct", middleware.RequireWalletImport(), mnemonicAuthHandler.AuthenticateWithShards)                       // 分片恢复钱包（同助记词登录流程）
+			securityGroup.POST("/mfa/setup", securityHandler.SetupMFA)                                                                                      // 设置MFA
+			securityGroup.POST("/mfa/verify", securityHandler.VerifyMFA)                                                                                    // 验证MFA
+			securityGroup.GET("/audit/logs", securityHandler.GetSecurityAuditLogs)                                                                          // 获取安全审计日志
+			securityGroup.GET("/ip-whitelist", securityHandler.ListIPWhitelist)                                                                             // 查询IP白名单
+			securityGroup.POST("/ip-whitelist", ipWhitelist, securityHandler.AddIPWhitelistEntry)                                                           // 添加IP白名单规则（IP或CIDR）
+			securityGroup.PUT("/ip-whitelist/:id", ipWhitelist, securityHandler.UpdateIPWhitelistEntry)                                                     // 修改IP白名单规则
+			securityGroup.DELETE("/ip-whitelist/:id", ipWhitelist, securityHandler.RemoveIPWhitelistEntry)                                                  // 删除IP白名单规则
+			securityGroup.GET("/devices", securityHandler.ListDevices)                                                                                      // 查询登录设备
+			securityGroup.POST("/devices/:id/trust", securityHandler.TrustDevice)                                                                           // 在已信任设备上确认新设备
+			securityGroup.DELETE("/devices/:id", securityHandler.RevokeDevice)                                                                              // 撤销设备并使其会话失效
+			securityGroup.GET("/blocklist", securityHandler.ListBlockedAddresses)                                                                           // 查询收款地址黑名单（global=true 为全局，仅管理员）
+			securityGroup.POST("/blocklist", ipWhitelist, securityHandler.AddBlockedAddress)                                                                // 添加黑名单地址
+			securityGroup.DELETE("/blocklist/:address", ipWhitelist, securityHandler.RemoveBlockedAddress)                                                  // 删除黑名单地址
+			securityGroup.GET("/blocklist/check/:address", securityHandler.CheckBlockedAddress)                                                             // 检查地址是否在黑名单中
+			securityGroup.GET("/audit-logs", securityHandler.QueryAuditLogs)                                                                                // 分页查询审计日志（管理员或仅自己）
+			securityGroup.GET("/audit/report/:address", securityHandler.GetSecurityReport)                                                                  // 获取安全报告
+			securityGroup.POST("/biometric/enable", securityHandler.EnableBiometric)                                                                        // 启用生物识别
+			securityGroup.POST("/biometric/verify", securityHandler.VerifyBiometric)                                                                        // 验证生物识别
+			securityGroup.GET("/status/:address", securityHandler.GetSecurityStatus)                                                                        // 获取安全状态
 		}
 
 		// 交易相关路由组
@@ -317,40 +472,78 @@ func NewRouter(walletService *services.WalletService) *gin.Engine {
 		transactionGroup.Use(middleware.TransactionRateLimit())  // 交易专用速率限制
 		transactionGroup.Use(middleware.TransactionValidation()) // 交易验证中间件
 		{
-			transactionGroup.POST("/send", walletHandler.SendTransaction)                  // 发送交易
-			transactionGroup.POST("/send-erc20", walletHandler.SendERC20)                  // 发送ERC20代币
-			transactionGroup.POST("/send-advanced", walletHandler.SendTransactionAdvanced) // 发送高级交易
-			transactionGroup.POST("/send-erc20-advanced", walletHandler.SendERC20Advanced) // 发送高级ERC20交易
-			transactionGroup.POST("/estimate", walletHandler.EstimateTransaction)          // 估算交易
-			transactionGroup.POST("/broadcast", walletHandler.BroadcastRawTransaction)     // 广播原始交易
-			transactionGroup.GET("/:hash/receipt", walletHandler.GetTxReceipt)             // 获取交易回执
+			transactionGroup.POST("/send", ipWhitelist, idempotent, walletHandler.SendTransaction)                  // 发送交易
+			transactionGroup.POST("/send-erc20", ipWhitelist, idempotent, walletHandler.SendERC20)                  // 发送ERC20代币
+			transactionGroup.POST("/send-advanced", ipWhitelist, idempotent, walletHandler.SendTransactionAdvanced) // 发送高级交易
+			transactionGroup.POST("/send-erc20-advanced", ipWhitelist, idempotent, walletHandler.SendERC20Advanced) // 发送高级ERC20交易
+			transactionGroup.POST("/batch", ipWhitelist, idempotent, walletHandler.SendBatch)                       // 批量发送（原生币/ERC20混合，nonce 连续分配）
+			transactionGroup.POST("/estimate", walletHandler.EstimateTransaction)                                   // 估算交易
+			transactionGroup.POST("/simulate", walletHandler.SimulateTransaction)                                   // 模拟交易（预检是否回滚）
+			transactionGroup.POST("/decode", walletHandler.DecodeCalldata)                                          // 解码任意 calldata（方法、参数与授权风险提示）
+			transactionGroup.POST("/access-list", walletHandler.GenerateAccessList)                                 // 生成 EIP-2930 访问列表并对比节省的Gas
+			transactionGroup.POST("/broadcast", ipWhitelist, idempotent, walletHandler.BroadcastRawTransaction)     // 广播原始交易
+			transactionGroup.POST("/replace", ipWhitelist, idempotent, walletHandler.ReplaceTransaction)            // 按 nonce 加速/取消交易
+			transactionGroup.GET("/relay/tokens", walletHandler.ListRelayTokens)                                    // 支持代付Gas的网络、转发合约与代币
+			transactionGroup.POST("/relay/build", walletHandler.BuildRelayRequest)                                  // 构造 ERC-2771 转发请求（待签署的 EIP-712 数据）
+			transactionGroup.POST("/relay", ipWhitelist, idempotent, walletHandler.RelayTransaction)                // 提交签名的转发请求，由中继账户代付Gas
+			transactionGroup.GET("/pending", walletHandler.GetPendingTransactions)                                  // 查询已发送交易的确认状态
+			transactionGroup.GET("/export", walletHandler.ExportTransactions)                                       // 流式导出交易历史并合并备注（?address=&format=csv|json）
+			transactionGroup.GET("/:hash/note", walletHandler.GetTxNote)                                            // 查询交易备注
+			transactionGroup.PUT("/:hash/note", walletHandler.SetTxNote)                                            // 设置交易备注与分类
+			transactionGroup.DELETE("/:hash/note", walletHandler.DeleteTxNote)                                      // 删除交易备注
+			transactionGroup.GET("/:hash/receipt", walletHandler.GetTxReceipt)                                      // 获取交易回执
+			transactionGroup.GET("/:hash/logs", walletHandler.GetTxLogs)                                            // 获取并解码交易事件
+			transactionGroup.GET("/:hash/wait", walletHandler.WaitForTxConfirmation)                                // 长轮询等待交易确认
+			transactionGroup.GET("/:hash/deadline", walletHandler.GetTxDeadline)                                    // 查询交易截止时间跟踪状态
+			transactionGroup.GET("/:hash/lifecycle", walletHandler.GetTransactionLifecycle)                         // 查询交易完整生命周期（审计）
+		}
+
+		// 通用合约调用路由组（按调用方提供的ABI编码参数与解码返回值）
+		contractGroup := v1.Group("/contracts")
+		{
+			contractGroup.POST("/:address/call", walletHandler.CallContractMethod)                                                             // 按ABI调用只读方法
+			contractGroup.POST("/:address/send", ipWhitelist, idempotent, middleware.TransactionRateLimit(), walletHandler.SendContractMethod) // 按ABI发送写入交易
 		}
 
 		// 代币相关路由组
-		// 提供代币元数据、授权管理等功能
+		// 提供自定义代币列表、代币元数据、授权管理等功能
 		tokenGroup := v1.Group("/tokens")
 		{
-			tokenGroup.GET("/:token/metadata", walletHandler.GetTokenMetadata) // 获取代币元数据
-			tokenGroup.POST("/:token/approve", walletHandler.ApproveToken)     // 授权代币
-			tokenGroup.GET("/:token/allowance", walletHandler.GetAllowance)    // 获取授权额度
+			tokenGroup.GET("", walletHandler.ListUserTokens)                                           // 获取自定义代币列表（?network=）
+			tokenGroup.POST("", walletHandler.AddUserToken)                                            // 添加自定义代币（校验ERC20并读取元数据）
+			tokenGroup.PUT("/:token", walletHandler.UpdateUserToken)                                   // 修改自定义代币显示符号
+			tokenGroup.DELETE("/:token", walletHandler.RemoveUserToken)                                // 删除自定义代币（?network=）
+			tokenGroup.GET("/:token/metadata", walletHandler.GetTokenMetadata)                         // 获取代币元数据
+			tokenGroup.POST("/:token/approve", ipWhitelist, idempotent, walletHandler.ApproveToken)    // 授权代币
+			tokenGroup.POST("/:token/permit", ipWhitelist, walletHandler.SignPermit)                   // 签署 EIP-2612 permit（免Gas授权）
+			tokenGroup.POST("/:token/disperse", ipWhitelist, idempotent, walletHandler.DisperseTokens) // 分发代币（空投，可使用 Disperse 合约一笔完成）
+			tokenGroup.GET("/:token/allowance", walletHandler.GetAllowance)                            // 获取授权额度
+		}
+
+		// 授权管理路由组：列出仍有额度的授权（无限授权优先），一次撤销一个或多个授权
+		approvalGroup := v1.Group("/approvals")
+		{
+			approvalGroup.GET("", walletHandler.GetActiveApprovals)                                                                  // 查询仍有额度的授权（?owner=&tokens=）
+			approvalGroup.POST("/revoke", ipWhitelist, idempotent, middleware.TransactionRateLimit(), walletHandler.RevokeApprovals) // 撤销授权（approve(spender, 0)，支持批量）
 		}
 
 		// 消息签名相关路由组
 		// 提供个人签名和EIP-712签名功能
 		signGroup := v1.Group("/sign")
 		{
-			signGroup.POST("/message", walletHandler.PersonalSign)  // 个人消息签名
-			signGroup.POST("/typed", walletHandler.SignTypedDataV4) // EIP-712签名
+			signGroup.POST("/message", ipWhitelist, walletHandler.PersonalSign)  // 个人消息签名
+			signGroup.POST("/typed", ipWhitelist, walletHandler.SignTypedDataV4) // EIP-712签名
+			signGroup.POST("/verify", walletHandler.VerifySignature)             // 验证签名（personal_sign / EIP-712）
 		}
 	}
 
 	// 健康检查接口（无需认证）
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":  "ok",
-			"message": "Wallet service is running",
-		})
-	})
+	healthHandler := handlers.NewHealthHandler(walletService.GetHealthService())
+	r.GET("/health", healthHandler.Health) // 存活检查
+	r.GET("/ready", healthHandler.Ready)   // 就绪检查（数据库与各网络RPC状态）
+
+	// Prometheus 指标（文本格式）
+	r.GET("/metrics", gin.WrapH(promhttp.HandlerFor(walletService.GetMetricsRegistry(), promhttp.HandlerOpts{})))
 
 	return r
 }
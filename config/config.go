@@ -23,16 +23,44 @@ import (
 // Config 主配置结构体，映射整个配置文件的内容
 // 包含服务器、数据库、网络、安全和Keystore配置
 type Config struct {
-	Server   ServerConfig             // 服务器配置
-	Database DatabaseConfig           // 数据库配置
-	Networks map[string]NetworkConfig `mapstructure:"networks"` // 网络配置映射
-	Security SecurityConfig           // 安全配置
-	Keystore KeystoreConfig           // 密钥库配置
+	Server        ServerConfig             // 服务器配置
+	Database      DatabaseConfig           // 数据库配置
+	Networks      map[string]NetworkConfig `mapstructure:"networks"` // 网络配置映射
+	Security      SecurityConfig           // 安全配置
+	Keystore      KeystoreConfig           // 密钥库配置
+	History       HistoryConfig            `mapstructure:"history"`            // 交易历史扫描配置
+	RPCPool       RPCPoolConfig            `mapstructure:"rpc_pool"`           // RPC 节点池故障转移与健康检查配置
+	Reserve       BalanceReserveConfig     `mapstructure:"balance_reserve"`    // 余额预留提醒配置
+	Wallet        WalletPolicyConfig       `mapstructure:"wallet"`             // 钱包创建/导入策略
+	Pending       PendingTxConfig          `mapstructure:"pending_tx"`         // 待确认交易跟踪配置
+	Phishing      PhishingConfig           `mapstructure:"phishing"`           // DApp 钓鱼网站黑名单配置
+	Blocklist     AddressBlocklistConfig   `mapstructure:"address_blocklist"`  // 收款地址黑名单配置
+	NFT           NFTConfig                `mapstructure:"nft"`                // NFT持有查询与元数据配置
+	IPFS          IPFSConfig               `mapstructure:"ipfs"`               // ipfs:// / ipns:// 内容获取配置
+	Price         PriceConfig              `mapstructure:"price"`              // 代币价格服务配置
+	Portfolio     PortfolioConfig          `mapstructure:"portfolio"`          // 跨链资产汇总配置
+	Alerts        PriceAlertConfig         `mapstructure:"price_alerts"`       // 价格提醒检查配置
+	Notifications NotificationConfig       `mapstructure:"notifications"`      // 通知投递（邮件、Webhook）配置
+	WatchMonitor  WatchOnlyMonitorConfig   `mapstructure:"watch_only_monitor"` // 只读地址到账监控配置
+	Relay         RelayConfig              `mapstructure:"relay"`              // ERC20 转账代付Gas（ERC-2771 元交易）配置
+	QRCode        QRCodeConfig             `mapstructure:"qr_code"`            // 二维码生成配置
+	Anomaly       AnomalyDetectionConfig   `mapstructure:"anomaly_detection"`  // 异常登录/交易检测配置
+	AddressInfo   AddressSummaryConfig     `mapstructure:"address_summary"`    // 地址活跃度摘要配置
+	Idempotency   IdempotencyConfig        `mapstructure:"idempotency"`        // 发送类接口 Idempotency-Key 去重配置
+	Decoder       CalldataDecoderConfig    `mapstructure:"calldata_decoder"`   // 交易 calldata 解码配置
+	Approvals     ApprovalsConfig          `mapstructure:"approvals"`          // ERC20 授权查看与撤销配置
+	FeeCeiling    FeeCeilingConfig         `mapstructure:"fee_ceiling"`        // 交易手续费上限配置
+	CORS          CORSConfig               `mapstructure:"cors"`               // 浏览器跨域访问（DApp）配置
+	WalletConnect WalletConnectConfig      `mapstructure:"walletconnect"`      // WalletConnect v2 配对与会话配置
+	Log           LogConfig                `mapstructure:"log"`                // 结构化日志配置
 }
 
 // ServerConfig HTTP服务器配置
 type ServerConfig struct {
 	Port int // 服务器监听端口
+	// TrustedProxies 可信反向代理的IP或CIDR；只有来自这些地址的请求才采信 X-Forwarded-For / X-Real-IP，
+	// 为空时客户端IP取连接的对端地址（IP白名单、速率限制、审计日志均依赖客户端IP）
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 // DatabaseConfig 数据库连接配置
@@ -49,16 +77,21 @@ type DatabaseConfig struct {
 // NetworkConfig 区块链网络配置
 // 支持多个区块链网络，包括以太坊主网、测试网、Polygon、BSC等
 type NetworkConfig struct {
-	Name             string `mapstructure:"name"`              // 网络显示名称
-	RPCURL           string `mapstructure:"rpc_url"`           // RPC节点地址
-	ChainID          int64  `mapstructure:"chain_id"`          // 区块链链 ID（EIP-155）
-	Symbol           string `mapstructure:"symbol"`            // 网络原生代币符号（如ETH、MATIC等）
-	Decimals         int    `mapstructure:"decimals"`          // 网络原生代币小数位数（通常为18）
-	BlockExplorer    string `mapstructure:"block_explorer"`    // 区块浏览器地址（如Etherscan）
-	Enabled          bool   `mapstructure:"enabled"`           // 是否启用该网络
-	Testnet          bool   `mapstructure:"testnet"`           // 是否为测试网络
-	MaxGasPrice      string `mapstructure:"max_gas_price"`     // 最大gas价格限制（wei单位）
-	MinConfirmations int    `mapstructure:"min_confirmations"` // 交易最小确认数
+	Name             string   `mapstructure:"name"`              // 网络显示名称
+	RPCURL           string   `mapstructure:"rpc_url"`           // RPC节点地址
+	FallbackRPCURLs  []string `mapstructure:"fallback_rpc_urls"` // 备用RPC节点地址（仅EVM、仅 http(s)），主节点故障时自动切换
+	ChainID          int64    `mapstructure:"chain_id"`          // 区块链链 ID（EIP-155）
+	Symbol           string   `mapstructure:"symbol"`            // 网络原生代币符号（如ETH、MATIC等）
+	Decimals         int      `mapstructure:"decimals"`          // 网络原生代币小数位数（通常为18）
+	BlockExplorer    string   `mapstructure:"block_explorer"`    // 区块浏览器地址（如Etherscan）
+	Enabled          bool     `mapstructure:"enabled"`           // 是否启用该网络
+	Testnet          bool     `mapstructure:"testnet"`           // 是否为测试网络
+	MaxGasPrice      string   `mapstructure:"max_gas_price"`     // 最大gas价格限制（wei单位）
+	MinConfirmations int      `mapstructure:"min_confirmations"` // 交易视为最终确认所需的确认数，为0时按链ID取默认值（见 RequiredConfirmations）
+	MulticallAddress string   `mapstructure:"multicall_address"` // Multicall3 合约地址（仅EVM，为空则批量查询逐个调用）
+	DisperseAddress  string   `mapstructure:"disperse_address"`  // Disperse 合约地址（仅EVM，为空则代币分发逐笔转账）
+	ExplorerAPIURL   string   `mapstructure:"explorer_api_url"`  // Etherscan 风格的区块浏览器API地址（仅EVM，为空则原生交易历史回退为区块扫描）
+	WSURL            string   `mapstructure:"ws_url"`            // 节点 WebSocket 地址（仅EVM，用于订阅新区块；为空且 rpc_url 为 ws(s):// 时使用 rpc_url）
 }
 
 // SecurityConfig 安全相关配置
@@ -68,8 +101,368 @@ type SecurityConfig struct {
 	EncryptionKey string          `mapstructure:"encryption_key"`  // 数据加密密钥（用于助记词加密）
 	RateLimit     RateLimitConfig `mapstructure:"rate_limit"`      // 速率限制配置
 	OneInchAPIKey string          `mapstructure:"oneinch_api_key"` // 1inch API密钥
+	// ProviderKeys 第三方服务的全局API密钥（alchemy/coingecko/opensea/etherscan 等）
+	// 用户未配置自己的密钥时使用
+	ProviderKeys map[string]string `mapstructure:"provider_keys"`
+	// DerivationPathAllowlist 允许用于签名/派生的路径（正则表达式，需完整匹配）
+	// 防止被入侵的客户端请求任意路径的签名；为空时允许各钱包标准的账户范围
+	DerivationPathAllowlist []string `mapstructure:"derivation_path_allowlist"`
+	// AdminAddresses 管理员钱包地址，可查询全部用户的安全审计日志；其他用户只能查询自己的日志
+	AdminAddresses []string `mapstructure:"admin_addresses"`
+	// CustomRPCAllowedHosts 自定义网络允许使用的内网RPC主机（主机名、IP 或 CIDR，如 localhost、10.0.0.0/8）
+	// 自定义RPC默认拒绝解析到回环、私有、链路本地（含云元数据）地址的主机，本地节点需在此显式放行
+	CustomRPCAllowedHosts []string `mapstructure:"custom_rpc_allowed_hosts"`
 }
 
+// DefaultDerivationPathAllowlist 默认允许的派生路径：以太坊标准（MetaMask/Trezor）、Ledger Live 与 Ledger 旧版账户范围
+var DefaultDerivationPathAllowlist = []string{
+	`m/44'/60'/0'/0/[0-9]+`,
+	`m/44'/60'/[0-9]+'/0/0`,
+	`m/44'/60'/0'/[0-9]+`,
+}
+
+// HistoryConfig 交易历史区块扫描配置
+// 扫描批次大小按节点表现自适应调整（AIMD），始终限制在 [MinBatchSize, MaxBatchSize] 内；
+// 扫描结果按 CacheStore 缓存，分页时只增量扫描新区块
+type HistoryConfig struct {
+	InitialBatchSize uint64 `mapstructure:"initial_batch_size"` // 初始每批区块数
+	MinBatchSize     uint64 `mapstructure:"min_batch_size"`     // 最小每批区块数
+	MaxBatchSize     uint64 `mapstructure:"max_batch_size"`     // 最大每批区块数
+	TargetLatencyMs  int    `mapstructure:"target_latency_ms"`  // 单批目标耗时（毫秒），超过则缩小批次
+
+	CacheStore        string `mapstructure:"cache_store"`         // memory：进程内缓存；database：持久化到数据库并用 SQL 分页；disabled：不缓存
+	CacheTTLMinutes   int    `mapstructure:"cache_ttl_minutes"`   // 内存缓存中地址的空闲淘汰时间（分钟）
+	CacheMaxAddresses int    `mapstructure:"cache_max_addresses"` // 内存缓存最多保留的 (网络, 地址) 数
+}
+
+// 交易历史缓存方式
+const (
+	HistoryCacheMemory   = "memory"
+	HistoryCacheDatabase = "database"
+	HistoryCacheDisabled = "disabled"
+)
+
+// RPCPoolConfig RPC 节点池配置
+// 网络配置了备用节点时，请求失败会自动重试下一个节点；连续失败达到阈值的节点标记为不健康，
+// 后台定期以 eth_blockNumber 检查所有节点，优先使用区块最新且延迟最低的节点
+type RPCPoolConfig struct {
+	HealthCheckIntervalSeconds int    `mapstructure:"health_check_interval_seconds"` // 健康检查间隔（秒）
+	FailureThreshold           int    `mapstructure:"failure_threshold"`             // 连续失败多少次标记为不健康
+	MaxBlockLag                uint64 `mapstructure:"max_block_lag"`                 // 落后最新区块超过该值的节点不优先使用
+}
+
+// QRCodeConfig 二维码生成配置
+// 请求未指定尺寸或纠错级别时使用默认值，请求指定的尺寸不能超过 MaxSize
+type QRCodeConfig struct {
+	DefaultSize   int    `mapstructure:"default_size"`   // 默认边长（像素）
+	MaxSize       int    `mapstructure:"max_size"`       // 允许的最大边长（像素）
+	RecoveryLevel string `mapstructure:"recovery_level"` // 默认纠错级别：low / medium / high / highest
+}
+
+// AnomalyDetectionConfig 异常登录与交易检测配置
+// 各风险信号的分数相加（上限1）得到风险分数：达到 ChallengeThreshold 需额外验证，达到 DenyThreshold 直接拒绝
+type AnomalyDetectionConfig struct {
+	Enabled               bool    `mapstructure:"enabled"`                 // 是否启用（默认启用）
+	ChallengeThreshold    float64 `mapstructure:"challenge_threshold"`     // 需额外验证（双因素验证码）的风险分数
+	DenyThreshold         float64 `mapstructure:"deny_threshold"`          // 直接拒绝的风险分数
+	NewDeviceScore        float64 `mapstructure:"new_device_score"`        // 新设备的风险分数
+	NewLocationScore      float64 `mapstructure:"new_location_score"`      // 新国家/地区的风险分数
+	ImpossibleTravelScore float64 `mapstructure:"impossible_travel_score"` // 不可能的移动（短时间内异地登录）的风险分数
+	LargeValueScore       float64 `mapstructure:"large_value_score"`       // 交易金额远超历史水平的风险分数
+	MaxTravelSpeedKmh     float64 `mapstructure:"max_travel_speed_kmh"`    // 两次事件之间允许的最大移动速度（公里/小时）
+	TravelWindowMinutes   int     `mapstructure:"travel_window_minutes"`   // 无坐标时，该时间窗口内国家变化视为不可能的移动
+	LargeValueMultiplier  float64 `mapstructure:"large_value_multiplier"`  // 交易金额超过历史中位数的倍数视为异常
+	MinTxHistory          int     `mapstructure:"min_tx_history"`          // 同一资产至少有多少笔历史交易才评估金额异常
+	MaxEventsPerUser      int     `mapstructure:"max_events_per_user"`     // 每个用户保留的最近事件数
+	RetentionDays         int     `mapstructure:"retention_days"`          // 事件保留天数（用于计算基线）
+}
+
+// BalanceReserveConfig 原生代币余额预留配置
+// 发送后余额低于预留值时在响应中给出提醒（不阻止发送），避免余额不足以支付后续Gas
+type BalanceReserveConfig struct {
+	Mode         string `mapstructure:"mode"`         // 预留方式：absolute（固定金额）/ transactions（按N笔交易Gas动态计算）/ disabled
+	AbsoluteWei  string `mapstructure:"absolute_wei"` // absolute 模式下的预留金额（wei，十进制字符串）
+	Transactions int    `mapstructure:"transactions"` // transactions 模式下预留的交易笔数
+	TransferGas  uint64 `mapstructure:"transfer_gas"` // 单笔普通转账的Gas用量
+}
+
+// 余额预留方式
+const (
+	ReserveModeAbsolute     = "absolute"
+	ReserveModeTransactions = "transactions"
+	ReserveModeDisabled     = "disabled"
+)
+
+// WalletPolicyConfig 钱包创建/导入策略
+// 托管部署中钱包仅通过内部流程发放时，可关闭用户自助创建与导入；已有钱包的操作不受影响
+type WalletPolicyConfig struct {
+	AllowWalletCreation bool `mapstructure:"allow_wallet_creation"` // 是否允许用户创建新钱包（默认允许）
+	AllowWalletImport   bool `mapstructure:"allow_wallet_import"`   // 是否允许用户导入钱包（默认允许）
+}
+
+// PendingTxConfig 待确认交易跟踪配置
+// 后台按 PollIntervalSeconds 轮询回执；提交超过 DropTimeoutMinutes 仍查不到且 nonce 已被使用的交易视为被丢弃
+type PendingTxConfig struct {
+	PollIntervalSeconds int `mapstructure:"poll_interval_seconds"` // 回执轮询间隔（秒）
+	DropTimeoutMinutes  int `mapstructure:"drop_timeout_minutes"`  // 判定交易被丢弃的等待时长（分钟）
+	RetentionHours      int `mapstructure:"retention_hours"`       // 跟踪记录保留时长（小时）
+}
+
+// PhishingConfig DApp 钓鱼网站黑名单配置
+// 黑名单格式与 MetaMask eth-phishing-detect 的 config.json 一致（blacklist / whitelist / fuzzylist / tolerance）
+type PhishingConfig struct {
+	ListURL        string `mapstructure:"list_url"`        // 黑名单地址，为空时使用 eth-phishing-detect 社区列表
+	RefreshMinutes int    `mapstructure:"refresh_minutes"` // 刷新间隔（分钟）
+}
+
+// AddressBlocklistConfig 收款地址黑名单配置
+// 全局黑名单由配置中的静态地址、远程名单与管理员添加的地址组成，用户还可维护自己的黑名单
+type AddressBlocklistConfig struct {
+	Mode           string   `mapstructure:"mode"`            // 命中时的处理方式：block 直接拒绝；warn 提示风险，需确认后才能发送
+	FeedURLs       []string `mapstructure:"feed_urls"`       // 远程名单地址（每行一个地址的文本或 JSON 数组）
+	RefreshMinutes int      `mapstructure:"refresh_minutes"` // 远程名单刷新间隔（分钟）
+	Addresses      []string `mapstructure:"addresses"`       // 静态黑名单，格式为 "地址" 或 "地址,原因"
+}
+
+// 收款地址黑名单处理方式
+const (
+	BlocklistModeBlock = "block"
+	BlocklistModeWarn  = "warn"
+)
+
+// DefaultPhishingListURL eth-phishing-detect 社区维护的钓鱼域名列表
+const DefaultPhishingListURL = "https://raw.githubusercontent.com/MetaMask/eth-phishing-detect/main/src/config.json"
+
+// PriceConfig 代币价格服务配置
+// 价格优先取自 CoinGecko，原生币可回退到链上 Chainlink 喂价；结果（包括无价格）缓存 CacheTTLSeconds 秒
+type PriceConfig struct {
+	CoinGeckoURL      string `mapstructure:"coingecko_url"`      // CoinGecko API 地址，为空时使用公共API
+	CacheTTLSeconds   int    `mapstructure:"cache_ttl_seconds"`  // 价格缓存时长（秒）
+	ChainlinkFallback bool   `mapstructure:"chainlink_fallback"` // CoinGecko 不可用时是否读取 Chainlink 原生币喂价
+}
+
+// PriceAlertConfig 价格提醒后台检查配置（通知经 NotificationConfig 配置的渠道投递）
+type PriceAlertConfig struct {
+	IntervalSeconds int `mapstructure:"interval_seconds"` // 检查间隔（秒），同一合约/链的提醒合并为一次价格查询
+	HistoryLimit    int `mapstructure:"history_limit"`    // 每个用户保留的触发记录数
+}
+
+// NotificationConfig 通知投递配置
+// 未配置 SMTPHost 时不发送邮件通知，收件箱、推送与 Webhook 不受影响
+type NotificationConfig struct {
+	SMTPHost           string `mapstructure:"smtp_host"`            // 邮件服务器地址
+	SMTPPort           int    `mapstructure:"smtp_port"`            // 邮件服务器端口
+	SMTPUsername       string `mapstructure:"smtp_username"`        // 邮件服务器用户名
+	SMTPPassword       string `mapstructure:"smtp_password"`        // 邮件服务器密码
+	EmailFrom          string `mapstructure:"email_from"`           // 发件人地址
+	WebhookTimeoutSec  int    `mapstructure:"webhook_timeout_sec"`  // Webhook 单次请求超时（秒）
+	WebhookMaxAttempts int    `mapstructure:"webhook_max_attempts"` // Webhook 最多投递次数（含首次）
+	WebhookBackoffSec  int    `mapstructure:"webhook_backoff_sec"`  // 首次重试等待（秒），之后每次翻倍
+}
+
+// WatchOnlyMonitorConfig 只读地址到账监控配置
+// 后台按 IntervalSeconds 扫描新区块中转入只读地址的原生代币与 ERC20 转账，落后较多时每轮最多处理 MaxBlocksPerPoll 个区块
+type WatchOnlyMonitorConfig struct {
+	IntervalSeconds  int    `mapstructure:"interval_seconds"`    // 扫描间隔（秒）
+	MaxBlocksPerPoll uint64 `mapstructure:"max_blocks_per_poll"` // 每个网络每轮最多扫描的区块数
+}
+
+// RelayConfig ERC20 转账代付Gas配置
+// 用户签署 ERC-2771 转发请求，由中继账户调用转发合约上链并支付Gas；未配置中继账户私钥时不启用
+type RelayConfig struct {
+	RelayerPrivateKey string                          `mapstructure:"relayer_private_key"` // 支付Gas的中继账户私钥（建议通过环境变量 RELAY_RELAYER_PRIVATE_KEY 设置）
+	FeeRecipient      string                          `mapstructure:"fee_recipient"`       // 代付手续费收款地址，为空时使用中继账户地址
+	DailyQuota        int                             `mapstructure:"daily_quota"`         // 每个用户24小时内可代付的转账次数
+	MaxGas            uint64                          `mapstructure:"max_gas"`             // 单个转发请求内部调用的 gas 上限
+	Forwarders        map[string]RelayForwarderConfig `mapstructure:"forwarders"`          // 网络ID -> 可信转发合约与支持的代币
+}
+
+// RelayForwarderConfig 单个网络的可信转发合约（兼容 OpenZeppelin MinimalForwarder）
+type RelayForwarderConfig struct {
+	Address       string             `mapstructure:"address"`        // 转发合约地址
+	DomainName    string             `mapstructure:"domain_name"`    // EIP-712 域名称
+	DomainVersion string             `mapstructure:"domain_version"` // EIP-712 域版本
+	Tokens        []RelayTokenConfig `mapstructure:"tokens"`         // 支持代付的代币（需信任该转发合约）
+}
+
+// RelayTokenConfig 支持代付的代币
+type RelayTokenConfig struct {
+	Address string `mapstructure:"address"` // 代币合约地址
+	Fee     string `mapstructure:"fee"`     // 每笔代付按该代币收取的手续费（最小单位，十进制字符串），为空或0时免费
+}
+
+// DefaultCoinGeckoURL CoinGecko 公共API地址
+const DefaultCoinGeckoURL = "https://api.coingecko.com/api/v3"
+
+// PortfolioConfig 跨链资产汇总配置
+// 未指定代币列表时，从最近 DetectLookbackBlocks 个区块的 Transfer 日志自动识别持有的代币
+type PortfolioConfig struct {
+	CacheTTLSeconds      int    `mapstructure:"cache_ttl_seconds"`      // 汇总结果缓存时长（秒）
+	DetectLookbackBlocks uint64 `mapstructure:"detect_lookback_blocks"` // 自动识别代币回溯的区块数
+	MaxTokens            int    `mapstructure:"max_tokens"`             // 每个网络最多查询的代币数
+}
+
+// AddressSummaryConfig 地址活跃度摘要配置
+// nonce、余额会随新交易变化，摘要只做短时缓存
+type AddressSummaryConfig struct {
+	CacheTTLSeconds      int    `mapstructure:"cache_ttl_seconds"`      // 摘要缓存时长（秒）
+	DetectLookbackBlocks uint64 `mapstructure:"detect_lookback_blocks"` // 识别持有代币与最近转账回溯的区块数
+	MaxTokens            int    `mapstructure:"max_tokens"`             // 最多查询余额的代币数
+}
+
+// IdempotencyConfig 发送类接口的 Idempotency-Key 去重配置
+// 幂等键与首次请求的响应保存 TTLHours 小时，期间相同键的重试直接返回原响应，不再广播交易
+type IdempotencyConfig struct {
+	TTLHours int `mapstructure:"ttl_hours"` // 幂等键保留时长（小时）
+}
+
+// CalldataDecoderConfig 交易 calldata 解码配置
+// 未提供ABI且内置方法无法识别时，按4字节选择器查询公开签名库；查询结果（包括无结果）缓存 CacheHours 小时
+type CalldataDecoderConfig struct {
+	SignatureDBURL string `mapstructure:"signature_db_url"` // 签名库查询接口，为空时使用 4byte.directory
+	CacheHours     int    `mapstructure:"cache_hours"`      // 选择器查询结果缓存时长（小时）
+}
+
+// ApprovalsConfig ERC20 授权查看与撤销配置
+// 扫描最近 LookbackBlocks 个区块内的 Approval 日志；SpenderLabels 为自定义的被授权地址标签，与内置的常见合约标签合并
+type ApprovalsConfig struct {
+	LookbackBlocks uint64            `mapstructure:"lookback_blocks"` // Approval 日志回溯的区块数
+	MaxPairs       int               `mapstructure:"max_pairs"`       // 最多查询额度的 (代币, spender) 数
+	SpenderLabels  map[string]string `mapstructure:"spender_labels"`  // 被授权地址 -> 标签
+}
+
+// FeeCeilingConfig 交易手续费上限配置
+// 用户未设置手续费上限时使用 DefaultMaxFeeUSD（按原生币美元价格折算），0 表示默认不限制
+type FeeCeilingConfig struct {
+	DefaultMaxFeeUSD float64 `mapstructure:"default_max_fee_usd"` // 默认单笔交易最高手续费（美元）
+}
+
+// CORSConfig 跨域资源共享配置
+// 只有 AllowedOrigins 中的来源会收到 CORS 响应头，未配置时拒绝所有跨域浏览器请求：
+//   - 完整来源，如 https://app.example.com（协议、主机与端口需一致）
+//   - 子域名通配，如 https://*.example.com 匹配 https://a.example.com、https://a.b.example.com，不匹配 example.com 本身；
+//     省略协议时（*.example.com）匹配任意协议
+//   - "*" 允许所有来源，此时不返回 Access-Control-Allow-Credentials
+type CORSConfig struct {
+	AllowedOrigins   []string `mapstructure:"allowed_origins"`   // 允许的来源
+	AllowedMethods   []string `mapstructure:"allowed_methods"`   // 允许的请求方法
+	AllowedHeaders   []string `mapstructure:"allowed_headers"`   // 允许的请求头（Authorization、Content-Type 与 Idempotency-Key 始终包含）
+	ExposedHeaders   []string `mapstructure:"exposed_headers"`   // 浏览器脚本可读取的响应头
+	AllowCredentials bool     `mapstructure:"allow_credentials"` // 是否允许携带 Cookie 等凭据
+	MaxAgeSeconds    int      `mapstructure:"max_age_seconds"`   // 预检结果缓存时间（秒）
+}
+
+// WalletConnectConfig WalletConnect v2 配置
+// 未配置 ProjectID（在 WalletConnect Cloud 创建项目获得）时不启用；Name、Description、URL、Icons 为展示给 DApp 的钱包信息
+type WalletConnectConfig struct {
+	ProjectID          string   `mapstructure:"project_id"`           // 项目ID，可通过环境变量 WALLETCONNECT_PROJECT_ID 设置
+	RelayURL           string   `mapstructure:"relay_url"`            // 中继 WebSocket 地址
+	Name               string   `mapstructure:"name"`                 // 钱包名称
+	Description        string   `mapstructure:"description"`          // 钱包描述
+	URL                string   `mapstructure:"url"`                  // 钱包网站
+	Icons              []string `mapstructure:"icons"`                // 钱包图标地址
+	SessionExpiryHours int      `mapstructure:"session_expiry_hours"` // 批准的会话有效期（小时），协议上限为 168
+}
+
+// DefaultWalletConnectRelayURL WalletConnect 官方中继
+const DefaultWalletConnectRelayURL = "wss://relay.walletconnect.org"
+
+// WithDefaults 填充 WalletConnect 配置的默认值
+func (wc WalletConnectConfig) WithDefaults() WalletConnectConfig {
+	if wc.RelayURL == "" {
+		wc.RelayURL = DefaultWalletConnectRelayURL
+	}
+	if wc.Name == "" {
+		wc.Name = "Wallet"
+	}
+	if wc.Icons == nil {
+		wc.Icons = []string{}
+	}
+	if wc.SessionExpiryHours <= 0 || wc.SessionExpiryHours > 168 {
+		wc.SessionExpiryHours = 168
+	}
+	return wc
+}
+
+// LogConfig 日志配置
+// 日志输出到标准输出，助记词、私钥、签名等内容在输出前脱敏（见 pkg/logger）
+type LogConfig struct {
+	Level  string `mapstructure:"level"`  // 日志级别：debug / info / warn / error
+	Format string `mapstructure:"format"` // 输出格式：json / text
+}
+
+// WithDefaults 填充日志配置的默认值，未知的级别按 info、未知的格式按 json 处理
+func (lc LogConfig) WithDefaults() LogConfig {
+	lc.Level = strings.ToLower(strings.TrimSpace(lc.Level))
+	switch lc.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		lc.Level = "info"
+	}
+	lc.Format = strings.ToLower(strings.TrimSpace(lc.Format))
+	if lc.Format != "text" {
+		lc.Format = "json"
+	}
+	return lc
+}
+
+// corsRequiredHeaders 始终允许的请求头：JWT 认证、JSON 请求体与发送类接口的幂等键
+var corsRequiredHeaders = []string{"Authorization", "Content-Type", "Idempotency-Key"}
+
+// WithDefaults 填充默认值，并确保必需的请求头在允许列表中
+func (cc CORSConfig) WithDefaults() CORSConfig {
+	if len(cc.AllowedMethods) == 0 {
+		cc.AllowedMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	}
+	if len(cc.AllowedHeaders) == 0 {
+		cc.AllowedHeaders = []string{"Origin", "Accept", "X-Requested-With", "X-API-Key", "X-User-Address", "X-Device-ID"}
+	}
+	headers := append([]string(nil), cc.AllowedHeaders...)
+	for _, required := range corsRequiredHeaders {
+		found := false
+		for _, h := range headers {
+			if strings.EqualFold(h, required) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			headers = append(headers, required)
+		}
+	}
+	cc.AllowedHeaders = headers
+	if len(cc.ExposedHeaders) == 0 {
+		cc.ExposedHeaders = []string{"Content-Length", "X-Request-ID", "Idempotent-Replayed"}
+	}
+	if cc.MaxAgeSeconds <= 0 {
+		cc.MaxAgeSeconds = 600
+	}
+	return cc
+}
+
+// DefaultSignatureDBURL 4byte.directory 的函数签名查询接口
+const DefaultSignatureDBURL = "https://www.4byte.directory/api/v1/signatures/"
+
+// NFTConfig NFT持有查询与元数据配置
+// 未指定合约时，从最近 DetectLookbackBlocks 个区块的 Transfer / TransferSingle / TransferBatch 日志识别持有的NFT合约
+type NFTConfig struct {
+	MetadataCacheMinutes  int    `mapstructure:"metadata_cache_minutes"`  // 元数据缓存时长（分钟）
+	OwnershipCacheSeconds int    `mapstructure:"ownership_cache_seconds"` // 持有列表缓存时长（秒），分页翻页时复用
+	DetectLookbackBlocks  uint64 `mapstructure:"detect_lookback_blocks"`  // 日志回溯的区块数
+	MaxTokensPerContract  int    `mapstructure:"max_tokens_per_contract"` // 每个合约最多枚举的代币数
+}
+
+// IPFSConfig ipfs:// 与 ipns:// 内容获取配置（NFT元数据、ENS头像）
+// 网关按顺序尝试，前一个失败（超时、非200）时使用下一个
+type IPFSConfig struct {
+	Gateways       []string `mapstructure:"gateways"`        // 网关根地址，如 https://ipfs.io，拼接 /ipfs/<cid> 或 /ipns/<name>
+	TimeoutSeconds int      `mapstructure:"timeout_seconds"` // 单个网关的请求超时（秒）
+	MaxContentKB   int      `mapstructure:"max_content_kb"`  // 响应内容大小上限（KB）
+}
+
+// DefaultIPFSGateways 未配置网关时使用的公共网关
+var DefaultIPFSGateways = []string{"https://ipfs.io", "https://dweb.link", "https://cloudflare-ipfs.com"}
+
 // RateLimitConfig API速率限制配置
 // 为不同API类型设置不同的限制策略
 type RateLimitConfig struct {
@@ -103,6 +496,11 @@ func LoadConfig() {
 	// 设置环境变量中单词间的分隔符
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
+	// 钱包策略默认允许创建与导入（配置项缺失时不改变原有行为）
+	viper.SetDefault("wallet.allow_wallet_creation", true)
+	viper.SetDefault("wallet.allow_wallet_import", true)
+	viper.SetDefault("anomaly_detection.enabled", true)
+
 	// 读取配置文件
 	if err := viper.ReadInConfig(); err != nil {
 		panic(fmt.Errorf("fatal error config file: %w", err))
@@ -148,6 +546,379 @@ func validateConfig() {
 	if AppConfig.Security.RateLimit.Auth == 0 {
 		AppConfig.Security.RateLimit.Auth = 5 // 默认每分钟5次认证请求
 	}
+
+	// 为历史扫描批次设置默认值
+	AppConfig.History = AppConfig.History.WithDefaults()
+
+	// 为RPC节点池设置默认值
+	AppConfig.RPCPool = AppConfig.RPCPool.WithDefaults()
+
+	// 为二维码生成设置默认值
+	AppConfig.QRCode = AppConfig.QRCode.WithDefaults()
+
+	// 为异常检测设置默认值
+	AppConfig.Anomaly = AppConfig.Anomaly.WithDefaults()
+
+	// 为派生路径白名单设置默认值
+	if len(AppConfig.Security.DerivationPathAllowlist) == 0 {
+		AppConfig.Security.DerivationPathAllowlist = DefaultDerivationPathAllowlist
+	}
+
+	// 为余额预留提醒设置默认值
+	AppConfig.Reserve = AppConfig.Reserve.WithDefaults()
+
+	// 为待确认交易跟踪设置默认值
+	AppConfig.Pending = AppConfig.Pending.WithDefaults()
+
+	// 为钓鱼黑名单设置默认值
+	AppConfig.Phishing = AppConfig.Phishing.WithDefaults()
+
+	// 为收款地址黑名单设置默认值
+	AppConfig.Blocklist = AppConfig.Blocklist.WithDefaults()
+
+	// 为NFT持有查询设置默认值
+	AppConfig.NFT = AppConfig.NFT.WithDefaults()
+	// 为IPFS内容获取设置默认值
+	AppConfig.IPFS = AppConfig.IPFS.WithDefaults()
+
+	// 为日志设置默认值
+	AppConfig.Log = AppConfig.Log.WithDefaults()
+}
+
+// WithDefaults 填充钓鱼黑名单配置的默认值
+func (pc PhishingConfig) WithDefaults() PhishingConfig {
+	if pc.ListURL == "" {
+		pc.ListURL = DefaultPhishingListURL
+	}
+	if pc.RefreshMinutes <= 0 {
+		pc.RefreshMinutes = 60
+	}
+	return pc
+}
+
+// WithDefaults 填充收款地址黑名单配置的默认值，未知的处理方式按 block 处理
+func (bc AddressBlocklistConfig) WithDefaults() AddressBlocklistConfig {
+	bc.Mode = strings.ToLower(strings.TrimSpace(bc.Mode))
+	if bc.Mode != BlocklistModeWarn {
+		bc.Mode = BlocklistModeBlock
+	}
+	if bc.RefreshMinutes <= 0 {
+		bc.RefreshMinutes = 360
+	}
+	return bc
+}
+
+// WithDefaults 填充代币价格服务配置的默认值
+func (pc PriceConfig) WithDefaults() PriceConfig {
+	if pc.CoinGeckoURL == "" {
+		pc.CoinGeckoURL = DefaultCoinGeckoURL
+	}
+	pc.CoinGeckoURL = strings.TrimRight(pc.CoinGeckoURL, "/")
+	if pc.CacheTTLSeconds <= 0 {
+		pc.CacheTTLSeconds = 60
+	}
+	return pc
+}
+
+// WithDefaults 填充跨链资产汇总配置的默认值
+func (pc PortfolioConfig) WithDefaults() PortfolioConfig {
+	if pc.CacheTTLSeconds <= 0 {
+		pc.CacheTTLSeconds = 30
+	}
+	if pc.DetectLookbackBlocks == 0 {
+		pc.DetectLookbackBlocks = 10000
+	}
+	if pc.MaxTokens <= 0 {
+		pc.MaxTokens = 50
+	}
+	return pc
+}
+
+// WithDefaults 填充地址活跃度摘要配置的默认值
+func (ac AddressSummaryConfig) WithDefaults() AddressSummaryConfig {
+	if ac.CacheTTLSeconds <= 0 {
+		ac.CacheTTLSeconds = 60
+	}
+	if ac.DetectLookbackBlocks == 0 {
+		ac.DetectLookbackBlocks = 10000
+	}
+	if ac.MaxTokens <= 0 {
+		ac.MaxTokens = 50
+	}
+	return ac
+}
+
+// WithDefaults 填充幂等键配置的默认值
+func (ic IdempotencyConfig) WithDefaults() IdempotencyConfig {
+	if ic.TTLHours <= 0 {
+		ic.TTLHours = 24
+	}
+	return ic
+}
+
+// WithDefaults 填充 calldata 解码配置的默认值
+func (dc CalldataDecoderConfig) WithDefaults() CalldataDecoderConfig {
+	if dc.SignatureDBURL == "" {
+		dc.SignatureDBURL = DefaultSignatureDBURL
+	}
+	if dc.CacheHours <= 0 {
+		dc.CacheHours = 24
+	}
+	return dc
+}
+
+// WithDefaults 填充授权查看配置的默认值
+func (ac ApprovalsConfig) WithDefaults() ApprovalsConfig {
+	if ac.LookbackBlocks == 0 {
+		ac.LookbackBlocks = 100000
+	}
+	if ac.MaxPairs <= 0 {
+		ac.MaxPairs = 200
+	}
+	return ac
+}
+
+// WithDefaults 填充NFT配置的默认值
+func (nc NFTConfig) WithDefaults() NFTConfig {
+	if nc.MetadataCacheMinutes <= 0 {
+		nc.MetadataCacheMinutes = 60
+	}
+	if nc.OwnershipCacheSeconds <= 0 {
+		nc.OwnershipCacheSeconds = 60
+	}
+	if nc.DetectLookbackBlocks == 0 {
+		nc.DetectLookbackBlocks = 10000
+	}
+	if nc.MaxTokensPerContract <= 0 {
+		nc.MaxTokensPerContract = 500
+	}
+	return nc
+}
+
+// WithDefaults 填充IPFS配置的默认值，网关地址去掉末尾的 / 与 /ipfs
+func (ic IPFSConfig) WithDefaults() IPFSConfig {
+	gateways := make([]string, 0, len(ic.Gateways))
+	for _, gateway := range ic.Gateways {
+		gateway = strings.TrimSuffix(strings.TrimRight(strings.TrimSpace(gateway), "/"), "/ipfs")
+		if gateway != "" {
+			gateways = append(gateways, gateway)
+		}
+	}
+	if len(gateways) == 0 {
+		gateways = append(gateways, DefaultIPFSGateways...)
+	}
+	ic.Gateways = gateways
+	if ic.TimeoutSeconds <= 0 {
+		ic.TimeoutSeconds = 10
+	}
+	if ic.MaxContentKB <= 0 {
+		ic.MaxContentKB = 2048
+	}
+	return ic
+}
+
+// WithDefaults 填充价格提醒配置的默认值
+func (ac PriceAlertConfig) WithDefaults() PriceAlertConfig {
+	if ac.IntervalSeconds <= 0 {
+		ac.IntervalSeconds = 60
+	}
+	if ac.HistoryLimit <= 0 {
+		ac.HistoryLimit = 100
+	}
+	return ac
+}
+
+// WithDefaults 填充通知投递配置的默认值
+func (nc NotificationConfig) WithDefaults() NotificationConfig {
+	if nc.SMTPPort <= 0 {
+		nc.SMTPPort = 587
+	}
+	if nc.EmailFrom == "" {
+		nc.EmailFrom = nc.SMTPUsername
+	}
+	if nc.WebhookTimeoutSec <= 0 {
+		nc.WebhookTimeoutSec = 10
+	}
+	if nc.WebhookMaxAttempts <= 0 {
+		nc.WebhookMaxAttempts = 5
+	}
+	if nc.WebhookBackoffSec <= 0 {
+		nc.WebhookBackoffSec = 2
+	}
+	return nc
+}
+
+// WithDefaults 填充只读地址到账监控配置的默认值
+func (wc WatchOnlyMonitorConfig) WithDefaults() WatchOnlyMonitorConfig {
+	if wc.IntervalSeconds <= 0 {
+		wc.IntervalSeconds = 30
+	}
+	if wc.MaxBlocksPerPoll == 0 {
+		wc.MaxBlocksPerPoll = 100
+	}
+	return wc
+}
+
+// WithDefaults 填充代付Gas配置的默认值
+func (rc RelayConfig) WithDefaults() RelayConfig {
+	if rc.DailyQuota <= 0 {
+		rc.DailyQuota = 10
+	}
+	if rc.MaxGas == 0 {
+		rc.MaxGas = 150000
+	}
+	forwarders := make(map[string]RelayForwarderConfig, len(rc.Forwarders))
+	for networkID, forwarder := range rc.Forwarders {
+		if forwarder.DomainName == "" {
+			forwarder.DomainName = "MinimalForwarder"
+		}
+		if forwarder.DomainVersion == "" {
+			forwarder.DomainVersion = "0.0.1"
+		}
+		forwarders[networkID] = forwarder
+	}
+	rc.Forwarders = forwarders
+	return rc
+}
+
+// WithDefaults 填充待确认交易跟踪配置的默认值
+func (pc PendingTxConfig) WithDefaults() PendingTxConfig {
+	if pc.PollIntervalSeconds <= 0 {
+		pc.PollIntervalSeconds = 15
+	}
+	if pc.DropTimeoutMinutes <= 0 {
+		pc.DropTimeoutMinutes = 30
+	}
+	if pc.RetentionHours <= 0 {
+		pc.RetentionHours = 24
+	}
+	return pc
+}
+
+// WithDefaults 填充余额预留配置的默认值
+func (rc BalanceReserveConfig) WithDefaults() BalanceReserveConfig {
+	if rc.Mode == "" {
+		rc.Mode = ReserveModeTransactions
+	}
+	if rc.Transactions <= 0 {
+		rc.Transactions = 3
+	}
+	if rc.TransferGas == 0 {
+		rc.TransferGas = 21000
+	}
+	return rc
+}
+
+// WithDefaults 填充异常检测配置的默认值
+func (ac AnomalyDetectionConfig) WithDefaults() AnomalyDetectionConfig {
+	if ac.ChallengeThreshold <= 0 {
+		ac.ChallengeThreshold = 0.5
+	}
+	if ac.DenyThreshold <= 0 {
+		ac.DenyThreshold = 0.9
+	}
+	if ac.DenyThreshold < ac.ChallengeThreshold {
+		ac.DenyThreshold = ac.ChallengeThreshold
+	}
+	if ac.NewDeviceScore <= 0 {
+		ac.NewDeviceScore = 0.3
+	}
+	if ac.NewLocationScore <= 0 {
+		ac.NewLocationScore = 0.3
+	}
+	if ac.ImpossibleTravelScore <= 0 {
+		ac.ImpossibleTravelScore = 0.6
+	}
+	if ac.LargeValueScore <= 0 {
+		ac.LargeValueScore = 0.5
+	}
+	if ac.MaxTravelSpeedKmh <= 0 {
+		ac.MaxTravelSpeedKmh = 900
+	}
+	if ac.TravelWindowMinutes <= 0 {
+		ac.TravelWindowMinutes = 60
+	}
+	if ac.LargeValueMultiplier <= 1 {
+		ac.LargeValueMultiplier = 5
+	}
+	if ac.MinTxHistory <= 0 {
+		ac.MinTxHistory = 3
+	}
+	if ac.MaxEventsPerUser <= 0 {
+		ac.MaxEventsPerUser = 200
+	}
+	if ac.RetentionDays <= 0 {
+		ac.RetentionDays = 90
+	}
+	return ac
+}
+
+// WithDefaults 填充二维码配置的默认值
+func (qc QRCodeConfig) WithDefaults() QRCodeConfig {
+	if qc.DefaultSize <= 0 {
+		qc.DefaultSize = 256
+	}
+	if qc.MaxSize <= 0 {
+		qc.MaxSize = 1024
+	}
+	if qc.DefaultSize > qc.MaxSize {
+		qc.DefaultSize = qc.MaxSize
+	}
+	if qc.RecoveryLevel == "" {
+		qc.RecoveryLevel = "medium"
+	}
+	return qc
+}
+
+// WithDefaults 填充RPC节点池配置的默认值
+func (rc RPCPoolConfig) WithDefaults() RPCPoolConfig {
+	if rc.HealthCheckIntervalSeconds <= 0 {
+		rc.HealthCheckIntervalSeconds = 30
+	}
+	if rc.FailureThreshold <= 0 {
+		rc.FailureThreshold = 3
+	}
+	if rc.MaxBlockLag == 0 {
+		rc.MaxBlockLag = 3
+	}
+	return rc
+}
+
+// WithDefaults 填充历史扫描配置的默认值并修正非法范围
+func (hc HistoryConfig) WithDefaults() HistoryConfig {
+	if hc.MinBatchSize == 0 {
+		hc.MinBatchSize = 10
+	}
+	if hc.MaxBatchSize == 0 {
+		hc.MaxBatchSize = 1000
+	}
+	if hc.MaxBatchSize < hc.MinBatchSize {
+		hc.MaxBatchSize = hc.MinBatchSize
+	}
+	if hc.InitialBatchSize == 0 {
+		hc.InitialBatchSize = 100
+	}
+	if hc.InitialBatchSize < hc.MinBatchSize {
+		hc.InitialBatchSize = hc.MinBatchSize
+	}
+	if hc.InitialBatchSize > hc.MaxBatchSize {
+		hc.InitialBatchSize = hc.MaxBatchSize
+	}
+	if hc.TargetLatencyMs <= 0 {
+		hc.TargetLatencyMs = 3000
+	}
+	switch hc.CacheStore {
+	case HistoryCacheMemory, HistoryCacheDatabase, HistoryCacheDisabled:
+	default:
+		hc.CacheStore = HistoryCacheMemory
+	}
+	if hc.CacheTTLMinutes <= 0 {
+		hc.CacheTTLMinutes = 10
+	}
+	if hc.CacheMaxAddresses <= 0 {
+		hc.CacheMaxAddresses = 1000
+	}
+	return hc
 }
 
 // GetNetwork 获取指定网络的配置信息
@@ -165,6 +936,30 @@ func GetNetwork(networkID string) (*NetworkConfig, error) {
 	return &network, nil
 }
 
+// defaultRequiredConfirmations 未配置 min_confirmations 时各链的默认确认数
+// 出块快、重组较深的链需要更多确认；即时最终性的链1个确认即可
+var defaultRequiredConfirmations = map[int64]uint64{
+	1:     12,  // Ethereum
+	56:    15,  // BNB Smart Chain
+	137:   128, // Polygon PoS（历史上出现过数十个区块的重组）
+	43114: 1,   // Avalanche C-Chain（即时最终性）
+}
+
+// RequiredConfirmations 交易视为最终确认所需的确认数（交易所在区块之后的出块数）
+// 优先使用 min_confirmations；未配置时按链ID取默认值，未知主网为12，测试网为3
+func (nc NetworkConfig) RequiredConfirmations() uint64 {
+	if nc.MinConfirmations > 0 {
+		return uint64(nc.MinConfirmations)
+	}
+	if n, ok := defaultRequiredConfirmations[nc.ChainID]; ok {
+		return n
+	}
+	if nc.Testnet {
+		return 3
+	}
+	return 12
+}
+
 // GetEnabledNetworks 获取所有已启用的网络配置
 // 返回: 网络标识符到配置的映射
 // 用于显示可用网络列表或网络切换
@@ -0,0 +1,230 @@
+/*
+ABI开发者工具
+
+本文件提供与合约ABI相关的小工具：
+- 函数签名 → 4字节选择器（keccak256(signature)[:4]）
+- 事件签名 → topic0（keccak256(signature)）
+- 签名格式校验与规范化（去除空格、校验参数类型）
+*/
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// signatureNamePattern 函数/事件名称：字母或下划线开头
+	signatureNamePattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+	// arraySuffixPattern 数组后缀，如 []、[3]、[][2]
+	arraySuffixPattern = regexp.MustCompile(`^(\[[0-9]*\])+$`)
+)
+
+// NormalizeSignature 校验并规范化函数/事件签名
+// 格式: name(type1,type2,...)，支持数组与元组类型，如 foo((address,uint256)[],bytes32)
+// 允许携带 indexed 修饰与参数名（如 Transfer(address indexed from, ...)），规范化时会去除
+// 返回规范签名
+func NormalizeSignature(signature string) (string, error) {
+	sig := strings.TrimSpace(signature)
+	open := strings.Index(sig, "(")
+	if open <= 0 || !strings.HasSuffix(sig, ")") {
+		return "", fmt.Errorf("签名格式错误，应为 name(type1,type2,...): %s", signature)
+	}
+	name := strings.TrimSpace(sig[:open])
+	if !signatureNamePattern.MatchString(name) {
+		return "", fmt.Errorf("无效的名称: %s", name)
+	}
+	params := sig[open+1 : len(sig)-1]
+	if strings.TrimSpace(params) == "" {
+		return name + "()", nil
+	}
+	types, err := splitTopLevel(params)
+	if err != nil {
+		return "", err
+	}
+	for i, t := range types {
+		canonical, err := validateABIType(t)
+		if err != nil {
+			return "", err
+		}
+		types[i] = canonical
+	}
+	return name + "(" + strings.Join(types, ",") + ")", nil
+}
+
+// FunctionSelector 计算函数签名的4字节选择器
+// 返回规范签名与0x开头的选择器
+func FunctionSelector(signature string) (string, string, error) {
+	sig, err := NormalizeSignature(signature)
+	if err != nil {
+		return "", "", err
+	}
+	return sig, hexutil.Encode(crypto.Keccak256([]byte(sig))[:4]), nil
+}
+
+// EventTopic 计算事件签名的topic0
+// 返回规范签名与0x开头的32字节topic
+func EventTopic(signature string) (string, string, error) {
+	sig, err := NormalizeSignature(signature)
+	if err != nil {
+		return "", "", err
+	}
+	return sig, crypto.Keccak256Hash([]byte(sig)).Hex(), nil
+}
+
+// validateABIType 校验单个参数类型，返回规范写法（如 uint → uint256）
+func validateABIType(t string) (string, error) {
+	t = stripParamName(t)
+	if t == "" {
+		return "", fmt.Errorf("参数类型不能为空")
+	}
+	// 元组类型：(t1,t2)[...]
+	if strings.HasPrefix(t, "(") {
+		closeIdx := matchingParen(t)
+		if closeIdx < 0 {
+			return "", fmt.Errorf("元组括号不匹配: %s", t)
+		}
+		suffix := t[closeIdx+1:]
+		if suffix != "" && !isArraySuffix(suffix) {
+			return "", fmt.Errorf("无效的元组类型: %s", t)
+		}
+		inner := t[1:closeIdx]
+		if inner == "" {
+			return "()" + suffix, nil
+		}
+		parts, err := splitTopLevel(inner)
+		if err != nil {
+			return "", err
+		}
+		for i, p := range parts {
+			canonical, err := validateABIType(p)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = canonical
+		}
+		return "(" + strings.Join(parts, ",") + ")" + suffix, nil
+	}
+
+	base, suffix := t, ""
+	if idx := strings.Index(t, "["); idx >= 0 {
+		base, suffix = t[:idx], t[idx:]
+		if !isArraySuffix(suffix) {
+			return "", fmt.Errorf("无效的数组类型: %s", t)
+		}
+	}
+	switch base {
+	case "uint":
+		base = "uint256"
+	case "int":
+		base = "int256"
+	}
+	if err := validateTypeSize(base); err != nil {
+		return "", err
+	}
+	if _, err := abi.NewType(base+suffix, "", nil); err != nil {
+		return "", fmt.Errorf("无效的参数类型 %s: %w", t, err)
+	}
+	return base + suffix, nil
+}
+
+// splitTopLevel 按顶层逗号拆分参数列表（忽略元组内部逗号）
+func splitTopLevel(s string) ([]string, error) {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+	for i, ch := range s {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("括号不匹配: %s", s)
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("括号不匹配: %s", s)
+	}
+	return append(parts, s[start:]), nil
+}
+
+// matchingParen 返回与首字符 '(' 匹配的右括号位置
+func matchingParen(s string) int {
+	depth := 0
+	for i, ch := range s {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// isArraySuffix 判断是否为合法的数组后缀，如 []、[3]、[][2]
+func isArraySuffix(s string) bool {
+	return arraySuffixPattern.MatchString(s)
+}
+
+// stripParamName 去除参数中的 indexed 修饰与参数名，仅保留类型
+func stripParamName(p string) string {
+	p = strings.TrimSpace(p)
+	if strings.HasPrefix(p, "(") {
+		closeIdx := matchingParen(p)
+		if closeIdx < 0 {
+			return p
+		}
+		rest := p[closeIdx+1:]
+		if idx := strings.IndexAny(rest, " \t\n"); idx >= 0 {
+			rest = rest[:idx]
+		}
+		return p[:closeIdx+1] + rest
+	}
+	if fields := strings.Fields(p); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// validateTypeSize 校验 uintN/intN/bytesN 的位宽
+func validateTypeSize(base string) error {
+	var prefix string
+	switch {
+	case strings.HasPrefix(base, "uint"):
+		prefix = "uint"
+	case strings.HasPrefix(base, "int"):
+		prefix = "int"
+	case strings.HasPrefix(base, "bytes") && base != "bytes":
+		n, err := strconv.Atoi(base[len("bytes"):])
+		if err != nil || n < 1 || n > 32 {
+			return fmt.Errorf("无效的参数类型: %s", base)
+		}
+		return nil
+	default:
+		return nil
+	}
+	n, err := strconv.Atoi(base[len(prefix):])
+	if err != nil || n < 8 || n > 256 || n%8 != 0 {
+		return fmt.Errorf("无效的参数类型: %s", base)
+	}
+	return nil
+}
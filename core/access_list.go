@@ -0,0 +1,122 @@
+/*
+EIP-2930 访问列表
+
+访问列表预先声明交易将访问的合约地址与存储槽，被声明的地址/槽按"热"访问计费，
+对多次读写外部合约存储的调用可降低 Gas：
+  - GenerateAccessList 通过 eth_createAccessList 生成访问列表，并与不带访问列表的估算比较得出节省的 Gas
+  - TxOptions.AccessList 非空且未指定 EIP-1559 费率时发送 AccessListTx（类型 1），指定了 EIP-1559 费率时附加到动态费率交易
+  - TxOptions.AutoAccessList 为 true 时发送前自动生成，仅在能节省 Gas 时使用
+  - 节点不支持 eth_createAccessList 或拒绝带类型的交易时（未升级 Berlin 的链），回退为不带访问列表的交易
+*/
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrAccessListUnsupported 节点或链不支持 EIP-2930 访问列表
+var ErrAccessListUnsupported = errors.New("当前网络不支持 EIP-2930 访问列表")
+
+// AccessListResult 生成的访问列表及其 Gas 对比
+type AccessListResult struct {
+	AccessList     types.AccessList `json:"access_list"`
+	GasWithList    uint64           `json:"gas_with_list"`    // 附带访问列表时的 Gas 用量
+	GasWithoutList uint64           `json:"gas_without_list"` // 不带访问列表的估算 Gas
+	GasSaved       int64            `json:"gas_saved"`        // 节省的 Gas，负数表示附带访问列表反而更贵
+	Recommended    bool             `json:"recommended"`      // 访问列表非空且能节省 Gas
+}
+
+// GenerateAccessList 通过 eth_createAccessList 为交易生成访问列表，并估算附带前后的 Gas 差
+// 节点不支持时返回 ErrAccessListUnsupported
+func (a *EVMAdapter) GenerateAccessList(ctx context.Context, from, to string, value *big.Int, data []byte) (*AccessListResult, error) {
+	if !common.IsHexAddress(from) {
+		return nil, fmt.Errorf("from 地址格式不正确: %s", from)
+	}
+	if !common.IsHexAddress(to) {
+		return nil, fmt.Errorf("to 地址格式不正确: %s", to)
+	}
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	return a.generateAccessList(ctx, common.HexToAddress(from), common.HexToAddress(to), value, data)
+}
+
+func (a *EVMAdapter) generateAccessList(ctx context.Context, from, to common.Address, value *big.Int, data []byte) (*AccessListResult, error) {
+	args := map[string]interface{}{
+		"from":  from,
+		"to":    to,
+		"value": (*hexutil.Big)(value),
+	}
+	if len(data) > 0 {
+		args["input"] = hexutil.Bytes(data)
+	}
+	var resp struct {
+		AccessList *types.AccessList `json:"accessList"`
+		GasUsed    hexutil.Uint64    `json:"gasUsed"`
+		Error      string            `json:"error,omitempty"`
+	}
+	if err := a.client.CallContext(ctx, &resp, "eth_createAccessList", args, "latest"); err != nil {
+		if isAccessListUnsupported(err) {
+			return nil, fmt.Errorf("%w: %v", ErrAccessListUnsupported, err)
+		}
+		return nil, fmt.Errorf("生成访问列表失败: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("生成访问列表失败，交易执行出错: %s", resp.Error)
+	}
+
+	result := &AccessListResult{AccessList: types.AccessList{}, GasWithList: uint64(resp.GasUsed)}
+	if resp.AccessList != nil {
+		result.AccessList = *resp.AccessList
+	}
+	gasWithout, err := a.client.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &to, Value: value, Data: data})
+	if err != nil {
+		return nil, fmt.Errorf("估算Gas失败: %w", err)
+	}
+	result.GasWithoutList = gasWithout
+	result.GasSaved = int64(gasWithout) - int64(result.GasWithList)
+	result.Recommended = len(result.AccessList) > 0 && result.GasSaved > 0
+	return result, nil
+}
+
+// autoAccessList 发送前自动生成访问列表，不支持或不能节省 Gas 时返回 nil
+func (a *EVMAdapter) autoAccessList(ctx context.Context, from, to common.Address, value *big.Int, data []byte) types.AccessList {
+	result, err := a.generateAccessList(ctx, from, to, value, data)
+	if err != nil {
+		if !errors.Is(err, ErrAccessListUnsupported) {
+			slog.WarnContext(ctx, "access list generation failed, sending without access list", "error", err)
+		}
+		return nil
+	}
+	if !result.Recommended {
+		return nil
+	}
+	return result.AccessList
+}
+
+// isAccessListUnsupported 判断节点错误是否表示不支持访问列表或带类型的交易
+func isAccessListUnsupported(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, pattern := range []string{
+		"method not found", "does not exist", "not available", "not supported", "unsupported",
+		"invalid transaction type", "unknown transaction type", "typed transaction too short",
+	} {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
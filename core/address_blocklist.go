@@ -0,0 +1,338 @@
+/*
+收款地址黑名单
+
+全局黑名单由三部分组成，CheckRecipient 按以下顺序匹配：
+- 管理员维护的地址（持久化在数据库，启动时加载）
+- 配置文件中的静态地址（address_blocklist.addresses）
+- 远程名单（address_blocklist.feed_urls），如已知诈骗地址、制裁地址列表，按间隔刷新，单个名单拉取失败时保留旧数据
+
+远程名单支持两种格式：
+- 文本：每行一个地址，可在地址后用逗号或空白分隔附带原因，# 开头为注释
+- JSON：地址字符串数组，或 [{"address": "0x...", "reason": "..."}] 对象数组
+*/
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// 黑名单来源
+const (
+	BlocklistSourceGlobal = "global" // 管理员添加
+	BlocklistSourceConfig = "config" // 配置文件
+	BlocklistSourceFeed   = "feed"   // 远程名单
+	BlocklistSourceUser   = "user"   // 用户自己添加
+)
+
+// 黑名单错误
+var (
+	ErrInvalidBlockedAddress = errors.New("无效的地址")
+	ErrRecipientBlocklisted  = errors.New("接收地址在黑名单中") // 签名前命中黑名单，拒绝签名
+)
+
+const (
+	blocklistFetchTimeout = 30 * time.Second
+	blocklistMaxFeedSize  = 16 << 20 // 远程名单响应大小上限
+)
+
+// BlocklistMatch 命中的黑名单条目
+type BlocklistMatch struct {
+	Address string `json:"address"`          // 地址（小写）
+	Reason  string `json:"reason,omitempty"` // 原因
+	Source  string `json:"source"`           // 来源：global / config / feed / user
+	FeedURL string `json:"feed_url,omitempty"`
+}
+
+// BlocklistFeedStatus 远程名单状态
+type BlocklistFeedStatus struct {
+	URL       string    `json:"url"`
+	Size      int       `json:"size"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Error     string    `json:"error,omitempty"` // 最近一次拉取失败的原因
+}
+
+// BlocklistStatus 全局黑名单状态
+type BlocklistStatus struct {
+	GlobalSize int                   `json:"global_size"`
+	ConfigSize int                   `json:"config_size"`
+	Feeds      []BlocklistFeedStatus `json:"feeds"`
+}
+
+// blocklistFeed 远程名单数据
+type blocklistFeed struct {
+	entries   map[string]string // 地址 -> 原因
+	updatedAt time.Time
+	err       string
+}
+
+// AddressBlocklist 全局收款地址黑名单
+type AddressBlocklist struct {
+	mu         sync.RWMutex
+	global     map[string]string // 管理员添加：地址 -> 原因
+	static     map[string]string // 配置文件：地址 -> 原因
+	feeds      map[string]*blocklistFeed
+	feedOrder  []string
+	httpClient *http.Client
+}
+
+// NewAddressBlocklist 创建空的全局黑名单
+func NewAddressBlocklist() *AddressBlocklist {
+	return &AddressBlocklist{
+		global:     make(map[string]string),
+		static:     make(map[string]string),
+		feeds:      make(map[string]*blocklistFeed),
+		httpClient: &http.Client{Timeout: blocklistFetchTimeout},
+	}
+}
+
+// NormalizeBlockedAddress 校验并规范化地址（小写），无效地址返回空字符串
+func NormalizeBlockedAddress(address string) string {
+	address = strings.TrimSpace(address)
+	if !common.IsHexAddress(address) {
+		return ""
+	}
+	return strings.ToLower(common.HexToAddress(address).Hex())
+}
+
+// CheckRecipient 判断收款地址是否在全局黑名单中，返回是否命中与原因
+func (bl *AddressBlocklist) CheckRecipient(address string) (blocked bool, reason string) {
+	match, ok := bl.Lookup(address)
+	if !ok {
+		return false, ""
+	}
+	return true, match.Reason
+}
+
+// CheckTransfer 签名前检查交易的 to 与代币转账接收方是否在全局黑名单中，命中时返回包装 ErrRecipientBlocklisted 的错误
+func (bl *AddressBlocklist) CheckTransfer(transfer *PendingTransfer) error {
+	if bl == nil || transfer == nil {
+		return nil
+	}
+	for _, address := range []string{transfer.Recipient, transfer.To} {
+		if blocked, reason := bl.CheckRecipient(address); blocked {
+			if reason == "" {
+				reason = "已知风险地址"
+			}
+			return fmt.Errorf("%w: %s（%s）", ErrRecipientBlocklisted, address, reason)
+		}
+	}
+	return nil
+}
+
+// Lookup 查找收款地址命中的全局黑名单条目
+func (bl *AddressBlocklist) Lookup(address string) (*BlocklistMatch, bool) {
+	addr := NormalizeBlockedAddress(address)
+	if addr == "" {
+		return nil, false
+	}
+	bl.mu.RLock()
+	defer bl.mu.RUnlock()
+	if reason, ok := bl.global[addr]; ok {
+		return &BlocklistMatch{Address: addr, Reason: reason, Source: BlocklistSourceGlobal}, true
+	}
+	if reason, ok := bl.static[addr]; ok {
+		return &BlocklistMatch{Address: addr, Reason: reason, Source: BlocklistSourceConfig}, true
+	}
+	for _, url := range bl.feedOrder {
+		if reason, ok := bl.feeds[url].entries[addr]; ok {
+			return &BlocklistMatch{Address: addr, Reason: reason, Source: BlocklistSourceFeed, FeedURL: url}, true
+		}
+	}
+	return nil, false
+}
+
+// SetStatic 替换配置文件中的静态黑名单，条目格式为 "地址" 或 "地址,原因"
+func (bl *AddressBlocklist) SetStatic(lines []string) {
+	static := parseBlocklistText(strings.Join(lines, "\n"))
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+	bl.static = static
+}
+
+// SetGlobal 添加或更新管理员维护的条目
+func (bl *AddressBlocklist) SetGlobal(address, reason string) {
+	if addr := NormalizeBlockedAddress(address); addr != "" {
+		bl.mu.Lock()
+		defer bl.mu.Unlock()
+		bl.global[addr] = reason
+	}
+}
+
+// RemoveGlobal 删除管理员维护的条目
+func (bl *AddressBlocklist) RemoveGlobal(address string) {
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+	delete(bl.global, NormalizeBlockedAddress(address))
+}
+
+// LoadFeed 拉取远程名单并替换该名单的数据；失败时保留旧数据并记录错误
+func (bl *AddressBlocklist) LoadFeed(ctx context.Context, url string) error {
+	entries, err := bl.fetchFeed(ctx, url)
+
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+	feed, ok := bl.feeds[url]
+	if !ok {
+		feed = &blocklistFeed{entries: map[string]string{}}
+		bl.feeds[url] = feed
+		bl.feedOrder = append(bl.feedOrder, url)
+	}
+	if err != nil {
+		feed.err = err.Error()
+		return err
+	}
+	feed.entries = entries
+	feed.updatedAt = time.Now()
+	feed.err = ""
+	slog.DebugContext(ctx, "address blocklist loaded", "url", url, "entries", len(entries))
+	return nil
+}
+
+// StartRefresh 立即加载全部远程名单并按间隔刷新，直到 ctx 取消
+func (bl *AddressBlocklist) StartRefresh(ctx context.Context, urls []string, interval time.Duration) {
+	if len(urls) == 0 {
+		return
+	}
+	refresh := func() {
+		for _, url := range urls {
+			loadCtx, cancel := context.WithTimeout(ctx, blocklistFetchTimeout)
+			if err := bl.LoadFeed(loadCtx, url); err != nil {
+				slog.DebugContext(ctx, "address blocklist refresh failed", "url", url, "error", err)
+			}
+			cancel()
+		}
+	}
+
+	go func() {
+		refresh()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				refresh()
+			}
+		}
+	}()
+}
+
+// Status 各部分名单的大小与远程名单的最近加载时间
+func (bl *AddressBlocklist) Status() BlocklistStatus {
+	bl.mu.RLock()
+	defer bl.mu.RUnlock()
+	status := BlocklistStatus{GlobalSize: len(bl.global), ConfigSize: len(bl.static), Feeds: []BlocklistFeedStatus{}}
+	for _, url := range bl.feedOrder {
+		feed := bl.feeds[url]
+		status.Feeds = append(status.Feeds, BlocklistFeedStatus{URL: url, Size: len(feed.entries), UpdatedAt: feed.updatedAt, Error: feed.err})
+	}
+	return status
+}
+
+// fetchFeed 拉取并解析远程名单
+func (bl *AddressBlocklist) fetchFeed(ctx context.Context, url string) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建黑名单请求失败: %w", err)
+	}
+	resp, err := bl.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("拉取地址黑名单失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("拉取地址黑名单失败: HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, blocklistMaxFeedSize))
+	if err != nil {
+		return nil, fmt.Errorf("读取地址黑名单失败: %w", err)
+	}
+
+	var entries map[string]string
+	if trimmed := strings.TrimSpace(string(body)); strings.HasPrefix(trimmed, "[") {
+		if entries, err = parseBlocklistJSON([]byte(trimmed)); err != nil {
+			return nil, fmt.Errorf("解析地址黑名单失败: %w", err)
+		}
+	} else {
+		entries = parseBlocklistText(trimmed)
+	}
+	if len(entries) == 0 {
+		// 空名单多半是来源异常，保留旧数据
+		return nil, fmt.Errorf("地址黑名单为空: %s", url)
+	}
+	return entries, nil
+}
+
+// parseBlocklistText 解析每行一个地址的文本名单，无效行忽略
+func parseBlocklistText(text string) map[string]string {
+	entries := make(map[string]string)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		address, reason := line, ""
+		if i := strings.IndexAny(line, ", \t"); i >= 0 {
+			address, reason = line[:i], strings.TrimSpace(strings.TrimLeft(line[i:], ", \t"))
+		}
+		if addr := NormalizeBlockedAddress(address); addr != "" {
+			entries[addr] = reason
+		}
+	}
+	return entries
+}
+
+// parseBlocklistJSON 解析地址字符串数组或 {address, reason} 对象数组
+func parseBlocklistJSON(body []byte) (map[string]string, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+	entries := make(map[string]string, len(raw))
+	for _, item := range raw {
+		var address, reason string
+		var obj struct {
+			Address string `json:"address"`
+			Reason  string `json:"reason"`
+		}
+		if err := json.Unmarshal(item, &address); err != nil {
+			if err := json.Unmarshal(item, &obj); err != nil {
+				continue
+			}
+			address, reason = obj.Address, obj.Reason
+		}
+		if addr := NormalizeBlockedAddress(address); addr != "" {
+			entries[addr] = reason
+		}
+	}
+	return entries, nil
+}
+
+// AddressBlocklist 全局收款地址黑名单
+func (sm *AdvancedSecurityManager) AddressBlocklist() *AddressBlocklist {
+	return sm.addressBlocklist
+}
+
+// multiSigRecipientBlocked 多签交易的接收地址（含 ERC20 transfer 的接收方）是否在钱包配置的黑名单或全局黑名单中
+func (sm *AdvancedSecurityManager) multiSigRecipientBlocked(wallet *MultiSigWallet, tx *MultiSigTransaction) error {
+	transfer := newPendingTransfer("", "", common.HexToAddress(tx.To), tx.Value, tx.Data)
+	for _, blocked := range wallet.Configuration.BlockedAddresses {
+		addr := NormalizeBlockedAddress(blocked)
+		if addr == NormalizeBlockedAddress(transfer.Recipient) || addr == NormalizeBlockedAddress(transfer.To) {
+			return fmt.Errorf("%w: %s 在多签钱包黑名单中", ErrRecipientBlocklisted, blocked)
+		}
+	}
+	return sm.addressBlocklist.CheckTransfer(transfer)
+}
@@ -0,0 +1,160 @@
+/*
+地址活跃度摘要
+
+用于判断收款地址是长期使用的地址还是新建地址（诈骗常见特征），全部基于节点RPC：
+  - 交易数：最新区块的 nonce（地址发出的交易数）；是否为合约：eth_getCode
+  - 首次出现：nonce > 0 时二分查找 nonce 首次变为 1 的区块（第一笔发出交易），与日志窗口内最早的 ERC20 转账取较早者；
+    历史状态查询需要归档节点，普通节点上失败时只剩日志窗口内的结果，并在 warnings 中说明
+  - 最近活跃：nonce 达到当前值的区块（最后一笔发出交易）与日志窗口内最近的 ERC20 转账取较晚者
+  - 持有代币数：按代币识别逻辑扫描最近 lookbackBlocks 个区块的 Transfer 日志，批量查询余额后统计余额大于0的代币
+
+只收到过原生币转账、从未发出交易的地址无法通过RPC确定首次出现时间。
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AddressSummary 地址活跃度摘要
+type AddressSummary struct {
+	Address         string     `json:"address"`
+	IsContract      bool       `json:"is_contract"`
+	TxCount         uint64     `json:"tx_count"`       // 发出的交易数（nonce）
+	NativeBalance   string     `json:"native_balance"` // 原生币余额（最小单位）
+	TokenCount      int        `json:"token_count"`    // 日志窗口内识别到且余额大于0的代币数
+	Tokens          []string   `json:"tokens"`         // 余额大于0的代币地址
+	FirstSeenBlock  *uint64    `json:"first_seen_block,omitempty"`
+	FirstSeenTime   *time.Time `json:"first_seen_time,omitempty"`
+	LastActiveBlock *uint64    `json:"last_active_block,omitempty"`
+	LastActiveTime  *time.Time `json:"last_active_time,omitempty"`
+	LatestBlock     uint64     `json:"latest_block"`    // 查询基于的区块
+	LookbackBlocks  uint64     `json:"lookback_blocks"` // 代币与转账日志回溯的区块数
+	Warnings        []string   `json:"warnings,omitempty"`
+}
+
+// GetAddressSummary 查询地址的交易数、是否为合约、原生币余额、持有代币数以及首次出现与最近活跃的区块
+// 单项查询失败（如非归档节点的历史状态、日志查询超限）记录在 Warnings 中，不影响其余字段
+func (a *EVMAdapter) GetAddressSummary(ctx context.Context, address string, lookbackBlocks uint64, maxTokens int) (*AddressSummary, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("无效的地址: %s", address)
+	}
+	addr := common.HexToAddress(address)
+	latest, err := a.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取最新区块失败: %w", err)
+	}
+	latestBig := new(big.Int).SetUint64(latest)
+	summary := &AddressSummary{Address: addr.Hex(), Tokens: []string{}, LatestBlock: latest, LookbackBlocks: lookbackBlocks}
+
+	if summary.TxCount, err = a.client.NonceAt(ctx, addr, latestBig); err != nil {
+		return nil, fmt.Errorf("获取nonce失败: %w", err)
+	}
+	code, err := a.client.CodeAt(ctx, addr, latestBig)
+	if err != nil {
+		return nil, fmt.Errorf("查询合约代码失败: %w", err)
+	}
+	summary.IsContract = len(code) > 0
+	balance, err := a.client.BalanceAt(ctx, addr, latestBig)
+	if err != nil {
+		return nil, fmt.Errorf("获取余额失败: %w", err)
+	}
+	summary.NativeBalance = balance.String()
+
+	var firstSeen, lastActive *uint64
+	if summary.TxCount > 0 {
+		if block, err := a.nonceReachedBlock(ctx, addr, 1, latest); err != nil {
+			summary.Warnings = append(summary.Warnings, "无法定位第一笔发出交易（可能需要归档节点）: "+err.Error())
+		} else {
+			firstSeen = &block
+		}
+		if block, err := a.nonceReachedBlock(ctx, addr, summary.TxCount, latest); err != nil {
+			summary.Warnings = append(summary.Warnings, "无法定位最后一笔发出交易（可能需要归档节点）: "+err.Error())
+		} else {
+			lastActive = &block
+		}
+	}
+
+	start := uint64(0)
+	if latest > lookbackBlocks {
+		start = latest - lookbackBlocks + 1
+	}
+	logs, err := a.erc20TransferLogs(ctx, addr, start, latest)
+	if err != nil {
+		summary.Warnings = append(summary.Warnings, "代币转账日志查询失败: "+err.Error())
+	}
+	seen := make(map[common.Address]bool)
+	var tokens []string
+	for _, lg := range logs {
+		block := lg.BlockNumber
+		if firstSeen == nil || block < *firstSeen {
+			firstSeen = &block
+		}
+		if lastActive == nil || block > *lastActive {
+			lastActive = &block
+		}
+		if !seen[lg.Address] {
+			seen[lg.Address] = true
+			tokens = append(tokens, lg.Address.Hex())
+		}
+	}
+	if len(tokens) > maxTokens {
+		tokens = tokens[:maxTokens]
+	}
+	if len(tokens) > 0 {
+		balances, err := a.GetERC20BalancesBatch(ctx, summary.Address, tokens)
+		if err != nil {
+			summary.Warnings = append(summary.Warnings, "代币余额查询失败: "+err.Error())
+		}
+		for _, token := range tokens {
+			if balance, ok := balances[token]; ok && balance.Sign() > 0 {
+				summary.Tokens = append(summary.Tokens, token)
+			}
+		}
+		summary.TokenCount = len(summary.Tokens)
+	}
+
+	if firstSeen != nil {
+		summary.FirstSeenBlock = firstSeen
+		summary.FirstSeenTime = a.summaryBlockTime(ctx, *firstSeen, summary)
+	}
+	if lastActive != nil {
+		summary.LastActiveBlock = lastActive
+		summary.LastActiveTime = a.summaryBlockTime(ctx, *lastActive, summary)
+	}
+	return summary, nil
+}
+
+// nonceReachedBlock 二分查找 addr 的 nonce 首次达到 target 的区块（需要节点保留历史状态）
+func (a *EVMAdapter) nonceReachedBlock(ctx context.Context, addr common.Address, target, latest uint64) (uint64, error) {
+	low, high := uint64(0), latest
+	for low < high {
+		mid := low + (high-low)/2
+		nonce, err := a.client.NonceAt(ctx, addr, new(big.Int).SetUint64(mid))
+		if err != nil {
+			return 0, err
+		}
+		if nonce >= target {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	return low, nil
+}
+
+// summaryBlockTime 查询区块时间，失败时记录警告并返回 nil
+func (a *EVMAdapter) summaryBlockTime(ctx context.Context, block uint64, summary *AddressSummary) *time.Time {
+	header, err := a.client.HeaderByNumber(ctx, new(big.Int).SetUint64(block))
+	if err != nil {
+		summary.Warnings = append(summary.Warnings, fmt.Sprintf("获取区块 %d 时间失败: %v", block, err))
+		return nil
+	}
+	t := time.Unix(int64(header.Time), 0).UTC()
+	return &t
+}
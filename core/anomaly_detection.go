@@ -0,0 +1,289 @@
+/*
+异常登录与交易检测
+
+按用户保存最近的登录/交易事件作为基线，对新事件计算风险分数：
+- 新设备：设备指纹未在历史事件中出现
+- 新地区：国家/地区未在历史事件中出现
+- 不可能的移动：与上一次事件的距离/时间超过最大移动速度；无坐标时，短时间内国家变化
+- 大额交易：金额超过同一资产历史交易中位数的指定倍数
+各信号分数相加（上限1），按阈值决定放行（allow）、额外验证（challenge）或拒绝（deny）。
+没有历史事件的用户（首次登录）不计新设备与新地区；评估本身不记录事件，调用方在操作成功后调用 Record 更新基线。
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"wallet/config"
+)
+
+// 安全事件类型
+const (
+	SecurityEventLogin       = "login"
+	SecurityEventTransaction = "transaction"
+)
+
+// 风险处置结果
+const (
+	RiskActionAllow     = "allow"
+	RiskActionChallenge = "challenge"
+	RiskActionDeny      = "deny"
+)
+
+// 风险信号
+const (
+	RiskFactorNewDevice        = "new_device"
+	RiskFactorNewLocation      = "new_location"
+	RiskFactorImpossibleTravel = "impossible_travel"
+	RiskFactorLargeValue       = "large_value"
+)
+
+// earthRadiusKm 地球平均半径（公里）
+const earthRadiusKm = 6371.0
+
+// minTravelDistanceKm 小于该距离的位置变化不计入不可能的移动（IP 定位本身存在误差）
+const minTravelDistanceKm = 100.0
+
+// GeoLocation 地理坐标
+type GeoLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// SecurityEvent 登录或交易事件
+type SecurityEvent struct {
+	Type              string       `json:"type"`                         // login / transaction
+	Timestamp         time.Time    `json:"timestamp"`                    // 发生时间，零值取当前时间
+	DeviceFingerprint string       `json:"device_fingerprint,omitempty"` // 设备指纹
+	IPAddress         string       `json:"ip_address,omitempty"`         // 客户端IP
+	Country           string       `json:"country,omitempty"`            // 国家/地区代码
+	Location          *GeoLocation `json:"location,omitempty"`           // 地理坐标，未知为空
+	Asset             string       `json:"asset,omitempty"`              // 交易资产标识（如 网络:代币地址）
+	Value             *big.Int     `json:"value,omitempty"`              // 交易金额（最小单位）
+}
+
+// RiskFactor 风险信号
+type RiskFactor struct {
+	Code   string  `json:"code"`   // 信号代码
+	Score  float64 `json:"score"`  // 贡献的风险分数
+	Detail string  `json:"detail"` // 说明
+}
+
+// RiskDecision 风险评估结果
+type RiskDecision struct {
+	Action  string       `json:"action"`  // allow / challenge / deny
+	Score   float64      `json:"score"`   // 风险分数（0~1）
+	Factors []RiskFactor `json:"factors"` // 命中的风险信号
+}
+
+// AnomalyDetector 异常检测器
+type AnomalyDetector struct {
+	mu     sync.RWMutex
+	events map[string][]SecurityEvent // 用户地址（小写）-> 按时间顺序的最近事件
+}
+
+// NewAnomalyDetector 创建异常检测器
+func NewAnomalyDetector() *AnomalyDetector {
+	return &AnomalyDetector{events: make(map[string][]SecurityEvent)}
+}
+
+// Evaluate 按用户历史事件评估新事件的风险，不修改基线
+func (ad *AnomalyDetector) Evaluate(userAddress string, event SecurityEvent) *RiskDecision {
+	cfg := config.AppConfig.Anomaly.WithDefaults()
+	decision := &RiskDecision{Action: RiskActionAllow, Factors: []RiskFactor{}}
+	if !config.AppConfig.Anomaly.Enabled {
+		return decision
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	ad.mu.RLock()
+	history := ad.recentLocked(strings.ToLower(userAddress), event.Timestamp, cfg)
+	ad.mu.RUnlock()
+
+	add := func(code string, score float64, detail string) {
+		decision.Factors = append(decision.Factors, RiskFactor{Code: code, Score: score, Detail: detail})
+		decision.Score += score
+	}
+	if event.DeviceFingerprint != "" && seenBefore(history, func(e *SecurityEvent) string { return e.DeviceFingerprint }, event.DeviceFingerprint) == unseen {
+		add(RiskFactorNewDevice, cfg.NewDeviceScore, "首次使用该设备")
+	}
+	if event.Country != "" && seenBefore(history, func(e *SecurityEvent) string { return e.Country }, event.Country) == unseen {
+		add(RiskFactorNewLocation, cfg.NewLocationScore, fmt.Sprintf("首次从 %s 访问", strings.ToUpper(event.Country)))
+	}
+	if detail, ok := impossibleTravel(history, &event, cfg); ok {
+		add(RiskFactorImpossibleTravel, cfg.ImpossibleTravelScore, detail)
+	}
+	if event.Type == SecurityEventTransaction {
+		if detail, ok := largeValue(history, &event, cfg); ok {
+			add(RiskFactorLargeValue, cfg.LargeValueScore, detail)
+		}
+	}
+
+	decision.Score = math.Min(1, math.Round(decision.Score*100)/100)
+	switch {
+	case decision.Score >= cfg.DenyThreshold:
+		decision.Action = RiskActionDeny
+	case decision.Score >= cfg.ChallengeThreshold:
+		decision.Action = RiskActionChallenge
+	}
+	return decision
+}
+
+// Record 记录成功的事件，更新用户基线
+func (ad *AnomalyDetector) Record(userAddress string, event SecurityEvent) {
+	if !config.AppConfig.Anomaly.Enabled || userAddress == "" {
+		return
+	}
+	cfg := config.AppConfig.Anomaly.WithDefaults()
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if event.Value != nil {
+		event.Value = new(big.Int).Set(event.Value)
+	}
+	key := strings.ToLower(userAddress)
+
+	ad.mu.Lock()
+	defer ad.mu.Unlock()
+	events := append(ad.recentLocked(key, event.Timestamp, cfg), event)
+	if len(events) > cfg.MaxEventsPerUser {
+		events = events[len(events)-cfg.MaxEventsPerUser:]
+	}
+	ad.events[key] = events
+}
+
+// recentLocked 返回保留期内的历史事件，调用方需持有锁
+func (ad *AnomalyDetector) recentLocked(key string, now time.Time, cfg config.AnomalyDetectionConfig) []SecurityEvent {
+	events := ad.events[key]
+	cutoff := now.AddDate(0, 0, -cfg.RetentionDays)
+	i := sort.Search(len(events), func(i int) bool { return events[i].Timestamp.After(cutoff) })
+	return events[i:len(events):len(events)]
+}
+
+// 历史中某属性值的出现情况
+const (
+	unseen     = iota // 历史中有该属性但从未出现该值
+	seen              // 出现过
+	noBaseline        // 历史中没有该属性，无法判断
+)
+
+// seenBefore 判断属性值是否在历史事件中出现过
+func seenBefore(history []SecurityEvent, attr func(*SecurityEvent) string, value string) int {
+	result := noBaseline
+	for i := range history {
+		v := attr(&history[i])
+		if v == "" {
+			continue
+		}
+		if strings.EqualFold(v, value) {
+			return seen
+		}
+		result = unseen
+	}
+	return result
+}
+
+// impossibleTravel 与上一次有位置信息的事件比较，判断是否在物理上不可能完成移动
+func impossibleTravel(history []SecurityEvent, event *SecurityEvent, cfg config.AnomalyDetectionConfig) (string, bool) {
+	for i := len(history) - 1; i >= 0; i-- {
+		prev := &history[i]
+		elapsed := event.Timestamp.Sub(prev.Timestamp)
+		if prev.Location != nil && event.Location != nil {
+			distance := haversineKm(prev.Location, event.Location)
+			if distance < minTravelDistanceKm {
+				return "", false
+			}
+			hours := math.Max(elapsed.Hours(), 1.0/60)
+			if speed := distance / hours; speed > cfg.MaxTravelSpeedKmh {
+				return fmt.Sprintf("%.0f 公里外的位置在 %s 前有活动（约 %.0f 公里/小时）", distance, elapsed.Round(time.Minute), speed), true
+			}
+			return "", false
+		}
+		if prev.Country != "" && event.Country != "" {
+			if !strings.EqualFold(prev.Country, event.Country) && elapsed < time.Duration(cfg.TravelWindowMinutes)*time.Minute {
+				return fmt.Sprintf("%s 前在 %s 有活动", elapsed.Round(time.Minute), strings.ToUpper(prev.Country)), true
+			}
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// largeValue 判断交易金额是否超过同一资产历史交易中位数的指定倍数
+func largeValue(history []SecurityEvent, event *SecurityEvent, cfg config.AnomalyDetectionConfig) (string, bool) {
+	if event.Value == nil || event.Value.Sign() <= 0 {
+		return "", false
+	}
+	var values []*big.Int
+	for i := range history {
+		h := &history[i]
+		if h.Type == SecurityEventTransaction && h.Value != nil && strings.EqualFold(h.Asset, event.Asset) {
+			values = append(values, h.Value)
+		}
+	}
+	if len(values) < cfg.MinTxHistory {
+		return "", false
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Cmp(values[j]) < 0 })
+	median := values[len(values)/2]
+	if median.Sign() <= 0 {
+		return "", false
+	}
+	ratio, _ := new(big.Float).Quo(new(big.Float).SetInt(event.Value), new(big.Float).SetInt(median)).Float64()
+	if ratio <= cfg.LargeValueMultiplier {
+		return "", false
+	}
+	return fmt.Sprintf("金额为历史中位数的 %.1f 倍", ratio), true
+}
+
+// haversineKm 两个坐标之间的球面距离（公里）
+func haversineKm(a, b *GeoLocation) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(b.Latitude - a.Latitude)
+	dLon := toRad(b.Longitude - a.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(toRad(a.Latitude))*math.Cos(toRad(b.Latitude))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// EvaluateRisk 评估用户登录/交易事件的风险，需额外验证或拒绝的结果记入审计日志
+func (sm *AdvancedSecurityManager) EvaluateRisk(ctx context.Context, userAddress string, event SecurityEvent) (*RiskDecision, error) {
+	if userAddress == "" {
+		return nil, fmt.Errorf("用户地址不能为空")
+	}
+	if event.Type != SecurityEventLogin && event.Type != SecurityEventTransaction {
+		return nil, fmt.Errorf("不支持的安全事件类型: %s", event.Type)
+	}
+	decision := sm.anomalyDetector.Evaluate(userAddress, event)
+	if decision.Action != RiskActionAllow {
+		actx := AuditContextFrom(ctx)
+		if actx.UserAddress == "" {
+			actx.UserAddress = userAddress
+		}
+		if actx.IPAddress == "" {
+			actx.IPAddress = event.IPAddress
+		}
+		codes := make([]string, 0, len(decision.Factors))
+		for _, f := range decision.Factors {
+			codes = append(codes, f.Code)
+		}
+		sm.auditLogger.LogActionFor(actx, "risk_evaluation", event.Type, userAddress, decision.Action, map[string]interface{}{
+			"score":   decision.Score,
+			"factors": codes,
+			"country": event.Country,
+		})
+	}
+	return decision, nil
+}
+
+// RecordSecurityEvent 记录成功完成的登录/交易事件，作为后续评估的基线
+func (sm *AdvancedSecurityManager) RecordSecurityEvent(userAddress string, event SecurityEvent) {
+	sm.anomalyDetector.Record(userAddress, event)
+}
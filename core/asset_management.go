@@ -46,13 +46,14 @@ type AssetManager struct {
 
 // Portfolio 投资组合
 type Portfolio struct {
-	UserAddress string              `json:"user_address"` // 用户地址
-	TotalValue  *big.Int            `json:"total_value"`  // 总价值
-	Holdings    []*AssetHolding     `json:"holdings"`     // 持仓
-	Allocation  *AssetAllocation    `json:"allocation"`   // 资产配置
-	Performance *PerformanceMetrics `json:"performance"`  // 表现指标
-	RiskMetrics *RiskMetrics        `json:"risk_metrics"` // 风险指标
-	LastUpdated time.Time           `json:"last_updated"` // 最后更新
+	UserAddress   string              `json:"user_address"`    // 用户地址
+	TotalValue    *big.Int            `json:"total_value"`     // 总价值
+	TotalValueUSD float64             `json:"total_value_usd"` // 总美元价值（有价格数据的持仓之和）
+	Holdings      []*AssetHolding     `json:"holdings"`        // 持仓
+	Allocation    *AssetAllocation    `json:"allocation"`      // 资产配置
+	Performance   *PerformanceMetrics `json:"performance"`     // 表现指标
+	RiskMetrics   *RiskMetrics        `json:"risk_metrics"`    // 风险指标
+	LastUpdated   time.Time           `json:"last_updated"`    // 最后更新
 }
 
 // AssetHolding 资产持仓
@@ -62,9 +63,13 @@ type AssetHolding struct {
 	Type            string     `json:"type"`             // 资产类型
 	Chain           string     `json:"chain"`            // 所在链
 	ContractAddress string     `json:"contract_address"` // 合约地址
+	Decimals        int        `json:"decimals"`         // 余额小数位数
 	Balance         *big.Int   `json:"balance"`          // 余额
 	Value           *big.Int   `json:"value"`            // 价值
 	Price           *big.Int   `json:"price"`            // 当前价格
+	PriceUSD        float64    `json:"price_usd"`        // 当前美元价格
+	ValueUSD        float64    `json:"value_usd"`        // 持仓美元价值
+	NoPrice         bool       `json:"no_price"`         // 是否缺少价格数据
 	AvgCost         *big.Int   `json:"avg_cost"`         // 平均成本
 	PnL             *big.Int   `json:"pnl"`              // 盈亏
 	PnLPercent      float64    `json:"pnl_percent"`      // 盈亏百分比
@@ -238,6 +243,7 @@ func (pa *PortfolioAnalyzer) Analyze(ctx context.Context, userAddress string) (*
 				Name:      "Ethereum",
 				Type:      "cryptocurrency",
 				Chain:     "ethereum",
+				Decimals:  18,
 				Balance:   balance,
 				Value:     balance,
 				Price:     price,
@@ -0,0 +1,291 @@
+/*
+安全审计日志的归属与查询
+
+审计日志需要能追溯到具体用户与请求：
+- 请求中间件将用户地址、IP、User-Agent、会话与设备ID放入 context，核心层通过 LogActionCtx 记录
+- 没有 context 的调用方可用 LogActionFor 显式传入归属信息
+- 风险分数按操作敏感程度与结果估算，便于按最低风险分数筛选
+- 查询按时间倒序分页，返回过滤后的总数
+- 设置持久化存储（AuditLogStore）后日志写穿到数据库，查询走存储，内存只缓存最近的日志
+- 存储中超过保留期限的记录定期分批删除
+- 会话ID只保存指纹，避免审计日志泄露可用的会话凭证
+*/
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// 审计日志分页限制
+const (
+	DefaultAuditLogLimit = 50
+	MaxAuditLogLimit     = 1000
+)
+
+// 审计日志缓存与清理参数
+const (
+	defaultAuditCacheSize = 10000     // 内存缓存的最近日志条数
+	auditPurgeInterval    = time.Hour // 清理存储中过期日志的最小间隔
+	auditPurgeBatchSize   = 1000      // 每批删除的过期日志条数
+)
+
+// AuditLogStore 审计日志持久化存储
+type AuditLogStore interface {
+	SaveAuditLog(entry AuditLog) error
+	QueryAuditLogs(filter AuditLogFilter) ([]AuditLog, int, error)
+	// DeleteAuditLogsBefore 删除 cutoff 之前的日志，最多 limit 条，返回实际删除条数
+	DeleteAuditLogsBefore(cutoff time.Time, limit int) (int, error)
+}
+
+// auditActionRisk 敏感操作的基础风险分数，未列出的操作为 0.1
+var auditActionRisk = map[string]float64{
+	"create_mnemonic_shards":       0.8,
+	"execute_multisig_transaction": 0.6,
+	"create_multisig_wallet":       0.3,
+	"risk_evaluation":              0.4,
+	"ip_whitelist_denied":          0.6,
+	"blocked_recipient_attempt":    0.7,
+}
+
+// AuditContext 审计日志的归属信息
+type AuditContext struct {
+	UserAddress string
+	IPAddress   string
+	UserAgent   string
+	SessionID   string
+	DeviceID    string
+}
+
+// auditCtxKey context中存放审计归属信息的键
+type auditCtxKey struct{}
+
+// WithAuditContext 将审计归属信息附加到context
+func WithAuditContext(ctx context.Context, actx AuditContext) context.Context {
+	return context.WithValue(ctx, auditCtxKey{}, actx)
+}
+
+// AuditContextFrom 读取context中的审计归属信息，未设置时返回零值
+func AuditContextFrom(ctx context.Context) AuditContext {
+	if ctx == nil {
+		return AuditContext{}
+	}
+	actx, _ := ctx.Value(auditCtxKey{}).(AuditContext)
+	return actx
+}
+
+// AuditLogFilter 审计日志查询条件，零值字段不过滤
+type AuditLogFilter struct {
+	UserAddress  string     // 用户地址（不区分大小写）
+	Action       string     // 操作
+	Result       string     // 结果（success / failed）
+	StartTime    *time.Time // 起始时间（含）
+	EndTime      *time.Time // 结束时间（含）
+	MinRiskScore float64    // 最低风险分数
+	Limit        int        // 每页条数，0 使用默认值
+	Offset       int        // 偏移量
+}
+
+// LogActionCtx 记录操作日志，归属信息取自 context（见 WithAuditContext）
+func (al *AuditLogger) LogActionCtx(ctx context.Context, action, resource, resourceID, result string, details map[string]interface{}) {
+	al.LogActionFor(AuditContextFrom(ctx), action, resource, resourceID, result, details)
+}
+
+// SetStore 设置持久化存储，之后的日志写穿到存储，查询改为读取存储
+func (al *AuditLogger) SetStore(store AuditLogStore) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	al.store = store
+}
+
+// LogActionFor 记录操作日志并指定归属信息
+// 写入存储失败时仅打印警告，日志仍保留在内存缓存中
+func (al *AuditLogger) LogActionFor(actx AuditContext, action, resource, resourceID, result string, details map[string]interface{}) {
+	risk := auditRiskScore(action, result)
+	entry := AuditLog{
+		ID:            al.generateLogID(),
+		Timestamp:     time.Now(),
+		UserAddress:   actx.UserAddress,
+		Action:        action,
+		Resource:      resource,
+		ResourceID:    resourceID,
+		Result:        result,
+		IPAddress:     actx.IPAddress,
+		UserAgent:     actx.UserAgent,
+		DeviceID:      actx.DeviceID,
+		SessionID:     sessionFingerprint(actx.SessionID),
+		Details:       details,
+		RiskScore:     risk,
+		SecurityLevel: auditSecurityLevel(risk),
+	}
+
+	al.mu.RLock()
+	store := al.store
+	al.mu.RUnlock()
+	if store != nil {
+		if err := store.SaveAuditLog(entry); err != nil {
+			slog.WarnContext(context.Background(), "audit log persistence failed", "action", action, "error", err)
+		}
+	}
+
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	al.logs = append(al.logs, entry)
+
+	// 清理过期日志
+	al.cleanExpiredLogs()
+}
+
+// QueryLogs 按条件查询审计日志（时间倒序），返回当前页及过滤后的总数
+// 设置了持久化存储时查询存储，否则查询内存缓存
+func (al *AuditLogger) QueryLogs(filter AuditLogFilter) ([]AuditLog, int, error) {
+	if err := filter.normalize(); err != nil {
+		return nil, 0, err
+	}
+
+	al.mu.RLock()
+	store := al.store
+	al.mu.RUnlock()
+	if store != nil {
+		return store.QueryAuditLogs(filter)
+	}
+
+	al.mu.RLock()
+	defer al.mu.RUnlock()
+
+	page := make([]AuditLog, 0, filter.Limit)
+	total := 0
+	// 日志按写入顺序追加，倒序遍历即为时间倒序
+	for i := len(al.logs) - 1; i >= 0; i-- {
+		entry := al.logs[i]
+		if !filter.matches(&entry) {
+			continue
+		}
+		if total >= filter.Offset && len(page) < filter.Limit {
+			page = append(page, entry)
+		}
+		total++
+	}
+	return page, total, nil
+}
+
+// purgeStore 分批删除存储中 cutoff 之前的日志，避免单次删除锁表过久
+func (al *AuditLogger) purgeStore(cutoff time.Time) {
+	defer func() {
+		al.mu.Lock()
+		al.purging = false
+		al.mu.Unlock()
+	}()
+
+	al.mu.RLock()
+	store := al.store
+	al.mu.RUnlock()
+
+	total := 0
+	for {
+		deleted, err := store.DeleteAuditLogsBefore(cutoff, auditPurgeBatchSize)
+		if err != nil {
+			slog.WarnContext(context.Background(), "audit log purge failed", "error", err)
+			return
+		}
+		total += deleted
+		if deleted < auditPurgeBatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		slog.DebugContext(context.Background(), "expired audit logs purged", "count", total)
+	}
+}
+
+// normalize 填充默认分页参数并校验查询条件
+func (f *AuditLogFilter) normalize() error {
+	if f.Limit == 0 {
+		f.Limit = DefaultAuditLogLimit
+	}
+	if f.Limit < 0 || f.Limit > MaxAuditLogLimit {
+		return fmt.Errorf("limit 需在 1~%d 之间", MaxAuditLogLimit)
+	}
+	if f.Offset < 0 {
+		return fmt.Errorf("offset 不能为负数")
+	}
+	if f.StartTime != nil && f.EndTime != nil && f.StartTime.After(*f.EndTime) {
+		return fmt.Errorf("起始时间不能晚于结束时间")
+	}
+	return nil
+}
+
+// sessionFingerprint 会话ID指纹（SHA-256 前8字节），用于关联同一会话的日志而不泄露会话ID
+func sessionFingerprint(sessionID string) string {
+	if sessionID == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(sessionID))
+	return hex.EncodeToString(sum[:8])
+}
+
+// matches 判断日志是否满足查询条件
+func (f *AuditLogFilter) matches(entry *AuditLog) bool {
+	if f.UserAddress != "" && !strings.EqualFold(entry.UserAddress, f.UserAddress) {
+		return false
+	}
+	if f.Action != "" && entry.Action != f.Action {
+		return false
+	}
+	if f.Result != "" && entry.Result != f.Result {
+		return false
+	}
+	if f.StartTime != nil && entry.Timestamp.Before(*f.StartTime) {
+		return false
+	}
+	if f.EndTime != nil && entry.Timestamp.After(*f.EndTime) {
+		return false
+	}
+	return entry.RiskScore >= f.MinRiskScore
+}
+
+// auditRiskScore 按操作敏感程度估算风险分数，失败的操作额外加 0.2，上限 1
+func auditRiskScore(action, result string) float64 {
+	risk, ok := auditActionRisk[action]
+	if !ok {
+		risk = 0.1
+	}
+	if result != "success" {
+		risk += 0.2
+	}
+	if risk > 1 {
+		risk = 1
+	}
+	return risk
+}
+
+// auditSecurityLevel 风险分数对应的安全级别
+func auditSecurityLevel(risk float64) string {
+	switch {
+	case risk >= 0.7:
+		return "high"
+	case risk >= 0.4:
+		return "medium"
+	}
+	return "low"
+}
+
+// LogSecurityAction 记录安全相关操作的审计日志，归属信息取自 context
+func (sm *AdvancedSecurityManager) LogSecurityAction(ctx context.Context, action, resource, resourceID, result string, details map[string]interface{}) {
+	sm.auditLogger.LogActionCtx(ctx, action, resource, resourceID, result, details)
+}
+
+// SetAuditLogStore 设置审计日志持久化存储
+func (sm *AdvancedSecurityManager) SetAuditLogStore(store AuditLogStore) {
+	sm.auditLogger.SetStore(store)
+}
+
+// QueryAuditLogs 查询安全审计日志
+func (sm *AdvancedSecurityManager) QueryAuditLogs(filter AuditLogFilter) ([]AuditLog, int, error) {
+	return sm.auditLogger.QueryLogs(filter)
+}
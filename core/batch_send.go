@@ -0,0 +1,127 @@
+/*
+批量发送（多笔转账按顺序广播）
+
+向多个地址转账时逐笔调用发送接口，每次都从节点获取 pending nonce，前一笔尚未被节点收录时会取到相同 nonce 而互相替换。
+SendBatch 只获取一次起始 nonce，为每笔交易分配连续的 nonce 并按顺序签名广播：
+  - 支持原生币与 ERC20 混合，每笔单独估算 gasLimit，费率参数（gasPrice 或 EIP-1559）对所有交易生效
+  - 某一笔构造或广播失败时立即停止：之后的交易不再发送（否则 nonce 出现空洞，后续交易会一直卡在交易池），
+    结果中标记为 skipped；失败与跳过的转账可原样重新提交，未消耗的 nonce 会从节点重新获取
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// 批量发送中单笔转账的状态
+const (
+	BatchStatusSent    = "sent"    // 已广播
+	BatchStatusFailed  = "failed"  // 构造或广播失败
+	BatchStatusSkipped = "skipped" // 前面的交易失败，未发送
+)
+
+// MaxBatchTransfers 单次批量发送的最大转账数
+const MaxBatchTransfers = 100
+
+// Transfer 批量发送中的一笔转账
+type Transfer struct {
+	To     string   // 接收地址
+	Token  string   // ERC20 合约地址，为空表示原生币
+	Amount *big.Int // 最小单位
+}
+
+// BatchTransferResult 单笔转账的发送结果
+type BatchTransferResult struct {
+	Index  int     `json:"index"`
+	To     string  `json:"to"`
+	Token  string  `json:"token,omitempty"`
+	Amount string  `json:"amount"`
+	Nonce  *uint64 `json:"nonce,omitempty"` // 使用的 nonce（skipped 为空）
+	Status string  `json:"status"`          // sent / failed / skipped
+	TxHash string  `json:"tx_hash,omitempty"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// SendBatch 使用连续的 nonce 按顺序发送多笔转账，opts.Nonce 为起始 nonce（为空时取 pending nonce），opts.GasLimit 不生效
+// 参数校验失败或无法获取 nonce 时不发送任何交易并返回错误；发送过程中的失败记录在对应结果中
+func (a *EVMAdapter) SendBatch(ctx context.Context, signer Signer, transfers []Transfer, opts *TxOptions) ([]BatchTransferResult, error) {
+	if len(transfers) == 0 {
+		return nil, fmt.Errorf("转账列表不能为空")
+	}
+	if len(transfers) > MaxBatchTransfers {
+		return nil, fmt.Errorf("单次最多发送 %d 笔转账", MaxBatchTransfers)
+	}
+	for i, transfer := range transfers {
+		if !common.IsHexAddress(transfer.To) {
+			return nil, fmt.Errorf("第 %d 笔转账的接收地址格式不正确: %s", i+1, transfer.To)
+		}
+		if transfer.Token != "" && !common.IsHexAddress(transfer.Token) {
+			return nil, fmt.Errorf("第 %d 笔转账的代币地址格式不正确: %s", i+1, transfer.Token)
+		}
+		if transfer.Amount == nil || transfer.Amount.Sign() <= 0 {
+			return nil, fmt.Errorf("第 %d 笔转账的金额必须大于0", i+1)
+		}
+	}
+	var (
+		nonce uint64
+		err   error
+	)
+	if opts != nil && opts.Nonce != nil {
+		nonce = *opts.Nonce
+	} else {
+		nonce, err = a.client.PendingNonceAt(ctx, signer.Address())
+		if err != nil {
+			return nil, fmt.Errorf("获取nonce失败: %w", err)
+		}
+	}
+
+	results := make([]BatchTransferResult, len(transfers))
+	failed := false
+	for i, transfer := range transfers {
+		result := BatchTransferResult{
+			Index:  i,
+			To:     common.HexToAddress(transfer.To).Hex(),
+			Amount: transfer.Amount.String(),
+		}
+		if transfer.Token != "" {
+			result.Token = common.HexToAddress(transfer.Token).Hex()
+		}
+		if failed {
+			result.Status = BatchStatusSkipped
+			results[i] = result
+			continue
+		}
+
+		itemNonce := nonce
+		result.Nonce = &itemNonce
+		itemOpts := &TxOptions{Nonce: &itemNonce}
+		if opts != nil {
+			itemOpts.GasPrice, itemOpts.TipCap, itemOpts.FeeCap = opts.GasPrice, opts.TipCap, opts.FeeCap
+			itemOpts.MaxFeeWei, itemOpts.AllowHighFee = opts.MaxFeeWei, opts.AllowHighFee
+		}
+		var txHash string
+		if transfer.Token == "" {
+			txHash, err = a.sendWithSigner(ctx, signer, common.HexToAddress(transfer.To), transfer.Amount, nil, itemOpts)
+		} else {
+			var data []byte
+			if data, err = ERC20TransferCallData(transfer.To, transfer.Amount); err == nil {
+				txHash, err = a.sendWithSigner(ctx, signer, common.HexToAddress(transfer.Token), big.NewInt(0), data, itemOpts)
+			}
+		}
+		if err != nil {
+			result.Status = BatchStatusFailed
+			result.Error = err.Error()
+			failed = true
+		} else {
+			result.Status = BatchStatusSent
+			result.TxHash = txHash
+			nonce++
+		}
+		results[i] = result
+	}
+	return results, nil
+}
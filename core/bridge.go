@@ -41,6 +41,8 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"net/http"
+	"sync"
 	"time"
 )
 
@@ -62,7 +64,7 @@ type BridgeProvider interface {
 	EstimateFee(ctx context.Context, params *BridgeParams) (*BridgeFeeEstimate, error)                              // 估算费用
 	GetQuote(ctx context.Context, params *BridgeParams) (*BridgeQuote, error)                                       // 获取报价
 	ExecuteBridge(ctx context.Context, params *BridgeParams, credentials *BridgeCredentials) (*BridgeResult, error) // 执行桥接
-	GetTransactionStatus(ctx context.Context, txHash string) (*BridgeStatus, error)                                 // 获取交易状态
+	GetTransactionStatus(ctx context.Context, fromChain, toChain, txHash string) (*BridgeStatus, error)             // 获取交易状态（源链确认数与目标链到账）
 	GetEstimatedTime(fromChain, toChain string) time.Duration                                                       // 获取预估时间
 }
 
@@ -74,10 +76,12 @@ type BridgeParams struct {
 	Amount            *big.Int `json:"amount"`             // 转移数量
 	FromAddress       string   `json:"from_address"`       // 发送地址
 	ToAddress         string   `json:"to_address"`         // 接收地址
-	SlippageTolerance float64  `json:"slippage_tolerance"` // 滑点容忍度
-	Deadline          int64    `json:"deadline"`           // 截止时间
+	SlippageTolerance float64  `json:"slippage_tolerance"` // 滑点容忍度（%，0 表示默认值）
+	Deadline          int64    `json:"deadline"`           // 截止时间（Unix 秒，0 表示不限）
 	GasPrice          *big.Int `json:"gas_price"`          // Gas价格（可选）
 	Priority          string   `json:"priority"`           // 优先级（fast/normal/slow）
+	CallbackURL       string   `json:"callback_url"`       // 桥接结束时回调的Webhook地址（可选）
+	MinAmountOut      *big.Int `json:"-"`                  // 滑点保护下限（执行时由管理器计算，提供商据此校验最新报价）
 }
 
 // BridgeCredentials 桥接认证信息
@@ -103,6 +107,8 @@ type BridgeQuote struct {
 	Provider     string             `json:"provider"`       // 提供商名称
 	AmountOut    *big.Int           `json:"amount_out"`     // 输出数量
 	AmountOutMin *big.Int           `json:"amount_out_min"` // 最小输出数量
+	FromDecimals int                `json:"from_decimals"`  // 源代币精度（与目标相同时可为0）
+	ToDecimals   int                `json:"to_decimals"`    // 目标代币精度（与源相同时可为0）
 	FeeEstimate  *BridgeFeeEstimate `json:"fee_estimate"`   // 费用估算
 	Route        *BridgeRoute       `json:"route"`          // 桥接路径
 	ValidUntil   int64              `json:"valid_until"`    // 报价有效期
@@ -143,6 +149,7 @@ type BridgeResult struct {
 	EstimatedTime int64        `json:"estimated_time"` // 预估完成时间
 	ActualFee     *big.Int     `json:"actual_fee"`     // 实际费用
 	CurrentStep   int          `json:"current_step"`   // 当前步骤
+	AmountOutMin  *big.Int     `json:"amount_out_min"` // 报价最低到账数量
 }
 
 // BridgeStatus 桥接状态
@@ -157,14 +164,26 @@ type BridgeStatus struct {
 	ConfirmBlocks       int       `json:"confirm_blocks"`       // 已确认区块数
 	RequiredBlocks      int       `json:"required_blocks"`      // 需要确认区块数
 	ErrorMessage        string    `json:"error_message"`        // 错误信息
+	Provider            string    `json:"provider"`             // 桥接提供商
+	FromChain           string    `json:"from_chain"`           // 源链
+	ToChain             string    `json:"to_chain"`             // 目标链
+	CreatedAt           time.Time `json:"created_at"`           // 发起时间
 	UpdatedAt           time.Time `json:"updated_at"`           // 更新时间
 	EstimatedCompletion time.Time `json:"estimated_completion"` // 预估完成时间
+	ToAddress           string    `json:"to_address"`           // 接收地址
+	AmountOutMin        *big.Int  `json:"amount_out_min"`       // 报价最低到账数量
+	AmountReceived      *big.Int  `json:"amount_received"`      // 实际到账数量（无法核实时为空）
+	BelowMinimum        bool      `json:"below_minimum"`        // 实际到账低于最低到账
+	callbackURL         string    // 结束时回调的Webhook地址
 }
 
 // BridgeStatusTracker 桥接状态追踪器
 type BridgeStatusTracker struct {
 	activeBridges  map[string]*BridgeStatus // 活跃桥接
 	historyBridges map[string]*BridgeStatus // 历史桥接
+	httpClient     *http.Client             // Webhook 回调客户端
+	onFinished     func(*BridgeStatus)      // 桥接结束回调
+	mu             sync.RWMutex             // 读写锁
 }
 
 // BridgeHistory 桥接历史记录
@@ -177,10 +196,12 @@ type BridgeHistory struct {
 
 // BridgeRecord 桥接记录
 type BridgeRecord struct {
-	*BridgeResult            // 继承桥接结果
-	CompletedAt   *time.Time `json:"completed_at"`   // 完成时间
-	Success       bool       `json:"success"`        // 是否成功
-	FailureReason string     `json:"failure_reason"` // 失败原因
+	*BridgeResult             // 继承桥接结果
+	CompletedAt    *time.Time `json:"completed_at"`    // 完成时间
+	Success        bool       `json:"success"`         // 是否成功
+	FailureReason  string     `json:"failure_reason"`  // 失败原因
+	AmountReceived *big.Int   `json:"amount_received"` // 实际到账数量
+	BelowMinimum   bool       `json:"below_minimum"`   // 实际到账低于报价最低到账
 }
 
 // BridgeAnalytics 桥接分析数据
@@ -258,6 +279,9 @@ func NewBridgeManager(multiChain *MultiChainManager) (*BridgeManager, error) {
 		return nil, fmt.Errorf("初始化桥接提供商失败: %w", err)
 	}
 
+	// 后台轮询活跃桥接的链上状态
+	go manager.pollLoop(bridgePollInterval)
+
 	return manager, nil
 }
 
@@ -304,20 +328,34 @@ func (bm *BridgeManager) ExecuteBridge(ctx context.Context, params *BridgeParams
 		return nil, fmt.Errorf("获取桥接路径失败: %w", err)
 	}
 
-	// 获取对应的提供商
-	provider, exists := bm.bridgeProviders[quote.Provider]
-	if !exists {
+	// 获取对应的提供商（报价中为提供商名称）
+	providerID, provider := bm.providerByName(quote.Provider)
+	if provider == nil {
 		return nil, fmt.Errorf("桥接提供商不存在: %s", quote.Provider)
 	}
 
+	// 滑点与截止时间保护：报价最低到账不得低于下限，提供商提交前据此校验最新报价
+	minimum, err := checkQuoteMinimum(params, quote)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBridgeDeadline(params, time.Now()); err != nil {
+		return nil, err
+	}
+	execParams := *params
+	execParams.MinAmountOut = minimum
+
 	// 执行桥接
-	result, err := provider.ExecuteBridge(ctx, params, credentials)
+	result, err := provider.ExecuteBridge(ctx, &execParams, credentials)
 	if err != nil {
 		return nil, fmt.Errorf("执行桥接失败: %w", err)
 	}
+	if result.AmountOutMin == nil {
+		result.AmountOutMin = quote.AmountOutMin
+	}
 
 	// 开始状态追踪
-	bm.statusTracker.StartTracking(result.BridgeID, result)
+	bm.statusTracker.StartTracking(providerID, params, result, quote.FeeEstimate.ConfirmBlocks)
 
 	return result, nil
 }
@@ -334,32 +372,58 @@ func NewBridgeStatusTracker() *BridgeStatusTracker {
 	return &BridgeStatusTracker{
 		activeBridges:  make(map[string]*BridgeStatus),
 		historyBridges: make(map[string]*BridgeStatus),
+		httpClient: &http.Client{
+			Timeout:   bridgeWebhookTimeout,
+			Transport: NewSafeTransport(),
+			// 不跟随重定向，回调地址只能是发起时校验过的地址
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
 	}
 }
 
-// StartTracking 开始追踪桥接状态
-func (bst *BridgeStatusTracker) StartTracking(bridgeID string, result *BridgeResult) {
+// StartTracking 开始追踪桥接状态，由后台轮询推进进度
+func (bst *BridgeStatusTracker) StartTracking(providerID string, params *BridgeParams, result *BridgeResult, requiredBlocks int) {
+	totalSteps := bridgeTotalSteps
+	if result.Route != nil && len(result.Route.Steps) > 0 {
+		totalSteps = len(result.Route.Steps)
+	}
+	now := time.Now()
 	status := &BridgeStatus{
-		BridgeID:            bridgeID,
+		BridgeID:            result.BridgeID,
 		Status:              result.Status,
 		Progress:            0.0,
 		CurrentStep:         result.CurrentStep,
-		TotalSteps:          len(result.Route.Steps),
+		TotalSteps:          totalSteps,
 		FromTxHash:          result.FromTxHash,
 		ToTxHash:            result.ToTxHash,
-		UpdatedAt:           time.Now(),
-		EstimatedCompletion: time.Now().Add(time.Duration(result.EstimatedTime) * time.Second),
+		RequiredBlocks:      requiredBlocks,
+		Provider:            providerID,
+		FromChain:           params.FromChain,
+		ToChain:             params.ToChain,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+		EstimatedCompletion: now.Add(time.Duration(result.EstimatedTime) * time.Second),
+		ToAddress:           params.ToAddress,
+		AmountOutMin:        result.AmountOutMin,
+		callbackURL:         params.CallbackURL,
 	}
-	bst.activeBridges[bridgeID] = status
+
+	bst.mu.Lock()
+	defer bst.mu.Unlock()
+	bst.activeBridges[result.BridgeID] = status
 }
 
-// GetStatus 获取桥接状态
+// GetStatus 获取桥接状态（副本）
 func (bst *BridgeStatusTracker) GetStatus(bridgeID string) *BridgeStatus {
+	bst.mu.RLock()
+	defer bst.mu.RUnlock()
 	if status, exists := bst.activeBridges[bridgeID]; exists {
-		return status
+		snapshot := *status
+		return &snapshot
 	}
 	if status, exists := bst.historyBridges[bridgeID]; exists {
-		return status
+		snapshot := *status
+		return &snapshot
 	}
 	return nil
 }
@@ -370,13 +434,16 @@ func (bm *BridgeManager) initBridgeProviders() error {
 	// 实际实现中会注册真实的桥接协议
 
 	// 示例：Polygon官方桥接
-	polygonBridge := &PolygonBridge{}
+	polygonBridge := &PolygonBridge{multiChain: bm.multiChain}
 	bm.bridgeProviders["polygon_bridge"] = polygonBridge
 
 	// 示例：多链桥接
-	multichainBridge := &MultichainBridge{}
+	multichainBridge := &MultichainBridge{multiChain: bm.multiChain}
 	bm.bridgeProviders["multichain"] = multichainBridge
 
+	// LI.FI 聚合桥接（真实报价与交易数据）
+	bm.bridgeProviders["lifi"] = NewLiFiBridge(bm.multiChain)
+
 	return nil
 }
 
@@ -394,7 +461,10 @@ func (bm *BridgeManager) validateBridgeParams(params *BridgeParams) error {
 	if params.Amount == nil || params.Amount.Sign() <= 0 {
 		return fmt.Errorf("转移数量必须大于0")
 	}
-	return nil
+	if _, err := BridgeSlippage(params.SlippageTolerance); err != nil {
+		return err
+	}
+	return checkBridgeDeadline(params, time.Now())
 }
 
 // isRouteSupported 检查是否支持该路径
@@ -449,7 +519,9 @@ func (bm *BridgeManager) isQuoteBetter(a, b *BridgeQuote, priority string) bool
 // 示例桥接提供商实现
 
 // PolygonBridge Polygon官方桥接
-type PolygonBridge struct{}
+type PolygonBridge struct {
+	multiChain *MultiChainManager
+}
 
 func (p *PolygonBridge) GetName() string {
 	return "Polygon Bridge"
@@ -473,7 +545,7 @@ func (p *PolygonBridge) EstimateFee(ctx context.Context, params *BridgeParams) (
 		TotalFee:      big.NewInt(27000000000000000), // 0.027 ETH
 		Currency:      "ETH",
 		EstimatedTime: 300, // 5分钟
-		ConfirmBlocks: 12,
+		ConfirmBlocks: polygonBridgeConfirmBlocks,
 	}, nil
 }
 
@@ -483,10 +555,16 @@ func (p *PolygonBridge) GetQuote(ctx context.Context, params *BridgeParams) (*Br
 		return nil, err
 	}
 
+	// 官方桥 1:1 到账，最低到账按滑点容忍度计算
+	amountOutMin, err := BridgeMinimumOut(params.Amount, 0, 0, params.SlippageTolerance)
+	if err != nil {
+		return nil, err
+	}
+
 	return &BridgeQuote{
 		Provider:     p.GetName(),
 		AmountOut:    params.Amount,
-		AmountOutMin: new(big.Int).Mul(params.Amount, big.NewInt(99)).Div(new(big.Int).Mul(params.Amount, big.NewInt(99)), big.NewInt(100)),
+		AmountOutMin: amountOutMin,
 		FeeEstimate:  feeEstimate,
 		ValidUntil:   time.Now().Add(5 * time.Minute).Unix(),
 		Confidence:   0.95,
@@ -506,14 +584,22 @@ func (p *PolygonBridge) ExecuteBridge(ctx context.Context, params *BridgeParams,
 	}, nil
 }
 
-func (p *PolygonBridge) GetTransactionStatus(ctx context.Context, txHash string) (*BridgeStatus, error) {
-	// 简化实现
-	return &BridgeStatus{
-		BridgeID:  "example_bridge",
-		Status:    "completed",
-		Progress:  1.0,
-		UpdatedAt: time.Now(),
-	}, nil
+// GetTransactionStatus 查询源链确认数；确认后检查目标链状态：
+// 以太坊 → Polygon 比对 StateSynced 事件 ID 与 Polygon StateReceiver.lastStateId（已同步即到账）；
+// Polygon → 以太坊 比对燃烧交易区块与 RootChain 最新检查点（已包含即可在以太坊提交退出）
+func (p *PolygonBridge) GetTransactionStatus(ctx context.Context, fromChain, toChain, txHash string) (*BridgeStatus, error) {
+	receipt, status, err := sourceChainStatus(ctx, p.multiChain, fromChain, txHash, polygonBridgeConfirmBlocks)
+	if err != nil || receipt == nil || status.Status != BridgeStatusProcessing {
+		return status, err
+	}
+
+	switch {
+	case fromChain == "ethereum" && toChain == "polygon":
+		return status, p.checkDeposit(ctx, receipt, status)
+	case fromChain == "polygon" && toChain == "ethereum":
+		return status, p.checkCheckpoint(ctx, receipt, status)
+	}
+	return status, nil
 }
 
 func (p *PolygonBridge) GetEstimatedTime(fromChain, toChain string) time.Duration {
@@ -521,7 +607,9 @@ func (p *PolygonBridge) GetEstimatedTime(fromChain, toChain string) time.Duratio
 }
 
 // MultichainBridge 多链桥接实现
-type MultichainBridge struct{}
+type MultichainBridge struct {
+	multiChain *MultiChainManager
+}
 
 func (m *MultichainBridge) GetName() string {
 	return "Multichain"
@@ -543,7 +631,7 @@ func (m *MultichainBridge) EstimateFee(ctx context.Context, params *BridgeParams
 		TotalFee:      big.NewInt(35000000000000000), // 0.035 ETH
 		Currency:      "ETH",
 		EstimatedTime: 180, // 3分钟
-		ConfirmBlocks: 6,
+		ConfirmBlocks: multichainConfirmBlocks,
 	}, nil
 }
 
@@ -553,10 +641,16 @@ func (m *MultichainBridge) GetQuote(ctx context.Context, params *BridgeParams) (
 		return nil, err
 	}
 
+	// 同币种路由按 1:1 到账，最低到账按滑点容忍度计算
+	amountOutMin, err := BridgeMinimumOut(params.Amount, 0, 0, params.SlippageTolerance)
+	if err != nil {
+		return nil, err
+	}
+
 	return &BridgeQuote{
 		Provider:     m.GetName(),
 		AmountOut:    params.Amount,
-		AmountOutMin: new(big.Int).Mul(params.Amount, big.NewInt(98)).Div(new(big.Int).Mul(params.Amount, big.NewInt(98)), big.NewInt(100)),
+		AmountOutMin: amountOutMin,
 		FeeEstimate:  feeEstimate,
 		ValidUntil:   time.Now().Add(3 * time.Minute).Unix(),
 		Confidence:   0.90,
@@ -575,13 +669,11 @@ func (m *MultichainBridge) ExecuteBridge(ctx context.Context, params *BridgePara
 	}, nil
 }
 
-func (m *MultichainBridge) GetTransactionStatus(ctx context.Context, txHash string) (*BridgeStatus, error) {
-	return &BridgeStatus{
-		BridgeID:  "example_multichain",
-		Status:    "pending",
-		Progress:  0.5,
-		UpdatedAt: time.Now(),
-	}, nil
+// GetTransactionStatus 查询源链确认数
+// Multichain 路由已停止运营，目标链到账无法通过公开接口核实，源链确认后保持 processing，超时由追踪器标记为 expired
+func (m *MultichainBridge) GetTransactionStatus(ctx context.Context, fromChain, toChain, txHash string) (*BridgeStatus, error) {
+	_, status, err := sourceChainStatus(ctx, m.multiChain, fromChain, txHash, multichainConfirmBlocks)
+	return status, err
 }
 
 func (m *MultichainBridge) GetEstimatedTime(fromChain, toChain string) time.Duration {
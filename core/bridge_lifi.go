@@ -0,0 +1,701 @@
+/*
+LI.FI 跨链聚合桥接提供商
+
+通过 LI.FI REST API（https://li.quest/v1）聚合多家桥接协议：
+- GET /chains：支持的链（缓存 1 小时），与本地已启用的 EVM 主网按 chain_id 取交集
+- GET /tokens：两条链都支持的代币符号（缓存 1 小时）
+- GET /quote：报价，包含执行路径、费用与可直接签名的 transactionRequest
+- GET /status：源链确认后查询目标链到账情况
+
+ExecuteBridge 重新获取报价，ERC20 授权不足时先发送 approve（授权额度为本次数量），
+再按 transactionRequest 的 to/data/value/gasLimit/gasPrice 签名并广播桥接交易。
+API 密钥（可选，提高限额）来自用户配置的 lifi 密钥或全局 provider_keys.lifi。
+*/
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"wallet/config"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	lifiBaseURL       = "https://li.quest/v1"
+	lifiCacheTTL      = time.Hour
+	lifiLookupTimeout = 15 * time.Second
+	lifiNativeToken   = "0x0000000000000000000000000000000000000000"
+	lifiConfirmBlocks = 3
+)
+
+// lifiToken LI.FI 代币信息
+type lifiToken struct {
+	Address  string `json:"address"`
+	Symbol   string `json:"symbol"`
+	Decimals int    `json:"decimals"`
+	ChainID  int64  `json:"chainId"`
+}
+
+// lifiCost LI.FI 费用项（gasCosts / feeCosts）
+type lifiCost struct {
+	Name     string    `json:"name"`
+	Amount   string    `json:"amount"`
+	Included bool      `json:"included"` // 费用已从转出数量中扣除
+	Token    lifiToken `json:"token"`
+}
+
+// lifiEstimate LI.FI 报价估算
+type lifiEstimate struct {
+	Tool              string     `json:"tool"`
+	FromAmount        string     `json:"fromAmount"`
+	ToAmount          string     `json:"toAmount"`
+	ToAmountMin       string     `json:"toAmountMin"`
+	ApprovalAddress   string     `json:"approvalAddress"`
+	ExecutionDuration float64    `json:"executionDuration"` // 秒
+	FeeCosts          []lifiCost `json:"feeCosts"`
+	GasCosts          []lifiCost `json:"gasCosts"`
+}
+
+// lifiQuote GET /quote 响应
+type lifiQuote struct {
+	ID          string `json:"id"`
+	Tool        string `json:"tool"`
+	ToolDetails struct {
+		Name string `json:"name"`
+	} `json:"toolDetails"`
+	Action struct {
+		FromChainID int64     `json:"fromChainId"`
+		ToChainID   int64     `json:"toChainId"`
+		FromToken   lifiToken `json:"fromToken"`
+		ToToken     lifiToken `json:"toToken"`
+	} `json:"action"`
+	Estimate      lifiEstimate `json:"estimate"`
+	IncludedSteps []struct {
+		Type     string       `json:"type"` // swap / cross / protocol
+		Tool     string       `json:"tool"`
+		Estimate lifiEstimate `json:"estimate"`
+		Action   struct {
+			FromChainID int64 `json:"fromChainId"`
+		} `json:"action"`
+	} `json:"includedSteps"`
+	TransactionRequest struct {
+		From     string `json:"from"`
+		To       string `json:"to"`
+		ChainID  int64  `json:"chainId"`
+		Data     string `json:"data"`
+		Value    string `json:"value"`
+		GasPrice string `json:"gasPrice"`
+		GasLimit string `json:"gasLimit"`
+	} `json:"transactionRequest"`
+}
+
+// lifiStatus GET /status 响应
+type lifiStatus struct {
+	Status    string `json:"status"`    // NOT_FOUND / INVALID / PENDING / DONE / FAILED
+	Substatus string `json:"substatus"` // DONE 时：COMPLETED / PARTIAL / REFUNDED
+	Receiving struct {
+		TxHash string `json:"txHash"`
+		Amount string `json:"amount"` // 目标链实际到账数量
+	} `json:"receiving"`
+}
+
+// LiFiBridge LI.FI 聚合桥接
+type LiFiBridge struct {
+	multiChain *MultiChainManager
+	baseURL    string
+	httpClient *http.Client
+
+	mu         sync.Mutex
+	chainIDs   map[int64]bool              // LI.FI 支持的链
+	chainsAt   time.Time                   // chainIDs 获取时间
+	tokens     map[int64]map[string]string // chainID -> 符号 -> 地址
+	tokensAt   map[int64]time.Time
+	lastQuotes map[string]*lifiQuote // 报价缓存（一分钟），EstimateFee/GetQuote 共用一次请求
+}
+
+// NewLiFiBridge 创建 LI.FI 桥接提供商
+func NewLiFiBridge(multiChain *MultiChainManager) *LiFiBridge {
+	return &LiFiBridge{
+		multiChain: multiChain,
+		baseURL:    lifiBaseURL,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		tokens:     make(map[int64]map[string]string),
+		tokensAt:   make(map[int64]time.Time),
+		lastQuotes: make(map[string]*lifiQuote),
+	}
+}
+
+func (l *LiFiBridge) GetName() string {
+	return "LI.FI"
+}
+
+// GetSupportedChains 已启用的 EVM 主网中 LI.FI 支持的网络；LI.FI 不可用时返回全部 EVM 主网
+func (l *LiFiBridge) GetSupportedChains() []string {
+	ctx, cancel := context.WithTimeout(context.Background(), lifiLookupTimeout)
+	defer cancel()
+	supported, fetchErr := l.chains(ctx)
+
+	chains := make([]string, 0)
+	for networkID, network := range config.GetMainnetNetworks() {
+		if _, err := bridgeEVMAdapter(l.multiChain, networkID); err != nil {
+			continue
+		}
+		if fetchErr == nil && !supported[network.ChainID] {
+			continue
+		}
+		chains = append(chains, networkID)
+	}
+	return chains
+}
+
+// GetSupportedTokens 两条链都支持的代币符号
+func (l *LiFiBridge) GetSupportedTokens(fromChain, toChain string) ([]string, error) {
+	fromID, err := lifiChainID(fromChain)
+	if err != nil {
+		return nil, err
+	}
+	toID, err := lifiChainID(toChain)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), lifiLookupTimeout)
+	defer cancel()
+	fromTokens, err := l.chainTokens(ctx, fromID)
+	if err != nil {
+		return nil, err
+	}
+	toTokens, err := l.chainTokens(ctx, toID)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]string, 0)
+	for symbol := range fromTokens {
+		if _, ok := toTokens[symbol]; ok {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols, nil
+}
+
+// EstimateFee 根据 LI.FI 报价估算费用（仅统计以源链原生代币计价的 Gas 与未包含在转出数量中的费用）
+func (l *LiFiBridge) EstimateFee(ctx context.Context, params *BridgeParams) (*BridgeFeeEstimate, error) {
+	quote, err := l.fetchQuote(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return lifiFeeEstimate(quote), nil
+}
+
+// GetQuote 获取 LI.FI 报价
+func (l *LiFiBridge) GetQuote(ctx context.Context, params *BridgeParams) (*BridgeQuote, error) {
+	quote, err := l.fetchQuote(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return l.bridgeQuote(quote)
+}
+
+// bridgeQuote 将 LI.FI 报价转换为统一报价格式
+func (l *LiFiBridge) bridgeQuote(quote *lifiQuote) (*BridgeQuote, error) {
+	amountOut, ok := new(big.Int).SetString(quote.Estimate.ToAmount, 10)
+	if !ok {
+		return nil, fmt.Errorf("LI.FI报价输出数量无效: %s", quote.Estimate.ToAmount)
+	}
+	amountOutMin, ok := new(big.Int).SetString(quote.Estimate.ToAmountMin, 10)
+	if !ok {
+		amountOutMin = amountOut
+	}
+
+	fee := lifiFeeEstimate(quote)
+	route := &BridgeRoute{
+		Steps:      make([]*BridgeStep, 0, len(quote.IncludedSteps)),
+		TotalTime:  fee.EstimatedTime,
+		TotalFee:   fee.TotalFee,
+		Complexity: "simple",
+		RiskLevel:  "low",
+	}
+	for i, step := range quote.IncludedSteps {
+		chain, _ := lifiNetworkID(step.Action.FromChainID)
+		route.Steps = append(route.Steps, &BridgeStep{
+			StepNumber:    i + 1,
+			Action:        step.Type,
+			Chain:         chain,
+			Description:   fmt.Sprintf("%s via %s", step.Type, step.Tool),
+			EstimatedTime: int64(step.Estimate.ExecutionDuration),
+			Status:        "pending",
+		})
+	}
+	if len(route.Steps) > 1 {
+		route.Complexity = "medium"
+	}
+
+	warnings := make([]string, 0)
+	if quote.Action.FromToken.Symbol != quote.Action.ToToken.Symbol {
+		warnings = append(warnings, fmt.Sprintf("目标链将收到 %s（源代币为 %s）", quote.Action.ToToken.Symbol, quote.Action.FromToken.Symbol))
+	}
+
+	return &BridgeQuote{
+		Provider:     l.GetName(),
+		AmountOut:    amountOut,
+		AmountOutMin: amountOutMin,
+		FromDecimals: quote.Action.FromToken.Decimals,
+		ToDecimals:   quote.Action.ToToken.Decimals,
+		FeeEstimate:  fee,
+		Route:        route,
+		ValidUntil:   time.Now().Add(time.Minute).Unix(),
+		Warnings:     warnings,
+		Confidence:   0.95,
+	}, nil
+}
+
+// ExecuteBridge 按 LI.FI 报价的 transactionRequest 签名并广播桥接交易
+func (l *LiFiBridge) ExecuteBridge(ctx context.Context, params *BridgeParams, credentials *BridgeCredentials) (*BridgeResult, error) {
+	if credentials == nil || credentials.Mnemonic == "" {
+		return nil, fmt.Errorf("缺少签名凭据")
+	}
+	signer, err := NewMnemonicSigner(credentials.Mnemonic, credentials.DerivationPath)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(signer.Address().Hex(), params.FromAddress) {
+		return nil, fmt.Errorf("签名账户 %s 与发送地址 %s 不一致", signer.Address().Hex(), params.FromAddress)
+	}
+
+	// 交易数据有时效，执行前重新获取报价
+	quote, err := l.requestQuote(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	txReq := quote.TransactionRequest
+	if !common.IsHexAddress(txReq.To) {
+		return nil, fmt.Errorf("LI.FI报价缺少交易数据")
+	}
+	if txReq.From != "" && !strings.EqualFold(txReq.From, params.FromAddress) {
+		return nil, fmt.Errorf("LI.FI交易发送方 %s 与请求不一致", txReq.From)
+	}
+	amountOutMin, ok := new(big.Int).SetString(quote.Estimate.ToAmountMin, 10)
+	if !ok {
+		return nil, fmt.Errorf("LI.FI报价缺少最低到账数量")
+	}
+	if params.MinAmountOut != nil && amountOutMin.Cmp(params.MinAmountOut) < 0 {
+		return nil, fmt.Errorf("最新报价最低到账 %s 低于滑点保护下限 %s", amountOutMin, params.MinAmountOut)
+	}
+	if err := checkBridgeDeadline(params, time.Now()); err != nil {
+		return nil, err
+	}
+
+	adapter, err := bridgeEVMAdapter(l.multiChain, params.FromChain)
+	if err != nil {
+		return nil, err
+	}
+	chainID, err := adapter.client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链ID失败: %w", err)
+	}
+	if txReq.ChainID != 0 && chainID.Int64() != txReq.ChainID {
+		return nil, fmt.Errorf("LI.FI交易链ID %d 与网络 %s（%d）不一致", txReq.ChainID, params.FromChain, chainID.Int64())
+	}
+
+	nonce, err := adapter.client.PendingNonceAt(ctx, signer.Address())
+	if err != nil {
+		return nil, fmt.Errorf("获取nonce失败: %w", err)
+	}
+
+	// ERC20 授权不足时先授权给 LI.FI 合约
+	if params.TokenAddress != "" && common.IsHexAddress(quote.Estimate.ApprovalAddress) {
+		allowance, err := adapter.GetAllowance(ctx, params.TokenAddress, params.FromAddress, quote.Estimate.ApprovalAddress)
+		if err != nil {
+			return nil, err
+		}
+		if allowance.Cmp(params.Amount) < 0 {
+			approveNonce := nonce
+			if _, err := adapter.ApproveWithSigner(ctx, signer, params.TokenAddress, quote.Estimate.ApprovalAddress, params.Amount, &TxOptions{Nonce: &approveNonce}); err != nil {
+				return nil, fmt.Errorf("授权代币失败: %w", err)
+			}
+			nonce++
+		}
+	}
+
+	opts := &TxOptions{Nonce: &nonce}
+	if gasLimit, err := lifiHexQuantity(txReq.GasLimit); err != nil {
+		return nil, err
+	} else if gasLimit != nil {
+		opts.GasLimit = gasLimit.Uint64()
+	}
+	if opts.GasPrice, err = lifiHexQuantity(txReq.GasPrice); err != nil {
+		return nil, err
+	}
+	value, err := lifiHexQuantity(txReq.Value)
+	if err != nil {
+		return nil, err
+	}
+	data, err := hexutil.Decode(txReq.Data)
+	if err != nil {
+		return nil, fmt.Errorf("LI.FI交易数据无效: %w", err)
+	}
+
+	txHash, err := adapter.sendWithSigner(ctx, signer, common.HexToAddress(txReq.To), value, data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	fee := lifiFeeEstimate(quote)
+	result := &BridgeResult{
+		BridgeID:      fmt.Sprintf("lifi_%d", time.Now().UnixNano()),
+		FromTxHash:    txHash,
+		Status:        BridgeStatusPending,
+		CreatedAt:     time.Now(),
+		EstimatedTime: int64(quote.Estimate.ExecutionDuration),
+		ActualFee:     fee.TotalFee,
+		CurrentStep:   1,
+		AmountOutMin:  amountOutMin,
+	}
+	if bridgeQuote, err := l.bridgeQuote(quote); err == nil {
+		result.Route = bridgeQuote.Route
+	}
+	return result, nil
+}
+
+// GetTransactionStatus 源链确认后通过 LI.FI /status 查询目标链到账
+func (l *LiFiBridge) GetTransactionStatus(ctx context.Context, fromChain, toChain, txHash string) (*BridgeStatus, error) {
+	_, status, err := sourceChainStatus(ctx, l.multiChain, fromChain, txHash, lifiConfirmBlocks)
+	if err != nil || status.Status != BridgeStatusProcessing {
+		return status, err
+	}
+
+	query := url.Values{"txHash": {txHash}}
+	if id, err := lifiChainID(fromChain); err == nil {
+		query.Set("fromChain", strconv.FormatInt(id, 10))
+	}
+	if id, err := lifiChainID(toChain); err == nil {
+		query.Set("toChain", strconv.FormatInt(id, 10))
+	}
+	var resp lifiStatus
+	if err := l.get(ctx, "/status", query, &resp); err != nil {
+		return nil, err
+	}
+
+	status.ToTxHash = resp.Receiving.TxHash
+	if amount, ok := new(big.Int).SetString(resp.Receiving.Amount, 10); ok {
+		status.AmountReceived = amount
+	}
+	switch resp.Status {
+	case "DONE":
+		switch resp.Substatus {
+		case "REFUNDED":
+			status.Status = BridgeStatusFailed
+			status.ErrorMessage = "桥接失败，资金已退回源链"
+		case "PARTIAL":
+			status.Status = BridgeStatusCompleted
+			status.ErrorMessage = "部分完成：目标链收到的是其他代币"
+		default:
+			status.Status = BridgeStatusCompleted
+		}
+	case "FAILED", "INVALID":
+		status.Status = BridgeStatusFailed
+		status.ErrorMessage = "LI.FI报告桥接失败"
+	}
+	if status.Status == BridgeStatusCompleted {
+		status.CurrentStep = 3
+		status.Progress = 1.0
+	}
+	return status, nil
+}
+
+func (l *LiFiBridge) GetEstimatedTime(fromChain, toChain string) time.Duration {
+	return 5 * time.Minute
+}
+
+// fetchQuote 获取报价，一分钟内相同参数复用上次结果
+func (l *LiFiBridge) fetchQuote(ctx context.Context, params *BridgeParams) (*lifiQuote, error) {
+	key := lifiQuoteKey(params)
+	l.mu.Lock()
+	cached, ok := l.lastQuotes[key]
+	l.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+	return l.requestQuote(ctx, params)
+}
+
+// requestQuote 请求 LI.FI /quote 并缓存一分钟
+func (l *LiFiBridge) requestQuote(ctx context.Context, params *BridgeParams) (*lifiQuote, error) {
+	fromID, err := lifiChainID(params.FromChain)
+	if err != nil {
+		return nil, err
+	}
+	toID, err := lifiChainID(params.ToChain)
+	if err != nil {
+		return nil, err
+	}
+	if params.Amount == nil || params.Amount.Sign() <= 0 {
+		return nil, fmt.Errorf("转移数量必须大于0")
+	}
+
+	fromToken, toToken := lifiNativeToken, lifiNativeToken
+	if params.TokenAddress != "" {
+		fromToken = params.TokenAddress
+		symbol, err := l.tokenSymbol(ctx, fromID, params.TokenAddress)
+		if err != nil {
+			return nil, err
+		}
+		toToken = symbol // LI.FI 接受代币符号，按目标链同名代币到账
+	}
+	toAddress := params.ToAddress
+	if toAddress == "" {
+		toAddress = params.FromAddress
+	}
+
+	query := url.Values{
+		"fromChain":   {strconv.FormatInt(fromID, 10)},
+		"toChain":     {strconv.FormatInt(toID, 10)},
+		"fromToken":   {fromToken},
+		"toToken":     {toToken},
+		"fromAmount":  {params.Amount.String()},
+		"fromAddress": {params.FromAddress},
+		"toAddress":   {toAddress},
+		"order":       {lifiOrder(params.Priority)},
+	}
+	// 请求中的滑点为百分比（0.5 表示 0.5%），LI.FI 使用小数
+	slippage, err := BridgeSlippage(params.SlippageTolerance)
+	if err != nil {
+		return nil, err
+	}
+	query.Set("slippage", strconv.FormatFloat(slippage/100, 'f', -1, 64))
+
+	var quote lifiQuote
+	if err := l.get(ctx, "/quote", query, &quote); err != nil {
+		return nil, err
+	}
+
+	key := lifiQuoteKey(params)
+	l.mu.Lock()
+	l.lastQuotes[key] = &quote
+	l.mu.Unlock()
+	time.AfterFunc(time.Minute, func() {
+		l.mu.Lock()
+		if l.lastQuotes[key] == &quote {
+			delete(l.lastQuotes, key)
+		}
+		l.mu.Unlock()
+	})
+	return &quote, nil
+}
+
+// chains LI.FI 支持的链ID（缓存）
+func (l *LiFiBridge) chains(ctx context.Context) (map[int64]bool, error) {
+	l.mu.Lock()
+	if l.chainIDs != nil && time.Since(l.chainsAt) < lifiCacheTTL {
+		defer l.mu.Unlock()
+		return l.chainIDs, nil
+	}
+	l.mu.Unlock()
+
+	var resp struct {
+		Chains []struct {
+			ID int64 `json:"id"`
+		} `json:"chains"`
+	}
+	if err := l.get(ctx, "/chains", nil, &resp); err != nil {
+		return nil, err
+	}
+	ids := make(map[int64]bool, len(resp.Chains))
+	for _, chain := range resp.Chains {
+		ids[chain.ID] = true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.chainIDs, l.chainsAt = ids, time.Now()
+	return ids, nil
+}
+
+// chainTokens 链上 LI.FI 支持的代币（符号 -> 地址，缓存）
+func (l *LiFiBridge) chainTokens(ctx context.Context, chainID int64) (map[string]string, error) {
+	l.mu.Lock()
+	if tokens, ok := l.tokens[chainID]; ok && time.Since(l.tokensAt[chainID]) < lifiCacheTTL {
+		defer l.mu.Unlock()
+		return tokens, nil
+	}
+	l.mu.Unlock()
+
+	var resp struct {
+		Tokens map[string][]lifiToken `json:"tokens"`
+	}
+	if err := l.get(ctx, "/tokens", url.Values{"chains": {strconv.FormatInt(chainID, 10)}}, &resp); err != nil {
+		return nil, err
+	}
+	tokens := make(map[string]string)
+	for _, token := range resp.Tokens[strconv.FormatInt(chainID, 10)] {
+		tokens[token.Symbol] = token.Address
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens[chainID], l.tokensAt[chainID] = tokens, time.Now()
+	return tokens, nil
+}
+
+// tokenSymbol 查询代币符号
+func (l *LiFiBridge) tokenSymbol(ctx context.Context, chainID int64, tokenAddress string) (string, error) {
+	var token lifiToken
+	query := url.Values{"chain": {strconv.FormatInt(chainID, 10)}, "token": {tokenAddress}}
+	if err := l.get(ctx, "/token", query, &token); err != nil {
+		return "", err
+	}
+	if token.Symbol == "" {
+		return "", fmt.Errorf("LI.FI不支持代币 %s", tokenAddress)
+	}
+	return token.Symbol, nil
+}
+
+// get 请求 LI.FI API 并解析 JSON
+func (l *LiFiBridge) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	endpoint := l.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	apiKey := ProviderKeyFromContext(ctx, "lifi")
+	if apiKey == "" {
+		apiKey = config.AppConfig.Security.ProviderKeys["lifi"]
+	}
+	if apiKey != "" {
+		req.Header.Set("x-lifi-api-key", apiKey)
+	}
+
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求LI.FI失败: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取LI.FI响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("LI.FI返回错误(HTTP %d): %s", resp.StatusCode, apiErr.Message)
+		}
+		return fmt.Errorf("LI.FI返回错误: HTTP %d", resp.StatusCode)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("解析LI.FI响应失败: %w", err)
+	}
+	return nil
+}
+
+// lifiFeeEstimate 汇总报价费用：Gas 与未包含在转出数量中的协议费（仅统计源链原生代币计价部分）
+func lifiFeeEstimate(quote *lifiQuote) *BridgeFeeEstimate {
+	gasFee, bridgeFee := big.NewInt(0), big.NewInt(0)
+	currency := ""
+	for _, cost := range quote.Estimate.GasCosts {
+		if amount, ok := new(big.Int).SetString(cost.Amount, 10); ok && lifiIsNative(cost.Token.Address) {
+			gasFee.Add(gasFee, amount)
+			currency = cost.Token.Symbol
+		}
+	}
+	for _, cost := range quote.Estimate.FeeCosts {
+		if cost.Included || !lifiIsNative(cost.Token.Address) {
+			continue
+		}
+		if amount, ok := new(big.Int).SetString(cost.Amount, 10); ok {
+			bridgeFee.Add(bridgeFee, amount)
+			currency = cost.Token.Symbol
+		}
+	}
+	if currency == "" {
+		if network, ok := lifiNetworkID(quote.Action.FromChainID); ok {
+			currency = config.AppConfig.Networks[network].Symbol
+		}
+	}
+	return &BridgeFeeEstimate{
+		GasFee:        gasFee,
+		BridgeFee:     bridgeFee,
+		ProtocolFee:   big.NewInt(0),
+		TotalFee:      new(big.Int).Add(gasFee, bridgeFee),
+		Currency:      currency,
+		EstimatedTime: int64(quote.Estimate.ExecutionDuration),
+		ConfirmBlocks: lifiConfirmBlocks,
+	}
+}
+
+// lifiChainID 网络ID对应的链ID
+func lifiChainID(networkID string) (int64, error) {
+	network, ok := config.AppConfig.Networks[networkID]
+	if !ok || network.ChainID == 0 {
+		return 0, fmt.Errorf("未配置网络: %s", networkID)
+	}
+	return network.ChainID, nil
+}
+
+// lifiNetworkID 链ID对应的已启用网络ID
+func lifiNetworkID(chainID int64) (string, bool) {
+	for networkID, network := range config.GetEnabledNetworks() {
+		if network.ChainID == chainID {
+			return networkID, true
+		}
+	}
+	return "", false
+}
+
+// lifiIsNative 是否为原生代币地址（LI.FI 使用零地址或 0xEeee...）
+func lifiIsNative(address string) bool {
+	return address == lifiNativeToken || strings.EqualFold(address, "0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+}
+
+// lifiOrder 优先级对应的 LI.FI 路由排序
+func lifiOrder(priority string) string {
+	switch priority {
+	case "fast":
+		return "FASTEST"
+	case "cheap":
+		return "CHEAPEST"
+	default:
+		return "RECOMMENDED"
+	}
+}
+
+// lifiHexQuantity 解析 transactionRequest 中的十六进制数值，空值返回 nil
+func lifiHexQuantity(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	quantity, ok := new(big.Int).SetString(value, 0)
+	if !ok {
+		return nil, fmt.Errorf("LI.FI交易数值无效: %s", value)
+	}
+	return quantity, nil
+}
+
+// lifiQuoteKey 报价缓存键
+func lifiQuoteKey(params *BridgeParams) string {
+	amount := ""
+	if params.Amount != nil {
+		amount = params.Amount.String()
+	}
+	return strings.ToLower(strings.Join([]string{
+		params.FromChain, params.ToChain, params.TokenAddress, amount,
+		params.FromAddress, params.ToAddress, params.Priority,
+		strconv.FormatFloat(params.SlippageTolerance, 'f', -1, 64),
+	}, "|"))
+}
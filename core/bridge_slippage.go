@@ -0,0 +1,137 @@
+/*
+跨链桥接滑点与最低到账保护
+
+滑点容忍度以百分比表示（0.5 表示 0.5%），为 0 时使用 defaultBridgeSlippage，
+涵盖桥接费用与兑换价格影响。执行前：
+- 截止时间（Deadline，Unix 秒）已过则拒绝
+- 最低可接受到账 = 转出数量（按目标代币精度换算）×（1 - 滑点），报价的 AmountOutMin 低于该值则拒绝
+完成后将实际到账数量与 AmountOutMin 比较，低于最低值时在状态与历史记录中标记。
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	defaultBridgeSlippage = 1.0  // 默认滑点容忍度（%）
+	maxBridgeSlippage     = 50.0 // 滑点容忍度上限（%）
+	slippagePrecision     = 1_000_000
+)
+
+// BridgeSlippage 返回有效的滑点容忍度（%），未设置时使用默认值
+func BridgeSlippage(percent float64) (float64, error) {
+	if percent == 0 {
+		return defaultBridgeSlippage, nil
+	}
+	if percent < 0 || percent > maxBridgeSlippage || math.IsNaN(percent) {
+		return 0, fmt.Errorf("滑点容忍度必须在 0 到 %.0f%% 之间", maxBridgeSlippage)
+	}
+	return percent, nil
+}
+
+// BridgeMinimumOut 计算最低可接受到账数量：amountIn 从 fromDecimals 换算到 toDecimals 后扣除滑点（向下取整）
+func BridgeMinimumOut(amountIn *big.Int, fromDecimals, toDecimals int, slippagePercent float64) (*big.Int, error) {
+	if amountIn == nil || amountIn.Sign() <= 0 {
+		return nil, fmt.Errorf("转移数量必须大于0")
+	}
+	slippage, err := BridgeSlippage(slippagePercent)
+	if err != nil {
+		return nil, err
+	}
+
+	scaled := new(big.Int).Set(amountIn)
+	if toDecimals > fromDecimals {
+		scaled.Mul(scaled, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(toDecimals-fromDecimals)), nil))
+	} else if fromDecimals > toDecimals {
+		scaled.Quo(scaled, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(fromDecimals-toDecimals)), nil))
+	}
+
+	// 百分比换算为百万分比，避免浮点误差累积到大整数
+	keep := slippagePrecision - int64(math.Round(slippage*slippagePrecision/100))
+	scaled.Mul(scaled, big.NewInt(keep))
+	return scaled.Quo(scaled, big.NewInt(slippagePrecision)), nil
+}
+
+// destinationReceived 从目标链交易解析接收地址实际到账数量：
+// 优先累加转给接收地址的 ERC20 Transfer 事件，否则取直接转给接收地址的原生币数量；无法判断时返回 nil
+func destinationReceived(ctx context.Context, multiChain *MultiChainManager, chain, txHash, recipient string) (*big.Int, error) {
+	if txHash == "" || !common.IsHexAddress(recipient) {
+		return nil, nil
+	}
+	adapter, err := bridgeEVMAdapter(multiChain, chain)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := adapter.GetTransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	to := common.HexToAddress(recipient)
+	received := new(big.Int)
+	found := false
+	for _, lg := range receipt.Logs {
+		if len(lg.Topics) != 3 || lg.Topics[0] != transferEventTopic || common.BytesToAddress(lg.Topics[2].Bytes()) != to {
+			continue
+		}
+		received.Add(received, new(big.Int).SetBytes(lg.Data))
+		found = true
+	}
+	if found {
+		return received, nil
+	}
+
+	tx, _, err := adapter.GetTransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if tx.To() != nil && strings.EqualFold(tx.To().Hex(), to.Hex()) {
+		return new(big.Int).Set(tx.Value()), nil
+	}
+	return nil, nil
+}
+
+// checkBridgeDeadline 截止时间已过时返回错误
+func checkBridgeDeadline(params *BridgeParams, now time.Time) error {
+	if params.Deadline > 0 && now.Unix() > params.Deadline {
+		return fmt.Errorf("已超过桥接截止时间（%s）", time.Unix(params.Deadline, 0).Format(time.RFC3339))
+	}
+	return nil
+}
+
+// checkQuoteMinimum 校验报价的最低到账不低于滑点保护下限，返回该下限
+func checkQuoteMinimum(params *BridgeParams, quote *BridgeQuote) (*big.Int, error) {
+	minimum, err := BridgeMinimumOut(params.Amount, quote.FromDecimals, quote.ToDecimals, params.SlippageTolerance)
+	if err != nil {
+		return nil, err
+	}
+	if quote.AmountOutMin == nil || quote.AmountOutMin.Cmp(minimum) < 0 {
+		return nil, fmt.Errorf("报价最低到账 %s 低于滑点保护下限 %s（滑点容忍度 %.2f%%），请提高滑点容忍度或稍后重试",
+			amountString(quote.AmountOutMin), minimum.String(), effectiveSlippage(params.SlippageTolerance))
+	}
+	return minimum, nil
+}
+
+// effectiveSlippage 用于提示的有效滑点
+func effectiveSlippage(percent float64) float64 {
+	if slippage, err := BridgeSlippage(percent); err == nil {
+		return slippage
+	}
+	return percent
+}
+
+// amountString nil 安全的数量字符串
+func amountString(amount *big.Int) string {
+	if amount == nil {
+		return "0"
+	}
+	return amount.String()
+}
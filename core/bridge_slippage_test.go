@@ -0,0 +1,102 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+)
+
+func bigString(t *testing.T, s string) *big.Int {
+	t.Helper()
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		t.Fatalf("无效的整数 %q", s)
+	}
+	return n
+}
+
+func TestBridgeMinimumOut(t *testing.T) {
+	cases := []struct {
+		name         string
+		amountIn     string
+		fromDecimals int
+		toDecimals   int
+		slippage     float64
+		want         string
+	}{
+		{"6位精度 0.5%", "1000000000", 6, 6, 0.5, "995000000"},
+		{"8位精度 默认滑点", "100000000", 8, 8, 0, "99000000"},
+		{"18位精度 0.3%", "1000000000000000000", 18, 18, 0.3, "997000000000000000"},
+		{"18位精度 0.29% 无浮点误差", "1000000000000000000", 18, 18, 0.29, "997100000000000000"},
+		{"18位换算到6位", "1500000000000000000", 18, 6, 1, "1485000"},
+		{"6位换算到18位", "2000000", 6, 18, 2.5, "1950000000000000000"},
+		{"8位换算到6位 截断后向下取整", "123456789", 8, 6, 1, "1222221"},
+		{"6位精度 非整数结果向下取整", "1000001", 6, 6, 0.5, "995000"},
+		{"最小单位扣除滑点后为0", "1", 18, 18, 1, "0"},
+		{"滑点上限", "1000000", 6, 6, 50, "500000"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := BridgeMinimumOut(bigString(t, tc.amountIn), tc.fromDecimals, tc.toDecimals, tc.slippage)
+			if err != nil {
+				t.Fatalf("BridgeMinimumOut: %v", err)
+			}
+			if got.String() != tc.want {
+				t.Fatalf("最低到账 = %s，期望 %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBridgeMinimumOutRejectsInvalidInput(t *testing.T) {
+	cases := []struct {
+		name     string
+		amountIn *big.Int
+		slippage float64
+	}{
+		{"数量为空", nil, 1},
+		{"数量为0", big.NewInt(0), 1},
+		{"数量为负", big.NewInt(-1), 1},
+		{"滑点为负", big.NewInt(1000), -0.1},
+		{"滑点超过上限", big.NewInt(1000), 50.01},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := BridgeMinimumOut(tc.amountIn, 6, 6, tc.slippage); err == nil {
+				t.Fatal("应返回错误")
+			}
+		})
+	}
+}
+
+// 1000001 × (1 - 0.5%) = 995000.995，下限向下取整为 995000：恰好等于下限的报价通过，少 1 个最小单位即拒绝
+func TestCheckQuoteMinimumBoundary(t *testing.T) {
+	params := &BridgeParams{Amount: big.NewInt(1_000_001), SlippageTolerance: 0.5}
+	cases := []struct {
+		name         string
+		amountOutMin *big.Int
+		wantErr      bool
+	}{
+		{"等于下限", big.NewInt(995_000), false},
+		{"高于下限", big.NewInt(995_001), false},
+		{"低于下限1个单位", big.NewInt(994_999), true},
+		{"未提供最低到账", nil, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			quote := &BridgeQuote{AmountOutMin: tc.amountOutMin, FromDecimals: 6, ToDecimals: 6}
+			minimum, err := checkQuoteMinimum(params, quote)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("应拒绝低于滑点保护下限的报价")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkQuoteMinimum: %v", err)
+			}
+			if minimum.Cmp(big.NewInt(995_000)) != 0 {
+				t.Fatalf("下限 = %s，期望 995000", minimum)
+			}
+		})
+	}
+}
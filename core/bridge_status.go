@@ -0,0 +1,366 @@
+/*
+跨链桥接状态轮询
+
+BridgeManager 后台按 bridgePollInterval 轮询所有活跃桥接：
+- 调用提供商 GetTransactionStatus 获取源链确认数与目标链到账情况，更新 ConfirmBlocks / Progress / CurrentStep
+- 进入结束状态（completed / claimable / failed / expired）的桥接从 activeBridges 移入 historyBridges
+- 发起时设置了 CallbackURL 的桥接结束后 POST 一次 Webhook（失败重试）；只连接公网地址（见 NewSafeTransport），不跟随重定向
+- 完成时比较实际到账与报价最低到账，低于最低值时标记 BelowMinimum
+
+进度约定（共三步）：
+1. 源链交易确认中：0 ~ 0.5，按确认数线性增长
+2. 桥接协议处理中：0.5（Polygon 提款检查点已包含时为 0.9，状态 claimable，需用户在以太坊提交退出交易）
+3. 目标链到账：1.0
+源链交易超过 bridgeDropTimeout 仍未上链视为失败；源链已确认但超过预估完成时间 bridgeExpireGrace 仍无法确认到账时标记为 expired。
+*/
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 桥接状态
+const (
+	BridgeStatusPending    = "pending"    // 源链交易待打包
+	BridgeStatusConfirming = "confirming" // 源链交易确认中
+	BridgeStatusProcessing = "processing" // 桥接协议处理中
+	BridgeStatusClaimable  = "claimable"  // 已可在目标链领取（需用户提交领取/退出交易）
+	BridgeStatusCompleted  = "completed"  // 目标链已到账
+	BridgeStatusFailed     = "failed"     // 失败
+	BridgeStatusExpired    = "expired"    // 超时仍无法确认到账
+)
+
+const (
+	bridgeTotalSteps       = 3
+	bridgePollInterval     = 15 * time.Second
+	bridgePollTimeout      = 10 * time.Second // 单个桥接的查询超时
+	bridgeDropTimeout      = time.Hour        // 源链交易未上链的最长等待
+	bridgeExpireGrace      = 24 * time.Hour   // 超过预估完成时间后的最长等待
+	bridgeWebhookTimeout   = 10 * time.Second
+	bridgeWebhookRetries   = 3
+	bridgeWebhookRetryWait = 5 * time.Second
+
+	polygonBridgeConfirmBlocks = 12
+	multichainConfirmBlocks    = 6
+)
+
+// Polygon PoS 桥主网合约
+var (
+	polygonStateSender   = common.HexToAddress("0x28e4F3a7f651294B9564800b2D01f35189A5bFbE") // 以太坊 StateSender，存款时发出 StateSynced
+	polygonStateReceiver = common.HexToAddress("0x0000000000000000000000000000000000001001") // Polygon StateReceiver 系统合约
+	polygonRootChain     = common.HexToAddress("0x86E4Dc95c7FBdBf52e33D563BbDB00823894C287") // 以太坊 RootChain，记录检查点
+
+	stateSyncedTopic       = crypto.Keccak256Hash([]byte("StateSynced(uint256,address,bytes)"))
+	lastStateIDSelector    = crypto.Keccak256([]byte("lastStateId()"))[:4]
+	lastChildBlockSelector = crypto.Keccak256([]byte("getLastChildBlock()"))[:4]
+)
+
+// BridgeWebhookPayload 桥接结束时回调的内容
+type BridgeWebhookPayload struct {
+	Event  string        `json:"event"`  // bridge.completed / bridge.claimable / bridge.failed / bridge.expired
+	Bridge *BridgeStatus `json:"bridge"` // 最终状态
+}
+
+// isBridgeFinished 是否为结束状态
+func isBridgeFinished(status string) bool {
+	switch status {
+	case BridgeStatusCompleted, BridgeStatusClaimable, BridgeStatusFailed, BridgeStatusExpired:
+		return true
+	}
+	return false
+}
+
+// providerByName 按提供商名称（报价中的 Provider）查找提供商及其注册ID
+func (bm *BridgeManager) providerByName(name string) (string, BridgeProvider) {
+	if provider, ok := bm.bridgeProviders[name]; ok {
+		return name, provider
+	}
+	for id, provider := range bm.bridgeProviders {
+		if provider.GetName() == name {
+			return id, provider
+		}
+	}
+	return "", nil
+}
+
+// pollLoop 后台轮询活跃桥接
+func (bm *BridgeManager) pollLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		bm.pollActiveBridges()
+	}
+}
+
+// pollActiveBridges 查询所有活跃桥接的最新状态，查询期间不持锁
+func (bm *BridgeManager) pollActiveBridges() {
+	for _, status := range bm.statusTracker.activeSnapshot() {
+		provider, ok := bm.bridgeProviders[status.Provider]
+		if !ok {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), bridgePollTimeout)
+		update, err := provider.GetTransactionStatus(ctx, status.FromChain, status.ToChain, status.FromTxHash)
+		if err == nil && update.Status == BridgeStatusCompleted && update.AmountReceived == nil {
+			// 提供商未返回到账数量时从目标链交易解析
+			toTxHash := update.ToTxHash
+			if toTxHash == "" {
+				toTxHash = status.ToTxHash
+			}
+			received, recvErr := destinationReceived(ctx, bm.multiChain, status.ToChain, toTxHash, status.ToAddress)
+			if recvErr != nil {
+				slog.DebugContext(ctx, "bridge received amount lookup failed", "bridge_id", status.BridgeID, "error", recvErr)
+			}
+			update.AmountReceived = received
+		}
+		cancel()
+		if err != nil {
+			slog.DebugContext(ctx, "bridge status poll failed", "bridge_id", status.BridgeID, "error", err)
+			continue
+		}
+		bm.statusTracker.applyUpdate(status.BridgeID, update, time.Now())
+	}
+}
+
+// OnBridgeFinished 注册桥接结束回调（用于同步历史记录）
+func (bm *BridgeManager) OnBridgeFinished(fn func(status *BridgeStatus)) {
+	bm.statusTracker.mu.Lock()
+	defer bm.statusTracker.mu.Unlock()
+	bm.statusTracker.onFinished = fn
+}
+
+// activeSnapshot 活跃桥接的副本
+func (bst *BridgeStatusTracker) activeSnapshot() []BridgeStatus {
+	bst.mu.RLock()
+	defer bst.mu.RUnlock()
+	out := make([]BridgeStatus, 0, len(bst.activeBridges))
+	for _, status := range bst.activeBridges {
+		out = append(out, *status)
+	}
+	return out
+}
+
+// applyUpdate 合并提供商返回的状态，处理超时，结束时移入历史并触发回调
+func (bst *BridgeStatusTracker) applyUpdate(bridgeID string, update *BridgeStatus, now time.Time) {
+	bst.mu.Lock()
+	status, ok := bst.activeBridges[bridgeID]
+	if !ok {
+		bst.mu.Unlock()
+		return
+	}
+
+	status.Status = update.Status
+	status.Progress = update.Progress
+	status.CurrentStep = update.CurrentStep
+	status.ConfirmBlocks = update.ConfirmBlocks
+	status.ErrorMessage = update.ErrorMessage
+	if update.RequiredBlocks > 0 {
+		status.RequiredBlocks = update.RequiredBlocks
+	}
+	if update.ToTxHash != "" {
+		status.ToTxHash = update.ToTxHash
+	}
+	if update.AmountReceived != nil {
+		status.AmountReceived = update.AmountReceived
+	}
+	status.UpdatedAt = now
+
+	switch {
+	case status.Status == BridgeStatusPending && now.Sub(status.CreatedAt) > bridgeDropTimeout:
+		status.Status = BridgeStatusFailed
+		status.ErrorMessage = "源链交易长时间未上链"
+	case !isBridgeFinished(status.Status) && now.After(status.EstimatedCompletion.Add(bridgeExpireGrace)):
+		status.Status = BridgeStatusExpired
+		status.ErrorMessage = "超过预计完成时间仍无法确认目标链到账，请在目标链浏览器核实"
+	}
+
+	if !isBridgeFinished(status.Status) {
+		bst.mu.Unlock()
+		return
+	}
+	if status.Status == BridgeStatusCompleted && status.AmountReceived != nil && status.AmountOutMin != nil &&
+		status.AmountReceived.Cmp(status.AmountOutMin) < 0 {
+		status.BelowMinimum = true
+		status.ErrorMessage = fmt.Sprintf("实际到账 %s 低于报价最低到账 %s", status.AmountReceived, status.AmountOutMin)
+	}
+	delete(bst.activeBridges, bridgeID)
+	bst.historyBridges[bridgeID] = status
+	final := *status
+	onFinished := bst.onFinished
+	bst.mu.Unlock()
+
+	if onFinished != nil {
+		onFinished(&final)
+	}
+
+	if final.callbackURL != "" {
+		go bst.sendWebhook(final.callbackURL, &BridgeWebhookPayload{Event: "bridge." + final.Status, Bridge: &final})
+	}
+}
+
+// sendWebhook POST 桥接结束事件，非 2xx 或请求失败时重试
+func (bst *BridgeStatusTracker) sendWebhook(callbackURL string, payload *BridgeWebhookPayload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	for attempt := 1; attempt <= bridgeWebhookRetries; attempt++ {
+		err = bst.postWebhook(callbackURL, body)
+		if err == nil || errors.Is(err, ErrUnsafeOutboundAddress) {
+			return
+		}
+		slog.DebugContext(context.Background(), "bridge webhook delivery failed", "bridge_id", payload.Bridge.BridgeID, "attempt", attempt, "error", err)
+		time.Sleep(time.Duration(attempt) * bridgeWebhookRetryWait)
+	}
+}
+
+// postWebhook 发送一次回调请求
+func (bst *BridgeStatusTracker) postWebhook(callbackURL string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := bst.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// bridgeEVMAdapter 获取桥接所在链的EVM适配器
+func bridgeEVMAdapter(multiChain *MultiChainManager, chain string) (*EVMAdapter, error) {
+	if multiChain == nil {
+		return nil, fmt.Errorf("未初始化多链管理器")
+	}
+	adapter, err := multiChain.GetAdapter(chain)
+	if err != nil {
+		return nil, err
+	}
+	evmAdapter, ok := adapter.(*EVMAdapter)
+	if !ok {
+		return nil, fmt.Errorf("网络 %s 不是EVM网络", chain)
+	}
+	return evmAdapter, nil
+}
+
+// sourceChainStatus 查询源链交易回执与确认数
+// 未上链时返回 pending（receipt 为 nil）；执行失败返回 failed；确认数不足返回 confirming；已确认返回 processing
+func sourceChainStatus(ctx context.Context, multiChain *MultiChainManager, chain, txHash string, requiredBlocks int) (*types.Receipt, *BridgeStatus, error) {
+	status := &BridgeStatus{
+		Status:         BridgeStatusPending,
+		CurrentStep:    1,
+		TotalSteps:     bridgeTotalSteps,
+		FromTxHash:     txHash,
+		RequiredBlocks: requiredBlocks,
+		UpdatedAt:      time.Now(),
+	}
+	adapter, err := bridgeEVMAdapter(multiChain, chain)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	receipt, err := adapter.GetTransactionReceipt(ctx, txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, status, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		status.Status = BridgeStatusFailed
+		status.ErrorMessage = "源链交易执行失败"
+		return receipt, status, nil
+	}
+
+	latest, err := adapter.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取最新区块失败: %w", err)
+	}
+	confirmations := 0
+	if included := receipt.BlockNumber.Uint64(); latest >= included {
+		confirmations = int(latest-included) + 1
+	}
+	status.ConfirmBlocks = confirmations
+
+	if confirmations < requiredBlocks {
+		status.Status = BridgeStatusConfirming
+		status.Progress = 0.5 * float64(confirmations) / float64(requiredBlocks)
+		return receipt, status, nil
+	}
+	status.Status = BridgeStatusProcessing
+	status.CurrentStep = 2
+	status.Progress = 0.5
+	return receipt, status, nil
+}
+
+// checkDeposit 以太坊 → Polygon：StateReceiver 已处理存款对应的 StateSynced 事件即到账
+func (p *PolygonBridge) checkDeposit(ctx context.Context, receipt *types.Receipt, status *BridgeStatus) error {
+	var stateID *big.Int
+	for _, lg := range receipt.Logs {
+		if lg.Address == polygonStateSender && len(lg.Topics) > 1 && lg.Topics[0] == stateSyncedTopic {
+			stateID = lg.Topics[1].Big()
+			break
+		}
+	}
+	if stateID == nil {
+		// 交易未经过 StateSender（非 PoS 桥存款），无法核实到账
+		return nil
+	}
+
+	adapter, err := bridgeEVMAdapter(p.multiChain, "polygon")
+	if err != nil {
+		return err
+	}
+	out, err := adapter.CallContract(ctx, ethereum.CallMsg{To: &polygonStateReceiver, Data: lastStateIDSelector}, nil)
+	if err != nil {
+		return fmt.Errorf("查询Polygon状态同步进度失败: %w", err)
+	}
+	if len(out) < 32 {
+		return fmt.Errorf("Polygon状态同步进度返回数据异常")
+	}
+	if new(big.Int).SetBytes(out[:32]).Cmp(stateID) >= 0 {
+		status.Status = BridgeStatusCompleted
+		status.CurrentStep = 3
+		status.Progress = 1.0
+	}
+	return nil
+}
+
+// checkCheckpoint Polygon → 以太坊：燃烧交易所在区块已被检查点包含后可在以太坊提交退出
+func (p *PolygonBridge) checkCheckpoint(ctx context.Context, receipt *types.Receipt, status *BridgeStatus) error {
+	adapter, err := bridgeEVMAdapter(p.multiChain, "ethereum")
+	if err != nil {
+		return err
+	}
+	out, err := adapter.CallContract(ctx, ethereum.CallMsg{To: &polygonRootChain, Data: lastChildBlockSelector}, nil)
+	if err != nil {
+		return fmt.Errorf("查询Polygon检查点失败: %w", err)
+	}
+	if len(out) < 32 {
+		return fmt.Errorf("Polygon检查点返回数据异常")
+	}
+	if new(big.Int).SetBytes(out[:32]).Cmp(receipt.BlockNumber) >= 0 {
+		status.Status = BridgeStatusClaimable
+		status.CurrentStep = 3
+		status.Progress = 0.9
+	}
+	return nil
+}
@@ -0,0 +1,395 @@
+/*
+交易 calldata 解码（"我在签什么"确认页）
+
+DApp 发起的 eth_sendTransaction 只有一段十六进制 data，用户无从判断实际调用。DecodeCalldata 按前4字节选择器识别方法并解码参数：
+  - 调用方提供 ABI 时按该 ABI 解码
+  - 否则使用内置的常见方法 ABI（ERC20、ERC721/ERC1155、WETH）
+  - 仍无法识别时，SignatureDirectory.Decode 到 4byte.directory 签名库查询选择器（结果缓存），
+    对每个候选签名尝试解码，并要求解码后重新编码与原数据完全一致，排除选择器碰撞的错误签名；
+    多个签名都能解码时取登记最早的一个，其余列入 candidates
+
+识别出的高风险调用会在 warnings 中标出：无限额度的 approve / increaseAllowance、setApprovalForAll(operator, true)。
+*/
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// 解码所用方法定义的来源
+const (
+	CalldataSourceABI         = "abi"          // 调用方提供的 ABI
+	CalldataSourceBuiltin     = "builtin"      // 内置常见方法
+	CalldataSourceSignatureDB = "signature_db" // 4byte.directory 签名库
+)
+
+// 风险提示等级
+const (
+	CalldataRiskHigh   = "high"
+	CalldataRiskMedium = "medium"
+)
+
+const (
+	signatureLookupTimeout  = 10 * time.Second
+	signatureMaxResponse    = 1 << 20 // 签名库响应大小上限
+	signatureMaxCandidates  = 20      // 每个选择器最多尝试的候选签名数
+	infiniteApprovalMinimum = 255     // 授权额度不低于 2^255 视为无限授权
+)
+
+// wellKnownMethodABIs 内置的常见方法ABI
+var wellKnownMethodABIs = []string{
+	// ERC20
+	`[
+		{"inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"name":"transfer","outputs":[{"type":"bool"}],"stateMutability":"nonpayable","type":"function"},
+		{"inputs":[{"name":"spender","type":"address"},{"name":"amount","type":"uint256"}],"name":"approve","outputs":[{"type":"bool"}],"stateMutability":"nonpayable","type":"function"},
+		{"inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"name":"transferFrom","outputs":[{"type":"bool"}],"stateMutability":"nonpayable","type":"function"},
+		{"inputs":[{"name":"spender","type":"address"},{"name":"addedValue","type":"uint256"}],"name":"increaseAllowance","outputs":[{"type":"bool"}],"stateMutability":"nonpayable","type":"function"},
+		{"inputs":[{"name":"spender","type":"address"},{"name":"subtractedValue","type":"uint256"}],"name":"decreaseAllowance","outputs":[{"type":"bool"}],"stateMutability":"nonpayable","type":"function"}
+	]`,
+	// ERC721 / ERC1155
+	`[
+		{"inputs":[{"name":"operator","type":"address"},{"name":"approved","type":"bool"}],"name":"setApprovalForAll","outputs":[],"stateMutability":"nonpayable","type":"function"},
+		{"inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"}],"name":"safeTransferFrom","outputs":[],"stateMutability":"nonpayable","type":"function"},
+		{"inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"},{"name":"data","type":"bytes"}],"name":"safeTransferFrom","outputs":[],"stateMutability":"nonpayable","type":"function"},
+		{"inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"id","type":"uint256"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"}],"name":"safeTransferFrom","outputs":[],"stateMutability":"nonpayable","type":"function"},
+		{"inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"ids","type":"uint256[]"},{"name":"values","type":"uint256[]"},{"name":"data","type":"bytes"}],"name":"safeBatchTransferFrom","outputs":[],"stateMutability":"nonpayable","type":"function"}
+	]`,
+	// WETH
+	`[
+		{"inputs":[],"name":"deposit","outputs":[],"stateMutability":"payable","type":"function"},
+		{"inputs":[{"name":"wad","type":"uint256"}],"name":"withdraw","outputs":[],"stateMutability":"nonpayable","type":"function"}
+	]`,
+}
+
+// DecodedArg 解码后的参数
+type DecodedArg struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"` // 大整数为十进制字符串，地址与字节为0x十六进制
+}
+
+// CalldataWarning 高风险调用提示
+type CalldataWarning struct {
+	Level   string `json:"level"` // high / medium
+	Code    string `json:"code"`  // infinite_approval / approval_for_all / unknown_method
+	Message string `json:"message"`
+}
+
+// DecodedCall calldata 解码结果
+type DecodedCall struct {
+	Selector   string            `json:"selector"`
+	Decoded    bool              `json:"decoded"`
+	Method     string            `json:"method,omitempty"`
+	Signature  string            `json:"signature,omitempty"`
+	Source     string            `json:"source,omitempty"` // abi / builtin / signature_db
+	Args       []DecodedArg      `json:"args"`
+	Candidates []string          `json:"candidates,omitempty"` // 签名库中同样能解码的其他签名
+	Warnings   []CalldataWarning `json:"warnings"`
+}
+
+// DecodeCalldata 按选择器识别方法并解码参数：abiJSON 非空时只使用该ABI，否则使用内置常见方法
+// 无法识别时返回 Decoded=false 的结果；calldata 不足4字节或ABI无效时返回错误
+func DecodeCalldata(data []byte, abiJSON string) (*DecodedCall, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("calldata 至少需要包含4字节方法选择器")
+	}
+	result := &DecodedCall{Selector: hexutil.Encode(data[:4]), Args: []DecodedArg{}, Warnings: []CalldataWarning{}}
+
+	sources := make([]string, 0, len(wellKnownMethodABIs))
+	source := CalldataSourceBuiltin
+	if strings.TrimSpace(abiJSON) != "" {
+		sources = append(sources, abiJSON)
+		source = CalldataSourceABI
+	} else {
+		sources = append(sources, wellKnownMethodABIs...)
+	}
+	for _, raw := range sources {
+		parsed, err := parseABICached(raw)
+		if err != nil {
+			return nil, fmt.Errorf("解析ABI失败: %w", err)
+		}
+		method, err := parsed.MethodById(data[:4])
+		if err != nil {
+			continue
+		}
+		args, err := decodeMethodArgs(method, data[4:])
+		if err != nil {
+			if source == CalldataSourceABI {
+				return nil, fmt.Errorf("按ABI解码 %s 参数失败: %w", method.Sig, err)
+			}
+			continue
+		}
+		result.setMethod(method, args, source)
+		return result, nil
+	}
+	result.Warnings = append(result.Warnings, unknownMethodWarning())
+	return result, nil
+}
+
+// setMethod 记录识别出的方法并检查高风险调用
+func (r *DecodedCall) setMethod(method *abi.Method, args []DecodedArg, source string) {
+	r.Decoded = true
+	r.Method = method.RawName
+	r.Signature = method.Sig
+	r.Source = source
+	r.Args = args
+	r.Warnings = calldataWarnings(method.Sig, args)
+}
+
+// decodeMethodArgs 解码方法参数，要求重新编码后与原数据一致（排除选择器碰撞或多余数据）
+func decodeMethodArgs(method *abi.Method, payload []byte) ([]DecodedArg, error) {
+	values, err := method.Inputs.Unpack(payload)
+	if err != nil {
+		return nil, err
+	}
+	packed, err := method.Inputs.Pack(values...)
+	if err != nil || !bytes.Equal(packed, payload) {
+		return nil, fmt.Errorf("参数编码与 %s 不一致", method.Sig)
+	}
+	args := make([]DecodedArg, len(values))
+	for i, value := range values {
+		name := method.Inputs[i].Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		args[i] = DecodedArg{Name: name, Type: method.Inputs[i].Type.String(), Value: formatABIValue(reflect.ValueOf(value))}
+	}
+	return args, nil
+}
+
+// calldataWarnings 识别无限授权与全部NFT授权
+func calldataWarnings(signature string, args []DecodedArg) []CalldataWarning {
+	warnings := []CalldataWarning{}
+	switch signature {
+	case "approve(address,uint256)", "increaseAllowance(address,uint256)":
+		amount, ok := new(big.Int).SetString(fmt.Sprint(args[1].Value), 10)
+		if ok && IsInfiniteAllowance(amount) {
+			warnings = append(warnings, CalldataWarning{
+				Level:   CalldataRiskHigh,
+				Code:    "infinite_approval",
+				Message: fmt.Sprintf("无限额度授权：%s 可随时转走该代币的全部余额，请确认对方可信或改为按需授权", args[0].Value),
+			})
+		}
+	case "setApprovalForAll(address,bool)":
+		if approved, _ := args[1].Value.(bool); approved {
+			warnings = append(warnings, CalldataWarning{
+				Level:   CalldataRiskHigh,
+				Code:    "approval_for_all",
+				Message: fmt.Sprintf("授权 %s 转移你在该合约下的全部NFT，常见于钓鱼网站，请确认对方可信", args[0].Value),
+			})
+		}
+	}
+	return warnings
+}
+
+// unknownMethodWarning 无法识别方法时的提示
+func unknownMethodWarning() CalldataWarning {
+	return CalldataWarning{
+		Level:   CalldataRiskMedium,
+		Code:    "unknown_method",
+		Message: "无法识别调用的方法，请确认DApp来源可信后再签名",
+	}
+}
+
+// signatureCacheEntry 签名库查询缓存
+type signatureCacheEntry struct {
+	signatures []string
+	expiresAt  time.Time
+}
+
+// SignatureDirectory 4byte.directory 兼容的函数签名库客户端
+type SignatureDirectory struct {
+	baseURL    string
+	cacheTTL   time.Duration
+	httpClient *http.Client
+	cache      map[string]*signatureCacheEntry // key: 小写选择器
+	mu         sync.Mutex
+}
+
+// NewSignatureDirectory 创建签名库客户端，查询结果（包括未找到）缓存 cacheTTL
+func NewSignatureDirectory(baseURL string, cacheTTL time.Duration) *SignatureDirectory {
+	return &SignatureDirectory{
+		baseURL:    baseURL,
+		cacheTTL:   cacheTTL,
+		httpClient: &http.Client{Timeout: signatureLookupTimeout},
+		cache:      make(map[string]*signatureCacheEntry),
+	}
+}
+
+// Decode 解码 calldata；未提供ABI且内置方法无法识别时查询签名库
+// 签名库查询失败不视为错误，结果中保留 unknown_method 提示
+func (d *SignatureDirectory) Decode(ctx context.Context, data []byte, abiJSON string) (*DecodedCall, error) {
+	result, err := DecodeCalldata(data, abiJSON)
+	if err != nil || result.Decoded || strings.TrimSpace(abiJSON) != "" {
+		return result, err
+	}
+	signatures, err := d.Lookup(ctx, result.Selector)
+	if err != nil {
+		result.Warnings[0].Message += "（签名库查询失败: " + err.Error() + "）"
+		return result, nil
+	}
+	for _, signature := range signatures {
+		method, err := methodFromSignature(signature)
+		if err != nil {
+			continue
+		}
+		args, err := decodeMethodArgs(method, data[4:])
+		if err != nil {
+			continue
+		}
+		if !result.Decoded {
+			result.setMethod(method, args, CalldataSourceSignatureDB)
+		} else {
+			result.Candidates = append(result.Candidates, method.Sig)
+		}
+	}
+	return result, nil
+}
+
+// Lookup 查询选择器对应的函数签名，按登记时间从早到晚排序
+func (d *SignatureDirectory) Lookup(ctx context.Context, selector string) ([]string, error) {
+	key := strings.ToLower(selector)
+	d.mu.Lock()
+	if entry, ok := d.cache[key]; ok && time.Now().Before(entry.expiresAt) {
+		d.mu.Unlock()
+		return entry.signatures, nil
+	}
+	d.mu.Unlock()
+
+	signatures, err := d.fetch(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+	for k, entry := range d.cache {
+		if now.After(entry.expiresAt) {
+			delete(d.cache, k)
+		}
+	}
+	d.cache[key] = &signatureCacheEntry{signatures: signatures, expiresAt: now.Add(d.cacheTTL)}
+	return signatures, nil
+}
+
+// fetch 请求签名库：GET {baseURL}?hex_signature=0x12345678
+func (d *SignatureDirectory) fetch(ctx context.Context, selector string) ([]string, error) {
+	endpoint := d.baseURL + "?hex_signature=" + url.QueryEscape(selector)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("签名库返回状态码 %d", resp.StatusCode)
+	}
+	var body struct {
+		Results []struct {
+			ID            int64  `json:"id"`
+			TextSignature string `json:"text_signature"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, signatureMaxResponse)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("解析签名库响应失败: %w", err)
+	}
+	sort.Slice(body.Results, func(i, j int) bool { return body.Results[i].ID < body.Results[j].ID })
+	signatures := make([]string, 0, len(body.Results))
+	for _, item := range body.Results {
+		if len(signatures) == signatureMaxCandidates {
+			break
+		}
+		signatures = append(signatures, item.TextSignature)
+	}
+	return signatures, nil
+}
+
+// methodFromSignature 由文本签名构造方法定义（参数名为 arg0、arg1…），支持元组类型
+func methodFromSignature(signature string) (*abi.Method, error) {
+	sig, err := NormalizeSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	open := strings.Index(sig, "(")
+	name, params := sig[:open], sig[open+1:len(sig)-1]
+	var inputs abi.Arguments
+	if params != "" {
+		types, err := splitTopLevel(params)
+		if err != nil {
+			return nil, err
+		}
+		for i, t := range types {
+			marshaling, err := abiTypeMarshaling(t)
+			if err != nil {
+				return nil, err
+			}
+			typ, err := abi.NewType(marshaling.Type, "", marshaling.Components)
+			if err != nil {
+				return nil, err
+			}
+			inputs = append(inputs, abi.Argument{Name: fmt.Sprintf("arg%d", i), Type: typ})
+		}
+	}
+	method := abi.NewMethod(name, name, abi.Function, "nonpayable", false, false, inputs, nil)
+	return &method, nil
+}
+
+// abiTypeMarshaling 将规范类型转换为 abi.NewType 的参数，元组展开为 tuple 及其组件
+func abiTypeMarshaling(t string) (abi.ArgumentMarshaling, error) {
+	if !strings.HasPrefix(t, "(") {
+		return abi.ArgumentMarshaling{Type: t}, nil
+	}
+	closeIdx := matchingParen(t)
+	if closeIdx < 0 {
+		return abi.ArgumentMarshaling{}, fmt.Errorf("元组括号不匹配: %s", t)
+	}
+	marshaling := abi.ArgumentMarshaling{Type: "tuple" + t[closeIdx+1:]}
+	inner := t[1:closeIdx]
+	if inner == "" {
+		return marshaling, nil
+	}
+	parts, err := splitTopLevel(inner)
+	if err != nil {
+		return abi.ArgumentMarshaling{}, err
+	}
+	for i, part := range parts {
+		component, err := abiTypeMarshaling(part)
+		if err != nil {
+			return abi.ArgumentMarshaling{}, err
+		}
+		component.Name = fmt.Sprintf("field%d", i)
+		marshaling.Components = append(marshaling.Components, component)
+	}
+	return marshaling, nil
+}
+
+// ParseCalldata 解析0x十六进制 calldata
+func ParseCalldata(data string) ([]byte, error) {
+	data = strings.TrimSpace(data)
+	if !strings.HasPrefix(data, "0x") && !strings.HasPrefix(data, "0X") {
+		data = "0x" + data
+	}
+	raw, err := hexutil.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("calldata 不是有效的十六进制: %w", err)
+	}
+	return raw, nil
+}
@@ -0,0 +1,112 @@
+/*
+链ID校验
+
+RPC 节点配置错误（如把测试网地址填到主网配置下）时，交易会按节点返回的链ID签名并广播到错误的链上：
+- 创建适配器与切换网络时通过 NetworkID 检测节点实际链ID，与网络配置声明的链ID不一致时拒绝使用
+- 每次签名发送前再次检测，防止节点在运行期间被替换导致签出其他链的交易
+- 广播外部已签名交易时校验交易自带的链ID（未启用重放保护的 legacy 交易无链ID，不做校验）
+*/
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrChainIDMismatch 节点链ID与网络配置声明的链ID不一致
+var ErrChainIDMismatch = errors.New("节点链ID与所选网络不一致")
+
+// chainIDCheckTimeout 创建适配器与切换网络时检测链ID的超时时间
+const chainIDCheckTimeout = 10 * time.Second
+
+// NewEVMAdapterForChain 创建EVM适配器（可带备用节点）并校验节点链ID与 expectedChainID 一致
+// expectedChainID <= 0 时不做校验
+func NewEVMAdapterForChain(rpcURL string, expectedChainID int64, fallbackURLs ...string) (*EVMAdapter, error) {
+	return newEVMAdapterForChain(rpcURL, expectedChainID, fallbackURLs, nil)
+}
+
+// newEVMAdapterForChain 同 NewEVMAdapterForChain，dial 不为空时节点连接经其建立（见 newEVMAdapter）
+func newEVMAdapterForChain(rpcURL string, expectedChainID int64, fallbackURLs []string, dial DialContextFunc) (*EVMAdapter, error) {
+	adapter, err := newEVMAdapter(rpcURL, fallbackURLs, dial)
+	if err != nil {
+		return nil, err
+	}
+	if expectedChainID <= 0 {
+		return adapter, nil
+	}
+	adapter.SetExpectedChainID(expectedChainID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), chainIDCheckTimeout)
+	defer cancel()
+	if _, err := adapter.VerifyChainID(ctx); err != nil {
+		adapter.Close()
+		return nil, err
+	}
+	return adapter, nil
+}
+
+// SetExpectedChainID 设置网络配置声明的链ID，<= 0 表示不校验
+func (a *EVMAdapter) SetExpectedChainID(chainID int64) {
+	if chainID <= 0 {
+		a.expectedChainID = nil
+		return
+	}
+	a.expectedChainID = big.NewInt(chainID)
+}
+
+// ExpectedChainID 返回网络配置声明的链ID，未设置时返回 0
+func (a *EVMAdapter) ExpectedChainID() int64 {
+	if a.expectedChainID == nil {
+		return 0
+	}
+	return a.expectedChainID.Int64()
+}
+
+// DetectChainID 通过 NetworkID 检测节点实际服务的链ID
+func (a *EVMAdapter) DetectChainID(ctx context.Context) (*big.Int, error) {
+	chainID, err := a.client.NetworkID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链ID失败: %w", err)
+	}
+	return chainID, nil
+}
+
+// VerifyChainID 检测节点链ID并与声明的链ID比对，返回节点链ID
+// 不一致时返回 ErrChainIDMismatch；未设置声明链ID时只做检测
+func (a *EVMAdapter) VerifyChainID(ctx context.Context) (*big.Int, error) {
+	chainID, err := a.DetectChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.checkChainID(chainID); err != nil {
+		return nil, err
+	}
+	return chainID, nil
+}
+
+// checkChainID 比对链ID与声明的链ID
+func (a *EVMAdapter) checkChainID(chainID *big.Int) error {
+	if a.expectedChainID == nil || chainID == nil {
+		return nil
+	}
+	if chainID.Cmp(a.expectedChainID) != 0 {
+		return fmt.Errorf("%w: 网络配置为 %s，节点实际为 %s", ErrChainIDMismatch, a.expectedChainID, chainID)
+	}
+	return nil
+}
+
+// checkRawTxChainID 校验已签名交易的链ID与声明的链ID一致（无重放保护的交易跳过）
+func (a *EVMAdapter) checkRawTxChainID(tx *types.Transaction) error {
+	if !tx.Protected() {
+		return nil
+	}
+	if err := a.checkChainID(tx.ChainId()); err != nil {
+		return fmt.Errorf("交易签名的链ID与所选网络不一致: %w", err)
+	}
+	return nil
+}
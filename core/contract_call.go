@@ -0,0 +1,290 @@
+/*
+基于ABI的通用合约调用
+
+调用方提供合约ABI、方法名与JSON形式的参数，无需自行计算选择器与编码：
+- CallMethod 只读调用（eth_call），按ABI解码返回值，结果转换为便于JSON展示的值
+- SendMethod/SendPayableMethod 写入调用，打包调用数据后由签名者签名发送
+
+JSON参数按ABI类型转换：
+- address：0x 地址字符串
+- intN/uintN：十进制或 0x 十六进制字符串、JSON数字
+- bool：true/false 或其字符串形式
+- bytes/bytesN：0x 十六进制字符串
+- 数组：JSON数组；元组：按字段名的JSON对象或按顺序的JSON数组
+重载方法在ABI中按 go-ethereum 规则命名（如 foo、foo0）。
+*/
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// CallMethod 按ABI调用合约只读方法，返回解码后的输出值（大整数为十进制字符串，字节为0x十六进制）
+func (a *EVMAdapter) CallMethod(ctx context.Context, contract, abiJSON, method string, args []interface{}) ([]interface{}, error) {
+	parsed, data, err := PackMethodCall(abiJSON, method, args)
+	if err != nil {
+		return nil, err
+	}
+	if !common.IsHexAddress(contract) {
+		return nil, fmt.Errorf("合约地址格式不正确: %s", contract)
+	}
+	to := common.HexToAddress(contract)
+	out, err := a.client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		if reason, ok := executionRevertReason(err); ok {
+			return nil, fmt.Errorf("调用 %s 失败: %s", method, reason)
+		}
+		return nil, fmt.Errorf("调用 %s 失败: %w", method, err)
+	}
+	values, err := parsed.Methods[method].Outputs.Unpack(out)
+	if err != nil {
+		return nil, fmt.Errorf("解码 %s 返回值失败: %w", method, err)
+	}
+	results := make([]interface{}, len(values))
+	for i, v := range values {
+		results[i] = formatABIValue(reflect.ValueOf(v))
+	}
+	return results, nil
+}
+
+// SendMethod 按ABI打包参数并发送合约写入交易（不附带原生代币）
+func (a *EVMAdapter) SendMethod(ctx context.Context, signer Signer, contract, abiJSON, method string, args []interface{}, opts *TxOptions) (string, error) {
+	return a.SendPayableMethod(ctx, signer, contract, abiJSON, method, args, nil, opts)
+}
+
+// SendPayableMethod 按ABI打包参数并发送合约写入交易，value 为附带的原生代币（最小单位）
+func (a *EVMAdapter) SendPayableMethod(ctx context.Context, signer Signer, contract, abiJSON, method string, args []interface{}, value *big.Int, opts *TxOptions) (string, error) {
+	parsed, data, err := PackMethodCall(abiJSON, method, args)
+	if err != nil {
+		return "", err
+	}
+	if !common.IsHexAddress(contract) {
+		return "", fmt.Errorf("合约地址格式不正确: %s", contract)
+	}
+	if value != nil && value.Sign() > 0 && !parsed.Methods[method].IsPayable() {
+		return "", fmt.Errorf("方法 %s 不是 payable，不能附带 value", method)
+	}
+	return a.SendContractCallWithSigner(ctx, signer, common.HexToAddress(contract), data, value, opts)
+}
+
+// PackMethodCall 解析ABI并按方法参数类型转换JSON参数，返回ABI与调用数据
+func PackMethodCall(abiJSON, method string, args []interface{}) (abi.ABI, []byte, error) {
+	parsed, err := parseABICached(abiJSON)
+	if err != nil {
+		return abi.ABI{}, nil, fmt.Errorf("解析ABI失败: %w", err)
+	}
+	m, ok := parsed.Methods[method]
+	if !ok {
+		return abi.ABI{}, nil, fmt.Errorf("ABI 中不存在方法: %s", method)
+	}
+	if len(args) != len(m.Inputs) {
+		return abi.ABI{}, nil, fmt.Errorf("方法 %s 需要 %d 个参数，实际提供 %d 个", method, len(m.Inputs), len(args))
+	}
+	values := make([]interface{}, len(args))
+	for i, input := range m.Inputs {
+		v, err := coerceABIArg(input.Type, args[i])
+		if err != nil {
+			name := input.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i)
+			}
+			return abi.ABI{}, nil, fmt.Errorf("参数 %s(%s) 无效: %w", name, input.Type.String(), err)
+		}
+		values[i] = v
+	}
+	data, err := parsed.Pack(method, values...)
+	if err != nil {
+		return abi.ABI{}, nil, fmt.Errorf("打包调用数据失败: %w", err)
+	}
+	return parsed, data, nil
+}
+
+// coerceABIArg 将JSON解码值转换为ABI类型对应的Go值
+func coerceABIArg(t abi.Type, v interface{}) (interface{}, error) {
+	rv, err := coerceABIValue(t, v)
+	if err != nil {
+		return nil, err
+	}
+	return rv.Interface(), nil
+}
+
+func coerceABIValue(t abi.Type, v interface{}) (reflect.Value, error) {
+	goType := t.GetType()
+	switch t.T {
+	case abi.AddressTy:
+		s, ok := v.(string)
+		if !ok || !common.IsHexAddress(s) {
+			return reflect.Value{}, fmt.Errorf("需要地址字符串")
+		}
+		return reflect.ValueOf(common.HexToAddress(s)), nil
+
+	case abi.IntTy, abi.UintTy:
+		n, err := jsonToBigInt(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if t.T == abi.UintTy && n.Sign() < 0 {
+			return reflect.Value{}, fmt.Errorf("无符号整数不能为负数")
+		}
+		if goType == reflect.TypeOf(&big.Int{}) {
+			return reflect.ValueOf(n), nil
+		}
+		out := reflect.New(goType).Elem()
+		if t.T == abi.UintTy {
+			if !n.IsUint64() || out.OverflowUint(n.Uint64()) {
+				return reflect.Value{}, fmt.Errorf("数值超出 %s 范围", t.String())
+			}
+			out.SetUint(n.Uint64())
+		} else {
+			if !n.IsInt64() || out.OverflowInt(n.Int64()) {
+				return reflect.Value{}, fmt.Errorf("数值超出 %s 范围", t.String())
+			}
+			out.SetInt(n.Int64())
+		}
+		return out, nil
+
+	case abi.BoolTy:
+		switch b := v.(type) {
+		case bool:
+			return reflect.ValueOf(b), nil
+		case string:
+			if b == "true" || b == "false" {
+				return reflect.ValueOf(b == "true"), nil
+			}
+		}
+		return reflect.Value{}, fmt.Errorf("需要布尔值")
+
+	case abi.StringTy:
+		s, ok := v.(string)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("需要字符串")
+		}
+		return reflect.ValueOf(s), nil
+
+	case abi.BytesTy:
+		b, err := jsonToBytes(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(b), nil
+
+	case abi.FixedBytesTy:
+		b, err := jsonToBytes(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if len(b) != t.Size {
+			return reflect.Value{}, fmt.Errorf("需要 %d 字节，实际 %d 字节", t.Size, len(b))
+		}
+		out := reflect.New(goType).Elem()
+		reflect.Copy(out, reflect.ValueOf(b))
+		return out, nil
+
+	case abi.SliceTy, abi.ArrayTy:
+		items, ok := v.([]interface{})
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("需要数组")
+		}
+		var out reflect.Value
+		if t.T == abi.ArrayTy {
+			if len(items) != t.Size {
+				return reflect.Value{}, fmt.Errorf("需要 %d 个元素，实际 %d 个", t.Size, len(items))
+			}
+			out = reflect.New(goType).Elem()
+		} else {
+			out = reflect.MakeSlice(goType, len(items), len(items))
+		}
+		for i, item := range items {
+			elem, err := coerceABIValue(*t.Elem, item)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("第 %d 个元素: %w", i, err)
+			}
+			out.Index(i).Set(elem)
+		}
+		return out, nil
+
+	case abi.TupleTy:
+		out := reflect.New(goType).Elem()
+		switch fields := v.(type) {
+		case map[string]interface{}:
+			for i, name := range t.TupleRawNames {
+				raw, ok := fields[name]
+				if !ok {
+					return reflect.Value{}, fmt.Errorf("缺少字段 %s", name)
+				}
+				field, err := coerceABIValue(*t.TupleElems[i], raw)
+				if err != nil {
+					return reflect.Value{}, fmt.Errorf("字段 %s: %w", name, err)
+				}
+				out.Field(i).Set(field)
+			}
+		case []interface{}:
+			if len(fields) != len(t.TupleElems) {
+				return reflect.Value{}, fmt.Errorf("需要 %d 个字段，实际 %d 个", len(t.TupleElems), len(fields))
+			}
+			for i, raw := range fields {
+				field, err := coerceABIValue(*t.TupleElems[i], raw)
+				if err != nil {
+					return reflect.Value{}, fmt.Errorf("第 %d 个字段: %w", i, err)
+				}
+				out.Field(i).Set(field)
+			}
+		default:
+			return reflect.Value{}, fmt.Errorf("元组需要JSON对象或数组")
+		}
+		return out, nil
+	}
+	return reflect.Value{}, fmt.Errorf("不支持的参数类型: %s", t.String())
+}
+
+// jsonToBigInt 将十进制/0x十六进制字符串或JSON数字转换为大整数
+func jsonToBigInt(v interface{}) (*big.Int, error) {
+	var s string
+	switch n := v.(type) {
+	case string:
+		s = strings.TrimSpace(n)
+	case json.Number:
+		s = n.String()
+	case float64:
+		if n != float64(int64(n)) {
+			return nil, fmt.Errorf("需要整数")
+		}
+		return big.NewInt(int64(n)), nil
+	default:
+		return nil, fmt.Errorf("需要整数")
+	}
+	out := new(big.Int)
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		if _, ok := out.SetString(s[2:], 16); !ok {
+			return nil, fmt.Errorf("无效的十六进制整数: %s", s)
+		}
+		return out, nil
+	}
+	if _, ok := out.SetString(s, 10); !ok {
+		return nil, fmt.Errorf("无效的整数: %s", s)
+	}
+	return out, nil
+}
+
+// jsonToBytes 将0x十六进制字符串转换为字节
+func jsonToBytes(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("需要0x十六进制字符串")
+	}
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("无效的十六进制: %w", err)
+	}
+	return b, nil
+}
@@ -49,7 +49,6 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"strings"
 	"sync"
 	"time"
 )
@@ -63,6 +62,7 @@ type DAppBrowser struct {
 	dappRegistry   *DAppRegistry              // DApp注册表
 	connections    map[string]*DAppConnection // 活跃连接
 	httpClient     *http.Client               // HTTP客户端
+	blocklist      *AddressBlocklist          // 全局收款地址黑名单，签名交易前检查，可为 nil
 	mu             sync.RWMutex               // 读写锁
 }
 
@@ -87,6 +87,7 @@ type DAppSession struct {
 	ExpiresAt      time.Time                  `json:"expires_at"`     // 过期时间
 	RequestQueue   []*Web3Request             `json:"request_queue"`  // 请求队列
 	EventListeners map[string][]EventCallback `json:"-"`              // 事件监听器
+	Security       *SecurityCheckResult       `json:"security"`       // 连接时的域名安全检查结果
 }
 
 // DAppConnection DApp连接
@@ -109,6 +110,7 @@ type PermissionManager struct {
 
 // DAppPermission DApp权限
 type DAppPermission struct {
+	ID          string       `json:"id"`           // 授权ID
 	DAppURL     string       `json:"dapp_url"`     // DApp URL
 	UserAddress string       `json:"user_address"` // 用户地址
 	Permissions []Permission `json:"permissions"`  // 权限列表
@@ -131,6 +133,12 @@ type SecurityManager struct {
 	phishingList   map[string]bool // 钓鱼网站列表
 	trustedDomains map[string]bool // 可信域名
 	riskRules      []*SecurityRule // 安全规则
+	allowList      map[string]bool // 黑名单来源的白名单（优先于黑名单与近似匹配）
+	fuzzyList      []string        // 易被仿冒的知名域名（近似/同形字匹配目标）
+	tolerance      int             // 近似匹配的最大编辑距离
+	listSource     string          // 黑名单来源地址
+	listUpdatedAt  time.Time       // 黑名单最近加载时间
+	httpClient     *http.Client    // 拉取黑名单的HTTP客户端
 	mu             sync.RWMutex    // 读写锁
 }
 
@@ -209,6 +217,7 @@ type Web3Request struct {
 	Status       string        `json:"status"`        // 状态
 	Response     interface{}   `json:"response"`      // 响应
 	Error        *Web3Error    `json:"error"`         // 错误
+	ChainID      string        `json:"chain_id"`      // 请求指定的链（十六进制），为空时使用会话当前链
 }
 
 // Web3Message Web3消息
@@ -260,9 +269,10 @@ func (db *DAppBrowser) ConnectDApp(ctx context.Context, dappURL, userAddress str
 		return nil, fmt.Errorf("无效的DApp URL: %w", err)
 	}
 
-	// 安全检查
-	if err := db.securityMgr.CheckSecurity(parsedURL.Host); err != nil {
-		return nil, fmt.Errorf("安全检查失败: %w", err)
+	// 安全检查：高风险直接拦截，中低风险随会话返回由前端提示
+	check := db.securityMgr.CheckSecurity(parsedURL.Host)
+	if check.Blocked() {
+		return nil, &SecurityBlockedError{Result: check}
 	}
 
 	// 创建会话
@@ -279,6 +289,7 @@ func (db *DAppBrowser) ConnectDApp(ctx context.Context, dappURL, userAddress str
 		ChainID:   session.ChainID,
 	})
 
+	session.Security = check
 	return session, nil
 }
 
@@ -289,16 +300,16 @@ func (db *DAppBrowser) ProcessWeb3Request(ctx context.Context, sessionID string,
 		return nil, fmt.Errorf("获取会话失败: %w", err)
 	}
 
-	// 检查权限
-	if request.RequiresAuth {
+	// 检查权限（eth_requestAccounts 未授权时进入待确认，由 handleRequestAccounts 处理）
+	if request.RequiresAuth && request.Method != "eth_requestAccounts" {
 		hasPermission, err := db.permissionMgr.CheckPermission(session.DAppURL, session.UserAddress, request.Method)
 		if err != nil {
 			return nil, fmt.Errorf("权限检查失败: %w", err)
 		}
 		if !hasPermission {
 			request.Error = &Web3Error{
-				Code:    4001,
-				Message: "用户拒绝授权",
+				Code:    4100, // EIP-1193 Unauthorized
+				Message: "该方法未获用户授权",
 			}
 			return request, nil
 		}
@@ -327,6 +338,27 @@ func (db *DAppBrowser) ProcessWeb3Request(ctx context.Context, sessionID string,
 	}
 }
 
+// ConnectExternalDApp 为外部连接（如 WalletConnect）创建会话，会话使用指定的链与过期时间
+// 与 ConnectDApp 一样进行域名安全检查，高风险域名直接拦截
+func (db *DAppBrowser) ConnectExternalDApp(ctx context.Context, dappURL, userAddress, chainID string, expiresAt time.Time) (*DAppSession, error) {
+	session, err := db.ConnectDApp(ctx, dappURL, userAddress)
+	if err != nil {
+		return nil, err
+	}
+	db.sessionManager.mu.Lock()
+	session.ChainID = chainID
+	session.ExpiresAt = expiresAt
+	db.sessionManager.mu.Unlock()
+	return session, nil
+}
+
+// CloseSession 关闭会话，未处理的待确认请求随会话丢弃
+func (db *DAppBrowser) CloseSession(sessionID string) {
+	db.sessionManager.mu.Lock()
+	defer db.sessionManager.mu.Unlock()
+	delete(db.sessionManager.sessions, sessionID)
+}
+
 // GetDAppCategories 获取DApp分类
 func (db *DAppBrowser) GetDAppCategories() map[string]*DAppCategory {
 	return db.dappRegistry.GetCategories()
@@ -347,6 +379,11 @@ func (db *DAppBrowser) GetUserFavorites(userAddress string) []*DAppInfo {
 	return db.dappRegistry.GetUserFavorites(userAddress)
 }
 
+// GetSession 获取DApp会话
+func (db *DAppBrowser) GetSession(sessionID string) (*DAppSession, error) {
+	return db.sessionManager.GetSession(sessionID)
+}
+
 // 私有方法实现
 
 // 处理账户请求
@@ -384,6 +421,9 @@ func (db *DAppBrowser) handleGetAccounts(ctx context.Context, session *DAppSessi
 func (db *DAppBrowser) handleGetChainId(ctx context.Context, session *DAppSession, request *Web3Request) (*Web3Request, error) {
 	request.Status = "completed"
 	request.Response = session.ChainID
+	if request.ChainID != "" {
+		request.Response = request.ChainID
+	}
 	return request, nil
 }
 
@@ -436,6 +476,7 @@ func (db *DAppBrowser) handleSendTransaction(ctx context.Context, session *DAppS
 	request.Status = "pending_auth"
 	request.UserPrompt = "DApp请求发送交易，请确认"
 	request.RiskLevel = "high"
+	db.sessionManager.enqueueRequest(session, request)
 	return request, nil
 }
 
@@ -444,6 +485,7 @@ func (db *DAppBrowser) handleSignTypedData(ctx context.Context, session *DAppSes
 	request.Status = "pending_auth"
 	request.UserPrompt = "DApp请求签名数据，请确认"
 	request.RiskLevel = "medium"
+	db.sessionManager.enqueueRequest(session, request)
 	return request, nil
 }
 
@@ -452,6 +494,7 @@ func (db *DAppBrowser) handlePersonalSign(ctx context.Context, session *DAppSess
 	request.Status = "pending_auth"
 	request.UserPrompt = "DApp请求签名消息，请确认"
 	request.RiskLevel = "low"
+	db.sessionManager.enqueueRequest(session, request)
 	return request, nil
 }
 
@@ -558,23 +601,19 @@ func (pm *PermissionManager) CheckPermission(dappURL, userAddress, method string
 	pm.mu.RLock()
 	defer pm.mu.RUnlock()
 
-	key := fmt.Sprintf("%s:%s", dappURL, userAddress)
-	permission, exists := pm.permissions[key]
-	if !exists {
-		return false, nil
-	}
-
-	if permission.IsRevoked {
-		return false, nil
+	origin, err := DAppOrigin(dappURL)
+	if err != nil {
+		return false, err
 	}
-
-	if permission.ExpiresAt != nil && time.Now().After(*permission.ExpiresAt) {
+	permission, exists := pm.permissions[permissionKey(origin, userAddress)]
+	if !exists || !permission.isActive(time.Now()) {
 		return false, nil
 	}
 
-	// 检查具体方法权限
+	// 检查具体方法权限（按方法精确授权）
+	scope := permissionScope(method)
 	for _, perm := range permission.Permissions {
-		if perm.Type == method || perm.Type == "all" {
+		if perm.Type == scope {
 			return true, nil
 		}
 	}
@@ -584,33 +623,18 @@ func (pm *PermissionManager) CheckPermission(dappURL, userAddress, method string
 
 // NewSecurityManager 创建安全管理器
 func NewSecurityManager() *SecurityManager {
-	return &SecurityManager{
+	sm := &SecurityManager{
 		phishingList:   make(map[string]bool),
 		trustedDomains: make(map[string]bool),
 		riskRules:      make([]*SecurityRule, 0),
+		allowList:      make(map[string]bool),
+		tolerance:      defaultPhishingTolerance,
+		httpClient:     &http.Client{Timeout: phishingFetchTimeout},
 	}
-}
-
-// CheckSecurity 安全检查
-func (sm *SecurityManager) CheckSecurity(domain string) error {
-	sm.mu.RLock()
-	defer sm.mu.RUnlock()
-
-	// 检查黑名单
-	if sm.phishingList[domain] {
-		return fmt.Errorf("检测到钓鱼网站")
-	}
-
-	// 检查安全规则
-	for _, rule := range sm.riskRules {
-		if rule.IsEnabled && strings.Contains(domain, rule.Pattern) {
-			if rule.RiskLevel == "high" && rule.Action == "block" {
-				return fmt.Errorf("域名违反安全规则: %s", rule.Name)
-			}
-		}
+	for _, domain := range defaultProtectedDomains {
+		sm.trustedDomains[domain] = true
 	}
-
-	return nil
+	return sm
 }
 
 // NewDAppRegistry 创建DApp注册表
@@ -0,0 +1,198 @@
+/*
+DApp 连接授权与方法级权限
+
+用户对 DApp 的授权按 (DApp 来源, 用户地址) 记录，授权范围精确到 RPC 方法，
+授权 eth_accounts 不会连带授权发送交易或签名：
+- eth_accounts：允许 DApp 读取账户（eth_requestAccounts / eth_accounts）
+- eth_sendTransaction、personal_sign、eth_signTypedData_v4、wallet_*：需单独授权
+- 授权可设置有效期，过期或撤销后立即失效；重复授权会合并方法并刷新有效期
+
+DApp 来源统一规范化为 scheme://host，同一站点不同路径共享授权。
+*/
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DAppGrantableMethods 可授权的方法
+var DAppGrantableMethods = map[string]bool{
+	"eth_accounts":               true,
+	"eth_sendTransaction":        true,
+	"personal_sign":              true,
+	"eth_signTypedData_v4":       true,
+	"wallet_switchEthereumChain": true,
+	"wallet_addEthereumChain":    true,
+}
+
+// DAppOrigin 将 DApp URL 规范化为 scheme://host（小写）
+func DAppOrigin(dappURL string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(dappURL))
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return "", fmt.Errorf("无效的DApp URL: %s", dappURL)
+	}
+	return strings.ToLower(parsed.Scheme + "://" + parsed.Host), nil
+}
+
+// permissionScope 方法对应的授权范围（eth_requestAccounts 与 eth_accounts 共用账户授权）
+func permissionScope(method string) string {
+	if method == "eth_requestAccounts" {
+		return "eth_accounts"
+	}
+	return method
+}
+
+// permissionKey 授权记录索引键
+func permissionKey(origin, userAddress string) string {
+	return origin + "|" + strings.ToLower(userAddress)
+}
+
+// Methods 已授权的方法列表
+func (p *DAppPermission) Methods() []string {
+	methods := make([]string, 0, len(p.Permissions))
+	for _, perm := range p.Permissions {
+		methods = append(methods, perm.Type)
+	}
+	return methods
+}
+
+// isActive 授权是否仍然有效
+func (p *DAppPermission) isActive(now time.Time) bool {
+	return !p.IsRevoked && (p.ExpiresAt == nil || now.Before(*p.ExpiresAt))
+}
+
+// GrantPermission 授权 DApp 调用指定方法，ttl 为 0 表示长期有效
+// 已有有效授权时合并方法并刷新有效期
+func (pm *PermissionManager) GrantPermission(dappURL, userAddress string, methods []string, ttl time.Duration) (*DAppPermission, error) {
+	origin, err := DAppOrigin(dappURL)
+	if err != nil {
+		return nil, err
+	}
+	if userAddress == "" {
+		return nil, fmt.Errorf("用户地址不能为空")
+	}
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("授权方法不能为空")
+	}
+	if ttl < 0 {
+		return nil, fmt.Errorf("无效的授权有效期")
+	}
+	scopes := make(map[string]bool)
+	for _, method := range methods {
+		scope := permissionScope(strings.TrimSpace(method))
+		if !DAppGrantableMethods[scope] {
+			return nil, fmt.Errorf("不支持授权的方法: %s", method)
+		}
+		scopes[scope] = true
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	now := time.Now()
+	key := permissionKey(origin, userAddress)
+	existing, ok := pm.permissions[key]
+	id := ""
+	if ok && existing.isActive(now) {
+		id = existing.ID
+		for _, perm := range existing.Permissions {
+			scopes[perm.Type] = true
+		}
+	}
+	if id == "" {
+		if id, err = randomHex(16); err != nil {
+			return nil, err
+		}
+	}
+
+	granted := make([]string, 0, len(scopes))
+	for scope := range scopes {
+		granted = append(granted, scope)
+	}
+	sort.Strings(granted)
+	permissions := make([]Permission, 0, len(granted))
+	for _, scope := range granted {
+		permissions = append(permissions, Permission{Type: scope, Resource: origin})
+	}
+
+	record := &DAppPermission{
+		ID:          id,
+		DAppURL:     origin,
+		UserAddress: userAddress,
+		Permissions: permissions,
+		GrantedAt:   now,
+	}
+	if ttl > 0 {
+		expires := now.Add(ttl)
+		record.ExpiresAt = &expires
+	}
+	pm.permissions[key] = record
+
+	copied := *record
+	return &copied, nil
+}
+
+// RevokePermission 撤销授权，只能撤销属于该用户的记录
+func (pm *PermissionManager) RevokePermission(id, userAddress string) (*DAppPermission, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	for _, record := range pm.permissions {
+		if record.ID != id || !strings.EqualFold(record.UserAddress, userAddress) {
+			continue
+		}
+		if record.IsRevoked {
+			return nil, fmt.Errorf("授权已撤销")
+		}
+		now := time.Now()
+		record.IsRevoked = true
+		record.RevokedAt = &now
+		copied := *record
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("授权记录不存在")
+}
+
+// ListPermissions 列出用户当前有效的 DApp 授权，按授权时间倒序
+func (pm *PermissionManager) ListPermissions(userAddress string) []*DAppPermission {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	now := time.Now()
+	out := make([]*DAppPermission, 0)
+	for _, record := range pm.permissions {
+		if strings.EqualFold(record.UserAddress, userAddress) && record.isActive(now) {
+			copied := *record
+			out = append(out, &copied)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].GrantedAt.After(out[j].GrantedAt) })
+	return out
+}
+
+// RestorePermission 载入已持久化的授权记录（启动时从数据库恢复）
+func (pm *PermissionManager) RestorePermission(record *DAppPermission) {
+	origin, err := DAppOrigin(record.DAppURL)
+	if err != nil {
+		return
+	}
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	key := permissionKey(origin, record.UserAddress)
+	if existing, ok := pm.permissions[key]; ok && existing.GrantedAt.After(record.GrantedAt) {
+		return
+	}
+	copied := *record
+	copied.DAppURL = origin
+	pm.permissions[key] = &copied
+}
+
+// PermissionManager 获取权限管理器
+func (db *DAppBrowser) PermissionManager() *PermissionManager {
+	return db.permissionMgr
+}
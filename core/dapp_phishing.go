@@ -0,0 +1,409 @@
+/*
+DApp 钓鱼域名检测
+
+黑名单来自 MetaMask eth-phishing-detect（或配置的兼容地址），格式：
+{"tolerance":2,"fuzzylist":[...],"whitelist":[...],"blacklist":[...]}
+LoadPhishingList 拉取并整体替换名单，StartPhishingListRefresh 按间隔定时刷新（失败时保留旧名单）。
+
+CheckSecurity 按以下顺序判定，返回风险等级与命中规则：
+- whitelist / 可信域名（含其子域名）：放行
+- blacklist（含其子域名）：high，拦截
+- 同形字仿冒：域名含非 ASCII 字符或 punycode（xn--），转换为拉丁骨架后等于某个知名域名：high，拦截
+- 近似仿冒：注册域名与知名域名编辑距离不超过 tolerance：medium，警告
+- 子域名冒用：子域名中嵌入知名域名（如 uniswap.org.example.com）：medium，警告
+- 自定义安全规则：action 为 block 时拦截，否则按规则风险等级警告
+*/
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+	"golang.org/x/text/unicode/norm"
+)
+
+// 安全检查风险等级
+const (
+	SecurityRiskNone   = "none"
+	SecurityRiskLow    = "low"
+	SecurityRiskMedium = "medium"
+	SecurityRiskHigh   = "high"
+)
+
+// 安全检查处理动作
+const (
+	SecurityActionAllow = "allow" // 放行
+	SecurityActionWarn  = "warn"  // 提示用户后允许继续
+	SecurityActionBlock = "block" // 拦截
+)
+
+const (
+	defaultPhishingTolerance = 2
+	phishingFetchTimeout     = 30 * time.Second
+	phishingMaxListSize      = 32 << 20 // 黑名单响应大小上限
+)
+
+// defaultProtectedDomains 内置的可信知名域名，黑名单未加载时也参与仿冒检测
+var defaultProtectedDomains = []string{
+	"metamask.io",
+	"uniswap.org",
+	"opensea.io",
+	"etherscan.io",
+	"aave.com",
+	"curve.fi",
+	"lido.fi",
+	"1inch.io",
+	"compound.finance",
+	"sushi.com",
+	"pancakeswap.finance",
+	"ens.domains",
+}
+
+// homoglyphs 常见的与拉丁字母同形的西里尔/希腊字母
+var homoglyphs = map[rune]rune{
+	// 西里尔字母
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'һ': 'h', 'і': 'i', 'ї': 'i', 'ј': 'j',
+	'к': 'k', 'ӏ': 'l', 'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p', 'ԛ': 'q', 'г': 'r',
+	'ѕ': 's', 'т': 't', 'ц': 'u', 'ѵ': 'v', 'ԝ': 'w', 'х': 'x', 'у': 'y', 'ԁ': 'd',
+	'ɡ': 'g', 'ո': 'n', 'ս': 'u',
+	// 希腊字母
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o',
+	'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x', 'γ': 'y', 'ω': 'w',
+}
+
+// SecurityCheckResult 域名安全检查结果
+type SecurityCheckResult struct {
+	Domain        string `json:"domain"`                   // 检查的域名（ASCII 形式）
+	RiskLevel     string `json:"risk_level"`               // 风险等级：none / low / medium / high
+	Action        string `json:"action"`                   // 处理动作：allow / warn / block
+	MatchedRule   string `json:"matched_rule,omitempty"`   // 命中规则：whitelist / blacklist / homoglyph / fuzzy / subdomain / 自定义规则ID
+	MatchedDomain string `json:"matched_domain,omitempty"` // 命中的名单域名或被仿冒的知名域名
+	Reason        string `json:"reason,omitempty"`         // 说明
+}
+
+// Blocked 是否应拦截
+func (r *SecurityCheckResult) Blocked() bool {
+	return r != nil && r.Action == SecurityActionBlock
+}
+
+// SecurityBlockedError 域名被安全检查拦截
+type SecurityBlockedError struct {
+	Result *SecurityCheckResult
+}
+
+func (e *SecurityBlockedError) Error() string {
+	return fmt.Sprintf("安全检查拦截: %s", e.Result.Reason)
+}
+
+// phishingConfig eth-phishing-detect config.json 格式
+type phishingConfig struct {
+	Tolerance int      `json:"tolerance"`
+	FuzzyList []string `json:"fuzzylist"`
+	Whitelist []string `json:"whitelist"`
+	Blacklist []string `json:"blacklist"`
+}
+
+// LoadPhishingList 拉取钓鱼域名名单并整体替换当前名单
+func (sm *SecurityManager) LoadPhishingList(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("创建黑名单请求失败: %w", err)
+	}
+	resp, err := sm.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("拉取钓鱼黑名单失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("拉取钓鱼黑名单失败: HTTP %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, phishingMaxListSize))
+	if err != nil {
+		return fmt.Errorf("读取钓鱼黑名单失败: %w", err)
+	}
+	var cfg phishingConfig
+	if err := json.Unmarshal(body, &cfg); err != nil {
+		return fmt.Errorf("解析钓鱼黑名单失败: %w", err)
+	}
+	if len(cfg.Blacklist) == 0 {
+		// 空名单多半是来源异常，保留旧名单
+		return fmt.Errorf("钓鱼黑名单为空: %s", url)
+	}
+
+	blacklist := make(map[string]bool, len(cfg.Blacklist))
+	for _, domain := range cfg.Blacklist {
+		if d := normalizeDomain(domain); d != "" {
+			blacklist[d] = true
+		}
+	}
+	allowList := make(map[string]bool, len(cfg.Whitelist))
+	for _, domain := range cfg.Whitelist {
+		if d := normalizeDomain(domain); d != "" {
+			allowList[d] = true
+		}
+	}
+	fuzzyList := make([]string, 0, len(cfg.FuzzyList))
+	for _, domain := range cfg.FuzzyList {
+		if d := normalizeDomain(domain); d != "" {
+			fuzzyList = append(fuzzyList, d)
+		}
+	}
+	tolerance := cfg.Tolerance
+	if tolerance <= 0 {
+		tolerance = defaultPhishingTolerance
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.phishingList = blacklist
+	sm.allowList = allowList
+	sm.fuzzyList = fuzzyList
+	sm.tolerance = tolerance
+	sm.listSource = url
+	sm.listUpdatedAt = time.Now()
+	slog.DebugContext(ctx, "phishing list loaded", "url", url, "blacklist", len(blacklist), "allowlist", len(allowList), "fuzzy_targets", len(fuzzyList))
+	return nil
+}
+
+// StartPhishingListRefresh 立即加载名单并按间隔刷新，直到 ctx 取消
+func (sm *SecurityManager) StartPhishingListRefresh(ctx context.Context, url string, interval time.Duration) {
+	refresh := func() {
+		loadCtx, cancel := context.WithTimeout(ctx, phishingFetchTimeout)
+		defer cancel()
+		if err := sm.LoadPhishingList(loadCtx, url); err != nil {
+			slog.DebugContext(ctx, "phishing list refresh failed", "url", url, "error", err)
+		}
+	}
+
+	go func() {
+		refresh()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				refresh()
+			}
+		}
+	}()
+}
+
+// PhishingListStatus 黑名单来源、域名数与最近加载时间
+func (sm *SecurityManager) PhishingListStatus() (source string, size int, updatedAt time.Time) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.listSource, len(sm.phishingList), sm.listUpdatedAt
+}
+
+// CheckSecurity 检查域名（可带端口，可为 Unicode 或 punycode 形式）的钓鱼风险
+func (sm *SecurityManager) CheckSecurity(domain string) *SecurityCheckResult {
+	ascii := normalizeDomain(domain)
+	result := &SecurityCheckResult{Domain: ascii, RiskLevel: SecurityRiskNone, Action: SecurityActionAllow}
+	if ascii == "" {
+		result.RiskLevel, result.Action = SecurityRiskHigh, SecurityActionBlock
+		result.MatchedRule, result.Reason = "invalid_domain", "无效的域名"
+		return result
+	}
+
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	// 白名单与可信域名优先
+	if matched := matchDomainOrParent(ascii, sm.allowList); matched != "" {
+		result.MatchedRule, result.MatchedDomain = "whitelist", matched
+		return result
+	}
+	if matched := matchDomainOrParent(ascii, sm.trustedDomains); matched != "" {
+		result.MatchedRule, result.MatchedDomain = "trusted", matched
+		return result
+	}
+
+	// 黑名单（含子域名）
+	if matched := matchDomainOrParent(ascii, sm.phishingList); matched != "" {
+		result.RiskLevel, result.Action = SecurityRiskHigh, SecurityActionBlock
+		result.MatchedRule, result.MatchedDomain = "blacklist", matched
+		result.Reason = "检测到钓鱼网站: " + matched
+		return result
+	}
+
+	registrable, err := publicsuffix.EffectiveTLDPlusOne(ascii)
+	if err != nil {
+		registrable = ascii
+	}
+	targets := sm.protectedDomains()
+
+	// 同形字仿冒：Unicode 域名的拉丁骨架与知名域名一致
+	unicodeForm := ascii
+	if u, err := idna.ToUnicode(ascii); err == nil {
+		unicodeForm = u
+	}
+	if !isASCII(unicodeForm) {
+		skeleton := domainSkeleton(unicodeForm)
+		for _, target := range targets {
+			if skeleton == target || strings.HasSuffix(skeleton, "."+target) {
+				result.RiskLevel, result.Action = SecurityRiskHigh, SecurityActionBlock
+				result.MatchedRule, result.MatchedDomain = "homoglyph", target
+				result.Reason = fmt.Sprintf("域名 %s 使用形近字符仿冒 %s", unicodeForm, target)
+				return result
+			}
+		}
+	}
+
+	// 近似仿冒：注册域名与知名域名仅有少量字符差异
+	for _, target := range targets {
+		if registrable == target {
+			continue
+		}
+		if distance := levenshtein(registrable, target); distance <= sm.tolerance {
+			result.RiskLevel, result.Action = SecurityRiskMedium, SecurityActionWarn
+			result.MatchedRule, result.MatchedDomain = "fuzzy", target
+			result.Reason = fmt.Sprintf("域名 %s 与 %s 高度相似，请确认是否为官方网站", registrable, target)
+			return result
+		}
+	}
+
+	// 子域名冒用：在其他注册域名下嵌入知名域名
+	if subdomain := strings.TrimSuffix(ascii, registrable); subdomain != "" {
+		labels := "." + subdomain
+		for _, target := range targets {
+			if strings.Contains(labels, "."+target+".") {
+				result.RiskLevel, result.Action = SecurityRiskMedium, SecurityActionWarn
+				result.MatchedRule, result.MatchedDomain = "subdomain", target
+				result.Reason = fmt.Sprintf("域名 %s 在子域名中冒用 %s，实际站点为 %s", ascii, target, registrable)
+				return result
+			}
+		}
+	}
+
+	// 自定义安全规则
+	for _, rule := range sm.riskRules {
+		if !rule.IsEnabled || !strings.Contains(ascii, rule.Pattern) {
+			continue
+		}
+		result.RiskLevel, result.Action = rule.RiskLevel, SecurityActionWarn
+		if rule.Action == SecurityActionBlock {
+			result.Action = SecurityActionBlock
+		}
+		result.MatchedRule, result.Reason = rule.ID, "域名违反安全规则: "+rule.Name
+		return result
+	}
+
+	return result
+}
+
+// protectedDomains 近似/同形字匹配的目标域名（调用方持有读锁）
+func (sm *SecurityManager) protectedDomains() []string {
+	targets := make([]string, 0, len(sm.fuzzyList)+len(sm.trustedDomains))
+	seen := make(map[string]bool, cap(targets))
+	for _, domain := range sm.fuzzyList {
+		if !seen[domain] {
+			seen[domain] = true
+			targets = append(targets, domain)
+		}
+	}
+	for domain := range sm.trustedDomains {
+		if !seen[domain] {
+			seen[domain] = true
+			targets = append(targets, domain)
+		}
+	}
+	return targets
+}
+
+// normalizeDomain 去除端口与末尾的点，转换为小写 ASCII（punycode）形式；无效域名返回空串
+func normalizeDomain(domain string) string {
+	domain = strings.TrimSpace(domain)
+	if host, _, err := net.SplitHostPort(domain); err == nil {
+		domain = host
+	}
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	if domain == "" {
+		return ""
+	}
+	ascii, err := idna.Lookup.ToASCII(domain)
+	if err != nil {
+		// 含非法字符的 Unicode 域名仍按小写原文比较
+		return domain
+	}
+	return ascii
+}
+
+// matchDomainOrParent 返回 domains 中与 domain 相同或为其父域名的条目
+func matchDomainOrParent(domain string, domains map[string]bool) string {
+	for {
+		if domains[domain] {
+			return domain
+		}
+		idx := strings.IndexByte(domain, '.')
+		if idx < 0 {
+			return ""
+		}
+		domain = domain[idx+1:]
+	}
+}
+
+// domainSkeleton 将 Unicode 域名转换为拉丁骨架：去除变音符号并替换同形字母
+func domainSkeleton(domain string) string {
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(domain) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if latin, ok := homoglyphs[r]; ok {
+			r = latin
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// isASCII 字符串是否仅含 ASCII 字符
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// levenshtein 计算两个字符串的编辑距离
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// SecurityManager 获取安全管理器
+func (db *DAppBrowser) SecurityManager() *SecurityManager {
+	return db.securityMgr
+}
@@ -0,0 +1,402 @@
+/*
+DApp 待确认请求的签名与执行
+
+eth_sendTransaction、personal_sign、eth_signTypedData_v4 进入待确认队列（会话 RequestQueue），
+用户确认后由 CompleteApprovedRequest 取出请求并使用用户的签名者完成：
+- eth_sendTransaction：解析 from/to/value/data/gas/费率/nonce，在会话所在链上构建、签名并广播，Response 为交易哈希
+- personal_sign：params 为 [message, address]，message 为 0x 十六进制时按字节签名
+- eth_signTypedData_v4：params 为 [address, typedData]，typedData 可为 JSON 字符串或对象
+请求中的地址必须与会话授权地址及签名者地址一致，否则拒绝执行；
+eth_sendTransaction 的接收地址（含 ERC20 transfer 的接收方）在拦截模式下命中全局黑名单时拒绝签名。
+*/
+package core
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+	"wallet/config"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// CompleteApprovedRequest 执行用户已确认的待处理请求，返回填充了 Response 的请求
+func (db *DAppBrowser) CompleteApprovedRequest(ctx context.Context, sessionID, requestID string, signer Signer) (*Web3Request, error) {
+	session, err := db.sessionManager.GetSession(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("获取会话失败: %w", err)
+	}
+	if signer == nil {
+		return nil, fmt.Errorf("缺少签名者")
+	}
+	if !strings.EqualFold(signer.Address().Hex(), session.UserAddress) {
+		return nil, fmt.Errorf("签名账户 %s 与会话授权地址不一致", signer.Address().Hex())
+	}
+
+	request, err := db.sessionManager.claimRequest(sessionID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch request.Method {
+	case "eth_sendTransaction":
+		err = db.completeSendTransaction(ctx, session, request, signer)
+	case "personal_sign":
+		err = completePersonalSign(session, request, signer)
+	case "eth_signTypedData_v4":
+		err = completeSignTypedData(session, request, signer)
+	default:
+		err = fmt.Errorf("不支持的方法: %s", request.Method)
+	}
+	if err != nil {
+		// 未完成签名或广播，放回队列允许用户重试或拒绝
+		db.sessionManager.releaseRequest(request)
+		return nil, err
+	}
+	if _, err := db.sessionManager.dequeueRequest(sessionID, requestID); err != nil {
+		return nil, err
+	}
+	request.Status = "completed"
+	return request, nil
+}
+
+// SetAddressBlocklist 设置签名 eth_sendTransaction 前检查的全局收款地址黑名单（初始化时调用）
+func (db *DAppBrowser) SetAddressBlocklist(blocklist *AddressBlocklist) {
+	db.blocklist = blocklist
+}
+
+// RejectRequest 用户拒绝待处理请求（EIP-1193 4001）
+func (db *DAppBrowser) RejectRequest(sessionID, requestID string) (*Web3Request, error) {
+	request, err := db.sessionManager.dequeueRequest(sessionID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	request.Status = "rejected"
+	request.Error = &Web3Error{Code: 4001, Message: "用户拒绝了请求"}
+	return request, nil
+}
+
+// PendingTransfer 待签名交易（DApp eth_sendTransaction、多签执行）的所在网络与资金流向，供签名前的风控、黑名单与支出限额校验
+// 调用数据为 ERC20 transfer 时 Token 为代币合约，Recipient/Amount 取自调用数据；否则 Token 为空，Recipient/Amount 为交易的 to/value
+type PendingTransfer struct {
+	NetworkID string
+	From      string
+	To        string // 交易的 to，合约调用时为合约地址
+	Token     string
+	Recipient string
+	Amount    *big.Int
+}
+
+// SendTransactionDetails 解析会话中 eth_sendTransaction 请求的网络与资金流向，其他方法返回 nil
+func (db *DAppBrowser) SendTransactionDetails(sessionID string, request *Web3Request) (*PendingTransfer, error) {
+	if request.Method != "eth_sendTransaction" {
+		return nil, nil
+	}
+	session, err := db.sessionManager.GetSession(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("获取会话失败: %w", err)
+	}
+	txParam, to, value, data, err := parseSendTransaction(session, request)
+	if err != nil {
+		return nil, err
+	}
+	networkID, err := sessionNetworkID(session, request)
+	if err != nil {
+		return nil, err
+	}
+
+	from, _ := txParam["from"].(string)
+	return newPendingTransfer(networkID, from, to, value, data), nil
+}
+
+// newPendingTransfer 由交易的 to/value/调用数据构建资金流向，ERC20 transfer 按调用数据中的接收方与数量
+func newPendingTransfer(networkID, from string, to common.Address, value *big.Int, data []byte) *PendingTransfer {
+	if value == nil {
+		value = new(big.Int)
+	}
+	transfer := &PendingTransfer{
+		NetworkID: networkID,
+		From:      from,
+		To:        to.Hex(),
+		Recipient: to.Hex(),
+		Amount:    value,
+	}
+	if recipient, amount, err := DecodeERC20TransferCallData(data); err == nil {
+		transfer.Token = to.Hex()
+		transfer.Recipient = recipient.Hex()
+		transfer.Amount = amount
+	}
+	return transfer
+}
+
+// parseSendTransaction 解析并校验 eth_sendTransaction 的交易参数、接收地址、金额与调用数据
+func parseSendTransaction(session *DAppSession, request *Web3Request) (map[string]interface{}, common.Address, *big.Int, []byte, error) {
+	if len(request.Params) == 0 {
+		return nil, common.Address{}, nil, nil, fmt.Errorf("缺少交易参数")
+	}
+	txParam, ok := request.Params[0].(map[string]interface{})
+	if !ok {
+		return nil, common.Address{}, nil, nil, fmt.Errorf("无效的交易参数")
+	}
+
+	from, _ := txParam["from"].(string)
+	if !strings.EqualFold(from, session.UserAddress) {
+		return nil, common.Address{}, nil, nil, fmt.Errorf("交易发送方 %s 与会话授权地址不一致", from)
+	}
+	toStr, _ := txParam["to"].(string)
+	if !common.IsHexAddress(toStr) {
+		return nil, common.Address{}, nil, nil, fmt.Errorf("缺少或无效的接收地址（暂不支持合约创建交易）")
+	}
+
+	value, err := web3Quantity(txParam, "value")
+	if err != nil {
+		return nil, common.Address{}, nil, nil, err
+	}
+	dataHex, _ := txParam["data"].(string)
+	if dataHex == "" {
+		dataHex, _ = txParam["input"].(string)
+	}
+	var data []byte
+	if dataHex != "" && dataHex != "0x" {
+		if data, err = hexutil.Decode(dataHex); err != nil {
+			return nil, common.Address{}, nil, nil, fmt.Errorf("无效的交易数据: %w", err)
+		}
+	}
+	return txParam, common.HexToAddress(toStr), value, data, nil
+}
+
+// completeSendTransaction 构建、签名并广播 eth_sendTransaction 交易
+func (db *DAppBrowser) completeSendTransaction(ctx context.Context, session *DAppSession, request *Web3Request, signer Signer) error {
+	txParam, to, value, data, err := parseSendTransaction(session, request)
+	if err != nil {
+		return err
+	}
+	// 拦截模式下命中全局黑名单直接拒绝；警告模式由调用方在确认前提示用户
+	if config.AppConfig.Blocklist.WithDefaults().Mode == config.BlocklistModeBlock {
+		if err := db.blocklist.CheckTransfer(newPendingTransfer("", session.UserAddress, to, value, data)); err != nil {
+			return err
+		}
+	}
+
+	opts := &TxOptions{}
+	if gas, err := web3Quantity(txParam, "gas"); err != nil {
+		return err
+	} else if gas != nil {
+		opts.GasLimit = gas.Uint64()
+	}
+	if opts.GasPrice, err = web3Quantity(txParam, "gasPrice"); err != nil {
+		return err
+	}
+	if opts.FeeCap, err = web3Quantity(txParam, "maxFeePerGas"); err != nil {
+		return err
+	}
+	if opts.TipCap, err = web3Quantity(txParam, "maxPriorityFeePerGas"); err != nil {
+		return err
+	}
+	if nonce, err := web3Quantity(txParam, "nonce"); err != nil {
+		return err
+	} else if nonce != nil {
+		n := nonce.Uint64()
+		opts.Nonce = &n
+	}
+
+	adapter, err := db.sessionAdapter(session, request)
+	if err != nil {
+		return err
+	}
+	txHash, err := adapter.sendWithSigner(ctx, signer, to, value, data, opts)
+	if err != nil {
+		return err
+	}
+	request.Response = txHash
+	return nil
+}
+
+// completePersonalSign 对 personal_sign 消息签名
+func completePersonalSign(session *DAppSession, request *Web3Request, signer Signer) error {
+	if len(request.Params) < 2 {
+		return fmt.Errorf("personal_sign 参数应为 [message, address]")
+	}
+	message, _ := request.Params[0].(string)
+	address, _ := request.Params[1].(string)
+	if !strings.EqualFold(address, session.UserAddress) {
+		return fmt.Errorf("签名地址 %s 与会话授权地址不一致", address)
+	}
+	// DApp 通常以十六进制传入 UTF-8 消息
+	if strings.HasPrefix(message, "0x") {
+		if raw, err := hexutil.Decode(message); err == nil {
+			message = string(raw)
+		}
+	}
+
+	signature, err := signDigest(signer, personalSignHash(message))
+	if err != nil {
+		return err
+	}
+	request.Response = signature
+	return nil
+}
+
+// completeSignTypedData 对 EIP-712 typed data（v4）签名
+func completeSignTypedData(session *DAppSession, request *Web3Request, signer Signer) error {
+	if len(request.Params) < 2 {
+		return fmt.Errorf("eth_signTypedData_v4 参数应为 [address, typedData]")
+	}
+	address, _ := request.Params[0].(string)
+	if !strings.EqualFold(address, session.UserAddress) {
+		return fmt.Errorf("签名地址 %s 与会话授权地址不一致", address)
+	}
+
+	var typedJSON []byte
+	switch data := request.Params[1].(type) {
+	case string:
+		typedJSON = []byte(data)
+	default:
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("无效的 typed data: %w", err)
+		}
+		typedJSON = raw
+	}
+	digest, err := typedDataDigest(typedJSON)
+	if err != nil {
+		return err
+	}
+
+	signature, err := signDigest(signer, digest)
+	if err != nil {
+		return err
+	}
+	request.Response = signature
+	return nil
+}
+
+// signDigest 对摘要签名并返回 V 为 27/28 的十六进制签名
+func signDigest(signer Signer, digest common.Hash) (string, error) {
+	sig, err := signer.SignHash(digest.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("签名失败: %w", err)
+	}
+	if len(sig) != 65 {
+		return "", fmt.Errorf("签名长度异常: %d", len(sig))
+	}
+	out := make([]byte, 65)
+	copy(out, sig)
+	if out[64] < 27 {
+		out[64] += 27
+	}
+	return "0x" + hex.EncodeToString(out), nil
+}
+
+// web3Quantity 解析交易参数中的十六进制（或十进制）数值，字段缺失时返回 nil
+func web3Quantity(params map[string]interface{}, field string) (*big.Int, error) {
+	raw, ok := params[field]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	str, ok := raw.(string)
+	if !ok || str == "" {
+		return nil, fmt.Errorf("无效的%s参数", field)
+	}
+	value, ok := new(big.Int).SetString(str, 0)
+	if !ok || value.Sign() < 0 {
+		return nil, fmt.Errorf("无效的%s参数: %s", field, str)
+	}
+	return value, nil
+}
+
+// sessionAdapter 获取请求所在链对应的 EVM 适配器，见 sessionNetworkID
+func (db *DAppBrowser) sessionAdapter(session *DAppSession, request *Web3Request) (*EVMAdapter, error) {
+	networkID, err := sessionNetworkID(session, request)
+	if err != nil {
+		return nil, err
+	}
+	adapter, err := db.multiChain.GetAdapter(networkID)
+	if err != nil {
+		return nil, err
+	}
+	evmAdapter, ok := adapter.(*EVMAdapter)
+	if !ok {
+		return nil, fmt.Errorf("网络 %s 不是EVM网络", networkID)
+	}
+	return evmAdapter, nil
+}
+
+// sessionNetworkID 请求所在链（请求未指定时为会话当前链，ChainID 为十六进制或十进制）对应的已启用网络
+func sessionNetworkID(session *DAppSession, request *Web3Request) (string, error) {
+	chainRef := session.ChainID
+	if request.ChainID != "" {
+		chainRef = request.ChainID
+	}
+	chainID, err := strconv.ParseInt(chainRef, 0, 64)
+	if err != nil {
+		return "", fmt.Errorf("无效的会话链ID: %s", chainRef)
+	}
+	for networkID, network := range config.AppConfig.Networks {
+		if network.Enabled && network.ChainID == chainID {
+			return networkID, nil
+		}
+	}
+	return "", fmt.Errorf("未配置链ID为 %d 的网络", chainID)
+}
+
+// enqueueRequest 将待确认请求加入会话队列
+func (sm *SessionManager) enqueueRequest(session *DAppSession, request *Web3Request) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	session.RequestQueue = append(session.RequestQueue, request)
+	session.LastActiveAt = time.Now()
+}
+
+// claimRequest 锁定待确认请求，防止同一请求被并发确认执行两次
+func (sm *SessionManager) claimRequest(sessionID, requestID string) (*Web3Request, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session, ok := sm.sessions[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("会话不存在")
+	}
+	for _, request := range session.RequestQueue {
+		if request.ID != requestID {
+			continue
+		}
+		if request.Status == "processing" {
+			return nil, fmt.Errorf("请求正在处理中")
+		}
+		request.Status = "processing"
+		return request, nil
+	}
+	return nil, fmt.Errorf("待确认请求不存在: %s", requestID)
+}
+
+// releaseRequest 执行失败时将请求恢复为待确认状态
+func (sm *SessionManager) releaseRequest(request *Web3Request) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	request.Status = "pending_auth"
+}
+
+// dequeueRequest 从会话队列取出待确认请求
+func (sm *SessionManager) dequeueRequest(sessionID, requestID string) (*Web3Request, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session, ok := sm.sessions[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("会话不存在")
+	}
+	for i, request := range session.RequestQueue {
+		if request.ID == requestID {
+			session.RequestQueue = append(session.RequestQueue[:i], session.RequestQueue[i+1:]...)
+			return request, nil
+		}
+	}
+	return nil, fmt.Errorf("待确认请求不存在: %s", requestID)
+}
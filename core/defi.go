@@ -119,26 +119,30 @@ type RouteHop struct {
 
 // SwapParams 交易参数
 type SwapParams struct {
-	TokenIn      string   `json:"token_in"`       // 输入代币地址
-	TokenOut     string   `json:"token_out"`      // 输出代币地址
-	AmountIn     *big.Int `json:"amount_in"`      // 输入数量
-	AmountOutMin *big.Int `json:"amount_out_min"` // 最小输出数量
-	Deadline     int64    `json:"deadline"`       // 交易截止时间
-	Recipient    string   `json:"recipient"`      // 接收地址
-	Slippage     string   `json:"slippage"`       // 滑点容忍度（百分比）
-	GasPrice     *big.Int `json:"gas_price"`      // Gas价格
+	TokenIn          string   `json:"token_in"`          // 输入代币地址
+	TokenOut         string   `json:"token_out"`         // 输出代币地址
+	AmountIn         *big.Int `json:"amount_in"`         // 输入数量
+	AmountOutMin     *big.Int `json:"amount_out_min"`    // 最小输出数量
+	Deadline         int64    `json:"deadline"`          // 交易截止时间
+	Recipient        string   `json:"recipient"`         // 接收地址
+	Slippage         string   `json:"slippage"`          // 滑点容忍度（百分比）
+	GasPrice         *big.Int `json:"gas_price"`         // Gas价格
+	Signer           Signer   `json:"-"`                 // 交易签名者
+	InfiniteApproval bool     `json:"infinite_approval"` // 授权不足时是否无限授权（默认仅授权所需数量）
+	AllowHighFee     bool     `json:"allow_high_fee"`    // 已确认高手续费，跳过手续费上限检查
 }
 
 // SwapResult 交易执行结果
 type SwapResult struct {
-	TxHash    string   `json:"tx_hash"`    // 交易哈希
-	AmountIn  *big.Int `json:"amount_in"`  // 实际输入数量
-	AmountOut *big.Int `json:"amount_out"` // 实际输出数量
-	GasUsed   uint64   `json:"gas_used"`   // 实际Gas消耗
-	GasPrice  *big.Int `json:"gas_price"`  // 实际Gas价格
-	Status    string   `json:"status"`     // 交易状态
-	Timestamp int64    `json:"timestamp"`  // 交易时间
-	Exchange  string   `json:"exchange"`   // 使用的交易所
+	TxHash    string          `json:"tx_hash"`            // 交易哈希
+	AmountIn  *big.Int        `json:"amount_in"`          // 实际输入数量
+	AmountOut *big.Int        `json:"amount_out"`         // 实际输出数量
+	GasUsed   uint64          `json:"gas_used"`           // 实际Gas消耗
+	GasPrice  *big.Int        `json:"gas_price"`          // 实际Gas价格
+	Status    string          `json:"status"`             // 交易状态
+	Timestamp int64           `json:"timestamp"`          // 交易时间
+	Exchange  string          `json:"exchange"`           // 使用的交易所
+	Approval  *ApprovalResult `json:"approval,omitempty"` // 兑换前的授权检查结果
 }
 
 // LiquidityPool 流动性池信息
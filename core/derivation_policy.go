@@ -0,0 +1,55 @@
+/*
+派生路径白名单
+
+为防止被入侵的客户端请求任意派生路径的签名（转走用户未预期的账户），
+所有按路径派生地址或私钥的操作都需通过白名单校验。
+白名单来自 config.security.derivation_path_allowlist（正则，需完整匹配）。
+*/
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"wallet/config"
+)
+
+// ErrDerivationPathNotAllowed 派生路径不在白名单内
+var ErrDerivationPathNotAllowed = errors.New("派生路径不在允许范围内")
+
+var (
+	derivationPolicyOnce     sync.Once
+	derivationPolicyPatterns []*regexp.Regexp
+	derivationPolicyErr      error
+)
+
+// CheckDerivationPath 校验派生路径是否在白名单内
+func CheckDerivationPath(derivationPath string) error {
+	derivationPolicyOnce.Do(loadDerivationPolicy)
+	if derivationPolicyErr != nil {
+		return derivationPolicyErr
+	}
+	for _, pattern := range derivationPolicyPatterns {
+		if pattern.MatchString(derivationPath) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrDerivationPathNotAllowed, derivationPath)
+}
+
+// loadDerivationPolicy 编译白名单正则，配置为空时使用默认标准账户范围
+func loadDerivationPolicy() {
+	patterns := config.AppConfig.Security.DerivationPathAllowlist
+	if len(patterns) == 0 {
+		patterns = config.DefaultDerivationPathAllowlist
+	}
+	for _, p := range patterns {
+		re, err := regexp.Compile("^(?:" + p + ")$")
+		if err != nil {
+			derivationPolicyErr = fmt.Errorf("派生路径白名单配置错误(%s): %w", p, err)
+			return
+		}
+		derivationPolicyPatterns = append(derivationPolicyPatterns, re)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+设备指纹
+
+登录设备按以下信息计算指纹，用于识别新设备（首次使用即信任，之后的新设备需额外确认）：
+- 客户端提供的稳定设备ID（X-Device-ID）
+- User-Agent 与客户端提示（Sec-CH-UA、Sec-CH-UA-Platform、Sec-CH-UA-Mobile、Sec-CH-UA-Model）
+User-Agent 与 Sec-CH-UA 中的版本号在计算前去除，浏览器或系统升级不会被识别为新设备。
+*/
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// 设备信任级别
+const (
+	DeviceTrustTrusted = "trusted" // 已信任，可直接登录
+	DeviceTrustPending = "pending" // 待确认，需双因素认证或在已信任设备上确认
+)
+
+// versionPattern 匹配 UA 与客户端提示中的版本号（如 120.0.6099.109、10_15_7）
+var versionPattern = regexp.MustCompile(`\d+(?:[._]\d+)*`)
+
+// DeviceInfo 登录请求中用于识别设备的信息
+type DeviceInfo struct {
+	DeviceID  string // 客户端提供的稳定设备ID
+	UserAgent string // User-Agent
+	Brands    string // Sec-CH-UA
+	Platform  string // Sec-CH-UA-Platform
+	Mobile    string // Sec-CH-UA-Mobile
+	Model     string // Sec-CH-UA-Model
+}
+
+// Fingerprint 设备指纹（SHA-256 前16字节十六进制），没有任何可识别信息时返回空字符串
+func (d DeviceInfo) Fingerprint() string {
+	parts := []string{
+		strings.TrimSpace(d.DeviceID),
+		normalizeDeviceHint(stripVersions(d.UserAgent)),
+		normalizeDeviceHint(stripVersions(d.Brands)),
+		normalizeDeviceHint(d.Platform),
+		normalizeDeviceHint(d.Mobile),
+		normalizeDeviceHint(d.Model),
+	}
+	if strings.Join(parts, "") == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// stripVersions 去除 User-Agent / Sec-CH-UA 中的版本号
+func stripVersions(value string) string {
+	return versionPattern.ReplaceAllString(value, "")
+}
+
+// normalizeDeviceHint 去除引号与多余空白并转为小写
+func normalizeDeviceHint(value string) string {
+	value = strings.ReplaceAll(value, `"`, "")
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
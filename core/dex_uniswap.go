@@ -20,6 +20,12 @@ Uniswap V2 DEX实现
 - Router: 0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D
 - Factory: 0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f
 - WETH: 0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2
+Sepolia、Polygon、BSC 使用 Uniswap 官方部署的 V2 合约，见 NewUniswapV2Exchange。
+
+交易执行：
+- 原生币输入调用 swapExactETHForTokens，原生币输出调用 swapExactTokensForETH，其余调用 swapExactTokensForTokens
+- 代币输入时通过 EnsureAllowance 检查额度，不足时先授权 Router（精确数量或无限授权）并等待打包
+- 使用 SwapParams.Signer 签名，amountOutMin 与 deadline 由合约强制校验
 */
 package core
 
@@ -35,6 +41,9 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
+// uniswapDefaultDeadline 未指定截止时间时的默认有效期
+const uniswapDefaultDeadline = 20 * time.Minute
+
 // UniswapV2Exchange Uniswap V2交易所实现
 type UniswapV2Exchange struct {
 	routerAddress  common.Address // Router合约地址
@@ -88,6 +97,21 @@ const uniswapV2RouterABI = `[
         "stateMutability": "nonpayable",
         "type": "function"
     },
+    {
+        "inputs": [
+            {"internalType": "uint256", "name": "amountIn", "type": "uint256"},
+            {"internalType": "uint256", "name": "amountOutMin", "type": "uint256"},
+            {"internalType": "address[]", "name": "path", "type": "address[]"},
+            {"internalType": "address", "name": "to", "type": "address"},
+            {"internalType": "uint256", "name": "deadline", "type": "uint256"}
+        ],
+        "name": "swapExactTokensForETH",
+        "outputs": [
+            {"internalType": "uint256[]", "name": "amounts", "type": "uint256[]"}
+        ],
+        "stateMutability": "nonpayable",
+        "type": "function"
+    },
     {
         "inputs": [
             {"internalType": "address", "name": "tokenA", "type": "address"},
@@ -149,6 +173,18 @@ func NewUniswapV2Exchange(evmAdapter *EVMAdapter, network string) (*UniswapV2Exc
 		routerAddr = "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D"
 		factoryAddr = "0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f"
 		wethAddr = "0xB4FBF271143F4FBf7B91A5ded31805e42b2208d6"
+	case "sepolia":
+		routerAddr = "0xeE567Fe1712Faf6149d80dA1E6934E354124CfE3"
+		factoryAddr = "0xF62c03E08ada871A0bEb309762E260a7a6a880E6"
+		wethAddr = "0xfFf9976782d46CC05630D1f6eBAb18b2324d6B14"
+	case "polygon":
+		routerAddr = "0xedf6066a2b290C185783862C7F4776A2C8077AD1"
+		factoryAddr = "0x9e5A52f57b3038F1B8EeE45F28b3C1967e22799C"
+		wethAddr = "0x0d500B1d8E8eF31E21C99d1Db9A6444d3ADf1270" // WMATIC
+	case "bsc":
+		routerAddr = "0x4752ba5DBc23f44D87826276BF6Fd6b1C372aD24"
+		factoryAddr = "0x8909Dc15e40173Ff4699343b6eB8132c65e18eC6"
+		wethAddr = "0xbb4CdB9CBd36B01bD1cBbEBe0Dc08d9A5e96095c" // WBNB
 	default:
 		return nil, fmt.Errorf("unsupported network: %s", network)
 	}
@@ -346,12 +382,45 @@ func (u *UniswapV2Exchange) GetQuote(tokenIn, tokenOut string, amountIn *big.Int
 
 // ExecuteSwap 执行交易
 func (u *UniswapV2Exchange) ExecuteSwap(ctx context.Context, params *SwapParams) (*SwapResult, error) {
+	if params.Signer == nil {
+		return nil, fmt.Errorf("missing signer")
+	}
+	if params.AmountIn == nil || params.AmountIn.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid amount in")
+	}
+	if params.AmountOutMin == nil || params.AmountOutMin.Sign() <= 0 {
+		return nil, fmt.Errorf("amountOutMin must be positive")
+	}
+	if params.Deadline == 0 {
+		params.Deadline = time.Now().Add(uniswapDefaultDeadline).Unix()
+	} else if params.Deadline <= time.Now().Unix() {
+		return nil, fmt.Errorf("swap deadline has passed")
+	}
+	if params.Recipient == "" {
+		params.Recipient = params.Signer.Address().Hex()
+	} else if !common.IsHexAddress(params.Recipient) {
+		return nil, fmt.Errorf("invalid recipient: %s", params.Recipient)
+	}
+
 	// 构建交易路径
 	path := u.buildTradingPath(params.TokenIn, params.TokenOut)
+	if path[0] == path[len(path)-1] {
+		return nil, fmt.Errorf("token in and token out are the same")
+	}
 
 	var txHash string
 	var err error
 
+	// 代币输入需要 Router 有足够授权额度
+	var approval *ApprovalResult
+	if !u.isETH(params.TokenIn) {
+		approval, err = u.evmAdapter.EnsureAllowance(ctx, params.TokenIn, params.Signer.Address().Hex(),
+			u.routerAddress.Hex(), params.AmountIn, params.Signer, params.InfiniteApproval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to ensure allowance: %w", err)
+		}
+	}
+
 	// 判断是否为ETH交易
 	if u.isETH(params.TokenIn) {
 		// ETH -> Token
@@ -372,9 +441,11 @@ func (u *UniswapV2Exchange) ExecuteSwap(ctx context.Context, params *SwapParams)
 		TxHash:    txHash,
 		AmountIn:  params.AmountIn,
 		AmountOut: new(big.Int).Set(params.AmountOutMin), // 实际数量需要从交易receipt获取
+		GasPrice:  params.GasPrice,
 		Status:    "pending",
 		Timestamp: getCurrentTimestamp(),
 		Exchange:  u.GetName(),
+		Approval:  approval,
 	}, nil
 }
 
@@ -409,10 +480,10 @@ func (u *UniswapV2Exchange) AddLiquidity(ctx context.Context, params *AddLiquidi
 	}, nil
 }
 
-// buildTradingPath 构建交易路径
+// buildTradingPath 构建交易路径（原生币以 WETH 代替）
 func (u *UniswapV2Exchange) buildTradingPath(tokenIn, tokenOut string) []common.Address {
-	tokenInAddr := common.HexToAddress(tokenIn)
-	tokenOutAddr := common.HexToAddress(tokenOut)
+	tokenInAddr := u.pathAddress(tokenIn)
+	tokenOutAddr := u.pathAddress(tokenOut)
 
 	// 如果其中一个是WETH，直接交易
 	if tokenInAddr == u.wethAddress || tokenOutAddr == u.wethAddress {
@@ -469,23 +540,47 @@ func (u *UniswapV2Exchange) isETH(tokenAddress string) bool {
 		strings.EqualFold(tokenAddress, "ETH")
 }
 
+// pathAddress 交易路径中的代币地址，原生币映射为 WETH
+func (u *UniswapV2Exchange) pathAddress(token string) common.Address {
+	if u.isETH(token) {
+		return u.wethAddress
+	}
+	return common.HexToAddress(token)
+}
+
 // swapETHForTokens ETH换代币
 func (u *UniswapV2Exchange) swapETHForTokens(ctx context.Context, params *SwapParams, path []common.Address) (string, error) {
-	// 实现ETH换代币的具体逻辑
-	// 需要调用router的swapExactETHForTokens方法
-	return "0x...", nil // 返回实际的交易哈希
+	data, err := u.routerABI.Pack("swapExactETHForTokens",
+		params.AmountOutMin, path, common.HexToAddress(params.Recipient), big.NewInt(params.Deadline))
+	if err != nil {
+		return "", fmt.Errorf("failed to pack swapExactETHForTokens call: %w", err)
+	}
+	return u.sendRouterTx(ctx, params, params.AmountIn, data)
 }
 
 // swapTokensForETH 代币换ETH
 func (u *UniswapV2Exchange) swapTokensForETH(ctx context.Context, params *SwapParams, path []common.Address) (string, error) {
-	// 实现代币换ETH的具体逻辑
-	return "0x...", nil
+	data, err := u.routerABI.Pack("swapExactTokensForETH",
+		params.AmountIn, params.AmountOutMin, path, common.HexToAddress(params.Recipient), big.NewInt(params.Deadline))
+	if err != nil {
+		return "", fmt.Errorf("failed to pack swapExactTokensForETH call: %w", err)
+	}
+	return u.sendRouterTx(ctx, params, nil, data)
 }
 
 // swapTokensForTokens 代币换代币
 func (u *UniswapV2Exchange) swapTokensForTokens(ctx context.Context, params *SwapParams, path []common.Address) (string, error) {
-	// 实现代币换代币的具体逻辑
-	return "0x...", nil
+	data, err := u.routerABI.Pack("swapExactTokensForTokens",
+		params.AmountIn, params.AmountOutMin, path, common.HexToAddress(params.Recipient), big.NewInt(params.Deadline))
+	if err != nil {
+		return "", fmt.Errorf("failed to pack swapExactTokensForTokens call: %w", err)
+	}
+	return u.sendRouterTx(ctx, params, nil, data)
+}
+
+// sendRouterTx 签名并发送 Router 调用
+func (u *UniswapV2Exchange) sendRouterTx(ctx context.Context, params *SwapParams, value *big.Int, data []byte) (string, error) {
+	return u.evmAdapter.sendWithSigner(ctx, params.Signer, u.routerAddress, value, data, &TxOptions{GasPrice: params.GasPrice, AllowHighFee: params.AllowHighFee})
 }
 
 // getCurrentTimestamp 获取当前时间戳
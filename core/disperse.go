@@ -0,0 +1,280 @@
+/*
+代币分发（空投）
+
+向多个地址分发同一种 ERC20 代币，每个接收方单独指定金额（按权重分配），或由 EqualSplit 将总额平均分配：
+  - contract：网络配置了 Disperse 合约（disperse_address）时，一笔 disperseToken(token, recipients, values) 交易完成全部转账，
+    比逐笔转账节省Gas；合约通过 transferFrom 扣款，需事先授权该合约不少于分发总额的额度
+  - batch：按 SendBatch 逐笔转账（nonce 连续分配），某一笔失败时之后的转账不再发送
+  - auto（默认）：配置了 Disperse 合约且授权额度足够时使用 contract，否则使用 batch
+
+发送前校验余额（及 contract 方式的授权额度）不少于分发总额；发送后等待回执汇总实际消耗的Gas，
+超时未确认的交易标记为 pending，其Gas不计入汇总。
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const disperseABI = `[{"inputs":[{"name":"token","type":"address"},{"name":"recipients","type":"address[]"},{"name":"values","type":"uint256[]"}],"name":"disperseToken","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
+
+// 分发方式
+const (
+	DisperseModeAuto     = "auto"
+	DisperseModeContract = "contract"
+	DisperseModeBatch    = "batch"
+)
+
+// 分发中单个接收方的状态
+const (
+	DisperseStatusSuccess  = "success"  // 交易已确认且执行成功
+	DisperseStatusReverted = "reverted" // 交易已确认但执行失败
+	DisperseStatusPending  = "pending"  // 已广播，等待回执超时
+)
+
+// disperseReceiptTimeout 发送后等待回执的最长时间
+const disperseReceiptTimeout = 2 * time.Minute
+
+// Recipient 分发的接收方
+type Recipient struct {
+	Address string
+	Amount  *big.Int // 最小单位
+}
+
+// DisperseRecipientResult 单个接收方的分发结果
+type DisperseRecipientResult struct {
+	Address string `json:"address"`
+	Amount  string `json:"amount"`
+	Status  string `json:"status"` // success / reverted / pending / failed / skipped
+	TxHash  string `json:"tx_hash,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// DisperseResult 分发汇总
+type DisperseResult struct {
+	Token        string                    `json:"token"`
+	Method       string                    `json:"method"` // contract / batch
+	Contract     string                    `json:"contract,omitempty"`
+	Total        string                    `json:"total"`      // 分发总额
+	TotalSent    string                    `json:"total_sent"` // 已确认成功的总额
+	Recipients   []DisperseRecipientResult `json:"recipients"`
+	Transactions []string                  `json:"transactions"` // 已广播的交易
+	GasUsed      uint64                    `json:"gas_used"`     // 已确认交易消耗的Gas合计
+	Confirmed    bool                      `json:"confirmed"`    // 已广播的交易是否全部确认
+}
+
+// SetDisperseAddress 设置 Disperse 合约地址，为空时分发只能逐笔转账
+func (a *EVMAdapter) SetDisperseAddress(address string) {
+	if address == "" || !common.IsHexAddress(address) {
+		a.disperse = nil
+		return
+	}
+	addr := common.HexToAddress(address)
+	a.disperse = &addr
+}
+
+// DisperseAddress 返回已配置的 Disperse 合约地址（未配置时为空字符串）
+func (a *EVMAdapter) DisperseAddress() string {
+	if a.disperse == nil {
+		return ""
+	}
+	return a.disperse.Hex()
+}
+
+// EqualSplit 将总额平均分配给各地址，不能整除的余数从第一个地址起每个多分 1 个最小单位
+func EqualSplit(total *big.Int, addresses []string) ([]Recipient, error) {
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("接收地址不能为空")
+	}
+	if total == nil || total.Sign() <= 0 {
+		return nil, fmt.Errorf("分发总额必须大于0")
+	}
+	share, remainder := new(big.Int).QuoRem(total, big.NewInt(int64(len(addresses))), new(big.Int))
+	recipients := make([]Recipient, len(addresses))
+	for i, address := range addresses {
+		amount := new(big.Int).Set(share)
+		if big.NewInt(int64(i)).Cmp(remainder) < 0 {
+			amount.Add(amount, big.NewInt(1))
+		}
+		recipients[i] = Recipient{Address: address, Amount: amount}
+	}
+	return recipients, nil
+}
+
+// DisperseTokens 向多个接收方分发 ERC20 代币，mode 为 auto/contract/batch（为空按 auto）
+// 参数或余额/授权校验失败时不发送任何交易并返回错误
+func (a *EVMAdapter) DisperseTokens(ctx context.Context, signer Signer, token string, recipients []Recipient, mode string, opts *TxOptions) (*DisperseResult, error) {
+	if !common.IsHexAddress(token) {
+		return nil, fmt.Errorf("代币地址格式不正确: %s", token)
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("接收方不能为空")
+	}
+	if len(recipients) > MaxBatchTransfers {
+		return nil, fmt.Errorf("单次最多分发给 %d 个地址", MaxBatchTransfers)
+	}
+	if mode == "" {
+		mode = DisperseModeAuto
+	}
+	if mode != DisperseModeAuto && mode != DisperseModeContract && mode != DisperseModeBatch {
+		return nil, fmt.Errorf("不支持的分发方式: %s（支持 auto/contract/batch）", mode)
+	}
+	total := new(big.Int)
+	for i, recipient := range recipients {
+		if !common.IsHexAddress(recipient.Address) {
+			return nil, fmt.Errorf("第 %d 个接收地址格式不正确: %s", i+1, recipient.Address)
+		}
+		if recipient.Amount == nil || recipient.Amount.Sign() <= 0 {
+			return nil, fmt.Errorf("第 %d 个接收方的金额必须大于0", i+1)
+		}
+		total.Add(total, recipient.Amount)
+	}
+
+	tokenAddr := common.HexToAddress(token).Hex()
+	owner := signer.Address().Hex()
+	balance, err := a.GetERC20Balance(ctx, tokenAddr, owner)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Cmp(total) < 0 {
+		return nil, fmt.Errorf("代币余额不足：分发总额 %s，当前余额 %s", total.String(), balance.String())
+	}
+
+	useContract := false
+	if mode != DisperseModeBatch {
+		if a.disperse == nil {
+			if mode == DisperseModeContract {
+				return nil, fmt.Errorf("当前网络未配置 Disperse 合约")
+			}
+		} else {
+			allowance, err := a.GetAllowance(ctx, tokenAddr, owner, a.disperse.Hex())
+			if err != nil {
+				return nil, err
+			}
+			useContract = allowance.Cmp(total) >= 0
+			if !useContract && mode == DisperseModeContract {
+				return nil, fmt.Errorf("授权额度不足：需授权 Disperse 合约 %s 至少 %s，当前 %s", a.disperse.Hex(), total.String(), allowance.String())
+			}
+		}
+	}
+
+	result := &DisperseResult{
+		Token:        tokenAddr,
+		Total:        total.String(),
+		Recipients:   make([]DisperseRecipientResult, len(recipients)),
+		Transactions: []string{},
+	}
+	for i, recipient := range recipients {
+		result.Recipients[i] = DisperseRecipientResult{
+			Address: common.HexToAddress(recipient.Address).Hex(),
+			Amount:  recipient.Amount.String(),
+		}
+	}
+	if useContract {
+		err = a.disperseWithContract(ctx, signer, tokenAddr, recipients, opts, result)
+	} else {
+		err = a.disperseWithBatch(ctx, signer, tokenAddr, recipients, opts, result)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a.collectDisperseReceipts(ctx, recipients, result)
+	return result, nil
+}
+
+// disperseWithContract 一笔 disperseToken 交易完成全部转账
+func (a *EVMAdapter) disperseWithContract(ctx context.Context, signer Signer, token string, recipients []Recipient, opts *TxOptions, result *DisperseResult) error {
+	parsed, err := abi.JSON(strings.NewReader(disperseABI))
+	if err != nil {
+		return err
+	}
+	addresses := make([]common.Address, len(recipients))
+	values := make([]*big.Int, len(recipients))
+	for i, recipient := range recipients {
+		addresses[i] = common.HexToAddress(recipient.Address)
+		values[i] = recipient.Amount
+	}
+	data, err := parsed.Pack("disperseToken", common.HexToAddress(token), addresses, values)
+	if err != nil {
+		return fmt.Errorf("打包 disperseToken 数据失败: %w", err)
+	}
+	txHash, err := a.sendWithSigner(ctx, signer, *a.disperse, big.NewInt(0), data, opts)
+	if err != nil {
+		return err
+	}
+	result.Method = DisperseModeContract
+	result.Contract = a.disperse.Hex()
+	result.Transactions = append(result.Transactions, txHash)
+	for i := range result.Recipients {
+		result.Recipients[i].TxHash = txHash
+	}
+	return nil
+}
+
+// disperseWithBatch 逐笔转账，失败与跳过的接收方记录在结果中
+func (a *EVMAdapter) disperseWithBatch(ctx context.Context, signer Signer, token string, recipients []Recipient, opts *TxOptions, result *DisperseResult) error {
+	transfers := make([]Transfer, len(recipients))
+	for i, recipient := range recipients {
+		transfers[i] = Transfer{To: recipient.Address, Token: token, Amount: recipient.Amount}
+	}
+	sent, err := a.SendBatch(ctx, signer, transfers, opts)
+	if err != nil {
+		return err
+	}
+	result.Method = DisperseModeBatch
+	for i, item := range sent {
+		result.Recipients[i].TxHash = item.TxHash
+		result.Recipients[i].Error = item.Error
+		if item.Status != BatchStatusSent {
+			result.Recipients[i].Status = item.Status
+			continue
+		}
+		result.Transactions = append(result.Transactions, item.TxHash)
+	}
+	return nil
+}
+
+// collectDisperseReceipts 等待已广播交易的回执，更新接收方状态、成功总额与Gas合计
+func (a *EVMAdapter) collectDisperseReceipts(ctx context.Context, recipients []Recipient, result *DisperseResult) {
+	waitCtx, cancel := context.WithTimeout(ctx, disperseReceiptTimeout)
+	defer cancel()
+
+	receipts := make(map[string]*types.Receipt, len(result.Transactions))
+	for _, txHash := range result.Transactions {
+		receipt, err := a.WaitForReceipt(waitCtx, txHash, 0)
+		if err != nil {
+			continue
+		}
+		receipts[txHash] = receipt
+		result.GasUsed += receipt.GasUsed
+	}
+	result.Confirmed = len(receipts) == len(result.Transactions)
+
+	totalSent := new(big.Int)
+	for i := range result.Recipients {
+		item := &result.Recipients[i]
+		if item.TxHash == "" || item.Status != "" {
+			continue
+		}
+		receipt, ok := receipts[item.TxHash]
+		switch {
+		case !ok:
+			item.Status = DisperseStatusPending
+		case receipt.Status == types.ReceiptStatusSuccessful:
+			item.Status = DisperseStatusSuccess
+			totalSent.Add(totalSent, recipients[i].Amount)
+		default:
+			item.Status = DisperseStatusReverted
+		}
+	}
+	result.TotalSent = totalSent.String()
+}
@@ -0,0 +1,273 @@
+/*
+ENS 域名解析（基于链上 ENS Registry）
+
+正向解析：namehash(name) → registry.resolver(node) → resolver.addr(node)
+反向解析：namehash("<addr小写去0x>.addr.reverse") → registry.resolver(node) → resolver.name(node)，
+再对得到的域名做一次正向解析，结果必须指回原地址，否则视为未设置主域名（防止伪造反向记录）。
+文本记录（avatar、description 等）按 ENSIP-5 通过 resolver.text(node, key) 读取，读取失败时忽略。
+avatar 按 ENSIP-12 解析：ipfs:// / ipns:// / data: / https 地址直接使用；eip155:<chainId>/erc721:<合约>/<tokenId>
+（或 erc1155）读取该NFT元数据中的图片，且要求NFT仍由域名绑定的地址持有，否则视为未设置头像。
+
+Registry 在以太坊主网与主要测试网部署于同一地址。
+域名仅做小写与首尾空白处理，未实现完整的 ENSIP-15 规范化。
+*/
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ENSRegistryAddress ENS Registry（带 fallback）合约地址
+var ENSRegistryAddress = common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+// ErrENSNotFound 域名未注册、未设置解析器或未绑定地址
+var ErrENSNotFound = errors.New("ENS记录不存在")
+
+const ensRegistryABI = `[
+	{"inputs":[{"name":"node","type":"bytes32"}],"name":"resolver","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"}
+]`
+
+const ensResolverABI = `[
+	{"inputs":[{"name":"node","type":"bytes32"}],"name":"addr","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"node","type":"bytes32"}],"name":"name","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"node","type":"bytes32"},{"name":"key","type":"string"}],"name":"text","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"}
+]`
+
+var (
+	ensRegistryParsed = mustParseABI(ensRegistryABI)
+	ensResolverParsed = mustParseABI(ensResolverABI)
+)
+
+// mustParseABI 解析内置 ABI 常量，格式错误属于编码错误
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(fmt.Sprintf("解析内置ABI失败: %v", err))
+	}
+	return parsed
+}
+
+// NormalizeENSName 规范化ENS域名（小写、去除首尾空白）
+func NormalizeENSName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// ENSNamehash 按 EIP-137 计算域名的 namehash
+func ENSNamehash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		labelHash := crypto.Keccak256([]byte(labels[i]))
+		node = crypto.Keccak256Hash(node.Bytes(), labelHash)
+	}
+	return node
+}
+
+// ENSResolveName 正向解析ENS域名为地址
+func (a *EVMAdapter) ENSResolveName(ctx context.Context, name string) (common.Address, error) {
+	name = NormalizeENSName(name)
+	if name == "" || !strings.Contains(name, ".") {
+		return common.Address{}, fmt.Errorf("无效的ENS域名: %q", name)
+	}
+	node := ENSNamehash(name)
+	resolver, err := a.ensResolverOf(ctx, node)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if resolver == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("%w: %s 未设置解析器", ErrENSNotFound, name)
+	}
+
+	out, err := a.callView(ctx, resolver, ensResolverParsed, "addr", node)
+	if err != nil {
+		return common.Address{}, err
+	}
+	addr, ok := out[0].(common.Address)
+	if !ok {
+		return common.Address{}, fmt.Errorf("addr 返回类型异常")
+	}
+	if addr == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("%w: %s 未绑定地址", ErrENSNotFound, name)
+	}
+	return addr, nil
+}
+
+// ENSLookupAddress 反向解析地址的主域名，并正向校验域名确实指回该地址
+func (a *EVMAdapter) ENSLookupAddress(ctx context.Context, address string) (string, error) {
+	if !common.IsHexAddress(address) {
+		return "", fmt.Errorf("无效的地址格式: %s", address)
+	}
+	addr := common.HexToAddress(address)
+	node := ENSNamehash(strings.ToLower(strings.TrimPrefix(addr.Hex(), "0x")) + ".addr.reverse")
+	resolver, err := a.ensResolverOf(ctx, node)
+	if err != nil {
+		return "", err
+	}
+	if resolver == (common.Address{}) {
+		return "", fmt.Errorf("%w: %s 未设置反向记录", ErrENSNotFound, addr.Hex())
+	}
+
+	out, err := a.callView(ctx, resolver, ensResolverParsed, "name", node)
+	if err != nil {
+		return "", err
+	}
+	name, _ := out[0].(string)
+	if name == "" {
+		return "", fmt.Errorf("%w: %s 未设置反向记录", ErrENSNotFound, addr.Hex())
+	}
+
+	forward, err := a.ENSResolveName(ctx, name)
+	if err != nil || forward != addr {
+		return "", fmt.Errorf("%w: %s 的反向记录 %s 未指回该地址", ErrENSNotFound, addr.Hex(), name)
+	}
+	return NormalizeENSName(name), nil
+}
+
+// ENSText 读取域名的文本记录（ENSIP-5），未设置时返回空字符串
+func (a *EVMAdapter) ENSText(ctx context.Context, name, key string) (string, error) {
+	node := ENSNamehash(NormalizeENSName(name))
+	resolver, err := a.ensResolverOf(ctx, node)
+	if err != nil {
+		return "", err
+	}
+	if resolver == (common.Address{}) {
+		return "", nil
+	}
+	out, err := a.callView(ctx, resolver, ensResolverParsed, "text", node, key)
+	if err != nil {
+		return "", err
+	}
+	text, _ := out[0].(string)
+	return text, nil
+}
+
+// ENSAvatarURI 将 avatar 文本记录解析为可获取的图片地址（可能是 ipfs:// 等，需经 URIResolver 获取）
+// NFT 头像要求在当前链上且由 owner 持有
+func (a *EVMAdapter) ENSAvatarURI(ctx context.Context, avatar string, owner common.Address, resolver *URIResolver) (string, error) {
+	avatar = strings.TrimSpace(avatar)
+	if !strings.HasPrefix(strings.ToLower(avatar), "eip155:") {
+		return avatar, nil
+	}
+
+	// eip155:1/erc721:0x.../123
+	parts := strings.Split(avatar, "/")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("无效的NFT头像记录: %s", avatar)
+	}
+	chainID, ok := new(big.Int).SetString(strings.TrimPrefix(strings.ToLower(parts[0]), "eip155:"), 10)
+	if !ok {
+		return "", fmt.Errorf("无效的NFT头像链ID: %s", parts[0])
+	}
+	namespace, contractHex, found := strings.Cut(strings.ToLower(parts[1]), ":")
+	if !found || !common.IsHexAddress(contractHex) {
+		return "", fmt.Errorf("无效的NFT头像合约: %s", parts[1])
+	}
+	tokenID, ok := new(big.Int).SetString(parts[2], 10)
+	if !ok {
+		return "", fmt.Errorf("无效的NFT头像tokenId: %s", parts[2])
+	}
+	contract := common.HexToAddress(contractHex)
+
+	current, err := a.DetectChainID(ctx)
+	if err != nil {
+		return "", err
+	}
+	if current.Cmp(chainID) != 0 {
+		return "", fmt.Errorf("NFT头像位于链 %s，当前链为 %s", chainID, current)
+	}
+
+	var standard string
+	switch namespace {
+	case "erc721":
+		standard = NFTStandardERC721
+		out, err := a.callView(ctx, contract, parsedNFTOwnershipABI, "ownerOf", tokenID)
+		if err != nil {
+			return "", err
+		}
+		if holder, _ := out[0].(common.Address); holder != owner {
+			return "", fmt.Errorf("NFT头像不属于 %s", owner.Hex())
+		}
+	case "erc1155":
+		standard = NFTStandardERC1155
+		out, err := a.callView(ctx, contract, parsedNFT1155OwnershipABI, "balanceOf", owner, tokenID)
+		if err != nil {
+			return "", err
+		}
+		if balance, _ := out[0].(*big.Int); balance == nil || balance.Sign() == 0 {
+			return "", fmt.Errorf("NFT头像不属于 %s", owner.Hex())
+		}
+	default:
+		return "", fmt.Errorf("不支持的NFT头像标准: %s", namespace)
+	}
+
+	tokenURI, err := a.nftTokenURI(ctx, contract, standard, tokenID)
+	if err != nil {
+		return "", err
+	}
+	body, _, err := resolver.Fetch(ctx, tokenURI)
+	if err != nil {
+		return "", fmt.Errorf("获取NFT头像元数据失败: %w", err)
+	}
+	var metadata struct {
+		Image     string `json:"image"`
+		ImageURL  string `json:"image_url"`
+		ImageData string `json:"image_data"`
+	}
+	if err := json.Unmarshal(body, &metadata); err != nil {
+		return "", fmt.Errorf("解析NFT头像元数据失败: %w", err)
+	}
+	switch {
+	case metadata.Image != "":
+		return metadata.Image, nil
+	case metadata.ImageURL != "":
+		return metadata.ImageURL, nil
+	case metadata.ImageData != "":
+		// image_data 为原始SVG
+		return "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(metadata.ImageData)), nil
+	}
+	return "", fmt.Errorf("NFT头像元数据中没有图片")
+}
+
+// ensResolverOf 查询节点在 Registry 中登记的解析器地址
+func (a *EVMAdapter) ensResolverOf(ctx context.Context, node common.Hash) (common.Address, error) {
+	out, err := a.callView(ctx, ENSRegistryAddress, ensRegistryParsed, "resolver", node)
+	if err != nil {
+		return common.Address{}, err
+	}
+	resolver, ok := out[0].(common.Address)
+	if !ok {
+		return common.Address{}, fmt.Errorf("resolver 返回类型异常")
+	}
+	return resolver, nil
+}
+
+// callView 调用合约的只读方法并解包结果
+func (a *EVMAdapter) callView(ctx context.Context, contract common.Address, parsed abi.ABI, method string, args ...interface{}) ([]interface{}, error) {
+	data, err := parsed.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("打包%s数据失败: %w", method, err)
+	}
+	raw, err := a.client.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("调用合约方法 %s 失败: %w", method, err)
+	}
+	out, err := parsed.Unpack(method, raw)
+	if err != nil || len(out) == 0 {
+		return nil, fmt.Errorf("解析 %s 返回结果失败: %v", method, err)
+	}
+	return out, nil
+}
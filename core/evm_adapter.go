@@ -28,12 +28,16 @@ package core
 import (
 	"context"
 	"encoding/hex"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math/big"
+	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"wallet/config"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -41,14 +45,28 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
-	apitypes "github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
 )
 
 // EVMAdapter EVM区块链适配器
 // 封装了与以太坊及其他EVM兼容链的交互功能
 // 通过RPC连接到区块链节点，提供统一的API接口
 type EVMAdapter struct {
-	client *ethclient.Client // 以太坊客户端，用于与区块链节点通信
+	client          *instrumentedClient                                     // 以太坊客户端（记录调用指标），用于与区块链节点通信
+	historyBatch    *adaptiveBatchSizer                                     // 历史扫描批次大小（按节点表现自适应）
+	multicall       *common.Address                                         // Multicall3 合约地址，为空时批量调用回退为逐个调用
+	disperse        *common.Address                                         // Disperse 合约地址，为空时代币分发逐笔转账
+	confirmations   uint64                                                  // 交易视为最终确认所需的确认数
+	explorerAPI     string                                                  // Etherscan 风格的区块浏览器API地址，为空时原生交易历史回退为区块扫描
+	wsURL           string                                                  // 节点 WebSocket 地址，用于订阅新区块，为空时不支持实时推送
+	expectedChainID *big.Int                                                // 网络配置声明的链ID，签名前与节点链ID比对，为空时不校验
+	rpcURL          string                                                  // 主RPC地址
+	rpcPool         *rpcPool                                                // 多节点故障转移池，仅配置了备用 http(s) 节点时启用
+	feeCeiling      func(ctx context.Context, from common.Address) *big.Int // 默认手续费上限（wei），为空时不限制
+	historyStore    HistoryStore                                            // 交易历史存储，为空时每次查询重新扫描（见 history_cache.go）
+	historyNetwork  string                                                  // historyStore 中使用的网络ID
+	dial            DialContextFunc                                         // 节点连接的拨号函数，为空时使用默认传输层（自定义网络见 SafeDialer）
 }
 
 // NewEVMAdapter 创建新的EVM适配器实例
@@ -61,7 +79,81 @@ func NewEVMAdapter(rpcURL string) (*EVMAdapter, error) {
 	if err != nil {
 		return nil, fmt.Errorf("连接以太坊节点失败: %w", err)
 	}
-	return &EVMAdapter{client: c}, nil
+	return &EVMAdapter{client: newInstrumentedClient(c), historyBatch: newAdaptiveBatchSizer(config.AppConfig.History), rpcURL: rpcURL}, nil
+}
+
+// NewEVMAdapterWithFallbacks 创建带备用节点的EVM适配器
+// 主节点与备用节点均为 http(s) 地址时启用节点池：请求失败自动切换节点，并在后台定期健康检查；
+// 否则（无备用节点或主节点为 ws 地址）等同于 NewEVMAdapter
+func NewEVMAdapterWithFallbacks(rpcURL string, fallbackURLs []string) (*EVMAdapter, error) {
+	return newEVMAdapter(rpcURL, fallbackURLs, nil)
+}
+
+// newEVMAdapter 创建带备用节点的EVM适配器，dial 不为空时所有节点连接（含节点池与新区块订阅）经其建立
+func newEVMAdapter(rpcURL string, fallbackURLs []string, dial DialContextFunc) (*EVMAdapter, error) {
+	urls := rpcEndpointURLs(rpcURL, fallbackURLs)
+	if len(urls) <= 1 || !isHTTPURL(rpcURL) {
+		if dial == nil {
+			return NewEVMAdapter(rpcURL)
+		}
+		rc, err := rpc.DialOptions(context.Background(), rpcURL, rpcDialOptions(dial)...)
+		if err != nil {
+			return nil, fmt.Errorf("连接以太坊节点失败: %w", err)
+		}
+		return &EVMAdapter{client: newInstrumentedClient(ethclient.NewClient(rc)), historyBatch: newAdaptiveBatchSizer(config.AppConfig.History), rpcURL: rpcURL, dial: dial}, nil
+	}
+	pool, err := newRPCPool(urls, config.AppConfig.RPCPool)
+	if err != nil {
+		return nil, err
+	}
+	if dial != nil {
+		pool.base = newDialTransport(dial)
+	}
+	rc, err := rpc.DialOptions(context.Background(), rpcURL, rpc.WithHTTPClient(&http.Client{Transport: pool}))
+	if err != nil {
+		return nil, fmt.Errorf("连接以太坊节点失败: %w", err)
+	}
+	pool.start()
+	return &EVMAdapter{
+		client:       newInstrumentedClient(ethclient.NewClient(rc)),
+		historyBatch: newAdaptiveBatchSizer(config.AppConfig.History),
+		rpcURL:       rpcURL,
+		rpcPool:      pool,
+		dial:         dial,
+	}, nil
+}
+
+// rpcDialOptions 经 dial 建立 http(s) 与 ws(s) 连接的客户端选项，dial 为空时使用默认传输层
+func rpcDialOptions(dial DialContextFunc) []rpc.ClientOption {
+	if dial == nil {
+		return nil
+	}
+	return []rpc.ClientOption{
+		rpc.WithHTTPClient(&http.Client{Transport: newDialTransport(dial)}),
+		rpc.WithWebsocketDialer(websocket.Dialer{NetDialContext: dial, HandshakeTimeout: 45 * time.Second}),
+	}
+}
+
+// Close 关闭节点连接并停止后台健康检查
+func (a *EVMAdapter) Close() {
+	if a.rpcPool != nil {
+		a.rpcPool.close()
+	}
+	a.client.Close()
+}
+
+// RPCHealth 返回各RPC节点状态；启用节点池时立即检查一次所有节点
+func (a *EVMAdapter) RPCHealth(ctx context.Context) []RPCEndpointStatus {
+	if a.rpcPool == nil {
+		return singleEndpointStatus(ctx, a, a.rpcURL)
+	}
+	a.rpcPool.checkAll(ctx)
+	return a.rpcPool.statuses()
+}
+
+// isHTTPURL 判断地址是否为 http(s) 地址
+func isHTTPURL(raw string) bool {
+	return strings.HasPrefix(raw, "http://") || strings.HasPrefix(raw, "https://")
 }
 
 // GetBalance 获取指定地址的原生代币余额
@@ -102,57 +194,135 @@ func (a *EVMAdapter) SendTransaction(ctx context.Context, from, to string, amoun
 // 返回: 交易哈希和错误信息
 // 注意: 该方法会自动估算Gas限制和价格，并等待短暂时间后返回
 func (a *EVMAdapter) SendETH(ctx context.Context, mnemonic, derivationPath, to string, valueWei *big.Int) (string, error) {
-	priv, fromAddr, err := DerivePrivateKeyFromMnemonic(mnemonic, derivationPath)
+	signer, err := NewMnemonicSigner(mnemonic, derivationPath)
 	if err != nil {
 		return "", err
 	}
+	return a.SendETHWithSigner(ctx, signer, to, valueWei, nil)
+}
 
-	chainID, err := a.client.NetworkID(ctx)
-	if err != nil {
-		return "", fmt.Errorf("获取链ID失败: %w", err)
-	}
+// SendETHWithSigner 使用指定签名者发送原生代币（opts 为空时使用 legacy 建议费率）
+func (a *EVMAdapter) SendETHWithSigner(ctx context.Context, signer Signer, to string, valueWei *big.Int, opts *TxOptions) (string, error) {
+	return a.sendWithSigner(ctx, signer, common.HexToAddress(to), valueWei, nil, opts)
+}
 
-	nonce, err := a.client.PendingNonceAt(ctx, fromAddr)
+// sendWithSigner 构建、签名并广播交易（自动识别 legacy/EIP-2930/EIP-1559）
+// opts 中未指定的 nonce、gasLimit 与费率从节点获取
+func (a *EVMAdapter) sendWithSigner(ctx context.Context, signer Signer, to common.Address, value *big.Int, data []byte, opts *TxOptions) (string, error) {
+	fromAddr := signer.Address()
+	// 签名前检测节点链ID，防止签出与所选网络不同链的交易
+	chainID, err := a.VerifyChainID(ctx)
 	if err != nil {
-		return "", fmt.Errorf("获取nonce失败: %w", err)
+		return "", err
+	}
+	if value == nil {
+		value = big.NewInt(0)
 	}
 
-	toAddr := common.HexToAddress(to)
+	// nonce
+	var nonce uint64
+	if opts != nil && opts.Nonce != nil {
+		nonce = *opts.Nonce
+	} else {
+		nonce, err = a.client.PendingNonceAt(ctx, fromAddr)
+		if err != nil {
+			return "", fmt.Errorf("获取nonce失败: %w", err)
+		}
+	}
 
-	// 估算 gas limit
-	msg := ethereum.CallMsg{
-		From:  fromAddr,
-		To:    &toAddr,
-		Value: valueWei,
-		Data:  nil,
+	// 访问列表（EIP-2930），见 access_list.go
+	var accessList types.AccessList
+	if opts != nil {
+		accessList = opts.AccessList
+		if len(accessList) == 0 && opts.AutoAccessList {
+			accessList = a.autoAccessList(ctx, fromAddr, to, value, data)
+		}
 	}
-	gasLimit, err := a.client.EstimateGas(ctx, msg)
-	if err != nil {
-		return "", fmt.Errorf("估算Gas失败: %w", err)
+
+	// gasLimit
+	gasLimit := uint64(0)
+	if opts != nil && opts.GasLimit > 0 {
+		gasLimit = opts.GasLimit
+	} else {
+		msg := ethereum.CallMsg{From: fromAddr, To: &to, Value: value, Data: data, AccessList: accessList}
+		gl, err := a.client.EstimateGas(ctx, msg)
+		if err != nil {
+			return "", fmt.Errorf("估算Gas失败: %w", err)
+		}
+		gasLimit = gl
 	}
 
-	// 建议 gas price（legacy 简化）
-	gasPrice, err := a.client.SuggestGasPrice(ctx)
-	if err != nil {
-		return "", fmt.Errorf("获取建议GasPrice失败: %w", err)
+	// 选择费率模式（指定了 EIP-1559 费率时使用动态费率交易）
+	var tx *types.Transaction
+	if opts != nil && (opts.TipCap != nil || opts.FeeCap != nil) {
+		tip := opts.TipCap
+		fee := opts.FeeCap
+		if tip == nil || fee == nil {
+			sug, err := a.GetGasSuggestion(ctx)
+			if err != nil {
+				return "", err
+			}
+			if tip == nil {
+				tip = sug.TipCap
+			}
+			if fee == nil {
+				fee = sug.MaxFee
+			}
+		}
+		tx = types.NewTx(&types.DynamicFeeTx{
+			ChainID:    chainID,
+			Nonce:      nonce,
+			To:         &to,
+			Value:      value,
+			Gas:        gasLimit,
+			GasFeeCap:  fee,
+			GasTipCap:  tip,
+			Data:       data,
+			AccessList: accessList,
+		})
+	} else {
+		gp := (*big.Int)(nil)
+		if opts != nil && opts.GasPrice != nil {
+			gp = opts.GasPrice
+		} else {
+			gp, err = a.client.SuggestGasPrice(ctx)
+			if err != nil {
+				return "", fmt.Errorf("获取建议GasPrice失败: %w", err)
+			}
+		}
+		if len(accessList) > 0 {
+			tx = types.NewTx(&types.AccessListTx{
+				ChainID:    chainID,
+				Nonce:      nonce,
+				To:         &to,
+				Value:      value,
+				Gas:        gasLimit,
+				GasPrice:   gp,
+				Data:       data,
+				AccessList: accessList,
+			})
+		} else {
+			tx = types.NewTransaction(nonce, to, value, gasLimit, gp, data)
+		}
 	}
 
-	// 构建与签名交易
-	tx := types.NewTransaction(nonce, toAddr, valueWei, gasLimit, gasPrice, nil)
-	signer := types.LatestSignerForChainID(chainID)
-	signedTx, err := types.SignTx(tx, signer, priv)
+	signedTx, err := a.signTx(ctx, signer, tx, chainID, opts)
 	if err != nil {
-		return "", fmt.Errorf("签名交易失败: %w", err)
+		return "", err
 	}
-
-	// 广播交易
 	if err := a.client.SendTransaction(ctx, signedTx); err != nil {
+		// 链不支持访问列表交易时以相同 nonce 回退为不带访问列表的交易（gasLimit 未指定时重新估算）
+		if tx.Type() == types.AccessListTxType && isAccessListUnsupported(err) {
+			slog.WarnContext(ctx, "node rejected EIP-2930 transaction, falling back to legacy", "error", err)
+			fallback := *opts
+			fallback.AccessList = nil
+			fallback.AutoAccessList = false
+			fallback.Nonce = &nonce
+			return a.sendWithSigner(ctx, signer, to, value, data, &fallback)
+		}
 		return "", fmt.Errorf("广播交易失败: %w", err)
 	}
-
-	// 可选：等待打包（简化为轻量等待/立即返回hash）
 	_ = a.waitBrief(ctx)
-
 	return signedTx.Hash().Hex(), nil
 }
 
@@ -168,6 +338,18 @@ func (a *EVMAdapter) CallContract(ctx context.Context, msg ethereum.CallMsg, blo
 	return a.client.CallContract(ctx, msg, blockNumber)
 }
 
+// GetCode 查询地址在最新区块的合约字节码（外部账户返回空）
+func (a *EVMAdapter) GetCode(ctx context.Context, address string) ([]byte, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("地址格式不正确: %s", address)
+	}
+	code, err := a.client.CodeAt(ctx, common.HexToAddress(address), nil)
+	if err != nil {
+		return nil, fmt.Errorf("查询合约代码失败: %w", err)
+	}
+	return code, nil
+}
+
 func (a *EVMAdapter) waitBrief(ctx context.Context) error {
 	// 简单小延迟，避免用户端立即查询不到
 	t := time.NewTimer(500 * time.Millisecond)
@@ -179,9 +361,22 @@ func (a *EVMAdapter) waitBrief(ctx context.Context) error {
 	}
 }
 
-const erc20ABI = `[{"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"type":"function"},{"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"type":"function"},{"constant":true,"inputs":[],"name":"name","outputs":[{"name":"","type":"string"}],"type":"function"},{"constant":true,"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"type":"function"},{"constant":false,"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"type":"function"}]`
+const erc20ABI = `[{"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"type":"function"},{"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"type":"function"},{"constant":true,"inputs":[],"name":"name","outputs":[{"name":"","type":"string"}],"type":"function"},{"constant":true,"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"type":"function"},{"constant":false,"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"type":"function"},{"constant":false,"inputs":[{"name":"spender","type":"address"},{"name":"value","type":"uint256"}],"name":"approve","outputs":[{"name":"","type":"bool"}],"type":"function"},{"constant":true,"inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"}],"name":"allowance","outputs":[{"name":"","type":"uint256"}],"type":"function"}]`
 
 func (a *EVMAdapter) GetERC20Balance(ctx context.Context, tokenAddress, ownerAddress string) (*big.Int, error) {
+	return a.erc20BalanceAt(ctx, tokenAddress, ownerAddress, nil)
+}
+
+// ErrStateUnavailable 节点不保留指定区块的状态（非归档节点查询历史状态时返回）
+var ErrStateUnavailable = errors.New("节点不保留该区块的状态数据，请使用归档节点")
+
+// GetERC20BalanceAtBlock 查询指定区块高度时的ERC20余额（需要节点保留该区块状态）
+func (a *EVMAdapter) GetERC20BalanceAtBlock(ctx context.Context, tokenAddress, ownerAddress string, blockNumber uint64) (*big.Int, error) {
+	return a.erc20BalanceAt(ctx, tokenAddress, ownerAddress, new(big.Int).SetUint64(blockNumber))
+}
+
+// erc20BalanceAt 调用 balanceOf，blockNumber 为 nil 时查询最新状态
+func (a *EVMAdapter) erc20BalanceAt(ctx context.Context, tokenAddress, ownerAddress string, blockNumber *big.Int) (*big.Int, error) {
 	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
 	if err != nil {
 		return nil, fmt.Errorf("解析ERC20 ABI失败: %w", err)
@@ -195,8 +390,11 @@ func (a *EVMAdapter) GetERC20Balance(ctx context.Context, tokenAddress, ownerAdd
 	}
 
 	call := ethereum.CallMsg{To: &token, Data: data}
-	out, err := a.client.CallContract(ctx, call, nil)
+	out, err := a.client.CallContract(ctx, call, blockNumber)
 	if err != nil {
+		if blockNumber != nil && isStateUnavailableError(err) {
+			return nil, fmt.Errorf("区块 %s: %w", blockNumber.String(), ErrStateUnavailable)
+		}
 		return nil, fmt.Errorf("调用合约失败: %w", err)
 	}
 
@@ -212,62 +410,44 @@ func (a *EVMAdapter) GetERC20Balance(ctx context.Context, tokenAddress, ownerAdd
 	return bal, nil
 }
 
+// isStateUnavailableError 判断节点错误是否表示历史状态已被裁剪
+// 不同客户端的错误文案不同（geth: missing trie node / historical state not available，erigon/nethermind 等类似）
+func isStateUnavailableError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, marker := range []string{
+		"missing trie node",
+		"historical state",
+		"state not available",
+		"state is not available",
+		"header not found",
+		"pruned",
+	} {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
 func (a *EVMAdapter) SendERC20(ctx context.Context, mnemonic, derivationPath, tokenAddress, toAddress string, amount *big.Int) (string, error) {
-	priv, fromAddr, err := DerivePrivateKeyFromMnemonic(mnemonic, derivationPath)
+	signer, err := NewMnemonicSigner(mnemonic, derivationPath)
 	if err != nil {
 		return "", err
 	}
-	chainID, err := a.client.NetworkID(ctx)
-	if err != nil {
-		return "", fmt.Errorf("获取链ID失败: %w", err)
-	}
-	nonce, err := a.client.PendingNonceAt(ctx, fromAddr)
-	if err != nil {
-		return "", fmt.Errorf("获取nonce失败: %w", err)
-	}
+	return a.SendERC20WithSigner(ctx, signer, tokenAddress, toAddress, amount, nil)
+}
 
+// SendERC20WithSigner 使用指定签名者发送 ERC20 转账（opts 为空时使用 legacy 建议费率）
+func (a *EVMAdapter) SendERC20WithSigner(ctx context.Context, signer Signer, tokenAddress, toAddress string, amount *big.Int, opts *TxOptions) (string, error) {
 	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
 	if err != nil {
 		return "", fmt.Errorf("解析ERC20 ABI失败: %w", err)
 	}
-	to := common.HexToAddress(toAddress)
-	token := common.HexToAddress(tokenAddress)
-	data, err := parsed.Pack("transfer", to, amount)
+	data, err := parsed.Pack("transfer", common.HexToAddress(toAddress), amount)
 	if err != nil {
 		return "", fmt.Errorf("打包transfer数据失败: %w", err)
 	}
-
-	// 估算 gas
-	msg := ethereum.CallMsg{
-		From: fromAddr,
-		To:   &token,
-		Data: data,
-	}
-	gasLimit, err := a.client.EstimateGas(ctx, msg)
-	if err != nil {
-		return "", fmt.Errorf("估算Gas失败: %w", err)
-	}
-
-	// legacy gas 简化
-	gasPrice, err := a.client.SuggestGasPrice(ctx)
-	if err != nil {
-		return "", fmt.Errorf("获取建议GasPrice失败: %w", err)
-	}
-
-	// 构建与签名交易（value=0，调用token合约）
-	tx := types.NewTransaction(nonce, token, big.NewInt(0), gasLimit, gasPrice, data)
-	signer := types.LatestSignerForChainID(chainID)
-	signedTx, err := types.SignTx(tx, signer, priv)
-	if err != nil {
-		return "", fmt.Errorf("签名交易失败: %w", err)
-	}
-
-	if err := a.client.SendTransaction(ctx, signedTx); err != nil {
-		return "", fmt.Errorf("广播交易失败: %w", err)
-	}
-
-	_ = a.waitBrief(ctx)
-	return signedTx.Hash().Hex(), nil
+	return a.SendContractCallWithSigner(ctx, signer, common.HexToAddress(tokenAddress), data, big.NewInt(0), opts)
 }
 
 // GasSuggestion EIP-1559/legacy 的 gas 建议
@@ -277,6 +457,16 @@ type GasSuggestion struct {
 	TipCap   *big.Int // 建议的 priority fee (maxPriorityFeePerGas)
 	MaxFee   *big.Int // 计算公式：tip + 2*baseFee（常见保守策略）
 	GasPrice *big.Int // legacy 模式的建议 gasPrice
+	// Tiers 基于 eth_feeHistory 的分档建议（slow/normal/fast），节点不支持 feeHistory 时为空
+	Tiers map[string]*GasTier
+	// BaseFeeTrend 近期 baseFee 走势：rising / falling / stable（仅 feeHistory 可用时）
+	BaseFeeTrend string
+}
+
+// GasTier 单档 EIP-1559 费用建议
+type GasTier struct {
+	TipCap *big.Int // maxPriorityFeePerGas
+	MaxFee *big.Int // maxFeePerGas
 }
 
 // GetNonces 获取地址的 nonce（latest 与 pending）
@@ -360,6 +550,9 @@ func (a *EVMAdapter) BroadcastRawTransaction(ctx context.Context, rawTxHex strin
 	if err := tx.UnmarshalBinary(b); err != nil {
 		return "", fmt.Errorf("解析原始交易失败: %w", err)
 	}
+	if err := a.checkRawTxChainID(tx); err != nil {
+		return "", err
+	}
 	if err := a.client.SendTransaction(ctx, tx); err != nil {
 		return "", fmt.Errorf("广播原始交易失败: %w", err)
 	}
@@ -526,8 +719,7 @@ func (a *EVMAdapter) PersonalSign(_ context.Context, mnemonic, derivationPath, m
 	if err != nil {
 		return "", "", err
 	}
-	prefix := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)
-	hash := crypto.Keccak256Hash([]byte(prefix))
+	hash := personalSignHash(message)
 	sig, err := crypto.Sign(hash.Bytes(), priv)
 	if err != nil {
 		return "", "", fmt.Errorf("签名失败: %w", err)
@@ -543,24 +735,10 @@ func (a *EVMAdapter) SignTypedDataV4(_ context.Context, mnemonic, derivationPath
 	if err != nil {
 		return "", "", err
 	}
-	var td apitypes.TypedData
-	if err := json.Unmarshal(typedJSON, &td); err != nil {
-		return "", "", fmt.Errorf("解析 typed data JSON 失败: %w", err)
-	}
-	// 计算 EIP-712 摘要: keccak256("\x19\x01" || domainSeparator || hashStruct(message))
-	domainSep, err := td.HashStruct("EIP712Domain", td.Domain.Map())
+	digest, err := typedDataDigest(typedJSON)
 	if err != nil {
-		return "", "", fmt.Errorf("计算domainSeparator失败: %w", err)
-	}
-	msgHash, err := td.HashStruct(td.PrimaryType, td.Message)
-	if err != nil {
-		return "", "", fmt.Errorf("计算message hash失败: %w", err)
+		return "", "", err
 	}
-	raw := make([]byte, 0, 2+len(domainSep)+len(msgHash))
-	raw = append(raw, 0x19, 0x01)
-	raw = append(raw, domainSep...)
-	raw = append(raw, msgHash...)
-	digest := crypto.Keccak256Hash(raw)
 
 	sig, err := crypto.Sign(digest.Bytes(), priv)
 	if err != nil {
@@ -577,311 +755,84 @@ type TxOptions struct {
 	FeeCap   *big.Int // EIP-1559 maxFeePerGas
 	GasLimit uint64   // 为 0 则自动估算
 	Nonce    *uint64  // 为空则自动获取 pending
+
+	MaxFeeWei    *big.Int // 本笔交易的手续费上限（wei），为空时使用默认上限
+	AllowHighFee bool     // 调用方已确认高手续费，跳过上限检查
+
+	AccessList     types.AccessList // EIP-2930 访问列表，未指定 EIP-1559 费率时发送 AccessListTx
+	AutoAccessList bool             // AccessList 为空时发送前通过 eth_createAccessList 自动生成（仅在节省 Gas 时使用）
 }
 
 // SendETHWithOptions 支持自定义 gas/nonce 的 ETH 发送（自动识别 legacy/EIP-1559）
 func (a *EVMAdapter) SendETHWithOptions(ctx context.Context, mnemonic, derivationPath, to string, valueWei *big.Int, opts *TxOptions) (string, error) {
-	priv, fromAddr, err := DerivePrivateKeyFromMnemonic(mnemonic, derivationPath)
+	signer, err := NewMnemonicSigner(mnemonic, derivationPath)
 	if err != nil {
 		return "", err
 	}
-	chainID, err := a.client.NetworkID(ctx)
-	if err != nil {
-		return "", fmt.Errorf("获取链ID失败: %w", err)
-	}
-	toAddr := common.HexToAddress(to)
-
-	// nonce
-	var nonce uint64
-	if opts != nil && opts.Nonce != nil {
-		nonce = *opts.Nonce
-	} else {
-		nonce, err = a.client.PendingNonceAt(ctx, fromAddr)
-		if err != nil {
-			return "", fmt.Errorf("获取nonce失败: %w", err)
-		}
-	}
-
-	// gasLimit
-	gasLimit := uint64(0)
-	if opts != nil && opts.GasLimit > 0 {
-		gasLimit = opts.GasLimit
-	} else {
-		msg := ethereum.CallMsg{From: fromAddr, To: &toAddr, Value: valueWei}
-		gl, err := a.client.EstimateGas(ctx, msg)
-		if err != nil {
-			return "", fmt.Errorf("估算Gas失败: %w", err)
-		}
-		gasLimit = gl
-	}
+	return a.SendETHWithSigner(ctx, signer, to, valueWei, opts)
+}
 
-	// 选择费率模式
-	var signedTx *types.Transaction
-	if opts != nil && (opts.TipCap != nil || opts.FeeCap != nil) {
-		// EIP-1559
-		tip := opts.TipCap
-		fee := opts.FeeCap
-		if tip == nil || fee == nil {
-			sug, err := a.GetGasSuggestion(ctx)
-			if err != nil {
-				return "", err
-			}
-			if tip == nil {
-				tip = sug.TipCap
-			}
-			if fee == nil {
-				fee = sug.MaxFee
-			}
-		}
-		tx := types.NewTx(&types.DynamicFeeTx{
-			ChainID:   chainID,
-			Nonce:     nonce,
-			To:        &toAddr,
-			Value:     valueWei,
-			Gas:       gasLimit,
-			GasFeeCap: fee,
-			GasTipCap: tip,
-			Data:      nil,
-		})
-		signer := types.LatestSignerForChainID(chainID)
-		signedTx, err = types.SignTx(tx, signer, priv)
-		if err != nil {
-			return "", fmt.Errorf("签名交易失败: %w", err)
-		}
-	} else {
-		// legacy
-		gp := (*big.Int)(nil)
-		if opts != nil && opts.GasPrice != nil {
-			gp = opts.GasPrice
-		} else {
-			gp, err = a.client.SuggestGasPrice(ctx)
-			if err != nil {
-				return "", fmt.Errorf("获取建议GasPrice失败: %w", err)
-			}
-		}
-		tx := types.NewTransaction(nonce, toAddr, valueWei, gasLimit, gp, nil)
-		signer := types.LatestSignerForChainID(chainID)
-		signedTx, err = types.SignTx(tx, signer, priv)
-		if err != nil {
-			return "", fmt.Errorf("签名交易失败: %w", err)
-		}
+// GetTransactionByHash 根据交易哈希查询交易，第二个返回值表示是否仍在交易池中等待打包
+func (a *EVMAdapter) GetTransactionByHash(ctx context.Context, txHash string) (*types.Transaction, bool, error) {
+	tx, isPending, err := a.client.TransactionByHash(ctx, common.HexToHash(txHash))
+	if err != nil {
+		return nil, false, fmt.Errorf("获取交易失败: %w", err)
 	}
+	return tx, isPending, nil
+}
 
-	if err := a.client.SendTransaction(ctx, signedTx); err != nil {
-		return "", fmt.Errorf("广播交易失败: %w", err)
+// GetBlockTimestamp 获取指定区块的时间戳（秒）
+func (a *EVMAdapter) GetBlockTimestamp(ctx context.Context, blockNumber *big.Int) (uint64, error) {
+	header, err := a.client.HeaderByNumber(ctx, blockNumber)
+	if err != nil {
+		return 0, fmt.Errorf("获取区块头失败: %w", err)
 	}
-	_ = a.waitBrief(ctx)
-	return signedTx.Hash().Hex(), nil
+	return header.Time, nil
 }
 
 // SendERC20WithOptions 支持自定义 gas/nonce 的 ERC20 发送（自动识别 legacy/EIP-1559）
 func (a *EVMAdapter) SendERC20WithOptions(ctx context.Context, mnemonic, derivationPath, tokenAddress, toAddress string, amount *big.Int, opts *TxOptions) (string, error) {
-	priv, fromAddr, err := DerivePrivateKeyFromMnemonic(mnemonic, derivationPath)
+	signer, err := NewMnemonicSigner(mnemonic, derivationPath)
 	if err != nil {
 		return "", err
 	}
-	chainID, err := a.client.NetworkID(ctx)
-	if err != nil {
-		return "", fmt.Errorf("获取链ID失败: %w", err)
-	}
-	to := common.HexToAddress(toAddress)
-	token := common.HexToAddress(tokenAddress)
+	return a.SendERC20WithSigner(ctx, signer, tokenAddress, toAddress, amount, opts)
+}
 
-	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
-	if err != nil {
-		return "", fmt.Errorf("解析ERC20 ABI失败: %w", err)
-	}
-	data, err := parsed.Pack("transfer", to, amount)
+// SendContractCallWithOptions 支持自定义 gas/nonce 的合约调用交易（自动识别 legacy/EIP-1559）
+func (a *EVMAdapter) SendContractCallWithOptions(ctx context.Context, mnemonic, derivationPath string, contract common.Address, data []byte, value *big.Int, opts *TxOptions) (string, error) {
+	signer, err := NewMnemonicSigner(mnemonic, derivationPath)
 	if err != nil {
-		return "", fmt.Errorf("打包transfer数据失败: %w", err)
-	}
-
-	// nonce
-	var nonce uint64
-	if opts != nil && opts.Nonce != nil {
-		nonce = *opts.Nonce
-	} else {
-		nonce, err = a.client.PendingNonceAt(ctx, fromAddr)
-		if err != nil {
-			return "", fmt.Errorf("获取nonce失败: %w", err)
-		}
-	}
-
-	// gasLimit
-	gasLimit := uint64(0)
-	if opts != nil && opts.GasLimit > 0 {
-		gasLimit = opts.GasLimit
-	} else {
-		msg := ethereum.CallMsg{From: fromAddr, To: &token, Data: data}
-		gl, err := a.client.EstimateGas(ctx, msg)
-		if err != nil {
-			return "", fmt.Errorf("估算Gas失败: %w", err)
-		}
-		gasLimit = gl
-	}
-
-	// 构建签名（EIP-1559 优先）
-	var signedTx *types.Transaction
-	if opts != nil && (opts.TipCap != nil || opts.FeeCap != nil) {
-		tip := opts.TipCap
-		fee := opts.FeeCap
-		if tip == nil || fee == nil {
-			sug, err := a.GetGasSuggestion(ctx)
-			if err != nil {
-				return "", err
-			}
-			if tip == nil {
-				tip = sug.TipCap
-			}
-			if fee == nil {
-				fee = sug.MaxFee
-			}
-		}
-		tx := types.NewTx(&types.DynamicFeeTx{
-			ChainID:   chainID,
-			Nonce:     nonce,
-			To:        &token,
-			Value:     big.NewInt(0),
-			Gas:       gasLimit,
-			GasFeeCap: fee,
-			GasTipCap: tip,
-			Data:      data,
-		})
-		signer := types.LatestSignerForChainID(chainID)
-		s, err := types.SignTx(tx, signer, priv)
-		if err != nil {
-			return "", fmt.Errorf("签名交易失败: %w", err)
-		}
-		signedTx = s
-	} else {
-		gp := (*big.Int)(nil)
-		if opts != nil && opts.GasPrice != nil {
-			gp = opts.GasPrice
-		} else {
-			gp, err = a.client.SuggestGasPrice(ctx)
-			if err != nil {
-				return "", fmt.Errorf("获取建议GasPrice失败: %w", err)
-			}
-		}
-		tx := types.NewTransaction(nonce, token, big.NewInt(0), gasLimit, gp, data)
-		signer := types.LatestSignerForChainID(chainID)
-		s, err := types.SignTx(tx, signer, priv)
-		if err != nil {
-			return "", fmt.Errorf("签名交易失败: %w", err)
-		}
-		signedTx = s
+		return "", err
 	}
+	return a.SendContractCallWithSigner(ctx, signer, contract, data, value, opts)
+}
 
-	if err := a.client.SendTransaction(ctx, signedTx); err != nil {
-		return "", fmt.Errorf("广播交易失败: %w", err)
-	}
-	_ = a.waitBrief(ctx)
-	return signedTx.Hash().Hex(), nil
+// SendContractCallWithSigner 使用指定签名者发送合约调用交易（自动识别 legacy/EIP-1559）
+func (a *EVMAdapter) SendContractCallWithSigner(ctx context.Context, signer Signer, contract common.Address, data []byte, value *big.Int, opts *TxOptions) (string, error) {
+	return a.sendWithSigner(ctx, signer, contract, value, data, opts)
 }
 
 // Approve 授权 spender 可花费 amount
 func (a *EVMAdapter) Approve(ctx context.Context, mnemonic, derivationPath, tokenAddress, spender string, amount *big.Int, opts *TxOptions) (string, error) {
-	priv, fromAddr, err := DerivePrivateKeyFromMnemonic(mnemonic, derivationPath)
+	signer, err := NewMnemonicSigner(mnemonic, derivationPath)
 	if err != nil {
 		return "", err
 	}
-	chainID, err := a.client.NetworkID(ctx)
-	if err != nil {
-		return "", fmt.Errorf("获取链ID失败: %w", err)
-	}
-	token := common.HexToAddress(tokenAddress)
-	sp := common.HexToAddress(spender)
+	return a.ApproveWithSigner(ctx, signer, tokenAddress, spender, amount, opts)
+}
 
+// ApproveWithSigner 使用指定签名者授权 spender 可花费 amount
+func (a *EVMAdapter) ApproveWithSigner(ctx context.Context, signer Signer, tokenAddress, spender string, amount *big.Int, opts *TxOptions) (string, error) {
 	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
 	if err != nil {
 		return "", fmt.Errorf("解析ERC20 ABI失败: %w", err)
 	}
-	data, err := parsed.Pack("approve", sp, amount)
+	data, err := parsed.Pack("approve", common.HexToAddress(spender), amount)
 	if err != nil {
 		return "", fmt.Errorf("打包approve数据失败: %w", err)
 	}
-
-	// nonce
-	var nonce uint64
-	if opts != nil && opts.Nonce != nil {
-		nonce = *opts.Nonce
-	} else {
-		nonce, err = a.client.PendingNonceAt(ctx, fromAddr)
-		if err != nil {
-			return "", fmt.Errorf("获取nonce失败: %w", err)
-		}
-	}
-
-	// gasLimit
-	gasLimit := uint64(0)
-	if opts != nil && opts.GasLimit > 0 {
-		gasLimit = opts.GasLimit
-	} else {
-		msg := ethereum.CallMsg{From: fromAddr, To: &token, Data: data}
-		gl, err := a.client.EstimateGas(ctx, msg)
-		if err != nil {
-			return "", fmt.Errorf("估算Gas失败: %w", err)
-		}
-		gasLimit = gl
-	}
-
-	// 构建签名并发送
-	var signedTx *types.Transaction
-	if opts != nil && (opts.TipCap != nil || opts.FeeCap != nil) {
-		tip := opts.TipCap
-		fee := opts.FeeCap
-		if tip == nil || fee == nil {
-			sug, err := a.GetGasSuggestion(ctx)
-			if err != nil {
-				return "", err
-			}
-			if tip == nil {
-				tip = sug.TipCap
-			}
-			if fee == nil {
-				fee = sug.MaxFee
-			}
-		}
-		tx := types.NewTx(&types.DynamicFeeTx{
-			ChainID:   chainID,
-			Nonce:     nonce,
-			To:        &token,
-			Value:     big.NewInt(0),
-			Gas:       gasLimit,
-			GasFeeCap: fee,
-			GasTipCap: tip,
-			Data:      data,
-		})
-		signer := types.LatestSignerForChainID(chainID)
-		s, err := types.SignTx(tx, signer, priv)
-		if err != nil {
-			return "", fmt.Errorf("签名交易失败: %w", err)
-		}
-		signedTx = s
-	} else {
-		gp := (*big.Int)(nil)
-		if opts != nil && opts.GasPrice != nil {
-			gp = opts.GasPrice
-		} else {
-			gp, err = a.client.SuggestGasPrice(ctx)
-			if err != nil {
-				return "", fmt.Errorf("获取建议GasPrice失败: %w", err)
-			}
-		}
-		tx := types.NewTransaction(nonce, token, big.NewInt(0), gasLimit, gp, data)
-		signer := types.LatestSignerForChainID(chainID)
-		s, err := types.SignTx(tx, signer, priv)
-		if err != nil {
-			return "", fmt.Errorf("签名交易失败: %w", err)
-		}
-		signedTx = s
-	}
-
-	if err := a.client.SendTransaction(ctx, signedTx); err != nil {
-		return "", fmt.Errorf("广播交易失败: %w", err)
-	}
-	_ = a.waitBrief(ctx)
-	return signedTx.Hash().Hex(), nil
+	return a.SendContractCallWithSigner(ctx, signer, common.HexToAddress(tokenAddress), data, big.NewInt(0), opts)
 }
 
 // GetAllowance 查询授权额度
@@ -915,20 +866,25 @@ func (a *EVMAdapter) GetAllowance(ctx context.Context, tokenAddress, owner, spen
 
 // TransactionInfo 交易信息结构
 type TransactionInfo struct {
-	Hash        string       `json:"hash"`
-	From        string       `json:"from"`
-	To          string       `json:"to"`
-	Value       string       `json:"value"`
-	GasPrice    string       `json:"gas_price"`
-	GasUsed     string       `json:"gas_used"`
-	GasLimit    string       `json:"gas_limit"`
-	Nonce       uint64       `json:"nonce"`
-	BlockNumber string       `json:"block_number"`
-	BlockHash   string       `json:"block_hash"`
-	Timestamp   uint64       `json:"timestamp"`
-	Status      uint64       `json:"status"`
-	TxType      string       `json:"tx_type"` // "ETH", "ERC20", "CONTRACT"
-	TokenInfo   *TokenTxInfo `json:"token_info,omitempty"`
+	Hash        string         `json:"hash"`
+	From        string         `json:"from"`
+	To          string         `json:"to"`
+	Value       string         `json:"value"`
+	GasPrice    string         `json:"gas_price"`
+	GasUsed     string         `json:"gas_used"`
+	GasLimit    string         `json:"gas_limit"`
+	Nonce       uint64         `json:"nonce"`
+	BlockNumber string         `json:"block_number"`
+	BlockHash   string         `json:"block_hash"`
+	Timestamp   uint64         `json:"timestamp"`
+	Status      uint64         `json:"status"`
+	TxType      string         `json:"tx_type"` // "ETH", "ERC20", "CONTRACT"
+	TokenInfo   *TokenTxInfo   `json:"token_info,omitempty"`
+	Events      []DecodedEvent `json:"events,omitempty"`  // 从回执日志解码出的事件
+	Summary     string         `json:"summary,omitempty"` // 基于标准转账事件的摘要，如 "Received 100 USDC"
+	// Confirmations 所在区块之后的出块数；Confirmed 表示已达到网络要求的确认数，未达到时应显示为待确认
+	Confirmations uint64 `json:"confirmations"`
+	Confirmed     bool   `json:"confirmed"`
 }
 
 // TokenTxInfo ERC20交易信息
@@ -955,11 +911,12 @@ type TransactionHistoryRequest struct {
 
 // TransactionHistoryResponse 交易历史查询响应
 type TransactionHistoryResponse struct {
-	Transactions []TransactionInfo `json:"transactions"`
-	Total        int               `json:"total"`
-	Page         int               `json:"page"`
-	Limit        int               `json:"limit"`
-	TotalPages   int               `json:"total_pages"`
+	Transactions          []TransactionInfo `json:"transactions"`
+	Total                 int               `json:"total"`
+	Page                  int               `json:"page"`
+	Limit                 int               `json:"limit"`
+	TotalPages            int               `json:"total_pages"`
+	RequiredConfirmations uint64            `json:"required_confirmations"` // 当前网络要求的确认数
 }
 
 // GetTransactionHistory 获取地址的交易历史
@@ -977,88 +934,150 @@ func (a *EVMAdapter) GetTransactionHistory(ctx context.Context, req *Transaction
 		req.SortOrder = "desc"
 	}
 
-	// 获取当前最新区块
-	latestBlock, err := a.client.BlockNumber(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("获取最新区块失败: %w", err)
-	}
-
-	// 设置查询范围
-	if req.EndBlock == 0 || req.EndBlock > latestBlock {
-		req.EndBlock = latestBlock
-	}
-	if req.StartBlock == 0 {
-		// 默认查询最近1000个区块
-		if req.EndBlock > 1000 {
-			req.StartBlock = req.EndBlock - 1000
-		} else {
-			req.StartBlock = 0
-		}
-	}
-
-	// 收集交易
-	transactions, err := a.collectTransactionsInRange(ctx, req.Address, req.StartBlock, req.EndBlock, req.TxType)
+	// 设置查询范围（默认查询最近1000个区块）
+	startBlock, endBlock, err := a.ResolveHistoryRange(ctx, req.StartBlock, req.EndBlock)
 	if err != nil {
 		return nil, err
 	}
+	req.StartBlock, req.EndBlock = startBlock, endBlock
 
-	// 排序
-	a.sortTransactions(transactions, req.SortBy, req.SortOrder)
-
-	// 分页
-	total := len(transactions)
-	totalPages := (total + req.Limit - 1) / req.Limit
-	start := (req.Page - 1) * req.Limit
-	end := start + req.Limit
-
-	if start >= total {
-		transactions = []TransactionInfo{}
+	var (
+		transactions []TransactionInfo
+		total        int
+	)
+	if a.historyStore != nil {
+		// 增量同步到存储后由存储过滤、排序与分页
+		address := strings.ToLower(req.Address)
+		if err := a.syncHistory(ctx, address, req.StartBlock, req.EndBlock); err != nil {
+			return nil, err
+		}
+		transactions, total, err = a.historyStore.QueryHistory(a.historyNetwork, address, req)
+		if err != nil {
+			return nil, fmt.Errorf("查询交易历史缓存失败: %w", err)
+		}
 	} else {
-		if end > total {
-			end = total
+		// 收集交易：代币转账按日志查询，原生交易优先使用区块浏览器索引
+		transactions, err = a.collectIndexedTransactions(ctx, req.Address, req.StartBlock, req.EndBlock, req.TxType)
+		if err != nil {
+			return nil, err
 		}
-		transactions = transactions[start:end]
+		sortTransactions(transactions, req.SortBy, req.SortOrder)
+		total = len(transactions)
+		transactions = paginateTransactions(transactions, req.Page, req.Limit)
 	}
+	totalPages := (total + req.Limit - 1) / req.Limit
+	a.markConfirmations(ctx, transactions)
 
 	return &TransactionHistoryResponse{
-		Transactions: transactions,
-		Total:        total,
-		Page:         req.Page,
-		Limit:        req.Limit,
-		TotalPages:   totalPages,
+		Transactions:          transactions,
+		Total:                 total,
+		Page:                  req.Page,
+		Limit:                 req.Limit,
+		TotalPages:            totalPages,
+		RequiredConfirmations: a.confirmations,
 	}, nil
 }
 
+// markConfirmations 按当前链头填充交易的确认数，并按网络要求的确认数标记是否已确认
+// 获取链头失败时保留零值（均视为待确认）
+func (a *EVMAdapter) markConfirmations(ctx context.Context, transactions []TransactionInfo) {
+	head, err := a.client.BlockNumber(ctx)
+	if err != nil {
+		return
+	}
+	for i := range transactions {
+		block, err := strconv.ParseUint(transactions[i].BlockNumber, 10, 64)
+		if err != nil {
+			continue
+		}
+		transactions[i].Confirmations = Confirmations(head, block)
+		transactions[i].Confirmed = transactions[i].Confirmations >= a.confirmations
+	}
+}
+
 // collectTransactionsInRange 收集指定区块范围内的交易
 func (a *EVMAdapter) collectTransactionsInRange(ctx context.Context, address string, startBlock, endBlock uint64, txType string) ([]TransactionInfo, error) {
 	var transactions []TransactionInfo
+	err := a.StreamTransactionsInRange(ctx, address, startBlock, endBlock, txType, func(tx TransactionInfo) error {
+		transactions = append(transactions, tx)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// StreamTransactionsInRange 按区块顺序扫描指定范围，每发现一笔相关交易即回调 emit
+// 不在内存中累积结果，适合导出大范围历史；ctx 取消或 emit 返回错误时立即停止扫描
+func (a *EVMAdapter) StreamTransactionsInRange(ctx context.Context, address string, startBlock, endBlock uint64, txType string, emit func(TransactionInfo) error) error {
 	addr := common.HexToAddress(address)
 
-	// 批量处理区块，避免一次查询太多
-	batchSize := uint64(100)
-	for current := startBlock; current <= endBlock; current += batchSize {
+	// 批量处理区块，批次大小根据节点的耗时与错误率自适应调整
+	if startBlock > endBlock {
+		return nil
+	}
+	for current := startBlock; ; {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		batchSize := a.historyBatch.Current()
 		batchEnd := current + batchSize - 1
-		if batchEnd > endBlock {
+		if batchEnd > endBlock || batchEnd < current {
 			batchEnd = endBlock
 		}
 
-		batchTxs, err := a.collectTransactionsInBatch(ctx, addr, current, batchEnd, txType)
+		started := time.Now()
+		batchTxs, failures, err := a.collectTransactionsInBatch(ctx, addr, current, batchEnd, txType)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		a.historyBatch.Observe(batchEnd-current+1, time.Since(started), failures)
+
+		for _, tx := range batchTxs {
+			if err := emit(tx); err != nil {
+				return err
+			}
+		}
+		if batchEnd == endBlock {
+			return nil
 		}
-		transactions = append(transactions, batchTxs...)
+		current = batchEnd + 1
 	}
+}
 
-	return transactions, nil
+// ResolveHistoryRange 规范化历史查询的区块范围
+// endBlock 为 0 或超过最新区块时取最新区块；startBlock 为 0 时默认取最近1000个区块
+func (a *EVMAdapter) ResolveHistoryRange(ctx context.Context, startBlock, endBlock uint64) (uint64, uint64, error) {
+	latestBlock, err := a.client.BlockNumber(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("获取最新区块失败: %w", err)
+	}
+	if endBlock == 0 || endBlock > latestBlock {
+		endBlock = latestBlock
+	}
+	if startBlock == 0 && endBlock > 1000 {
+		startBlock = endBlock - 1000
+	}
+	if startBlock > endBlock {
+		return 0, 0, fmt.Errorf("起始区块不能大于结束区块")
+	}
+	return startBlock, endBlock, nil
 }
 
 // collectTransactionsInBatch 收集批量区块中的交易
-func (a *EVMAdapter) collectTransactionsInBatch(ctx context.Context, addr common.Address, startBlock, endBlock uint64, txType string) ([]TransactionInfo, error) {
+// 返回: 相关交易、获取失败的区块数（用于自适应调整批次大小）和错误信息
+func (a *EVMAdapter) collectTransactionsInBatch(ctx context.Context, addr common.Address, startBlock, endBlock uint64, txType string) ([]TransactionInfo, int, error) {
 	var transactions []TransactionInfo
+	failures := 0
 
 	for blockNum := startBlock; blockNum <= endBlock; blockNum++ {
+		if err := ctx.Err(); err != nil {
+			return nil, failures, err
+		}
 		block, err := a.client.BlockByNumber(ctx, new(big.Int).SetUint64(blockNum))
 		if err != nil {
+			failures++
 			continue // 跽过获取失败的区块
 		}
 
@@ -1088,7 +1107,7 @@ func (a *EVMAdapter) collectTransactionsInBatch(ctx context.Context, addr common
 			}
 
 			// 构建交易信息
-			txInfo := a.buildTransactionInfo(tx, receipt, block, addr)
+			txInfo := a.buildTransactionInfo(tx, receipt, block.Time(), addr)
 
 			// 过滤交易类型
 			if txType != "all" && txInfo.TxType != txType {
@@ -1099,11 +1118,11 @@ func (a *EVMAdapter) collectTransactionsInBatch(ctx context.Context, addr common
 		}
 	}
 
-	return transactions, nil
+	return transactions, failures, nil
 }
 
 // buildTransactionInfo 构建交易信息
-func (a *EVMAdapter) buildTransactionInfo(tx *types.Transaction, receipt *types.Receipt, block *types.Block, userAddr common.Address) *TransactionInfo {
+func (a *EVMAdapter) buildTransactionInfo(tx *types.Transaction, receipt *types.Receipt, blockTime uint64, userAddr common.Address) *TransactionInfo {
 	txInfo := &TransactionInfo{
 		Hash:        tx.Hash().Hex(),
 		To:          "",
@@ -1112,7 +1131,7 @@ func (a *EVMAdapter) buildTransactionInfo(tx *types.Transaction, receipt *types.
 		Nonce:       tx.Nonce(),
 		BlockNumber: receipt.BlockNumber.String(),
 		BlockHash:   receipt.BlockHash.Hex(),
-		Timestamp:   block.Time(),
+		Timestamp:   blockTime,
 		Status:      receipt.Status,
 		TxType:      "ETH",
 	}
@@ -1151,6 +1170,16 @@ func (a *EVMAdapter) buildTransactionInfo(tx *types.Transaction, receipt *types.
 		}
 	}
 
+	// 解码回执日志，合约交互也能展示实际的代币流向
+	if events, err := a.DecodeLogs(receipt, ""); err == nil && len(events) > 0 {
+		txInfo.Events = events
+		summary, tokenInfo := a.summarizeEvents(context.Background(), events, userAddr)
+		txInfo.Summary = summary
+		if txInfo.TokenInfo == nil {
+			txInfo.TokenInfo = tokenInfo
+		}
+	}
+
 	return txInfo
 }
 
@@ -1188,7 +1217,7 @@ func (a *EVMAdapter) parseERC20Transfer(tx *types.Transaction, userAddr common.A
 }
 
 // sortTransactions 排序交易
-func (a *EVMAdapter) sortTransactions(transactions []TransactionInfo, sortBy, sortOrder string) {
+func sortTransactions(transactions []TransactionInfo, sortBy, sortOrder string) {
 	sort.Slice(transactions, func(i, j int) bool {
 		var less bool
 		switch sortBy {
@@ -1252,14 +1281,21 @@ func decodeRevertReason(data []byte) string {
 //
 // 返回: 交易哈希和错误信息
 func (a *EVMAdapter) SendContractTransaction(ctx context.Context, mnemonic, derivationPath string, contractAddr common.Address, data []byte, value, gasLimit, gasPrice *big.Int) (string, error) {
-	priv, fromAddr, err := DerivePrivateKeyFromMnemonic(mnemonic, derivationPath)
+	signer, err := NewMnemonicSigner(mnemonic, derivationPath)
 	if err != nil {
 		return "", err
 	}
+	return a.SendContractTransactionWithSigner(ctx, signer, contractAddr, data, value, gasLimit, gasPrice, nil)
+}
 
-	chainID, err := a.client.NetworkID(ctx)
+// SendContractTransactionWithSigner 使用指定签名者发送智能合约交易
+// 未指定 gasLimit 时在估算值基础上增加 20% 安全边际；feeOpts 仅使用其中的手续费上限设置（MaxFeeWei/AllowHighFee），可为空
+func (a *EVMAdapter) SendContractTransactionWithSigner(ctx context.Context, signer Signer, contractAddr common.Address, data []byte, value, gasLimit, gasPrice *big.Int, feeOpts *TxOptions) (string, error) {
+	fromAddr := signer.Address()
+	// 签名前检测节点链ID，防止签出与所选网络不同链的交易
+	chainID, err := a.VerifyChainID(ctx)
 	if err != nil {
-		return "", fmt.Errorf("获取链ID失败: %w", err)
+		return "", err
 	}
 
 	nonce, err := a.client.PendingNonceAt(ctx, fromAddr)
@@ -1294,10 +1330,9 @@ func (a *EVMAdapter) SendContractTransaction(ctx context.Context, mnemonic, deri
 
 	// 构建与签名交易
 	tx := types.NewTransaction(nonce, contractAddr, value, gasLimit.Uint64(), gasPrice, data)
-	signer := types.LatestSignerForChainID(chainID)
-	signedTx, err := types.SignTx(tx, signer, priv)
+	signedTx, err := a.signTx(ctx, signer, tx, chainID, feeOpts)
 	if err != nil {
-		return "", fmt.Errorf("签名交易失败: %w", err)
+		return "", err
 	}
 
 	// 广播交易
@@ -0,0 +1,92 @@
+/*
+交易手续费上限
+
+Gas 价格暴涨时，一笔小额转账的手续费可能超过转账金额本身。signTx 在签名前计算交易可能支付的最高手续费
+（gasLimit × maxFeePerGas，legacy 交易为 gasLimit × gasPrice），超过上限时拒绝发送并返回 FeeTooHighError：
+  - 上限优先取 TxOptions.MaxFeeWei（本笔交易指定），其次为适配器上设置的默认上限（见 MultiChainManager.SetFeeCeiling，
+    通常由服务层按用户设置折算），均为空时不限制
+  - TxOptions.AllowHighFee 为 true 表示调用方已确认高手续费，跳过检查
+
+适配器内所有签名路径都经 signTx 签名：sendWithSigner（转账、ERC20、授权、批量发送、合约调用等）、
+交易加速/取消（sendWithNonce）以及 SendContractTransactionWithSigner（1inch 等聚合器返回的交易），都受此限制。
+*/
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrFeeTooHigh 交易手续费超过设定的上限
+var ErrFeeTooHigh = errors.New("交易手续费超过设定的上限")
+
+// FeeCeilingFunc 返回 from 在 networkID 上发送交易的默认手续费上限（wei），nil 表示不限制
+type FeeCeilingFunc func(ctx context.Context, networkID string, from common.Address) *big.Int
+
+// FeeTooHighError 手续费超限的详细信息，errors.Is(err, ErrFeeTooHigh) 为 true
+type FeeTooHighError struct {
+	GasLimit  uint64   // 交易的 gasLimit
+	FeePerGas *big.Int // maxFeePerGas 或 gasPrice
+	Fee       *big.Int // 最高手续费 = GasLimit × FeePerGas
+	Ceiling   *big.Int // 手续费上限
+}
+
+func (e *FeeTooHighError) Error() string {
+	return fmt.Sprintf("%v：预计最高手续费 %s wei（gasLimit %d × 每Gas %s wei），上限 %s wei；确认后可设置 allow_high_fee 重新提交",
+		ErrFeeTooHigh, e.Fee, e.GasLimit, e.FeePerGas, e.Ceiling)
+}
+
+func (e *FeeTooHighError) Unwrap() error {
+	return ErrFeeTooHigh
+}
+
+// SetFeeCeiling 设置默认手续费上限的查询函数，networkID 为该适配器对应的网络
+func (a *EVMAdapter) SetFeeCeiling(networkID string, fn FeeCeilingFunc) {
+	if fn == nil {
+		a.feeCeiling = nil
+		return
+	}
+	a.feeCeiling = func(ctx context.Context, from common.Address) *big.Int {
+		return fn(ctx, networkID, from)
+	}
+}
+
+// signTx 校验手续费上限后签名交易，适配器内构建的交易都经此签名
+func (a *EVMAdapter) signTx(ctx context.Context, signer Signer, tx *types.Transaction, chainID *big.Int, opts *TxOptions) (*types.Transaction, error) {
+	if err := a.checkFeeCeiling(ctx, signer.Address(), tx, opts); err != nil {
+		return nil, err
+	}
+	return signer.SignTx(tx, chainID)
+}
+
+// checkFeeCeiling 签名前校验交易的最高手续费不超过上限
+func (a *EVMAdapter) checkFeeCeiling(ctx context.Context, from common.Address, tx *types.Transaction, opts *TxOptions) error {
+	if opts != nil && opts.AllowHighFee {
+		return nil
+	}
+	var ceiling *big.Int
+	if opts != nil && opts.MaxFeeWei != nil {
+		ceiling = opts.MaxFeeWei
+	} else if a.feeCeiling != nil {
+		ceiling = a.feeCeiling(ctx, from)
+	}
+	if ceiling == nil {
+		return nil
+	}
+	feePerGas := tx.GasFeeCap()
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), feePerGas)
+	if fee.Cmp(ceiling) <= 0 {
+		return nil
+	}
+	return &FeeTooHighError{
+		GasLimit:  tx.Gas(),
+		FeePerGas: new(big.Int).Set(feePerGas),
+		Fee:       fee,
+		Ceiling:   new(big.Int).Set(ceiling),
+	}
+}
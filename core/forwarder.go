@@ -0,0 +1,280 @@
+/*
+ERC-2771 元交易（可信转发合约）
+
+用户只签署 EIP-712 转发请求，由中继账户调用转发合约 execute() 上链并支付Gas，
+目标合约通过 ERC2771Context 从调用数据末尾取出原始发送者（_msgSender()）：
+  - 转发合约需兼容 OpenZeppelin MinimalForwarder（v4.x）：ForwardRequest(from,to,value,gas,nonce,data)，
+    提供 getNonce(from)、verify(req,signature)、execute(req,signature)；EIP-712 域的 name/version 按部署配置
+  - OpenZeppelin v5 的 ERC2771Forwarder（请求含 deadline、nonce 不在结构体中）与 GSN 不兼容
+  - 目标合约需信任该转发合约（isTrustedForwarder 返回 true），否则代币仍以转发合约为发送者，转账必然失败
+  - execute() 在内部调用失败时不回滚（仅返回 success=false 且消耗 nonce），提交前用 eth_call 模拟执行结果
+*/
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+const forwarderABI = `[{"inputs":[{"name":"from","type":"address"}],"name":"getNonce","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"components":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"gas","type":"uint256"},{"name":"nonce","type":"uint256"},{"name":"data","type":"bytes"}],"name":"req","type":"tuple"},{"name":"signature","type":"bytes"}],"name":"verify","outputs":[{"name":"","type":"bool"}],"stateMutability":"view","type":"function"},{"inputs":[{"components":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"gas","type":"uint256"},{"name":"nonce","type":"uint256"},{"name":"data","type":"bytes"}],"name":"req","type":"tuple"},{"name":"signature","type":"bytes"}],"name":"execute","outputs":[{"name":"","type":"bool"},{"name":"","type":"bytes"}],"stateMutability":"payable","type":"function"}]`
+
+const trustedForwarderABI = `[{"inputs":[{"name":"forwarder","type":"address"}],"name":"isTrustedForwarder","outputs":[{"name":"","type":"bool"}],"stateMutability":"view","type":"function"}]`
+
+// ErrForwardExecutionFailed 模拟执行时转发的内部调用失败（如余额不足、代币未信任转发合约）
+var ErrForwardExecutionFailed = errors.New("转发请求的内部调用执行失败")
+
+// ForwarderDomain 转发合约地址及其 EIP-712 域
+type ForwarderDomain struct {
+	Address string // 转发合约地址
+	Name    string // EIP-712 域名称（MinimalForwarder 默认为 MinimalForwarder）
+	Version string // EIP-712 域版本（MinimalForwarder 默认为 0.0.1）
+}
+
+// ForwardRequest 转发请求（与 MinimalForwarder.ForwardRequest 字段一致）
+type ForwardRequest struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Value string `json:"value"` // 随调用转出的原生代币（wei，十进制字符串）
+	Gas   uint64 `json:"gas"`   // 内部调用的 gas 上限
+	Nonce string `json:"nonce"` // 转发合约中 from 的 nonce（十进制字符串）
+	Data  string `json:"data"`  // 0x 开头的调用数据
+}
+
+// forwardRequestTuple ABI 打包用的转发请求
+type forwardRequestTuple struct {
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+	Gas   *big.Int
+	Nonce *big.Int
+	Data  []byte
+}
+
+// tuple 解析并校验转发请求字段
+func (r ForwardRequest) tuple() (forwardRequestTuple, error) {
+	if !common.IsHexAddress(r.From) || !common.IsHexAddress(r.To) {
+		return forwardRequestTuple{}, fmt.Errorf("转发请求的 from/to 地址格式不正确")
+	}
+	value, ok := new(big.Int).SetString(r.Value, 10)
+	if !ok || value.Sign() < 0 {
+		return forwardRequestTuple{}, fmt.Errorf("转发请求的 value 无效: %s", r.Value)
+	}
+	nonce, ok := new(big.Int).SetString(r.Nonce, 10)
+	if !ok || nonce.Sign() < 0 {
+		return forwardRequestTuple{}, fmt.Errorf("转发请求的 nonce 无效: %s", r.Nonce)
+	}
+	data, err := hexutil.Decode(r.Data)
+	if err != nil {
+		return forwardRequestTuple{}, fmt.Errorf("转发请求的 data 不是有效的十六进制: %w", err)
+	}
+	return forwardRequestTuple{
+		From:  common.HexToAddress(r.From),
+		To:    common.HexToAddress(r.To),
+		Value: value,
+		Gas:   new(big.Int).SetUint64(r.Gas),
+		Nonce: nonce,
+		Data:  data,
+	}, nil
+}
+
+// ForwardRequestTypedData 转发请求的 EIP-712 typed data，可直接用 eth_signTypedData_v4 签名
+func ForwardRequestTypedData(domain ForwarderDomain, chainID *big.Int, req ForwardRequest) apitypes.TypedData {
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"ForwardRequest": {
+				{Name: "from", Type: "address"},
+				{Name: "to", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "gas", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "data", Type: "bytes"},
+			},
+		},
+		PrimaryType: "ForwardRequest",
+		Domain: apitypes.TypedDataDomain{
+			Name:              domain.Name,
+			Version:           domain.Version,
+			ChainId:           (*math.HexOrDecimal256)(chainID),
+			VerifyingContract: common.HexToAddress(domain.Address).Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"from":  common.HexToAddress(req.From).Hex(),
+			"to":    common.HexToAddress(req.To).Hex(),
+			"value": req.Value,
+			"gas":   new(big.Int).SetUint64(req.Gas).String(),
+			"nonce": req.Nonce,
+			"data":  req.Data,
+		},
+	}
+}
+
+// RecoverForwardRequestSigner 从签名恢复转发请求的签署者
+func RecoverForwardRequestSigner(domain ForwarderDomain, chainID *big.Int, req ForwardRequest, signature string) (common.Address, error) {
+	if _, err := req.tuple(); err != nil {
+		return common.Address{}, err
+	}
+	digest, err := typedDataHash(ForwardRequestTypedData(domain, chainID, req))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("计算转发请求哈希失败: %w", err)
+	}
+	return recoverSigner(digest, signature)
+}
+
+// ForwarderNonce 查询 from 在转发合约中的当前 nonce
+func (a *EVMAdapter) ForwarderNonce(ctx context.Context, forwarder, from string) (*big.Int, error) {
+	parsed, err := abi.JSON(strings.NewReader(forwarderABI))
+	if err != nil {
+		return nil, err
+	}
+	out, err := a.callView(ctx, common.HexToAddress(forwarder), parsed, "getNonce", common.HexToAddress(from))
+	if err != nil {
+		return nil, fmt.Errorf("查询转发合约 nonce 失败: %w", err)
+	}
+	nonce, ok := out[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("转发合约 nonce 返回值无效")
+	}
+	return nonce, nil
+}
+
+// VerifyForwardRequest 调用转发合约 verify() 校验签名与 nonce
+func (a *EVMAdapter) VerifyForwardRequest(ctx context.Context, forwarder string, req ForwardRequest, signature []byte) (bool, error) {
+	parsed, err := abi.JSON(strings.NewReader(forwarderABI))
+	if err != nil {
+		return false, err
+	}
+	tuple, err := req.tuple()
+	if err != nil {
+		return false, err
+	}
+	out, err := a.callView(ctx, common.HexToAddress(forwarder), parsed, "verify", tuple, signature)
+	if err != nil {
+		return false, fmt.Errorf("转发合约校验失败: %w", err)
+	}
+	ok, _ := out[0].(bool)
+	return ok, nil
+}
+
+// IsTrustedForwarder 查询目标合约是否信任该转发合约（未实现 ERC2771Context 的合约返回错误）
+func (a *EVMAdapter) IsTrustedForwarder(ctx context.Context, contract, forwarder string) (bool, error) {
+	parsed, err := abi.JSON(strings.NewReader(trustedForwarderABI))
+	if err != nil {
+		return false, err
+	}
+	out, err := a.callView(ctx, common.HexToAddress(contract), parsed, "isTrustedForwarder", common.HexToAddress(forwarder))
+	if err != nil {
+		return false, err
+	}
+	ok, _ := out[0].(bool)
+	return ok, nil
+}
+
+// ForwardExecuteCallData 构造 execute(req, signature) 调用数据
+func ForwardExecuteCallData(req ForwardRequest, signature []byte) ([]byte, error) {
+	parsed, err := abi.JSON(strings.NewReader(forwarderABI))
+	if err != nil {
+		return nil, err
+	}
+	tuple, err := req.tuple()
+	if err != nil {
+		return nil, err
+	}
+	data, err := parsed.Pack("execute", tuple, signature)
+	if err != nil {
+		return nil, fmt.Errorf("打包 execute 数据失败: %w", err)
+	}
+	return data, nil
+}
+
+// SimulateForward 以 relayer 身份模拟 execute()，内部调用失败时返回 ErrForwardExecutionFailed，成功时返回估算的交易 gas
+func (a *EVMAdapter) SimulateForward(ctx context.Context, relayer common.Address, forwarder string, req ForwardRequest, signature []byte) (uint64, error) {
+	data, err := ForwardExecuteCallData(req, signature)
+	if err != nil {
+		return 0, err
+	}
+	parsed, err := abi.JSON(strings.NewReader(forwarderABI))
+	if err != nil {
+		return 0, err
+	}
+	to := common.HexToAddress(forwarder)
+	msg := ethereum.CallMsg{From: relayer, To: &to, Data: data}
+	raw, err := a.client.CallContract(ctx, msg, nil)
+	if err != nil {
+		return 0, fmt.Errorf("模拟转发失败: %w", err)
+	}
+	out, err := parsed.Unpack("execute", raw)
+	if err != nil || len(out) == 0 {
+		return 0, fmt.Errorf("解析 execute 返回结果失败: %v", err)
+	}
+	if success, _ := out[0].(bool); !success {
+		return 0, ErrForwardExecutionFailed
+	}
+	gas, err := a.client.EstimateGas(ctx, msg)
+	if err != nil {
+		return 0, fmt.Errorf("估算Gas失败: %w", err)
+	}
+	return gas, nil
+}
+
+// NormalizeForwardSignature 解析65字节签名并将 v 统一为 27/28（转发合约使用 OpenZeppelin ECDSA.recover）
+func NormalizeForwardSignature(signature string) ([]byte, error) {
+	sig, err := hexutil.Decode(strings.TrimSpace(signature))
+	if err != nil {
+		return nil, fmt.Errorf("签名不是有效的十六进制: %w", err)
+	}
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("签名长度应为 65 字节，实际 %d 字节", len(sig))
+	}
+	if sig[64] < 27 {
+		sig[64] += 27
+	}
+	return sig, nil
+}
+
+// ERC20TransferCallData 构造 ERC20 transfer(to, amount) 调用数据
+func ERC20TransferCallData(to string, amount *big.Int) ([]byte, error) {
+	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return nil, err
+	}
+	return parsed.Pack("transfer", common.HexToAddress(to), amount)
+}
+
+// DecodeERC20TransferCallData 解析 ERC20 transfer(to, amount) 调用数据，其他调用返回错误
+func DecodeERC20TransferCallData(data []byte) (common.Address, *big.Int, error) {
+	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	method := parsed.Methods["transfer"]
+	if len(data) != 4+64 || !bytes.Equal(data[:4], method.ID) {
+		return common.Address{}, nil, fmt.Errorf("调用数据不是 ERC20 transfer")
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil || len(args) != 2 {
+		return common.Address{}, nil, fmt.Errorf("解析 transfer 参数失败: %v", err)
+	}
+	to, _ := args[0].(common.Address)
+	amount, _ := args[1].(*big.Int)
+	if amount == nil {
+		return common.Address{}, nil, fmt.Errorf("解析 transfer 参数失败")
+	}
+	return to, amount, nil
+}
@@ -0,0 +1,163 @@
+/*
+基于 eth_feeHistory 的 Gas 建议
+
+GetGasSuggestion 使用 tip + 2*baseFee 的粗略公式，平稳时多付、拥堵时少付。
+本文件根据最近 N 个区块的费用历史给出分档建议：
+- 小费：各区块在给定百分位（默认 25/50/75）的 priority fee 取中位数，分别对应 slow/normal/fast
+- 最大费用：按 baseFee 走势为下一区块 baseFee 预留若干区块的涨幅空间（EIP-1559 单区块最多上涨12.5%）
+- 上限：maxFee 不超过 tip + baseFee × gasMaxFeeCapMultiple，避免意外多付
+节点不支持 feeHistory（或链未启用 EIP-1559）时回退到 GetGasSuggestion。
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// Gas 分档名称
+const (
+	GasTierSlow   = "slow"
+	GasTierNormal = "normal"
+	GasTierFast   = "fast"
+)
+
+// baseFee 走势
+const (
+	BaseFeeRising  = "rising"
+	BaseFeeFalling = "falling"
+	BaseFeeStable  = "stable"
+)
+
+var (
+	// DefaultFeeHistoryPercentiles 默认小费百分位，依次对应 slow/normal/fast
+	DefaultFeeHistoryPercentiles = []float64{25, 50, 75}
+	// gasTierNames 分档名称，与百分位一一对应
+	gasTierNames = []string{GasTierSlow, GasTierNormal, GasTierFast}
+	// gasTierHeadroomBlocks 各档为 baseFee 预留的涨幅区块数
+	gasTierHeadroomBlocks = []int{1, 2, 4}
+)
+
+// gasMaxFeeCapMultiple maxFee 不超过 tip + 当前 baseFee × 该倍数
+const gasMaxFeeCapMultiple = 3
+
+// GetGasSuggestionFromHistory 基于最近 blocks 个区块的费用历史给出分档Gas建议
+// 参数: percentiles - 三个小费百分位（依次对应 slow/normal/fast），为空使用 25/50/75
+// 顶层 TipCap/MaxFee 取 normal 档，以兼容只读取顶层字段的调用方
+func (a *EVMAdapter) GetGasSuggestionFromHistory(ctx context.Context, blocks int, percentiles []float64) (*GasSuggestion, error) {
+	if len(percentiles) == 0 {
+		percentiles = DefaultFeeHistoryPercentiles
+	}
+	if len(percentiles) != len(gasTierNames) {
+		return nil, fmt.Errorf("需要 %d 个百分位（slow/normal/fast），实际 %d 个", len(gasTierNames), len(percentiles))
+	}
+	if blocks <= 0 {
+		blocks = mempoolFeeHistoryBlocks
+	}
+
+	history, err := a.client.FeeHistory(ctx, uint64(blocks), nil, percentiles)
+	if err != nil || len(history.BaseFee) == 0 || len(history.Reward) == 0 {
+		// 节点不支持 feeHistory，回退到原有估算
+		return a.GetGasSuggestion(ctx)
+	}
+	nextBaseFee := history.BaseFee[len(history.BaseFee)-1]
+	if nextBaseFee == nil || nextBaseFee.Sign() == 0 {
+		// 未启用 EIP-1559 的链
+		return a.GetGasSuggestion(ctx)
+	}
+
+	chainID, err := a.client.NetworkID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链ID失败: %w", err)
+	}
+	gasPrice, err := a.client.SuggestGasPrice(ctx)
+	if err != nil {
+		gasPrice = big.NewInt(0)
+	}
+
+	trend := baseFeeTrend(history.BaseFee)
+	maxFeeCap := new(big.Int).Mul(nextBaseFee, big.NewInt(gasMaxFeeCapMultiple))
+	tiers := make(map[string]*GasTier, len(gasTierNames))
+	for i, name := range gasTierNames {
+		tip := medianReward(history.Reward, i)
+		headroom := gasTierHeadroomBlocks[i]
+		if trend == BaseFeeRising {
+			headroom++
+		}
+		projected := projectBaseFee(nextBaseFee, headroom)
+		if projected.Cmp(maxFeeCap) > 0 {
+			projected = new(big.Int).Set(maxFeeCap)
+		}
+		tiers[name] = &GasTier{
+			TipCap: tip,
+			MaxFee: new(big.Int).Add(projected, tip),
+		}
+	}
+
+	normal := tiers[GasTierNormal]
+	return &GasSuggestion{
+		ChainID:      chainID,
+		BaseFee:      nextBaseFee,
+		TipCap:       normal.TipCap,
+		MaxFee:       normal.MaxFee,
+		GasPrice:     gasPrice,
+		Tiers:        tiers,
+		BaseFeeTrend: trend,
+	}, nil
+}
+
+// medianReward 取各区块第 idx 个百分位小费的中位数（忽略空区块）
+func medianReward(rewards [][]*big.Int, idx int) *big.Int {
+	values := make([]*big.Int, 0, len(rewards))
+	for _, r := range rewards {
+		if idx < len(r) && r[idx] != nil {
+			values = append(values, r[idx])
+		}
+	}
+	if len(values) == 0 {
+		return big.NewInt(0)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Cmp(values[j]) < 0 })
+	return new(big.Int).Set(values[len(values)/2])
+}
+
+// baseFeeTrend 比较下一区块 baseFee 与近期均值判断走势（±5% 以内视为平稳）
+func baseFeeTrend(baseFees []*big.Int) string {
+	if len(baseFees) < 2 {
+		return BaseFeeStable
+	}
+	next := baseFees[len(baseFees)-1]
+	sum := new(big.Int)
+	count := 0
+	for _, fee := range baseFees[:len(baseFees)-1] {
+		if fee != nil {
+			sum.Add(sum, fee)
+			count++
+		}
+	}
+	if count == 0 || sum.Sign() == 0 {
+		return BaseFeeStable
+	}
+	// next*count 与 sum 比较，避免除法精度损失
+	scaled := new(big.Int).Mul(next, big.NewInt(int64(count)*100))
+	switch {
+	case scaled.Cmp(new(big.Int).Mul(sum, big.NewInt(105))) > 0:
+		return BaseFeeRising
+	case scaled.Cmp(new(big.Int).Mul(sum, big.NewInt(95))) < 0:
+		return BaseFeeFalling
+	default:
+		return BaseFeeStable
+	}
+}
+
+// projectBaseFee 按每区块最多上涨 12.5% 预估 blocks 个区块后的 baseFee 上界
+func projectBaseFee(baseFee *big.Int, blocks int) *big.Int {
+	projected := new(big.Int).Set(baseFee)
+	for i := 0; i < blocks; i++ {
+		projected.Mul(projected, big.NewInt(1125))
+		projected.Div(projected, big.NewInt(1000))
+	}
+	return projected
+}
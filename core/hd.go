@@ -2,7 +2,7 @@
 HD钱包核心功能包
 
 本包实现了分层确定性（HD）钱包的核心功能，包括：
-- BIP39助记词生成和验证
+- BIP39助记词生成和验证（校验与多语言词表见 mnemonic.go）
 - BIP44地址派生（支持以太坊和其他EVM兼容链）
 - 私钥和地址管理
 - 批量地址生成
@@ -10,25 +10,28 @@ HD钱包核心功能包
 支持的派生路径格式：
 m/44'/60'/0'/0/0 - 以太坊主网标准路径
 m/44'/60'/0'/0/1 - 以太坊第二个地址
+m/44'/60'/N'/0/0 - Ledger Live 账户；m/44'/60'/0'/N - Ledger 旧版（MEW）路径（见 DerivationScheme）
 其中 44' 是BIP44约定，60' 是以太坊的coin_type
+可用路径受 config.security.derivation_path_allowlist 白名单限制（见 derivation_policy.go）
 */
 package core
 
 import (
 	"crypto/ecdsa"
 	"fmt"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 	hdwallet "github.com/miguelmota/go-ethereum-hdwallet"
 	bip39 "github.com/tyler-smith/go-bip39"
 )
 
-// GenerateMnemonic 生成BIP39标准的助记词
-// 参数: strength - 熟值强度（128位生成12个单词，256位生成24个单词）
+// GenerateMnemonic 生成BIP39标准的英文助记词
+// 参数: strength - 熵强度（128/160/192/224/256位分别生成12/15/18/21/24个单词，其他值按128位处理）
 // 返回: 助记词字符串和错误信息
 // 注意: 助记词是钱包恢复的唯一凭证，必须安全保存
 func GenerateMnemonic(strength int) (string, error) {
-	if strength != 128 && strength != 256 {
+	if strength < 128 || strength > 256 || strength%32 != 0 {
 		strength = 128
 	}
 	entropy, err := bip39.NewEntropy(strength)
@@ -51,7 +54,10 @@ func GenerateMnemonic(strength int) (string, error) {
 // 返回: 以太坊地址字符串（0x开头）和错误信息
 // 用途: 用于显示地址或验证钱包可访问性
 func DeriveAddressFromMnemonic(mnemonic, derivationPath string) (string, error) {
-	w, err := hdwallet.NewFromMnemonic(mnemonic)
+	if err := CheckDerivationPath(derivationPath); err != nil {
+		return "", err
+	}
+	w, err := newHDWallet(mnemonic)
 	if err != nil {
 		return "", fmt.Errorf("根据助记词创建钱包失败: %w", err)
 	}
@@ -80,7 +86,10 @@ func DeriveAddressFromMnemonic(mnemonic, derivationPath string) (string, error)
 // 用途: 用于交易签名，私钥需要安全处理
 // 警告: 私钥有超级权限，不可泄露给第三方
 func DerivePrivateKeyFromMnemonic(mnemonic, derivationPath string) (*ecdsa.PrivateKey, common.Address, error) {
-	w, err := hdwallet.NewFromMnemonic(mnemonic)
+	if err := CheckDerivationPath(derivationPath); err != nil {
+		return nil, common.Address{}, err
+	}
+	w, err := newHDWallet(mnemonic)
 	if err != nil {
 		return nil, common.Address{}, fmt.Errorf("根据助记词创建钱包失败: %w", err)
 	}
@@ -115,21 +124,96 @@ func DerivePrivateKeyFromMnemonic(mnemonic, derivationPath string) (*ecdsa.Priva
 // 用途: 为用户显示多个地址选项，或批量导入地址
 // 注意: 最终派生路径 = pathPrefix + "/{start+i}"，i从0到count-1
 func DeriveAddressesFromMnemonic(mnemonic, pathPrefix string, start, count int) ([]string, error) {
+	if pathPrefix == "" {
+		pathPrefix = "m/44'/60'/0'/0"
+	}
+	derived, err := deriveAddresses(mnemonic, start, count, func(index int) string {
+		return fmt.Sprintf("%s/%d", pathPrefix, index)
+	})
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]string, 0, len(derived))
+	for _, d := range derived {
+		addresses = append(addresses, d.Address)
+	}
+	return addresses, nil
+}
+
+// DerivationScheme 钱包派生路径标准
+// 不同钱包对“第N个账户”使用不同的路径模式，导入其他钱包的助记词时需选择对应标准才能得到相同地址
+type DerivationScheme string
+
+const (
+	// SchemeMetaMask MetaMask 等主流钱包：m/44'/60'/0'/0/N
+	SchemeMetaMask DerivationScheme = "metamask"
+	// SchemeLedgerLive Ledger Live：m/44'/60'/N'/0/0（按账户层级递增）
+	SchemeLedgerLive DerivationScheme = "ledger_live"
+	// SchemeLedgerLegacy Ledger 旧版（MEW/MyCrypto）：m/44'/60'/0'/N
+	SchemeLedgerLegacy DerivationScheme = "ledger_legacy"
+	// SchemeTrezorBIP44 Trezor 标准 BIP44：m/44'/60'/0'/0/N
+	SchemeTrezorBIP44 DerivationScheme = "trezor"
+)
+
+// DerivedAddress 派生出的地址及其完整派生路径
+type DerivedAddress struct {
+	Index   int    `json:"index"`   // 账户序号
+	Path    string `json:"path"`    // 完整派生路径
+	Address string `json:"address"` // 以太坊地址
+}
+
+// ParseDerivationScheme 解析派生标准名称（大小写不敏感），空字符串返回 MetaMask 标准
+func ParseDerivationScheme(name string) (DerivationScheme, error) {
+	scheme := DerivationScheme(strings.ToLower(strings.TrimSpace(name)))
+	switch scheme {
+	case "":
+		return SchemeMetaMask, nil
+	case SchemeMetaMask, SchemeLedgerLive, SchemeLedgerLegacy, SchemeTrezorBIP44:
+		return scheme, nil
+	}
+	return "", fmt.Errorf("不支持的派生标准: %s（可选 metamask、ledger_live、ledger_legacy、trezor）", name)
+}
+
+// Path 返回该标准下第 index 个账户的完整派生路径
+func (s DerivationScheme) Path(index int) string {
+	switch s {
+	case SchemeLedgerLive:
+		return fmt.Sprintf("m/44'/60'/%d'/0/0", index)
+	case SchemeLedgerLegacy:
+		return fmt.Sprintf("m/44'/60'/0'/%d", index)
+	default:
+		return fmt.Sprintf("m/44'/60'/0'/0/%d", index)
+	}
+}
+
+// DeriveAddressesScheme 按钱包派生标准从助记词批量生成地址，返回每个地址对应的完整路径
+func DeriveAddressesScheme(mnemonic string, scheme DerivationScheme, start, count int) ([]DerivedAddress, error) {
+	if _, err := ParseDerivationScheme(string(scheme)); err != nil {
+		return nil, err
+	}
+	return deriveAddresses(mnemonic, start, count, scheme.Path)
+}
+
+// deriveAddresses 按路径生成函数派生索引 start 到 start+count-1 的地址
+func deriveAddresses(mnemonic string, start, count int, pathFor func(index int) string) ([]DerivedAddress, error) {
 	if count <= 0 {
 		return nil, fmt.Errorf("count 必须大于 0")
 	}
-	if pathPrefix == "" {
-		pathPrefix = "m/44'/60'/0'/0"
+	if start < 0 {
+		return nil, fmt.Errorf("start 不能为负数")
 	}
 
-	w, err := hdwallet.NewFromMnemonic(mnemonic)
+	w, err := newHDWallet(mnemonic)
 	if err != nil {
 		return nil, fmt.Errorf("根据助记词创建钱包失败: %w", err)
 	}
 
-	addresses := make([]string, 0, count)
+	derived := make([]DerivedAddress, 0, count)
 	for i := 0; i < count; i++ {
-		fullPath := fmt.Sprintf("%s/%d", pathPrefix, start+i)
+		fullPath := pathFor(start + i)
+		if err := CheckDerivationPath(fullPath); err != nil {
+			return nil, err
+		}
 		path, err := hdwallet.ParseDerivationPath(fullPath)
 		if err != nil {
 			return nil, fmt.Errorf("解析派生路径失败(%s): %w", fullPath, err)
@@ -138,7 +222,7 @@ func DeriveAddressesFromMnemonic(mnemonic, pathPrefix string, start, count int)
 		if err != nil {
 			return nil, fmt.Errorf("派生账户失败(%s): %w", fullPath, err)
 		}
-		addresses = append(addresses, account.Address.Hex())
+		derived = append(derived, DerivedAddress{Index: start + i, Path: fullPath, Address: account.Address.Hex()})
 	}
-	return addresses, nil
+	return derived, nil
 }
@@ -0,0 +1,179 @@
+/*
+新区块订阅（eth_subscribe newHeads）
+
+通过节点的 WebSocket 端点订阅新区块头，供实时余额与到账推送使用：
+- WebSocket 地址来自网络配置 ws_url；未配置且 rpc_url 本身为 ws:// 或 wss:// 时直接使用 rpc_url
+- 每个订阅者独立建立上游连接，连接断开或订阅出错时按指数退避（1s 起，最长 30s）自动重连
+- ctx 取消后取消订阅并关闭连接
+
+重连期间可能漏掉若干区块，调用方应以最新区块状态（如余额）为准，而不是依赖每个区块都被推送。
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	headReconnectMinBackoff = time.Second
+	headReconnectMaxBackoff = 30 * time.Second
+	headChannelSize         = 16
+)
+
+// IncomingTransfer 转入被监听地址的原生代币交易或 ERC20 转账
+type IncomingTransfer struct {
+	TxHash      string   `json:"tx_hash"`             // 交易哈希
+	From        string   `json:"from"`                // 发送地址
+	To          string   `json:"to"`                  // 接收地址（被监听地址）
+	Value       *big.Int `json:"value"`               // 转账金额（最小单位）
+	Token       string   `json:"token,omitempty"`     // ERC20 合约地址，原生代币为空
+	LogIndex    uint     `json:"log_index,omitempty"` // ERC20 转账所在日志序号
+	BlockNumber uint64   `json:"block_number"`        // 所在区块
+}
+
+// NewHeadsSubscriber 新区块头订阅器
+type NewHeadsSubscriber struct {
+	wsURL string
+	dial  DialContextFunc // 为空时使用默认拨号
+}
+
+// SetWSURL 设置节点 WebSocket 地址（如 wss://ethereum-rpc.publicnode.com），为空时禁用订阅
+func (a *EVMAdapter) SetWSURL(wsURL string) {
+	a.wsURL = strings.TrimSpace(wsURL)
+}
+
+// WSURL 返回可用于订阅的 WebSocket 地址（未配置时为空字符串）
+func (a *EVMAdapter) WSURL() string {
+	return a.wsURL
+}
+
+// wsURLFor 选择订阅用的 WebSocket 地址：优先 ws_url，其次 ws(s):// 形式的 rpc_url
+func wsURLFor(rpcURL, wsURL string) string {
+	if wsURL = strings.TrimSpace(wsURL); wsURL != "" {
+		return wsURL
+	}
+	lower := strings.ToLower(strings.TrimSpace(rpcURL))
+	if strings.HasPrefix(lower, "ws://") || strings.HasPrefix(lower, "wss://") {
+		return strings.TrimSpace(rpcURL)
+	}
+	return ""
+}
+
+// NewHeadsSubscriber 创建新区块头订阅器，网络未配置 WebSocket 地址时返回错误
+func (a *EVMAdapter) NewHeadsSubscriber() (*NewHeadsSubscriber, error) {
+	if a.wsURL == "" {
+		return nil, fmt.Errorf("网络未配置WebSocket节点地址(ws_url)")
+	}
+	return &NewHeadsSubscriber{wsURL: a.wsURL, dial: a.dial}, nil
+}
+
+// Run 持续订阅新区块头并回调 onHead，阻塞直到 ctx 取消
+// 上游连接断开时自动重连，onHead 在同一协程内串行调用
+func (s *NewHeadsSubscriber) Run(ctx context.Context, onHead func(*types.Header)) {
+	backoff := headReconnectMinBackoff
+	for {
+		err := s.subscribeOnce(ctx, onHead, func() { backoff = headReconnectMinBackoff })
+		if ctx.Err() != nil {
+			return
+		}
+		slog.DebugContext(ctx, "new heads subscription interrupted, reconnecting", "backoff", backoff.String(), "error", err)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > headReconnectMaxBackoff {
+			backoff = headReconnectMaxBackoff
+		}
+	}
+}
+
+// subscribeOnce 建立一次上游连接并转发区块头，连接或订阅失败时返回错误
+// 收到第一个区块头后调用 onConnected，用于重置退避时间
+func (s *NewHeadsSubscriber) subscribeOnce(ctx context.Context, onHead func(*types.Header), onConnected func()) error {
+	rc, err := rpc.DialOptions(ctx, s.wsURL, rpcDialOptions(s.dial)...)
+	if err != nil {
+		return fmt.Errorf("连接WebSocket节点失败: %w", err)
+	}
+	client := ethclient.NewClient(rc)
+	defer client.Close()
+
+	heads := make(chan *types.Header, headChannelSize)
+	sub, err := client.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		return fmt.Errorf("订阅新区块失败: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	connected := false
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			if err == nil {
+				err = fmt.Errorf("订阅已关闭")
+			}
+			return err
+		case header := <-heads:
+			if !connected {
+				connected = true
+				onConnected()
+			}
+			onHead(header)
+		}
+	}
+}
+
+// IncomingTransfers 查找区块中转入被监听地址的原生代币交易
+func (a *EVMAdapter) IncomingTransfers(ctx context.Context, blockHash common.Hash, watched map[common.Address]bool) ([]IncomingTransfer, error) {
+	if len(watched) == 0 {
+		return nil, nil
+	}
+	block, err := a.client.BlockByHash(ctx, blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("获取区块失败: %w", err)
+	}
+
+	chainID, err := a.client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链ID失败: %w", err)
+	}
+	return nativeIncomingTransfers(block, types.LatestSignerForChainID(chainID), watched), nil
+}
+
+// nativeIncomingTransfers 区块中转入被监听地址且金额非零的原生代币交易
+func nativeIncomingTransfers(block *types.Block, signer types.Signer, watched map[common.Address]bool) []IncomingTransfer {
+	var transfers []IncomingTransfer
+	for _, tx := range block.Transactions() {
+		to := tx.To()
+		if to == nil || !watched[*to] || tx.Value().Sign() == 0 {
+			continue
+		}
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			continue
+		}
+		transfers = append(transfers, IncomingTransfer{
+			TxHash:      tx.Hash().Hex(),
+			From:        from.Hex(),
+			To:          to.Hex(),
+			Value:       tx.Value(),
+			BlockNumber: block.NumberU64(),
+		})
+	}
+	return transfers
+}
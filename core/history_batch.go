@@ -0,0 +1,73 @@
+/*
+交易历史扫描的自适应批次大小
+
+不同RPC节点的处理能力与限流策略差异很大，固定批次要么过慢要么触发限流。
+本文件按 AIMD（加性增、乘性减）策略调整每批扫描的区块数：
+- 批次耗时低于目标且无错误：批次 += 步长（加性增）
+- 批次耗时超过目标或出现区块获取失败：批次减半（乘性减）
+批次大小始终限制在配置的 [min, max] 范围内，并按适配器（节点）独立维护。
+*/
+package core
+
+import (
+	"sync"
+	"time"
+	"wallet/config"
+)
+
+// adaptiveBatchSizer 按节点表现自适应的批次大小
+type adaptiveBatchSizer struct {
+	size   uint64        // 当前批次大小
+	min    uint64        // 下限
+	max    uint64        // 上限
+	step   uint64        // 加性增步长
+	target time.Duration // 单批目标耗时
+	mu     sync.Mutex
+}
+
+// newAdaptiveBatchSizer 根据配置创建批次大小调节器
+func newAdaptiveBatchSizer(cfg config.HistoryConfig) *adaptiveBatchSizer {
+	cfg = cfg.WithDefaults()
+	step := cfg.MinBatchSize
+	if step == 0 {
+		step = 1
+	}
+	return &adaptiveBatchSizer{
+		size:   cfg.InitialBatchSize,
+		min:    cfg.MinBatchSize,
+		max:    cfg.MaxBatchSize,
+		step:   step,
+		target: time.Duration(cfg.TargetLatencyMs) * time.Millisecond,
+	}
+}
+
+// Current 返回当前批次大小
+func (b *adaptiveBatchSizer) Current() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.size
+}
+
+// Observe 记录一批扫描的结果并调整批次大小
+// 参数: blocks - 本批区块数；elapsed - 本批耗时；failures - 获取失败的区块数
+func (b *adaptiveBatchSizer) Observe(blocks uint64, elapsed time.Duration, failures int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if failures > 0 || elapsed > b.target {
+		b.size /= 2
+		if b.size < b.min {
+			b.size = b.min
+		}
+		return
+	}
+
+	// 最后一批可能不足当前批次，不足时不增长，避免据此误判
+	if blocks < b.size {
+		return
+	}
+	b.size += b.step
+	if b.size > b.max {
+		b.size = b.max
+	}
+}
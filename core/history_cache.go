@@ -0,0 +1,222 @@
+/*
+交易历史缓存
+
+分页浏览交易历史时每页请求都会重新收集整个区块范围，代价与范围大小成正比。设置 HistoryStore 后
+GetTransactionHistory 先把请求范围同步到存储，再由存储过滤、排序与分页：
+  - 存储按 (网络, 地址) 记录已扫描的区块区间及区间内的全部相关交易（不按类型过滤），
+    tx_type、sort_by 与 sort_order 在每次查询时应用，不同参数的请求共用同一份数据
+  - 请求范围从已确认区间内开始时只增量扫描之后的区块；否则重新扫描请求范围并替换该地址的原有记录
+  - 达到网络确认数的区块计入已确认区间；之后的区块可能重组，链头变化后重新扫描并覆盖
+
+内置内存存储（NewMemoryHistoryStore，按空闲时间与地址数淘汰），服务层可提供数据库存储以持久化并用 SQL 分页。
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HistoryCoverage 地址已扫描的区块区间
+type HistoryCoverage struct {
+	From        uint64 `json:"from"`         // 起始区块（含）
+	ConfirmedTo uint64 `json:"confirmed_to"` // 已确认部分的结束区块（不含），之后的区块可能重组
+	ScannedTo   uint64 `json:"scanned_to"`   // 实际扫描到的区块（含未确认部分）
+	Head        uint64 `json:"head"`         // 扫描时的链头
+}
+
+// HistoryStore 交易历史存储，address 为小写地址
+type HistoryStore interface {
+	// HistoryCoverage 返回已扫描的区块区间，未扫描过时返回 nil
+	HistoryCoverage(networkID, address string) (*HistoryCoverage, error)
+	// SaveHistory 删除区块号不小于 replaceFrom 的已存交易，写入 txs 并更新扫描区间
+	SaveHistory(networkID, address string, coverage HistoryCoverage, replaceFrom uint64, txs []TransactionInfo) error
+	// QueryHistory 按 req 的区块范围与交易类型过滤、排序后返回一页交易及过滤后的总数
+	QueryHistory(networkID, address string, req *TransactionHistoryRequest) ([]TransactionInfo, int, error)
+}
+
+// SetHistoryStore 设置交易历史存储，networkID 为该适配器对应的网络；store 为空时每次查询重新扫描
+func (a *EVMAdapter) SetHistoryStore(networkID string, store HistoryStore) {
+	a.historyStore = store
+	a.historyNetwork = networkID
+}
+
+// syncHistory 将 [start, end] 范围内与 address 相关的交易同步到存储，只扫描尚未扫描或可能重组的区块
+func (a *EVMAdapter) syncHistory(ctx context.Context, address string, start, end uint64) error {
+	coverage, err := a.historyStore.HistoryCoverage(a.historyNetwork, address)
+	if err != nil {
+		return fmt.Errorf("读取交易历史缓存失败: %w", err)
+	}
+	head, err := a.client.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("获取最新区块失败: %w", err)
+	}
+
+	next := HistoryCoverage{From: start, ScannedTo: end, Head: head}
+	scanFrom, replaceFrom := start, uint64(0)
+	if coverage != nil && start >= coverage.From && start <= coverage.ConfirmedTo {
+		if end < coverage.ConfirmedTo || (head == coverage.Head && end <= coverage.ScannedTo) {
+			return nil
+		}
+		next.From = coverage.From
+		scanFrom, replaceFrom = coverage.ConfirmedTo, coverage.ConfirmedTo
+	}
+
+	txs, err := a.collectIndexedTransactions(ctx, address, scanFrom, end, "all")
+	if err != nil {
+		return err
+	}
+	next.ConfirmedTo = scanFrom
+	if head >= a.confirmations {
+		if stable := head - a.confirmations + 1; stable > next.ConfirmedTo {
+			next.ConfirmedTo = stable
+		}
+	}
+	if next.ConfirmedTo > end+1 {
+		next.ConfirmedTo = end + 1
+	}
+	if err := a.historyStore.SaveHistory(a.historyNetwork, address, next, replaceFrom, txs); err != nil {
+		return fmt.Errorf("写入交易历史缓存失败: %w", err)
+	}
+	return nil
+}
+
+// MemoryHistoryStore 进程内的交易历史存储，超过 ttl 未访问的地址被淘汰，地址数超过上限时淘汰最久未访问的
+type MemoryHistoryStore struct {
+	mu         sync.Mutex
+	entries    map[string]*memoryHistoryEntry
+	ttl        time.Duration
+	maxEntries int
+}
+
+type memoryHistoryEntry struct {
+	coverage HistoryCoverage
+	txs      []TransactionInfo
+	touched  time.Time
+}
+
+// NewMemoryHistoryStore 创建内存交易历史存储
+func NewMemoryHistoryStore(ttl time.Duration, maxEntries int) *MemoryHistoryStore {
+	return &MemoryHistoryStore{entries: make(map[string]*memoryHistoryEntry), ttl: ttl, maxEntries: maxEntries}
+}
+
+func (s *MemoryHistoryStore) HistoryCoverage(networkID, address string) (*HistoryCoverage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := s.entryLocked(networkID, address)
+	if entry == nil {
+		return nil, nil
+	}
+	coverage := entry.coverage
+	return &coverage, nil
+}
+
+func (s *MemoryHistoryStore) SaveHistory(networkID, address string, coverage HistoryCoverage, replaceFrom uint64, txs []TransactionInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := networkID + "|" + address
+	entry := s.entryLocked(networkID, address)
+	if entry == nil {
+		entry = &memoryHistoryEntry{}
+		s.entries[key] = entry
+		s.evictLocked(key)
+	}
+	added := make(map[string]bool, len(txs))
+	for _, tx := range txs {
+		added[tx.Hash] = true
+	}
+	kept := make([]TransactionInfo, 0, len(entry.txs)+len(txs))
+	for _, tx := range entry.txs {
+		if txBlockNumber(tx) < replaceFrom && !added[tx.Hash] {
+			kept = append(kept, tx)
+		}
+	}
+	entry.txs = append(kept, txs...)
+	entry.coverage = coverage
+	entry.touched = time.Now()
+	return nil
+}
+
+func (s *MemoryHistoryStore) QueryHistory(networkID, address string, req *TransactionHistoryRequest) ([]TransactionInfo, int, error) {
+	s.mu.Lock()
+	var matched []TransactionInfo
+	if entry := s.entryLocked(networkID, address); entry != nil {
+		for _, tx := range entry.txs {
+			if block := txBlockNumber(tx); block >= req.StartBlock && block <= req.EndBlock && matchesTxType(tx, req.TxType) {
+				matched = append(matched, tx)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	sortTransactions(matched, req.SortBy, req.SortOrder)
+	return paginateTransactions(matched, req.Page, req.Limit), len(matched), nil
+}
+
+// entryLocked 返回未过期的缓存项并刷新访问时间
+func (s *MemoryHistoryStore) entryLocked(networkID, address string) *memoryHistoryEntry {
+	key := networkID + "|" + address
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil
+	}
+	if s.ttl > 0 && time.Since(entry.touched) > s.ttl {
+		delete(s.entries, key)
+		return nil
+	}
+	entry.touched = time.Now()
+	return entry
+}
+
+// evictLocked 淘汰过期项，仍超过地址数上限时淘汰最久未访问的（keep 除外）
+func (s *MemoryHistoryStore) evictLocked(keep string) {
+	if s.maxEntries <= 0 || len(s.entries) <= s.maxEntries {
+		return
+	}
+	type aged struct {
+		key     string
+		touched time.Time
+	}
+	var candidates []aged
+	for key, entry := range s.entries {
+		if key == keep {
+			continue
+		}
+		if s.ttl > 0 && time.Since(entry.touched) > s.ttl {
+			delete(s.entries, key)
+			continue
+		}
+		candidates = append(candidates, aged{key, entry.touched})
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].touched.Before(candidates[j].touched) })
+	for _, c := range candidates {
+		if len(s.entries) <= s.maxEntries {
+			return
+		}
+		delete(s.entries, c.key)
+	}
+}
+
+// paginateTransactions 返回第 page 页（从1开始），超出范围时返回空列表
+func paginateTransactions(transactions []TransactionInfo, page, limit int) []TransactionInfo {
+	start := (page - 1) * limit
+	if start >= len(transactions) {
+		return []TransactionInfo{}
+	}
+	end := start + limit
+	if end > len(transactions) {
+		end = len(transactions)
+	}
+	return transactions[start:end]
+}
+
+// txBlockNumber 解析交易所在区块号，无法解析时返回 0
+func txBlockNumber(tx TransactionInfo) uint64 {
+	block, _ := strconv.ParseUint(strings.TrimSpace(tx.BlockNumber), 10, 64)
+	return block
+}
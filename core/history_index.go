@@ -0,0 +1,330 @@
+/*
+基于日志与区块浏览器索引的交易历史查询
+
+逐块扫描需要为每个区块与每笔相关交易发起RPC请求，公共节点上查询1000个区块即会超时。
+本文件改为按数据来源分别查询：
+- ERC20 转账：eth_getLogs 按 Transfer 事件 topic0 过滤，topics[1]/topics[2] 为用户地址，一次范围查询返回
+- 原生交易：网络配置了 explorer_api_url（Etherscan 风格）时通过 txlist 接口获取
+- 未配置区块浏览器或其请求失败时，原生交易回退为逐块扫描
+两路结果按交易哈希合并，代币交易补充回执中的事件与摘要。
+*/
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"wallet/config"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// logQueryBlockRange 单次 eth_getLogs 查询的最大区块跨度（多数公共节点限制在数千个区块内）
+const logQueryBlockRange = 2000
+
+// explorerHTTPClient 区块浏览器API请求客户端
+var explorerHTTPClient = &http.Client{Timeout: 15 * time.Second}
+
+// explorerTx Etherscan 风格 txlist 接口返回的交易
+type explorerTx struct {
+	Hash            string `json:"hash"`
+	BlockNumber     string `json:"blockNumber"`
+	BlockHash       string `json:"blockHash"`
+	TimeStamp       string `json:"timeStamp"`
+	Nonce           string `json:"nonce"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	Value           string `json:"value"`
+	Gas             string `json:"gas"`
+	GasPrice        string `json:"gasPrice"`
+	GasUsed         string `json:"gasUsed"`
+	IsError         string `json:"isError"`
+	TxReceiptStatus string `json:"txreceipt_status"`
+	Input           string `json:"input"`
+}
+
+// SetExplorerAPI 设置 Etherscan 风格的区块浏览器API地址（如 https://api.etherscan.io/api），为空时禁用
+func (a *EVMAdapter) SetExplorerAPI(apiURL string) {
+	a.explorerAPI = strings.TrimRight(strings.TrimSpace(apiURL), "/")
+}
+
+// ExplorerAPI 返回已配置的区块浏览器API地址（未配置时为空字符串）
+func (a *EVMAdapter) ExplorerAPI() string {
+	return a.explorerAPI
+}
+
+// collectIndexedTransactions 收集指定区块范围内与地址相关的交易
+// 仅查询 ERC20 时只发起日志查询；其余类型需要原生交易，优先使用区块浏览器，不可用时回退为区块扫描
+func (a *EVMAdapter) collectIndexedTransactions(ctx context.Context, address string, startBlock, endBlock uint64, txType string) ([]TransactionInfo, error) {
+	addr := common.HexToAddress(address)
+	byHash := make(map[string]TransactionInfo)
+	var order []string
+	add := func(tx TransactionInfo) {
+		if _, ok := byHash[tx.Hash]; !ok {
+			order = append(order, tx.Hash)
+		}
+		byHash[tx.Hash] = tx
+	}
+
+	if txType != "ERC20" {
+		var (
+			native []TransactionInfo
+			err    error
+		)
+		if a.explorerAPI != "" {
+			native, err = a.explorerTransactions(ctx, addr, startBlock, endBlock)
+			if err != nil {
+				slog.WarnContext(ctx, "block explorer query failed, falling back to block scan", "address", address, "error", err)
+			}
+		}
+		if a.explorerAPI == "" || err != nil {
+			native, err = a.collectTransactionsInRange(ctx, address, startBlock, endBlock, "all")
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, tx := range native {
+			add(tx)
+		}
+	}
+
+	logs, err := a.erc20TransferLogs(ctx, addr, startBlock, endBlock)
+	if err != nil {
+		return nil, err
+	}
+	blockTimes := make(map[uint64]uint64)
+	for _, lg := range logs {
+		hash := lg.TxHash.Hex()
+		// 区块扫描得到的交易已包含完整回执信息；区块浏览器的结果缺少事件，需要补充
+		if existing, ok := byHash[hash]; ok && len(existing.Events) > 0 {
+			continue
+		}
+		info, err := a.transactionInfoFromLog(ctx, lg, addr, blockTimes)
+		if err != nil {
+			return nil, err
+		}
+		add(*info)
+	}
+
+	transactions := make([]TransactionInfo, 0, len(order))
+	for _, hash := range order {
+		if tx := byHash[hash]; matchesTxType(tx, txType) {
+			transactions = append(transactions, tx)
+		}
+	}
+	return transactions, nil
+}
+
+// matchesTxType 判断交易是否符合类型过滤
+// 通过合约交互收到/转出代币（如兑换）的交易虽为 CONTRACT 类型，也计入 ERC20 结果
+func matchesTxType(tx TransactionInfo, txType string) bool {
+	switch txType {
+	case "", "all":
+		return true
+	case "ERC20":
+		return tx.TxType == "ERC20" || tx.TokenInfo != nil
+	default:
+		return tx.TxType == txType
+	}
+}
+
+// erc20TransferLogs 查询范围内转出或转入 addr 的 ERC20 Transfer 日志
+// ERC721 Transfer 的 topic0 相同，但 tokenId 为 indexed（共4个topic），此处排除
+func (a *EVMAdapter) erc20TransferLogs(ctx context.Context, addr common.Address, startBlock, endBlock uint64) ([]types.Log, error) {
+	addrTopic := common.BytesToHash(addr.Bytes())
+	queries := [][][]common.Hash{
+		{{transferEventTopic}, {addrTopic}},      // from = addr
+		{{transferEventTopic}, nil, {addrTopic}}, // to = addr
+	}
+
+	var logs []types.Log
+	for from := startBlock; from <= endBlock; from += logQueryBlockRange {
+		to := from + logQueryBlockRange - 1
+		if to > endBlock || to < from {
+			to = endBlock
+		}
+		for _, topics := range queries {
+			result, err := a.client.FilterLogs(ctx, ethereum.FilterQuery{
+				FromBlock: new(big.Int).SetUint64(from),
+				ToBlock:   new(big.Int).SetUint64(to),
+				Topics:    topics,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("查询Transfer日志失败（区块 %d-%d）: %w", from, to, err)
+			}
+			for _, lg := range result {
+				if !lg.Removed && len(lg.Topics) == 3 {
+					logs = append(logs, lg)
+				}
+			}
+		}
+		if to == endBlock {
+			break
+		}
+	}
+	return logs, nil
+}
+
+// DetectERC20Tokens 扫描最近 lookbackBlocks 个区块内与 address 相关的 ERC20 Transfer 日志，
+// 返回出现过的代币合约地址（checksum 格式，按首次出现顺序去重）
+func (a *EVMAdapter) DetectERC20Tokens(ctx context.Context, address string, lookbackBlocks uint64) ([]string, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("无效的地址: %s", address)
+	}
+	latest, err := a.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取最新区块失败: %w", err)
+	}
+	start := uint64(0)
+	if latest > lookbackBlocks {
+		start = latest - lookbackBlocks + 1
+	}
+	logs, err := a.erc20TransferLogs(ctx, common.HexToAddress(address), start, latest)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[common.Address]bool)
+	var tokens []string
+	for _, lg := range logs {
+		if seen[lg.Address] {
+			continue
+		}
+		seen[lg.Address] = true
+		tokens = append(tokens, lg.Address.Hex())
+	}
+	return tokens, nil
+}
+
+// transactionInfoFromLog 根据日志所在交易构建交易信息，区块时间按区块号缓存
+func (a *EVMAdapter) transactionInfoFromLog(ctx context.Context, lg types.Log, userAddr common.Address, blockTimes map[uint64]uint64) (*TransactionInfo, error) {
+	tx, _, err := a.client.TransactionByHash(ctx, lg.TxHash)
+	if err != nil {
+		return nil, fmt.Errorf("获取交易 %s 失败: %w", lg.TxHash.Hex(), err)
+	}
+	receipt, err := a.client.TransactionReceipt(ctx, lg.TxHash)
+	if err != nil {
+		return nil, fmt.Errorf("获取交易回执 %s 失败: %w", lg.TxHash.Hex(), err)
+	}
+	blockTime, ok := blockTimes[lg.BlockNumber]
+	if !ok {
+		header, err := a.client.HeaderByNumber(ctx, new(big.Int).SetUint64(lg.BlockNumber))
+		if err != nil {
+			return nil, fmt.Errorf("获取区块 %d 失败: %w", lg.BlockNumber, err)
+		}
+		blockTime = header.Time
+		blockTimes[lg.BlockNumber] = blockTime
+	}
+	return a.buildTransactionInfo(tx, receipt, blockTime, userAddr), nil
+}
+
+// explorerTransactions 通过区块浏览器 txlist 接口获取地址的外部交易
+// API密钥优先使用当前用户配置的 etherscan 密钥，其次为全局密钥
+func (a *EVMAdapter) explorerTransactions(ctx context.Context, addr common.Address, startBlock, endBlock uint64) ([]TransactionInfo, error) {
+	params := url.Values{}
+	params.Set("module", "account")
+	params.Set("action", "txlist")
+	params.Set("address", addr.Hex())
+	params.Set("startblock", strconv.FormatUint(startBlock, 10))
+	params.Set("endblock", strconv.FormatUint(endBlock, 10))
+	params.Set("sort", "asc")
+	apiKey := ProviderKeyFromContext(ctx, "etherscan")
+	if apiKey == "" {
+		apiKey = config.AppConfig.Security.ProviderKeys["etherscan"]
+	}
+	if apiKey != "" {
+		params.Set("apikey", apiKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.explorerAPI+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := explorerHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求区块浏览器失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("区块浏览器返回错误: %s", resp.Status)
+	}
+
+	var body struct {
+		Status  string          `json:"status"`
+		Message string          `json:"message"`
+		Result  json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("解析区块浏览器响应失败: %w", err)
+	}
+	if body.Status != "1" {
+		if strings.HasPrefix(body.Message, "No transactions found") {
+			return []TransactionInfo{}, nil
+		}
+		var reason string
+		if json.Unmarshal(body.Result, &reason) != nil {
+			reason = string(body.Result)
+		}
+		return nil, fmt.Errorf("区块浏览器返回错误: %s %s", body.Message, reason)
+	}
+	var items []explorerTx
+	if err := json.Unmarshal(body.Result, &items); err != nil {
+		return nil, fmt.Errorf("解析区块浏览器交易列表失败: %w", err)
+	}
+
+	transactions := make([]TransactionInfo, 0, len(items))
+	for _, item := range items {
+		transactions = append(transactions, item.toTransactionInfo())
+	}
+	return transactions, nil
+}
+
+// toTransactionInfo 转换为交易信息；ERC20 代币明细由日志查询补充
+func (t explorerTx) toTransactionInfo() TransactionInfo {
+	nonce, _ := strconv.ParseUint(t.Nonce, 10, 64)
+	timestamp, _ := strconv.ParseUint(t.TimeStamp, 10, 64)
+	info := TransactionInfo{
+		Hash:        common.HexToHash(t.Hash).Hex(),
+		From:        checksumOrEmpty(t.From),
+		To:          checksumOrEmpty(t.To),
+		Value:       t.Value,
+		GasPrice:    t.GasPrice,
+		GasUsed:     t.GasUsed,
+		GasLimit:    t.Gas,
+		Nonce:       nonce,
+		BlockNumber: t.BlockNumber,
+		BlockHash:   t.BlockHash,
+		Timestamp:   timestamp,
+		TxType:      "ETH",
+	}
+	// 拜占庭分叉前的交易没有 txreceipt_status，以 isError 判断
+	if t.TxReceiptStatus == "1" || (t.TxReceiptStatus == "" && t.IsError == "0") {
+		info.Status = 1
+	}
+	input := strings.ToLower(t.Input)
+	switch {
+	case input == "" || input == "0x":
+	case strings.HasPrefix(input, "0xa9059cbb"): // transfer(address,uint256)
+		info.TxType = "ERC20"
+	default:
+		info.TxType = "CONTRACT"
+	}
+	return info
+}
+
+// checksumOrEmpty 将地址转换为 checksum 格式，空地址（合约创建）保持为空
+func checksumOrEmpty(address string) string {
+	if !common.IsHexAddress(address) {
+		return ""
+	}
+	return common.HexToAddress(address).Hex()
+}
@@ -0,0 +1,23 @@
+/*
+发送类请求的幂等键
+
+客户端重试（网络抖动、重复点击）时携带相同的 Idempotency-Key，服务端只处理一次并向重试返回首次的响应，
+避免同一笔转账被广播两次。键的存储见 services.IdempotencyService，HTTP 处理见 middleware.Idempotency。
+*/
+package core
+
+import "errors"
+
+// 发送类请求的 Idempotency-Key 去重错误（由幂等中间件转换为 HTTP 响应）
+var (
+	// ErrIdempotencyInProgress 相同幂等键的请求仍在处理中
+	ErrIdempotencyInProgress = errors.New("相同 Idempotency-Key 的请求正在处理中")
+	// ErrIdempotencyKeyReused 幂等键已用于内容不同的请求
+	ErrIdempotencyKeyReused = errors.New("Idempotency-Key 已用于内容不同的请求")
+)
+
+// IdempotentResponse 幂等键对应的首次请求响应
+type IdempotentResponse struct {
+	Status int
+	Body   []byte
+}
@@ -0,0 +1,110 @@
+/*
+按区块范围查找转入被监听地址的转账（观察地址到账通知）
+
+  - ERC20：eth_getLogs 按 Transfer 事件 topic0 过滤，topics[2]（to）为被监听地址集合，
+    每次最多查询 logQueryBlockRange 个区块、incomingTopicBatch 个地址，与交易历史的日志查询方式一致
+  - 原生代币：逐块获取区块交易，筛选 to 为被监听地址且金额非零的交易（不含合约内部转账）
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// incomingTopicBatch 单次日志查询的接收地址数（topic 的 OR 集合）
+const incomingTopicBatch = 100
+
+// LatestBlockNumber 获取最新区块号
+func (a *EVMAdapter) LatestBlockNumber(ctx context.Context) (uint64, error) {
+	number, err := a.client.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("获取最新区块失败: %w", err)
+	}
+	return number, nil
+}
+
+// IncomingTransfersInRange 查找 [fromBlock, toBlock] 内转入被监听地址的原生代币交易与 ERC20 转账
+func (a *EVMAdapter) IncomingTransfersInRange(ctx context.Context, fromBlock, toBlock uint64, watched map[common.Address]bool) ([]IncomingTransfer, error) {
+	if len(watched) == 0 || fromBlock > toBlock {
+		return nil, nil
+	}
+	chainID, err := a.client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链ID失败: %w", err)
+	}
+	signer := types.LatestSignerForChainID(chainID)
+
+	var transfers []IncomingTransfer
+	for number := fromBlock; number <= toBlock; number++ {
+		block, err := a.client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			return nil, fmt.Errorf("获取区块 %d 失败: %w", number, err)
+		}
+		transfers = append(transfers, nativeIncomingTransfers(block, signer, watched)...)
+	}
+
+	tokenTransfers, err := a.erc20IncomingTransfers(ctx, fromBlock, toBlock, watched)
+	if err != nil {
+		return nil, err
+	}
+	return append(transfers, tokenTransfers...), nil
+}
+
+// erc20IncomingTransfers 查询范围内 to 为被监听地址的 ERC20 Transfer 日志
+// ERC721 Transfer 的 topic0 相同，但 tokenId 为 indexed（共4个topic），此处排除
+func (a *EVMAdapter) erc20IncomingTransfers(ctx context.Context, fromBlock, toBlock uint64, watched map[common.Address]bool) ([]IncomingTransfer, error) {
+	topics := make([]common.Hash, 0, len(watched))
+	for addr := range watched {
+		topics = append(topics, common.BytesToHash(addr.Bytes()))
+	}
+
+	var transfers []IncomingTransfer
+	for from := fromBlock; from <= toBlock; from += logQueryBlockRange {
+		to := from + logQueryBlockRange - 1
+		if to > toBlock || to < from {
+			to = toBlock
+		}
+		for start := 0; start < len(topics); start += incomingTopicBatch {
+			end := start + incomingTopicBatch
+			if end > len(topics) {
+				end = len(topics)
+			}
+			logs, err := a.client.FilterLogs(ctx, ethereum.FilterQuery{
+				FromBlock: new(big.Int).SetUint64(from),
+				ToBlock:   new(big.Int).SetUint64(to),
+				Topics:    [][]common.Hash{{transferEventTopic}, nil, topics[start:end]},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("查询Transfer日志失败（区块 %d-%d）: %w", from, to, err)
+			}
+			for _, lg := range logs {
+				if lg.Removed || len(lg.Topics) != 3 || len(lg.Data) < 32 {
+					continue
+				}
+				value := new(big.Int).SetBytes(lg.Data[:32])
+				if value.Sign() == 0 {
+					continue
+				}
+				transfers = append(transfers, IncomingTransfer{
+					TxHash:      lg.TxHash.Hex(),
+					From:        common.BytesToAddress(lg.Topics[1].Bytes()).Hex(),
+					To:          common.BytesToAddress(lg.Topics[2].Bytes()).Hex(),
+					Value:       value,
+					Token:       lg.Address.Hex(),
+					LogIndex:    lg.Index,
+					BlockNumber: lg.BlockNumber,
+				})
+			}
+		}
+		if to == toBlock {
+			break
+		}
+	}
+	return transfers, nil
+}
@@ -0,0 +1,76 @@
+/*
+IP白名单
+
+签名、发送等敏感操作只允许来自白名单中的客户端IP：
+- 规则为单个IP或CIDR网段，单个IP规范化为 /32（IPv4）或 /128（IPv6）
+- 规则列表为空表示不限制
+- IPv4 映射的 IPv6 地址（::ffff:a.b.c.d）按 IPv4 匹配
+*/
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+var (
+	// ErrIPNotWhitelisted 客户端IP不在白名单中
+	ErrIPNotWhitelisted = errors.New("客户端IP不在白名单中")
+	// ErrIPWhitelistNoOwner 请求既没有有效会话也没有助记词，无法确定按哪个钱包的白名单校验
+	ErrIPWhitelistNoOwner = errors.New("无法确定请求所属钱包，需要有效的会话或助记词")
+	// ErrIPWhitelistOwnerMismatch 认证令牌所属钱包与请求中用于签名的钱包不一致
+	ErrIPWhitelistOwnerMismatch = errors.New("请求中的会话或助记词不属于当前登录的钱包")
+)
+
+// NormalizeIPRule 校验并规范化白名单规则（单个IP或CIDR）
+// 例如 "10.0.0.7" -> "10.0.0.7/32"，"10.0.0.7/24" -> "10.0.0.0/24"
+func NormalizeIPRule(rule string) (string, error) {
+	rule = strings.TrimSpace(rule)
+	if rule == "" {
+		return "", fmt.Errorf("IP规则不能为空")
+	}
+	if strings.Contains(rule, "/") {
+		prefix, err := netip.ParsePrefix(rule)
+		if err != nil {
+			return "", fmt.Errorf("无效的CIDR: %s", rule)
+		}
+		if prefix.Addr().Is4In6() {
+			bits := prefix.Bits() - 96
+			if bits < 0 {
+				return "", fmt.Errorf("无效的CIDR: %s", rule)
+			}
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), bits)
+		}
+		return prefix.Masked().String(), nil
+	}
+	addr, err := netip.ParseAddr(rule)
+	if err != nil {
+		return "", fmt.Errorf("无效的IP地址: %s", rule)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()).String(), nil
+}
+
+// IPAllowed 判断IP是否匹配任一规则；规则为空时允许所有IP，无法解析的IP或规则不匹配
+func IPAllowed(ip string, rules []string) bool {
+	if len(rules) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, rule := range rules {
+		normalized, err := NormalizeIPRule(rule)
+		if err != nil {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(normalized); err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
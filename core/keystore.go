@@ -0,0 +1,216 @@
+/*
+Keystore（V3）导入导出
+
+Keystore 文件是以太坊客户端通用的私钥加密格式（scrypt/pbkdf2 + AES-128-CTR，MAC 校验密码）：
+- ImportKeystore 解密 Keystore，私钥保存在进程内存中并返回句柄，调用方通过 KeyHandleSigner 使用后调用 ReleaseKeyHandle 释放
+- ExportKeystore 从助记词派生私钥并重新加密为 V3 Keystore，用于备份
+
+句柄在 keyHandleTTL 后自动失效，避免调用方遗漏释放时私钥长期驻留内存。
+*/
+package core
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// keyHandleTTL 私钥句柄的有效期
+const keyHandleTTL = 10 * time.Minute
+
+// minKeystorePasswordLength 导出 Keystore 的最短密码长度
+const minKeystorePasswordLength = 8
+
+// ErrKeystorePassword Keystore 密码错误（MAC 校验失败）
+var ErrKeystorePassword = errors.New("Keystore 密码错误（MAC 校验失败），请确认密码后重试")
+
+// keyHandleEntry 已解密的私钥
+type keyHandleEntry struct {
+	priv      *ecdsa.PrivateKey
+	expiresAt time.Time
+}
+
+var (
+	keyHandles   = make(map[string]*keyHandleEntry)
+	keyHandlesMu sync.Mutex
+)
+
+// ImportKeystore 使用密码解密 V3 Keystore，返回地址与私钥句柄
+func ImportKeystore(keystoreJSON []byte, password string) (string, string, error) {
+	var header struct {
+		Address string          `json:"address"`
+		Crypto  json.RawMessage `json:"crypto"`
+		Version int             `json:"version"`
+	}
+	if err := json.Unmarshal(keystoreJSON, &header); err != nil {
+		return "", "", fmt.Errorf("Keystore 格式无效: %w", err)
+	}
+	if header.Version != 3 || len(header.Crypto) == 0 {
+		return "", "", fmt.Errorf("仅支持 V3 格式的 Keystore")
+	}
+	if password == "" {
+		return "", "", fmt.Errorf("Keystore 密码不能为空")
+	}
+
+	key, err := keystore.DecryptKey(keystoreJSON, password)
+	if err != nil {
+		if errors.Is(err, keystore.ErrDecrypt) {
+			return "", "", ErrKeystorePassword
+		}
+		return "", "", fmt.Errorf("解密 Keystore 失败: %w", err)
+	}
+	// 文件中的地址与私钥不一致说明 Keystore 被篡改
+	if header.Address != "" && common.HexToAddress(header.Address) != key.Address {
+		return "", "", fmt.Errorf("Keystore 地址与私钥不匹配")
+	}
+
+	handle, err := storeKeyHandle(key.PrivateKey)
+	if err != nil {
+		return "", "", err
+	}
+	return key.Address.Hex(), handle, nil
+}
+
+// ExportKeystore 从助记词按派生路径派生私钥，并用密码加密为 V3 Keystore JSON
+func ExportKeystore(mnemonic, derivationPath, password string) ([]byte, error) {
+	if len(password) < minKeystorePasswordLength {
+		return nil, fmt.Errorf("Keystore 密码至少需要 %d 个字符", minKeystorePasswordLength)
+	}
+	priv, _, err := DerivePrivateKeyFromMnemonic(mnemonic, derivationPath)
+	if err != nil {
+		return nil, err
+	}
+	return EncryptPrivateKeyToKeystore(priv, password)
+}
+
+// EncryptPrivateKeyToKeystore 使用标准 scrypt 参数将私钥加密为 V3 Keystore JSON
+func EncryptPrivateKeyToKeystore(priv *ecdsa.PrivateKey, password string) ([]byte, error) {
+	id, err := newKeystoreID()
+	if err != nil {
+		return nil, err
+	}
+	key := &keystore.Key{
+		Address:    crypto.PubkeyToAddress(priv.PublicKey),
+		PrivateKey: priv,
+	}
+	copy(key.Id[:], id)
+	keyJSON, err := keystore.EncryptKey(key, password, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		return nil, fmt.Errorf("加密 Keystore 失败: %w", err)
+	}
+	return keyJSON, nil
+}
+
+// KeyHandleSigner 根据私钥句柄创建签名者
+func KeyHandleSigner(handle string) (*PrivateKeySigner, error) {
+	keyHandlesMu.Lock()
+	defer keyHandlesMu.Unlock()
+	purgeExpiredKeyHandles(time.Now())
+	entry, ok := keyHandles[handle]
+	if !ok {
+		return nil, fmt.Errorf("私钥句柄不存在或已过期")
+	}
+	return NewPrivateKeySigner(entry.priv), nil
+}
+
+// ReleaseKeyHandle 释放私钥句柄
+func ReleaseKeyHandle(handle string) {
+	keyHandlesMu.Lock()
+	defer keyHandlesMu.Unlock()
+	delete(keyHandles, handle)
+}
+
+// storeKeyHandle 保存私钥并返回随机句柄
+func storeKeyHandle(priv *ecdsa.PrivateKey) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成私钥句柄失败: %w", err)
+	}
+	handle := hex.EncodeToString(b)
+
+	keyHandlesMu.Lock()
+	defer keyHandlesMu.Unlock()
+	now := time.Now()
+	purgeExpiredKeyHandles(now)
+	keyHandles[handle] = &keyHandleEntry{priv: priv, expiresAt: now.Add(keyHandleTTL)}
+	return handle, nil
+}
+
+// purgeExpiredKeyHandles 清理过期句柄（调用方持有锁）
+func purgeExpiredKeyHandles(now time.Time) {
+	for handle, entry := range keyHandles {
+		if now.After(entry.expiresAt) {
+			delete(keyHandles, handle)
+		}
+	}
+}
+
+// newKeystoreID 生成 Keystore 的随机 UUID（v4）
+func newKeystoreID() ([]byte, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("生成 Keystore ID 失败: %w", err)
+	}
+	id[6] = (id[6] & 0x0f) | 0x40
+	id[8] = (id[8] & 0x3f) | 0x80
+	return id, nil
+}
+
+// PrivateKeySigner 基于明文私钥的签名者（Keystore 导入的账户）
+type PrivateKeySigner struct {
+	priv    *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewPrivateKeySigner 由私钥创建签名者
+func NewPrivateKeySigner(priv *ecdsa.PrivateKey) *PrivateKeySigner {
+	return &PrivateKeySigner{priv: priv, address: crypto.PubkeyToAddress(priv.PublicKey)}
+}
+
+// PrivateKeySignerFromBytes 由32字节私钥创建签名者
+func PrivateKeySignerFromBytes(key []byte) (*PrivateKeySigner, error) {
+	priv, err := crypto.ToECDSA(key)
+	if err != nil {
+		return nil, fmt.Errorf("私钥无效: %w", err)
+	}
+	return NewPrivateKeySigner(priv), nil
+}
+
+// PrivateKeyBytes 返回32字节私钥（调用方负责在使用后清零）
+func (s *PrivateKeySigner) PrivateKeyBytes() []byte {
+	return crypto.FromECDSA(s.priv)
+}
+
+// Address 签名账户地址
+func (s *PrivateKeySigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx 使用私钥对交易签名
+func (s *PrivateKeySigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), s.priv)
+	if err != nil {
+		return nil, fmt.Errorf("签名交易失败: %w", err)
+	}
+	return signedTx, nil
+}
+
+// SignHash 使用私钥对摘要签名
+func (s *PrivateKeySigner) SignHash(hash []byte) ([]byte, error) {
+	sig, err := crypto.Sign(hash, s.priv)
+	if err != nil {
+		return nil, fmt.Errorf("签名失败: %w", err)
+	}
+	return sig, nil
+}
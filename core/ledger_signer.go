@@ -0,0 +1,257 @@
+/*
+Ledger 硬件钱包签名者
+
+私钥保存在 Ledger 设备中，服务端只负责组装 APDU 指令并通过 USB HID 传输：
+- GET ETH PUBLIC ADDRESS（INS 0x02）：按派生路径读取地址
+- SIGN ETH TRANSACTION（INS 0x04）：发送 RLP 编码的待签名交易，设备返回 V/R/S
+
+HID 传输按 Ledger 协议分帧：每个报告 64 字节，头部为 通道(0x0101) + 标签(0x05) + 序号，
+首帧额外携带 2 字节 APDU 总长度。
+
+当前服务端未集成 USB HID 驱动，OpenLedgerHID 总是返回 ErrLedgerUnavailable；
+接入驱动后，将设备句柄（io.ReadWriter）交给 NewLedgerHIDTransport 即可使用 LedgerSigner。
+*/
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Ledger 设备与协议常量
+const (
+	LedgerVendorID = 0x2c97 // Ledger USB 厂商ID
+
+	ledgerHIDReportSize = 64     // HID 报告长度
+	ledgerHIDChannel    = 0x0101 // HID 通道
+	ledgerHIDTagAPDU    = 0x05   // APDU 数据标签
+	ledgerAPDUChunkSize = 255    // 单条 APDU 数据段最大长度
+
+	ledgerCLA           = 0xe0 // 以太坊应用指令类别
+	ledgerInsGetAddress = 0x02 // 读取地址
+	ledgerInsSignTx     = 0x04 // 签名交易
+	ledgerP1FirstChunk  = 0x00 // 首段数据
+	ledgerP1MoreChunks  = 0x80 // 后续数据段
+	ledgerStatusOK      = 0x9000
+)
+
+// ErrLedgerUnavailable 服务端未接入 USB HID 驱动或未检测到设备
+var ErrLedgerUnavailable = errors.New("Ledger设备不可用: 服务端未接入USB HID驱动")
+
+// LedgerTransport Ledger APDU 传输通道
+type LedgerTransport interface {
+	// Exchange 发送一条 APDU 指令并返回响应数据（不含状态字）
+	Exchange(apdu []byte) ([]byte, error)
+}
+
+// OpenLedgerHID 打开第一个连接的 Ledger 设备
+// 尚未集成 USB HID 驱动，始终返回 ErrLedgerUnavailable
+func OpenLedgerHID() (LedgerTransport, error) {
+	return nil, ErrLedgerUnavailable
+}
+
+// ledgerHIDTransport 基于 HID 设备读写的 Ledger 传输实现
+type ledgerHIDTransport struct {
+	device io.ReadWriter
+}
+
+// NewLedgerHIDTransport 使用已打开的 HID 设备句柄创建传输通道
+func NewLedgerHIDTransport(device io.ReadWriter) LedgerTransport {
+	return &ledgerHIDTransport{device: device}
+}
+
+// Exchange 按 Ledger HID 协议分帧发送 APDU 并组装响应
+func (t *ledgerHIDTransport) Exchange(apdu []byte) ([]byte, error) {
+	// 首帧携带 APDU 总长度
+	payload := make([]byte, 2+len(apdu))
+	binary.BigEndian.PutUint16(payload, uint16(len(apdu)))
+	copy(payload[2:], apdu)
+
+	for seq := uint16(0); len(payload) > 0; seq++ {
+		report := make([]byte, ledgerHIDReportSize)
+		binary.BigEndian.PutUint16(report[0:], ledgerHIDChannel)
+		report[2] = ledgerHIDTagAPDU
+		binary.BigEndian.PutUint16(report[3:], seq)
+		n := copy(report[5:], payload)
+		payload = payload[n:]
+		if _, err := t.device.Write(report); err != nil {
+			return nil, fmt.Errorf("写入Ledger设备失败: %w", err)
+		}
+	}
+
+	var (
+		reply []byte
+		total int
+	)
+	for seq := uint16(0); ; seq++ {
+		report := make([]byte, ledgerHIDReportSize)
+		if _, err := io.ReadFull(t.device, report); err != nil {
+			return nil, fmt.Errorf("读取Ledger设备失败: %w", err)
+		}
+		if binary.BigEndian.Uint16(report[0:]) != ledgerHIDChannel || report[2] != ledgerHIDTagAPDU || binary.BigEndian.Uint16(report[3:]) != seq {
+			return nil, fmt.Errorf("Ledger响应帧格式错误")
+		}
+		chunk := report[5:]
+		if seq == 0 {
+			total = int(binary.BigEndian.Uint16(chunk))
+			chunk = chunk[2:]
+		}
+		reply = append(reply, chunk...)
+		if len(reply) >= total {
+			reply = reply[:total]
+			break
+		}
+	}
+
+	if len(reply) < 2 {
+		return nil, fmt.Errorf("Ledger响应缺少状态字")
+	}
+	status := binary.BigEndian.Uint16(reply[len(reply)-2:])
+	if status != ledgerStatusOK {
+		return nil, fmt.Errorf("Ledger返回错误状态: 0x%04x", status)
+	}
+	return reply[:len(reply)-2], nil
+}
+
+// LedgerSigner 通过 Ledger 设备签名的签名者
+type LedgerSigner struct {
+	transport LedgerTransport
+	path      accounts.DerivationPath
+	address   common.Address
+}
+
+// NewLedgerSigner 创建 Ledger 签名者，并从设备读取派生路径对应的地址
+func NewLedgerSigner(transport LedgerTransport, derivationPath string) (*LedgerSigner, error) {
+	if err := CheckDerivationPath(derivationPath); err != nil {
+		return nil, err
+	}
+	path, err := accounts.ParseDerivationPath(derivationPath)
+	if err != nil {
+		return nil, fmt.Errorf("解析派生路径失败: %w", err)
+	}
+	s := &LedgerSigner{transport: transport, path: path}
+	if s.address, err = s.deriveAddress(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Address 签名账户地址
+func (s *LedgerSigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx 将待签名交易发送到设备确认并签名
+func (s *LedgerSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	var payload []byte
+	var err error
+	switch tx.Type() {
+	case types.LegacyTxType:
+		// EIP-155：rlp([nonce, gasPrice, gas, to, value, data, chainID, 0, 0])
+		payload, err = rlp.EncodeToBytes([]interface{}{
+			tx.Nonce(), tx.GasPrice(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), chainID, uint(0), uint(0),
+		})
+	case types.DynamicFeeTxType:
+		// EIP-1559：0x02 || rlp([chainID, nonce, tip, feeCap, gas, to, value, data, accessList])
+		payload, err = rlp.EncodeToBytes([]interface{}{
+			chainID, tx.Nonce(), tx.GasTipCap(), tx.GasFeeCap(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), tx.AccessList(),
+		})
+		payload = append([]byte{types.DynamicFeeTxType}, payload...)
+	default:
+		return nil, fmt.Errorf("Ledger签名不支持交易类型: %d", tx.Type())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("编码待签名交易失败: %w", err)
+	}
+
+	data := append(s.serializedPath(), payload...)
+	var reply []byte
+	for first := true; len(data) > 0; first = false {
+		chunk := data
+		if len(chunk) > ledgerAPDUChunkSize {
+			chunk = chunk[:ledgerAPDUChunkSize]
+		}
+		p1 := byte(ledgerP1MoreChunks)
+		if first {
+			p1 = ledgerP1FirstChunk
+		}
+		if reply, err = s.transport.Exchange(ledgerAPDU(ledgerInsSignTx, p1, 0x00, chunk)); err != nil {
+			return nil, fmt.Errorf("Ledger签名交易失败: %w", err)
+		}
+		data = data[len(chunk):]
+	}
+	if len(reply) != 65 {
+		return nil, fmt.Errorf("Ledger签名响应长度错误: %d", len(reply))
+	}
+
+	// 设备返回 V || R || S；legacy 交易的 V 为按 EIP-155 计算后截断的单字节
+	sig := append(append([]byte{}, reply[1:]...), reply[0])
+	if tx.Type() == types.LegacyTxType {
+		sig[64] -= byte(chainID.Uint64()*2 + 35)
+	}
+	signedTx, err := tx.WithSignature(types.LatestSignerForChainID(chainID), sig)
+	if err != nil {
+		return nil, fmt.Errorf("组装签名交易失败: %w", err)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signedTx)
+	if err != nil || sender != s.address {
+		return nil, fmt.Errorf("Ledger签名校验失败: 签名者与设备地址不一致")
+	}
+	return signedTx, nil
+}
+
+// SignHash Ledger 以太坊应用不支持对任意摘要盲签
+func (s *LedgerSigner) SignHash(hash []byte) ([]byte, error) {
+	return nil, fmt.Errorf("Ledger设备不支持直接签名摘要")
+}
+
+// deriveAddress 从设备读取派生路径对应的地址
+// 响应格式：公钥长度(1) + 公钥 + 地址长度(1) + 地址（十六进制ASCII）
+func (s *LedgerSigner) deriveAddress() (common.Address, error) {
+	reply, err := s.transport.Exchange(ledgerAPDU(ledgerInsGetAddress, 0x00, 0x00, s.serializedPath()))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("读取Ledger地址失败: %w", err)
+	}
+	if len(reply) < 1 || len(reply) < 1+int(reply[0])+1 {
+		return common.Address{}, fmt.Errorf("Ledger地址响应格式错误")
+	}
+	reply = reply[1+int(reply[0]):]
+	addrLen := int(reply[0])
+	if len(reply) < 1+addrLen {
+		return common.Address{}, fmt.Errorf("Ledger地址响应格式错误")
+	}
+	hexAddr := string(reply[1 : 1+addrLen])
+	if !strings.HasPrefix(hexAddr, "0x") {
+		hexAddr = "0x" + hexAddr
+	}
+	if !common.IsHexAddress(hexAddr) {
+		return common.Address{}, fmt.Errorf("Ledger返回无效地址: %s", hexAddr)
+	}
+	return common.HexToAddress(hexAddr), nil
+}
+
+// serializedPath 按 Ledger 格式编码派生路径：层级数(1) + 每级 uint32 大端
+func (s *LedgerSigner) serializedPath() []byte {
+	out := make([]byte, 1+4*len(s.path))
+	out[0] = byte(len(s.path))
+	for i, component := range s.path {
+		binary.BigEndian.PutUint32(out[1+4*i:], component)
+	}
+	return out
+}
+
+// ledgerAPDU 组装 APDU 指令：CLA INS P1 P2 Lc data
+func ledgerAPDU(ins, p1, p2 byte, data []byte) []byte {
+	apdu := make([]byte, 0, 5+len(data))
+	apdu = append(apdu, ledgerCLA, ins, p1, p2, byte(len(data)))
+	return append(apdu, data...)
+}
@@ -0,0 +1,387 @@
+/*
+交易事件日志解码
+
+本文件将交易回执中的日志解码为可读的事件：
+- 优先使用调用方提供的合约ABI
+- 其次使用内置的常见事件ABI（ERC20/ERC721/ERC1155、WETH、Uniswap V2/V3）
+- 均无法匹配时保留原始 topics 与 data
+
+DecodeLogs 供交易历史使用：标准 ERC20 Transfer/Approval 与 ERC721 Transfer 直接按 topic0 识别，
+无需完整ABI；解析过的ABI按原文缓存，重复调用不再重复解析。
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 常见事件ABI
+// ERC20 与 ERC721 的 Transfer/Approval 签名相同、topic0 相同，按 indexed 参数个数区分，因此分开存放
+var wellKnownEventABIs = []string{
+	// ERC20
+	`[
+		{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"},
+		{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":true,"name":"spender","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Approval","type":"event"}
+	]`,
+	// ERC721
+	`[
+		{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":true,"name":"tokenId","type":"uint256"}],"name":"Transfer","type":"event"},
+		{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":true,"name":"approved","type":"address"},{"indexed":true,"name":"tokenId","type":"uint256"}],"name":"Approval","type":"event"},
+		{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":true,"name":"operator","type":"address"},{"indexed":false,"name":"approved","type":"bool"}],"name":"ApprovalForAll","type":"event"}
+	]`,
+	// ERC1155
+	`[
+		{"anonymous":false,"inputs":[{"indexed":true,"name":"operator","type":"address"},{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"id","type":"uint256"},{"indexed":false,"name":"value","type":"uint256"}],"name":"TransferSingle","type":"event"},
+		{"anonymous":false,"inputs":[{"indexed":true,"name":"operator","type":"address"},{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"ids","type":"uint256[]"},{"indexed":false,"name":"values","type":"uint256[]"}],"name":"TransferBatch","type":"event"}
+	]`,
+	// WETH
+	`[
+		{"anonymous":false,"inputs":[{"indexed":true,"name":"dst","type":"address"},{"indexed":false,"name":"wad","type":"uint256"}],"name":"Deposit","type":"event"},
+		{"anonymous":false,"inputs":[{"indexed":true,"name":"src","type":"address"},{"indexed":false,"name":"wad","type":"uint256"}],"name":"Withdrawal","type":"event"}
+	]`,
+	// Uniswap V2 Pair
+	`[
+		{"anonymous":false,"inputs":[{"indexed":true,"name":"sender","type":"address"},{"indexed":false,"name":"amount0In","type":"uint256"},{"indexed":false,"name":"amount1In","type":"uint256"},{"indexed":false,"name":"amount0Out","type":"uint256"},{"indexed":false,"name":"amount1Out","type":"uint256"},{"indexed":true,"name":"to","type":"address"}],"name":"Swap","type":"event"},
+		{"anonymous":false,"inputs":[{"indexed":false,"name":"reserve0","type":"uint112"},{"indexed":false,"name":"reserve1","type":"uint112"}],"name":"Sync","type":"event"},
+		{"anonymous":false,"inputs":[{"indexed":true,"name":"sender","type":"address"},{"indexed":false,"name":"amount0","type":"uint256"},{"indexed":false,"name":"amount1","type":"uint256"}],"name":"Mint","type":"event"},
+		{"anonymous":false,"inputs":[{"indexed":true,"name":"sender","type":"address"},{"indexed":false,"name":"amount0","type":"uint256"},{"indexed":false,"name":"amount1","type":"uint256"},{"indexed":true,"name":"to","type":"address"}],"name":"Burn","type":"event"}
+	]`,
+	// Uniswap V3 Pool
+	`[
+		{"anonymous":false,"inputs":[{"indexed":true,"name":"sender","type":"address"},{"indexed":true,"name":"recipient","type":"address"},{"indexed":false,"name":"amount0","type":"int256"},{"indexed":false,"name":"amount1","type":"int256"},{"indexed":false,"name":"sqrtPriceX96","type":"uint160"},{"indexed":false,"name":"liquidity","type":"uint128"},{"indexed":false,"name":"tick","type":"int24"}],"name":"Swap","type":"event"}
+	]`,
+}
+
+// DecodedLog 解码后的事件日志
+type DecodedLog struct {
+	LogIndex  uint                   `json:"log_index"`           // 日志在区块中的序号
+	Address   string                 `json:"address"`             // 触发事件的合约地址
+	Decoded   bool                   `json:"decoded"`             // 是否成功解码
+	Event     string                 `json:"event,omitempty"`     // 事件名称
+	Signature string                 `json:"signature,omitempty"` // 事件签名，如 Transfer(address,address,uint256)
+	Args      map[string]interface{} `json:"args,omitempty"`      // 解码后的参数
+	Topics    []string               `json:"topics"`              // 原始 topics
+	Data      string                 `json:"data"`                // 原始 data
+}
+
+// GetTransactionLogs 获取交易回执中的事件日志并解码
+// 参数: abiJSON - 可选的合约ABI（JSON），优先于内置常见事件ABI
+func (a *EVMAdapter) GetTransactionLogs(ctx context.Context, txHash string, abiJSON string) ([]DecodedLog, error) {
+	abis, err := eventABIs(abiJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt, err := a.GetTransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]DecodedLog, 0, len(receipt.Logs))
+	for _, lg := range receipt.Logs {
+		logs = append(logs, DecodeLog(lg, abis))
+	}
+	return logs, nil
+}
+
+// DecodeLog 按顺序尝试用给定ABI解码单条日志，均不匹配时仅返回原始数据
+func DecodeLog(lg *types.Log, abis []abi.ABI) DecodedLog {
+	decoded := DecodedLog{
+		LogIndex: lg.Index,
+		Address:  lg.Address.Hex(),
+		Topics:   make([]string, 0, len(lg.Topics)),
+		Data:     hexutil.Encode(lg.Data),
+	}
+	for _, t := range lg.Topics {
+		decoded.Topics = append(decoded.Topics, t.Hex())
+	}
+	if len(lg.Topics) == 0 {
+		return decoded // 匿名事件无法识别
+	}
+
+	for _, parsed := range abis {
+		event, err := parsed.EventByID(lg.Topics[0])
+		if err != nil {
+			continue
+		}
+		args, err := unpackEvent(event, lg)
+		if err != nil {
+			continue
+		}
+		decoded.Decoded = true
+		decoded.Event = event.RawName
+		decoded.Signature = event.Sig
+		decoded.Args = args
+		return decoded
+	}
+	return decoded
+}
+
+// unpackEvent 解码事件的 indexed（topics）与非 indexed（data）参数
+func unpackEvent(event *abi.Event, lg *types.Log) (map[string]interface{}, error) {
+	var indexed abi.Arguments
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	// topic 数量不一致说明同名签名的另一种事件（如ERC20与ERC721的Transfer）
+	if len(indexed) != len(lg.Topics)-1 {
+		return nil, fmt.Errorf("indexed参数数量不匹配")
+	}
+
+	raw := make(map[string]interface{})
+	if err := event.Inputs.UnpackIntoMap(raw, lg.Data); err != nil {
+		return nil, err
+	}
+	if err := abi.ParseTopicsIntoMap(raw, indexed, lg.Topics[1:]); err != nil {
+		return nil, err
+	}
+
+	args := make(map[string]interface{}, len(raw))
+	for name, value := range raw {
+		args[name] = formatABIValue(reflect.ValueOf(value))
+	}
+	return args, nil
+}
+
+// formatABIValue 将ABI解码结果转换为便于JSON展示的值
+// 大整数转为十进制字符串，地址/哈希/字节转为0x十六进制，数组与元组递归处理
+func formatABIValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	switch val := v.Interface().(type) {
+	case *big.Int:
+		if val == nil {
+			return nil
+		}
+		return val.String()
+	case common.Address:
+		return val.Hex()
+	case common.Hash:
+		return val.Hex()
+	case []byte:
+		return hexutil.Encode(val)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return formatABIValue(v.Elem())
+	case reflect.Array:
+		// 定长字节数组（bytesN）
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			buf := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(buf), v)
+			return hexutil.Encode(buf)
+		}
+		fallthrough
+	case reflect.Slice:
+		items := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items[i] = formatABIValue(v.Index(i))
+		}
+		return items
+	case reflect.Struct:
+		fields := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			fields[abi.ToCamelCase(v.Type().Field(i).Name)] = formatABIValue(v.Field(i))
+		}
+		return fields
+	}
+	return v.Interface()
+}
+
+// 标准事件 topic0
+var (
+	transferEventTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	approvalEventTopic = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+)
+
+// 按 topic0 识别出的标准
+const (
+	EventStandardERC20  = "ERC20"
+	EventStandardERC721 = "ERC721"
+)
+
+// parsedABICache 已解析的ABI，键为ABI JSON原文
+var parsedABICache sync.Map
+
+// parseABICached 解析ABI JSON，结果按原文缓存
+func parseABICached(abiJSON string) (abi.ABI, error) {
+	if cached, ok := parsedABICache.Load(abiJSON); ok {
+		return cached.(abi.ABI), nil
+	}
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return abi.ABI{}, err
+	}
+	parsedABICache.Store(abiJSON, parsed)
+	return parsed, nil
+}
+
+// eventABIs 返回用于解码的ABI列表：调用方ABI（可选）在前，内置常见事件ABI在后
+func eventABIs(abiJSON string) ([]abi.ABI, error) {
+	abis := make([]abi.ABI, 0, len(wellKnownEventABIs)+1)
+	if strings.TrimSpace(abiJSON) != "" {
+		parsed, err := parseABICached(abiJSON)
+		if err != nil {
+			return nil, fmt.Errorf("解析ABI失败: %w", err)
+		}
+		abis = append(abis, parsed)
+	}
+	for _, known := range wellKnownEventABIs {
+		parsed, err := parseABICached(known)
+		if err != nil {
+			return nil, fmt.Errorf("解析内置事件ABI失败: %w", err)
+		}
+		abis = append(abis, parsed)
+	}
+	return abis, nil
+}
+
+// DecodedEvent 交易历史中展示的已识别事件
+type DecodedEvent struct {
+	LogIndex uint                   `json:"log_index"`          // 日志在区块中的序号
+	Address  string                 `json:"address"`            // 触发事件的合约地址
+	Name     string                 `json:"name"`               // 事件名称
+	Standard string                 `json:"standard,omitempty"` // 按 topic0 识别出的标准（ERC20/ERC721）
+	Args     map[string]interface{} `json:"args"`               // 解码后的参数
+}
+
+// DecodeLogs 解码回执中的日志，仅返回能识别的事件
+// 参数: abiJSON - 可选的合约ABI（JSON），匹配时优先；否则按 topic0 识别标准事件，再尝试内置常见事件ABI
+func (a *EVMAdapter) DecodeLogs(receipt *types.Receipt, abiJSON string) ([]DecodedEvent, error) {
+	if receipt == nil {
+		return nil, fmt.Errorf("交易回执为空")
+	}
+	var custom []abi.ABI
+	if strings.TrimSpace(abiJSON) != "" {
+		parsed, err := parseABICached(abiJSON)
+		if err != nil {
+			return nil, fmt.Errorf("解析ABI失败: %w", err)
+		}
+		custom = []abi.ABI{parsed}
+	}
+	known, err := eventABIs("")
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]DecodedEvent, 0, len(receipt.Logs))
+	for _, lg := range receipt.Logs {
+		if lg == nil || len(lg.Topics) == 0 {
+			continue // 匿名事件无法识别
+		}
+		if custom != nil {
+			if decoded := DecodeLog(lg, custom); decoded.Decoded {
+				events = append(events, DecodedEvent{LogIndex: lg.Index, Address: decoded.Address, Name: decoded.Event, Args: decoded.Args})
+				continue
+			}
+		}
+		if ev, ok := decodeStandardEvent(lg); ok {
+			events = append(events, ev)
+			continue
+		}
+		if decoded := DecodeLog(lg, known); decoded.Decoded {
+			events = append(events, DecodedEvent{LogIndex: lg.Index, Address: decoded.Address, Name: decoded.Event, Args: decoded.Args})
+		}
+	}
+	return events, nil
+}
+
+// decodeStandardEvent 按 topic0 与 topic 数量识别 ERC20 Transfer/Approval 与 ERC721 Transfer
+func decodeStandardEvent(lg *types.Log) (DecodedEvent, bool) {
+	ev := DecodedEvent{LogIndex: lg.Index, Address: lg.Address.Hex()}
+	switch {
+	case lg.Topics[0] == transferEventTopic && len(lg.Topics) == 3 && len(lg.Data) == 32:
+		ev.Name, ev.Standard = "Transfer", EventStandardERC20
+		ev.Args = map[string]interface{}{
+			"from":  common.BytesToAddress(lg.Topics[1].Bytes()).Hex(),
+			"to":    common.BytesToAddress(lg.Topics[2].Bytes()).Hex(),
+			"value": new(big.Int).SetBytes(lg.Data).String(),
+		}
+	case lg.Topics[0] == transferEventTopic && len(lg.Topics) == 4:
+		ev.Name, ev.Standard = "Transfer", EventStandardERC721
+		ev.Args = map[string]interface{}{
+			"from":    common.BytesToAddress(lg.Topics[1].Bytes()).Hex(),
+			"to":      common.BytesToAddress(lg.Topics[2].Bytes()).Hex(),
+			"tokenId": lg.Topics[3].Big().String(),
+		}
+	case lg.Topics[0] == approvalEventTopic && len(lg.Topics) == 3 && len(lg.Data) == 32:
+		ev.Name, ev.Standard = "Approval", EventStandardERC20
+		ev.Args = map[string]interface{}{
+			"owner":   common.BytesToAddress(lg.Topics[1].Bytes()).Hex(),
+			"spender": common.BytesToAddress(lg.Topics[2].Bytes()).Hex(),
+			"value":   new(big.Int).SetBytes(lg.Data).String(),
+		}
+	default:
+		return DecodedEvent{}, false
+	}
+	return ev, true
+}
+
+// summarizeEvents 根据与用户相关的标准转账事件生成摘要，如 "Received 100 USDC"
+// 同时返回第一笔相关的 ERC20 转账，供 calldata 未识别出代币信息的交易补充 TokenInfo
+func (a *EVMAdapter) summarizeEvents(ctx context.Context, events []DecodedEvent, userAddr common.Address) (string, *TokenTxInfo) {
+	var (
+		parts      []string
+		firstToken *TokenTxInfo
+		metadata   = make(map[string]TokenTxInfo)
+	)
+	for _, ev := range events {
+		if ev.Name != "Transfer" || ev.Standard == "" {
+			continue
+		}
+		from, _ := ev.Args["from"].(string)
+		to, _ := ev.Args["to"].(string)
+		var verb string
+		switch {
+		case strings.EqualFold(from, to):
+			continue
+		case strings.EqualFold(to, userAddr.Hex()):
+			verb = "Received"
+		case strings.EqualFold(from, userAddr.Hex()):
+			verb = "Sent"
+		default:
+			continue
+		}
+
+		if ev.Standard == EventStandardERC721 {
+			parts = append(parts, fmt.Sprintf("%s NFT #%v (%s)", verb, ev.Args["tokenId"], ev.Address))
+			continue
+		}
+
+		meta, ok := metadata[ev.Address]
+		if !ok {
+			name, symbol, decimals, err := a.GetERC20Metadata(ctx, ev.Address)
+			if err != nil {
+				name, symbol, decimals = "Unknown Token", "UNKNOWN", 18
+			}
+			meta = TokenTxInfo{TokenAddress: ev.Address, TokenName: name, TokenSymbol: symbol, Decimals: decimals}
+			metadata[ev.Address] = meta
+		}
+		value, _ := ev.Args["value"].(string)
+		amount, _ := new(big.Int).SetString(value, 10)
+		parts = append(parts, fmt.Sprintf("%s %s %s", verb, FormatUnits(amount, int(meta.Decimals)), meta.TokenSymbol))
+		if firstToken == nil {
+			meta.Amount = value
+			meta.ToAddress = to
+			firstToken = &meta
+		}
+	}
+	return strings.Join(parts, ", "), firstToken
+}
@@ -0,0 +1,135 @@
+/*
+内存池拥堵监测
+
+为用户选择发送时机提供拥堵指标：
+- txpool_status：节点内存池中 pending/queued 交易数（部分节点/服务商不支持）
+- eth_feeHistory：最近区块的 baseFee 与区块使用率
+拥堵等级综合当前 baseFee 相对近期均值的比例、区块使用率以及（可用时）待打包交易数得出。
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// 拥堵等级
+const (
+	CongestionLow    = "low"
+	CongestionMedium = "medium"
+	CongestionHigh   = "high"
+)
+
+// 数据来源
+const (
+	MempoolSourceTxPool     = "txpool_status+fee_history"
+	MempoolSourceFeeHistory = "fee_history"
+)
+
+// mempoolFeeHistoryBlocks 计算近期均值使用的区块数
+const mempoolFeeHistoryBlocks = 20
+
+// MempoolStatus 内存池与网络拥堵状态
+type MempoolStatus struct {
+	PendingCount    *uint64 `json:"pending_count,omitempty"` // 待打包交易数（txpool_status 可用时）
+	QueuedCount     *uint64 `json:"queued_count,omitempty"`  // 排队交易数（nonce 不连续）
+	BaseFee         string  `json:"base_fee"`                // 下一区块 baseFee（wei）
+	AverageBaseFee  string  `json:"average_base_fee"`        // 近期区块 baseFee 均值（wei）
+	BaseFeeRatio    float64 `json:"base_fee_ratio"`          // 当前 / 近期均值
+	AverageGasUsed  float64 `json:"average_gas_used_ratio"`  // 近期区块平均使用率（0~1）
+	BlocksSampled   int     `json:"blocks_sampled"`          // 统计的区块数
+	CongestionLevel string  `json:"congestion_level"`        // low / medium / high
+	Source          string  `json:"source"`                  // 数据来源
+	TxPoolSupported bool    `json:"txpool_supported"`        // 节点是否支持 txpool_status
+}
+
+// GetMempoolStatus 获取内存池与网络拥堵状态
+// 节点不支持 txpool_status 时仅依据 baseFee 比例与区块使用率判断
+func (a *EVMAdapter) GetMempoolStatus(ctx context.Context) (*MempoolStatus, error) {
+	history, err := a.client.FeeHistory(ctx, mempoolFeeHistoryBlocks, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取费用历史失败: %w", err)
+	}
+	if len(history.BaseFee) == 0 {
+		return nil, fmt.Errorf("费用历史为空")
+	}
+
+	status := &MempoolStatus{
+		Source:        MempoolSourceFeeHistory,
+		BlocksSampled: len(history.GasUsedRatio),
+	}
+
+	// BaseFee 比 GasUsedRatio 多一个元素，最后一个为下一区块的 baseFee
+	current := history.BaseFee[len(history.BaseFee)-1]
+	if current == nil {
+		current = new(big.Int)
+	}
+	sum := new(big.Int)
+	for _, fee := range history.BaseFee[:len(history.BaseFee)-1] {
+		if fee != nil {
+			sum.Add(sum, fee)
+		}
+	}
+	average := new(big.Int)
+	if n := len(history.BaseFee) - 1; n > 0 {
+		average.Div(sum, big.NewInt(int64(n)))
+	}
+	status.BaseFee = current.String()
+	status.AverageBaseFee = average.String()
+	if average.Sign() > 0 {
+		status.BaseFeeRatio, _ = new(big.Float).Quo(new(big.Float).SetInt(current), new(big.Float).SetInt(average)).Float64()
+	}
+	for _, ratio := range history.GasUsedRatio {
+		status.AverageGasUsed += ratio
+	}
+	if len(history.GasUsedRatio) > 0 {
+		status.AverageGasUsed /= float64(len(history.GasUsedRatio))
+	}
+
+	// txpool_status 并非标准接口，失败时静默回退
+	var pool struct {
+		Pending hexutil.Uint64 `json:"pending"`
+		Queued  hexutil.Uint64 `json:"queued"`
+	}
+	if err := a.client.CallContext(ctx, &pool, "txpool_status"); err == nil {
+		pending, queued := uint64(pool.Pending), uint64(pool.Queued)
+		status.PendingCount = &pending
+		status.QueuedCount = &queued
+		status.TxPoolSupported = true
+		status.Source = MempoolSourceTxPool
+	}
+
+	status.CongestionLevel = congestionLevel(status)
+	return status, nil
+}
+
+// congestionLevel 综合 baseFee 比例、区块使用率与待打包交易数判断拥堵等级
+// 无 EIP-1559 的链 baseFee 为0，仅依据区块使用率与交易数
+func congestionLevel(s *MempoolStatus) string {
+	level := CongestionLow
+	raise := func(l string) {
+		if l == CongestionHigh || (l == CongestionMedium && level == CongestionLow) {
+			level = l
+		}
+	}
+
+	switch {
+	case s.BaseFeeRatio >= 1.25 || s.AverageGasUsed >= 0.9:
+		raise(CongestionHigh)
+	case s.BaseFeeRatio >= 1.05 || s.AverageGasUsed >= 0.6:
+		raise(CongestionMedium)
+	}
+
+	if s.PendingCount != nil {
+		switch {
+		case *s.PendingCount >= 20000:
+			raise(CongestionHigh)
+		case *s.PendingCount >= 5000:
+			raise(CongestionMedium)
+		}
+	}
+	return level
+}
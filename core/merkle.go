@@ -0,0 +1,93 @@
+/*
+Merkle 证明工具（空投领取）
+
+与 OpenZeppelin MerkleProof.verify 兼容：
+- 哈希函数为 keccak256
+- 父节点 = keccak256(sort(a, b))，即两个子节点按字节序排序后拼接再哈希
+- 层内节点数为奇数时，最后一个节点直接提升到上一层（与 merkletreejs sortPairs 一致）
+
+叶子为32字节的叶子哈希，常见做法为 keccak256(abi.encodePacked(account, amount))。
+*/
+package core
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// BuildMerkleTree 构建Merkle树并返回根
+func BuildMerkleTree(leaves [][]byte) ([]byte, error) {
+	layers, err := merkleLayers(leaves)
+	if err != nil {
+		return nil, err
+	}
+	return layers[len(layers)-1][0], nil
+}
+
+// GetMerkleProof 生成第 index 个叶子的证明（自底向上的兄弟节点列表）
+func GetMerkleProof(leaves [][]byte, index int) ([][]byte, error) {
+	layers, err := merkleLayers(leaves)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("叶子索引越界: %d", index)
+	}
+
+	proof := make([][]byte, 0, len(layers)-1)
+	for _, layer := range layers[:len(layers)-1] {
+		sibling := index ^ 1
+		// 奇数层的最后一个节点没有兄弟节点，直接提升
+		if sibling < len(layer) {
+			proof = append(proof, layer[sibling])
+		}
+		index /= 2
+	}
+	return proof, nil
+}
+
+// VerifyMerkleProof 校验叶子与证明能否还原出给定的根
+func VerifyMerkleProof(root, leaf []byte, proof [][]byte) bool {
+	computed := leaf
+	for _, sibling := range proof {
+		computed = hashMerklePair(computed, sibling)
+	}
+	return bytes.Equal(computed, root)
+}
+
+// merkleLayers 自底向上逐层计算，返回各层节点（最后一层为根）
+func merkleLayers(leaves [][]byte) ([][][]byte, error) {
+	if len(leaves) == 0 {
+		return nil, fmt.Errorf("叶子列表不能为空")
+	}
+	for i, leaf := range leaves {
+		if len(leaf) != 32 {
+			return nil, fmt.Errorf("第 %d 个叶子长度应为32字节，实际 %d", i, len(leaf))
+		}
+	}
+
+	layers := [][][]byte{leaves}
+	for current := leaves; len(current) > 1; {
+		next := make([][]byte, 0, (len(current)+1)/2)
+		for i := 0; i < len(current); i += 2 {
+			if i+1 == len(current) {
+				next = append(next, current[i])
+				continue
+			}
+			next = append(next, hashMerklePair(current[i], current[i+1]))
+		}
+		layers = append(layers, next)
+		current = next
+	}
+	return layers, nil
+}
+
+// hashMerklePair 按字节序排序后拼接哈希（OpenZeppelin commutative keccak256）
+func hashMerklePair(a, b []byte) []byte {
+	if bytes.Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	return crypto.Keccak256(a, b)
+}
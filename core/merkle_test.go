@@ -0,0 +1,98 @@
+package core
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 叶子为 keccak256("a") ... keccak256("e")，共5个：第0层与第1层节点数均为奇数
+// 期望值按 merkletreejs（sortPairs: true，hashLeaves: false，奇数节点直接提升）的算法以独立的 keccak256 实现计算，
+// 可被 OpenZeppelin MerkleProof.verify 校验
+const merkleTestRoot = "1dd0d2a6ae466d665cb26e1a31f07c57ae5df7d2bc559cd5826d417be9141a5d"
+
+var merkleTestProofs = [][]string{
+	{
+		"b5553de315e0edf504d9150af82dafa5c4667fa618ed0a6f19c69b41166c5510",
+		"d253a52d4cb00de2895e85f2529e2976e6aaaa5c18106b68ab66813e14415669",
+		"a8982c89d80987fb9a510e25981ee9170206be21af3c8e0eb312ef1d3382e761",
+	},
+	{
+		"3ac225168df54212a25c1c01fd35bebfea408fdac2e31ddd6f80a4bbf9a5f1cb",
+		"d253a52d4cb00de2895e85f2529e2976e6aaaa5c18106b68ab66813e14415669",
+		"a8982c89d80987fb9a510e25981ee9170206be21af3c8e0eb312ef1d3382e761",
+	},
+	{
+		"f1918e8562236eb17adc8502332f4c9c82bc14e19bfc0aa10ab674ff75b3d2f3",
+		"805b21d846b189efaeb0377d6bb0d201b3872a363e607c25088f025b0c6ae1f8",
+		"a8982c89d80987fb9a510e25981ee9170206be21af3c8e0eb312ef1d3382e761",
+	},
+	{
+		"0b42b6393c1f53060fe3ddbfcd7aadcca894465a5a438f69c87d790b2299b9b2",
+		"805b21d846b189efaeb0377d6bb0d201b3872a363e607c25088f025b0c6ae1f8",
+		"a8982c89d80987fb9a510e25981ee9170206be21af3c8e0eb312ef1d3382e761",
+	},
+	// 最后一个叶子在两个奇数层均被直接提升，证明只有一个兄弟节点
+	{
+		"68203f90e9d07dc5859259d7536e87a6ba9d345f2552b5b9de2999ddce9ce1bf",
+	},
+}
+
+func merkleTestLeaves() [][]byte {
+	leaves := make([][]byte, 0, 5)
+	for _, s := range []string{"a", "b", "c", "d", "e"} {
+		leaves = append(leaves, crypto.Keccak256([]byte(s)))
+	}
+	return leaves
+}
+
+func TestBuildMerkleTreeMatchesMerkletreejs(t *testing.T) {
+	root, err := BuildMerkleTree(merkleTestLeaves())
+	if err != nil {
+		t.Fatalf("BuildMerkleTree: %v", err)
+	}
+	if got := hex.EncodeToString(root); got != merkleTestRoot {
+		t.Fatalf("root = %s，期望 %s", got, merkleTestRoot)
+	}
+}
+
+func TestGetMerkleProofMatchesMerkletreejs(t *testing.T) {
+	leaves := merkleTestLeaves()
+	root, _ := hex.DecodeString(merkleTestRoot)
+	for i, want := range merkleTestProofs {
+		proof, err := GetMerkleProof(leaves, i)
+		if err != nil {
+			t.Fatalf("叶子 %d: GetMerkleProof: %v", i, err)
+		}
+		if len(proof) != len(want) {
+			t.Fatalf("叶子 %d: 证明长度 = %d，期望 %d", i, len(proof), len(want))
+		}
+		for j := range want {
+			if got := hex.EncodeToString(proof[j]); got != want[j] {
+				t.Errorf("叶子 %d: proof[%d] = %s，期望 %s", i, j, got, want[j])
+			}
+		}
+		if !VerifyMerkleProof(root, leaves[i], proof) {
+			t.Errorf("叶子 %d: 证明校验失败", i)
+		}
+	}
+}
+
+func TestVerifyMerkleProofRejectsWrongLeaf(t *testing.T) {
+	leaves := merkleTestLeaves()
+	root, _ := hex.DecodeString(merkleTestRoot)
+	proof, err := GetMerkleProof(leaves, 0)
+	if err != nil {
+		t.Fatalf("GetMerkleProof: %v", err)
+	}
+	if VerifyMerkleProof(root, leaves[1], proof) {
+		t.Fatal("使用其他叶子的证明不应校验通过")
+	}
+}
+
+func TestGetMerkleProofIndexOutOfRange(t *testing.T) {
+	if _, err := GetMerkleProof(merkleTestLeaves(), 5); err == nil {
+		t.Fatal("索引越界应返回错误")
+	}
+}
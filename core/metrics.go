@@ -0,0 +1,349 @@
+/*
+Prometheus 指标
+
+EVMAdapter 的节点客户端经 instrumentedClient 包装，每次RPC调用按 JSON-RPC 方法名记录：
+  - rpc_calls_total{method,network,status}：调用次数，status 为 success、error，查询不存在的交易/收据为 not_found
+  - rpc_duration_seconds{method,network}：调用耗时
+  - transactions_sent_total{type,network,status}：广播的交易数，type 按交易数据区分为 native（原生币转账）、
+    token（ERC20 transfer/transferFrom）与 contract（其他合约调用）
+  - gas_price_gwei{network}：最近一次查询到的建议 Gas 价格
+  - wallet_build_info{version,revision,go_version}：构建信息，值恒为 1
+
+指标注册到调用方传入的 prometheus.Registerer（服务层使用独立的 Registry 并通过 /metrics 暴露），
+未设置指标（SetMetrics 未调用或传入 nil）的适配器不记录指标。调用失败时以调用方的 context 记录 rpc call failed 日志，
+经 HTTP 请求触发的调用带有该请求的 request_id（见 pkg/logger）。
+*/
+package core
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/big"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RPC 调用状态
+const (
+	rpcStatusSuccess  = "success"
+	rpcStatusError    = "error"
+	rpcStatusNotFound = "not_found"
+)
+
+// 交易类型
+const (
+	txTypeNative   = "native"
+	txTypeToken    = "token"
+	txTypeContract = "contract"
+)
+
+// Metrics 钱包服务的 Prometheus 指标
+type Metrics struct {
+	rpcCalls    *prometheus.CounterVec
+	rpcDuration *prometheus.HistogramVec
+	txSent      *prometheus.CounterVec
+	gasPrice    *prometheus.GaugeVec
+	buildInfo   *prometheus.GaugeVec
+}
+
+// NewMetrics 创建指标并注册到 reg；同一个 reg 重复注册时返回错误
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		rpcCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rpc_calls_total",
+			Help: "Number of JSON-RPC calls made to blockchain nodes.",
+		}, []string{"method", "network", "status"}),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "rpc_duration_seconds",
+			Help:    "Latency of JSON-RPC calls made to blockchain nodes.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "network"}),
+		txSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "transactions_sent_total",
+			Help: "Number of transactions broadcast to blockchain nodes.",
+		}, []string{"type", "network", "status"}),
+		gasPrice: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "gas_price_gwei",
+			Help: "Most recently observed suggested gas price in gwei.",
+		}, []string{"network"}),
+		buildInfo: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "wallet_build_info",
+			Help: "Build information of the wallet service, always 1.",
+		}, []string{"version", "revision", "go_version"}),
+	}
+	for _, c := range []prometheus.Collector{m.rpcCalls, m.rpcDuration, m.txSent, m.gasPrice, m.buildInfo} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	version, revision := buildVersion()
+	m.buildInfo.WithLabelValues(version, revision, runtime.Version()).Set(1)
+	return m, nil
+}
+
+// buildVersion 从模块构建信息读取版本与 VCS 提交，未知时为 unknown
+func buildVersion() (version, revision string) {
+	version, revision = "unknown", "unknown"
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	if info.Main.Version != "" {
+		version = info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" && setting.Value != "" {
+			revision = setting.Value
+		}
+	}
+	return
+}
+
+// rpcObserver 客户端所属网络及记录的指标
+type rpcObserver struct {
+	metrics *Metrics
+	network string
+}
+
+// instrumentedClient 包装节点客户端，每次调用记录次数与耗时
+// 只暴露适配器用到的方法，新增调用需在此添加对应的包装以免漏记
+type instrumentedClient struct {
+	c        *ethclient.Client
+	observer atomic.Pointer[rpcObserver]
+}
+
+func newInstrumentedClient(c *ethclient.Client) *instrumentedClient {
+	return &instrumentedClient{c: c}
+}
+
+// setMetrics 设置记录的指标与网络标签，m 为空时只在调用失败时记录日志
+func (ic *instrumentedClient) setMetrics(network string, m *Metrics) {
+	ic.observer.Store(&rpcObserver{metrics: m, network: network})
+}
+
+// observe 记录一次调用的结果与耗时；调用失败时以 ctx 记录日志（带请求ID）
+func (ic *instrumentedClient) observe(ctx context.Context, method string, start time.Time, err error) {
+	duration := time.Since(start)
+	o := ic.observer.Load()
+	status := rpcStatusSuccess
+	switch {
+	case errors.Is(err, ethereum.NotFound):
+		status = rpcStatusNotFound
+	case err != nil:
+		status = rpcStatusError
+		logRPCError(ctx, o, method, duration, err)
+	}
+	if o == nil || o.metrics == nil {
+		return
+	}
+	o.metrics.rpcCalls.WithLabelValues(method, o.network, status).Inc()
+	o.metrics.rpcDuration.WithLabelValues(method, o.network).Observe(duration.Seconds())
+}
+
+// logRPCError 记录节点调用失败；eth_call / eth_estimateGas 被节点拒绝（如合约回滚）较常见，记为 DEBUG
+func logRPCError(ctx context.Context, o *rpcObserver, method string, duration time.Duration, err error) {
+	level := slog.LevelWarn
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && (method == "eth_call" || method == "eth_estimateGas") {
+		level = slog.LevelDebug
+	}
+	network := ""
+	if o != nil {
+		network = o.network
+	}
+	slog.Log(ctx, level, "rpc call failed",
+		"method", method,
+		"network", network,
+		"duration_ms", duration.Milliseconds(),
+		"error", stripRequestURL(err).Error(),
+	)
+}
+
+// SetMetrics 设置RPC调用指标，networkID 为该适配器对应的网络，m 为空时不记录
+func (a *EVMAdapter) SetMetrics(networkID string, m *Metrics) {
+	a.client.setMetrics(networkID, m)
+}
+
+// Close 关闭连接
+func (ic *instrumentedClient) Close() {
+	ic.c.Close()
+}
+
+// CallContext 调用任意 JSON-RPC 方法（ethclient 未封装的方法，如 txpool_status）
+func (ic *instrumentedClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	start := time.Now()
+	err := ic.c.Client().CallContext(ctx, result, method, args...)
+	ic.observe(ctx, method, start, err)
+	return err
+}
+
+func (ic *instrumentedClient) BlockNumber(ctx context.Context) (uint64, error) {
+	start := time.Now()
+	n, err := ic.c.BlockNumber(ctx)
+	ic.observe(ctx, "eth_blockNumber", start, err)
+	return n, err
+}
+
+func (ic *instrumentedClient) ChainID(ctx context.Context) (*big.Int, error) {
+	start := time.Now()
+	id, err := ic.c.ChainID(ctx)
+	ic.observe(ctx, "eth_chainId", start, err)
+	return id, err
+}
+
+func (ic *instrumentedClient) NetworkID(ctx context.Context) (*big.Int, error) {
+	start := time.Now()
+	id, err := ic.c.NetworkID(ctx)
+	ic.observe(ctx, "net_version", start, err)
+	return id, err
+}
+
+func (ic *instrumentedClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	start := time.Now()
+	balance, err := ic.c.BalanceAt(ctx, account, blockNumber)
+	ic.observe(ctx, "eth_getBalance", start, err)
+	return balance, err
+}
+
+func (ic *instrumentedClient) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	start := time.Now()
+	code, err := ic.c.CodeAt(ctx, account, blockNumber)
+	ic.observe(ctx, "eth_getCode", start, err)
+	return code, err
+}
+
+func (ic *instrumentedClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	start := time.Now()
+	nonce, err := ic.c.NonceAt(ctx, account, blockNumber)
+	ic.observe(ctx, "eth_getTransactionCount", start, err)
+	return nonce, err
+}
+
+func (ic *instrumentedClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	start := time.Now()
+	nonce, err := ic.c.PendingNonceAt(ctx, account)
+	ic.observe(ctx, "eth_getTransactionCount", start, err)
+	return nonce, err
+}
+
+func (ic *instrumentedClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	start := time.Now()
+	out, err := ic.c.CallContract(ctx, msg, blockNumber)
+	ic.observe(ctx, "eth_call", start, err)
+	return out, err
+}
+
+func (ic *instrumentedClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	start := time.Now()
+	gas, err := ic.c.EstimateGas(ctx, msg)
+	ic.observe(ctx, "eth_estimateGas", start, err)
+	return gas, err
+}
+
+// SuggestGasPrice 查询建议 Gas 价格，成功时同时更新 gas_price_gwei
+func (ic *instrumentedClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	start := time.Now()
+	price, err := ic.c.SuggestGasPrice(ctx)
+	ic.observe(ctx, "eth_gasPrice", start, err)
+	if o := ic.observer.Load(); o != nil && o.metrics != nil && err == nil {
+		gwei, _ := new(big.Float).Quo(new(big.Float).SetInt(price), big.NewFloat(1e9)).Float64()
+		o.metrics.gasPrice.WithLabelValues(o.network).Set(gwei)
+	}
+	return price, err
+}
+
+func (ic *instrumentedClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	start := time.Now()
+	tip, err := ic.c.SuggestGasTipCap(ctx)
+	ic.observe(ctx, "eth_maxPriorityFeePerGas", start, err)
+	return tip, err
+}
+
+func (ic *instrumentedClient) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	start := time.Now()
+	history, err := ic.c.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+	ic.observe(ctx, "eth_feeHistory", start, err)
+	return history, err
+}
+
+func (ic *instrumentedClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	start := time.Now()
+	header, err := ic.c.HeaderByNumber(ctx, number)
+	ic.observe(ctx, "eth_getBlockByNumber", start, err)
+	return header, err
+}
+
+func (ic *instrumentedClient) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	start := time.Now()
+	block, err := ic.c.BlockByNumber(ctx, number)
+	ic.observe(ctx, "eth_getBlockByNumber", start, err)
+	return block, err
+}
+
+func (ic *instrumentedClient) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	start := time.Now()
+	block, err := ic.c.BlockByHash(ctx, hash)
+	ic.observe(ctx, "eth_getBlockByHash", start, err)
+	return block, err
+}
+
+func (ic *instrumentedClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	start := time.Now()
+	logs, err := ic.c.FilterLogs(ctx, q)
+	ic.observe(ctx, "eth_getLogs", start, err)
+	return logs, err
+}
+
+func (ic *instrumentedClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	start := time.Now()
+	tx, isPending, err := ic.c.TransactionByHash(ctx, hash)
+	ic.observe(ctx, "eth_getTransactionByHash", start, err)
+	return tx, isPending, err
+}
+
+func (ic *instrumentedClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	start := time.Now()
+	receipt, err := ic.c.TransactionReceipt(ctx, txHash)
+	ic.observe(ctx, "eth_getTransactionReceipt", start, err)
+	return receipt, err
+}
+
+// SendTransaction 广播交易，同时按交易类型记录 transactions_sent_total
+func (ic *instrumentedClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	start := time.Now()
+	err := ic.c.SendTransaction(ctx, tx)
+	ic.observe(ctx, "eth_sendRawTransaction", start, err)
+	if o := ic.observer.Load(); o != nil && o.metrics != nil {
+		status := rpcStatusSuccess
+		if err != nil {
+			status = rpcStatusError
+		}
+		o.metrics.txSent.WithLabelValues(sentTxType(tx), o.network, status).Inc()
+	}
+	return err
+}
+
+// sentTxType 按交易数据区分原生币转账、代币转账与其他合约调用
+func sentTxType(tx *types.Transaction) string {
+	data := tx.Data()
+	if len(data) == 0 {
+		return txTypeNative
+	}
+	if len(data) >= 4 {
+		switch common.Bytes2Hex(data[:4]) {
+		case "a9059cbb", "23b872dd": // transfer(address,uint256) / transferFrom(address,address,uint256)
+			return txTypeToken
+		}
+	}
+	return txTypeContract
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// metricsTestFailAddress 测试节点对该地址的 eth_getBalance 返回错误
+const metricsTestFailAddress = "0x000000000000000000000000000000000000dead"
+
+// newMetricsTestNode 模拟节点：eth_getBalance 返回 100 wei，查询 metricsTestFailAddress 时返回 JSON-RPC 错误
+func newMetricsTestNode(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if req.Method == "eth_getBalance" && len(req.Params) > 0 && !strings.Contains(strings.ToLower(string(req.Params[0])), metricsTestFailAddress[2:]) {
+			resp["result"] = "0x64"
+		} else {
+			resp["error"] = map[string]interface{}{"code": -32000, "message": "node unavailable"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestMetricsRecordRPCCalls(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := NewMetrics(reg)
+	if err != nil {
+		t.Fatalf("NewMetrics: %v", err)
+	}
+	adapter, err := NewEVMAdapter(newMetricsTestNode(t).URL)
+	if err != nil {
+		t.Fatalf("NewEVMAdapter: %v", err)
+	}
+	adapter.SetMetrics("testnet", m)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := adapter.GetBalance(ctx, "0x0000000000000000000000000000000000000001"); err != nil {
+			t.Fatalf("GetBalance: %v", err)
+		}
+	}
+	if _, err := adapter.GetBalance(ctx, metricsTestFailAddress); err == nil {
+		t.Fatal("节点返回错误时 GetBalance 应失败")
+	}
+
+	expected := `
+# HELP rpc_calls_total Number of JSON-RPC calls made to blockchain nodes.
+# TYPE rpc_calls_total counter
+rpc_calls_total{method="eth_getBalance",network="testnet",status="error"} 1
+rpc_calls_total{method="eth_getBalance",network="testnet",status="success"} 2
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "rpc_calls_total"); err != nil {
+		t.Fatal(err)
+	}
+	if n := testutil.CollectAndCount(m.rpcDuration); n != 1 {
+		t.Fatalf("rpc_duration_seconds 序列数 = %d，期望 1", n)
+	}
+
+	// /metrics 处理器输出同一注册表中的计数
+	rec := httptest.NewRecorder()
+	promhttp.HandlerFor(reg, promhttp.HandlerOpts{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	if want := `rpc_calls_total{method="eth_getBalance",network="testnet",status="success"} 2`; !strings.Contains(string(body), want) {
+		t.Fatalf("/metrics 输出缺少 %s:\n%s", want, body)
+	}
+}
+
+func TestNewMetricsRejectsDuplicateRegistration(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	if _, err := NewMetrics(reg); err != nil {
+		t.Fatalf("NewMetrics: %v", err)
+	}
+	if _, err := NewMetrics(reg); err == nil {
+		t.Fatal("同一注册表重复注册应返回错误")
+	}
+}
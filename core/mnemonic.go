@@ -0,0 +1,188 @@
+/*
+助记词校验
+
+按 BIP39 校验助记词并给出可供界面展示的结果：
+  - 单词数量须为 12/15/18/21/24，分别对应 128/160/192/224/256 位熵
+  - 自动识别词表语言，目前支持英文与简体中文；中文助记词可用空格分隔，也可连续书写
+  - 逐个检查单词是否在词表中，并校验末尾的校验和位
+
+go-bip39 的词表是包级全局状态，切换后影响所有调用方，
+因此这里按词表自行查找单词序号并计算校验和，不调用 bip39.SetWordList。
+*/
+package core
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	hdwallet "github.com/miguelmota/go-ethereum-hdwallet"
+	bip39 "github.com/tyler-smith/go-bip39"
+	"github.com/tyler-smith/go-bip39/wordlists"
+	"golang.org/x/text/unicode/norm"
+)
+
+// 助记词语言（对应 BIP39 词表）
+const (
+	MnemonicLanguageEnglish           = "english"
+	MnemonicLanguageChineseSimplified = "chinese_simplified"
+)
+
+var (
+	// ErrMnemonicWordCount 助记词单词数量不是 12/15/18/21/24
+	ErrMnemonicWordCount = errors.New("助记词单词数量无效")
+	// ErrMnemonicInvalidWord 助记词包含不在词表中的单词
+	ErrMnemonicInvalidWord = errors.New("助记词包含无效单词")
+	// ErrMnemonicChecksum 单词均有效但校验和不匹配（通常是抄错或顺序错误）
+	ErrMnemonicChecksum = errors.New("助记词校验和错误")
+)
+
+// mnemonicWordCounts 单词数量与熵位数的对应关系
+var mnemonicWordCounts = map[int]int{12: 128, 15: 160, 18: 192, 21: 224, 24: 256}
+
+// mnemonicWordlist 可识别的词表，按优先级排列（单词同时出现在多个词表时取靠前的）
+type mnemonicWordlist struct {
+	language string
+	index    map[string]int
+}
+
+var mnemonicWordlists = []mnemonicWordlist{
+	{MnemonicLanguageEnglish, wordIndex(wordlists.English)},
+	{MnemonicLanguageChineseSimplified, wordIndex(wordlists.ChineseSimplified)},
+}
+
+func wordIndex(list []string) map[string]int {
+	index := make(map[string]int, len(list))
+	for i, w := range list {
+		index[w] = i
+	}
+	return index
+}
+
+// MnemonicValidation 助记词校验结果
+type MnemonicValidation struct {
+	Valid         bool                  `json:"valid"`
+	WordCount     int                   `json:"word_count"`
+	Language      string                `json:"language,omitempty"` // 识别出的词表语言，无法识别时为空
+	InvalidWords  []InvalidMnemonicWord `json:"invalid_words,omitempty"`
+	ChecksumValid bool                  `json:"checksum_valid"`
+	Error         string                `json:"error,omitempty"`
+}
+
+// InvalidMnemonicWord 不在词表中的单词
+type InvalidMnemonicWord struct {
+	Position int    `json:"position"` // 从 1 开始的位置
+	Word     string `json:"word"`
+}
+
+// MnemonicStrength 返回单词数量对应的熵位数
+func MnemonicStrength(wordCount int) (int, error) {
+	strength, ok := mnemonicWordCounts[wordCount]
+	if !ok {
+		return 0, fmt.Errorf("%w: %d，应为 12/15/18/21/24", ErrMnemonicWordCount, wordCount)
+	}
+	return strength, nil
+}
+
+// ValidateMnemonic 校验助记词，返回逐词的校验结果
+// 结果总是非空；助记词无效时同时返回 ErrMnemonicWordCount、ErrMnemonicInvalidWord 或 ErrMnemonicChecksum（按此顺序优先）
+// 返回的错误信息只包含单词位置，不包含单词本身，可直接写入日志
+func ValidateMnemonic(mnemonic string) (*MnemonicValidation, error) {
+	result, err := validateMnemonicWords(mnemonicWords(mnemonic))
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result, err
+}
+
+// mnemonicWords 规范化助记词（NFKD、小写）并拆分为单词
+func mnemonicWords(mnemonic string) []string {
+	words := strings.Fields(strings.ToLower(norm.NFKD.String(mnemonic)))
+	if len(words) == 1 && utf8.RuneCountInString(words[0]) > 1 {
+		// 连续书写的中文助记词按字拆分
+		if r, _ := utf8.DecodeRuneInString(words[0]); unicode.Is(unicode.Han, r) {
+			runes := []rune(words[0])
+			words = make([]string, len(runes))
+			for i, r := range runes {
+				words[i] = string(r)
+			}
+		}
+	}
+	return words
+}
+
+// validateMnemonicWords 校验规范化后的单词
+func validateMnemonicWords(words []string) (*MnemonicValidation, error) {
+	result := &MnemonicValidation{WordCount: len(words)}
+
+	// 选择匹配单词最多的词表
+	var list *mnemonicWordlist
+	best := 0
+	for i := range mnemonicWordlists {
+		matched := 0
+		for _, w := range words {
+			if _, ok := mnemonicWordlists[i].index[w]; ok {
+				matched++
+			}
+		}
+		if matched > best {
+			list, best = &mnemonicWordlists[i], matched
+		}
+	}
+
+	var positions []string
+	for i, w := range words {
+		if list != nil {
+			if _, ok := list.index[w]; ok {
+				continue
+			}
+		}
+		result.InvalidWords = append(result.InvalidWords, InvalidMnemonicWord{Position: i + 1, Word: w})
+		positions = append(positions, strconv.Itoa(i+1))
+	}
+	if list != nil {
+		result.Language = list.language
+	}
+
+	if _, ok := mnemonicWordCounts[len(words)]; !ok {
+		return result, fmt.Errorf("%w: 共 %d 个单词，应为 12/15/18/21/24 个", ErrMnemonicWordCount, len(words))
+	}
+	if len(positions) > 0 {
+		return result, fmt.Errorf("%w: 第 %s 个单词不在词表中", ErrMnemonicInvalidWord, strings.Join(positions, "、"))
+	}
+	if !mnemonicChecksumValid(words, list) {
+		return result, fmt.Errorf("%w，请检查单词是否抄错或顺序是否正确", ErrMnemonicChecksum)
+	}
+	result.ChecksumValid = true
+	result.Valid = true
+	return result, nil
+}
+
+// mnemonicChecksumValid 校验末尾校验和位：每个单词 11 位，其中最后 n/3 位为熵的 SHA-256 首字节高位
+func mnemonicChecksumValid(words []string, list *mnemonicWordlist) bool {
+	bits := new(big.Int)
+	for _, w := range words {
+		bits.Lsh(bits, 11)
+		bits.Or(bits, big.NewInt(int64(list.index[w])))
+	}
+	checksumBits := uint(len(words) / 3)
+	checksum := new(big.Int).And(bits, big.NewInt(1<<checksumBits-1))
+	entropy := new(big.Int).Rsh(bits, checksumBits).FillBytes(make([]byte, len(words)*4/3))
+	hash := sha256.Sum256(entropy)
+	return uint64(hash[0]>>(8-checksumBits)) == checksum.Uint64()
+}
+
+// newHDWallet 校验助记词并由规范化后的助记词（单词以空格连接）生成 BIP39 种子创建HD钱包
+// 与 hdwallet.NewFromMnemonic 不同，该方法不依赖 go-bip39 的全局词表，支持所有可识别的语言
+func newHDWallet(mnemonic string) (*hdwallet.Wallet, error) {
+	words := mnemonicWords(mnemonic)
+	if _, err := validateMnemonicWords(words); err != nil {
+		return nil, err
+	}
+	return hdwallet.NewFromSeed(bip39.NewSeed(strings.Join(words, " "), ""))
+}
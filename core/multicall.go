@@ -0,0 +1,299 @@
+/*
+Multicall 批量调用
+
+通过 Multicall3 合约的 aggregate3 将多个 eth_call 合并为一次调用，减少 RPC 往返：
+- 合约地址按网络配置（networks.<id>.multicall_address），多数 EVM 链部署在 DefaultMulticall3Address
+- 未配置地址的网络回退为逐个 eth_call
+- 单次调用数量过多时按 multicallChunkSize 分批，避免超出节点的 gas/响应大小限制
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultMulticall3Address Multicall3 在多数 EVM 链上的确定性部署地址
+const DefaultMulticall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+// multicallChunkSize 单次 aggregate3 打包的最大调用数
+const multicallChunkSize = 200
+
+const multicall3ABI = `[{"inputs":[{"components":[{"internalType":"address","name":"target","type":"address"},{"internalType":"bool","name":"allowFailure","type":"bool"},{"internalType":"bytes","name":"callData","type":"bytes"}],"internalType":"struct Multicall3.Call3[]","name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"bytes","name":"returnData","type":"bytes"}],"internalType":"struct Multicall3.Result[]","name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}]`
+
+// MulticallRequest 单个合约调用
+type MulticallRequest struct {
+	Target       common.Address // 目标合约
+	CallData     []byte         // ABI 编码的调用数据
+	AllowFailure bool           // 为 false 时该调用失败会使整批失败
+}
+
+// MulticallResult 单个调用结果，与请求一一对应
+type MulticallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// multicall3Call 与 aggregate3 的 Call3 结构对应（字段顺序需与ABI一致）
+type multicall3Call struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// SetMulticallAddress 设置 Multicall3 合约地址，为空时禁用批量调用
+func (a *EVMAdapter) SetMulticallAddress(address string) {
+	if address == "" || !common.IsHexAddress(address) {
+		a.multicall = nil
+		return
+	}
+	addr := common.HexToAddress(address)
+	a.multicall = &addr
+}
+
+// MulticallAddress 返回已配置的 Multicall3 合约地址（未配置时为空字符串）
+func (a *EVMAdapter) MulticallAddress() string {
+	if a.multicall == nil {
+		return ""
+	}
+	return a.multicall.Hex()
+}
+
+// Multicall 批量执行合约只读调用，结果顺序与 calls 一致
+// 未配置 Multicall3 地址时逐个调用
+func (a *EVMAdapter) Multicall(ctx context.Context, calls []MulticallRequest) ([]MulticallResult, error) {
+	if len(calls) == 0 {
+		return []MulticallResult{}, nil
+	}
+	if a.multicall == nil {
+		return a.sequentialCalls(ctx, calls)
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(multicall3ABI))
+	if err != nil {
+		return nil, fmt.Errorf("解析Multicall3 ABI失败: %w", err)
+	}
+	results := make([]MulticallResult, 0, len(calls))
+	for start := 0; start < len(calls); start += multicallChunkSize {
+		end := start + multicallChunkSize
+		if end > len(calls) {
+			end = len(calls)
+		}
+		chunk, err := a.aggregate3(ctx, parsed, calls[start:end])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, chunk...)
+	}
+	return results, nil
+}
+
+// aggregate3 将一批调用打包为一次 aggregate3 eth_call
+func (a *EVMAdapter) aggregate3(ctx context.Context, parsed abi.ABI, calls []MulticallRequest) ([]MulticallResult, error) {
+	packedCalls := make([]multicall3Call, len(calls))
+	for i, c := range calls {
+		packedCalls[i] = multicall3Call{Target: c.Target, AllowFailure: c.AllowFailure, CallData: c.CallData}
+	}
+	data, err := parsed.Pack("aggregate3", packedCalls)
+	if err != nil {
+		return nil, fmt.Errorf("打包aggregate3数据失败: %w", err)
+	}
+	out, err := a.client.CallContract(ctx, ethereum.CallMsg{To: a.multicall, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("调用Multicall3失败: %w", err)
+	}
+
+	var decoded []struct {
+		Success    bool
+		ReturnData []byte
+	}
+	if err := parsed.UnpackIntoInterface(&decoded, "aggregate3", out); err != nil {
+		return nil, fmt.Errorf("解析aggregate3结果失败: %w", err)
+	}
+	if len(decoded) != len(calls) {
+		return nil, fmt.Errorf("aggregate3 返回结果数量不匹配: %d/%d", len(decoded), len(calls))
+	}
+	results := make([]MulticallResult, len(decoded))
+	for i, r := range decoded {
+		results[i] = MulticallResult{Success: r.Success, ReturnData: r.ReturnData}
+	}
+	return results, nil
+}
+
+// sequentialCalls 逐个执行 eth_call（未配置 Multicall3 时的回退）
+func (a *EVMAdapter) sequentialCalls(ctx context.Context, calls []MulticallRequest) ([]MulticallResult, error) {
+	results := make([]MulticallResult, len(calls))
+	for i, c := range calls {
+		target := c.Target
+		out, err := a.client.CallContract(ctx, ethereum.CallMsg{To: &target, Data: c.CallData}, nil)
+		if err != nil {
+			if !c.AllowFailure {
+				return nil, fmt.Errorf("调用合约 %s 失败: %w", target.Hex(), err)
+			}
+			continue
+		}
+		results[i] = MulticallResult{Success: true, ReturnData: out}
+	}
+	return results, nil
+}
+
+// GetERC20BalancesBatch 批量查询 owner 在多个 ERC20 代币上的余额
+// 返回以代币地址（checksum 格式）为键的余额；调用失败或返回异常的代币不出现在结果中
+func (a *EVMAdapter) GetERC20BalancesBatch(ctx context.Context, owner string, tokens []string) (map[string]*big.Int, error) {
+	if !common.IsHexAddress(owner) {
+		return nil, fmt.Errorf("无效的地址: %s", owner)
+	}
+	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return nil, fmt.Errorf("解析ERC20 ABI失败: %w", err)
+	}
+	data, err := parsed.Pack("balanceOf", common.HexToAddress(owner))
+	if err != nil {
+		return nil, fmt.Errorf("打包balanceOf数据失败: %w", err)
+	}
+
+	calls := make([]MulticallRequest, 0, len(tokens))
+	for _, token := range tokens {
+		if !common.IsHexAddress(token) {
+			return nil, fmt.Errorf("无效的代币地址: %s", token)
+		}
+		calls = append(calls, MulticallRequest{Target: common.HexToAddress(token), CallData: data, AllowFailure: true})
+	}
+	results, err := a.Multicall(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[string]*big.Int, len(calls))
+	for i, r := range results {
+		// 非合约地址调用成功但返回空数据，需一并排除
+		if !r.Success || len(r.ReturnData) < 32 {
+			continue
+		}
+		balances[calls[i].Target.Hex()] = new(big.Int).SetBytes(r.ReturnData[:32])
+	}
+	return balances, nil
+}
+
+// ERC20Metadata 代币符号与小数位（名称仅 GetERC20BalanceWithMetadata 填充）
+type ERC20Metadata struct {
+	Name     string `json:"name,omitempty"`
+	Symbol   string `json:"symbol"`
+	Decimals int    `json:"decimals"`
+}
+
+// GetERC20MetadataBatch 批量查询多个代币的 symbol 与 decimals
+// 返回以代币地址（checksum 格式）为键的结果；decimals 调用失败的代币不出现在结果中，symbol 失败时为空
+func (a *EVMAdapter) GetERC20MetadataBatch(ctx context.Context, tokens []string) (map[string]ERC20Metadata, error) {
+	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return nil, fmt.Errorf("解析ERC20 ABI失败: %w", err)
+	}
+	decimalsData, _ := parsed.Pack("decimals")
+	symbolData, _ := parsed.Pack("symbol")
+
+	calls := make([]MulticallRequest, 0, len(tokens)*2)
+	for _, token := range tokens {
+		if !common.IsHexAddress(token) {
+			return nil, fmt.Errorf("无效的代币地址: %s", token)
+		}
+		target := common.HexToAddress(token)
+		calls = append(calls,
+			MulticallRequest{Target: target, CallData: decimalsData, AllowFailure: true},
+			MulticallRequest{Target: target, CallData: symbolData, AllowFailure: true},
+		)
+	}
+	results, err := a.Multicall(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := make(map[string]ERC20Metadata, len(tokens))
+	for i := 0; i+1 < len(results); i += 2 {
+		dec := results[i]
+		if !dec.Success || len(dec.ReturnData) < 32 {
+			continue
+		}
+		metadata[calls[i].Target.Hex()] = ERC20Metadata{
+			Decimals: int(new(big.Int).SetBytes(dec.ReturnData[:32]).Int64()),
+			Symbol:   decodeTokenSymbol(parsed, results[i+1]),
+		}
+	}
+	return metadata, nil
+}
+
+// decodeTokenSymbol 解析 symbol() 返回值，兼容返回 bytes32 的早期代币（如 MKR）
+func decodeTokenSymbol(parsed abi.ABI, r MulticallResult) string {
+	return decodeTokenString(parsed, "symbol", r)
+}
+
+// decodeTokenString 解析 name()/symbol() 返回的字符串，兼容返回 bytes32 的早期代币
+func decodeTokenString(parsed abi.ABI, method string, r MulticallResult) string {
+	if !r.Success || len(r.ReturnData) == 0 {
+		return ""
+	}
+	if vals, err := parsed.Unpack(method, r.ReturnData); err == nil && len(vals) > 0 {
+		if s, ok := vals[0].(string); ok {
+			return s
+		}
+	}
+	if len(r.ReturnData) == 32 {
+		return strings.TrimRight(string(r.ReturnData), "\x00")
+	}
+	return ""
+}
+
+// GetERC20BalanceWithMetadata 一次 Multicall 查询 owner 的代币余额与 name/symbol/decimals
+// meta 非空（调用方已缓存元数据）时只查询余额并原样返回 meta；decimals 调用失败视为不是 ERC20 代币
+func (a *EVMAdapter) GetERC20BalanceWithMetadata(ctx context.Context, owner, token string, meta *ERC20Metadata) (*big.Int, *ERC20Metadata, error) {
+	if !common.IsHexAddress(owner) {
+		return nil, nil, fmt.Errorf("无效的地址: %s", owner)
+	}
+	if !common.IsHexAddress(token) {
+		return nil, nil, fmt.Errorf("无效的代币地址: %s", token)
+	}
+	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return nil, nil, fmt.Errorf("解析ERC20 ABI失败: %w", err)
+	}
+	balanceData, err := parsed.Pack("balanceOf", common.HexToAddress(owner))
+	if err != nil {
+		return nil, nil, fmt.Errorf("打包balanceOf数据失败: %w", err)
+	}
+	target := common.HexToAddress(token)
+	calls := []MulticallRequest{{Target: target, CallData: balanceData, AllowFailure: true}}
+	if meta == nil {
+		for _, method := range []string{"decimals", "symbol", "name"} {
+			data, _ := parsed.Pack(method)
+			calls = append(calls, MulticallRequest{Target: target, CallData: data, AllowFailure: true})
+		}
+	}
+	results, err := a.Multicall(ctx, calls)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 非合约地址调用成功但返回空数据，需一并排除
+	if !results[0].Success || len(results[0].ReturnData) < 32 {
+		return nil, nil, fmt.Errorf("查询 %s 余额失败，地址可能不是ERC20代币", target.Hex())
+	}
+	balance := new(big.Int).SetBytes(results[0].ReturnData[:32])
+	if meta != nil {
+		return balance, meta, nil
+	}
+	dec := results[1]
+	if !dec.Success || len(dec.ReturnData) < 32 {
+		return nil, nil, fmt.Errorf("查询 %s 的 decimals 失败，地址可能不是ERC20代币", target.Hex())
+	}
+	return balance, &ERC20Metadata{
+		Name:     decodeTokenString(parsed, "name", results[3]),
+		Symbol:   decodeTokenString(parsed, "symbol", results[2]),
+		Decimals: int(new(big.Int).SetBytes(dec.ReturnData[:32]).Int64()),
+	}, nil
+}
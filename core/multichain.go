@@ -2,9 +2,14 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math/big"
+	"net/url"
+	"sort"
 	"sync"
+	"time"
 	"wallet/config"
 )
 
@@ -14,23 +19,35 @@ type MultiChainManager struct {
 	solanaAdapters   map[string]*SolanaAdapter
 	bitcoinAdapters  map[string]*BitcoinAdapter
 	currentNetwork   string
-	currentChainType string // "evm", "solana", "bitcoin"
+	currentChainType string                          // "evm", "solana", "bitcoin"
+	customNetworks   map[string]config.NetworkConfig // 运行时添加的自定义网络（不在配置文件中）
+	confirmations    map[string]uint64               // 运行时设置的最终确认数，优先于网络配置
+	feeCeiling       FeeCeilingFunc                  // 默认手续费上限，应用到所有EVM适配器（包括之后添加的自定义网络）
+	historyStore     HistoryStore                    // 交易历史存储，应用到所有EVM适配器（包括之后添加的自定义网络）
+	metrics          *Metrics                        // RPC调用指标，应用到所有EVM适配器（包括之后添加的自定义网络）
 	mu               sync.RWMutex
 }
 
 // NetworkInfo 网络信息
 type NetworkInfo struct {
-	ID            string         `json:"id"`
-	Name          string         `json:"name"`
-	ChainID       int64          `json:"chain_id"`
-	Symbol        string         `json:"symbol"`
-	Decimals      int            `json:"decimals"`
-	BlockExplorer string         `json:"block_explorer"`
-	Testnet       bool           `json:"testnet"`
-	LatestBlock   uint64         `json:"latest_block"`
-	GasSuggestion *GasSuggestion `json:"gas_suggestion"`
-	Connected     bool           `json:"connected"`
-	ChainType     string         `json:"chain_type"` // 新增字段：链类型 (evm, solana, bitcoin)
+	ID              string         `json:"id"`
+	Name            string         `json:"name"`
+	ChainID         int64          `json:"chain_id"`                    // 网络配置声明的链ID
+	DetectedChainID int64          `json:"detected_chain_id,omitempty"` // 节点实际返回的链ID（仅EVM，检测失败时为0）
+	ChainIDMismatch bool           `json:"chain_id_mismatch,omitempty"` // 节点链ID与声明不一致
+	Symbol          string         `json:"symbol"`
+	Decimals        int            `json:"decimals"`
+	BlockExplorer   string         `json:"block_explorer"`
+	Testnet         bool           `json:"testnet"`
+	LatestBlock     uint64         `json:"latest_block"`
+	GasSuggestion   *GasSuggestion `json:"gas_suggestion"`
+	Connected       bool           `json:"connected"`
+	ChainType       string         `json:"chain_type"`                  // 新增字段：链类型 (evm, solana, bitcoin)
+	Multicall       string         `json:"multicall_address,omitempty"` // Multicall3 合约地址（仅EVM）
+	Confirmations   uint64         `json:"required_confirmations"`      // 交易视为最终确认所需的确认数（交易所在区块之后的出块数）
+	RPCURL          string         `json:"rpc_url,omitempty"`           // RPC 地址（仅自定义网络返回，配置文件中的地址可能含密钥）
+	RPCURLs         []string       `json:"rpc_urls,omitempty"`          // 全部RPC地址，首个为主节点，其余为备用节点（仅自定义网络返回）
+	Custom          bool           `json:"custom,omitempty"`            // 是否为运行时添加的自定义网络
 }
 
 // NewMultiChainManager 创建多链管理器
@@ -39,6 +56,8 @@ func NewMultiChainManager() (*MultiChainManager, error) {
 		evmAdapters:     make(map[string]*EVMAdapter),
 		solanaAdapters:  make(map[string]*SolanaAdapter),
 		bitcoinAdapters: make(map[string]*BitcoinAdapter),
+		customNetworks:  make(map[string]config.NetworkConfig),
+		confirmations:   make(map[string]uint64),
 	}
 
 	// 初始化所有启用的网络
@@ -53,7 +72,7 @@ func NewMultiChainManager() (*MultiChainManager, error) {
 			// 初始化Solana适配器
 			adapter, err := NewSolanaAdapter(networkConfig.RPCURL)
 			if err != nil {
-				fmt.Printf("警告: 无法创建Solana网络适配器 %s: %v\n", networkID, err)
+				slog.WarnContext(context.Background(), "solana adapter init failed", "network", networkID, "error", err)
 				continue
 			}
 			manager.solanaAdapters[networkID] = adapter
@@ -61,18 +80,23 @@ func NewMultiChainManager() (*MultiChainManager, error) {
 			// 初始化Bitcoin适配器
 			adapter, err := NewBitcoinAdapter(networkConfig.RPCURL)
 			if err != nil {
-				fmt.Printf("警告: 无法创建Bitcoin网络适配器 %s: %v\n", networkID, err)
+				slog.WarnContext(context.Background(), "bitcoin adapter init failed", "network", networkID, "error", err)
 				continue
 			}
 			manager.bitcoinAdapters[networkID] = adapter
 		default:
-			// 初始化EVM适配器
-			adapter, err := NewEVMAdapter(networkConfig.RPCURL)
+			// 初始化EVM适配器（节点链ID与配置不一致时跳过，避免签出错误链的交易）
+			adapter, err := NewEVMAdapterForChain(networkConfig.RPCURL, networkConfig.ChainID, networkConfig.FallbackRPCURLs...)
 			if err != nil {
 				// 记录错误但不终止，允许其他网络正常工作
-				fmt.Printf("警告: 无法连接到网络 %s: %v\n", networkID, err)
+				slog.WarnContext(context.Background(), "network connection failed", "network", networkID, "error", err)
 				continue
 			}
+			adapter.SetMulticallAddress(networkConfig.MulticallAddress)
+			adapter.SetDisperseAddress(networkConfig.DisperseAddress)
+			adapter.SetRequiredConfirmations(networkConfig.RequiredConfirmations())
+			adapter.SetExplorerAPI(networkConfig.ExplorerAPIURL)
+			adapter.SetWSURL(wsURLFor(networkConfig.RPCURL, networkConfig.WSURL))
 			manager.evmAdapters[networkID] = adapter
 		}
 	}
@@ -136,7 +160,19 @@ func (mcm *MultiChainManager) GetCurrentChainType() string {
 }
 
 // SwitchNetwork 切换网络
+// EVM 网络切换前重新检测节点链ID，与配置声明不一致时拒绝切换
 func (mcm *MultiChainManager) SwitchNetwork(networkID string) error {
+	mcm.mu.RLock()
+	evmAdapter := mcm.evmAdapters[networkID]
+	mcm.mu.RUnlock()
+	if evmAdapter != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), chainIDCheckTimeout)
+		defer cancel()
+		if _, err := evmAdapter.VerifyChainID(ctx); err != nil {
+			return fmt.Errorf("切换到网络 %s 失败: %w", networkID, err)
+		}
+	}
+
 	mcm.mu.Lock()
 	defer mcm.mu.Unlock()
 
@@ -223,15 +259,16 @@ func (mcm *MultiChainManager) GetAvailableNetworks() []NetworkInfo {
 
 	// 添加EVM网络
 	for networkID, adapter := range mcm.evmAdapters {
-		networkConfig, err := config.GetNetwork(networkID)
+		networkConfig, err := mcm.networkConfigLocked(networkID)
 		if err != nil {
 			continue
 		}
 
 		ctx := context.Background()
-		chainID, err := adapter.client.NetworkID(ctx)
-		if err != nil {
-			chainID = big.NewInt(networkConfig.ChainID)
+		chainID := big.NewInt(networkConfig.ChainID)
+		var detectedChainID int64
+		if detected, err := adapter.DetectChainID(ctx); err == nil {
+			detectedChainID = detected.Int64()
 		}
 
 		latestBlock, err := adapter.client.BlockNumber(ctx)
@@ -251,23 +288,30 @@ func (mcm *MultiChainManager) GetAvailableNetworks() []NetworkInfo {
 		}
 
 		networks = append(networks, NetworkInfo{
-			ID:            networkID,
-			Name:          networkConfig.Name,
-			ChainID:       chainID.Int64(),
-			Symbol:        networkConfig.Symbol,
-			Decimals:      networkConfig.Decimals,
-			BlockExplorer: networkConfig.BlockExplorer,
-			Testnet:       networkConfig.Testnet,
-			LatestBlock:   latestBlock,
-			GasSuggestion: gasSuggestion,
-			Connected:     true,
-			ChainType:     "evm",
+			ID:              networkID,
+			Name:            networkConfig.Name,
+			ChainID:         chainID.Int64(),
+			DetectedChainID: detectedChainID,
+			ChainIDMismatch: detectedChainID != 0 && detectedChainID != networkConfig.ChainID,
+			Symbol:          networkConfig.Symbol,
+			Decimals:        networkConfig.Decimals,
+			BlockExplorer:   networkConfig.BlockExplorer,
+			Testnet:         networkConfig.Testnet,
+			LatestBlock:     latestBlock,
+			GasSuggestion:   gasSuggestion,
+			Connected:       true,
+			ChainType:       "evm",
+			Multicall:       adapter.MulticallAddress(),
+			Confirmations:   adapter.RequiredConfirmations(),
+			RPCURL:          mcm.customRPCURLLocked(networkID),
+			RPCURLs:         mcm.customRPCURLsLocked(networkID),
+			Custom:          mcm.isCustomLocked(networkID),
 		})
 	}
 
 	// 添加Solana网络
 	for networkID, adapter := range mcm.solanaAdapters {
-		networkConfig, err := config.GetNetwork(networkID)
+		networkConfig, err := mcm.networkConfigLocked(networkID)
 		if err != nil {
 			continue
 		}
@@ -293,14 +337,15 @@ func (mcm *MultiChainManager) GetAvailableNetworks() []NetworkInfo {
 				MaxFee:   big.NewInt(0),
 				GasPrice: big.NewInt(0),
 			},
-			Connected: true,
-			ChainType: "solana",
+			Connected:     true,
+			ChainType:     "solana",
+			Confirmations: mcm.requiredConfirmationsLocked(networkID, networkConfig),
 		})
 	}
 
 	// 添加Bitcoin网络
 	for networkID, adapter := range mcm.bitcoinAdapters {
-		networkConfig, err := config.GetNetwork(networkID)
+		networkConfig, err := mcm.networkConfigLocked(networkID)
 		if err != nil {
 			continue
 		}
@@ -326,8 +371,9 @@ func (mcm *MultiChainManager) GetAvailableNetworks() []NetworkInfo {
 				MaxFee:   big.NewInt(0),
 				GasPrice: big.NewInt(0),
 			},
-			Connected: true,
-			ChainType: "bitcoin",
+			Connected:     true,
+			ChainType:     "bitcoin",
+			Confirmations: mcm.requiredConfirmationsLocked(networkID, networkConfig),
 		})
 	}
 
@@ -337,7 +383,7 @@ func (mcm *MultiChainManager) GetAvailableNetworks() []NetworkInfo {
 // GetNetworkInfo 获取网络信息
 func (mcm *MultiChainManager) GetNetworkInfo(networkID string) (*NetworkInfo, error) {
 	// 获取配置
-	networkConfig, err := config.GetNetwork(networkID)
+	networkConfig, err := mcm.NetworkConfig(networkID)
 	if err != nil {
 		return nil, err
 	}
@@ -350,17 +396,18 @@ func (mcm *MultiChainManager) GetNetworkInfo(networkID string) (*NetworkInfo, er
 
 	ctx := context.Background()
 
-	var chainID *big.Int
+	chainID := big.NewInt(networkConfig.ChainID)
+	var detectedChainID int64
 	var latestBlock uint64
 	var gasSuggestion *GasSuggestion
 	var chainType string
+	var multicall string
 
 	// 根据适配器类型获取信息
 	switch a := adapter.(type) {
 	case *EVMAdapter:
-		chainID, err = a.client.NetworkID(ctx)
-		if err != nil {
-			chainID = big.NewInt(networkConfig.ChainID)
+		if detected, err := a.DetectChainID(ctx); err == nil {
+			detectedChainID = detected.Int64()
 		}
 
 		latestBlock, err = a.client.BlockNumber(ctx)
@@ -379,9 +426,9 @@ func (mcm *MultiChainManager) GetNetworkInfo(networkID string) (*NetworkInfo, er
 			}
 		}
 		chainType = "evm"
+		multicall = a.MulticallAddress()
 
 	case *SolanaAdapter:
-		chainID = big.NewInt(networkConfig.ChainID)
 		latestBlock = 0 // TODO: 获取最新区块
 		gasSuggestion = &GasSuggestion{
 			ChainID:  chainID,
@@ -393,7 +440,6 @@ func (mcm *MultiChainManager) GetNetworkInfo(networkID string) (*NetworkInfo, er
 		chainType = "solana"
 
 	case *BitcoinAdapter:
-		chainID = big.NewInt(networkConfig.ChainID)
 		latestBlock = 0 // TODO: 获取最新区块
 		gasSuggestion = &GasSuggestion{
 			ChainID:  chainID,
@@ -409,26 +455,170 @@ func (mcm *MultiChainManager) GetNetworkInfo(networkID string) (*NetworkInfo, er
 	}
 
 	return &NetworkInfo{
-		ID:            networkID,
-		Name:          networkConfig.Name,
-		ChainID:       chainID.Int64(),
-		Symbol:        networkConfig.Symbol,
-		Decimals:      networkConfig.Decimals,
-		BlockExplorer: networkConfig.BlockExplorer,
-		Testnet:       networkConfig.Testnet,
-		LatestBlock:   latestBlock,
-		GasSuggestion: gasSuggestion,
-		Connected:     true,
-		ChainType:     chainType,
+		ID:              networkID,
+		Name:            networkConfig.Name,
+		ChainID:         chainID.Int64(),
+		DetectedChainID: detectedChainID,
+		ChainIDMismatch: detectedChainID != 0 && detectedChainID != networkConfig.ChainID,
+		Symbol:          networkConfig.Symbol,
+		Decimals:        networkConfig.Decimals,
+		BlockExplorer:   networkConfig.BlockExplorer,
+		Testnet:         networkConfig.Testnet,
+		LatestBlock:     latestBlock,
+		GasSuggestion:   gasSuggestion,
+		Connected:       true,
+		ChainType:       chainType,
+		Multicall:       multicall,
+		Confirmations:   mcm.RequiredConfirmations(networkID),
+		RPCURL:          mcm.customRPCURL(networkID),
+		RPCURLs:         mcm.customRPCURLs(networkID),
+		Custom:          mcm.IsCustomNetwork(networkID),
 	}, nil
 }
 
-// AddNetwork 添加新网络
-func (mcm *MultiChainManager) AddNetwork(networkID string, rpcURL string, chainType string) error {
+// AddNetwork 在运行时添加自定义EVM网络（对应 wallet_addEthereumChain）
+// 校验 RPC 可连接且节点链ID与 info.ChainID 一致；网络ID或链ID已被使用时拒绝
+func (mcm *MultiChainManager) AddNetwork(info *NetworkInfo) error {
+	if info == nil || info.ID == "" {
+		return fmt.Errorf("网络ID不能为空")
+	}
+	urls := rpcEndpointURLs(info.RPCURL, info.RPCURLs)
+	if len(urls) == 0 {
+		return fmt.Errorf("RPC 地址不能为空")
+	}
+	if info.ChainID <= 0 {
+		return fmt.Errorf("Chain ID 必须大于 0")
+	}
+	if info.ChainType != "" && info.ChainType != "evm" {
+		return fmt.Errorf("自定义网络仅支持EVM链，不支持: %s", info.ChainType)
+	}
+	if err := mcm.checkNetworkAvailable(info.ID, info.ChainID); err != nil {
+		return err
+	}
+
+	// 连接并校验链ID（不持有锁，避免慢节点阻塞其他请求）
+	// 用户提供的节点地址只连接公网地址（security.custom_rpc_allowed_hosts 除外），连接时校验可防 DNS 重绑定
+	adapter, err := newEVMAdapterForChain(urls[0], info.ChainID, urls[1:], SafeDialer(config.AppConfig.Security.CustomRPCAllowedHosts))
+	if err != nil {
+		return fmt.Errorf("添加网络 %s 失败: %w", info.ID, err)
+	}
+	adapter.SetMulticallAddress(info.Multicall)
+	adapter.SetWSURL(wsURLFor(urls[0], ""))
+
+	decimals := info.Decimals
+	if decimals == 0 {
+		decimals = 18
+	}
+	networkConfig := config.NetworkConfig{
+		Name:             info.Name,
+		RPCURL:           urls[0],
+		FallbackRPCURLs:  urls[1:],
+		ChainID:          info.ChainID,
+		Symbol:           info.Symbol,
+		Decimals:         decimals,
+		BlockExplorer:    info.BlockExplorer,
+		Enabled:          true,
+		Testnet:          info.Testnet,
+		MulticallAddress: info.Multicall,
+		MinConfirmations: int(info.Confirmations),
+	}
+	adapter.SetRequiredConfirmations(networkConfig.RequiredConfirmations())
+
+	mcm.mu.Lock()
+	defer mcm.mu.Unlock()
+	// 连接期间可能已有同名网络被添加
+	if err := mcm.checkNetworkAvailableLocked(info.ID, info.ChainID); err != nil {
+		adapter.Close()
+		return err
+	}
+	if n, ok := mcm.confirmations[info.ID]; ok {
+		adapter.SetRequiredConfirmations(n)
+	}
+	adapter.SetFeeCeiling(info.ID, mcm.feeCeiling)
+	adapter.SetHistoryStore(info.ID, mcm.historyStore)
+	adapter.SetMetrics(info.ID, mcm.metrics)
+	mcm.evmAdapters[info.ID] = adapter
+	mcm.customNetworks[info.ID] = networkConfig
+	return nil
+}
+
+// RequiredConfirmations 网络的最终确认数：运行时设置的值优先，其次为网络配置（min_confirmations 或链默认值）
+// 网络不存在时返回 0
+func (mcm *MultiChainManager) RequiredConfirmations(networkID string) uint64 {
+	mcm.mu.RLock()
+	defer mcm.mu.RUnlock()
+	networkConfig, err := mcm.networkConfigLocked(networkID)
+	if err != nil {
+		return 0
+	}
+	return mcm.requiredConfirmationsLocked(networkID, networkConfig)
+}
+
+func (mcm *MultiChainManager) requiredConfirmationsLocked(networkID string, networkConfig *config.NetworkConfig) uint64 {
+	if n, ok := mcm.confirmations[networkID]; ok {
+		return n
+	}
+	return networkConfig.RequiredConfirmations()
+}
+
+// SetRequiredConfirmations 在运行时设置网络的最终确认数，confirmations 为 0 时恢复网络配置的值
+// 同步更新EVM适配器，之后的确认等待与待确认交易跟踪按新值判断
+func (mcm *MultiChainManager) SetRequiredConfirmations(networkID string, confirmations uint64) error {
+	mcm.mu.Lock()
+	defer mcm.mu.Unlock()
+	networkConfig, err := mcm.networkConfigLocked(networkID)
+	if err != nil {
+		return err
+	}
+	if confirmations == 0 {
+		delete(mcm.confirmations, networkID)
+	} else {
+		mcm.confirmations[networkID] = confirmations
+	}
+	if adapter, ok := mcm.evmAdapters[networkID]; ok {
+		adapter.SetRequiredConfirmations(mcm.requiredConfirmationsLocked(networkID, networkConfig))
+	}
+	return nil
+}
+
+// SetFeeCeiling 设置所有EVM网络发送交易时的默认手续费上限，fn 为空时不限制（见 FeeCeilingFunc）
+func (mcm *MultiChainManager) SetFeeCeiling(fn FeeCeilingFunc) {
 	mcm.mu.Lock()
 	defer mcm.mu.Unlock()
+	mcm.feeCeiling = fn
+	for networkID, adapter := range mcm.evmAdapters {
+		adapter.SetFeeCeiling(networkID, fn)
+	}
+}
+
+// SetHistoryStore 设置所有EVM网络的交易历史存储，store 为空时每次查询重新扫描（见 HistoryStore）
+func (mcm *MultiChainManager) SetHistoryStore(store HistoryStore) {
+	mcm.mu.Lock()
+	defer mcm.mu.Unlock()
+	mcm.historyStore = store
+	for networkID, adapter := range mcm.evmAdapters {
+		adapter.SetHistoryStore(networkID, store)
+	}
+}
+
+// SetMetrics 设置所有EVM网络的RPC调用指标，m 为空时不记录（见 Metrics）
+func (mcm *MultiChainManager) SetMetrics(m *Metrics) {
+	mcm.mu.Lock()
+	defer mcm.mu.Unlock()
+	mcm.metrics = m
+	for networkID, adapter := range mcm.evmAdapters {
+		adapter.SetMetrics(networkID, m)
+	}
+}
+
+// checkNetworkAvailable 校验网络ID与链ID未被已有网络使用
+func (mcm *MultiChainManager) checkNetworkAvailable(networkID string, chainID int64) error {
+	mcm.mu.RLock()
+	defer mcm.mu.RUnlock()
+	return mcm.checkNetworkAvailableLocked(networkID, chainID)
+}
 
-	// 检查网络是否已存在
+func (mcm *MultiChainManager) checkNetworkAvailableLocked(networkID string, chainID int64) error {
 	if _, exists := mcm.evmAdapters[networkID]; exists {
 		return fmt.Errorf("网络 %s 已存在", networkID)
 	}
@@ -438,35 +628,18 @@ func (mcm *MultiChainManager) AddNetwork(networkID string, rpcURL string, chainT
 	if _, exists := mcm.bitcoinAdapters[networkID]; exists {
 		return fmt.Errorf("网络 %s 已存在", networkID)
 	}
-
-	// 创建新的适配器
-	switch chainType {
-	case "evm":
-		adapter, err := NewEVMAdapter(rpcURL)
-		if err != nil {
-			return fmt.Errorf("创建EVM网络适配器失败: %w", err)
-		}
-		mcm.evmAdapters[networkID] = adapter
-	case "solana":
-		adapter, err := NewSolanaAdapter(rpcURL)
-		if err != nil {
-			return fmt.Errorf("创建Solana网络适配器失败: %w", err)
-		}
-		mcm.solanaAdapters[networkID] = adapter
-	case "bitcoin":
-		adapter, err := NewBitcoinAdapter(rpcURL)
-		if err != nil {
-			return fmt.Errorf("创建Bitcoin网络适配器失败: %w", err)
+	if _, exists := config.AppConfig.Networks[networkID]; exists {
+		return fmt.Errorf("网络 %s 已在配置文件中定义", networkID)
+	}
+	for existingID := range mcm.evmAdapters {
+		if cfg, err := mcm.networkConfigLocked(existingID); err == nil && cfg.ChainID == chainID {
+			return fmt.Errorf("Chain ID %d 已由网络 %s 使用", chainID, existingID)
 		}
-		mcm.bitcoinAdapters[networkID] = adapter
-	default:
-		return fmt.Errorf("不支持的链类型: %s", chainType)
 	}
-
 	return nil
 }
 
-// RemoveNetwork 移除网络
+// RemoveNetwork 移除网络并关闭其节点连接，当前正在使用的网络不能移除
 func (mcm *MultiChainManager) RemoveNetwork(networkID string) error {
 	mcm.mu.Lock()
 	defer mcm.mu.Unlock()
@@ -477,8 +650,10 @@ func (mcm *MultiChainManager) RemoveNetwork(networkID string) error {
 	}
 
 	// 移除网络
-	if _, exists := mcm.evmAdapters[networkID]; exists {
+	if adapter, exists := mcm.evmAdapters[networkID]; exists {
 		delete(mcm.evmAdapters, networkID)
+		delete(mcm.customNetworks, networkID)
+		adapter.Close()
 		return nil
 	}
 
@@ -495,6 +670,67 @@ func (mcm *MultiChainManager) RemoveNetwork(networkID string) error {
 	return fmt.Errorf("网络 %s 不存在", networkID)
 }
 
+// IsCustomNetwork 判断网络是否为运行时添加的自定义网络
+func (mcm *MultiChainManager) IsCustomNetwork(networkID string) bool {
+	mcm.mu.RLock()
+	defer mcm.mu.RUnlock()
+	return mcm.isCustomLocked(networkID)
+}
+
+func (mcm *MultiChainManager) isCustomLocked(networkID string) bool {
+	_, exists := mcm.customNetworks[networkID]
+	return exists
+}
+
+func (mcm *MultiChainManager) customRPCURL(networkID string) string {
+	mcm.mu.RLock()
+	defer mcm.mu.RUnlock()
+	return mcm.customRPCURLLocked(networkID)
+}
+
+func (mcm *MultiChainManager) customRPCURLLocked(networkID string) string {
+	return mcm.customNetworks[networkID].RPCURL
+}
+
+func (mcm *MultiChainManager) customRPCURLs(networkID string) []string {
+	mcm.mu.RLock()
+	defer mcm.mu.RUnlock()
+	return mcm.customRPCURLsLocked(networkID)
+}
+
+func (mcm *MultiChainManager) customRPCURLsLocked(networkID string) []string {
+	networkConfig, exists := mcm.customNetworks[networkID]
+	if !exists {
+		return nil
+	}
+	return rpcEndpointURLs(networkConfig.RPCURL, networkConfig.FallbackRPCURLs)
+}
+
+// NetworkHealth 检查指定EVM网络各RPC节点的健康状态
+func (mcm *MultiChainManager) NetworkHealth(ctx context.Context, networkID string) ([]RPCEndpointStatus, error) {
+	mcm.mu.RLock()
+	adapter, exists := mcm.evmAdapters[networkID]
+	mcm.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("网络 %s 不存在或不是EVM网络", networkID)
+	}
+	return adapter.RPCHealth(ctx), nil
+}
+
+// NetworkConfig 获取网络配置，自定义网络优先，其次为配置文件
+func (mcm *MultiChainManager) NetworkConfig(networkID string) (*config.NetworkConfig, error) {
+	mcm.mu.RLock()
+	defer mcm.mu.RUnlock()
+	return mcm.networkConfigLocked(networkID)
+}
+
+func (mcm *MultiChainManager) networkConfigLocked(networkID string) (*config.NetworkConfig, error) {
+	if networkConfig, exists := mcm.customNetworks[networkID]; exists {
+		return &networkConfig, nil
+	}
+	return config.GetNetwork(networkID)
+}
+
 // CheckNetworkHealth 检查网络健康状态
 func (mcm *MultiChainManager) CheckNetworkHealth(networkID string) error {
 	adapter, err := mcm.GetAdapter(networkID)
@@ -544,6 +780,82 @@ func (mcm *MultiChainManager) CheckAllNetworksHealth() map[string]error {
 	return health
 }
 
+// NetworkProbe 单个网络的RPC连通性检查结果
+type NetworkProbe struct {
+	Network     string `json:"network"`
+	ChainType   string `json:"chain_type,omitempty"`
+	Status      string `json:"status"` // up、down，或 skipped（该链类型尚不支持检查）
+	LatestBlock uint64 `json:"latest_block,omitempty"`
+	LatencyMs   int64  `json:"latency_ms"`
+	Error       string `json:"error,omitempty"`
+}
+
+// 网络检查状态
+const (
+	NetworkProbeUp      = "up"
+	NetworkProbeDown    = "down"
+	NetworkProbeSkipped = "skipped"
+)
+
+// ProbeNetworks 并发检查所有已配置网络（配置文件中启用的网络与自定义网络），按网络ID排序返回
+// EVM 网络调用 eth_blockNumber，单个网络的耗时受 ctx 的超时控制；启用但启动时未能连接的网络直接标记为 down
+func (mcm *MultiChainManager) ProbeNetworks(ctx context.Context) []NetworkProbe {
+	mcm.mu.RLock()
+	probes := make(map[string]*NetworkProbe)
+	evm := make(map[string]*EVMAdapter, len(mcm.evmAdapters))
+	for networkID, adapter := range mcm.evmAdapters {
+		evm[networkID] = adapter
+		probes[networkID] = &NetworkProbe{Network: networkID, ChainType: "evm"}
+	}
+	for networkID := range mcm.solanaAdapters {
+		probes[networkID] = &NetworkProbe{Network: networkID, ChainType: "solana", Status: NetworkProbeSkipped}
+	}
+	for networkID := range mcm.bitcoinAdapters {
+		probes[networkID] = &NetworkProbe{Network: networkID, ChainType: "bitcoin", Status: NetworkProbeSkipped}
+	}
+	mcm.mu.RUnlock()
+	for networkID := range config.GetEnabledNetworks() {
+		if _, exists := probes[networkID]; !exists {
+			probes[networkID] = &NetworkProbe{Network: networkID, Status: NetworkProbeDown, Error: "网络适配器未初始化（启动时连接失败）"}
+		}
+	}
+
+	var wg sync.WaitGroup
+	for networkID, adapter := range evm {
+		wg.Add(1)
+		go func(probe *NetworkProbe, adapter *EVMAdapter) {
+			defer wg.Done()
+			start := time.Now()
+			block, err := adapter.client.BlockNumber(ctx)
+			probe.LatencyMs = time.Since(start).Milliseconds()
+			if err != nil {
+				probe.Status = NetworkProbeDown
+				probe.Error = stripRequestURL(err).Error()
+				return
+			}
+			probe.Status = NetworkProbeUp
+			probe.LatestBlock = block
+		}(probes[networkID], adapter)
+	}
+	wg.Wait()
+
+	out := make([]NetworkProbe, 0, len(probes))
+	for _, probe := range probes {
+		out = append(out, *probe)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Network < out[j].Network })
+	return out
+}
+
+// stripRequestURL 去掉错误中的请求地址，RPC地址可能含密钥
+func stripRequestURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
 // CrossChainBalance 跨链余额查询
 func (mcm *MultiChainManager) GetCrossChainBalance(address string, networks []string) (map[string]*big.Int, error) {
 	balances := make(map[string]*big.Int)
@@ -0,0 +1,626 @@
+/*
+多签交易链上执行（Gnosis Safe 合约）
+
+多签钱包登记的是已部署的 Safe 合约：登记时读取合约的 getThreshold/getOwners，阈值须一致且签名者均为 owner。
+发起交易时确定 Safe nonce 并随交易保存：取链上 nonce 与该钱包待处理交易最大 nonce+1 中的较大者（与 Safe 的交易队列一致），
+calldata 原样保存在 MultiSigTransaction.Data 中。签名校验与链上执行都使用保存的 nonce。
+
+添加签名时按 Safe v1.3+ 的 EIP-712 规则在本地计算 safeTxHash（domain 为 chainId + verifyingContract，
+nonce 取 MultiSigTransaction.Nonce），恢复出的签名者必须是声明的活跃签名者，且每个签名者只能签一次。
+
+签名收集完成后，由执行者调用 Safe 合约的 execTransaction 提交交易：
+1. 读取 Safe 当前 nonce（须等于交易保存的 nonce），以保存的 nonce 通过合约的 getTransactionHash 计算 safeTxHash（兼容各版本的 EIP-712 domain）
+2. 逐个校验已收集签名能恢复出声明的签名者，只有有效签名计入阈值
+3. 按签名者地址升序拼接签名（Safe 合约要求），调用 execTransaction
+
+签名格式：65 字节 [R || S || V]
+- V 为 27/28：对 safeTxHash 的 EIP-712 签名（0/1 自动转换为 27/28）
+- V 为 31/32：对 safeTxHash 的 eth_sign 签名
+暂不支持合约签名（EIP-1271）与 approveHash 预批准。
+交易固定为 CALL 操作，不使用 Safe 的 Gas 退款机制（safeTxGas/baseGas/gasPrice 均为 0）。
+*/
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"wallet/config"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 多签交易状态
+const (
+	MultiSigStatusReady     = "ready_to_execute" // 已达到签名阈值
+	MultiSigStatusExecuting = "executing"        // 正在提交链上交易
+	MultiSigStatusExecuted  = "executed"         // 已在链上执行
+)
+
+const gnosisSafeABI = `[
+	{"inputs":[],"name":"getOwners","outputs":[{"name":"","type":"address[]"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"getThreshold","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"nonce","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"_nonce","type":"uint256"}],"name":"getTransactionHash","outputs":[{"name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"signatures","type":"bytes"}],"name":"execTransaction","outputs":[{"name":"success","type":"bool"}],"stateMutability":"payable","type":"function"}
+]`
+
+var gnosisSafeParsed = mustParseABI(gnosisSafeABI)
+
+var (
+	safeDomainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(uint256 chainId,address verifyingContract)"))
+	safeTxTypeHash     = crypto.Keccak256Hash([]byte("SafeTx(address to,uint256 value,bytes data,uint8 operation,uint256 safeTxGas,uint256 baseGas,uint256 gasPrice,address gasToken,address refundReceiver,uint256 nonce)"))
+)
+
+// SafeTxHash 按 Safe EIP-712 规则计算多签交易的签名摘要
+// 固定为 CALL 操作且不使用 Gas 退款，与 ExecuteMultiSigTransaction 提交的参数一致
+func SafeTxHash(chainID *big.Int, safe common.Address, tx *MultiSigTransaction) common.Hash {
+	value := tx.Value
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	zero := common.Hash{}
+	structHash := crypto.Keccak256(
+		safeTxTypeHash.Bytes(),
+		common.BytesToHash(common.HexToAddress(tx.To).Bytes()).Bytes(),
+		common.BigToHash(value).Bytes(),
+		crypto.Keccak256(tx.Data),
+		zero.Bytes(), // operation: CALL
+		zero.Bytes(), // safeTxGas
+		zero.Bytes(), // baseGas
+		zero.Bytes(), // gasPrice
+		zero.Bytes(), // gasToken
+		zero.Bytes(), // refundReceiver
+		common.BigToHash(new(big.Int).SetUint64(tx.Nonce)).Bytes(),
+	)
+	domainSeparator := crypto.Keccak256(
+		safeDomainTypeHash.Bytes(),
+		common.BigToHash(chainID).Bytes(),
+		common.BytesToHash(safe.Bytes()).Bytes(),
+	)
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domainSeparator, structHash)
+}
+
+// multiSigChainID 解析多签钱包的链ID：数字直接使用，否则按网络ID读取配置，为空时使用当前网络
+func (sm *AdvancedSecurityManager) multiSigChainID(chain string) (*big.Int, error) {
+	if id, err := strconv.ParseInt(chain, 10, 64); err == nil && id > 0 {
+		return big.NewInt(id), nil
+	}
+	networkID := chain
+	if networkID == "" {
+		if sm.multiChain == nil {
+			return nil, fmt.Errorf("多签钱包未指定链ID")
+		}
+		networkID = sm.multiChain.GetCurrentNetwork()
+	}
+	network, err := config.GetNetwork(networkID)
+	if err != nil {
+		return nil, err
+	}
+	return big.NewInt(network.ChainID), nil
+}
+
+// verifySafeOwners 校验 Safe 合约的链上阈值与 owner 列表，登记的签名者必须全部是 owner
+func (sm *AdvancedSecurityManager) verifySafeOwners(ctx context.Context, networkID string, safe common.Address, signers []MultiSigSigner, threshold int) error {
+	if sm.multiChain == nil {
+		return fmt.Errorf("未配置多链管理器，无法校验Safe合约")
+	}
+	evmAdapter, err := sm.multiSigAdapter(networkID)
+	if err != nil {
+		return err
+	}
+	out, err := evmAdapter.callView(ctx, safe, gnosisSafeParsed, "getThreshold")
+	if err != nil {
+		return fmt.Errorf("读取Safe阈值失败（地址不是已部署的Safe合约？）: %w", err)
+	}
+	onchainThreshold, ok := out[0].(*big.Int)
+	if !ok {
+		return fmt.Errorf("getThreshold 返回类型异常")
+	}
+	if !onchainThreshold.IsInt64() || onchainThreshold.Int64() != int64(threshold) {
+		return fmt.Errorf("签名阈值 %d 与Safe合约的链上阈值 %s 不一致", threshold, onchainThreshold)
+	}
+	out, err = evmAdapter.callView(ctx, safe, gnosisSafeParsed, "getOwners")
+	if err != nil {
+		return fmt.Errorf("读取Safe owner 列表失败: %w", err)
+	}
+	owners, ok := out[0].([]common.Address)
+	if !ok {
+		return fmt.Errorf("getOwners 返回类型异常")
+	}
+	isOwner := make(map[common.Address]bool, len(owners))
+	for _, owner := range owners {
+		isOwner[owner] = true
+	}
+	seen := make(map[common.Address]bool, len(signers))
+	for _, signer := range signers {
+		if !common.IsHexAddress(signer.Address) {
+			return fmt.Errorf("无效的签名者地址: %s", signer.Address)
+		}
+		addr := common.HexToAddress(signer.Address)
+		if seen[addr] {
+			return fmt.Errorf("签名者 %s 重复", addr.Hex())
+		}
+		seen[addr] = true
+		if !isOwner[addr] {
+			return fmt.Errorf("签名者 %s 不是Safe合约的owner", addr.Hex())
+		}
+	}
+	return nil
+}
+
+// safeNonce 读取 Safe 合约当前的 nonce
+func safeNonce(ctx context.Context, evmAdapter *EVMAdapter, safe common.Address) (*big.Int, error) {
+	out, err := evmAdapter.callView(ctx, safe, gnosisSafeParsed, "nonce")
+	if err != nil {
+		return nil, fmt.Errorf("读取Safe nonce失败: %w", err)
+	}
+	nonce, ok := out[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("nonce 返回类型异常")
+	}
+	return nonce, nil
+}
+
+// ProposeMultiSigTransaction 为多签钱包创建待签名交易
+// 发起人须为钱包创建者或活跃签名者；nonce 排在链上 nonce 与已有待处理交易之后，未指定时间锁与过期时间时使用钱包配置
+func (sm *AdvancedSecurityManager) ProposeMultiSigTransaction(ctx context.Context, walletID, proposer string, tx *MultiSigTransaction) (*MultiSigTransaction, error) {
+	if sm.multiChain == nil {
+		return nil, fmt.Errorf("未配置多链管理器，无法创建多签交易")
+	}
+	if !common.IsHexAddress(tx.To) {
+		return nil, fmt.Errorf("无效的接收地址: %s", tx.To)
+	}
+	if !common.IsHexAddress(proposer) {
+		return nil, fmt.Errorf("无效的发起人地址: %s", proposer)
+	}
+	proposerAddr := common.HexToAddress(proposer)
+
+	sm.mu.RLock()
+	wallet, exists := sm.multiSigWallets[walletID]
+	if !exists {
+		sm.mu.RUnlock()
+		return nil, fmt.Errorf("多签钱包不存在")
+	}
+	authorized := common.IsHexAddress(wallet.CreatedBy) && common.HexToAddress(wallet.CreatedBy) == proposerAddr
+	for _, signer := range wallet.Signers {
+		if signer.IsActive && common.IsHexAddress(signer.Address) && common.HexToAddress(signer.Address) == proposerAddr {
+			authorized = true
+		}
+	}
+	networkID := wallet.ChainID
+	safe := common.HexToAddress(wallet.ContractAddress)
+	sm.mu.RUnlock()
+	if !authorized {
+		return nil, fmt.Errorf("只有钱包创建者或签名者可以发起多签交易")
+	}
+
+	evmAdapter, err := sm.multiSigAdapter(networkID)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := safeNonce(ctx, evmAdapter, safe)
+	if err != nil {
+		return nil, err
+	}
+	if !nonce.IsUint64() {
+		return nil, fmt.Errorf("Safe nonce 超出范围: %s", nonce)
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.multiSigWallets[walletID] != wallet {
+		return nil, fmt.Errorf("多签钱包不存在")
+	}
+	// 已过期的交易不会再执行，不占用队列中的 nonce
+	now := time.Now()
+	next := nonce.Uint64()
+	for _, pending := range wallet.PendingTxs {
+		if pending.ExpiresAt != nil && now.After(*pending.ExpiresAt) {
+			continue
+		}
+		if pending.Nonce >= next {
+			next = pending.Nonce + 1
+		}
+	}
+	tx.ID = sm.generateWalletID()
+	tx.To = common.HexToAddress(tx.To).Hex()
+	if tx.Value == nil {
+		tx.Value = big.NewInt(0)
+	}
+	tx.Nonce = next
+	tx.Status = "pending"
+	tx.RequiredSigs = wallet.Threshold
+	tx.CurrentSigs = 0
+	tx.Signatures = make([]MultiSigSignature, 0)
+	tx.CreatedBy = proposerAddr.Hex()
+	tx.CreatedAt = now
+	if tx.Timelock == nil && wallet.Configuration.TimelockDuration > 0 {
+		timelock := now.Add(wallet.Configuration.TimelockDuration)
+		tx.Timelock = &timelock
+	}
+	if tx.ExpiresAt == nil && wallet.Configuration.ExpirationTime > 0 {
+		expires := now.Add(wallet.Configuration.ExpirationTime)
+		tx.ExpiresAt = &expires
+	}
+	wallet.PendingTxs = append(wallet.PendingTxs, *tx)
+	wallet.UpdatedAt = now
+
+	sm.auditLogger.LogActionCtx(ctx, "create_multisig_transaction", "multisig_transaction", tx.ID, "success", map[string]interface{}{
+		"wallet_id": walletID,
+		"to":        tx.To,
+		"value":     tx.Value.String(),
+		"nonce":     tx.Nonce,
+	})
+
+	created := *tx
+	return &created, nil
+}
+
+// MultiSigSafeTxHash 返回待处理多签交易的 safeTxHash，供签名者签名
+func (sm *AdvancedSecurityManager) MultiSigSafeTxHash(walletID, txID string) (common.Hash, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	wallet, tx, err := sm.findPendingMultiSigTx(walletID, txID)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	chainID, err := sm.multiSigChainID(wallet.ChainID)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return SafeTxHash(chainID, common.HexToAddress(wallet.ContractAddress), tx), nil
+}
+
+// safeSignature 已校验的 Safe 签名
+type safeSignature struct {
+	signer common.Address
+	data   []byte // 65 字节，V 已规范为 Safe 可识别的值
+}
+
+// ExecuteMultiSigTransaction 签名达到阈值后由 executor 调用 Safe 合约执行交易
+// 返回链上交易哈希；执行成功后交易移入 ExecutedTxs
+func (sm *AdvancedSecurityManager) ExecuteMultiSigTransaction(ctx context.Context, walletID, txID string, executor Signer) (string, error) {
+	if sm.multiChain == nil {
+		return "", fmt.Errorf("未配置多链管理器，无法执行多签交易")
+	}
+
+	sm.mu.Lock()
+	wallet, tx, err := sm.findPendingMultiSigTx(walletID, txID)
+	if err != nil {
+		sm.mu.Unlock()
+		return "", err
+	}
+	if err := checkMultiSigExecutable(wallet, tx, time.Now()); err != nil {
+		sm.mu.Unlock()
+		return "", err
+	}
+	if err := sm.multiSigRecipientBlocked(wallet, tx); err != nil {
+		sm.mu.Unlock()
+		return "", err
+	}
+
+	// 复制执行所需数据后释放锁，链上调用期间不阻塞其他操作
+	snapshot := *tx
+	snapshot.Signatures = append([]MultiSigSignature(nil), tx.Signatures...)
+	signers := make(map[common.Address]bool)
+	for _, signer := range wallet.Signers {
+		if signer.IsActive && common.IsHexAddress(signer.Address) {
+			signers[common.HexToAddress(signer.Address)] = true
+		}
+	}
+	threshold := wallet.Threshold
+	networkID := wallet.ChainID
+	safe := common.HexToAddress(wallet.ContractAddress)
+	previousStatus := tx.Status
+	tx.Status = MultiSigStatusExecuting
+	sm.mu.Unlock()
+
+	txHash, validity, execErr := sm.executeOnSafe(ctx, networkID, safe, &snapshot, signers, threshold, executor)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	var index = -1
+	for i := range wallet.PendingTxs {
+		if wallet.PendingTxs[i].ID == txID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return txHash, execErr
+	}
+	tx = &wallet.PendingTxs[index]
+	for i := range tx.Signatures {
+		if valid, checked := validity[i]; checked {
+			tx.Signatures[i].IsValid = valid
+		}
+	}
+
+	if execErr != nil {
+		tx.Status = previousStatus
+		sm.auditLogger.LogActionCtx(ctx, "execute_multisig_transaction", "multisig_transaction", txID, "failed", map[string]interface{}{
+			"wallet_id": walletID,
+			"error":     execErr.Error(),
+		})
+		return "", execErr
+	}
+
+	now := time.Now()
+	tx.Status = MultiSigStatusExecuted
+	tx.TxHash = txHash
+	tx.ExecutedAt = &now
+	wallet.ExecutedTxs = append(wallet.ExecutedTxs, *tx)
+	wallet.PendingTxs = append(wallet.PendingTxs[:index], wallet.PendingTxs[index+1:]...)
+	wallet.UpdatedAt = now
+
+	sm.auditLogger.LogActionCtx(ctx, "execute_multisig_transaction", "multisig_transaction", txID, "success", map[string]interface{}{
+		"wallet_id": walletID,
+		"tx_hash":   txHash,
+		"executor":  executor.Address().Hex(),
+	})
+
+	return txHash, nil
+}
+
+// findPendingMultiSigTx 查找多签钱包中的待处理交易（调用方需持有锁）
+func (sm *AdvancedSecurityManager) findPendingMultiSigTx(walletID, txID string) (*MultiSigWallet, *MultiSigTransaction, error) {
+	wallet, exists := sm.multiSigWallets[walletID]
+	if !exists {
+		return nil, nil, fmt.Errorf("多签钱包不存在")
+	}
+	for i := range wallet.PendingTxs {
+		if wallet.PendingTxs[i].ID == txID {
+			return wallet, &wallet.PendingTxs[i], nil
+		}
+	}
+	return nil, nil, fmt.Errorf("交易不存在")
+}
+
+// MultiSigPendingTransfer 待执行多签交易的所在网络与资金流向，供执行前的风控、黑名单与支出限额校验
+// 发送方记为执行者 executor（由其支出限额承担）
+func (sm *AdvancedSecurityManager) MultiSigPendingTransfer(walletID, txID, executor string) (*PendingTransfer, error) {
+	if sm.multiChain == nil {
+		return nil, fmt.Errorf("未配置多链管理器，无法执行多签交易")
+	}
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	wallet, tx, err := sm.findPendingMultiSigTx(walletID, txID)
+	if err != nil {
+		return nil, err
+	}
+	if !common.IsHexAddress(tx.To) {
+		return nil, fmt.Errorf("无效的接收地址: %s", tx.To)
+	}
+	return newPendingTransfer(sm.multiSigNetworkID(wallet.ChainID), executor, common.HexToAddress(tx.To), tx.Value, tx.Data), nil
+}
+
+// MultiSigApprovalState 多签交易的签名进度
+type MultiSigApprovalState struct {
+	WalletID       string   `json:"wallet_id"`
+	WalletName     string   `json:"wallet_name"`
+	TransactionID  string   `json:"transaction_id"`
+	Title          string   `json:"title"`
+	Status         string   `json:"status"`
+	CurrentSigs    int      `json:"current_sigs"`
+	Threshold      int      `json:"threshold"`
+	CreatedBy      string   `json:"created_by"`
+	Signers        []string `json:"signers"`         // 全部活跃签名者
+	PendingSigners []string `json:"pending_signers"` // 尚未签名的活跃签名者
+}
+
+// MultiSigApprovalState 查询待处理多签交易的签名进度
+func (sm *AdvancedSecurityManager) MultiSigApprovalState(walletID, txID string) (*MultiSigApprovalState, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	wallet, tx, err := sm.findPendingMultiSigTx(walletID, txID)
+	if err != nil {
+		return nil, err
+	}
+	signed := make(map[common.Address]bool, len(tx.Signatures))
+	for _, sig := range tx.Signatures {
+		if common.IsHexAddress(sig.Signer) {
+			signed[common.HexToAddress(sig.Signer)] = true
+		}
+	}
+	state := &MultiSigApprovalState{
+		WalletID:       wallet.ID,
+		WalletName:     wallet.Name,
+		TransactionID:  tx.ID,
+		Title:          tx.Title,
+		Status:         tx.Status,
+		CurrentSigs:    tx.CurrentSigs,
+		Threshold:      wallet.Threshold,
+		CreatedBy:      tx.CreatedBy,
+		Signers:        []string{},
+		PendingSigners: []string{},
+	}
+	for _, signer := range wallet.Signers {
+		if !signer.IsActive || !common.IsHexAddress(signer.Address) {
+			continue
+		}
+		addr := common.HexToAddress(signer.Address)
+		state.Signers = append(state.Signers, addr.Hex())
+		if !signed[addr] {
+			state.PendingSigners = append(state.PendingSigners, addr.Hex())
+		}
+	}
+	return state, nil
+}
+
+// checkMultiSigExecutable 检查交易状态、时间锁、过期时间与签名数量
+func checkMultiSigExecutable(wallet *MultiSigWallet, tx *MultiSigTransaction, now time.Time) error {
+	if tx.Status == MultiSigStatusExecuting {
+		return fmt.Errorf("交易正在执行中")
+	}
+	if tx.Timelock != nil && now.Before(*tx.Timelock) {
+		return fmt.Errorf("交易处于时间锁中，最早执行时间: %s", tx.Timelock.Format(time.RFC3339))
+	}
+	if tx.ExpiresAt != nil && now.After(*tx.ExpiresAt) {
+		return fmt.Errorf("交易已过期")
+	}
+	if tx.CurrentSigs < wallet.Threshold {
+		return fmt.Errorf("签名数量不足: %d/%d", tx.CurrentSigs, wallet.Threshold)
+	}
+	if !common.IsHexAddress(wallet.ContractAddress) {
+		return fmt.Errorf("多签钱包合约地址无效: %s", wallet.ContractAddress)
+	}
+	if !common.IsHexAddress(tx.To) {
+		return fmt.Errorf("无效的接收地址: %s", tx.To)
+	}
+	return checkMultiSigSpendingLimit(wallet, tx, now)
+}
+
+// executeOnSafe 校验签名并调用 Safe.execTransaction
+// 返回链上交易哈希与每个签名（按 Signatures 下标）的校验结果
+func (sm *AdvancedSecurityManager) executeOnSafe(ctx context.Context, networkID string, safe common.Address, tx *MultiSigTransaction, signers map[common.Address]bool, threshold int, executor Signer) (string, map[int]bool, error) {
+	evmAdapter, err := sm.multiSigAdapter(networkID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	to := common.HexToAddress(tx.To)
+	value := tx.Value
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	zero := big.NewInt(0)
+
+	// 签名基于发起时保存的 nonce，链上 nonce 不一致时签名对应的交易无法执行
+	onchainNonce, err := safeNonce(ctx, evmAdapter, safe)
+	if err != nil {
+		return "", nil, err
+	}
+	nonce := new(big.Int).SetUint64(tx.Nonce)
+	switch onchainNonce.Cmp(nonce) {
+	case 1:
+		return "", nil, fmt.Errorf("Safe nonce %d 已被其他交易使用（当前链上 nonce 为 %s），该交易已失效", tx.Nonce, onchainNonce)
+	case -1:
+		return "", nil, fmt.Errorf("需先执行 nonce 为 %s 的交易（该交易 nonce 为 %d）", onchainNonce, tx.Nonce)
+	}
+	out, err := evmAdapter.callView(ctx, safe, gnosisSafeParsed, "getTransactionHash",
+		to, value, tx.Data, uint8(0), zero, zero, zero, common.Address{}, common.Address{}, nonce)
+	if err != nil {
+		return "", nil, fmt.Errorf("计算Safe交易哈希失败: %w", err)
+	}
+	safeTxHash, ok := out[0].([32]byte)
+	if !ok {
+		return "", nil, fmt.Errorf("getTransactionHash 返回类型异常")
+	}
+
+	// 校验签名：必须来自活跃签名者、能恢复出声明地址，同一签名者只计一次
+	validity := make(map[int]bool)
+	var valid []safeSignature
+	seen := make(map[common.Address]bool)
+	for i, sig := range tx.Signatures {
+		validity[i] = false
+		if !common.IsHexAddress(sig.Signer) {
+			continue
+		}
+		claimed := common.HexToAddress(sig.Signer)
+		if !signers[claimed] || seen[claimed] {
+			continue
+		}
+		recovered, data, err := recoverSafeSigner(common.Hash(safeTxHash), sig.Signature)
+		if err != nil || recovered != claimed {
+			continue
+		}
+		validity[i] = true
+		seen[claimed] = true
+		valid = append(valid, safeSignature{signer: claimed, data: data})
+	}
+	if len(valid) < threshold {
+		return "", validity, fmt.Errorf("有效签名数量不足: %d/%d", len(valid), threshold)
+	}
+
+	// Safe 合约要求签名按签名者地址严格升序排列
+	sort.Slice(valid, func(i, j int) bool {
+		return bytes.Compare(valid[i].signer.Bytes(), valid[j].signer.Bytes()) < 0
+	})
+	packed := make([]byte, 0, len(valid)*crypto.SignatureLength)
+	for _, sig := range valid {
+		packed = append(packed, sig.data...)
+	}
+
+	data, err := gnosisSafeParsed.Pack("execTransaction",
+		to, value, tx.Data, uint8(0), zero, zero, zero, common.Address{}, common.Address{}, packed)
+	if err != nil {
+		return "", validity, fmt.Errorf("打包execTransaction数据失败: %w", err)
+	}
+	txHash, err := evmAdapter.SendContractCallWithSigner(ctx, executor, safe, data, nil, nil)
+	if err != nil {
+		return "", validity, fmt.Errorf("提交execTransaction失败: %w", err)
+	}
+	return txHash, validity, nil
+}
+
+// multiSigAdapter 获取多签钱包所在网络的EVM适配器（支持网络ID或数字链ID），未指定时使用当前网络
+func (sm *AdvancedSecurityManager) multiSigAdapter(networkID string) (*EVMAdapter, error) {
+	adapter, err := sm.multiChain.GetAdapter(sm.multiSigNetworkID(networkID))
+	if err != nil {
+		return nil, err
+	}
+	evmAdapter, ok := adapter.(*EVMAdapter)
+	if !ok {
+		return nil, fmt.Errorf("多签执行仅支持EVM网络")
+	}
+	return evmAdapter, nil
+}
+
+// multiSigNetworkID 多签钱包所在网络ID：数字链ID按配置映射到网络ID，未指定时为当前网络
+func (sm *AdvancedSecurityManager) multiSigNetworkID(chain string) string {
+	if id, err := strconv.ParseInt(chain, 10, 64); err == nil {
+		for candidate, network := range config.AppConfig.Networks {
+			if network.Enabled && network.ChainID == id {
+				return candidate
+			}
+		}
+	}
+	if chain == "" {
+		return sm.multiChain.GetCurrentNetwork()
+	}
+	return chain
+}
+
+// recoverSafeSigner 按 Safe 签名规则恢复签名者，并返回 V 规范化后的签名
+func recoverSafeSigner(safeTxHash common.Hash, signature string) (common.Address, []byte, error) {
+	sig, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(signature), "0x"))
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("签名不是有效的十六进制: %w", err)
+	}
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, nil, fmt.Errorf("签名长度应为 %d 字节，实际 %d 字节", crypto.SignatureLength, len(sig))
+	}
+
+	digest := safeTxHash
+	switch sig[64] {
+	case 0, 1:
+		sig[64] += 27
+	case 27, 28:
+	case 31, 32:
+		// eth_sign：签名对象为带前缀的 safeTxHash
+		digest = personalSignHash(string(safeTxHash.Bytes()))
+	default:
+		return common.Address{}, nil, fmt.Errorf("不支持的签名 v 值: %d", sig[64])
+	}
+
+	recoverable := append([]byte(nil), sig...)
+	if recoverable[64] >= 31 {
+		recoverable[64] -= 31
+	} else {
+		recoverable[64] -= 27
+	}
+	pub, err := crypto.SigToPub(digest.Bytes(), recoverable)
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("恢复签名者失败: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pub), sig, nil
+}
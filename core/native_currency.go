@@ -0,0 +1,134 @@
+/*
+原生代币信息与金额格式化
+
+不同网络的原生代币符号与小数位不同（如 Polygon 为 MATIC/18、Solana 为 SOL/9、Bitcoin 为 BTC/8），
+余额、手续费等展示统一按所在网络的配置格式化，避免默认按 ETH/18 处理。
+代币金额在可读单位（如 "1.5" USDC）与最小单位之间转换使用 ParseTokenAmount/FormatTokenAmount。
+*/
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"wallet/config"
+)
+
+// 配置缺失时的默认原生代币
+const (
+	defaultNativeSymbol   = "ETH"
+	defaultNativeDecimals = 18
+)
+
+// NativeCurrency 网络原生代币
+type NativeCurrency struct {
+	Symbol   string `json:"symbol"`   // 代币符号
+	Decimals int    `json:"decimals"` // 小数位数
+}
+
+// NativeCurrencyFor 获取指定网络的原生代币信息（配置缺失时回退到 ETH/18）
+func NativeCurrencyFor(networkID string) NativeCurrency {
+	currency := NativeCurrency{Symbol: defaultNativeSymbol, Decimals: defaultNativeDecimals}
+	networkConfig, err := config.GetNetwork(networkID)
+	if err != nil {
+		return currency
+	}
+	if networkConfig.Symbol != "" {
+		currency.Symbol = networkConfig.Symbol
+	}
+	if networkConfig.Decimals > 0 {
+		currency.Decimals = networkConfig.Decimals
+	}
+	return currency
+}
+
+// GetCurrentNativeCurrency 获取当前网络的原生代币信息
+func (mcm *MultiChainManager) GetCurrentNativeCurrency() NativeCurrency {
+	return NativeCurrencyFor(mcm.GetCurrentNetwork())
+}
+
+// Format 将最小单位金额格式化为带符号的可读字符串，如 "1.5 MATIC"
+func (n NativeCurrency) Format(amount *big.Int) string {
+	return FormatUnits(amount, n.Decimals) + " " + n.Symbol
+}
+
+// Fee 计算手续费（gasUsed × 每单位Gas价格），返回最小单位金额与按小数位格式化的可读金额（不含符号）
+func (n NativeCurrency) Fee(gasUsed uint64, feePerGas *big.Int) (*big.Int, string) {
+	if feePerGas == nil {
+		feePerGas = new(big.Int)
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), feePerGas)
+	return fee, FormatUnits(fee, n.Decimals)
+}
+
+// FormatUnits 将最小单位金额按小数位转换为十进制字符串（精确，不经浮点），去除末尾多余的0
+func FormatUnits(amount *big.Int, decimals int) string {
+	if amount == nil {
+		return "0"
+	}
+	if decimals <= 0 {
+		return amount.String()
+	}
+	negative := amount.Sign() < 0
+	digits := new(big.Int).Abs(amount).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	intPart := digits[:len(digits)-decimals]
+	fracPart := strings.TrimRight(digits[len(digits)-decimals:], "0")
+
+	result := intPart
+	if fracPart != "" {
+		result += "." + fracPart
+	}
+	if negative {
+		result = "-" + result
+	}
+	return result
+}
+
+// FormatTokenAmount 将代币最小单位金额按代币小数位格式化为可读字符串
+func FormatTokenAmount(raw *big.Int, decimals uint8) string {
+	return FormatUnits(raw, int(decimals))
+}
+
+// ParseTokenAmount 将可读单位的十进制金额（如 "1.5"）按代币小数位转换为最小单位
+// 仅接受非负的普通十进制写法；小数位数超过代币精度时返回错误，不做截断
+func ParseTokenAmount(amountHuman string, decimals uint8) (*big.Int, error) {
+	amount := strings.TrimSpace(amountHuman)
+	if amount == "" {
+		return nil, fmt.Errorf("金额不能为空")
+	}
+	intPart, fracPart, hasDot := strings.Cut(amount, ".")
+	if intPart == "" && fracPart == "" {
+		return nil, fmt.Errorf("金额格式不正确: %s", amountHuman)
+	}
+	if hasDot && fracPart == "" {
+		return nil, fmt.Errorf("金额格式不正确: %s", amountHuman)
+	}
+	if !isDecimalDigits(intPart) || !isDecimalDigits(fracPart) {
+		return nil, fmt.Errorf("金额格式不正确: %s（仅支持非负十进制数字）", amountHuman)
+	}
+	// 末尾的0不影响精度，去掉后再与代币小数位比较
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > int(decimals) {
+		return nil, fmt.Errorf("金额 %s 的小数位数超过代币精度（最多 %d 位）", amountHuman, decimals)
+	}
+
+	digits := intPart + fracPart + strings.Repeat("0", int(decimals)-len(fracPart))
+	raw, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("金额格式不正确: %s", amountHuman)
+	}
+	return raw, nil
+}
+
+// isDecimalDigits 字符串是否仅由0-9组成（空串视为合法）
+func isDecimalDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
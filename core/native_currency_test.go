@@ -0,0 +1,81 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+	"wallet/config"
+)
+
+// withTestNetworks 临时替换网络配置，测试结束后恢复
+func withTestNetworks(t *testing.T, networks map[string]config.NetworkConfig) {
+	t.Helper()
+	previous := config.AppConfig.Networks
+	config.AppConfig.Networks = networks
+	t.Cleanup(func() { config.AppConfig.Networks = previous })
+}
+
+func TestNativeCurrencyFormatting(t *testing.T) {
+	withTestNetworks(t, map[string]config.NetworkConfig{
+		"testbtc":   {Symbol: "BTC", Decimals: 8, Enabled: true},
+		"testusd":   {Symbol: "USDX", Decimals: 6, Enabled: true},
+		"testsol":   {Symbol: "SOL", Decimals: 9, Enabled: true},
+		"testmatic": {Symbol: "MATIC", Decimals: 18, Enabled: true},
+		"disabled":  {Symbol: "OFF", Decimals: 8, Enabled: false},
+	})
+
+	gwei := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1_000_000_000)) }
+	cases := []struct {
+		network      string
+		wantSymbol   string
+		wantDecimals int
+		balance      *big.Int
+		wantBalance  string
+		gasUsed      uint64
+		feePerGas    *big.Int
+		wantFeeRaw   string
+		wantFee      string
+	}{
+		{"testbtc", "BTC", 8, big.NewInt(150_000_000), "1.5 BTC", 21000, big.NewInt(10), "210000", "0.0021"},
+		{"testusd", "USDX", 6, big.NewInt(1_234_567), "1.234567 USDX", 21000, big.NewInt(1000), "21000000", "21"},
+		{"testsol", "SOL", 9, big.NewInt(1), "0.000000001 SOL", 5000, big.NewInt(1), "5000", "0.000005"},
+		{"testmatic", "MATIC", 18, big.NewInt(2_500_000_000_000_000_000), "2.5 MATIC", 21000, gwei(30), "630000000000000", "0.00063"},
+		// 未配置或未启用的网络回退到 ETH/18
+		{"missing", "ETH", 18, big.NewInt(1_000_000_000_000_000_000), "1 ETH", 21000, gwei(1), "21000000000000", "0.000021"},
+		{"disabled", "ETH", 18, big.NewInt(0), "0 ETH", 0, gwei(1), "0", "0"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.network, func(t *testing.T) {
+			native := NativeCurrencyFor(tc.network)
+			if native.Symbol != tc.wantSymbol || native.Decimals != tc.wantDecimals {
+				t.Fatalf("NativeCurrencyFor = %s/%d，期望 %s/%d", native.Symbol, native.Decimals, tc.wantSymbol, tc.wantDecimals)
+			}
+			if got := native.Format(tc.balance); got != tc.wantBalance {
+				t.Errorf("余额 = %q，期望 %q", got, tc.wantBalance)
+			}
+			raw, fee := native.Fee(tc.gasUsed, tc.feePerGas)
+			if raw.String() != tc.wantFeeRaw || fee != tc.wantFee {
+				t.Errorf("手续费 = %s (%s)，期望 %s (%s)", raw, fee, tc.wantFeeRaw, tc.wantFee)
+			}
+		})
+	}
+}
+
+func TestFormatUnits(t *testing.T) {
+	cases := []struct {
+		amount   *big.Int
+		decimals int
+		want     string
+	}{
+		{nil, 8, "0"},
+		{big.NewInt(0), 6, "0"},
+		{big.NewInt(100_000_000), 8, "1"},
+		{big.NewInt(1), 8, "0.00000001"},
+		{big.NewInt(-150), 2, "-1.5"},
+		{big.NewInt(42), 0, "42"},
+	}
+	for _, tc := range cases {
+		if got := FormatUnits(tc.amount, tc.decimals); got != tc.want {
+			t.Errorf("FormatUnits(%v, %d) = %q，期望 %q", tc.amount, tc.decimals, got, tc.want)
+		}
+	}
+}
@@ -53,7 +53,9 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
+	"wallet/config"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -64,26 +66,31 @@ import (
 // 统一管理NFT相关操作，包括元数据获取、所有权验证、转账等
 type NFTManager struct {
 	evmAdapter      *EVMAdapter            // EVM适配器，用于与区块链交互
-	metadataCache   map[string]*NFTCache   // 元数据缓存
+	metadataCache   map[string]*NFTCache   // 元数据缓存（键为 小写合约地址:tokenId）
 	collectionCache map[string]*Collection // 集合信息缓存
 	abi721          abi.ABI                // ERC-721 ABI
 	abi1155         abi.ABI                // ERC-1155 ABI
+	uriResolver     *URIResolver           // 元数据地址解析（ipfs:// 网关、data URI）
+	metadataTTL     time.Duration          // 元数据缓存时长
+	mu              sync.RWMutex           // 保护元数据缓存
 }
 
 // NFT NFT基础信息结构
 type NFT struct {
-	TokenID      string       `json:"token_id"`      // 代币ID
-	ContractAddr string       `json:"contract_addr"` // 合约地址
-	Standard     string       `json:"standard"`      // 标准(ERC-721/ERC-1155)
-	Owner        string       `json:"owner"`         // 当前所有者
-	Metadata     *NFTMetadata `json:"metadata"`      // 元数据信息
-	Collection   *Collection  `json:"collection"`    // 所属集合
-	Attributes   []*Attribute `json:"attributes"`    // 属性列表
-	RarityRank   int          `json:"rarity_rank"`   // 稀有度排名
-	LastSale     *SaleInfo    `json:"last_sale"`     // 最近成交信息
-	MarketData   *MarketData  `json:"market_data"`   // 市场数据
-	CreatedAt    time.Time    `json:"created_at"`    // 铸造时间
-	UpdatedAt    time.Time    `json:"updated_at"`    // 最后更新时间
+	TokenID      string       `json:"token_id"`            // 代币ID
+	ContractAddr string       `json:"contract_addr"`       // 合约地址
+	Standard     string       `json:"standard"`            // 标准(ERC-721/ERC-1155)
+	Owner        string       `json:"owner"`               // 当前所有者
+	Balance      *big.Int     `json:"balance,omitempty"`   // 持有数量（ERC-1155）
+	TokenURI     string       `json:"token_uri,omitempty"` // 元数据URI（tokenURI / uri）
+	Metadata     *NFTMetadata `json:"metadata"`            // 元数据信息
+	Collection   *Collection  `json:"collection"`          // 所属集合
+	Attributes   []*Attribute `json:"attributes"`          // 属性列表
+	RarityRank   int          `json:"rarity_rank"`         // 稀有度排名
+	LastSale     *SaleInfo    `json:"last_sale"`           // 最近成交信息
+	MarketData   *MarketData  `json:"market_data"`         // 市场数据
+	CreatedAt    time.Time    `json:"created_at"`          // 铸造时间
+	UpdatedAt    time.Time    `json:"updated_at"`          // 最后更新时间
 }
 
 // NFTMetadata NFT元数据结构
@@ -213,10 +220,11 @@ type NFTApprovalParams struct {
 
 // NFTCache NFT缓存信息
 type NFTCache struct {
-	Metadata  *NFTMetadata `json:"metadata"`   // 元数据
-	CachedAt  time.Time    `json:"cached_at"`  // 缓存时间
-	ExpiresAt time.Time    `json:"expires_at"` // 过期时间
-	Version   string       `json:"version"`    // 版本号
+	Metadata   *NFTMetadata `json:"metadata"`   // 元数据
+	Attributes []*Attribute `json:"attributes"` // 属性列表
+	CachedAt   time.Time    `json:"cached_at"`  // 缓存时间
+	ExpiresAt  time.Time    `json:"expires_at"` // 过期时间
+	Version    string       `json:"version"`    // 版本号
 }
 
 // NewNFTManager 创建NFT管理器实例
@@ -235,12 +243,15 @@ func NewNFTManager(evmAdapter *EVMAdapter) (*NFTManager, error) {
 		return nil, fmt.Errorf("加载ERC-1155 ABI失败: %w", err)
 	}
 
+	nftConfig := config.AppConfig.NFT.WithDefaults()
 	return &NFTManager{
 		evmAdapter:      evmAdapter,
 		metadataCache:   make(map[string]*NFTCache),
 		collectionCache: make(map[string]*Collection),
 		abi721:          abi721,
 		abi1155:         abi1155,
+		uriResolver:     DefaultURIResolver(),
+		metadataTTL:     time.Duration(nftConfig.MetadataCacheMinutes) * time.Minute,
 	}, nil
 }
 
@@ -275,7 +286,7 @@ func (n *NFTManager) GetNFT(ctx context.Context, contractAddr, tokenID string) (
 	nft.Owner = owner
 
 	// 获取元数据
-	metadata, err := n.getMetadata(ctx, contractAddr, tokenID)
+	metadata, attributes, err := n.getMetadata(ctx, contractAddr, tokenID, standard)
 	if err != nil {
 		// 元数据获取失败不应该导致整个请求失败
 		metadata = &NFTMetadata{
@@ -284,6 +295,7 @@ func (n *NFTManager) GetNFT(ctx context.Context, contractAddr, tokenID string) (
 		}
 	}
 	nft.Metadata = metadata
+	nft.Attributes = attributes
 
 	// 获取集合信息
 	collection, err := n.getCollection(ctx, contractAddr)
@@ -343,13 +355,15 @@ func (n *NFTManager) TransferNFT(ctx context.Context, params *NFTTransferParams,
 		return "", fmt.Errorf("检测NFT标准失败: %w", err)
 	}
 
-	// 验证所有权
-	owner, err := n.getOwner(ctx, params.ContractAddr, params.TokenID, standard)
-	if err != nil {
-		return "", fmt.Errorf("验证所有权失败: %w", err)
-	}
-	if !strings.EqualFold(owner, params.From) {
-		return "", fmt.Errorf("用户不是该NFT的所有者")
+	// 验证所有权（ERC-1155 无单一持有者，转账时按余额校验）
+	if standard == "ERC-721" {
+		owner, err := n.getOwner(ctx, params.ContractAddr, params.TokenID, standard)
+		if err != nil {
+			return "", fmt.Errorf("验证所有权失败: %w", err)
+		}
+		if !strings.EqualFold(owner, params.From) {
+			return "", fmt.Errorf("用户不是该NFT的所有者")
+		}
 	}
 
 	// 根据标准执行转账
@@ -487,14 +501,14 @@ func (n *NFTManager) getOwner(ctx context.Context, contractAddr, tokenID, standa
 	return owner.Hex(), nil
 }
 
-// getMetadata 获取NFT元数据
-func (n *NFTManager) getMetadata(ctx context.Context, contractAddr, tokenID string) (*NFTMetadata, error) {
-	// 简化实现：返回示例元数据
-	return &NFTMetadata{
-		Name:        fmt.Sprintf("NFT #%s", tokenID),
-		Description: "这是一个示例NFT",
-		Image:       "https://example.com/nft.png",
-	}, nil
+// getMetadata 获取NFT元数据（tokenURI / uri），见 FetchTokenMetadata
+func (n *NFTManager) getMetadata(ctx context.Context, contractAddr, tokenID, standard string) (*NFTMetadata, []*Attribute, error) {
+	tokenIDInt, ok := new(big.Int).SetString(tokenID, 10)
+	if !ok {
+		return nil, nil, fmt.Errorf("无效的tokenID: %s", tokenID)
+	}
+	metadata, attributes, _, err := n.FetchTokenMetadata(ctx, contractAddr, standard, tokenIDInt)
+	return metadata, attributes, err
 }
 
 // getCollection 获取集合信息
@@ -517,81 +531,35 @@ func (n *NFTManager) getUserNFTsFromContract(ctx context.Context, userAddr, cont
 	return []*NFT{}, nil
 }
 
-// transferERC721 转账ERC-721 NFT
+// transferERC721 转账ERC-721 NFT（safeTransferFrom）
 func (n *NFTManager) transferERC721(ctx context.Context, params *NFTTransferParams, mnemonic, derivationPath string) (string, error) {
-	// 构建transferFrom ABI
-	abiJSON := `[{
-		"inputs": [
-			{"name": "from", "type": "address"},
-			{"name": "to", "type": "address"},
-			{"name": "tokenId", "type": "uint256"}
-		],
-		"name": "transferFrom",
-		"outputs": [],
-		"type": "function"
-	}]`
-
-	parsedABI, err := abi.JSON(strings.NewReader(abiJSON))
-	if err != nil {
-		return "", fmt.Errorf("解析ABI失败: %w", err)
-	}
-
-	fromAddr := common.HexToAddress(params.From)
-	toAddr := common.HexToAddress(params.To)
 	tokenIDInt, ok := new(big.Int).SetString(params.TokenID, 10)
 	if !ok {
 		return "", fmt.Errorf("无效的tokenID: %s", params.TokenID)
 	}
+	return n.evmAdapter.TransferERC721(ctx, mnemonic, derivationPath, params.ContractAddr, params.To, tokenIDInt, nftTransferOptions(params))
+}
 
-	data, err := parsedABI.Pack("transferFrom", fromAddr, toAddr, tokenIDInt)
-	if err != nil {
-		return "", fmt.Errorf("打包数据失败: %w", err)
-	}
-
-	// 使用EVM适配器发送交易
-	contractAddr := common.HexToAddress(params.ContractAddr)
-
-	// 如果未指定GasPrice，使用默认值
-	gasPrice := params.GasPrice
-	if gasPrice == nil || gasPrice.Cmp(big.NewInt(0)) == 0 {
-		// 获取当前网络建议的Gas价格
-		gasSuggestion, err := n.evmAdapter.GetGasSuggestion(ctx)
-		if err != nil {
-			return "", fmt.Errorf("获取Gas建议失败: %w", err)
-		}
-		gasPrice = gasSuggestion.GasPrice
-	}
-
-	// 如果未指定GasLimit，使用默认值
-	gasLimit := params.GasLimit
-	if gasLimit == 0 {
-		// 估算Gas
-		msg := ethereum.CallMsg{
-			From:  fromAddr,
-			To:    &contractAddr,
-			Data:  data,
-			Value: big.NewInt(0),
-		}
-		estimatedGas, err := n.evmAdapter.client.EstimateGas(ctx, msg)
-		if err != nil {
-			return "", fmt.Errorf("估算Gas失败: %w", err)
-		}
-		// 增加20%的安全边际
-		gasLimit = uint64(float64(estimatedGas) * 1.2)
+// transferERC1155 转账ERC-1155 NFT（safeTransferFrom，数量默认为1）
+func (n *NFTManager) transferERC1155(ctx context.Context, params *NFTTransferParams, mnemonic, derivationPath string) (string, error) {
+	tokenIDInt, ok := new(big.Int).SetString(params.TokenID, 10)
+	if !ok {
+		return "", fmt.Errorf("无效的tokenID: %s", params.TokenID)
 	}
-
-	txHash, err := n.evmAdapter.SendContractTransaction(ctx, mnemonic, derivationPath, contractAddr, data, big.NewInt(0), big.NewInt(int64(gasLimit)), gasPrice)
-	if err != nil {
-		return "", fmt.Errorf("发送交易失败: %w", err)
+	amount := params.Amount
+	if amount == nil || amount.Sign() <= 0 {
+		amount = big.NewInt(1)
 	}
-
-	return txHash, nil
+	return n.evmAdapter.TransferERC1155(ctx, mnemonic, derivationPath, params.ContractAddr, params.To, tokenIDInt, amount, nftTransferOptions(params))
 }
 
-// transferERC1155 转账ERC-1155 NFT
-func (n *NFTManager) transferERC1155(ctx context.Context, params *NFTTransferParams, mnemonic, derivationPath string) (string, error) {
-	// 简化实现：返回示例交易哈希
-	return "0x" + fmt.Sprintf("%x", time.Now().UnixNano()), nil
+// nftTransferOptions 将转账参数中的 gas 设置转换为交易选项
+func nftTransferOptions(params *NFTTransferParams) *TxOptions {
+	opts := &TxOptions{GasLimit: params.GasLimit}
+	if params.GasPrice != nil && params.GasPrice.Sign() > 0 {
+		opts.GasPrice = params.GasPrice
+	}
+	return opts
 }
 
 // 辅助方法
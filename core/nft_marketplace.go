@@ -25,14 +25,33 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
+	"math/rand"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 )
 
+// 市场API请求重试参数
+const (
+	marketAPIMaxAttempts    = 3                      // 最多尝试次数（含首次）
+	marketAPIBaseBackoff    = 500 * time.Millisecond // 指数退避基准时长
+	marketAPIMaxBackoff     = 30 * time.Second       // 单次等待上限（含 Retry-After）
+	rateLimitShrinkAfter    = 2                      // 连续 429 次数达到后降低请求速率
+	rateLimitCooldownPeriod = time.Minute            // 降速持续时长
+)
+
+var (
+	// ErrRateLimited 市场API持续限流（HTTP 429），重试后仍失败
+	ErrRateLimited = errors.New("市场API请求被限流")
+	// ErrMarketNotFound 市场API返回 404（集合或NFT不存在）
+	ErrMarketNotFound = errors.New("市场数据不存在")
+)
+
 // NFTMarketplace NFT市场管理器
 // 负责与各个NFT市场API交互，提供统一的市场数据接口
 type NFTMarketplace struct {
@@ -45,10 +64,16 @@ type NFTMarketplace struct {
 }
 
 // RateLimit 速率限制器
+// 连续收到 429 时 RequestsPerSecond 临时减半，冷却期结束后恢复为 baseRPS
 type RateLimit struct {
-	RequestsPerSecond int           // 每秒请求数限制
+	RequestsPerSecond int           // 每秒请求数限制（当前生效值）
 	LastRequest       time.Time     // 最后请求时间
 	TokenBucket       chan struct{} // 令牌桶
+
+	baseRPS       int        // 配置的请求速率
+	throttled     int        // 连续 429 次数
+	cooldownUntil time.Time  // 降速截止时间
+	mu            sync.Mutex // 保护速率调整字段
 }
 
 // CacheEntry 缓存条目
@@ -193,7 +218,7 @@ func NewNFTMarketplace() *NFTMarketplace {
 			Timeout: 30 * time.Second,
 		},
 		apiEndpoints: map[string]string{
-			"opensea":    "https://api.opensea.io/api/v1",
+			"opensea":    "https://api.opensea.io/api/v2",
 			"rarible":    "https://api.rarible.org/v0.1",
 			"foundation": "https://api.foundation.app/v1",
 			"superrare":  "https://api.superrare.com/v1",
@@ -238,6 +263,7 @@ func (nm *NFTMarketplace) GetMarketListings(ctx context.Context, request *Market
 	// 并发查询各平台
 	var wg sync.WaitGroup
 	var mu sync.Mutex
+	var errs []error
 
 	for _, platform := range platforms {
 		wg.Add(1)
@@ -247,6 +273,9 @@ func (nm *NFTMarketplace) GetMarketListings(ctx context.Context, request *Market
 			listings, err := nm.getListingsFromPlatform(ctx, p, request)
 			if err != nil {
 				// 记录错误但继续处理其他平台
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
 				return
 			}
 
@@ -258,6 +287,11 @@ func (nm *NFTMarketplace) GetMarketListings(ctx context.Context, request *Market
 
 	wg.Wait()
 
+	// 所有平台都失败时返回错误，限流错误优先，便于调用方区分限流与无数据
+	if len(allListings) == 0 && len(errs) == len(platforms) {
+		return nil, pickMarketError(errs)
+	}
+
 	// 排序和分页
 	nm.sortListings(allListings, request.SortBy, request.SortOrder)
 
@@ -286,6 +320,7 @@ func (nm *NFTMarketplace) GetMarketTransactions(ctx context.Context, request *Ma
 	// 并发查询各平台
 	var wg sync.WaitGroup
 	var mu sync.Mutex
+	var errs []error
 
 	for _, platform := range platforms {
 		wg.Add(1)
@@ -295,6 +330,9 @@ func (nm *NFTMarketplace) GetMarketTransactions(ctx context.Context, request *Ma
 			transactions, err := nm.getTransactionsFromPlatform(ctx, p, request)
 			if err != nil {
 				// 记录错误但继续处理其他平台
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
 				return
 			}
 
@@ -306,6 +344,11 @@ func (nm *NFTMarketplace) GetMarketTransactions(ctx context.Context, request *Ma
 
 	wg.Wait()
 
+	// 所有平台都失败时返回错误，限流错误优先，便于调用方区分限流与无数据
+	if len(allTransactions) == 0 && len(errs) == len(platforms) {
+		return nil, pickMarketError(errs)
+	}
+
 	// 排序和分页
 	nm.sortTransactions(allTransactions, request.SortBy, request.SortOrder)
 
@@ -406,6 +449,8 @@ func (nm *NFTMarketplace) GetPriceHistory(ctx context.Context, contract, tokenID
 // initRateLimiters 初始化速率限制器
 func (nm *NFTMarketplace) initRateLimiters() {
 	for platform, rateLimit := range nm.rateLimit {
+		rateLimit.baseRPS = rateLimit.RequestsPerSecond
+
 		// 填充令牌桶
 		for i := 0; i < rateLimit.RequestsPerSecond; i++ {
 			select {
@@ -419,13 +464,11 @@ func (nm *NFTMarketplace) initRateLimiters() {
 	}
 }
 
-// refillTokenBucket 补充令牌桶
+// refillTokenBucket 补充令牌桶，补充间隔随当前生效速率调整
 func (nm *NFTMarketplace) refillTokenBucket(platform string) {
 	rateLimit := nm.rateLimit[platform]
-	ticker := time.NewTicker(time.Second / time.Duration(rateLimit.RequestsPerSecond))
-	defer ticker.Stop()
-
-	for range ticker.C {
+	for {
+		time.Sleep(rateLimit.refillInterval())
 		select {
 		case rateLimit.TokenBucket <- struct{}{}:
 		default:
@@ -434,18 +477,102 @@ func (nm *NFTMarketplace) refillTokenBucket(platform string) {
 	}
 }
 
-// waitForRateLimit 等待速率限制
-func (nm *NFTMarketplace) waitForRateLimit(platform string) {
-	if rateLimit, exists := nm.rateLimit[platform]; exists {
-		<-rateLimit.TokenBucket
+// refillInterval 当前令牌补充间隔，冷却期结束后恢复配置速率
+func (rl *RateLimit) refillInterval() time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if !rl.cooldownUntil.IsZero() && time.Now().After(rl.cooldownUntil) {
+		rl.RequestsPerSecond = rl.baseRPS
+		rl.cooldownUntil = time.Time{}
+	}
+	return time.Second / time.Duration(rl.RequestsPerSecond)
+}
+
+// recordThrottled 记录一次 429，连续多次时将速率减半并进入冷却期
+func (rl *RateLimit) recordThrottled() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.throttled++
+	if rl.throttled >= rateLimitShrinkAfter {
+		if rl.RequestsPerSecond > 1 {
+			rl.RequestsPerSecond /= 2
+		}
+		rl.cooldownUntil = time.Now().Add(rateLimitCooldownPeriod)
+		rl.throttled = 0
+	}
+}
+
+// recordSuccess 请求成功后清零连续 429 计数
+func (rl *RateLimit) recordSuccess() {
+	rl.mu.Lock()
+	rl.throttled = 0
+	rl.mu.Unlock()
+}
+
+// waitForRateLimit 等待速率限制，ctx 取消时返回错误
+func (nm *NFTMarketplace) waitForRateLimit(ctx context.Context, platform string) error {
+	rateLimit, exists := nm.rateLimit[platform]
+	if !exists {
+		return nil
+	}
+	select {
+	case <-rateLimit.TokenBucket:
+		rateLimit.mu.Lock()
+		rateLimit.LastRequest = time.Now()
+		rateLimit.mu.Unlock()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
 // makeAPIRequest 发送API请求
+// 遇到 429 / 5xx / 网络错误时按指数退避加抖动重试（最多 marketAPIMaxAttempts 次），优先遵循 Retry-After
 func (nm *NFTMarketplace) makeAPIRequest(ctx context.Context, platform, endpoint string, params map[string]string) ([]byte, error) {
-	// 速率限制
-	nm.waitForRateLimit(platform)
+	var lastErr error
+	for attempt := 0; attempt < marketAPIMaxAttempts; attempt++ {
+		// 速率限制
+		if err := nm.waitForRateLimit(ctx, platform); err != nil {
+			return nil, err
+		}
 
+		body, retryAfter, err := nm.doAPIRequest(ctx, platform, endpoint, params)
+		if err == nil {
+			if rateLimit, exists := nm.rateLimit[platform]; exists {
+				rateLimit.recordSuccess()
+			}
+			return body, nil
+		}
+		lastErr = err
+		if errors.Is(err, ErrRateLimited) {
+			if rateLimit, exists := nm.rateLimit[platform]; exists {
+				rateLimit.recordThrottled()
+			}
+		}
+		if !isRetryableMarketError(err) || attempt == marketAPIMaxAttempts-1 {
+			break
+		}
+
+		wait := retryAfter
+		if wait < 0 {
+			wait = marketBackoff(attempt)
+		}
+		if wait > marketAPIMaxBackoff {
+			wait = marketAPIMaxBackoff
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return nil, lastErr
+}
+
+// doAPIRequest 发送单次API请求，返回响应体与服务端要求的重试等待时长（未指定时为负数）
+func (nm *NFTMarketplace) doAPIRequest(ctx context.Context, platform, endpoint string, params map[string]string) ([]byte, time.Duration, error) {
 	// 构建URL
 	baseURL := nm.apiEndpoints[platform]
 	url := fmt.Sprintf("%s%s", baseURL, endpoint)
@@ -453,7 +580,7 @@ func (nm *NFTMarketplace) makeAPIRequest(ctx context.Context, platform, endpoint
 	// 创建请求
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, err
+		return nil, -1, err
 	}
 
 	// 添加查询参数
@@ -463,8 +590,14 @@ func (nm *NFTMarketplace) makeAPIRequest(ctx context.Context, platform, endpoint
 	}
 	req.URL.RawQuery = q.Encode()
 
-	// 添加API密钥
-	if apiKey, exists := nm.apiKeys[platform]; exists {
+	// 添加API密钥（优先使用当前用户配置的密钥，其次为全局密钥）
+	apiKey := ProviderKeyFromContext(ctx, platform)
+	if apiKey == "" {
+		nm.mu.RLock()
+		apiKey = nm.apiKeys[platform]
+		nm.mu.RUnlock()
+	}
+	if apiKey != "" {
 		switch platform {
 		case "opensea":
 			req.Header.Set("X-API-KEY", apiKey)
@@ -476,21 +609,78 @@ func (nm *NFTMarketplace) makeAPIRequest(ctx context.Context, platform, endpoint
 	// 发送请求
 	resp, err := nm.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, -1, &marketTransientError{err: err}
 	}
 	defer resp.Body.Close()
 
 	// 读取响应
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, -1, &marketTransientError{err: err}
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API请求失败: %s", resp.Status)
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return body, -1, nil
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return nil, parseRetryAfter(resp.Header.Get("Retry-After")), fmt.Errorf("%w: %s", ErrRateLimited, platform)
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, -1, fmt.Errorf("%w: %s%s", ErrMarketNotFound, platform, endpoint)
+	case resp.StatusCode >= 500:
+		return nil, parseRetryAfter(resp.Header.Get("Retry-After")), &marketTransientError{err: fmt.Errorf("API请求失败: %s", resp.Status)}
+	default:
+		return nil, -1, fmt.Errorf("API请求失败: %s", resp.Status)
 	}
+}
 
-	return body, nil
+// marketTransientError 可重试的临时错误（网络错误、5xx）
+type marketTransientError struct {
+	err error
+}
+
+func (e *marketTransientError) Error() string { return e.err.Error() }
+func (e *marketTransientError) Unwrap() error { return e.err }
+
+// isRetryableMarketError 判断错误是否值得重试
+func isRetryableMarketError(err error) bool {
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+	var transient *marketTransientError
+	return errors.As(err, &transient) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// pickMarketError 从多个平台的错误中选出返回给调用方的错误，限流错误优先
+func pickMarketError(errs []error) error {
+	for _, err := range errs {
+		if errors.Is(err, ErrRateLimited) {
+			return err
+		}
+	}
+	return errs[0]
+}
+
+// marketBackoff 第 attempt 次失败后的退避时长：base * 2^attempt，加上最多 50% 的随机抖动
+func marketBackoff(attempt int) time.Duration {
+	backoff := marketAPIBaseBackoff << uint(attempt)
+	return backoff + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// parseRetryAfter 解析 Retry-After 头（秒数或 HTTP 日期），缺失或无效时返回 -1
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return -1
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+		return 0
+	}
+	return -1
 }
 
 // getFromCache 从缓存获取数据
@@ -523,6 +713,10 @@ func (nm *NFTMarketplace) setCache(key string, data interface{}, duration time.D
 
 // getListingsFromPlatform 从特定平台获取挂单
 func (nm *NFTMarketplace) getListingsFromPlatform(ctx context.Context, platform string, request *MarketListingRequest) ([]*MarketListing, error) {
+	if platform == "opensea" {
+		return nm.getOpenSeaListings(ctx, request)
+	}
+
 	// 简化实现：返回模拟数据
 	return []*MarketListing{
 		{
@@ -575,33 +769,6 @@ func (nm *NFTMarketplace) getTransactionsFromPlatform(ctx context.Context, platf
 }
 
 // 其他简化实现的方法...
-func (nm *NFTMarketplace) getOpenSeaStats(ctx context.Context, contract string) (*MarketStats, error) {
-	// 简化实现
-	return &MarketStats{
-		Contract: contract,
-		Platform: "opensea",
-		FloorPrice: &MarketPrice{
-			Amount:   big.NewInt(500000000000000000), // 0.5 ETH
-			Currency: "ETH",
-			USDValue: 1000.0,
-			Symbol:   "ETH",
-			Decimals: 18,
-		},
-		Volume24h: &MarketPrice{
-			Amount:   new(big.Int).SetUint64(10000000000000000000), // 10 ETH
-			Currency: "ETH",
-			USDValue: 20000.0,
-			Symbol:   "ETH",
-			Decimals: 18,
-		},
-		Sales24h:    5,
-		TotalSupply: 10000,
-		OwnersCount: 3500,
-		ListedCount: 250,
-		UpdatedAt:   time.Now(),
-	}, nil
-}
-
 func (nm *NFTMarketplace) getRaribleStats(ctx context.Context, contract string) (*MarketStats, error) {
 	// 简化实现
 	return nm.getOpenSeaStats(ctx, contract)
@@ -0,0 +1,252 @@
+/*
+OpenSea v2 API 集成
+
+OpenSea v2 以集合 slug 为主键，本文件负责：
+- 合约地址 → slug：GET /chain/{chain}/contract/{address}，结果缓存 24 小时
+- 集合统计：GET /collections/{slug}/stats（地板价、成交量、持有者数等，金额为以 ETH 计的小数）
+- 集合挂单：GET /listings/collection/{slug}/all（价格为 wei 十进制字符串，卖家与 Token 来自 Seaport 订单参数）
+
+请求统一经 makeAPIRequest 发送（携带 X-API-KEY，带限流与重试），
+集合不存在时返回包装了 ErrMarketNotFound 的错误。
+*/
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	openSeaChain        = "ethereum" // 合约地址默认所在链
+	openSeaSlugCacheTTL = 24 * time.Hour
+	openSeaMaxPageSize  = 100 // listings 接口单页上限
+	openSeaAssetURL     = "https://opensea.io/assets/%s/%s/%s"
+)
+
+// openSeaContract GET /chain/{chain}/contract/{address} 响应
+type openSeaContract struct {
+	Address    string `json:"address"`
+	Collection string `json:"collection"`
+	Name       string `json:"name"`
+}
+
+// openSeaStatsResponse GET /collections/{slug}/stats 响应
+type openSeaStatsResponse struct {
+	Total struct {
+		Volume           float64 `json:"volume"`
+		Sales            int     `json:"sales"`
+		AveragePrice     float64 `json:"average_price"`
+		NumOwners        int     `json:"num_owners"`
+		FloorPrice       float64 `json:"floor_price"`
+		FloorPriceSymbol string  `json:"floor_price_symbol"`
+	} `json:"total"`
+	Intervals []struct {
+		Interval     string  `json:"interval"` // one_day / seven_day / thirty_day
+		Volume       float64 `json:"volume"`
+		VolumeChange float64 `json:"volume_change"`
+		Sales        int     `json:"sales"`
+		AveragePrice float64 `json:"average_price"`
+	} `json:"intervals"`
+}
+
+// openSeaListingsResponse GET /listings/collection/{slug}/all 响应
+type openSeaListingsResponse struct {
+	Listings []struct {
+		OrderHash string `json:"order_hash"`
+		Chain     string `json:"chain"`
+		Price     struct {
+			Current struct {
+				Currency string `json:"currency"`
+				Decimals int    `json:"decimals"`
+				Value    string `json:"value"`
+			} `json:"current"`
+		} `json:"price"`
+		ProtocolData struct {
+			Parameters struct {
+				Offerer   string `json:"offerer"`
+				StartTime string `json:"startTime"`
+				EndTime   string `json:"endTime"`
+				Offer     []struct {
+					Token                string `json:"token"`
+					IdentifierOrCriteria string `json:"identifierOrCriteria"`
+				} `json:"offer"`
+			} `json:"parameters"`
+		} `json:"protocol_data"`
+	} `json:"listings"`
+	Next string `json:"next"`
+}
+
+// openSeaSlug 将合约地址解析为 OpenSea 集合 slug；传入的不是地址时视为 slug 原样返回
+func (nm *NFTMarketplace) openSeaSlug(ctx context.Context, contract string) (string, error) {
+	contract = strings.TrimSpace(contract)
+	if contract == "" {
+		return "", fmt.Errorf("合约地址或集合slug不能为空")
+	}
+	if !common.IsHexAddress(contract) {
+		return contract, nil
+	}
+
+	address := strings.ToLower(contract)
+	cacheKey := "opensea_slug_" + address
+	if cached := nm.getFromCache(cacheKey); cached != nil {
+		if slug, ok := cached.(string); ok {
+			return slug, nil
+		}
+	}
+
+	body, err := nm.makeAPIRequest(ctx, "opensea", fmt.Sprintf("/chain/%s/contract/%s", openSeaChain, address), nil)
+	if err != nil {
+		return "", err
+	}
+	var resp openSeaContract
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("解析OpenSea合约信息失败: %w", err)
+	}
+	if resp.Collection == "" {
+		return "", fmt.Errorf("%w: 合约 %s 未被OpenSea收录为集合", ErrMarketNotFound, contract)
+	}
+
+	nm.setCache(cacheKey, resp.Collection, openSeaSlugCacheTTL)
+	return resp.Collection, nil
+}
+
+// getOpenSeaStats 获取 OpenSea 集合统计（结果由 GetMarketStats 缓存 5 分钟）
+func (nm *NFTMarketplace) getOpenSeaStats(ctx context.Context, contract string) (*MarketStats, error) {
+	slug, err := nm.openSeaSlug(ctx, contract)
+	if err != nil {
+		return nil, err
+	}
+	body, err := nm.makeAPIRequest(ctx, "opensea", fmt.Sprintf("/collections/%s/stats", slug), nil)
+	if err != nil {
+		return nil, err
+	}
+	var resp openSeaStatsResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("解析OpenSea统计数据失败: %w", err)
+	}
+
+	symbol := resp.Total.FloorPriceSymbol
+	if symbol == "" {
+		symbol = "ETH"
+	}
+	stats := &MarketStats{
+		Contract:     contract,
+		Platform:     "opensea",
+		FloorPrice:   openSeaEtherPrice(resp.Total.FloorPrice, symbol),
+		AveragePrice: openSeaEtherPrice(resp.Total.AveragePrice, "ETH"),
+		OwnersCount:  resp.Total.NumOwners,
+		UpdatedAt:    time.Now(),
+	}
+	for _, interval := range resp.Intervals {
+		switch interval.Interval {
+		case "one_day":
+			stats.Volume24h = openSeaEtherPrice(interval.Volume, "ETH")
+			stats.Sales24h = interval.Sales
+			stats.VolumeChange24h = interval.VolumeChange
+		case "seven_day":
+			stats.Volume7d = openSeaEtherPrice(interval.Volume, "ETH")
+			stats.Sales7d = interval.Sales
+		case "thirty_day":
+			stats.Volume30d = openSeaEtherPrice(interval.Volume, "ETH")
+			stats.Sales30d = interval.Sales
+		}
+	}
+	return stats, nil
+}
+
+// getOpenSeaListings 获取 OpenSea 集合挂单，按请求中的 Token、卖家与价格区间过滤
+func (nm *NFTMarketplace) getOpenSeaListings(ctx context.Context, request *MarketListingRequest) ([]*MarketListing, error) {
+	slug, err := nm.openSeaSlug(ctx, request.Contract)
+	if err != nil {
+		return nil, err
+	}
+
+	pageSize := request.Offset + request.Limit
+	if pageSize <= 0 || pageSize > openSeaMaxPageSize {
+		pageSize = openSeaMaxPageSize
+	}
+	body, err := nm.makeAPIRequest(ctx, "opensea", fmt.Sprintf("/listings/collection/%s/all", slug), map[string]string{
+		"limit": strconv.Itoa(pageSize),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var resp openSeaListingsResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("解析OpenSea挂单数据失败: %w", err)
+	}
+
+	listings := make([]*MarketListing, 0, len(resp.Listings))
+	for _, item := range resp.Listings {
+		params := item.ProtocolData.Parameters
+		if len(params.Offer) == 0 {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(item.Price.Current.Value, 10)
+		if !ok {
+			continue
+		}
+		token := params.Offer[0]
+		if request.TokenID != "" && token.IdentifierOrCriteria != request.TokenID {
+			continue
+		}
+		if request.Seller != "" && !strings.EqualFold(params.Offerer, request.Seller) {
+			continue
+		}
+		if request.MinPrice != nil && amount.Cmp(request.MinPrice) < 0 {
+			continue
+		}
+		if request.MaxPrice != nil && amount.Cmp(request.MaxPrice) > 0 {
+			continue
+		}
+
+		chain := item.Chain
+		if chain == "" {
+			chain = openSeaChain
+		}
+		listing := &MarketListing{
+			ID:          item.OrderHash,
+			Platform:    "opensea",
+			NFTContract: token.Token,
+			TokenID:     token.IdentifierOrCriteria,
+			Seller:      params.Offerer,
+			Price: &MarketPrice{
+				Amount:   amount,
+				Currency: item.Price.Current.Currency,
+				Symbol:   item.Price.Current.Currency,
+				Decimals: item.Price.Current.Decimals,
+			},
+			Currency:   item.Price.Current.Currency,
+			Status:     "active",
+			ListingURL: fmt.Sprintf(openSeaAssetURL, chain, token.Token, token.IdentifierOrCriteria),
+			Metadata:   map[string]interface{}{"collection": slug},
+		}
+		if start, err := strconv.ParseInt(params.StartTime, 10, 64); err == nil {
+			listing.CreatedAt = time.Unix(start, 0)
+		}
+		if end, err := strconv.ParseInt(params.EndTime, 10, 64); err == nil {
+			expires := time.Unix(end, 0)
+			listing.ExpiresAt = &expires
+		}
+		listings = append(listings, listing)
+	}
+	return listings, nil
+}
+
+// openSeaEtherPrice 将 OpenSea 返回的 ETH 小数金额转换为 wei
+func openSeaEtherPrice(value float64, symbol string) *MarketPrice {
+	wei, _ := new(big.Float).Mul(big.NewFloat(value), big.NewFloat(1e18)).Int(nil)
+	return &MarketPrice{
+		Amount:   wei,
+		Currency: symbol,
+		Symbol:   symbol,
+		Decimals: 18,
+	}
+}
@@ -0,0 +1,389 @@
+/*
+NFT持有枚举与元数据
+
+按合约枚举地址持有的NFT：
+- 支持 ERC721Enumerable（0x780e9d63）的合约：balanceOf + tokenOfOwnerByIndex 逐个读取，结果完整
+- 其他 ERC-721 合约：从 Transfer 日志（tokenId 为 indexed，共4个topic）收集转入/转出过的 tokenId，再用 ownerOf 确认仍持有
+- ERC-1155 合约：从 TransferSingle / TransferBatch 日志收集 id，再用 balanceOf 确认持有数量
+
+日志只回溯最近 lookbackBlocks 个区块，更早转入且之后没有转账记录的代币可能遗漏；未指定合约时同样按日志识别合约。
+
+元数据读取 tokenURI（ERC-721）或 uri（ERC-1155，{id} 替换为64位十六进制），
+通过 URIResolver 获取（ipfs:// / ipns:// 按网关顺序重试，data: 内联元数据直接解码），成功结果按 TTL 缓存。
+*/
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// NFT标准名称
+const (
+	NFTStandardERC721  = "ERC-721"
+	NFTStandardERC1155 = "ERC-1155"
+)
+
+// ERC-1155 转账事件 topic0
+var (
+	transferSingleEventTopic = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+	transferBatchEventTopic  = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+)
+
+// erc721EnumerableInterface ERC721Enumerable 接口ID
+var erc721EnumerableInterface = [4]byte{0x78, 0x0e, 0x9d, 0x63}
+
+// nftOwnershipABI ERC-721 持有查询与元数据方法
+const nftOwnershipABI = `[
+	{"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"owner","type":"address"},{"name":"index","type":"uint256"}],"name":"tokenOfOwnerByIndex","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"tokenId","type":"uint256"}],"name":"ownerOf","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"tokenId","type":"uint256"}],"name":"tokenURI","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"}
+]`
+
+// nft1155OwnershipABI ERC-1155 持有查询、元数据方法与 TransferBatch 事件
+const nft1155OwnershipABI = `[
+	{"inputs":[{"name":"account","type":"address"},{"name":"id","type":"uint256"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"id","type":"uint256"}],"name":"uri","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"operator","type":"address"},{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"ids","type":"uint256[]"},{"indexed":false,"name":"values","type":"uint256[]"}],"name":"TransferBatch","type":"event"}
+]`
+
+var (
+	parsedNFTOwnershipABI     = mustParseABI(nftOwnershipABI)
+	parsedNFT1155OwnershipABI = mustParseABI(nft1155OwnershipABI)
+)
+
+// OwnedNFT 地址持有的一个NFT
+type OwnedNFT struct {
+	Contract string   // 合约地址（checksum）
+	Standard string   // ERC-721 / ERC-1155
+	TokenID  *big.Int // 代币ID
+	Balance  *big.Int // 持有数量，ERC-721 为 1
+}
+
+// nftCandidates 日志中与地址相关的合约与 tokenId
+type nftCandidates struct {
+	standards map[common.Address]string
+	tokenIDs  map[common.Address]map[string]*big.Int
+	order     []common.Address
+}
+
+func (c *nftCandidates) add(contract common.Address, standard string, id *big.Int) {
+	if _, ok := c.standards[contract]; !ok {
+		c.standards[contract] = standard
+		c.tokenIDs[contract] = make(map[string]*big.Int)
+		c.order = append(c.order, contract)
+	}
+	c.tokenIDs[contract][id.String()] = id
+}
+
+// SetMetadataOptions 设置元数据获取使用的地址解析器与缓存时长
+func (n *NFTManager) SetMetadataOptions(resolver *URIResolver, ttl time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.uriResolver = resolver
+	n.metadataTTL = ttl
+}
+
+// EnumerateOwnedNFTs 枚举 owner 持有的NFT，按合约、tokenId 排序
+// contracts 为空时从最近 lookbackBlocks 个区块的转账日志识别合约；每个合约最多返回 maxPerContract 个代币
+func (n *NFTManager) EnumerateOwnedNFTs(ctx context.Context, owner string, contracts []string, lookbackBlocks uint64, maxPerContract int) ([]*OwnedNFT, error) {
+	if !common.IsHexAddress(owner) {
+		return nil, fmt.Errorf("无效的地址: %s", owner)
+	}
+	ownerAddr := common.HexToAddress(owner)
+
+	var candidates *nftCandidates
+	scan := func(filter []common.Address) error {
+		if candidates != nil {
+			return nil
+		}
+		var err error
+		candidates, err = n.evmAdapter.nftTransferCandidates(ctx, ownerAddr, filter, lookbackBlocks)
+		return err
+	}
+
+	// 确定每个合约的标准：指定合约时通过 supportsInterface 检测，否则取日志中的事件类型
+	var targets []common.Address
+	standards := make(map[common.Address]string)
+	if len(contracts) > 0 {
+		for _, c := range contracts {
+			if !common.IsHexAddress(c) {
+				return nil, fmt.Errorf("无效的合约地址: %s", c)
+			}
+			addr := common.HexToAddress(c)
+			if _, ok := standards[addr]; ok {
+				continue
+			}
+			standard, err := n.detectNFTStandard(ctx, addr.Hex())
+			if err != nil {
+				return nil, fmt.Errorf("合约 %s: %w", addr.Hex(), err)
+			}
+			standards[addr] = standard
+			targets = append(targets, addr)
+		}
+	} else {
+		if err := scan(nil); err != nil {
+			return nil, err
+		}
+		targets = candidates.order
+		for addr, standard := range candidates.standards {
+			standards[addr] = standard
+		}
+	}
+
+	var owned []*OwnedNFT
+	for _, contract := range targets {
+		var (
+			tokens []*OwnedNFT
+			err    error
+		)
+		enumerable := false
+		if standards[contract] == NFTStandardERC721 {
+			enumerable, _ = n.supportsInterface(ctx, contract.Hex(), erc721EnumerableInterface)
+		}
+		if enumerable {
+			tokens, err = n.enumerateERC721(ctx, contract, ownerAddr, maxPerContract)
+		} else {
+			if err := scan(targets); err != nil {
+				return nil, err
+			}
+			tokens, err = n.confirmHoldings(ctx, contract, standards[contract], ownerAddr, candidates.tokenIDs[contract], maxPerContract)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("枚举合约 %s 失败: %w", contract.Hex(), err)
+		}
+		owned = append(owned, tokens...)
+	}
+
+	sort.Slice(owned, func(i, j int) bool {
+		if owned[i].Contract != owned[j].Contract {
+			return owned[i].Contract < owned[j].Contract
+		}
+		return owned[i].TokenID.Cmp(owned[j].TokenID) < 0
+	})
+	return owned, nil
+}
+
+// enumerateERC721 通过 ERC721Enumerable 读取 owner 持有的全部 tokenId
+func (n *NFTManager) enumerateERC721(ctx context.Context, contract, owner common.Address, max int) ([]*OwnedNFT, error) {
+	out, err := n.callNFT(ctx, parsedNFTOwnershipABI, contract, "balanceOf", owner)
+	if err != nil {
+		return nil, err
+	}
+	balance := out[0].(*big.Int)
+	count := int(balance.Int64())
+	if !balance.IsInt64() || count > max {
+		count = max
+	}
+	tokens := make([]*OwnedNFT, 0, count)
+	for i := 0; i < count; i++ {
+		out, err := n.callNFT(ctx, parsedNFTOwnershipABI, contract, "tokenOfOwnerByIndex", owner, big.NewInt(int64(i)))
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, &OwnedNFT{Contract: contract.Hex(), Standard: NFTStandardERC721, TokenID: out[0].(*big.Int), Balance: big.NewInt(1)})
+	}
+	return tokens, nil
+}
+
+// confirmHoldings 逐个确认日志中出现过的 tokenId 当前是否仍由 owner 持有
+func (n *NFTManager) confirmHoldings(ctx context.Context, contract common.Address, standard string, owner common.Address, ids map[string]*big.Int, max int) ([]*OwnedNFT, error) {
+	sorted := make([]*big.Int, 0, len(ids))
+	for _, id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+
+	var tokens []*OwnedNFT
+	for _, id := range sorted {
+		if len(tokens) >= max {
+			break
+		}
+		if standard == NFTStandardERC1155 {
+			out, err := n.callNFT(ctx, parsedNFT1155OwnershipABI, contract, "balanceOf", owner, id)
+			if err != nil {
+				return nil, err
+			}
+			if balance := out[0].(*big.Int); balance.Sign() > 0 {
+				tokens = append(tokens, &OwnedNFT{Contract: contract.Hex(), Standard: standard, TokenID: id, Balance: balance})
+			}
+			continue
+		}
+		out, err := n.callNFT(ctx, parsedNFTOwnershipABI, contract, "ownerOf", id)
+		if err != nil {
+			continue // 已销毁的代币 ownerOf 会回滚
+		}
+		if out[0].(common.Address) == owner {
+			tokens = append(tokens, &OwnedNFT{Contract: contract.Hex(), Standard: standard, TokenID: id, Balance: big.NewInt(1)})
+		}
+	}
+	return tokens, nil
+}
+
+// callNFT 调用合约只读方法并解码返回值
+func (n *NFTManager) callNFT(ctx context.Context, parsed abi.ABI, contract common.Address, method string, args ...interface{}) ([]interface{}, error) {
+	data, err := parsed.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("打包 %s 数据失败: %w", method, err)
+	}
+	result, err := n.evmAdapter.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("调用 %s 失败: %w", method, err)
+	}
+	out, err := parsed.Unpack(method, result)
+	if err != nil || len(out) == 0 {
+		return nil, fmt.Errorf("解析 %s 返回值失败: %v", method, err)
+	}
+	return out, nil
+}
+
+// nftTransferCandidates 查询最近 lookbackBlocks 个区块内 owner 转入或转出的NFT转账日志
+// contracts 非空时只查询这些合约
+func (a *EVMAdapter) nftTransferCandidates(ctx context.Context, owner common.Address, contracts []common.Address, lookbackBlocks uint64) (*nftCandidates, error) {
+	latest, err := a.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取最新区块失败: %w", err)
+	}
+	start := uint64(0)
+	if latest > lookbackBlocks {
+		start = latest - lookbackBlocks + 1
+	}
+
+	ownerTopic := common.BytesToHash(owner.Bytes())
+	erc1155Topics := []common.Hash{transferSingleEventTopic, transferBatchEventTopic}
+	queries := [][][]common.Hash{
+		{{transferEventTopic}, {ownerTopic}},      // ERC-721 from = owner
+		{{transferEventTopic}, nil, {ownerTopic}}, // ERC-721 to = owner
+		{erc1155Topics, nil, {ownerTopic}},        // ERC-1155 from = owner
+		{erc1155Topics, nil, nil, {ownerTopic}},   // ERC-1155 to = owner
+	}
+
+	candidates := &nftCandidates{standards: make(map[common.Address]string), tokenIDs: make(map[common.Address]map[string]*big.Int)}
+	for from := start; from <= latest; from += logQueryBlockRange {
+		to := from + logQueryBlockRange - 1
+		if to > latest || to < from {
+			to = latest
+		}
+		for _, topics := range queries {
+			logs, err := a.client.FilterLogs(ctx, ethereum.FilterQuery{
+				FromBlock: new(big.Int).SetUint64(from),
+				ToBlock:   new(big.Int).SetUint64(to),
+				Addresses: contracts,
+				Topics:    topics,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("查询NFT转账日志失败（区块 %d-%d）: %w", from, to, err)
+			}
+			for _, lg := range logs {
+				if !lg.Removed {
+					addNFTTransferLog(candidates, lg)
+				}
+			}
+		}
+		if to == latest {
+			break
+		}
+	}
+	return candidates, nil
+}
+
+// addNFTTransferLog 从一条转账日志中提取合约与 tokenId；ERC-20 Transfer（3个topic）忽略
+func addNFTTransferLog(candidates *nftCandidates, lg types.Log) {
+	switch {
+	case lg.Topics[0] == transferEventTopic && len(lg.Topics) == 4:
+		candidates.add(lg.Address, NFTStandardERC721, lg.Topics[3].Big())
+	case lg.Topics[0] == transferSingleEventTopic && len(lg.Data) >= 64:
+		candidates.add(lg.Address, NFTStandardERC1155, new(big.Int).SetBytes(lg.Data[:32]))
+	case lg.Topics[0] == transferBatchEventTopic:
+		out, err := parsedNFT1155OwnershipABI.Unpack("TransferBatch", lg.Data)
+		if err != nil || len(out) < 1 {
+			return
+		}
+		ids, _ := out[0].([]*big.Int)
+		for _, id := range ids {
+			candidates.add(lg.Address, NFTStandardERC1155, id)
+		}
+	}
+}
+
+// FetchTokenMetadata 读取NFT的元数据URI并获取元数据，成功结果按 TTL 缓存
+// 返回元数据、属性与原始URI
+func (n *NFTManager) FetchTokenMetadata(ctx context.Context, contract, standard string, tokenID *big.Int) (*NFTMetadata, []*Attribute, string, error) {
+	cacheKey := strings.ToLower(contract) + ":" + tokenID.String()
+	n.mu.RLock()
+	cached, ok := n.metadataCache[cacheKey]
+	resolver, ttl := n.uriResolver, n.metadataTTL
+	n.mu.RUnlock()
+	if ok && time.Now().Before(cached.ExpiresAt) {
+		return cached.Metadata, cached.Attributes, cached.Version, nil
+	}
+
+	tokenURI, err := n.evmAdapter.nftTokenURI(ctx, common.HexToAddress(contract), standard, tokenID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if strings.TrimSpace(tokenURI) == "" {
+		return nil, nil, "", fmt.Errorf("元数据URI为空")
+	}
+	body, _, err := resolver.Fetch(ctx, tokenURI)
+	if err != nil {
+		return nil, nil, tokenURI, fmt.Errorf("获取元数据失败: %w", err)
+	}
+	metadata, attributes, err := parseNFTMetadata(body, resolver)
+	if err != nil {
+		return nil, nil, tokenURI, err
+	}
+
+	now := time.Now()
+	n.mu.Lock()
+	n.metadataCache[cacheKey] = &NFTCache{Metadata: metadata, Attributes: attributes, CachedAt: now, ExpiresAt: now.Add(ttl), Version: tokenURI}
+	n.mu.Unlock()
+	return metadata, attributes, tokenURI, nil
+}
+
+// nftTokenURI 读取 tokenURI（ERC-721）或 uri（ERC-1155）
+func (a *EVMAdapter) nftTokenURI(ctx context.Context, contract common.Address, standard string, tokenID *big.Int) (string, error) {
+	if standard == NFTStandardERC1155 {
+		out, err := a.callView(ctx, contract, parsedNFT1155OwnershipABI, "uri", tokenID)
+		if err != nil {
+			return "", err
+		}
+		uri, _ := out[0].(string)
+		// ERC-1155 约定 {id} 替换为小写、补零至64位的十六进制ID
+		return strings.ReplaceAll(uri, "{id}", fmt.Sprintf("%064x", tokenID)), nil
+	}
+	out, err := a.callView(ctx, contract, parsedNFTOwnershipABI, "tokenURI", tokenID)
+	if err != nil {
+		return "", err
+	}
+	uri, _ := out[0].(string)
+	return uri, nil
+}
+
+// parseNFTMetadata 解析 OpenSea 风格的元数据JSON，图片与动画地址转换为网关地址
+func parseNFTMetadata(body []byte, resolver *URIResolver) (*NFTMetadata, []*Attribute, error) {
+	var doc struct {
+		NFTMetadata
+		Attributes []*Attribute    `json:"attributes"`
+		Properties json.RawMessage `json:"properties"` // 非标准字段，类型不固定，不解析
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, nil, fmt.Errorf("解析元数据失败: %w", err)
+	}
+	metadata := doc.NFTMetadata
+	metadata.Image = resolver.GatewayURL(metadata.Image)
+	metadata.Animation = resolver.GatewayURL(metadata.Animation)
+	return &metadata, doc.Attributes, nil
+}
@@ -0,0 +1,165 @@
+/*
+NFT 转账与持有查询（ERC-721 / ERC-1155）
+
+两种标准的 safeTransferFrom 签名不同：
+  - ERC-721:  safeTransferFrom(address from, address to, uint256 tokenId)
+    （另有带 bytes data 的重载，这里使用三参数版本，避免 ABI 重载命名歧义）
+  - ERC-1155: safeTransferFrom(address from, address to, uint256 id, uint256 amount, bytes data)
+    （仅此一个签名，data 必填，无附加数据时传空字节）
+
+接收方为合约时，两者都会回调 onERC721Received / onERC1155Received，不支持的合约会导致交易回滚。
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const erc721TransferABI = `[
+	{"inputs":[{"name":"tokenId","type":"uint256"}],"name":"ownerOf","outputs":[{"name":"owner","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"}],"name":"safeTransferFrom","outputs":[],"stateMutability":"nonpayable","type":"function"}
+]`
+
+const erc1155TransferABI = `[
+	{"inputs":[{"name":"account","type":"address"},{"name":"id","type":"uint256"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"id","type":"uint256"},{"name":"amount","type":"uint256"},{"name":"data","type":"bytes"}],"name":"safeTransferFrom","outputs":[],"stateMutability":"nonpayable","type":"function"}
+]`
+
+// GetERC721Owner 查询 ERC-721 代币的持有者
+func (a *EVMAdapter) GetERC721Owner(ctx context.Context, contract string, tokenID *big.Int) (string, error) {
+	if !common.IsHexAddress(contract) {
+		return "", fmt.Errorf("无效的合约地址: %s", contract)
+	}
+	parsed, err := abi.JSON(strings.NewReader(erc721TransferABI))
+	if err != nil {
+		return "", fmt.Errorf("解析ERC-721 ABI失败: %w", err)
+	}
+	data, err := parsed.Pack("ownerOf", tokenID)
+	if err != nil {
+		return "", fmt.Errorf("打包ownerOf数据失败: %w", err)
+	}
+	to := common.HexToAddress(contract)
+	out, err := a.client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return "", fmt.Errorf("调用ownerOf失败: %w", err)
+	}
+	res, err := parsed.Unpack("ownerOf", out)
+	if err != nil || len(res) == 0 {
+		return "", fmt.Errorf("解析ownerOf结果失败: %v", err)
+	}
+	owner, ok := res[0].(common.Address)
+	if !ok {
+		return "", fmt.Errorf("ownerOf 返回类型异常")
+	}
+	return owner.Hex(), nil
+}
+
+// GetERC1155Balance 查询 owner 持有的 ERC-1155 代币数量
+func (a *EVMAdapter) GetERC1155Balance(ctx context.Context, contract, owner string, tokenID *big.Int) (*big.Int, error) {
+	if !common.IsHexAddress(contract) {
+		return nil, fmt.Errorf("无效的合约地址: %s", contract)
+	}
+	if !common.IsHexAddress(owner) {
+		return nil, fmt.Errorf("无效的地址: %s", owner)
+	}
+	parsed, err := abi.JSON(strings.NewReader(erc1155TransferABI))
+	if err != nil {
+		return nil, fmt.Errorf("解析ERC-1155 ABI失败: %w", err)
+	}
+	data, err := parsed.Pack("balanceOf", common.HexToAddress(owner), tokenID)
+	if err != nil {
+		return nil, fmt.Errorf("打包balanceOf数据失败: %w", err)
+	}
+	to := common.HexToAddress(contract)
+	out, err := a.client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("调用balanceOf失败: %w", err)
+	}
+	res, err := parsed.Unpack("balanceOf", out)
+	if err != nil || len(res) == 0 {
+		return nil, fmt.Errorf("解析balanceOf结果失败: %v", err)
+	}
+	balance, ok := res[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("balanceOf 返回类型异常")
+	}
+	return balance, nil
+}
+
+// TransferERC721 通过 safeTransferFrom 转出 ERC-721 代币，发送前校验当前钱包为持有者
+func (a *EVMAdapter) TransferERC721(ctx context.Context, mnemonic, derivationPath, contract, to string, tokenID *big.Int, opts *TxOptions) (string, error) {
+	signer, err := NewMnemonicSigner(mnemonic, derivationPath)
+	if err != nil {
+		return "", err
+	}
+	return a.TransferERC721WithSigner(ctx, signer, contract, to, tokenID, opts)
+}
+
+// TransferERC721WithSigner 使用指定签名者转出 ERC-721 代币，发送前校验签名者为持有者
+func (a *EVMAdapter) TransferERC721WithSigner(ctx context.Context, signer Signer, contract, to string, tokenID *big.Int, opts *TxOptions) (string, error) {
+	if !common.IsHexAddress(to) {
+		return "", fmt.Errorf("无效的接收方地址: %s", to)
+	}
+	fromAddr := signer.Address().Hex()
+	owner, err := a.GetERC721Owner(ctx, contract, tokenID)
+	if err != nil {
+		return "", err
+	}
+	if !strings.EqualFold(owner, fromAddr) {
+		return "", fmt.Errorf("当前钱包 %s 不是该NFT的持有者（持有者: %s）", fromAddr, owner)
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(erc721TransferABI))
+	if err != nil {
+		return "", fmt.Errorf("解析ERC-721 ABI失败: %w", err)
+	}
+	data, err := parsed.Pack("safeTransferFrom", common.HexToAddress(fromAddr), common.HexToAddress(to), tokenID)
+	if err != nil {
+		return "", fmt.Errorf("打包safeTransferFrom数据失败: %w", err)
+	}
+	return a.SendContractCallWithSigner(ctx, signer, common.HexToAddress(contract), data, big.NewInt(0), opts)
+}
+
+// TransferERC1155 通过 safeTransferFrom 转出指定数量的 ERC-1155 代币，发送前校验余额
+func (a *EVMAdapter) TransferERC1155(ctx context.Context, mnemonic, derivationPath, contract, to string, tokenID, amount *big.Int, opts *TxOptions) (string, error) {
+	signer, err := NewMnemonicSigner(mnemonic, derivationPath)
+	if err != nil {
+		return "", err
+	}
+	return a.TransferERC1155WithSigner(ctx, signer, contract, to, tokenID, amount, opts)
+}
+
+// TransferERC1155WithSigner 使用指定签名者转出指定数量的 ERC-1155 代币，发送前校验余额
+func (a *EVMAdapter) TransferERC1155WithSigner(ctx context.Context, signer Signer, contract, to string, tokenID, amount *big.Int, opts *TxOptions) (string, error) {
+	if !common.IsHexAddress(to) {
+		return "", fmt.Errorf("无效的接收方地址: %s", to)
+	}
+	if amount == nil || amount.Sign() <= 0 {
+		return "", fmt.Errorf("转账数量必须大于0")
+	}
+	fromAddr := signer.Address().Hex()
+	balance, err := a.GetERC1155Balance(ctx, contract, fromAddr, tokenID)
+	if err != nil {
+		return "", err
+	}
+	if balance.Cmp(amount) < 0 {
+		return "", fmt.Errorf("持有数量不足: 当前 %s，需要 %s", balance, amount)
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(erc1155TransferABI))
+	if err != nil {
+		return "", fmt.Errorf("解析ERC-1155 ABI失败: %w", err)
+	}
+	data, err := parsed.Pack("safeTransferFrom", common.HexToAddress(fromAddr), common.HexToAddress(to), tokenID, amount, []byte{})
+	if err != nil {
+		return "", fmt.Errorf("打包safeTransferFrom数据失败: %w", err)
+	}
+	return a.SendContractCallWithSigner(ctx, signer, common.HexToAddress(contract), data, big.NewInt(0), opts)
+}
@@ -0,0 +1,202 @@
+/*
+待处理交易 nonce 阻塞检测
+
+交易按 nonce 顺序打包：latest（已上链的交易数）与 pending（含交易池的下一个 nonce）之间的交易未打包前，
+之后发送的交易都会排队等待。费率过低的最低 nonce 交易会让整个队列卡住，而用户只看到交易一直未确认。
+DetectNonceGap 列出占用 [latest, pending) 的交易及其当前费率，并针对最低 nonce 给出处理建议：
+  - 已知原交易：加速（相同内容、提高费率），建议费率满足节点 10% 的替换涨幅且不低于当前网络建议
+  - 原交易内容未知（节点不支持 txpool_contentFrom，且没有已登记的交易哈希）：取消（向自身发送 0 值交易）
+
+建议可直接作为 POST /api/v1/transactions/replace 的参数提交。
+排队池（queued）中 nonce 不连续的交易同样列出，并给出其前面缺少的 nonce。
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 解除阻塞的建议操作（与 /transactions/replace 的 mode 一致）
+const (
+	UnstickSpeedUp = "speed_up"
+	UnstickCancel  = "cancel"
+)
+
+// maxMissingNonces 返回的缺失 nonce 数量上限
+const maxMissingNonces = 100
+
+// StuckTransaction 占用 nonce 的待处理交易
+type StuckTransaction struct {
+	Nonce                uint64 `json:"nonce"`
+	Pool                 string `json:"pool"` // pending / queued，交易内容未知时为 unknown
+	TxHash               string `json:"tx_hash,omitempty"`
+	To                   string `json:"to,omitempty"`
+	Value                string `json:"value,omitempty"`
+	GasLimit             uint64 `json:"gas_limit,omitempty"`
+	GasPrice             string `json:"gas_price,omitempty"`                // legacy 费率
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas,omitempty"` // EIP-1559 tip
+	MaxFeePerGas         string `json:"max_fee_per_gas,omitempty"`          // EIP-1559 feeCap
+	Underpriced          bool   `json:"underpriced"`                        // 费率低于当前网络建议，可能无法被打包
+}
+
+// UnstickSuggestion 针对最低 nonce 交易的处理建议
+type UnstickSuggestion struct {
+	Action               string `json:"action"` // speed_up / cancel
+	Nonce                uint64 `json:"nonce"`
+	TxHash               string `json:"tx_hash,omitempty"`
+	Reason               string `json:"reason"`
+	GasPrice             string `json:"gas_price,omitempty"` // 建议的新费率
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas,omitempty"`
+	MaxFeePerGas         string `json:"max_fee_per_gas,omitempty"`
+}
+
+// NonceGapReport nonce 阻塞检测结果
+type NonceGapReport struct {
+	Address         string             `json:"address"`
+	LatestNonce     uint64             `json:"latest_nonce"`
+	PendingNonce    uint64             `json:"pending_nonce"`
+	HasGap          bool               `json:"has_gap"`        // 存在未打包的交易（pending > latest）
+	Stuck           []StuckTransaction `json:"stuck"`          // nonce 在 [latest, pending) 的交易，按 nonce 升序
+	Queued          []StuckTransaction `json:"queued"`         // nonce 不连续、无法打包的排队交易
+	MissingNonces   []uint64           `json:"missing_nonces"` // 排队交易之前缺少的 nonce
+	TxPoolSupported bool               `json:"txpool_supported"`
+	Suggestion      *UnstickSuggestion `json:"suggestion,omitempty"`
+}
+
+// DetectNonceGap 检测地址在 latest 与 pending nonce 之间的待处理交易，并给出加速或取消建议
+// knownHashes 为已知的 nonce -> 交易哈希（如本服务广播的交易），节点不支持 txpool_contentFrom 时用于补全交易内容
+func (a *EVMAdapter) DetectNonceGap(ctx context.Context, address string, knownHashes map[uint64]string) (*NonceGapReport, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("无效的地址: %s", address)
+	}
+	from := common.HexToAddress(address)
+	pending, latest, err := a.GetNonces(ctx, from.Hex())
+	if err != nil {
+		return nil, err
+	}
+	report := &NonceGapReport{
+		Address:       from.Hex(),
+		LatestNonce:   latest,
+		PendingNonce:  pending,
+		HasGap:        pending > latest,
+		Stuck:         []StuckTransaction{},
+		Queued:        []StuckTransaction{},
+		MissingNonces: []uint64{},
+	}
+
+	content, err := a.txPoolContentFrom(ctx, from)
+	report.TxPoolSupported = err == nil
+	sug, err := a.GetGasSuggestion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var lowest *types.Transaction
+	for nonce := latest; nonce < pending; nonce++ {
+		tx := content["pending"][strconv.FormatUint(nonce, 10)]
+		if tx == nil {
+			if hash, ok := knownHashes[nonce]; ok {
+				if known, isPending, err := a.GetTransactionByHash(ctx, hash); err == nil && isPending && known.Nonce() == nonce {
+					tx = known
+				}
+			}
+		}
+		if nonce == latest {
+			lowest = tx
+		}
+		report.Stuck = append(report.Stuck, stuckTransaction(nonce, "pending", tx, sug))
+	}
+
+	var queuedNonces []uint64
+	for key := range content["queued"] {
+		if nonce, err := strconv.ParseUint(key, 10, 64); err == nil && nonce >= pending {
+			queuedNonces = append(queuedNonces, nonce)
+		}
+	}
+	sort.Slice(queuedNonces, func(i, j int) bool { return queuedNonces[i] < queuedNonces[j] })
+	next := pending
+	for _, nonce := range queuedNonces {
+		for ; next < nonce && len(report.MissingNonces) < maxMissingNonces; next++ {
+			report.MissingNonces = append(report.MissingNonces, next)
+		}
+		next = nonce + 1
+		report.Queued = append(report.Queued, stuckTransaction(nonce, "queued", content["queued"][strconv.FormatUint(nonce, 10)], sug))
+	}
+
+	if len(report.Stuck) > 0 {
+		if report.Suggestion, err = a.unstickSuggestion(ctx, report.Stuck[0], lowest); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// txPoolContentFrom 通过 txpool_contentFrom 查询发送方在交易池中的交易（pending / queued，按十进制 nonce 索引）
+func (a *EVMAdapter) txPoolContentFrom(ctx context.Context, from common.Address) (map[string]map[string]*types.Transaction, error) {
+	var content map[string]map[string]*types.Transaction
+	if err := a.client.CallContext(ctx, &content, "txpool_contentFrom", from); err != nil {
+		return nil, fmt.Errorf("查询交易池失败: %w", err)
+	}
+	return content, nil
+}
+
+// stuckTransaction 汇总交易内容与费率，tx 为空时只记录 nonce
+func stuckTransaction(nonce uint64, pool string, tx *types.Transaction, sug *GasSuggestion) StuckTransaction {
+	item := StuckTransaction{Nonce: nonce, Pool: pool}
+	if tx == nil {
+		item.Pool = "unknown"
+		return item
+	}
+	item.TxHash = tx.Hash().Hex()
+	if tx.To() != nil {
+		item.To = tx.To().Hex()
+	}
+	item.Value = tx.Value().String()
+	item.GasLimit = tx.Gas()
+	fee := TxFeeOf(tx)
+	if fee.IsDynamic() {
+		item.MaxPriorityFeePerGas = fee.TipCap.String()
+		item.MaxFeePerGas = fee.FeeCap.String()
+		item.Underpriced = fee.TipCap.Cmp(sug.TipCap) < 0 || (sug.BaseFee != nil && fee.FeeCap.Cmp(sug.BaseFee) < 0)
+	} else {
+		item.GasPrice = fee.GasPrice.String()
+		item.Underpriced = fee.GasPrice.Cmp(sug.GasPrice) < 0
+	}
+	return item
+}
+
+// unstickSuggestion 为最低 nonce 交易生成加速（内容已知）或取消（内容未知）建议，费率按替换规则自动计算
+func (a *EVMAdapter) unstickSuggestion(ctx context.Context, lowest StuckTransaction, tx *types.Transaction) (*UnstickSuggestion, error) {
+	suggestion := &UnstickSuggestion{Nonce: lowest.Nonce, TxHash: lowest.TxHash}
+	base := TxFee{}
+	if tx == nil {
+		suggestion.Action = UnstickCancel
+		suggestion.Reason = fmt.Sprintf("无法获取 nonce=%d 的原交易内容，只能以0值交易取消；新费率需比原交易高至少%d%%，被节点拒绝时请提高费率重试", lowest.Nonce, ReplacementMinBumpPercent)
+	} else {
+		suggestion.Action = UnstickSpeedUp
+		if lowest.Underpriced {
+			suggestion.Reason = fmt.Sprintf("nonce=%d 的交易费率低于当前网络建议，之后的交易均在等待它被打包，建议加速", lowest.Nonce)
+		} else {
+			suggestion.Reason = fmt.Sprintf("nonce=%d 的交易尚未打包，之后的交易均在等待它；如长时间未确认可加速或取消", lowest.Nonce)
+		}
+		base = TxFeeOf(tx)
+	}
+
+	fee, err := a.replacementFee(ctx, base, &ReplaceOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if fee.IsDynamic() {
+		suggestion.MaxPriorityFeePerGas = fee.TipCap.String()
+		suggestion.MaxFeePerGas = fee.FeeCap.String()
+	} else if fee.GasPrice != nil {
+		suggestion.GasPrice = fee.GasPrice.String()
+	}
+	return suggestion, nil
+}
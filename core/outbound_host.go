@@ -0,0 +1,184 @@
+/*
+出站请求目标地址校验
+
+服务端代用户访问的地址（自定义RPC、Webhook、桥接回调、合约/ENS 记录中的元数据地址等）可能被用来探测内网（SSRF）：
+- 主机名解析后的任一地址为回环、私有、链路本地（含 169.254.169.254 等云元数据）、运营商NAT、未指定或组播地址时拒绝
+- 调用方可传入允许列表（主机名、IP 或 CIDR）放行特定内网目标
+- SafeDialControl 在建立连接时校验实际连接的 IP，防止校验通过后通过 DNS 重绑定指向内网；NewSafeTransport 为使用它的直连传输层
+- SafeDialer 在连接时做同样的校验并放行允许列表中的目标，用于自定义网络的RPC连接（security.custom_rpc_allowed_hosts）
+*/
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrUnsafeOutboundAddress 连接目标为内网或保留地址（SafeDialControl 拒绝连接）
+var ErrUnsafeOutboundAddress = errors.New("拒绝连接到内网或保留地址")
+
+// outboundResolveTimeout 校验时解析主机名的超时时间
+const outboundResolveTimeout = 5 * time.Second
+
+// unsafeNetworks 除标准库可识别的类别外，额外禁止的网段
+var unsafeNetworks = mustParseCIDRs(
+	"0.0.0.0/8",     // 本网络
+	"100.64.0.0/10", // 运营商级NAT
+	"192.0.0.0/24",  // IETF 协议分配
+	"198.18.0.0/15", // 网络基准测试
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// isPublicIP 判断 IP 是否为可安全访问的公网地址
+func isPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, n := range unsafeNetworks {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// hostAllowed 主机名或 IP 是否在允许列表中
+func hostAllowed(host string, ip net.IP, allowed []string) bool {
+	for _, entry := range allowed {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.EqualFold(entry, host) {
+			return true
+		}
+		if ip == nil {
+			continue
+		}
+		if _, n, err := net.ParseCIDR(entry); err == nil && n.Contains(ip) {
+			return true
+		}
+		if allowedIP := net.ParseIP(entry); allowedIP != nil && allowedIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckOutboundHost 解析主机名并校验所有地址均为公网地址，allowed 中的主机名、IP 或网段除外
+func CheckOutboundHost(ctx context.Context, host string, allowed []string) error {
+	host = strings.TrimSuffix(strings.Trim(host, "[]"), ".")
+	if host == "" {
+		return fmt.Errorf("主机名不能为空")
+	}
+	if hostAllowed(host, nil, allowed) {
+		return nil
+	}
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		ctx, cancel := context.WithTimeout(ctx, outboundResolveTimeout)
+		defer cancel()
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return fmt.Errorf("无法解析主机 %s: %w", host, err)
+		}
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+	for _, ip := range ips {
+		if !isPublicIP(ip) && !hostAllowed(host, ip, allowed) {
+			return fmt.Errorf("主机 %s 解析到内网或保留地址 %s，不允许访问", host, ip)
+		}
+	}
+	return nil
+}
+
+// SafeDialControl 用于 net.Dialer.Control，拒绝连接到非公网地址（在解析之后、连接之前校验，可防 DNS 重绑定）
+func SafeDialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w %s", ErrUnsafeOutboundAddress, host)
+	}
+	return nil
+}
+
+// DialContextFunc 建立网络连接的函数（与 net.Dialer.DialContext 签名相同）
+type DialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// SafeDialer 返回只连接公网地址的拨号函数，allowed 中的主机名、IP 或网段除外
+// 主机名按拨号时的目标主机匹配，IP 与网段按解析后实际连接的地址匹配
+func SafeDialer(allowed []string) DialContextFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		control := SafeDialControl
+		if len(allowed) > 0 {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			control = allowedDialControl(strings.TrimSuffix(host, "."), allowed)
+		}
+		d := &net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Control:   control,
+		}
+		return d.DialContext(ctx, network, addr)
+	}
+}
+
+// allowedDialControl 与 SafeDialControl 相同，但放行允许列表中的目标
+func allowedDialControl(host string, allowed []string) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		err := SafeDialControl(network, address, c)
+		if err == nil {
+			return nil
+		}
+		ip, _, splitErr := net.SplitHostPort(address)
+		if splitErr == nil && hostAllowed(host, net.ParseIP(ip), allowed) {
+			return nil
+		}
+		return err
+	}
+}
+
+// NewSafeTransport 只连接公网地址的 HTTP 传输层
+// 直连而不使用环境变量中的代理，否则校验的是代理地址而不是目标地址
+func NewSafeTransport() *http.Transport {
+	return newDialTransport(SafeDialer(nil))
+}
+
+// newDialTransport 经 dial 直连（不使用代理）的 HTTP 传输层
+func newDialTransport(dial DialContextFunc) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dial
+	return transport
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// newLoopbackNode 回环地址上的模拟节点，所有方法均返回 0x64（如 eth_getBalance 为 100 wei）
+func newLoopbackNode(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x64"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSafeDialerAllowList(t *testing.T) {
+	srv := newLoopbackNode(t)
+	u, _ := url.Parse(srv.URL)
+	_, port, _ := net.SplitHostPort(u.Host)
+
+	cases := []struct {
+		name    string
+		addr    string
+		allowed []string
+		wantErr bool
+	}{
+		{"未放行的回环地址", u.Host, nil, true},
+		{"按 IP 放行", u.Host, []string{"127.0.0.1"}, false},
+		{"按网段放行", u.Host, []string{"127.0.0.0/8"}, false},
+		{"按主机名放行", net.JoinHostPort("localhost", port), []string{"localhost"}, false},
+		{"主机名解析到内网且未放行", net.JoinHostPort("localhost", port), []string{"10.0.0.0/8"}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			conn, err := SafeDialer(tc.allowed)(context.Background(), "tcp", tc.addr)
+			if tc.wantErr {
+				if !errors.Is(err, ErrUnsafeOutboundAddress) {
+					t.Fatalf("err = %v，期望 ErrUnsafeOutboundAddress", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SafeDialer: %v", err)
+			}
+			conn.Close()
+		})
+	}
+}
+
+func TestEVMAdapterDialsThroughSafeDialer(t *testing.T) {
+	srv := newLoopbackNode(t)
+	for _, fallbacks := range [][]string{nil, {srv.URL + "/fallback"}} {
+		adapter, err := newEVMAdapter(srv.URL, fallbacks, SafeDialer(nil))
+		if err != nil {
+			t.Fatalf("newEVMAdapter: %v", err)
+		}
+		_, err = adapter.GetBalance(context.Background(), "0x0000000000000000000000000000000000000001")
+		adapter.Close()
+		if !errors.Is(err, ErrUnsafeOutboundAddress) {
+			t.Fatalf("备用节点 %v: err = %v，期望拒绝连接回环地址", fallbacks, err)
+		}
+
+		adapter, err = newEVMAdapter(srv.URL, fallbacks, SafeDialer([]string{"127.0.0.1"}))
+		if err != nil {
+			t.Fatalf("newEVMAdapter: %v", err)
+		}
+		balance, err := adapter.GetBalance(context.Background(), "0x0000000000000000000000000000000000000001")
+		adapter.Close()
+		if err != nil || balance.Int64() != 100 {
+			t.Fatalf("备用节点 %v: 放行后 GetBalance = %v, %v", fallbacks, balance, err)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+/*
+EIP-681 支付链接
+
+支持“请求付款”与扫码付款两种场景：
+- 原生币转账：ethereum:<to>[@chainId][?value=<wei>]
+- ERC20 转账：ethereum:<token>[@chainId]/transfer?address=<to>[&uint256=<amount>]
+- 兼容 pay- 前缀；数值支持十进制整数与科学计数法（如 1.5e18），结果必须为非负整数
+- 收款方与代币仅支持 0x 地址，ENS 名称需由调用方先行解析
+*/
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PaymentURIScheme EIP-681 链接前缀
+const PaymentURIScheme = "ethereum:"
+
+// PaymentRequest 解析后的支付请求
+type PaymentRequest struct {
+	Recipient string   // 收款地址（校验和格式）
+	ChainID   int64    // 链ID，0 表示链接未指定
+	Token     string   // ERC20 代币地址，为空表示原生币
+	Amount    *big.Int // 金额（wei 或代币最小单位），nil 表示未指定
+	GasLimit  uint64   // 建议 Gas 上限，0 表示未指定
+	GasPrice  *big.Int // 建议 Gas 价格，nil 表示未指定
+}
+
+// BuildPaymentURI 构建 EIP-681 支付链接
+// 调用方需保证地址合法；未指定链ID、金额时省略对应部分
+func BuildPaymentURI(req *PaymentRequest) string {
+	recipient := common.HexToAddress(req.Recipient).Hex()
+
+	var target, path string
+	query := url.Values{}
+	if req.Token == "" {
+		target = recipient
+		if req.Amount != nil {
+			query.Set("value", req.Amount.String())
+		}
+	} else {
+		target, path = common.HexToAddress(req.Token).Hex(), "/transfer"
+		query.Set("address", recipient)
+		if req.Amount != nil {
+			query.Set("uint256", req.Amount.String())
+		}
+	}
+	if req.GasLimit > 0 {
+		query.Set("gasLimit", strconv.FormatUint(req.GasLimit, 10))
+	}
+	if req.GasPrice != nil {
+		query.Set("gasPrice", req.GasPrice.String())
+	}
+
+	uri := PaymentURIScheme + target
+	if req.ChainID > 0 {
+		uri += fmt.Sprintf("@%d", req.ChainID)
+	}
+	uri += path
+	if len(query) > 0 {
+		uri += "?" + query.Encode()
+	}
+	return uri
+}
+
+// ParsePaymentURI 解析 EIP-681 支付链接，仅支持原生币转账与 ERC20 transfer
+func ParsePaymentURI(uri string) (*PaymentRequest, error) {
+	uri = strings.TrimSpace(uri)
+	if len(uri) < len(PaymentURIScheme) || !strings.EqualFold(uri[:len(PaymentURIScheme)], PaymentURIScheme) {
+		return nil, fmt.Errorf("支付链接需以 %s 开头", PaymentURIScheme)
+	}
+	rest := uri[len(PaymentURIScheme):]
+	rest = strings.TrimPrefix(rest, "pay-")
+
+	rawQuery := ""
+	if i := strings.IndexByte(rest, '?'); i >= 0 {
+		rest, rawQuery = rest[:i], rest[i+1:]
+	}
+	function := ""
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		rest, function = rest[:i], rest[i+1:]
+	}
+	target := rest
+	req := &PaymentRequest{}
+	if i := strings.IndexByte(rest, '@'); i >= 0 {
+		target = rest[:i]
+		chainID, err := strconv.ParseInt(rest[i+1:], 10, 64)
+		if err != nil || chainID <= 0 {
+			return nil, fmt.Errorf("支付链接链ID格式不正确: %s", rest[i+1:])
+		}
+		req.ChainID = chainID
+	}
+	if !common.IsHexAddress(target) {
+		return nil, fmt.Errorf("支付链接目标地址格式不正确: %s", target)
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("支付链接参数格式不正确: %w", err)
+	}
+
+	switch function {
+	case "":
+		req.Recipient = common.HexToAddress(target).Hex()
+		if req.Amount, err = paymentNumberParam(query, "value"); err != nil {
+			return nil, err
+		}
+	case "transfer":
+		req.Token = common.HexToAddress(target).Hex()
+		to := query.Get("address")
+		if !common.IsHexAddress(to) {
+			return nil, fmt.Errorf("支付链接收款地址格式不正确: %s", to)
+		}
+		req.Recipient = common.HexToAddress(to).Hex()
+		if req.Amount, err = paymentNumberParam(query, "uint256"); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("不支持的支付链接方法: %s", function)
+	}
+
+	gasLimit, err := paymentNumberParam(query, "gasLimit", "gas")
+	if err != nil {
+		return nil, err
+	}
+	if gasLimit != nil {
+		if !gasLimit.IsUint64() {
+			return nil, fmt.Errorf("支付链接 gasLimit 超出范围")
+		}
+		req.GasLimit = gasLimit.Uint64()
+	}
+	if req.GasPrice, err = paymentNumberParam(query, "gasPrice"); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// paymentNumberParam 读取第一个出现的数值参数，均未出现时返回 nil
+func paymentNumberParam(query url.Values, names ...string) (*big.Int, error) {
+	for _, name := range names {
+		if raw := query.Get(name); raw != "" {
+			n, err := parsePaymentNumber(raw)
+			if err != nil {
+				return nil, fmt.Errorf("支付链接参数 %s 格式不正确: %w", name, err)
+			}
+			return n, nil
+		}
+	}
+	return nil, nil
+}
+
+// parsePaymentNumber 解析 EIP-681 数值：十进制整数或科学计数法，结果必须为非负整数
+func parsePaymentNumber(raw string) (*big.Int, error) {
+	mantissa, exponent := raw, 0
+	if i := strings.IndexAny(raw, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(raw[i+1:])
+		if err != nil || exp < 0 || exp > 77 {
+			return nil, fmt.Errorf("指数不正确: %s", raw)
+		}
+		mantissa, exponent = raw[:i], exp
+	}
+	intPart, fracPart := mantissa, ""
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		intPart, fracPart = mantissa[:i], mantissa[i+1:]
+	}
+	if intPart == "" && fracPart == "" {
+		return nil, fmt.Errorf("不是数字: %s", raw)
+	}
+	for _, ch := range intPart + fracPart {
+		if ch < '0' || ch > '9' {
+			return nil, fmt.Errorf("不是非负整数: %s", raw)
+		}
+	}
+	// 小数位必须被指数完全吸收，且吸收后不留非零尾数
+	trimmed := strings.TrimRight(fracPart, "0")
+	if len(trimmed) > exponent {
+		return nil, fmt.Errorf("不是整数: %s", raw)
+	}
+	digits := intPart + trimmed + strings.Repeat("0", exponent-len(trimmed))
+	n, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("不是数字: %s", raw)
+	}
+	return n, nil
+}
@@ -0,0 +1,257 @@
+/*
+EIP-2612 Permit 签名
+
+支持 permit 的 ERC20 代币允许持有者链下签署授权，由任意一方提交 permit() 上链，免去单独的 approve 交易：
+- 通过探测 nonces(owner) 与 DOMAIN_SEPARATOR() 判断代币是否支持 permit
+- 读取 name()/version() 还原 EIP-712 域，并与链上 DOMAIN_SEPARATOR 比对，不一致时拒绝签名（避免签出无效签名）
+- PERMIT_TYPEHASH 与标准不同的代币（如 DAI 的 allowed 形式）视为不支持
+- PermitMulticallData 将 permit() 与后续调用打包为 Multicall3 aggregate3 调用数据，供花费方一次提交
+*/
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// ErrPermitNotSupported 代币未实现 EIP-2612 permit
+var ErrPermitNotSupported = errors.New("该代币不支持 EIP-2612 permit")
+
+const permitABI = `[{"inputs":[{"name":"owner","type":"address"}],"name":"nonces","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"DOMAIN_SEPARATOR","outputs":[{"name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"PERMIT_TYPEHASH","outputs":[{"name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"name","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"version","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},{"inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"},{"name":"value","type":"uint256"},{"name":"deadline","type":"uint256"},{"name":"v","type":"uint8"},{"name":"r","type":"bytes32"},{"name":"s","type":"bytes32"}],"name":"permit","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
+
+// standardPermitTypeHash EIP-2612 标准 Permit 类型哈希
+var standardPermitTypeHash = crypto.Keccak256Hash([]byte("Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)"))
+
+// PermitSignature permit 签名及其参数
+type PermitSignature struct {
+	Token    string `json:"token"`     // 代币合约
+	Owner    string `json:"owner"`     // 授权人
+	Spender  string `json:"spender"`   // 被授权地址
+	Value    string `json:"value"`     // 授权额度（最小单位）
+	Nonce    string `json:"nonce"`     // 签名时的 permit nonce
+	Deadline int64  `json:"deadline"`  // 签名截止时间（Unix 秒）
+	V        uint8  `json:"v"`         // 27/28
+	R        string `json:"r"`         // 0x 开头的32字节
+	S        string `json:"s"`         // 0x 开头的32字节
+	Sig      string `json:"signature"` // 65字节 r||s||v
+}
+
+// SignPermit 使用助记词派生的私钥签署 permit，返回 v/r/s
+func (a *EVMAdapter) SignPermit(ctx context.Context, mnemonic, derivationPath, token, spender string, value *big.Int, deadline int64) (uint8, [32]byte, [32]byte, error) {
+	signer, err := NewMnemonicSigner(mnemonic, derivationPath)
+	if err != nil {
+		return 0, [32]byte{}, [32]byte{}, err
+	}
+	permit, err := a.SignPermitWithSigner(ctx, signer, token, spender, value, deadline)
+	if err != nil {
+		return 0, [32]byte{}, [32]byte{}, err
+	}
+	var r, s [32]byte
+	copy(r[:], common.FromHex(permit.R))
+	copy(s[:], common.FromHex(permit.S))
+	return permit.V, r, s, nil
+}
+
+// SignPermitWithSigner 读取代币的 EIP-712 域与 nonce，使用 signer 签署 permit
+func (a *EVMAdapter) SignPermitWithSigner(ctx context.Context, signer Signer, token, spender string, value *big.Int, deadline int64) (*PermitSignature, error) {
+	if !common.IsHexAddress(token) {
+		return nil, fmt.Errorf("代币地址格式不正确: %s", token)
+	}
+	if !common.IsHexAddress(spender) {
+		return nil, fmt.Errorf("spender 地址格式不正确: %s", spender)
+	}
+	if value == nil || value.Sign() < 0 {
+		return nil, fmt.Errorf("授权额度无效")
+	}
+	if deadline <= 0 {
+		return nil, fmt.Errorf("deadline 无效")
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(permitABI))
+	if err != nil {
+		return nil, err
+	}
+	tokenAddr := common.HexToAddress(token)
+	owner := signer.Address()
+
+	// 探测 permit 支持：nonces 与 DOMAIN_SEPARATOR 均可调用
+	nonceOut, err := a.callView(ctx, tokenAddr, parsed, "nonces", owner)
+	if err != nil {
+		return nil, ErrPermitNotSupported
+	}
+	nonce, ok := nonceOut[0].(*big.Int)
+	if !ok {
+		return nil, ErrPermitNotSupported
+	}
+	sepOut, err := a.callView(ctx, tokenAddr, parsed, "DOMAIN_SEPARATOR")
+	if err != nil {
+		return nil, ErrPermitNotSupported
+	}
+	onchainSeparator, ok := sepOut[0].([32]byte)
+	if !ok {
+		return nil, ErrPermitNotSupported
+	}
+	if out, err := a.callView(ctx, tokenAddr, parsed, "PERMIT_TYPEHASH"); err == nil {
+		if typeHash, ok := out[0].([32]byte); ok && common.Hash(typeHash) != standardPermitTypeHash {
+			return nil, fmt.Errorf("%w（PERMIT_TYPEHASH 非标准）", ErrPermitNotSupported)
+		}
+	}
+
+	chainID, err := a.client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链ID失败: %w", err)
+	}
+	domain, err := a.permitDomain(ctx, parsed, tokenAddr, chainID, onchainSeparator)
+	if err != nil {
+		return nil, err
+	}
+
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Permit": {
+				{Name: "owner", Type: "address"},
+				{Name: "spender", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain:      domain,
+		Message: apitypes.TypedDataMessage{
+			"owner":    owner.Hex(),
+			"spender":  common.HexToAddress(spender).Hex(),
+			"value":    value.String(),
+			"nonce":    nonce.String(),
+			"deadline": big.NewInt(deadline).String(),
+		},
+	}
+	msgHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		return nil, fmt.Errorf("计算 permit 哈希失败: %w", err)
+	}
+	digest := crypto.Keccak256Hash([]byte{0x19, 0x01}, onchainSeparator[:], msgHash)
+
+	sig, err := signer.SignHash(digest.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return &PermitSignature{
+		Token:    tokenAddr.Hex(),
+		Owner:    owner.Hex(),
+		Spender:  common.HexToAddress(spender).Hex(),
+		Value:    value.String(),
+		Nonce:    nonce.String(),
+		Deadline: deadline,
+		V:        sig[64],
+		R:        hexutil.Encode(sig[:32]),
+		S:        hexutil.Encode(sig[32:64]),
+		Sig:      hexutil.Encode(sig),
+	}, nil
+}
+
+// permitDomain 还原代币的 EIP-712 域，并校验与链上 DOMAIN_SEPARATOR 一致
+// 未实现 version() 的代币依次尝试常见版本 "1"、"2"
+func (a *EVMAdapter) permitDomain(ctx context.Context, parsed abi.ABI, token common.Address, chainID *big.Int, onchain [32]byte) (apitypes.TypedDataDomain, error) {
+	nameOut, err := a.callView(ctx, token, parsed, "name")
+	if err != nil {
+		return apitypes.TypedDataDomain{}, fmt.Errorf("读取代币名称失败: %w", err)
+	}
+	name, _ := nameOut[0].(string)
+
+	versions := []string{"1", "2"}
+	if out, err := a.callView(ctx, token, parsed, "version"); err == nil {
+		if v, ok := out[0].(string); ok && v != "" {
+			versions = []string{v}
+		}
+	}
+
+	domainTypes := apitypes.Types{
+		"EIP712Domain": {
+			{Name: "name", Type: "string"},
+			{Name: "version", Type: "string"},
+			{Name: "chainId", Type: "uint256"},
+			{Name: "verifyingContract", Type: "address"},
+		},
+	}
+	for _, version := range versions {
+		domain := apitypes.TypedDataDomain{
+			Name:              name,
+			Version:           version,
+			ChainId:           (*math.HexOrDecimal256)(chainID),
+			VerifyingContract: token.Hex(),
+		}
+		td := apitypes.TypedData{Types: domainTypes, Domain: domain}
+		separator, err := td.HashStruct("EIP712Domain", domain.Map())
+		if err != nil {
+			return apitypes.TypedDataDomain{}, fmt.Errorf("计算domainSeparator失败: %w", err)
+		}
+		if bytes.Equal(separator, onchain[:]) {
+			return domain, nil
+		}
+	}
+	return apitypes.TypedDataDomain{}, fmt.Errorf("%w（无法还原 EIP-712 域，DOMAIN_SEPARATOR 不匹配）", ErrPermitNotSupported)
+}
+
+// PermitCallData 构造 permit(owner, spender, value, deadline, v, r, s) 调用数据
+func PermitCallData(permit *PermitSignature) ([]byte, error) {
+	parsed, err := abi.JSON(strings.NewReader(permitABI))
+	if err != nil {
+		return nil, err
+	}
+	value, ok := new(big.Int).SetString(permit.Value, 10)
+	if !ok {
+		return nil, fmt.Errorf("授权额度无效: %s", permit.Value)
+	}
+	var r, s [32]byte
+	copy(r[:], common.FromHex(permit.R))
+	copy(s[:], common.FromHex(permit.S))
+	return parsed.Pack("permit",
+		common.HexToAddress(permit.Owner),
+		common.HexToAddress(permit.Spender),
+		value,
+		big.NewInt(permit.Deadline),
+		permit.V, r, s,
+	)
+}
+
+// PermitMulticallData 将 permit() 与后续调用（如 transferFrom、兑换）打包为 Multicall3 aggregate3 调用数据
+// permit 可由任意地址提交；后续调用以 Multicall3 为 msg.sender 执行，需由花费方按自身合约语义构造
+func PermitMulticallData(permit *PermitSignature, calls []MulticallRequest) ([]byte, error) {
+	permitData, err := PermitCallData(permit)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := abi.JSON(strings.NewReader(multicall3ABI))
+	if err != nil {
+		return nil, fmt.Errorf("解析Multicall3 ABI失败: %w", err)
+	}
+	packed := make([]multicall3Call, 0, len(calls)+1)
+	packed = append(packed, multicall3Call{Target: common.HexToAddress(permit.Token), CallData: permitData})
+	for _, c := range calls {
+		packed = append(packed, multicall3Call{Target: c.Target, AllowFailure: c.AllowFailure, CallData: c.CallData})
+	}
+	data, err := parsed.Pack("aggregate3", packed)
+	if err != nil {
+		return nil, fmt.Errorf("打包aggregate3数据失败: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,34 @@
+/*
+第三方服务密钥上下文
+
+用户可以配置自己的第三方API密钥（OpenSea/Alchemy/CoinGecko/Etherscan 等）。
+服务层在请求开始时将当前用户的密钥放入 context，核心层发起外部请求时优先使用，
+未配置时再回退到全局配置的密钥。
+*/
+package core
+
+import "context"
+
+// providerKeysCtxKey context中存放用户密钥的键
+type providerKeysCtxKey struct{}
+
+// WithProviderKeys 将用户的第三方服务密钥附加到context
+// keys: provider -> apiKey（provider 统一使用小写，如 opensea、coingecko）
+func WithProviderKeys(ctx context.Context, keys map[string]string) context.Context {
+	if len(keys) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, providerKeysCtxKey{}, keys)
+}
+
+// ProviderKeyFromContext 读取context中用户配置的第三方服务密钥，未配置返回空字符串
+func ProviderKeyFromContext(ctx context.Context, provider string) string {
+	if ctx == nil {
+		return ""
+	}
+	keys, ok := ctx.Value(providerKeysCtxKey{}).(map[string]string)
+	if !ok {
+		return ""
+	}
+	return keys[provider]
+}
@@ -0,0 +1,79 @@
+/*
+二维码生成
+
+用于分享记录与收款地址的二维码（PNG）：
+- 尺寸与纠错级别可配置（config.yaml 的 qr_code 段），请求可在允许范围内覆盖
+- 收款二维码内容为 EIP-681 支付链接（见 payment_uri.go），钱包扫码后可直接填充收款地址、网络与金额
+*/
+package core
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"wallet/config"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// 二维码尺寸下限（像素），过小时扫码设备无法识别
+const MinQRCodeSize = 64
+
+// QRCodeOptions 二维码生成参数，零值字段使用配置默认值
+type QRCodeOptions struct {
+	Size          int    // 图片边长（像素）
+	RecoveryLevel string // 纠错级别：low / medium / high / highest
+}
+
+// GenerateQRCode 使用默认尺寸与纠错级别生成二维码（PNG）
+func GenerateQRCode(data string) ([]byte, error) {
+	return GenerateQRCodeWithOptions(data, QRCodeOptions{})
+}
+
+// GenerateQRCodeWithOptions 按指定尺寸与纠错级别生成二维码（PNG）
+func GenerateQRCodeWithOptions(data string, opts QRCodeOptions) ([]byte, error) {
+	if data == "" {
+		return nil, fmt.Errorf("二维码内容不能为空")
+	}
+	cfg := config.AppConfig.QRCode.WithDefaults()
+	size := opts.Size
+	if size == 0 {
+		size = cfg.DefaultSize
+	}
+	if size < MinQRCodeSize || size > cfg.MaxSize {
+		return nil, fmt.Errorf("二维码尺寸需在 %d~%d 像素之间", MinQRCodeSize, cfg.MaxSize)
+	}
+	levelName := opts.RecoveryLevel
+	if levelName == "" {
+		levelName = cfg.RecoveryLevel
+	}
+	level, err := ParseQRRecoveryLevel(levelName)
+	if err != nil {
+		return nil, err
+	}
+	png, err := qrcode.Encode(data, level, size)
+	if err != nil {
+		return nil, fmt.Errorf("生成二维码失败: %w", err)
+	}
+	return png, nil
+}
+
+// ParseQRRecoveryLevel 解析纠错级别（low/medium/high/highest，也接受 L/M/Q/H）
+func ParseQRRecoveryLevel(level string) (qrcode.RecoveryLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "low", "l":
+		return qrcode.Low, nil
+	case "medium", "m":
+		return qrcode.Medium, nil
+	case "high", "q":
+		return qrcode.High, nil
+	case "highest", "h":
+		return qrcode.Highest, nil
+	}
+	return qrcode.Medium, fmt.Errorf("不支持的二维码纠错级别: %s", level)
+}
+
+// QRCodeDataURL 将 PNG 图片编码为 data URL，可直接用于 <img src>
+func QRCodeDataURL(png []byte) string {
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(png)
+}
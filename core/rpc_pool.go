@@ -0,0 +1,377 @@
+/*
+RPC 节点池（故障转移与健康检查）
+
+网络配置了多个 http(s) RPC 地址时，EVMAdapter 的 ethclient 底层使用 rpcPool 作为 HTTP Transport：
+  - 每个 JSON-RPC 请求优先发往当前选中的节点；连接失败、5xx 或 429 时在同一请求内依次重试其他节点，
+    调用方不会因为首个节点故障而收到连接错误
+  - 节点连续失败达到阈值后标记为不健康，排到候选列表末尾（所有节点都不健康时仍会尝试）
+  - 后台定期以 eth_blockNumber 检查所有节点，恢复响应的节点重新标记为健康；
+    在区块落后不超过 MaxBlockLag 的健康节点中选择延迟最低的作为当前节点
+*/
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"wallet/config"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// rpcHealthCheckTimeout 单个节点健康检查的超时时间
+const rpcHealthCheckTimeout = 5 * time.Second
+
+// RPCEndpointStatus RPC 节点状态
+type RPCEndpointStatus struct {
+	URL                 string    `json:"url"`                    // 节点地址（路径与参数已脱敏）
+	Active              bool      `json:"active"`                 // 是否为当前优先使用的节点
+	Healthy             bool      `json:"healthy"`                // 是否健康
+	LatestBlock         uint64    `json:"latest_block"`           // 最近一次检查到的区块高度
+	LatencyMs           int64     `json:"latency_ms"`             // 最近一次检查的响应延迟（毫秒）
+	ConsecutiveFailures int       `json:"consecutive_failures"`   // 连续失败次数
+	LastError           string    `json:"last_error,omitempty"`   // 最近一次错误
+	LastChecked         time.Time `json:"last_checked,omitempty"` // 最近一次检查时间
+}
+
+// rpcEndpoint 节点池中的单个节点
+type rpcEndpoint struct {
+	url         *url.URL
+	healthy     bool
+	failures    int
+	latestBlock uint64
+	latency     time.Duration
+	lastError   string
+	lastChecked time.Time
+}
+
+// rpcPool 多节点 HTTP Transport，实现 http.RoundTripper
+type rpcPool struct {
+	mu        sync.RWMutex
+	endpoints []*rpcEndpoint
+	active    int
+	cfg       config.RPCPoolConfig
+	base      http.RoundTripper
+	stop      chan struct{}
+	stopOnce  sync.Once
+}
+
+// newRPCPool 创建节点池，rpcURLs 必须均为 http(s) 地址
+func newRPCPool(rpcURLs []string, cfg config.RPCPoolConfig) (*rpcPool, error) {
+	if len(rpcURLs) == 0 {
+		return nil, errors.New("RPC 地址不能为空")
+	}
+	p := &rpcPool{
+		cfg:  cfg.WithDefaults(),
+		base: http.DefaultTransport,
+		stop: make(chan struct{}),
+	}
+	for _, raw := range rpcURLs {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return nil, fmt.Errorf("RPC 节点池仅支持 http(s) 地址: %s", raw)
+		}
+		p.endpoints = append(p.endpoints, &rpcEndpoint{url: u, healthy: true})
+	}
+	return p, nil
+}
+
+// RoundTrip 将请求发往优先节点，失败时依次重试其他节点
+func (p *rpcPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+
+	candidates := p.candidates()
+	var lastErr error
+	for n, idx := range candidates {
+		ep := p.endpoints[idx]
+		attempt := req.Clone(req.Context())
+		attempt.URL = ep.url
+		attempt.Host = ""
+		if ep.url.User != nil && attempt.Header.Get("Authorization") == "" {
+			password, _ := ep.url.User.Password()
+			attempt.SetBasicAuth(ep.url.User.Username(), password)
+		}
+		attempt.Body = io.NopCloser(bytes.NewReader(body))
+		attempt.ContentLength = int64(len(body))
+
+		resp, err := p.base.RoundTrip(attempt)
+		if err != nil {
+			// 调用方取消或超时，不再重试
+			if ctxErr := req.Context().Err(); ctxErr != nil {
+				return nil, err
+			}
+			p.recordFailure(idx, err.Error())
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+			p.recordFailure(idx, resp.Status)
+			if n < len(candidates)-1 {
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				continue
+			}
+			return resp, nil
+		}
+		p.recordSuccess(idx)
+		return resp, nil
+	}
+	return nil, fmt.Errorf("所有RPC节点均不可用: %w", lastErr)
+}
+
+// candidates 返回本次请求的节点尝试顺序：健康节点在前（当前节点优先），不健康节点在后
+func (p *rpcPool) candidates() []int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	order := make([]int, 0, len(p.endpoints))
+	var unhealthy []int
+	add := func(i int) {
+		if p.endpoints[i].healthy {
+			order = append(order, i)
+		} else {
+			unhealthy = append(unhealthy, i)
+		}
+	}
+	add(p.active)
+	for i := range p.endpoints {
+		if i != p.active {
+			add(i)
+		}
+	}
+	return append(order, unhealthy...)
+}
+
+// recordFailure 记录请求失败，连续失败达到阈值时标记不健康并重新选择当前节点
+func (p *rpcPool) recordFailure(idx int, reason string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ep := p.endpoints[idx]
+	ep.failures++
+	ep.lastError = reason
+	if ep.failures >= p.cfg.FailureThreshold && ep.healthy {
+		ep.healthy = false
+		if idx == p.active {
+			p.selectLocked()
+		}
+	}
+}
+
+// recordSuccess 记录请求成功
+func (p *rpcPool) recordSuccess(idx int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ep := p.endpoints[idx]
+	ep.failures = 0
+	if !ep.healthy {
+		ep.healthy = true
+		ep.lastError = ""
+	}
+}
+
+// selectLocked 在区块落后不超过 MaxBlockLag 的健康节点中选择延迟最低的节点
+// 没有健康节点时保持当前选择
+func (p *rpcPool) selectLocked() {
+	var maxHead uint64
+	for _, ep := range p.endpoints {
+		if ep.healthy && ep.latestBlock > maxHead {
+			maxHead = ep.latestBlock
+		}
+	}
+	best := -1
+	for i, ep := range p.endpoints {
+		if !ep.healthy {
+			continue
+		}
+		if ep.latestBlock+p.cfg.MaxBlockLag < maxHead {
+			continue
+		}
+		if best < 0 || ep.latency < p.endpoints[best].latency {
+			best = i
+		}
+	}
+	if best >= 0 {
+		p.active = best
+	}
+}
+
+// checkAll 并发检查所有节点的 eth_blockNumber，更新状态并重新选择当前节点
+func (p *rpcPool) checkAll(ctx context.Context) {
+	type result struct {
+		block   uint64
+		latency time.Duration
+		err     error
+	}
+	results := make([]result, len(p.endpoints))
+	var wg sync.WaitGroup
+	for i, ep := range p.endpoints {
+		wg.Add(1)
+		go func(i int, u *url.URL) {
+			defer wg.Done()
+			start := time.Now()
+			block, err := p.blockNumber(ctx, u)
+			results[i] = result{block: block, latency: time.Since(start), err: err}
+		}(i, ep.url)
+	}
+	wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	for i, r := range results {
+		ep := p.endpoints[i]
+		ep.lastChecked = now
+		if r.err != nil {
+			ep.failures++
+			ep.healthy = false
+			ep.lastError = r.err.Error()
+			continue
+		}
+		ep.failures = 0
+		ep.healthy = true
+		ep.lastError = ""
+		ep.latestBlock = r.block
+		ep.latency = r.latency
+	}
+	p.selectLocked()
+}
+
+// blockNumber 直接向指定节点请求 eth_blockNumber（不经过故障转移）
+func (p *rpcPool) blockNumber(ctx context.Context, u *url.URL) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, rpcHealthCheckTimeout)
+	defer cancel()
+	payload := []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if u.User != nil {
+		password, _ := u.User.Password()
+		req.SetBasicAuth(u.User.Username(), password)
+	}
+	resp, err := p.base.RoundTrip(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("HTTP %s", resp.Status)
+	}
+	var out struct {
+		Result string `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return 0, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if out.Error != nil {
+		return 0, errors.New(out.Error.Message)
+	}
+	return hexutil.DecodeUint64(out.Result)
+}
+
+// start 启动后台健康检查
+func (p *rpcPool) start() {
+	interval := time.Duration(p.cfg.HealthCheckIntervalSeconds) * time.Second
+	go func() {
+		p.checkAll(context.Background())
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.checkAll(context.Background())
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// close 停止后台健康检查
+func (p *rpcPool) close() {
+	p.stopOnce.Do(func() { close(p.stop) })
+}
+
+// statuses 返回各节点状态（按配置顺序）
+func (p *rpcPool) statuses() []RPCEndpointStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	out := make([]RPCEndpointStatus, len(p.endpoints))
+	for i, ep := range p.endpoints {
+		out[i] = RPCEndpointStatus{
+			URL:                 maskRPCURL(ep.url),
+			Active:              i == p.active,
+			Healthy:             ep.healthy,
+			LatestBlock:         ep.latestBlock,
+			LatencyMs:           ep.latency.Milliseconds(),
+			ConsecutiveFailures: ep.failures,
+			LastError:           ep.lastError,
+			LastChecked:         ep.lastChecked,
+		}
+	}
+	return out
+}
+
+// maskRPCURL 隐藏RPC地址中的路径、参数与认证信息（常含API密钥），仅保留协议与主机
+func maskRPCURL(u *url.URL) string {
+	masked := u.Scheme + "://" + u.Host
+	if strings.Trim(u.Path, "/") != "" || u.RawQuery != "" || u.User != nil {
+		masked += "/***"
+	}
+	return masked
+}
+
+// rpcEndpointURLs 合并主节点与备用节点地址（去重、去空，保持顺序）
+func rpcEndpointURLs(primary string, fallbacks []string) []string {
+	seen := make(map[string]bool)
+	var urls []string
+	for _, raw := range append([]string{primary}, fallbacks...) {
+		raw = strings.TrimSpace(raw)
+		if raw == "" || seen[raw] {
+			continue
+		}
+		seen[raw] = true
+		urls = append(urls, raw)
+	}
+	return urls
+}
+
+// singleEndpointStatus 未启用节点池的适配器（单节点或 ws 地址）检测一次并返回状态
+func singleEndpointStatus(ctx context.Context, a *EVMAdapter, rawURL string) []RPCEndpointStatus {
+	status := RPCEndpointStatus{Active: true, LastChecked: time.Now()}
+	if u, err := url.Parse(rawURL); err == nil {
+		status.URL = maskRPCURL(u)
+	}
+	ctx, cancel := context.WithTimeout(ctx, rpcHealthCheckTimeout)
+	defer cancel()
+	start := time.Now()
+	block, err := a.client.BlockNumber(ctx)
+	status.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		status.LastError = err.Error()
+		status.ConsecutiveFailures = 1
+		return []RPCEndpointStatus{status}
+	}
+	status.Healthy = true
+	status.LatestBlock = block
+	return []RPCEndpointStatus{status}
+}
@@ -51,12 +51,13 @@ package core
 import (
 	"context"
 	"crypto/rand"
-	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"math/big"
 	"sync"
 	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 // AdvancedSecurityManager 高级安全管理器
@@ -67,6 +68,9 @@ type AdvancedSecurityManager struct {
 	keyManager       *KeyManager                // 密钥管理器
 	auditLogger      *AuditLogger               // 审计日志
 	securityPolicies map[string]*SecurityPolicy // 安全策略
+	multiChain       *MultiChainManager         // 多链管理器，用于多签交易链上执行
+	anomalyDetector  *AnomalyDetector           // 异常登录/交易检测
+	addressBlocklist *AddressBlocklist          // 全局收款地址黑名单
 	mu               sync.RWMutex               // 读写锁
 }
 
@@ -200,9 +204,12 @@ type NotificationPreferences struct {
 // AuthenticationManager 认证管理器
 type AuthenticationManager struct {
 	mfaProviders   map[string]*MFAProvider     // MFA提供者
+	pendingTOTP    map[string]*MFAProvider     // 待首次验证的TOTP密钥（验证成功前已启用的密钥继续有效）
+	mfaFailures    map[string]*mfaFailureState // 验证码连续失败计数（锁定用）
 	deviceRegistry map[string]*TrustedDevice   // 可信设备注册表
 	sessions       map[string]*SecuritySession // 安全会话
 	biometrics     *BiometricManager           // 生物识别管理器
+	totpStore      TOTPStore                   // TOTP 配置持久化存储，为空时只保存在内存中
 	mu             sync.RWMutex                // 读写锁
 }
 
@@ -338,9 +345,14 @@ type EmergencyAccess struct {
 }
 
 // AuditLogger 审计日志
+// 设置了持久化存储时逐条写穿到存储，内存中只保留最近的日志作为缓存
 type AuditLogger struct {
-	logs      []AuditLog    // 日志记录
+	logs      []AuditLog    // 日志记录（按时间顺序）
 	retention time.Duration // 保留期限
+	capacity  int           // 内存缓存上限
+	store     AuditLogStore // 持久化存储，可为空
+	lastPurge time.Time     // 上次清理存储中过期日志的时间
+	purging   bool          // 是否正在清理存储
 	mu        sync.RWMutex  // 读写锁
 }
 
@@ -351,6 +363,7 @@ type AuditLog struct {
 	UserAddress   string                 `json:"user_address"`   // 用户地址
 	Action        string                 `json:"action"`         // 操作
 	Resource      string                 `json:"resource"`       // 资源
+	ResourceID    string                 `json:"resource_id"`    // 资源ID
 	Result        string                 `json:"result"`         // 结果
 	IPAddress     string                 `json:"ip_address"`     // IP地址
 	UserAgent     string                 `json:"user_agent"`     // 用户代理
@@ -384,14 +397,17 @@ type AdvancedSecurityRule struct {
 }
 
 // NewAdvancedSecurityManager 创建高级安全管理器
-func NewAdvancedSecurityManager() *AdvancedSecurityManager {
+func NewAdvancedSecurityManager(multiChain *MultiChainManager) *AdvancedSecurityManager {
 	return &AdvancedSecurityManager{
+		multiChain:       multiChain,
 		hardwareWallets:  make(map[string]*HardwareWallet),
 		multiSigWallets:  make(map[string]*MultiSigWallet),
 		authManager:      NewAuthenticationManager(),
 		keyManager:       NewKeyManager(),
 		auditLogger:      NewAuditLogger(),
 		securityPolicies: make(map[string]*SecurityPolicy),
+		anomalyDetector:  NewAnomalyDetector(),
+		addressBlocklist: NewAddressBlocklist(),
 	}
 }
 
@@ -421,20 +437,33 @@ func (sm *AdvancedSecurityManager) DetectHardwareWallets(ctx context.Context) ([
 	return wallets, nil
 }
 
-// CreateMultiSigWallet 创建多签钱包
-func (sm *AdvancedSecurityManager) CreateMultiSigWallet(ctx context.Context, config *MultiSigConfig, signers []MultiSigSigner, threshold int) (*MultiSigWallet, error) {
+// CreateMultiSigWallet 登记已部署的 Safe 多签钱包
+// Safe 合约的链上阈值须与 threshold 一致，且每个签名者都须是合约的 owner
+func (sm *AdvancedSecurityManager) CreateMultiSigWallet(ctx context.Context, name, chainID, safeAddress, createdBy string, config *MultiSigConfig, signers []MultiSigSigner, threshold int) (*MultiSigWallet, error) {
 	if threshold < 1 || threshold > len(signers) {
 		return nil, fmt.Errorf("无效的签名阈值")
 	}
+	if !common.IsHexAddress(safeAddress) {
+		return nil, fmt.Errorf("无效的Safe合约地址: %s", safeAddress)
+	}
+	safe := common.HexToAddress(safeAddress)
+	if err := sm.verifySafeOwners(ctx, chainID, safe, signers, threshold); err != nil {
+		return nil, err
+	}
 
 	walletID := sm.generateWalletID()
 	wallet := &MultiSigWallet{
-		ID:            walletID,
-		Threshold:     threshold,
-		Signers:       signers,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
-		Configuration: *config,
+		ID:              walletID,
+		Name:            name,
+		Address:         safe.Hex(),
+		ContractAddress: safe.Hex(),
+		ChainID:         chainID,
+		Threshold:       threshold,
+		Signers:         signers,
+		CreatedBy:       createdBy,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+		Configuration:   *config,
 		SecuritySettings: MultiSigSecurity{
 			RequireMFA:        true,
 			SessionTimeout:    30 * time.Minute,
@@ -449,7 +478,10 @@ func (sm *AdvancedSecurityManager) CreateMultiSigWallet(ctx context.Context, con
 	sm.mu.Unlock()
 
 	// 记录审计日志
-	sm.auditLogger.LogAction("create_multisig_wallet", "multisig_wallet", walletID, "success", nil)
+	sm.auditLogger.LogActionCtx(ctx, "create_multisig_wallet", "multisig_wallet", walletID, "success", map[string]interface{}{
+		"contract_address": safe.Hex(),
+		"chain_id":         chainID,
+	})
 
 	return wallet, nil
 }
@@ -478,22 +510,50 @@ func (sm *AdvancedSecurityManager) SignMultiSigTransaction(ctx context.Context,
 	}
 
 	// 验证签名者
+	if !common.IsHexAddress(signerAddress) {
+		return fmt.Errorf("无效的签名者地址: %s", signerAddress)
+	}
+	claimed := common.HexToAddress(signerAddress)
 	var signer *MultiSigSigner
 	for i := range wallet.Signers {
-		if wallet.Signers[i].Address == signerAddress {
+		if common.IsHexAddress(wallet.Signers[i].Address) && common.HexToAddress(wallet.Signers[i].Address) == claimed {
 			signer = &wallet.Signers[i]
 			break
 		}
 	}
 
-	if signer == nil {
+	if signer == nil || !signer.IsActive {
 		return fmt.Errorf("无效的签名者")
 	}
 
+	// 同一签名者对同一交易只能签名一次
+	for _, existing := range tx.Signatures {
+		if common.IsHexAddress(existing.Signer) && common.HexToAddress(existing.Signer) == claimed {
+			return fmt.Errorf("签名者 %s 已签名该交易", claimed.Hex())
+		}
+	}
+
+	// 按 Safe EIP-712 交易哈希恢复签名者，必须与声明的签名者一致
+	if !common.IsHexAddress(wallet.ContractAddress) {
+		return fmt.Errorf("多签钱包合约地址无效: %s", wallet.ContractAddress)
+	}
+	chainID, err := sm.multiSigChainID(wallet.ChainID)
+	if err != nil {
+		return err
+	}
+	safeTxHash := SafeTxHash(chainID, common.HexToAddress(wallet.ContractAddress), tx)
+	recovered, normalized, err := recoverSafeSigner(safeTxHash, signature)
+	if err != nil {
+		return fmt.Errorf("签名无效: %w", err)
+	}
+	if recovered != claimed {
+		return fmt.Errorf("签名无效: 恢复出的签名者 %s 与声明的签名者 %s 不一致", recovered.Hex(), claimed.Hex())
+	}
+
 	// 添加签名
 	sig := MultiSigSignature{
-		Signer:    signerAddress,
-		Signature: signature,
+		Signer:    claimed.Hex(),
+		Signature: "0x" + hex.EncodeToString(normalized),
 		SignedAt:  time.Now(),
 		IsValid:   true,
 	}
@@ -503,7 +563,7 @@ func (sm *AdvancedSecurityManager) SignMultiSigTransaction(ctx context.Context,
 
 	// 检查是否达到阈值
 	if tx.CurrentSigs >= wallet.Threshold {
-		tx.Status = "ready_to_execute"
+		tx.Status = MultiSigStatusReady
 	}
 
 	now := time.Now()
@@ -518,6 +578,8 @@ func (sm *AdvancedSecurityManager) SignMultiSigTransaction(ctx context.Context,
 func NewAuthenticationManager() *AuthenticationManager {
 	return &AuthenticationManager{
 		mfaProviders:   make(map[string]*MFAProvider),
+		pendingTOTP:    make(map[string]*MFAProvider),
+		mfaFailures:    make(map[string]*mfaFailureState),
 		deviceRegistry: make(map[string]*TrustedDevice),
 		sessions:       make(map[string]*SecuritySession),
 		biometrics:     NewBiometricManager(),
@@ -548,27 +610,13 @@ func NewAuditLogger() *AuditLogger {
 	return &AuditLogger{
 		logs:      make([]AuditLog, 0),
 		retention: 365 * 24 * time.Hour, // 保留一年
+		capacity:  defaultAuditCacheSize,
 	}
 }
 
-// LogAction 记录操作日志
+// LogAction 记录操作日志（无归属信息，请求内调用应使用 LogActionCtx）
 func (al *AuditLogger) LogAction(action, resource, resourceID, result string, details map[string]interface{}) {
-	al.mu.Lock()
-	defer al.mu.Unlock()
-
-	log := AuditLog{
-		ID:        al.generateLogID(),
-		Timestamp: time.Now(),
-		Action:    action,
-		Resource:  resource,
-		Result:    result,
-		Details:   details,
-	}
-
-	al.logs = append(al.logs, log)
-
-	// 清理过期日志
-	al.cleanExpiredLogs()
+	al.LogActionFor(AuditContext{}, action, resource, resourceID, result, details)
 }
 
 // generateWalletID 生成钱包ID
@@ -578,22 +626,33 @@ func (sm *AdvancedSecurityManager) generateWalletID() string {
 	return hex.EncodeToString(bytes)
 }
 
-// generateLogID 生成日志ID
+// generateLogID 生成日志ID（随机16字节，持久化时作为唯一键）
 func (al *AuditLogger) generateLogID() string {
-	hash := sha256.Sum256([]byte(fmt.Sprintf("%d", time.Now().UnixNano())))
-	return hex.EncodeToString(hash[:8])
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
 }
 
-// cleanExpiredLogs 清理过期日志
+// cleanExpiredLogs 清理过期日志，调用方需持有写锁
+// 内存缓存按时间顺序丢弃过期及超出容量的日志；存储中的过期日志每隔 auditPurgeInterval 在后台分批删除
 func (al *AuditLogger) cleanExpiredLogs() {
-	cutoff := time.Now().Add(-al.retention)
-	filtered := make([]AuditLog, 0)
+	now := time.Now()
+	cutoff := now.Add(-al.retention)
 
-	for _, log := range al.logs {
-		if log.Timestamp.After(cutoff) {
-			filtered = append(filtered, log)
-		}
+	drop := 0
+	for drop < len(al.logs) && !al.logs[drop].Timestamp.After(cutoff) {
+		drop++
+	}
+	if over := len(al.logs) - drop - al.capacity; al.capacity > 0 && over > 0 {
+		drop += over
+	}
+	if drop > 0 {
+		al.logs = append(make([]AuditLog, 0, len(al.logs)-drop), al.logs[drop:]...)
 	}
 
-	al.logs = filtered
+	if al.store != nil && !al.purging && now.Sub(al.lastPurge) >= auditPurgeInterval {
+		al.purging = true
+		al.lastPurge = now
+		go al.purgeStore(cutoff)
+	}
 }
@@ -0,0 +1,238 @@
+/*
+助记词 Shamir 秘密分享（GF(256)）
+
+将助记词按字节拆分为 total 个分片，任意 threshold 个分片即可恢复，少于 threshold 个分片得不到任何信息：
+- 每个字节独立构造 threshold-1 次随机多项式 f(x)，常数项为该字节，分片 i 保存 f(i)（i = 1..total）
+- 恢复时对 x=0 做拉格朗日插值，与分片顺序无关
+- 有限域为 GF(2^8)，既约多项式 x^8 + x^4 + x^3 + x + 1（0x11b，与 AES 相同）
+
+服务端只保存分片元数据（索引、阈值、总数），分片数据仅返回给调用方自行分发保管。
+*/
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GF(256) 对数/指数表，生成元为 3
+var gf256Exp, gf256Log = buildGF256Tables()
+
+func buildGF256Tables() (exp [510]byte, log [256]byte) {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i] = x
+		log[x] = byte(i)
+		// x *= 3，即 x ^ (x * 2)，按 0x11b 取模
+		hi := x & 0x80
+		doubled := x << 1
+		if hi != 0 {
+			doubled ^= 0x1b
+		}
+		x ^= doubled
+	}
+	// 复制一份避免乘法时取模
+	for i := 255; i < 510; i++ {
+		exp[i] = exp[i-255]
+	}
+	return exp, log
+}
+
+// gf256Mul GF(256) 乘法
+func gf256Mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gf256Exp[int(gf256Log[a])+int(gf256Log[b])]
+}
+
+// gf256Div GF(256) 除法，b 不能为 0
+func gf256Div(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gf256Exp[int(gf256Log[a])+255-int(gf256Log[b])]
+}
+
+// SplitMnemonic 将助记词拆分为 total 个分片，任意 threshold 个可恢复
+func SplitMnemonic(mnemonic string, threshold, total int) ([]KeyShard, error) {
+	mnemonic = strings.TrimSpace(mnemonic)
+	if _, err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, fmt.Errorf("无效的助记词: %w", err)
+	}
+	if threshold < 2 || threshold > total {
+		return nil, fmt.Errorf("无效的恢复阈值: 需满足 2 <= threshold <= total")
+	}
+	if total > 255 {
+		return nil, fmt.Errorf("分片总数不能超过255")
+	}
+
+	secret := []byte(mnemonic)
+	defer zeroBytes(secret)
+
+	setID, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	shards := make([]KeyShard, total)
+	for i := range shards {
+		shards[i] = KeyShard{
+			ID:          fmt.Sprintf("%s-%d", setID, i+1),
+			ShardIndex:  i + 1,
+			ShardData:   make([]byte, len(secret)),
+			Threshold:   threshold,
+			TotalShards: total,
+			CreatedAt:   now,
+			IsActive:    true,
+		}
+	}
+
+	coeffs := make([]byte, threshold)
+	defer zeroBytes(coeffs)
+	for pos, b := range secret {
+		coeffs[0] = b
+		if _, err := rand.Read(coeffs[1:]); err != nil {
+			return nil, fmt.Errorf("生成随机系数失败: %w", err)
+		}
+		for i := range shards {
+			// 霍纳法计算 f(x)
+			x := byte(shards[i].ShardIndex)
+			var y byte
+			for k := threshold - 1; k >= 0; k-- {
+				y = gf256Mul(y, x) ^ coeffs[k]
+			}
+			shards[i].ShardData[pos] = y
+		}
+	}
+	return shards, nil
+}
+
+// ReconstructMnemonic 由至少 threshold 个分片恢复助记词，分片顺序不影响结果
+func ReconstructMnemonic(shards []KeyShard) (string, error) {
+	if len(shards) == 0 {
+		return "", fmt.Errorf("分片不能为空")
+	}
+	threshold := shards[0].Threshold
+	length := len(shards[0].ShardData)
+	seen := make(map[int]bool)
+	for _, shard := range shards {
+		if shard.Threshold != threshold || shard.TotalShards != shards[0].TotalShards {
+			return "", fmt.Errorf("分片不属于同一组")
+		}
+		if len(shard.ShardData) != length || length == 0 {
+			return "", fmt.Errorf("分片数据长度不一致")
+		}
+		if shard.ShardIndex < 1 || shard.ShardIndex > 255 {
+			return "", fmt.Errorf("无效的分片索引: %d", shard.ShardIndex)
+		}
+		if seen[shard.ShardIndex] {
+			return "", fmt.Errorf("重复的分片索引: %d", shard.ShardIndex)
+		}
+		seen[shard.ShardIndex] = true
+	}
+	if len(shards) < threshold {
+		return "", fmt.Errorf("分片数量不足: 需要 %d 个，提供 %d 个", threshold, len(shards))
+	}
+
+	// 只需 threshold 个分片即可插值
+	used := shards[:threshold]
+	secret := make([]byte, length)
+	defer zeroBytes(secret)
+	for pos := 0; pos < length; pos++ {
+		var value byte
+		for j, sj := range used {
+			xj := byte(sj.ShardIndex)
+			// 拉格朗日基函数在 x=0 处的值：∏ xm / (xm - xj)，GF(2^8) 中减法即异或
+			basis := byte(1)
+			for m, sm := range used {
+				if m == j {
+					continue
+				}
+				xm := byte(sm.ShardIndex)
+				basis = gf256Mul(basis, gf256Div(xm, xm^xj))
+			}
+			value ^= gf256Mul(sj.ShardData[pos], basis)
+		}
+		secret[pos] = value
+	}
+
+	mnemonic := string(secret)
+	if _, err := validateMnemonicWords(mnemonicWords(mnemonic)); err != nil {
+		return "", fmt.Errorf("恢复失败: 分片无效或不属于同一助记词")
+	}
+	return mnemonic, nil
+}
+
+// CreateMnemonicShards 拆分用户助记词并登记分片元数据（不保存分片数据），返回完整分片供分发
+func (sm *AdvancedSecurityManager) CreateMnemonicShards(ctx context.Context, userAddress, mnemonic string, threshold, total int) ([]KeyShard, error) {
+	shards, err := SplitMnemonic(mnemonic, threshold, total)
+	if err != nil {
+		return nil, err
+	}
+	for i := range shards {
+		shards[i].UserAddress = userAddress
+	}
+	sm.keyManager.registerShards(shards)
+
+	actx := AuditContextFrom(ctx)
+	if actx.UserAddress == "" {
+		actx.UserAddress = userAddress
+	}
+	sm.auditLogger.LogActionFor(actx, "create_mnemonic_shards", "key_shard", userAddress, "success", map[string]interface{}{
+		"threshold":    threshold,
+		"total_shards": total,
+	})
+	return shards, nil
+}
+
+// GetShardMetadata 查询用户已登记的分片元数据
+func (sm *AdvancedSecurityManager) GetShardMetadata(userAddress string) []KeyShard {
+	return sm.keyManager.shardsOf(userAddress)
+}
+
+// registerShards 登记分片元数据，分片数据不落在服务端
+func (km *KeyManager) registerShards(shards []KeyShard) {
+	km.mu.Lock()
+	defer km.mu.Unlock()
+
+	for _, shard := range shards {
+		meta := shard
+		meta.ShardData = nil
+		km.keyShards[meta.ID] = &meta
+	}
+}
+
+// shardsOf 返回用户的分片元数据
+func (km *KeyManager) shardsOf(userAddress string) []KeyShard {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+
+	out := make([]KeyShard, 0)
+	for _, shard := range km.keyShards {
+		if strings.EqualFold(shard.UserAddress, userAddress) {
+			out = append(out, *shard)
+		}
+	}
+	return out
+}
+
+// randomHex 生成 n 字节随机数的十六进制表示
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成随机数失败: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// zeroBytes 清零敏感数据
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,69 @@
+package core
+
+import "testing"
+
+const shamirTestMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestReconstructMnemonicThreshold(t *testing.T) {
+	shards, err := SplitMnemonic(shamirTestMnemonic, 3, 5)
+	if err != nil {
+		t.Fatalf("SplitMnemonic: %v", err)
+	}
+	if len(shards) != 5 {
+		t.Fatalf("分片数量 = %d，期望 5", len(shards))
+	}
+
+	if _, err := ReconstructMnemonic(shards[:2]); err == nil {
+		t.Fatal("threshold-1 个分片不应能恢复助记词")
+	}
+
+	got, err := ReconstructMnemonic(shards[2:])
+	if err != nil {
+		t.Fatalf("恰好 threshold 个分片恢复失败: %v", err)
+	}
+	if got != shamirTestMnemonic {
+		t.Fatalf("恢复结果 = %q，期望 %q", got, shamirTestMnemonic)
+	}
+}
+
+func TestReconstructMnemonicShardOrder(t *testing.T) {
+	shards, err := SplitMnemonic(shamirTestMnemonic, 3, 5)
+	if err != nil {
+		t.Fatalf("SplitMnemonic: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		indexes []int
+	}{
+		{"升序", []int{0, 1, 2}},
+		{"降序", []int{2, 1, 0}},
+		{"乱序", []int{4, 0, 3}},
+		{"全部分片乱序", []int{3, 1, 4, 0, 2}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			subset := make([]KeyShard, len(tc.indexes))
+			for i, idx := range tc.indexes {
+				subset[i] = shards[idx]
+			}
+			got, err := ReconstructMnemonic(subset)
+			if err != nil {
+				t.Fatalf("ReconstructMnemonic: %v", err)
+			}
+			if got != shamirTestMnemonic {
+				t.Fatalf("恢复结果 = %q，期望 %q", got, shamirTestMnemonic)
+			}
+		})
+	}
+}
+
+func TestReconstructMnemonicRejectsDuplicateShard(t *testing.T) {
+	shards, err := SplitMnemonic(shamirTestMnemonic, 2, 3)
+	if err != nil {
+		t.Fatalf("SplitMnemonic: %v", err)
+	}
+	if _, err := ReconstructMnemonic([]KeyShard{shards[0], shards[0]}); err == nil {
+		t.Fatal("重复分片不应能恢复助记词")
+	}
+}
@@ -0,0 +1,153 @@
+/*
+分享记录的访问控制与查看统计
+
+- 过期的分享记录不可访问，并由后台定时清理
+- 访问者查看时按 SharePrivacy 校验：RequireAuth 需登录，AllowedUsers 非空时仅允许列表中的地址与创建者
+- 非创建者查看时按隐私设置隐藏金额、地址
+- 每次访问者查看累加查看次数，记录最后查看时间、平台与国家；不传访问者时仅做只读查询，不计入统计
+*/
+package core
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// 分享记录访问错误
+var (
+	ErrShareNotFound     = errors.New("分享记录不存在")
+	ErrShareExpired      = errors.New("分享已过期")
+	ErrShareAuthRequired = errors.New("查看该分享需要登录")
+	ErrShareForbidden    = errors.New("无权查看该分享")
+)
+
+// ShareViewer 分享访问者信息
+type ShareViewer struct {
+	Address  string // 已登录访问者的钱包地址，未登录为空
+	ViewerID string // 访问者标识（地址或客户端IP），用于统计独立访客
+	Platform string // 访问平台（ios / android / windows / macos / linux / other）
+	Country  string // 国家代码，未知为空
+}
+
+// expiredAt 判断分享在 now 时刻是否已过期
+func (r *ShareRecord) expiredAt(now time.Time) bool {
+	return r.ExpiresAt != nil && !now.Before(*r.ExpiresAt)
+}
+
+// authorize 按隐私设置校验访问者
+func (r *ShareRecord) authorize(viewer *ShareViewer) error {
+	if r.isCreator(viewer.Address) {
+		return nil
+	}
+	if r.Privacy.RequireAuth && viewer.Address == "" {
+		return ErrShareAuthRequired
+	}
+	if len(r.Privacy.AllowedUsers) > 0 {
+		if viewer.Address == "" {
+			return ErrShareAuthRequired
+		}
+		for _, allowed := range r.Privacy.AllowedUsers {
+			if strings.EqualFold(allowed, viewer.Address) {
+				return nil
+			}
+		}
+		return ErrShareForbidden
+	}
+	return nil
+}
+
+// isCreator 判断地址是否为分享创建者
+func (r *ShareRecord) isCreator(address string) bool {
+	return address != "" && r.CreatedBy != "" && strings.EqualFold(r.CreatedBy, address)
+}
+
+// recordView 累加查看统计，调用方需持有写锁
+func (r *ShareRecord) recordView(viewer *ShareViewer, now time.Time) {
+	r.ViewCount++
+	r.Analytics.Views++
+	r.Analytics.LastViewedAt = &now
+	if viewer.ViewerID != "" {
+		if r.viewers == nil {
+			r.viewers = make(map[string]struct{})
+		}
+		if _, seen := r.viewers[viewer.ViewerID]; !seen {
+			r.viewers[viewer.ViewerID] = struct{}{}
+			r.Analytics.UniqueViewers++
+		}
+	}
+	if r.Analytics.Platforms == nil {
+		r.Analytics.Platforms = make(map[string]int)
+	}
+	if r.Analytics.Countries == nil {
+		r.Analytics.Countries = make(map[string]int)
+	}
+	if viewer.Platform != "" {
+		r.Analytics.Platforms[viewer.Platform]++
+	}
+	if viewer.Country != "" {
+		r.Analytics.Countries[strings.ToUpper(viewer.Country)]++
+	}
+}
+
+// snapshot 复制分享记录（含统计中的 map），避免调用方读取时与后续查看统计并发冲突
+func (r *ShareRecord) snapshot() *ShareRecord {
+	cp := *r
+	cp.viewers = nil
+	cp.Analytics.Platforms = copyCountMap(r.Analytics.Platforms)
+	cp.Analytics.Countries = copyCountMap(r.Analytics.Countries)
+	if r.Content.Amount != nil {
+		cp.Content.Amount = new(big.Int).Set(r.Content.Amount)
+	}
+	cp.Content.Tags = append([]string(nil), r.Content.Tags...)
+	cp.Privacy.AllowedUsers = append([]string(nil), r.Privacy.AllowedUsers...)
+	return &cp
+}
+
+// redactFor 按隐私设置为非创建者隐藏金额与地址；允许名单只对创建者可见
+func (r *ShareRecord) redactFor(viewer *ShareViewer) {
+	if r.isCreator(viewer.Address) {
+		return
+	}
+	if r.Privacy.HideAmounts {
+		r.Content.Amount = nil
+	}
+	if r.Privacy.HideAddresses {
+		r.Content.FromAddress = maskShareAddress(r.Content.FromAddress)
+		r.Content.ToAddress = maskShareAddress(r.Content.ToAddress)
+		r.CreatedBy = maskShareAddress(r.CreatedBy)
+	}
+	r.Privacy.AllowedUsers = nil
+}
+
+// PurgeExpired 删除在 now 时刻已过期的分享记录，返回删除数量
+func (sm *ShareManager) PurgeExpired(now time.Time) int {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	purged := 0
+	for id, record := range sm.shareRecords {
+		if record.expiredAt(now) {
+			delete(sm.shareRecords, id)
+			purged++
+		}
+	}
+	return purged
+}
+
+// maskShareAddress 地址脱敏，仅保留前6位与后4位
+func maskShareAddress(address string) string {
+	if len(address) <= 10 {
+		return address
+	}
+	return address[:6] + "..." + address[len(address)-4:]
+}
+
+func copyCountMap(m map[string]int) map[string]int {
+	cp := make(map[string]int, len(m))
+	for k, v := range m {
+		cp[k] = v
+	}
+	return cp
+}
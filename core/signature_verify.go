@@ -0,0 +1,119 @@
+/*
+消息签名验证
+
+DApp 登录等流程需要在服务端验证用户提交的签名：
+- personal_sign：对 "\x19Ethereum Signed Message:\n" + 长度 + 消息 的 keccak256 摘要签名
+- EIP-712（eth_signTypedData_v4）：对 keccak256("\x19\x01" || domainSeparator || hashStruct(message)) 签名
+通过 crypto.SigToPub 恢复签名者地址并与期望地址比较。
+签名的 v 值兼容 27/28 与 0/1 两种写法。
+*/
+package core
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	apitypes "github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// personalSignHash 计算 personal_sign 的消息摘要
+func personalSignHash(message string) common.Hash {
+	prefix := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)
+	return crypto.Keccak256Hash([]byte(prefix))
+}
+
+// typedDataDigest 计算 EIP-712 typed data（v4）的签名摘要
+func typedDataDigest(typedJSON []byte) (common.Hash, error) {
+	var td apitypes.TypedData
+	if err := json.Unmarshal(typedJSON, &td); err != nil {
+		return common.Hash{}, fmt.Errorf("解析 typed data JSON 失败: %w", err)
+	}
+	return typedDataHash(td)
+}
+
+// typedDataHash 计算已解析的 EIP-712 typed data 的签名摘要
+func typedDataHash(td apitypes.TypedData) (common.Hash, error) {
+	// 计算 EIP-712 摘要: keccak256("\x19\x01" || domainSeparator || hashStruct(message))
+	domainSep, err := td.HashStruct("EIP712Domain", td.Domain.Map())
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("计算domainSeparator失败: %w", err)
+	}
+	msgHash, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("计算message hash失败: %w", err)
+	}
+	raw := make([]byte, 0, 2+len(domainSep)+len(msgHash))
+	raw = append(raw, 0x19, 0x01)
+	raw = append(raw, domainSep...)
+	raw = append(raw, msgHash...)
+	return crypto.Keccak256Hash(raw), nil
+}
+
+// VerifyPersonalSign 验证 personal_sign 签名是否由 expectedAddress 签署
+func (a *EVMAdapter) VerifyPersonalSign(message, signature, expectedAddress string) (bool, error) {
+	recovered, err := a.RecoverPersonalSigner(message, signature)
+	if err != nil {
+		return false, err
+	}
+	return matchesSigner(recovered, expectedAddress)
+}
+
+// VerifyTypedDataV4 验证 EIP-712 typed data 签名是否由 expectedAddress 签署
+func (a *EVMAdapter) VerifyTypedDataV4(typedJSON []byte, signature, expectedAddress string) (bool, error) {
+	recovered, err := a.RecoverTypedDataV4Signer(typedJSON, signature)
+	if err != nil {
+		return false, err
+	}
+	return matchesSigner(recovered, expectedAddress)
+}
+
+// RecoverPersonalSigner 从 personal_sign 签名恢复签名者地址
+func (a *EVMAdapter) RecoverPersonalSigner(message, signature string) (common.Address, error) {
+	return recoverSigner(personalSignHash(message), signature)
+}
+
+// RecoverTypedDataV4Signer 从 EIP-712 typed data 签名恢复签名者地址
+func (a *EVMAdapter) RecoverTypedDataV4Signer(typedJSON []byte, signature string) (common.Address, error) {
+	digest, err := typedDataDigest(typedJSON)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return recoverSigner(digest, signature)
+}
+
+// matchesSigner 比较恢复出的签名者与期望地址
+func matchesSigner(recovered common.Address, expectedAddress string) (bool, error) {
+	if !common.IsHexAddress(expectedAddress) {
+		return false, fmt.Errorf("无效的地址格式: %s", expectedAddress)
+	}
+	return recovered == common.HexToAddress(expectedAddress), nil
+}
+
+// recoverSigner 从摘要与65字节签名恢复签名者地址
+func recoverSigner(digest common.Hash, signature string) (common.Address, error) {
+	sig, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(signature), "0x"))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("签名不是有效的十六进制: %w", err)
+	}
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("签名长度应为 %d 字节，实际 %d 字节", crypto.SignatureLength, len(sig))
+	}
+	// SigToPub 要求 v 为 0/1，钱包通常返回 27/28
+	switch sig[64] {
+	case 0, 1:
+	case 27, 28:
+		sig[64] -= 27
+	default:
+		return common.Address{}, fmt.Errorf("签名 v 值无效: %d", sig[64])
+	}
+
+	pub, err := crypto.SigToPub(digest.Bytes(), sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("恢复签名者失败: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
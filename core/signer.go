@@ -0,0 +1,108 @@
+/*
+交易签名者抽象
+
+EVMAdapter 的发送方法只依赖 Signer 接口完成签名，私钥可以不在服务端：
+- MnemonicSigner：由助记词和派生路径在内存中派生私钥（原有行为）
+- LedgerSigner：通过 USB HID 将交易发送到 Ledger 设备签名，见 ledger_signer.go
+- KeySigner：服务端配置的单个私钥（如代付Gas的中继账户，见 forwarder.go）
+
+接收助记词的发送方法保留为便捷封装，内部构造 MnemonicSigner 后调用对应的 *WithSigner 方法。
+*/
+package core
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Signer 交易签名者
+type Signer interface {
+	// Address 签名账户地址
+	Address() common.Address
+	// SignTx 按 chainID 对交易签名（EIP-155 / 类型化交易），返回已签名交易
+	SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+	// SignHash 对32字节摘要签名，返回 65 字节 [R || S || V] 签名（V 为 0/1）
+	SignHash(hash []byte) ([]byte, error)
+}
+
+// MnemonicSigner 基于助记词派生私钥的签名者
+type MnemonicSigner struct {
+	priv    *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewMnemonicSigner 从助记词和派生路径创建签名者（派生路径受白名单约束）
+func NewMnemonicSigner(mnemonic, derivationPath string) (*MnemonicSigner, error) {
+	priv, addr, err := DerivePrivateKeyFromMnemonic(mnemonic, derivationPath)
+	if err != nil {
+		return nil, err
+	}
+	return &MnemonicSigner{priv: priv, address: addr}, nil
+}
+
+// Address 签名账户地址
+func (s *MnemonicSigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx 使用派生私钥对交易签名
+func (s *MnemonicSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), s.priv)
+	if err != nil {
+		return nil, fmt.Errorf("签名交易失败: %w", err)
+	}
+	return signedTx, nil
+}
+
+// SignHash 使用派生私钥对摘要签名
+func (s *MnemonicSigner) SignHash(hash []byte) ([]byte, error) {
+	sig, err := crypto.Sign(hash, s.priv)
+	if err != nil {
+		return nil, fmt.Errorf("签名失败: %w", err)
+	}
+	return sig, nil
+}
+
+// KeySigner 基于单个私钥的签名者（如服务端配置的中继账户）
+type KeySigner struct {
+	priv    *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewKeySigner 从十六进制私钥（可带 0x 前缀）创建签名者
+func NewKeySigner(hexKey string) (*KeySigner, error) {
+	priv, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(hexKey), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("私钥格式不正确: %w", err)
+	}
+	return &KeySigner{priv: priv, address: crypto.PubkeyToAddress(priv.PublicKey)}, nil
+}
+
+// Address 签名账户地址
+func (s *KeySigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx 使用私钥对交易签名
+func (s *KeySigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), s.priv)
+	if err != nil {
+		return nil, fmt.Errorf("签名交易失败: %w", err)
+	}
+	return signedTx, nil
+}
+
+// SignHash 使用私钥对摘要签名
+func (s *KeySigner) SignHash(hash []byte) ([]byte, error) {
+	sig, err := crypto.Sign(hash, s.priv)
+	if err != nil {
+		return nil, fmt.Errorf("签名失败: %w", err)
+	}
+	return sig, nil
+}
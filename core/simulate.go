@@ -0,0 +1,113 @@
+/*
+交易模拟（dry-run）
+
+发送前在最新区块对交易做一次 eth_call，提前发现会回滚的交易，避免白白消耗 Gas：
+- 执行成功时返回返回数据与预估 gasLimit
+- 节点判定回滚时解码 revert reason（Error(string)/Panic(uint256)），无法解码时使用节点错误信息
+- 网络错误等非执行类错误直接返回 error，不视为模拟失败
+*/
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// SimulationResult 交易模拟结果
+type SimulationResult struct {
+	Success      bool   `json:"success"`                 // 是否执行成功
+	RevertReason string `json:"revert_reason,omitempty"` // 回滚原因
+	ReturnData   string `json:"return_data,omitempty"`   // 返回数据（hex）
+	GasEstimate  uint64 `json:"gas_estimate,omitempty"`  // 预估 gasLimit（仅成功时）
+	BlockNumber  uint64 `json:"block_number"`            // 模拟所基于的区块高度
+}
+
+// SimulateTransaction 在最新区块模拟执行交易，返回是否会回滚及原因
+func (a *EVMAdapter) SimulateTransaction(ctx context.Context, from, to string, value *big.Int, data []byte) (*SimulationResult, error) {
+	if !common.IsHexAddress(from) {
+		return nil, fmt.Errorf("from 地址格式不正确: %s", from)
+	}
+	if to != "" && !common.IsHexAddress(to) {
+		return nil, fmt.Errorf("to 地址格式不正确: %s", to)
+	}
+
+	blockNumber, err := a.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取最新区块失败: %w", err)
+	}
+	call := ethereum.CallMsg{From: common.HexToAddress(from), Value: value, Data: data}
+	if to != "" {
+		toAddr := common.HexToAddress(to)
+		call.To = &toAddr
+	}
+
+	result := &SimulationResult{BlockNumber: blockNumber}
+	out, err := a.client.CallContract(ctx, call, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		reason, ok := executionRevertReason(err)
+		if !ok {
+			return nil, fmt.Errorf("模拟交易失败: %w", err)
+		}
+		result.RevertReason = reason
+		return result, nil
+	}
+	if len(out) > 0 {
+		result.ReturnData = hexutil.Encode(out)
+	}
+
+	gas, err := a.client.EstimateGas(ctx, call)
+	if err != nil {
+		// eth_call 成功但估算失败（如余额不足以支付 Gas），同样视为发送后会失败
+		reason, ok := executionRevertReason(err)
+		if !ok {
+			return nil, fmt.Errorf("估算Gas失败: %w", err)
+		}
+		result.RevertReason = reason
+		return result, nil
+	}
+	result.Success = true
+	result.GasEstimate = gas
+	return result, nil
+}
+
+// ERC20TransferData 构造 ERC20 transfer(to, amount) 调用数据
+func ERC20TransferData(to string, amount *big.Int) ([]byte, error) {
+	if !common.IsHexAddress(to) {
+		return nil, fmt.Errorf("接收地址格式不正确: %s", to)
+	}
+	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return nil, err
+	}
+	return parsed.Pack("transfer", common.HexToAddress(to), amount)
+}
+
+// executionRevertReason 从节点返回的执行错误中提取回滚原因
+// 节点返回的 JSON-RPC 错误视为执行失败（ok=true），网络等其他错误返回 ok=false
+func executionRevertReason(err error) (string, bool) {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if hexData, isStr := dataErr.ErrorData().(string); isStr {
+			if raw, decodeErr := hexutil.Decode(hexData); decodeErr == nil {
+				if reason := decodeRevertReason(raw); reason != "" {
+					return reason, true
+				}
+			}
+		}
+		return dataErr.Error(), true
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Error(), true
+	}
+	return "", false
+}
@@ -51,6 +51,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 // SocialManager 社交功能管理器
@@ -160,6 +162,8 @@ type ShareRecord struct {
 	CreatedBy string         `json:"created_by"` // 创建者
 	CreatedAt time.Time      `json:"created_at"` // 创建时间
 	Analytics ShareAnalytics `json:"analytics"`  // 分享统计
+
+	viewers map[string]struct{} // 已查看的访问者标识，用于统计独立访客
 }
 
 // ShareContent 分享内容
@@ -347,32 +351,41 @@ type UserPermissions struct {
 }
 
 // ENSResolver ENS域名解析器
+// 通过以太坊主网适配器查询链上 ENS Registry，结果按 TTL 缓存
 type ENSResolver struct {
-	cache map[string]*ENSRecord // ENS缓存
-	mu    sync.RWMutex          // 读写锁
+	multiChain *MultiChainManager    // 多链管理器，用于获取主网适配器
+	cache      map[string]*ENSRecord // ENS缓存（按域名）
+	reverse    map[string]*ENSRecord // 反向解析缓存（按地址）
+	ttl        time.Duration         // 缓存有效期
+	uris       *URIResolver          // 头像地址解析
+	mu         sync.RWMutex          // 读写锁
 }
 
 // ENSRecord ENS记录
 type ENSRecord struct {
-	Name        string    `json:"name"`        // ENS名称
-	Address     string    `json:"address"`     // 对应地址
-	Avatar      string    `json:"avatar"`      // 头像
-	Description string    `json:"description"` // 描述
-	Website     string    `json:"website"`     // 网站
-	Twitter     string    `json:"twitter"`     // Twitter
-	Github      string    `json:"github"`      // Github
-	ResolvedAt  time.Time `json:"resolved_at"` // 解析时间
-	ExpiresAt   time.Time `json:"expires_at"`  // 过期时间
+	Name        string    `json:"name"`                 // ENS名称
+	Address     string    `json:"address"`              // 对应地址
+	Avatar      string    `json:"avatar"`               // 头像（avatar 文本记录原文）
+	AvatarURL   string    `json:"avatar_url,omitempty"` // 头像图片的HTTP网关地址（或 data URI）
+	Description string    `json:"description"`          // 描述
+	Website     string    `json:"website"`              // 网站
+	Twitter     string    `json:"twitter"`              // Twitter
+	Github      string    `json:"github"`               // Github
+	ResolvedAt  time.Time `json:"resolved_at"`          // 解析时间
+	ExpiresAt   time.Time `json:"expires_at"`           // 过期时间
+
+	avatarURI string // 头像图片原始地址（ipfs:// 等），获取时按网关顺序重试
 }
 
 // NewSocialManager 创建社交管理器
-func NewSocialManager() *SocialManager {
+// multiChain 用于ENS链上解析，为 nil 时ENS解析不可用
+func NewSocialManager(multiChain *MultiChainManager) *SocialManager {
 	return &SocialManager{
 		addressBook:    NewAddressBook(),
 		shareManager:   NewShareManager(),
 		socialNetwork:  NewSocialNetwork(),
 		privacyManager: NewPrivacyManager(),
-		ensResolver:    NewENSResolver(),
+		ensResolver:    NewENSResolver(multiChain),
 	}
 }
 
@@ -396,14 +409,19 @@ func (sm *SocialManager) DeleteContact(ctx context.Context, userAddress, contact
 	return sm.addressBook.DeleteContact(userAddress, contactID)
 }
 
-// CreateShareRecord 创建分享记录
-func (sm *SocialManager) CreateShareRecord(ctx context.Context, content *ShareContent, privacy *SharePrivacy) (*ShareRecord, error) {
-	return sm.shareManager.CreateShareRecord(content, privacy)
+// CreateShareRecord 创建分享记录，expiresAt 为 nil 时永不过期
+func (sm *SocialManager) CreateShareRecord(ctx context.Context, createdBy string, content *ShareContent, privacy *SharePrivacy, expiresAt *time.Time) (*ShareRecord, error) {
+	return sm.shareManager.CreateShareRecord(createdBy, content, privacy, expiresAt)
 }
 
-// GetShareRecord 获取分享记录
-func (sm *SocialManager) GetShareRecord(ctx context.Context, shareID string) (*ShareRecord, error) {
-	return sm.shareManager.GetShareRecord(shareID)
+// GetShareRecord 获取分享记录，viewer 不为空时校验访问权限并计入查看统计
+func (sm *SocialManager) GetShareRecord(ctx context.Context, shareID string, viewer *ShareViewer) (*ShareRecord, error) {
+	return sm.shareManager.GetShareRecord(shareID, viewer)
+}
+
+// PurgeExpiredShares 清理已过期的分享记录
+func (sm *SocialManager) PurgeExpiredShares() int {
+	return sm.shareManager.PurgeExpired(time.Now())
 }
 
 // FollowUser 关注用户
@@ -416,6 +434,21 @@ func (sm *SocialManager) UnfollowUser(ctx context.Context, followerAddress, targ
 	return sm.socialNetwork.UnfollowUser(followerAddress, targetAddress)
 }
 
+// ResolveENS 解析ENS域名
+func (sm *SocialManager) ResolveENS(ctx context.Context, ensName string) (*ENSRecord, error) {
+	return sm.ensResolver.ResolveENS(ctx, ensName)
+}
+
+// FetchENSAvatar 获取ENS域名的头像图片
+func (sm *SocialManager) FetchENSAvatar(ctx context.Context, ensName string) ([]byte, string, error) {
+	return sm.ensResolver.FetchAvatar(ctx, ensName)
+}
+
+// ResolveAddress 反向解析地址的ENS主域名
+func (sm *SocialManager) ResolveAddress(ctx context.Context, address string) (string, error) {
+	return sm.ensResolver.ResolveAddress(ctx, address)
+}
+
 // 辅助构造函数和私有方法
 
 // NewAddressBook 创建地址簿
@@ -439,7 +472,7 @@ func (ab *AddressBook) AddContact(userAddress string, contact *Contact) error {
 	contact.UpdatedAt = time.Now()
 
 	// 存储联系人
-	key := fmt.Sprintf("%s:%s", userAddress, contact.ID)
+	key := contactKey(userAddress, contact.ID)
 	ab.contacts[key] = contact
 
 	return nil
@@ -450,7 +483,7 @@ func (ab *AddressBook) GetContact(userAddress, contactID string) (*Contact, erro
 	ab.mu.RLock()
 	defer ab.mu.RUnlock()
 
-	key := fmt.Sprintf("%s:%s", userAddress, contactID)
+	key := contactKey(userAddress, contactID)
 	contact, exists := ab.contacts[key]
 	if !exists {
 		return nil, fmt.Errorf("联系人不存在")
@@ -464,7 +497,7 @@ func (ab *AddressBook) UpdateContact(userAddress string, contact *Contact) error
 	ab.mu.Lock()
 	defer ab.mu.Unlock()
 
-	key := fmt.Sprintf("%s:%s", userAddress, contact.ID)
+	key := contactKey(userAddress, contact.ID)
 	if _, exists := ab.contacts[key]; !exists {
 		return fmt.Errorf("联系人不存在")
 	}
@@ -479,7 +512,7 @@ func (ab *AddressBook) DeleteContact(userAddress, contactID string) error {
 	ab.mu.Lock()
 	defer ab.mu.Unlock()
 
-	key := fmt.Sprintf("%s:%s", userAddress, contactID)
+	key := contactKey(userAddress, contactID)
 	if _, exists := ab.contacts[key]; !exists {
 		return fmt.Errorf("联系人不存在")
 	}
@@ -488,6 +521,11 @@ func (ab *AddressBook) DeleteContact(userAddress, contactID string) error {
 	return nil
 }
 
+// contactKey 联系人存储键，用户地址不区分大小写
+func contactKey(userAddress, contactID string) string {
+	return fmt.Sprintf("%s:%s", strings.ToLower(userAddress), contactID)
+}
+
 // generateContactID 生成联系人ID
 func (ab *AddressBook) generateContactID(userAddress, name string) string {
 	hash := sha256.Sum256([]byte(fmt.Sprintf("%s:%s:%d", userAddress, name, time.Now().UnixNano())))
@@ -504,17 +542,25 @@ func NewShareManager() *ShareManager {
 }
 
 // CreateShareRecord 创建分享记录
-func (sm *ShareManager) CreateShareRecord(content *ShareContent, privacy *SharePrivacy) (*ShareRecord, error) {
+func (sm *ShareManager) CreateShareRecord(createdBy string, content *ShareContent, privacy *SharePrivacy, expiresAt *time.Time) (*ShareRecord, error) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
 	shareID := sm.generateShareID(content)
+	shareURL := fmt.Sprintf("https://wallet.example.com/share/%s", shareID)
+	png, err := GenerateQRCode(shareURL)
+	if err != nil {
+		return nil, err
+	}
 	shareRecord := &ShareRecord{
 		ID:        shareID,
 		Type:      "transaction",
 		Content:   *content,
-		ShareURL:  fmt.Sprintf("https://wallet.example.com/share/%s", shareID),
+		ShareURL:  shareURL,
+		QRCode:    QRCodeDataURL(png),
+		ExpiresAt: expiresAt,
 		Privacy:   *privacy,
+		CreatedBy: createdBy,
 		CreatedAt: time.Now(),
 		Analytics: ShareAnalytics{
 			Platforms: make(map[string]int),
@@ -523,20 +569,34 @@ func (sm *ShareManager) CreateShareRecord(content *ShareContent, privacy *ShareP
 	}
 
 	sm.shareRecords[shareID] = shareRecord
-	return shareRecord, nil
+	return shareRecord.snapshot(), nil
 }
 
-// GetShareRecord 获取分享记录
-func (sm *ShareManager) GetShareRecord(shareID string) (*ShareRecord, error) {
-	sm.mu.RLock()
-	defer sm.mu.RUnlock()
+// GetShareRecord 获取分享记录，过期返回 ErrShareExpired
+// viewer 不为空时按隐私设置校验访问者、计入查看统计，并对非创建者隐藏敏感字段
+func (sm *ShareManager) GetShareRecord(shareID string, viewer *ShareViewer) (*ShareRecord, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 
 	record, exists := sm.shareRecords[shareID]
 	if !exists {
-		return nil, fmt.Errorf("分享记录不存在")
+		return nil, ErrShareNotFound
+	}
+	now := time.Now()
+	if record.expiredAt(now) {
+		return nil, ErrShareExpired
+	}
+	if viewer == nil {
+		return record.snapshot(), nil
 	}
 
-	return record, nil
+	if err := record.authorize(viewer); err != nil {
+		return nil, err
+	}
+	record.recordView(viewer, now)
+	view := record.snapshot()
+	view.redactFor(viewer)
+	return view, nil
 }
 
 // generateShareID 生成分享ID
@@ -618,15 +678,28 @@ func NewPrivacyManager() *PrivacyManager {
 	}
 }
 
+// ensNetworkID ENS Registry 所在网络
+const ensNetworkID = "ethereum"
+
+// ensCacheTTL ENS解析结果缓存时间
+const ensCacheTTL = 1 * time.Hour
+
 // NewENSResolver 创建ENS解析器
-func NewENSResolver() *ENSResolver {
+func NewENSResolver(multiChain *MultiChainManager) *ENSResolver {
 	return &ENSResolver{
-		cache: make(map[string]*ENSRecord),
+		multiChain: multiChain,
+		cache:      make(map[string]*ENSRecord),
+		reverse:    make(map[string]*ENSRecord),
+		ttl:        ensCacheTTL,
+		uris:       DefaultURIResolver(),
 	}
 }
 
 // ResolveENS 解析ENS域名
+// 域名未注册或未绑定地址时返回 ErrENSNotFound，不会返回占位地址
 func (er *ENSResolver) ResolveENS(ctx context.Context, ensName string) (*ENSRecord, error) {
+	ensName = NormalizeENSName(ensName)
+
 	er.mu.RLock()
 	cached, exists := er.cache[ensName]
 	er.mu.RUnlock()
@@ -635,14 +708,39 @@ func (er *ENSResolver) ResolveENS(ctx context.Context, ensName string) (*ENSReco
 		return cached, nil
 	}
 
-	// 简化实现：返回模拟数据
+	adapter, err := er.adapter()
+	if err != nil {
+		return nil, err
+	}
+	addr, err := adapter.ENSResolveName(ctx, ensName)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
 	record := &ENSRecord{
-		Name:        ensName,
-		Address:     "0x" + strings.Repeat("1234567890", 4),
-		Avatar:      "https://example.com/avatar.png",
-		Description: "ENS用户",
-		ResolvedAt:  time.Now(),
-		ExpiresAt:   time.Now().Add(1 * time.Hour),
+		Name:       ensName,
+		Address:    addr.Hex(),
+		ResolvedAt: now,
+		ExpiresAt:  now.Add(er.ttl),
+	}
+	// 文本记录为可选信息，读取失败不影响地址解析
+	for key, field := range map[string]*string{
+		"avatar":      &record.Avatar,
+		"description": &record.Description,
+		"url":         &record.Website,
+		"com.twitter": &record.Twitter,
+		"com.github":  &record.Github,
+	} {
+		if text, err := adapter.ENSText(ctx, ensName, key); err == nil {
+			*field = text
+		}
+	}
+	if record.Avatar != "" {
+		if uri, err := adapter.ENSAvatarURI(ctx, record.Avatar, addr, er.uris); err == nil {
+			record.avatarURI = uri
+			record.AvatarURL = er.uris.GatewayURL(uri)
+		}
 	}
 
 	er.mu.Lock()
@@ -651,3 +749,77 @@ func (er *ENSResolver) ResolveENS(ctx context.Context, ensName string) (*ENSReco
 
 	return record, nil
 }
+
+// FetchAvatar 获取域名头像图片，返回内容与 Content-Type
+// 未设置头像或头像无法解析时返回 ErrENSNotFound；内容不是图片时返回错误
+func (er *ENSResolver) FetchAvatar(ctx context.Context, ensName string) ([]byte, string, error) {
+	record, err := er.ResolveENS(ctx, ensName)
+	if err != nil {
+		return nil, "", err
+	}
+	if record.avatarURI == "" {
+		return nil, "", fmt.Errorf("%w: %s 未设置头像", ErrENSNotFound, record.Name)
+	}
+	content, contentType, err := er.uris.Fetch(ctx, record.avatarURI)
+	if err != nil {
+		return nil, "", err
+	}
+	if !strings.HasPrefix(strings.ToLower(contentType), "image/") {
+		return nil, "", fmt.Errorf("%w: 头像内容类型为 %s", ErrUnsupportedURI, contentType)
+	}
+	return content, contentType, nil
+}
+
+// ResolveAddress 反向解析地址的ENS主域名
+// 未设置反向记录或反向记录未指回该地址时返回 ErrENSNotFound
+func (er *ENSResolver) ResolveAddress(ctx context.Context, address string) (string, error) {
+	if !common.IsHexAddress(address) {
+		return "", fmt.Errorf("无效的地址格式: %s", address)
+	}
+	key := common.HexToAddress(address).Hex()
+
+	er.mu.RLock()
+	cached, exists := er.reverse[key]
+	er.mu.RUnlock()
+
+	if exists && time.Now().Before(cached.ExpiresAt) {
+		return cached.Name, nil
+	}
+
+	adapter, err := er.adapter()
+	if err != nil {
+		return "", err
+	}
+	name, err := adapter.ENSLookupAddress(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	er.mu.Lock()
+	er.reverse[key] = &ENSRecord{
+		Name:       name,
+		Address:    key,
+		ResolvedAt: now,
+		ExpiresAt:  now.Add(er.ttl),
+	}
+	er.mu.Unlock()
+
+	return name, nil
+}
+
+// adapter 获取 ENS Registry 所在网络的EVM适配器
+func (er *ENSResolver) adapter() (*EVMAdapter, error) {
+	if er.multiChain == nil {
+		return nil, fmt.Errorf("ENS解析不可用: 未配置多链管理器")
+	}
+	adapter, err := er.multiChain.GetAdapter(ensNetworkID)
+	if err != nil {
+		return nil, fmt.Errorf("ENS解析不可用: %w", err)
+	}
+	evmAdapter, ok := adapter.(*EVMAdapter)
+	if !ok {
+		return nil, fmt.Errorf("ENS解析不可用: 网络 %s 不是EVM链", ensNetworkID)
+	}
+	return evmAdapter, nil
+}
@@ -0,0 +1,77 @@
+/*
+支出限额
+
+按自然日、自然月统计支出：
+- 周期起点按限额配置的时区计算（当地 0 点、当月 1 日 0 点），夏令时切换日同样正确
+- 多签钱包的 DailyLimit / MonthlyLimit 以原生币最小单位计，执行交易前按已执行与执行中交易的金额校验（UTC）
+*/
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// 限额周期
+const (
+	SpendingPeriodDaily   = "daily"
+	SpendingPeriodMonthly = "monthly"
+)
+
+// ErrSpendingLimitExceeded 超出支出限额
+var ErrSpendingLimitExceeded = errors.New("超出支出限额")
+
+// SpendingWindow 返回 now 所在周期在 loc 时区下的起止时间 [start, end)
+func SpendingWindow(now time.Time, loc *time.Location, period string) (start, end time.Time) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+	if period == SpendingPeriodMonthly {
+		start = time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, loc)
+		return start, start.AddDate(0, 1, 0)
+	}
+	start = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// checkMultiSigSpendingLimit 校验执行该交易后当日/当月已执行金额是否超过多签钱包限额（调用方需持有锁）
+func checkMultiSigSpendingLimit(wallet *MultiSigWallet, tx *MultiSigTransaction, now time.Time) error {
+	if tx.Value == nil || tx.Value.Sign() <= 0 {
+		return nil
+	}
+	limits := []struct {
+		period string
+		name   string
+		limit  *big.Int
+	}{
+		{SpendingPeriodDaily, "今日", wallet.Configuration.DailyLimit},
+		{SpendingPeriodMonthly, "本月", wallet.Configuration.MonthlyLimit},
+	}
+	for _, l := range limits {
+		if l.limit == nil || l.limit.Sign() <= 0 {
+			continue
+		}
+		start, _ := SpendingWindow(now, time.UTC, l.period)
+		spent := new(big.Int)
+		for i := range wallet.ExecutedTxs {
+			executed := &wallet.ExecutedTxs[i]
+			if executed.Value != nil && executed.ExecutedAt != nil && !executed.ExecutedAt.Before(start) {
+				spent.Add(spent, executed.Value)
+			}
+		}
+		// 正在执行中的交易同样占用额度，避免并发执行绕过限额
+		for i := range wallet.PendingTxs {
+			pending := &wallet.PendingTxs[i]
+			if pending.ID != tx.ID && pending.Status == MultiSigStatusExecuting && pending.Value != nil {
+				spent.Add(spent, pending.Value)
+			}
+		}
+		if total := new(big.Int).Add(spent, tx.Value); total.Cmp(l.limit) > 0 {
+			return fmt.Errorf("%w: %s已用 %s，本笔 %s，限额 %s", ErrSpendingLimitExceeded, l.name, spent, tx.Value, l.limit)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+ERC20 授权检查
+
+兑换等需要合约划转用户代币的操作前调用 EnsureAllowance：
+额度足够时不发送交易；不足时授权所需数量（或 uint256 最大值），并等待授权交易打包后返回，
+确保随后的交易估算与执行不会因额度不足失败。返回结果标明是否发送了授权交易，便于前端展示两步流程。
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// approvalConfirmTimeout 上下文未设置截止时间时等待授权交易打包的最长时间
+const approvalConfirmTimeout = 3 * time.Minute
+
+// ApprovalResult 授权检查结果
+type ApprovalResult struct {
+	Approved  bool     `json:"approved"`          // 是否发送了授权交易
+	TxHash    string   `json:"tx_hash,omitempty"` // 授权交易哈希
+	Amount    *big.Int `json:"amount,omitempty"`  // 授权数量
+	Infinite  bool     `json:"infinite"`          // 是否为无限授权
+	Allowance *big.Int `json:"allowance"`         // 检查时的已有额度
+}
+
+// EnsureAllowance 确保 owner 对 spender 的授权额度不少于 needed，不足时由 signer 发送授权并等待打包
+// infinite 为 true 时授权 uint256 最大值，否则仅授权 needed
+func (a *EVMAdapter) EnsureAllowance(ctx context.Context, token, owner, spender string, needed *big.Int, signer Signer, infinite bool) (*ApprovalResult, error) {
+	if needed == nil || needed.Sign() <= 0 {
+		return nil, fmt.Errorf("授权数量必须大于0")
+	}
+	if signer == nil {
+		return nil, fmt.Errorf("缺少签名者")
+	}
+	if !strings.EqualFold(signer.Address().Hex(), owner) {
+		return nil, fmt.Errorf("签名账户 %s 与代币持有地址 %s 不一致", signer.Address().Hex(), owner)
+	}
+
+	allowance, err := a.GetAllowance(ctx, token, owner, spender)
+	if err != nil {
+		return nil, err
+	}
+	result := &ApprovalResult{Allowance: allowance}
+	if allowance.Cmp(needed) >= 0 {
+		return result, nil
+	}
+
+	amount := new(big.Int).Set(needed)
+	if infinite {
+		amount = new(big.Int).Set(math.MaxBig256)
+	}
+	txHash, err := a.ApproveWithSigner(ctx, signer, token, spender, amount, nil)
+	if err != nil {
+		return nil, fmt.Errorf("发送授权交易失败: %w", err)
+	}
+	result.Approved = true
+	result.TxHash = txHash
+	result.Amount = amount
+	result.Infinite = infinite
+
+	waitCtx := ctx
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, approvalConfirmTimeout)
+		defer cancel()
+	}
+	receipt, err := a.WaitForReceipt(waitCtx, txHash, 0)
+	if err != nil {
+		return result, fmt.Errorf("授权交易 %s 未确认: %w", txHash, err)
+	}
+	if receipt.Status == 0 {
+		return result, fmt.Errorf("授权交易 %s 执行失败", txHash)
+	}
+	return result, nil
+}
@@ -0,0 +1,344 @@
+/*
+ERC20 授权管理（查看与撤销）
+
+长期有效的授权（尤其是 uint256 最大值的无限授权）是资产被盗的常见入口：被授权合约出现漏洞或本身就是钓鱼合约时，
+可在任意时间转走全部余额。GetActiveApprovals 列出地址当前仍有额度的授权：
+  - 扫描最近 lookbackBlocks 个区块内 owner 发出的 Approval 日志（ERC721 的 Approval 第三个参数为 indexed tokenId，日志有4个 topic，不计入），
+    按 (代币, spender) 去重后批量查询当前额度，额度为0（已撤销或已用完）的不返回
+  - 指定代币时，另外对已知 spender（DEX 路由、Permit2 等）直接查询额度，覆盖日志窗口之外的早期授权
+  - 额度不低于 2^255 视为无限授权（部分代币在划转后会从最大值递减），排在结果最前
+
+RevokeApprovals 将授权额度设为0，多笔撤销与批量发送相同：只获取一次起始 nonce 按顺序广播，某一笔失败后其余跳过。
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MaxBatchRevocations 单次批量撤销的最大授权数
+const MaxBatchRevocations = 50
+
+// knownSpenderLabels 常见的被授权合约（键为小写地址）
+var knownSpenderLabels = map[string]string{
+	"0x7a250d5630b4cf539739df2c5dacb4c659f2488d": "Uniswap V2 Router",
+	"0xe592427a0aece92de3edee1f18e0157c05861564": "Uniswap V3 SwapRouter",
+	"0x68b3465833fb72a70ecdf485e0e4c7bd8665fc45": "Uniswap SwapRouter02",
+	"0x000000000022d473030f116ddee9f6b43ac78ba3": "Uniswap Permit2",
+	"0x1111111254eeb25477b68fb85ed929f73a960582": "1inch Aggregation Router V5",
+}
+
+// KnownSpenderLabels 返回常见被授权合约的标签（键为小写地址），调用方可合并自定义标签
+func KnownSpenderLabels() map[string]string {
+	labels := make(map[string]string, len(knownSpenderLabels))
+	for addr, label := range knownSpenderLabels {
+		labels[addr] = label
+	}
+	return labels
+}
+
+// IsInfiniteAllowance 授权额度不低于 2^255 视为无限授权
+func IsInfiniteAllowance(amount *big.Int) bool {
+	return amount != nil && amount.BitLen() > infiniteApprovalMinimum
+}
+
+// TokenApproval 一条仍有额度的 ERC20 授权
+type TokenApproval struct {
+	Token          string `json:"token"`
+	Symbol         string `json:"symbol,omitempty"`
+	Decimals       *int   `json:"decimals,omitempty"`
+	Spender        string `json:"spender"`
+	SpenderLabel   string `json:"spender_label,omitempty"`  // 已知合约或自定义标签
+	Allowance      string `json:"allowance"`                // 当前额度（最小单位）
+	Infinite       bool   `json:"infinite"`                 // 是否为无限授权
+	ApprovedBlock  uint64 `json:"approved_block,omitempty"` // 日志窗口内最近一次 Approval 事件所在区块，按已知 spender 查到的为空
+	ApprovedTxHash string `json:"approved_tx_hash,omitempty"`
+}
+
+// ApprovalScan 授权查询结果
+type ApprovalScan struct {
+	Owner          string          `json:"owner"`
+	Approvals      []TokenApproval `json:"approvals"`
+	InfiniteCount  int             `json:"infinite_count"`  // 无限授权数
+	LatestBlock    uint64          `json:"latest_block"`    // 查询基于的区块
+	LookbackBlocks uint64          `json:"lookback_blocks"` // Approval 日志回溯的区块数
+	Warnings       []string        `json:"warnings,omitempty"`
+}
+
+// approvalPair 待查询额度的 (代币, spender)
+type approvalPair struct {
+	token, spender common.Address
+	block          uint64
+	txHash         string
+}
+
+// GetActiveApprovals 列出 owner 当前仍有额度的 ERC20 授权；tokens 为空时扫描全部代币的 Approval 日志
+// labels 为 spender 标签（键为小写地址），maxPairs 限制查询额度的 (代币, spender) 数量（优先最近的授权）
+func (a *EVMAdapter) GetActiveApprovals(ctx context.Context, owner string, tokens []string, lookbackBlocks uint64, maxPairs int, labels map[string]string) (*ApprovalScan, error) {
+	if !common.IsHexAddress(owner) {
+		return nil, fmt.Errorf("无效的地址: %s", owner)
+	}
+	ownerAddr := common.HexToAddress(owner)
+	tokenAddrs := make([]common.Address, 0, len(tokens))
+	for _, token := range tokens {
+		if !common.IsHexAddress(token) {
+			return nil, fmt.Errorf("无效的代币地址: %s", token)
+		}
+		tokenAddrs = append(tokenAddrs, common.HexToAddress(token))
+	}
+	latest, err := a.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取最新区块失败: %w", err)
+	}
+	scan := &ApprovalScan{Owner: ownerAddr.Hex(), Approvals: []TokenApproval{}, LatestBlock: latest, LookbackBlocks: lookbackBlocks}
+
+	start := uint64(0)
+	if latest > lookbackBlocks {
+		start = latest - lookbackBlocks + 1
+	}
+	pairs, err := a.approvalLogPairs(ctx, ownerAddr, tokenAddrs, start, latest)
+	if err != nil {
+		scan.Warnings = append(scan.Warnings, "Approval 日志查询失败: "+err.Error())
+	}
+	// 最近的授权优先
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].block > pairs[j].block })
+	seen := make(map[[2]common.Address]bool, len(pairs))
+	for _, p := range pairs {
+		seen[[2]common.Address{p.token, p.spender}] = true
+	}
+	for _, token := range tokenAddrs {
+		for spender := range labels {
+			if !common.IsHexAddress(spender) {
+				continue
+			}
+			key := [2]common.Address{token, common.HexToAddress(spender)}
+			if !seen[key] {
+				seen[key] = true
+				pairs = append(pairs, approvalPair{token: key[0], spender: key[1]})
+			}
+		}
+	}
+	if maxPairs > 0 && len(pairs) > maxPairs {
+		scan.Warnings = append(scan.Warnings, fmt.Sprintf("授权记录较多，仅查询最近的 %d 条", maxPairs))
+		pairs = pairs[:maxPairs]
+	}
+	if len(pairs) == 0 {
+		return scan, nil
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return nil, fmt.Errorf("解析ERC20 ABI失败: %w", err)
+	}
+	calls := make([]MulticallRequest, 0, len(pairs))
+	for _, p := range pairs {
+		data, err := parsed.Pack("allowance", ownerAddr, p.spender)
+		if err != nil {
+			return nil, fmt.Errorf("打包allowance数据失败: %w", err)
+		}
+		calls = append(calls, MulticallRequest{Target: p.token, CallData: data, AllowFailure: true})
+	}
+	results, err := a.Multicall(ctx, calls)
+	if err != nil {
+		return nil, fmt.Errorf("查询授权额度失败: %w", err)
+	}
+
+	var active []TokenApproval
+	tokenSet := make(map[string]bool)
+	for i, r := range results {
+		if !r.Success || len(r.ReturnData) < 32 {
+			continue
+		}
+		allowance := new(big.Int).SetBytes(r.ReturnData[:32])
+		if allowance.Sign() == 0 {
+			continue
+		}
+		p := pairs[i]
+		approval := TokenApproval{
+			Token:          p.token.Hex(),
+			Spender:        p.spender.Hex(),
+			SpenderLabel:   labels[strings.ToLower(p.spender.Hex())],
+			Allowance:      allowance.String(),
+			Infinite:       IsInfiniteAllowance(allowance),
+			ApprovedBlock:  p.block,
+			ApprovedTxHash: p.txHash,
+		}
+		if approval.Infinite {
+			scan.InfiniteCount++
+		}
+		tokenSet[approval.Token] = true
+		active = append(active, approval)
+	}
+
+	if len(tokenSet) > 0 {
+		activeTokens := make([]string, 0, len(tokenSet))
+		for token := range tokenSet {
+			activeTokens = append(activeTokens, token)
+		}
+		metadata, err := a.GetERC20MetadataBatch(ctx, activeTokens)
+		if err != nil {
+			scan.Warnings = append(scan.Warnings, "代币信息查询失败: "+err.Error())
+		}
+		for i := range active {
+			if meta, ok := metadata[active[i].Token]; ok {
+				decimals := meta.Decimals
+				active[i].Symbol = meta.Symbol
+				active[i].Decimals = &decimals
+			}
+		}
+	}
+
+	// 无限授权在前，其余按最近授权排序
+	sort.SliceStable(active, func(i, j int) bool {
+		if active[i].Infinite != active[j].Infinite {
+			return active[i].Infinite
+		}
+		return active[i].ApprovedBlock > active[j].ApprovedBlock
+	})
+	if active != nil {
+		scan.Approvals = active
+	}
+	return scan, nil
+}
+
+// approvalLogPairs 按区块范围分段查询 owner 发出的 ERC20 Approval 日志，返回去重后的 (代币, spender) 及最近一次授权的位置
+func (a *EVMAdapter) approvalLogPairs(ctx context.Context, owner common.Address, tokens []common.Address, startBlock, endBlock uint64) ([]approvalPair, error) {
+	ownerTopic := common.BytesToHash(owner.Bytes())
+	index := make(map[[2]common.Address]int)
+	var pairs []approvalPair
+	for from := startBlock; from <= endBlock; from += logQueryBlockRange {
+		to := from + logQueryBlockRange - 1
+		if to > endBlock || to < from {
+			to = endBlock
+		}
+		logs, err := a.client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: tokens,
+			Topics:    [][]common.Hash{{approvalEventTopic}, {ownerTopic}},
+		})
+		if err != nil {
+			return pairs, fmt.Errorf("查询Approval日志失败（区块 %d-%d）: %w", from, to, err)
+		}
+		for _, lg := range logs {
+			if lg.Removed || len(lg.Topics) != 3 {
+				continue
+			}
+			p := approvalPair{
+				token:   lg.Address,
+				spender: common.BytesToAddress(lg.Topics[2].Bytes()),
+				block:   lg.BlockNumber,
+				txHash:  lg.TxHash.Hex(),
+			}
+			key := [2]common.Address{p.token, p.spender}
+			if i, ok := index[key]; ok {
+				if p.block >= pairs[i].block {
+					pairs[i] = p
+				}
+				continue
+			}
+			index[key] = len(pairs)
+			pairs = append(pairs, p)
+		}
+		if to == endBlock {
+			break
+		}
+	}
+	return pairs, nil
+}
+
+// ApprovalRevocation 待撤销的授权
+type ApprovalRevocation struct {
+	Token   string // ERC20 合约地址
+	Spender string // 被授权地址
+}
+
+// RevokeResult 单笔撤销的发送结果，状态取值同批量发送（sent / failed / skipped）
+type RevokeResult struct {
+	Index   int     `json:"index"`
+	Token   string  `json:"token"`
+	Spender string  `json:"spender"`
+	Nonce   *uint64 `json:"nonce,omitempty"`
+	Status  string  `json:"status"`
+	TxHash  string  `json:"tx_hash,omitempty"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// RevokeApproval 发送 approve(spender, 0) 撤销授权
+func (a *EVMAdapter) RevokeApproval(ctx context.Context, signer Signer, token, spender string, opts *TxOptions) (string, error) {
+	return a.ApproveWithSigner(ctx, signer, token, spender, big.NewInt(0), opts)
+}
+
+// RevokeApprovals 使用连续的 nonce 按顺序撤销多个授权，opts.Nonce 为起始 nonce（为空时取 pending nonce），opts.GasLimit 不生效
+// 参数校验失败或无法获取 nonce 时不发送任何交易并返回错误；某一笔失败后其余标记为 skipped
+func (a *EVMAdapter) RevokeApprovals(ctx context.Context, signer Signer, revocations []ApprovalRevocation, opts *TxOptions) ([]RevokeResult, error) {
+	if len(revocations) == 0 {
+		return nil, fmt.Errorf("撤销列表不能为空")
+	}
+	if len(revocations) > MaxBatchRevocations {
+		return nil, fmt.Errorf("单次最多撤销 %d 个授权", MaxBatchRevocations)
+	}
+	for i, r := range revocations {
+		if !common.IsHexAddress(r.Token) {
+			return nil, fmt.Errorf("第 %d 项的代币地址格式不正确: %s", i+1, r.Token)
+		}
+		if !common.IsHexAddress(r.Spender) {
+			return nil, fmt.Errorf("第 %d 项的 spender 地址格式不正确: %s", i+1, r.Spender)
+		}
+	}
+	var (
+		nonce uint64
+		err   error
+	)
+	if opts != nil && opts.Nonce != nil {
+		nonce = *opts.Nonce
+	} else {
+		nonce, err = a.client.PendingNonceAt(ctx, signer.Address())
+		if err != nil {
+			return nil, fmt.Errorf("获取nonce失败: %w", err)
+		}
+	}
+
+	results := make([]RevokeResult, len(revocations))
+	failed := false
+	for i, r := range revocations {
+		result := RevokeResult{
+			Index:   i,
+			Token:   common.HexToAddress(r.Token).Hex(),
+			Spender: common.HexToAddress(r.Spender).Hex(),
+		}
+		if failed {
+			result.Status = BatchStatusSkipped
+			results[i] = result
+			continue
+		}
+
+		itemNonce := nonce
+		result.Nonce = &itemNonce
+		itemOpts := &TxOptions{Nonce: &itemNonce}
+		if opts != nil {
+			itemOpts.GasPrice, itemOpts.TipCap, itemOpts.FeeCap = opts.GasPrice, opts.TipCap, opts.FeeCap
+			itemOpts.MaxFeeWei, itemOpts.AllowHighFee = opts.MaxFeeWei, opts.AllowHighFee
+		}
+		txHash, err := a.RevokeApproval(ctx, signer, r.Token, r.Spender, itemOpts)
+		if err != nil {
+			result.Status = BatchStatusFailed
+			result.Error = err.Error()
+			failed = true
+		} else {
+			result.Status = BatchStatusSent
+			result.TxHash = txHash
+			nonce++
+		}
+		results[i] = result
+	}
+	return results, nil
+}
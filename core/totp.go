@@ -0,0 +1,449 @@
+/*
+TOTP 双因素认证（RFC 6238）
+
+- 密钥：20 字节随机数，Base32（无填充）编码，通过 otpauth:// URL 供验证器 App 扫码导入
+- 验证码：HMAC-SHA1，6 位数字，30 秒时间窗口，允许前后各 1 个窗口的时钟偏差
+- 防重放：同一时间窗口内已验证过的验证码不能再次使用
+- 备用码：启用时生成 10 个一次性备用码，仅保存 SHA-256 哈希，使用后立即作废
+
+启用后首次验证成功才正式生效（IsEnabled=true），避免用户未完成绑定就被要求输入验证码；
+已启用时更换密钥需提交当前验证码，新密钥验证成功前旧密钥继续有效。
+同一地址验证码（含备用码）连续失败 5 次后锁定 15 分钟。
+MFA 数据按小写地址索引。设置 TOTPStore 后密钥、备用码哈希与已使用的时间窗口写穿到存储，
+每次读取或校验前从存储重新加载，重启后绑定仍然有效、已使用的验证码仍不能重放；失败锁定计数只保存在内存中。
+*/
+package core
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP 参数
+const (
+	MFATypeTOTP = "TOTP"
+
+	totpIssuer          = "Wallet"
+	totpSecretSize      = 20 // 密钥字节数（RFC 4226 推荐 160 位）
+	totpDigits          = 6
+	totpPeriod          = 30 // 时间窗口（秒）
+	totpSkew            = 1  // 允许的前后窗口数
+	backupCodeCount     = 10
+	backupCodeSize      = 5 // 备用码随机字节数（10 位十六进制）
+	totpLastStepSetting = "last_step"
+)
+
+// 验证码失败锁定：窗口内连续失败达到上限后锁定一段时间，防止暴力猜测
+const (
+	mfaMaxFailures     = 5
+	mfaFailureWindow   = 15 * time.Minute
+	mfaLockoutDuration = 15 * time.Minute
+)
+
+var (
+	// ErrMFACodeRequired 已启用TOTP，更换密钥需提交当前验证码
+	ErrMFACodeRequired = errors.New("已启用双因素认证，需提交当前验证码")
+	// ErrMFACodeInvalid 提交的当前验证码错误
+	ErrMFACodeInvalid = errors.New("验证码错误")
+	// ErrMFALocked 验证码错误次数过多，暂时锁定
+	ErrMFALocked = errors.New("验证码错误次数过多，请稍后再试")
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTPStore TOTP 配置的持久化存储，userAddress 为小写地址
+type TOTPStore interface {
+	// LoadTOTP 读取已启用与待生效的 TOTP 配置，不存在时为 nil
+	LoadTOTP(userAddress string) (active, pending *MFAProvider, err error)
+	// SaveTOTP 保存已启用与待生效的 TOTP 配置，为 nil 时删除对应记录
+	SaveTOTP(userAddress string, active, pending *MFAProvider) error
+}
+
+// mfaFailureState 验证码失败计数
+type mfaFailureState struct {
+	count       int
+	first       time.Time // 当前计数窗口的开始时间
+	lockedUntil time.Time
+}
+
+// EnableTOTP 为用户生成新的 TOTP 密钥，返回 Base32 密钥与 otpauth:// URL
+// 新密钥在首次验证成功前处于待生效状态，已启用的旧密钥继续有效；
+// 已启用 TOTP 时必须提交当前有效的验证码（或备用码）才能更换密钥
+func (am *AuthenticationManager) EnableTOTP(userAddress, currentCode string) (secret, otpauthURL string, err error) {
+	key := mfaKey(userAddress)
+	am.mu.Lock()
+	if err := am.loadTOTPLocked(key); err != nil {
+		am.mu.Unlock()
+		return "", "", err
+	}
+	if active, ok := am.mfaProviders[key]; ok && active.IsEnabled {
+		if strings.TrimSpace(currentCode) == "" {
+			am.mu.Unlock()
+			return "", "", ErrMFACodeRequired
+		}
+		valid, err := am.verifyCodeLocked(key, currentCode, time.Now())
+		if err != nil || !valid {
+			am.mu.Unlock()
+			if err == nil {
+				err = ErrMFACodeInvalid
+			}
+			return "", "", err
+		}
+	}
+	am.mu.Unlock()
+
+	raw := make([]byte, totpSecretSize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("生成TOTP密钥失败: %w", err)
+	}
+	secret = totpEncoding.EncodeToString(raw)
+
+	am.mu.Lock()
+	am.pendingTOTP[key] = &MFAProvider{
+		Type:          MFATypeTOTP,
+		Name:          "Authenticator",
+		IsEnabled:     false,
+		Secret:        secret,
+		BackupCodes:   nil,
+		Configuration: make(map[string]interface{}),
+	}
+	err = am.saveTOTPLocked(key)
+	am.mu.Unlock()
+	if err != nil {
+		return "", "", err
+	}
+
+	label := url.PathEscape(totpIssuer + ":" + userAddress)
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", totpIssuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprintf("%d", totpDigits))
+	query.Set("period", fmt.Sprintf("%d", totpPeriod))
+	otpauthURL = fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode())
+	return secret, otpauthURL, nil
+}
+
+// GenerateBackupCodes 为已设置 TOTP 的用户生成一组新的一次性备用码（旧备用码作废）
+// 有待生效的新密钥时备用码随新密钥生效；返回明文备用码，服务端只保存哈希
+func (am *AuthenticationManager) GenerateBackupCodes(userAddress string) ([]string, error) {
+	codes := make([]string, backupCodeCount)
+	hashes := make([]string, backupCodeCount)
+	for i := range codes {
+		raw := make([]byte, backupCodeSize)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("生成备用码失败: %w", err)
+		}
+		code := hex.EncodeToString(raw)
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = hashBackupCode(code)
+	}
+
+	key := mfaKey(userAddress)
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	if err := am.loadTOTPLocked(key); err != nil {
+		return nil, err
+	}
+	provider, ok := am.pendingTOTP[key]
+	if !ok {
+		provider, ok = am.mfaProviders[key]
+	}
+	if !ok {
+		return nil, fmt.Errorf("用户未设置TOTP")
+	}
+	provider.BackupCodes = hashes
+	if err := am.saveTOTPLocked(key); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// IsTOTPEnabled 用户是否已启用 TOTP（完成首次验证）
+// 无法读取存储时视为已启用，要求提交验证码（校验时同样读取失败而拒绝），避免存储故障时绕过双因素认证
+func (am *AuthenticationManager) IsTOTPEnabled(userAddress string) bool {
+	key := mfaKey(userAddress)
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	if err := am.loadTOTPLocked(key); err != nil {
+		return true
+	}
+	provider, ok := am.mfaProviders[key]
+	return ok && provider.IsEnabled
+}
+
+// VerifyTOTP 校验 6 位验证码（当前窗口 ±1），与待生效密钥匹配时该密钥正式启用
+// 连续失败 mfaMaxFailures 次后锁定 mfaLockoutDuration，锁定期间返回 ErrMFALocked
+func (am *AuthenticationManager) VerifyTOTP(userAddress, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return false, nil
+	}
+
+	key := mfaKey(userAddress)
+	now := time.Now()
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	if err := am.checkMFALockout(key, now); err != nil {
+		return false, err
+	}
+	if err := am.loadTOTPLocked(key); err != nil {
+		return false, err
+	}
+	active := am.mfaProviders[key]
+	pending := am.pendingTOTP[key]
+	if active == nil && pending == nil {
+		return false, fmt.Errorf("用户未设置TOTP")
+	}
+	if active != nil {
+		ok, err := matchTOTP(active, code, now)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return am.acceptCodeLocked(key)
+		}
+	}
+	if pending != nil {
+		ok, err := matchTOTP(pending, code, now)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			pending.IsEnabled = true
+			am.mfaProviders[key] = pending
+			delete(am.pendingTOTP, key)
+			return am.acceptCodeLocked(key)
+		}
+	}
+	am.recordMFAFailure(key, now)
+	return false, nil
+}
+
+// VerifyBackupCode 校验一次性备用码，成功后该备用码作废；失败计入锁定计数
+func (am *AuthenticationManager) VerifyBackupCode(userAddress, code string) (bool, error) {
+	key := mfaKey(userAddress)
+	now := time.Now()
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	if err := am.checkMFALockout(key, now); err != nil {
+		return false, err
+	}
+	if err := am.loadTOTPLocked(key); err != nil {
+		return false, err
+	}
+	provider, ok := am.mfaProviders[key]
+	if !ok || !provider.IsEnabled {
+		return false, fmt.Errorf("用户未启用TOTP")
+	}
+	if matchBackupCode(provider, code, now) {
+		return am.acceptCodeLocked(key)
+	}
+	am.recordMFAFailure(key, now)
+	return false, nil
+}
+
+// verifyCodeLocked 用已启用的密钥校验验证码或备用码（调用方持有写锁）
+func (am *AuthenticationManager) verifyCodeLocked(key, code string, now time.Time) (bool, error) {
+	if err := am.checkMFALockout(key, now); err != nil {
+		return false, err
+	}
+	provider := am.mfaProviders[key]
+	code = strings.TrimSpace(code)
+	var ok bool
+	if len(code) == totpDigits {
+		var err error
+		if ok, err = matchTOTP(provider, code, now); err != nil {
+			return false, err
+		}
+	} else {
+		ok = matchBackupCode(provider, code, now)
+	}
+	if ok {
+		return am.acceptCodeLocked(key)
+	}
+	am.recordMFAFailure(key, now)
+	return false, nil
+}
+
+// acceptCodeLocked 验证成功后清除失败计数并保存已使用的时间窗口与备用码；保存失败时拒绝本次验证，避免验证码在重启后被重放
+func (am *AuthenticationManager) acceptCodeLocked(key string) (bool, error) {
+	am.resetMFAFailures(key)
+	if err := am.saveTOTPLocked(key); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetTOTPStore 设置 TOTP 配置的持久化存储
+func (am *AuthenticationManager) SetTOTPStore(store TOTPStore) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	am.totpStore = store
+}
+
+// loadTOTPLocked 从存储重新加载用户的 TOTP 配置（调用方持有写锁），未设置存储时不做处理
+func (am *AuthenticationManager) loadTOTPLocked(key string) error {
+	if am.totpStore == nil {
+		return nil
+	}
+	active, pending, err := am.totpStore.LoadTOTP(key)
+	if err != nil {
+		return fmt.Errorf("读取TOTP配置失败: %w", err)
+	}
+	setMFAProvider(am.mfaProviders, key, active)
+	setMFAProvider(am.pendingTOTP, key, pending)
+	return nil
+}
+
+// saveTOTPLocked 将用户的 TOTP 配置写入存储（调用方持有写锁），未设置存储时不做处理
+func (am *AuthenticationManager) saveTOTPLocked(key string) error {
+	if am.totpStore == nil {
+		return nil
+	}
+	if err := am.totpStore.SaveTOTP(key, am.mfaProviders[key], am.pendingTOTP[key]); err != nil {
+		return fmt.Errorf("保存TOTP配置失败: %w", err)
+	}
+	return nil
+}
+
+func setMFAProvider(providers map[string]*MFAProvider, key string, provider *MFAProvider) {
+	if provider == nil {
+		delete(providers, key)
+		return
+	}
+	providers[key] = provider
+}
+
+// NewTOTPProvider 由持久化的数据恢复 TOTP 配置，lastStep 为最近一次验证成功的时间窗口
+func NewTOTPProvider(secret string, enabled bool, backupCodes []string, lastStep int64, lastUsed *time.Time) *MFAProvider {
+	return &MFAProvider{
+		Type:          MFATypeTOTP,
+		Name:          "Authenticator",
+		IsEnabled:     enabled,
+		Secret:        secret,
+		BackupCodes:   backupCodes,
+		LastUsed:      lastUsed,
+		Configuration: map[string]interface{}{totpLastStepSetting: lastStep},
+	}
+}
+
+// TOTPLastStep 最近一次验证成功的时间窗口（防重放），未使用过时为 0
+func (p *MFAProvider) TOTPLastStep() int64 {
+	step, _ := p.Configuration[totpLastStepSetting].(int64)
+	return step
+}
+
+// checkMFALockout 锁定期内返回 ErrMFALocked
+func (am *AuthenticationManager) checkMFALockout(key string, now time.Time) error {
+	if state, ok := am.mfaFailures[key]; ok && now.Before(state.lockedUntil) {
+		return ErrMFALocked
+	}
+	return nil
+}
+
+// recordMFAFailure 记录一次失败，窗口内达到上限时锁定
+func (am *AuthenticationManager) recordMFAFailure(key string, now time.Time) {
+	state, ok := am.mfaFailures[key]
+	if !ok || now.Sub(state.first) > mfaFailureWindow {
+		state = &mfaFailureState{first: now}
+		am.mfaFailures[key] = state
+	}
+	state.count++
+	if state.count >= mfaMaxFailures {
+		state.lockedUntil = now.Add(mfaLockoutDuration)
+		state.count = 0
+		state.first = now
+	}
+}
+
+// resetMFAFailures 验证成功后清除失败计数
+func (am *AuthenticationManager) resetMFAFailures(key string) {
+	delete(am.mfaFailures, key)
+}
+
+// matchTOTP 校验验证码并记录已使用的时间窗口（防重放）
+func matchTOTP(provider *MFAProvider, code string, now time.Time) (bool, error) {
+	if provider.Type != MFATypeTOTP {
+		return false, fmt.Errorf("用户未设置TOTP")
+	}
+	key, err := totpEncoding.DecodeString(provider.Secret)
+	if err != nil {
+		return false, fmt.Errorf("TOTP密钥格式错误: %w", err)
+	}
+	current := now.Unix() / totpPeriod
+	lastStep := provider.TOTPLastStep()
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= lastStep {
+			continue // 已使用过的窗口，防止重放
+		}
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			provider.Configuration[totpLastStepSetting] = step
+			provider.LastUsed = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchBackupCode 校验备用码，匹配的备用码立即作废
+func matchBackupCode(provider *MFAProvider, code string, now time.Time) bool {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if normalized == "" {
+		return false
+	}
+	hashed := hashBackupCode(normalized)
+	for i, stored := range provider.BackupCodes {
+		if subtle.ConstantTimeCompare([]byte(stored), []byte(hashed)) == 1 {
+			provider.BackupCodes = append(provider.BackupCodes[:i], provider.BackupCodes[i+1:]...)
+			provider.LastUsed = &now
+			return true
+		}
+	}
+	return false
+}
+
+// AuthManager 获取认证管理器
+func (sm *AdvancedSecurityManager) AuthManager() *AuthenticationManager {
+	return sm.authManager
+}
+
+// SetTOTPStore 设置 TOTP 配置的持久化存储
+func (sm *AdvancedSecurityManager) SetTOTPStore(store TOTPStore) {
+	sm.authManager.SetTOTPStore(store)
+}
+
+// totpCode 计算指定时间步的验证码（RFC 4226 动态截断）
+func totpCode(key []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// hashBackupCode 备用码哈希
+func hashBackupCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// mfaKey MFA 数据索引键
+func mfaKey(userAddress string) string {
+	return strings.ToLower(strings.TrimSpace(userAddress))
+}
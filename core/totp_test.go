@@ -0,0 +1,121 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const totpTestAddress = "0xAbC0000000000000000000000000000000000001"
+
+// memoryTOTPStore 按值保存 TOTP 配置的存储，模拟重启后从数据库读取
+type memoryTOTPStore struct {
+	records map[string][2]*MFAProvider
+	loadErr error
+}
+
+func newMemoryTOTPStore() *memoryTOTPStore {
+	return &memoryTOTPStore{records: make(map[string][2]*MFAProvider)}
+}
+
+func cloneTOTPProvider(p *MFAProvider) *MFAProvider {
+	if p == nil {
+		return nil
+	}
+	return NewTOTPProvider(p.Secret, p.IsEnabled, append([]string(nil), p.BackupCodes...), p.TOTPLastStep(), p.LastUsed)
+}
+
+func (s *memoryTOTPStore) LoadTOTP(userAddress string) (*MFAProvider, *MFAProvider, error) {
+	if s.loadErr != nil {
+		return nil, nil, s.loadErr
+	}
+	r := s.records[userAddress]
+	return cloneTOTPProvider(r[0]), cloneTOTPProvider(r[1]), nil
+}
+
+func (s *memoryTOTPStore) SaveTOTP(userAddress string, active, pending *MFAProvider) error {
+	s.records[userAddress] = [2]*MFAProvider{cloneTOTPProvider(active), cloneTOTPProvider(pending)}
+	return nil
+}
+
+// newTOTPTestManager 模拟一次进程启动：新的认证管理器使用同一个存储
+func newTOTPTestManager(store TOTPStore) *AuthenticationManager {
+	am := NewAuthenticationManager()
+	am.SetTOTPStore(store)
+	return am
+}
+
+func currentTOTPCode(t *testing.T, secret string) string {
+	t.Helper()
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("解码密钥: %v", err)
+	}
+	return totpCode(key, time.Now().Unix()/totpPeriod)
+}
+
+func TestTOTPEnrollmentSurvivesRestart(t *testing.T) {
+	store := newMemoryTOTPStore()
+	am := newTOTPTestManager(store)
+	secret, _, err := am.EnableTOTP(totpTestAddress, "")
+	if err != nil {
+		t.Fatalf("EnableTOTP: %v", err)
+	}
+	if newTOTPTestManager(store).IsTOTPEnabled(totpTestAddress) {
+		t.Fatal("首次验证前不应启用")
+	}
+
+	code := currentTOTPCode(t, secret)
+	if ok, err := newTOTPTestManager(store).VerifyTOTP(totpTestAddress, code); err != nil || !ok {
+		t.Fatalf("重启后验证待生效密钥 = %v, %v", ok, err)
+	}
+
+	restarted := newTOTPTestManager(store)
+	if !restarted.IsTOTPEnabled(totpTestAddress) {
+		t.Fatal("重启后应保持已启用")
+	}
+	if ok, err := restarted.VerifyTOTP(totpTestAddress, code); err != nil || ok {
+		t.Fatalf("重启后重放已使用的验证码 = %v, %v，期望拒绝", ok, err)
+	}
+	if _, _, err := restarted.EnableTOTP(totpTestAddress, ""); !errors.Is(err, ErrMFACodeRequired) {
+		t.Fatalf("重启后更换密钥 err = %v，期望 ErrMFACodeRequired", err)
+	}
+}
+
+func TestTOTPBackupCodeConsumedAcrossRestart(t *testing.T) {
+	store := newMemoryTOTPStore()
+	am := newTOTPTestManager(store)
+	secret, _, err := am.EnableTOTP(totpTestAddress, "")
+	if err != nil {
+		t.Fatalf("EnableTOTP: %v", err)
+	}
+	codes, err := am.GenerateBackupCodes(totpTestAddress)
+	if err != nil {
+		t.Fatalf("GenerateBackupCodes: %v", err)
+	}
+	if ok, err := am.VerifyTOTP(totpTestAddress, currentTOTPCode(t, secret)); err != nil || !ok {
+		t.Fatalf("VerifyTOTP = %v, %v", ok, err)
+	}
+
+	if ok, err := newTOTPTestManager(store).VerifyBackupCode(totpTestAddress, codes[0]); err != nil || !ok {
+		t.Fatalf("重启后使用备用码 = %v, %v", ok, err)
+	}
+	if ok, err := newTOTPTestManager(store).VerifyBackupCode(totpTestAddress, codes[0]); err != nil || ok {
+		t.Fatalf("重启后再次使用同一备用码 = %v, %v，期望拒绝", ok, err)
+	}
+	if ok, err := newTOTPTestManager(store).VerifyBackupCode(totpTestAddress, codes[1]); err != nil || !ok {
+		t.Fatalf("其他备用码 = %v, %v，期望可用", ok, err)
+	}
+}
+
+func TestTOTPStoreFailureFailsClosed(t *testing.T) {
+	store := newMemoryTOTPStore()
+	store.loadErr = errors.New("database unavailable")
+	am := newTOTPTestManager(store)
+	if !am.IsTOTPEnabled(totpTestAddress) {
+		t.Fatal("无法读取存储时应要求双因素认证")
+	}
+	if ok, err := am.VerifyTOTP(totpTestAddress, "123456"); err == nil || ok {
+		t.Fatalf("无法读取存储时 VerifyTOTP = %v, %v，期望返回错误", ok, err)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+交易确认等待
+
+轮询交易回执，直到交易被打包且链头超过回执所在区块指定的确认数。
+每轮都会核对回执所在区块哈希是否仍在主链上：发生重组时旧回执作废，继续等待交易被重新打包。
+各网络的最终确认数见 RequiredConfirmations（由网络配置 min_confirmations 或链默认值设置），调用方未指定确认数时以此为准。
+*/
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// receiptPollInterval 轮询回执的间隔
+const receiptPollInterval = 2 * time.Second
+
+// ErrConfirmationTimeout 在上下文截止前交易未达到要求的确认数
+var ErrConfirmationTimeout = errors.New("等待交易确认超时")
+
+// SetRequiredConfirmations 设置交易视为最终确认所需的确认数
+func (a *EVMAdapter) SetRequiredConfirmations(confirmations uint64) {
+	a.confirmations = confirmations
+}
+
+// RequiredConfirmations 交易视为最终确认所需的确认数（交易所在区块之后的出块数）
+func (a *EVMAdapter) RequiredConfirmations() uint64 {
+	return a.confirmations
+}
+
+// Confirmations 按当前链头计算 blockNumber 所在区块之后的出块数
+func Confirmations(head, blockNumber uint64) uint64 {
+	if head <= blockNumber {
+		return 0
+	}
+	return head - blockNumber
+}
+
+// WaitForReceipt 等待交易被打包并达到 confirmations 个确认后返回回执
+// confirmations 为回执所在区块之后还需出块的数量，0 表示打包即返回
+func (a *EVMAdapter) WaitForReceipt(ctx context.Context, txHash string, confirmations uint64) (*types.Receipt, error) {
+	hash := common.HexToHash(txHash)
+	ticker := time.NewTicker(receiptPollInterval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		receipt, err := a.confirmedReceipt(ctx, hash, confirmations)
+		if err != nil {
+			lastErr = err
+		} else if receipt != nil {
+			return receipt, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if lastErr != nil {
+				return nil, fmt.Errorf("%w: %v（最近一次错误: %v）", ErrConfirmationTimeout, ctx.Err(), lastErr)
+			}
+			return nil, fmt.Errorf("%w: %v", ErrConfirmationTimeout, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// confirmedReceipt 单轮检查：尚未打包、已被重组或确认数不足时返回 nil
+func (a *EVMAdapter) confirmedReceipt(ctx context.Context, hash common.Hash, confirmations uint64) (*types.Receipt, error) {
+	receipt, err := a.client.TransactionReceipt(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("获取交易回执失败: %w", err)
+	}
+
+	// 重组检查：回执所在区块需仍为该高度的主链区块
+	header, err := a.client.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("获取区块头失败: %w", err)
+	}
+	if header.Hash() != receipt.BlockHash {
+		return nil, nil
+	}
+
+	head, err := a.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取最新区块失败: %w", err)
+	}
+	if head < receipt.BlockNumber.Uint64()+confirmations {
+		return nil, nil
+	}
+	return receipt, nil
+}
@@ -0,0 +1,324 @@
+/*
+交易替换（加速 / 取消）
+
+以相同 nonce 重新签发交易顶替仍在交易池中的交易：
+- 加速：原样重发原交易的 to/value/data，仅提高费率
+- 取消：向自身发送 0 值交易
+
+节点要求替换交易的费率（legacy 的 gasPrice，或 EIP-1559 的 tip 与 feeCap）均比原交易高至少 10%，
+否则返回 replacement transaction underpriced。原交易费率优先从交易池（txpool_contentFrom）或原交易哈希读取，
+均不可用时使用调用方提供的原费率。
+*/
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ReplacementMinBumpPercent 替换交易费率相对原交易的最小涨幅（与 geth 默认 txpool.pricebump 一致）
+const ReplacementMinBumpPercent = 10
+
+// replacementAutoBumpPercent 未指定新费率时的自动涨幅，略高于最小涨幅以留出余量
+const replacementAutoBumpPercent = 15
+
+// ErrReplacementUnderpriced 替换交易费率涨幅不足
+var ErrReplacementUnderpriced = errors.New("替换交易费率需比原交易至少高10%")
+
+// ReplaceOptions 替换交易参数
+type ReplaceOptions struct {
+	GasPrice *big.Int // 新交易 legacy 费率
+	TipCap   *big.Int // 新交易 EIP-1559 maxPriorityFeePerGas
+	FeeCap   *big.Int // 新交易 EIP-1559 maxFeePerGas
+	GasLimit uint64   // 为 0 时加速沿用原交易 gasLimit，取消使用 21000
+
+	OriginalTxHash   string   // 可选：原交易哈希，节点不支持 txpool_contentFrom 时用于定位原交易
+	OriginalGasPrice *big.Int // 可选：原交易 legacy 费率（无法查询原交易时用于校验涨幅）
+	OriginalTipCap   *big.Int // 可选：原交易 maxPriorityFeePerGas
+	OriginalFeeCap   *big.Int // 可选：原交易 maxFeePerGas
+
+	MaxFeeWei    *big.Int // 本笔替换交易的手续费上限（wei），为空时使用默认上限
+	AllowHighFee bool     // 调用方已确认高手续费，跳过上限检查
+}
+
+// TxFee 交易费率（legacy 仅 GasPrice，EIP-1559 为 TipCap/FeeCap）
+type TxFee struct {
+	GasPrice *big.Int
+	TipCap   *big.Int
+	FeeCap   *big.Int
+}
+
+// IsDynamic 是否为 EIP-1559 费率
+func (f TxFee) IsDynamic() bool {
+	return f.TipCap != nil || f.FeeCap != nil
+}
+
+// IsZero 是否未设置任何费率
+func (f TxFee) IsZero() bool {
+	return f.GasPrice == nil && f.TipCap == nil && f.FeeCap == nil
+}
+
+// caps 返回用于比较的 (tip, feeCap)，legacy 交易两者均为 gasPrice
+func (f TxFee) caps() (*big.Int, *big.Int) {
+	if f.IsDynamic() {
+		return f.TipCap, f.FeeCap
+	}
+	return f.GasPrice, f.GasPrice
+}
+
+// TxFeeOf 读取交易的费率
+func TxFeeOf(tx *types.Transaction) TxFee {
+	if tx.Type() == types.LegacyTxType || tx.Type() == types.AccessListTxType {
+		return TxFee{GasPrice: tx.GasPrice()}
+	}
+	return TxFee{TipCap: tx.GasTipCap(), FeeCap: tx.GasFeeCap()}
+}
+
+// CheckReplacementFee 校验替换费率的 tip 与 feeCap 是否均比原交易高至少 ReplacementMinBumpPercent
+func CheckReplacementFee(original, replacement TxFee) error {
+	origTip, origCap := original.caps()
+	newTip, newCap := replacement.caps()
+	if origTip == nil || origCap == nil {
+		return nil
+	}
+	if newTip == nil || newCap == nil {
+		return fmt.Errorf("%w: 未提供完整的新费率", ErrReplacementUnderpriced)
+	}
+	minTip := bumpPercent(origTip, ReplacementMinBumpPercent)
+	minCap := bumpPercent(origCap, ReplacementMinBumpPercent)
+	if newTip.Cmp(minTip) < 0 || newCap.Cmp(minCap) < 0 {
+		return fmt.Errorf("%w: 原交易 tip=%s feeCap=%s，新交易至少需 tip=%s feeCap=%s",
+			ErrReplacementUnderpriced, origTip, origCap, minTip, minCap)
+	}
+	return nil
+}
+
+// FindPendingTransaction 通过 txpool_contentFrom 在交易池中按 nonce 查找发送方的待处理交易
+// 未找到时返回 nil；节点不支持该接口时返回错误
+func (a *EVMAdapter) FindPendingTransaction(ctx context.Context, from common.Address, nonce uint64) (*types.Transaction, error) {
+	content, err := a.txPoolContentFrom(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("%d", nonce)
+	for _, pool := range []string{"pending", "queued"} {
+		if tx, ok := content[pool][key]; ok && tx != nil {
+			return tx, nil
+		}
+	}
+	return nil, nil
+}
+
+// ReplaceTransaction 以相同 nonce 重发原交易并提高费率（加速）
+// 原交易从交易池或 newOpts.OriginalTxHash 获取，两者均不可用时无法得知原交易内容
+func (a *EVMAdapter) ReplaceTransaction(ctx context.Context, mnemonic, derivationPath string, originalNonce uint64, newOpts *ReplaceOptions) (string, error) {
+	signer, err := NewMnemonicSigner(mnemonic, derivationPath)
+	if err != nil {
+		return "", err
+	}
+	return a.ReplaceTransactionWithSigner(ctx, signer, originalNonce, newOpts)
+}
+
+// ReplaceTransactionWithSigner 使用指定签名者加速 nonce 对应的待处理交易
+func (a *EVMAdapter) ReplaceTransactionWithSigner(ctx context.Context, signer Signer, originalNonce uint64, newOpts *ReplaceOptions) (string, error) {
+	fromAddr := signer.Address()
+	if newOpts == nil {
+		newOpts = &ReplaceOptions{}
+	}
+	original, base, err := a.resolveReplacementBase(ctx, fromAddr, originalNonce, newOpts)
+	if err != nil {
+		return "", err
+	}
+	if original == nil {
+		return "", fmt.Errorf("未在交易池中找到 nonce=%d 的待处理交易，请提供原交易哈希", originalNonce)
+	}
+	fee, err := a.replacementFee(ctx, base, newOpts)
+	if err != nil {
+		return "", err
+	}
+	gasLimit := original.Gas()
+	if newOpts.GasLimit > 0 {
+		gasLimit = newOpts.GasLimit
+	}
+	return a.sendWithNonce(ctx, signer, originalNonce, original.To(), original.Value(), original.Data(), gasLimit, fee, newOpts)
+}
+
+// CancelTransaction 以相同 nonce 向自身发送 0 值交易，用于顶替仍在交易池中的交易
+// 未指定新费率时在原交易费率基础上自动上调；已知原费率时校验涨幅不低于 10%
+func (a *EVMAdapter) CancelTransaction(ctx context.Context, mnemonic, derivationPath string, nonce uint64, newOpts *ReplaceOptions) (string, error) {
+	signer, err := NewMnemonicSigner(mnemonic, derivationPath)
+	if err != nil {
+		return "", err
+	}
+	return a.CancelTransactionWithSigner(ctx, signer, nonce, newOpts)
+}
+
+// CancelTransactionWithSigner 使用指定签名者取消 nonce 对应的待处理交易
+func (a *EVMAdapter) CancelTransactionWithSigner(ctx context.Context, signer Signer, nonce uint64, newOpts *ReplaceOptions) (string, error) {
+	fromAddr := signer.Address()
+	if newOpts == nil {
+		newOpts = &ReplaceOptions{}
+	}
+	_, base, err := a.resolveReplacementBase(ctx, fromAddr, nonce, newOpts)
+	if err != nil {
+		return "", err
+	}
+	fee, err := a.replacementFee(ctx, base, newOpts)
+	if err != nil {
+		return "", err
+	}
+	gasLimit := uint64(21000)
+	if newOpts.GasLimit > 0 {
+		gasLimit = newOpts.GasLimit
+	}
+	return a.sendWithNonce(ctx, signer, nonce, &fromAddr, big.NewInt(0), nil, gasLimit, fee, newOpts)
+}
+
+// resolveReplacementBase 定位原交易并确定其费率
+// 顺序：原交易哈希 → 交易池 → 调用方提供的原费率；返回的原交易可能为 nil
+func (a *EVMAdapter) resolveReplacementBase(ctx context.Context, from common.Address, nonce uint64, opts *ReplaceOptions) (*types.Transaction, TxFee, error) {
+	latest, err := a.client.NonceAt(ctx, from, nil)
+	if err != nil {
+		return nil, TxFee{}, fmt.Errorf("获取nonce失败: %w", err)
+	}
+	if nonce < latest {
+		return nil, TxFee{}, fmt.Errorf("nonce=%d 的交易已上链，无法替换", nonce)
+	}
+
+	var original *types.Transaction
+	if opts.OriginalTxHash != "" {
+		tx, isPending, err := a.GetTransactionByHash(ctx, opts.OriginalTxHash)
+		if err != nil {
+			return nil, TxFee{}, err
+		}
+		if !isPending {
+			return nil, TxFee{}, fmt.Errorf("原交易已打包，无法替换")
+		}
+		if tx.Nonce() != nonce {
+			return nil, TxFee{}, fmt.Errorf("原交易 nonce=%d 与指定 nonce=%d 不一致", tx.Nonce(), nonce)
+		}
+		sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil || sender != from {
+			return nil, TxFee{}, fmt.Errorf("原交易发送方与当前钱包地址不一致")
+		}
+		original = tx
+	} else if tx, err := a.FindPendingTransaction(ctx, from, nonce); err == nil {
+		// txpool_contentFrom 并非标准接口，失败时回退到调用方提供的原费率
+		original = tx
+	}
+
+	if original != nil {
+		return original, TxFeeOf(original), nil
+	}
+	return nil, TxFee{GasPrice: opts.OriginalGasPrice, TipCap: opts.OriginalTipCap, FeeCap: opts.OriginalFeeCap}, nil
+}
+
+// replacementFee 确定替换交易费率
+// 调用方指定新费率时校验涨幅；未指定时取 原费率上浮 replacementAutoBumpPercent 与当前建议费率中的较大值
+func (a *EVMAdapter) replacementFee(ctx context.Context, base TxFee, opts *ReplaceOptions) (TxFee, error) {
+	requested := TxFee{GasPrice: opts.GasPrice, TipCap: opts.TipCap, FeeCap: opts.FeeCap}
+	if !requested.IsZero() {
+		if requested.IsDynamic() && (requested.TipCap == nil || requested.FeeCap == nil) {
+			return TxFee{}, fmt.Errorf("EIP-1559 替换交易需同时指定 max_priority_fee_per_gas 与 max_fee_per_gas")
+		}
+		if err := CheckReplacementFee(base, requested); err != nil {
+			return TxFee{}, err
+		}
+		return requested, nil
+	}
+
+	sug, err := a.GetGasSuggestion(ctx)
+	if err != nil {
+		return TxFee{}, err
+	}
+	baseTip, baseCap := base.caps()
+	if base.IsZero() {
+		// 原费率未知，仅能使用当前建议费率，是否足以替换由节点判定
+		if sug.BaseFee != nil && sug.BaseFee.Sign() > 0 {
+			return TxFee{TipCap: sug.TipCap, FeeCap: sug.MaxFee}, nil
+		}
+		return TxFee{GasPrice: sug.GasPrice}, nil
+	}
+	if base.IsDynamic() {
+		tip := maxBigInt(bumpPercent(baseTip, replacementAutoBumpPercent), sug.TipCap)
+		feeCap := maxBigInt(bumpPercent(baseCap, replacementAutoBumpPercent), sug.MaxFee)
+		if feeCap.Cmp(tip) < 0 {
+			feeCap = new(big.Int).Set(tip)
+		}
+		return TxFee{TipCap: tip, FeeCap: feeCap}, nil
+	}
+	return TxFee{GasPrice: maxBigInt(bumpPercent(base.GasPrice, replacementAutoBumpPercent), sug.GasPrice)}, nil
+}
+
+// sendWithNonce 以指定 nonce 与费率签名并广播交易，签名前按 opts 校验手续费上限
+func (a *EVMAdapter) sendWithNonce(ctx context.Context, signer Signer, nonce uint64, to *common.Address, value *big.Int, data []byte, gasLimit uint64, fee TxFee, opts *ReplaceOptions) (string, error) {
+	// 签名前检测节点链ID，防止签出与所选网络不同链的交易
+	chainID, err := a.VerifyChainID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var tx *types.Transaction
+	if fee.IsDynamic() {
+		tx = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			To:        to,
+			Value:     value,
+			Gas:       gasLimit,
+			GasFeeCap: fee.FeeCap,
+			GasTipCap: fee.TipCap,
+			Data:      data,
+		})
+	} else {
+		tx = types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			To:       to,
+			Value:    value,
+			Gas:      gasLimit,
+			GasPrice: fee.GasPrice,
+			Data:     data,
+		})
+	}
+	var feeOpts *TxOptions
+	if opts != nil {
+		feeOpts = &TxOptions{MaxFeeWei: opts.MaxFeeWei, AllowHighFee: opts.AllowHighFee}
+	}
+	signedTx, err := a.signTx(ctx, signer, tx, chainID, feeOpts)
+	if err != nil {
+		return "", err
+	}
+	if err := a.client.SendTransaction(ctx, signedTx); err != nil {
+		return "", fmt.Errorf("广播交易失败: %w", err)
+	}
+	_ = a.waitBrief(ctx)
+	return signedTx.Hash().Hex(), nil
+}
+
+// bumpPercent 按百分比上浮，nil 视为 0
+func bumpPercent(v *big.Int, percent int64) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	out := new(big.Int).Mul(v, big.NewInt(100+percent))
+	return out.Div(out, big.NewInt(100))
+}
+
+// maxBigInt 返回两个数中的较大值，nil 视为 0
+func maxBigInt(a, b *big.Int) *big.Int {
+	if a == nil {
+		a = new(big.Int)
+	}
+	if b == nil {
+		b = new(big.Int)
+	}
+	if a.Cmp(b) >= 0 {
+		return new(big.Int).Set(a)
+	}
+	return new(big.Int).Set(b)
+}
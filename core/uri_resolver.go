@@ -0,0 +1,259 @@
+/*
+去中心化存储地址解析（NFT元数据、ENS头像）
+
+支持的地址：
+- ipfs://<cid>/<path>、ipns://<name>/<path>：依次改写为各网关的 /ipfs/、/ipns/ 路径（ipfs.gateways），前一个网关失败时尝试下一个
+- ar://<id>：改写为 arweave.net
+- data:[<mediatype>][;base64],<data>：直接解码，不发起请求
+- http(s)://：原样请求
+
+改写后只允许 http/https 地址（重定向同样校验），file://、gopher:// 等其他协议一律拒绝，防止借元数据地址访问本地资源。
+地址来自合约或 ENS 记录，由任何人控制：配置的网关以外的主机须解析到公网地址（每一跳重定向都重新校验），
+并通过 NewSafeTransport 连接，连接时再次校验实际 IP，防止借 DNS 重绑定访问内网。
+每个网关请求单独超时（ipfs.timeout_seconds），响应超过 ipfs.max_content_kb 时直接失败，不再尝试其他网关。
+*/
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"wallet/config"
+)
+
+// 地址解析错误
+var (
+	ErrUnsupportedURI      = errors.New("不支持的内容地址")
+	ErrURIContentTooLarge  = errors.New("内容超过大小上限")
+	errTooManyURIRedirects = errors.New("重定向次数过多")
+)
+
+const (
+	arweaveGateway       = "https://arweave.net/"
+	uriMaxRedirects      = 5
+	dataURIDefaultMedium = "text/plain;charset=US-ASCII" // RFC 2397 未声明类型时的默认值
+)
+
+// URIResolver 将 ipfs:// 等地址改写为HTTP网关地址并获取内容
+type URIResolver struct {
+	gateways     []string // 网关根地址（不含末尾 /）
+	gatewayHosts []string // 配置的网关与 arweave.net 的主机名，可信，允许指向内网（如本地 IPFS 节点）
+	maxSize      int64    // 内容大小上限（字节）
+	httpClient   *http.Client
+	safeClient   *http.Client // 请求网关以外的主机，只连接公网地址
+}
+
+var (
+	defaultURIResolver     *URIResolver
+	defaultURIResolverOnce sync.Once
+)
+
+// NewURIResolver 按配置创建地址解析器
+func NewURIResolver(ipfsConfig config.IPFSConfig) *URIResolver {
+	ipfsConfig = ipfsConfig.WithDefaults()
+	r := &URIResolver{
+		gateways: ipfsConfig.Gateways,
+		maxSize:  int64(ipfsConfig.MaxContentKB) << 10,
+	}
+	for _, gateway := range append([]string{arweaveGateway}, ipfsConfig.Gateways...) {
+		if u, err := url.Parse(gateway); err == nil && u.Hostname() != "" {
+			r.gatewayHosts = append(r.gatewayHosts, u.Hostname())
+		}
+	}
+	timeout := time.Duration(ipfsConfig.TimeoutSeconds) * time.Second
+	r.httpClient = &http.Client{Timeout: timeout, CheckRedirect: r.checkRedirect}
+	r.safeClient = &http.Client{Timeout: timeout, CheckRedirect: r.checkRedirect, Transport: NewSafeTransport()}
+	return r
+}
+
+// checkRedirect 校验每一跳重定向：次数上限、协议，网关以外的主机须解析到公网地址
+func (r *URIResolver) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= uriMaxRedirects {
+		return errTooManyURIRedirects
+	}
+	if !isFetchableURL(req.URL) {
+		return fmt.Errorf("%w: 重定向到 %s", ErrUnsupportedURI, req.URL.Scheme)
+	}
+	if !r.isGatewayHost(req.URL.Hostname()) {
+		if err := CheckOutboundHost(req.Context(), req.URL.Hostname(), nil); err != nil {
+			return fmt.Errorf("%w: %v", ErrUnsupportedURI, err)
+		}
+	}
+	return nil
+}
+
+// isGatewayHost 主机是否为配置的网关
+func (r *URIResolver) isGatewayHost(host string) bool {
+	for _, gatewayHost := range r.gatewayHosts {
+		if strings.EqualFold(gatewayHost, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultURIResolver 按 config.AppConfig.IPFS 创建的共享解析器
+func DefaultURIResolver() *URIResolver {
+	defaultURIResolverOnce.Do(func() {
+		defaultURIResolver = NewURIResolver(config.AppConfig.IPFS)
+	})
+	return defaultURIResolver
+}
+
+// ResolveURI 使用共享解析器获取地址内容，返回内容与 Content-Type
+func ResolveURI(uri string) (content []byte, contentType string, err error) {
+	return DefaultURIResolver().Fetch(context.Background(), uri)
+}
+
+// Fetch 获取地址内容：data URI 直接解码，其余依次请求改写后的HTTP地址
+func (r *URIResolver) Fetch(ctx context.Context, uri string) ([]byte, string, error) {
+	uri = strings.TrimSpace(uri)
+	if strings.HasPrefix(uri, "data:") {
+		return r.decodeDataURI(uri)
+	}
+	candidates, err := r.CandidateURLs(uri)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var lastErr error
+	for _, candidate := range candidates {
+		content, contentType, err := r.fetchHTTP(ctx, candidate)
+		if err == nil {
+			return content, contentType, nil
+		}
+		// 超限、地址不允许与调用方取消在其他网关上结果相同，不再重试
+		if errors.Is(err, ErrURIContentTooLarge) || errors.Is(err, ErrUnsupportedURI) || ctx.Err() != nil {
+			return nil, "", err
+		}
+		lastErr = err
+	}
+	return nil, "", fmt.Errorf("获取 %s 失败: %w", uri, lastErr)
+}
+
+// CandidateURLs 按网关顺序返回可请求的HTTP地址，改写后不是 http/https 的地址返回 ErrUnsupportedURI
+func (r *URIResolver) CandidateURLs(uri string) ([]string, error) {
+	uri = strings.TrimSpace(uri)
+	var candidates []string
+	switch {
+	case uri == "":
+		return nil, fmt.Errorf("%w: 地址为空", ErrUnsupportedURI)
+	case strings.HasPrefix(uri, "ipfs://"):
+		path := strings.TrimLeft(strings.TrimPrefix(strings.TrimPrefix(uri, "ipfs://"), "ipfs/"), "/")
+		for _, gateway := range r.gateways {
+			candidates = append(candidates, gateway+"/ipfs/"+path)
+		}
+	case strings.HasPrefix(uri, "ipns://"):
+		path := strings.TrimLeft(strings.TrimPrefix(strings.TrimPrefix(uri, "ipns://"), "ipns/"), "/")
+		for _, gateway := range r.gateways {
+			candidates = append(candidates, gateway+"/ipns/"+path)
+		}
+	case strings.HasPrefix(uri, "ar://"):
+		candidates = []string{arweaveGateway + strings.TrimPrefix(uri, "ar://")}
+	default:
+		candidates = []string{uri}
+	}
+
+	for _, candidate := range candidates {
+		parsed, err := url.Parse(candidate)
+		if err != nil || !isFetchableURL(parsed) {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedURI, uri)
+		}
+	}
+	return candidates, nil
+}
+
+// GatewayURL 返回客户端可直接展示的地址：data URI 原样返回，其余为第一个网关地址，不支持的地址返回空字符串
+func (r *URIResolver) GatewayURL(uri string) string {
+	uri = strings.TrimSpace(uri)
+	if strings.HasPrefix(uri, "data:") {
+		return uri
+	}
+	candidates, err := r.CandidateURLs(uri)
+	if err != nil {
+		return ""
+	}
+	return candidates[0]
+}
+
+// fetchHTTP 请求单个HTTP地址，非200或超过大小上限时返回错误
+// 网关以外的主机须解析到公网地址，并经只连接公网地址的客户端请求
+func (r *URIResolver) fetchHTTP(ctx context.Context, target string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("创建请求失败: %w", err)
+	}
+	client := r.httpClient
+	if !r.isGatewayHost(req.URL.Hostname()) {
+		if err := CheckOutboundHost(ctx, req.URL.Hostname(), nil); err != nil {
+			return nil, "", fmt.Errorf("%w: %v", ErrUnsupportedURI, err)
+		}
+		client = r.safeClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("请求 %s 失败: %w", target, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("请求 %s 失败: HTTP %d", target, resp.StatusCode)
+	}
+	if resp.ContentLength > r.maxSize {
+		return nil, "", fmt.Errorf("%w: %d 字节", ErrURIContentTooLarge, resp.ContentLength)
+	}
+	content, err := io.ReadAll(io.LimitReader(resp.Body, r.maxSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("读取 %s 失败: %w", target, err)
+	}
+	if int64(len(content)) > r.maxSize {
+		return nil, "", fmt.Errorf("%w: 超过 %d 字节", ErrURIContentTooLarge, r.maxSize)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+	return content, contentType, nil
+}
+
+// decodeDataURI 解码 data:[<mediatype>][;base64],<data>
+func (r *URIResolver) decodeDataURI(uri string) ([]byte, string, error) {
+	comma := strings.IndexByte(uri, ',')
+	if comma < 0 {
+		return nil, "", fmt.Errorf("%w: 无效的 data URI", ErrUnsupportedURI)
+	}
+	meta, payload := uri[len("data:"):comma], uri[comma+1:]
+	base64Encoded := strings.HasSuffix(meta, ";base64")
+	contentType := strings.TrimSuffix(meta, ";base64")
+	if contentType == "" {
+		contentType = dataURIDefaultMedium
+	}
+
+	var content []byte
+	if base64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return nil, "", fmt.Errorf("解码 data URI 失败: %w", err)
+		}
+		content = decoded
+	} else if decoded, err := url.PathUnescape(payload); err == nil {
+		content = []byte(decoded)
+	} else {
+		content = []byte(payload)
+	}
+	if int64(len(content)) > r.maxSize {
+		return nil, "", fmt.Errorf("%w: 超过 %d 字节", ErrURIContentTooLarge, r.maxSize)
+	}
+	return content, contentType, nil
+}
+
+// isFetchableURL 是否为带主机名的 http/https 地址
+func isFetchableURL(u *url.URL) bool {
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,848 @@
+/*
+WalletConnect v2 配对与会话
+
+用户将 DApp 展示的配对 URI（wc:{topic}@2?relay-protocol=irn&symKey=...）交给钱包后：
+ 1. 订阅配对主题，收到 wc_sessionPropose 后由 OnProposal 决定批准的命名空间（账户、链、方法、事件），返回错误即拒绝
+ 2. 生成 X25519 密钥对，与 DApp 公钥协商出会话对称密钥（HKDF-SHA256），会话主题为对称密钥的 SHA-256
+ 3. 在配对主题上回复本方公钥，在会话主题上发送 wc_sessionSettle
+ 4. 会话主题上的 wc_sessionRequest 校验链与方法后交给 OnRequest，上层处理完成后通过 Respond 回复结果或错误
+
+消息使用 ChaCha20-Poly1305 加密，信封为 base64(类型 0 || iv || 密文)。
+会话到期、DApp 发送 wc_sessionDelete 或调用 Disconnect 后会话关闭并回调 OnClose。
+配对与会话仅保存在内存中，服务重启后需要重新配对。
+*/
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	wcPairingTTL       = 5 * time.Minute    // 配对 URI 未携带过期时间时的有效期
+	wcProposalWait     = 30 * time.Second   // Pair 等待会话提议的时间
+	wcMaxSessionExpiry = 7 * 24 * time.Hour // 会话最长有效期（协议上限）
+	wcSweepInterval    = time.Minute        // 过期会话与配对的清理间隔
+	wcSeenTTL          = 24 * time.Hour     // 已处理消息的去重记录保留时间
+	wcEnvelopeType0    = 0                  // 使用主题对称密钥加密的信封
+)
+
+// WalletConnect 错误码（与 WalletConnect SDK 的 getSdkError 一致）
+const (
+	WCErrUserRejected         = 5000
+	WCErrUnsupportedChains    = 5100
+	WCErrUnsupportedMethods   = 5101
+	WCErrUnsupportedNamespace = 5104
+	WCErrUserDisconnected     = 6000
+	WCErrExpired              = 8000
+)
+
+// ErrWCProposalPending 等待期间未收到 DApp 的会话提议，配对仍在有效期内，收到提议后会话在后台建立
+var ErrWCProposalPending = errors.New("尚未收到 DApp 的会话提议")
+
+// wcRPCOption 协议方法对应的中继标签与消息保存时长
+type wcRPCOption struct {
+	reqTag, resTag int
+	ttl            time.Duration
+}
+
+var wcRPCOptions = map[string]wcRPCOption{
+	"wc_pairingDelete":  {1000, 1001, 24 * time.Hour},
+	"wc_pairingPing":    {1002, 1003, 30 * time.Second},
+	"wc_sessionPropose": {1100, 1101, 5 * time.Minute},
+	"wc_sessionSettle":  {1102, 1103, 5 * time.Minute},
+	"wc_sessionUpdate":  {1104, 1105, 24 * time.Hour},
+	"wc_sessionExtend":  {1106, 1107, 24 * time.Hour},
+	"wc_sessionRequest": {1108, 1109, 5 * time.Minute},
+	"wc_sessionEvent":   {1110, 1111, 5 * time.Minute},
+	"wc_sessionDelete":  {1112, 1113, 24 * time.Hour},
+	"wc_sessionPing":    {1114, 1115, 30 * time.Second},
+}
+
+// wcProposalRejectTag 拒绝会话提议的中继标签
+const wcProposalRejectTag = 1120
+
+// WCError WalletConnect 协议错误
+type WCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *WCError) Error() string {
+	return fmt.Sprintf("%s (%d)", e.Message, e.Code)
+}
+
+// WCPairingURI 解析后的配对 URI
+type WCPairingURI struct {
+	Topic         string    // 配对主题
+	SymKey        []byte    // 配对对称密钥
+	RelayProtocol string    // 中继协议，目前只有 irn
+	ExpiresAt     time.Time // 配对过期时间，URI 未携带时为零值
+}
+
+// WCMetadata 钱包或 DApp 的展示信息
+type WCMetadata struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	URL         string   `json:"url"`
+	Icons       []string `json:"icons"`
+}
+
+// WCProposalNamespace DApp 请求的命名空间
+type WCProposalNamespace struct {
+	Chains  []string `json:"chains,omitempty"` // CAIP-2 链ID，如 eip155:1
+	Methods []string `json:"methods"`
+	Events  []string `json:"events"`
+}
+
+// WCNamespace 钱包批准的命名空间
+type WCNamespace struct {
+	Chains   []string `json:"chains,omitempty"`
+	Accounts []string `json:"accounts"` // CAIP-10 账户，如 eip155:1:0xab...
+	Methods  []string `json:"methods"`
+	Events   []string `json:"events"`
+}
+
+// WCProposal DApp 的会话提议
+type WCProposal struct {
+	ID                 int64                          `json:"id"`
+	PairingTopic       string                         `json:"pairing_topic"`
+	Proposer           WCMetadata                     `json:"proposer"`
+	RequiredNamespaces map[string]WCProposalNamespace `json:"required_namespaces"`
+	OptionalNamespaces map[string]WCProposalNamespace `json:"optional_namespaces"`
+}
+
+// WCSession 已建立的会话
+type WCSession struct {
+	Topic        string                 `json:"topic"`
+	PairingTopic string                 `json:"pairing_topic"`
+	Owner        string                 `json:"owner"` // 批准会话的钱包地址
+	Peer         WCMetadata             `json:"peer"`
+	Namespaces   map[string]WCNamespace `json:"namespaces"`
+	Expiry       time.Time              `json:"expiry"`
+	CreatedAt    time.Time              `json:"created_at"`
+
+	symKey   []byte
+	settleID int64
+}
+
+// WCSessionRequest DApp 通过会话发起的 RPC 请求
+type WCSessionRequest struct {
+	ID         int64           `json:"id"`
+	Topic      string          `json:"topic"`
+	ChainID    string          `json:"chain_id"` // CAIP-2 链ID
+	Method     string          `json:"method"`
+	Params     json.RawMessage `json:"params"`
+	ReceivedAt time.Time       `json:"received_at"`
+	ExpiresAt  *time.Time      `json:"expires_at,omitempty"` // DApp 指定的请求过期时间
+}
+
+// WCHandlers 上层对协议事件的处理
+type WCHandlers struct {
+	// OnProposal 返回批准的命名空间，返回错误时拒绝提议（*WCError 原样回复，其他错误按用户拒绝回复）
+	OnProposal func(owner string, proposal *WCProposal) (map[string]WCNamespace, error)
+	// OnSession 会话密钥协商完成、回复 DApp 之前调用，返回错误时拒绝提议
+	OnSession func(session *WCSession) error
+	// OnRequest 收到已通过链与方法校验的请求，需调用 Respond 回复
+	OnRequest func(session *WCSession, request *WCSessionRequest)
+	// OnClose 会话关闭：disconnected（本方断开）、peer_disconnected、expired、settle_failed
+	OnClose func(session *WCSession, reason string)
+}
+
+// WalletConnectManager WalletConnect v2 钱包端会话管理器
+type WalletConnectManager struct {
+	relay         *wcRelay
+	metadata      WCMetadata
+	sessionExpiry time.Duration
+	handlers      WCHandlers
+
+	mu       sync.RWMutex
+	pairings map[string]*wcPairing
+	sessions map[string]*WCSession
+	seen     map[string]time.Time // 已处理的消息，中继可能重复投递
+	stop     chan struct{}
+}
+
+// wcPairing 等待会话提议的配对
+type wcPairing struct {
+	topic     string
+	symKey    []byte
+	owner     string
+	expiresAt time.Time
+
+	once    sync.Once
+	done    chan struct{}
+	session *WCSession
+	err     error
+}
+
+func (p *wcPairing) finish(session *WCSession, err error) {
+	p.once.Do(func() {
+		p.session, p.err = session, err
+		close(p.done)
+	})
+}
+
+// NewWalletConnectManager 创建会话管理器，relayURL 为中继 WebSocket 地址，projectID 为 WalletConnect Cloud 项目ID
+// sessionExpiry 为批准的会话有效期，超过协议上限（7天）时按上限处理
+func NewWalletConnectManager(relayURL, projectID string, metadata WCMetadata, sessionExpiry time.Duration, handlers WCHandlers) (*WalletConnectManager, error) {
+	if projectID == "" {
+		return nil, fmt.Errorf("未配置 WalletConnect 项目ID")
+	}
+	if sessionExpiry <= 0 || sessionExpiry > wcMaxSessionExpiry {
+		sessionExpiry = wcMaxSessionExpiry
+	}
+	m := &WalletConnectManager{
+		metadata:      metadata,
+		sessionExpiry: sessionExpiry,
+		handlers:      handlers,
+		pairings:      make(map[string]*wcPairing),
+		sessions:      make(map[string]*WCSession),
+		seen:          make(map[string]time.Time),
+		stop:          make(chan struct{}),
+	}
+	relay, err := newWCRelay(relayURL, projectID, m.handleMessage)
+	if err != nil {
+		return nil, err
+	}
+	m.relay = relay
+	go m.sweepLoop()
+	return m, nil
+}
+
+// ParseWalletConnectURI 解析 v2 配对 URI
+func ParseWalletConnectURI(raw string) (*WCPairingURI, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.HasPrefix(raw, "wc:") {
+		return nil, fmt.Errorf("无效的 WalletConnect URI: 需要以 wc: 开头")
+	}
+	body := strings.TrimPrefix(strings.TrimPrefix(raw, "wc:"), "//")
+	topic, rest, ok := strings.Cut(body, "@")
+	if !ok || topic == "" {
+		return nil, fmt.Errorf("无效的 WalletConnect URI: 缺少配对主题")
+	}
+	version, query, _ := strings.Cut(rest, "?")
+	if version != "2" {
+		return nil, fmt.Errorf("仅支持 WalletConnect v2 配对 URI（当前版本: %s）", version)
+	}
+	if raw, err := hex.DecodeString(topic); err != nil || len(raw) != 32 {
+		return nil, fmt.Errorf("无效的 WalletConnect URI: 配对主题格式不正确")
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("无效的 WalletConnect URI: %w", err)
+	}
+
+	uri := &WCPairingURI{Topic: topic, RelayProtocol: values.Get("relay-protocol")}
+	if uri.RelayProtocol == "" {
+		uri.RelayProtocol = "irn"
+	}
+	if uri.RelayProtocol != "irn" {
+		return nil, fmt.Errorf("不支持的中继协议: %s", uri.RelayProtocol)
+	}
+	if uri.SymKey, err = hex.DecodeString(values.Get("symKey")); err != nil || len(uri.SymKey) != 32 {
+		return nil, fmt.Errorf("无效的 WalletConnect URI: symKey 需要是 32 字节 hex")
+	}
+	if expiry := values.Get("expiryTimestamp"); expiry != "" {
+		seconds, err := strconv.ParseInt(expiry, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("无效的 WalletConnect URI: expiryTimestamp 格式不正确")
+		}
+		uri.ExpiresAt = time.Unix(seconds, 0)
+	}
+	return uri, nil
+}
+
+// Pair 使用配对 URI 与 DApp 配对，owner 为批准会话的钱包地址
+// 在等待时间内收到并批准提议时返回建立的会话；提议被 OnProposal 拒绝时返回其错误；
+// 未收到提议时返回 ErrWCProposalPending，配对在有效期内继续等待
+func (m *WalletConnectManager) Pair(ctx context.Context, rawURI, owner string) (*WCSession, error) {
+	uri, err := ParseWalletConnectURI(rawURI)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := uri.ExpiresAt
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(wcPairingTTL)
+	}
+	if time.Now().After(expiresAt) {
+		return nil, fmt.Errorf("配对 URI 已过期，请在 DApp 中重新生成")
+	}
+
+	m.mu.Lock()
+	if _, exists := m.pairings[uri.Topic]; exists {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("该配对 URI 正在处理中")
+	}
+	for _, session := range m.sessions {
+		if session.PairingTopic == uri.Topic {
+			m.mu.Unlock()
+			return nil, fmt.Errorf("该配对 URI 已建立会话: %s", session.Topic)
+		}
+	}
+	pairing := &wcPairing{topic: uri.Topic, symKey: uri.SymKey, owner: owner, expiresAt: expiresAt, done: make(chan struct{})}
+	m.pairings[uri.Topic] = pairing
+	m.mu.Unlock()
+
+	if err := m.relay.subscribe(ctx, uri.Topic); err != nil {
+		m.removePairing(uri.Topic)
+		return nil, err
+	}
+
+	timer := time.NewTimer(wcProposalWait)
+	defer timer.Stop()
+	select {
+	case <-pairing.done:
+		if pairing.err != nil {
+			return nil, pairing.err
+		}
+		return pairing.session.snapshot(), nil
+	case <-timer.C:
+		return nil, ErrWCProposalPending
+	case <-ctx.Done():
+		return nil, ErrWCProposalPending
+	}
+}
+
+// Sessions 列出钱包地址的有效会话，按建立时间倒序；owner 为空时列出全部
+func (m *WalletConnectManager) Sessions(owner string) []*WCSession {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	now := time.Now()
+	out := make([]*WCSession, 0)
+	for _, session := range m.sessions {
+		if now.Before(session.Expiry) && (owner == "" || strings.EqualFold(session.Owner, owner)) {
+			out = append(out, session.snapshot())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out
+}
+
+// Session 查询有效会话
+func (m *WalletConnectManager) Session(topic string) (*WCSession, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	session, ok := m.sessions[topic]
+	if !ok || time.Now().After(session.Expiry) {
+		return nil, false
+	}
+	return session.snapshot(), true
+}
+
+// Respond 回复会话请求，rpcErr 非空时回复错误
+func (m *WalletConnectManager) Respond(ctx context.Context, topic string, requestID int64, result interface{}, rpcErr *WCError) error {
+	m.mu.RLock()
+	session, ok := m.sessions[topic]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("WalletConnect 会话不存在或已断开")
+	}
+	if rpcErr != nil {
+		return m.sendError(ctx, topic, session.symKey, "wc_sessionRequest", requestID, rpcErr, 0)
+	}
+	return m.sendResult(ctx, topic, session.symKey, "wc_sessionRequest", requestID, result)
+}
+
+// Disconnect 通知 DApp 断开并关闭会话
+func (m *WalletConnectManager) Disconnect(ctx context.Context, topic string) error {
+	m.mu.RLock()
+	session, ok := m.sessions[topic]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("WalletConnect 会话不存在或已断开")
+	}
+	params := &WCError{Code: WCErrUserDisconnected, Message: "User disconnected."}
+	if _, err := m.sendRequest(ctx, topic, session.symKey, "wc_sessionDelete", params); err != nil {
+		slog.WarnContext(ctx, "walletconnect session delete notification failed", "topic", topic, "error", err)
+	}
+	m.removeSession(topic, "disconnected")
+	return nil
+}
+
+// Close 停止清理任务并断开中继
+func (m *WalletConnectManager) Close() {
+	close(m.stop)
+	m.relay.close()
+}
+
+// handleMessage 解密并分发中继推送的消息
+func (m *WalletConnectManager) handleMessage(topic, message string) {
+	// 中继会把本端发布的消息同样推送给订阅者，直接忽略
+	if !m.markSeen(topic, wcMessageKey(message)) {
+		return
+	}
+	m.mu.RLock()
+	pairing := m.pairings[topic]
+	session := m.sessions[topic]
+	m.mu.RUnlock()
+
+	var key []byte
+	switch {
+	case session != nil:
+		key = session.symKey
+	case pairing != nil:
+		key = pairing.symKey
+	default:
+		return
+	}
+	payload, err := wcDecrypt(key, message)
+	if err != nil {
+		slog.WarnContext(context.Background(), "walletconnect message decrypt failed", "topic", topic, "error", err)
+		return
+	}
+	var msg wcRPCMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		slog.WarnContext(context.Background(), "walletconnect message decode failed", "topic", topic, "error", err)
+		return
+	}
+	if !m.markSeen(topic, fmt.Sprintf("%d|%t", msg.ID, msg.Method != "")) {
+		return
+	}
+
+	ctx := context.Background()
+	if session != nil {
+		m.handleSessionMessage(ctx, session, &msg)
+		return
+	}
+	switch msg.Method {
+	case "wc_sessionPropose":
+		m.handleProposal(ctx, pairing, &msg)
+	case "wc_pairingPing":
+		_ = m.sendResult(ctx, topic, key, msg.Method, msg.ID, true)
+	case "wc_pairingDelete":
+		_ = m.sendResult(ctx, topic, key, msg.Method, msg.ID, true)
+		pairing.finish(nil, fmt.Errorf("DApp 已取消配对"))
+		m.removePairing(topic)
+	}
+}
+
+// handleSessionMessage 处理会话主题上的消息
+func (m *WalletConnectManager) handleSessionMessage(ctx context.Context, session *WCSession, msg *wcRPCMessage) {
+	if msg.Method == "" {
+		m.mu.RLock()
+		settleID := session.settleID
+		m.mu.RUnlock()
+		if msg.ID == settleID && msg.Error != nil {
+			slog.WarnContext(ctx, "dapp rejected walletconnect session settle", "topic", session.Topic, "error", msg.Error)
+			m.removeSession(session.Topic, "settle_failed")
+		}
+		return
+	}
+
+	switch msg.Method {
+	case "wc_sessionRequest":
+		m.handleSessionRequest(ctx, session, msg)
+	case "wc_sessionPing":
+		_ = m.sendResult(ctx, session.Topic, session.symKey, msg.Method, msg.ID, true)
+	case "wc_sessionDelete":
+		_ = m.sendResult(ctx, session.Topic, session.symKey, msg.Method, msg.ID, true)
+		m.removeSession(session.Topic, "peer_disconnected")
+	default:
+		// 会话更新、续期与事件只能由钱包（控制方）发起
+		_ = m.sendError(ctx, session.Topic, session.symKey, msg.Method, msg.ID,
+			&WCError{Code: -32601, Message: "Method not supported: " + msg.Method}, 0)
+	}
+}
+
+// handleProposal 处理会话提议：由 OnProposal 决定批准或拒绝，批准后建立会话
+func (m *WalletConnectManager) handleProposal(ctx context.Context, pairing *wcPairing, msg *wcRPCMessage) {
+	defer m.removePairing(pairing.topic)
+
+	var params struct {
+		Proposer struct {
+			PublicKey string     `json:"publicKey"`
+			Metadata  WCMetadata `json:"metadata"`
+		} `json:"proposer"`
+		RequiredNamespaces map[string]WCProposalNamespace `json:"requiredNamespaces"`
+		OptionalNamespaces map[string]WCProposalNamespace `json:"optionalNamespaces"`
+		ExpiryTimestamp    int64                          `json:"expiryTimestamp"`
+	}
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		pairing.finish(nil, fmt.Errorf("无效的会话提议: %w", err))
+		return
+	}
+	if params.ExpiryTimestamp > 0 && time.Now().Unix() > params.ExpiryTimestamp {
+		err := &WCError{Code: WCErrExpired, Message: "Proposal expired"}
+		_ = m.sendError(ctx, pairing.topic, pairing.symKey, msg.Method, msg.ID, err, wcProposalRejectTag)
+		pairing.finish(nil, fmt.Errorf("会话提议已过期，请在 DApp 中重新连接"))
+		return
+	}
+
+	proposal := &WCProposal{
+		ID:                 msg.ID,
+		PairingTopic:       pairing.topic,
+		Proposer:           params.Proposer.Metadata,
+		RequiredNamespaces: params.RequiredNamespaces,
+		OptionalNamespaces: params.OptionalNamespaces,
+	}
+	namespaces, err := m.handlers.OnProposal(pairing.owner, proposal)
+	if err != nil {
+		m.rejectProposal(ctx, pairing, msg.ID, err)
+		pairing.finish(nil, err)
+		return
+	}
+	session, err := m.settle(ctx, pairing, proposal, params.Proposer.PublicKey, namespaces)
+	pairing.finish(session, err)
+}
+
+// settle 协商会话密钥，回复提议并发送 wc_sessionSettle
+func (m *WalletConnectManager) settle(ctx context.Context, pairing *wcPairing, proposal *WCProposal, proposerKey string, namespaces map[string]WCNamespace) (*WCSession, error) {
+	peerKey, err := hex.DecodeString(proposerKey)
+	if err != nil || len(peerKey) != curve25519.PointSize {
+		err = fmt.Errorf("会话提议中的公钥格式不正确")
+		m.rejectProposal(ctx, pairing, proposal.ID, err)
+		return nil, err
+	}
+	privateKey := make([]byte, curve25519.ScalarSize)
+	if _, err := rand.Read(privateKey); err != nil {
+		return nil, fmt.Errorf("生成会话密钥失败: %w", err)
+	}
+	publicKey, err := curve25519.X25519(privateKey, curve25519.Basepoint)
+	if err != nil {
+		return nil, fmt.Errorf("生成会话密钥失败: %w", err)
+	}
+	symKey, err := wcDeriveSymKey(privateKey, peerKey)
+	if err != nil {
+		m.rejectProposal(ctx, pairing, proposal.ID, err)
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &WCSession{
+		Topic:        wcTopic(symKey),
+		PairingTopic: pairing.topic,
+		Owner:        pairing.owner,
+		Peer:         proposal.Proposer,
+		Namespaces:   namespaces,
+		Expiry:       now.Add(m.sessionExpiry),
+		CreatedAt:    now,
+		symKey:       symKey,
+	}
+	if m.handlers.OnSession != nil {
+		if err := m.handlers.OnSession(session.snapshot()); err != nil {
+			m.rejectProposal(ctx, pairing, proposal.ID, err)
+			return nil, err
+		}
+	}
+
+	m.mu.Lock()
+	m.sessions[session.Topic] = session
+	m.mu.Unlock()
+	fail := func(err error) (*WCSession, error) {
+		m.removeSession(session.Topic, "settle_failed")
+		return nil, err
+	}
+
+	if err := m.relay.subscribe(ctx, session.Topic); err != nil {
+		return fail(err)
+	}
+	relay := map[string]string{"protocol": "irn"}
+	approval := map[string]interface{}{"relay": relay, "responderPublicKey": hex.EncodeToString(publicKey)}
+	if err := m.sendResult(ctx, pairing.topic, pairing.symKey, "wc_sessionPropose", proposal.ID, approval); err != nil {
+		return fail(err)
+	}
+	settleParams := map[string]interface{}{
+		"relay":      relay,
+		"namespaces": namespaces,
+		"controller": map[string]interface{}{"publicKey": hex.EncodeToString(publicKey), "metadata": m.metadata},
+		"expiry":     session.Expiry.Unix(),
+	}
+	settleID, err := m.sendRequest(ctx, session.Topic, symKey, "wc_sessionSettle", settleParams)
+	if err != nil {
+		return fail(err)
+	}
+	m.mu.Lock()
+	session.settleID = settleID
+	m.mu.Unlock()
+	return session.snapshot(), nil
+}
+
+// handleSessionRequest 校验会话请求的链与方法后交给 OnRequest
+func (m *WalletConnectManager) handleSessionRequest(ctx context.Context, session *WCSession, msg *wcRPCMessage) {
+	var params struct {
+		Request struct {
+			Method          string          `json:"method"`
+			Params          json.RawMessage `json:"params"`
+			ExpiryTimestamp int64           `json:"expiryTimestamp"`
+		} `json:"request"`
+		ChainID string `json:"chainId"`
+	}
+	reply := func(err *WCError) {
+		_ = m.sendError(ctx, session.Topic, session.symKey, msg.Method, msg.ID, err, 0)
+	}
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		reply(&WCError{Code: -32602, Message: "Invalid params"})
+		return
+	}
+	if time.Now().After(session.Expiry) {
+		reply(&WCError{Code: WCErrExpired, Message: "Session expired"})
+		return
+	}
+	if err := session.supports(params.ChainID, params.Request.Method); err != nil {
+		reply(err)
+		return
+	}
+
+	request := &WCSessionRequest{
+		ID:         msg.ID,
+		Topic:      session.Topic,
+		ChainID:    params.ChainID,
+		Method:     params.Request.Method,
+		Params:     params.Request.Params,
+		ReceivedAt: time.Now(),
+	}
+	if params.Request.ExpiryTimestamp > 0 {
+		expiresAt := time.Unix(params.Request.ExpiryTimestamp, 0)
+		request.ExpiresAt = &expiresAt
+	}
+	m.handlers.OnRequest(session.snapshot(), request)
+}
+
+// rejectProposal 拒绝会话提议
+func (m *WalletConnectManager) rejectProposal(ctx context.Context, pairing *wcPairing, proposalID int64, cause error) {
+	var rpcErr *WCError
+	if !errors.As(cause, &rpcErr) {
+		rpcErr = &WCError{Code: WCErrUserRejected, Message: cause.Error()}
+	}
+	if err := m.sendError(ctx, pairing.topic, pairing.symKey, "wc_sessionPropose", proposalID, rpcErr, wcProposalRejectTag); err != nil {
+		slog.WarnContext(ctx, "walletconnect proposal rejection failed", "topic", pairing.topic, "proposal_id", proposalID, "error", err)
+	}
+}
+
+// sendRequest 在主题上发送协议请求，返回请求ID
+func (m *WalletConnectManager) sendRequest(ctx context.Context, topic string, key []byte, method string, params interface{}) (int64, error) {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return 0, err
+	}
+	msg := &wcRPCMessage{ID: wcPayloadID(), JSONRPC: "2.0", Method: method, Params: raw}
+	option := wcRPCOptions[method]
+	return msg.ID, m.publish(ctx, topic, key, msg, option.reqTag, option.ttl)
+}
+
+// sendResult 回复协议请求的结果
+func (m *WalletConnectManager) sendResult(ctx context.Context, topic string, key []byte, method string, id int64, result interface{}) error {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	option := wcRPCOptions[method]
+	return m.publish(ctx, topic, key, &wcRPCMessage{ID: id, JSONRPC: "2.0", Result: raw}, option.resTag, option.ttl)
+}
+
+// sendError 回复协议请求的错误，tag 为 0 时使用方法的响应标签
+func (m *WalletConnectManager) sendError(ctx context.Context, topic string, key []byte, method string, id int64, rpcErr *WCError, tag int) error {
+	option, ok := wcRPCOptions[method]
+	if !ok {
+		option = wcRPCOption{ttl: 5 * time.Minute}
+	}
+	if tag == 0 {
+		tag = option.resTag
+	}
+	return m.publish(ctx, topic, key, &wcRPCMessage{ID: id, JSONRPC: "2.0", Error: rpcErr}, tag, option.ttl)
+}
+
+func (m *WalletConnectManager) publish(ctx context.Context, topic string, key []byte, msg *wcRPCMessage, tag int, ttl time.Duration) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	envelope, err := wcEncrypt(key, payload)
+	if err != nil {
+		return err
+	}
+	m.markSeen(topic, wcMessageKey(envelope))
+	return m.relay.publish(ctx, topic, envelope, ttl, tag)
+}
+
+// removePairing 移除配对并取消订阅
+func (m *WalletConnectManager) removePairing(topic string) {
+	m.mu.Lock()
+	_, ok := m.pairings[topic]
+	delete(m.pairings, topic)
+	m.mu.Unlock()
+	if ok {
+		m.relay.unsubscribe(context.Background(), topic)
+	}
+}
+
+// removeSession 移除会话、取消订阅并回调 OnClose
+func (m *WalletConnectManager) removeSession(topic, reason string) {
+	m.mu.Lock()
+	session, ok := m.sessions[topic]
+	delete(m.sessions, topic)
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+	m.relay.unsubscribe(context.Background(), topic)
+	if m.handlers.OnClose != nil {
+		m.handlers.OnClose(session.snapshot(), reason)
+	}
+}
+
+// markSeen 记录已处理或已发布的消息，重复时返回 false
+func (m *WalletConnectManager) markSeen(topic, id string) bool {
+	key := topic + "|" + id
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.seen[key]; ok {
+		return false
+	}
+	m.seen[key] = time.Now()
+	return true
+}
+
+// wcMessageKey 原始信封的摘要，用于识别本端发布的消息
+func wcMessageKey(envelope string) string {
+	sum := sha256.Sum256([]byte(envelope))
+	return hex.EncodeToString(sum[:])
+}
+
+// sweepLoop 定期关闭过期会话、移除过期配对
+func (m *WalletConnectManager) sweepLoop() {
+	ticker := time.NewTicker(wcSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.sweep()
+		}
+	}
+}
+
+func (m *WalletConnectManager) sweep() {
+	now := time.Now()
+	var expiredSessions []string
+	var expiredPairings []*wcPairing
+	m.mu.Lock()
+	for topic, session := range m.sessions {
+		if now.After(session.Expiry) {
+			expiredSessions = append(expiredSessions, topic)
+		}
+	}
+	for _, pairing := range m.pairings {
+		if now.After(pairing.expiresAt) {
+			expiredPairings = append(expiredPairings, pairing)
+		}
+	}
+	for key, at := range m.seen {
+		if now.Sub(at) > wcSeenTTL {
+			delete(m.seen, key)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, topic := range expiredSessions {
+		m.removeSession(topic, "expired")
+	}
+	for _, pairing := range expiredPairings {
+		pairing.finish(nil, fmt.Errorf("配对已过期"))
+		m.removePairing(pairing.topic)
+	}
+}
+
+// snapshot 返回不含密钥的副本
+func (s *WCSession) snapshot() *WCSession {
+	copied := *s
+	copied.symKey = nil
+	return &copied
+}
+
+// supports 检查会话是否批准了该链上的方法
+func (s *WCSession) supports(chainID, method string) *WCError {
+	for _, namespace := range s.Namespaces {
+		if !containsString(namespace.Chains, chainID) {
+			continue
+		}
+		if containsString(namespace.Methods, method) {
+			return nil
+		}
+		return &WCError{Code: WCErrUnsupportedMethods, Message: "Unsupported method: " + method}
+	}
+	return &WCError{Code: WCErrUnsupportedChains, Message: "Unsupported chain: " + chainID}
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// wcDeriveSymKey 由 X25519 共享密钥经 HKDF-SHA256 派生会话对称密钥
+func wcDeriveSymKey(privateKey, peerPublicKey []byte) ([]byte, error) {
+	shared, err := curve25519.X25519(privateKey, peerPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("协商会话密钥失败: %w", err)
+	}
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, shared, nil, nil), key); err != nil {
+		return nil, fmt.Errorf("派生会话密钥失败: %w", err)
+	}
+	return key, nil
+}
+
+// wcTopic 对称密钥对应的主题（SHA-256 hex）
+func wcTopic(symKey []byte) string {
+	sum := sha256.Sum256(symKey)
+	return hex.EncodeToString(sum[:])
+}
+
+// wcEncrypt 使用对称密钥加密为类型 0 信封
+func wcEncrypt(symKey, plaintext []byte) (string, error) {
+	aead, err := chacha20poly1305.New(symKey)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, chacha20poly1305.NonceSize)
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+	envelope := append([]byte{wcEnvelopeType0}, iv...)
+	envelope = aead.Seal(envelope, iv, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(envelope), nil
+}
+
+// wcDecrypt 解密类型 0 信封
+func wcDecrypt(symKey []byte, message string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(message)
+	if err != nil {
+		return nil, fmt.Errorf("信封不是有效的 base64: %w", err)
+	}
+	if len(raw) == 0 || raw[0] != wcEnvelopeType0 {
+		return nil, fmt.Errorf("不支持的信封类型")
+	}
+	body := raw[1:]
+	if len(body) < chacha20poly1305.NonceSize+chacha20poly1305.Overhead {
+		return nil, fmt.Errorf("信封长度不足")
+	}
+	aead, err := chacha20poly1305.New(symKey)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(nil, body[:chacha20poly1305.NonceSize], body[chacha20poly1305.NonceSize:], nil)
+}
@@ -0,0 +1,397 @@
+/*
+WalletConnect v2 中继连接
+
+钱包与 DApp 之间的消息经 WalletConnect 中继（IRN）按主题转发，中继只看到加密后的信封：
+  - 连接时在 URL 中携带 projectId 与 auth（did:key 格式的 Ed25519 公钥签发的 JWT，见 authToken）
+  - irn_subscribe / irn_unsubscribe 订阅与取消订阅主题，irn_publish 发布消息
+  - 中继以 irn_subscription 推送已订阅主题上的消息，需回复 true 确认，否则会重复投递
+  - 连接断开后按退避间隔重连并重新订阅全部主题
+*/
+package core
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wcRelayCallTimeout  = 15 * time.Second // 中继请求等待响应的时间
+	wcRelayPingInterval = 30 * time.Second // 连接保活间隔
+	wcRelayMaxBackoff   = time.Minute      // 重连最大退避间隔
+	wcRelayAuthTTL      = 24 * time.Hour   // 中继认证 JWT 有效期
+)
+
+// errWCRelayClosed 中继连接已断开
+var errWCRelayClosed = errors.New("WalletConnect 中继连接已断开")
+
+// wcRPCMessage JSON-RPC 消息（中继协议与钱包/DApp 之间的协议共用）
+type wcRPCMessage struct {
+	ID      int64           `json:"id"`
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *WCError        `json:"error,omitempty"`
+}
+
+// wcRelay 中继 WebSocket 客户端
+type wcRelay struct {
+	endpoint  string
+	projectID string
+	key       ed25519.PrivateKey
+	onMessage func(topic, message string) // 收到订阅主题上的消息，在独立的 goroutine 中调用
+
+	mu           sync.Mutex
+	conn         *websocket.Conn
+	topics       map[string]string            // 已订阅主题 -> 订阅ID
+	calls        map[int64]chan *wcRPCMessage // 等待响应的请求
+	reconnecting bool
+	closed       bool
+
+	writeMu sync.Mutex
+}
+
+func newWCRelay(endpoint, projectID string, onMessage func(topic, message string)) (*wcRelay, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("生成中继认证密钥失败: %w", err)
+	}
+	return &wcRelay{
+		endpoint:  endpoint,
+		projectID: projectID,
+		key:       key,
+		onMessage: onMessage,
+		topics:    make(map[string]string),
+		calls:     make(map[int64]chan *wcRPCMessage),
+	}, nil
+}
+
+// subscribe 订阅主题，重连后自动重新订阅
+func (r *wcRelay) subscribe(ctx context.Context, topic string) error {
+	var subscriptionID string
+	if err := r.call(ctx, "irn_subscribe", map[string]interface{}{"topic": topic}, &subscriptionID); err != nil {
+		return fmt.Errorf("订阅主题失败: %w", err)
+	}
+	r.mu.Lock()
+	r.topics[topic] = subscriptionID
+	r.mu.Unlock()
+	return nil
+}
+
+// unsubscribe 取消订阅主题
+func (r *wcRelay) unsubscribe(ctx context.Context, topic string) {
+	r.mu.Lock()
+	subscriptionID, ok := r.topics[topic]
+	delete(r.topics, topic)
+	connected := r.conn != nil
+	r.mu.Unlock()
+	if !ok || !connected {
+		return
+	}
+	if err := r.call(ctx, "irn_unsubscribe", map[string]interface{}{"topic": topic, "id": subscriptionID}, nil); err != nil {
+		slog.WarnContext(ctx, "walletconnect unsubscribe failed", "topic", topic, "error", err)
+	}
+}
+
+// publish 在主题上发布加密后的消息，ttl 为中继保存消息的时长
+func (r *wcRelay) publish(ctx context.Context, topic, message string, ttl time.Duration, tag int) error {
+	params := map[string]interface{}{
+		"topic":   topic,
+		"message": message,
+		"ttl":     int64(ttl / time.Second),
+		"tag":     tag,
+		"prompt":  false,
+	}
+	if err := r.call(ctx, "irn_publish", params, nil); err != nil {
+		return fmt.Errorf("发布消息失败: %w", err)
+	}
+	return nil
+}
+
+// close 断开连接并停止重连
+func (r *wcRelay) close() {
+	r.mu.Lock()
+	r.closed = true
+	conn := r.conn
+	r.conn = nil
+	r.mu.Unlock()
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+// call 发送中继请求并等待响应
+func (r *wcRelay) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	conn, err := r.connection(ctx)
+	if err != nil {
+		return err
+	}
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	id := wcPayloadID()
+	ch := make(chan *wcRPCMessage, 1)
+	r.mu.Lock()
+	r.calls[id] = ch
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.calls, id)
+		r.mu.Unlock()
+	}()
+
+	if err := r.write(conn, &wcRPCMessage{ID: id, JSONRPC: "2.0", Method: method, Params: rawParams}); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, wcRelayCallTimeout)
+	defer cancel()
+	select {
+	case resp := <-ch:
+		if resp == nil {
+			return errWCRelayClosed
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result != nil && len(resp.Result) > 0 {
+			return json.Unmarshal(resp.Result, result)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("等待中继响应超时: %w", ctx.Err())
+	}
+}
+
+// connection 返回当前连接，未连接时建立连接
+func (r *wcRelay) connection(ctx context.Context) (*websocket.Conn, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil, errWCRelayClosed
+	}
+	if r.conn != nil {
+		return r.conn, nil
+	}
+
+	token, err := r.authToken()
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	query.Set("auth", token)
+	query.Set("projectId", r.projectID)
+	dialCtx, cancel := context.WithTimeout(ctx, wcRelayCallTimeout)
+	defer cancel()
+	conn, _, err := websocket.DefaultDialer.DialContext(dialCtx, r.endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("连接 WalletConnect 中继失败: %w", err)
+	}
+	r.conn = conn
+	go r.readLoop(conn)
+	go r.keepAlive(conn)
+	return conn, nil
+}
+
+// authToken 签发中继认证 JWT：iss 为 did:key，aud 为中继地址
+func (r *wcRelay) authToken() (string, error) {
+	subject := make([]byte, 32)
+	if _, err := rand.Read(subject); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss": wcDIDKey(r.key.Public().(ed25519.PublicKey)),
+		"sub": hex.EncodeToString(subject),
+		"aud": r.endpoint,
+		"iat": now.Unix(),
+		"exp": now.Add(wcRelayAuthTTL).Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims).SignedString(r.key)
+	if err != nil {
+		return "", fmt.Errorf("签发中继认证令牌失败: %w", err)
+	}
+	return token, nil
+}
+
+func (r *wcRelay) write(conn *websocket.Conn, msg *wcRPCMessage) error {
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+	if err := conn.SetWriteDeadline(time.Now().Add(wcRelayCallTimeout)); err != nil {
+		return err
+	}
+	return conn.WriteJSON(msg)
+}
+
+// readLoop 分发中继响应与订阅消息，连接断开后触发重连
+func (r *wcRelay) readLoop(conn *websocket.Conn) {
+	for {
+		var msg wcRPCMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			r.disconnected(conn, err)
+			return
+		}
+		if msg.Method == "" {
+			r.mu.Lock()
+			ch, ok := r.calls[msg.ID]
+			r.mu.Unlock()
+			if ok {
+				select {
+				case ch <- &msg:
+				default:
+				}
+			}
+			continue
+		}
+		if msg.Method != "irn_subscription" {
+			continue
+		}
+		var params struct {
+			Data struct {
+				Topic   string `json:"topic"`
+				Message string `json:"message"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			continue
+		}
+		// 确认收到，否则中继会重复投递
+		_ = r.write(conn, &wcRPCMessage{ID: msg.ID, JSONRPC: "2.0", Result: json.RawMessage("true")})
+		go r.onMessage(params.Data.Topic, params.Data.Message)
+	}
+}
+
+// keepAlive 定期发送 ping，连接被替换或断开后退出
+func (r *wcRelay) keepAlive(conn *websocket.Conn) {
+	ticker := time.NewTicker(wcRelayPingInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		r.mu.Lock()
+		current := r.conn == conn
+		r.mu.Unlock()
+		if !current {
+			return
+		}
+		r.writeMu.Lock()
+		err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wcRelayCallTimeout))
+		r.writeMu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// disconnected 清理断开的连接，仍有订阅时在后台重连
+func (r *wcRelay) disconnected(conn *websocket.Conn, cause error) {
+	conn.Close()
+	r.mu.Lock()
+	if r.conn != conn {
+		r.mu.Unlock()
+		return
+	}
+	r.conn = nil
+	for id, ch := range r.calls {
+		select {
+		case ch <- nil:
+		default:
+		}
+		delete(r.calls, id)
+	}
+	reconnect := !r.closed && len(r.topics) > 0 && !r.reconnecting
+	if reconnect {
+		r.reconnecting = true
+	}
+	r.mu.Unlock()
+	if reconnect {
+		slog.WarnContext(context.Background(), "walletconnect relay disconnected, reconnecting", "error", cause)
+		go r.reconnect()
+	}
+}
+
+// reconnect 按退避间隔重连并重新订阅全部主题
+func (r *wcRelay) reconnect() {
+	defer func() {
+		r.mu.Lock()
+		r.reconnecting = false
+		r.mu.Unlock()
+	}()
+	backoff := time.Second
+	for {
+		time.Sleep(backoff)
+		r.mu.Lock()
+		topics := make([]string, 0, len(r.topics))
+		for topic := range r.topics {
+			topics = append(topics, topic)
+		}
+		stop := r.closed || len(topics) == 0
+		r.mu.Unlock()
+		if stop {
+			return
+		}
+
+		failed := false
+		for _, topic := range topics {
+			if err := r.subscribe(context.Background(), topic); err != nil {
+				slog.WarnContext(context.Background(), "walletconnect resubscribe failed", "topic", topic, "error", err)
+				failed = true
+				break
+			}
+		}
+		if !failed {
+			return
+		}
+		if backoff *= 2; backoff > wcRelayMaxBackoff {
+			backoff = wcRelayMaxBackoff
+		}
+	}
+}
+
+// wcPayloadID 生成 JSON-RPC 请求ID（毫秒时间戳 × 1000 + 随机数，与 WalletConnect SDK 一致）
+func wcPayloadID() int64 {
+	n, _ := rand.Int(rand.Reader, big.NewInt(1000))
+	return time.Now().UnixMilli()*1000 + n.Int64()
+}
+
+// wcDIDKey 将 Ed25519 公钥编码为 did:key（multicodec 0xed01 + base58btc）
+func wcDIDKey(pub ed25519.PublicKey) string {
+	return "did:key:z" + base58Encode(append([]byte{0xed, 0x01}, pub...))
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Encode 按比特币字母表进行 base58 编码，前导零字节编码为 '1'
+func base58Encode(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+	num := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+	var out []byte
+	for num.Sign() > 0 {
+		num.DivMod(num, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		out = append(out, base58Alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
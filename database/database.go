@@ -13,8 +13,9 @@
 package database
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"time"
 	"wallet/models"
@@ -116,7 +117,7 @@ func InitDatabase(config DatabaseConfig) error {
 		sqlDB.SetConnMaxIdleTime(config.ConnMaxIdleTime)
 	}
 
-	log.Printf("✅ Database connected successfully using %s driver", config.Driver)
+	slog.InfoContext(context.Background(), "database connected", "driver", config.Driver)
 	return nil
 }
 
@@ -129,7 +130,7 @@ func AutoMigrate() error {
 		return fmt.Errorf("database not initialized")
 	}
 
-	log.Println("🔄 Starting database migration...")
+	slog.InfoContext(context.Background(), "database migration started")
 
 	// 按依赖顺序迁移表
 	err := DB.AutoMigrate(
@@ -140,11 +141,62 @@ func AutoMigrate() error {
 
 		// 地址和钱包相关表
 		&models.WatchAddress{},
+		&models.WatchOnlyAddress{},
+		&models.UserToken{},
 		&models.UserWallet{},
+		&models.WalletAccount{},
 		&models.AddressBalanceHistory{},
 
 		// 日志表
 		&models.ActivityLog{},
+		&models.AuditLog{},
+
+		// 第三方服务密钥表
+		&models.UserProviderKey{},
+
+		// IP白名单表
+		&models.IPWhitelistEntry{},
+
+		// 用户设备表
+		&models.TrustedDevice{},
+
+		// TOTP 双因素认证表
+		&models.TOTPSecret{},
+
+		// 支出限额与支出记录表
+		&models.SpendingLimit{},
+		&models.SpendingRecord{},
+
+		// 交易手续费上限表
+		&models.FeeCeiling{},
+
+		// 收款地址黑名单表
+		&models.BlockedAddress{},
+
+		// DApp授权表
+		&models.DAppPermission{},
+
+		// 自定义网络表
+		&models.CustomNetwork{},
+		&models.NetworkConfirmation{},
+
+		// 交易备注表
+		&models.TxNote{},
+
+		// 通知收件箱、投递记录与渠道设置表
+		&models.Notification{},
+		&models.NotificationDelivery{},
+		&models.NotificationSetting{},
+
+		// 代付Gas转账记录表
+		&models.RelayTransaction{},
+
+		// 发送类请求幂等键表
+		&models.IdempotencyRecord{},
+
+		// 交易历史缓存表
+		&models.HistoryTransaction{},
+		&models.HistoryScan{},
 	)
 
 	if err != nil {
@@ -153,10 +205,10 @@ func AutoMigrate() error {
 
 	// 创建额外的索引（如果需要）
 	if err := createAdditionalIndexes(); err != nil {
-		log.Printf("⚠️ Warning: failed to create additional indexes: %v", err)
+		slog.WarnContext(context.Background(), "additional index creation failed", "error", err)
 	}
 
-	log.Println("✅ Database migration completed successfully")
+	slog.InfoContext(context.Background(), "database migration completed")
 	return nil
 }
 
@@ -188,6 +240,14 @@ func createAdditionalIndexes() error {
  * 检查数据库连接状态
  */
 func HealthCheck() error {
+	return HealthCheckContext(context.Background())
+}
+
+/**
+ * 带超时控制的健康检查
+ * 供就绪检查使用，数据库无响应时在 ctx 到期后返回
+ */
+func HealthCheckContext(ctx context.Context) error {
 	if DB == nil {
 		return fmt.Errorf("database not initialized")
 	}
@@ -197,7 +257,7 @@ func HealthCheck() error {
 		return fmt.Errorf("failed to get underlying sql.DB: %w", err)
 	}
 
-	return sqlDB.Ping()
+	return sqlDB.PingContext(ctx)
 }
 
 /**
@@ -214,7 +274,7 @@ func CloseDatabase() error {
 		return err
 	}
 
-	log.Println("🔌 Closing database connection...")
+	slog.InfoContext(context.Background(), "closing database connection")
 	return sqlDB.Close()
 }
 
@@ -288,10 +348,11 @@ func ResetDatabase() error {
 		return fmt.Errorf("cannot reset database in production environment")
 	}
 
-	log.Println("⚠️ WARNING: Resetting database - all data will be lost!")
+	slog.WarnContext(context.Background(), "resetting database, all data will be lost")
 
 	// 删除所有表
 	tables := []string{
+		"user_provider_keys",
 		"address_balance_histories",
 		"activity_logs",
 		"user_wallets",
@@ -303,7 +364,7 @@ func ResetDatabase() error {
 
 	for _, table := range tables {
 		if err := DB.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE", table)).Error; err != nil {
-			log.Printf("Warning: failed to drop table %s: %v", table, err)
+			slog.WarnContext(context.Background(), "drop table failed", "table", table, "error", err)
 		}
 	}
 
@@ -2,7 +2,7 @@
 区块链钱包服务应用程序入口文件
 
 本文件是区块链钱包服务的主启动程序，负责：
-1. 加载应用配置
+1. 加载应用配置并初始化结构化日志
 2. 初始化数据库连接和迁移
 3. 初始化认证和安全中间件
 4. 初始化钱包服务
@@ -21,12 +21,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
 	"wallet/api/middleware"
 	"wallet/api/router"
 	"wallet/config"
 	"wallet/database"
+	"wallet/pkg/logger"
 	"wallet/services"
 )
 
@@ -36,27 +39,33 @@ func main() {
 	// 1. 加载配置文件和环境变量
 	// 从config.yaml加载服务器、数据库、网络等配置
 	config.LoadConfig()
+	// 初始化结构化日志（级别与格式来自 log 配置，标准库 log 的输出同样经过脱敏）
+	logger.Init(config.AppConfig.Log.Level, config.AppConfig.Log.Format)
+	ctx := context.Background()
 
 	// 2. 初始化数据库连接
 	// 使用默认配置初始化数据库（支持PostgreSQL、MySQL、SQLite）
 	dbConfig := database.GetDefaultConfig()
-	log.Printf("🔄 正在初始化数据库（驱动：%s）...", dbConfig.Driver)
+	slog.InfoContext(ctx, "initializing database", "driver", dbConfig.Driver)
 
 	if err := database.InitDatabase(dbConfig); err != nil {
-		log.Fatalf("❌ 数据库初始化失败: %v", err)
+		slog.ErrorContext(ctx, "database initialization failed", "error", err)
+		os.Exit(1)
 	}
 
 	// 3. 执行数据库迁移
 	// 根据模型定义自动创建/更新表结构
-	log.Println("🔄 执行数据库迁移...")
+	slog.InfoContext(ctx, "running database migration")
 	if err := database.AutoMigrate(); err != nil {
-		log.Fatalf("❌ 数据库迁移失败: %v", err)
+		slog.ErrorContext(ctx, "database migration failed", "error", err)
+		os.Exit(1)
 	}
 
 	// 4. 初始化认证和安全中间件
 	// 设置JWT密钥和速率限制器，为API接口提供安全保护
 	middleware.InitAuth(config.AppConfig.Security.JWTSecret)
 	middleware.InitRateLimiters()
+	middleware.LogWalletPolicy()
 
 	// 5. 初始化钱包服务，并注入到路由
 	// 创建钱包服务实例，包含多链管理器和加密管理器
@@ -66,7 +75,7 @@ func main() {
 	// 6. 启动HTTP服务器
 	// 在配置的端口上启动Gin HTTP服务器
 	addr := fmt.Sprintf(":%d", config.AppConfig.Server.Port)
-	fmt.Printf("🚀 服务器启动成功，运行在 %s\n", addr)
+	slog.InfoContext(ctx, "server starting", "addr", addr)
 	if err := r.Run(addr); err != nil {
 		panic(fmt.Sprintf("Failed to start server: %v", err))
 	}
@@ -74,7 +83,7 @@ func main() {
 	// 7. 优雅关闭时清理资源
 	defer func() {
 		if err := database.CloseDatabase(); err != nil {
-			log.Printf("⚠️ 关闭数据库连接时出错: %v", err)
+			slog.WarnContext(ctx, "database close failed", "error", err)
 		}
 	}()
 }
@@ -162,6 +162,39 @@ type WatchAddress struct {
 	BalanceHistory []AddressBalanceHistory `gorm:"foreignKey:WatchAddressID" json:"balance_history,omitempty"`
 }
 
+/**
+ * 只读钱包地址模型
+ * 按钱包地址（会话所属用户）持久化 watch-only 列表，同一用户在同一网络下地址唯一
+ * 后台监控转入该地址的转账并通知 OwnerAddress，LastProcessedBlock 保证每笔转账只通知一次
+ */
+type WatchOnlyAddress struct {
+	BaseModel
+
+	OwnerAddress string `gorm:"size:42;not null;uniqueIndex:idx_watch_only_owner_address_network" json:"owner_address"`
+	Address      string `gorm:"size:42;not null;uniqueIndex:idx_watch_only_owner_address_network" json:"address"` // 校验和格式
+	Network      string `gorm:"size:50;not null;uniqueIndex:idx_watch_only_owner_address_network" json:"network"` // 网络ID，如 ethereum、polygon
+	Label        string `gorm:"size:100" json:"label,omitempty"`
+
+	MinValueUSD        float64 `gorm:"not null;default:0" json:"min_value_usd"` // 到账通知的最小金额（美元），0 表示全部通知
+	LastProcessedBlock uint64  `gorm:"not null;default:0" json:"-"`             // 到账监控已处理到的区块，0 表示尚未开始
+}
+
+/**
+ * 用户自定义代币模型
+ * 按钱包地址（会话所属用户）记录关注的ERC20代币，同一用户在同一网络下合约唯一
+ * 余额与资产汇总接口在未指定代币时使用该列表
+ */
+type UserToken struct {
+	BaseModel
+
+	OwnerAddress string `gorm:"size:42;not null;uniqueIndex:idx_user_token_owner_network_contract" json:"owner_address"`
+	Network      string `gorm:"size:50;not null;uniqueIndex:idx_user_token_owner_network_contract" json:"network"`  // 网络ID，如 ethereum、polygon
+	Contract     string `gorm:"size:42;not null;uniqueIndex:idx_user_token_owner_network_contract" json:"contract"` // 校验和格式
+	Symbol       string `gorm:"size:32" json:"symbol"`
+	Name         string `gorm:"size:100" json:"name"`
+	Decimals     int    `gorm:"not null" json:"decimals"`
+}
+
 /**
  * 用户钱包记录模型
  * 记录用户导入/创建的钱包(不存储私钥)
@@ -224,6 +257,343 @@ type ActivityLog struct {
 	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
 
+/**
+ * 安全审计日志模型
+ * 审计日志持久化（写穿），超过保留期限的记录由审计日志器分批删除
+ * user_address 统一小写；session_id 为会话ID的指纹（不保存原始会话ID）
+ */
+type AuditLog struct {
+	BaseModel
+
+	LogID         string    `gorm:"size:32;not null;uniqueIndex" json:"log_id"`
+	Timestamp     time.Time `gorm:"not null;index:idx_audit_logs_user_time,priority:2;index:idx_audit_logs_action_time,priority:2" json:"timestamp"`
+	UserAddress   string    `gorm:"size:42;index:idx_audit_logs_user_time,priority:1" json:"user_address"`
+	Action        string    `gorm:"size:100;not null;index:idx_audit_logs_action_time,priority:1" json:"action"`
+	Resource      string    `gorm:"size:100" json:"resource"`
+	ResourceID    string    `gorm:"size:200" json:"resource_id"`
+	Result        string    `gorm:"size:20" json:"result"`
+	IPAddress     string    `gorm:"size:64" json:"ip_address"`
+	UserAgent     string    `gorm:"type:text" json:"user_agent"`
+	DeviceID      string    `gorm:"size:100" json:"device_id"`
+	SessionID     string    `gorm:"size:32" json:"session_id"`
+	Details       string    `gorm:"type:text" json:"details"` // JSON
+	RiskScore     float64   `gorm:"index" json:"risk_score"`
+	SecurityLevel string    `gorm:"size:20" json:"security_level"`
+}
+
+// =============================================================================
+// 第三方服务密钥模型
+// =============================================================================
+
+/**
+ * 用户IP白名单规则模型
+ * 按钱包地址记录允许执行签名/发送操作的客户端IP（单个IP或CIDR网段）
+ * 用户没有任何规则时不限制IP
+ */
+type IPWhitelistEntry struct {
+	BaseModel
+
+	OwnerAddress string `gorm:"size:42;not null;uniqueIndex:idx_ip_whitelist_owner_cidr" json:"owner_address"`
+	CIDR         string `gorm:"column:cidr;size:64;not null;uniqueIndex:idx_ip_whitelist_owner_cidr" json:"cidr"` // 规范化的网段，单个IP存为 /32 或 /128
+	Label        string `gorm:"size:100" json:"label,omitempty"`
+}
+
+/**
+ * 用户设备模型
+ * 按钱包地址与设备指纹唯一记录登录过的设备；trusted 设备可直接登录，
+ * pending 设备需通过双因素认证或在已信任设备上确认后才能登录
+ */
+type TrustedDevice struct {
+	BaseModel
+
+	OwnerAddress string    `gorm:"size:42;not null;uniqueIndex:idx_trusted_device_owner_fingerprint" json:"owner_address"`
+	Fingerprint  string    `gorm:"size:64;not null;uniqueIndex:idx_trusted_device_owner_fingerprint" json:"fingerprint"`
+	Name         string    `gorm:"size:100" json:"name"`
+	DeviceType   string    `gorm:"size:20" json:"device_type"` // ios / android / windows / macos / linux / other
+	UserAgent    string    `gorm:"size:512" json:"user_agent"`
+	IPAddress    string    `gorm:"size:45" json:"ip_address"`                 // 最近一次登录IP
+	Location     string    `gorm:"size:10" json:"location"`                   // 最近一次登录的国家/地区代码
+	TrustLevel   string    `gorm:"size:20;not null;index" json:"trust_level"` // trusted / pending
+	FirstSeen    time.Time `json:"first_seen"`
+	LastSeen     time.Time `json:"last_seen"`
+}
+
+/**
+ * TOTP 双因素认证模型
+ * 按钱包地址与状态各保存一条：active 为已启用的密钥，pending 为待首次验证的新密钥；
+ * 密钥加密存储，备用码只保存 SHA-256 哈希，last_step 为最近一次验证成功的时间窗口（防重放）
+ */
+type TOTPSecret struct {
+	BaseModel
+
+	OwnerAddress    string     `gorm:"size:42;not null;uniqueIndex:idx_totp_owner_state" json:"owner_address"`
+	State           string     `gorm:"size:10;not null;uniqueIndex:idx_totp_owner_state" json:"state"` // active / pending
+	EncryptedSecret string     `gorm:"type:text;not null" json:"-"`                                    // Base64密文
+	Nonce           string     `gorm:"size:64" json:"-"`
+	BackupCodes     string     `gorm:"type:text" json:"-"` // 备用码哈希，逗号分隔
+	LastStep        int64      `gorm:"not null;default:0" json:"-"`
+	LastUsed        *time.Time `json:"last_used,omitempty"`
+}
+
+/**
+ * 钱包支出限额模型
+ * 按钱包地址配置每日/每月的美元支出上限，0 表示该周期不限制；周期按 Timezone 的自然日/自然月计算
+ */
+type SpendingLimit struct {
+	BaseModel
+
+	OwnerAddress    string  `gorm:"size:42;not null;uniqueIndex" json:"owner_address"`
+	DailyLimitUSD   float64 `json:"daily_limit_usd"`
+	MonthlyLimitUSD float64 `json:"monthly_limit_usd"`
+	Timezone        string  `gorm:"size:64;not null;default:UTC" json:"timezone"` // IANA 时区，如 Asia/Shanghai
+}
+
+/**
+ * 交易手续费上限模型
+ * 按钱包地址设置单笔交易最高手续费（美元），发送时按原生币价格折算为 wei；未设置时使用全局默认值
+ */
+type FeeCeiling struct {
+	BaseModel
+
+	OwnerAddress string  `gorm:"size:42;not null;uniqueIndex" json:"owner_address"`
+	MaxFeeUSD    float64 `gorm:"not null" json:"max_fee_usd"`
+}
+
+/**
+ * 钱包支出记录模型
+ * 每笔受限额约束的发送交易按发送时的美元估值记录一次，用于统计周期内的累计支出（重启后不丢失）
+ */
+type SpendingRecord struct {
+	BaseModel
+
+	OwnerAddress string    `gorm:"size:42;not null;index:idx_spending_owner_time" json:"owner_address"`
+	SpentAt      time.Time `gorm:"not null;index:idx_spending_owner_time" json:"spent_at"`
+	TxHash       string    `gorm:"size:66" json:"tx_hash"`
+	Network      string    `gorm:"size:50" json:"network"`
+	Asset        string    `gorm:"size:42" json:"asset"`  // 代币地址，原生币为 native
+	Amount       string    `gorm:"size:78" json:"amount"` // 最小单位
+	ValueUSD     float64   `json:"value_usd"`
+	Override     bool      `json:"override"` // 是否通过双因素认证超额发送
+}
+
+/**
+ * 收款地址黑名单模型
+ * OwnerAddress 为空表示管理员维护的全局条目，否则为该钱包自己的黑名单；向黑名单地址发送前会被拒绝或要求确认
+ */
+type BlockedAddress struct {
+	BaseModel
+
+	OwnerAddress string `gorm:"size:42;not null;default:'';uniqueIndex:idx_blocked_owner_address" json:"owner_address"`
+	Address      string `gorm:"size:42;not null;uniqueIndex:idx_blocked_owner_address" json:"address"`
+	Reason       string `gorm:"size:255" json:"reason"`
+	CreatedBy    string `gorm:"size:42" json:"created_by"`
+}
+
+/**
+ * 用户第三方服务密钥模型
+ * 按钱包地址存储用户自带的 Alchemy/CoinGecko/OpenSea 等API密钥
+ * 密钥以AES-GCM加密存储，接口只写不读
+ */
+type UserProviderKey struct {
+	BaseModel
+
+	OwnerAddress string `gorm:"size:42;not null;uniqueIndex:idx_owner_provider" json:"owner_address"`
+	Provider     string `gorm:"size:30;not null;uniqueIndex:idx_owner_provider" json:"provider"` // alchemy, coingecko, opensea, etherscan ...
+	EncryptedKey string `gorm:"type:text;not null" json:"-"`                                     // Base64密文
+	Salt         string `gorm:"size:64" json:"-"`
+	Nonce        string `gorm:"size:64" json:"-"`
+}
+
+/**
+ * DApp授权模型
+ * 记录用户对DApp（按来源 scheme://host）的授权方法，撤销后保留记录（revoked_at 非空）
+ */
+type DAppPermission struct {
+	BaseModel
+
+	PermissionID string     `gorm:"size:64;not null;uniqueIndex" json:"permission_id"`
+	OwnerAddress string     `gorm:"size:42;not null;index" json:"owner_address"`
+	DAppOrigin   string     `gorm:"size:255;not null;index" json:"dapp_origin"`
+	Methods      string     `gorm:"type:text;not null" json:"methods"` // 逗号分隔的授权方法
+	GrantedAt    time.Time  `gorm:"not null" json:"granted_at"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+}
+
+/**
+ * 自定义网络模型
+ * 记录运行时添加的EVM网络（自定义RPC），服务启动时重新加载到多链管理器
+ */
+type CustomNetwork struct {
+	BaseModel
+
+	NetworkID     string `gorm:"size:50;not null;uniqueIndex" json:"network_id"`
+	Name          string `gorm:"size:100;not null" json:"name"`
+	RPCURL        string `gorm:"size:500;not null" json:"rpc_url"`
+	FallbackRPCs  string `gorm:"type:text" json:"fallback_rpc_urls"` // 逗号分隔的备用RPC地址
+	ChainID       int64  `gorm:"not null;uniqueIndex" json:"chain_id"`
+	Symbol        string `gorm:"size:32;not null" json:"symbol"`
+	Decimals      int    `gorm:"not null;default:18" json:"decimals"`
+	BlockExplorer string `gorm:"size:255" json:"block_explorer"`
+	Testnet       bool   `gorm:"default:false" json:"testnet"`
+	AddedBy       string `gorm:"size:42" json:"added_by"` // 添加者钱包地址
+}
+
+/**
+ * 网络确认数设置模型
+ * 覆盖网络配置的最终确认数（min_confirmations），服务启动时重新加载到多链管理器
+ */
+type NetworkConfirmation struct {
+	BaseModel
+
+	NetworkID     string `gorm:"size:50;not null;uniqueIndex" json:"network_id"`
+	Confirmations uint64 `gorm:"not null" json:"confirmations"`
+	UpdatedBy     string `gorm:"size:42" json:"updated_by"` // 最近修改者钱包地址
+}
+
+/**
+ * HD钱包账户模型
+ * 同一助记词下的多个账户（m/44'/60'/N'/0/0），钱包以账户0的地址标识，
+ * 同一助记词无论通过哪个会话或加密钱包解锁，看到的都是同一份账户列表
+ */
+type WalletAccount struct {
+	BaseModel
+
+	WalletAddress  string `gorm:"size:42;not null;uniqueIndex:idx_wallet_account_index" json:"wallet_address"` // 账户0的地址（小写）
+	AccountIndex   int    `gorm:"not null;uniqueIndex:idx_wallet_account_index" json:"account_index"`
+	Label          string `gorm:"size:100;not null" json:"label"`
+	DerivationPath string `gorm:"size:100;not null" json:"derivation_path"`
+	Address        string `gorm:"size:42;not null;index" json:"address"` // 账户的主地址
+}
+
+/**
+ * 交易备注模型
+ * 用户对交易的备注与分类（对账用），同一用户在同一网络下每笔交易一条
+ */
+type TxNote struct {
+	BaseModel
+
+	OwnerAddress string `gorm:"size:42;not null;uniqueIndex:idx_tx_note_owner_network_hash" json:"owner_address"`
+	Network      string `gorm:"size:50;not null;uniqueIndex:idx_tx_note_owner_network_hash" json:"network"`
+	TxHash       string `gorm:"size:66;not null;uniqueIndex:idx_tx_note_owner_network_hash" json:"tx_hash"` // 小写
+	Note         string `gorm:"type:text" json:"note"`
+	Category     string `gorm:"size:50;index" json:"category"` // 如 income、expense、transfer、trade
+}
+
+/**
+ * 用户通知收件箱模型
+ * 价格提醒、多签审批、到账等事件写入接收地址的收件箱，同时按 NotificationSetting 投递到各渠道
+ */
+type Notification struct {
+	BaseModel
+
+	OwnerAddress string     `gorm:"size:42;not null;index:idx_notification_owner_read" json:"owner_address"` // 小写
+	Type         string     `gorm:"size:50;not null;index" json:"type"`                                      // price_alert / multisig_approval / incoming_transfer
+	Title        string     `gorm:"size:255" json:"title"`
+	Message      string     `gorm:"type:text" json:"message"`
+	Data         JSON       `gorm:"type:jsonb" json:"data"`
+	Priority     string     `gorm:"size:20" json:"priority"` // low / normal / high
+	IsRead       bool       `gorm:"not null;index:idx_notification_owner_read" json:"is_read"`
+	ReadAt       *time.Time `json:"read_at"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+}
+
+/**
+ * 通知投递记录模型
+ * 每条通知在每个渠道一条，记录投递状态与重试次数（Webhook 失败后退避重试）
+ */
+type NotificationDelivery struct {
+	BaseModel
+
+	NotificationID uint       `gorm:"not null;index" json:"notification_id"`
+	Channel        string     `gorm:"size:20;not null" json:"channel"` // push / email / webhook
+	Status         string     `gorm:"size:20;not null" json:"status"`  // pending / sent / failed
+	Attempts       int        `json:"attempts"`
+	LastError      string     `gorm:"size:500" json:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+}
+
+/**
+ * 用户通知渠道设置模型
+ * 未设置时只推送到实时连接；Webhook 请求用 WebhookSecret 做 HMAC-SHA256 签名
+ */
+type NotificationSetting struct {
+	BaseModel
+
+	OwnerAddress  string `gorm:"size:42;not null;uniqueIndex" json:"owner_address"`
+	PushEnabled   bool   `gorm:"not null" json:"push_enabled"`
+	Email         string `gorm:"size:255" json:"email"`
+	WebhookURL    string `gorm:"size:500" json:"webhook_url"`
+	WebhookSecret string `gorm:"size:64" json:"-"`
+}
+
+/**
+ * 代付Gas转账记录模型
+ * 中继账户每提交一笔 ERC-2771 转发转账记录一次，用于统计用户24小时内的代付次数（重启后不丢失）
+ */
+type RelayTransaction struct {
+	BaseModel
+
+	OwnerAddress string `gorm:"size:42;not null;index" json:"owner_address"`
+	Network      string `gorm:"size:50;not null" json:"network"`
+	Forwarder    string `gorm:"size:42;not null" json:"forwarder"`
+	Token        string `gorm:"size:42;not null" json:"token"`
+	Recipient    string `gorm:"size:42;not null" json:"recipient"`
+	Amount       string `gorm:"size:78" json:"amount"`      // 最小单位
+	Fee          string `gorm:"size:78" json:"fee"`         // 按代币收取的手续费（最小单位），免费为空
+	TxHash       string `gorm:"size:66" json:"tx_hash"`     // 转账的中继交易
+	FeeTxHash    string `gorm:"size:66" json:"fee_tx_hash"` // 手续费的中继交易
+}
+
+/**
+ * 幂等键模型
+ * 发送类请求的 Idempotency-Key 与首次请求的响应；(owner, idempotency_key) 唯一，插入成功者获得处理权，
+ * 并发的相同请求插入失败后不会再次广播交易
+ */
+type IdempotencyRecord struct {
+	BaseModel
+
+	Owner          string    `gorm:"size:100;not null;uniqueIndex:idx_idempotency_owner_key" json:"owner"` // 钱包地址，未登录时为 ip:<客户端IP>
+	IdempotencyKey string    `gorm:"size:255;not null;uniqueIndex:idx_idempotency_owner_key" json:"idempotency_key"`
+	Fingerprint    string    `gorm:"size:64;not null" json:"fingerprint"` // 请求方法、路径与请求体的 SHA-256
+	Status         string    `gorm:"size:20;not null" json:"status"`      // processing / completed
+	ResponseStatus int       `json:"response_status"`
+	ResponseBody   string    `gorm:"type:text" json:"response_body"`
+	ExpiresAt      time.Time `gorm:"not null;index" json:"expires_at"`
+}
+
+/**
+ * 交易历史缓存模型
+ * 按 (网络, 地址) 持久化扫描到的交易，分页直接使用 SQL 排序与 OFFSET；Payload 为完整的交易信息 JSON
+ */
+type HistoryTransaction struct {
+	BaseModel
+
+	NetworkID   string `gorm:"size:50;not null;uniqueIndex:idx_history_tx_network_address_hash;index:idx_history_tx_network_address_block" json:"network_id"`
+	Address     string `gorm:"size:42;not null;uniqueIndex:idx_history_tx_network_address_hash;index:idx_history_tx_network_address_block" json:"address"` // 小写
+	Hash        string `gorm:"size:66;not null;uniqueIndex:idx_history_tx_network_address_hash" json:"hash"`
+	BlockNumber uint64 `gorm:"not null;index:idx_history_tx_network_address_block" json:"block_number"`
+	Timestamp   uint64 `gorm:"not null" json:"timestamp"`
+	TxType      string `gorm:"size:20;not null" json:"tx_type"` // ETH / ERC20 / CONTRACT
+	HasToken    bool   `json:"has_token"`                       // 包含代币转账（合约交互中的代币转账也计入 ERC20 结果）
+	Payload     string `gorm:"type:text;not null" json:"payload"`
+}
+
+/**
+ * 交易历史扫描区间模型
+ * 记录 (网络, 地址) 已扫描的区块区间；ConfirmedTo 之后的区块可能重组，链头变化后重新扫描
+ */
+type HistoryScan struct {
+	BaseModel
+
+	NetworkID   string `gorm:"size:50;not null;uniqueIndex:idx_history_scan_network_address" json:"network_id"`
+	Address     string `gorm:"size:42;not null;uniqueIndex:idx_history_scan_network_address" json:"address"` // 小写
+	FromBlock   uint64 `gorm:"not null" json:"from_block"`
+	ConfirmedTo uint64 `gorm:"not null" json:"confirmed_to"` // 不含
+	ScannedTo   uint64 `gorm:"not null" json:"scanned_to"`
+	HeadBlock   uint64 `gorm:"not null" json:"head_block"`
+}
+
 // =============================================================================
 // 模型方法
 // =============================================================================
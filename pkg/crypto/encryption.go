@@ -244,3 +244,56 @@ func GenerateSecureKey(length int) (string, error) {
 
 	return hex.EncodeToString(key), nil
 }
+
+// NewEphemeralCryptoManager 创建使用随机默认密钥的加密管理器
+// 密钥仅存在于当前进程内存，进程重启后无法解密，适用于会话等临时敏感数据的包装
+func NewEphemeralCryptoManager() (*CryptoManager, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("生成进程密钥失败: %w", err)
+	}
+	return &CryptoManager{defaultKey: key}, nil
+}
+
+// EncryptWithKey 使用调用方提供的32字节密钥加密数据
+func (cm *CryptoManager) EncryptWithKey(plaintext, key []byte) (*EncryptedData, error) {
+	encryptedData, nonce, err := cm.encryptAESGCM(plaintext, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncryptedData{
+		Data:  base64.StdEncoding.EncodeToString(encryptedData),
+		Nonce: hex.EncodeToString(nonce),
+	}, nil
+}
+
+// DecryptWithKey 使用调用方提供的32字节密钥解密数据，返回的明文切片可由调用方清零
+func (cm *CryptoManager) DecryptWithKey(encData *EncryptedData, key []byte) ([]byte, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(encData.Data)
+	if err != nil {
+		return nil, fmt.Errorf("解码加密数据失败: %w", err)
+	}
+	nonce, err := hex.DecodeString(encData.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("解码nonce失败: %w", err)
+	}
+	return cm.decryptAESGCM(ciphertext, nonce, key)
+}
+
+// WrapKey 使用默认密钥包装（加密）数据密钥
+func (cm *CryptoManager) WrapKey(key []byte) (*EncryptedData, error) {
+	return cm.EncryptWithKey(key, cm.defaultKey)
+}
+
+// UnwrapKey 使用默认密钥解包数据密钥
+func (cm *CryptoManager) UnwrapKey(wrapped *EncryptedData) ([]byte, error) {
+	return cm.DecryptWithKey(wrapped, cm.defaultKey)
+}
+
+// ZeroBytes 将切片内容清零，用于用完即弃的密钥和明文
+func ZeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -32,18 +32,55 @@ const (
 	ErrorRateLimit  = 429 // 请求频率过高（被限流）
 
 	// 钱包相关业务错误码 (10xxx系列)
-	ErrorWalletCreate         = 10001 // 创建钱包失败
-	ErrorWalletGet            = 10002 // 获取钱包信息失败
-	ErrorWalletImport         = 10003 // 导入钱包失败（助记词或私钥错误）
-	ErrorWalletKeystore       = 10004 // 钱包keystore处理失败
-	ErrorTransactionSend      = 10005 // 发送交易失败
-	ErrorTransactionBuild     = 10006 // 构建交易失败
-	ErrorGetBalance           = 10007 // 获取余额失败
-	ErrorContractCall         = 10008 // 调用智能合约失败
-	ErrorInvalidPassword      = 10009 // 钱包密码错误
-	ErrorWalletAddressInvalid = 10010 // 无效的钱包地址格式
-	ErrorGasSuggestion        = 10011 // 获取Gas建议失败
-	ErrorNonceGet             = 10012 // 获取Nonce失败
-	ErrorBroadcastRawTx       = 10013 // 广播原始交易失败
-	ErrorDeFiOperation        = 10014 // DeFi操作失败
+	ErrorWalletCreate          = 10001 // 创建钱包失败
+	ErrorWalletGet             = 10002 // 获取钱包信息失败
+	ErrorWalletImport          = 10003 // 导入钱包失败（助记词或私钥错误）
+	ErrorWalletKeystore        = 10004 // 钱包keystore处理失败
+	ErrorTransactionSend       = 10005 // 发送交易失败
+	ErrorTransactionBuild      = 10006 // 构建交易失败
+	ErrorGetBalance            = 10007 // 获取余额失败
+	ErrorContractCall          = 10008 // 调用智能合约失败
+	ErrorInvalidPassword       = 10009 // 钱包密码错误
+	ErrorWalletAddressInvalid  = 10010 // 无效的钱包地址格式
+	ErrorGasSuggestion         = 10011 // 获取Gas建议失败
+	ErrorNonceGet              = 10012 // 获取Nonce失败
+	ErrorBroadcastRawTx        = 10013 // 广播原始交易失败
+	ErrorDeFiOperation         = 10014 // DeFi操作失败
+	ErrorWalletCreateDisabled  = 10015 // 当前部署禁止自助创建钱包
+	ErrorWalletImportDisabled  = 10016 // 当前部署禁止自助导入钱包
+	ErrorSignatureVerify       = 10017 // 签名验证失败（签名格式错误或无法恢复签名者）
+	ErrorMFARequired           = 10018 // 已启用双因素认证，需提交验证码
+	ErrorTxSimulationFailed    = 10019 // 交易模拟失败（发送后预计会回滚）
+	ErrorRiskChallenge         = 10020 // 操作存在异常风险，需提交双因素验证码
+	ErrorRiskDenied            = 10021 // 操作因异常风险被拒绝
+	ErrorIPNotWhitelisted      = 10022 // 客户端IP不在用户配置的白名单中
+	ErrorDeviceUnrecognized    = 10023 // 未识别的登录设备，需双因素认证或在已信任设备上确认
+	ErrorSpendingLimitExceeded = 10024 // 超出钱包支出限额（已启用双因素认证时可提交验证码超额发送）
+	ErrorRecipientBlocked      = 10025 // 接收地址在黑名单中（warn 模式下确认风险后可发送）
+	ErrorRelayQuotaExceeded    = 10026 // 24小时内的代付Gas次数已用完
+
+	// 链上交易失败细分错误码 (由 ClassifyRPCError 根据节点返回的错误识别)
+	ErrorInsufficientFunds      = 10027 // 余额不足以支付转账金额与Gas费用
+	ErrorNonceTooLow            = 10028 // nonce 已被使用
+	ErrorNonceTooHigh           = 10029 // nonce 过高，交易无法立即打包
+	ErrorReplacementUnderpriced = 10030 // 替换同 nonce 交易的费率涨幅不足
+	ErrorTxUnderpriced          = 10031 // 交易费率低于节点或区块要求的最低值
+	ErrorGasTooLow              = 10032 // Gas上限低于交易所需
+	ErrorGasLimitExceeded       = 10033 // Gas上限超过区块Gas上限
+	ErrorExecutionReverted      = 10034 // 合约执行回滚
+	ErrorTxAlreadyKnown         = 10035 // 相同交易已在交易池中
+	ErrorTxFeeCapExceeded       = 10036 // 交易手续费超过节点允许的上限
+	ErrorRPCUnavailable         = 10037 // 区块链节点不可用（连接失败、超时或被限流）
+
+	// 幂等键错误码
+	ErrorIdempotencyInProgress = 10038 // 相同 Idempotency-Key 的请求正在处理中
+	ErrorIdempotencyKeyReused  = 10039 // Idempotency-Key 已用于内容不同的请求
+
+	// 手续费上限错误码
+	ErrorFeeTooHigh = 10040 // 交易手续费超过用户设定的上限（确认后可设置 allow_high_fee 发送）
+
+	// 助记词校验错误码
+	ErrorMnemonicWordCount   = 10041 // 助记词单词数量不是 12/15/18/21/24
+	ErrorMnemonicInvalidWord = 10042 // 助记词包含不在词表中的单词
+	ErrorMnemonicChecksum    = 10043 // 助记词校验和错误（单词抄错或顺序错误）
 )
@@ -14,20 +14,57 @@ var MsgFlags = map[int]string{
 	ErrorRateLimit:  "请求频率过高", // 超出了API调用限制
 
 	// 钱包操作相关错误消息
-	ErrorWalletCreate:         "创建钱包失败",         // 助记词生成或钱包初始化失败
-	ErrorWalletGet:            "获取钱包信息失败",       // 查询钱包信息或地址失败
-	ErrorWalletImport:         "导入钱包失败",         // 助记词或私钥格式错误
-	ErrorWalletKeystore:       "钱包keystore处理失败", // Keystore文件加密或解密失败
-	ErrorTransactionSend:      "发送交易失败",         // 交易广播到区块链失败
-	ErrorTransactionBuild:     "构建交易失败",         // 交易参数错误或签名失败
-	ErrorGetBalance:           "获取余额失败",         // 查询钱包或代币余额失败
-	ErrorContractCall:         "调用合约失败",         // 智能合约调用执行失败
-	ErrorInvalidPassword:      "钱包密码错误",         // 解锁钱包密码不正确
-	ErrorWalletAddressInvalid: "无效的钱包地址",        // 地址格式不符合以太坊标准
-	ErrorGasSuggestion:        "获取Gas建议失败",      // 交易费估算服务异常
-	ErrorNonceGet:             "获取Nonce失败",      // 交易顺序号获取失败
-	ErrorBroadcastRawTx:       "广播原始交易失败",       // 签名交易发送失败
-	ErrorDeFiOperation:        "DeFi操作失败",       // DeFi聚合器操作失败
+	ErrorWalletCreate:          "创建钱包失败",              // 助记词生成或钱包初始化失败
+	ErrorWalletGet:             "获取钱包信息失败",            // 查询钱包信息或地址失败
+	ErrorWalletImport:          "导入钱包失败",              // 助记词或私钥格式错误
+	ErrorWalletKeystore:        "钱包keystore处理失败",      // Keystore文件加密或解密失败
+	ErrorTransactionSend:       "发送交易失败",              // 交易广播到区块链失败
+	ErrorTransactionBuild:      "构建交易失败",              // 交易参数错误或签名失败
+	ErrorGetBalance:            "获取余额失败",              // 查询钱包或代币余额失败
+	ErrorContractCall:          "调用合约失败",              // 智能合约调用执行失败
+	ErrorInvalidPassword:       "钱包密码错误",              // 解锁钱包密码不正确
+	ErrorWalletAddressInvalid:  "无效的钱包地址",             // 地址格式不符合以太坊标准
+	ErrorGasSuggestion:         "获取Gas建议失败",           // 交易费估算服务异常
+	ErrorNonceGet:              "获取Nonce失败",           // 交易顺序号获取失败
+	ErrorBroadcastRawTx:        "广播原始交易失败",            // 签名交易发送失败
+	ErrorDeFiOperation:         "DeFi操作失败",            // DeFi聚合器操作失败
+	ErrorWalletCreateDisabled:  "当前部署不允许创建钱包",         // 钱包由内部流程统一发放
+	ErrorWalletImportDisabled:  "当前部署不允许导入钱包",         // 钱包由内部流程统一发放
+	ErrorSignatureVerify:       "签名验证失败",              // 签名格式错误或无法恢复签名者
+	ErrorMFARequired:           "需要双因素认证验证码",          // 已启用TOTP的用户登录需提交验证码
+	ErrorTxSimulationFailed:    "交易模拟失败",              // 发送前模拟执行回滚，已中止发送
+	ErrorRiskChallenge:         "操作存在异常风险，需要双因素认证验证码", // 新设备、异地或大额交易等
+	ErrorRiskDenied:            "操作因安全风险被拒绝",          // 风险分数达到拒绝阈值
+	ErrorIPNotWhitelisted:      "当前IP不在白名单中",          // 签名/发送操作仅允许白名单IP
+	ErrorDeviceUnrecognized:    "未识别的设备，需要确认",         // 通过双因素认证登录或在已信任设备上确认
+	ErrorSpendingLimitExceeded: "超出支出限额",              // 调高限额或提交双因素验证码超额发送
+	ErrorRecipientBlocked:      "接收地址在黑名单中",           // 可能是已知诈骗地址，warn 模式下需确认后发送
+	ErrorRelayQuotaExceeded:    "代付次数已用完",             // 每个用户24小时内可代付的次数由 relay.daily_quota 配置
+
+	// 链上交易失败细分错误消息
+	ErrorInsufficientFunds:      "余额不足以支付转账金额与Gas费用", // 充值原生币或减少金额
+	ErrorNonceTooLow:            "nonce已被使用",         // 交易已上链或指定的 nonce 过小
+	ErrorNonceTooHigh:           "nonce过高",           // 之前的 nonce 尚有空缺
+	ErrorReplacementUnderpriced: "替换交易的费率涨幅不足",       // 需比原交易至少高10%
+	ErrorTxUnderpriced:          "交易费率过低",            // 低于节点最低费率或区块基础费用
+	ErrorGasTooLow:              "Gas上限过低",           // 低于交易所需的Gas
+	ErrorGasLimitExceeded:       "Gas上限超过区块限制",       // 降低 gas_limit
+	ErrorExecutionReverted:      "合约执行失败",            // 合约 revert，详见 data
+	ErrorTxAlreadyKnown:         "交易已在交易池中",          // 重复广播相同交易
+	ErrorTxFeeCapExceeded:       "交易手续费超过节点上限",       // 节点 rpc.txfeecap 限制
+	ErrorRPCUnavailable:         "区块链节点暂时不可用",        // 连接失败、超时或被限流，可稍后重试
+
+	// 幂等键错误消息
+	ErrorIdempotencyInProgress: "相同请求正在处理中",                // 等待首次请求完成后再重试
+	ErrorIdempotencyKeyReused:  "Idempotency-Key 已被其他请求使用", // 新的转账需使用新的键
+
+	// 手续费上限错误消息
+	ErrorFeeTooHigh: "交易手续费超过设定的上限", // data 中包含预计手续费与上限
+
+	// 助记词校验错误消息
+	ErrorMnemonicWordCount:   "助记词单词数量无效", // 应为 12/15/18/21/24 个单词
+	ErrorMnemonicInvalidWord: "助记词包含无效单词", // data 中给出无效单词的位置
+	ErrorMnemonicChecksum:    "助记词校验和错误",  // 请检查单词是否抄错或顺序是否正确
 }
 
 // GetMsg 根据错误码获取对应的中文错误消息
@@ -0,0 +1,57 @@
+/*
+节点错误分类
+
+go-ethereum 与各类节点（geth、erigon、第三方RPC服务）在发送交易失败时只返回错误字符串，
+ClassifyRPCError 按已知的错误文本识别失败原因，返回细分错误码与面向用户的提示，
+使客户端可以按 code 分别处理（如余额不足提示充值、nonce 过低时刷新 nonce、节点不可用时重试）。
+*/
+package e
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+// rpcErrorRule 错误文本与错误码的对应关系
+type rpcErrorRule struct {
+	code     int
+	patterns []string // 小写，包含任意一个即命中
+	message  string   // 面向用户的提示
+}
+
+// rpcErrorRules 按顺序匹配；更具体的规则在前（如 replacement transaction underpriced 先于 transaction underpriced）
+var rpcErrorRules = []rpcErrorRule{
+	{ErrorInsufficientFunds, []string{"insufficient funds", "insufficient balance for transfer"}, "余额不足以支付转账金额与Gas费用，请充值原生币或减少金额"},
+	{ErrorNonceTooLow, []string{"nonce too low", "nonce has already been used", "already been imported"}, "nonce已被使用，请刷新nonce后重新发送"},
+	{ErrorNonceTooHigh, []string{"nonce too high", "nonce gap"}, "nonce过高，之前的nonce尚未使用"},
+	{ErrorReplacementUnderpriced, []string{"replacement transaction underpriced", "replacement fee too low"}, "替换交易的费率需比原交易至少高10%"},
+	{ErrorTxUnderpriced, []string{"transaction underpriced", "max fee per gas less than block base fee", "fee cap less than block base fee", "max priority fee per gas higher than max fee per gas", "tip higher than fee cap", "gas price below minimum"}, "交易费率过低，请提高gas价格后重试"},
+	{ErrorGasTooLow, []string{"intrinsic gas too low", "gas too low", "floor data gas cost"}, "Gas上限过低，请提高gas_limit"},
+	{ErrorGasLimitExceeded, []string{"exceeds block gas limit", "gas limit reached"}, "Gas上限超过区块限制，请降低gas_limit"},
+	{ErrorExecutionReverted, []string{"execution reverted", "out of gas", "invalid opcode", "transaction would revert"}, "合约执行失败，交易未发送"},
+	{ErrorTxAlreadyKnown, []string{"already known", "known transaction", "alreadyknown"}, "相同交易已在交易池中，无需重复发送"},
+	{ErrorTxFeeCapExceeded, []string{"exceeds the configured cap"}, "交易手续费超过节点允许的上限"},
+	{ErrorRPCUnavailable, []string{"connection refused", "connection reset", "no such host", "dial tcp", "i/o timeout", "context deadline exceeded", "too many requests", "502 bad gateway", "503 service unavailable", "504 gateway timeout", ": eof"}, "区块链节点暂时不可用，请稍后重试"},
+}
+
+// ClassifyRPCError 识别节点返回的交易错误，返回细分错误码与面向用户的提示
+// 无法识别时返回 ERROR 与通用失败消息，调用方可改用自身场景的错误码
+func ClassifyRPCError(err error) (code int, userMessage string) {
+	if err == nil {
+		return SUCCESS, GetMsg(SUCCESS)
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) {
+		return ErrorRPCUnavailable, "区块链节点暂时不可用，请稍后重试"
+	}
+	text := strings.ToLower(err.Error())
+	for _, rule := range rpcErrorRules {
+		for _, pattern := range rule.patterns {
+			if strings.Contains(text, pattern) {
+				return rule.code, rule.message
+			}
+		}
+	}
+	return ERROR, GetMsg(ERROR)
+}
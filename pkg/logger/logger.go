@@ -0,0 +1,238 @@
+/*
+结构化日志
+
+基于 log/slog 输出 JSON（或文本）日志：
+  - 请求ID经 WithRequestID 放入 context，使用 slog 的 *Context 方法记录时自动附加 request_id 字段，
+    同一请求触发的节点调用错误与访问日志可按 request_id 关联
+  - 输出前脱敏：字段名为助记词、私钥、签名、密码等的值整体替换；消息与字符串值中的
+    签名（65字节十六进制）、private_key=<64位十六进制> 形式的私钥以及连续 12 个以上 BIP39 单词替换为占位符
+  - 助记词识别英文与简体中文词表；中文日志本身由汉字组成，未以空白分隔的连续汉字只有在
+    单词数量有效且校验和正确时才视为助记词
+  - Init 将其设为默认日志，标准库 log 包的输出同样经过该处理器（级别为 INFO）
+
+结构体等复合值按 JSON 序列化输出，不做脱敏，记录时应只传入需要的字段。
+*/
+package logger
+
+import (
+	"context"
+	"crypto/sha256"
+	"io"
+	"log/slog"
+	"math/big"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39/wordlists"
+)
+
+// redacted 脱敏占位符
+const redacted = "[REDACTED]"
+
+// minMnemonicWords 视为助记词的最少连续单词数
+const minMnemonicWords = 12
+
+// sensitiveKeys 需整体脱敏的字段名（小写并去掉 _ 与 -）
+var sensitiveKeys = map[string]bool{
+	"mnemonic":   true,
+	"seed":       true,
+	"seedphrase": true,
+	"privatekey": true,
+	"privkey":    true,
+	"signature":  true,
+	"sig":        true,
+	"sighex":     true,
+	"password":   true,
+	"passphrase": true,
+	"secret":     true,
+}
+
+var (
+	signaturePattern  = regexp.MustCompile(`\b(?:0x)?[0-9a-fA-F]{130}\b`)
+	privateKeyPattern = regexp.MustCompile(`(?i)((?:private[ _-]?key|priv[ _-]?key|secret)["']?\s*[:=]?\s*["']?)(?:0x)?[0-9a-f]{64}\b`)
+	wordPattern       = regexp.MustCompile(`[A-Za-z]+|\p{Han}`) // 中文助记词每个汉字为一个单词
+)
+
+// BIP39 词表（单词 -> 序号）
+var (
+	englishWords = wordIndex(wordlists.English)
+	chineseWords = wordIndex(wordlists.ChineseSimplified)
+)
+
+func wordIndex(list []string) map[string]int {
+	index := make(map[string]int, len(list))
+	for i, w := range list {
+		index[w] = i
+	}
+	return index
+}
+
+type requestIDKey struct{}
+
+// WithRequestID 返回携带请求ID的 context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID 返回 context 中的请求ID，没有时为空
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Init 按配置创建日志并设为默认（包括标准库 log 包的输出）
+func Init(level, format string) *slog.Logger {
+	l := New(os.Stdout, ParseLevel(level), format)
+	slog.SetDefault(l)
+	return l
+}
+
+// New 创建输出到 w 的日志，format 为 text 时输出文本格式，其余为 JSON
+func New(w io.Writer, level slog.Level, format string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: redactAttr}
+	var h slog.Handler
+	if strings.EqualFold(format, "text") {
+		h = slog.NewTextHandler(w, opts)
+	} else {
+		h = slog.NewJSONHandler(w, opts)
+	}
+	return slog.New(contextHandler{h})
+}
+
+// ParseLevel 解析日志级别（debug / info / warn / error），无法识别时为 info
+func ParseLevel(level string) slog.Level {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(level))); err != nil {
+		return slog.LevelInfo
+	}
+	return l
+}
+
+// contextHandler 从 context 读取请求ID附加到每条日志
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// redactAttr 脱敏敏感字段与字符串中的敏感内容
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() == slog.KindGroup {
+		return a
+	}
+	if sensitiveKeys[normalizeKey(a.Key)] {
+		return slog.String(a.Key, redacted)
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		if s := a.Value.String(); s != "" {
+			return slog.String(a.Key, Redact(s))
+		}
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, Redact(err.Error()))
+		}
+	}
+	return a
+}
+
+func normalizeKey(key string) string {
+	key = strings.ToLower(key)
+	key = strings.ReplaceAll(key, "_", "")
+	return strings.ReplaceAll(key, "-", "")
+}
+
+// Redact 替换文本中的签名、私钥与助记词
+func Redact(s string) string {
+	s = signaturePattern.ReplaceAllString(s, redacted)
+	s = privateKeyPattern.ReplaceAllString(s, "${1}"+redacted)
+	return redactMnemonics(s)
+}
+
+// redactMnemonics 替换同一词表中连续 minMnemonicWords 个以上（仅以空白分隔）的 BIP39 单词
+// 未以空白分隔的连续汉字须单词数量有效且校验和正确，避免误替换普通的中文日志
+func redactMnemonics(s string) string {
+	words := wordPattern.FindAllStringIndex(s, -1)
+	if len(words) < minMnemonicWords {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	runStart := 0
+	chinese := false // 当前连续单词是否为中文
+	joined := true   // 当前连续单词之间是否都没有空白
+	flush := func(end int) {
+		if end-runStart < minMnemonicWords {
+			return
+		}
+		if chinese && joined && !mnemonicChecksumValid(s, words[runStart:end]) {
+			return
+		}
+		b.WriteString(s[last:words[runStart][0]])
+		b.WriteString(redacted)
+		last = words[end-1][1]
+	}
+	for i, w := range words {
+		word := strings.ToLower(s[w[0]:w[1]])
+		_, isEnglish := englishWords[word]
+		_, isChinese := chineseWords[word]
+		if !isEnglish && !isChinese {
+			flush(i)
+			runStart, joined = i+1, true
+			continue
+		}
+		if i > runStart {
+			gap := s[words[i-1][1]:w[0]]
+			if strings.TrimSpace(gap) != "" || isChinese != chinese {
+				// 与前一个单词之间有非空白字符或属于不同词表，重新开始计数
+				flush(i)
+				runStart, joined = i, true
+			} else if gap != "" {
+				joined = false
+			}
+		}
+		chinese = isChinese
+	}
+	flush(len(words))
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// mnemonicChecksumValid 连续汉字是否为单词数量有效（12/15/18/21/24）且校验和正确的中文助记词
+// 每个单词 11 位，末尾 n/3 位为熵的 SHA-256 首字节高位（与 core 的助记词校验一致）
+func mnemonicChecksumValid(s string, words [][]int) bool {
+	n := len(words)
+	if n%3 != 0 || n < 12 || n > 24 {
+		return false
+	}
+	bits := new(big.Int)
+	for _, w := range words {
+		bits.Lsh(bits, 11)
+		bits.Or(bits, big.NewInt(int64(chineseWords[s[w[0]:w[1]]])))
+	}
+	checksumBits := uint(n / 3)
+	checksum := new(big.Int).And(bits, big.NewInt(1<<checksumBits-1))
+	entropy := new(big.Int).Rsh(bits, checksumBits).FillBytes(make([]byte, n*4/3))
+	hash := sha256.Sum256(entropy)
+	return uint64(hash[0]>>(8-checksumBits)) == checksum.Uint64()
+}
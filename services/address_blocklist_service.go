@@ -0,0 +1,233 @@
+/*
+收款地址黑名单
+
+发送前按以下顺序检查接收地址：
+- 用户自己的黑名单（数据库，OwnerAddress 为该钱包）
+- 全局黑名单（管理员添加、配置文件静态地址、远程名单，见 core.AddressBlocklist）
+
+命中后按 address_blocklist.mode 处理：block 直接拒绝；warn 提示风险，用户确认后放行。
+每次命中都记入审计日志（blocked_recipient_attempt），用户自己的黑名单只由用户本人维护，全局条目只有管理员可增删。
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"wallet/config"
+	"wallet/core"
+	"wallet/database"
+	"wallet/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 收款地址黑名单错误
+var (
+	ErrRecipientBlocked         = errors.New("接收地址在黑名单中，已拒绝发送")
+	ErrRecipientConfirmRequired = errors.New("接收地址在黑名单中，确认风险后才能发送")
+	ErrBlockedAddressNotFound   = errors.New("黑名单中没有该地址")
+	ErrBlocklistAdminRequired   = errors.New("只有管理员可以管理全局黑名单")
+)
+
+// RecipientCheck 接收地址检查结果
+type RecipientCheck struct {
+	Address string `json:"address"`
+	Blocked bool   `json:"blocked"`
+	Reason  string `json:"reason,omitempty"`
+	Source  string `json:"source,omitempty"` // user / global / config / feed
+	Action  string `json:"action,omitempty"` // 命中时的处理方式：block / warn
+}
+
+// BlockedAddressEntry 黑名单条目
+type BlockedAddressEntry struct {
+	Address   string    `json:"address"`
+	Reason    string    `json:"reason"`
+	Global    bool      `json:"global"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// initAddressBlocklist 加载配置中的静态地址与数据库中的全局条目，并启动远程名单刷新
+func (ss *SecurityService) initAddressBlocklist() {
+	blocklistConfig := config.AppConfig.Blocklist.WithDefaults()
+	blocklist := ss.securityManager.AddressBlocklist()
+	blocklist.SetStatic(blocklistConfig.Addresses)
+
+	if database.DB != nil {
+		var records []models.BlockedAddress
+		if err := database.DB.Where("owner_address = ?", "").Find(&records).Error; err != nil {
+			slog.DebugContext(context.Background(), "global address blocklist load failed", "error", err)
+		}
+		for _, record := range records {
+			blocklist.SetGlobal(record.Address, record.Reason)
+		}
+	}
+	blocklist.StartRefresh(context.Background(), blocklistConfig.FeedURLs, time.Duration(blocklistConfig.RefreshMinutes)*time.Minute)
+}
+
+// BlocklistMode 命中黑名单时的处理方式：block / warn
+func BlocklistMode() string {
+	return config.AppConfig.Blocklist.WithDefaults().Mode
+}
+
+// CheckRecipient 检查接收地址是否在用户黑名单或全局黑名单中
+func (ss *SecurityService) CheckRecipient(owner, address string) (*RecipientCheck, error) {
+	addr := core.NormalizeBlockedAddress(address)
+	if addr == "" {
+		return nil, fmt.Errorf("%w: %s", core.ErrInvalidBlockedAddress, address)
+	}
+	check := &RecipientCheck{Address: addr}
+
+	if owner != "" && database.DB != nil {
+		var record models.BlockedAddress
+		err := database.DB.Where("owner_address = ? AND address = ?", strings.ToLower(owner), addr).First(&record).Error
+		switch {
+		case err == nil:
+			check.Blocked, check.Reason, check.Source = true, record.Reason, core.BlocklistSourceUser
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, fmt.Errorf("查询地址黑名单失败: %w", err)
+		}
+	}
+	if !check.Blocked {
+		if match, ok := ss.securityManager.AddressBlocklist().Lookup(addr); ok {
+			check.Blocked, check.Reason, check.Source = true, match.Reason, match.Source
+		}
+	}
+	if check.Blocked {
+		check.Action = BlocklistMode()
+	}
+	return check, nil
+}
+
+// AuthorizeRecipient 发送前检查接收地址，命中黑名单时记入审计日志
+// block 模式返回 ErrRecipientBlocked；warn 模式未确认时返回 ErrRecipientConfirmRequired，已确认时放行并返回命中结果
+func (ss *SecurityService) AuthorizeRecipient(ctx context.Context, owner, address string, confirmed bool, resource string) (*RecipientCheck, error) {
+	check, err := ss.CheckRecipient(owner, address)
+	if err != nil || !check.Blocked {
+		return check, err
+	}
+
+	result := "denied"
+	switch {
+	case check.Action == config.BlocklistModeWarn && confirmed:
+		result = "confirmed"
+		err = nil
+	case check.Action == config.BlocklistModeWarn:
+		result = "confirm_required"
+		err = ErrRecipientConfirmRequired
+	default:
+		err = ErrRecipientBlocked
+	}
+
+	actx := core.AuditContextFrom(ctx)
+	if actx.UserAddress == "" {
+		actx.UserAddress = owner
+	}
+	ss.securityManager.LogSecurityAction(core.WithAuditContext(ctx, actx), "blocked_recipient_attempt", "recipient", check.Address, result, map[string]interface{}{
+		"endpoint": resource,
+		"source":   check.Source,
+		"reason":   check.Reason,
+	})
+	return check, err
+}
+
+// ListBlockedAddresses 返回用户自己的黑名单；global 为 true 时返回管理员维护的全局条目
+func (ss *SecurityService) ListBlockedAddresses(owner string, global bool) ([]BlockedAddressEntry, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	scope, err := ss.blocklistScope(owner, global)
+	if err != nil {
+		return nil, err
+	}
+	var records []models.BlockedAddress
+	if err := database.DB.Where("owner_address = ?", scope).Order("id DESC").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("查询地址黑名单失败: %w", err)
+	}
+	entries := make([]BlockedAddressEntry, 0, len(records))
+	for _, record := range records {
+		entries = append(entries, BlockedAddressEntry{Address: record.Address, Reason: record.Reason, Global: global, CreatedAt: record.CreatedAt})
+	}
+	return entries, nil
+}
+
+// AddBlockedAddress 添加或更新黑名单条目；global 为 true 时添加全局条目（需管理员）
+func (ss *SecurityService) AddBlockedAddress(ctx context.Context, owner, address, reason string, global bool) (*BlockedAddressEntry, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	scope, err := ss.blocklistScope(owner, global)
+	if err != nil {
+		return nil, err
+	}
+	addr := core.NormalizeBlockedAddress(address)
+	if addr == "" {
+		return nil, fmt.Errorf("%w: %s", core.ErrInvalidBlockedAddress, address)
+	}
+	reason = truncate(strings.TrimSpace(reason), 255)
+
+	record := models.BlockedAddress{OwnerAddress: scope, Address: addr, Reason: reason, CreatedBy: strings.ToLower(owner)}
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "owner_address"}, {Name: "address"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"reason": reason, "created_by": record.CreatedBy, "updated_at": time.Now(), "deleted_at": nil}),
+	}).Create(&record).Error; err != nil {
+		return nil, fmt.Errorf("保存地址黑名单失败: %w", err)
+	}
+	if global {
+		ss.securityManager.AddressBlocklist().SetGlobal(addr, reason)
+	}
+	ss.securityManager.LogSecurityAction(ctx, "add_blocked_address", "blocklist", addr, "success", map[string]interface{}{
+		"global": global,
+		"reason": reason,
+	})
+	return &BlockedAddressEntry{Address: addr, Reason: reason, Global: global, CreatedAt: record.CreatedAt}, nil
+}
+
+// RemoveBlockedAddress 删除黑名单条目；global 为 true 时删除全局条目（需管理员）
+func (ss *SecurityService) RemoveBlockedAddress(ctx context.Context, owner, address string, global bool) error {
+	if database.DB == nil {
+		return errors.New("数据库未初始化")
+	}
+	scope, err := ss.blocklistScope(owner, global)
+	if err != nil {
+		return err
+	}
+	addr := core.NormalizeBlockedAddress(address)
+	if addr == "" {
+		return fmt.Errorf("%w: %s", core.ErrInvalidBlockedAddress, address)
+	}
+	result := database.DB.Unscoped().Where("owner_address = ? AND address = ?", scope, addr).Delete(&models.BlockedAddress{})
+	if result.Error != nil {
+		return fmt.Errorf("删除地址黑名单失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrBlockedAddressNotFound
+	}
+	if global {
+		ss.securityManager.AddressBlocklist().RemoveGlobal(addr)
+	}
+	ss.securityManager.LogSecurityAction(ctx, "remove_blocked_address", "blocklist", addr, "success", map[string]interface{}{
+		"global": global,
+	})
+	return nil
+}
+
+// BlocklistStatus 全局黑名单各来源的状态
+func (ss *SecurityService) BlocklistStatus() core.BlocklistStatus {
+	return ss.securityManager.AddressBlocklist().Status()
+}
+
+// blocklistScope 黑名单条目归属：全局条目为空字符串，需管理员权限
+func (ss *SecurityService) blocklistScope(owner string, global bool) (string, error) {
+	if !global {
+		return strings.ToLower(owner), nil
+	}
+	if !ss.IsAuditAdmin(owner) {
+		return "", ErrBlocklistAdminRequired
+	}
+	return "", nil
+}
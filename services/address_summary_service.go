@@ -0,0 +1,104 @@
+/*
+地址活跃度摘要服务
+
+查询当前网络上地址的首次出现、交易数、最近活跃、是否为合约、原生币余额与持有代币数（见 core.GetAddressSummary），
+帮助用户在转账前判断收款地址是长期使用的地址还是刚创建的地址。
+
+摘要按 (网络, 地址) 缓存 CacheTTLSeconds 秒：首次出现需要数十次历史状态查询，短时间内重复查看同一地址时不再访问节点。
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"wallet/config"
+	"wallet/core"
+)
+
+// AddressSummaryResult 地址活跃度摘要及其网络与缓存信息
+type AddressSummaryResult struct {
+	*core.AddressSummary
+	Network   string    `json:"network"`
+	UpdatedAt time.Time `json:"updated_at"` // 数据获取时间
+	Cached    bool      `json:"cached"`     // 是否来自缓存
+}
+
+// addressSummaryCacheEntry 摘要缓存条目
+type addressSummaryCacheEntry struct {
+	result    *AddressSummaryResult
+	expiresAt time.Time
+}
+
+// AddressSummaryService 地址活跃度摘要服务
+type AddressSummaryService struct {
+	multiChain *core.MultiChainManager
+	cfg        config.AddressSummaryConfig
+	cache      map[string]*addressSummaryCacheEntry
+	mu         sync.RWMutex
+}
+
+// NewAddressSummaryService 创建地址活跃度摘要服务
+func NewAddressSummaryService(multiChain *core.MultiChainManager, cfg config.AddressSummaryConfig) *AddressSummaryService {
+	return &AddressSummaryService{
+		multiChain: multiChain,
+		cfg:        cfg.WithDefaults(),
+		cache:      make(map[string]*addressSummaryCacheEntry),
+	}
+}
+
+// GetAddressSummary 查询当前网络上地址的活跃度摘要，优先返回未过期的缓存
+func (as *AddressSummaryService) GetAddressSummary(ctx context.Context, address string) (*AddressSummaryResult, error) {
+	networkID := as.multiChain.GetCurrentNetwork()
+	key := networkID + ":" + strings.ToLower(address)
+	if cached := as.getCached(key); cached != nil {
+		return cached, nil
+	}
+
+	adapter, err := as.multiChain.GetCurrentAdapter()
+	if err != nil {
+		return nil, err
+	}
+	evmAdapter, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		return nil, fmt.Errorf("当前链不支持地址摘要")
+	}
+	summary, err := evmAdapter.GetAddressSummary(ctx, address, as.cfg.DetectLookbackBlocks, as.cfg.MaxTokens)
+	if err != nil {
+		return nil, err
+	}
+	result := &AddressSummaryResult{AddressSummary: summary, Network: networkID, UpdatedAt: time.Now()}
+	as.setCached(key, result)
+	return result, nil
+}
+
+// getCached 读取未过期的缓存，返回副本并标记 Cached
+func (as *AddressSummaryService) getCached(key string) *AddressSummaryResult {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+	entry, ok := as.cache[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil
+	}
+	cached := *entry.result
+	cached.Cached = true
+	return &cached
+}
+
+// setCached 写入缓存并清理过期条目
+func (as *AddressSummaryService) setCached(key string, result *AddressSummaryResult) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	now := time.Now()
+	for k, entry := range as.cache {
+		if now.After(entry.expiresAt) {
+			delete(as.cache, k)
+		}
+	}
+	as.cache[key] = &addressSummaryCacheEntry{
+		result:    result,
+		expiresAt: now.Add(time.Duration(as.cfg.CacheTTLSeconds) * time.Second),
+	}
+}
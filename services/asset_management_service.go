@@ -18,6 +18,7 @@ import (
 type AssetManagementService struct {
 	assetManager   *core.AssetManager         // 资产管理器
 	multiChain     *core.MultiChainManager    // 多链管理器
+	priceService   *PriceService              // 代币美元价格服务
 	portfolioCache map[string]*PortfolioCache // 投资组合缓存
 	mu             sync.RWMutex               // 读写锁
 }
@@ -66,10 +67,11 @@ type AssetPerformance struct {
 }
 
 // NewAssetManagementService 创建资产管理服务
-func NewAssetManagementService(multiChain *core.MultiChainManager) *AssetManagementService {
+func NewAssetManagementService(multiChain *core.MultiChainManager, priceService *PriceService) *AssetManagementService {
 	return &AssetManagementService{
 		assetManager:   core.NewAssetManager(),
 		multiChain:     multiChain,
+		priceService:   priceService,
 		portfolioCache: make(map[string]*PortfolioCache),
 	}
 }
@@ -89,6 +91,9 @@ func (ams *AssetManagementService) GetPortfolio(ctx context.Context, request *Po
 		return nil, fmt.Errorf("分析投资组合失败: %w", err)
 	}
 
+	// 按实时美元价格估值
+	ams.valuePortfolio(ctx, portfolio)
+
 	// 应用过滤器
 	portfolio = ams.applyFilters(portfolio, request)
 
@@ -145,6 +150,60 @@ func (ams *AssetManagementService) cachePortfolio(userAddress string, portfolio
 	}
 }
 
+// valuePortfolio 按链批量查询持仓代币的美元价格，填充持仓价值、总价值与权重
+// 无价格数据的持仓标记 NoPrice，价值计为 0
+func (ams *AssetManagementService) valuePortfolio(ctx context.Context, portfolio *core.Portfolio) {
+	if ams.priceService == nil || len(portfolio.Holdings) == 0 {
+		return
+	}
+
+	byChain := make(map[int][]*core.AssetHolding)
+	for _, holding := range portfolio.Holdings {
+		chainID := ChainIDForNetwork(holding.Chain)
+		if chainID == 0 {
+			holding.NoPrice = true
+			continue
+		}
+		byChain[chainID] = append(byChain[chainID], holding)
+	}
+
+	total := 0.0
+	for chainID, holdings := range byChain {
+		tokens := make([]string, 0, len(holdings))
+		for _, holding := range holdings {
+			tokens = append(tokens, holding.ContractAddress)
+		}
+		prices, err := ams.priceService.GetTokenPricesUSD(ctx, chainID, tokens)
+		if err != nil {
+			for _, holding := range holdings {
+				holding.NoPrice = true
+			}
+			continue
+		}
+		for _, holding := range holdings {
+			price := prices[normalizePriceToken(holding.ContractAddress)]
+			if price == nil || price.NoPrice {
+				holding.NoPrice = true
+				continue
+			}
+			decimals := holding.Decimals
+			if decimals == 0 {
+				decimals = 18
+			}
+			holding.PriceUSD = price.PriceUSD
+			holding.ValueUSD = ValueUSD(holding.Balance, decimals, price.PriceUSD)
+			total += holding.ValueUSD
+		}
+	}
+
+	portfolio.TotalValueUSD = total
+	if total > 0 {
+		for _, holding := range portfolio.Holdings {
+			holding.Weight = holding.ValueUSD / total
+		}
+	}
+}
+
 // applyFilters 应用过滤器
 func (ams *AssetManagementService) applyFilters(portfolio *core.Portfolio, request *PortfolioRequest) *core.Portfolio {
 	// 简化实现：直接返回原始组合
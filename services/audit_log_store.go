@@ -0,0 +1,122 @@
+/*
+安全审计日志持久化
+
+实现 core.AuditLogStore，将审计日志写入 audit_logs 表：
+- 用户地址统一小写存储，按 (user_address, timestamp) 与 (action, timestamp) 索引支持查询接口
+- 详细信息以 JSON 文本存储
+- 过期日志先按主键取一批再删除，兼容不支持 DELETE ... LIMIT 的数据库
+*/
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"wallet/core"
+	"wallet/database"
+	"wallet/models"
+)
+
+// dbAuditLogStore 基于数据库的审计日志存储
+type dbAuditLogStore struct{}
+
+// SaveAuditLog 写入一条审计日志
+func (dbAuditLogStore) SaveAuditLog(entry core.AuditLog) error {
+	details := ""
+	if len(entry.Details) > 0 {
+		raw, err := json.Marshal(entry.Details)
+		if err != nil {
+			return fmt.Errorf("序列化审计日志详情失败: %w", err)
+		}
+		details = string(raw)
+	}
+	record := models.AuditLog{
+		LogID:         entry.ID,
+		Timestamp:     entry.Timestamp.UTC(),
+		UserAddress:   strings.ToLower(entry.UserAddress),
+		Action:        entry.Action,
+		Resource:      entry.Resource,
+		ResourceID:    entry.ResourceID,
+		Result:        entry.Result,
+		IPAddress:     entry.IPAddress,
+		UserAgent:     entry.UserAgent,
+		DeviceID:      entry.DeviceID,
+		SessionID:     entry.SessionID,
+		Details:       details,
+		RiskScore:     entry.RiskScore,
+		SecurityLevel: entry.SecurityLevel,
+	}
+	return database.DB.Create(&record).Error
+}
+
+// QueryAuditLogs 按条件分页查询审计日志（时间倒序）
+func (dbAuditLogStore) QueryAuditLogs(filter core.AuditLogFilter) ([]core.AuditLog, int, error) {
+	query := database.DB.Model(&models.AuditLog{})
+	if filter.UserAddress != "" {
+		query = query.Where("user_address = ?", strings.ToLower(filter.UserAddress))
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Result != "" {
+		query = query.Where("result = ?", filter.Result)
+	}
+	if filter.StartTime != nil {
+		query = query.Where("timestamp >= ?", filter.StartTime.UTC())
+	}
+	if filter.EndTime != nil {
+		query = query.Where("timestamp <= ?", filter.EndTime.UTC())
+	}
+	if filter.MinRiskScore > 0 {
+		query = query.Where("risk_score >= ?", filter.MinRiskScore)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计审计日志失败: %w", err)
+	}
+	var records []models.AuditLog
+	if err := query.Order("timestamp DESC, id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&records).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询审计日志失败: %w", err)
+	}
+
+	logs := make([]core.AuditLog, 0, len(records))
+	for _, r := range records {
+		entry := core.AuditLog{
+			ID:            r.LogID,
+			Timestamp:     r.Timestamp,
+			UserAddress:   r.UserAddress,
+			Action:        r.Action,
+			Resource:      r.Resource,
+			ResourceID:    r.ResourceID,
+			Result:        r.Result,
+			IPAddress:     r.IPAddress,
+			UserAgent:     r.UserAgent,
+			DeviceID:      r.DeviceID,
+			SessionID:     r.SessionID,
+			RiskScore:     r.RiskScore,
+			SecurityLevel: r.SecurityLevel,
+		}
+		if r.Details != "" {
+			_ = json.Unmarshal([]byte(r.Details), &entry.Details)
+		}
+		logs = append(logs, entry)
+	}
+	return logs, int(total), nil
+}
+
+// DeleteAuditLogsBefore 删除 cutoff 之前的审计日志，最多 limit 条
+func (dbAuditLogStore) DeleteAuditLogsBefore(cutoff time.Time, limit int) (int, error) {
+	var ids []uint
+	err := database.DB.Unscoped().Model(&models.AuditLog{}).
+		Where("timestamp < ?", cutoff.UTC()).Order("id").Limit(limit).Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := database.DB.Unscoped().Where("id IN ?", ids).Delete(&models.AuditLog{})
+	return int(result.RowsAffected), result.Error
+}
@@ -0,0 +1,129 @@
+/*
+余额预留提醒服务
+
+用户经常把账户余额全部转出，导致后续交易无法支付Gas。
+本文件在原生代币发送前做预检：若发送后（扣除转账金额与本笔Gas费用）余额低于预留值，
+返回提醒信息，由接口随发送结果一起返回。提醒不阻止发送。
+
+预留值支持两种方式（config.balance_reserve）：
+- absolute：固定金额（wei）
+- transactions：当前Gas价格 × 单笔转账Gas × N，随网络费用动态变化
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"wallet/config"
+	"wallet/core"
+)
+
+// BalanceReserveWarning 余额预留提醒
+type BalanceReserveWarning struct {
+	Code         string `json:"code"`          // 提醒类型，固定为 below_reserve
+	Message      string `json:"message"`       // 提醒说明
+	Mode         string `json:"mode"`          // 预留方式
+	BalanceWei   string `json:"balance_wei"`   // 发送前余额
+	CostWei      string `json:"cost_wei"`      // 本笔预计花费（金额 + Gas费用）
+	RemainingWei string `json:"remaining_wei"` // 发送后预计剩余
+	ReserveWei   string `json:"reserve_wei"`   // 预留值
+}
+
+// CheckBalanceReserve 检查原生代币发送后余额是否低于预留值
+// 低于预留值时返回提醒；余额充足、未启用或查询失败时返回 nil（预检失败不影响发送）
+func (s *WalletService) CheckBalanceReserve(ctx context.Context, from string, valueWei *big.Int, opts *TxOptions) *BalanceReserveWarning {
+	cfg := config.AppConfig.Reserve.WithDefaults()
+	if cfg.Mode == config.ReserveModeDisabled || from == "" {
+		return nil
+	}
+	adapter, err := s.multiChain.GetCurrentAdapter()
+	if err != nil {
+		return nil
+	}
+	evmAdapter, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		return nil
+	}
+
+	balance, err := evmAdapter.GetBalance(ctx, from)
+	if err != nil {
+		return nil
+	}
+	gasPrice, err := reserveGasPrice(ctx, evmAdapter, opts)
+	if err != nil {
+		return nil
+	}
+	reserve, err := balanceReserve(cfg, gasPrice)
+	if err != nil || reserve.Sign() <= 0 {
+		return nil
+	}
+
+	gasLimit := cfg.TransferGas
+	if opts != nil && opts.GasLimit > 0 {
+		gasLimit = opts.GasLimit
+	}
+	cost := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasLimit))
+	if valueWei != nil {
+		cost.Add(cost, valueWei)
+	}
+	remaining := new(big.Int).Sub(balance, cost)
+	if remaining.Cmp(reserve) >= 0 {
+		return nil
+	}
+
+	native := s.GetNativeCurrency()
+	message := fmt.Sprintf("发送后余额预计为 %s，低于预留值 %s，可能不足以支付后续交易的Gas费用", native.Format(remaining), native.Format(reserve))
+	if cfg.Mode == config.ReserveModeTransactions {
+		message = fmt.Sprintf("发送后余额预计为 %s，不足以支付后续 %d 笔转账的Gas费用（约 %s）", native.Format(remaining), cfg.Transactions, native.Format(reserve))
+	}
+	return &BalanceReserveWarning{
+		Code:         "below_reserve",
+		Message:      message,
+		Mode:         cfg.Mode,
+		BalanceWei:   balance.String(),
+		CostWei:      cost.String(),
+		RemainingWei: remaining.String(),
+		ReserveWei:   reserve.String(),
+	}
+}
+
+// balanceReserve 按配置计算预留值
+func balanceReserve(cfg config.BalanceReserveConfig, gasPrice *big.Int) (*big.Int, error) {
+	switch cfg.Mode {
+	case config.ReserveModeAbsolute:
+		reserve, ok := new(big.Int).SetString(cfg.AbsoluteWei, 10)
+		if !ok {
+			return nil, fmt.Errorf("无效的预留金额: %s", cfg.AbsoluteWei)
+		}
+		return reserve, nil
+	case config.ReserveModeTransactions:
+		reserve := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(cfg.TransferGas))
+		return reserve.Mul(reserve, big.NewInt(int64(cfg.Transactions))), nil
+	default:
+		return nil, fmt.Errorf("不支持的预留方式: %s", cfg.Mode)
+	}
+}
+
+// reserveGasPrice 确定计算费用使用的Gas价格：优先用户指定，其次节点建议
+func reserveGasPrice(ctx context.Context, evmAdapter *core.EVMAdapter, opts *TxOptions) (*big.Int, error) {
+	if opts != nil {
+		if opts.FeeCap != nil && opts.FeeCap.Sign() > 0 {
+			return opts.FeeCap, nil
+		}
+		if opts.GasPrice != nil && opts.GasPrice.Sign() > 0 {
+			return opts.GasPrice, nil
+		}
+	}
+	suggestion, err := evmAdapter.GetGasSuggestion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if suggestion.MaxFee != nil && suggestion.MaxFee.Sign() > 0 {
+		return suggestion.MaxFee, nil
+	}
+	if suggestion.GasPrice != nil && suggestion.GasPrice.Sign() > 0 {
+		return suggestion.GasPrice, nil
+	}
+	return nil, fmt.Errorf("无法获取Gas价格")
+}
@@ -15,6 +15,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"net/url"
 	"sync"
 	"time"
 	"wallet/core"
@@ -72,6 +73,7 @@ type BridgeExecuteRequest struct {
 	Mnemonic          string  `json:"mnemonic" binding:"required"`
 	DerivationPath    string  `json:"derivation_path"`
 	SessionID         string  `json:"session_id"`
+	CallbackURL       string  `json:"callback_url"` // 桥接结束时回调的Webhook地址（可选，http/https）
 }
 
 // BridgeExecuteResponse 桥接执行响应
@@ -82,6 +84,7 @@ type BridgeExecuteResponse struct {
 	EstimatedTime int64    `json:"estimated_time"`
 	TrackingURL   string   `json:"tracking_url"`
 	NextSteps     []string `json:"next_steps"`
+	AmountOutMin  *big.Int `json:"amount_out_min"` // 报价最低到账数量
 }
 
 // BridgeStatusResponse 桥接状态响应
@@ -89,8 +92,19 @@ type BridgeStatusResponse struct {
 	BridgeID            string    `json:"bridge_id"`
 	Status              string    `json:"status"`
 	Progress            float64   `json:"progress"`
+	CurrentStep         int       `json:"current_step"`
+	TotalSteps          int       `json:"total_steps"`
+	ConfirmBlocks       int       `json:"confirm_blocks"`
+	RequiredBlocks      int       `json:"required_blocks"`
+	FromChain           string    `json:"from_chain"`
+	ToChain             string    `json:"to_chain"`
 	FromTxHash          string    `json:"from_tx_hash"`
 	ToTxHash            string    `json:"to_tx_hash"`
+	ErrorMessage        string    `json:"error_message,omitempty"`
+	AmountOutMin        *big.Int  `json:"amount_out_min,omitempty"`  // 报价最低到账数量
+	AmountReceived      *big.Int  `json:"amount_received,omitempty"` // 实际到账数量
+	BelowMinimum        bool      `json:"below_minimum"`             // 实际到账低于最低到账
+	UpdatedAt           time.Time `json:"updated_at"`
 	EstimatedCompletion time.Time `json:"estimated_completion"`
 	NextAction          string    `json:"next_action"`
 }
@@ -110,11 +124,13 @@ func NewBridgeService(multiChain *core.MultiChainManager) (*BridgeService, error
 		return nil, fmt.Errorf("创建桥接管理器失败: %w", err)
 	}
 
-	return &BridgeService{
+	service := &BridgeService{
 		bridgeManager: bridgeManager,
 		multiChain:    multiChain,
 		bridgeHistory: make(map[string]*BridgeUserHistory),
-	}, nil
+	}
+	bridgeManager.OnBridgeFinished(service.finishBridgeRecord)
+	return service, nil
 }
 
 // GetBestRoute 获取最佳桥接路径
@@ -152,6 +168,16 @@ func (s *BridgeService) GetBestRoute(ctx context.Context, request *BridgeQuoteRe
 
 // ExecuteBridge 执行桥接
 func (s *BridgeService) ExecuteBridge(ctx context.Context, request *BridgeExecuteRequest) (*BridgeExecuteResponse, error) {
+	if request.CallbackURL != "" {
+		u, err := url.Parse(request.CallbackURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("无效的回调地址: %s", request.CallbackURL)
+		}
+		if err := core.CheckOutboundHost(ctx, u.Hostname(), nil); err != nil {
+			return nil, fmt.Errorf("无效的回调地址: %w", err)
+		}
+	}
+
 	// 构建桥接参数
 	amount, _ := new(big.Int).SetString(request.Amount, 10)
 	params := &core.BridgeParams{
@@ -164,6 +190,7 @@ func (s *BridgeService) ExecuteBridge(ctx context.Context, request *BridgeExecut
 		SlippageTolerance: request.SlippageTolerance,
 		Priority:          request.Priority,
 		Deadline:          request.Deadline,
+		CallbackURL:       request.CallbackURL,
 	}
 
 	// 构建认证信息
@@ -190,6 +217,7 @@ func (s *BridgeService) ExecuteBridge(ctx context.Context, request *BridgeExecut
 		EstimatedTime: result.EstimatedTime,
 		TrackingURL:   s.generateTrackingURL(result.BridgeID),
 		NextSteps:     s.generateNextSteps(),
+		AmountOutMin:  result.AmountOutMin,
 	}
 
 	return response, nil
@@ -210,8 +238,19 @@ func (s *BridgeService) GetBridgeStatus(ctx context.Context, bridgeID string) (*
 		BridgeID:            status.BridgeID,
 		Status:              status.Status,
 		Progress:            status.Progress,
+		CurrentStep:         status.CurrentStep,
+		TotalSteps:          status.TotalSteps,
+		ConfirmBlocks:       status.ConfirmBlocks,
+		RequiredBlocks:      status.RequiredBlocks,
+		FromChain:           status.FromChain,
+		ToChain:             status.ToChain,
 		FromTxHash:          status.FromTxHash,
 		ToTxHash:            status.ToTxHash,
+		ErrorMessage:        status.ErrorMessage,
+		AmountOutMin:        status.AmountOutMin,
+		AmountReceived:      status.AmountReceived,
+		BelowMinimum:        status.BelowMinimum,
+		UpdatedAt:           status.UpdatedAt,
 		EstimatedCompletion: status.EstimatedCompletion,
 		NextAction:          s.determineNextAction(status),
 	}
@@ -278,6 +317,29 @@ func (s *BridgeService) recordBridgeHistory(userAddress string, result *core.Bri
 	s.bridgeHistory[userAddress].LastActivity = time.Now()
 }
 
+// finishBridgeRecord 桥接结束时更新对应历史记录的结果与到账核对
+func (s *BridgeService) finishBridgeRecord(status *core.BridgeStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, history := range s.bridgeHistory {
+		for _, record := range history.Records {
+			if record.BridgeResult == nil || record.BridgeID != status.BridgeID {
+				continue
+			}
+			completedAt := status.UpdatedAt
+			record.CompletedAt = &completedAt
+			record.Status = status.Status
+			record.ToTxHash = status.ToTxHash
+			record.Success = status.Status == core.BridgeStatusCompleted
+			record.FailureReason = status.ErrorMessage
+			record.AmountReceived = status.AmountReceived
+			record.BelowMinimum = status.BelowMinimum
+			return
+		}
+	}
+}
+
 // generateTrackingURL 生成追踪URL
 func (s *BridgeService) generateTrackingURL(bridgeID string) string {
 	return fmt.Sprintf("https://wallet.example.com/bridge/track/%s", bridgeID)
@@ -296,13 +358,19 @@ func (s *BridgeService) generateNextSteps() []string {
 // determineNextAction 确定下一步操作
 func (s *BridgeService) determineNextAction(status *core.BridgeStatus) string {
 	switch status.Status {
-	case "pending":
+	case core.BridgeStatusPending:
 		return "等待交易确认"
-	case "processing":
+	case core.BridgeStatusConfirming:
+		return fmt.Sprintf("源链确认中（%d/%d）", status.ConfirmBlocks, status.RequiredBlocks)
+	case core.BridgeStatusProcessing:
 		return "桥接处理中，请耐心等待"
-	case "completed":
+	case core.BridgeStatusClaimable:
+		return "已通过检查点，请在目标链提交领取交易"
+	case core.BridgeStatusCompleted:
 		return "桥接已完成"
-	case "failed":
+	case core.BridgeStatusExpired:
+		return "超时未确认到账，请在目标链浏览器核实"
+	case core.BridgeStatusFailed:
 		return "桥接失败，请联系客服"
 	default:
 		return "检查交易状态"
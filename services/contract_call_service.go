@@ -0,0 +1,49 @@
+/*
+通用合约方法调用
+
+基于调用方提供的ABI读写任意合约，参数为JSON解码后的值，由 core 按ABI类型转换与编码。
+*/
+package services
+
+import (
+	"context"
+	"math/big"
+	"time"
+	"wallet/core"
+)
+
+// contractCallTimeout 合约只读调用的超时时间
+const contractCallTimeout = 15 * time.Second
+
+// CallContractMethod 在当前网络按ABI调用合约只读方法
+func (s *WalletService) CallContractMethod(ctx context.Context, contract, abiJSON, method string, args []interface{}) ([]interface{}, error) {
+	evmAdapter, err := s.currentEVMAdapter("合约调用")
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, contractCallTimeout)
+	defer cancel()
+	return evmAdapter.CallMethod(ctx, contract, abiJSON, method, args)
+}
+
+// SendContractMethod 在当前网络按ABI发送合约写入交易，sessionID 优先，未提供时使用 mnemonic
+func (s *WalletService) SendContractMethod(ctx context.Context, sessionID, mnemonic, derivationPath, contract, abiJSON, method string, args []interface{}, value *big.Int, opts *TxOptions) (string, error) {
+	ctx = sendContext(ctx)
+	evmAdapter, err := s.currentEVMAdapter("合约交易")
+	if err != nil {
+		return "", err
+	}
+	var signer core.Signer
+	if sessionID != "" {
+		signer, err = s.SessionSigner(sessionID, derivationPath)
+	} else {
+		if derivationPath == "" {
+			derivationPath = "m/44'/60'/0'/0/0"
+		}
+		signer, err = core.NewMnemonicSigner(mnemonic, derivationPath)
+	}
+	if err != nil {
+		return "", err
+	}
+	return evmAdapter.SendPayableMethod(ctx, signer, contract, abiJSON, method, args, value, s.toCoreTxOptions(opts))
+}
@@ -0,0 +1,208 @@
+/*
+自定义网络（自定义RPC）
+
+用户可在运行时添加配置文件之外的EVM网络（本地节点、新的L2等），对应 wallet_addEthereumChain：
+- 添加时连接 RPC 并校验节点链ID与声明一致，网络ID或链ID已被使用时拒绝
+- 可同时提供备用RPC地址，主节点故障时自动切换（见 core/rpc_pool.go）
+- 添加成功后持久化到数据库，服务启动时重新加载；加载失败的网络跳过并打印警告
+- RPC 地址解析到回环、私有、链路本地等内网地址时拒绝，需由 security.custom_rpc_allowed_hosts 显式放行
+- 添加需要登录会话；只有添加者或管理员可以移除，且只能移除自定义网络、不能移除当前正在使用的网络
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+	"wallet/config"
+	"wallet/core"
+	"wallet/database"
+	"wallet/models"
+)
+
+// ErrCustomNetworkForbidden 非添加者或管理员移除自定义网络
+var ErrCustomNetworkForbidden = errors.New("只有添加者或管理员可以移除该网络")
+
+// customNetworkIDPattern 自定义网络ID格式（小写字母、数字、下划线与连字符）
+var customNetworkIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+// CustomNetworkInput 添加自定义网络的参数
+type CustomNetworkInput struct {
+	ID            string // 网络ID，为空时使用 chain_<chainID>
+	Name          string
+	RPCURL        string
+	FallbackRPCs  []string // 备用RPC地址（仅 http(s)），主节点故障时自动切换
+	ChainID       int64
+	Symbol        string
+	Decimals      int // 为0时使用18
+	BlockExplorer string
+	Testnet       bool
+}
+
+// AddCustomNetwork 以 owner 的身份添加自定义EVM网络并持久化，返回添加后的网络信息
+// 持久化失败时撤销注册，保证内存与数据库一致
+func (s *WalletService) AddCustomNetwork(ctx context.Context, owner string, in CustomNetworkInput) (*core.NetworkInfo, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	if owner == "" {
+		return nil, errors.New("添加自定义网络需要登录会话")
+	}
+	in.ID = strings.TrimSpace(in.ID)
+	if in.ID == "" {
+		in.ID = fmt.Sprintf("chain_%d", in.ChainID)
+	}
+	if !customNetworkIDPattern.MatchString(in.ID) {
+		return nil, fmt.Errorf("网络ID格式不正确: %s", in.ID)
+	}
+	if strings.TrimSpace(in.Name) == "" {
+		return nil, errors.New("网络名称不能为空")
+	}
+	if strings.TrimSpace(in.Symbol) == "" {
+		return nil, errors.New("原生代币符号不能为空")
+	}
+	if err := validateRPCURLs(ctx, in.RPCURL, in.FallbackRPCs); err != nil {
+		return nil, err
+	}
+	if in.Decimals == 0 {
+		in.Decimals = 18
+	}
+
+	err := s.multiChain.AddNetwork(&core.NetworkInfo{
+		ID:            in.ID,
+		Name:          in.Name,
+		ChainID:       in.ChainID,
+		Symbol:        in.Symbol,
+		Decimals:      in.Decimals,
+		BlockExplorer: in.BlockExplorer,
+		Testnet:       in.Testnet,
+		ChainType:     "evm",
+		RPCURL:        in.RPCURL,
+		RPCURLs:       append([]string{in.RPCURL}, in.FallbackRPCs...),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	record := models.CustomNetwork{
+		NetworkID:     in.ID,
+		Name:          in.Name,
+		RPCURL:        in.RPCURL,
+		FallbackRPCs:  strings.Join(in.FallbackRPCs, ","),
+		ChainID:       in.ChainID,
+		Symbol:        in.Symbol,
+		Decimals:      in.Decimals,
+		BlockExplorer: in.BlockExplorer,
+		Testnet:       in.Testnet,
+		AddedBy:       strings.ToLower(owner),
+	}
+	if err := database.DB.Create(&record).Error; err != nil {
+		_ = s.multiChain.RemoveNetwork(in.ID)
+		return nil, fmt.Errorf("保存自定义网络失败: %w", err)
+	}
+	return s.multiChain.GetNetworkInfo(in.ID)
+}
+
+// RemoveCustomNetwork 移除自定义网络并删除持久化记录，requester 须为添加者或管理员
+func (s *WalletService) RemoveCustomNetwork(requester, networkID string) error {
+	if database.DB == nil {
+		return errors.New("数据库未初始化")
+	}
+	if !s.multiChain.IsCustomNetwork(networkID) {
+		return fmt.Errorf("网络 %s 不是自定义网络，不能移除", networkID)
+	}
+	var record models.CustomNetwork
+	if err := database.DB.Where("network_id = ?", networkID).First(&record).Error; err != nil {
+		return fmt.Errorf("查询自定义网络失败: %w", err)
+	}
+	isAdmin := false
+	if securityService := s.GetSecurityService(); securityService != nil {
+		isAdmin = securityService.IsAuditAdmin(requester)
+	}
+	if requester == "" || (!isAdmin && !strings.EqualFold(record.AddedBy, requester)) {
+		return ErrCustomNetworkForbidden
+	}
+	if err := s.multiChain.RemoveNetwork(networkID); err != nil {
+		return err
+	}
+	if err := database.DB.Unscoped().Where("network_id = ?", networkID).Delete(&models.CustomNetwork{}).Error; err != nil {
+		return fmt.Errorf("删除自定义网络记录失败: %w", err)
+	}
+	return nil
+}
+
+// LoadCustomNetworks 从数据库加载自定义网络并注册到多链管理器
+// 节点不可达、链ID不一致或RPC地址不再被允许的网络跳过（保留记录，下次启动重试）
+func (s *WalletService) LoadCustomNetworks() {
+	if database.DB == nil {
+		return
+	}
+	var records []models.CustomNetwork
+	if err := database.DB.Order("created_at ASC").Find(&records).Error; err != nil {
+		slog.WarnContext(context.Background(), "custom networks load failed", "error", err)
+		return
+	}
+	for _, r := range records {
+		if err := validateRPCURLs(context.Background(), r.RPCURL, splitRPCURLs(r.FallbackRPCs)); err != nil {
+			slog.WarnContext(context.Background(), "custom network load failed", "network", r.NetworkID, "error", err)
+			continue
+		}
+		err := s.multiChain.AddNetwork(&core.NetworkInfo{
+			ID:            r.NetworkID,
+			Name:          r.Name,
+			ChainID:       r.ChainID,
+			Symbol:        r.Symbol,
+			Decimals:      r.Decimals,
+			BlockExplorer: r.BlockExplorer,
+			Testnet:       r.Testnet,
+			ChainType:     "evm",
+			RPCURL:        r.RPCURL,
+			RPCURLs:       append([]string{r.RPCURL}, splitRPCURLs(r.FallbackRPCs)...),
+		})
+		if err != nil {
+			slog.WarnContext(context.Background(), "custom network load failed", "network", r.NetworkID, "error", err)
+		}
+	}
+}
+
+// splitRPCURLs 拆分逗号分隔的RPC地址列表
+func splitRPCURLs(joined string) []string {
+	var urls []string
+	for _, raw := range strings.Split(joined, ",") {
+		if raw = strings.TrimSpace(raw); raw != "" {
+			urls = append(urls, raw)
+		}
+	}
+	return urls
+}
+
+// validateRPCURLs 校验主RPC与备用RPC地址
+func validateRPCURLs(ctx context.Context, primary string, fallbacks []string) error {
+	for _, raw := range append([]string{primary}, fallbacks...) {
+		if err := validateRPCURL(ctx, raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateRPCURL 校验 RPC 地址为 http(s) 或 ws(s) 地址，且主机不指向内网（security.custom_rpc_allowed_hosts 中的除外）
+func validateRPCURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("RPC 地址格式不正确: %s", raw)
+	}
+	switch u.Scheme {
+	case "http", "https", "ws", "wss":
+	default:
+		return fmt.Errorf("RPC 地址仅支持 http(s)/ws(s): %s", raw)
+	}
+	if err := core.CheckOutboundHost(ctx, u.Hostname(), config.AppConfig.Security.CustomRPCAllowedHosts); err != nil {
+		return fmt.Errorf("RPC 地址不可用: %w", err)
+	}
+	return nil
+}
@@ -8,9 +8,10 @@ package services
 import (
 	"context"
 	"fmt"
-	"math/big"
+	"net/url"
 	"sync"
 	"time"
+	"wallet/config"
 	"wallet/core"
 )
 
@@ -19,9 +20,13 @@ type DAppBrowserService struct {
 	dappBrowser    *core.DAppBrowser          // DApp浏览器
 	walletService  *WalletService             // 钱包服务
 	activeRequests map[string]*PendingRequest // 待处理请求
+	onResolved     RequestResolvedFunc        // 待处理请求被确认或拒绝后的回调
 	mu             sync.RWMutex               // 读写锁
 }
 
+// RequestResolvedFunc 待处理请求被确认或拒绝后的回调，request 为执行后的请求（拒绝时带 4001 错误）
+type RequestResolvedFunc func(sessionID string, request *core.Web3Request)
+
 // PendingRequest 待处理请求
 type PendingRequest struct {
 	Request          *core.Web3Request `json:"request"`    // Web3请求
@@ -46,6 +51,8 @@ type DAppConnectionResponse struct {
 	Accounts       []string  `json:"accounts"`        // 账户列表
 	Permissions    []string  `json:"permissions"`     // 授权权限
 	ExpiresAt      time.Time `json:"expires_at"`      // 过期时间
+	// Security 域名安全检查结果，action 为 warn 时前端应提示用户确认
+	Security *core.SecurityCheckResult `json:"security"`
 }
 
 // Web3RequestData Web3请求数据
@@ -54,6 +61,7 @@ type Web3RequestData struct {
 	Method    string        `json:"method" binding:"required"`
 	Params    []interface{} `json:"params"`
 	Origin    string        `json:"origin"`
+	ChainID   string        `json:"chain_id"` // 可选，请求所在链（十六进制），为空时使用会话当前链
 }
 
 // Web3ResponseData Web3响应数据
@@ -130,11 +138,26 @@ type ActivityStatistics struct {
 
 // NewDAppBrowserService 创建DApp浏览器服务
 func NewDAppBrowserService(dappBrowser *core.DAppBrowser, walletService *WalletService) *DAppBrowserService {
-	return &DAppBrowserService{
+	service := &DAppBrowserService{
 		dappBrowser:    dappBrowser,
 		walletService:  walletService,
 		activeRequests: make(map[string]*PendingRequest),
 	}
+	service.loadPermissions()
+
+	// 加载钓鱼黑名单并定时刷新
+	phishing := config.AppConfig.Phishing.WithDefaults()
+	dappBrowser.SecurityManager().StartPhishingListRefresh(context.Background(), phishing.ListURL, time.Duration(phishing.RefreshMinutes)*time.Minute)
+	return service
+}
+
+// CheckDAppSecurity 检查 DApp 地址的钓鱼风险（不建立连接）
+func (dbs *DAppBrowserService) CheckDAppSecurity(dappURL string) (*core.SecurityCheckResult, error) {
+	parsedURL, err := url.Parse(dappURL)
+	if err != nil || parsedURL.Host == "" {
+		return nil, fmt.Errorf("无效的DApp URL: %s", dappURL)
+	}
+	return dbs.dappBrowser.SecurityManager().CheckSecurity(parsedURL.Host), nil
 }
 
 // ConnectDApp 连接DApp
@@ -157,6 +180,7 @@ func (dbs *DAppBrowserService) ConnectDApp(ctx context.Context, request *DAppCon
 		Accounts:       []string{session.UserAddress},
 		Permissions:    session.Permissions,
 		ExpiresAt:      session.ExpiresAt,
+		Security:       session.Security,
 	}
 
 	return response, nil
@@ -173,6 +197,7 @@ func (dbs *DAppBrowserService) ProcessWeb3Request(ctx context.Context, requestDa
 		Timestamp:    time.Now(),
 		RequiresAuth: dbs.isMethodRequiresAuth(requestData.Method),
 		Status:       "pending",
+		ChainID:      requestData.ChainID,
 	}
 
 	// 处理请求
@@ -273,28 +298,33 @@ func (dbs *DAppBrowserService) GetUserActivity(ctx context.Context, request *Use
 	return response, nil
 }
 
-// ConfirmWeb3Request 确认Web3请求
-func (dbs *DAppBrowserService) ConfirmWeb3Request(ctx context.Context, requestID string, approved bool, signature string) error {
-	dbs.mu.Lock()
-	defer dbs.mu.Unlock()
-
+// ConfirmWeb3Request 确认或拒绝Web3请求
+// 确认发送交易或签名时使用钱包会话（walletSessionID）派生的签名者执行，返回执行后的请求
+func (dbs *DAppBrowserService) ConfirmWeb3Request(ctx context.Context, walletSessionID, derivationPath, requestID string, approved bool) (*core.Web3Request, error) {
+	dbs.mu.RLock()
 	pendingRequest, exists := dbs.activeRequests[requestID]
+	dbs.mu.RUnlock()
 	if !exists {
-		return fmt.Errorf("请求不存在或已过期")
+		return nil, fmt.Errorf("请求不存在或已过期")
 	}
 
 	// 检查过期时间
 	if time.Now().After(pendingRequest.ExpiresAt) {
-		delete(dbs.activeRequests, requestID)
-		return fmt.Errorf("请求已过期")
+		dbs.removePendingRequest(requestID)
+		return nil, fmt.Errorf("请求已过期")
 	}
 
-	// 处理用户确认
+	result := pendingRequest.Request
 	if approved {
 		// 执行实际操作
-		err := dbs.executeWeb3Request(ctx, pendingRequest, signature)
+		completed, err := dbs.executeWeb3Request(ctx, pendingRequest, walletSessionID, derivationPath)
 		if err != nil {
-			return fmt.Errorf("执行请求失败: %w", err)
+			return nil, fmt.Errorf("执行请求失败: %w", err)
+		}
+		result = completed
+	} else if pendingRequest.Request.Method != "eth_requestAccounts" {
+		if rejected, err := dbs.dappBrowser.RejectRequest(pendingRequest.SessionID, requestID); err == nil {
+			result = rejected
 		}
 	}
 
@@ -303,10 +333,27 @@ func (dbs *DAppBrowserService) ConfirmWeb3Request(ctx context.Context, requestID
 	case pendingRequest.UserConfirmation <- approved:
 	default:
 	}
+	dbs.mu.RLock()
+	onResolved := dbs.onResolved
+	dbs.mu.RUnlock()
+	if onResolved != nil {
+		onResolved(pendingRequest.SessionID, result)
+	}
 
 	// 清理请求
-	delete(dbs.activeRequests, requestID)
-	return nil
+	dbs.removePendingRequest(requestID)
+	return result, nil
+}
+
+// PendingTransaction 待确认的 eth_sendTransaction 请求的网络与资金流向，供确认前校验；其他方法返回 nil
+func (dbs *DAppBrowserService) PendingTransaction(requestID string) (*core.PendingTransfer, error) {
+	dbs.mu.RLock()
+	pendingRequest, exists := dbs.activeRequests[requestID]
+	dbs.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("请求不存在或已过期")
+	}
+	return dbs.dappBrowser.SendTransactionDetails(pendingRequest.SessionID, pendingRequest.Request)
 }
 
 // GetPendingRequests 获取待处理请求
@@ -323,6 +370,72 @@ func (dbs *DAppBrowserService) GetPendingRequests(userAddress string) []*Pending
 	return pendingRequests
 }
 
+// PendingRequest 查询待处理请求
+func (dbs *DAppBrowserService) PendingRequest(requestID string) (*PendingRequest, bool) {
+	dbs.mu.RLock()
+	defer dbs.mu.RUnlock()
+	request, ok := dbs.activeRequests[requestID]
+	return request, ok
+}
+
+// DiscardRequest 丢弃待处理请求（请求方已不再等待结果，如 WalletConnect 请求过期或会话断开）
+func (dbs *DAppBrowserService) DiscardRequest(requestID string) {
+	pendingRequest, ok := dbs.PendingRequest(requestID)
+	if !ok {
+		return
+	}
+	_, _ = dbs.dappBrowser.RejectRequest(pendingRequest.SessionID, requestID)
+	dbs.removePendingRequest(requestID)
+}
+
+// SetRequestResolvedHook 设置待处理请求被确认或拒绝后的回调
+func (dbs *DAppBrowserService) SetRequestResolvedHook(fn RequestResolvedFunc) {
+	dbs.mu.Lock()
+	defer dbs.mu.Unlock()
+	dbs.onResolved = fn
+}
+
+// OpenExternalSession 为外部连接（如 WalletConnect）创建 DApp 会话，并授权会话批准的方法直到会话过期
+// 已有的授权覆盖全部方法时不重复授权，避免缩短用户原有授权的有效期
+func (dbs *DAppBrowserService) OpenExternalSession(ctx context.Context, dappURL, userAddress, chainID string, methods []string, expiresAt time.Time) (*core.DAppSession, error) {
+	session, err := dbs.dappBrowser.ConnectExternalDApp(ctx, dappURL, userAddress, chainID, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	manager := dbs.dappBrowser.PermissionManager()
+	var missing []string
+	for _, method := range methods {
+		if granted, err := manager.CheckPermission(dappURL, userAddress, method); err != nil || !granted {
+			missing = append(missing, method)
+		}
+	}
+	if len(missing) == 0 {
+		return session, nil
+	}
+	permission, err := manager.GrantPermission(dappURL, userAddress, methods, time.Until(expiresAt))
+	if err == nil {
+		err = dbs.savePermission(permission)
+	}
+	if err != nil {
+		dbs.CloseSession(session.ID)
+		return nil, fmt.Errorf("授权DApp失败: %w", err)
+	}
+	return session, nil
+}
+
+// CloseSession 关闭 DApp 会话并丢弃其待处理请求
+func (dbs *DAppBrowserService) CloseSession(sessionID string) {
+	dbs.dappBrowser.CloseSession(sessionID)
+	dbs.mu.Lock()
+	defer dbs.mu.Unlock()
+	for id, request := range dbs.activeRequests {
+		if request.SessionID == sessionID {
+			delete(dbs.activeRequests, id)
+		}
+	}
+}
+
 // 私有方法
 
 // isMethodRequiresAuth 判断方法是否需要授权
@@ -355,77 +468,50 @@ func (dbs *DAppBrowserService) addPendingRequest(sessionID string, request *core
 	dbs.activeRequests[request.ID] = pendingRequest
 }
 
+// removePendingRequest 移除待处理请求
+func (dbs *DAppBrowserService) removePendingRequest(requestID string) {
+	dbs.mu.Lock()
+	defer dbs.mu.Unlock()
+	delete(dbs.activeRequests, requestID)
+}
+
 // executeWeb3Request 执行Web3请求
-func (dbs *DAppBrowserService) executeWeb3Request(ctx context.Context, pendingRequest *PendingRequest, signature string) error {
+func (dbs *DAppBrowserService) executeWeb3Request(ctx context.Context, pendingRequest *PendingRequest, walletSessionID, derivationPath string) (*core.Web3Request, error) {
 	request := pendingRequest.Request
 
 	switch request.Method {
-	case "eth_sendTransaction":
-		return dbs.executeSendTransaction(ctx, request, signature)
-	case "eth_signTypedData_v4":
-		return dbs.executeSignTypedData(ctx, request, signature)
-	case "personal_sign":
-		return dbs.executePersonalSign(ctx, request, signature)
+	case "eth_requestAccounts":
+		if err := dbs.executeRequestAccounts(pendingRequest); err != nil {
+			return nil, err
+		}
+		return request, nil
+	case "eth_sendTransaction", "eth_signTypedData_v4", "personal_sign":
+		signer, err := dbs.walletService.SessionSigner(walletSessionID, derivationPath)
+		if err != nil {
+			return nil, fmt.Errorf("无效会话: %w", err)
+		}
+		return dbs.dappBrowser.CompleteApprovedRequest(ctx, pendingRequest.SessionID, request.ID, signer)
 	default:
-		return fmt.Errorf("不支持的方法: %s", request.Method)
+		return nil, fmt.Errorf("不支持的方法: %s", request.Method)
 	}
 }
 
-// executeSendTransaction 执行发送交易
-func (dbs *DAppBrowserService) executeSendTransaction(ctx context.Context, request *core.Web3Request, signature string) error {
-	if len(request.Params) == 0 {
-		return fmt.Errorf("缺少交易参数")
+// executeRequestAccounts 用户同意连接：授予 DApp 账户读取权限（不含交易与签名权限）
+func (dbs *DAppBrowserService) executeRequestAccounts(pendingRequest *PendingRequest) error {
+	session, err := dbs.dappBrowser.GetSession(pendingRequest.SessionID)
+	if err != nil {
+		return err
 	}
-
-	// 解析交易参数
-	txParam, ok := request.Params[0].(map[string]interface{})
-	if !ok {
-		return fmt.Errorf("无效的交易参数")
+	permission, err := dbs.dappBrowser.PermissionManager().GrantPermission(session.DAppURL, session.UserAddress, []string{"eth_accounts"}, 0)
+	if err != nil {
+		return err
 	}
-
-	// 构建交易
-	to, _ := txParam["to"].(string)
-	value, _ := txParam["value"].(string)
-	data, _ := txParam["data"].(string)
-
-	// 解析金额
-	amount := big.NewInt(0)
-	if value != "" {
-		amount, _ = new(big.Int).SetString(value, 0)
+	if err := dbs.savePermission(permission); err != nil {
+		return err
 	}
 
-	// 简化实现：生成模拟交易哈希
-	txHash := fmt.Sprintf("0x%x", time.Now().UnixNano())
-	request.Response = txHash
-	request.Status = "completed"
-
-	_ = to
-	_ = amount
-	_ = data
-	_ = signature
-
-	return nil
-}
-
-// executeSignTypedData 执行类型化数据签名
-func (dbs *DAppBrowserService) executeSignTypedData(ctx context.Context, request *core.Web3Request, signature string) error {
-	// 简化实现：返回模拟签名
-	mockSignature := "0x" + fmt.Sprintf("%064x", time.Now().UnixNano()) +
-		fmt.Sprintf("%064x", time.Now().UnixNano()) + "1b"
-
-	request.Response = mockSignature
-	request.Status = "completed"
-	return nil
-}
-
-// executePersonalSign 执行个人签名
-func (dbs *DAppBrowserService) executePersonalSign(ctx context.Context, request *core.Web3Request, signature string) error {
-	// 简化实现：返回模拟签名
-	mockSignature := "0x" + fmt.Sprintf("%064x", time.Now().UnixNano()) +
-		fmt.Sprintf("%064x", time.Now().UnixNano()) + "1c"
-
-	request.Response = mockSignature
-	request.Status = "completed"
+	pendingRequest.Request.Response = []string{session.UserAddress}
+	pendingRequest.Request.Status = "completed"
 	return nil
 }
 
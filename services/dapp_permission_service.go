@@ -0,0 +1,120 @@
+/*
+DApp 授权持久化
+
+DAppBrowserService 的授权管理：授权在内存 PermissionManager 中生效，
+同时写入 dapp_permissions 表，服务启动时恢复未撤销的授权。
+数据库未初始化时仅保存在内存中。
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"wallet/core"
+	"wallet/database"
+	"wallet/models"
+
+	"gorm.io/gorm"
+)
+
+// DAppPermissionRequest DApp授权请求
+type DAppPermissionRequest struct {
+	DAppURL    string   `json:"dapp_url" binding:"required"` // DApp URL
+	Methods    []string `json:"methods" binding:"required"`  // 授权方法，如 eth_accounts、eth_sendTransaction
+	TTLSeconds int64    `json:"ttl_seconds"`                 // 有效期（秒），0 表示长期有效
+}
+
+// GrantPermission 授权 DApp 调用指定方法
+func (dbs *DAppBrowserService) GrantPermission(userAddress string, request *DAppPermissionRequest) (*core.DAppPermission, error) {
+	if request.TTLSeconds < 0 {
+		return nil, fmt.Errorf("无效的授权有效期")
+	}
+	permission, err := dbs.dappBrowser.PermissionManager().GrantPermission(
+		request.DAppURL, userAddress, request.Methods, time.Duration(request.TTLSeconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if err := dbs.savePermission(permission); err != nil {
+		return nil, err
+	}
+	return permission, nil
+}
+
+// RevokePermission 撤销用户的 DApp 授权
+func (dbs *DAppBrowserService) RevokePermission(userAddress, permissionID string) error {
+	permission, err := dbs.dappBrowser.PermissionManager().RevokePermission(permissionID, userAddress)
+	if err != nil {
+		return err
+	}
+	return dbs.savePermission(permission)
+}
+
+// ListPermissions 列出用户当前有效的 DApp 授权
+func (dbs *DAppBrowserService) ListPermissions(userAddress string) []*core.DAppPermission {
+	return dbs.dappBrowser.PermissionManager().ListPermissions(userAddress)
+}
+
+// SessionAddress 获取钱包会话对应的用户地址
+func (dbs *DAppBrowserService) SessionAddress(sessionID string) (string, error) {
+	return dbs.walletService.GetSessionAddress(sessionID)
+}
+
+// savePermission 按授权ID写入或更新授权记录
+func (dbs *DAppBrowserService) savePermission(permission *core.DAppPermission) error {
+	if database.DB == nil {
+		return nil
+	}
+
+	var record models.DAppPermission
+	err := database.DB.Where("permission_id = ?", permission.ID).First(&record).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("查询授权记录失败: %w", err)
+	}
+	record.PermissionID = permission.ID
+	record.OwnerAddress = strings.ToLower(permission.UserAddress)
+	record.DAppOrigin = permission.DAppURL
+	record.Methods = strings.Join(permission.Methods(), ",")
+	record.GrantedAt = permission.GrantedAt
+	record.ExpiresAt = permission.ExpiresAt
+	record.RevokedAt = permission.RevokedAt
+	if err := database.DB.Save(&record).Error; err != nil {
+		return fmt.Errorf("保存授权记录失败: %w", err)
+	}
+	return nil
+}
+
+// loadPermissions 从数据库恢复未撤销且未过期的授权
+func (dbs *DAppBrowserService) loadPermissions() {
+	if database.DB == nil {
+		return
+	}
+
+	var records []models.DAppPermission
+	err := database.DB.Where("revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", time.Now()).
+		Find(&records).Error
+	if err != nil {
+		slog.WarnContext(context.Background(), "dapp permissions load failed", "error", err)
+		return
+	}
+
+	manager := dbs.dappBrowser.PermissionManager()
+	for _, record := range records {
+		permission := &core.DAppPermission{
+			ID:          record.PermissionID,
+			DAppURL:     record.DAppOrigin,
+			UserAddress: record.OwnerAddress,
+			GrantedAt:   record.GrantedAt,
+			ExpiresAt:   record.ExpiresAt,
+		}
+		for _, method := range strings.Split(record.Methods, ",") {
+			if method != "" {
+				permission.Permissions = append(permission.Permissions, core.Permission{Type: method, Resource: record.DAppOrigin})
+			}
+		}
+		manager.RestorePermission(permission)
+	}
+}
@@ -34,6 +34,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"math/big"
 	"sort"
 	"strconv"
@@ -41,8 +42,13 @@ import (
 	"sync"
 	"time"
 	"wallet/core"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
+// swapReceiptTimeout 广播兑换交易后同步等待回执的最长时间
+const swapReceiptTimeout = 2 * time.Minute
+
 // DeFiService DeFi业务服务
 // 提供完整的DeFi功能封装，包括交易、收益、流动性等服务
 type DeFiService struct {
@@ -52,18 +58,23 @@ type DeFiService struct {
 	userPositions  map[string][]*UserPosition  // 用户仓位映射
 	priceCache     map[string]*PriceCache      // 价格缓存
 	oneInchService *OneInchService             // 1inch聚合器服务
+	walletService  *WalletService              // 钱包服务（解析会话签名者）
+	priceService   *PriceService               // 代币美元价格服务
 	mu             sync.RWMutex                // 读写锁
 }
 
 // SwapRequest 交易请求参数
 type SwapRequest struct {
-	TokenIn     string `json:"token_in" binding:"required"`     // 输入代币地址
-	TokenOut    string `json:"token_out" binding:"required"`    // 输出代币地址
-	AmountIn    string `json:"amount_in" binding:"required"`    // 输入数量
-	Slippage    string `json:"slippage"`                        // 滑点容忍度（百分比）
-	UserAddress string `json:"user_address" binding:"required"` // 用户地址
-	Deadline    int64  `json:"deadline"`                        // 交易截止时间
-	GasPrice    string `json:"gas_price"`                       // Gas价格
+	TokenIn          string `json:"token_in" binding:"required"`     // 输入代币地址
+	TokenOut         string `json:"token_out" binding:"required"`    // 输出代币地址
+	AmountIn         string `json:"amount_in" binding:"required"`    // 输入数量
+	Slippage         string `json:"slippage"`                        // 滑点容忍度（百分比）
+	UserAddress      string `json:"user_address" binding:"required"` // 用户地址
+	Deadline         int64  `json:"deadline"`                        // 交易截止时间
+	GasPrice         string `json:"gas_price"`                       // Gas价格
+	DerivationPath   string `json:"derivation_path"`                 // 签名账户派生路径（可选）
+	InfiniteApproval bool   `json:"infinite_approval"`               // 授权不足时是否无限授权（默认仅授权所需数量）
+	AllowHighFee     bool   `json:"allow_high_fee"`                  // 已确认高手续费，跳过手续费上限检查
 }
 
 // SwapQuote 交易报价
@@ -167,7 +178,8 @@ type TokenInfo struct {
 	Name      string `json:"name"`       // 代币名称
 	Decimals  int    `json:"decimals"`   // 小数位数
 	LogoURL   string `json:"logo_url"`   // 图标URL
-	Price     string `json:"price"`      // 当前价格
+	Price     string `json:"price"`      // 当前美元价格（无价格数据时为 "0"）
+	NoPrice   bool   `json:"no_price"`   // 是否缺少价格数据
 	Change24h string `json:"change_24h"` // 24小时涨跌幅
 }
 
@@ -210,9 +222,31 @@ func NewDeFiService(multiChain *core.MultiChainManager) *DeFiService {
 	// 初始化默认策略
 	service.initDefaultStrategies()
 
+	// 注册当前网络的 Uniswap V2 Router
+	service.registerUniswapV2()
+
 	return service
 }
 
+// registerUniswapV2 当前网络有 Uniswap V2 部署时注册为 "uniswap_v2"
+func (s *DeFiService) registerUniswapV2() {
+	network := s.multiChain.GetCurrentNetwork()
+	adapter, err := s.multiChain.GetCurrentAdapter()
+	if err != nil {
+		return
+	}
+	evmAdapter, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		return
+	}
+	exchange, err := core.NewUniswapV2Exchange(evmAdapter, network)
+	if err != nil {
+		slog.DebugContext(context.Background(), "uniswap v2 not registered", "network", network, "error", err)
+		return
+	}
+	s.exchanges["uniswap_v2"] = exchange
+}
+
 // SetOneInchAPIKey 设置1inch API密钥
 func (s *DeFiService) SetOneInchAPIKey(apiKey string) {
 	s.mu.Lock()
@@ -379,7 +413,7 @@ func (s *DeFiService) ExecuteSwap(req *SwapRequest, sessionID string) (*core.Swa
 
 	// 如果是1inch交易，使用1inch执行
 	if quote.Exchange == "1inch" && s.oneInchService != nil && s.oneInchService.apiKey != "" {
-		return s.executeOneInchSwap(req, quote)
+		return s.executeOneInchSwap(req, quote, sessionID)
 	}
 
 	// 获取对应的交易所
@@ -395,14 +429,37 @@ func (s *DeFiService) ExecuteSwap(req *SwapRequest, sessionID string) (*core.Swa
 	amountOutMin := new(big.Int)
 	amountOutMin.SetString(quote.AmountOutMin, 10)
 
+	// 指定了滑点时按预期输出重新计算最小输出
+	if slippage := parseFloatFromString(req.Slippage); slippage > 0 {
+		amountOut, ok := new(big.Int).SetString(quote.AmountOut, 10)
+		if ok {
+			keep := big.NewInt(int64(10000 - slippage*100))
+			amountOutMin = amountOut.Mul(amountOut, keep).Div(amountOut, big.NewInt(10000))
+		}
+	}
+
+	signer, err := s.sessionSigner(sessionID, req.DerivationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var gasPrice *big.Int
+	if req.GasPrice != "" {
+		gasPrice, _ = new(big.Int).SetString(req.GasPrice, 10)
+	}
+
 	swapParams := &core.SwapParams{
-		TokenIn:      req.TokenIn,
-		TokenOut:     req.TokenOut,
-		AmountIn:     amountIn,
-		AmountOutMin: amountOutMin,
-		Deadline:     req.Deadline,
-		Recipient:    req.UserAddress,
-		Slippage:     req.Slippage,
+		TokenIn:          req.TokenIn,
+		TokenOut:         req.TokenOut,
+		AmountIn:         amountIn,
+		AmountOutMin:     amountOutMin,
+		Deadline:         req.Deadline,
+		Recipient:        req.UserAddress,
+		Slippage:         req.Slippage,
+		GasPrice:         gasPrice,
+		Signer:           signer,
+		InfiniteApproval: req.InfiniteApproval,
+		AllowHighFee:     req.AllowHighFee,
 	}
 
 	// 执行交易
@@ -418,8 +475,111 @@ func (s *DeFiService) ExecuteSwap(req *SwapRequest, sessionID string) (*core.Swa
 	return result, nil
 }
 
+// awaitSwapReceipt 在 swapReceiptTimeout 内等待交易回执，更新最终状态与实际Gas；超时保持 pending
+func (s *DeFiService) awaitSwapReceipt(ctx context.Context, evmAdapter *core.EVMAdapter, result *core.SwapResult) {
+	waitCtx, cancel := context.WithTimeout(ctx, swapReceiptTimeout)
+	defer cancel()
+	receipt, err := evmAdapter.WaitForReceipt(waitCtx, result.TxHash, 0)
+	if err != nil {
+		slog.DebugContext(ctx, "swap receipt wait failed", "tx_hash", result.TxHash, "error", err)
+		return
+	}
+	result.GasUsed = receipt.GasUsed
+	if receipt.EffectiveGasPrice != nil {
+		result.GasPrice = receipt.EffectiveGasPrice
+	}
+	if receipt.Status == 1 {
+		result.Status = "success"
+	} else {
+		result.Status = "failed"
+	}
+}
+
+// validateOneInchRouter 校验地址为已知的1inch路由合约
+func validateOneInchRouter(address string) error {
+	for _, router := range oneInchRouters {
+		if strings.EqualFold(address, router) {
+			return nil
+		}
+	}
+	return fmt.Errorf("unexpected 1inch router address: %s", address)
+}
+
+// currentEVMAdapter 获取当前网络的EVM适配器
+func (s *DeFiService) currentEVMAdapter() (*core.EVMAdapter, error) {
+	adapter, err := s.multiChain.GetCurrentAdapter()
+	if err != nil {
+		return nil, err
+	}
+	evmAdapter, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		return nil, fmt.Errorf("当前网络不支持DeFi交易")
+	}
+	return evmAdapter, nil
+}
+
+// SwapSpendToken Swap 支出的代币，原生币返回空字符串（与支出限额的 token 参数一致）
+func SwapSpendToken(tokenIn string) string {
+	if isOneInchNative(tokenIn) {
+		return ""
+	}
+	return tokenIn
+}
+
+// isOneInchNative 1inch 使用 0xEeee...EEeE 表示原生币
+func isOneInchNative(token string) bool {
+	return strings.EqualFold(token, "0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE") ||
+		strings.EqualFold(token, "0x0000000000000000000000000000000000000000")
+}
+
+// sessionSigner 由钱包会话构建交易签名者
+func (s *DeFiService) sessionSigner(sessionID, derivationPath string) (core.Signer, error) {
+	if sessionID == "" {
+		return nil, fmt.Errorf("缺少会话ID")
+	}
+	if s.walletService == nil {
+		return nil, fmt.Errorf("钱包服务未初始化")
+	}
+	signer, err := s.walletService.SessionSigner(sessionID, derivationPath)
+	if err != nil {
+		return nil, fmt.Errorf("无效会话: %w", err)
+	}
+	return signer, nil
+}
+
 // executeOneInchSwap 执行1inch交换
-func (s *DeFiService) executeOneInchSwap(req *SwapRequest, quote *SwapQuote) (*core.SwapResult, error) {
+// 提供会话时先确保1inch路由的授权额度，再签名广播交换交易并等待回执；未提供会话时仅返回交易数据
+// 授权对象与交易目标必须是已知的1inch路由合约，防止被API响应重定向到恶意合约
+func (s *DeFiService) executeOneInchSwap(req *SwapRequest, quote *SwapQuote, sessionID string) (*core.SwapResult, error) {
+	ctx := context.Background()
+
+	var signer core.Signer
+	var evmAdapter *core.EVMAdapter
+	var approval *core.ApprovalResult
+	if sessionID != "" {
+		var err error
+		if signer, err = s.sessionSigner(sessionID, req.DerivationPath); err != nil {
+			return nil, err
+		}
+		if evmAdapter, err = s.currentEVMAdapter(); err != nil {
+			return nil, err
+		}
+		if !isOneInchNative(req.TokenIn) {
+			spender, err := s.oneInchService.GetSpender(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get 1inch spender: %w", err)
+			}
+			if err := validateOneInchRouter(spender); err != nil {
+				return nil, err
+			}
+			amountIn, _ := new(big.Int).SetString(req.AmountIn, 10)
+			approval, err = evmAdapter.EnsureAllowance(ctx, req.TokenIn, req.UserAddress, spender, amountIn, signer, req.InfiniteApproval)
+			if err != nil {
+				return nil, fmt.Errorf("failed to ensure allowance: %w", err)
+			}
+		}
+	}
+
 	// 构建1inch交换请求
 	slippage := "1" // 默认1%滑点
 	if req.Slippage != "" {
@@ -435,11 +595,17 @@ func (s *DeFiService) executeOneInchSwap(req *SwapRequest, quote *SwapQuote) (*c
 		GasPrice:         req.GasPrice,
 	}
 
-	// 获取交换数据
-	swapResp, err := s.oneInchService.GetSwap(context.Background(), oneInchReq)
+	// 获取交换数据（授权完成后获取，避免1inch因额度不足拒绝估算）
+	swapResp, err := s.oneInchService.GetSwap(ctx, oneInchReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get 1inch swap data: %w", err)
 	}
+	if swapResp.Tx == nil {
+		return nil, fmt.Errorf("1inch swap response missing tx")
+	}
+	if err := validateOneInchRouter(swapResp.Tx.To); err != nil {
+		return nil, err
+	}
 
 	// 构建交换结果
 	amountOut := new(big.Int)
@@ -448,13 +614,40 @@ func (s *DeFiService) executeOneInchSwap(req *SwapRequest, quote *SwapQuote) (*c
 	gasPrice := new(big.Int)
 	gasPrice.SetString(swapResp.Tx.GasPrice, 10)
 
+	amountIn, _ := new(big.Int).SetString(req.AmountIn, 10)
 	result := &core.SwapResult{
-		TxHash:    "", // 交易哈希将在实际发送后获得
+		AmountIn:  amountIn,
 		AmountOut: amountOut,
-		GasUsed:   uint64(swapResp.Tx.Gas),
+		GasUsed:   uint64(swapResp.Tx.Gas), // 未广播时为1inch估算值
 		GasPrice:  gasPrice,
-		Status:    "success",
+		Status:    "unsigned",
+		Timestamp: time.Now().Unix(),
 		Exchange:  "1inch",
+		Approval:  approval,
+	}
+
+	if signer != nil {
+		if swapResp.Tx.From != "" && !strings.EqualFold(swapResp.Tx.From, signer.Address().Hex()) {
+			return nil, fmt.Errorf("1inch tx sender %s does not match signer %s", swapResp.Tx.From, signer.Address().Hex())
+		}
+		value, ok := new(big.Int).SetString(swapResp.Tx.Value, 10)
+		if !ok {
+			value = big.NewInt(0)
+		}
+		var gasLimit, txGasPrice *big.Int
+		if swapResp.Tx.Gas > 0 {
+			gasLimit = big.NewInt(swapResp.Tx.Gas)
+		}
+		if gasPrice.Sign() > 0 {
+			txGasPrice = gasPrice
+		}
+		txHash, err := evmAdapter.SendContractTransactionWithSigner(ctx, signer, common.HexToAddress(swapResp.Tx.To), common.FromHex(swapResp.Tx.Data), value, gasLimit, txGasPrice, &core.TxOptions{AllowHighFee: req.AllowHighFee})
+		if err != nil {
+			return nil, fmt.Errorf("failed to send 1inch swap: %w", err)
+		}
+		result.TxHash = txHash
+		result.Status = "pending"
+		s.awaitSwapReceipt(ctx, evmAdapter, result)
 	}
 
 	// 记录交易历史
@@ -578,6 +771,8 @@ func (s *DeFiService) GetLiquidityPools(exchange string, sortBy string) ([]*Liqu
 		}
 	}
 
+	s.fillPoolTokenPrices(pools)
+
 	// 根据指定字段排序
 	switch strings.ToLower(sortBy) {
 	case "tvl":
@@ -602,6 +797,61 @@ func (s *DeFiService) GetLiquidityPools(exchange string, sortBy string) ([]*Liqu
 	return pools, nil
 }
 
+// GetTokenPrices 批量查询代币美元价格，chainID 为 0 时使用当前网络
+func (s *DeFiService) GetTokenPrices(ctx context.Context, chainID int, tokens []string) (map[string]*TokenPrice, error) {
+	if s.priceService == nil {
+		return nil, fmt.Errorf("价格服务未初始化")
+	}
+	if chainID == 0 {
+		chainID = ChainIDForNetwork(s.multiChain.GetCurrentNetwork())
+	}
+	return s.priceService.GetTokenPricesUSD(ctx, chainID, tokens)
+}
+
+// fillPoolTokenPrices 为流动性池中的代币与奖励代币填充当前网络的美元价格
+func (s *DeFiService) fillPoolTokenPrices(pools []*LiquidityPoolInfo) {
+	if s.priceService == nil || len(pools) == 0 {
+		return
+	}
+	var tokens []*TokenInfo
+	for _, pool := range pools {
+		tokens = append(tokens, pool.TokenA, pool.TokenB)
+		for _, reward := range pool.Rewards {
+			tokens = append(tokens, reward.Token)
+		}
+	}
+
+	var addresses []string
+	for _, token := range tokens {
+		if token != nil && common.IsHexAddress(token.Address) {
+			addresses = append(addresses, token.Address)
+		}
+	}
+	if len(addresses) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	prices, err := s.GetTokenPrices(ctx, 0, addresses)
+	if err != nil {
+		slog.WarnContext(ctx, "liquidity pool token prices failed", "error", err)
+		return
+	}
+	for _, token := range tokens {
+		if token == nil {
+			continue
+		}
+		price, ok := prices[normalizePriceToken(token.Address)]
+		if !ok || price.NoPrice {
+			token.Price = "0"
+			token.NoPrice = true
+			continue
+		}
+		token.Price = strconv.FormatFloat(price.PriceUSD, 'f', -1, 64)
+	}
+}
+
 // 辅助函数
 func parseFloatFromString(s string) float64 {
 	if s == "" {
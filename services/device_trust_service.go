@@ -0,0 +1,236 @@
+/*
+登录设备管理
+
+登录时按设备指纹（见 core.DeviceInfo）登记设备，首次使用即信任（TOFU）：
+- 用户还没有任何已信任设备时，首次登录的设备直接信任
+- 之后的新设备登记为待确认（pending），需通过双因素认证登录，或在已信任设备上确认后才能登录
+- 每次登录更新设备的最近使用时间、IP与地区
+- 撤销设备时删除记录并使该设备上的会话失效
+*/
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"wallet/core"
+	"wallet/database"
+	"wallet/models"
+
+	"gorm.io/gorm"
+)
+
+// 设备管理错误
+var (
+	ErrDeviceConfirmationRequired = errors.New("未识别的设备，请通过双因素认证登录或在已信任设备上确认")
+	ErrDeviceNotFound             = errors.New("设备不存在")
+	ErrDeviceNotTrusted           = errors.New("只能在已信任的设备上确认新设备")
+)
+
+// LoginDevice 登录请求中的设备信息
+type LoginDevice struct {
+	Info      core.DeviceInfo
+	IPAddress string
+	Country   string
+}
+
+func toTrustedDevice(m *models.TrustedDevice) *core.TrustedDevice {
+	return &core.TrustedDevice{
+		ID:          strconv.FormatUint(uint64(m.ID), 10),
+		Name:        m.Name,
+		DeviceType:  m.DeviceType,
+		Fingerprint: m.Fingerprint,
+		UserAgent:   m.UserAgent,
+		IPAddress:   m.IPAddress,
+		Location:    m.Location,
+		IsActive:    m.TrustLevel == core.DeviceTrustTrusted,
+		FirstSeen:   m.FirstSeen,
+		LastSeen:    m.LastSeen,
+		TrustLevel:  m.TrustLevel,
+	}
+}
+
+// RegisterLoginDevice 登记登录设备，mfaVerified 表示本次登录已通过双因素认证
+// 设备需确认时返回登记的待确认设备与 ErrDeviceConfirmationRequired；数据库未初始化时不做限制
+// 请求中没有任何设备信息时不登记设备，已有信任设备的用户需通过双因素认证
+func (ss *SecurityService) RegisterLoginDevice(owner string, device LoginDevice, mfaVerified bool) (*core.TrustedDevice, error) {
+	if database.DB == nil {
+		return nil, nil
+	}
+	owner = strings.ToLower(owner)
+	fingerprint := device.Info.Fingerprint()
+	if fingerprint == "" {
+		if mfaVerified {
+			return nil, nil
+		}
+		trusted, err := ss.trustedDeviceCount(owner)
+		if err != nil {
+			return nil, err
+		}
+		if trusted > 0 {
+			return nil, ErrDeviceConfirmationRequired
+		}
+		return nil, nil
+	}
+	now := time.Now()
+
+	var record models.TrustedDevice
+	err := database.DB.Where("owner_address = ? AND fingerprint = ?", owner, fingerprint).First(&record).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		trusted, err := ss.trustedDeviceCount(owner)
+		if err != nil {
+			return nil, err
+		}
+		record = models.TrustedDevice{
+			OwnerAddress: owner,
+			Fingerprint:  fingerprint,
+			Name:         deviceName(device.Info),
+			DeviceType:   deviceType(device.Info),
+			UserAgent:    truncate(device.Info.UserAgent, 512),
+			TrustLevel:   core.DeviceTrustPending,
+			FirstSeen:    now,
+		}
+		if trusted == 0 || mfaVerified {
+			record.TrustLevel = core.DeviceTrustTrusted
+		}
+	case err != nil:
+		return nil, fmt.Errorf("查询设备失败: %w", err)
+	case record.TrustLevel != core.DeviceTrustTrusted && mfaVerified:
+		record.TrustLevel = core.DeviceTrustTrusted
+	}
+
+	record.IPAddress = device.IPAddress
+	record.Location = strings.ToUpper(device.Country)
+	if record.TrustLevel == core.DeviceTrustTrusted {
+		record.LastSeen = now
+	}
+	if err := database.DB.Save(&record).Error; err != nil {
+		return nil, fmt.Errorf("保存设备失败: %w", err)
+	}
+	if record.TrustLevel != core.DeviceTrustTrusted {
+		return toTrustedDevice(&record), ErrDeviceConfirmationRequired
+	}
+	return toTrustedDevice(&record), nil
+}
+
+// ListDevices 按最近使用时间倒序返回用户的设备
+func (ss *SecurityService) ListDevices(owner string) ([]*core.TrustedDevice, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	var records []models.TrustedDevice
+	if err := database.DB.Where("owner_address = ?", strings.ToLower(owner)).
+		Order("last_seen DESC, id DESC").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("查询设备失败: %w", err)
+	}
+	devices := make([]*core.TrustedDevice, 0, len(records))
+	for i := range records {
+		devices = append(devices, toTrustedDevice(&records[i]))
+	}
+	return devices, nil
+}
+
+// TrustDevice 在已信任设备的会话中确认待确认设备
+func (ss *SecurityService) TrustDevice(owner, sessionID string, id uint) (*core.TrustedDevice, error) {
+	record, err := ss.findDevice(owner, id)
+	if err != nil {
+		return nil, err
+	}
+	var current models.TrustedDevice
+	err = database.DB.Where("owner_address = ? AND fingerprint = ? AND trust_level = ?",
+		strings.ToLower(owner), ss.walletService.SessionDevice(sessionID), core.DeviceTrustTrusted).First(&current).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDeviceNotTrusted
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询设备失败: %w", err)
+	}
+
+	if record.TrustLevel != core.DeviceTrustTrusted {
+		record.TrustLevel = core.DeviceTrustTrusted
+		if err := database.DB.Save(record).Error; err != nil {
+			return nil, fmt.Errorf("保存设备失败: %w", err)
+		}
+	}
+	return toTrustedDevice(record), nil
+}
+
+// RevokeDevice 删除设备并使其上的会话失效，返回失效的会话数
+// 被撤销的设备再次登录时按新设备处理
+func (ss *SecurityService) RevokeDevice(owner string, id uint) (int, error) {
+	record, err := ss.findDevice(owner, id)
+	if err != nil {
+		return 0, err
+	}
+	if err := database.DB.Unscoped().Delete(record).Error; err != nil {
+		return 0, fmt.Errorf("删除设备失败: %w", err)
+	}
+	return ss.walletService.ClearDeviceSessions(owner, record.Fingerprint), nil
+}
+
+// trustedDeviceCount 用户已信任的设备数
+func (ss *SecurityService) trustedDeviceCount(owner string) (int64, error) {
+	var count int64
+	if err := database.DB.Model(&models.TrustedDevice{}).
+		Where("owner_address = ? AND trust_level = ?", strings.ToLower(owner), core.DeviceTrustTrusted).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("查询设备失败: %w", err)
+	}
+	return count, nil
+}
+
+// findDevice 查找属于用户的设备
+func (ss *SecurityService) findDevice(owner string, id uint) (*models.TrustedDevice, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	var record models.TrustedDevice
+	err := database.DB.Where("id = ? AND owner_address = ?", id, strings.ToLower(owner)).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDeviceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询设备失败: %w", err)
+	}
+	return &record, nil
+}
+
+// deviceType 按客户端提示与 User-Agent 推断设备类型
+func deviceType(info core.DeviceInfo) string {
+	hint := strings.ToLower(strings.Trim(info.Platform, `" `) + " " + info.UserAgent)
+	switch {
+	case strings.Contains(hint, "iphone") || strings.Contains(hint, "ipad") || strings.Contains(hint, "ios"):
+		return "ios"
+	case strings.Contains(hint, "android"):
+		return "android"
+	case strings.Contains(hint, "windows"):
+		return "windows"
+	case strings.Contains(hint, "mac"):
+		return "macos"
+	case strings.Contains(hint, "linux"):
+		return "linux"
+	}
+	return "other"
+}
+
+// deviceName 设备显示名称：优先取设备型号，其次为设备类型
+func deviceName(info core.DeviceInfo) string {
+	if model := strings.Trim(info.Model, `" `); model != "" {
+		return truncate(model, 100)
+	}
+	return deviceType(info)
+}
+
+// truncate 按字节截断字符串，不截断多字节字符
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
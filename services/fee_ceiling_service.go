@@ -0,0 +1,127 @@
+/*
+交易手续费上限
+
+按钱包地址设置单笔交易的最高手续费（美元），未设置时使用 fee_ceiling.default_max_fee_usd：
+- 发送时按网络原生币的美元价格折算为 wei，由 core 在签名前与 gasLimit × maxFeePerGas 比较（见 core.FeeTooHighError）
+- 无法获取原生币价格（价格服务不可用、自定义网络）时不做检查，避免价格源故障导致所有交易无法发送
+- 单笔交易可通过 TxOptions.MaxFeeWei 指定上限，或 AllowHighFee 确认后跳过
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+	"wallet/config"
+	"wallet/core"
+	"wallet/database"
+	"wallet/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+)
+
+// ErrFeeCeilingNotFound 钱包未设置手续费上限
+var ErrFeeCeilingNotFound = errors.New("未设置手续费上限")
+
+// FeeCeilingStatus 钱包的手续费上限
+type FeeCeilingStatus struct {
+	Address   string     `json:"address"`
+	MaxFeeUSD float64    `json:"max_fee_usd"` // 生效的上限（美元），0 表示不限制
+	Default   bool       `json:"default"`     // 是否为全局默认值（钱包未设置）
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// GetFeeCeiling 查询钱包生效的手续费上限，未设置时返回全局默认值
+func (s *WalletService) GetFeeCeiling(owner string) (*FeeCeilingStatus, error) {
+	ceiling, err := findFeeCeiling(owner)
+	if errors.Is(err, ErrFeeCeilingNotFound) {
+		return &FeeCeilingStatus{Address: strings.ToLower(owner), MaxFeeUSD: config.AppConfig.FeeCeiling.DefaultMaxFeeUSD, Default: true}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &FeeCeilingStatus{Address: ceiling.OwnerAddress, MaxFeeUSD: ceiling.MaxFeeUSD, UpdatedAt: &ceiling.UpdatedAt}, nil
+}
+
+// SetFeeCeiling 设置钱包的单笔交易手续费上限（美元）
+func (s *WalletService) SetFeeCeiling(owner string, maxFeeUSD float64) (*FeeCeilingStatus, error) {
+	if maxFeeUSD <= 0 || math.IsNaN(maxFeeUSD) || math.IsInf(maxFeeUSD, 0) {
+		return nil, fmt.Errorf("max_fee_usd 必须大于0，取消上限请删除")
+	}
+	if database.DB == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+	ceiling, err := findFeeCeiling(owner)
+	switch {
+	case errors.Is(err, ErrFeeCeilingNotFound):
+		ceiling = &models.FeeCeiling{OwnerAddress: strings.ToLower(owner)}
+	case err != nil:
+		return nil, err
+	}
+	ceiling.MaxFeeUSD = maxFeeUSD
+	if err := database.DB.Save(ceiling).Error; err != nil {
+		return nil, fmt.Errorf("保存手续费上限失败: %w", err)
+	}
+	return &FeeCeilingStatus{Address: ceiling.OwnerAddress, MaxFeeUSD: ceiling.MaxFeeUSD, UpdatedAt: &ceiling.UpdatedAt}, nil
+}
+
+// DeleteFeeCeiling 删除钱包的手续费上限，之后使用全局默认值
+func (s *WalletService) DeleteFeeCeiling(owner string) error {
+	ceiling, err := findFeeCeiling(owner)
+	if err != nil {
+		return err
+	}
+	if err := database.DB.Unscoped().Delete(ceiling).Error; err != nil {
+		return fmt.Errorf("删除手续费上限失败: %w", err)
+	}
+	return nil
+}
+
+// feeCeilingWei 折算 from 在 networkID 上的默认手续费上限（wei），注册为 core.FeeCeilingFunc
+func (s *WalletService) feeCeilingWei(ctx context.Context, networkID string, from common.Address) *big.Int {
+	maxFeeUSD := config.AppConfig.FeeCeiling.DefaultMaxFeeUSD
+	if ceiling, err := findFeeCeiling(from.Hex()); err == nil {
+		maxFeeUSD = ceiling.MaxFeeUSD
+	} else if !errors.Is(err, ErrFeeCeilingNotFound) {
+		slog.WarnContext(ctx, "fee ceiling lookup failed", "from", from.Hex(), "error", err)
+	}
+	if maxFeeUSD <= 0 {
+		return nil
+	}
+	chainID := ChainIDForNetwork(networkID)
+	if s.priceService == nil || chainID == 0 {
+		return nil
+	}
+	price, err := s.priceService.GetNativePriceUSD(ctx, chainID)
+	if err != nil || price <= 0 {
+		slog.WarnContext(ctx, "native price unavailable, skipping fee ceiling check", "network", networkID, "error", err)
+		return nil
+	}
+	decimals := core.NativeCurrencyFor(networkID).Decimals
+	units := new(big.Float).Quo(big.NewFloat(maxFeeUSD), big.NewFloat(price))
+	units.Mul(units, new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)))
+	wei, _ := units.Int(nil)
+	return wei
+}
+
+// findFeeCeiling 查询钱包的手续费上限记录
+func findFeeCeiling(owner string) (*models.FeeCeiling, error) {
+	if database.DB == nil {
+		return nil, ErrFeeCeilingNotFound
+	}
+	var ceiling models.FeeCeiling
+	err := database.DB.Where("owner_address = ?", strings.ToLower(owner)).First(&ceiling).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrFeeCeilingNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询手续费上限失败: %w", err)
+	}
+	return &ceiling, nil
+}
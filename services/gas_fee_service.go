@@ -0,0 +1,104 @@
+/*
+Gas 费用美元估算
+
+Gas 建议只给出 wei 单位的费率，用户难以判断实际花费。本服务按常见交易的 Gas 用量估算各档位的总费用，
+并通过价格服务换算为美元：
+- 原生币转账按 21000 Gas，ERC20 转账按 65000 Gas 估算，调用方可额外指定 gasLimit
+- EIP-1559 档位按预期实际费率 min(maxFee, baseFee+tip) 计算，legacy 按 gasPrice 计算
+- 原生币价格复用价格服务的缓存（CacheTTLSeconds），不会每次请求都访问预言机；查不到价格时美元字段为 0 并标记 no_price
+*/
+package services
+
+import (
+	"context"
+	"math/big"
+	"wallet/core"
+)
+
+// 常见交易的 Gas 用量
+const (
+	NativeTransferGas uint64 = 21000
+	ERC20TransferGas  uint64 = 65000
+)
+
+// GasTierFee 单档费率下的费用估算
+type GasTierFee struct {
+	FeePerGas      string  `json:"fee_per_gas"`                 // 预期实际费率（wei）
+	TransferFeeWei string  `json:"transfer_fee_wei"`            // 原生币转账费用（wei）
+	TransferFeeUSD float64 `json:"transfer_fee_usd"`            // 原生币转账费用（美元）
+	ERC20FeeWei    string  `json:"erc20_fee_wei"`               // ERC20 转账费用（wei）
+	ERC20FeeUSD    float64 `json:"erc20_fee_usd"`               // ERC20 转账费用（美元）
+	CustomFeeWei   string  `json:"gas_limit_fee_wei,omitempty"` // 指定 gasLimit 的费用（wei）
+	CustomFeeUSD   float64 `json:"gas_limit_fee_usd,omitempty"` // 指定 gasLimit 的费用（美元）
+}
+
+// GasFeeEstimate 各档位的费用估算
+type GasFeeEstimate struct {
+	NativePriceUSD float64                `json:"native_price_usd"` // 原生币美元价格
+	NoPrice        bool                   `json:"no_price"`         // 是否缺少原生币价格（美元字段均为 0）
+	GasLimit       uint64                 `json:"gas_limit,omitempty"`
+	Default        GasTierFee             `json:"default"`         // 默认档（normal 档或当前建议费率）
+	Tiers          map[string]*GasTierFee `json:"tiers,omitempty"` // slow/normal/fast 分档
+}
+
+// EstimateGasFeesUSD 按当前网络的 Gas 建议估算常见交易的费用及美元价值，gasLimit 为 0 时不估算自定义用量
+func (s *WalletService) EstimateGasFeesUSD(ctx context.Context, sug *core.GasSuggestion, gasLimit uint64) *GasFeeEstimate {
+	estimate := &GasFeeEstimate{GasLimit: gasLimit, NoPrice: true}
+	decimals := s.GetNativeCurrency().Decimals
+	if s.priceService != nil && sug.ChainID != nil {
+		if price, err := s.priceService.GetNativePriceUSD(ctx, int(sug.ChainID.Int64())); err == nil && price > 0 {
+			estimate.NativePriceUSD = price
+			estimate.NoPrice = false
+		}
+	}
+
+	feeFor := func(feePerGas *big.Int) GasTierFee {
+		if feePerGas == nil {
+			feePerGas = big.NewInt(0)
+		}
+		cost := func(gas uint64) *big.Int { return new(big.Int).Mul(feePerGas, new(big.Int).SetUint64(gas)) }
+		transfer, erc20 := cost(NativeTransferGas), cost(ERC20TransferGas)
+		fee := GasTierFee{
+			FeePerGas:      feePerGas.String(),
+			TransferFeeWei: transfer.String(),
+			TransferFeeUSD: ValueUSD(transfer, decimals, estimate.NativePriceUSD),
+			ERC20FeeWei:    erc20.String(),
+			ERC20FeeUSD:    ValueUSD(erc20, decimals, estimate.NativePriceUSD),
+		}
+		if gasLimit > 0 {
+			custom := cost(gasLimit)
+			fee.CustomFeeWei = custom.String()
+			fee.CustomFeeUSD = ValueUSD(custom, decimals, estimate.NativePriceUSD)
+		}
+		return fee
+	}
+
+	if sug.BaseFee != nil && sug.BaseFee.Sign() > 0 {
+		estimate.Default = feeFor(effectiveFeePerGas(sug.BaseFee, sug.TipCap, sug.MaxFee))
+		if len(sug.Tiers) > 0 {
+			estimate.Tiers = make(map[string]*GasTierFee, len(sug.Tiers))
+			for name, tier := range sug.Tiers {
+				fee := feeFor(effectiveFeePerGas(sug.BaseFee, tier.TipCap, tier.MaxFee))
+				estimate.Tiers[name] = &fee
+			}
+			if normal, ok := estimate.Tiers["normal"]; ok {
+				estimate.Default = *normal
+			}
+		}
+	} else {
+		estimate.Default = feeFor(sug.GasPrice)
+	}
+	return estimate
+}
+
+// effectiveFeePerGas EIP-1559 交易预期的实际费率：min(maxFee, baseFee+tip)
+func effectiveFeePerGas(baseFee, tip, maxFee *big.Int) *big.Int {
+	if tip == nil {
+		tip = big.NewInt(0)
+	}
+	fee := new(big.Int).Add(baseFee, tip)
+	if maxFee != nil && maxFee.Sign() > 0 && fee.Cmp(maxFee) > 0 {
+		return new(big.Int).Set(maxFee)
+	}
+	return fee
+}
@@ -0,0 +1,80 @@
+/*
+服务就绪检查
+
+/ready 依赖的检查逻辑：数据库 Ping 与各已配置网络的RPC节点（eth_blockNumber）并发执行，
+整体受 readinessTimeout 限制，个别节点无响应不会拖慢检查。
+数据库可用且默认网络（未指定网络时使用的当前网络）可达时视为就绪，其余网络不可达只在明细中体现。
+*/
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+	"wallet/core"
+	"wallet/database"
+)
+
+// readinessTimeout 就绪检查中每项依赖的超时
+const readinessTimeout = 2 * time.Second
+
+// DependencyStatus 单项依赖的检查结果
+type DependencyStatus struct {
+	Status    string `json:"status"` // up / down
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ReadinessReport 就绪检查结果
+type ReadinessReport struct {
+	Ready          bool                `json:"ready"`
+	Database       DependencyStatus    `json:"database"`
+	DefaultNetwork string              `json:"default_network"`
+	Networks       []core.NetworkProbe `json:"networks"`
+	CheckedAt      time.Time           `json:"checked_at"`
+}
+
+// HealthService 服务就绪检查
+type HealthService struct {
+	multiChain *core.MultiChainManager
+}
+
+// NewHealthService 创建就绪检查服务
+func NewHealthService(multiChain *core.MultiChainManager) *HealthService {
+	return &HealthService{multiChain: multiChain}
+}
+
+// Ready 并发检查数据库与各网络RPC节点
+func (s *HealthService) Ready(ctx context.Context) *ReadinessReport {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	report := &ReadinessReport{DefaultNetwork: s.multiChain.GetCurrentNetwork(), CheckedAt: time.Now()}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		start := time.Now()
+		err := database.HealthCheckContext(ctx)
+		report.Database = DependencyStatus{Status: core.NetworkProbeUp, LatencyMs: time.Since(start).Milliseconds()}
+		if err != nil {
+			report.Database.Status = core.NetworkProbeDown
+			report.Database.Error = err.Error()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		report.Networks = s.multiChain.ProbeNetworks(ctx)
+	}()
+	wg.Wait()
+
+	defaultUp := false
+	for _, probe := range report.Networks {
+		if probe.Network == report.DefaultNetwork {
+			defaultUp = probe.Status != core.NetworkProbeDown
+			break
+		}
+	}
+	report.Ready = report.Database.Status == core.NetworkProbeUp && defaultUp
+	return report
+}
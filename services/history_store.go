@@ -0,0 +1,158 @@
+/*
+交易历史持久化存储
+
+实现 core.HistoryStore：扫描到的交易写入 history_transactions，已扫描的区块区间写入 history_scans，
+分页查询直接在数据库中过滤、排序与 OFFSET，重启后无需重新扫描已确认的区块。
+按 history.cache_store 选择（见 configureHistoryStore）。
+*/
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+	"wallet/config"
+	"wallet/core"
+	"wallet/database"
+	"wallet/models"
+
+	"gorm.io/gorm"
+)
+
+// historyInsertBatch 批量写入交易的每批条数
+const historyInsertBatch = 100
+
+// DBHistoryStore 基于数据库的交易历史存储
+type DBHistoryStore struct{}
+
+// NewDBHistoryStore 创建数据库交易历史存储
+func NewDBHistoryStore() *DBHistoryStore {
+	return &DBHistoryStore{}
+}
+
+// configureHistoryStore 按配置为所有EVM网络设置交易历史存储
+// database 模式下数据库未初始化时回退为内存缓存
+func configureHistoryStore(multiChain *core.MultiChainManager, cfg config.HistoryConfig) {
+	cfg = cfg.WithDefaults()
+	switch cfg.CacheStore {
+	case config.HistoryCacheDisabled:
+		return
+	case config.HistoryCacheDatabase:
+		if database.DB != nil {
+			multiChain.SetHistoryStore(NewDBHistoryStore())
+			return
+		}
+		slog.WarnContext(context.Background(), "database not initialized, using in-memory transaction history")
+	}
+	multiChain.SetHistoryStore(core.NewMemoryHistoryStore(time.Duration(cfg.CacheTTLMinutes)*time.Minute, cfg.CacheMaxAddresses))
+}
+
+func (s *DBHistoryStore) HistoryCoverage(networkID, address string) (*core.HistoryCoverage, error) {
+	var scan models.HistoryScan
+	err := database.DB.Where("network_id = ? AND address = ?", networkID, address).First(&scan).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &core.HistoryCoverage{From: scan.FromBlock, ConfirmedTo: scan.ConfirmedTo, ScannedTo: scan.ScannedTo, Head: scan.HeadBlock}, nil
+}
+
+func (s *DBHistoryStore) SaveHistory(networkID, address string, coverage core.HistoryCoverage, replaceFrom uint64, txs []core.TransactionInfo) error {
+	records := make([]models.HistoryTransaction, 0, len(txs))
+	hashes := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		payload, err := json.Marshal(tx)
+		if err != nil {
+			return fmt.Errorf("序列化交易失败: %w", err)
+		}
+		block, _ := strconv.ParseUint(tx.BlockNumber, 10, 64)
+		records = append(records, models.HistoryTransaction{
+			NetworkID:   networkID,
+			Address:     address,
+			Hash:        tx.Hash,
+			BlockNumber: block,
+			Timestamp:   tx.Timestamp,
+			TxType:      tx.TxType,
+			HasToken:    tx.TokenInfo != nil,
+			Payload:     string(payload),
+		})
+		hashes = append(hashes, tx.Hash)
+	}
+
+	return database.DB.Transaction(func(db *gorm.DB) error {
+		if err := db.Unscoped().Where("network_id = ? AND address = ? AND block_number >= ?", networkID, address, replaceFrom).
+			Delete(&models.HistoryTransaction{}).Error; err != nil {
+			return err
+		}
+		if len(hashes) > 0 {
+			if err := db.Unscoped().Where("network_id = ? AND address = ? AND hash IN ?", networkID, address, hashes).
+				Delete(&models.HistoryTransaction{}).Error; err != nil {
+				return err
+			}
+			if err := db.CreateInBatches(records, historyInsertBatch).Error; err != nil {
+				return err
+			}
+		}
+
+		var scan models.HistoryScan
+		err := db.Where("network_id = ? AND address = ?", networkID, address).First(&scan).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		scan.NetworkID, scan.Address = networkID, address
+		scan.FromBlock, scan.ConfirmedTo, scan.ScannedTo, scan.HeadBlock = coverage.From, coverage.ConfirmedTo, coverage.ScannedTo, coverage.Head
+		return db.Save(&scan).Error
+	})
+}
+
+func (s *DBHistoryStore) QueryHistory(networkID, address string, req *core.TransactionHistoryRequest) ([]core.TransactionInfo, int, error) {
+	query := database.DB.Model(&models.HistoryTransaction{}).
+		Where("network_id = ? AND address = ? AND block_number BETWEEN ? AND ?", networkID, address, req.StartBlock, req.EndBlock)
+	switch req.TxType {
+	case "", "all":
+	case "ERC20":
+		// 与 core 的类型过滤一致：合约交互中的代币转账也计入 ERC20
+		query = query.Where("(tx_type = ? OR has_token = ?)", "ERC20", true)
+	default:
+		query = query.Where("tx_type = ?", req.TxType)
+	}
+
+	// 计数与分页查询共用过滤条件
+	query = query.Session(&gorm.Session{})
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	column := "timestamp"
+	if req.SortBy == "block_number" {
+		column = "block_number"
+	}
+	direction := "DESC"
+	if req.SortOrder == "asc" {
+		direction = "ASC"
+	}
+	var records []models.HistoryTransaction
+	err := query.Order(column + " " + direction).Order("id " + direction).
+		Offset((req.Page - 1) * req.Limit).Limit(req.Limit).
+		Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	transactions := make([]core.TransactionInfo, 0, len(records))
+	for _, record := range records {
+		var tx core.TransactionInfo
+		if err := json.Unmarshal([]byte(record.Payload), &tx); err != nil {
+			return nil, 0, fmt.Errorf("解析缓存的交易 %s 失败: %w", record.Hash, err)
+		}
+		transactions = append(transactions, tx)
+	}
+	return transactions, int(total), nil
+}
@@ -0,0 +1,156 @@
+/*
+发送类请求的幂等键存储
+
+幂等键按 (owner, key) 保存在数据库中，owner 为会话所属钱包地址（未登录时为 ip:<客户端IP>）：
+  - Acquire 以唯一插入占用键：插入成功者处理请求；并发的相同请求插入失败，得到 processing 状态而不会再次广播
+  - 请求处理完成后 Complete 保存响应，TTL 内相同键的重试直接返回该响应
+  - 同一个键用于内容不同的请求（方法、路径或请求体不同）时拒绝，避免客户端复用键导致新转账被当作重试吞掉
+  - 请求在广播前被拒绝（4xx）时 Release 删除键，客户端修正后可用同一个键重新提交
+
+过期记录由后台每小时清理；占用时过期记录会先被删除。数据库不可用时返回错误，不在无法去重的情况下发送交易。
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"wallet/config"
+	"wallet/core"
+	"wallet/database"
+	"wallet/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 幂等键状态
+const (
+	IdempotencyStatusProcessing = "processing"
+	IdempotencyStatusCompleted  = "completed"
+)
+
+// idempotencyCleanupInterval 过期幂等键的清理间隔
+const idempotencyCleanupInterval = time.Hour
+
+// IdempotencyService 幂等键存储
+type IdempotencyService struct {
+	cfg          config.IdempotencyConfig
+	resolveOwner func(sessionID string) (string, error)
+}
+
+// NewIdempotencyService 创建幂等键存储并启动过期记录清理；resolveOwner 根据会话ID解析钱包地址
+func NewIdempotencyService(cfg config.IdempotencyConfig, resolveOwner func(sessionID string) (string, error)) *IdempotencyService {
+	is := &IdempotencyService{cfg: cfg.WithDefaults(), resolveOwner: resolveOwner}
+	go is.cleanupLoop()
+	return is
+}
+
+// Acquire 占用幂等键，返回键的归属；键已完成时返回首次请求的响应
+// 键正在处理返回 core.ErrIdempotencyInProgress，键已用于不同请求返回 core.ErrIdempotencyKeyReused
+func (is *IdempotencyService) Acquire(ctx context.Context, sessionID, clientIP, key, fingerprint string) (string, *core.IdempotentResponse, error) {
+	if database.DB == nil {
+		return "", nil, errors.New("数据库未初始化，无法处理 Idempotency-Key")
+	}
+	owner := is.owner(sessionID, clientIP)
+	db := database.DB.WithContext(ctx)
+
+	// 第二次尝试用于占用已过期的旧记录
+	for attempt := 0; attempt < 2; attempt++ {
+		now := time.Now()
+		record := models.IdempotencyRecord{
+			Owner:          owner,
+			IdempotencyKey: key,
+			Fingerprint:    fingerprint,
+			Status:         IdempotencyStatusProcessing,
+			ExpiresAt:      now.Add(time.Duration(is.cfg.TTLHours) * time.Hour),
+		}
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+		if result.Error != nil {
+			return "", nil, fmt.Errorf("保存幂等键失败: %w", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			return owner, nil, nil
+		}
+
+		var existing models.IdempotencyRecord
+		err := db.Unscoped().Where("owner = ? AND idempotency_key = ?", owner, key).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue // 冲突的记录刚被释放
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("查询幂等键失败: %w", err)
+		}
+		if now.After(existing.ExpiresAt) || existing.DeletedAt.Valid {
+			if err := db.Unscoped().Where("id = ?", existing.ID).Delete(&models.IdempotencyRecord{}).Error; err != nil {
+				return "", nil, fmt.Errorf("删除过期幂等键失败: %w", err)
+			}
+			continue
+		}
+		if existing.Fingerprint != fingerprint {
+			return "", nil, core.ErrIdempotencyKeyReused
+		}
+		if existing.Status != IdempotencyStatusCompleted {
+			return "", nil, core.ErrIdempotencyInProgress
+		}
+		return owner, &core.IdempotentResponse{Status: existing.ResponseStatus, Body: []byte(existing.ResponseBody)}, nil
+	}
+	return "", nil, core.ErrIdempotencyInProgress
+}
+
+// Complete 保存请求的响应，之后相同键的重试直接返回该响应
+func (is *IdempotencyService) Complete(owner, key string, status int, body []byte) {
+	if database.DB == nil {
+		return
+	}
+	err := database.DB.Model(&models.IdempotencyRecord{}).
+		Where("owner = ? AND idempotency_key = ?", owner, key).
+		Updates(map[string]interface{}{
+			"status":          IdempotencyStatusCompleted,
+			"response_status": status,
+			"response_body":   string(body),
+		}).Error
+	if err != nil {
+		slog.WarnContext(context.Background(), "idempotency response save failed", "key", key, "error", err)
+	}
+}
+
+// Release 删除未完成的幂等键（请求在广播前被拒绝），客户端可用同一个键重新提交
+func (is *IdempotencyService) Release(owner, key string) {
+	if database.DB == nil {
+		return
+	}
+	err := database.DB.Unscoped().
+		Where("owner = ? AND idempotency_key = ? AND status = ?", owner, key, IdempotencyStatusProcessing).
+		Delete(&models.IdempotencyRecord{}).Error
+	if err != nil {
+		slog.WarnContext(context.Background(), "idempotency key release failed", "key", key, "error", err)
+	}
+}
+
+// owner 幂等键归属：会话所属钱包地址，未登录或会话失效时按客户端IP区分
+func (is *IdempotencyService) owner(sessionID, clientIP string) string {
+	if sessionID != "" && is.resolveOwner != nil {
+		if address, err := is.resolveOwner(sessionID); err == nil && address != "" {
+			return strings.ToLower(address)
+		}
+	}
+	return "ip:" + clientIP
+}
+
+// cleanupLoop 定期删除过期的幂等键
+func (is *IdempotencyService) cleanupLoop() {
+	ticker := time.NewTicker(idempotencyCleanupInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if database.DB == nil {
+			continue
+		}
+		if err := database.DB.Unscoped().Where("expires_at < ?", time.Now()).Delete(&models.IdempotencyRecord{}).Error; err != nil {
+			slog.WarnContext(context.Background(), "expired idempotency keys purge failed", "error", err)
+		}
+	}
+}
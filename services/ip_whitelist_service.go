@@ -0,0 +1,242 @@
+/*
+用户IP白名单
+
+用户可为自己的钱包地址配置允许执行签名/发送操作的客户端IP（单个IP或CIDR网段）：
+- 没有任何规则时不限制IP
+- 规则按规范化后的网段去重，每个用户最多 maxIPWhitelistEntries 条
+- 新增、修改、删除后的白名单必须仍包含当前请求IP，避免用户把自己锁在外面
+- 被拒绝的请求记入安全审计日志（ip_whitelist_denied）
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"wallet/core"
+	"wallet/database"
+	"wallet/models"
+
+	"gorm.io/gorm"
+)
+
+// maxIPWhitelistEntries 每个用户的白名单规则数上限
+const maxIPWhitelistEntries = 100
+
+// IP白名单错误
+var (
+	ErrIPWhitelistEntryNotFound = errors.New("白名单规则不存在")
+	ErrIPWhitelistLockout       = errors.New("修改后当前IP将不在白名单中，请先添加当前IP")
+)
+
+// IPWhitelistEntry 用户IP白名单规则
+type IPWhitelistEntry struct {
+	ID        uint      `json:"id"`         // 规则ID
+	CIDR      string    `json:"cidr"`       // 规范化的网段
+	Label     string    `json:"label"`      // 备注
+	CreatedAt time.Time `json:"created_at"` // 添加时间
+}
+
+func toIPWhitelistEntry(m *models.IPWhitelistEntry) IPWhitelistEntry {
+	return IPWhitelistEntry{ID: m.ID, CIDR: m.CIDR, Label: m.Label, CreatedAt: m.CreatedAt}
+}
+
+// ListIPWhitelist 按添加顺序返回用户的IP白名单
+func (ss *SecurityService) ListIPWhitelist(owner string) ([]IPWhitelistEntry, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	var records []models.IPWhitelistEntry
+	if err := database.DB.Where("owner_address = ?", strings.ToLower(owner)).Order("id").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("查询IP白名单失败: %w", err)
+	}
+	entries := make([]IPWhitelistEntry, 0, len(records))
+	for i := range records {
+		entries = append(entries, toIPWhitelistEntry(&records[i]))
+	}
+	return entries, nil
+}
+
+// AddIPWhitelistEntry 添加白名单规则，clientIP 为当前请求IP；规则已存在时更新备注
+func (ss *SecurityService) AddIPWhitelistEntry(owner, rule, label, clientIP string) (*IPWhitelistEntry, error) {
+	cidr, err := core.NormalizeIPRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := ss.whitelistRules(owner)
+	if err != nil {
+		return nil, err
+	}
+	var existing models.IPWhitelistEntry
+	err = database.DB.Where("owner_address = ? AND cidr = ?", strings.ToLower(owner), cidr).First(&existing).Error
+	switch {
+	case err == nil:
+		existing.Label = label
+		if err := database.DB.Save(&existing).Error; err != nil {
+			return nil, fmt.Errorf("更新白名单规则失败: %w", err)
+		}
+		entry := toIPWhitelistEntry(&existing)
+		return &entry, nil
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, fmt.Errorf("查询IP白名单失败: %w", err)
+	}
+	if len(rules) >= maxIPWhitelistEntries {
+		return nil, fmt.Errorf("IP白名单最多 %d 条规则", maxIPWhitelistEntries)
+	}
+	if !core.IPAllowed(clientIP, append(rules, cidr)) {
+		return nil, ErrIPWhitelistLockout
+	}
+
+	record := models.IPWhitelistEntry{OwnerAddress: strings.ToLower(owner), CIDR: cidr, Label: label}
+	if err := database.DB.Create(&record).Error; err != nil {
+		return nil, fmt.Errorf("添加白名单规则失败: %w", err)
+	}
+	entry := toIPWhitelistEntry(&record)
+	return &entry, nil
+}
+
+// UpdateIPWhitelistEntry 修改白名单规则的网段与备注
+func (ss *SecurityService) UpdateIPWhitelistEntry(owner string, id uint, rule, label, clientIP string) (*IPWhitelistEntry, error) {
+	cidr, err := core.NormalizeIPRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	record, err := ss.findIPWhitelistEntry(owner, id)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := ss.whitelistRules(owner)
+	if err != nil {
+		return nil, err
+	}
+	updated := make([]string, 0, len(rules))
+	for _, r := range rules {
+		if r == cidr && r != record.CIDR {
+			return nil, fmt.Errorf("规则 %s 已存在", cidr)
+		}
+		if r != record.CIDR {
+			updated = append(updated, r)
+		}
+	}
+	if !core.IPAllowed(clientIP, append(updated, cidr)) {
+		return nil, ErrIPWhitelistLockout
+	}
+
+	record.CIDR = cidr
+	record.Label = label
+	if err := database.DB.Save(record).Error; err != nil {
+		return nil, fmt.Errorf("更新白名单规则失败: %w", err)
+	}
+	entry := toIPWhitelistEntry(record)
+	return &entry, nil
+}
+
+// RemoveIPWhitelistEntry 删除白名单规则；删除最后一条规则即取消IP限制
+func (ss *SecurityService) RemoveIPWhitelistEntry(owner string, id uint, clientIP string) error {
+	record, err := ss.findIPWhitelistEntry(owner, id)
+	if err != nil {
+		return err
+	}
+	rules, err := ss.whitelistRules(owner)
+	if err != nil {
+		return err
+	}
+	remaining := make([]string, 0, len(rules))
+	for _, r := range rules {
+		if r != record.CIDR {
+			remaining = append(remaining, r)
+		}
+	}
+	if !core.IPAllowed(clientIP, remaining) {
+		return ErrIPWhitelistLockout
+	}
+	if err := database.DB.Unscoped().Delete(record).Error; err != nil {
+		return fmt.Errorf("删除白名单规则失败: %w", err)
+	}
+	return nil
+}
+
+// CheckRequestIP 校验请求所属用户是否允许从 ip 访问，resource 为被访问的接口（记入审计日志）
+// 按处理器实际用于签名的凭据（请求体中的 session_id，或助记词与派生路径）确定钱包，未提供时使用认证令牌的会话；
+// 认证令牌与请求体分属不同钱包时返回 core.ErrIPWhitelistOwnerMismatch；
+// 凭据无效或都未提供时返回 core.ErrIPWhitelistNoOwner，不放行
+func (ss *SecurityService) CheckRequestIP(ctx context.Context, authSessionID, sessionID, mnemonic, derivationPath, ip, resource string) error {
+	var authOwner, owner string
+	if authSessionID != "" {
+		if authOwner, _ = ss.walletService.GetSessionAddress(authSessionID); authOwner == "" {
+			return core.ErrIPWhitelistNoOwner
+		}
+	}
+	switch {
+	case sessionID != "":
+		owner, _ = ss.walletService.GetSessionAddress(sessionID)
+	case mnemonic != "":
+		owner, _ = ss.walletService.ImportMnemonic(mnemonic, derivationPath)
+	default:
+		owner = authOwner
+	}
+	if owner == "" {
+		return core.ErrIPWhitelistNoOwner
+	}
+	if authOwner != "" && !strings.EqualFold(authOwner, owner) {
+		return core.ErrIPWhitelistOwnerMismatch
+	}
+	return ss.CheckClientIP(ctx, owner, ip, resource)
+}
+
+// CheckClientIP 校验用户是否允许从 ip 访问，不在白名单中时返回 core.ErrIPNotWhitelisted 并记入审计日志
+func (ss *SecurityService) CheckClientIP(ctx context.Context, owner, ip, resource string) error {
+	if database.DB == nil {
+		return nil
+	}
+	rules, err := ss.whitelistRules(owner)
+	if err != nil {
+		return err
+	}
+	if core.IPAllowed(ip, rules) {
+		return nil
+	}
+
+	actx := core.AuditContextFrom(ctx)
+	if actx.UserAddress == "" {
+		actx.UserAddress = owner
+	}
+	if actx.IPAddress == "" {
+		actx.IPAddress = ip
+	}
+	ss.securityManager.LogSecurityAction(core.WithAuditContext(ctx, actx), "ip_whitelist_denied", "endpoint", resource, "denied", map[string]interface{}{
+		"ip": ip,
+	})
+	return core.ErrIPNotWhitelisted
+}
+
+// whitelistRules 读取用户的全部白名单网段
+func (ss *SecurityService) whitelistRules(owner string) ([]string, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	var rules []string
+	if err := database.DB.Model(&models.IPWhitelistEntry{}).Where("owner_address = ?", strings.ToLower(owner)).
+		Order("id").Pluck("cidr", &rules).Error; err != nil {
+		return nil, fmt.Errorf("查询IP白名单失败: %w", err)
+	}
+	return rules, nil
+}
+
+// findIPWhitelistEntry 查找属于用户的白名单规则
+func (ss *SecurityService) findIPWhitelistEntry(owner string, id uint) (*models.IPWhitelistEntry, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	var record models.IPWhitelistEntry
+	err := database.DB.Where("id = ? AND owner_address = ?", id, strings.ToLower(owner)).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrIPWhitelistEntryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询IP白名单失败: %w", err)
+	}
+	return &record, nil
+}
@@ -0,0 +1,29 @@
+/*
+Prometheus 指标注册表
+
+/metrics 使用独立的 Registry（不使用全局默认注册表），包含 Go 运行时、进程指标及节点调用指标（见 core/metrics.go）。
+*/
+package services
+
+import (
+	"fmt"
+	"wallet/core"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// newMetricsRegistry 创建指标注册表，并为所有EVM网络设置节点调用指标
+func newMetricsRegistry(multiChain *core.MultiChainManager) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	metrics, err := core.NewMetrics(registry)
+	if err != nil {
+		panic(fmt.Errorf("注册指标失败: %w", err))
+	}
+	multiChain.SetMetrics(metrics)
+	return registry
+}
@@ -0,0 +1,87 @@
+/*
+网络确认数设置
+
+不同链的最终性差异很大：Polygon 上1个确认的交易远不如以太坊主网可靠。
+各网络默认使用配置文件的 min_confirmations（未配置时按链ID取默认值，见 config.NetworkConfig.RequiredConfirmations），
+管理员（security.admin_addresses）可按网络覆盖该值，覆盖对所有用户生效：
+  - 设置后持久化到数据库，服务启动时重新加载（在自定义网络加载之后）
+  - 设置为 0 时删除覆盖，恢复网络配置的值
+  - 确认等待、待确认交易跟踪与交易历史的 confirmed 标记均按此值判断
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"wallet/database"
+	"wallet/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// MaxRequiredConfirmations 允许设置的最大确认数
+const MaxRequiredConfirmations = 10000
+
+// ErrConfirmationsForbidden 非管理员修改全局确认数设置
+var ErrConfirmationsForbidden = errors.New("只有管理员可以修改网络确认数")
+
+// SetRequiredConfirmations 设置网络的最终确认数并持久化，confirmations 为 0 时恢复网络配置的值
+// owner 须为管理员；返回生效后的确认数
+func (s *WalletService) SetRequiredConfirmations(owner, networkID string, confirmations uint64) (uint64, error) {
+	if database.DB == nil {
+		return 0, errors.New("数据库未初始化")
+	}
+	if securityService := s.GetSecurityService(); securityService == nil || !securityService.IsAuditAdmin(owner) {
+		return 0, ErrConfirmationsForbidden
+	}
+	if confirmations > MaxRequiredConfirmations {
+		return 0, fmt.Errorf("确认数不能超过 %d", MaxRequiredConfirmations)
+	}
+	if _, err := s.multiChain.NetworkConfig(networkID); err != nil {
+		return 0, err
+	}
+
+	if confirmations == 0 {
+		if err := database.DB.Unscoped().Where("network_id = ?", networkID).Delete(&models.NetworkConfirmation{}).Error; err != nil {
+			return 0, fmt.Errorf("删除确认数设置失败: %w", err)
+		}
+	} else {
+		record := models.NetworkConfirmation{
+			NetworkID:     networkID,
+			Confirmations: confirmations,
+			UpdatedBy:     strings.ToLower(owner),
+		}
+		conflict := clause.OnConflict{
+			Columns:   []clause.Column{{Name: "network_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"confirmations", "updated_by", "updated_at"}),
+		}
+		if err := database.DB.Clauses(conflict).Create(&record).Error; err != nil {
+			return 0, fmt.Errorf("保存确认数设置失败: %w", err)
+		}
+	}
+	if err := s.multiChain.SetRequiredConfirmations(networkID, confirmations); err != nil {
+		return 0, err
+	}
+	return s.multiChain.RequiredConfirmations(networkID), nil
+}
+
+// LoadRequiredConfirmations 从数据库加载各网络的确认数设置
+// 网络已不存在（如自定义网络加载失败）的设置跳过，保留记录
+func (s *WalletService) LoadRequiredConfirmations() {
+	if database.DB == nil {
+		return
+	}
+	var records []models.NetworkConfirmation
+	if err := database.DB.Find(&records).Error; err != nil {
+		slog.WarnContext(context.Background(), "network confirmation settings load failed", "error", err)
+		return
+	}
+	for _, r := range records {
+		if err := s.multiChain.SetRequiredConfirmations(r.NetworkID, r.Confirmations); err != nil {
+			slog.WarnContext(context.Background(), "network confirmation setting not applied", "network", r.NetworkID, "error", err)
+		}
+	}
+}
@@ -10,8 +10,10 @@ import (
 	"fmt"
 	"math/big"
 	"sort"
+	"strings"
 	"sync"
 	"time"
+	"wallet/config"
 	"wallet/core"
 )
 
@@ -22,9 +24,27 @@ type NFTMarketplaceService struct {
 	userPreferences map[string]*UserMarketPrefs // 用户市场偏好
 	watchlists      map[string]*Watchlist       // 用户关注列表
 	priceAlerts     map[string]*PriceAlert      // 价格提醒
+	priceService    *PriceService               // 代币美元价格服务（填充 USDValue）
+	notifier        *AlertNotifier              // 价格提醒通知分发
+	stopAlerts      context.CancelFunc          // 停止价格提醒后台检查
 	mu              sync.RWMutex                // 读写锁
 }
 
+// marketCurrencyTokens 市场计价货币符号 -> 用于查询美元价格的链ID与代币地址
+var marketCurrencyTokens = map[string]struct {
+	ChainID int
+	Token   string
+}{
+	"ETH":   {1, NativeTokenAddress},
+	"WETH":  {1, NativeTokenAddress},
+	"USDC":  {1, "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"},
+	"USDT":  {1, "0xdAC17F958D2ee523a2206206994597C13D831ec7"},
+	"DAI":   {1, "0x6B175474E89094C44Da98b954EedeAC495271d0F"},
+	"MATIC": {137, NativeTokenAddress},
+	"POL":   {137, NativeTokenAddress},
+	"BNB":   {56, NativeTokenAddress},
+}
+
 // UserMarketPrefs 用户市场偏好
 type UserMarketPrefs struct {
 	UserAddress          string                `json:"user_address"`          // 用户地址
@@ -73,16 +93,25 @@ type WatchlistItem struct {
 }
 
 // PriceAlert 价格提醒
+// 集合提醒（TokenID 为空）比较地板价，单个NFT提醒比较该NFT的最低挂单价
 type PriceAlert struct {
-	ID          string            `json:"id"`           // 提醒ID
-	UserAddress string            `json:"user_address"` // 用户地址
-	Contract    string            `json:"contract"`     // 合约地址
-	TokenID     string            `json:"token_id"`     // Token ID（可选）
-	AlertType   string            `json:"alert_type"`   // 提醒类型（above/below/change）
-	TargetPrice *core.MarketPrice `json:"target_price"` // 目标价格
-	IsActive    bool              `json:"is_active"`    // 是否激活
-	CreatedAt   time.Time         `json:"created_at"`   // 创建时间
-	TriggeredAt *time.Time        `json:"triggered_at"` // 触发时间
+	ID            string            `json:"id"`                       // 提醒ID
+	UserAddress   string            `json:"user_address"`             // 用户地址
+	Contract      string            `json:"contract"`                 // 合约地址
+	TokenID       string            `json:"token_id"`                 // Token ID（可选）
+	AlertType     string            `json:"alert_type"`               // 提醒类型（above/below/change）
+	TargetPrice   *core.MarketPrice `json:"target_price"`             // 目标价格（above/below）
+	ChangePercent float64           `json:"change_percent,omitempty"` // 涨跌幅阈值（%，change）
+	Repeat        bool              `json:"repeat"`                   // 重复提醒；为 false 时触发一次后停用
+	IsActive      bool              `json:"is_active"`                // 是否激活（暂停时为 false）
+	BasePrice     *core.MarketPrice `json:"base_price,omitempty"`     // change 类型的基准价（首次检查或上次触发时的价格）
+	LastPrice     *core.MarketPrice `json:"last_price,omitempty"`     // 最近一次检查的价格
+	LastCheckedAt *time.Time        `json:"last_checked_at,omitempty"`
+	TriggerCount  int               `json:"trigger_count"`
+	CreatedAt     time.Time         `json:"created_at"`   // 创建时间
+	TriggeredAt   *time.Time        `json:"triggered_at"` // 最近一次触发时间
+
+	state alertState
 }
 
 // MarketAnalysisRequest 市场分析请求
@@ -208,8 +237,16 @@ type ComparableItem struct {
 
 // NewNFTMarketplaceService 创建NFT市场服务
 func NewNFTMarketplaceService(nftService *NFTService) *NFTMarketplaceService {
+	marketplace := core.NewNFTMarketplace()
+	// 全局密钥作为兜底，用户自己的密钥通过 context 传入
+	for _, platform := range []string{"opensea", "rarible"} {
+		if key := config.AppConfig.Security.ProviderKeys[platform]; key != "" {
+			marketplace.SetAPIKey(platform, key)
+		}
+	}
+
 	return &NFTMarketplaceService{
-		marketplace:     core.NewNFTMarketplace(),
+		marketplace:     marketplace,
 		nftService:      nftService,
 		userPreferences: make(map[string]*UserMarketPrefs),
 		watchlists:      make(map[string]*Watchlist),
@@ -224,17 +261,66 @@ func (nms *NFTMarketplaceService) GetMarketListings(ctx context.Context, userAdd
 		nms.applyUserPreferencesToListingRequest(request, prefs)
 	}
 
-	return nms.marketplace.GetMarketListings(ctx, request)
+	listings, err := nms.marketplace.GetMarketListings(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	prices := make([]*core.MarketPrice, 0, len(listings))
+	for _, listing := range listings {
+		prices = append(prices, listing.Price)
+	}
+	nms.fillUSDValues(ctx, prices...)
+	return listings, nil
 }
 
 // GetMarketTransactions 获取市场交易记录
 func (nms *NFTMarketplaceService) GetMarketTransactions(ctx context.Context, userAddress string, request *core.MarketTransactionRequest) ([]*core.MarketTransaction, error) {
-	return nms.marketplace.GetMarketTransactions(ctx, request)
+	transactions, err := nms.marketplace.GetMarketTransactions(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	prices := make([]*core.MarketPrice, 0, len(transactions))
+	for _, tx := range transactions {
+		prices = append(prices, tx.Price)
+	}
+	nms.fillUSDValues(ctx, prices...)
+	return transactions, nil
 }
 
 // GetMarketStats 获取市场统计数据
 func (nms *NFTMarketplaceService) GetMarketStats(ctx context.Context, contract, platform string) (*core.MarketStats, error) {
-	return nms.marketplace.GetMarketStats(ctx, contract, platform)
+	stats, err := nms.marketplace.GetMarketStats(ctx, contract, platform)
+	if err != nil {
+		return nil, err
+	}
+	nms.fillUSDValues(ctx, stats.FloorPrice, stats.CeilingPrice, stats.AveragePrice, stats.Volume24h, stats.Volume7d, stats.Volume30d)
+	return stats, nil
+}
+
+// fillUSDValues 按计价货币的美元价格填充 MarketPrice.USDValue
+// 未知货币或无价格数据时保持为 0，不影响其余数据返回
+func (nms *NFTMarketplaceService) fillUSDValues(ctx context.Context, prices ...*core.MarketPrice) {
+	if nms.priceService == nil {
+		return
+	}
+	for _, price := range prices {
+		if price == nil || price.Amount == nil {
+			continue
+		}
+		currency, ok := marketCurrencyTokens[strings.ToUpper(price.Currency)]
+		if !ok {
+			continue
+		}
+		usd, err := nms.priceService.GetTokenPriceUSD(ctx, currency.ChainID, currency.Token)
+		if err != nil || usd == 0 {
+			continue
+		}
+		decimals := price.Decimals
+		if decimals == 0 {
+			decimals = 18
+		}
+		price.USDValue = ValueUSD(price.Amount, decimals, usd)
+	}
 }
 
 // GetPriceHistory 获取价格历史数据
@@ -354,21 +440,29 @@ func (nms *NFTMarketplaceService) GetWatchlist(userAddress, listName string) (*W
 }
 
 // CreatePriceAlert 创建价格提醒
-func (nms *NFTMarketplaceService) CreatePriceAlert(userAddress, contract, tokenID, alertType string, targetPrice *core.MarketPrice) (*PriceAlert, error) {
+// above/below 需要目标价；change 需要涨跌幅阈值，基准价取创建后首次检查到的价格
+func (nms *NFTMarketplaceService) CreatePriceAlert(userAddress, contract, tokenID, alertType string, targetPrice *core.MarketPrice, changePercent float64, repeat bool) (*PriceAlert, error) {
+	if err := ValidatePriceAlert(alertType, targetPrice != nil && targetPrice.Amount != nil, changePercent); err != nil {
+		return nil, err
+	}
+
 	nms.mu.Lock()
 	defer nms.mu.Unlock()
 
 	alertID := fmt.Sprintf("alert_%d", time.Now().UnixNano())
 
 	alert := &PriceAlert{
-		ID:          alertID,
-		UserAddress: userAddress,
-		Contract:    contract,
-		TokenID:     tokenID,
-		AlertType:   alertType,
-		TargetPrice: targetPrice,
-		IsActive:    true,
-		CreatedAt:   time.Now(),
+		ID:            alertID,
+		UserAddress:   userAddress,
+		Contract:      contract,
+		TokenID:       tokenID,
+		AlertType:     alertType,
+		TargetPrice:   targetPrice,
+		ChangePercent: changePercent,
+		Repeat:        repeat,
+		IsActive:      true,
+		CreatedAt:     time.Now(),
+		state:         alertState{armed: true},
 	}
 
 	nms.priceAlerts[alertID] = alert
@@ -384,7 +478,9 @@ func (nms *NFTMarketplaceService) GetPriceAlerts(userAddress string) ([]*PriceAl
 	var alerts []*PriceAlert
 	for _, alert := range nms.priceAlerts {
 		if alert.UserAddress == userAddress {
-			alerts = append(alerts, alert)
+			// 返回副本，后台检查会并发更新提醒状态
+			snapshot := *alert
+			alerts = append(alerts, &snapshot)
 		}
 	}
 
@@ -0,0 +1,150 @@
+/*
+NFT持有查询
+
+枚举地址持有的NFT（见 core.NFTManager.EnumerateOwnedNFTs）并分页返回：
+- 持有列表按 地址+合约列表 缓存 nft.ownership_cache_seconds，翻页时不重复扫描日志与链上调用
+- 只为当前页的NFT获取元数据，元数据由 NFTManager 按 nft.metadata_cache_minutes 缓存
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"wallet/config"
+	"wallet/core"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ownedNFTMetadataWorkers 并发获取元数据的数量
+const ownedNFTMetadataWorkers = 5
+
+// OwnedNFTPage 地址持有的NFT（分页）
+type OwnedNFTPage struct {
+	Address   string      `json:"address"`
+	Contracts []string    `json:"contracts"` // 查询的合约（未指定时为从日志识别出的合约）
+	NFTs      []*core.NFT `json:"nfts"`
+	Total     int         `json:"total"`
+	Offset    int         `json:"offset"`
+	Limit     int         `json:"limit"`
+}
+
+// ownedNFTsEntry 持有列表缓存
+type ownedNFTsEntry struct {
+	owned     []*core.OwnedNFT
+	expiresAt time.Time
+}
+
+// GetOwnedNFTs 返回 owner 持有的NFT，按合约、tokenId 排序后取 [offset, offset+limit) 并获取元数据
+// contracts 为空时从最近的转账日志识别持有的合约；元数据获取失败的NFT仍然返回，Metadata 为空
+func (s *NFTService) GetOwnedNFTs(ctx context.Context, owner string, contracts []string, offset, limit int) (*OwnedNFTPage, error) {
+	if !common.IsHexAddress(owner) {
+		return nil, fmt.Errorf("无效的地址: %s", owner)
+	}
+	owner = common.HexToAddress(owner).Hex()
+	owned, err := s.ownedNFTs(ctx, owner, contracts)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &OwnedNFTPage{Address: owner, Contracts: ownedContracts(owned, contracts), NFTs: []*core.NFT{}, Total: len(owned), Offset: offset, Limit: limit}
+	if offset >= len(owned) {
+		return page, nil
+	}
+	end := offset + limit
+	if end > len(owned) {
+		end = len(owned)
+	}
+	page.NFTs = s.ownedNFTDetails(ctx, owner, owned[offset:end])
+	return page, nil
+}
+
+// ownedNFTs 读取持有列表，命中缓存时直接返回
+func (s *NFTService) ownedNFTs(ctx context.Context, owner string, contracts []string) ([]*core.OwnedNFT, error) {
+	normalized := make([]string, 0, len(contracts))
+	for _, c := range contracts {
+		if c = strings.TrimSpace(c); c != "" {
+			normalized = append(normalized, strings.ToLower(c))
+		}
+	}
+	sort.Strings(normalized)
+	cacheKey := strings.ToLower(owner) + "|" + strings.Join(normalized, ",")
+
+	s.mu.RLock()
+	entry, ok := s.ownedCache[cacheKey]
+	s.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.owned, nil
+	}
+
+	nftConfig := config.AppConfig.NFT.WithDefaults()
+	owned, err := s.nftManager.EnumerateOwnedNFTs(ctx, owner, normalized, nftConfig.DetectLookbackBlocks, nftConfig.MaxTokensPerContract)
+	if err != nil {
+		return nil, fmt.Errorf("枚举持有的NFT失败: %w", err)
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	for key, cached := range s.ownedCache {
+		if now.After(cached.expiresAt) {
+			delete(s.ownedCache, key)
+		}
+	}
+	s.ownedCache[cacheKey] = &ownedNFTsEntry{owned: owned, expiresAt: now.Add(time.Duration(nftConfig.OwnershipCacheSeconds) * time.Second)}
+	s.mu.Unlock()
+	return owned, nil
+}
+
+// ownedNFTDetails 并发获取当前页NFT的元数据，保持原有顺序
+func (s *NFTService) ownedNFTDetails(ctx context.Context, owner string, owned []*core.OwnedNFT) []*core.NFT {
+	nfts := make([]*core.NFT, len(owned))
+	sem := make(chan struct{}, ownedNFTMetadataWorkers)
+	var wg sync.WaitGroup
+	for i, item := range owned {
+		nft := &core.NFT{
+			TokenID:      item.TokenID.String(),
+			ContractAddr: item.Contract,
+			Standard:     item.Standard,
+			Owner:        owner,
+			UpdatedAt:    time.Now(),
+		}
+		if item.Standard == core.NFTStandardERC1155 {
+			nft.Balance = item.Balance
+		}
+		nfts[i] = nft
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(nft *core.NFT, item *core.OwnedNFT) {
+			defer func() { <-sem; wg.Done() }()
+			metadata, attributes, tokenURI, err := s.nftManager.FetchTokenMetadata(ctx, item.Contract, item.Standard, item.TokenID)
+			nft.TokenURI = tokenURI
+			if err == nil {
+				nft.Metadata = metadata
+				nft.Attributes = attributes
+			}
+		}(nft, item)
+	}
+	wg.Wait()
+	return nfts
+}
+
+// ownedContracts 查询涉及的合约：指定了合约时原样返回，否则为持有列表中出现的合约
+func ownedContracts(owned []*core.OwnedNFT, requested []string) []string {
+	if len(requested) > 0 {
+		return requested
+	}
+	contracts := []string{}
+	seen := make(map[string]bool)
+	for _, item := range owned {
+		if !seen[item.Contract] {
+			seen[item.Contract] = true
+			contracts = append(contracts, item.Contract)
+		}
+	}
+	return contracts
+}
@@ -0,0 +1,278 @@
+/*
+NFT价格提醒后台检查
+
+每轮检查按合约合并启用的提醒，每个合约最多两次市场查询：
+- 集合提醒：GetMarketStats 的地板价（结果有5分钟缓存）
+- 单个NFT提醒：一次查询合约的挂单，取各 Token 的最低挂单价，没有挂单的NFT本轮跳过
+目标价与当前价币种相同时比较数量，否则按美元价值比较；遇到市场API限流时结束本轮，剩余合约下一轮再查。
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+	"wallet/config"
+	"wallet/core"
+)
+
+// ErrPriceAlertNotFound 价格提醒不存在或不属于该用户
+var ErrPriceAlertNotFound = errors.New("价格提醒不存在")
+
+// nftAlertListingLimit 单个NFT提醒每轮查询的挂单数
+const nftAlertListingLimit = 200
+
+// SetAlertNotifier 设置价格提醒的通知分发器
+func (nms *NFTMarketplaceService) SetAlertNotifier(notifier *AlertNotifier) {
+	nms.mu.Lock()
+	defer nms.mu.Unlock()
+	nms.notifier = notifier
+}
+
+// SetPriceAlertActive 暂停或恢复价格提醒；恢复时重新进入待触发状态，change 类型重新取基准价
+func (nms *NFTMarketplaceService) SetPriceAlertActive(userAddress, alertID string, active bool) (*PriceAlert, error) {
+	nms.mu.Lock()
+	defer nms.mu.Unlock()
+	alert, ok := nms.priceAlerts[alertID]
+	if !ok || !strings.EqualFold(alert.UserAddress, userAddress) {
+		return nil, ErrPriceAlertNotFound
+	}
+	if active && !alert.IsActive {
+		alert.state.armed = true
+		if alert.AlertType == PriceAlertChange {
+			alert.BasePrice = nil
+		}
+	}
+	alert.IsActive = active
+	snapshot := *alert
+	return &snapshot, nil
+}
+
+// GetPriceAlertHistory 用户NFT价格提醒的触发记录（新的在前）
+func (nms *NFTMarketplaceService) GetPriceAlertHistory(userAddress string) []*PriceAlertTrigger {
+	nms.mu.RLock()
+	notifier := nms.notifier
+	nms.mu.RUnlock()
+	if notifier == nil {
+		return []*PriceAlertTrigger{}
+	}
+	return notifier.History(userAddress, PriceAlertKindNFT)
+}
+
+// StartAlertEvaluator 启动价格提醒后台检查，重复调用时替换之前的检查
+func (nms *NFTMarketplaceService) StartAlertEvaluator(cfg config.PriceAlertConfig) {
+	cfg = cfg.WithDefaults()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	nms.mu.Lock()
+	if nms.stopAlerts != nil {
+		nms.stopAlerts()
+	}
+	nms.stopAlerts = cancel
+	nms.mu.Unlock()
+
+	go runAlertLoop(ctx, time.Duration(cfg.IntervalSeconds)*time.Second, nms.evaluatePriceAlerts)
+}
+
+// evaluatePriceAlerts 检查一轮全部启用的提醒
+func (nms *NFTMarketplaceService) evaluatePriceAlerts(ctx context.Context) {
+	nms.mu.RLock()
+	byContract := make(map[string][]*PriceAlert)
+	var contracts []string
+	for _, alert := range nms.priceAlerts {
+		if !alert.IsActive {
+			continue
+		}
+		key := strings.ToLower(alert.Contract)
+		if _, ok := byContract[key]; !ok {
+			contracts = append(contracts, key)
+		}
+		byContract[key] = append(byContract[key], alert)
+	}
+	nms.mu.RUnlock()
+
+	for _, contract := range contracts {
+		prices, err := nms.alertPrices(ctx, contract, byContract[contract])
+		if err != nil {
+			slog.DebugContext(ctx, "nft alert price lookup failed", "contract", contract, "error", err)
+			if errors.Is(err, core.ErrRateLimited) || ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		for _, alert := range byContract[contract] {
+			if price := prices[alert.TokenID]; price != nil {
+				nms.checkPriceAlert(ctx, alert, price)
+			}
+		}
+	}
+}
+
+// alertPrices 查询合约下提醒所需的价格：键为 TokenID，集合地板价的键为空字符串
+func (nms *NFTMarketplaceService) alertPrices(ctx context.Context, contract string, alerts []*PriceAlert) (map[string]*core.MarketPrice, error) {
+	needFloor := false
+	tokens := make(map[string]bool)
+	for _, alert := range alerts {
+		if alert.TokenID == "" {
+			needFloor = true
+		} else {
+			tokens[alert.TokenID] = true
+		}
+	}
+
+	prices := make(map[string]*core.MarketPrice)
+	if needFloor {
+		stats, err := nms.marketplace.GetMarketStats(ctx, contract, "")
+		if err != nil {
+			return nil, err
+		}
+		if stats.FloorPrice != nil && stats.FloorPrice.Amount != nil && stats.FloorPrice.Amount.Sign() > 0 {
+			floor := *stats.FloorPrice
+			prices[""] = &floor
+		}
+	}
+	if len(tokens) > 0 {
+		listings, err := nms.marketplace.GetMarketListings(ctx, &core.MarketListingRequest{Contract: contract, Limit: nftAlertListingLimit})
+		if err != nil {
+			return nil, err
+		}
+		for _, listing := range listings {
+			if !tokens[listing.TokenID] || listing.Price == nil || listing.Price.Amount == nil {
+				continue
+			}
+			price := *listing.Price
+			nms.fillUSDValues(ctx, &price)
+			if lowest := prices[listing.TokenID]; lowest == nil || nms.lowerPrice(ctx, &price, lowest) {
+				prices[listing.TokenID] = &price
+			}
+		}
+	}
+	for _, price := range prices {
+		if price.USDValue == 0 {
+			nms.fillUSDValues(ctx, price)
+		}
+	}
+	return prices, nil
+}
+
+// lowerPrice a 是否低于 b
+func (nms *NFTMarketplaceService) lowerPrice(ctx context.Context, a, b *core.MarketPrice) bool {
+	av, bv, ok := nms.comparablePrices(ctx, a, b)
+	return ok && av < bv
+}
+
+// comparablePrices 换算为可比较的数值：币种相同（或参考价未指定币种）时比较数量，否则比较美元价值
+func (nms *NFTMarketplaceService) comparablePrices(ctx context.Context, current, reference *core.MarketPrice) (float64, float64, bool) {
+	if reference.Currency == "" || strings.EqualFold(current.Currency, reference.Currency) {
+		cur, _ := new(big.Float).SetInt(current.Amount).Float64()
+		ref, _ := new(big.Float).SetInt(reference.Amount).Float64()
+		return cur, ref, true
+	}
+	ref := *reference
+	ref.USDValue = 0
+	nms.fillUSDValues(ctx, &ref)
+	if current.USDValue == 0 || ref.USDValue == 0 {
+		return 0, 0, false
+	}
+	return current.USDValue, ref.USDValue, true
+}
+
+// checkPriceAlert 用当前价格检查提醒，触发时更新状态并分发通知
+func (nms *NFTMarketplaceService) checkPriceAlert(ctx context.Context, alert *PriceAlert, price *core.MarketPrice) {
+	nms.mu.RLock()
+	alertType, changePercent := alert.AlertType, alert.ChangePercent
+	reference := alert.TargetPrice
+	if alertType == PriceAlertChange {
+		reference = alert.BasePrice
+	}
+	nms.mu.RUnlock()
+
+	met, change := false, 0.0
+	comparable := reference != nil && reference.Amount != nil
+	if comparable {
+		var cur, ref float64
+		if cur, ref, comparable = nms.comparablePrices(ctx, price, reference); comparable {
+			met, change = alertConditionMet(alertType, cur, ref, ref, changePercent)
+		}
+	}
+
+	now := time.Now()
+	nms.mu.Lock()
+	if !alert.IsActive {
+		nms.mu.Unlock()
+		return
+	}
+	alert.LastPrice = price
+	alert.LastCheckedAt = &now
+	if alertType == PriceAlertChange && alert.BasePrice == nil {
+		alert.BasePrice = price
+	}
+	if !comparable || !alert.state.shouldTrigger(met) {
+		nms.mu.Unlock()
+		return
+	}
+	alert.TriggeredAt = &now
+	alert.TriggerCount++
+	if !alert.Repeat {
+		alert.IsActive = false
+	}
+	if alertType == PriceAlertChange {
+		alert.BasePrice = price
+		alert.state.armed = true
+	}
+	trigger := &PriceAlertTrigger{
+		AlertID:       alert.ID,
+		Kind:          PriceAlertKindNFT,
+		UserAddress:   alert.UserAddress,
+		AlertType:     alertType,
+		Contract:      alert.Contract,
+		TokenID:       alert.TokenID,
+		Price:         price,
+		TargetPrice:   alert.TargetPrice,
+		ChangePercent: change,
+		Message:       nftAlertMessage(alert, price, change),
+		TriggeredAt:   now,
+	}
+	notifier := nms.notifier
+	nms.mu.Unlock()
+
+	if notifier != nil {
+		notifier.Dispatch(ctx, trigger)
+	}
+}
+
+// nftAlertMessage 通知文本
+func nftAlertMessage(alert *PriceAlert, price *core.MarketPrice, change float64) string {
+	subject := "集合 " + alert.Contract + " 地板价"
+	if alert.TokenID != "" {
+		subject = fmt.Sprintf("NFT %s #%s 最低挂单价", alert.Contract, alert.TokenID)
+	}
+	current := formatMarketPrice(price)
+	switch alert.AlertType {
+	case PriceAlertAbove:
+		return fmt.Sprintf("%s %s，已高于目标价 %s", subject, current, formatMarketPrice(alert.TargetPrice))
+	case PriceAlertBelow:
+		return fmt.Sprintf("%s %s，已低于目标价 %s", subject, current, formatMarketPrice(alert.TargetPrice))
+	}
+	return fmt.Sprintf("%s %s，较基准价变化 %+.2f%%", subject, current, change)
+}
+
+// formatMarketPrice 按小数位格式化价格，如 "1.5 ETH"
+func formatMarketPrice(price *core.MarketPrice) string {
+	if price == nil || price.Amount == nil {
+		return "-"
+	}
+	decimals := price.Decimals
+	if decimals == 0 {
+		decimals = 18
+	}
+	currency := price.Currency
+	if currency == "" {
+		currency = price.Symbol
+	}
+	return strings.TrimSpace(core.FormatUnits(price.Amount, decimals) + " " + currency)
+}
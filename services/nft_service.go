@@ -58,6 +58,7 @@ type NFTService struct {
 	userPortfolios map[string]*UserPortfolio  // 用户投资组合
 	marketData     map[string]*MarketTrend    // 市场趋势数据
 	hotCollections []*HotCollection           // 热门集合
+	ownedCache     map[string]*ownedNFTsEntry // 地址持有列表缓存（分页复用）
 	mu             sync.RWMutex               // 读写锁
 	lastUpdate     time.Time                  // 最后更新时间
 }
@@ -220,6 +221,7 @@ func NewNFTService(multiChain *core.MultiChainManager) (*NFTService, error) {
 		userPortfolios: make(map[string]*UserPortfolio),
 		marketData:     make(map[string]*MarketTrend),
 		hotCollections: make([]*HotCollection, 0),
+		ownedCache:     make(map[string]*ownedNFTsEntry),
 		lastUpdate:     time.Now(),
 	}
 
@@ -0,0 +1,674 @@
+/*
+通知投递服务
+
+价格提醒、多签审批、到账等事件统一经 Send 投递给接收地址：
+- 写入用户收件箱（models.Notification），GET /api/v1/notifications 查询、标记已读
+- 按用户的渠道设置（models.NotificationSetting）投递，每个渠道记录一条投递状态（models.NotificationDelivery）：
+  - push：经 PushProvider 推送，默认推送到订阅了该地址的实时连接（notification 事件），接入 FCM/APNs 时替换 PushProvider 即可
+  - email：通过 notifications.smtp_* 配置的邮件服务器发送，未配置时跳过
+  - webhook：POST 通知 JSON，X-Wallet-Signature 为 "sha256=" + hex(HMAC-SHA256(密钥, 时间戳 + "." + 请求体))；
+    失败后从 webhook_backoff_sec 起指数退避重试，最多 webhook_max_attempts 次，4xx（408/429 除外）不再重试；
+    回调地址设置时须解析到公网地址，投递时在连接前再次校验实际连接的 IP（防 DNS 重绑定），不经过代理
+
+- 未设置渠道的用户默认只推送
+
+数据库未初始化时不写收件箱，仍投递到各渠道。
+*/
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"wallet/config"
+	"wallet/core"
+	"wallet/database"
+	"wallet/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+)
+
+// 通知类型
+const (
+	NotificationTypePriceAlert       = "price_alert"       // 价格提醒触发
+	NotificationTypeMultiSigApproval = "multisig_approval" // 多签交易待签名/可执行
+	NotificationTypeIncomingTransfer = "incoming_transfer" // 原生代币到账
+)
+
+// 通知优先级
+const (
+	NotificationPriorityNormal = "normal"
+	NotificationPriorityHigh   = "high"
+)
+
+// 通知渠道
+const (
+	NotificationChannelPush    = "push"
+	NotificationChannelEmail   = "email"
+	NotificationChannelWebhook = "webhook"
+)
+
+// 投递状态
+const (
+	DeliveryStatusPending = "pending" // 待投递或等待重试
+	DeliveryStatusSent    = "sent"
+	DeliveryStatusFailed  = "failed"
+)
+
+const (
+	maxNotificationPageSize = 100
+	webhookSecretBytes      = 32
+	maxDeliveryErrorLength  = 500
+)
+
+// 通知错误
+var (
+	ErrNotificationNotFound      = errors.New("通知不存在")
+	ErrNoPushReceiver            = errors.New("没有在线的推送连接")
+	errNotificationDBUnavailable = errors.New("数据库未初始化")
+)
+
+// PushProvider 推送渠道
+type PushProvider interface {
+	Push(ctx context.Context, userAddress string, notification *core.Notification) error
+}
+
+// RealtimePushProvider 推送到订阅了用户地址的实时（WebSocket）连接
+type RealtimePushProvider struct {
+	realtime *RealtimeService
+}
+
+// NewRealtimePushProvider 创建实时连接推送渠道
+func NewRealtimePushProvider(realtime *RealtimeService) *RealtimePushProvider {
+	return &RealtimePushProvider{realtime: realtime}
+}
+
+// Push 推送 notification 事件，没有在线连接时返回 ErrNoPushReceiver
+func (p *RealtimePushProvider) Push(ctx context.Context, userAddress string, notification *core.Notification) error {
+	delivered := p.realtime.NotifyAddress(userAddress, &RealtimeEvent{
+		Type:         RealtimeEventNotification,
+		Address:      userAddress,
+		Notification: notification,
+		Timestamp:    notification.CreatedAt,
+	})
+	if delivered == 0 {
+		return ErrNoPushReceiver
+	}
+	return nil
+}
+
+// NotificationDeliveryInfo 通知在单个渠道的投递状态
+type NotificationDeliveryInfo struct {
+	Channel     string     `json:"channel"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	LastError   string     `json:"last_error,omitempty"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+}
+
+// InboxNotification 收件箱中的通知
+type InboxNotification struct {
+	core.Notification
+	UserAddress string                     `json:"user_address"`
+	ReadAt      *time.Time                 `json:"read_at,omitempty"`
+	Deliveries  []NotificationDeliveryInfo `json:"deliveries,omitempty"` // 仅详情与 Send 返回
+}
+
+// NotificationPage 收件箱分页结果
+type NotificationPage struct {
+	Items  []*InboxNotification `json:"items"`
+	Total  int64                `json:"total"`
+	Unread int64                `json:"unread"` // 全部未读数（不受筛选条件影响）
+	Offset int                  `json:"offset"`
+	Limit  int                  `json:"limit"`
+}
+
+// NotificationChannelSettings 用户的通知渠道设置
+type NotificationChannelSettings struct {
+	PushEnabled   bool   `json:"push_enabled"`
+	Email         string `json:"email"`
+	WebhookURL    string `json:"webhook_url"`
+	WebhookSecret string `json:"webhook_secret,omitempty"` // 校验 X-Wallet-Signature 用，只返回给本人
+}
+
+// NotificationSettingsUpdate 渠道设置的修改，nil 字段保持不变，空字符串表示关闭该渠道
+type NotificationSettingsUpdate struct {
+	PushEnabled  *bool
+	Email        *string
+	WebhookURL   *string
+	RotateSecret bool // 重新生成 Webhook 签名密钥
+}
+
+// NotificationService 通知投递服务
+type NotificationService struct {
+	cfg        config.NotificationConfig
+	httpClient *http.Client
+	push       PushProvider
+	mu         sync.RWMutex
+}
+
+// NewNotificationService 创建通知投递服务，push 为 nil 时不推送
+func NewNotificationService(cfg config.NotificationConfig, push PushProvider) *NotificationService {
+	cfg = cfg.WithDefaults()
+	return &NotificationService{
+		cfg: cfg,
+		httpClient: &http.Client{
+			Timeout:   time.Duration(cfg.WebhookTimeoutSec) * time.Second,
+			Transport: core.NewSafeTransport(),
+			// 不跟随重定向，回调地址只能是用户设置的地址
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		push: push,
+	}
+}
+
+// SetPushProvider 替换推送渠道
+func (ns *NotificationService) SetPushProvider(push PushProvider) {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+	ns.push = push
+}
+
+// Send 写入用户收件箱并按渠道设置投递；推送同步完成，邮件与 Webhook 异步发送
+// 返回的通知带有各渠道的初始投递状态
+func (ns *NotificationService) Send(ctx context.Context, userAddress string, n core.Notification) (*InboxNotification, error) {
+	if !common.IsHexAddress(userAddress) {
+		return nil, fmt.Errorf("无效的用户地址: %s", userAddress)
+	}
+	if n.Type == "" {
+		return nil, errors.New("通知类型不能为空")
+	}
+	owner := strings.ToLower(userAddress)
+	if n.Priority == "" {
+		n.Priority = NotificationPriorityNormal
+	}
+	n.IsRead = false
+	n.CreatedAt = time.Now()
+
+	settings := ns.channelSettings(owner)
+	ns.mu.RLock()
+	push := ns.push
+	ns.mu.RUnlock()
+
+	var channels []string
+	if settings.PushEnabled && push != nil {
+		channels = append(channels, NotificationChannelPush)
+	}
+	if settings.Email != "" && ns.cfg.SMTPHost != "" {
+		channels = append(channels, NotificationChannelEmail)
+	}
+	if settings.WebhookURL != "" {
+		channels = append(channels, NotificationChannelWebhook)
+	}
+
+	inbox := &InboxNotification{Notification: n, UserAddress: owner}
+	deliveries := ns.store(inbox, channels)
+
+	// 推送前先拷贝，异步渠道只读取 payload，不与调用方共享 inbox
+	payload := *inbox
+	for _, delivery := range deliveries {
+		if delivery.Channel != NotificationChannelPush {
+			continue
+		}
+		delivery.Attempts = 1
+		ns.finishDelivery(delivery, push.Push(ctx, owner, &payload.Notification))
+	}
+	for _, delivery := range deliveries {
+		inbox.Deliveries = append(inbox.Deliveries, toDeliveryInfo(delivery))
+	}
+
+	for _, delivery := range deliveries {
+		switch delivery.Channel {
+		case NotificationChannelEmail:
+			go ns.deliverEmail(settings.Email, &payload, delivery)
+		case NotificationChannelWebhook:
+			go ns.deliverWebhook(settings.WebhookURL, settings.WebhookSecret, &payload, delivery)
+		}
+	}
+	return inbox, nil
+}
+
+// store 写入收件箱与各渠道的待投递记录，数据库不可用时只生成内存中的记录
+func (ns *NotificationService) store(inbox *InboxNotification, channels []string) []*models.NotificationDelivery {
+	deliveries := make([]*models.NotificationDelivery, len(channels))
+	for i, channel := range channels {
+		deliveries[i] = &models.NotificationDelivery{Channel: channel, Status: DeliveryStatusPending}
+	}
+	if database.DB == nil {
+		inbox.ID = randomNotificationID()
+		return deliveries
+	}
+
+	record := models.Notification{
+		OwnerAddress: inbox.UserAddress,
+		Type:         inbox.Type,
+		Title:        inbox.Title,
+		Message:      inbox.Message,
+		Data:         models.JSON(inbox.Data),
+		Priority:     inbox.Priority,
+		ExpiresAt:    inbox.ExpiresAt,
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&record).Error; err != nil {
+			return err
+		}
+		for _, delivery := range deliveries {
+			delivery.NotificationID = record.ID
+			if err := tx.Create(delivery).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		slog.DebugContext(context.Background(), "notification inbox write failed", "owner", inbox.UserAddress, "error", err)
+		inbox.ID = randomNotificationID()
+		for _, delivery := range deliveries {
+			delivery.ID = 0
+		}
+		return deliveries
+	}
+	inbox.ID = strconv.FormatUint(uint64(record.ID), 10)
+	inbox.CreatedAt = record.CreatedAt
+	return deliveries
+}
+
+// finishDelivery 记录一次投递结果：成功为 sent，失败为 failed
+func (ns *NotificationService) finishDelivery(delivery *models.NotificationDelivery, err error) {
+	if err == nil {
+		now := time.Now()
+		delivery.Status = DeliveryStatusSent
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	} else {
+		delivery.Status = DeliveryStatusFailed
+		delivery.LastError = truncateDeliveryError(err)
+	}
+	ns.saveDelivery(delivery)
+}
+
+// saveDelivery 持久化投递状态（没有对应数据库记录时忽略）
+func (ns *NotificationService) saveDelivery(delivery *models.NotificationDelivery) {
+	if database.DB == nil || delivery.ID == 0 {
+		return
+	}
+	err := database.DB.Model(&models.NotificationDelivery{}).Where("id = ?", delivery.ID).Updates(map[string]interface{}{
+		"status":       delivery.Status,
+		"attempts":     delivery.Attempts,
+		"last_error":   delivery.LastError,
+		"delivered_at": delivery.DeliveredAt,
+	}).Error
+	if err != nil {
+		slog.DebugContext(context.Background(), "notification delivery update failed", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+// deliverWebhook POST 签名后的通知，失败时指数退避重试
+func (ns *NotificationService) deliverWebhook(webhookURL, secret string, n *InboxNotification, delivery *models.NotificationDelivery) {
+	body, err := json.Marshal(n)
+	if err != nil {
+		delivery.Attempts = 1
+		ns.finishDelivery(delivery, err)
+		return
+	}
+	wait := time.Duration(ns.cfg.WebhookBackoffSec) * time.Second
+	for attempt := 1; ; attempt++ {
+		delivery.Attempts = attempt
+		retryable, err := ns.postWebhook(webhookURL, secret, n.Type, delivery.ID, body)
+		if err == nil {
+			ns.finishDelivery(delivery, nil)
+			return
+		}
+		if !retryable || attempt >= ns.cfg.WebhookMaxAttempts {
+			slog.DebugContext(context.Background(), "notification webhook delivery failed", "notification_id", n.ID, "attempts", attempt, "error", err)
+			ns.finishDelivery(delivery, err)
+			return
+		}
+		delivery.LastError = truncateDeliveryError(err)
+		ns.saveDelivery(delivery)
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// postWebhook 发送一次回调请求，返回失败是否值得重试
+// 回调地址在投递时可能已被重新解析到内网，由 core.NewSafeTransport 在连接前拒绝（不重试）
+func (ns *NotificationService) postWebhook(webhookURL, secret, eventType string, deliveryID uint, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Wallet-Event", eventType)
+	req.Header.Set("X-Wallet-Delivery", strconv.FormatUint(uint64(deliveryID), 10))
+	req.Header.Set("X-Wallet-Timestamp", timestamp)
+	req.Header.Set("X-Wallet-Signature", "sha256="+SignWebhookPayload(secret, timestamp, body))
+
+	resp, err := ns.httpClient.Do(req)
+	if err != nil {
+		return !errors.Is(err, core.ErrUnsafeOutboundAddress), err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return true, nil
+	}
+	retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests
+	return retryable, fmt.Errorf("HTTP %d", resp.StatusCode)
+}
+
+// SignWebhookPayload 计算 Webhook 签名：hex(HMAC-SHA256(secret, timestamp + "." + body))
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliverEmail 通过配置的SMTP服务器发送通知邮件
+func (ns *NotificationService) deliverEmail(to string, n *InboxNotification, delivery *models.NotificationDelivery) {
+	delivery.Attempts = 1
+	// 标题来自事件内容，去掉换行防止邮件头注入（收件人在设置时已校验）
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace(n.Title)
+	if subject == "" {
+		subject = "钱包通知"
+	}
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\n", ns.cfg.EmailFrom)
+	fmt.Fprintf(&body, "To: %s\r\n", to)
+	fmt.Fprintf(&body, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", subject))
+	body.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+	body.WriteString(n.Message + "\r\n")
+	fmt.Fprintf(&body, "\r\n通知ID: %s\r\n时间: %s\r\n", n.ID, n.CreatedAt.Format(time.RFC3339))
+
+	var auth smtp.Auth
+	if ns.cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", ns.cfg.SMTPUsername, ns.cfg.SMTPPassword, ns.cfg.SMTPHost)
+	}
+	addr := ns.cfg.SMTPHost + ":" + strconv.Itoa(ns.cfg.SMTPPort)
+	err := smtp.SendMail(addr, auth, ns.cfg.EmailFrom, []string{to}, []byte(body.String()))
+	if err != nil {
+		slog.DebugContext(context.Background(), "notification email delivery failed", "notification_id", n.ID, "error", err)
+	}
+	ns.finishDelivery(delivery, err)
+}
+
+// channelSettings 用户的渠道设置，未设置或数据库不可用时只推送
+func (ns *NotificationService) channelSettings(owner string) NotificationChannelSettings {
+	settings := NotificationChannelSettings{PushEnabled: true}
+	if database.DB == nil {
+		return settings
+	}
+	var record models.NotificationSetting
+	err := database.DB.Where("owner_address = ?", owner).First(&record).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.DebugContext(context.Background(), "notification settings lookup failed", "owner", owner, "error", err)
+		}
+		return settings
+	}
+	return toChannelSettings(&record)
+}
+
+// GetSettings 查询用户的通知渠道设置
+func (ns *NotificationService) GetSettings(owner string) (*NotificationChannelSettings, error) {
+	if database.DB == nil {
+		return nil, errNotificationDBUnavailable
+	}
+	settings := ns.channelSettings(strings.ToLower(owner))
+	return &settings, nil
+}
+
+// UpdateSettings 修改用户的通知渠道设置；首次设置 Webhook 时生成签名密钥，关闭 Webhook 时清除
+func (ns *NotificationService) UpdateSettings(owner string, update NotificationSettingsUpdate) (*NotificationChannelSettings, error) {
+	if database.DB == nil {
+		return nil, errNotificationDBUnavailable
+	}
+	owner = strings.ToLower(owner)
+	var record models.NotificationSetting
+	err := database.DB.Where("owner_address = ?", owner).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		record = models.NotificationSetting{OwnerAddress: owner, PushEnabled: true}
+	} else if err != nil {
+		return nil, fmt.Errorf("查询通知设置失败: %w", err)
+	}
+
+	if update.PushEnabled != nil {
+		record.PushEnabled = *update.PushEnabled
+	}
+	if update.Email != nil {
+		email, err := normalizeNotificationEmail(*update.Email)
+		if err != nil {
+			return nil, err
+		}
+		record.Email = email
+	}
+	if update.WebhookURL != nil {
+		webhookURL, err := normalizeWebhookURL(*update.WebhookURL)
+		if err != nil {
+			return nil, err
+		}
+		record.WebhookURL = webhookURL
+	}
+	switch {
+	case record.WebhookURL == "":
+		record.WebhookSecret = ""
+	case record.WebhookSecret == "" || update.RotateSecret:
+		secret := make([]byte, webhookSecretBytes)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("生成签名密钥失败: %w", err)
+		}
+		record.WebhookSecret = hex.EncodeToString(secret)
+	}
+
+	if err := database.DB.Save(&record).Error; err != nil {
+		return nil, fmt.Errorf("保存通知设置失败: %w", err)
+	}
+	settings := toChannelSettings(&record)
+	return &settings, nil
+}
+
+// ListNotifications 分页查询收件箱（新的在前），已过期的通知不返回
+func (ns *NotificationService) ListNotifications(owner string, unreadOnly bool, notificationType string, offset, limit int) (*NotificationPage, error) {
+	if database.DB == nil {
+		return nil, errNotificationDBUnavailable
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || limit > maxNotificationPageSize {
+		limit = maxNotificationPageSize
+	}
+	base := ns.inboxQuery(strings.ToLower(owner))
+
+	page := &NotificationPage{Items: []*InboxNotification{}, Offset: offset, Limit: limit}
+	if err := base.Session(&gorm.Session{}).Where("is_read = ?", false).Count(&page.Unread).Error; err != nil {
+		return nil, fmt.Errorf("查询未读通知数失败: %w", err)
+	}
+	query := base.Session(&gorm.Session{})
+	if unreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+	if notificationType != "" {
+		query = query.Where("type = ?", notificationType)
+	}
+	if err := query.Session(&gorm.Session{}).Count(&page.Total).Error; err != nil {
+		return nil, fmt.Errorf("查询通知失败: %w", err)
+	}
+	var records []models.Notification
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("查询通知失败: %w", err)
+	}
+	for i := range records {
+		page.Items = append(page.Items, toInboxNotification(&records[i]))
+	}
+	return page, nil
+}
+
+// GetNotification 查询单条通知及其各渠道的投递状态
+func (ns *NotificationService) GetNotification(owner string, id uint) (*InboxNotification, error) {
+	if database.DB == nil {
+		return nil, errNotificationDBUnavailable
+	}
+	var record models.Notification
+	err := ns.inboxQuery(strings.ToLower(owner)).Where("id = ?", id).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotificationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询通知失败: %w", err)
+	}
+	var deliveries []models.NotificationDelivery
+	if err := database.DB.Where("notification_id = ?", record.ID).Order("id").Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("查询投递记录失败: %w", err)
+	}
+	notification := toInboxNotification(&record)
+	for i := range deliveries {
+		notification.Deliveries = append(notification.Deliveries, toDeliveryInfo(&deliveries[i]))
+	}
+	return notification, nil
+}
+
+// MarkRead 标记单条通知为已读
+func (ns *NotificationService) MarkRead(owner string, id uint) error {
+	if database.DB == nil {
+		return errNotificationDBUnavailable
+	}
+	owner = strings.ToLower(owner)
+	var record models.Notification
+	err := ns.inboxQuery(owner).Where("id = ?", id).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotificationNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("查询通知失败: %w", err)
+	}
+	if record.IsRead {
+		return nil
+	}
+	err = database.DB.Model(&models.Notification{}).Where("id = ? AND owner_address = ?", id, owner).
+		Updates(map[string]interface{}{"is_read": true, "read_at": time.Now()}).Error
+	if err != nil {
+		return fmt.Errorf("标记已读失败: %w", err)
+	}
+	return nil
+}
+
+// MarkAllRead 标记用户全部未读通知为已读，返回标记的数量
+func (ns *NotificationService) MarkAllRead(owner string) (int64, error) {
+	if database.DB == nil {
+		return 0, errNotificationDBUnavailable
+	}
+	result := database.DB.Model(&models.Notification{}).
+		Where("owner_address = ? AND is_read = ?", strings.ToLower(owner), false).
+		Updates(map[string]interface{}{"is_read": true, "read_at": time.Now()})
+	if result.Error != nil {
+		return 0, fmt.Errorf("标记已读失败: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// inboxQuery 用户收件箱中未过期的通知
+func (ns *NotificationService) inboxQuery(owner string) *gorm.DB {
+	return database.DB.Model(&models.Notification{}).
+		Where("owner_address = ? AND (expires_at IS NULL OR expires_at > ?)", owner, time.Now())
+}
+
+func toInboxNotification(record *models.Notification) *InboxNotification {
+	return &InboxNotification{
+		Notification: core.Notification{
+			ID:        strconv.FormatUint(uint64(record.ID), 10),
+			Type:      record.Type,
+			Title:     record.Title,
+			Message:   record.Message,
+			Data:      map[string]interface{}(record.Data),
+			IsRead:    record.IsRead,
+			Priority:  record.Priority,
+			ExpiresAt: record.ExpiresAt,
+			CreatedAt: record.CreatedAt,
+		},
+		UserAddress: record.OwnerAddress,
+		ReadAt:      record.ReadAt,
+	}
+}
+
+func toDeliveryInfo(delivery *models.NotificationDelivery) NotificationDeliveryInfo {
+	return NotificationDeliveryInfo{
+		Channel:     delivery.Channel,
+		Status:      delivery.Status,
+		Attempts:    delivery.Attempts,
+		LastError:   delivery.LastError,
+		DeliveredAt: delivery.DeliveredAt,
+	}
+}
+
+func toChannelSettings(record *models.NotificationSetting) NotificationChannelSettings {
+	return NotificationChannelSettings{
+		PushEnabled:   record.PushEnabled,
+		Email:         record.Email,
+		WebhookURL:    record.WebhookURL,
+		WebhookSecret: record.WebhookSecret,
+	}
+}
+
+// normalizeNotificationEmail 校验邮件地址，空字符串表示关闭邮件通知
+func normalizeNotificationEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return "", nil
+	}
+	parsed, err := mail.ParseAddress(email)
+	if err != nil || strings.ContainsAny(parsed.Address, "\r\n") {
+		return "", fmt.Errorf("无效的邮件地址: %s", email)
+	}
+	return parsed.Address, nil
+}
+
+// normalizeWebhookURL 校验回调地址（http/https，须解析到公网地址），空字符串表示关闭 Webhook
+func normalizeWebhookURL(webhookURL string) (string, error) {
+	webhookURL = strings.TrimSpace(webhookURL)
+	if webhookURL == "" {
+		return "", nil
+	}
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("无效的 Webhook 地址: %s", webhookURL)
+	}
+	if err := core.CheckOutboundHost(context.Background(), parsed.Hostname(), nil); err != nil {
+		return "", fmt.Errorf("无效的 Webhook 地址: %w", err)
+	}
+	return parsed.String(), nil
+}
+
+func truncateDeliveryError(err error) string {
+	msg := err.Error()
+	if len(msg) > maxDeliveryErrorLength {
+		msg = strings.ToValidUTF8(msg[:maxDeliveryErrorLength], "")
+	}
+	return msg
+}
+
+// randomNotificationID 未写入收件箱的通知使用随机ID
+func randomNotificationID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
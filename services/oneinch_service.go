@@ -10,6 +10,7 @@
 1inch API端点：
 - Quote: https://api.1inch.dev/swap/v5.2/{chain_id}/quote
 - Swap: https://api.1inch.dev/swap/v5.2/{chain_id}/swap
+- Approve spender: https://api.1inch.dev/swap/v5.2/{chain_id}/approve/spender
 - Tokens: https://api.1inch.dev/token/v1.2/{chain_id}
 - Liquidity sources: https://api.1inch.dev/swap/v5.2/{chain_id}/liquidity-sources
 */
@@ -27,6 +28,12 @@ import (
 	"time"
 )
 
+// oneInchRouters 已知的1inch聚合路由合约（各EVM链地址相同）
+var oneInchRouters = []string{
+	"0x1111111254EEB25477B68fb85Ed929f73A960582", // Aggregation Router V5（swap API v5.x）
+	"0x111111125421cA6dc452d289314280a0f8842A65", // Aggregation Router V6（swap API v6.x）
+}
+
 // OneInchService 1inch聚合器服务
 type OneInchService struct {
 	apiKey     string
@@ -241,6 +248,41 @@ func (s *OneInchService) GetSwap(ctx context.Context, req *OneInchSwapRequest) (
 	return &swapResp, nil
 }
 
+// GetSpender 获取需要授权的1inch路由合约地址
+func (s *OneInchService) GetSpender(ctx context.Context) (string, error) {
+	url := fmt.Sprintf("%s/swap/v5.2/%d/approve/spender", s.baseURL, s.chainID)
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.apiKey))
+
+	resp, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var spender struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(body, &spender); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	if spender.Address == "" {
+		return "", fmt.Errorf("empty spender address")
+	}
+	return spender.Address, nil
+}
+
 // GetTokens 获取代币列表
 func (s *OneInchService) GetTokens(ctx context.Context) (*TokenListResponse, error) {
 	// 构建请求URL
@@ -0,0 +1,238 @@
+/*
+待确认交易跟踪服务
+
+发送接口只返回交易哈希，节点丢弃交易后用户无从得知。
+本文件登记所有发送接口广播的交易，后台定期轮询回执：
+  - 有回执：执行失败标记为 failed；执行成功且确认数达到网络要求（NetworkInfo.required_confirmations）标记为 confirmed，
+    未达到前为 mined 并继续跟踪确认数，期间回执消失（重组）则回到 pending
+  - 提交超过 drop_timeout 仍查不到交易，且发送地址的 nonce 已被使用：标记为 dropped
+  - 未能获取 nonce 的交易（节点从未见过该交易）超时后同样标记为 dropped
+  - 超过保留时长的记录自动清理
+
+轮询间隔、丢弃判定时长与保留时长见 config.PendingTxConfig。
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"wallet/config"
+	"wallet/core"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// 待确认交易状态
+const (
+	PendingStatusPending   = "pending"   // 等待打包
+	PendingStatusMined     = "mined"     // 已打包且执行成功，确认数未达到网络要求
+	PendingStatusConfirmed = "confirmed" // 已打包且执行成功，确认数达到网络要求
+	PendingStatusFailed    = "failed"    // 已打包但执行失败
+	PendingStatusDropped   = "dropped"   // 被节点丢弃或被同 nonce 交易顶替
+)
+
+// PendingTx 待确认交易跟踪记录
+type PendingTx struct {
+	TxHash        string    `json:"tx_hash"`                // 交易哈希
+	Network       string    `json:"network"`                // 所在网络
+	From          string    `json:"from"`                   // 发送地址
+	Nonce         *uint64   `json:"nonce,omitempty"`        // 交易nonce，节点尚未返回交易时为空
+	Status        string    `json:"status"`                 // 当前状态
+	BlockNumber   uint64    `json:"block_number,omitempty"` // 打包区块（mined/confirmed/failed）
+	Confirmations uint64    `json:"confirmations"`          // 打包区块之后的出块数
+	Required      uint64    `json:"required_confirmations"` // 网络要求的确认数
+	SubmittedAt   time.Time `json:"submitted_at"`           // 提交时间
+	UpdatedAt     time.Time `json:"updated_at"`             // 最近状态更新时间
+}
+
+// PendingTxTracker 待确认交易跟踪器
+type PendingTxTracker struct {
+	multiChain *core.MultiChainManager
+	cfg        config.PendingTxConfig
+	txs        map[string]*PendingTx // key: 小写交易哈希
+	mu         sync.Mutex
+}
+
+// NewPendingTxTracker 创建跟踪器并启动后台轮询
+func NewPendingTxTracker(multiChain *core.MultiChainManager, cfg config.PendingTxConfig) *PendingTxTracker {
+	t := &PendingTxTracker{
+		multiChain: multiChain,
+		cfg:        cfg.WithDefaults(),
+		txs:        make(map[string]*PendingTx),
+	}
+	go t.loop()
+	return t
+}
+
+// Register 登记一笔已广播的交易；不访问节点，nonce 由后台轮询补全
+func (t *PendingTxTracker) Register(networkID, from, txHash string) {
+	if txHash == "" {
+		return
+	}
+	now := time.Now()
+	record := &PendingTx{
+		TxHash:      txHash,
+		Network:     networkID,
+		From:        common.HexToAddress(from).Hex(),
+		Status:      PendingStatusPending,
+		SubmittedAt: now,
+		UpdatedAt:   now,
+	}
+
+	t.mu.Lock()
+	t.txs[strings.ToLower(txHash)] = record
+	t.mu.Unlock()
+}
+
+// List 查询地址登记的交易，address 为空时返回全部；按提交时间倒序
+func (t *PendingTxTracker) List(address string) []PendingTx {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([]PendingTx, 0)
+	for _, record := range t.txs {
+		if address != "" && !strings.EqualFold(record.From, address) {
+			continue
+		}
+		out = append(out, *record)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].SubmittedAt.After(out[j].SubmittedAt)
+	})
+	return out
+}
+
+// KnownNonces 返回地址在指定网络上尚未打包、nonce 已知的登记交易（nonce -> 交易哈希），同一 nonce 取最近提交的交易
+func (t *PendingTxTracker) KnownNonces(networkID, address string) map[uint64]string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make(map[uint64]string)
+	submitted := make(map[uint64]time.Time)
+	for _, record := range t.txs {
+		if record.Network != networkID || record.Nonce == nil || record.Status != PendingStatusPending || !strings.EqualFold(record.From, address) {
+			continue
+		}
+		if at, ok := submitted[*record.Nonce]; ok && at.After(record.SubmittedAt) {
+			continue
+		}
+		out[*record.Nonce] = record.TxHash
+		submitted[*record.Nonce] = record.SubmittedAt
+	}
+	return out
+}
+
+// loop 后台轮询
+func (t *PendingTxTracker) loop() {
+	interval := time.Duration(t.cfg.PollIntervalSeconds) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		t.checkAll(interval)
+	}
+}
+
+// checkAll 检查所有待确认交易并清理过期记录
+// 查询节点时不持有锁，避免轮询期间阻塞登记与查询
+func (t *PendingTxTracker) checkAll(timeout time.Duration) {
+	now := time.Now()
+	retention := time.Duration(t.cfg.RetentionHours) * time.Hour
+
+	t.mu.Lock()
+	pending := make(map[string]PendingTx)
+	for key, record := range t.txs {
+		if now.Sub(record.SubmittedAt) > retention {
+			delete(t.txs, key)
+			continue
+		}
+		if record.Status == PendingStatusPending || record.Status == PendingStatusMined {
+			pending[key] = *record
+		}
+	}
+	t.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for key, record := range pending {
+		evmAdapter, err := t.evmAdapter(record.Network)
+		if err != nil {
+			continue
+		}
+		t.check(ctx, evmAdapter, &record, now)
+
+		t.mu.Lock()
+		if current, ok := t.txs[key]; ok {
+			*current = record
+		}
+		t.mu.Unlock()
+	}
+}
+
+// check 查询回执更新状态与确认数，超时未打包时判断是否已被丢弃
+func (t *PendingTxTracker) check(ctx context.Context, evmAdapter *core.EVMAdapter, record *PendingTx, now time.Time) {
+	record.Required = evmAdapter.RequiredConfirmations()
+	if receipt, err := evmAdapter.GetTransactionReceipt(ctx, record.TxHash); err == nil {
+		if receipt.BlockNumber != nil {
+			record.BlockNumber = receipt.BlockNumber.Uint64()
+		}
+		if head, err := evmAdapter.LatestBlockNumber(ctx); err == nil {
+			record.Confirmations = core.Confirmations(head, record.BlockNumber)
+		}
+		status := PendingStatusFailed
+		if receipt.Status == 1 {
+			status = PendingStatusMined
+			if record.Confirmations >= record.Required {
+				status = PendingStatusConfirmed
+			}
+		}
+		if status != record.Status {
+			record.Status = status
+			record.UpdatedAt = now
+		}
+		return
+	}
+	if record.Status == PendingStatusMined {
+		// 已打包的交易查不到回执：所在区块被重组，回到等待打包
+		record.Status = PendingStatusPending
+		record.BlockNumber, record.Confirmations = 0, 0
+		record.UpdatedAt = now
+	}
+
+	tx, _, err := evmAdapter.GetTransactionByHash(ctx, record.TxHash)
+	if err == nil {
+		if record.Nonce == nil {
+			nonce := tx.Nonce()
+			record.Nonce = &nonce
+		}
+		return
+	}
+
+	if now.Sub(record.SubmittedAt) < time.Duration(t.cfg.DropTimeoutMinutes)*time.Minute {
+		return
+	}
+	if record.Nonce != nil {
+		// nonce 仍未被使用时交易可能只是暂时不在该节点的交易池中，继续等待
+		if _, latest, err := evmAdapter.GetNonces(ctx, record.From); err != nil || latest <= *record.Nonce {
+			return
+		}
+	}
+	record.Status = PendingStatusDropped
+	record.UpdatedAt = now
+}
+
+// evmAdapter 获取指定网络的EVM适配器
+func (t *PendingTxTracker) evmAdapter(networkID string) (*core.EVMAdapter, error) {
+	adapter, err := t.multiChain.GetAdapter(networkID)
+	if err != nil {
+		return nil, err
+	}
+	evmAdapter, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		return nil, fmt.Errorf("网络 %s 不支持待确认交易跟踪", networkID)
+	}
+	return evmAdapter, nil
+}
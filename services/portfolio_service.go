@@ -0,0 +1,369 @@
+/*
+跨链资产汇总服务
+
+一次请求汇总多个地址在多个网络上的资产：
+- 原生币余额通过 MultiChainManager.GetCrossChainBalance 查询
+- ERC20 余额与元数据通过 Multicall3 批量查询（未配置 Multicall3 的网络逐个调用）
+- 代币列表可由调用方指定；未指定时从最近的 Transfer 日志自动识别地址持有过的代币
+- 所有资产按 PriceService 的美元价格估值，并汇总每个地址、每个网络以及总计的美元价值
+
+结果按 (地址, 网络, 代币) 组合缓存 CacheTTLSeconds 秒，单个网络查询失败只记录错误，不影响其余网络。
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"wallet/config"
+	"wallet/core"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// portfolioNetworkAliases 常用网络简写 -> 网络ID
+var portfolioNetworkAliases = map[string]string{
+	"eth":   "ethereum",
+	"matic": "polygon",
+	"bnb":   "bsc",
+}
+
+// PortfolioAsset 单个资产持仓
+type PortfolioAsset struct {
+	Token     string  `json:"token"`     // 代币地址（原生币为 NativeTokenAddress）
+	Symbol    string  `json:"symbol"`    // 代币符号
+	Decimals  int     `json:"decimals"`  // 小数位数
+	Balance   string  `json:"balance"`   // 余额（最小单位）
+	Formatted string  `json:"formatted"` // 按小数位格式化的余额
+	PriceUSD  float64 `json:"price_usd"` // 美元价格
+	ValueUSD  float64 `json:"value_usd"` // 美元价值
+	NoPrice   bool    `json:"no_price"`  // 是否缺少价格数据
+}
+
+// AddressHoldings 单个地址在某个网络上的持仓
+type AddressHoldings struct {
+	Address  string            `json:"address"`         // 持有地址
+	Native   *PortfolioAsset   `json:"native"`          // 原生币余额
+	Tokens   []*PortfolioAsset `json:"tokens"`          // 代币余额（仅余额大于0）
+	TotalUSD float64           `json:"total_usd"`       // 美元价值合计
+	Error    string            `json:"error,omitempty"` // 代币查询失败原因
+}
+
+// NetworkPortfolio 单个网络的持仓汇总
+type NetworkPortfolio struct {
+	Network  string             `json:"network"`         // 网络ID
+	ChainID  int                `json:"chain_id"`        // 链ID
+	Holdings []*AddressHoldings `json:"holdings"`        // 各地址持仓
+	TotalUSD float64            `json:"total_usd"`       // 美元价值合计
+	Error    string             `json:"error,omitempty"` // 网络不可用时的错误
+}
+
+// CrossChainPortfolio 跨链资产汇总结果
+type CrossChainPortfolio struct {
+	Addresses []string            `json:"addresses"`  // 查询的地址
+	Networks  []*NetworkPortfolio `json:"networks"`   // 各网络汇总
+	TotalUSD  float64             `json:"total_usd"`  // 美元价值总计
+	UpdatedAt time.Time           `json:"updated_at"` // 数据获取时间
+	Cached    bool                `json:"cached"`     // 是否来自缓存
+}
+
+// portfolioCacheEntry 汇总结果缓存条目
+type portfolioCacheEntry struct {
+	portfolio *CrossChainPortfolio
+	expiresAt time.Time
+}
+
+// PortfolioService 跨链资产汇总服务
+type PortfolioService struct {
+	multiChain   *core.MultiChainManager
+	priceService *PriceService
+	cfg          config.PortfolioConfig
+	cache        map[string]*portfolioCacheEntry
+	mu           sync.RWMutex
+}
+
+// NewPortfolioService 创建跨链资产汇总服务
+func NewPortfolioService(multiChain *core.MultiChainManager, priceService *PriceService, cfg config.PortfolioConfig) *PortfolioService {
+	return &PortfolioService{
+		multiChain:   multiChain,
+		priceService: priceService,
+		cfg:          cfg.WithDefaults(),
+		cache:        make(map[string]*portfolioCacheEntry),
+	}
+}
+
+// GetPortfolio 汇总多个地址在多个网络上的原生币与代币余额及美元价值
+// networks 为空时查询所有可用网络；tokens 为空时按 Transfer 日志自动识别各网络上的代币
+func (ps *PortfolioService) GetPortfolio(ctx context.Context, addresses, networks, tokens []string) (*CrossChainPortfolio, error) {
+	networks = ps.resolveNetworks(networks)
+	var tokensByNetwork map[string][]string
+	if len(tokens) > 0 {
+		tokensByNetwork = make(map[string][]string, len(networks))
+		for _, networkID := range networks {
+			tokensByNetwork[networkID] = tokens
+		}
+	}
+	return ps.GetPortfolioForTokens(ctx, addresses, networks, tokensByNetwork)
+}
+
+// GetPortfolioForTokens 与 GetPortfolio 相同，但按网络分别指定代币列表（如用户自定义代币列表）
+// 未出现在 tokensByNetwork 中的网络按 Transfer 日志自动识别代币
+func (ps *PortfolioService) GetPortfolioForTokens(ctx context.Context, addresses, networks []string, tokensByNetwork map[string][]string) (*CrossChainPortfolio, error) {
+	addresses = uniqueStrings(addresses)
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("至少需要一个地址")
+	}
+	networks = ps.resolveNetworks(networks)
+	if len(networks) == 0 {
+		return nil, fmt.Errorf("没有可用的网络")
+	}
+	for _, tokens := range tokensByNetwork {
+		for _, token := range tokens {
+			if !common.IsHexAddress(token) {
+				return nil, fmt.Errorf("无效的代币地址: %s", token)
+			}
+		}
+	}
+
+	key := portfolioCacheKey(addresses, networks, tokensByNetwork)
+	if cached := ps.getCached(key); cached != nil {
+		return cached, nil
+	}
+
+	result := &CrossChainPortfolio{
+		Addresses: addresses,
+		Networks:  make([]*NetworkPortfolio, len(networks)),
+		UpdatedAt: time.Now(),
+	}
+	var wg sync.WaitGroup
+	for i, networkID := range networks {
+		wg.Add(1)
+		go func(i int, networkID string) {
+			defer wg.Done()
+			result.Networks[i] = ps.networkPortfolio(ctx, networkID, addresses, tokensByNetwork[networkID])
+		}(i, networkID)
+	}
+	wg.Wait()
+
+	for _, network := range result.Networks {
+		result.TotalUSD += network.TotalUSD
+	}
+	ps.setCached(key, result)
+	return result, nil
+}
+
+// networkPortfolio 查询单个网络上所有地址的持仓
+func (ps *PortfolioService) networkPortfolio(ctx context.Context, networkID string, addresses, tokens []string) *NetworkPortfolio {
+	network := &NetworkPortfolio{Network: networkID, ChainID: ChainIDForNetwork(networkID)}
+	adapter, err := ps.multiChain.GetAdapter(networkID)
+	if err != nil {
+		network.Error = err.Error()
+		return network
+	}
+	evmAdapter, isEVM := adapter.(*core.EVMAdapter)
+	native := core.NativeCurrencyFor(networkID)
+
+	for _, address := range addresses {
+		// EVM 网络只接受十六进制地址，其他地址格式属于别的链，跳过
+		if isEVM && !common.IsHexAddress(address) {
+			continue
+		}
+		balances, err := ps.multiChain.GetCrossChainBalance(address, []string{networkID})
+		if err != nil {
+			network.Error = err.Error()
+			return network
+		}
+		holdings := &AddressHoldings{
+			Address: address,
+			Native: &PortfolioAsset{
+				Token:    NativeTokenAddress,
+				Symbol:   native.Symbol,
+				Decimals: native.Decimals,
+				Balance:  balances[networkID].String(),
+			},
+		}
+		if isEVM {
+			if err := ps.fillTokenBalances(ctx, evmAdapter, holdings, tokens); err != nil {
+				holdings.Error = err.Error()
+			}
+		}
+		network.Holdings = append(network.Holdings, holdings)
+	}
+
+	ps.valueNetwork(ctx, network)
+	return network
+}
+
+// fillTokenBalances 批量查询地址的代币余额与元数据，仅保留余额大于0的代币
+func (ps *PortfolioService) fillTokenBalances(ctx context.Context, adapter *core.EVMAdapter, holdings *AddressHoldings, tokens []string) error {
+	if len(tokens) == 0 {
+		detected, err := adapter.DetectERC20Tokens(ctx, holdings.Address, ps.cfg.DetectLookbackBlocks)
+		if err != nil {
+			return fmt.Errorf("自动识别代币失败: %w", err)
+		}
+		tokens = detected
+	}
+	if len(tokens) > ps.cfg.MaxTokens {
+		tokens = tokens[:ps.cfg.MaxTokens]
+	}
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	balances, err := adapter.GetERC20BalancesBatch(ctx, holdings.Address, tokens)
+	if err != nil {
+		return fmt.Errorf("查询代币余额失败: %w", err)
+	}
+	var held []string
+	for token, balance := range balances {
+		if balance.Sign() > 0 {
+			held = append(held, token)
+		}
+	}
+	if len(held) == 0 {
+		return nil
+	}
+	metadata, err := adapter.GetERC20MetadataBatch(ctx, held)
+	if err != nil {
+		return fmt.Errorf("查询代币信息失败: %w", err)
+	}
+
+	sort.Strings(held)
+	for _, token := range held {
+		meta, ok := metadata[token]
+		if !ok {
+			continue // 没有 decimals 的合约无法正确展示余额
+		}
+		holdings.Tokens = append(holdings.Tokens, &PortfolioAsset{
+			Token:    token,
+			Symbol:   meta.Symbol,
+			Decimals: meta.Decimals,
+			Balance:  balances[token].String(),
+		})
+	}
+	return nil
+}
+
+// valueNetwork 按网络批量查询价格，填充格式化余额与美元价值并汇总
+func (ps *PortfolioService) valueNetwork(ctx context.Context, network *NetworkPortfolio) {
+	var assets []*PortfolioAsset
+	var tokens []string
+	for _, holdings := range network.Holdings {
+		assets = append(assets, holdings.Native)
+		tokens = append(tokens, holdings.Native.Token)
+		for _, asset := range holdings.Tokens {
+			assets = append(assets, asset)
+			tokens = append(tokens, asset.Token)
+		}
+	}
+
+	var prices map[string]*TokenPrice
+	if ps.priceService != nil && network.ChainID != 0 && len(tokens) > 0 {
+		prices, _ = ps.priceService.GetTokenPricesUSD(ctx, network.ChainID, tokens)
+	}
+	for _, asset := range assets {
+		balance, _ := new(big.Int).SetString(asset.Balance, 10)
+		asset.Formatted = core.FormatUnits(balance, asset.Decimals)
+		price := prices[normalizePriceToken(asset.Token)]
+		if price == nil || price.NoPrice {
+			asset.NoPrice = true
+			continue
+		}
+		asset.PriceUSD = price.PriceUSD
+		asset.ValueUSD = ValueUSD(balance, asset.Decimals, price.PriceUSD)
+	}
+
+	for _, holdings := range network.Holdings {
+		holdings.TotalUSD = holdings.Native.ValueUSD
+		for _, asset := range holdings.Tokens {
+			holdings.TotalUSD += asset.ValueUSD
+		}
+		network.TotalUSD += holdings.TotalUSD
+	}
+}
+
+// resolveNetworks 解析网络简写并去重，为空时使用所有可用网络
+func (ps *PortfolioService) resolveNetworks(networks []string) []string {
+	if len(networks) == 0 {
+		for _, info := range ps.multiChain.GetAvailableNetworks() {
+			networks = append(networks, info.ID)
+		}
+		sort.Strings(networks)
+	}
+	resolved := make([]string, 0, len(networks))
+	for _, networkID := range networks {
+		networkID = strings.ToLower(strings.TrimSpace(networkID))
+		if alias, ok := portfolioNetworkAliases[networkID]; ok {
+			networkID = alias
+		}
+		resolved = append(resolved, networkID)
+	}
+	return uniqueStrings(resolved)
+}
+
+// getCached 读取未过期的缓存结果
+func (ps *PortfolioService) getCached(key string) *CrossChainPortfolio {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	entry, ok := ps.cache[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil
+	}
+	cached := *entry.portfolio
+	cached.Cached = true
+	return &cached
+}
+
+// setCached 写入缓存并清理过期条目
+func (ps *PortfolioService) setCached(key string, portfolio *CrossChainPortfolio) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	now := time.Now()
+	for k, entry := range ps.cache {
+		if now.After(entry.expiresAt) {
+			delete(ps.cache, k)
+		}
+	}
+	ps.cache[key] = &portfolioCacheEntry{
+		portfolio: portfolio,
+		expiresAt: now.Add(time.Duration(ps.cfg.CacheTTLSeconds) * time.Second),
+	}
+}
+
+// portfolioCacheKey 按地址、网络、代币组合生成缓存键（地址与代币不区分大小写）
+func portfolioCacheKey(addresses, networks []string, tokensByNetwork map[string][]string) string {
+	lower := func(values []string) string {
+		out := make([]string, len(values))
+		for i, v := range values {
+			out[i] = strings.ToLower(v)
+		}
+		sort.Strings(out)
+		return strings.Join(out, ",")
+	}
+	key := lower(addresses) + "|" + lower(networks)
+	for _, networkID := range networks {
+		if tokens, ok := tokensByNetwork[networkID]; ok {
+			key += "|" + networkID + ":" + lower(tokens)
+		}
+	}
+	return key
+}
+
+// uniqueStrings 去除空白项与重复项，保持原有顺序
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[strings.ToLower(v)] {
+			continue
+		}
+		seen[strings.ToLower(v)] = true
+		out = append(out, v)
+	}
+	return out
+}
@@ -0,0 +1,209 @@
+/*
+价格提醒的触发判断与通知
+
+NFT价格提醒（NFTMarketplaceService）与代币价格提醒（PriceService）共用：
+- 触发条件：above 价格 >= 目标价；below 价格 <= 目标价；change 相对基准价的涨跌幅绝对值 >= 阈值（%）
+- 穿越判断：条件满足且处于待触发状态时触发，触发后需条件先不满足才会再次触发，避免价格停留在阈值一侧时重复通知
+- 一次性提醒触发后自动停用；重复提醒保持启用，change 类型以触发时的价格作为新基准
+
+触发时经 NotificationService 发送 price_alert 通知（收件箱 + 用户设置的推送/邮件/Webhook 渠道），
+并记录到用户的触发历史（保留 price_alerts.history_limit 条）。
+*/
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"time"
+	"wallet/config"
+	"wallet/core"
+)
+
+// 价格提醒类型
+const (
+	PriceAlertAbove  = "above"  // 价格高于目标价
+	PriceAlertBelow  = "below"  // 价格低于目标价
+	PriceAlertChange = "change" // 相对基准价涨跌超过阈值
+)
+
+// 价格提醒种类
+const (
+	PriceAlertKindNFT   = "nft"
+	PriceAlertKindToken = "token"
+)
+
+// alertEvaluateTimeout 单轮检查的超时
+const alertEvaluateTimeout = 2 * time.Minute
+
+// PriceAlertTrigger 价格提醒的一次触发（通知内容与触发历史）
+type PriceAlertTrigger struct {
+	Event          string            `json:"event"` // price_alert.triggered
+	AlertID        string            `json:"alert_id"`
+	Kind           string            `json:"kind"` // nft / token
+	UserAddress    string            `json:"user_address"`
+	AlertType      string            `json:"alert_type"`
+	Contract       string            `json:"contract,omitempty"`       // NFT合约
+	TokenID        string            `json:"token_id,omitempty"`       // NFT Token ID（集合提醒为空）
+	ChainID        int               `json:"chain_id,omitempty"`       // 代币所在链
+	Token          string            `json:"token,omitempty"`          // 代币地址
+	Price          *core.MarketPrice `json:"price,omitempty"`          // 触发时的NFT价格
+	TargetPrice    *core.MarketPrice `json:"target_price,omitempty"`   // NFT目标价
+	PriceUSD       float64           `json:"price_usd,omitempty"`      // 触发时的代币美元价格
+	TargetUSD      float64           `json:"target_usd,omitempty"`     // 代币目标美元价格
+	ChangePercent  float64           `json:"change_percent,omitempty"` // change 类型的实际涨跌幅（%）
+	Message        string            `json:"message"`
+	NotificationID string            `json:"notification_id,omitempty"` // 收件箱中的通知ID
+	Channels       []string          `json:"channels"`                  // 已投递或已排队的通知渠道
+	TriggeredAt    time.Time         `json:"triggered_at"`
+}
+
+// alertState 提醒的运行状态（由所属服务的锁保护）
+type alertState struct {
+	armed bool // 待触发：条件满足时触发，触发后等条件不满足再重新待触发
+}
+
+// alertConditionMet 判断提醒条件是否满足，change 类型同时返回相对基准价的涨跌幅（%）
+func alertConditionMet(alertType string, current, target, base, changePercent float64) (bool, float64) {
+	switch alertType {
+	case PriceAlertAbove:
+		return current >= target, 0
+	case PriceAlertBelow:
+		return current <= target, 0
+	case PriceAlertChange:
+		if base <= 0 {
+			return false, 0
+		}
+		change := (current - base) / base * 100
+		return math.Abs(change) >= changePercent, change
+	}
+	return false, 0
+}
+
+// shouldTrigger 按穿越语义更新状态，返回本次是否触发
+func (s *alertState) shouldTrigger(met bool) bool {
+	if !met {
+		s.armed = true
+		return false
+	}
+	if !s.armed {
+		return false
+	}
+	s.armed = false
+	return true
+}
+
+// ValidatePriceAlert 校验提醒类型与阈值
+func ValidatePriceAlert(alertType string, hasTarget bool, changePercent float64) error {
+	switch alertType {
+	case PriceAlertAbove, PriceAlertBelow:
+		if !hasTarget {
+			return fmt.Errorf("%s 类型的提醒需要目标价", alertType)
+		}
+	case PriceAlertChange:
+		if changePercent <= 0 {
+			return fmt.Errorf("change 类型的提醒需要大于0的涨跌幅阈值")
+		}
+	default:
+		return fmt.Errorf("不支持的提醒类型: %s（支持 above/below/change）", alertType)
+	}
+	return nil
+}
+
+// AlertNotifier 价格提醒的触发历史，通知经 NotificationService 投递
+type AlertNotifier struct {
+	notifications *NotificationService
+	cfg           config.PriceAlertConfig
+	history       map[string][]*PriceAlertTrigger // 小写用户地址 -> 触发记录（新的在前）
+	mu            sync.RWMutex
+}
+
+// NewAlertNotifier 创建价格提醒通知器
+func NewAlertNotifier(notifications *NotificationService, cfg config.PriceAlertConfig) *AlertNotifier {
+	return &AlertNotifier{
+		notifications: notifications,
+		cfg:           cfg.WithDefaults(),
+		history:       make(map[string][]*PriceAlertTrigger),
+	}
+}
+
+// Dispatch 记录触发并发送 price_alert 通知
+func (n *AlertNotifier) Dispatch(ctx context.Context, trigger *PriceAlertTrigger) {
+	trigger.Event = "price_alert.triggered"
+	if trigger.TriggeredAt.IsZero() {
+		trigger.TriggeredAt = time.Now()
+	}
+	trigger.Channels = []string{}
+
+	if n.notifications != nil {
+		sent, err := n.notifications.Send(ctx, trigger.UserAddress, core.Notification{
+			Type:     NotificationTypePriceAlert,
+			Title:    "价格提醒已触发",
+			Message:  trigger.Message,
+			Data:     priceAlertNotificationData(trigger),
+			Priority: NotificationPriorityHigh,
+		})
+		if err != nil {
+			slog.DebugContext(ctx, "price alert notification failed", "alert_id", trigger.AlertID, "error", err)
+		} else {
+			trigger.NotificationID = sent.ID
+			for _, delivery := range sent.Deliveries {
+				trigger.Channels = append(trigger.Channels, delivery.Channel)
+			}
+		}
+	}
+
+	key := strings.ToLower(trigger.UserAddress)
+	n.mu.Lock()
+	records := append([]*PriceAlertTrigger{trigger}, n.history[key]...)
+	if len(records) > n.cfg.HistoryLimit {
+		records = records[:n.cfg.HistoryLimit]
+	}
+	n.history[key] = records
+	n.mu.Unlock()
+}
+
+// History 用户的触发记录（新的在前），kind 为空时返回全部
+func (n *AlertNotifier) History(userAddress, kind string) []*PriceAlertTrigger {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	records := []*PriceAlertTrigger{}
+	for _, record := range n.history[strings.ToLower(userAddress)] {
+		if kind == "" || record.Kind == kind {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// priceAlertNotificationData 触发记录转为通知数据（与触发记录的JSON字段一致）
+func priceAlertNotificationData(trigger *PriceAlertTrigger) map[string]interface{} {
+	data := map[string]interface{}{}
+	encoded, err := json.Marshal(trigger)
+	if err == nil {
+		json.Unmarshal(encoded, &data)
+	}
+	delete(data, "channels")
+	delete(data, "message")
+	return data
+}
+
+// runAlertLoop 按间隔执行 evaluate，直到 ctx 取消
+func runAlertLoop(ctx context.Context, interval time.Duration, evaluate func(context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			evalCtx, cancel := context.WithTimeout(ctx, alertEvaluateTimeout)
+			evaluate(evalCtx)
+			cancel()
+		}
+	}
+}
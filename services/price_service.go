@@ -0,0 +1,383 @@
+/*
+代币价格服务
+
+为余额、DeFi 池子、NFT 挂单和投资组合提供美元估值：
+- 主数据源为 CoinGecko：ERC20 使用 /simple/token_price/{platform}，原生币使用 /simple/price
+- CoinGecko 不可用时，原生币可回退到链上 Chainlink 喂价（latestRoundData）
+- 价格在内存中缓存 CacheTTLSeconds 秒，无价格的结果同样缓存，避免反复请求冷门代币
+- 查不到价格的代币返回 0 并标记 NoPrice，不作为错误，调用方可正常展示其余数据
+
+CoinGecko 密钥优先使用用户在 context 中配置的 coingecko 密钥，其次使用全局 provider_keys。
+*/
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"wallet/config"
+	"wallet/core"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// 价格来源
+const (
+	PriceSourceCoinGecko = "coingecko"
+	PriceSourceChainlink = "chainlink"
+)
+
+// NativeTokenAddress 表示链原生币的占位地址（与1inch约定一致），空字符串同样视为原生币
+const NativeTokenAddress = "0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE"
+
+// coinGeckoPlatforms 链ID -> CoinGecko 资产平台ID与原生币ID
+var coinGeckoPlatforms = map[int]struct {
+	Platform string
+	NativeID string
+}{
+	1:     {"ethereum", "ethereum"},
+	10:    {"optimistic-ethereum", "ethereum"},
+	56:    {"binance-smart-chain", "binancecoin"},
+	137:   {"polygon-pos", "polygon-ecosystem-token"},
+	8453:  {"base", "ethereum"},
+	42161: {"arbitrum-one", "ethereum"},
+	43114: {"avalanche", "avalanche-2"},
+}
+
+// chainlinkNativeFeeds 链ID -> 部署在该链上的原生币/USD Chainlink 喂价合约
+var chainlinkNativeFeeds = map[int]string{
+	1:   "0x5f4eC3Df9cbd43714FE2740f5E3616155c5b8419", // ETH/USD
+	56:  "0x0567F2323251f0Aab15c8dFb1967E4e8A7D42aeE", // BNB/USD
+	137: "0xAB594600376Ec9fD91F8e885dADF0CE036862dE0", // MATIC/USD
+}
+
+// Chainlink AggregatorV3Interface 方法选择器
+var (
+	chainlinkLatestRoundData = common.FromHex("0xfeaf968c")
+	chainlinkDecimals        = common.FromHex("0x313ce567")
+)
+
+// chainlinkMaxStaleness Chainlink 喂价超过该时长未更新则不采用
+const chainlinkMaxStaleness = 2 * time.Hour
+
+// TokenPrice 代币美元价格
+type TokenPrice struct {
+	ChainID   int       `json:"chain_id"`         // 链ID
+	Token     string    `json:"token"`            // 代币地址（原生币为 NativeTokenAddress）
+	PriceUSD  float64   `json:"price_usd"`        // 美元价格，无价格时为0
+	NoPrice   bool      `json:"no_price"`         // 是否缺少价格数据
+	Source    string    `json:"source,omitempty"` // 价格来源
+	UpdatedAt time.Time `json:"updated_at"`       // 获取时间
+}
+
+// priceCacheEntry 价格缓存条目
+type priceCacheEntry struct {
+	price     *TokenPrice
+	expiresAt time.Time
+}
+
+// PriceService 代币价格服务
+type PriceService struct {
+	multiChain *core.MultiChainManager
+	httpClient *http.Client
+	cfg        config.PriceConfig
+	cache      map[string]*priceCacheEntry
+	mu         sync.RWMutex
+
+	// 代币价格提醒（见 token_price_alert_service.go）
+	tokenAlerts map[string]*TokenPriceAlert
+	notifier    *AlertNotifier
+	stopAlerts  context.CancelFunc
+	alertMu     sync.RWMutex
+}
+
+// NewPriceService 创建代币价格服务
+func NewPriceService(multiChain *core.MultiChainManager, cfg config.PriceConfig) *PriceService {
+	return &PriceService{
+		multiChain: multiChain,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		cfg:        cfg.WithDefaults(),
+		cache:      make(map[string]*priceCacheEntry),
+
+		tokenAlerts: make(map[string]*TokenPriceAlert),
+	}
+}
+
+// GetTokenPriceUSD 查询单个代币的美元价格，无价格数据时返回 0 且不返回错误
+func (ps *PriceService) GetTokenPriceUSD(ctx context.Context, chainID int, tokenAddress string) (float64, error) {
+	prices, err := ps.GetTokenPricesUSD(ctx, chainID, []string{tokenAddress})
+	if err != nil {
+		return 0, err
+	}
+	return prices[normalizePriceToken(tokenAddress)].PriceUSD, nil
+}
+
+// GetTokenPricesUSD 批量查询同一条链上多个代币的美元价格
+// 返回以规范化地址（小写，原生币为 NativeTokenAddress）为键的结果，每个请求的代币都有条目；
+// 仅参数非法时返回错误，数据源故障时相应代币标记为 NoPrice
+func (ps *PriceService) GetTokenPricesUSD(ctx context.Context, chainID int, tokens []string) (map[string]*TokenPrice, error) {
+	result := make(map[string]*TokenPrice, len(tokens))
+	var missing []string
+	for _, token := range tokens {
+		key := normalizePriceToken(token)
+		if key != strings.ToLower(NativeTokenAddress) && !common.IsHexAddress(key) {
+			return nil, fmt.Errorf("无效的代币地址: %s", token)
+		}
+		if _, seen := result[key]; seen {
+			continue
+		}
+		if cached := ps.getCached(chainID, key); cached != nil {
+			result[key] = cached
+			continue
+		}
+		result[key] = nil
+		missing = append(missing, key)
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	fetched := ps.fetchPrices(ctx, chainID, missing)
+	now := time.Now()
+	for _, key := range missing {
+		price, ok := fetched[key]
+		if !ok {
+			price = &TokenPrice{ChainID: chainID, Token: key, NoPrice: true, UpdatedAt: now}
+		}
+		ps.setCached(chainID, key, price)
+		result[key] = price
+	}
+	return result, nil
+}
+
+// GetNativePriceUSD 查询链原生币的美元价格
+func (ps *PriceService) GetNativePriceUSD(ctx context.Context, chainID int) (float64, error) {
+	return ps.GetTokenPriceUSD(ctx, chainID, NativeTokenAddress)
+}
+
+// ValueUSD 将最小单位数量按小数位换算后乘以单价，得到美元价值
+func ValueUSD(amount *big.Int, decimals int, priceUSD float64) float64 {
+	if amount == nil || priceUSD == 0 {
+		return 0
+	}
+	units, _ := new(big.Float).SetInt(amount).Float64()
+	return units / math.Pow10(decimals) * priceUSD
+}
+
+// ChainIDForNetwork 将网络ID（如 ethereum、polygon）转换为链ID，未知网络返回 0
+func ChainIDForNetwork(networkID string) int {
+	networkConfig, err := config.GetNetwork(networkID)
+	if err != nil {
+		return 0
+	}
+	return int(networkConfig.ChainID)
+}
+
+// fetchPrices 从 CoinGecko 获取价格，原生币失败时回退到 Chainlink
+func (ps *PriceService) fetchPrices(ctx context.Context, chainID int, tokens []string) map[string]*TokenPrice {
+	prices := make(map[string]*TokenPrice)
+	native := strings.ToLower(NativeTokenAddress)
+
+	var contracts []string
+	wantNative := false
+	for _, token := range tokens {
+		if token == native {
+			wantNative = true
+		} else {
+			contracts = append(contracts, token)
+		}
+	}
+
+	platform, supported := coinGeckoPlatforms[chainID]
+	if supported && len(contracts) > 0 {
+		if found, err := ps.coinGeckoTokenPrices(ctx, platform.Platform, contracts); err == nil {
+			for token, usd := range found {
+				prices[token] = ps.newPrice(chainID, token, usd, PriceSourceCoinGecko)
+			}
+		}
+	}
+	if wantNative {
+		if supported {
+			if usd, err := ps.coinGeckoNativePrice(ctx, platform.NativeID); err == nil && usd > 0 {
+				prices[native] = ps.newPrice(chainID, native, usd, PriceSourceCoinGecko)
+			}
+		}
+		if _, ok := prices[native]; !ok && ps.cfg.ChainlinkFallback {
+			if usd, err := ps.chainlinkNativePrice(ctx, chainID); err == nil && usd > 0 {
+				prices[native] = ps.newPrice(chainID, native, usd, PriceSourceChainlink)
+			}
+		}
+	}
+	return prices
+}
+
+// coinGeckoTokenPrices 批量查询 ERC20 价格，返回 小写地址 -> 美元价格（无报价的代币不包含在内）
+func (ps *PriceService) coinGeckoTokenPrices(ctx context.Context, platform string, contracts []string) (map[string]float64, error) {
+	query := url.Values{}
+	query.Set("contract_addresses", strings.Join(contracts, ","))
+	query.Set("vs_currencies", "usd")
+
+	var resp map[string]map[string]float64
+	if err := ps.coinGeckoGet(ctx, "/simple/token_price/"+platform, query, &resp); err != nil {
+		return nil, err
+	}
+	prices := make(map[string]float64, len(resp))
+	for addr, quote := range resp {
+		if usd, ok := quote["usd"]; ok && usd > 0 {
+			prices[strings.ToLower(addr)] = usd
+		}
+	}
+	return prices, nil
+}
+
+// coinGeckoNativePrice 查询原生币价格
+func (ps *PriceService) coinGeckoNativePrice(ctx context.Context, coinID string) (float64, error) {
+	query := url.Values{}
+	query.Set("ids", coinID)
+	query.Set("vs_currencies", "usd")
+
+	var resp map[string]map[string]float64
+	if err := ps.coinGeckoGet(ctx, "/simple/price", query, &resp); err != nil {
+		return 0, err
+	}
+	return resp[coinID]["usd"], nil
+}
+
+// coinGeckoGet 发送 CoinGecko GET 请求并解析JSON
+func (ps *PriceService) coinGeckoGet(ctx context.Context, path string, query url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ps.cfg.CoinGeckoURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	apiKey := core.ProviderKeyFromContext(ctx, "coingecko")
+	if apiKey == "" {
+		apiKey = config.AppConfig.Security.ProviderKeys["coingecko"]
+	}
+	if apiKey != "" {
+		req.Header.Set("x-cg-demo-api-key", apiKey)
+	}
+
+	resp, err := ps.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求CoinGecko失败: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取CoinGecko响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("CoinGecko返回错误: HTTP %d", resp.StatusCode)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("解析CoinGecko响应失败: %w", err)
+	}
+	return nil
+}
+
+// chainlinkNativePrice 读取链上 Chainlink 原生币/USD 喂价
+func (ps *PriceService) chainlinkNativePrice(ctx context.Context, chainID int) (float64, error) {
+	feed, ok := chainlinkNativeFeeds[chainID]
+	if !ok || ps.multiChain == nil {
+		return 0, fmt.Errorf("链 %d 没有可用的 Chainlink 喂价", chainID)
+	}
+	adapter, err := ps.adapterForChain(chainID)
+	if err != nil {
+		return 0, err
+	}
+
+	feedAddr := common.HexToAddress(feed)
+	decimalsOut, err := adapter.CallContract(ctx, ethereum.CallMsg{To: &feedAddr, Data: chainlinkDecimals}, nil)
+	if err != nil || len(decimalsOut) < 32 {
+		return 0, fmt.Errorf("读取喂价精度失败: %v", err)
+	}
+	roundOut, err := adapter.CallContract(ctx, ethereum.CallMsg{To: &feedAddr, Data: chainlinkLatestRoundData}, nil)
+	if err != nil || len(roundOut) < 160 {
+		return 0, fmt.Errorf("读取喂价失败: %v", err)
+	}
+
+	// latestRoundData 返回 (roundId, answer, startedAt, updatedAt, answeredInRound)
+	answer := new(big.Int).SetBytes(roundOut[32:64])
+	if roundOut[32]&0x80 != 0 {
+		return 0, fmt.Errorf("喂价为负数")
+	}
+	updatedAt := new(big.Int).SetBytes(roundOut[96:128]).Int64()
+	if time.Since(time.Unix(updatedAt, 0)) > chainlinkMaxStaleness {
+		return 0, fmt.Errorf("喂价已过期")
+	}
+	decimals := int(new(big.Int).SetBytes(decimalsOut[:32]).Int64())
+	return ValueUSD(answer, decimals, 1), nil
+}
+
+// adapterForChain 按链ID查找已启用网络的EVM适配器
+func (ps *PriceService) adapterForChain(chainID int) (*core.EVMAdapter, error) {
+	for networkID, network := range config.GetEnabledNetworks() {
+		if int(network.ChainID) != chainID {
+			continue
+		}
+		adapter, err := ps.multiChain.GetAdapter(networkID)
+		if err != nil {
+			continue
+		}
+		if evm, ok := adapter.(*core.EVMAdapter); ok {
+			return evm, nil
+		}
+	}
+	return nil, fmt.Errorf("链 %d 没有可用的EVM适配器", chainID)
+}
+
+// newPrice 构造有效价格
+func (ps *PriceService) newPrice(chainID int, token string, usd float64, source string) *TokenPrice {
+	return &TokenPrice{ChainID: chainID, Token: token, PriceUSD: usd, Source: source, UpdatedAt: time.Now()}
+}
+
+// getCached 读取未过期的缓存价格
+func (ps *PriceService) getCached(chainID int, token string) *TokenPrice {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	entry, ok := ps.cache[priceCacheKey(chainID, token)]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil
+	}
+	return entry.price
+}
+
+// setCached 写入缓存并顺带清理过期条目
+func (ps *PriceService) setCached(chainID int, token string, price *TokenPrice) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	now := time.Now()
+	for key, entry := range ps.cache {
+		if now.After(entry.expiresAt) {
+			delete(ps.cache, key)
+		}
+	}
+	ps.cache[priceCacheKey(chainID, token)] = &priceCacheEntry{
+		price:     price,
+		expiresAt: now.Add(time.Duration(ps.cfg.CacheTTLSeconds) * time.Second),
+	}
+}
+
+// priceCacheKey 缓存键
+func priceCacheKey(chainID int, token string) string {
+	return fmt.Sprintf("%d:%s", chainID, token)
+}
+
+// normalizePriceToken 规范化代币地址：小写，原生币（空、native、零地址、0xEeee...）统一为 NativeTokenAddress
+func normalizePriceToken(token string) string {
+	token = strings.TrimSpace(token)
+	if token == "" || strings.EqualFold(token, "native") || isOneInchNative(token) {
+		return strings.ToLower(NativeTokenAddress)
+	}
+	return strings.ToLower(token)
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"wallet/config"
+	"wallet/core"
+)
+
+func TestValueUSDUsesNativeDecimals(t *testing.T) {
+	previous := config.AppConfig.Networks
+	config.AppConfig.Networks = map[string]config.NetworkConfig{
+		"testbtc":   {ChainID: 9001, Symbol: "BTC", Decimals: 8, Enabled: true},
+		"testusd":   {ChainID: 9002, Symbol: "USDX", Decimals: 6, Enabled: true},
+		"testsol":   {ChainID: 9003, Symbol: "SOL", Decimals: 9, Enabled: true},
+		"testmatic": {ChainID: 137, Symbol: "MATIC", Decimals: 18, Enabled: true},
+	}
+	t.Cleanup(func() { config.AppConfig.Networks = previous })
+
+	cases := []struct {
+		network  string
+		amount   *big.Int
+		priceUSD float64
+		wantUSD  float64
+	}{
+		{"testbtc", big.NewInt(150_000_000), 60000, 90000},
+		{"testusd", big.NewInt(1_234_567), 1, 1.234567},
+		{"testsol", big.NewInt(2_500_000_000), 150, 375},
+		{"testmatic", big.NewInt(2_500_000_000_000_000_000), 0.8, 2},
+		// 按18位小数折算时 BTC 金额会被低估 10^10 倍
+		{"testbtc", big.NewInt(1), 60000, 0.0006},
+		{"testusd", big.NewInt(0), 1, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.network, func(t *testing.T) {
+			decimals := core.NativeCurrencyFor(tc.network).Decimals
+			got := ValueUSD(tc.amount, decimals, tc.priceUSD)
+			if math.Abs(got-tc.wantUSD) > 1e-9*math.Max(1, tc.wantUSD) {
+				t.Fatalf("ValueUSD = %v，期望 %v", got, tc.wantUSD)
+			}
+			if ChainIDForNetwork(tc.network) == 0 {
+				t.Fatalf("ChainIDForNetwork(%s) = 0", tc.network)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+/*
+第三方服务密钥业务服务层
+
+本文件实现了按用户（钱包地址）加密存储第三方API密钥的功能：
+- 用户密钥使用 CryptoManager 加密后存入数据库，接口只写不读
+- 集成服务（NFT市场、价格、区块浏览器ABI）优先使用当前用户的密钥
+- 用户未配置时回退到 config.security.provider_keys 中的全局密钥
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"wallet/config"
+	"wallet/core"
+	"wallet/database"
+	"wallet/models"
+	"wallet/pkg/crypto"
+
+	"gorm.io/gorm"
+)
+
+// SupportedProviders 支持用户自带密钥的第三方服务
+var SupportedProviders = map[string]struct{}{
+	"alchemy":   {},
+	"coingecko": {},
+	"opensea":   {},
+	"rarible":   {},
+	"etherscan": {},
+	"oneinch":   {},
+}
+
+// ProviderKeyService 第三方服务密钥服务
+type ProviderKeyService struct {
+	cryptoManager *crypto.CryptoManager // 加密管理器
+}
+
+// NewProviderKeyService 创建第三方服务密钥服务
+func NewProviderKeyService(cryptoManager *crypto.CryptoManager) *ProviderKeyService {
+	return &ProviderKeyService{cryptoManager: cryptoManager}
+}
+
+// SetProviderKeys 写入用户的第三方服务密钥
+// keys 中值为空字符串表示删除该服务的用户密钥（回退到全局密钥）
+func (s *ProviderKeyService) SetProviderKeys(ownerAddress string, keys map[string]string) error {
+	if database.DB == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+	owner := strings.ToLower(ownerAddress)
+
+	normalized := make(map[string]string, len(keys))
+	for provider, key := range keys {
+		provider = strings.ToLower(strings.TrimSpace(provider))
+		if _, ok := SupportedProviders[provider]; !ok {
+			return fmt.Errorf("不支持的服务: %s", provider)
+		}
+		normalized[provider] = strings.TrimSpace(key)
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		for provider, key := range normalized {
+			if key == "" {
+				if err := tx.Unscoped().Where("owner_address = ? AND provider = ?", owner, provider).
+					Delete(&models.UserProviderKey{}).Error; err != nil {
+					return fmt.Errorf("删除%s密钥失败: %w", provider, err)
+				}
+				continue
+			}
+
+			enc, err := s.cryptoManager.EncryptDefault(key)
+			if err != nil {
+				return fmt.Errorf("加密%s密钥失败: %w", provider, err)
+			}
+
+			var record models.UserProviderKey
+			err = tx.Where("owner_address = ? AND provider = ?", owner, provider).First(&record).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("查询%s密钥失败: %w", provider, err)
+			}
+			record.OwnerAddress = owner
+			record.Provider = provider
+			record.EncryptedKey = enc.Data
+			record.Salt = enc.Salt
+			record.Nonce = enc.Nonce
+			if err := tx.Save(&record).Error; err != nil {
+				return fmt.Errorf("保存%s密钥失败: %w", provider, err)
+			}
+		}
+		return nil
+	})
+}
+
+// ConfiguredProviders 返回用户已配置密钥的服务列表（不返回密钥本身）
+func (s *ProviderKeyService) ConfiguredProviders(ownerAddress string) ([]string, error) {
+	if database.DB == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+	var providers []string
+	err := database.DB.Model(&models.UserProviderKey{}).
+		Where("owner_address = ?", strings.ToLower(ownerAddress)).
+		Order("provider").
+		Pluck("provider", &providers).Error
+	return providers, err
+}
+
+// GetProviderKey 获取某服务应使用的密钥：优先用户密钥，其次全局配置
+func (s *ProviderKeyService) GetProviderKey(ownerAddress, provider string) string {
+	if ownerAddress != "" {
+		if keys, err := s.userKeys(ownerAddress); err == nil {
+			if key := keys[provider]; key != "" {
+				return key
+			}
+		}
+	}
+	return config.AppConfig.Security.ProviderKeys[provider]
+}
+
+// WithUserProviderKeys 将用户的全部第三方服务密钥附加到context，供核心层外部请求使用
+func (s *ProviderKeyService) WithUserProviderKeys(ctx context.Context, ownerAddress string) context.Context {
+	if ownerAddress == "" {
+		return ctx
+	}
+	keys, err := s.userKeys(ownerAddress)
+	if err != nil {
+		return ctx
+	}
+	return core.WithProviderKeys(ctx, keys)
+}
+
+// userKeys 读取并解密用户的全部密钥
+func (s *ProviderKeyService) userKeys(ownerAddress string) (map[string]string, error) {
+	if database.DB == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+	var records []models.UserProviderKey
+	if err := database.DB.Where("owner_address = ?", strings.ToLower(ownerAddress)).Find(&records).Error; err != nil {
+		return nil, err
+	}
+	keys := make(map[string]string, len(records))
+	for _, r := range records {
+		key, err := s.cryptoManager.DecryptDefault(&crypto.EncryptedData{Data: r.EncryptedKey, Salt: r.Salt, Nonce: r.Nonce})
+		if err != nil {
+			continue
+		}
+		keys[r.Provider] = key
+	}
+	return keys, nil
+}
@@ -0,0 +1,100 @@
+/*
+收款二维码与支付链接
+
+为收款地址生成 EIP-681 支付链接及其二维码，并解析扫码得到的支付链接：
+- 链ID取当前网络，仅支持 EVM 网络
+- 金额按可读单位传入（如 "1.5"），原生币按网络小数位、代币按合约 decimals 转换为最小单位
+- 解析时链接指定的链ID必须与当前网络一致，未指定时视为当前网络
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"wallet/core"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ReceiveQRRequest 收款二维码参数
+type ReceiveQRRequest struct {
+	Address       string // 收款地址
+	Amount        string // 可读单位金额，可选
+	Token         string // ERC20 代币地址，为空表示原生币
+	Size          int    // 图片边长（像素），0 使用默认值
+	RecoveryLevel string // 纠错级别，为空使用默认值
+}
+
+// ReceiveQR 收款二维码结果
+type ReceiveQR struct {
+	URI     string `json:"uri"`      // EIP-681 支付链接
+	ChainID int64  `json:"chain_id"` // 链ID
+	Amount  string `json:"amount,omitempty"`
+	PNG     []byte `json:"-"`
+}
+
+// ReceiveQRCode 生成当前网络的收款二维码
+func (s *WalletService) ReceiveQRCode(ctx context.Context, req ReceiveQRRequest) (*ReceiveQR, error) {
+	network := s.multiChain.GetCurrentNetwork()
+	info, err := s.multiChain.GetNetworkInfo(network)
+	if err != nil {
+		return nil, err
+	}
+	if info.ChainType != "evm" {
+		return nil, fmt.Errorf("网络 %s 不支持 EIP-681 收款链接", network)
+	}
+
+	var amount *big.Int
+	if amountHuman := strings.TrimSpace(req.Amount); amountHuman != "" {
+		decimals := uint8(s.GetNativeCurrency().Decimals)
+		if req.Token != "" {
+			if _, _, decimals, err = s.GetTokenMetadata(ctx, req.Token); err != nil {
+				return nil, fmt.Errorf("获取代币精度失败: %w", err)
+			}
+		}
+		if amount, err = core.ParseTokenAmount(amountHuman, decimals); err != nil {
+			return nil, err
+		}
+	}
+
+	if !common.IsHexAddress(req.Address) {
+		return nil, fmt.Errorf("收款地址格式不正确: %s", req.Address)
+	}
+	if req.Token != "" && !common.IsHexAddress(req.Token) {
+		return nil, fmt.Errorf("代币地址格式不正确: %s", req.Token)
+	}
+	uri := core.BuildPaymentURI(&core.PaymentRequest{Recipient: req.Address, ChainID: info.ChainID, Token: req.Token, Amount: amount})
+	png, err := core.GenerateQRCodeWithOptions(uri, core.QRCodeOptions{Size: req.Size, RecoveryLevel: req.RecoveryLevel})
+	if err != nil {
+		return nil, err
+	}
+	result := &ReceiveQR{URI: uri, ChainID: info.ChainID, PNG: png}
+	if amount != nil {
+		result.Amount = amount.String()
+	}
+	return result, nil
+}
+
+// ParsePaymentRequest 解析支付链接并校验链ID与当前网络一致，返回支付请求及当前网络ID
+func (s *WalletService) ParsePaymentRequest(uri string) (*core.PaymentRequest, string, error) {
+	req, err := core.ParsePaymentURI(uri)
+	if err != nil {
+		return nil, "", err
+	}
+	network := s.multiChain.GetCurrentNetwork()
+	info, err := s.multiChain.GetNetworkInfo(network)
+	if err != nil {
+		return nil, "", err
+	}
+	if info.ChainType != "evm" {
+		return nil, "", fmt.Errorf("网络 %s 不支持 EIP-681 支付链接", network)
+	}
+	if req.ChainID == 0 {
+		req.ChainID = info.ChainID
+	} else if req.ChainID != info.ChainID {
+		return nil, "", fmt.Errorf("支付链接链ID %d 与当前网络 %s（链ID %d）不一致，请先切换网络", req.ChainID, network, info.ChainID)
+	}
+	return req, network, nil
+}
@@ -0,0 +1,359 @@
+/*
+实时余额与到账推送服务
+
+替代客户端轮询余额：客户端（WebSocket 连接）订阅若干地址后，
+服务端按网络共享一条上游 newHeads 订阅，每个新区块：
+- 查询被订阅地址的最新余额，与上次推送值不同则推送 balance 事件
+- 扫描区块交易，转入被订阅地址的原生代币转账推送 incoming_transfer 事件
+到账交易同时经 NotificationService 写入接收地址的收件箱；
+通知服务向订阅了用户地址（任意网络）的客户端推送 notification 事件（价格提醒、多签审批等）。
+某网络的最后一个订阅者离开后自动关闭上游订阅。
+事件通道满时丢弃事件（余额以下一次推送为准），不阻塞其他订阅者。
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync"
+	"time"
+	"wallet/core"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 实时事件类型
+const (
+	RealtimeEventBalance          = "balance"           // 余额变化
+	RealtimeEventIncomingTransfer = "incoming_transfer" // 原生代币到账
+	RealtimeEventNotification     = "notification"      // 用户通知（价格提醒、多签审批、到账等）
+)
+
+const (
+	realtimeEventBuffer      = 64               // 每个订阅的事件缓冲
+	realtimeMaxAddresses     = 20               // 每个订阅最多监听的地址数
+	realtimeHeadQueryTimeout = 15 * time.Second // 每个区块的查询超时
+)
+
+// RealtimeEvent 推送给客户端的实时事件
+type RealtimeEvent struct {
+	Type         string                 `json:"type"`                   // 事件类型
+	Network      string                 `json:"network"`                // 网络ID
+	Address      string                 `json:"address"`                // 被订阅地址
+	Balance      string                 `json:"balance,omitempty"`      // 最新余额（wei），balance 事件
+	Transfer     *core.IncomingTransfer `json:"transfer,omitempty"`     // 到账交易，incoming_transfer 事件
+	Notification *core.Notification     `json:"notification,omitempty"` // 用户通知，notification 事件
+	BlockNumber  uint64                 `json:"block_number,omitempty"` // 触发事件的区块
+	Timestamp    time.Time              `json:"timestamp"`              // 事件时间
+}
+
+// RealtimeSubscription 单个客户端的订阅，持有其监听地址与事件通道
+type RealtimeSubscription struct {
+	service  *RealtimeService
+	events   chan *RealtimeEvent
+	balances map[string]map[common.Address]*big.Int // network -> 地址 -> 上次推送的余额
+	closed   bool
+}
+
+// headWatcher 单个网络的上游新区块订阅
+type headWatcher struct {
+	adapter *core.EVMAdapter
+	cancel  context.CancelFunc
+	subs    map[*RealtimeSubscription]struct{}
+}
+
+// RealtimeService 实时推送服务
+type RealtimeService struct {
+	multiChain    *core.MultiChainManager
+	watchers      map[string]*headWatcher // key: 网络ID
+	notifications *NotificationService    // 到账通知，可为 nil
+	mu            sync.Mutex
+}
+
+// NewRealtimeService 创建实时推送服务
+func NewRealtimeService(multiChain *core.MultiChainManager) *RealtimeService {
+	return &RealtimeService{
+		multiChain: multiChain,
+		watchers:   make(map[string]*headWatcher),
+	}
+}
+
+// SetNotificationService 设置到账通知使用的通知服务
+func (s *RealtimeService) SetNotificationService(notifications *NotificationService) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifications = notifications
+}
+
+// NewSubscription 为一个客户端创建订阅，使用完毕必须调用 Close
+func (s *RealtimeService) NewSubscription() *RealtimeSubscription {
+	return &RealtimeSubscription{
+		service:  s,
+		events:   make(chan *RealtimeEvent, realtimeEventBuffer),
+		balances: make(map[string]map[common.Address]*big.Int),
+	}
+}
+
+// Events 事件通道，订阅关闭后通道关闭
+func (sub *RealtimeSubscription) Events() <-chan *RealtimeEvent {
+	return sub.events
+}
+
+// Watch 订阅地址在指定网络上的余额与到账事件（networkID 为空时使用当前网络），并立即推送一次当前余额
+func (sub *RealtimeSubscription) Watch(ctx context.Context, networkID, address string) error {
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("无效的地址: %s", address)
+	}
+	s := sub.service
+	if networkID == "" {
+		networkID = s.multiChain.GetCurrentNetwork()
+	}
+	adapter, err := s.multiChain.GetAdapter(networkID)
+	if err != nil {
+		return err
+	}
+	evm, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		return fmt.Errorf("网络 %s 不支持实时订阅", networkID)
+	}
+	subscriber, err := evm.NewHeadsSubscriber()
+	if err != nil {
+		return err
+	}
+
+	addr := common.HexToAddress(address)
+	balance, err := evm.GetBalance(ctx, addr.Hex())
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sub.closed {
+		return fmt.Errorf("订阅已关闭")
+	}
+	if sub.addressCount() >= realtimeMaxAddresses {
+		return fmt.Errorf("每个连接最多订阅 %d 个地址", realtimeMaxAddresses)
+	}
+	if sub.balances[networkID] == nil {
+		sub.balances[networkID] = make(map[common.Address]*big.Int)
+	}
+	sub.balances[networkID][addr] = balance
+
+	watcher, exists := s.watchers[networkID]
+	if !exists {
+		watchCtx, cancel := context.WithCancel(context.Background())
+		watcher = &headWatcher{adapter: evm, cancel: cancel, subs: make(map[*RealtimeSubscription]struct{})}
+		s.watchers[networkID] = watcher
+		go subscriber.Run(watchCtx, func(header *types.Header) {
+			s.handleHead(watchCtx, networkID, watcher, header)
+		})
+	}
+	watcher.subs[sub] = struct{}{}
+
+	sub.push(&RealtimeEvent{
+		Type:      RealtimeEventBalance,
+		Network:   networkID,
+		Address:   addr.Hex(),
+		Balance:   balance.String(),
+		Timestamp: time.Now(),
+	})
+	return nil
+}
+
+// Unwatch 取消订阅地址（networkID 为空时使用当前网络）
+func (sub *RealtimeSubscription) Unwatch(networkID, address string) {
+	s := sub.service
+	if networkID == "" {
+		networkID = s.multiChain.GetCurrentNetwork()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(sub.balances[networkID], common.HexToAddress(address))
+	if len(sub.balances[networkID]) == 0 {
+		delete(sub.balances, networkID)
+		s.detach(networkID, sub)
+	}
+}
+
+// Close 取消全部订阅并关闭事件通道，可重复调用
+func (sub *RealtimeSubscription) Close() {
+	s := sub.service
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	for networkID := range sub.balances {
+		s.detach(networkID, sub)
+	}
+	sub.balances = nil
+	close(sub.events)
+}
+
+// detach 将订阅移出网络监听，最后一个订阅者离开时关闭上游订阅（调用方持有 s.mu）
+func (s *RealtimeService) detach(networkID string, sub *RealtimeSubscription) {
+	watcher, ok := s.watchers[networkID]
+	if !ok {
+		return
+	}
+	delete(watcher.subs, sub)
+	if len(watcher.subs) == 0 {
+		watcher.cancel()
+		delete(s.watchers, networkID)
+	}
+}
+
+// handleHead 处理新区块：推送余额变化与到账交易
+func (s *RealtimeService) handleHead(ctx context.Context, networkID string, watcher *headWatcher, header *types.Header) {
+	// 快照被订阅地址，RPC 查询不持锁
+	s.mu.Lock()
+	watched := make(map[common.Address]bool)
+	for sub := range watcher.subs {
+		for addr := range sub.balances[networkID] {
+			watched[addr] = true
+		}
+	}
+	s.mu.Unlock()
+	if len(watched) == 0 {
+		return
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, realtimeHeadQueryTimeout)
+	defer cancel()
+
+	transfers, err := watcher.adapter.IncomingTransfers(queryCtx, header.Hash(), watched)
+	if err != nil {
+		slog.DebugContext(ctx, "incoming transfer scan failed", "network", networkID, "block", header.Number.Uint64(), "error", err)
+	}
+	// 通知服务推送时会获取 s.mu，须在加锁前发送
+	s.notifyIncomingTransfers(queryCtx, networkID, transfers)
+	balances := make(map[common.Address]*big.Int, len(watched))
+	for addr := range watched {
+		balance, err := watcher.adapter.GetBalance(queryCtx, addr.Hex())
+		if err != nil {
+			slog.DebugContext(ctx, "balance lookup failed", "network", networkID, "address", addr.Hex(), "error", err)
+			continue
+		}
+		balances[addr] = balance
+	}
+
+	blockNumber := header.Number.Uint64()
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range watcher.subs {
+		known := sub.balances[networkID]
+		for i := range transfers {
+			to := common.HexToAddress(transfers[i].To)
+			if _, ok := known[to]; !ok {
+				continue
+			}
+			transfer := transfers[i]
+			sub.push(&RealtimeEvent{
+				Type:        RealtimeEventIncomingTransfer,
+				Network:     networkID,
+				Address:     to.Hex(),
+				Transfer:    &transfer,
+				BlockNumber: blockNumber,
+				Timestamp:   now,
+			})
+		}
+		for addr, last := range known {
+			balance, ok := balances[addr]
+			if !ok || (last != nil && last.Cmp(balance) == 0) {
+				continue
+			}
+			known[addr] = balance
+			sub.push(&RealtimeEvent{
+				Type:        RealtimeEventBalance,
+				Network:     networkID,
+				Address:     addr.Hex(),
+				Balance:     balance.String(),
+				BlockNumber: blockNumber,
+				Timestamp:   now,
+			})
+		}
+	}
+}
+
+// notifyIncomingTransfers 为每笔到账向接收地址发送 incoming_transfer 通知（每笔一次，与订阅数无关）
+func (s *RealtimeService) notifyIncomingTransfers(ctx context.Context, networkID string, transfers []core.IncomingTransfer) {
+	s.mu.Lock()
+	notifications := s.notifications
+	s.mu.Unlock()
+	if notifications == nil {
+		return
+	}
+	currency := core.NativeCurrencyFor(networkID)
+	for _, transfer := range transfers {
+		_, err := notifications.Send(ctx, transfer.To, core.Notification{
+			Type:    NotificationTypeIncomingTransfer,
+			Title:   "收到转账",
+			Message: fmt.Sprintf("%s 上收到 %s，来自 %s", networkID, currency.Format(transfer.Value), transfer.From),
+			Data: map[string]interface{}{
+				"network":      networkID,
+				"tx_hash":      transfer.TxHash,
+				"from":         transfer.From,
+				"to":           transfer.To,
+				"value":        transfer.Value.String(),
+				"block_number": transfer.BlockNumber,
+			},
+		})
+		if err != nil {
+			slog.DebugContext(ctx, "incoming transfer notification failed", "network", networkID, "tx_hash", transfer.TxHash, "error", err)
+		}
+	}
+}
+
+// NotifyAddress 向订阅了该地址（任意网络）的客户端推送事件，返回投递的订阅数
+func (s *RealtimeService) NotifyAddress(address string, event *RealtimeEvent) int {
+	if !common.IsHexAddress(address) {
+		return 0
+	}
+	addr := common.HexToAddress(address)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delivered := make(map[*RealtimeSubscription]bool)
+	for _, watcher := range s.watchers {
+		for sub := range watcher.subs {
+			if delivered[sub] {
+				continue
+			}
+			for _, addrs := range sub.balances {
+				if _, ok := addrs[addr]; ok {
+					sub.push(event)
+					delivered[sub] = true
+					break
+				}
+			}
+		}
+	}
+	return len(delivered)
+}
+
+// push 非阻塞投递事件，通道满时丢弃（调用方持有 s.mu）
+func (sub *RealtimeSubscription) push(event *RealtimeEvent) {
+	if sub.closed {
+		return
+	}
+	select {
+	case sub.events <- event:
+	default:
+		slog.DebugContext(context.Background(), "realtime event channel full, dropping event", "type", event.Type, "address", event.Address)
+	}
+}
+
+// addressCount 订阅的地址总数（调用方持有 s.mu）
+func (sub *RealtimeSubscription) addressCount() int {
+	count := 0
+	for _, addrs := range sub.balances {
+		count += len(addrs)
+	}
+	return count
+}
@@ -0,0 +1,541 @@
+/*
+ERC20 转账代付Gas（ERC-2771 元交易）
+
+新钱包常持有代币但没有原生币支付Gas，无法转出。代付流程：
+1. BuildForwardRequest：按当前网络配置的可信转发合约构造转发请求（代币 transfer 调用）及其 EIP-712 typed data
+2. 用户用钱包（或 POST /api/v1/sign/typed）签署 typed data
+3. RelayTransaction：校验转发合约、nonce、签名与用户配额后，由中继账户调用 execute() 提交并支付Gas
+
+支持范围（见 config.yaml 的 relay 配置）：
+- 仅 relay.forwarders 中配置的网络、转发合约（兼容 OpenZeppelin MinimalForwarder v4.x）与代币
+- 代币需继承 ERC2771Context 并信任该转发合约（isTrustedForwarder），构造请求时会在链上检查；普通 ERC20 无法代付
+- 仅支持 ERC20 transfer，不转发任意合约调用
+
+代币配置了手续费时，额外构造一笔向手续费收款地址转账的转发请求（nonce+1），与转账一起签署；
+中继账户先提交转账、再提交手续费，两笔交易使用连续的中继账户 nonce。
+每个用户24小时内的代付次数受 relay.daily_quota 限制，记录保存在 relay_transactions 表。
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+	"wallet/config"
+	"wallet/core"
+	"wallet/database"
+	"wallet/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// 转发请求种类
+const (
+	RelayRequestTransfer = "transfer" // 用户的代币转账
+	RelayRequestFee      = "fee"      // 按代币收取的代付手续费
+)
+
+// relayForwardOverheadGas execute() 在内部调用之外的 gas 开销（签名校验、nonce 更新）
+const relayForwardOverheadGas = 60000
+
+// 代付错误
+var (
+	ErrRelayDisabled      = errors.New("未启用代付Gas（未配置中继账户）")
+	ErrRelayNotSupported  = errors.New("当前网络或代币不支持代付Gas")
+	ErrRelayQuotaExceeded = errors.New("24小时内的代付次数已用完")
+)
+
+// RelayToken 支持代付的代币
+type RelayToken struct {
+	Network   string `json:"network"`
+	ChainID   int64  `json:"chain_id"`
+	Forwarder string `json:"forwarder"` // 可信转发合约
+	Token     string `json:"token"`
+	Fee       string `json:"fee"` // 每笔手续费（代币最小单位），"0" 表示免费
+}
+
+// RelayDraftRequest 待用户签署的转发请求
+type RelayDraftRequest struct {
+	Kind      string              `json:"kind"` // transfer / fee
+	Request   core.ForwardRequest `json:"request"`
+	TypedData apitypes.TypedData  `json:"typed_data"` // eth_signTypedData_v4 的签名内容
+}
+
+// RelayDraft BuildForwardRequest 的结果，用户按顺序签署 Requests 后提交 RelayTransaction
+type RelayDraft struct {
+	Network        string              `json:"network"`
+	ChainID        int64               `json:"chain_id"`
+	Forwarder      string              `json:"forwarder"`
+	Token          string              `json:"token"`
+	Recipient      string              `json:"recipient"`
+	Amount         string              `json:"amount"`
+	Fee            string              `json:"fee"`
+	FeeRecipient   string              `json:"fee_recipient,omitempty"`
+	Requests       []RelayDraftRequest `json:"requests"`
+	QuotaRemaining int                 `json:"quota_remaining"`
+}
+
+// SignedForwardRequest 用户签署后的转发请求
+type SignedForwardRequest struct {
+	Request   core.ForwardRequest `json:"request"`
+	Signature string              `json:"signature"` // 65字节签名（v 为 27/28 或 0/1）
+}
+
+// RelayTransfer 转发请求中的代币转账
+type RelayTransfer struct {
+	Token     string
+	Recipient string
+	Amount    *big.Int
+}
+
+// RelayResult 代付提交结果
+type RelayResult struct {
+	Network        string `json:"network"`
+	Forwarder      string `json:"forwarder"`
+	Relayer        string `json:"relayer"`
+	Token          string `json:"token"`
+	Recipient      string `json:"recipient"`
+	Amount         string `json:"amount"`
+	Fee            string `json:"fee"`
+	TxHash         string `json:"tx_hash"`
+	FeeTxHash      string `json:"fee_tx_hash,omitempty"`
+	QuotaRemaining int    `json:"quota_remaining"`
+}
+
+// RelayService 代付Gas服务
+type RelayService struct {
+	multiChain *core.MultiChainManager
+	cfg        config.RelayConfig
+	relayer    core.Signer // 未配置私钥时为 nil
+	mu         sync.Mutex  // 串行化配额检查与中继账户的交易提交
+}
+
+// NewRelayService 创建代付Gas服务，中继账户私钥无效时不启用
+func NewRelayService(multiChain *core.MultiChainManager, cfg config.RelayConfig) *RelayService {
+	s := &RelayService{multiChain: multiChain, cfg: cfg.WithDefaults()}
+	if strings.TrimSpace(cfg.RelayerPrivateKey) != "" {
+		relayer, err := core.NewKeySigner(cfg.RelayerPrivateKey)
+		if err != nil {
+			slog.WarnContext(context.Background(), "invalid relayer key, gas relay disabled", "error", err)
+		} else {
+			s.relayer = relayer
+		}
+	}
+	return s
+}
+
+// SupportedTokens 列出已配置的代付网络、转发合约与代币
+func (s *RelayService) SupportedTokens() []RelayToken {
+	tokens := []RelayToken{}
+	if s.relayer == nil {
+		return tokens
+	}
+	for networkID, forwarder := range s.cfg.Forwarders {
+		network, err := config.GetNetwork(networkID)
+		if err != nil || !common.IsHexAddress(forwarder.Address) {
+			continue
+		}
+		for _, token := range forwarder.Tokens {
+			if !common.IsHexAddress(token.Address) {
+				continue
+			}
+			tokens = append(tokens, RelayToken{
+				Network:   networkID,
+				ChainID:   network.ChainID,
+				Forwarder: common.HexToAddress(forwarder.Address).Hex(),
+				Token:     common.HexToAddress(token.Address).Hex(),
+				Fee:       relayFee(token).String(),
+			})
+		}
+	}
+	return tokens
+}
+
+// BuildForwardRequest 为 owner 构造代币转账（及手续费）的转发请求，返回待签署的 typed data
+func (s *RelayService) BuildForwardRequest(ctx context.Context, owner, token, to string, amount *big.Int) (*RelayDraft, error) {
+	if !common.IsHexAddress(to) {
+		return nil, fmt.Errorf("接收地址格式不正确: %s", to)
+	}
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("转账金额必须大于0")
+	}
+	target, err := s.resolveTarget(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	remaining, err := s.quotaRemaining(owner)
+	if err != nil {
+		return nil, err
+	}
+	if remaining <= 0 {
+		return nil, ErrRelayQuotaExceeded
+	}
+
+	fee := relayFee(target.token)
+	if err := s.checkTokenBalance(ctx, target, owner, amount, fee); err != nil {
+		return nil, err
+	}
+	nonce, err := target.evm.ForwarderNonce(ctx, target.domain.Address, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	draft := &RelayDraft{
+		Network:        target.network,
+		ChainID:        target.chainID.Int64(),
+		Forwarder:      target.domain.Address,
+		Token:          target.tokenAddress,
+		Recipient:      common.HexToAddress(to).Hex(),
+		Amount:         amount.String(),
+		Fee:            fee.String(),
+		QuotaRemaining: remaining,
+	}
+	transfer, err := s.draftTransfer(ctx, target, owner, to, amount, nonce)
+	if err != nil {
+		return nil, err
+	}
+	draft.Requests = append(draft.Requests, RelayDraftRequest{Kind: RelayRequestTransfer, Request: transfer})
+	if fee.Sign() > 0 {
+		draft.FeeRecipient = s.feeRecipient().Hex()
+		feeReq, err := s.draftTransfer(ctx, target, owner, draft.FeeRecipient, fee, new(big.Int).Add(nonce, big.NewInt(1)))
+		if err != nil {
+			return nil, err
+		}
+		draft.Requests = append(draft.Requests, RelayDraftRequest{Kind: RelayRequestFee, Request: feeReq})
+	}
+	for i := range draft.Requests {
+		draft.Requests[i].TypedData = core.ForwardRequestTypedData(target.domain, target.chainID, draft.Requests[i].Request)
+	}
+	return draft, nil
+}
+
+// DecodeRelayTransfer 解析签名请求中的代币转账（第一笔转发请求），不访问链上状态
+func DecodeRelayTransfer(requests []SignedForwardRequest) (*RelayTransfer, error) {
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("缺少转发请求")
+	}
+	data, err := hexutil.Decode(requests[0].Request.Data)
+	if err != nil {
+		return nil, fmt.Errorf("转发请求的 data 不是有效的十六进制: %w", err)
+	}
+	recipient, amount, err := core.DecodeERC20TransferCallData(data)
+	if err != nil {
+		return nil, err
+	}
+	return &RelayTransfer{
+		Token:     common.HexToAddress(requests[0].Request.To).Hex(),
+		Recipient: recipient.Hex(),
+		Amount:    amount,
+	}, nil
+}
+
+// RelayTransaction 校验 owner 签署的转发请求并由中继账户提交，手续费请求在转账之后提交
+func (s *RelayService) RelayTransaction(ctx context.Context, owner string, requests []SignedForwardRequest) (*RelayResult, error) {
+	transfer, err := DecodeRelayTransfer(requests)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.resolveTarget(ctx, transfer.Token)
+	if err != nil {
+		return nil, err
+	}
+	fee := relayFee(target.token)
+	wantRequests := 1
+	if fee.Sign() > 0 {
+		wantRequests = 2
+	}
+	if len(requests) != wantRequests {
+		return nil, fmt.Errorf("该代币需要提交 %d 个转发请求，实际 %d 个", wantRequests, len(requests))
+	}
+	if transfer.Amount.Sign() <= 0 {
+		return nil, fmt.Errorf("转账金额必须大于0")
+	}
+
+	// 逐个校验转发请求的字段与签名
+	signatures := make([][]byte, len(requests))
+	for i, signed := range requests {
+		if err := s.checkForwardRequest(target, owner, signed.Request); err != nil {
+			return nil, err
+		}
+		signer, err := core.RecoverForwardRequestSigner(target.domain, target.chainID, signed.Request, signed.Signature)
+		if err != nil {
+			return nil, err
+		}
+		if signer != common.HexToAddress(owner) {
+			return nil, fmt.Errorf("转发请求的签名者 %s 与当前钱包不一致", signer.Hex())
+		}
+		if signatures[i], err = core.NormalizeForwardSignature(signed.Signature); err != nil {
+			return nil, err
+		}
+	}
+	if len(requests) == 2 {
+		data, _ := hexutil.Decode(requests[1].Request.Data)
+		feeTo, feeAmount, err := core.DecodeERC20TransferCallData(data)
+		if err != nil || feeTo != s.feeRecipient() || feeAmount.Cmp(fee) != 0 {
+			return nil, fmt.Errorf("手续费请求应向 %s 转账 %s", s.feeRecipient().Hex(), fee.String())
+		}
+		first, _ := new(big.Int).SetString(requests[0].Request.Nonce, 10)
+		second, _ := new(big.Int).SetString(requests[1].Request.Nonce, 10)
+		if new(big.Int).Sub(second, first).Cmp(big.NewInt(1)) != 0 {
+			return nil, fmt.Errorf("手续费请求的 nonce 应为转账请求的 nonce+1")
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	remaining, err := s.quotaRemaining(owner)
+	if err != nil {
+		return nil, err
+	}
+	if remaining <= 0 {
+		return nil, ErrRelayQuotaExceeded
+	}
+	nonce, err := target.evm.ForwarderNonce(ctx, target.domain.Address, owner)
+	if err != nil {
+		return nil, err
+	}
+	if nonce.String() != requests[0].Request.Nonce {
+		return nil, fmt.Errorf("转发请求的 nonce 已失效（当前为 %s），请重新构造并签名", nonce.String())
+	}
+	valid, err := target.evm.VerifyForwardRequest(ctx, target.domain.Address, requests[0].Request, signatures[0])
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, fmt.Errorf("转发合约校验签名未通过")
+	}
+	if err := s.checkTokenBalance(ctx, target, owner, transfer.Amount, fee); err != nil {
+		return nil, err
+	}
+	gasLimit, err := target.evm.SimulateForward(ctx, s.relayer.Address(), target.domain.Address, requests[0].Request, signatures[0])
+	if err != nil {
+		return nil, err
+	}
+
+	// 两笔交易使用连续的中继账户 nonce；手续费请求依赖转账执行后的转发合约 nonce，无法预先估算，按请求的 gas 计算上限
+	relayerNonce, _, err := target.evm.GetNonces(ctx, s.relayer.Address().Hex())
+	if err != nil {
+		return nil, err
+	}
+	txHash, err := s.submit(ctx, target, requests[0].Request, signatures[0], gasLimit+gasLimit/10, relayerNonce)
+	if err != nil {
+		return nil, err
+	}
+	result := &RelayResult{
+		Network:        target.network,
+		Forwarder:      target.domain.Address,
+		Relayer:        s.relayer.Address().Hex(),
+		Token:          target.tokenAddress,
+		Recipient:      transfer.Recipient,
+		Amount:         transfer.Amount.String(),
+		Fee:            fee.String(),
+		TxHash:         txHash,
+		QuotaRemaining: remaining - 1,
+	}
+	if len(requests) == 2 {
+		feeGas := requests[1].Request.Gas*64/63 + relayForwardOverheadGas
+		result.FeeTxHash, err = s.submit(ctx, target, requests[1].Request, signatures[1], feeGas, relayerNonce+1)
+		if err != nil {
+			slog.DebugContext(ctx, "relay fee submission failed after transfer was submitted", "tx_hash", txHash, "error", err)
+		}
+	}
+
+	record := models.RelayTransaction{
+		OwnerAddress: strings.ToLower(owner),
+		Network:      target.network,
+		Forwarder:    target.domain.Address,
+		Token:        target.tokenAddress,
+		Recipient:    transfer.Recipient,
+		Amount:       result.Amount,
+		TxHash:       result.TxHash,
+		FeeTxHash:    result.FeeTxHash,
+	}
+	if fee.Sign() > 0 {
+		record.Fee = result.Fee
+	}
+	if err := database.DB.Create(&record).Error; err != nil {
+		slog.DebugContext(ctx, "relay record save failed", "tx_hash", txHash, "error", err)
+	}
+	return result, nil
+}
+
+// relayTarget 当前网络的转发合约与代币配置
+type relayTarget struct {
+	network      string
+	chainID      *big.Int
+	evm          *core.EVMAdapter
+	domain       core.ForwarderDomain
+	token        config.RelayTokenConfig
+	tokenAddress string
+}
+
+// resolveTarget 校验当前网络已配置转发合约、代币受支持且代币信任该转发合约
+func (s *RelayService) resolveTarget(ctx context.Context, token string) (*relayTarget, error) {
+	if s.relayer == nil {
+		return nil, ErrRelayDisabled
+	}
+	if !common.IsHexAddress(token) {
+		return nil, fmt.Errorf("代币地址格式不正确: %s", token)
+	}
+	networkID := s.multiChain.GetCurrentNetwork()
+	forwarder, ok := s.cfg.Forwarders[networkID]
+	if !ok || !common.IsHexAddress(forwarder.Address) {
+		return nil, fmt.Errorf("%w：网络 %s 未配置转发合约", ErrRelayNotSupported, networkID)
+	}
+	target := &relayTarget{
+		network:      networkID,
+		tokenAddress: common.HexToAddress(token).Hex(),
+		domain: core.ForwarderDomain{
+			Address: common.HexToAddress(forwarder.Address).Hex(),
+			Name:    forwarder.DomainName,
+			Version: forwarder.DomainVersion,
+		},
+	}
+	found := false
+	for _, configured := range forwarder.Tokens {
+		if strings.EqualFold(configured.Address, token) {
+			target.token = configured
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%w：代币 %s 不在 %s 的代付列表中", ErrRelayNotSupported, target.tokenAddress, networkID)
+	}
+
+	adapter, err := s.multiChain.GetAdapter(networkID)
+	if err != nil {
+		return nil, err
+	}
+	evm, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		return nil, fmt.Errorf("%w：网络 %s 不是EVM链", ErrRelayNotSupported, networkID)
+	}
+	target.evm = evm
+	if target.chainID, err = evm.VerifyChainID(ctx); err != nil {
+		return nil, err
+	}
+	code, err := evm.GetCode(ctx, target.domain.Address)
+	if err != nil {
+		return nil, err
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("%w：转发合约 %s 未部署", ErrRelayNotSupported, target.domain.Address)
+	}
+	trusted, err := evm.IsTrustedForwarder(ctx, target.tokenAddress, target.domain.Address)
+	if err != nil || !trusted {
+		return nil, fmt.Errorf("%w：代币 %s 未信任转发合约 %s", ErrRelayNotSupported, target.tokenAddress, target.domain.Address)
+	}
+	return target, nil
+}
+
+// draftTransfer 构造代币 transfer 的转发请求，gas 按 owner 直接转账的估算值加20%余量，不超过 relay.max_gas
+func (s *RelayService) draftTransfer(ctx context.Context, target *relayTarget, owner, to string, amount, nonce *big.Int) (core.ForwardRequest, error) {
+	data, err := core.ERC20TransferCallData(to, amount)
+	if err != nil {
+		return core.ForwardRequest{}, err
+	}
+	gas, err := target.evm.EstimateGas(ctx, owner, target.tokenAddress, big.NewInt(0), data)
+	if err != nil {
+		return core.ForwardRequest{}, err
+	}
+	gas += gas / 5
+	if gas > s.cfg.MaxGas {
+		return core.ForwardRequest{}, fmt.Errorf("代币转账需要约 %d gas，超过代付上限 %d", gas, s.cfg.MaxGas)
+	}
+	return core.ForwardRequest{
+		From:  common.HexToAddress(owner).Hex(),
+		To:    target.tokenAddress,
+		Value: "0",
+		Gas:   gas,
+		Nonce: nonce.String(),
+		Data:  hexutil.Encode(data),
+	}, nil
+}
+
+// checkForwardRequest 校验转发请求只转发 owner 对该代币的 transfer，不携带原生币且 gas 不超过上限
+func (s *RelayService) checkForwardRequest(target *relayTarget, owner string, req core.ForwardRequest) error {
+	if !common.IsHexAddress(req.From) || common.HexToAddress(req.From) != common.HexToAddress(owner) {
+		return fmt.Errorf("转发请求的 from 必须为当前钱包地址")
+	}
+	if !common.IsHexAddress(req.To) || common.HexToAddress(req.To).Hex() != target.tokenAddress {
+		return fmt.Errorf("转发请求的 to 必须为代币合约 %s", target.tokenAddress)
+	}
+	if req.Value != "0" {
+		return fmt.Errorf("代付转发请求不能携带原生币")
+	}
+	if req.Gas == 0 || req.Gas > s.cfg.MaxGas {
+		return fmt.Errorf("转发请求的 gas 需在 1-%d 之间", s.cfg.MaxGas)
+	}
+	data, err := hexutil.Decode(req.Data)
+	if err != nil {
+		return fmt.Errorf("转发请求的 data 不是有效的十六进制: %w", err)
+	}
+	_, _, err = core.DecodeERC20TransferCallData(data)
+	return err
+}
+
+// checkTokenBalance 校验 owner 的代币余额足以支付转账金额与手续费
+func (s *RelayService) checkTokenBalance(ctx context.Context, target *relayTarget, owner string, amount, fee *big.Int) error {
+	balance, err := target.evm.GetERC20Balance(ctx, target.tokenAddress, owner)
+	if err != nil {
+		return err
+	}
+	need := new(big.Int).Add(amount, fee)
+	if balance.Cmp(need) < 0 {
+		return fmt.Errorf("代币余额不足：需要 %s（含手续费 %s），当前 %s", need.String(), fee.String(), balance.String())
+	}
+	return nil
+}
+
+// submit 由中继账户调用转发合约 execute()
+func (s *RelayService) submit(ctx context.Context, target *relayTarget, req core.ForwardRequest, signature []byte, gasLimit, nonce uint64) (string, error) {
+	data, err := core.ForwardExecuteCallData(req, signature)
+	if err != nil {
+		return "", err
+	}
+	return target.evm.SendContractCallWithSigner(ctx, s.relayer, common.HexToAddress(target.domain.Address), data, nil, &core.TxOptions{
+		GasLimit: gasLimit,
+		Nonce:    &nonce,
+	})
+}
+
+// quotaRemaining owner 在最近24小时内剩余的代付次数
+func (s *RelayService) quotaRemaining(owner string) (int, error) {
+	if database.DB == nil {
+		return 0, errors.New("数据库未初始化")
+	}
+	var used int64
+	err := database.DB.Model(&models.RelayTransaction{}).
+		Where("owner_address = ? AND created_at >= ?", strings.ToLower(owner), time.Now().Add(-24*time.Hour)).
+		Count(&used).Error
+	if err != nil {
+		return 0, fmt.Errorf("查询代付次数失败: %w", err)
+	}
+	return s.cfg.DailyQuota - int(used), nil
+}
+
+// feeRecipient 手续费收款地址，未配置时为中继账户
+func (s *RelayService) feeRecipient() common.Address {
+	if common.IsHexAddress(s.cfg.FeeRecipient) {
+		return common.HexToAddress(s.cfg.FeeRecipient)
+	}
+	return s.relayer.Address()
+}
+
+// relayFee 代币配置的手续费，未配置或格式无效时为0
+func relayFee(token config.RelayTokenConfig) *big.Int {
+	fee, ok := new(big.Int).SetString(strings.TrimSpace(token.Fee), 10)
+	if !ok || fee.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return fee
+}
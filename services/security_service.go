@@ -7,11 +7,20 @@ package services
 
 import (
 	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math/big"
+	"strings"
 	"sync"
 	"time"
+	"wallet/config"
 	"wallet/core"
+	"wallet/database"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 // SecurityService 安全功能服务
@@ -19,6 +28,7 @@ type SecurityService struct {
 	securityManager *core.AdvancedSecurityManager   // 高级安全管理器
 	walletService   *WalletService                  // 钱包服务
 	activeSessions  map[string]*SecuritySessionInfo // 活跃安全会话
+	notifications   *NotificationService            // 多签审批通知，可为 nil
 	mu              sync.RWMutex                    // 读写锁
 }
 
@@ -51,11 +61,12 @@ type HardwareWalletResponse struct {
 
 // MultiSigWalletRequest 多签钱包创建请求
 type MultiSigWalletRequest struct {
-	Name          string                  `json:"name" binding:"required"`
-	Threshold     int                     `json:"threshold" binding:"required"`
-	Signers       []MultiSigSignerRequest `json:"signers" binding:"required"`
-	ChainID       string                  `json:"chain_id" binding:"required"`
-	Configuration MultiSigConfigRequest   `json:"configuration"`
+	Name            string                  `json:"name" binding:"required"`
+	Threshold       int                     `json:"threshold" binding:"required"`
+	Signers         []MultiSigSignerRequest `json:"signers" binding:"required"`
+	ChainID         string                  `json:"chain_id" binding:"required"`
+	ContractAddress string                  `json:"contract_address" binding:"required"` // 已部署的 Safe 合约地址
+	Configuration   MultiSigConfigRequest   `json:"configuration"`
 }
 
 // MultiSigSignerRequest 多签签名者请求
@@ -81,9 +92,8 @@ type MultiSigConfigRequest struct {
 
 // MultiSigWalletResponse 多签钱包响应
 type MultiSigWalletResponse struct {
-	Wallet           *core.MultiSigWallet `json:"wallet"`             // 钱包信息
-	ContractAddress  string               `json:"contract_address"`   // 合约地址
-	DeploymentTxHash string               `json:"deployment_tx_hash"` // 部署交易哈希
+	Wallet          *core.MultiSigWallet `json:"wallet"`           // 钱包信息
+	ContractAddress string               `json:"contract_address"` // Safe 合约地址
 }
 
 // MultiSigTransactionRequest 多签交易请求
@@ -103,9 +113,9 @@ type MultiSigTransactionRequest struct {
 // MultiSigTransactionResponse 多签交易响应
 type MultiSigTransactionResponse struct {
 	Transaction    *core.MultiSigTransaction `json:"transaction"`     // 交易信息
+	SafeTxHash     string                    `json:"safe_tx_hash"`    // 签名者需要签名的 Safe 交易哈希
 	RequiredSigs   int                       `json:"required_sigs"`   // 需要的签名数
 	PendingSigners []string                  `json:"pending_signers"` // 待签名者
-	EstimatedGas   uint64                    `json:"estimated_gas"`   // 预估Gas
 }
 
 // SignTransactionRequest 签名交易请求
@@ -119,12 +129,47 @@ type SignTransactionRequest struct {
 	Comments      string `json:"comments"`
 }
 
+// ExecuteMultiSigRequest 执行多签交易请求
+type ExecuteMultiSigRequest struct {
+	WalletID       string `json:"wallet_id" binding:"required"`
+	TransactionID  string `json:"transaction_id" binding:"required"`
+	SessionID      string `json:"session_id" binding:"required"` // 执行者会话，用于支付Gas并提交交易
+	DerivationPath string `json:"derivation_path"`
+	MFACode        string `json:"mfa_code"` // 交易被判定为异常或超出执行者支出限额时需提交的双因素验证码
+	// 接收地址在执行者或全局黑名单中且处理方式为 warn 时，确认风险后置为 true 重新提交
+	ConfirmRecipient bool `json:"confirm_recipient"`
+}
+
+// MnemonicShard 助记词分片（分片数据为十六进制，由用户自行分发保管）
+type MnemonicShard struct {
+	ID          string `json:"id"`
+	Index       int    `json:"index" binding:"required"`
+	Threshold   int    `json:"threshold" binding:"required"`
+	TotalShards int    `json:"total_shards" binding:"required"`
+	Data        string `json:"data" binding:"required"`
+}
+
+// SplitMnemonicRequest 助记词分片请求
+type SplitMnemonicRequest struct {
+	SessionID   string `json:"session_id" binding:"required"` // 使用会话中的助记词
+	Threshold   int    `json:"threshold" binding:"required"`  // 恢复所需分片数
+	TotalShards int    `json:"total_shards" binding:"required"`
+}
+
+// ReconstructMnemonicRequest 分片恢复请求
+type ReconstructMnemonicRequest struct {
+	Shards         []MnemonicShard `json:"shards" binding:"required"`
+	DerivationPath string          `json:"derivation_path"`
+	MFACode        string          `json:"mfa_code"` // 已启用双因素认证时必填
+}
+
 // MFASetupRequest MFA设置请求
 type MFASetupRequest struct {
 	MFAType     string `json:"mfa_type" binding:"required"` // TOTP, SMS, Email
 	PhoneNumber string `json:"phone_number"`
 	Email       string `json:"email"`
 	BackupCodes bool   `json:"backup_codes"`
+	CurrentCode string `json:"current_code"` // 已启用TOTP时更换密钥需提交当前验证码或备用码
 }
 
 // MFASetupResponse MFA设置响应
@@ -176,11 +221,19 @@ type RiskAnalysis struct {
 
 // NewSecurityService 创建安全功能服务
 func NewSecurityService(walletService *WalletService) *SecurityService {
-	return &SecurityService{
-		securityManager: core.NewAdvancedSecurityManager(),
+	securityManager := core.NewAdvancedSecurityManager(walletService.multiChain)
+	// 数据库可用时审计日志与 TOTP 配置写穿到数据库，重启后仍然有效
+	if database.DB != nil {
+		securityManager.SetAuditLogStore(dbAuditLogStore{})
+		securityManager.SetTOTPStore(dbTOTPStore{cryptoManager: walletService.cryptoManager})
+	}
+	ss := &SecurityService{
+		securityManager: securityManager,
 		walletService:   walletService,
 		activeSessions:  make(map[string]*SecuritySessionInfo),
 	}
+	ss.initAddressBlocklist()
+	return ss
 }
 
 // DetectHardwareWallets 检测硬件钱包
@@ -289,57 +342,71 @@ func (ss *SecurityService) CreateMultiSigWallet(ctx context.Context, userAddress
 		}
 	}
 
-	// 创建多签钱包
-	wallet, err := ss.securityManager.CreateMultiSigWallet(ctx, config, signers, request.Threshold)
+	// 登记已部署的 Safe 合约（链上校验阈值与 owner）
+	wallet, err := ss.securityManager.CreateMultiSigWallet(ctx, request.Name, request.ChainID, request.ContractAddress, userAddress, config, signers, request.Threshold)
 	if err != nil {
 		return nil, fmt.Errorf("创建多签钱包失败: %w", err)
 	}
 
-	// 设置钱包名称和链ID
-	wallet.Name = request.Name
-	wallet.ChainID = request.ChainID
-
-	// 生成合约地址（简化实现）
-	contractAddress := "0x" + fmt.Sprintf("%040x", time.Now().UnixNano())
-	wallet.ContractAddress = contractAddress
-
 	response := &MultiSigWalletResponse{
-		Wallet:           wallet,
-		ContractAddress:  contractAddress,
-		DeploymentTxHash: "0x" + fmt.Sprintf("%064x", time.Now().UnixNano()),
+		Wallet:          wallet,
+		ContractAddress: wallet.ContractAddress,
 	}
 	return response, nil
 }
 
 // CreateMultiSigTransaction 创建多签交易
+// 指定 token_address 时构造 ERC20 transfer 调用（交易发往代币合约，金额为 token_amount），否则按 to/value/data 原样发起
 func (ss *SecurityService) CreateMultiSigTransaction(ctx context.Context, userAddress string, request *MultiSigTransactionRequest) (*MultiSigTransactionResponse, error) {
-	// 验证钱包ID
-	// 这里简化实现，实际应该从数据库获取钱包信息
+	if !ss.walletService.IsValidAddress(request.To) {
+		return nil, fmt.Errorf("无效的接收地址: %s", request.To)
+	}
 
 	// 解析金额
 	value := big.NewInt(0)
 	if request.Value != "" {
 		var ok bool
 		value, ok = new(big.Int).SetString(request.Value, 10)
-		if !ok {
+		if !ok || value.Sign() < 0 {
 			return nil, fmt.Errorf("无效的金额格式")
 		}
 	}
 
-	// 创建交易
-	txID := ss.generateTransactionID()
+	var data []byte
+	if request.Data != "" {
+		var err error
+		data, err = hexutil.Decode(request.Data)
+		if err != nil {
+			return nil, fmt.Errorf("无效的交易数据: %w", err)
+		}
+	}
+
+	to := request.To
+	if request.TokenAddress != "" {
+		if !ss.walletService.IsValidAddress(request.TokenAddress) {
+			return nil, fmt.Errorf("无效的代币地址: %s", request.TokenAddress)
+		}
+		if len(data) > 0 || value.Sign() > 0 {
+			return nil, fmt.Errorf("代币转账不能同时指定 value 或 data")
+		}
+		amount, ok := new(big.Int).SetString(request.TokenAmount, 10)
+		if !ok || amount.Sign() <= 0 {
+			return nil, fmt.Errorf("无效的代币数量")
+		}
+		var err error
+		data, err = core.ERC20TransferData(request.To, amount)
+		if err != nil {
+			return nil, err
+		}
+		to = request.TokenAddress
+	}
+
 	transaction := &core.MultiSigTransaction{
-		ID:           txID,
-		Title:        request.Title,
-		Description:  request.Description,
-		To:           request.To,
-		Value:        value,
-		Status:       "pending",
-		RequiredSigs: 2, // 简化实现
-		CurrentSigs:  0,
-		CreatedBy:    userAddress,
-		CreatedAt:    time.Now(),
-		Signatures:   make([]core.MultiSigSignature, 0),
+		Title:       request.Title,
+		Description: request.Description,
+		To:          to,
+		Value:       value,
+		Data:        data,
 	}
 
 	// 设置时间锁
@@ -354,11 +421,24 @@ func (ss *SecurityService) CreateMultiSigTransaction(ctx context.Context, userAd
 		transaction.ExpiresAt = &expires
 	}
 
+	created, err := ss.securityManager.ProposeMultiSigTransaction(ctx, request.WalletID, userAddress, transaction)
+	if err != nil {
+		return nil, err
+	}
+	safeTxHash, err := ss.securityManager.MultiSigSafeTxHash(request.WalletID, created.ID)
+	if err != nil {
+		return nil, err
+	}
+	state, err := ss.securityManager.MultiSigApprovalState(request.WalletID, created.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	response := &MultiSigTransactionResponse{
-		Transaction:    transaction,
-		RequiredSigs:   transaction.RequiredSigs,
-		PendingSigners: []string{"0x1111111111111111111111111111111111111111", "0x2222222222222222222222222222222222222222"},
-		EstimatedGas:   21000,
+		Transaction:    created,
+		SafeTxHash:     safeTxHash.Hex(),
+		RequiredSigs:   created.RequiredSigs,
+		PendingSigners: state.PendingSigners,
 	}
 
 	return response, nil
@@ -387,22 +467,164 @@ func (ss *SecurityService) SignMultiSigTransaction(ctx context.Context, request
 		return fmt.Errorf("签名失败: %w", err)
 	}
 
+	ss.notifyMultiSigApproval(ctx, request.WalletID, request.TransactionID, request.SignerAddress)
 	return nil
 }
 
+// SetNotificationService 设置多签审批通知使用的通知服务
+func (ss *SecurityService) SetNotificationService(notifications *NotificationService) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.notifications = notifications
+}
+
+// notifyMultiSigApproval 签名后通知：未达阈值时提醒尚未签名的签名者，达到阈值时通知全部签名者与发起人可以执行
+func (ss *SecurityService) notifyMultiSigApproval(ctx context.Context, walletID, txID, signer string) {
+	ss.mu.RLock()
+	notifications := ss.notifications
+	ss.mu.RUnlock()
+	if notifications == nil {
+		return
+	}
+	state, err := ss.securityManager.MultiSigApprovalState(walletID, txID)
+	if err != nil {
+		return
+	}
+
+	title := state.Title
+	if title == "" {
+		title = state.TransactionID
+	}
+	data := map[string]interface{}{
+		"wallet_id":      state.WalletID,
+		"transaction_id": state.TransactionID,
+		"signer":         signer,
+		"current_sigs":   state.CurrentSigs,
+		"threshold":      state.Threshold,
+		"status":         state.Status,
+	}
+	notification := core.Notification{Type: NotificationTypeMultiSigApproval, Data: data}
+	var recipients []string
+	if state.Status == core.MultiSigStatusReady {
+		notification.Title = "多签交易可以执行"
+		notification.Message = fmt.Sprintf("多签钱包 %s 的交易「%s」已收集 %d/%d 个签名，可以执行", state.WalletName, title, state.CurrentSigs, state.Threshold)
+		notification.Priority = NotificationPriorityHigh
+		recipients = append(recipients, state.Signers...)
+		if common.IsHexAddress(state.CreatedBy) {
+			recipients = append(recipients, state.CreatedBy)
+		}
+	} else {
+		notification.Title = "多签交易等待您的签名"
+		notification.Message = fmt.Sprintf("%s 已签名多签钱包 %s 的交易「%s」（%d/%d），等待您的签名", signer, state.WalletName, title, state.CurrentSigs, state.Threshold)
+		recipients = state.PendingSigners
+	}
+
+	notified := make(map[common.Address]bool)
+	for _, recipient := range recipients {
+		addr := common.HexToAddress(recipient)
+		if notified[addr] {
+			continue
+		}
+		notified[addr] = true
+		if _, err := notifications.Send(ctx, addr.Hex(), notification); err != nil {
+			slog.DebugContext(ctx, "multisig approval notification failed", "wallet_id", walletID, "tx_id", txID, "error", err)
+		}
+	}
+}
+
+// ExecuteMultiSigTransaction 使用会话账户作为执行者，将达到阈值的多签交易提交到链上
+func (ss *SecurityService) ExecuteMultiSigTransaction(ctx context.Context, request *ExecuteMultiSigRequest) (string, error) {
+	executor, err := ss.walletService.SessionSigner(request.SessionID, request.DerivationPath)
+	if err != nil {
+		return "", fmt.Errorf("无效会话: %w", err)
+	}
+
+	txHash, err := ss.securityManager.ExecuteMultiSigTransaction(ctx, request.WalletID, request.TransactionID, executor)
+	if err != nil {
+		return "", fmt.Errorf("执行失败: %w", err)
+	}
+	return txHash, nil
+}
+
+// MultiSigPendingTransfer 待执行多签交易的网络与资金流向，发送方为执行者会话的签名地址，供执行前校验
+func (ss *SecurityService) MultiSigPendingTransfer(request *ExecuteMultiSigRequest) (*core.PendingTransfer, error) {
+	executor, err := ss.walletService.SessionSigner(request.SessionID, request.DerivationPath)
+	if err != nil {
+		return nil, fmt.Errorf("无效会话: %w", err)
+	}
+	return ss.securityManager.MultiSigPendingTransfer(request.WalletID, request.TransactionID, executor.Address().Hex())
+}
+
+// SplitMnemonic 将会话助记词拆分为 Shamir 分片，服务端仅登记分片元数据
+func (ss *SecurityService) SplitMnemonic(ctx context.Context, request *SplitMnemonicRequest) ([]MnemonicShard, error) {
+	mnemonic, err := ss.walletService.GetSessionMnemonic(request.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("无效会话: %w", err)
+	}
+	userAddress, err := ss.walletService.GetSessionAddress(request.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("无效会话: %w", err)
+	}
+
+	shards, err := ss.securityManager.CreateMnemonicShards(ctx, userAddress, mnemonic, request.Threshold, request.TotalShards)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]MnemonicShard, len(shards))
+	for i, shard := range shards {
+		out[i] = MnemonicShard{
+			ID:          shard.ID,
+			Index:       shard.ShardIndex,
+			Threshold:   shard.Threshold,
+			TotalShards: shard.TotalShards,
+			Data:        hex.EncodeToString(shard.ShardData),
+		}
+	}
+	return out, nil
+}
+
+// ReconstructMnemonic 由分片恢复助记词
+// 不在此创建会话：调用方须按助记词登录流程完成导入策略、双因素认证与设备登记后再建会话
+func (ss *SecurityService) ReconstructMnemonic(ctx context.Context, request *ReconstructMnemonicRequest) (string, error) {
+	shards := make([]core.KeyShard, len(request.Shards))
+	for i, shard := range request.Shards {
+		data, err := hex.DecodeString(strings.TrimPrefix(shard.Data, "0x"))
+		if err != nil {
+			return "", fmt.Errorf("分片 %d 数据不是有效的十六进制", shard.Index)
+		}
+		shards[i] = core.KeyShard{
+			ID:          shard.ID,
+			ShardIndex:  shard.Index,
+			ShardData:   data,
+			Threshold:   shard.Threshold,
+			TotalShards: shard.TotalShards,
+		}
+	}
+	return core.ReconstructMnemonic(shards)
+}
+
 // SetupMFA 设置多因素认证
 func (ss *SecurityService) SetupMFA(ctx context.Context, userAddress string, request *MFASetupRequest) (*MFASetupResponse, error) {
 	response := &MFASetupResponse{}
 
 	switch request.MFAType {
-	case "TOTP":
-		// 生成TOTP密钥
-		secret := ss.generateTOTPSecret()
-		qrCode := ss.generateQRCode(userAddress, secret)
+	case core.MFATypeTOTP:
+		// 生成TOTP密钥与一次性备用码，首次验证成功后生效
+		authManager := ss.securityManager.AuthManager()
+		secret, otpauthURL, err := authManager.EnableTOTP(userAddress, request.CurrentCode)
+		if err != nil {
+			return nil, err
+		}
+		backupCodes, err := authManager.GenerateBackupCodes(userAddress)
+		if err != nil {
+			return nil, err
+		}
 
 		response.Secret = secret
-		response.QRCode = qrCode
+		response.QRCode = otpauthURL
+		response.BackupCodes = backupCodes
 		response.SetupComplete = false
+		return response, nil
 
 	case "SMS":
 		if request.PhoneNumber == "" {
@@ -486,28 +708,93 @@ func (ss *SecurityService) GetSecurityAuditLogs(ctx context.Context, request *Se
 	return response, nil
 }
 
+// ErrAuditForbidden 非管理员查询其他用户的审计日志
+var ErrAuditForbidden = errors.New("只能查询自己的审计日志")
+
+// IsAuditAdmin 判断地址是否为可查询全部审计日志的管理员（config security.admin_addresses）
+func (ss *SecurityService) IsAuditAdmin(address string) bool {
+	if address == "" {
+		return false
+	}
+	for _, admin := range config.AppConfig.Security.AdminAddresses {
+		if strings.EqualFold(strings.TrimSpace(admin), address) {
+			return true
+		}
+	}
+	return false
+}
+
+// QueryAuditLogs 分页查询审计日志
+// 管理员可按任意用户过滤；其他用户只能查询自己的日志，未指定用户时默认为自己
+func (ss *SecurityService) QueryAuditLogs(requester string, filter core.AuditLogFilter) ([]core.AuditLog, int, error) {
+	if !ss.IsAuditAdmin(requester) {
+		if filter.UserAddress != "" && !strings.EqualFold(filter.UserAddress, requester) {
+			return nil, 0, ErrAuditForbidden
+		}
+		filter.UserAddress = requester
+	}
+	return ss.securityManager.QueryAuditLogs(filter)
+}
+
 // 私有方法
 
-// generateTransactionID 生成交易ID
-func (ss *SecurityService) generateTransactionID() string {
-	return fmt.Sprintf("tx_%d", time.Now().UnixNano())
+// 风险处置错误
+var (
+	ErrRiskDenied            = errors.New("操作因安全风险被拒绝")
+	ErrRiskChallengeRequired = errors.New("操作存在异常风险，需要提交双因素认证验证码")
+)
+
+// EvaluateRisk 评估登录/交易事件的风险（新设备、新地区、不可能的移动、大额交易），返回处置建议与命中的风险信号
+func (ss *SecurityService) EvaluateRisk(ctx context.Context, userAddress string, event core.SecurityEvent) (*core.RiskDecision, error) {
+	return ss.securityManager.EvaluateRisk(ctx, userAddress, event)
 }
 
-// verifyMFACode 验证MFA代码
-func (ss *SecurityService) verifyMFACode(userAddress, code string) bool {
-	// 简化实现：总是返回true
-	return true
+// RecordSecurityEvent 操作成功后记录事件，作为后续风险评估的基线
+func (ss *SecurityService) RecordSecurityEvent(userAddress string, event core.SecurityEvent) {
+	ss.securityManager.RecordSecurityEvent(userAddress, event)
+}
+
+// AuthorizeRiskyAction 评估风险并处置：deny 返回 ErrRiskDenied；
+// challenge 时已启用双因素认证的用户需提交有效验证码（否则返回 ErrRiskChallengeRequired），
+// 未启用双因素认证的用户无法额外验证，放行并由调用方在响应中提示风险
+func (ss *SecurityService) AuthorizeRiskyAction(ctx context.Context, userAddress string, event core.SecurityEvent, mfaCode string) (*core.RiskDecision, error) {
+	decision, err := ss.EvaluateRisk(ctx, userAddress, event)
+	if err != nil {
+		return nil, err
+	}
+	switch decision.Action {
+	case core.RiskActionDeny:
+		return decision, ErrRiskDenied
+	case core.RiskActionChallenge:
+		if ss.RequiresMFA(userAddress) && (mfaCode == "" || !ss.verifyMFACode(userAddress, mfaCode)) {
+			return decision, ErrRiskChallengeRequired
+		}
+	}
+	return decision, nil
 }
 
-// generateTOTPSecret 生成TOTP密钥
-func (ss *SecurityService) generateTOTPSecret() string {
-	return "JBSWY3DPEHPK3PXP" // 示例密钥
+// RequiresMFA 用户是否已启用TOTP，启用后登录需提交验证码
+func (ss *SecurityService) RequiresMFA(userAddress string) bool {
+	return ss.securityManager.AuthManager().IsTOTPEnabled(userAddress)
 }
 
-// generateQRCode 生成二维码
-func (ss *SecurityService) generateQRCode(userAddress, secret string) string {
-	// 简化实现：返回示例二维码URL
-	return fmt.Sprintf("otpauth://totp/Wallet:%s?secret=%s&issuer=Wallet", userAddress, secret)
+// VerifyMFACode 校验TOTP验证码，6位数字以外的输入按一次性备用码校验
+func (ss *SecurityService) VerifyMFACode(userAddress, code string) (bool, error) {
+	authManager := ss.securityManager.AuthManager()
+	valid, err := authManager.VerifyTOTP(userAddress, code)
+	if err != nil || valid {
+		return valid, err
+	}
+	if len(strings.TrimSpace(code)) == 6 {
+		return false, nil
+	}
+	return authManager.VerifyBackupCode(userAddress, code)
+}
+
+// verifyMFACode 验证MFA代码
+func (ss *SecurityService) verifyMFACode(userAddress, code string) bool {
+	valid, err := ss.VerifyMFACode(userAddress, code)
+	return err == nil && valid
 }
 
 // generateBackupCodes 生成备用代码
@@ -8,10 +8,14 @@ package services
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"math/big"
+	"strings"
 	"sync"
 	"time"
 	"wallet/core"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 // SocialService 社交功能服务
@@ -19,9 +23,13 @@ type SocialService struct {
 	socialManager *core.SocialManager     // 社交管理器
 	walletService *WalletService          // 钱包服务
 	userSessions  map[string]*UserSession // 用户会话
+	stopPurger    context.CancelFunc      // 停止过期分享清理协程
 	mu            sync.RWMutex            // 读写锁
 }
 
+// defaultSharePurgeInterval 过期分享清理间隔
+const defaultSharePurgeInterval = 10 * time.Minute
+
 // UserSession 用户会话
 type UserSession struct {
 	UserAddress  string              `json:"user_address"`  // 用户地址
@@ -178,7 +186,7 @@ type UserPrivacyRequest struct {
 // NewSocialService 创建社交功能服务
 func NewSocialService(walletService *WalletService) *SocialService {
 	return &SocialService{
-		socialManager: core.NewSocialManager(),
+		socialManager: core.NewSocialManager(walletService.multiChain),
 		walletService: walletService,
 		userSessions:  make(map[string]*UserSession),
 	}
@@ -351,31 +359,88 @@ func (ss *SocialService) ShareTransaction(ctx context.Context, userAddress strin
 		Watermark:     request.Privacy.Watermark,
 	}
 
+	var expiresAt *time.Time
+	if request.ExpiresIn > 0 {
+		t := time.Now().Add(time.Duration(request.ExpiresIn) * time.Second)
+		expiresAt = &t
+	}
+
 	// 创建分享记录
-	shareRecord, err := ss.socialManager.CreateShareRecord(ctx, shareContent, sharePrivacy)
+	shareRecord, err := ss.socialManager.CreateShareRecord(ctx, userAddress, shareContent, sharePrivacy, expiresAt)
 	if err != nil {
 		return nil, fmt.Errorf("创建分享记录失败: %w", err)
 	}
 
-	// 生成二维码（简化实现）
-	qrCode := fmt.Sprintf("data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg==")
-
 	// 生成短链接
 	shortURL := fmt.Sprintf("https://w.io/%s", shareRecord.ID[:8])
 
 	response := &ShareTransactionResponse{
 		ShareRecord: shareRecord,
 		ShareURL:    shareRecord.ShareURL,
-		QRCode:      qrCode,
+		QRCode:      shareRecord.QRCode,
 		ShortURL:    shortURL,
 	}
 
 	return response, nil
 }
 
-// GetShareRecord 获取分享记录
+// GetShareRecord 获取分享记录（只读，不计入查看统计）
 func (ss *SocialService) GetShareRecord(ctx context.Context, shareID string) (*core.ShareRecord, error) {
-	return ss.socialManager.GetShareRecord(ctx, shareID)
+	return ss.socialManager.GetShareRecord(ctx, shareID, nil)
+}
+
+// ViewShare 访问者查看分享：校验过期与隐私设置，计入查看统计并隐藏敏感字段
+// sessionID 有效时以会话所属地址作为访问者地址，否则视为未登录访问者
+func (ss *SocialService) ViewShare(ctx context.Context, shareID, sessionID string, viewer *core.ShareViewer) (*core.ShareRecord, error) {
+	if sessionID != "" {
+		if address, err := ss.walletService.GetSessionAddress(sessionID); err == nil {
+			viewer.Address = address
+			viewer.ViewerID = strings.ToLower(address)
+		}
+	}
+	return ss.socialManager.GetShareRecord(ctx, shareID, viewer)
+}
+
+// StartSharePurger 启动后台协程，每隔 interval 清理过期分享记录
+// 重复调用会先停止已有的清理协程，interval<=0 时使用默认值
+func (ss *SocialService) StartSharePurger(interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultSharePurgeInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ss.mu.Lock()
+	if ss.stopPurger != nil {
+		ss.stopPurger()
+	}
+	ss.stopPurger = cancel
+	ss.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if purged := ss.socialManager.PurgeExpiredShares(); purged > 0 {
+					slog.DebugContext(context.Background(), "expired shares purged", "count", purged)
+				}
+			}
+		}
+	}()
+}
+
+// Close 停止过期分享清理协程
+func (ss *SocialService) Close() {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if ss.stopPurger != nil {
+		ss.stopPurger()
+		ss.stopPurger = nil
+	}
 }
 
 // SocialNetworkAction 社交网络操作
@@ -445,6 +510,136 @@ func (ss *SocialService) SearchUsers(ctx context.Context, query string, limit in
 	return users, nil
 }
 
+// RecipientResolution 收款目标解析结果
+type RecipientResolution struct {
+	Input        string `json:"input"`                   // 原始输入
+	Address      string `json:"address"`                 // 解析得到的地址（校验和格式）
+	Source       string `json:"source"`                  // 解析来源：address / contact / ens
+	ContactID    string `json:"contact_id,omitempty"`    // 联系人ID（来源为 contact 时）
+	ContactName  string `json:"contact_name,omitempty"`  // 联系人名称，用于前端二次确认
+	AddressLabel string `json:"address_label,omitempty"` // 联系人地址标签
+	ENSName      string `json:"ens_name,omitempty"`      // ENS域名（来源为 ens 时）
+}
+
+// ContactRecipientPrefix 显式引用联系人的收款目标前缀，如 "contact:abc123"
+const ContactRecipientPrefix = "contact:"
+
+// ResolveRecipient 将地址、联系人ID或ENS域名解析为具体收款地址
+// "contact:<ID>" 只在 userAddress 的地址簿中查找；其余输入按 地址 → 联系人 → ENS 的顺序尝试
+// 联系人在当前网络有多个不同地址、ENS 未绑定地址或解析为零地址时返回错误，不会回退为零地址
+func (ss *SocialService) ResolveRecipient(ctx context.Context, userAddress, input string) (*RecipientResolution, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, fmt.Errorf("收款目标不能为空")
+	}
+
+	// 1. 显式联系人引用
+	if strings.HasPrefix(strings.ToLower(input), ContactRecipientPrefix) {
+		contactID := strings.TrimSpace(input[len(ContactRecipientPrefix):])
+		if contactID == "" {
+			return nil, fmt.Errorf("联系人ID不能为空")
+		}
+		if userAddress == "" {
+			return nil, fmt.Errorf("使用联系人作为收款目标需要登录")
+		}
+		contact, err := ss.socialManager.GetContact(ctx, userAddress, contactID)
+		if err != nil {
+			return nil, fmt.Errorf("联系人 %s 不存在", contactID)
+		}
+		return ss.contactResolution(input, contact)
+	}
+
+	// 2. 原始地址
+	if ss.walletService.IsValidAddress(input) {
+		addr := common.HexToAddress(input)
+		if addr == (common.Address{}) {
+			return nil, fmt.Errorf("收款地址不能为零地址")
+		}
+		return &RecipientResolution{
+			Input:   input,
+			Address: addr.Hex(),
+			Source:  "address",
+		}, nil
+	}
+
+	// 3. 联系人
+	if userAddress != "" {
+		if contact, err := ss.socialManager.GetContact(ctx, userAddress, input); err == nil {
+			return ss.contactResolution(input, contact)
+		}
+	}
+
+	// 4. ENS
+	if strings.Contains(input, ".") {
+		record, err := ss.socialManager.ResolveENS(ctx, strings.ToLower(input))
+		if err != nil {
+			return nil, fmt.Errorf("解析ENS失败: %w", err)
+		}
+		if !ss.walletService.IsValidAddress(record.Address) || common.HexToAddress(record.Address) == (common.Address{}) {
+			return nil, fmt.Errorf("ENS域名未绑定有效地址: %s", input)
+		}
+		return &RecipientResolution{
+			Input:   input,
+			Address: common.HexToAddress(record.Address).Hex(),
+			Source:  "ens",
+			ENSName: record.Name,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("无法解析收款目标: %s", input)
+}
+
+// contactResolution 选取联系人在当前网络的收款地址
+// 未标注网络的地址视为通用地址；候选地址去重后必须恰好一个，否则视为歧义
+func (ss *SocialService) contactResolution(input string, contact *core.Contact) (*RecipientResolution, error) {
+	network := ss.walletService.GetMultiChainManager().GetCurrentNetwork()
+	var picked *core.ContactAddress
+	for i := range contact.Addresses {
+		addr := &contact.Addresses[i]
+		if addr.Chain != "" && !strings.EqualFold(addr.Chain, network) {
+			continue
+		}
+		if !common.IsHexAddress(addr.Address) || common.HexToAddress(addr.Address) == (common.Address{}) {
+			continue
+		}
+		if picked != nil && common.HexToAddress(picked.Address) != common.HexToAddress(addr.Address) {
+			return nil, fmt.Errorf("联系人 %s 在网络 %s 上有多个地址，请直接指定收款地址", contact.Name, network)
+		}
+		if picked == nil {
+			picked = addr
+		}
+	}
+	if picked == nil {
+		return nil, fmt.Errorf("联系人 %s 在网络 %s 上没有可用地址", contact.Name, network)
+	}
+	return &RecipientResolution{
+		Input:        input,
+		Address:      common.HexToAddress(picked.Address).Hex(),
+		Source:       "contact",
+		ContactID:    contact.ID,
+		ContactName:  contact.Name,
+		AddressLabel: picked.Label,
+	}, nil
+}
+
+// ResolveENS 正向解析ENS域名，返回地址及文本记录
+func (ss *SocialService) ResolveENS(ctx context.Context, name string) (*core.ENSRecord, error) {
+	return ss.socialManager.ResolveENS(ctx, name)
+}
+
+// FetchENSAvatar 获取ENS域名的头像图片（ENSIP-12），返回内容与 Content-Type
+func (ss *SocialService) FetchENSAvatar(ctx context.Context, name string) ([]byte, string, error) {
+	return ss.socialManager.FetchENSAvatar(ctx, name)
+}
+
+// LookupENSName 反向解析地址的ENS主域名
+func (ss *SocialService) LookupENSName(ctx context.Context, address string) (string, error) {
+	if !ss.walletService.IsValidAddress(address) {
+		return "", fmt.Errorf("无效的地址格式: %s", address)
+	}
+	return ss.socialManager.ResolveAddress(ctx, address)
+}
+
 // 私有方法
 
 // buildContactResponse 构建联系人响应
@@ -0,0 +1,404 @@
+/*
+钱包支出限额
+
+按钱包地址配置每日/每月的美元支出上限：
+- 原生币与代币按发送时的美元价格折算，累计值持久化到 spending_records 表，重启不清零
+- 周期为限额时区下的自然日/自然月（见 core.SpendingWindow）
+- 发送前校验，超出限额（或无法获取价格）时拒绝；已启用双因素认证的用户可提交验证码超额发送
+- DApp/WalletConnect 确认交易、Swap 与多签执行同样在签名前校验，多签交易计入执行者的限额
+- 同一钱包的校验与额度预留串行执行：通过校验即写入预留记录，发送成功后补记交易哈希，失败时由 ReleaseSpending 删除
+- 放宽（调高或取消某个周期的限额）与删除限额需要双因素认证验证码（已启用时）
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+	"wallet/core"
+	"wallet/database"
+	"wallet/models"
+
+	"gorm.io/gorm"
+)
+
+// spendingRecordRetention 支出记录保留时长，需覆盖最长的统计周期（自然月）
+const spendingRecordRetention = 62 * 24 * time.Hour
+
+// 支出限额错误
+var (
+	ErrSpendingLimitNotFound    = errors.New("未设置支出限额")
+	ErrSpendingLimitMFARequired = errors.New("放宽或删除支出限额需要双因素认证验证码")
+)
+
+// SpendingWindowStatus 一个统计周期的限额使用情况
+type SpendingWindowStatus struct {
+	Period       string    `json:"period"`        // daily / monthly
+	LimitUSD     float64   `json:"limit_usd"`     // 限额
+	SpentUSD     float64   `json:"spent_usd"`     // 本周期已支出
+	RemainingUSD float64   `json:"remaining_usd"` // 剩余额度
+	StartsAt     time.Time `json:"starts_at"`     // 周期开始时间
+	ResetsAt     time.Time `json:"resets_at"`     // 下次重置时间
+}
+
+// SpendingLimitStatus 钱包支出限额及当前使用情况
+type SpendingLimitStatus struct {
+	Address         string                 `json:"address"`
+	DailyLimitUSD   float64                `json:"daily_limit_usd"`   // 0 表示不限制
+	MonthlyLimitUSD float64                `json:"monthly_limit_usd"` // 0 表示不限制
+	Timezone        string                 `json:"timezone"`
+	Windows         []SpendingWindowStatus `json:"windows"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+}
+
+// SpendingCheck 发送前的限额校验结果，通过校验时已预留额度
+// 发送成功后通过 RecordSpending 确认，发送失败时通过 ReleaseSpending 释放
+type SpendingCheck struct {
+	Owner    string                 `json:"-"`
+	Network  string                 `json:"network"`
+	Asset    string                 `json:"asset"`  // 代币地址，原生币为 native
+	Amount   string                 `json:"amount"` // 最小单位
+	ValueUSD float64                `json:"value_usd"`
+	Priced   bool                   `json:"priced"`             // 是否获取到美元价格
+	Exceeded []SpendingWindowStatus `json:"exceeded,omitempty"` // 超出的周期
+	Override bool                   `json:"override"`           // 已通过双因素认证超额发送
+
+	reservation uint // 预留记录ID，确认或释放后为 0
+}
+
+// GetSpendingLimit 查询钱包的支出限额及本周期使用情况
+func (s *WalletService) GetSpendingLimit(owner string) (*SpendingLimitStatus, error) {
+	limit, err := findSpendingLimit(owner)
+	if err != nil {
+		return nil, err
+	}
+	return spendingLimitStatus(limit, time.Now())
+}
+
+// SetSpendingLimit 设置钱包的支出限额，daily/monthly 为 0 表示该周期不限制，timezone 为空时使用 UTC
+// 调高或取消已有限额时，已启用双因素认证的用户需提交有效验证码
+func (s *WalletService) SetSpendingLimit(owner string, dailyUSD, monthlyUSD float64, timezone, mfaCode string) (*SpendingLimitStatus, error) {
+	for _, v := range []float64{dailyUSD, monthlyUSD} {
+		if v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("限额必须为非负数")
+		}
+	}
+	if dailyUSD == 0 && monthlyUSD == 0 {
+		return nil, fmt.Errorf("至少需要设置每日或每月限额，取消限额请删除")
+	}
+	if dailyUSD > 0 && monthlyUSD > 0 && dailyUSD > monthlyUSD {
+		return nil, fmt.Errorf("每日限额不能大于每月限额")
+	}
+	timezone = strings.TrimSpace(timezone)
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if strings.EqualFold(timezone, "Local") {
+		return nil, fmt.Errorf("请使用 IANA 时区名称，如 Asia/Shanghai")
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return nil, fmt.Errorf("无效的时区: %s", timezone)
+	}
+
+	limit, err := findSpendingLimit(owner)
+	switch {
+	case errors.Is(err, ErrSpendingLimitNotFound):
+		limit = &models.SpendingLimit{OwnerAddress: strings.ToLower(owner)}
+	case err != nil:
+		return nil, err
+	case loosensLimit(limit.DailyLimitUSD, dailyUSD) || loosensLimit(limit.MonthlyLimitUSD, monthlyUSD):
+		if err := s.requireSpendingLimitMFA(owner, mfaCode); err != nil {
+			return nil, err
+		}
+	}
+
+	limit.DailyLimitUSD = dailyUSD
+	limit.MonthlyLimitUSD = monthlyUSD
+	limit.Timezone = timezone
+	if err := database.DB.Save(limit).Error; err != nil {
+		return nil, fmt.Errorf("保存支出限额失败: %w", err)
+	}
+	return spendingLimitStatus(limit, time.Now())
+}
+
+// DeleteSpendingLimit 删除钱包的支出限额，已启用双因素认证的用户需提交有效验证码
+// 支出记录保留，重新设置限额后本周期已支出的金额仍然计入
+func (s *WalletService) DeleteSpendingLimit(owner, mfaCode string) error {
+	limit, err := findSpendingLimit(owner)
+	if err != nil {
+		return err
+	}
+	if err := s.requireSpendingLimitMFA(owner, mfaCode); err != nil {
+		return err
+	}
+	if err := database.DB.Unscoped().Delete(limit).Error; err != nil {
+		return fmt.Errorf("删除支出限额失败: %w", err)
+	}
+	return nil
+}
+
+// CheckSpendingLimit 校验从 owner 发送 amount（token 为空表示原生币）是否超出限额，未超出时预留该额度
+// 未设置限额时返回 nil；超出限额或无法获取美元价格时返回校验结果与 core.ErrSpendingLimitExceeded（不预留）
+func (s *WalletService) CheckSpendingLimit(ctx context.Context, owner, network, token string, amount *big.Int) (*SpendingCheck, error) {
+	if database.DB == nil || owner == "" {
+		return nil, nil
+	}
+	if _, err := findSpendingLimit(owner); errors.Is(err, ErrSpendingLimitNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	check := &SpendingCheck{Owner: strings.ToLower(owner), Network: network, Asset: "native", Amount: "0", Priced: true}
+	if token != "" {
+		check.Asset = strings.ToLower(token)
+	}
+	if amount != nil {
+		check.Amount = amount.String()
+	}
+	// 价格查询涉及网络请求，在加锁前完成
+	if amount != nil && amount.Sign() > 0 {
+		check.ValueUSD, check.Priced = s.spendingValueUSD(ctx, network, token, amount)
+	}
+
+	unlock := s.lockSpending(check.Owner)
+	defer unlock()
+	// 加锁后重新读取限额，期间可能已被修改或删除
+	limit, err := findSpendingLimit(owner)
+	if errors.Is(err, ErrSpendingLimitNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	status, err := spendingLimitStatus(limit, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range status.Windows {
+		if !check.Priced || w.SpentUSD+check.ValueUSD > w.LimitUSD+1e-9 {
+			check.Exceeded = append(check.Exceeded, w)
+		}
+	}
+	if len(check.Exceeded) == 0 {
+		if err := reserveSpending(check); err != nil {
+			return nil, err
+		}
+		return check, nil
+	}
+	if !check.Priced {
+		return check, fmt.Errorf("%w: 无法获取 %s 的美元价格，无法校验限额", core.ErrSpendingLimitExceeded, check.Asset)
+	}
+	w := check.Exceeded[0]
+	return check, fmt.Errorf("%w: 本笔约 $%.2f，%s剩余额度 $%.2f（限额 $%.2f，%s 重置）", core.ErrSpendingLimitExceeded,
+		check.ValueUSD, spendingPeriodName(w.Period), w.RemainingUSD, w.LimitUSD, w.ResetsAt.Format(time.RFC3339))
+}
+
+// AuthorizeSpendingOverride 已启用双因素认证的用户提交有效验证码后允许超额发送，并预留该额度
+func (s *WalletService) AuthorizeSpendingOverride(check *SpendingCheck, mfaCode string) bool {
+	securityService := s.GetSecurityService()
+	if check == nil || securityService == nil || mfaCode == "" || !securityService.RequiresMFA(check.Owner) {
+		return false
+	}
+	valid, err := securityService.VerifyMFACode(check.Owner, mfaCode)
+	if err != nil || !valid {
+		return false
+	}
+	if err := s.OverrideSpendingLimit(check); err != nil {
+		slog.WarnContext(context.Background(), "spending reservation failed", "owner", check.Owner, "network", check.Network, "error", err)
+		return false
+	}
+	return true
+}
+
+// OverrideSpendingLimit 调用方已完成双因素认证时标记超额发送并预留该额度
+func (s *WalletService) OverrideSpendingLimit(check *SpendingCheck) error {
+	if check == nil || check.reservation != 0 {
+		return nil
+	}
+	unlock := s.lockSpending(check.Owner)
+	defer unlock()
+	check.Override = true
+	return reserveSpending(check)
+}
+
+// RecordSpending 交易发送成功后为预留记录补记交易哈希，并清理超出保留期的记录
+func (s *WalletService) RecordSpending(check *SpendingCheck, txHash string) {
+	if check == nil || database.DB == nil {
+		return
+	}
+	if check.reservation == 0 {
+		// 未经预留（如已释放）时直接计入
+		if err := reserveSpending(check); err != nil {
+			slog.WarnContext(context.Background(), "spending record failed", "tx_hash", txHash, "error", err)
+			return
+		}
+	}
+	if err := database.DB.Model(&models.SpendingRecord{}).Where("id = ?", check.reservation).
+		Update("tx_hash", txHash).Error; err != nil {
+		slog.WarnContext(context.Background(), "spending record failed", "tx_hash", txHash, "error", err)
+	}
+	check.reservation = 0
+	database.DB.Unscoped().Where("owner_address = ? AND spent_at < ?", check.Owner, time.Now().UTC().Add(-spendingRecordRetention)).
+		Delete(&models.SpendingRecord{})
+}
+
+// ReleaseSpending 交易未发送时释放预留的额度，已确认或已释放时不做处理
+func (s *WalletService) ReleaseSpending(check *SpendingCheck) {
+	if check == nil || check.reservation == 0 || database.DB == nil {
+		return
+	}
+	if err := database.DB.Unscoped().Delete(&models.SpendingRecord{}, check.reservation).Error; err != nil {
+		slog.WarnContext(context.Background(), "spending release failed", "reservation_id", check.reservation, "error", err)
+		return
+	}
+	check.reservation = 0
+}
+
+// lockSpending 获取钱包的限额锁，返回解锁函数
+func (s *WalletService) lockSpending(owner string) func() {
+	v, _ := s.spendingLocks.LoadOrStore(owner, &sync.Mutex{})
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// reserveSpending 写入无交易哈希的支出记录占用额度（调用方需持有钱包的限额锁）
+func reserveSpending(check *SpendingCheck) error {
+	record := models.SpendingRecord{
+		OwnerAddress: check.Owner,
+		SpentAt:      time.Now().UTC(),
+		Network:      check.Network,
+		Asset:        check.Asset,
+		Amount:       check.Amount,
+		ValueUSD:     check.ValueUSD,
+		Override:     check.Override,
+	}
+	if err := database.DB.Create(&record).Error; err != nil {
+		return fmt.Errorf("预留支出额度失败: %w", err)
+	}
+	check.reservation = record.ID
+	return nil
+}
+
+// spendingValueUSD 按当前价格折算美元价值，无法获取价格时第二个返回值为 false
+func (s *WalletService) spendingValueUSD(ctx context.Context, network, token string, amount *big.Int) (float64, bool) {
+	chainID := ChainIDForNetwork(network)
+	if s.priceService == nil || chainID == 0 {
+		return 0, false
+	}
+	var (
+		price    float64
+		decimals int
+		err      error
+	)
+	if token == "" {
+		decimals = core.NativeCurrencyFor(network).Decimals
+		price, err = s.priceService.GetNativePriceUSD(ctx, chainID)
+	} else {
+		var d uint8
+		if _, _, d, err = s.GetTokenMetadata(ctx, token); err != nil {
+			return 0, false
+		}
+		decimals = int(d)
+		price, err = s.priceService.GetTokenPriceUSD(ctx, chainID, token)
+	}
+	if err != nil || price <= 0 {
+		return 0, false
+	}
+	return ValueUSD(amount, decimals, price), true
+}
+
+// requireSpendingLimitMFA 已启用双因素认证的用户需提交有效验证码
+func (s *WalletService) requireSpendingLimitMFA(owner, mfaCode string) error {
+	securityService := s.GetSecurityService()
+	if securityService == nil || !securityService.RequiresMFA(owner) {
+		return nil
+	}
+	if mfaCode == "" {
+		return ErrSpendingLimitMFARequired
+	}
+	if valid, err := securityService.VerifyMFACode(owner, mfaCode); err != nil || !valid {
+		return ErrSpendingLimitMFARequired
+	}
+	return nil
+}
+
+// findSpendingLimit 查询钱包的限额配置
+func findSpendingLimit(owner string) (*models.SpendingLimit, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	var limit models.SpendingLimit
+	err := database.DB.Where("owner_address = ?", strings.ToLower(owner)).First(&limit).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSpendingLimitNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询支出限额失败: %w", err)
+	}
+	return &limit, nil
+}
+
+// spendingLimitStatus 统计各周期的已支出金额
+func spendingLimitStatus(limit *models.SpendingLimit, now time.Time) (*SpendingLimitStatus, error) {
+	loc, err := time.LoadLocation(limit.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	status := &SpendingLimitStatus{
+		Address:         limit.OwnerAddress,
+		DailyLimitUSD:   limit.DailyLimitUSD,
+		MonthlyLimitUSD: limit.MonthlyLimitUSD,
+		Timezone:        limit.Timezone,
+		Windows:         []SpendingWindowStatus{},
+		UpdatedAt:       limit.UpdatedAt,
+	}
+	periods := []struct {
+		period string
+		limit  float64
+	}{
+		{core.SpendingPeriodDaily, limit.DailyLimitUSD},
+		{core.SpendingPeriodMonthly, limit.MonthlyLimitUSD},
+	}
+	for _, p := range periods {
+		if p.limit <= 0 {
+			continue
+		}
+		start, end := core.SpendingWindow(now, loc, p.period)
+		var spent float64
+		if err := database.DB.Model(&models.SpendingRecord{}).
+			Where("owner_address = ? AND spent_at >= ?", limit.OwnerAddress, start.UTC()).
+			Select("COALESCE(SUM(value_usd), 0)").Scan(&spent).Error; err != nil {
+			return nil, fmt.Errorf("统计支出失败: %w", err)
+		}
+		status.Windows = append(status.Windows, SpendingWindowStatus{
+			Period:       p.period,
+			LimitUSD:     p.limit,
+			SpentUSD:     spent,
+			RemainingUSD: math.Max(0, p.limit-spent),
+			StartsAt:     start,
+			ResetsAt:     end,
+		})
+	}
+	return status, nil
+}
+
+// loosensLimit 新限额是否比原限额宽松（0 表示不限制）
+func loosensLimit(current, next float64) bool {
+	return current > 0 && (next == 0 || next > current)
+}
+
+// spendingPeriodName 周期的中文名称
+func spendingPeriodName(period string) string {
+	if period == core.SpendingPeriodMonthly {
+		return "本月"
+	}
+	return "今日"
+}
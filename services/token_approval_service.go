@@ -0,0 +1,61 @@
+/*
+ERC20 授权查看与撤销
+
+列出地址在当前网络上仍有额度的授权（见 core.GetActiveApprovals），spender 标签由内置的常见合约与配置中的自定义标签合并；
+撤销授权发送 approve(spender, 0)，支持一次撤销多个，已广播的交易登记到待确认交易跟踪器。
+*/
+package services
+
+import (
+	"context"
+	"strings"
+	"wallet/config"
+	"wallet/core"
+)
+
+// GetActiveApprovals 查询 owner 在当前网络上仍有额度的 ERC20 授权，tokens 为空时扫描全部代币
+func (s *WalletService) GetActiveApprovals(ctx context.Context, owner string, tokens []string) (*core.ApprovalScan, error) {
+	evmAdapter, err := s.currentEVMAdapter("授权查询")
+	if err != nil {
+		return nil, err
+	}
+	cfg := config.AppConfig.Approvals.WithDefaults()
+	labels := core.KnownSpenderLabels()
+	for addr, label := range cfg.SpenderLabels {
+		labels[strings.ToLower(addr)] = label
+	}
+	return evmAdapter.GetActiveApprovals(ctx, owner, tokens, cfg.LookbackBlocks, cfg.MaxPairs, labels)
+}
+
+// RevokeApprovals 使用会话或助记词撤销一个或多个授权，见 core.RevokeApprovals
+// 已广播的交易登记到待确认交易跟踪器
+func (s *WalletService) RevokeApprovals(ctx context.Context, sessionID, mnemonic, derivationPath string, revocations []core.ApprovalRevocation, opts *TxOptions) ([]core.RevokeResult, error) {
+	ctx = sendContext(ctx)
+	evmAdapter, err := s.currentEVMAdapter("撤销授权")
+	if err != nil {
+		return nil, err
+	}
+	var signer core.Signer
+	if sessionID != "" {
+		signer, err = s.SessionSigner(sessionID, derivationPath)
+	} else {
+		if derivationPath == "" {
+			derivationPath = "m/44'/60'/0'/0/0"
+		}
+		signer, err = core.NewMnemonicSigner(mnemonic, derivationPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	results, err := evmAdapter.RevokeApprovals(ctx, signer, revocations, s.toCoreTxOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	networkID := s.multiChain.GetCurrentNetwork()
+	for _, result := range results {
+		if result.Status == core.BatchStatusSent {
+			s.pendingTxs.Register(networkID, signer.Address().Hex(), result.TxHash)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,239 @@
+/*
+代币价格提醒
+
+按代币美元价格提醒（above/below 目标美元价，change 涨跌幅%），与NFT价格提醒共用触发判断与通知分发。
+每轮检查按链合并启用的提醒，同一条链的代币一次批量查询（GetTokenPricesUSD，结果按 price.cache_ttl_seconds 缓存），
+无价格数据的代币本轮跳过。
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"wallet/config"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TokenPriceAlert 代币价格提醒
+type TokenPriceAlert struct {
+	ID            string     `json:"id"`
+	UserAddress   string     `json:"user_address"`
+	ChainID       int        `json:"chain_id"`
+	Token         string     `json:"token"`                    // 代币地址（规范化，原生币为 NativeTokenAddress）
+	AlertType     string     `json:"alert_type"`               // above / below / change
+	TargetUSD     float64    `json:"target_usd,omitempty"`     // 目标美元价格（above/below）
+	ChangePercent float64    `json:"change_percent,omitempty"` // 涨跌幅阈值（%，change）
+	Repeat        bool       `json:"repeat"`                   // 重复提醒；为 false 时触发一次后停用
+	IsActive      bool       `json:"is_active"`
+	BasePriceUSD  float64    `json:"base_price_usd,omitempty"` // change 类型的基准价
+	LastPriceUSD  float64    `json:"last_price_usd,omitempty"` // 最近一次检查的价格
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty"`
+	TriggerCount  int        `json:"trigger_count"`
+	CreatedAt     time.Time  `json:"created_at"`
+	TriggeredAt   *time.Time `json:"triggered_at"`
+
+	state alertState
+}
+
+// SetAlertNotifier 设置价格提醒的通知分发器
+func (ps *PriceService) SetAlertNotifier(notifier *AlertNotifier) {
+	ps.alertMu.Lock()
+	defer ps.alertMu.Unlock()
+	ps.notifier = notifier
+}
+
+// CreateTokenPriceAlert 创建代币价格提醒
+func (ps *PriceService) CreateTokenPriceAlert(userAddress string, chainID int, token, alertType string, targetUSD, changePercent float64, repeat bool) (*TokenPriceAlert, error) {
+	if chainID <= 0 {
+		return nil, fmt.Errorf("无效的链ID: %d", chainID)
+	}
+	normalized := normalizePriceToken(token)
+	if !common.IsHexAddress(normalized) {
+		return nil, fmt.Errorf("无效的代币地址: %s", token)
+	}
+	if err := ValidatePriceAlert(alertType, targetUSD > 0, changePercent); err != nil {
+		return nil, err
+	}
+
+	alert := &TokenPriceAlert{
+		ID:            fmt.Sprintf("token_alert_%d", time.Now().UnixNano()),
+		UserAddress:   userAddress,
+		ChainID:       chainID,
+		Token:         normalized,
+		AlertType:     alertType,
+		TargetUSD:     targetUSD,
+		ChangePercent: changePercent,
+		Repeat:        repeat,
+		IsActive:      true,
+		CreatedAt:     time.Now(),
+		state:         alertState{armed: true},
+	}
+	ps.alertMu.Lock()
+	ps.tokenAlerts[alert.ID] = alert
+	ps.alertMu.Unlock()
+	snapshot := *alert
+	return &snapshot, nil
+}
+
+// GetTokenPriceAlerts 用户的代币价格提醒（新的在前）
+func (ps *PriceService) GetTokenPriceAlerts(userAddress string) []*TokenPriceAlert {
+	ps.alertMu.RLock()
+	defer ps.alertMu.RUnlock()
+	alerts := []*TokenPriceAlert{}
+	for _, alert := range ps.tokenAlerts {
+		if strings.EqualFold(alert.UserAddress, userAddress) {
+			snapshot := *alert
+			alerts = append(alerts, &snapshot)
+		}
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].CreatedAt.After(alerts[j].CreatedAt)
+	})
+	return alerts
+}
+
+// SetTokenPriceAlertActive 暂停或恢复代币价格提醒；恢复时重新进入待触发状态，change 类型重新取基准价
+func (ps *PriceService) SetTokenPriceAlertActive(userAddress, alertID string, active bool) (*TokenPriceAlert, error) {
+	ps.alertMu.Lock()
+	defer ps.alertMu.Unlock()
+	alert, ok := ps.tokenAlerts[alertID]
+	if !ok || !strings.EqualFold(alert.UserAddress, userAddress) {
+		return nil, ErrPriceAlertNotFound
+	}
+	if active && !alert.IsActive {
+		alert.state.armed = true
+		if alert.AlertType == PriceAlertChange {
+			alert.BasePriceUSD = 0
+		}
+	}
+	alert.IsActive = active
+	snapshot := *alert
+	return &snapshot, nil
+}
+
+// GetTokenPriceAlertHistory 用户代币价格提醒的触发记录（新的在前）
+func (ps *PriceService) GetTokenPriceAlertHistory(userAddress string) []*PriceAlertTrigger {
+	ps.alertMu.RLock()
+	notifier := ps.notifier
+	ps.alertMu.RUnlock()
+	if notifier == nil {
+		return []*PriceAlertTrigger{}
+	}
+	return notifier.History(userAddress, PriceAlertKindToken)
+}
+
+// StartAlertEvaluator 启动代币价格提醒后台检查，重复调用时替换之前的检查
+func (ps *PriceService) StartAlertEvaluator(cfg config.PriceAlertConfig) {
+	cfg = cfg.WithDefaults()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ps.alertMu.Lock()
+	if ps.stopAlerts != nil {
+		ps.stopAlerts()
+	}
+	ps.stopAlerts = cancel
+	ps.alertMu.Unlock()
+
+	go runAlertLoop(ctx, time.Duration(cfg.IntervalSeconds)*time.Second, ps.evaluateTokenAlerts)
+}
+
+// evaluateTokenAlerts 检查一轮全部启用的代币提醒，每条链一次批量查价
+func (ps *PriceService) evaluateTokenAlerts(ctx context.Context) {
+	ps.alertMu.RLock()
+	byChain := make(map[int][]*TokenPriceAlert)
+	for _, alert := range ps.tokenAlerts {
+		if alert.IsActive {
+			byChain[alert.ChainID] = append(byChain[alert.ChainID], alert)
+		}
+	}
+	ps.alertMu.RUnlock()
+
+	for chainID, alerts := range byChain {
+		seen := make(map[string]bool)
+		tokens := make([]string, 0, len(alerts))
+		for _, alert := range alerts {
+			if !seen[alert.Token] {
+				seen[alert.Token] = true
+				tokens = append(tokens, alert.Token)
+			}
+		}
+		prices, err := ps.GetTokenPricesUSD(ctx, chainID, tokens)
+		if err != nil {
+			continue
+		}
+		for _, alert := range alerts {
+			if price := prices[alert.Token]; price != nil && !price.NoPrice && price.PriceUSD > 0 {
+				ps.checkTokenAlert(ctx, alert, price.PriceUSD)
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// checkTokenAlert 用当前价格检查提醒，触发时更新状态并分发通知
+func (ps *PriceService) checkTokenAlert(ctx context.Context, alert *TokenPriceAlert, priceUSD float64) {
+	now := time.Now()
+	ps.alertMu.Lock()
+	if !alert.IsActive {
+		ps.alertMu.Unlock()
+		return
+	}
+	alert.LastPriceUSD = priceUSD
+	alert.LastCheckedAt = &now
+	if alert.AlertType == PriceAlertChange && alert.BasePriceUSD == 0 {
+		alert.BasePriceUSD = priceUSD
+		ps.alertMu.Unlock()
+		return
+	}
+	met, change := alertConditionMet(alert.AlertType, priceUSD, alert.TargetUSD, alert.BasePriceUSD, alert.ChangePercent)
+	if !alert.state.shouldTrigger(met) {
+		ps.alertMu.Unlock()
+		return
+	}
+	alert.TriggeredAt = &now
+	alert.TriggerCount++
+	if !alert.Repeat {
+		alert.IsActive = false
+	}
+	if alert.AlertType == PriceAlertChange {
+		alert.BasePriceUSD = priceUSD
+		alert.state.armed = true
+	}
+	trigger := &PriceAlertTrigger{
+		AlertID:       alert.ID,
+		Kind:          PriceAlertKindToken,
+		UserAddress:   alert.UserAddress,
+		AlertType:     alert.AlertType,
+		ChainID:       alert.ChainID,
+		Token:         alert.Token,
+		PriceUSD:      priceUSD,
+		TargetUSD:     alert.TargetUSD,
+		ChangePercent: change,
+		Message:       tokenAlertMessage(alert, priceUSD, change),
+		TriggeredAt:   now,
+	}
+	notifier := ps.notifier
+	ps.alertMu.Unlock()
+
+	if notifier != nil {
+		notifier.Dispatch(ctx, trigger)
+	}
+}
+
+// tokenAlertMessage 通知文本
+func tokenAlertMessage(alert *TokenPriceAlert, priceUSD, change float64) string {
+	subject := fmt.Sprintf("链 %d 代币 %s 价格 $%g", alert.ChainID, alert.Token, priceUSD)
+	switch alert.AlertType {
+	case PriceAlertAbove:
+		return fmt.Sprintf("%s，已高于目标价 $%g", subject, alert.TargetUSD)
+	case PriceAlertBelow:
+		return fmt.Sprintf("%s，已低于目标价 $%g", subject, alert.TargetUSD)
+	}
+	return fmt.Sprintf("%s，较基准价变化 %+.2f%%", subject, change)
+}
@@ -0,0 +1,98 @@
+/*
+TOTP 双因素认证持久化
+
+实现 core.TOTPStore，将 TOTP 配置写入 totp_secrets 表：
+- 每个地址最多两条记录：active（已启用）与 pending（待首次验证的新密钥）
+- 密钥经 CryptoManager 加密存储，备用码只保存哈希
+- last_step 随每次验证成功更新，重启后已使用的验证码仍不能重放
+*/
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"wallet/core"
+	"wallet/database"
+	"wallet/models"
+	"wallet/pkg/crypto"
+
+	"gorm.io/gorm"
+)
+
+// TOTP 记录状态
+const (
+	totpStateActive  = "active"
+	totpStatePending = "pending"
+)
+
+// dbTOTPStore 基于数据库的 TOTP 配置存储
+type dbTOTPStore struct {
+	cryptoManager *crypto.CryptoManager
+}
+
+// LoadTOTP 读取用户的已启用与待生效 TOTP 配置
+func (s dbTOTPStore) LoadTOTP(userAddress string) (active, pending *core.MFAProvider, err error) {
+	var records []models.TOTPSecret
+	if err := database.DB.Where("owner_address = ?", strings.ToLower(userAddress)).Find(&records).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, r := range records {
+		secret, err := s.cryptoManager.DecryptDefault(&crypto.EncryptedData{Data: r.EncryptedSecret, Nonce: r.Nonce})
+		if err != nil {
+			return nil, nil, fmt.Errorf("解密TOTP密钥失败: %w", err)
+		}
+		var backupCodes []string
+		if r.BackupCodes != "" {
+			backupCodes = strings.Split(r.BackupCodes, ",")
+		}
+		provider := core.NewTOTPProvider(secret, r.State == totpStateActive, backupCodes, r.LastStep, r.LastUsed)
+		switch r.State {
+		case totpStateActive:
+			active = provider
+		case totpStatePending:
+			pending = provider
+		}
+	}
+	return active, pending, nil
+}
+
+// SaveTOTP 在一个事务内保存两种状态的记录，provider 为 nil 时删除对应记录
+func (s dbTOTPStore) SaveTOTP(userAddress string, active, pending *core.MFAProvider) error {
+	owner := strings.ToLower(userAddress)
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, item := range []struct {
+			state    string
+			provider *core.MFAProvider
+		}{{totpStateActive, active}, {totpStatePending, pending}} {
+			if item.provider == nil {
+				if err := tx.Unscoped().Where("owner_address = ? AND state = ?", owner, item.state).
+					Delete(&models.TOTPSecret{}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			enc, err := s.cryptoManager.EncryptDefault(item.provider.Secret)
+			if err != nil {
+				return fmt.Errorf("加密TOTP密钥失败: %w", err)
+			}
+			var record models.TOTPSecret
+			err = tx.Where("owner_address = ? AND state = ?", owner, item.state).First(&record).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			record.OwnerAddress = owner
+			record.State = item.state
+			record.EncryptedSecret = enc.Data
+			record.Nonce = enc.Nonce
+			record.BackupCodes = strings.Join(item.provider.BackupCodes, ",")
+			record.LastStep = item.provider.TOTPLastStep()
+			record.LastUsed = item.provider.LastUsed
+			if err := tx.Save(&record).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,284 @@
+/*
+交易截止时间跟踪服务
+
+以太坊本身没有交易过期机制，EIP-1559 交易可能长期滞留在交易池中。
+本文件为排队发送的交易提供 valid_until 截止时间跟踪：
+- 后台定期轮询回执，记录确认/失败状态；每条记录按发送时所在网络查询，不受当前网络切换影响
+- 超过截止时间仍未打包的交易标记为过期
+- 可选自动取消：以相同 nonce 向自身发送 0 值交易并提高费率顶替原交易（费率上浮规则见 core 的交易替换）
+- 自动取消所需的签名者按需获取：会话模式保存会话ID，助记词模式只保存加密后的助记词，跟踪器不持有明文
+- 进入终态的记录保留 deadlineRetention 后删除；过期且不再自动取消的记录在截止时间后保留同样时长
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"wallet/config"
+	"wallet/core"
+)
+
+// 截止时间跟踪的交易状态
+const (
+	DeadlineStatusPending    = "pending"    // 等待打包
+	DeadlineStatusConfirmed  = "confirmed"  // 已打包且执行成功
+	DeadlineStatusFailed     = "failed"     // 已打包但执行失败
+	DeadlineStatusExpired    = "expired"    // 超过截止时间仍未打包
+	DeadlineStatusCancelling = "cancelling" // 已发送取消交易，等待其打包
+	DeadlineStatusCancelled  = "cancelled"  // 取消交易已打包
+	DeadlineStatusReplaced   = "replaced"   // nonce 已被其他交易占用
+)
+
+const (
+	deadlinePollInterval = 15 * time.Second // 轮询间隔
+	deadlineRetention    = 24 * time.Hour   // 终态记录保留时长
+)
+
+// DeadlineSigner 按需获取自动取消交易的签名者
+type DeadlineSigner func() (core.Signer, error)
+
+// DeadlineTx 带截止时间的交易跟踪记录
+type DeadlineTx struct {
+	TxHash       string    `json:"tx_hash"`                  // 原交易哈希
+	Network      string    `json:"network"`                  // 交易所在网络ID
+	ChainID      int64     `json:"chain_id,omitempty"`       // 交易所在链ID
+	From         string    `json:"from"`                     // 发送地址
+	Nonce        uint64    `json:"nonce"`                    // 交易nonce
+	ValidUntil   time.Time `json:"valid_until"`              // 截止时间
+	AutoCancel   bool      `json:"auto_cancel"`              // 过期后是否自动取消
+	Status       string    `json:"status"`                   // 当前状态
+	CancelTxHash string    `json:"cancel_tx_hash,omitempty"` // 取消交易哈希
+	Error        string    `json:"error,omitempty"`          // 最近一次处理错误
+	SubmittedAt  time.Time `json:"submitted_at"`             // 提交时间
+	UpdatedAt    time.Time `json:"updated_at"`               // 最近更新时间
+
+	signer DeadlineSigner // 仅在需要自动取消时保留，进入终态后清除
+	fee    core.TxFee     // 原交易费率，交易池查不到原交易时作为替换费率的基准
+}
+
+// final 记录是否不再需要轮询
+func (r *DeadlineTx) final() bool {
+	switch r.Status {
+	case DeadlineStatusPending, DeadlineStatusCancelling:
+		return false
+	case DeadlineStatusExpired:
+		return r.signer == nil && time.Since(r.ValidUntil) > deadlineRetention
+	}
+	return true
+}
+
+// TxDeadlineTracker 交易截止时间跟踪器
+type TxDeadlineTracker struct {
+	multiChain *core.MultiChainManager
+	txs        map[string]*DeadlineTx // key: 小写交易哈希
+	mu         sync.Mutex
+}
+
+// NewTxDeadlineTracker 创建跟踪器并启动后台轮询
+func NewTxDeadlineTracker(multiChain *core.MultiChainManager) *TxDeadlineTracker {
+	t := &TxDeadlineTracker{
+		multiChain: multiChain,
+		txs:        make(map[string]*DeadlineTx),
+	}
+	go t.loop()
+	return t
+}
+
+// Track 登记一笔已在 networkID 上广播的交易，从链上读取其 nonce 与费率
+// autoCancel 为 true 时 signer 不能为空，过期后用于发送取消交易
+func (t *TxDeadlineTracker) Track(networkID, txHash, from string, signer DeadlineSigner, validUntil time.Time, autoCancel bool) (*DeadlineTx, error) {
+	if autoCancel && signer == nil {
+		return nil, fmt.Errorf("自动取消需要签名者")
+	}
+	evmAdapter, err := t.evmAdapter(networkID)
+	if err != nil {
+		return nil, err
+	}
+	tx, _, err := evmAdapter.GetTransactionByHash(context.Background(), txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	record := &DeadlineTx{
+		TxHash:      txHash,
+		Network:     networkID,
+		From:        from,
+		Nonce:       tx.Nonce(),
+		ValidUntil:  validUntil,
+		AutoCancel:  autoCancel,
+		Status:      DeadlineStatusPending,
+		SubmittedAt: now,
+		UpdatedAt:   now,
+		fee:         core.TxFeeOf(tx),
+	}
+	if network, err := config.GetNetwork(networkID); err == nil {
+		record.ChainID = network.ChainID
+	}
+	if autoCancel {
+		record.signer = signer
+	}
+
+	t.mu.Lock()
+	t.txs[strings.ToLower(txHash)] = record
+	t.mu.Unlock()
+
+	out := *record
+	return &out, nil
+}
+
+// Get 查询交易的截止时间跟踪状态
+func (t *TxDeadlineTracker) Get(txHash string) (*DeadlineTx, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	record, ok := t.txs[strings.ToLower(txHash)]
+	if !ok {
+		return nil, fmt.Errorf("未找到交易跟踪记录: %s", txHash)
+	}
+	out := *record
+	return &out, nil
+}
+
+// loop 后台轮询
+func (t *TxDeadlineTracker) loop() {
+	ticker := time.NewTicker(deadlinePollInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		t.checkAll()
+	}
+}
+
+// checkAll 检查所有跟踪中的交易
+// 持锁时只复制待检查的记录并清理过期记录，链上查询与取消交易在锁外进行，完成后写回
+func (t *TxDeadlineTracker) checkAll() {
+	now := time.Now()
+	var pending []DeadlineTx
+	t.mu.Lock()
+	for key, record := range t.txs {
+		if record.final() {
+			if now.Sub(record.UpdatedAt) > deadlineRetention {
+				delete(t.txs, key)
+			}
+			continue
+		}
+		pending = append(pending, *record)
+	}
+	t.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), deadlinePollInterval)
+	defer cancel()
+	for i := range pending {
+		record := &pending[i]
+		evmAdapter, err := t.evmAdapter(record.Network)
+		if err != nil {
+			record.Error = err.Error()
+		} else if record.Status == DeadlineStatusCancelling {
+			t.checkCancel(ctx, evmAdapter, record, now)
+		} else {
+			t.checkOriginal(ctx, evmAdapter, record, now)
+		}
+
+		t.mu.Lock()
+		if current, ok := t.txs[strings.ToLower(record.TxHash)]; ok {
+			*current = *record
+		}
+		t.mu.Unlock()
+	}
+}
+
+// checkOriginal 检查原交易是否已打包，超时则标记过期并按需自动取消
+func (t *TxDeadlineTracker) checkOriginal(ctx context.Context, evmAdapter *core.EVMAdapter, record *DeadlineTx, now time.Time) {
+	if receipt, err := evmAdapter.GetTransactionReceipt(ctx, record.TxHash); err == nil {
+		if receipt.Status == 1 {
+			t.finish(record, DeadlineStatusConfirmed, now)
+		} else {
+			t.finish(record, DeadlineStatusFailed, now)
+		}
+		return
+	}
+
+	// 原交易无回执但 nonce 已被使用，说明被其他交易顶替
+	if _, latest, err := evmAdapter.GetNonces(ctx, record.From); err == nil && latest > record.Nonce {
+		t.finish(record, DeadlineStatusReplaced, now)
+		return
+	}
+
+	if now.Before(record.ValidUntil) {
+		return
+	}
+	if record.Status == DeadlineStatusPending {
+		record.Status = DeadlineStatusExpired
+		record.UpdatedAt = now
+	}
+	if record.signer == nil {
+		return
+	}
+
+	signer, err := record.signer()
+	if err != nil {
+		// 会话已失效等情况无法再取消，保留过期状态
+		record.signer = nil
+		record.Error = fmt.Sprintf("自动取消失败: %v", err)
+		record.UpdatedAt = now
+		return
+	}
+	// 未指定新费率，由 core 在原费率上浮与当前建议费率中取较大值
+	opts := &core.ReplaceOptions{
+		OriginalGasPrice: record.fee.GasPrice,
+		OriginalTipCap:   record.fee.TipCap,
+		OriginalFeeCap:   record.fee.FeeCap,
+	}
+	cancelHash, err := evmAdapter.CancelTransactionWithSigner(ctx, signer, record.Nonce, opts)
+	if err != nil {
+		// 下一轮继续重试
+		record.Error = fmt.Sprintf("自动取消失败: %v", err)
+		record.UpdatedAt = now
+		return
+	}
+	record.CancelTxHash = cancelHash
+	record.Status = DeadlineStatusCancelling
+	record.Error = ""
+	record.UpdatedAt = now
+}
+
+// checkCancel 检查取消交易是否已打包
+func (t *TxDeadlineTracker) checkCancel(ctx context.Context, evmAdapter *core.EVMAdapter, record *DeadlineTx, now time.Time) {
+	if receipt, err := evmAdapter.GetTransactionReceipt(ctx, record.TxHash); err == nil {
+		// 原交易抢先打包
+		if receipt.Status == 1 {
+			t.finish(record, DeadlineStatusConfirmed, now)
+		} else {
+			t.finish(record, DeadlineStatusFailed, now)
+		}
+		return
+	}
+	if _, err := evmAdapter.GetTransactionReceipt(ctx, record.CancelTxHash); err == nil {
+		t.finish(record, DeadlineStatusCancelled, now)
+	}
+}
+
+// finish 进入终态并清除保留的签名者
+func (t *TxDeadlineTracker) finish(record *DeadlineTx, status string, now time.Time) {
+	record.Status = status
+	record.UpdatedAt = now
+	record.signer = nil
+}
+
+// evmAdapter 获取记录所在网络的EVM适配器
+func (t *TxDeadlineTracker) evmAdapter(networkID string) (*core.EVMAdapter, error) {
+	adapter, err := t.multiChain.GetAdapter(networkID)
+	if err != nil {
+		return nil, err
+	}
+	evmAdapter, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		return nil, fmt.Errorf("网络 %s 不支持交易截止时间跟踪", networkID)
+	}
+	return evmAdapter, nil
+}
@@ -0,0 +1,232 @@
+/*
+交易生命周期审计服务
+
+为争议处理提供单笔交易的完整时间线：
+- 审计日志：谁在何时、从哪个IP/会话发起了请求，选择了什么费率
+- 跟踪记录：截止时间、过期、自动取消等排队/确认状态
+- 链上数据：交易所在区块、确认时间、实际Gas消耗
+查询仅限交易发送方本人。
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+	"wallet/core"
+	"wallet/database"
+	"wallet/models"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 交易审计日志的资源类型
+const txAuditResourceType = "transaction"
+
+// TxAuditContext 发起交易请求的上下文信息
+type TxAuditContext struct {
+	SessionID string // 会话ID
+	From      string // 发送地址
+	IPAddress string // 客户端IP
+	UserAgent string // 客户端UA
+	Endpoint  string // 请求接口
+}
+
+// TxLifecycleEvent 交易生命周期事件
+type TxLifecycleEvent struct {
+	Time    time.Time              `json:"time"`              // 事件时间
+	Event   string                 `json:"event"`             // 事件名称
+	Source  string                 `json:"source"`            // 数据来源：audit / tracker / chain
+	Details map[string]interface{} `json:"details,omitempty"` // 事件详情
+}
+
+// TxLifecycle 交易生命周期
+type TxLifecycle struct {
+	TxHash string             `json:"tx_hash"`
+	From   string             `json:"from"`
+	To     string             `json:"to"`
+	Nonce  uint64             `json:"nonce"`
+	Status string             `json:"status"` // pending / confirmed / failed / unknown
+	Events []TxLifecycleEvent `json:"events"`
+}
+
+// RecordTxAudit 记录交易相关操作的审计日志（发送、广播、取消等）
+// 记录失败不影响业务流程
+func (s *WalletService) RecordTxAudit(action, txHash string, actx *TxAuditContext, details map[string]interface{}) {
+	if database.DB == nil || actx == nil {
+		return
+	}
+	payload := models.JSON{
+		"session_id": actx.SessionID,
+		"from":       strings.ToLower(actx.From),
+		"endpoint":   actx.Endpoint,
+		"timestamp":  time.Now().Unix(),
+	}
+	for k, v := range details {
+		payload[k] = v
+	}
+	resourceType := txAuditResourceType
+	resourceID := strings.ToLower(txHash)
+	entry := models.ActivityLog{
+		Action:       action,
+		ResourceType: &resourceType,
+		ResourceID:   &resourceID,
+		Details:      payload,
+		IPAddress:    &actx.IPAddress,
+		UserAgent:    &actx.UserAgent,
+		Status:       "success",
+	}
+	if err := database.DB.Create(&entry).Error; err != nil {
+		slog.WarnContext(context.Background(), "transaction audit log failed", "action", action, "tx_hash", txHash, "error", err)
+	}
+}
+
+// GetTransactionLifecycle 汇总审计日志、跟踪记录与链上回执，返回按时间排序的交易时间线
+// 参数: ownerAddress - 当前认证用户地址，仅允许查询自己发送的交易
+func (s *WalletService) GetTransactionLifecycle(ctx context.Context, txHash, ownerAddress string) (*TxLifecycle, error) {
+	adapter, err := s.multiChain.GetCurrentAdapter()
+	if err != nil {
+		return nil, err
+	}
+	evmAdapter, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		return nil, fmt.Errorf("当前链不支持交易生命周期查询")
+	}
+
+	lifecycle := &TxLifecycle{TxHash: txHash, Status: "unknown"}
+	owner := strings.ToLower(ownerAddress)
+
+	// 链上交易本体
+	tx, isPending, txErr := evmAdapter.GetTransactionByHash(ctx, txHash)
+	if txErr == nil {
+		signer := types.LatestSignerForChainID(tx.ChainId())
+		if from, err := types.Sender(signer, tx); err == nil {
+			lifecycle.From = from.Hex()
+		}
+		if tx.To() != nil {
+			lifecycle.To = tx.To().Hex()
+		}
+		lifecycle.Nonce = tx.Nonce()
+		if isPending {
+			lifecycle.Status = "pending"
+		}
+	}
+
+	// 审计日志
+	auditLogs, err := s.txAuditLogs(txHash)
+	if err != nil {
+		return nil, err
+	}
+	if lifecycle.From == "" {
+		for _, l := range auditLogs {
+			if from, _ := l.Details["from"].(string); from != "" {
+				lifecycle.From = from
+				break
+			}
+		}
+	}
+
+	// 权限校验：只能查询自己作为发送方的交易
+	if lifecycle.From == "" {
+		return nil, fmt.Errorf("未找到交易: %s", txHash)
+	}
+	if strings.ToLower(lifecycle.From) != owner {
+		return nil, fmt.Errorf("无权查看该交易")
+	}
+
+	for _, l := range auditLogs {
+		details := map[string]interface{}(l.Details)
+		if l.IPAddress != nil {
+			details["ip_address"] = *l.IPAddress
+		}
+		if l.UserAgent != nil {
+			details["user_agent"] = *l.UserAgent
+		}
+		lifecycle.Events = append(lifecycle.Events, TxLifecycleEvent{
+			Time:    l.CreatedAt,
+			Event:   l.Action,
+			Source:  "audit",
+			Details: details,
+		})
+	}
+
+	// 截止时间跟踪记录
+	if record, err := s.deadlineTracker.Get(txHash); err == nil {
+		lifecycle.Events = append(lifecycle.Events, TxLifecycleEvent{
+			Time:   record.SubmittedAt,
+			Event:  "tracking_started",
+			Source: "tracker",
+			Details: map[string]interface{}{
+				"valid_until": record.ValidUntil,
+				"auto_cancel": record.AutoCancel,
+			},
+		})
+		if record.Status != DeadlineStatusPending {
+			details := map[string]interface{}{"status": record.Status}
+			if record.CancelTxHash != "" {
+				details["cancel_tx_hash"] = record.CancelTxHash
+			}
+			if record.Error != "" {
+				details["error"] = record.Error
+			}
+			lifecycle.Events = append(lifecycle.Events, TxLifecycleEvent{
+				Time:    record.UpdatedAt,
+				Event:   "tracking_" + record.Status,
+				Source:  "tracker",
+				Details: details,
+			})
+		}
+	}
+
+	// 链上回执
+	if receipt, err := evmAdapter.GetTransactionReceipt(ctx, txHash); err == nil {
+		lifecycle.Status = "confirmed"
+		event := "confirmed"
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			lifecycle.Status = "failed"
+			event = "failed"
+		}
+		details := map[string]interface{}{
+			"block_number": receipt.BlockNumber.String(),
+			"block_hash":   receipt.BlockHash.Hex(),
+			"gas_used":     receipt.GasUsed,
+		}
+		if receipt.EffectiveGasPrice != nil {
+			details["effective_gas_price"] = receipt.EffectiveGasPrice.String()
+		}
+		minedAt := time.Time{}
+		if ts, err := evmAdapter.GetBlockTimestamp(ctx, receipt.BlockNumber); err == nil {
+			minedAt = time.Unix(int64(ts), 0)
+		}
+		lifecycle.Events = append(lifecycle.Events, TxLifecycleEvent{
+			Time:    minedAt,
+			Event:   event,
+			Source:  "chain",
+			Details: details,
+		})
+	}
+
+	sort.SliceStable(lifecycle.Events, func(i, j int) bool {
+		return lifecycle.Events[i].Time.Before(lifecycle.Events[j].Time)
+	})
+	return lifecycle, nil
+}
+
+// txAuditLogs 查询交易相关的审计日志
+func (s *WalletService) txAuditLogs(txHash string) ([]models.ActivityLog, error) {
+	if database.DB == nil {
+		return nil, nil
+	}
+	var logs []models.ActivityLog
+	err := database.DB.
+		Where("resource_type = ? AND resource_id = ?", txAuditResourceType, strings.ToLower(txHash)).
+		Order("created_at ASC").
+		Find(&logs).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询审计日志失败: %w", err)
+	}
+	return logs, nil
+}
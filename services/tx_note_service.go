@@ -0,0 +1,187 @@
+/*
+交易备注与导出
+
+用户对账时可为交易添加备注与分类，导出交易历史时与链上数据合并：
+- 备注按钱包地址（会话所属用户）、网络与交易哈希唯一，重复设置即覆盖
+- 导出时一次性加载该用户在当前网络下的全部备注（数量由用户手工录入，远小于交易数）
+- 链上历史仍按批次扫描并逐条写出，不在内存中缓存全部交易
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"wallet/core"
+	"wallet/database"
+	"wallet/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 备注长度限制
+const (
+	maxTxNoteLength     = 1000
+	maxTxCategoryLength = 50
+)
+
+// TxNoteEntry 交易备注
+type TxNoteEntry struct {
+	Network   string    `json:"network"`
+	TxHash    string    `json:"tx_hash"`
+	Note      string    `json:"note"`
+	Category  string    `json:"category,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AnnotatedTransaction 合并了用户备注的交易（导出用）
+type AnnotatedTransaction struct {
+	core.TransactionInfo
+	Fee      string `json:"fee"`                // 交易费（wei，gas_used*gas_price，无法计算时为空）
+	Note     string `json:"note,omitempty"`     // 用户备注
+	Category string `json:"category,omitempty"` // 用户分类
+}
+
+func toTxNoteEntry(m *models.TxNote) TxNoteEntry {
+	return TxNoteEntry{Network: m.Network, TxHash: m.TxHash, Note: m.Note, Category: m.Category, UpdatedAt: m.UpdatedAt}
+}
+
+// SetTxNote 设置交易备注（已存在则覆盖），network 为空时使用当前网络
+func (s *WalletService) SetTxNote(owner, network, txHash, note, category string) (*TxNoteEntry, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	txHash, err := normalizeTxHash(txHash)
+	if err != nil {
+		return nil, err
+	}
+	note, category = strings.TrimSpace(note), strings.TrimSpace(category)
+	if note == "" && category == "" {
+		return nil, errors.New("备注与分类不能同时为空")
+	}
+	if utf8.RuneCountInString(note) > maxTxNoteLength {
+		return nil, fmt.Errorf("备注不能超过 %d 个字符", maxTxNoteLength)
+	}
+	if utf8.RuneCountInString(category) > maxTxCategoryLength {
+		return nil, fmt.Errorf("分类不能超过 %d 个字符", maxTxCategoryLength)
+	}
+	if network == "" {
+		network = s.multiChain.GetCurrentNetwork()
+	}
+
+	record := models.TxNote{
+		OwnerAddress: strings.ToLower(owner),
+		Network:      network,
+		TxHash:       txHash,
+		Note:         note,
+		Category:     category,
+	}
+	conflict := clause.OnConflict{
+		Columns:   []clause.Column{{Name: "owner_address"}, {Name: "network"}, {Name: "tx_hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"note", "category", "updated_at"}),
+	}
+	if err := database.DB.Clauses(conflict).Create(&record).Error; err != nil {
+		return nil, fmt.Errorf("保存交易备注失败: %w", err)
+	}
+	return s.GetTxNote(owner, network, txHash)
+}
+
+// GetTxNote 查询交易备注，network 为空时使用当前网络
+func (s *WalletService) GetTxNote(owner, network, txHash string) (*TxNoteEntry, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	txHash, err := normalizeTxHash(txHash)
+	if err != nil {
+		return nil, err
+	}
+	if network == "" {
+		network = s.multiChain.GetCurrentNetwork()
+	}
+	var record models.TxNote
+	err = database.DB.Where("owner_address = ? AND network = ? AND tx_hash = ?", strings.ToLower(owner), network, txHash).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("交易备注不存在")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询交易备注失败: %w", err)
+	}
+	entry := toTxNoteEntry(&record)
+	return &entry, nil
+}
+
+// DeleteTxNote 删除交易备注，network 为空时使用当前网络
+func (s *WalletService) DeleteTxNote(owner, network, txHash string) error {
+	if database.DB == nil {
+		return errors.New("数据库未初始化")
+	}
+	txHash, err := normalizeTxHash(txHash)
+	if err != nil {
+		return err
+	}
+	if network == "" {
+		network = s.multiChain.GetCurrentNetwork()
+	}
+	result := database.DB.Unscoped().Where("owner_address = ? AND network = ? AND tx_hash = ?",
+		strings.ToLower(owner), network, txHash).Delete(&models.TxNote{})
+	if result.Error != nil {
+		return fmt.Errorf("删除交易备注失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("交易备注不存在")
+	}
+	return nil
+}
+
+// ExportAnnotatedHistory 流式导出当前网络的交易历史并合并用户备注，每笔交易调用一次 emit
+// owner 为空（未登录）时不合并备注
+func (s *WalletService) ExportAnnotatedHistory(ctx context.Context, owner string, req *core.TransactionHistoryRequest, emit func(AnnotatedTransaction) error) error {
+	notes := make(map[string]*models.TxNote)
+	if owner != "" && database.DB != nil {
+		var records []models.TxNote
+		err := database.DB.Where("owner_address = ? AND network = ?", strings.ToLower(owner), s.multiChain.GetCurrentNetwork()).Find(&records).Error
+		if err != nil {
+			return fmt.Errorf("查询交易备注失败: %w", err)
+		}
+		for i := range records {
+			notes[records[i].TxHash] = &records[i]
+		}
+	}
+
+	return s.ExportTransactionHistory(ctx, req, func(tx core.TransactionInfo) error {
+		row := AnnotatedTransaction{TransactionInfo: tx, Fee: txFeeWei(tx.GasUsed, tx.GasPrice)}
+		if note, ok := notes[strings.ToLower(tx.Hash)]; ok {
+			row.Note, row.Category = note.Note, note.Category
+		}
+		return emit(row)
+	})
+}
+
+// txFeeWei 计算交易费 gasUsed*gasPrice（十进制字符串），任一缺失或非法时返回空
+func txFeeWei(gasUsed, gasPrice string) string {
+	used, ok1 := new(big.Int).SetString(gasUsed, 10)
+	price, ok2 := new(big.Int).SetString(gasPrice, 10)
+	if !ok1 || !ok2 {
+		return ""
+	}
+	return new(big.Int).Mul(used, price).String()
+}
+
+// normalizeTxHash 校验并规范化交易哈希（0x 开头的32字节十六进制，小写）
+func normalizeTxHash(txHash string) (string, error) {
+	h := strings.ToLower(strings.TrimSpace(txHash))
+	if len(h) != 66 || !strings.HasPrefix(h, "0x") {
+		return "", fmt.Errorf("交易哈希格式不正确: %s", txHash)
+	}
+	for _, ch := range h[2:] {
+		if !(ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'f') {
+			return "", fmt.Errorf("交易哈希格式不正确: %s", txHash)
+		}
+	}
+	return h, nil
+}
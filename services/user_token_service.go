@@ -0,0 +1,207 @@
+/*
+用户自定义代币列表
+
+用户按网络维护关注的ERC20代币，余额与资产汇总接口未指定代币时自动使用该列表，
+无需每次传入合约地址。添加时从链上读取 name/symbol/decimals，读取失败（非ERC20合约）则拒绝保存；
+同一用户在同一网络下按合约地址去重。
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"wallet/core"
+	"wallet/database"
+	"wallet/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// userTokenMetadataTimeout 添加代币时读取链上元数据的超时时间
+const userTokenMetadataTimeout = 10 * time.Second
+
+// UserTokenEntry 用户自定义代币
+type UserTokenEntry struct {
+	Network  string    `json:"network"`  // 所属网络ID
+	Contract string    `json:"contract"` // 校验和格式合约地址
+	Symbol   string    `json:"symbol"`   // 代币符号
+	Name     string    `json:"name"`     // 代币名称
+	Decimals int       `json:"decimals"` // 小数位数
+	AddedAt  time.Time `json:"added_at"` // 添加时间
+}
+
+func toUserTokenEntry(m *models.UserToken) UserTokenEntry {
+	return UserTokenEntry{
+		Network:  m.Network,
+		Contract: m.Contract,
+		Symbol:   m.Symbol,
+		Name:     m.Name,
+		Decimals: m.Decimals,
+		AddedAt:  m.CreatedAt,
+	}
+}
+
+// AddUserToken 将代币加入用户的代币列表，network 为空时使用当前网络
+// 保存前校验合约实现了 ERC20 的 symbol/decimals；已存在时直接返回已有记录
+func (s *WalletService) AddUserToken(ctx context.Context, owner, network, contract string) (*UserTokenEntry, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	if !common.IsHexAddress(contract) {
+		return nil, errors.New("合约地址格式不正确")
+	}
+	if network == "" {
+		network = s.multiChain.GetCurrentNetwork()
+	}
+	adapter, err := s.multiChain.GetAdapter(network)
+	if err != nil {
+		return nil, err
+	}
+	evmAdapter, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		return nil, fmt.Errorf("网络 %s 不支持ERC20代币", network)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, userTokenMetadataTimeout)
+	defer cancel()
+	name, symbol, decimals, err := evmAdapter.GetERC20Metadata(ctx, contract)
+	if err != nil {
+		return nil, fmt.Errorf("合约 %s 不是有效的ERC20代币: %w", contract, err)
+	}
+	if strings.TrimSpace(symbol) == "" {
+		return nil, fmt.Errorf("合约 %s 不是有效的ERC20代币: symbol 为空", contract)
+	}
+
+	record := models.UserToken{
+		OwnerAddress: strings.ToLower(owner),
+		Network:      network,
+		Contract:     common.HexToAddress(contract).Hex(),
+		Symbol:       symbol,
+		Name:         name,
+		Decimals:     int(decimals),
+	}
+	conflict := clause.OnConflict{
+		Columns:   []clause.Column{{Name: "owner_address"}, {Name: "network"}, {Name: "contract"}},
+		DoNothing: true,
+	}
+	if err := database.DB.Clauses(conflict).Create(&record).Error; err != nil {
+		return nil, fmt.Errorf("保存代币失败: %w", err)
+	}
+
+	saved, err := findUserToken(owner, network, contract)
+	if err != nil {
+		return nil, err
+	}
+	entry := toUserTokenEntry(saved)
+	return &entry, nil
+}
+
+// UpdateUserTokenSymbol 修改代币的显示符号（空字符串表示恢复为链上符号）
+func (s *WalletService) UpdateUserTokenSymbol(ctx context.Context, owner, network, contract, symbol string) (*UserTokenEntry, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	if network == "" {
+		network = s.multiChain.GetCurrentNetwork()
+	}
+	record, err := findUserToken(owner, network, contract)
+	if err != nil {
+		return nil, err
+	}
+	if symbol == "" {
+		adapter, err := s.multiChain.GetAdapter(network)
+		if err != nil {
+			return nil, err
+		}
+		evmAdapter, ok := adapter.(*core.EVMAdapter)
+		if !ok {
+			return nil, fmt.Errorf("网络 %s 不支持ERC20代币", network)
+		}
+		ctx, cancel := context.WithTimeout(ctx, userTokenMetadataTimeout)
+		defer cancel()
+		if _, symbol, _, err = evmAdapter.GetERC20Metadata(ctx, record.Contract); err != nil {
+			return nil, fmt.Errorf("读取链上符号失败: %w", err)
+		}
+	}
+	if err := database.DB.Model(record).Update("symbol", symbol).Error; err != nil {
+		return nil, fmt.Errorf("更新代币失败: %w", err)
+	}
+	record.Symbol = symbol
+	entry := toUserTokenEntry(record)
+	return &entry, nil
+}
+
+// RemoveUserToken 从用户的代币列表删除代币，network 为空时使用当前网络
+func (s *WalletService) RemoveUserToken(owner, network, contract string) error {
+	if database.DB == nil {
+		return errors.New("数据库未初始化")
+	}
+	if network == "" {
+		network = s.multiChain.GetCurrentNetwork()
+	}
+	result := database.DB.Unscoped().Where("owner_address = ? AND network = ? AND contract = ?",
+		strings.ToLower(owner), network, common.HexToAddress(contract).Hex()).Delete(&models.UserToken{})
+	if result.Error != nil {
+		return fmt.Errorf("删除代币失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("代币不存在")
+	}
+	return nil
+}
+
+// ListUserTokens 按添加时间返回用户的代币列表，network 为空时返回全部网络
+func (s *WalletService) ListUserTokens(owner, network string) ([]UserTokenEntry, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	query := database.DB.Where("owner_address = ?", strings.ToLower(owner))
+	if network != "" {
+		query = query.Where("network = ?", network)
+	}
+	var records []models.UserToken
+	if err := query.Order("created_at ASC").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("查询代币列表失败: %w", err)
+	}
+	entries := make([]UserTokenEntry, 0, len(records))
+	for i := range records {
+		entries = append(entries, toUserTokenEntry(&records[i]))
+	}
+	return entries, nil
+}
+
+// UserTokenContracts 返回用户代币列表中各网络的合约地址，供余额与资产汇总接口使用
+// 数据库不可用或列表为空时返回空映射
+func (s *WalletService) UserTokenContracts(owner string) map[string][]string {
+	contracts := make(map[string][]string)
+	if owner == "" {
+		return contracts
+	}
+	entries, err := s.ListUserTokens(owner, "")
+	if err != nil {
+		return contracts
+	}
+	for _, entry := range entries {
+		contracts[entry.Network] = append(contracts[entry.Network], entry.Contract)
+	}
+	return contracts
+}
+
+// findUserToken 查询用户在指定网络下的代币记录
+func findUserToken(owner, network, contract string) (*models.UserToken, error) {
+	var record models.UserToken
+	err := database.DB.Where("owner_address = ? AND network = ? AND contract = ?",
+		strings.ToLower(owner), network, common.HexToAddress(contract).Hex()).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("代币不存在")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询代币失败: %w", err)
+	}
+	return &record, nil
+}
@@ -0,0 +1,271 @@
+/*
+HD钱包多账户
+
+同一助记词下按账户序号派生多个账户（类似 MetaMask 的 Account 1/2/3）：
+  - 账户 N 的派生路径为 m/44'/60'/N'/0/0，直接按路径派生，不需要派生 0..N-1
+  - 钱包以账户0的地址标识，账户列表持久化到数据库；助记词不落盘，创建账户时由会话或请求中的助记词派生地址
+  - 账户0始终存在，首次查询时自动记录（标签为 Account 1）
+  - 发送交易时可用账户序号代替派生路径，序号须为已创建的账户
+*/
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"wallet/core"
+	"wallet/database"
+	"wallet/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 账户数量与标签长度限制
+const (
+	maxWalletAccounts     = 100
+	maxAccountIndex       = 1<<31 - 1 // 硬化派生序号上限
+	maxAccountLabelLength = 100
+)
+
+var (
+	// ErrAccountNotFound 钱包下不存在该序号的账户
+	ErrAccountNotFound = errors.New("账户不存在")
+	// ErrAccountExists 钱包下已存在该序号的账户
+	ErrAccountExists = errors.New("账户已存在")
+)
+
+// WalletAccountInfo 钱包账户
+type WalletAccountInfo struct {
+	Index          int       `json:"index"`
+	Label          string    `json:"label"`
+	DerivationPath string    `json:"derivation_path"`
+	Address        string    `json:"address"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func toWalletAccountInfo(m *models.WalletAccount) WalletAccountInfo {
+	return WalletAccountInfo{Index: m.AccountIndex, Label: m.Label, DerivationPath: m.DerivationPath, Address: m.Address, CreatedAt: m.CreatedAt}
+}
+
+// accountDerivationPath 账户序号对应的派生路径
+func accountDerivationPath(index int) string {
+	return fmt.Sprintf("m/44'/60'/%d'/0/0", index)
+}
+
+// defaultAccountLabel 账户的默认标签（序号从1开始显示）
+func defaultAccountLabel(index int) string {
+	return fmt.Sprintf("Account %d", index+1)
+}
+
+// accountMnemonic 取请求中的助记词，未提供时使用会话中的助记词
+func (s *WalletService) accountMnemonic(sessionID, mnemonic string) (string, error) {
+	if mnemonic != "" {
+		return mnemonic, nil
+	}
+	if sessionID == "" {
+		return "", errors.New("需要提供 session_id 或 mnemonic")
+	}
+	return s.getSessionMnemonic(sessionID)
+}
+
+// ensurePrimaryAccount 返回钱包标识（账户0地址，小写），并在账户0未记录时写入
+func ensurePrimaryAccount(mnemonic string) (string, error) {
+	path := accountDerivationPath(0)
+	primary, err := core.DeriveAddressFromMnemonic(mnemonic, path)
+	if err != nil {
+		return "", err
+	}
+	walletAddress := strings.ToLower(primary)
+	record := models.WalletAccount{
+		WalletAddress:  walletAddress,
+		AccountIndex:   0,
+		Label:          defaultAccountLabel(0),
+		DerivationPath: path,
+		Address:        primary,
+	}
+	conflict := clause.OnConflict{Columns: []clause.Column{{Name: "wallet_address"}, {Name: "account_index"}}, DoNothing: true}
+	if err := database.DB.Clauses(conflict).Create(&record).Error; err != nil {
+		return "", fmt.Errorf("保存账户失败: %w", err)
+	}
+	return walletAddress, nil
+}
+
+// ListAccounts 列出会话钱包的全部账户（按序号排序）
+func (s *WalletService) ListAccounts(sessionID string) ([]WalletAccountInfo, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	mnemonic, err := s.accountMnemonic(sessionID, "")
+	if err != nil {
+		return nil, err
+	}
+	walletAddress, err := ensurePrimaryAccount(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	var records []models.WalletAccount
+	if err := database.DB.Where("wallet_address = ?", walletAddress).Order("account_index").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("查询账户失败: %w", err)
+	}
+	accounts := make([]WalletAccountInfo, 0, len(records))
+	for i := range records {
+		accounts = append(accounts, toWalletAccountInfo(&records[i]))
+	}
+	return accounts, nil
+}
+
+// CreateAccount 为会话钱包创建账户并派生地址
+// index 为空时使用当前最大序号+1；label 为空时为 Account N
+func (s *WalletService) CreateAccount(sessionID, label string, index *int) (*WalletAccountInfo, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	label = strings.TrimSpace(label)
+	if utf8.RuneCountInString(label) > maxAccountLabelLength {
+		return nil, fmt.Errorf("标签不能超过 %d 个字符", maxAccountLabelLength)
+	}
+	if index != nil && (*index < 0 || *index > maxAccountIndex) {
+		return nil, fmt.Errorf("账户序号须在 0~%d 之间", maxAccountIndex)
+	}
+	mnemonic, err := s.accountMnemonic(sessionID, "")
+	if err != nil {
+		return nil, err
+	}
+	walletAddress, err := ensurePrimaryAccount(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	var record models.WalletAccount
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.WalletAccount{}).Where("wallet_address = ?", walletAddress).Count(&count).Error; err != nil {
+			return fmt.Errorf("查询账户失败: %w", err)
+		}
+		if count >= maxWalletAccounts {
+			return fmt.Errorf("每个钱包最多 %d 个账户", maxWalletAccounts)
+		}
+
+		next := 0
+		if index != nil {
+			next = *index
+		} else {
+			var last models.WalletAccount
+			if err := tx.Where("wallet_address = ?", walletAddress).Order("account_index DESC").First(&last).Error; err != nil {
+				return fmt.Errorf("查询账户失败: %w", err)
+			}
+			if last.AccountIndex >= maxAccountIndex {
+				return fmt.Errorf("账户序号已达上限，请指定 index")
+			}
+			next = last.AccountIndex + 1
+		}
+
+		path := accountDerivationPath(next)
+		address, err := core.DeriveAddressFromMnemonic(mnemonic, path)
+		if err != nil {
+			return err
+		}
+		if label == "" {
+			label = defaultAccountLabel(next)
+		}
+		record = models.WalletAccount{
+			WalletAddress:  walletAddress,
+			AccountIndex:   next,
+			Label:          label,
+			DerivationPath: path,
+			Address:        address,
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+		if result.Error != nil {
+			return fmt.Errorf("保存账户失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: 序号 %d", ErrAccountExists, next)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	info := toWalletAccountInfo(&record)
+	return &info, nil
+}
+
+// RenameAccount 修改会话钱包中账户的标签
+func (s *WalletService) RenameAccount(sessionID string, index int, label string) (*WalletAccountInfo, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return nil, errors.New("标签不能为空")
+	}
+	if utf8.RuneCountInString(label) > maxAccountLabelLength {
+		return nil, fmt.Errorf("标签不能超过 %d 个字符", maxAccountLabelLength)
+	}
+	record, err := s.findAccount(sessionID, "", index)
+	if err != nil {
+		return nil, err
+	}
+	if err := database.DB.Model(record).Update("label", label).Error; err != nil {
+		return nil, fmt.Errorf("更新账户失败: %w", err)
+	}
+	record.Label = label
+	info := toWalletAccountInfo(record)
+	return &info, nil
+}
+
+// GetAccount 查询会话钱包中的账户
+func (s *WalletService) GetAccount(sessionID string, index int) (*WalletAccountInfo, error) {
+	record, err := s.findAccount(sessionID, "", index)
+	if err != nil {
+		return nil, err
+	}
+	info := toWalletAccountInfo(record)
+	return &info, nil
+}
+
+// AccountDerivationPath 返回账户的派生路径，用于发送交易时以账户序号代替派生路径
+// 钱包由会话或助记词确定（助记词优先），账户须已创建（账户0始终可用）；Keystore 会话只有单个私钥，仅账户0可用
+func (s *WalletService) AccountDerivationPath(sessionID, mnemonic string, index int) (string, error) {
+	if index == 0 {
+		return accountDerivationPath(0), nil
+	}
+	if mnemonic == "" && sessionID != "" {
+		if session, err := s.GetSession(sessionID); err == nil && session.isKeySession() {
+			return "", fmt.Errorf("%w: Keystore 会话只有账户 0", ErrAccountNotFound)
+		}
+	}
+	record, err := s.findAccount(sessionID, mnemonic, index)
+	if err != nil {
+		return "", err
+	}
+	return record.DerivationPath, nil
+}
+
+// findAccount 按序号查找钱包的账户
+func (s *WalletService) findAccount(sessionID, mnemonic string, index int) (*models.WalletAccount, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	mnemonic, err := s.accountMnemonic(sessionID, mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	walletAddress, err := ensurePrimaryAccount(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	var record models.WalletAccount
+	err = database.DB.Where("wallet_address = ? AND account_index = ?", walletAddress, index).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: 序号 %d", ErrAccountNotFound, index)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询账户失败: %w", err)
+	}
+	return &record, nil
+}
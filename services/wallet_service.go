@@ -32,11 +32,15 @@ package services
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"math"
 	"math/big"
 	"os"
 	"strings"
 	"wallet/config"
 	"wallet/core"
+	"wallet/database"
+	"wallet/models"
 	"wallet/pkg/crypto"
 	"wallet/utils"
 
@@ -48,6 +52,10 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // WalletService 钱包服务核心类
@@ -55,15 +63,34 @@ import (
 type WalletService struct {
 	multiChain            *core.MultiChainManager     // 多链管理器，支持动态网络切换
 	sessions              map[string]sessionInfo      // 临时会话存储（助记词等敏感信息）
-	watchOnly             map[string]struct{}         // 只读钱包地址集合（不包含私钥）
 	encryptedWallets      map[string]*EncryptedWallet // 加密存储的钱包信息
 	cryptoManager         *crypto.CryptoManager       // 加密管理器，用于助记词加密
+	sessionCrypto         *crypto.CryptoManager       // 进程级随机密钥，用于包装会话密钥
 	defiService           *DeFiService                // DeFi功能服务实例
 	nftService            *NFTService                 // NFT功能服务实例
 	dappBrowserService    *DAppBrowserService         // DApp浏览器服务实例
+	walletConnect         *WalletConnectService       // WalletConnect v2 会话服务
+	health                *HealthService              // 就绪检查（数据库与各网络RPC）
+	metricsRegistry       *prometheus.Registry        // /metrics 暴露的指标注册表
 	socialService         *SocialService              // 社交功能服务实例
 	securityService       *SecurityService            // 安全功能服务实例
 	nftMarketplaceService *NFTMarketplaceService      // NFT市场服务实例
+	deadlineTracker       *TxDeadlineTracker          // 交易截止时间跟踪器
+	providerKeyService    *ProviderKeyService         // 用户第三方服务密钥服务
+	pendingTxs            *PendingTxTracker           // 待确认交易跟踪器
+	realtimeService       *RealtimeService            // 实时余额与到账推送服务
+	notificationService   *NotificationService        // 通知收件箱与渠道投递服务
+	watchOnlyMonitor      *WatchOnlyMonitor           // 只读地址到账监控
+	relayService          *RelayService               // ERC20 转账代付Gas服务
+	bridgeService         *BridgeService              // 跨链桥接服务实例
+	priceService          *PriceService               // 代币美元价格服务
+	portfolioService      *PortfolioService           // 跨链资产汇总服务
+	addressSummary        *AddressSummaryService      // 地址活跃度摘要服务
+	idempotency           *IdempotencyService         // 发送类请求的幂等键存储
+	signatureDir          *core.SignatureDirectory    // calldata 解码与函数签名库查询
+	tokenMetadata         sync.Map                    // 网络:代币地址 -> *core.ERC20Metadata（元数据不可变，永久缓存）
+	spendingLocks         sync.Map                    // 钱包地址 -> *sync.Mutex，串行化同一钱包的限额校验与额度预留
+	stopReaper            context.CancelFunc          // 停止过期会话清理协程
 	mu                    sync.RWMutex                // 读写锁，保证并发安全
 }
 
@@ -80,9 +107,18 @@ func NewWalletService() *WalletService {
 
 	// 初始化加密管理器（实际生产环境应该从配置或环境变量获取密码）
 	cryptoManager := crypto.NewCryptoManager("wallet_master_key_2024")
+	// 会话密钥包装使用进程内随机密钥，不落盘
+	sessionCrypto, err := crypto.NewEphemeralCryptoManager()
+	if err != nil {
+		panic(fmt.Errorf("初始化会话加密失败: %w", err))
+	}
+
+	// 初始化价格服务
+	priceService := NewPriceService(multiChain, config.AppConfig.Price)
 
 	// 初始化DeFi服务
 	defiService := NewDeFiService(multiChain)
+	defiService.priceService = priceService
 	// 设置1inch API密钥
 	oneInchAPIKey := config.AppConfig.Security.OneInchAPIKey
 	// 如果配置文件中没有设置，尝试从环境变量获取
@@ -99,6 +135,12 @@ func NewWalletService() *WalletService {
 		panic(fmt.Errorf("初始化NFT服务失败: %w", err))
 	}
 
+	// 初始化跨链桥接服务
+	bridgeService, err := NewBridgeService(multiChain)
+	if err != nil {
+		panic(fmt.Errorf("初始化桥接服务失败: %w", err))
+	}
+
 	// 初始化DApp浏览器
 	dappBrowser := core.NewDAppBrowser(multiChain)
 	dappBrowserService := NewDAppBrowserService(dappBrowser, nil) // 将在创建完WalletService后设置
@@ -106,29 +148,81 @@ func NewWalletService() *WalletService {
 	walletService := &WalletService{
 		multiChain:         multiChain,
 		sessions:           make(map[string]sessionInfo),
-		watchOnly:          make(map[string]struct{}),
 		encryptedWallets:   make(map[string]*EncryptedWallet),
 		cryptoManager:      cryptoManager,
+		sessionCrypto:      sessionCrypto,
 		defiService:        defiService,
 		nftService:         nftService,
 		dappBrowserService: dappBrowserService,
-	}
+		deadlineTracker:    NewTxDeadlineTracker(multiChain),
+		providerKeyService: NewProviderKeyService(cryptoManager),
+		pendingTxs:         NewPendingTxTracker(multiChain, config.AppConfig.Pending),
+		realtimeService:    NewRealtimeService(multiChain),
+		bridgeService:      bridgeService,
+		priceService:       priceService,
+		relayService:       NewRelayService(multiChain, config.AppConfig.Relay),
+		portfolioService:   NewPortfolioService(multiChain, priceService, config.AppConfig.Portfolio),
+		addressSummary:     NewAddressSummaryService(multiChain, config.AppConfig.AddressInfo),
+	}
+	walletService.idempotency = NewIdempotencyService(config.AppConfig.Idempotency, walletService.GetSessionAddress)
+	decoderCfg := config.AppConfig.Decoder.WithDefaults()
+	walletService.signatureDir = core.NewSignatureDirectory(decoderCfg.SignatureDBURL, time.Duration(decoderCfg.CacheHours)*time.Hour)
+	// 签名前的手续费上限检查（需在加载自定义网络之前设置，之后添加的网络同样生效）
+	multiChain.SetFeeCeiling(walletService.feeCeilingWei)
+	// 交易历史缓存（同样需在加载自定义网络之前设置）
+	configureHistoryStore(multiChain, config.AppConfig.History)
+	// 节点调用指标（同样需在加载自定义网络之前设置）
+	walletService.metricsRegistry = newMetricsRegistry(multiChain)
+
+	// 加载用户添加的自定义网络
+	walletService.LoadCustomNetworks()
+	// 加载各网络的确认数设置（需在自定义网络之后）
+	walletService.LoadRequiredConfirmations()
+
+	// 启动过期会话后台清理
+	walletService.StartSessionReaper(defaultSessionReapInterval)
 
 	// 设置DApp浏览器服务的钱包服务引用
 	dappBrowserService.walletService = walletService
+	// WalletConnect 会话请求经 DApp 浏览器的确认流程处理
+	walletService.walletConnect = NewWalletConnectService(config.AppConfig.WalletConnect, multiChain, dappBrowserService)
+	walletService.health = NewHealthService(multiChain)
+	// 设置DeFi服务的钱包服务引用（交易签名）
+	defiService.walletService = walletService
 
 	// 初始化社交服务
 	socialService := NewSocialService(walletService)
 	walletService.socialService = socialService
+	socialService.StartSharePurger(defaultSharePurgeInterval)
+
+	// 初始化通知服务：写入收件箱并投递到实时连接、邮件与 Webhook，到账交易与多签审批经其通知
+	notificationService := NewNotificationService(config.AppConfig.Notifications, NewRealtimePushProvider(walletService.realtimeService))
+	walletService.notificationService = notificationService
+	walletService.realtimeService.SetNotificationService(notificationService)
 
 	// 初始化安全服务
 	securityService := NewSecurityService(walletService)
 	walletService.securityService = securityService
+	securityService.SetNotificationService(notificationService)
+	// DApp/WalletConnect 交易签名前检查全局收款地址黑名单
+	dappBrowser.SetAddressBlocklist(securityService.securityManager.AddressBlocklist())
 
 	// 初始化NFT市场服务
 	nftMarketplaceService := NewNFTMarketplaceService(nftService)
+	nftMarketplaceService.priceService = priceService
 	walletService.nftMarketplaceService = nftMarketplaceService
 
+	// 启动NFT与代币价格提醒的后台检查，触发时经通知服务投递
+	alertNotifier := NewAlertNotifier(notificationService, config.AppConfig.Alerts)
+	nftMarketplaceService.SetAlertNotifier(alertNotifier)
+	nftMarketplaceService.StartAlertEvaluator(config.AppConfig.Alerts)
+	priceService.SetAlertNotifier(alertNotifier)
+	priceService.StartAlertEvaluator(config.AppConfig.Alerts)
+
+	// 启动只读地址到账监控
+	walletService.watchOnlyMonitor = NewWatchOnlyMonitor(multiChain, notificationService, priceService, config.AppConfig.WatchMonitor)
+	walletService.watchOnlyMonitor.Start()
+
 	return walletService
 }
 
@@ -138,12 +232,16 @@ func NewWalletService() *WalletService {
 //	mnemonic - BIP39助记词字符串
 //	derivationPath - BIP44派生路径，空则使用默认路径
 //
-// 返回: 派生的以太坊地址和错误信息
+// 返回: 派生的以太坊地址和错误信息；助记词无效时错误为 core.ErrMnemonicWordCount、
+// core.ErrMnemonicInvalidWord 或 core.ErrMnemonicChecksum
 // 注意: 该方法不会持久化存储助记词，仅用于验证和地址生成
 func (s *WalletService) ImportMnemonic(mnemonic, derivationPath string) (string, error) {
 	if derivationPath == "" {
 		derivationPath = "m/44'/60'/0'/0/0"
 	}
+	if _, err := core.ValidateMnemonic(mnemonic); err != nil {
+		return "", err
+	}
 	addr, err := core.DeriveAddressFromMnemonic(mnemonic, derivationPath)
 	if err != nil {
 		return "", err
@@ -152,7 +250,7 @@ func (s *WalletService) ImportMnemonic(mnemonic, derivationPath string) (string,
 }
 
 // GetBalance 查询地址余额（wei）
-func (s *WalletService) GetBalance(address string) (*big.Int, error) {
+func (s *WalletService) GetBalance(ctx context.Context, address string) (*big.Int, error) {
 	adapter, err := s.multiChain.GetCurrentAdapter()
 	if err != nil {
 		return nil, fmt.Errorf("获取当前链适配器失败: %w", err)
@@ -163,7 +261,6 @@ func (s *WalletService) GetBalance(address string) (*big.Int, error) {
 		return nil, fmt.Errorf("当前链适配器为空")
 	}
 
-	ctx := context.Background()
 	balance, err := adapter.GetBalance(ctx, address)
 	if err != nil {
 		// 如果获取余额失败，返回0而不是错误，避免API 500错误
@@ -173,7 +270,8 @@ func (s *WalletService) GetBalance(address string) (*big.Int, error) {
 }
 
 // SendETH 发送 ETH 交易，返回 txhash（MVP：使用助记词签名，不持久化）
-func (s *WalletService) SendETH(mnemonic, derivationPath, to string, valueWei *big.Int) (string, error) {
+func (s *WalletService) SendETH(ctx context.Context, mnemonic, derivationPath, to string, valueWei *big.Int) (string, error) {
+	ctx = sendContext(ctx)
 	adapter, err := s.multiChain.GetCurrentAdapter()
 	if err != nil {
 		return "", err
@@ -184,8 +282,11 @@ func (s *WalletService) SendETH(mnemonic, derivationPath, to string, valueWei *b
 		if derivationPath == "" {
 			derivationPath = "m/44'/60'/0'/0/0"
 		}
-		ctx := context.Background()
-		return evmAdapter.SendETH(ctx, mnemonic, derivationPath, to, valueWei)
+		txHash, err := evmAdapter.SendETH(ctx, mnemonic, derivationPath, to, valueWei)
+		if err == nil {
+			s.trackPending(s.multiChain.GetCurrentNetwork(), mnemonic, derivationPath, txHash)
+		}
+		return txHash, err
 	}
 
 	// 对于非EVM链，使用通用的SendTransaction方法
@@ -199,12 +300,11 @@ func (s *WalletService) SendETH(mnemonic, derivationPath, to string, valueWei *b
 		return "", err
 	}
 
-	ctx := context.Background()
 	return adapter.SendTransaction(ctx, fromAddr, to, valueWei, mnemonic)
 }
 
 // GetERC20Balance 查询 ERC20 余额（最小单位）
-func (s *WalletService) GetERC20Balance(address, token string) (*big.Int, error) {
+func (s *WalletService) GetERC20Balance(ctx context.Context, address, token string) (*big.Int, error) {
 	adapter, err := s.multiChain.GetCurrentAdapter()
 	if err != nil {
 		return nil, err
@@ -212,17 +312,105 @@ func (s *WalletService) GetERC20Balance(address, token string) (*big.Int, error)
 
 	// 类型断言，检查是否为EVM适配器
 	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
-		ctx := context.Background()
 		return evmAdapter.GetERC20Balance(ctx, token, address)
 	}
 
 	// 对于非EVM链，使用通用的GetTokenBalance方法
-	ctx := context.Background()
 	return adapter.(core.TokenSupporter).GetTokenBalance(ctx, token, address)
 }
 
+// GetERC20BalancesBatch 批量查询多个ERC20代币余额（当前网络配置了 Multicall3 时合并为一次调用）
+func (s *WalletService) GetERC20BalancesBatch(ctx context.Context, address string, tokens []string) (map[string]*big.Int, error) {
+	adapter, err := s.multiChain.GetCurrentAdapter()
+	if err != nil {
+		return nil, err
+	}
+
+	// 类型断言，检查是否为EVM适配器
+	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
+		return evmAdapter.GetERC20BalancesBatch(ctx, address, tokens)
+	}
+
+	// 对于非EVM链，返回错误
+	return nil, fmt.Errorf("当前链不支持批量代币余额查询")
+}
+
+// TokenBalanceWithMetadata 代币余额及其元数据与美元价值
+type TokenBalanceWithMetadata struct {
+	Address   string  `json:"address"`             // 持有地址
+	Token     string  `json:"token"`               // 代币地址
+	Network   string  `json:"network"`             // 网络
+	Name      string  `json:"name"`                // 代币名称
+	Symbol    string  `json:"symbol"`              // 代币符号
+	Decimals  int     `json:"decimals"`            // 小数位数
+	Balance   string  `json:"balance"`             // 余额（最小单位）
+	Formatted string  `json:"formatted"`           // 按小数位格式化的余额
+	PriceUSD  float64 `json:"price_usd,omitempty"` // 美元价格
+	ValueUSD  float64 `json:"value_usd,omitempty"` // 美元价值
+	NoPrice   bool    `json:"no_price"`            // 是否缺少价格数据（价格服务不可用或无报价）
+}
+
+// GetTokenBalanceWithMetadata 一次查询代币余额、名称、符号、小数位与美元价值
+// 余额每次实时查询；元数据按 (网络, 代币) 永久缓存，命中缓存时只查询余额
+func (s *WalletService) GetTokenBalanceWithMetadata(ctx context.Context, owner, token string) (*TokenBalanceWithMetadata, error) {
+	evmAdapter, err := s.currentEVMAdapter("代币余额查询")
+	if err != nil {
+		return nil, err
+	}
+	networkID := s.multiChain.GetCurrentNetwork()
+	key := networkID + ":" + strings.ToLower(token)
+	var cached *core.ERC20Metadata
+	if v, ok := s.tokenMetadata.Load(key); ok {
+		cached = v.(*core.ERC20Metadata)
+	}
+	balance, meta, err := evmAdapter.GetERC20BalanceWithMetadata(ctx, owner, token, cached)
+	if err != nil {
+		return nil, err
+	}
+	if cached == nil {
+		s.tokenMetadata.Store(key, meta)
+	}
+
+	result := &TokenBalanceWithMetadata{
+		Address:   common.HexToAddress(owner).Hex(),
+		Token:     common.HexToAddress(token).Hex(),
+		Network:   networkID,
+		Name:      meta.Name,
+		Symbol:    meta.Symbol,
+		Decimals:  meta.Decimals,
+		Balance:   balance.String(),
+		Formatted: core.FormatUnits(balance, meta.Decimals),
+		NoPrice:   true,
+	}
+	if chainID := ChainIDForNetwork(networkID); s.priceService != nil && chainID != 0 {
+		if price, err := s.priceService.GetTokenPriceUSD(ctx, chainID, token); err == nil && price > 0 {
+			result.PriceUSD = price
+			result.ValueUSD = ValueUSD(balance, meta.Decimals, price)
+			result.NoPrice = false
+		}
+	}
+	return result, nil
+}
+
+// GetERC20BalanceAtBlock 查询指定区块高度时的ERC20余额（用于税务等历史对账）
+func (s *WalletService) GetERC20BalanceAtBlock(ctx context.Context, address, token string, blockNumber uint64) (*big.Int, error) {
+	adapter, err := s.multiChain.GetCurrentAdapter()
+	if err != nil {
+		return nil, err
+	}
+
+	// 类型断言，检查是否为EVM适配器
+	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
+		return evmAdapter.GetERC20BalanceAtBlock(ctx, token, address, blockNumber)
+	}
+
+	// 对于非EVM链，返回错误
+	return nil, fmt.Errorf("当前链不支持历史余额查询")
+}
+
 // SendERC20 发送 ERC20 转账
-func (s *WalletService) SendERC20(mnemonic, derivationPath, token, to string, amount *big.Int) (string, error) {
+func (s *WalletService) SendERC20(ctx context.Context, mnemonic, derivationPath, token, to string, amount *big.Int) (string, error) {
+	ctx = sendContext(ctx)
 	adapter, err := s.multiChain.GetCurrentAdapter()
 	if err != nil {
 		return "", err
@@ -233,8 +421,11 @@ func (s *WalletService) SendERC20(mnemonic, derivationPath, token, to string, am
 		if derivationPath == "" {
 			derivationPath = "m/44'/60'/0'/0/0"
 		}
-		ctx := context.Background()
-		return evmAdapter.SendERC20(ctx, mnemonic, derivationPath, token, to, amount)
+		txHash, err := evmAdapter.SendERC20(ctx, mnemonic, derivationPath, token, to, amount)
+		if err == nil {
+			s.trackPending(s.multiChain.GetCurrentNetwork(), mnemonic, derivationPath, txHash)
+		}
+		return txHash, err
 	}
 
 	// 对于非EVM链，使用通用的SendTokenTransaction方法
@@ -248,12 +439,11 @@ func (s *WalletService) SendERC20(mnemonic, derivationPath, token, to string, am
 		return "", err
 	}
 
-	ctx := context.Background()
 	return adapter.(core.TokenSupporter).SendTokenTransaction(ctx, fromAddr, to, token, amount, mnemonic)
 }
 
 // GetNonces 获取地址的 latest 与 pending nonce
-func (s *WalletService) GetNonces(address string) (pending uint64, latest uint64, err error) {
+func (s *WalletService) GetNonces(ctx context.Context, address string) (pending uint64, latest uint64, err error) {
 	adapter, err := s.multiChain.GetCurrentAdapter()
 	if err != nil {
 		return 0, 0, err
@@ -261,7 +451,6 @@ func (s *WalletService) GetNonces(address string) (pending uint64, latest uint64
 
 	// 类型断言，检查是否为EVM适配器
 	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
-		ctx := context.Background()
 		return evmAdapter.GetNonces(ctx, address)
 	}
 
@@ -269,18 +458,52 @@ func (s *WalletService) GetNonces(address string) (pending uint64, latest uint64
 	return 0, 0, fmt.Errorf("当前链不支持nonce查询")
 }
 
+// DetectNonceGap 检测地址在当前网络上阻塞的待处理交易，并针对最低 nonce 给出加速或取消建议
+// 本服务广播且仍在跟踪的交易用于在节点不支持 txpool_contentFrom 时补全交易内容
+func (s *WalletService) DetectNonceGap(ctx context.Context, address string) (*core.NonceGapReport, error) {
+	evmAdapter, err := s.currentEVMAdapter("nonce阻塞检测")
+	if err != nil {
+		return nil, err
+	}
+	return evmAdapter.DetectNonceGap(ctx, address, s.pendingTxs.KnownNonces(s.multiChain.GetCurrentNetwork(), address))
+}
+
 // GetGasSuggestion 获取 EIP-1559/legacy gas 建议
-func (s *WalletService) GetGasSuggestion() (*core.GasSuggestion, error) {
+func (s *WalletService) GetGasSuggestion(ctx context.Context) (*core.GasSuggestion, error) {
 	adapter, err := s.multiChain.GetCurrentAdapter()
 	if err != nil {
 		return nil, err
 	}
-	ctx := context.Background()
+	// EVM链优先使用费用历史给出分档建议（不支持时内部回退）
+	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
+		return evmAdapter.GetGasSuggestionFromHistory(ctx, 20, core.DefaultFeeHistoryPercentiles)
+	}
 	return adapter.GetGasSuggestion(ctx)
 }
 
+// GetNativeCurrency 获取当前网络的原生代币符号与小数位
+func (s *WalletService) GetNativeCurrency() core.NativeCurrency {
+	return s.multiChain.GetCurrentNativeCurrency()
+}
+
+// GetMempoolStatus 获取当前网络的内存池与拥堵状态
+func (s *WalletService) GetMempoolStatus(ctx context.Context) (*core.MempoolStatus, error) {
+	adapter, err := s.multiChain.GetCurrentAdapter()
+	if err != nil {
+		return nil, err
+	}
+
+	// 类型断言，检查是否为EVM适配器
+	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
+		return evmAdapter.GetMempoolStatus(ctx)
+	}
+
+	// 对于非EVM链，返回错误
+	return nil, fmt.Errorf("当前链不支持拥堵状态查询")
+}
+
 // EstimateGas 估算交易 gasLimit（valueWei 可为 nil 或 0，data 可为 0xHex 或 空）
-func (s *WalletService) EstimateGas(from, to string, valueWei *big.Int, data []byte) (uint64, error) {
+func (s *WalletService) EstimateGas(ctx context.Context, from, to string, valueWei *big.Int, data []byte) (uint64, error) {
 	adapter, err := s.multiChain.GetCurrentAdapter()
 	if err != nil {
 		return 0, err
@@ -288,7 +511,6 @@ func (s *WalletService) EstimateGas(from, to string, valueWei *big.Int, data []b
 
 	// 类型断言，检查是否为EVM适配器
 	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
-		ctx := context.Background()
 		// 将 data 视为 hex 字符串进行解析
 		raw := strings.TrimSpace(string(data))
 		if raw == "" {
@@ -306,6 +528,66 @@ func (s *WalletService) EstimateGas(from, to string, valueWei *big.Int, data []b
 	return 0, fmt.Errorf("当前链不支持Gas估算")
 }
 
+// SimulateTransaction 在当前网络最新区块模拟执行交易（data 为 hex 字符串，可为空）
+func (s *WalletService) SimulateTransaction(ctx context.Context, from, to string, valueWei *big.Int, dataHex string) (*core.SimulationResult, error) {
+	var data []byte
+	if raw := strings.TrimPrefix(strings.TrimSpace(dataHex), "0x"); raw != "" {
+		decoded, err := hexToBytes(raw)
+		if err != nil {
+			return nil, fmt.Errorf("解析 data(hex) 失败: %w", err)
+		}
+		data = decoded
+	}
+	return s.simulate(ctx, from, to, valueWei, data)
+}
+
+// SimulateERC20Transfer 模拟 ERC20 转账（余额不足、代币暂停等会在此阶段暴露）
+func (s *WalletService) SimulateERC20Transfer(ctx context.Context, from, token, to string, amount *big.Int) (*core.SimulationResult, error) {
+	data, err := core.ERC20TransferData(to, amount)
+	if err != nil {
+		return nil, err
+	}
+	return s.simulate(ctx, from, token, big.NewInt(0), data)
+}
+
+// GenerateAccessList 在当前网络为交易生成 EIP-2930 访问列表并给出节省的 Gas（data 为 hex 字符串，可为空）
+func (s *WalletService) GenerateAccessList(ctx context.Context, from, to string, valueWei *big.Int, dataHex string) (*core.AccessListResult, error) {
+	var data []byte
+	if raw := strings.TrimPrefix(strings.TrimSpace(dataHex), "0x"); raw != "" {
+		decoded, err := hexToBytes(raw)
+		if err != nil {
+			return nil, fmt.Errorf("解析 data(hex) 失败: %w", err)
+		}
+		data = decoded
+	}
+	evmAdapter, err := s.currentEVMAdapter("访问列表")
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	return evmAdapter.GenerateAccessList(ctx, from, to, valueWei, data)
+}
+
+func (s *WalletService) simulate(ctx context.Context, from, to string, valueWei *big.Int, data []byte) (*core.SimulationResult, error) {
+	adapter, err := s.multiChain.GetCurrentAdapter()
+	if err != nil {
+		return nil, err
+	}
+	evmAdapter, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		return nil, fmt.Errorf("当前链不支持交易模拟")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	return evmAdapter.SimulateTransaction(ctx, from, to, valueWei, data)
+}
+
+// sendContext 签名与广播使用的上下文：保留请求ID用于日志关联，但不随客户端断开而取消，避免发送中途中止
+func sendContext(ctx context.Context) context.Context {
+	return context.WithoutCancel(ctx)
+}
+
 // hexToBytes 本地解析（与 core 中一致的轻量实现）
 func hexToBytes(s string) ([]byte, error) {
 	if len(s)%2 == 1 {
@@ -333,7 +615,8 @@ func hexToBytes(s string) ([]byte, error) {
 }
 
 // BroadcastRawTx 广播原始交易
-func (s *WalletService) BroadcastRawTx(rawTxHex string) (string, error) {
+func (s *WalletService) BroadcastRawTx(ctx context.Context, rawTxHex string) (string, error) {
+	ctx = sendContext(ctx)
 	adapter, err := s.multiChain.GetCurrentAdapter()
 	if err != nil {
 		return "", err
@@ -341,7 +624,6 @@ func (s *WalletService) BroadcastRawTx(rawTxHex string) (string, error) {
 
 	// 类型断言，检查是否为EVM适配器
 	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
-		ctx := context.Background()
 		return evmAdapter.BroadcastRawTransaction(ctx, rawTxHex)
 	}
 
@@ -350,11 +632,22 @@ func (s *WalletService) BroadcastRawTx(rawTxHex string) (string, error) {
 }
 
 // sessionInfo 会话信息
+// 助记词（或 Keystore 导入的私钥）使用每个会话独立的随机密钥加密，会话密钥再由进程级密钥包装，内存中不保存明文
 type sessionInfo struct {
-	Mnemonic       string    `json:"mnemonic"`
-	DerivationPath string    `json:"derivation_path"`
-	CreatedAt      time.Time `json:"created_at"`
-	ExpiresAt      time.Time `json:"expires_at"`
+	EncryptedMnemonic   *crypto.EncryptedData `json:"-"`               // 会话密钥加密的助记词
+	EncryptedPrivateKey *crypto.EncryptedData `json:"-"`               // 会话密钥加密的私钥（Keystore 会话）
+	WrappedKey          *crypto.EncryptedData `json:"-"`               // 进程级密钥包装的会话密钥
+	KeyAddress          string                `json:"key_address"`     // Keystore 会话的账户地址
+	DerivationPath      string                `json:"derivation_path"` // 派生路径
+	CreatedAt           time.Time             `json:"created_at"`
+	ExpiresAt           time.Time             `json:"expires_at"`
+	Owner               string                `json:"-"` // 登录时绑定的钱包地址（小写），用于按设备撤销会话
+	DeviceFingerprint   string                `json:"-"` // 登录设备指纹
+}
+
+// isKeySession 会话是否由 Keystore 私钥创建（不含助记词）
+func (s *sessionInfo) isKeySession() bool {
+	return s.EncryptedPrivateKey != nil
 }
 
 // GetExpireAt 获取过期时间
@@ -403,7 +696,53 @@ func (s *WalletService) getSessionMnemonic(sessionID string) (string, error) {
 	if time.Now().After(info.ExpiresAt) {
 		return "", errors.New("session 已过期")
 	}
-	return info.Mnemonic, nil
+	if info.isKeySession() {
+		return "", errors.New("该会话由 Keystore 导入，不包含助记词")
+	}
+	return s.decryptSessionMnemonic(info)
+}
+
+// encryptSessionMnemonic 生成会话密钥加密助记词，并用进程级密钥包装会话密钥
+func (s *WalletService) encryptSessionMnemonic(mnemonic string) (*crypto.EncryptedData, *crypto.EncryptedData, error) {
+	plaintext := []byte(mnemonic)
+	defer crypto.ZeroBytes(plaintext)
+	return s.encryptSessionSecret(plaintext)
+}
+
+// encryptSessionSecret 生成会话密钥加密敏感数据，并用进程级密钥包装会话密钥
+func (s *WalletService) encryptSessionSecret(plaintext []byte) (*crypto.EncryptedData, *crypto.EncryptedData, error) {
+	key := make([]byte, 32)
+	defer crypto.ZeroBytes(key)
+	if _, err := rand.Read(key); err != nil {
+		return nil, nil, fmt.Errorf("生成会话密钥失败: %w", err)
+	}
+
+	encrypted, err := s.sessionCrypto.EncryptWithKey(plaintext, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("加密会话数据失败: %w", err)
+	}
+	wrappedKey, err := s.sessionCrypto.WrapKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("包装会话密钥失败: %w", err)
+	}
+	return encrypted, wrappedKey, nil
+}
+
+// decryptSessionMnemonic 按需解密会话助记词，用完即清零中间缓冲区
+// Go 字符串不可变，返回的助记词字符串无法清零，调用方应避免长期持有
+func (s *WalletService) decryptSessionMnemonic(info sessionInfo) (string, error) {
+	key, err := s.sessionCrypto.UnwrapKey(info.WrappedKey)
+	if err != nil {
+		return "", fmt.Errorf("解包会话密钥失败: %w", err)
+	}
+	defer crypto.ZeroBytes(key)
+
+	plaintext, err := s.sessionCrypto.DecryptWithKey(info.EncryptedMnemonic, key)
+	if err != nil {
+		return "", fmt.Errorf("解密会话助记词失败: %w", err)
+	}
+	defer crypto.ZeroBytes(plaintext)
+	return string(plaintext), nil
 }
 
 // GetSessionMnemonic 获取会话助记词（对外接口）
@@ -413,6 +752,12 @@ func (s *WalletService) GetSessionMnemonic(sessionID string) (string, error) {
 
 // CreateSession 创建临时会话
 func (s *WalletService) CreateSession(mnemonic, derivationPath string) (string, error) {
+	// 加密助记词（在加锁前完成，避免阻塞其他会话操作）
+	encMnemonic, wrappedKey, err := s.encryptSessionMnemonic(mnemonic)
+	if err != nil {
+		return "", err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -424,18 +769,123 @@ func (s *WalletService) CreateSession(mnemonic, derivationPath string) (string,
 
 	// 存储会话信息
 	s.sessions[sessionID] = sessionInfo{
-		Mnemonic:       mnemonic,
-		DerivationPath: derivationPath,
-		CreatedAt:      time.Now(),
-		ExpiresAt:      expiresAt,
+		EncryptedMnemonic: encMnemonic,
+		WrappedKey:        wrappedKey,
+		DerivationPath:    derivationPath,
+		CreatedAt:         time.Now(),
+		ExpiresAt:         expiresAt,
 	}
 
-	// 启动定时清理任务
-	go s.cleanupExpiredSessions()
-
 	return sessionID, nil
 }
 
+// ImportKeystoreToSession 解密 V3 Keystore 并创建临时会话，返回会话ID与账户地址
+// 私钥与助记词会话一样以会话密钥加密保存，可用于该会话内的交易签名
+func (s *WalletService) ImportKeystoreToSession(keystoreJSON []byte, password string) (string, string, error) {
+	address, handle, err := core.ImportKeystore(keystoreJSON, password)
+	if err != nil {
+		return "", "", err
+	}
+	defer core.ReleaseKeyHandle(handle)
+
+	signer, err := core.KeyHandleSigner(handle)
+	if err != nil {
+		return "", "", err
+	}
+	keyBytes := signer.PrivateKeyBytes()
+	defer crypto.ZeroBytes(keyBytes)
+	encKey, wrappedKey, err := s.encryptSessionSecret(keyBytes)
+	if err != nil {
+		return "", "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessionID := utils.GenerateSessionID()
+	s.sessions[sessionID] = sessionInfo{
+		EncryptedPrivateKey: encKey,
+		WrappedKey:          wrappedKey,
+		KeyAddress:          address,
+		CreatedAt:           time.Now(),
+		ExpiresAt:           time.Now().Add(1 * time.Hour),
+	}
+	return sessionID, address, nil
+}
+
+// SessionSigner 获取会话的交易签名者
+// 助记词会话按派生路径派生私钥（为空时使用默认路径），Keystore 会话忽略派生路径
+func (s *WalletService) SessionSigner(sessionID, derivationPath string) (core.Signer, error) {
+	session, err := s.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.isKeySession() {
+		key, err := s.sessionCrypto.UnwrapKey(session.WrappedKey)
+		if err != nil {
+			return nil, fmt.Errorf("解包会话密钥失败: %w", err)
+		}
+		defer crypto.ZeroBytes(key)
+		keyBytes, err := s.sessionCrypto.DecryptWithKey(session.EncryptedPrivateKey, key)
+		if err != nil {
+			return nil, fmt.Errorf("解密会话私钥失败: %w", err)
+		}
+		defer crypto.ZeroBytes(keyBytes)
+		return core.PrivateKeySignerFromBytes(keyBytes)
+	}
+
+	mnemonic, err := s.decryptSessionMnemonic(*session)
+	if err != nil {
+		return nil, err
+	}
+	if derivationPath == "" {
+		derivationPath = "m/44'/60'/0'/0/0"
+	}
+	return core.NewMnemonicSigner(mnemonic, derivationPath)
+}
+
+// ExportKeystore 将会话钱包指定派生路径的私钥导出为 V3 Keystore，用于备份
+func (s *WalletService) ExportKeystore(sessionID, derivationPath, password string) ([]byte, error) {
+	mnemonic, err := s.getSessionMnemonic(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if derivationPath == "" {
+		derivationPath = "m/44'/60'/0'/0/0"
+	}
+	return core.ExportKeystore(mnemonic, derivationPath, password)
+}
+
+// GetSessionAddress 获取会话对应的钱包地址
+func (s *WalletService) GetSessionAddress(sessionID string) (string, error) {
+	session, err := s.GetSession(sessionID)
+	if err != nil {
+		return "", err
+	}
+	if session.isKeySession() {
+		return session.KeyAddress, nil
+	}
+	mnemonic, err := s.decryptSessionMnemonic(*session)
+	if err != nil {
+		return "", err
+	}
+	derivationPath := session.DerivationPath
+	if derivationPath == "" {
+		derivationPath = "m/44'/60'/0'/0/0"
+	}
+	return core.DeriveAddressFromMnemonic(mnemonic, derivationPath)
+}
+
+// WithUserProviderKeys 将会话用户配置的第三方服务密钥附加到context
+// 会话无效时原样返回ctx，下游将使用全局密钥
+func (s *WalletService) WithUserProviderKeys(ctx context.Context, sessionID string) context.Context {
+	address, err := s.GetSessionAddress(sessionID)
+	if err != nil {
+		return ctx
+	}
+	return s.providerKeyService.WithUserProviderKeys(ctx, address)
+}
+
 // GetSession 获取会话信息
 func (s *WalletService) GetSession(sessionID string) (*sessionInfo, error) {
 	s.mu.RLock()
@@ -456,6 +906,42 @@ func (s *WalletService) GetSession(sessionID string) (*sessionInfo, error) {
 	return &session, nil
 }
 
+// BindSessionDevice 记录会话的登录设备，撤销设备时据此使会话失效
+func (s *WalletService) BindSessionDevice(sessionID, owner, fingerprint string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session, ok := s.sessions[sessionID]; ok {
+		session.Owner = strings.ToLower(owner)
+		session.DeviceFingerprint = fingerprint
+		s.sessions[sessionID] = session
+	}
+}
+
+// SessionDevice 返回会话绑定的登录设备指纹，未绑定时为空
+func (s *WalletService) SessionDevice(sessionID string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.sessions[sessionID].DeviceFingerprint
+}
+
+// ClearDeviceSessions 清除用户在指定设备上的全部会话，返回清除数量
+func (s *WalletService) ClearDeviceSessions(owner, fingerprint string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	owner = strings.ToLower(owner)
+	cleared := 0
+	for id, session := range s.sessions {
+		if session.Owner == owner && session.DeviceFingerprint == fingerprint {
+			delete(s.sessions, id)
+			cleared++
+		}
+	}
+	return cleared
+}
+
 // ClearSession 清理会话
 func (s *WalletService) ClearSession(sessionID string) {
 	s.mu.Lock()
@@ -464,41 +950,116 @@ func (s *WalletService) ClearSession(sessionID string) {
 	delete(s.sessions, sessionID)
 }
 
-// cleanupExpiredSessions 清理过期会话
-func (s *WalletService) cleanupExpiredSessions() {
+// defaultSessionReapInterval 过期会话默认清理间隔
+const defaultSessionReapInterval = 60 * time.Second
+
+// cleanupExpiredSessions 清理过期会话，返回清理数量
+func (s *WalletService) cleanupExpiredSessions() int {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	now := time.Now()
+	reaped := 0
 	for sessionID, session := range s.sessions {
-		if now.After(session.ExpiresAt) {
+		if now.After(session.GetExpireAt()) {
 			delete(s.sessions, sessionID)
+			reaped++
+		}
+	}
+	return reaped
+}
+
+// StartSessionReaper 启动后台协程，每隔 interval 清理过期会话
+// 避免无人访问的会话长期驻留内存；重复调用会先停止已有的清理协程，interval<=0 时使用默认值
+func (s *WalletService) StartSessionReaper(interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultSessionReapInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s.mu.Lock()
+	if s.stopReaper != nil {
+		s.stopReaper()
+	}
+	s.stopReaper = cancel
+	s.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if reaped := s.cleanupExpiredSessions(); reaped > 0 {
+					slog.DebugContext(context.Background(), "expired sessions reaped", "count", reaped)
+				}
+			}
 		}
+	}()
+}
+
+// Close 停止钱包服务的后台清理协程（过期会话与过期分享）
+func (s *WalletService) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopReaper != nil {
+		s.stopReaper()
+		s.stopReaper = nil
+	}
+	if s.socialService != nil {
+		s.socialService.Close()
 	}
 }
 
 // SendETHWithSession 通过会话发送ETH
-func (s *WalletService) SendETHWithSession(sessionID, derivationPath, to string, valueWei *big.Int) (string, error) {
-	// 获取会话信息
-	session, err := s.GetSession(sessionID)
+// EVM链使用会话签名者发送，助记词会话与 Keystore 会话均可用；非EVM链需要助记词会话
+func (s *WalletService) SendETHWithSession(ctx context.Context, sessionID, derivationPath, to string, valueWei *big.Int) (string, error) {
+	adapter, err := s.multiChain.GetCurrentAdapter()
 	if err != nil {
-		return "", fmt.Errorf("无效会话: %w", err)
+		return "", err
+	}
+	evmAdapter, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		mnemonic, err := s.getSessionMnemonic(sessionID)
+		if err != nil {
+			return "", fmt.Errorf("无效会话: %w", err)
+		}
+		return s.SendETH(ctx, mnemonic, derivationPath, to, valueWei)
 	}
 
-	// 使用会话中的助记词发送交易
-	return s.SendETH(session.Mnemonic, derivationPath, to, valueWei)
+	signer, err := s.SessionSigner(sessionID, derivationPath)
+	if err != nil {
+		return "", fmt.Errorf("无效会话: %w", err)
+	}
+	txHash, err := evmAdapter.SendETHWithSigner(sendContext(ctx), signer, to, valueWei, nil)
+	return s.trackSigned(signer, txHash, err)
 }
 
 // SendERC20WithSession 通过会话发送ERC20代币
-func (s *WalletService) SendERC20WithSession(sessionID, derivationPath, token, to string, amount *big.Int) (string, error) {
-	// 获取会话信息
-	session, err := s.GetSession(sessionID)
+// EVM链使用会话签名者发送，助记词会话与 Keystore 会话均可用；非EVM链需要助记词会话
+func (s *WalletService) SendERC20WithSession(ctx context.Context, sessionID, derivationPath, token, to string, amount *big.Int) (string, error) {
+	adapter, err := s.multiChain.GetCurrentAdapter()
 	if err != nil {
-		return "", fmt.Errorf("无效会话: %w", err)
+		return "", err
+	}
+	evmAdapter, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		mnemonic, err := s.getSessionMnemonic(sessionID)
+		if err != nil {
+			return "", fmt.Errorf("无效会话: %w", err)
+		}
+		return s.SendERC20(ctx, mnemonic, derivationPath, token, to, amount)
 	}
 
-	// 使用会话中的助记词发送交易
-	return s.SendERC20(session.Mnemonic, derivationPath, token, to, amount)
+	signer, err := s.SessionSigner(sessionID, derivationPath)
+	if err != nil {
+		return "", fmt.Errorf("无效会话: %w", err)
+	}
+	txHash, err := evmAdapter.SendERC20WithSigner(sendContext(ctx), signer, token, to, amount, nil)
+	return s.trackSigned(signer, txHash, err)
 }
 
 // -------- 批量地址派生（支持会话/助记词） --------
@@ -518,250 +1079,911 @@ func (s *WalletService) DeriveAddressesBySession(sessionID, pathPrefix string, s
 	return s.DeriveAddressesFromMnemonic(mn, pathPrefix, start, count)
 }
 
-// -------- 只读钱包（watch-only） --------
+// DeriveAddressesScheme 按钱包派生标准从助记词批量生成地址（含完整派生路径）
+func (s *WalletService) DeriveAddressesScheme(mnemonic string, scheme core.DerivationScheme, start, count int) ([]core.DerivedAddress, error) {
+	return core.DeriveAddressesScheme(mnemonic, scheme, start, count)
+}
+
+// DeriveAddressesSchemeBySession 按钱包派生标准从会话助记词批量生成地址
+func (s *WalletService) DeriveAddressesSchemeBySession(sessionID string, scheme core.DerivationScheme, start, count int) ([]core.DerivedAddress, error) {
+	mn, err := s.getSessionMnemonic(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return core.DeriveAddressesScheme(mn, scheme, start, count)
+}
+
+// -------- 只读钱包（watch-only） --------
+
+// WatchOnlyEntry 只读钱包地址
+type WatchOnlyEntry struct {
+	Address     string    `json:"address"`         // 校验和格式地址
+	Label       string    `json:"label,omitempty"` // 用户备注
+	Network     string    `json:"network"`         // 所属网络ID
+	MinValueUSD float64   `json:"min_value_usd"`   // 到账通知的最小金额（美元），0 表示全部通知
+	AddedAt     time.Time `json:"added_at"`        // 添加时间
+}
+
+// WatchOnlyBalance 带当前余额的只读钱包地址
+type WatchOnlyBalance struct {
+	WatchOnlyEntry
+	BalanceWei string `json:"balance_wei"`     // 余额（最小单位）
+	Balance    string `json:"balance"`         // 按原生代币小数位格式化的余额
+	Symbol     string `json:"symbol"`          // 原生代币符号
+	Error      string `json:"error,omitempty"` // 余额查询失败原因
+}
+
+func toWatchOnlyEntry(m *models.WatchOnlyAddress) WatchOnlyEntry {
+	return WatchOnlyEntry{Address: m.Address, Label: m.Label, Network: m.Network, MinValueUSD: m.MinValueUSD, AddedAt: m.CreatedAt}
+}
+
+// AddWatchOnly 为用户添加只读钱包地址，已存在时更新标签（label 为空则保留原标签）
+// network 为空时使用当前网络；依赖 (owner, address, network) 唯一索引避免并发重复添加
+func (s *WalletService) AddWatchOnly(owner, address, label, network string) (*WatchOnlyEntry, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	if address == "" {
+		return nil, errors.New("address 不能为空")
+	}
+	if !common.IsHexAddress(address) {
+		return nil, errors.New("address 格式不正确")
+	}
+	if network == "" {
+		network = s.multiChain.GetCurrentNetwork()
+	}
+	record := models.WatchOnlyAddress{
+		OwnerAddress: strings.ToLower(owner),
+		Address:      common.HexToAddress(address).Hex(),
+		Network:      network,
+		Label:        label,
+	}
+
+	conflict := clause.OnConflict{
+		Columns:   []clause.Column{{Name: "owner_address"}, {Name: "address"}, {Name: "network"}},
+		DoNothing: true,
+	}
+	if label != "" {
+		conflict.DoNothing = false
+		conflict.DoUpdates = clause.AssignmentColumns([]string{"label", "updated_at"})
+	}
+	if err := database.DB.Clauses(conflict).Create(&record).Error; err != nil {
+		return nil, fmt.Errorf("保存只读地址失败: %w", err)
+	}
+
+	var saved models.WatchOnlyAddress
+	if err := database.DB.Where("owner_address = ? AND address = ? AND network = ?", record.OwnerAddress, record.Address, record.Network).
+		First(&saved).Error; err != nil {
+		return nil, fmt.Errorf("查询只读地址失败: %w", err)
+	}
+	entry := toWatchOnlyEntry(&saved)
+	return &entry, nil
+}
+
+// SetWatchOnlyLabel 设置只读钱包地址的标签（空字符串表示清除），network 为空时使用当前网络
+func (s *WalletService) SetWatchOnlyLabel(owner, address, network, label string) (*WatchOnlyEntry, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	if network == "" {
+		network = s.multiChain.GetCurrentNetwork()
+	}
+	var record models.WatchOnlyAddress
+	err := database.DB.Where("owner_address = ? AND address = ? AND network = ?",
+		strings.ToLower(owner), common.HexToAddress(address).Hex(), network).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("address 不存在")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询只读地址失败: %w", err)
+	}
+	if err := database.DB.Model(&record).Update("label", label).Error; err != nil {
+		return nil, fmt.Errorf("更新标签失败: %w", err)
+	}
+	entry := toWatchOnlyEntry(&record)
+	return &entry, nil
+}
+
+// SetWatchOnlyThreshold 设置只读钱包地址到账通知的最小金额（美元，0 表示全部通知），network 为空时使用当前网络
+func (s *WalletService) SetWatchOnlyThreshold(owner, address, network string, minValueUSD float64) (*WatchOnlyEntry, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	if minValueUSD < 0 || math.IsNaN(minValueUSD) || math.IsInf(minValueUSD, 0) {
+		return nil, errors.New("min_value_usd 不能为负数")
+	}
+	if network == "" {
+		network = s.multiChain.GetCurrentNetwork()
+	}
+	var record models.WatchOnlyAddress
+	err := database.DB.Where("owner_address = ? AND address = ? AND network = ?",
+		strings.ToLower(owner), common.HexToAddress(address).Hex(), network).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("address 不存在")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询只读地址失败: %w", err)
+	}
+	if err := database.DB.Model(&record).Update("min_value_usd", minValueUSD).Error; err != nil {
+		return nil, fmt.Errorf("更新通知阈值失败: %w", err)
+	}
+	entry := toWatchOnlyEntry(&record)
+	return &entry, nil
+}
+
+// RemoveWatchOnly 删除只读钱包地址，network 为空时使用当前网络
+// 物理删除，避免软删除记录占用唯一索引导致无法再次添加
+func (s *WalletService) RemoveWatchOnly(owner, address, network string) error {
+	if database.DB == nil {
+		return errors.New("数据库未初始化")
+	}
+	if network == "" {
+		network = s.multiChain.GetCurrentNetwork()
+	}
+	result := database.DB.Unscoped().Where("owner_address = ? AND address = ? AND network = ?",
+		strings.ToLower(owner), common.HexToAddress(address).Hex(), network).Delete(&models.WatchOnlyAddress{})
+	if result.Error != nil {
+		return fmt.Errorf("删除只读地址失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("address 不存在")
+	}
+	return nil
+}
+
+// ListWatchOnly 按添加时间返回用户的只读钱包地址列表，network 为空时返回全部网络
+func (s *WalletService) ListWatchOnly(owner, network string) ([]WatchOnlyEntry, error) {
+	if database.DB == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+	query := database.DB.Where("owner_address = ?", strings.ToLower(owner))
+	if network != "" {
+		query = query.Where("network = ?", network)
+	}
+	var records []models.WatchOnlyAddress
+	if err := query.Order("created_at ASC, address ASC").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("查询只读地址失败: %w", err)
+	}
+	res := make([]WatchOnlyEntry, 0, len(records))
+	for i := range records {
+		res = append(res, toWatchOnlyEntry(&records[i]))
+	}
+	return res, nil
+}
+
+// ListWatchOnlyWithBalances 返回只读钱包地址及其所属网络的原生代币余额
+// 单个地址查询失败不影响整体结果，失败原因记录在 Error 字段
+func (s *WalletService) ListWatchOnlyWithBalances(ctx context.Context, owner, network string) ([]WatchOnlyBalance, error) {
+	entries, err := s.ListWatchOnly(owner, network)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]WatchOnlyBalance, 0, len(entries))
+	for _, entry := range entries {
+		native := core.NativeCurrencyFor(entry.Network)
+		item := WatchOnlyBalance{WatchOnlyEntry: entry, Symbol: native.Symbol}
+		adapter, err := s.multiChain.GetAdapter(entry.Network)
+		if err != nil {
+			item.Error = err.Error()
+			res = append(res, item)
+			continue
+		}
+		if bal, err := adapter.GetBalance(ctx, entry.Address); err != nil {
+			item.Error = err.Error()
+		} else {
+			item.BalanceWei = bal.String()
+			item.Balance = core.FormatUnits(bal, native.Decimals)
+		}
+		res = append(res, item)
+	}
+	return res, nil
+}
+
+// -------- 基于会话的发送（免提交助记词） --------
+
+type TxReceiptDTO struct {
+	TxHash            string  `json:"tx_hash"`
+	Status            uint64  `json:"status"`
+	BlockNumber       string  `json:"block_number"`
+	GasUsed           string  `json:"gas_used"`
+	EffectiveGasPrice string  `json:"effective_gas_price,omitempty"`
+	ContractAddress   string  `json:"contract_address,omitempty"`
+	TransactionIndex  uint    `json:"transaction_index"`
+	RevertReason      string  `json:"revert_reason,omitempty"`
+	GasLimit          string  `json:"gas_limit,omitempty"`      // 交易设置的 gasLimit
+	GasUsedRatio      float64 `json:"gas_used_ratio,omitempty"` // gasUsed / gasLimit
+	NearOutOfGas      bool    `json:"near_out_of_gas"`          // 使用比例超过阈值，交易几乎耗尽Gas
+	FeeWei            string  `json:"fee_wei,omitempty"`        // 实际手续费 = gasUsed × effectiveGasPrice
+	Fee               string  `json:"fee,omitempty"`            // 按原生代币小数位格式化的手续费
+	FeeSymbol         string  `json:"fee_symbol,omitempty"`     // 手续费币种（当前网络原生代币）
+}
+
+// nearOutOfGasRatio Gas使用比例超过该值时提示交易几乎耗尽Gas
+const nearOutOfGasRatio = 0.95
+
+func (s *WalletService) GetReceipt(ctx context.Context, txHash string) (*TxReceiptDTO, error) {
+	adapter, err := s.multiChain.GetCurrentAdapter()
+	if err != nil {
+		return nil, err
+	}
+
+	// 类型断言，检查是否为EVM适配器
+	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
+		receipt, err := evmAdapter.GetTransactionReceipt(ctx, txHash)
+		if err != nil {
+			return nil, err
+		}
+		return s.receiptDTO(ctx, evmAdapter, txHash, receipt), nil
+	}
+
+	// 对于非EVM链，返回错误
+	return nil, fmt.Errorf("当前链不支持交易回执查询")
+}
+
+// WaitForConfirmation 等待交易达到指定确认数后返回回执，ctx 截止时返回 core.ErrConfirmationTimeout
+func (s *WalletService) WaitForConfirmation(ctx context.Context, txHash string, confirmations uint64) (*TxReceiptDTO, error) {
+	adapter, err := s.multiChain.GetCurrentAdapter()
+	if err != nil {
+		return nil, err
+	}
+	evmAdapter, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		return nil, fmt.Errorf("当前链不支持交易确认等待")
+	}
+	receipt, err := evmAdapter.WaitForReceipt(ctx, txHash, confirmations)
+	if err != nil {
+		return nil, err
+	}
+	// 等待可能耗尽 ctx，补充信息使用不随其取消的上下文（保留请求ID）
+	return s.receiptDTO(context.WithoutCancel(ctx), evmAdapter, txHash, receipt), nil
+}
+
+// receiptDTO 将回执转换为响应结构，补充手续费、Gas使用比例与 revert reason
+func (s *WalletService) receiptDTO(ctx context.Context, evmAdapter *core.EVMAdapter, txHash string, receipt *types.Receipt) *TxReceiptDTO {
+	dto := &TxReceiptDTO{
+		TxHash:           txHash,
+		Status:           receipt.Status,
+		BlockNumber:      receipt.BlockNumber.String(),
+		GasUsed:          new(big.Int).SetUint64(receipt.GasUsed).String(),
+		TransactionIndex: uint(receipt.TransactionIndex),
+	}
+	if receipt.EffectiveGasPrice != nil {
+		dto.EffectiveGasPrice = receipt.EffectiveGasPrice.String()
+		native := s.GetNativeCurrency()
+		fee, formatted := native.Fee(receipt.GasUsed, receipt.EffectiveGasPrice)
+		dto.FeeWei = fee.String()
+		dto.Fee = formatted
+		dto.FeeSymbol = native.Symbol
+	}
+	if receipt.ContractAddress != (common.Address{}) {
+		// 这里无 CommonAddressZero，直接判断是否为 0 地址
+		if receipt.ContractAddress.Hex() != "0x0000000000000000000000000000000000000000" {
+			dto.ContractAddress = receipt.ContractAddress.Hex()
+		}
+	}
+	// 对比交易设置的 gasLimit，诊断接近耗尽Gas的交易
+	if tx, _, err := evmAdapter.GetTransactionByHash(ctx, txHash); err == nil && tx.Gas() > 0 {
+		dto.GasLimit = new(big.Int).SetUint64(tx.Gas()).String()
+		dto.GasUsedRatio = float64(receipt.GasUsed) / float64(tx.Gas())
+		dto.NearOutOfGas = dto.GasUsedRatio > nearOutOfGasRatio
+	}
+	// 失败时尝试提取 revert reason
+	if receipt.Status == 0 {
+		reason, _ := evmAdapter.GetRevertReason(ctx, txHash)
+		dto.RevertReason = reason
+	}
+	return dto
+}
+
+// GetTransactionLogs 获取交易触发的事件并解码（abiJSON 可选，未匹配的日志返回原始 topics/data）
+func (s *WalletService) GetTransactionLogs(ctx context.Context, txHash, abiJSON string) ([]core.DecodedLog, error) {
+	adapter, err := s.multiChain.GetCurrentAdapter()
+	if err != nil {
+		return nil, err
+	}
+
+	// 类型断言，检查是否为EVM适配器
+	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
+		return evmAdapter.GetTransactionLogs(ctx, txHash, abiJSON)
+	}
+
+	// 对于非EVM链，返回错误
+	return nil, fmt.Errorf("当前链不支持交易事件查询")
+}
+
+// DecodeCalldata 解码任意交易 calldata（只读，不依赖当前网络）；abiJSON 为空时使用内置方法与签名库
+func (s *WalletService) DecodeCalldata(ctx context.Context, data []byte, abiJSON string) (*core.DecodedCall, error) {
+	return s.signatureDir.Decode(ctx, data, abiJSON)
+}
+
+func (s *WalletService) GetTokenMetadata(ctx context.Context, token string) (name, symbol string, decimals uint8, err error) {
+	adapter, err := s.multiChain.GetCurrentAdapter()
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	// 类型断言，检查是否为EVM适配器
+	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
+		return evmAdapter.GetERC20Metadata(ctx, token)
+	}
+
+	// 对于非EVM链，返回错误
+	return "", "", 0, fmt.Errorf("当前链不支持代币元数据查询")
+}
+
+func (s *WalletService) PersonalSign(ctx context.Context, mnemonic, derivationPath, message string) (sigHex, address string, err error) {
+	adapter, err := s.multiChain.GetCurrentAdapter()
+	if err != nil {
+		return "", "", err
+	}
+
+	// 类型断言，检查是否为EVM适配器
+	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
+		return evmAdapter.PersonalSign(ctx, mnemonic, derivationPath, message)
+	}
+
+	// 对于非EVM链，返回错误
+	return "", "", fmt.Errorf("当前链不支持个人签名")
+}
+
+func (s *WalletService) SignTypedDataV4(ctx context.Context, mnemonic, derivationPath string, typedJSON []byte) (sigHex, address string, err error) {
+	adapter, err := s.multiChain.GetCurrentAdapter()
+	if err != nil {
+		return "", "", err
+	}
+
+	// 类型断言，检查是否为EVM适配器
+	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
+		return evmAdapter.SignTypedDataV4(ctx, mnemonic, derivationPath, typedJSON)
+	}
+
+	// 对于非EVM链，返回错误
+	return "", "", fmt.Errorf("当前链不支持EIP-712签名")
+}
+
+// VerifyPersonalSign 验证 personal_sign 签名，返回是否匹配以及恢复出的签名者地址
+func (s *WalletService) VerifyPersonalSign(message, signature, expectedAddress string) (valid bool, recovered string, err error) {
+	evmAdapter, err := s.signatureVerifier()
+	if err != nil {
+		return false, "", err
+	}
+	signer, err := evmAdapter.RecoverPersonalSigner(message, signature)
+	if err != nil {
+		return false, "", err
+	}
+	valid, err = evmAdapter.VerifyPersonalSign(message, signature, expectedAddress)
+	return valid, signer.Hex(), err
+}
+
+// VerifyTypedDataV4 验证 EIP-712 签名，返回是否匹配以及恢复出的签名者地址
+func (s *WalletService) VerifyTypedDataV4(typedJSON []byte, signature, expectedAddress string) (valid bool, recovered string, err error) {
+	evmAdapter, err := s.signatureVerifier()
+	if err != nil {
+		return false, "", err
+	}
+	signer, err := evmAdapter.RecoverTypedDataV4Signer(typedJSON, signature)
+	if err != nil {
+		return false, "", err
+	}
+	valid, err = evmAdapter.VerifyTypedDataV4(typedJSON, signature, expectedAddress)
+	return valid, signer.Hex(), err
+}
+
+// signatureVerifier 返回用于签名验证的EVM适配器
+func (s *WalletService) signatureVerifier() (*core.EVMAdapter, error) {
+	adapter, err := s.multiChain.GetCurrentAdapter()
+	if err != nil {
+		return nil, err
+	}
+	evmAdapter, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		return nil, fmt.Errorf("当前链不支持签名验证")
+	}
+	return evmAdapter, nil
+}
+
+// TxOptions 服务层版本，避免 handler 直接依赖 core
+type TxOptions struct {
+	GasPrice *big.Int
+	TipCap   *big.Int
+	FeeCap   *big.Int
+	GasLimit uint64
+	Nonce    *uint64
+
+	MaxFeeWei    *big.Int // 本笔交易的手续费上限，为空时使用钱包设置（见 fee_ceiling_service.go）
+	AllowHighFee bool     // 已确认高手续费，跳过上限检查
+
+	AccessList     types.AccessList // EIP-2930 访问列表
+	AutoAccessList bool             // 发送前自动生成访问列表（仅在节省 Gas 时使用）
+}
+
+func (s *WalletService) toCoreTxOptions(o *TxOptions) *core.TxOptions {
+	if o == nil {
+		return nil
+	}
+	return &core.TxOptions{
+		GasPrice: o.GasPrice,
+		TipCap:   o.TipCap,
+		FeeCap:   o.FeeCap,
+		GasLimit: o.GasLimit,
+		Nonce:    o.Nonce,
+
+		MaxFeeWei:    o.MaxFeeWei,
+		AllowHighFee: o.AllowHighFee,
+
+		AccessList:     o.AccessList,
+		AutoAccessList: o.AutoAccessList,
+	}
+}
+
+// 高级发送 ETH（支持 TxOptions）
+func (s *WalletService) SendETHAdvanced(ctx context.Context, mnemonic, derivationPath, to string, valueWei *big.Int, opts *TxOptions) (string, error) {
+	ctx = sendContext(ctx)
+	adapter, err := s.multiChain.GetCurrentAdapter()
+	if err != nil {
+		return "", err
+	}
+
+	// 类型断言，检查是否为EVM适配器
+	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
+		txHash, err := evmAdapter.SendETHWithOptions(ctx, mnemonic, derivationPath, to, valueWei, s.toCoreTxOptions(opts))
+		if err == nil {
+			s.trackPending(s.multiChain.GetCurrentNetwork(), mnemonic, derivationPath, txHash)
+		}
+		return txHash, err
+	}
+
+	// 对于非EVM链，返回错误
+	return "", fmt.Errorf("当前链不支持高级ETH发送")
+}
+
+func (s *WalletService) SendETHAdvancedWithSession(ctx context.Context, sessionID, derivationPath, to string, valueWei *big.Int, opts *TxOptions) (string, error) {
+	evmAdapter, err := s.currentEVMAdapter("高级ETH发送")
+	if err != nil {
+		return "", err
+	}
+	signer, err := s.SessionSigner(sessionID, derivationPath)
+	if err != nil {
+		return "", err
+	}
+	txHash, err := evmAdapter.SendETHWithSigner(sendContext(ctx), signer, to, valueWei, s.toCoreTxOptions(opts))
+	return s.trackSigned(signer, txHash, err)
+}
+
+// SendETHAdvancedWithDeadline 高级发送 ETH 并登记截止时间跟踪
+// 超过 validUntil 仍未打包的交易会被标记为过期，autoCancel 为 true 时自动发送取消交易
+// 自动取消所需的助记词加密后保存，跟踪器不持有明文
+func (s *WalletService) SendETHAdvancedWithDeadline(ctx context.Context, mnemonic, derivationPath, to string, valueWei *big.Int, opts *TxOptions, validUntil time.Time, autoCancel bool) (*DeadlineTx, error) {
+	if derivationPath == "" {
+		derivationPath = "m/44'/60'/0'/0/0"
+	}
+	signer, err := core.NewMnemonicSigner(mnemonic, derivationPath)
+	if err != nil {
+		return nil, err
+	}
+	var cancelSigner DeadlineSigner
+	if autoCancel {
+		if cancelSigner, err = s.encryptedMnemonicSigner(mnemonic, derivationPath); err != nil {
+			return nil, err
+		}
+	}
+	return s.sendETHWithDeadline(ctx, signer, cancelSigner, to, valueWei, opts, validUntil, autoCancel)
+}
+
+// SendETHAdvancedWithDeadlineSession 使用会话高级发送 ETH 并登记截止时间跟踪，自动取消时按会话ID重新获取签名者
+func (s *WalletService) SendETHAdvancedWithDeadlineSession(ctx context.Context, sessionID, derivationPath, to string, valueWei *big.Int, opts *TxOptions, validUntil time.Time, autoCancel bool) (*DeadlineTx, error) {
+	signer, err := s.SessionSigner(sessionID, derivationPath)
+	if err != nil {
+		return nil, err
+	}
+	cancelSigner := func() (core.Signer, error) {
+		return s.SessionSigner(sessionID, derivationPath)
+	}
+	return s.sendETHWithDeadline(ctx, signer, cancelSigner, to, valueWei, opts, validUntil, autoCancel)
+}
+
+// sendETHWithDeadline 在当前网络发送 ETH 并按该网络登记截止时间跟踪
+func (s *WalletService) sendETHWithDeadline(ctx context.Context, signer core.Signer, cancelSigner DeadlineSigner, to string, valueWei *big.Int, opts *TxOptions, validUntil time.Time, autoCancel bool) (*DeadlineTx, error) {
+	if !validUntil.After(time.Now()) {
+		return nil, fmt.Errorf("valid_until 必须晚于当前时间")
+	}
+	networkID := s.multiChain.GetCurrentNetwork()
+	adapter, err := s.multiChain.GetAdapter(networkID)
+	if err != nil {
+		return nil, err
+	}
+	evmAdapter, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		return nil, fmt.Errorf("当前链不支持高级ETH发送")
+	}
+	txHash, err := evmAdapter.SendETHWithSigner(sendContext(ctx), signer, to, valueWei, s.toCoreTxOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	from := signer.Address().Hex()
+	s.pendingTxs.Register(networkID, from, txHash)
+	record, err := s.deadlineTracker.Track(networkID, txHash, from, cancelSigner, validUntil, autoCancel)
+	if err != nil {
+		return nil, fmt.Errorf("交易已广播(%s)，但登记截止时间跟踪失败: %w", txHash, err)
+	}
+	return record, nil
+}
+
+// encryptedMnemonicSigner 以会话加密方式保存助记词，返回按需解密并派生签名者的函数
+func (s *WalletService) encryptedMnemonicSigner(mnemonic, derivationPath string) (DeadlineSigner, error) {
+	encrypted, wrappedKey, err := s.encryptSessionMnemonic(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	return func() (core.Signer, error) {
+		mn, err := s.decryptSessionMnemonic(sessionInfo{EncryptedMnemonic: encrypted, WrappedKey: wrappedKey})
+		if err != nil {
+			return nil, err
+		}
+		return core.NewMnemonicSigner(mn, derivationPath)
+	}, nil
+}
+
+// trackSigned 交易广播成功后按签名者地址登记到当前网络的待确认交易跟踪器
+func (s *WalletService) trackSigned(signer core.Signer, txHash string, err error) (string, error) {
+	if err == nil {
+		s.pendingTxs.Register(s.multiChain.GetCurrentNetwork(), signer.Address().Hex(), txHash)
+	}
+	return txHash, err
+}
+
+// trackPending 将已广播的交易登记到待确认交易跟踪器
+func (s *WalletService) trackPending(networkID, mnemonic, derivationPath, txHash string) {
+	if derivationPath == "" {
+		derivationPath = "m/44'/60'/0'/0/0"
+	}
+	from, err := core.DeriveAddressFromMnemonic(mnemonic, derivationPath)
+	if err != nil {
+		return
+	}
+	s.pendingTxs.Register(networkID, from, txHash)
+}
+
+// GetPendingTransactions 查询地址通过本服务发送的交易及其确认状态
+func (s *WalletService) GetPendingTransactions(address string) ([]PendingTx, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("无效的地址格式: %s", address)
+	}
+	return s.pendingTxs.List(address), nil
+}
+
+// GetDeadlineTx 查询交易的截止时间跟踪状态
+func (s *WalletService) GetDeadlineTx(txHash string) (*DeadlineTx, error) {
+	return s.deadlineTracker.Get(txHash)
+}
+
+// 交易替换模式
+const (
+	ReplaceModeSpeedUp = "speed_up" // 加速：以更高费率重发原交易
+	ReplaceModeCancel  = "cancel"   // 取消：以更高费率向自身发送 0 值交易
+)
+
+// ReplaceTxOptions 交易替换参数
+type ReplaceTxOptions struct {
+	GasPrice *big.Int // 新费率，均为空时在原费率基础上自动上调
+	TipCap   *big.Int
+	FeeCap   *big.Int
+	GasLimit uint64
+
+	OriginalTxHash   string   // 可选：原交易哈希
+	OriginalGasPrice *big.Int // 可选：原交易费率，交易池无法查询时用于校验涨幅
+	OriginalTipCap   *big.Int
+	OriginalFeeCap   *big.Int
+
+	MaxFeeWei    *big.Int // 本笔交易的手续费上限（wei），为空时使用钱包设置
+	AllowHighFee bool     // 已确认高手续费，跳过上限检查
+}
 
-func (s *WalletService) AddWatchOnly(address string) (string, error) {
-	if address == "" {
-		return "", errors.New("address 不能为空")
+func (s *WalletService) toCoreReplaceOptions(o *ReplaceTxOptions) *core.ReplaceOptions {
+	if o == nil {
+		return nil
+	}
+	return &core.ReplaceOptions{
+		GasPrice:         o.GasPrice,
+		TipCap:           o.TipCap,
+		FeeCap:           o.FeeCap,
+		GasLimit:         o.GasLimit,
+		OriginalTxHash:   o.OriginalTxHash,
+		OriginalGasPrice: o.OriginalGasPrice,
+		OriginalTipCap:   o.OriginalTipCap,
+		OriginalFeeCap:   o.OriginalFeeCap,
+		MaxFeeWei:        o.MaxFeeWei,
+		AllowHighFee:     o.AllowHighFee,
 	}
-	checksum := common.HexToAddress(address).Hex()
-	s.mu.Lock()
-	s.watchOnly[checksum] = struct{}{}
-	s.mu.Unlock()
-	return checksum, nil
 }
 
-func (s *WalletService) RemoveWatchOnly(address string) error {
-	checksum := common.HexToAddress(address).Hex()
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	if _, ok := s.watchOnly[checksum]; !ok {
-		return errors.New("address 不存在")
+// ReplaceTransaction 按 nonce 加速或取消仍在交易池中的交易，返回新交易哈希
+// 新费率需比原交易至少高 10%（core.ReplacementMinBumpPercent）
+func (s *WalletService) ReplaceTransaction(ctx context.Context, mnemonic, derivationPath, mode string, nonce uint64, opts *ReplaceTxOptions) (string, error) {
+	ctx = sendContext(ctx)
+	if derivationPath == "" {
+		derivationPath = "m/44'/60'/0'/0/0"
 	}
-	delete(s.watchOnly, checksum)
-	return nil
+	adapter, err := s.multiChain.GetCurrentAdapter()
+	if err != nil {
+		return "", err
+	}
+	evmAdapter, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		return "", fmt.Errorf("当前链不支持交易替换")
+	}
+	signer, err := core.NewMnemonicSigner(mnemonic, derivationPath)
+	if err != nil {
+		return "", err
+	}
+	return s.replaceWithSigner(ctx, evmAdapter, signer, mode, nonce, opts)
 }
 
-func (s *WalletService) ListWatchOnly() []string {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	res := make([]string, 0, len(s.watchOnly))
-	for a := range s.watchOnly {
-		res = append(res, a)
+func (s *WalletService) ReplaceTransactionWithSession(ctx context.Context, sessionID, derivationPath, mode string, nonce uint64, opts *ReplaceTxOptions) (string, error) {
+	evmAdapter, err := s.currentEVMAdapter("交易替换")
+	if err != nil {
+		return "", err
+	}
+	signer, err := s.SessionSigner(sessionID, derivationPath)
+	if err != nil {
+		return "", err
 	}
-	return res
+	return s.replaceWithSigner(sendContext(ctx), evmAdapter, signer, mode, nonce, opts)
 }
 
-// -------- 基于会话的发送（免提交助记词） --------
-
-type TxReceiptDTO struct {
-	TxHash            string `json:"tx_hash"`
-	Status            uint64 `json:"status"`
-	BlockNumber       string `json:"block_number"`
-	GasUsed           string `json:"gas_used"`
-	EffectiveGasPrice string `json:"effective_gas_price,omitempty"`
-	ContractAddress   string `json:"contract_address,omitempty"`
-	TransactionIndex  uint   `json:"transaction_index"`
-	RevertReason      string `json:"revert_reason,omitempty"`
+// replaceWithSigner 按替换模式加速或取消签名者 nonce 对应的待处理交易
+func (s *WalletService) replaceWithSigner(ctx context.Context, evmAdapter *core.EVMAdapter, signer core.Signer, mode string, nonce uint64, opts *ReplaceTxOptions) (string, error) {
+	var txHash string
+	var err error
+	switch mode {
+	case ReplaceModeSpeedUp:
+		txHash, err = evmAdapter.ReplaceTransactionWithSigner(ctx, signer, nonce, s.toCoreReplaceOptions(opts))
+	case ReplaceModeCancel:
+		txHash, err = evmAdapter.CancelTransactionWithSigner(ctx, signer, nonce, s.toCoreReplaceOptions(opts))
+	default:
+		return "", fmt.Errorf("不支持的替换模式: %s", mode)
+	}
+	return s.trackSigned(signer, txHash, err)
 }
 
-func (s *WalletService) GetReceipt(txHash string) (*TxReceiptDTO, error) {
+// 高级发送 ERC20（支持 TxOptions）
+func (s *WalletService) SendERC20Advanced(ctx context.Context, mnemonic, derivationPath, token, to string, amount *big.Int, opts *TxOptions) (string, error) {
+	ctx = sendContext(ctx)
 	adapter, err := s.multiChain.GetCurrentAdapter()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	// 类型断言，检查是否为EVM适配器
 	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
-		ctx := context.Background()
-		receipt, err := evmAdapter.GetTransactionReceipt(ctx, txHash)
-		if err != nil {
-			return nil, err
+		txHash, err := evmAdapter.SendERC20WithOptions(ctx, mnemonic, derivationPath, token, to, amount, s.toCoreTxOptions(opts))
+		if err == nil {
+			s.trackPending(s.multiChain.GetCurrentNetwork(), mnemonic, derivationPath, txHash)
 		}
-		dto := &TxReceiptDTO{
-			TxHash:           txHash,
-			Status:           receipt.Status,
-			BlockNumber:      receipt.BlockNumber.String(),
-			GasUsed:          new(big.Int).SetUint64(receipt.GasUsed).String(),
-			TransactionIndex: uint(receipt.TransactionIndex),
-		}
-		if receipt.EffectiveGasPrice != nil {
-			dto.EffectiveGasPrice = receipt.EffectiveGasPrice.String()
-		}
-		if receipt.ContractAddress != (common.Address{}) {
-			// 这里无 CommonAddressZero，直接判断是否为 0 地址
-			if receipt.ContractAddress.Hex() != "0x0000000000000000000000000000000000000000" {
-				dto.ContractAddress = receipt.ContractAddress.Hex()
-			}
-		}
-		// 失败时尝试提取 revert reason
-		if receipt.Status == 0 {
-			reason, _ := evmAdapter.GetRevertReason(ctx, txHash)
-			dto.RevertReason = reason
-		}
-		return dto, nil
+		return txHash, err
 	}
 
 	// 对于非EVM链，返回错误
-	return nil, fmt.Errorf("当前链不支持交易回执查询")
+	return "", fmt.Errorf("当前链不支持高级ERC20发送")
 }
 
-func (s *WalletService) GetTokenMetadata(token string) (name, symbol string, decimals uint8, err error) {
-	adapter, err := s.multiChain.GetCurrentAdapter()
+func (s *WalletService) SendERC20AdvancedWithSession(ctx context.Context, sessionID, derivationPath, token, to string, amount *big.Int, opts *TxOptions) (string, error) {
+	evmAdapter, err := s.currentEVMAdapter("高级ERC20发送")
 	if err != nil {
-		return "", "", 0, err
+		return "", err
 	}
-
-	// 类型断言，检查是否为EVM适配器
-	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
-		ctx := context.Background()
-		return evmAdapter.GetERC20Metadata(ctx, token)
+	signer, err := s.SessionSigner(sessionID, derivationPath)
+	if err != nil {
+		return "", err
 	}
-
-	// 对于非EVM链，返回错误
-	return "", "", 0, fmt.Errorf("当前链不支持代币元数据查询")
+	txHash, err := evmAdapter.SendERC20WithSigner(sendContext(ctx), signer, token, to, amount, s.toCoreTxOptions(opts))
+	return s.trackSigned(signer, txHash, err)
 }
 
-func (s *WalletService) PersonalSign(mnemonic, derivationPath, message string) (sigHex, address string, err error) {
+// ERC20 授权 approve
+func (s *WalletService) ApproveToken(ctx context.Context, mnemonic, derivationPath, token, spender string, amount *big.Int, opts *TxOptions) (string, error) {
+	ctx = sendContext(ctx)
 	adapter, err := s.multiChain.GetCurrentAdapter()
 	if err != nil {
-		return "", "", err
+		return "", err
 	}
 
 	// 类型断言，检查是否为EVM适配器
 	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
-		ctx := context.Background()
-		return evmAdapter.PersonalSign(ctx, mnemonic, derivationPath, message)
+		return evmAdapter.Approve(ctx, mnemonic, derivationPath, token, spender, amount, s.toCoreTxOptions(opts))
 	}
 
 	// 对于非EVM链，返回错误
-	return "", "", fmt.Errorf("当前链不支持个人签名")
+	return "", fmt.Errorf("当前链不支持代币授权")
 }
 
-func (s *WalletService) SignTypedDataV4(mnemonic, derivationPath string, typedJSON []byte) (sigHex, address string, err error) {
-	adapter, err := s.multiChain.GetCurrentAdapter()
+func (s *WalletService) ApproveTokenWithSession(ctx context.Context, sessionID, derivationPath, token, spender string, amount *big.Int, opts *TxOptions) (string, error) {
+	evmAdapter, err := s.currentEVMAdapter("代币授权")
 	if err != nil {
-		return "", "", err
+		return "", err
 	}
-
-	// 类型断言，检查是否为EVM适配器
-	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
-		ctx := context.Background()
-		return evmAdapter.SignTypedDataV4(ctx, mnemonic, derivationPath, typedJSON)
+	signer, err := s.SessionSigner(sessionID, derivationPath)
+	if err != nil {
+		return "", err
 	}
+	return evmAdapter.ApproveWithSigner(sendContext(ctx), signer, token, spender, amount, s.toCoreTxOptions(opts))
+}
 
-	// 对于非EVM链，返回错误
-	return "", "", fmt.Errorf("当前链不支持EIP-712签名")
+// SignPermit 签署 EIP-2612 permit（链下授权），返回签名与可直接提交的 permit 调用数据
+// sessionID 优先，未提供时使用 mnemonic
+func (s *WalletService) SignPermit(ctx context.Context, sessionID, mnemonic, derivationPath, token, spender string, value *big.Int, deadline int64) (*core.PermitSignature, error) {
+	evmAdapter, err := s.currentEVMAdapter("EIP-2612 permit")
+	if err != nil {
+		return nil, err
+	}
+	var signer core.Signer
+	if sessionID != "" {
+		signer, err = s.SessionSigner(sessionID, derivationPath)
+	} else {
+		if derivationPath == "" {
+			derivationPath = "m/44'/60'/0'/0/0"
+		}
+		signer, err = core.NewMnemonicSigner(mnemonic, derivationPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	return evmAdapter.SignPermitWithSigner(ctx, signer, token, spender, value, deadline)
 }
 
-// TxOptions 服务层版本，避免 handler 直接依赖 core
-type TxOptions struct {
-	GasPrice *big.Int
-	TipCap   *big.Int
-	FeeCap   *big.Int
-	GasLimit uint64
-	Nonce    *uint64
+// SendBatch 使用会话或助记词按顺序发送多笔原生币/ERC20转账，nonce 连续分配，见 core.SendBatch
+// 已广播的交易登记到待确认交易跟踪器
+func (s *WalletService) SendBatch(ctx context.Context, sessionID, mnemonic, derivationPath string, transfers []core.Transfer, opts *TxOptions) ([]core.BatchTransferResult, error) {
+	ctx = sendContext(ctx)
+	evmAdapter, err := s.currentEVMAdapter("批量发送")
+	if err != nil {
+		return nil, err
+	}
+	var signer core.Signer
+	if sessionID != "" {
+		signer, err = s.SessionSigner(sessionID, derivationPath)
+	} else {
+		if derivationPath == "" {
+			derivationPath = "m/44'/60'/0'/0/0"
+		}
+		signer, err = core.NewMnemonicSigner(mnemonic, derivationPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	results, err := evmAdapter.SendBatch(ctx, signer, transfers, s.toCoreTxOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	networkID := s.multiChain.GetCurrentNetwork()
+	for _, result := range results {
+		if result.Status == core.BatchStatusSent {
+			s.pendingTxs.Register(networkID, signer.Address().Hex(), result.TxHash)
+		}
+	}
+	return results, nil
 }
 
-func (s *WalletService) toCoreTxOptions(o *TxOptions) *core.TxOptions {
-	if o == nil {
-		return nil
+// DisperseTokens 使用会话或助记词向多个接收方分发 ERC20 代币，见 core.DisperseTokens
+// 已广播的交易登记到待确认交易跟踪器
+func (s *WalletService) DisperseTokens(ctx context.Context, sessionID, mnemonic, derivationPath, token string, recipients []core.Recipient, mode string, opts *TxOptions) (*core.DisperseResult, error) {
+	ctx = sendContext(ctx)
+	evmAdapter, err := s.currentEVMAdapter("代币分发")
+	if err != nil {
+		return nil, err
 	}
-	return &core.TxOptions{
-		GasPrice: o.GasPrice,
-		TipCap:   o.TipCap,
-		FeeCap:   o.FeeCap,
-		GasLimit: o.GasLimit,
-		Nonce:    o.Nonce,
+	var signer core.Signer
+	if sessionID != "" {
+		signer, err = s.SessionSigner(sessionID, derivationPath)
+	} else {
+		if derivationPath == "" {
+			derivationPath = "m/44'/60'/0'/0/0"
+		}
+		signer, err = core.NewMnemonicSigner(mnemonic, derivationPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result, err := evmAdapter.DisperseTokens(ctx, signer, token, recipients, mode, s.toCoreTxOptions(opts))
+	if err != nil {
+		return nil, err
 	}
+	networkID := s.multiChain.GetCurrentNetwork()
+	for _, txHash := range result.Transactions {
+		s.pendingTxs.Register(networkID, signer.Address().Hex(), txHash)
+	}
+	return result, nil
 }
 
-// 高级发送 ETH（支持 TxOptions）
-func (s *WalletService) SendETHAdvanced(mnemonic, derivationPath, to string, valueWei *big.Int, opts *TxOptions) (string, error) {
+// currentEVMAdapter 获取当前网络的EVM适配器，非EVM链返回 unsupported 描述的错误
+func (s *WalletService) currentEVMAdapter(unsupported string) (*core.EVMAdapter, error) {
 	adapter, err := s.multiChain.GetCurrentAdapter()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-
-	// 类型断言，检查是否为EVM适配器
-	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
-		ctx := context.Background()
-		return evmAdapter.SendETHWithOptions(ctx, mnemonic, derivationPath, to, valueWei, s.toCoreTxOptions(opts))
+	evmAdapter, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		return nil, fmt.Errorf("当前链不支持%s", unsupported)
 	}
+	return evmAdapter, nil
+}
 
-	// 对于非EVM链，返回错误
-	return "", fmt.Errorf("当前链不支持高级ETH发送")
+// TransferERC721 转出 ERC-721 NFT（safeTransferFrom）
+func (s *WalletService) TransferERC721(ctx context.Context, mnemonic, derivationPath, contract, to string, tokenID *big.Int, opts *TxOptions) (string, error) {
+	ctx = sendContext(ctx)
+	evmAdapter, err := s.currentEVMAdapter("ERC-721转账")
+	if err != nil {
+		return "", err
+	}
+	txHash, err := evmAdapter.TransferERC721(ctx, mnemonic, derivationPath, contract, to, tokenID, s.toCoreTxOptions(opts))
+	if err == nil {
+		s.trackPending(s.multiChain.GetCurrentNetwork(), mnemonic, derivationPath, txHash)
+	}
+	return txHash, err
 }
 
-func (s *WalletService) SendETHAdvancedWithSession(sessionID, derivationPath, to string, valueWei *big.Int, opts *TxOptions) (string, error) {
-	if derivationPath == "" {
-		derivationPath = "m/44'/60'/0'/0/0"
+func (s *WalletService) TransferERC721WithSession(ctx context.Context, sessionID, derivationPath, contract, to string, tokenID *big.Int, opts *TxOptions) (string, error) {
+	evmAdapter, err := s.currentEVMAdapter("ERC-721转账")
+	if err != nil {
+		return "", err
 	}
-	mn, err := s.getSessionMnemonic(sessionID)
+	signer, err := s.SessionSigner(sessionID, derivationPath)
 	if err != nil {
 		return "", err
 	}
-	return s.SendETHAdvanced(mn, derivationPath, to, valueWei, opts)
+	txHash, err := evmAdapter.TransferERC721WithSigner(sendContext(ctx), signer, contract, to, tokenID, s.toCoreTxOptions(opts))
+	return s.trackSigned(signer, txHash, err)
 }
 
-// 高级发送 ERC20（支持 TxOptions）
-func (s *WalletService) SendERC20Advanced(mnemonic, derivationPath, token, to string, amount *big.Int, opts *TxOptions) (string, error) {
-	adapter, err := s.multiChain.GetCurrentAdapter()
+// TransferERC1155 转出指定数量的 ERC-1155 代币（safeTransferFrom）
+func (s *WalletService) TransferERC1155(ctx context.Context, mnemonic, derivationPath, contract, to string, tokenID, amount *big.Int, opts *TxOptions) (string, error) {
+	ctx = sendContext(ctx)
+	evmAdapter, err := s.currentEVMAdapter("ERC-1155转账")
 	if err != nil {
 		return "", err
 	}
-
-	// 类型断言，检查是否为EVM适配器
-	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
-		ctx := context.Background()
-		return evmAdapter.SendERC20WithOptions(ctx, mnemonic, derivationPath, token, to, amount, s.toCoreTxOptions(opts))
+	txHash, err := evmAdapter.TransferERC1155(ctx, mnemonic, derivationPath, contract, to, tokenID, amount, s.toCoreTxOptions(opts))
+	if err == nil {
+		s.trackPending(s.multiChain.GetCurrentNetwork(), mnemonic, derivationPath, txHash)
 	}
-
-	// 对于非EVM链，返回错误
-	return "", fmt.Errorf("当前链不支持高级ERC20发送")
+	return txHash, err
 }
 
-func (s *WalletService) SendERC20AdvancedWithSession(sessionID, derivationPath, token, to string, amount *big.Int, opts *TxOptions) (string, error) {
-	if derivationPath == "" {
-		derivationPath = "m/44'/60'/0'/0/0"
+func (s *WalletService) TransferERC1155WithSession(ctx context.Context, sessionID, derivationPath, contract, to string, tokenID, amount *big.Int, opts *TxOptions) (string, error) {
+	evmAdapter, err := s.currentEVMAdapter("ERC-1155转账")
+	if err != nil {
+		return "", err
 	}
-	mn, err := s.getSessionMnemonic(sessionID)
+	signer, err := s.SessionSigner(sessionID, derivationPath)
 	if err != nil {
 		return "", err
 	}
-	return s.SendERC20Advanced(mn, derivationPath, token, to, amount, opts)
+	txHash, err := evmAdapter.TransferERC1155WithSigner(sendContext(ctx), signer, contract, to, tokenID, amount, s.toCoreTxOptions(opts))
+	return s.trackSigned(signer, txHash, err)
 }
 
-// ERC20 授权 approve
-func (s *WalletService) ApproveToken(mnemonic, derivationPath, token, spender string, amount *big.Int, opts *TxOptions) (string, error) {
-	adapter, err := s.multiChain.GetCurrentAdapter()
+// GetERC721Owner 查询 ERC-721 代币持有者
+func (s *WalletService) GetERC721Owner(ctx context.Context, contract string, tokenID *big.Int) (string, error) {
+	evmAdapter, err := s.currentEVMAdapter("ERC-721查询")
 	if err != nil {
 		return "", err
 	}
-
-	// 类型断言，检查是否为EVM适配器
-	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
-		ctx := context.Background()
-		return evmAdapter.Approve(ctx, mnemonic, derivationPath, token, spender, amount, s.toCoreTxOptions(opts))
-	}
-
-	// 对于非EVM链，返回错误
-	return "", fmt.Errorf("当前链不支持代币授权")
+	return evmAdapter.GetERC721Owner(ctx, contract, tokenID)
 }
 
-func (s *WalletService) ApproveTokenWithSession(sessionID, derivationPath, token, spender string, amount *big.Int, opts *TxOptions) (string, error) {
-	if derivationPath == "" {
-		derivationPath = "m/44'/60'/0'/0/0"
-	}
-	mn, err := s.getSessionMnemonic(sessionID)
+// GetERC1155Balance 查询 ERC-1155 代币持有数量
+func (s *WalletService) GetERC1155Balance(ctx context.Context, contract, owner string, tokenID *big.Int) (*big.Int, error) {
+	evmAdapter, err := s.currentEVMAdapter("ERC-1155查询")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return s.ApproveToken(mn, derivationPath, token, spender, amount, opts)
+	return evmAdapter.GetERC1155Balance(ctx, contract, owner, tokenID)
 }
 
 // ERC20 allowance 读取
-func (s *WalletService) GetAllowance(token, owner, spender string) (*big.Int, error) {
+func (s *WalletService) GetAllowance(ctx context.Context, token, owner, spender string) (*big.Int, error) {
 	adapter, err := s.multiChain.GetCurrentAdapter()
 	if err != nil {
 		return nil, err
@@ -769,7 +1991,6 @@ func (s *WalletService) GetAllowance(token, owner, spender string) (*big.Int, er
 
 	// 类型断言，检查是否为EVM适配器
 	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
-		ctx := context.Background()
 		return evmAdapter.GetAllowance(ctx, token, owner, spender)
 	}
 
@@ -778,7 +1999,8 @@ func (s *WalletService) GetAllowance(token, owner, spender string) (*big.Int, er
 }
 
 // GetTransactionHistory 获取交易历史
-func (s *WalletService) GetTransactionHistory(req *core.TransactionHistoryRequest) (*core.TransactionHistoryResponse, error) {
+// ctx 中可携带用户的第三方服务密钥（区块浏览器查询使用 etherscan 密钥）
+func (s *WalletService) GetTransactionHistory(ctx context.Context, req *core.TransactionHistoryRequest) (*core.TransactionHistoryResponse, error) {
 	// 验证地址格式
 	if !common.IsHexAddress(req.Address) {
 		return nil, fmt.Errorf("无效的地址格式: %s", req.Address)
@@ -793,7 +2015,6 @@ func (s *WalletService) GetTransactionHistory(req *core.TransactionHistoryReques
 	// 类型断言，检查是否为EVM适配器
 	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
 		// 查询交易历史
-		ctx := context.Background()
 		transactions, err := evmAdapter.GetTransactionHistory(ctx, req)
 		if err != nil {
 			return nil, fmt.Errorf("获取交易历史失败: %w", err)
@@ -828,6 +2049,35 @@ func (s *WalletService) GetTransactionHistory(req *core.TransactionHistoryReques
 	return nil, fmt.Errorf("当前链不支持交易历史查询")
 }
 
+// ExportTransactionHistory 流式导出交易历史
+// 按区块顺序扫描 req 指定的范围，每发现一笔交易即回调 emit，不在内存中累积结果
+// ctx 取消（如客户端断开连接）时扫描立即停止
+func (s *WalletService) ExportTransactionHistory(ctx context.Context, req *core.TransactionHistoryRequest, emit func(core.TransactionInfo) error) error {
+	if !common.IsHexAddress(req.Address) {
+		return fmt.Errorf("无效的地址格式: %s", req.Address)
+	}
+
+	adapter, err := s.multiChain.GetCurrentAdapter()
+	if err != nil {
+		return fmt.Errorf("获取链适配器失败: %w", err)
+	}
+
+	evmAdapter, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		return fmt.Errorf("当前链不支持交易历史导出")
+	}
+
+	startBlock, endBlock, err := evmAdapter.ResolveHistoryRange(ctx, req.StartBlock, req.EndBlock)
+	if err != nil {
+		return err
+	}
+	txType := req.TxType
+	if txType == "" {
+		txType = "all"
+	}
+	return evmAdapter.StreamTransactionsInRange(ctx, req.Address, startBlock, endBlock, txType, emit)
+}
+
 // getTransactionCount 获取地址的总交易数
 func (s *WalletService) getTransactionCount(adapter *core.EVMAdapter, ctx context.Context, address string) (int, error) {
 	// 这里实现获取总交易数的逻辑
@@ -887,17 +2137,17 @@ func (s *WalletService) GetCrossChainTokenBalance(address, tokenAddress string,
 }
 
 // GetBalanceOnNetwork 获取指定网络上的余额
-func (s *WalletService) GetBalanceOnNetwork(address, networkID string) (*big.Int, error) {
+func (s *WalletService) GetBalanceOnNetwork(ctx context.Context, address, networkID string) (*big.Int, error) {
 	adapter, err := s.multiChain.GetAdapter(networkID)
 	if err != nil {
 		return nil, err
 	}
-	ctx := context.Background()
 	return adapter.GetBalance(ctx, address)
 }
 
 // SendETHOnNetwork 在指定网络上发送ETH
-func (s *WalletService) SendETHOnNetwork(networkID, mnemonic, derivationPath, to string, valueWei *big.Int) (string, error) {
+func (s *WalletService) SendETHOnNetwork(ctx context.Context, networkID, mnemonic, derivationPath, to string, valueWei *big.Int) (string, error) {
+	ctx = sendContext(ctx)
 	adapter, err := s.multiChain.GetAdapter(networkID)
 	if err != nil {
 		return "", err
@@ -908,8 +2158,11 @@ func (s *WalletService) SendETHOnNetwork(networkID, mnemonic, derivationPath, to
 
 	// 类型断言，检查是否为EVM适配器
 	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
-		ctx := context.Background()
-		return evmAdapter.SendETH(ctx, mnemonic, derivationPath, to, valueWei)
+		txHash, err := evmAdapter.SendETH(ctx, mnemonic, derivationPath, to, valueWei)
+		if err == nil {
+			s.trackPending(networkID, mnemonic, derivationPath, txHash)
+		}
+		return txHash, err
 	}
 
 	// 对于非EVM链，使用通用的SendTransaction方法
@@ -918,12 +2171,39 @@ func (s *WalletService) SendETHOnNetwork(networkID, mnemonic, derivationPath, to
 		return "", err
 	}
 
-	ctx := context.Background()
 	return adapter.SendTransaction(ctx, fromAddr, to, valueWei, mnemonic)
 }
 
+// SendETHOnNetworkWithSession 使用会话在指定网络上发送ETH
+// EVM网络使用会话签名者发送，助记词会话与 Keystore 会话均可用；非EVM网络需要助记词会话
+func (s *WalletService) SendETHOnNetworkWithSession(ctx context.Context, networkID, sessionID, derivationPath, to string, valueWei *big.Int) (string, error) {
+	adapter, err := s.multiChain.GetAdapter(networkID)
+	if err != nil {
+		return "", err
+	}
+	evmAdapter, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		mnemonic, err := s.getSessionMnemonic(sessionID)
+		if err != nil {
+			return "", fmt.Errorf("无效会话: %w", err)
+		}
+		return s.SendETHOnNetwork(ctx, networkID, mnemonic, derivationPath, to, valueWei)
+	}
+
+	signer, err := s.SessionSigner(sessionID, derivationPath)
+	if err != nil {
+		return "", fmt.Errorf("无效会话: %w", err)
+	}
+	txHash, err := evmAdapter.SendETHWithSigner(sendContext(ctx), signer, to, valueWei, nil)
+	if err == nil {
+		s.pendingTxs.Register(networkID, signer.Address().Hex(), txHash)
+	}
+	return txHash, err
+}
+
 // SendERC20OnNetwork 在指定网络上发送ERC20
-func (s *WalletService) SendERC20OnNetwork(networkID, mnemonic, derivationPath, token, to string, amount *big.Int) (string, error) {
+func (s *WalletService) SendERC20OnNetwork(ctx context.Context, networkID, mnemonic, derivationPath, token, to string, amount *big.Int) (string, error) {
+	ctx = sendContext(ctx)
 	adapter, err := s.multiChain.GetAdapter(networkID)
 	if err != nil {
 		return "", err
@@ -934,8 +2214,11 @@ func (s *WalletService) SendERC20OnNetwork(networkID, mnemonic, derivationPath,
 
 	// 类型断言，检查是否为EVM适配器
 	if evmAdapter, ok := adapter.(*core.EVMAdapter); ok {
-		ctx := context.Background()
-		return evmAdapter.SendERC20(ctx, mnemonic, derivationPath, token, to, amount)
+		txHash, err := evmAdapter.SendERC20(ctx, mnemonic, derivationPath, token, to, amount)
+		if err == nil {
+			s.trackPending(networkID, mnemonic, derivationPath, txHash)
+		}
+		return txHash, err
 	}
 
 	// 对于非EVM链，使用通用的SendTokenTransaction方法
@@ -944,7 +2227,6 @@ func (s *WalletService) SendERC20OnNetwork(networkID, mnemonic, derivationPath,
 		return "", err
 	}
 
-	ctx := context.Background()
 	return adapter.(core.TokenSupporter).SendTokenTransaction(ctx, fromAddr, to, token, amount, mnemonic)
 }
 
@@ -1132,7 +2414,7 @@ func (s *WalletService) DeleteEncryptedWallet(walletID, password string) error {
 }
 
 // SendETHWithEncryptedWallet 使用加密钱包发送ETH
-func (s *WalletService) SendETHWithEncryptedWallet(walletID, password, derivationPath, to string, valueWei *big.Int) (string, error) {
+func (s *WalletService) SendETHWithEncryptedWallet(ctx context.Context, walletID, password, derivationPath, to string, valueWei *big.Int) (string, error) {
 	// 解锁钱包
 	mnemonic, err := s.UnlockWallet(walletID, password)
 	if err != nil {
@@ -1140,11 +2422,11 @@ func (s *WalletService) SendETHWithEncryptedWallet(walletID, password, derivatio
 	}
 
 	// 发送交易
-	return s.SendETH(mnemonic, derivationPath, to, valueWei)
+	return s.SendETH(ctx, mnemonic, derivationPath, to, valueWei)
 }
 
 // SendERC20WithEncryptedWallet 使用加密钱包发送ERC20
-func (s *WalletService) SendERC20WithEncryptedWallet(walletID, password, derivationPath, token, to string, amount *big.Int) (string, error) {
+func (s *WalletService) SendERC20WithEncryptedWallet(ctx context.Context, walletID, password, derivationPath, token, to string, amount *big.Int) (string, error) {
 	// 解锁钱包
 	mnemonic, err := s.UnlockWallet(walletID, password)
 	if err != nil {
@@ -1152,12 +2434,26 @@ func (s *WalletService) SendERC20WithEncryptedWallet(walletID, password, derivat
 	}
 
 	// 发送交易
-	return s.SendERC20(mnemonic, derivationPath, token, to, amount)
+	return s.SendERC20(ctx, mnemonic, derivationPath, token, to, amount)
+}
+
+// ValidateMnemonic 校验助记词（单词数量、是否在词表中、校验和），结果用于界面逐词提示
+func (s *WalletService) ValidateMnemonic(mnemonic string) *core.MnemonicValidation {
+	result, _ := core.ValidateMnemonic(mnemonic)
+	return result
 }
 
 // CreateNewWallet 生成新的助记词和地址
-func (s *WalletService) CreateNewWallet() (mnemonic, address string, err error) {
-	mnemonic, err = core.GenerateMnemonic(128)
+// wordCount 为助记词单词数量（12/15/18/21/24），0 时为 12；其他值返回 core.ErrMnemonicWordCount
+func (s *WalletService) CreateNewWallet(wordCount int) (mnemonic, address string, err error) {
+	if wordCount == 0 {
+		wordCount = 12
+	}
+	strength, err := core.MnemonicStrength(wordCount)
+	if err != nil {
+		return "", "", err
+	}
+	mnemonic, err = core.GenerateMnemonic(strength)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to generate mnemonic: %w", err)
 	}
@@ -1205,7 +2501,112 @@ func (s *WalletService) GetNFTMarketplaceService() *NFTMarketplaceService {
 	return s.nftMarketplaceService
 }
 
+// GetPriceService 获取代币价格服务实例
+func (s *WalletService) GetPriceService() *PriceService {
+	return s.priceService
+}
+
+// GetAddressSummaryService 获取地址活跃度摘要服务实例
+func (s *WalletService) GetAddressSummaryService() *AddressSummaryService {
+	return s.addressSummary
+}
+
+// GetIdempotencyService 获取发送类请求的幂等键存储
+func (s *WalletService) GetIdempotencyService() *IdempotencyService {
+	return s.idempotency
+}
+
+// GetPortfolioService 获取跨链资产汇总服务实例
+func (s *WalletService) GetPortfolioService() *PortfolioService {
+	return s.portfolioService
+}
+
+// GetWalletConnectService 获取 WalletConnect 会话服务
+func (s *WalletService) GetWalletConnectService() *WalletConnectService {
+	return s.walletConnect
+}
+
+// GetHealthService 获取就绪检查服务
+func (s *WalletService) GetHealthService() *HealthService {
+	return s.health
+}
+
+// GetMetricsRegistry 获取 /metrics 暴露的指标注册表
+func (s *WalletService) GetMetricsRegistry() *prometheus.Registry {
+	return s.metricsRegistry
+}
+
+// GetNotificationService 获取通知服务
+func (s *WalletService) GetNotificationService() *NotificationService {
+	return s.notificationService
+}
+
+// GetRelayService 获取代付Gas服务
+func (s *WalletService) GetRelayService() *RelayService {
+	return s.relayService
+}
+
+// GetRealtimeService 获取实时推送服务
+func (s *WalletService) GetRealtimeService() *RealtimeService {
+	return s.realtimeService
+}
+
+// GetBridgeService 获取跨链桥接服务实例
+func (s *WalletService) GetBridgeService() *BridgeService {
+	return s.bridgeService
+}
+
+// GetProviderKeyService 获取第三方服务密钥服务
+func (s *WalletService) GetProviderKeyService() *ProviderKeyService {
+	return s.providerKeyService
+}
+
 // IsValidAddress 验证地址格式
 func (s *WalletService) IsValidAddress(address string) bool {
 	return common.IsHexAddress(address)
 }
+
+// AddressValidation 地址校验结果
+type AddressValidation struct {
+	Address          string `json:"address"`                        // 原始输入
+	Valid            bool   `json:"valid"`                          // 是否为合法的20字节十六进制地址
+	Checksummed      string `json:"checksummed,omitempty"`          // EIP-55 校验和格式
+	HasChecksum      bool   `json:"has_checksum"`                   // 输入是否为大小写混合（携带校验和）
+	IsChecksumValid  bool   `json:"is_checksum_valid"`              // 输入与校验和格式完全一致
+	IsContract       bool   `json:"is_contract"`                    // 当前网络上是否为合约地址
+	ContractCheckErr string `json:"contract_check_error,omitempty"` // 合约检测失败原因（节点不可用等）
+}
+
+// ValidateAddress 校验地址格式与 EIP-55 校验和，并检测当前网络上是否为合约
+// 全小写或全大写地址不含校验和，HasChecksum 为 false；大小写混合但与校验和不一致通常意味着输入错误
+func (s *WalletService) ValidateAddress(ctx context.Context, address string) *AddressValidation {
+	address = strings.TrimSpace(address)
+	result := &AddressValidation{Address: address}
+	if !common.IsHexAddress(address) {
+		return result
+	}
+	result.Valid = true
+	result.Checksummed = common.HexToAddress(address).Hex()
+
+	body := strings.TrimPrefix(strings.TrimPrefix(address, "0x"), "0X")
+	result.HasChecksum = body != strings.ToLower(body) && body != strings.ToUpper(body)
+	result.IsChecksumValid = address == result.Checksummed
+
+	adapter, err := s.multiChain.GetCurrentAdapter()
+	if err != nil {
+		result.ContractCheckErr = err.Error()
+		return result
+	}
+	evmAdapter, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		result.ContractCheckErr = "当前网络不支持合约检测"
+		return result
+	}
+	code, err := evmAdapter.GetCode(ctx, result.Checksummed)
+	if err != nil {
+		result.ContractCheckErr = err.Error()
+		return result
+	}
+	result.IsContract = len(code) > 0
+	return result
+}
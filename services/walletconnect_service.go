@@ -0,0 +1,468 @@
+/*
+WalletConnect v2 会话服务
+
+将 WalletConnect 会话接入 DApp 浏览器的请求处理与确认流程：
+  - 配对时以当前会话钱包响应提议：批准 DApp 请求的、本服务已启用的 EVM 链，账户为会话钱包地址
+  - 会话建立后创建对应的 DApp 会话（经过钓鱼域名检查），并授权会话批准的方法直到会话过期
+  - session_request 按请求携带的链交给 ProcessWeb3Request；需要确认的请求进入待确认列表，
+    用户确认或拒绝后（WalletConnect 接口或 /dapp/web3/confirm 均可）将结果回复给 DApp
+  - 待确认请求超时（DApp 指定的过期时间或5分钟）后回复过期错误；会话过期或断开时丢弃其待确认请求
+*/
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"wallet/config"
+	"wallet/core"
+)
+
+// walletConnectSweepInterval 待确认请求的过期检查间隔
+const walletConnectSweepInterval = 30 * time.Second
+
+// ErrWalletConnectDisabled 未配置 WalletConnect 项目ID
+var ErrWalletConnectDisabled = errors.New("未启用 WalletConnect（未配置 walletconnect.project_id）")
+
+// ErrWalletConnectRequestNotFound 请求不存在、已处理或不属于当前钱包
+var ErrWalletConnectRequestNotFound = errors.New("WalletConnect 请求不存在或已处理")
+
+// walletConnectMethods 可通过 WalletConnect 调用的方法（与 DApp 浏览器支持的方法一致）
+var walletConnectMethods = map[string]bool{
+	"eth_sendTransaction":  true,
+	"personal_sign":        true,
+	"eth_signTypedData_v4": true,
+	"eth_accounts":         true,
+	"eth_requestAccounts":  true,
+	"eth_chainId":          true,
+}
+
+// walletConnectEvents 钱包可发送的事件
+var walletConnectEvents = map[string]bool{
+	"chainChanged":    true,
+	"accountsChanged": true,
+}
+
+// WalletConnectRequest 等待用户确认的 WalletConnect 请求
+type WalletConnectRequest struct {
+	RequestID  string          `json:"request_id"` // 确认或拒绝时使用的请求ID
+	Topic      string          `json:"topic"`      // WalletConnect 会话主题
+	Owner      string          `json:"owner"`      // 会话钱包地址
+	Peer       core.WCMetadata `json:"peer"`       // 发起请求的 DApp
+	Method     string          `json:"method"`
+	ChainID    string          `json:"chain_id"` // CAIP-2 链ID
+	Params     []interface{}   `json:"params"`
+	UserPrompt string          `json:"user_prompt"`
+	RiskLevel  string          `json:"risk_level"`
+	ReceivedAt time.Time       `json:"received_at"`
+	ExpiresAt  time.Time       `json:"expires_at"`
+
+	rpcID int64 // WalletConnect 请求ID，回复时使用
+}
+
+// WalletConnectService WalletConnect v2 会话服务
+type WalletConnectService struct {
+	manager    *core.WalletConnectManager // 未配置项目ID时为 nil
+	dapp       *DAppBrowserService
+	multiChain *core.MultiChainManager
+
+	mu       sync.Mutex
+	sessions map[string]string                // WalletConnect 会话主题 -> DApp 会话ID
+	requests map[string]*WalletConnectRequest // DApp 请求ID -> 待确认请求
+}
+
+// NewWalletConnectService 创建 WalletConnect 会话服务，未配置项目ID时所有操作返回 ErrWalletConnectDisabled
+func NewWalletConnectService(cfg config.WalletConnectConfig, multiChain *core.MultiChainManager, dapp *DAppBrowserService) *WalletConnectService {
+	service := &WalletConnectService{
+		dapp:       dapp,
+		multiChain: multiChain,
+		sessions:   make(map[string]string),
+		requests:   make(map[string]*WalletConnectRequest),
+	}
+	cfg = cfg.WithDefaults()
+	if cfg.ProjectID == "" {
+		return service
+	}
+
+	metadata := core.WCMetadata{Name: cfg.Name, Description: cfg.Description, URL: cfg.URL, Icons: cfg.Icons}
+	manager, err := core.NewWalletConnectManager(cfg.RelayURL, cfg.ProjectID, metadata, time.Duration(cfg.SessionExpiryHours)*time.Hour, core.WCHandlers{
+		OnProposal: service.onProposal,
+		OnSession:  service.onSession,
+		OnRequest:  service.onRequest,
+		OnClose:    service.onClose,
+	})
+	if err != nil {
+		slog.WarnContext(context.Background(), "walletconnect init failed", "error", err)
+		return service
+	}
+	service.manager = manager
+	dapp.SetRequestResolvedHook(service.onRequestResolved)
+	go service.sweepLoop()
+	return service
+}
+
+// Pair 使用 DApp 展示的 wc: URI 配对，以 owner 钱包响应会话提议
+func (s *WalletConnectService) Pair(ctx context.Context, uri, owner string) (*core.WCSession, error) {
+	if s.manager == nil {
+		return nil, ErrWalletConnectDisabled
+	}
+	return s.manager.Pair(ctx, uri, owner)
+}
+
+// ListSessions 列出钱包的有效会话
+func (s *WalletConnectService) ListSessions(owner string) ([]*core.WCSession, error) {
+	if s.manager == nil {
+		return nil, ErrWalletConnectDisabled
+	}
+	return s.manager.Sessions(owner), nil
+}
+
+// Disconnect 断开钱包的会话
+func (s *WalletConnectService) Disconnect(ctx context.Context, owner, topic string) error {
+	if s.manager == nil {
+		return ErrWalletConnectDisabled
+	}
+	session, ok := s.manager.Session(topic)
+	if !ok || !strings.EqualFold(session.Owner, owner) {
+		return fmt.Errorf("WalletConnect 会话不存在或已断开")
+	}
+	return s.manager.Disconnect(ctx, topic)
+}
+
+// ListRequests 列出钱包待确认的请求，按接收时间排序
+func (s *WalletConnectService) ListRequests(owner string) []*WalletConnectRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*WalletConnectRequest, 0)
+	for _, request := range s.requests {
+		if strings.EqualFold(request.Owner, owner) {
+			copied := *request
+			out = append(out, &copied)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ReceivedAt.Before(out[j].ReceivedAt) })
+	return out
+}
+
+// ResolveRequest 确认或拒绝钱包的待确认请求，确认时使用钱包会话派生的签名者执行，结果回复给 DApp
+func (s *WalletConnectService) ResolveRequest(ctx context.Context, walletSessionID, owner, requestID, derivationPath string, approved bool) (*core.Web3Request, error) {
+	s.mu.Lock()
+	request, ok := s.requests[requestID]
+	s.mu.Unlock()
+	if !ok || !strings.EqualFold(request.Owner, owner) {
+		return nil, ErrWalletConnectRequestNotFound
+	}
+	return s.dapp.ConfirmWeb3Request(ctx, walletSessionID, derivationPath, requestID, approved)
+}
+
+// PendingTransaction 当前钱包待确认的 eth_sendTransaction 请求的网络与资金流向，其他方法返回 nil
+func (s *WalletConnectService) PendingTransaction(owner, requestID string) (*core.PendingTransfer, error) {
+	s.mu.Lock()
+	request, ok := s.requests[requestID]
+	s.mu.Unlock()
+	if !ok || !strings.EqualFold(request.Owner, owner) {
+		return nil, ErrWalletConnectRequestNotFound
+	}
+	return s.dapp.PendingTransaction(requestID)
+}
+
+// onProposal 按 DApp 请求的命名空间与已启用的 EVM 链生成批准的命名空间
+// DApp 要求的方法即使不支持也一并批准（否则 DApp 无法建立会话），调用时返回不支持的方法错误
+func (s *WalletConnectService) onProposal(owner string, proposal *core.WCProposal) (map[string]core.WCNamespace, error) {
+	supported := s.supportedChains()
+	chains := make(map[string]bool)
+	methods := make(map[string]bool)
+	events := make(map[string]bool)
+
+	for key, namespace := range proposal.RequiredNamespaces {
+		name, chain, _ := strings.Cut(key, ":")
+		if name != "eip155" {
+			return nil, &core.WCError{Code: core.WCErrUnsupportedNamespace, Message: "Unsupported namespace key: " + key}
+		}
+		required := namespace.Chains
+		if chain != "" {
+			required = []string{key}
+		}
+		for _, c := range required {
+			if !supported[c] {
+				return nil, &core.WCError{Code: core.WCErrUnsupportedChains, Message: "Unsupported chain: " + c}
+			}
+			chains[c] = true
+		}
+		for _, method := range namespace.Methods {
+			methods[method] = true
+		}
+		for _, event := range namespace.Events {
+			events[event] = true
+		}
+	}
+	for key, namespace := range proposal.OptionalNamespaces {
+		name, chain, _ := strings.Cut(key, ":")
+		if name != "eip155" {
+			continue
+		}
+		optional := namespace.Chains
+		if chain != "" {
+			optional = []string{key}
+		}
+		for _, c := range optional {
+			if supported[c] {
+				chains[c] = true
+			}
+		}
+		for _, method := range namespace.Methods {
+			if walletConnectMethods[method] {
+				methods[method] = true
+			}
+		}
+		for _, event := range namespace.Events {
+			if walletConnectEvents[event] {
+				events[event] = true
+			}
+		}
+	}
+	if len(chains) == 0 {
+		return nil, &core.WCError{Code: core.WCErrUnsupportedChains, Message: "No supported EVM chains requested"}
+	}
+
+	namespace := core.WCNamespace{
+		Chains:   sortedKeys(chains),
+		Methods:  sortedKeys(methods),
+		Events:   sortedKeys(events),
+		Accounts: make([]string, 0, len(chains)),
+	}
+	for _, chain := range namespace.Chains {
+		namespace.Accounts = append(namespace.Accounts, chain+":"+owner)
+	}
+	return map[string]core.WCNamespace{"eip155": namespace}, nil
+}
+
+// onSession 为会话创建 DApp 会话并授权批准的方法，高风险域名（core.SecurityBlockedError）拒绝提议
+func (s *WalletConnectService) onSession(session *core.WCSession) error {
+	namespace := session.Namespaces["eip155"]
+	chainID, err := caipChainHex(namespace.Chains[0])
+	if err != nil {
+		return err
+	}
+	grants := []string{"eth_accounts"}
+	for _, method := range namespace.Methods {
+		if core.DAppGrantableMethods[method] && walletConnectMethods[method] {
+			grants = append(grants, method)
+		}
+	}
+	dappSession, err := s.dapp.OpenExternalSession(context.Background(), session.Peer.URL, session.Owner, chainID, grants, session.Expiry)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.sessions[session.Topic] = dappSession.ID
+	s.mu.Unlock()
+	return nil
+}
+
+// onRequest 将会话请求交给 DApp 浏览器处理，需要确认的请求进入待确认列表
+func (s *WalletConnectService) onRequest(session *core.WCSession, request *core.WCSessionRequest) {
+	ctx := context.Background()
+	respondError := func(code int, message string) {
+		if err := s.manager.Respond(ctx, session.Topic, request.ID, nil, &core.WCError{Code: code, Message: message}); err != nil {
+			slog.WarnContext(ctx, "walletconnect response failed", "topic", session.Topic, "error", err)
+		}
+	}
+	if !walletConnectMethods[request.Method] {
+		respondError(core.WCErrUnsupportedMethods, "Unsupported method: "+request.Method)
+		return
+	}
+	chainID, err := caipChainHex(request.ChainID)
+	if err != nil {
+		respondError(core.WCErrUnsupportedChains, err.Error())
+		return
+	}
+	params, err := walletConnectParams(request.Params)
+	if err != nil {
+		respondError(-32602, err.Error())
+		return
+	}
+
+	// 持有锁直到登记完成，避免请求在登记前被确认而丢失回复
+	s.mu.Lock()
+	dappSessionID, ok := s.sessions[session.Topic]
+	if !ok {
+		s.mu.Unlock()
+		respondError(core.WCErrUserDisconnected, "Session not found")
+		return
+	}
+	response, err := s.dapp.ProcessWeb3Request(ctx, &Web3RequestData{
+		SessionID: dappSessionID,
+		Method:    request.Method,
+		Params:    params,
+		Origin:    session.Peer.URL,
+		ChainID:   chainID,
+	})
+	var pending *PendingRequest
+	if err == nil {
+		pending, ok = s.dapp.PendingRequest(response.RequestID)
+	}
+	if err == nil && ok {
+		expiresAt := pending.ExpiresAt
+		if request.ExpiresAt != nil && request.ExpiresAt.Before(expiresAt) {
+			expiresAt = *request.ExpiresAt
+		}
+		s.requests[response.RequestID] = &WalletConnectRequest{
+			RequestID:  response.RequestID,
+			Topic:      session.Topic,
+			Owner:      session.Owner,
+			Peer:       session.Peer,
+			Method:     request.Method,
+			ChainID:    request.ChainID,
+			Params:     params,
+			UserPrompt: response.UserPrompt,
+			RiskLevel:  response.RiskLevel,
+			ReceivedAt: request.ReceivedAt,
+			ExpiresAt:  expiresAt,
+			rpcID:      request.ID,
+		}
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+
+	switch {
+	case err != nil:
+		respondError(-32603, err.Error())
+	case response.Error != nil:
+		respondError(response.Error.Code, response.Error.Message)
+	default:
+		if err := s.manager.Respond(ctx, session.Topic, request.ID, response.Result, nil); err != nil {
+			slog.WarnContext(ctx, "walletconnect response failed", "topic", session.Topic, "error", err)
+		}
+	}
+}
+
+// onRequestResolved 用户确认或拒绝后将结果回复给 DApp
+func (s *WalletConnectService) onRequestResolved(sessionID string, result *core.Web3Request) {
+	s.mu.Lock()
+	request, ok := s.requests[result.ID]
+	delete(s.requests, result.ID)
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	var rpcErr *core.WCError
+	switch {
+	case result.Error != nil:
+		rpcErr = &core.WCError{Code: result.Error.Code, Message: result.Error.Message}
+	case result.Status != "completed":
+		rpcErr = &core.WCError{Code: 4001, Message: "用户拒绝了请求"}
+	}
+	if err := s.manager.Respond(context.Background(), request.Topic, request.rpcID, result.Response, rpcErr); err != nil {
+		slog.WarnContext(context.Background(), "walletconnect response failed", "topic", request.Topic, "error", err)
+	}
+}
+
+// onClose 会话关闭时关闭对应的 DApp 会话并丢弃待确认请求
+func (s *WalletConnectService) onClose(session *core.WCSession, reason string) {
+	s.mu.Lock()
+	dappSessionID, ok := s.sessions[session.Topic]
+	delete(s.sessions, session.Topic)
+	for id, request := range s.requests {
+		if request.Topic == session.Topic {
+			delete(s.requests, id)
+		}
+	}
+	s.mu.Unlock()
+	if ok {
+		s.dapp.CloseSession(dappSessionID)
+	}
+	slog.InfoContext(context.Background(), "walletconnect session closed", "topic", session.Topic, "peer", session.Peer.Name, "reason", reason)
+}
+
+// sweepLoop 定期回复已过期的待确认请求
+func (s *WalletConnectService) sweepLoop() {
+	ticker := time.NewTicker(walletConnectSweepInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		now := time.Now()
+		var expired []*WalletConnectRequest
+		s.mu.Lock()
+		for id, request := range s.requests {
+			if now.After(request.ExpiresAt) {
+				expired = append(expired, request)
+				delete(s.requests, id)
+			}
+		}
+		s.mu.Unlock()
+
+		for _, request := range expired {
+			s.dapp.DiscardRequest(request.RequestID)
+			rpcErr := &core.WCError{Code: core.WCErrExpired, Message: "Request expired"}
+			if err := s.manager.Respond(context.Background(), request.Topic, request.rpcID, nil, rpcErr); err != nil {
+				slog.WarnContext(context.Background(), "walletconnect expiry response failed", "topic", request.Topic, "error", err)
+			}
+		}
+	}
+}
+
+// supportedChains 已启用的 EVM 网络对应的 CAIP-2 链ID
+func (s *WalletConnectService) supportedChains() map[string]bool {
+	chains := make(map[string]bool)
+	for networkID, network := range config.AppConfig.Networks {
+		if !network.Enabled || network.ChainID <= 0 {
+			continue
+		}
+		adapter, err := s.multiChain.GetAdapter(networkID)
+		if err != nil {
+			continue
+		}
+		if _, ok := adapter.(*core.EVMAdapter); ok {
+			chains["eip155:"+strconv.FormatInt(network.ChainID, 10)] = true
+		}
+	}
+	return chains
+}
+
+// caipChainHex 将 CAIP-2 链ID（eip155:137）转换为十六进制链ID（0x89）
+func caipChainHex(chain string) (string, error) {
+	name, reference, ok := strings.Cut(chain, ":")
+	if !ok || name != "eip155" {
+		return "", fmt.Errorf("不支持的链: %s", chain)
+	}
+	chainID, err := strconv.ParseInt(reference, 10, 64)
+	if err != nil || chainID <= 0 {
+		return "", fmt.Errorf("无效的链ID: %s", chain)
+	}
+	return "0x" + strconv.FormatInt(chainID, 16), nil
+}
+
+// walletConnectParams 解析请求参数，对象形式的参数按单个参数处理
+func walletConnectParams(raw json.RawMessage) ([]interface{}, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var params []interface{}
+	if err := json.Unmarshal(raw, &params); err == nil {
+		return params, nil
+	}
+	var single interface{}
+	if err := json.Unmarshal(raw, &single); err != nil {
+		return nil, fmt.Errorf("无效的请求参数: %w", err)
+	}
+	return []interface{}{single}, nil
+}
+
+// sortedKeys 返回排序后的集合元素
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
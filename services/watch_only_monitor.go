@@ -0,0 +1,278 @@
+/*
+只读地址到账监控
+
+只读地址（models.WatchOnlyAddress）添加后，后台按 watch_only_monitor.interval_seconds 扫描新区块，
+发现转入该地址的原生代币或 ERC20 转账时，经 NotificationService 通知添加该地址的用户（incoming_transfer）：
+  - 按网络合并：同一网络的全部只读地址共用一次区块范围查询（ERC20 按 Transfer 日志批量查询，原生代币逐块扫描）
+  - 去重：每条记录保存已处理到的区块（LastProcessedBlock），只通知该区块之后的转账，扫描成功后才推进；
+    新添加的地址从当前区块开始监控，不回溯历史
+  - 小额过滤：记录设置了 MinValueUSD 时，按 PriceService 的美元价格估值，低于阈值或没有价格的转账（多为垃圾代币）不通知
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"wallet/config"
+	"wallet/core"
+	"wallet/database"
+	"wallet/models"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// watchTokenInfo ERC20 代币的展示信息
+type watchTokenInfo struct {
+	symbol   string
+	decimals int
+}
+
+// WatchOnlyMonitor 只读地址到账监控
+type WatchOnlyMonitor struct {
+	multiChain    *core.MultiChainManager
+	notifications *NotificationService
+	prices        *PriceService // 可为 nil，此时不按金额过滤
+	cfg           config.WatchOnlyMonitorConfig
+	tokens        map[string]watchTokenInfo // 网络ID/小写合约地址 -> 代币信息
+	stop          context.CancelFunc
+	mu            sync.Mutex
+}
+
+// NewWatchOnlyMonitor 创建只读地址到账监控
+func NewWatchOnlyMonitor(multiChain *core.MultiChainManager, notifications *NotificationService, prices *PriceService, cfg config.WatchOnlyMonitorConfig) *WatchOnlyMonitor {
+	return &WatchOnlyMonitor{
+		multiChain:    multiChain,
+		notifications: notifications,
+		prices:        prices,
+		cfg:           cfg.WithDefaults(),
+		tokens:        make(map[string]watchTokenInfo),
+	}
+}
+
+// Start 启动后台扫描，重复调用时替换之前的扫描
+func (m *WatchOnlyMonitor) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.mu.Lock()
+	if m.stop != nil {
+		m.stop()
+	}
+	m.stop = cancel
+	m.mu.Unlock()
+	go runAlertLoop(ctx, time.Duration(m.cfg.IntervalSeconds)*time.Second, m.poll)
+}
+
+// Stop 停止后台扫描
+func (m *WatchOnlyMonitor) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stop != nil {
+		m.stop()
+		m.stop = nil
+	}
+}
+
+// poll 扫描一轮全部网络
+func (m *WatchOnlyMonitor) poll(ctx context.Context) {
+	if database.DB == nil {
+		return
+	}
+	var records []models.WatchOnlyAddress
+	if err := database.DB.Order("id").Find(&records).Error; err != nil {
+		slog.DebugContext(ctx, "watch-only addresses load failed", "error", err)
+		return
+	}
+	byNetwork := make(map[string][]*models.WatchOnlyAddress)
+	for i := range records {
+		byNetwork[records[i].Network] = append(byNetwork[records[i].Network], &records[i])
+	}
+	for networkID, networkRecords := range byNetwork {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := m.pollNetwork(ctx, networkID, networkRecords); err != nil {
+			slog.DebugContext(ctx, "watch-only transfer scan failed", "network", networkID, "error", err)
+		}
+	}
+}
+
+// pollNetwork 扫描单个网络：从各记录中最早的未处理区块开始，最多 MaxBlocksPerPoll 个区块
+func (m *WatchOnlyMonitor) pollNetwork(ctx context.Context, networkID string, records []*models.WatchOnlyAddress) error {
+	adapter, err := m.multiChain.GetAdapter(networkID)
+	if err != nil {
+		return err
+	}
+	evm, ok := adapter.(*core.EVMAdapter)
+	if !ok {
+		return nil
+	}
+	latest, err := evm.LatestBlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+
+	var active []*models.WatchOnlyAddress
+	var from uint64
+	for _, record := range records {
+		if record.LastProcessedBlock == 0 {
+			// 新添加的地址从当前区块开始监控
+			m.advance(record, latest)
+			continue
+		}
+		if record.LastProcessedBlock >= latest {
+			continue
+		}
+		active = append(active, record)
+		if start := record.LastProcessedBlock + 1; from == 0 || start < from {
+			from = start
+		}
+	}
+	if len(active) == 0 {
+		return nil
+	}
+	to := latest
+	if to-from+1 > m.cfg.MaxBlocksPerPoll {
+		to = from + m.cfg.MaxBlocksPerPoll - 1
+	}
+
+	watched := make(map[common.Address]bool, len(active))
+	for _, record := range active {
+		watched[common.HexToAddress(record.Address)] = true
+	}
+	transfers, err := evm.IncomingTransfersInRange(ctx, from, to, watched)
+	if err != nil {
+		return err
+	}
+	byRecipient := make(map[common.Address][]core.IncomingTransfer)
+	for _, transfer := range transfers {
+		recipient := common.HexToAddress(transfer.To)
+		byRecipient[recipient] = append(byRecipient[recipient], transfer)
+	}
+
+	var prices map[string]*TokenPrice
+	for _, record := range active {
+		for _, transfer := range byRecipient[common.HexToAddress(record.Address)] {
+			if transfer.BlockNumber <= record.LastProcessedBlock || transfer.BlockNumber > to {
+				continue
+			}
+			if record.MinValueUSD > 0 && prices == nil {
+				prices = m.transferPrices(ctx, networkID, transfers)
+			}
+			m.notifyTransfer(ctx, evm, networkID, record, transfer, prices)
+		}
+		// 进度超过本轮范围的记录（其他用户更早添加了同一地址）保持不变
+		if to > record.LastProcessedBlock {
+			m.advance(record, to)
+		}
+	}
+	return nil
+}
+
+// notifyTransfer 估值并通知添加该只读地址的用户，低于阈值时跳过
+func (m *WatchOnlyMonitor) notifyTransfer(ctx context.Context, evm *core.EVMAdapter, networkID string, record *models.WatchOnlyAddress, transfer core.IncomingTransfer, prices map[string]*TokenPrice) {
+	symbol, decimals := m.tokenInfo(ctx, evm, networkID, transfer.Token)
+	amount := core.FormatUnits(transfer.Value, decimals)
+
+	data := map[string]interface{}{
+		"network":      networkID,
+		"address":      record.Address,
+		"label":        record.Label,
+		"tx_hash":      transfer.TxHash,
+		"from":         transfer.From,
+		"token":        transfer.Token,
+		"symbol":       symbol,
+		"amount":       amount,
+		"value":        transfer.Value.String(),
+		"block_number": transfer.BlockNumber,
+	}
+	if record.MinValueUSD > 0 {
+		price := prices[normalizePriceToken(transfer.Token)]
+		if price == nil || price.NoPrice {
+			return
+		}
+		amountFloat, err := strconv.ParseFloat(amount, 64)
+		if err != nil || amountFloat*price.PriceUSD < record.MinValueUSD {
+			return
+		}
+		data["value_usd"] = amountFloat * price.PriceUSD
+	}
+
+	name := record.Address
+	if record.Label != "" {
+		name = fmt.Sprintf("%s（%s）", record.Label, record.Address)
+	}
+	_, err := m.notifications.Send(ctx, record.OwnerAddress, core.Notification{
+		Type:    NotificationTypeIncomingTransfer,
+		Title:   "观察地址收到转账",
+		Message: fmt.Sprintf("%s 在 %s 上收到 %s %s，来自 %s", name, networkID, amount, symbol, transfer.From),
+		Data:    data,
+	})
+	if err != nil {
+		slog.DebugContext(ctx, "watch-only transfer notification failed", "network", networkID, "address", record.Address, "error", err)
+	}
+}
+
+// transferPrices 批量查询本轮转账涉及代币的美元价格，价格服务不可用时返回空结果（设置了阈值的转账均不通知）
+func (m *WatchOnlyMonitor) transferPrices(ctx context.Context, networkID string, transfers []core.IncomingTransfer) map[string]*TokenPrice {
+	prices := map[string]*TokenPrice{}
+	networkConfig, err := config.GetNetwork(networkID)
+	if m.prices == nil || err != nil {
+		return prices
+	}
+	seen := make(map[string]bool)
+	var tokens []string
+	for _, transfer := range transfers {
+		token := transfer.Token
+		if token == "" {
+			token = NativeTokenAddress
+		}
+		if !seen[token] {
+			seen[token] = true
+			tokens = append(tokens, token)
+		}
+	}
+	result, err := m.prices.GetTokenPricesUSD(ctx, int(networkConfig.ChainID), tokens)
+	if err != nil {
+		return prices
+	}
+	return result
+}
+
+// tokenInfo 代币符号与小数位：原生代币取网络配置，ERC20 查询合约元数据并缓存，查询失败时显示合约地址与最小单位
+func (m *WatchOnlyMonitor) tokenInfo(ctx context.Context, evm *core.EVMAdapter, networkID, token string) (string, int) {
+	if token == "" {
+		native := core.NativeCurrencyFor(networkID)
+		return native.Symbol, native.Decimals
+	}
+	key := networkID + "/" + strings.ToLower(token)
+	m.mu.Lock()
+	info, ok := m.tokens[key]
+	m.mu.Unlock()
+	if ok {
+		return info.symbol, info.decimals
+	}
+	_, symbol, decimals, err := evm.GetERC20Metadata(ctx, token)
+	if err != nil || symbol == "" {
+		return token, 0
+	}
+	info = watchTokenInfo{symbol: symbol, decimals: int(decimals)}
+	m.mu.Lock()
+	m.tokens[key] = info
+	m.mu.Unlock()
+	return info.symbol, info.decimals
+}
+
+// advance 推进记录已处理到的区块
+func (m *WatchOnlyMonitor) advance(record *models.WatchOnlyAddress, block uint64) {
+	err := database.DB.Model(&models.WatchOnlyAddress{}).Where("id = ?", record.ID).
+		Update("last_processed_block", block).Error
+	if err != nil {
+		slog.DebugContext(context.Background(), "watch-only scan progress update failed", "address", record.Address, "block", block, "error", err)
+		return
+	}
+	record.LastProcessedBlock = block
+}